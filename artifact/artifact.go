@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package artifact writes and reads the files of exports and backups, compressed and optionally
+// encrypted, so that they can be kept in shared object stores. The name of a file says how it was
+// written: a .gz suffix for gzip, followed by .enc if it's encrypted.
+//
+// Encrypted files are sealed with AES-256-GCM, in chunks so that they can be streamed. Every file
+// has its own data key, which is stored in its header encrypted by either a key read from a file,
+// or an AWS KMS key. Key files can hold several versions of the key, so that it can be rotated.
+package artifact
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type Options struct {
+	// Either "gzip" or "none".
+	Compression string
+	// Level of gzip compression, from 1 to 9, or -1 for the default.
+	Level int
+	// File with the 256 bit key to encrypt with, as 32 bytes or 64 hex digits, or with versions
+	// of it, one per line.
+	KeyFile string
+	// Id, ARN or alias of the AWS KMS key to encrypt with.
+	KMSKey string
+}
+
+var Config = Options{
+	Compression: "gzip",
+	Level:       gzip.BestCompression,
+}
+
+const (
+	gzExt  = ".gz"
+	encExt = ".enc"
+)
+
+// Validate returns an error if the options aren't valid, or if the key file can't be read.
+func Validate(o Options) error {
+	switch o.Compression {
+	case "gzip":
+		if o.Level != gzip.DefaultCompression && (o.Level < gzip.BestSpeed ||
+			o.Level > gzip.BestCompression) {
+			return x.Errorf("Invalid gzip compression level: %d", o.Level)
+		}
+	case "none":
+	case "zstd":
+		return x.Errorf("zstd compression isn't supported by this build. Use gzip or none")
+	default:
+		return x.Errorf("Invalid compression: %q. Use gzip or none", o.Compression)
+	}
+	if o.KeyFile != "" && o.KMSKey != "" {
+		return x.Errorf("Only one of a key file and a KMS key can be used for encryption")
+	}
+	if o.KeyFile != "" {
+		if _, err := readKeyring(o.KeyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encrypted returns whether files are written encrypted.
+func Encrypted() bool {
+	return Config.KeyFile != "" || Config.KMSKey != ""
+}
+
+// Ext returns the suffix of the names of the files written.
+func Ext() string {
+	var ext string
+	if Config.Compression != "none" {
+		ext = gzExt
+	}
+	if Encrypted() {
+		ext += encExt
+	}
+	return ext
+}
+
+// TrimExt returns name without the suffixes added by Ext.
+func TrimExt(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, encExt), gzExt)
+}
+
+// HasExt returns whether name has one of the suffixes added by Ext.
+func HasExt(name string) bool {
+	return strings.HasSuffix(name, encExt) || strings.HasSuffix(name, gzExt)
+}
+
+type writer struct {
+	gw *gzip.Writer
+	ew *encWriter
+	w  io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (w *writer) Close() error {
+	if w.gw != nil {
+		if err := w.gw.Close(); err != nil {
+			return err
+		}
+	}
+	if w.ew != nil {
+		return w.ew.Close()
+	}
+	return nil
+}
+
+// NewWriter returns a writer which compresses and encrypts what's written to it onto w, as
+// configured. Closing it flushes everything to w, but doesn't close w.
+func NewWriter(w io.Writer) (io.WriteCloser, error) {
+	aw := &writer{w: w}
+	if Encrypted() {
+		ew, err := newEncWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		aw.ew, aw.w = ew, ew
+	}
+	if Config.Compression != "none" {
+		gw, err := gzip.NewWriterLevel(aw.w, Config.Level)
+		if err != nil {
+			return nil, err
+		}
+		aw.gw, aw.w = gw, gw
+	}
+	return aw, nil
+}
+
+type reader struct {
+	gr *gzip.Reader
+	r  io.Reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *reader) Close() error {
+	if r.gr != nil {
+		return r.gr.Close()
+	}
+	return nil
+}
+
+// NewReader returns a reader of the contents of the file with the given name, read from r,
+// decrypting and decompressing it according to its suffixes. Closing it doesn't close r.
+func NewReader(r io.Reader, name string) (io.ReadCloser, error) {
+	ar := &reader{r: r}
+	if strings.HasSuffix(name, encExt) {
+		er, err := newEncReader(r)
+		if err != nil {
+			return nil, x.Wrapf(err, "While decrypting %s", name)
+		}
+		ar.r = er
+		name = strings.TrimSuffix(name, encExt)
+	}
+	if strings.HasSuffix(strings.ToLower(name), gzExt) {
+		gr, err := gzip.NewReader(ar.r)
+		if err != nil {
+			return nil, err
+		}
+		ar.gr, ar.r = gr, gr
+	}
+	return ar, nil
+}
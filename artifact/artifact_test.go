@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package artifact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func roundTrip(t *testing.T, data []byte) ([]byte, string) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes(), "file.rdf" + Ext()
+}
+
+func read(b []byte, name string) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(b), name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func TestKeyFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "key")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(strings.Repeat("ab", 32) + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	defer func(o Options) { Config = o }(Config)
+	Config.KeyFile = f.Name()
+	require.NoError(t, Validate(Config))
+	require.Equal(t, ".gz.enc", Ext())
+
+	// More than a chunk, which doesn't compress.
+	data := make([]byte, 3*chunkSize/2)
+	for i := range data {
+		data[i] = byte(i * 7919 >> 3)
+	}
+	b, name := roundTrip(t, data)
+	require.False(t, bytes.Contains(b, data[:64]))
+	out, err := read(b, name)
+	require.NoError(t, err)
+	require.Equal(t, data, out)
+
+	_, err = read(b[:len(b)-10], name)
+	require.Error(t, err)
+	b[len(b)/2] ^= 1
+	_, err = read(b, name)
+	require.Error(t, err)
+
+	Config.Compression = "none"
+	b, name = roundTrip(t, []byte("<a> <b> <c> .\n"))
+	require.Equal(t, "file.rdf.enc", name)
+	out, err = read(b, name)
+	require.NoError(t, err)
+	require.Equal(t, "<a> <b> <c> .\n", string(out))
+
+	// Without the key, the file can't be read.
+	Config.KeyFile = ""
+	_, err = read(b, name)
+	require.Error(t, err)
+}
+
+func TestKMS(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{7}, 32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Contains(t, r.Header.Get("Authorization"), "/kms/aws4_request")
+		var in map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			require.Equal(t, "alias/backups", in["KeyId"])
+			json.NewEncoder(w).Encode(map[string][]byte{
+				"Plaintext":      dataKey,
+				"CiphertextBlob": []byte("wrapped"),
+			})
+		case "TrentService.Decrypt":
+			require.Equal(t, "d3JhcHBlZA==", in["CiphertextBlob"])
+			json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": dataKey})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	for k, v := range map[string]string{
+		"KMS_ENDPOINT":          srv.URL,
+		"AWS_ACCESS_KEY_ID":     "id",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+	} {
+		defer os.Setenv(k, os.Getenv(k))
+		os.Setenv(k, v)
+	}
+
+	defer func(o Options) { Config = o }(Config)
+	Config.KMSKey = "alias/backups"
+	require.NoError(t, Validate(Config))
+	b, name := roundTrip(t, []byte("<a> <b> <c> .\n"))
+
+	// The data key is decrypted by KMS, without the key being configured.
+	Config.KMSKey = ""
+	out, err := read(b, name)
+	require.NoError(t, err)
+	require.Equal(t, "<a> <b> <c> .\n", string(out))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(Options{Compression: "gzip", Level: 1}))
+	require.Error(t, Validate(Options{Compression: "gzip", Level: 10}))
+	require.Error(t, Validate(Options{Compression: "zstd"}))
+	require.Error(t, Validate(Options{Compression: "none", KeyFile: "k", KMSKey: "alias/k"}))
+	require.Error(t, Validate(Options{Compression: "none", KeyFile: "/nonexistent"}))
+}
+
+func TestKeyRotation(t *testing.T) {
+	f, err := ioutil.TempFile("", "key")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(strings.Repeat("ab", 32) + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	defer func(o Options) { Config = o }(Config)
+	Config.KeyFile = f.Name()
+	require.NoError(t, ReloadKeys())
+	v, versions, err := KeyVersions()
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), v)
+	require.Equal(t, []uint32{1}, versions)
+	old, name := roundTrip(t, []byte("<a> <b> <c> .\n"))
+
+	v, err = RotateKey()
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), v)
+	_, versions, err = KeyVersions()
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1, 2}, versions)
+	b, _ := roundTrip(t, []byte("<d> <e> <f> .\n"))
+
+	// Files encrypted with either version can be read.
+	for _, enc := range [][]byte{old, b} {
+		_, err := read(enc, name)
+		require.NoError(t, err)
+	}
+
+	// Without version 1 in the key file, only the files of version 2 can.
+	data, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte(lines[1]+"\n"), 0600))
+	require.NoError(t, ReloadKeys())
+	_, err = read(old, name)
+	require.Error(t, err)
+	out, err := read(b, name)
+	require.NoError(t, err)
+	require.Equal(t, "<d> <e> <f> .\n", string(out))
+
+	require.NoError(t, ioutil.WriteFile(f.Name(), []byte("1 "+strings.Repeat("ab", 32)+"\n1 "+
+		strings.Repeat("cd", 32)+"\n"), 0600))
+	require.Error(t, ReloadKeys())
+}
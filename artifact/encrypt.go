@@ -0,0 +1,311 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package artifact
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/dgraph-io/dgraph/objstore"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// An encrypted file starts with a header of
+//   magic | kind of key (1 byte) | length of encrypted data key (2 bytes) | encrypted data key
+// With a key file, the encrypted data key starts with the version of the key it's encrypted with
+// (4 bytes), which it authenticates, followed by its nonce.
+// followed by chunks of
+//   length (4 bytes, with the top bit set for the last chunk) | sealed chunk
+// Each chunk is sealed with the data key and a nonce counting the chunks, and authenticates
+// whether it's the last one, so that a file can't be truncated or reordered unnoticed.
+
+var magic = []byte("DGENC1")
+
+const (
+	// keyFromFile is a key file without versions, as written before keys could be rotated.
+	keyFromFile    = 1
+	keyFromKMS     = 2
+	keyFromKeyring = 3
+
+	chunkSize = 64 << 10
+	lastChunk = 1 << 31
+)
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newDataKey returns a new data key, and its header.
+func newDataKey() ([]byte, []byte, error) {
+	var kind byte
+	var dataKey, encrypted []byte
+	if Config.KMSKey != "" {
+		var err error
+		kind = keyFromKMS
+		if dataKey, encrypted, err = objstore.GenerateDataKey(Config.KMSKey); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		kind = keyFromKeyring
+		ring, err := loadKeyring()
+		if err != nil {
+			return nil, nil, err
+		}
+		dataKey = make([]byte, 32)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, nil, err
+		}
+		gcm, err := newGCM(ring.keys[ring.current])
+		if err != nil {
+			return nil, nil, err
+		}
+		prefix := make([]byte, 4+gcm.NonceSize())
+		binary.BigEndian.PutUint32(prefix, ring.current)
+		if _, err := rand.Read(prefix[4:]); err != nil {
+			return nil, nil, err
+		}
+		encrypted = gcm.Seal(prefix, prefix[4:], dataKey, prefix[:4])
+	}
+
+	var hdr bytes.Buffer
+	hdr.Write(magic)
+	hdr.WriteByte(kind)
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(len(encrypted)))
+	hdr.Write(l[:])
+	hdr.Write(encrypted)
+	return dataKey, hdr.Bytes(), nil
+}
+
+// readDataKey reads the header of an encrypted file from r, and returns its data key.
+func readDataKey(r io.Reader) ([]byte, error) {
+	hdr := make([]byte, len(magic)+3)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hdr[:len(magic)], magic) {
+		return nil, x.Errorf("Not an encrypted file")
+	}
+	kind := hdr[len(magic)]
+	encrypted := make([]byte, binary.BigEndian.Uint16(hdr[len(magic)+1:]))
+	if _, err := io.ReadFull(r, encrypted); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case keyFromKMS:
+		return objstore.DecryptDataKey(encrypted)
+	case keyFromFile, keyFromKeyring:
+		if Config.KeyFile == "" {
+			return nil, x.Errorf("The file is encrypted with a key file, but none was given")
+		}
+		ring, err := loadKeyring()
+		if err != nil {
+			return nil, err
+		}
+		if kind == keyFromFile {
+			// The version isn't known, so each of them is tried.
+			for _, k := range ring.keys {
+				if dataKey, err := openDataKey(k, encrypted, nil); err == nil {
+					return dataKey, nil
+				}
+			}
+			return nil, x.Errorf("The file isn't encrypted with the given key")
+		}
+		if len(encrypted) < 4 {
+			return nil, x.Errorf("Invalid data key")
+		}
+		v := binary.BigEndian.Uint32(encrypted)
+		k, ok := ring.keys[v]
+		if !ok {
+			return nil, x.Errorf("The file is encrypted with version %d of the key, which isn't "+
+				"in the key file", v)
+		}
+		dataKey, err := openDataKey(k, encrypted[4:], encrypted[:4])
+		if err != nil {
+			return nil, x.Errorf("The file isn't encrypted with version %d of the given key", v)
+		}
+		return dataKey, nil
+	}
+	return nil, x.Errorf("Unknown kind of key: %d", kind)
+}
+
+// openDataKey decrypts the data key encrypted with the key k, which starts with its nonce.
+func openDataKey(k, encrypted, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(k)
+	if err != nil {
+		return nil, err
+	}
+	ns := gcm.NonceSize()
+	if len(encrypted) < ns {
+		return nil, x.Errorf("Invalid data key")
+	}
+	return gcm.Open(nil, encrypted[:ns], encrypted[ns:], aad)
+}
+
+type encWriter struct {
+	w     io.Writer
+	gcm   cipher.AEAD
+	hdr   []byte
+	buf   []byte
+	nonce []byte
+	count uint64
+}
+
+func newEncWriter(w io.Writer) (*encWriter, error) {
+	dataKey, hdr, err := newDataKey()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encWriter{
+		w:     w,
+		gcm:   gcm,
+		hdr:   hdr,
+		buf:   make([]byte, 0, chunkSize),
+		nonce: make([]byte, gcm.NonceSize()),
+	}, nil
+}
+
+// seal writes the buffered chunk.
+func (e *encWriter) seal(last bool) error {
+	if e.hdr != nil {
+		if _, err := e.w.Write(e.hdr); err != nil {
+			return err
+		}
+		e.hdr = nil
+	}
+	binary.BigEndian.PutUint64(e.nonce[len(e.nonce)-8:], e.count)
+	e.count++
+	aad := []byte{0}
+	if last {
+		aad[0] = 1
+	}
+	sealed := e.gcm.Seal(nil, e.nonce, e.buf, aad)
+	l := uint32(len(sealed))
+	if last {
+		l |= lastChunk
+	}
+	var lb [4]byte
+	binary.BigEndian.PutUint32(lb[:], l)
+	if _, err := e.w.Write(lb[:]); err != nil {
+		return err
+	}
+	_, err := e.w.Write(sealed)
+	e.buf = e.buf[:0]
+	return err
+}
+
+func (e *encWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+		}
+		c := chunkSize - len(e.buf)
+		if c > len(p) {
+			c = len(p)
+		}
+		e.buf = append(e.buf, p[:c]...)
+		p = p[c:]
+	}
+	return n, nil
+}
+
+// Close writes the last chunk.
+func (e *encWriter) Close() error {
+	return e.seal(true)
+}
+
+type encReader struct {
+	r     io.Reader
+	gcm   cipher.AEAD
+	buf   []byte
+	nonce []byte
+	count uint64
+	last  bool
+}
+
+func newEncReader(r io.Reader) (*encReader, error) {
+	dataKey, err := readDataKey(r)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encReader{r: r, gcm: gcm, nonce: make([]byte, gcm.NonceSize())}, nil
+}
+
+func (e *encReader) next() error {
+	var lb [4]byte
+	if _, err := io.ReadFull(e.r, lb[:]); err == io.EOF {
+		return x.Errorf("The encrypted file is truncated")
+	} else if err != nil {
+		return err
+	}
+	l := binary.BigEndian.Uint32(lb[:])
+	last := l&lastChunk != 0
+	l &^= lastChunk
+	if l > chunkSize+uint32(e.gcm.Overhead()) {
+		return x.Errorf("Invalid chunk of encrypted file")
+	}
+	sealed := make([]byte, l)
+	if _, err := io.ReadFull(e.r, sealed); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(e.nonce[len(e.nonce)-8:], e.count)
+	e.count++
+	aad := []byte{0}
+	if last {
+		aad[0] = 1
+	}
+	buf, err := e.gcm.Open(sealed[:0], e.nonce, sealed, aad)
+	if err != nil {
+		return x.Errorf("The encrypted file is corrupt")
+	}
+	e.buf, e.last = buf, last
+	return nil
+}
+
+func (e *encReader) Read(p []byte) (int, error) {
+	for len(e.buf) == 0 {
+		if e.last {
+			return 0, io.EOF
+		}
+		if err := e.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package artifact
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A key file holds either a single key, which is version 1 of it, or versions of the key, one per
+// line, as the version followed by the key in hex:
+//
+//	1 5f0c...
+//	2 9ab3...
+//
+// Files are encrypted with the latest version, and record it in their headers, so that those
+// encrypted with earlier versions can still be read as long as they're kept in the file. Rotating
+// the key adds a version; older ones can be removed once no file encrypted with them is needed.
+
+type keyring struct {
+	current uint32
+	keys    map[uint32][]byte
+}
+
+var keys struct {
+	sync.Mutex
+	path string
+	ring *keyring
+}
+
+func parseKey(s, fpath string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 32 {
+		return nil, x.Errorf("The key in %s should be 32 bytes, or 64 hex digits", fpath)
+	}
+	return b, nil
+}
+
+func readKeyring(fpath string) (*keyring, error) {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 32 {
+		return &keyring{current: 1, keys: map[uint32][]byte{1: b}}, nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) == 1 && !strings.ContainsAny(lines[0], " \t") {
+		k, err := parseKey(strings.TrimSpace(lines[0]), fpath)
+		if err != nil {
+			return nil, err
+		}
+		return &keyring{current: 1, keys: map[uint32][]byte{1: k}}, nil
+	}
+	ring := &keyring{keys: make(map[uint32][]byte)}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, x.Errorf("Invalid line in %s. Expected the version and the key", fpath)
+		}
+		v, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil || v == 0 {
+			return nil, x.Errorf("Invalid key version in %s: %q", fpath, fields[0])
+		}
+		if _, ok := ring.keys[uint32(v)]; ok {
+			return nil, x.Errorf("Version %d of the key is in %s twice", v, fpath)
+		}
+		if ring.keys[uint32(v)], err = parseKey(fields[1], fpath); err != nil {
+			return nil, err
+		}
+		if uint32(v) > ring.current {
+			ring.current = uint32(v)
+		}
+	}
+	if len(ring.keys) == 0 {
+		return nil, x.Errorf("No key in %s", fpath)
+	}
+	return ring, nil
+}
+
+// loadKeyring returns the versions of the key in Config.KeyFile, which are read once.
+func loadKeyring() (*keyring, error) {
+	keys.Lock()
+	defer keys.Unlock()
+	if keys.ring != nil && keys.path == Config.KeyFile {
+		return keys.ring, nil
+	}
+	ring, err := readKeyring(Config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	keys.ring, keys.path = ring, Config.KeyFile
+	return ring, nil
+}
+
+// ReloadKeys reads the key file again, for the versions added to it since it was read.
+func ReloadKeys() error {
+	if Config.KeyFile == "" {
+		return nil
+	}
+	ring, err := readKeyring(Config.KeyFile)
+	if err != nil {
+		return err
+	}
+	keys.Lock()
+	keys.ring, keys.path = ring, Config.KeyFile
+	keys.Unlock()
+	return nil
+}
+
+// KeyVersions returns the version of the key files are encrypted with, and all the versions in the
+// key file. The version is 0 without a key file.
+func KeyVersions() (uint32, []uint32, error) {
+	if Config.KeyFile == "" {
+		return 0, nil, nil
+	}
+	ring, err := loadKeyring()
+	if err != nil {
+		return 0, nil, err
+	}
+	var versions []uint32
+	for v := range ring.keys {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return ring.current, versions, nil
+}
+
+// RotateKey adds a new version of the key to the key file, which files are encrypted with from
+// then on, and returns it. Files encrypted with earlier versions can still be read.
+func RotateKey() (uint32, error) {
+	if Config.KeyFile == "" {
+		return 0, x.Errorf("Rotating the key needs a key file. KMS keys are rotated in KMS")
+	}
+	keys.Lock()
+	defer keys.Unlock()
+	// The file is read again, not to lose versions added to it by others.
+	ring, err := readKeyring(Config.KeyFile)
+	if err != nil {
+		return 0, err
+	}
+	k := make([]byte, 32)
+	if _, err := rand.Read(k); err != nil {
+		return 0, err
+	}
+	ring.current++
+	ring.keys[ring.current] = k
+
+	var versions []uint32
+	for v := range ring.keys {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	var buf bytes.Buffer
+	for _, v := range versions {
+		fmt.Fprintf(&buf, "%d %s\n", v, hex.EncodeToString(ring.keys[v]))
+	}
+	if err := writeKeyFile(Config.KeyFile, buf.Bytes()); err != nil {
+		return 0, err
+	}
+	keys.ring, keys.path = ring, Config.KeyFile
+	return ring.current, nil
+}
+
+// writeKeyFile replaces the key file at fpath with data at once, so that no key is ever lost to a
+// failed write.
+func writeKeyFile(fpath string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(fpath), "."+filepath.Base(fpath)+"-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fpath)
+}
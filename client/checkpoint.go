@@ -72,6 +72,16 @@ func (d *Dgraph) Checkpoint(file string) (uint64, error) {
 	return d.alloc.getFromKV(fmt.Sprintf("checkpoint-%s", file))
 }
 
+// SaveCheckpoint records that everything up to line of file has been processed by the server, for
+// files which aren't loaded with BatchSetWithMark. file doesn't have to be a path, but it shouldn't
+// be the path of a file loaded with marks.
+func (d *Dgraph) SaveCheckpoint(file string, line uint64) error {
+	d.syncMappings()
+	var buf [10]byte
+	n := binary.PutUvarint(buf[:], line)
+	return d.alloc.kv.Set([]byte(fmt.Sprintf("checkpoint-%s", file)), buf[:n], 0)
+}
+
 // Used to write checkpoints to Badger.
 func (d *Dgraph) writeCheckpoint() {
 	wb := make([]*badger.Entry, 0, len(d.marks))
@@ -86,6 +96,12 @@ func (d *Dgraph) writeCheckpoint() {
 		n := binary.PutUvarint(buf[:], doneUntil)
 		wb = badger.EntriesSet(wb, []byte(fmt.Sprintf("checkpoint-%s", file)), buf[:n])
 	}
+	if len(wb) == 0 {
+		return
+	}
+	// The uids assigned to the nodes in the lines done have to be written before the checkpoint,
+	// or a resumed load would assign new uids to them.
+	d.syncMappings()
 
 	if err := d.alloc.kv.BatchSet(wb); err != nil {
 		fmt.Printf("Error while writing to disk %v\n", err)
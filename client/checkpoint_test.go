@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 Dgraph Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// fakeClient only assigns uids, a thousand at a time.
+type fakeClient struct {
+	protos.DgraphClient
+	next uint64
+}
+
+func (c *fakeClient) AssignUids(ctx context.Context, num *protos.Num,
+	_ ...grpc.CallOption) (*protos.AssignedIds, error) {
+	start := c.next + 1
+	c.next += num.Val
+	return &protos.AssignedIds{StartId: start, EndId: c.next}, nil
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clientdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fc := &fakeClient{}
+	d := NewClient([]protos.DgraphClient{fc}, DefaultOptions, dir)
+	alice, err := d.NodeXid("alice", false)
+	require.NoError(t, err)
+	bob, err := d.NodeBlank("bob")
+	require.NoError(t, err)
+	// The mappings have to be on disk once the checkpoint is.
+	require.NoError(t, d.SaveCheckpoint("file", 10))
+	require.NoError(t, d.Close())
+
+	d = NewClient([]protos.DgraphClient{fc}, DefaultOptions, dir)
+	defer d.Close()
+	line, err := d.Checkpoint("file")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), line)
+
+	n, err := d.NodeXid("alice", false)
+	require.NoError(t, err)
+	require.Equal(t, alice.String(), n.String())
+	n, err = d.NodeBlank("bob")
+	require.NoError(t, err)
+	require.Equal(t, bob.String(), n.String())
+	n, err = d.NodeXid("carol", false)
+	require.NoError(t, err)
+	require.NotEqual(t, alice.String(), n.String())
+}
@@ -18,7 +18,6 @@ package client
 
 import (
 	"encoding/base64"
-	"encoding/binary"
 	"fmt"
 	"sync"
 	"time"
@@ -27,8 +26,6 @@ import (
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 	geom "github.com/twpayne/go-geom"
-	"github.com/twpayne/go-geom/encoding/geojson"
-	"github.com/twpayne/go-geom/encoding/wkb"
 )
 
 type opType int
@@ -40,6 +37,9 @@ const (
 	DEL
 )
 
+// APIVersion is the latest API version of the server the client supports.
+const APIVersion = 2
+
 // A Req represents a single request to the backend Dgraph instance.  Each request may contain
 // multiple set, delete and schema mutations, and a single GraphQL+- query.  If the query contains
 // GraphQL variables, then it must be set with SetQueryWithVariables rather than SetQuery.
@@ -91,6 +91,19 @@ func (req *Req) SetQueryWithVariables(q string, vars map[string]string) {
 	req.gr.Vars = vars
 }
 
+// SetPersistedQuery sets req to run the query persisted on the server under id, with vars as
+// its query variables. It can't be combined with a query set by SetQuery.
+func (req *Req) SetPersistedQuery(id string, vars map[string]string) {
+	req.gr.QueryId = id
+	req.gr.Vars = vars
+}
+
+// SetAPIVersion sets the API version to run req with, like the one returned by
+// Dgraph.NegotiateAPIVersion. Requests without one run with the oldest version of the server.
+func (req *Req) SetAPIVersion(v uint32) {
+	req.gr.ApiVersion = v
+}
+
 func (req *Req) addMutation(e Edge, op opType) {
 	if req.gr.Mutation == nil {
 		req.gr.Mutation = new(protos.Mutation)
@@ -408,14 +421,14 @@ func (e *Edge) SetValueDatetime(dateTime time.Time) error {
 // the type of the edge to types.GeoID.  If the edge had previous been assigned another value (even
 // of another type), the value and type are overwritten.  If the edge has previously been connected
 // to a node, the edge and type are left unchanged and ErrConnected is returned. If the string
-// fails to parse with geojson.Unmarshal() the edge is left unchanged and an error returned.
+// fails to parse with types.UnmarshalGeoJSON() the edge is left unchanged and an error returned.
 func (e *Edge) SetValueGeoJson(json string) error {
 	if len(e.nq.ObjectId) > 0 {
 		return ErrConnected
 	}
 	var g geom.T
 	// Parse the json
-	err := geojson.Unmarshal([]byte(json), &g)
+	err := types.UnmarshalGeoJSON([]byte(json), &g)
 	if err != nil {
 		return err
 	}
@@ -435,13 +448,13 @@ func (e *Edge) SetValueGeoJson(json string) error {
 // the type of the edge to types.GeoID.  If the edge had previous been assigned another value (even
 // of another type), the value and type are overwritten.  If the edge has previously been connected
 // to a node, the edge and type are left unchanged and ErrConnected is returned. If the geometry
-// fails to be marshalled with wkb.Marshal() the edge is left unchanged and an error returned.
+// fails to be marshalled with types.MarshalWKB() the edge is left unchanged and an error returned.
 func (e *Edge) SetValueGeoGeometry(g geom.T) error {
 	if len(e.nq.ObjectId) > 0 {
 		return ErrConnected
 	}
 
-	b, err := wkb.Marshal(g, binary.LittleEndian)
+	b, err := types.MarshalWKB(g)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,500 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+var ErrNoServers = errors.New("No servers of the cluster are available.")
+
+// ClusterOptions sets how a client made by NewClusterClient discovers the servers of the cluster
+// and fails over between them.
+type ClusterOptions struct {
+	// DialOptions are used for the connections to the servers.
+	DialOptions []grpc.DialOption
+	// RefreshInterval is how often the servers of the cluster are discovered again.
+	RefreshInterval time.Duration
+	// Retries is how many other servers an idempotent request is tried on, while the servers
+	// it's sent to are unavailable.
+	Retries int
+	// DownFor is how long requests aren't sent to a server found unavailable.
+	DownFor time.Duration
+}
+
+var DefaultClusterOptions = ClusterOptions{
+	RefreshInterval: time.Minute,
+	Retries:         3,
+	DownFor:         10 * time.Second,
+}
+
+// endpoint is a server of the cluster the client is connected to.
+type endpoint struct {
+	addr      string
+	dc        protos.DgraphClient
+	conn      *grpc.ClientConn // Nil unless dialed by NewClusterClient.
+	downUntil time.Time
+}
+
+// cluster is a protos.DgraphClient which sends each request to one of the servers of a cluster.
+// Queries are balanced across the servers, mutations are sent to the leader of the group serving
+// most of their predicates, and idempotent requests are retried on other servers on failover.
+type cluster struct {
+	sync.RWMutex
+	opts ClusterOptions
+	dial func(addr string) (*endpoint, error)
+
+	endpoints map[string]*endpoint
+	// addrs has the keys of endpoints in order, for round robin.
+	addrs []string
+	next  uint32
+	// leaders has the address of the leader of each group.
+	leaders map[uint32]string
+	// groups has the group serving each predicate mutated so far.
+	groups map[string]uint32
+
+	refreshCh chan struct{}
+	done      chan struct{}
+}
+
+// NewClusterClient creates a Dgraph client for a cluster, which discovers the servers of the
+// cluster from any of seeds, the gRPC addresses of some of them. The servers are discovered again
+// every opts.RefreshInterval, and once one is found unavailable.
+//
+// Requests are sent to one server at a time: queries in turn to each one, and mutations to the
+// leader of the group serving the most of their predicates. Requests which can be run more than
+// once with the same outcome, those which don't mutate blank nodes, are retried on up to
+// opts.Retries other servers while the servers they're sent to are unavailable.
+func NewClusterClient(ctx context.Context, seeds []string, copts ClusterOptions,
+	opts BatchMutationOptions, clientDir string) (*Dgraph, error) {
+	c := newCluster(copts, func(addr string) (*endpoint, error) {
+		conn, err := grpc.Dial(addr, copts.DialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return &endpoint{addr: addr, dc: protos.NewDgraphClient(conn), conn: conn}, nil
+	})
+	if err := c.discover(ctx, seeds); err != nil {
+		c.close()
+		return nil, err
+	}
+	go c.refreshPeriodically()
+
+	d := NewClient([]protos.DgraphClient{c}, opts, clientDir)
+	d.cluster = c
+	return d, nil
+}
+
+func newCluster(opts ClusterOptions, dial func(addr string) (*endpoint, error)) *cluster {
+	if opts.RefreshInterval == 0 {
+		opts.RefreshInterval = DefaultClusterOptions.RefreshInterval
+	}
+	if opts.DownFor == 0 {
+		opts.DownFor = DefaultClusterOptions.DownFor
+	}
+	return &cluster{
+		opts:      opts,
+		dial:      dial,
+		endpoints: make(map[string]*endpoint),
+		leaders:   make(map[uint32]string),
+		groups:    make(map[string]uint32),
+		refreshCh: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+}
+
+// discover connects to the seeds, and then to the servers of the cluster they tell of.
+func (c *cluster) discover(ctx context.Context, seeds []string) error {
+	c.connect(seeds)
+	if len(c.addrs) == 0 {
+		return fmt.Errorf("Couldn't connect to any of %v", seeds)
+	}
+	if err := c.refresh(ctx); err != nil {
+		return fmt.Errorf("While discovering the cluster from %v: %v", seeds, err)
+	}
+	return nil
+}
+
+// connect dials the addresses not connected to yet. Addresses which fail to be dialed are left
+// out, until they're discovered again.
+func (c *cluster) connect(addrs []string) {
+	var dialed []*endpoint
+	c.RLock()
+	for _, addr := range addrs {
+		if _, ok := c.endpoints[addr]; ok {
+			continue
+		}
+		if e, err := c.dial(addr); err == nil {
+			dialed = append(dialed, e)
+		}
+	}
+	c.RUnlock()
+
+	c.Lock()
+	defer c.Unlock()
+	for _, e := range dialed {
+		if _, ok := c.endpoints[e.addr]; ok {
+			if e.conn != nil {
+				e.conn.Close()
+			}
+			continue
+		}
+		c.endpoints[e.addr] = e
+	}
+	c.sortAddrs()
+}
+
+func (c *cluster) sortAddrs() {
+	c.addrs = c.addrs[:0]
+	for addr := range c.endpoints {
+		c.addrs = append(c.addrs, addr)
+	}
+	sort.Strings(c.addrs)
+}
+
+// refresh asks a server for the members of the cluster, connects to the new ones and drops the
+// ones gone.
+func (c *cluster) refresh(ctx context.Context) error {
+	list, err := c.members(ctx, nil)
+	if err != nil {
+		return err
+	}
+	c.update(list)
+	return nil
+}
+
+func (c *cluster) members(ctx context.Context, preds []string) (*protos.MemberList, error) {
+	var list *protos.MemberList
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		list, err = dc.Members(ctx, &protos.MembersRequest{Predicates: preds})
+		return err
+	})
+	return list, err
+}
+
+func (c *cluster) update(list *protos.MemberList) {
+	// Servers which don't tell their client address aren't listed, so the connections we have
+	// are kept if none are.
+	if len(list.Members) == 0 {
+		return
+	}
+	listed := make(map[string]bool)
+	leaders := make(map[uint32]string)
+	var addrs []string
+	for _, m := range list.Members {
+		if !listed[m.Addr] {
+			addrs = append(addrs, m.Addr)
+		}
+		listed[m.Addr] = true
+		if m.Leader {
+			leaders[m.GroupId] = m.Addr
+		}
+	}
+	c.connect(addrs)
+
+	c.Lock()
+	defer c.Unlock()
+	c.leaders = leaders
+	connected := false
+	for _, addr := range addrs {
+		if _, ok := c.endpoints[addr]; ok {
+			connected = true
+		}
+	}
+	for addr, e := range c.endpoints {
+		// The servers gone are only dropped once we're connected to one of the listed ones.
+		if listed[addr] || !connected {
+			continue
+		}
+		delete(c.endpoints, addr)
+		if e.conn != nil {
+			e.conn.Close()
+		}
+	}
+	c.sortAddrs()
+}
+
+func (c *cluster) refreshPeriodically() {
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		case <-c.refreshCh:
+		}
+		// If no server can be asked now, the members are asked for again on the next tick.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		c.refresh(ctx)
+		cancel()
+	}
+}
+
+func (c *cluster) close() {
+	close(c.done)
+	c.Lock()
+	defer c.Unlock()
+	for _, e := range c.endpoints {
+		if e.conn != nil {
+			e.conn.Close()
+		}
+	}
+}
+
+// markDown keeps requests from being sent to e for opts.DownFor, and has the members of the
+// cluster discovered again.
+func (c *cluster) markDown(e *endpoint) {
+	c.Lock()
+	e.downUntil = time.Now().Add(c.opts.DownFor)
+	c.Unlock()
+	select {
+	case c.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// pick returns the server to send gr to, out of those not tried yet.
+func (c *cluster) pick(ctx context.Context, gr *protos.Request,
+	tried map[string]bool) (*endpoint, error) {
+	if preds := predicates(gr); len(preds) > 0 {
+		if e := c.leaderFor(ctx, preds, tried); e != nil {
+			return e, nil
+		}
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+	now := time.Now()
+	var down *endpoint
+	for range c.addrs {
+		idx := atomic.AddUint32(&c.next, 1) % uint32(len(c.addrs))
+		e := c.endpoints[c.addrs[idx]]
+		if tried[e.addr] {
+			continue
+		}
+		if now.Before(e.downUntil) {
+			if down == nil {
+				down = e
+			}
+			continue
+		}
+		return e, nil
+	}
+	// Better to try a server which was down than to fail without trying.
+	if down != nil {
+		return down, nil
+	}
+	return nil, ErrNoServers
+}
+
+// leaderFor returns the leader of the group serving the most of preds, if it's up and wasn't
+// tried yet.
+func (c *cluster) leaderFor(ctx context.Context, preds []string,
+	tried map[string]bool) *endpoint {
+	var unknown []string
+	c.RLock()
+	for _, pred := range preds {
+		if _, ok := c.groups[pred]; !ok {
+			unknown = append(unknown, pred)
+		}
+	}
+	c.RUnlock()
+	if len(unknown) > 0 {
+		list, err := c.members(ctx, unknown)
+		if err != nil || len(list.Groups) != len(unknown) {
+			return nil
+		}
+		c.update(list)
+		c.Lock()
+		for i, gid := range list.Groups {
+			c.groups[unknown[i]] = gid
+		}
+		c.Unlock()
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+	var best uint32
+	count := make(map[uint32]int)
+	for _, pred := range preds {
+		gid := c.groups[pred]
+		count[gid]++
+		if count[gid] > count[best] {
+			best = gid
+		}
+	}
+	e, ok := c.endpoints[c.leaders[best]]
+	if !ok || tried[e.addr] || time.Now().Before(e.downUntil) {
+		return nil
+	}
+	return e
+}
+
+// predicates returns the predicates of the N-Quads mutated by gr.
+func predicates(gr *protos.Request) []string {
+	if gr == nil || gr.Mutation == nil {
+		return nil
+	}
+	var preds []string
+	for _, nq := range gr.Mutation.Set {
+		preds = append(preds, nq.Predicate)
+	}
+	for _, nq := range gr.Mutation.Del {
+		preds = append(preds, nq.Predicate)
+	}
+	return preds
+}
+
+// idempotent returns whether gr has the same outcome however many times it's run, which it
+// doesn't if it mutates blank nodes, as they're assigned new uids each time.
+func idempotent(gr *protos.Request) bool {
+	if gr == nil {
+		return true
+	}
+	if strings.Contains(gr.Query, "_:") {
+		return false
+	}
+	if gr.Mutation == nil {
+		return true
+	}
+	for _, nqs := range [][]*protos.NQuad{gr.Mutation.Set, gr.Mutation.Del} {
+		for _, nq := range nqs {
+			if strings.HasPrefix(nq.Subject, "_:") || strings.HasPrefix(nq.ObjectId, "_:") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// call runs fn with the server picked for gr. While the servers are unavailable, fn is run again
+// with up to opts.Retries other servers, if gr is idempotent.
+func (c *cluster) call(ctx context.Context, gr *protos.Request,
+	fn func(protos.DgraphClient) error) error {
+	tried := make(map[string]bool)
+	var lastErr error
+	for {
+		e, err := c.pick(ctx, gr, tried)
+		if err != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return err
+		}
+		err = fn(e.dc)
+		if err == nil || grpc.Code(err) != codes.Unavailable {
+			return err
+		}
+		c.markDown(e)
+		tried[e.addr] = true
+		lastErr = err
+		if !idempotent(gr) || len(tried) > c.opts.Retries || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+func (c *cluster) Run(ctx context.Context, in *protos.Request,
+	opts ...grpc.CallOption) (*protos.Response, error) {
+	var resp *protos.Response
+	err := c.call(ctx, in, func(dc protos.DgraphClient) error {
+		var err error
+		resp, err = dc.Run(ctx, in, opts...)
+		return err
+	})
+	return resp, err
+}
+
+func (c *cluster) RunStream(ctx context.Context, in *protos.Request,
+	opts ...grpc.CallOption) (protos.Dgraph_RunStreamClient, error) {
+	var stream protos.Dgraph_RunStreamClient
+	err := c.call(ctx, in, func(dc protos.DgraphClient) error {
+		var err error
+		stream, err = dc.RunStream(ctx, in, opts...)
+		return err
+	})
+	return stream, err
+}
+
+func (c *cluster) CheckVersion(ctx context.Context, in *protos.Check,
+	opts ...grpc.CallOption) (*protos.Version, error) {
+	var v *protos.Version
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		v, err = dc.CheckVersion(ctx, in, opts...)
+		return err
+	})
+	return v, err
+}
+
+func (c *cluster) AssignUids(ctx context.Context, in *protos.Num,
+	opts ...grpc.CallOption) (*protos.AssignedIds, error) {
+	var ids *protos.AssignedIds
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		ids, err = dc.AssignUids(ctx, in, opts...)
+		return err
+	})
+	return ids, err
+}
+
+func (c *cluster) Export(ctx context.Context, in *protos.ExportRequest,
+	opts ...grpc.CallOption) (protos.Dgraph_ExportClient, error) {
+	var stream protos.Dgraph_ExportClient
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		stream, err = dc.Export(ctx, in, opts...)
+		return err
+	})
+	return stream, err
+}
+
+func (c *cluster) Members(ctx context.Context, in *protos.MembersRequest,
+	opts ...grpc.CallOption) (*protos.MemberList, error) {
+	var list *protos.MemberList
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		list, err = dc.Members(ctx, in, opts...)
+		return err
+	})
+	return list, err
+}
+
+func (c *cluster) Subscribe(ctx context.Context, in *protos.SubscribeRequest,
+	opts ...grpc.CallOption) (protos.Dgraph_SubscribeClient, error) {
+	var stream protos.Dgraph_SubscribeClient
+	err := c.call(ctx, nil, func(dc protos.DgraphClient) error {
+		var err error
+		stream, err = dc.Subscribe(ctx, in, opts...)
+		return err
+	})
+	return stream, err
+}
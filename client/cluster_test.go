@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// fakeServer runs requests by counting them, unless it's down.
+type fakeServer struct {
+	protos.DgraphClient
+	members *protos.MemberList
+	groups  map[string]uint32
+	down    bool
+	runs    int
+}
+
+func (s *fakeServer) Run(ctx context.Context, in *protos.Request,
+	_ ...grpc.CallOption) (*protos.Response, error) {
+	if s.down {
+		return nil, grpc.Errorf(codes.Unavailable, "down")
+	}
+	s.runs++
+	return &protos.Response{}, nil
+}
+
+func (s *fakeServer) Members(ctx context.Context, in *protos.MembersRequest,
+	_ ...grpc.CallOption) (*protos.MemberList, error) {
+	if s.down {
+		return nil, grpc.Errorf(codes.Unavailable, "down")
+	}
+	list := &protos.MemberList{Members: s.members.Members}
+	for _, pred := range in.Predicates {
+		list.Groups = append(list.Groups, s.groups[pred])
+	}
+	return list, nil
+}
+
+func newFakeCluster(t *testing.T) (*cluster, map[string]*fakeServer) {
+	members := &protos.MemberList{Members: []*protos.Member{
+		{Id: 1, GroupId: 1, Addr: "a", Leader: true},
+		{Id: 2, GroupId: 1, Addr: "b"},
+		{Id: 2, GroupId: 2, Addr: "b", Leader: true},
+		{Id: 3, GroupId: 2, Addr: "c"},
+	}}
+	groups := map[string]uint32{"name": 1, "age": 2, "friend": 2}
+	servers := map[string]*fakeServer{
+		"a": {members: members, groups: groups},
+		"b": {members: members, groups: groups},
+		"c": {members: members, groups: groups},
+	}
+	c := newCluster(DefaultClusterOptions, func(addr string) (*endpoint, error) {
+		return &endpoint{addr: addr, dc: servers[addr]}, nil
+	})
+	require.NoError(t, c.discover(context.Background(), []string{"a"}))
+	require.Equal(t, []string{"a", "b", "c"}, c.addrs)
+	return c, servers
+}
+
+func TestClusterBalancesQueries(t *testing.T) {
+	c, servers := newFakeCluster(t)
+	for i := 0; i < 6; i++ {
+		_, err := c.Run(context.Background(), &protos.Request{Query: "{ me(id: 0x1) { name } }"})
+		require.NoError(t, err)
+	}
+	for _, s := range servers {
+		require.Equal(t, 2, s.runs)
+	}
+}
+
+func TestClusterRoutesMutationsToLeaders(t *testing.T) {
+	c, servers := newFakeCluster(t)
+	name := &protos.Value{Val: &protos.Value_StrVal{StrVal: "Alice"}}
+	age := &protos.Value{Val: &protos.Value_IntVal{IntVal: 30}}
+	req := &protos.Request{Mutation: &protos.Mutation{Set: []*protos.NQuad{
+		{Subject: "0x1", Predicate: "name", ObjectValue: name},
+		{Subject: "0x1", Predicate: "age", ObjectValue: age},
+		{Subject: "0x1", Predicate: "friend", ObjectId: "0x2"},
+	}}}
+	for i := 0; i < 3; i++ {
+		_, err := c.Run(context.Background(), req)
+		require.NoError(t, err)
+	}
+	// Group 2 serves two of the three predicates, and b leads it.
+	require.Equal(t, 3, servers["b"].runs)
+	require.Equal(t, uint32(1), c.groups["name"])
+	require.Equal(t, uint32(2), c.groups["friend"])
+}
+
+func TestClusterRetriesOnFailover(t *testing.T) {
+	c, servers := newFakeCluster(t)
+	servers["a"].down = true
+	servers["b"].down = true
+	for i := 0; i < 3; i++ {
+		_, err := c.Run(context.Background(), &protos.Request{Query: "{ me(id: 0x1) { name } }"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, servers["c"].runs)
+
+	// Mutations of blank nodes aren't retried, as they'd create the nodes again.
+	c, servers = newFakeCluster(t)
+	servers["b"].down = true
+	req := &protos.Request{Mutation: &protos.Mutation{Set: []*protos.NQuad{
+		{Subject: "_:a", Predicate: "friend", ObjectId: "_:b"},
+	}}}
+	_, err := c.Run(context.Background(), req)
+	require.Equal(t, codes.Unavailable, grpc.Code(err))
+	require.Equal(t, 0, servers["a"].runs+servers["c"].runs)
+
+	// Down servers are skipped until they're up again.
+	servers["b"].down = false
+	_, err = c.Run(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, 0, servers["b"].runs)
+}
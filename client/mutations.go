@@ -21,6 +21,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
@@ -77,7 +78,7 @@ type allocator struct {
 	kv  *badger.KV
 	ids *cache
 
-	syncCh chan entry
+	syncCh chan syncEntry
 
 	startId uint64
 	endId   uint64
@@ -155,7 +156,7 @@ func (a *allocator) assignOrGet(id string) (uid uint64, isNew bool, err error) {
 		// TODO: Better to delete after it's persisted, can cause race
 		// may be persist it during eviction and delete after it's synced
 		// to disk
-		a.syncCh <- entry{key: id, value: uid}
+		a.syncCh <- syncEntry{entry: entry{key: id, value: uid}}
 	}
 	a.ids.Add(id, uid)
 	isNew = true
@@ -183,7 +184,9 @@ type Dgraph struct {
 	nquads chan nquadOp
 	dc     []protos.DgraphClient
 	alloc  *allocator
-	ticker *time.Ticker
+	// Set for clients made by NewClusterClient, whose dc is the cluster alone.
+	cluster *cluster
+	ticker  *time.Ticker
 
 	// Miscellaneous information to print counters.
 	// Num of RDF's sent
@@ -239,7 +242,7 @@ func NewClient(clients []protos.DgraphClient, opts BatchMutationOptions, clientD
 		dc:     clients[0],
 		ids:    newCache(100000),
 		kv:     kv,
-		syncCh: make(chan entry, 10000),
+		syncCh: make(chan syncEntry, 10000),
 		ctx:    opts.Ctx,
 	}
 
@@ -280,9 +283,26 @@ func NewClient(clients []protos.DgraphClient, opts BatchMutationOptions, clientD
 // Close makes sure that the kv-store is closed properly. This should be called after using the
 // Dgraph client.
 func (d *Dgraph) Close() error {
+	if d.cluster != nil {
+		d.cluster.close()
+	}
 	return d.alloc.kv.Close()
 }
 
+// A syncEntry is a mapping to write to the client dir. One with done set only marks a point in
+// syncCh: done is closed once all the mappings sent before it have been written.
+type syncEntry struct {
+	entry
+	done chan struct{}
+}
+
+// syncMappings waits for all the mappings assigned so far to be written to the client dir.
+func (d *Dgraph) syncMappings() {
+	done := make(chan struct{})
+	d.alloc.syncCh <- syncEntry{done: done}
+	<-done
+}
+
 func (d *Dgraph) batchSync() {
 	var entries []entry
 	var loop uint64
@@ -290,9 +310,14 @@ func (d *Dgraph) batchSync() {
 
 	for {
 		ent := <-d.alloc.syncCh
+		var synced []chan struct{}
 	slurpLoop:
 		for {
-			entries = append(entries, ent)
+			if ent.done != nil {
+				synced = append(synced, ent.done)
+			} else {
+				entries = append(entries, ent.entry)
+			}
 			if len(entries) == 1000 {
 				// Avoid making infinite batch, push back against syncCh.
 				break
@@ -321,6 +346,9 @@ func (d *Dgraph) batchSync() {
 		}
 		wb = wb[:0]
 		entries = entries[:0]
+		for _, done := range synced {
+			close(done)
+		}
 	}
 }
 
@@ -590,6 +618,8 @@ func (d *Dgraph) BatchFlush() error {
 		select {
 		case err := <-d.che:
 			if err != nil {
+				// Keep the progress made until now, so that the load can be resumed.
+				d.writeCheckpoint()
 				// To signal other go-routines to stop.
 				d.stopTickers()
 				return err
@@ -614,7 +644,7 @@ func (d *Dgraph) BatchFlush() error {
 // Mutations in the request are run before a query --- except when query variables link the
 // mutation and query (see for example NodeUidVar) when the query is run first.
 //
-// Run returns a protos.Response which has the following fields
+// # Run returns a protos.Response which has the following fields
 //
 // - L : Latency information
 //
@@ -631,20 +661,23 @@ func (d *Dgraph) BatchFlush() error {
 // query, the Attribute is the edge followed and the Properties are the scalar edges.
 //
 // Print a response with
-// 	"github.com/gogo/protobuf/proto"
-// 	...
-// 	req.SetQuery(`{
-// 		friends(func: eq(name, "Alex")) {
+//
+//	"github.com/gogo/protobuf/proto"
+//	...
+//	req.SetQuery(`{
+//		friends(func: eq(name, "Alex")) {
 //			name
 //			friend {
-// 				name
+//				name
 //			}
 //		}
 //	}`)
-// 	...
-// 	resp, err := dgraphClient.Run(context.Background(), &req)
-// 	fmt.Printf("%+v\n", proto.MarshalTextString(resp))
+//	...
+//	resp, err := dgraphClient.Run(context.Background(), &req)
+//	fmt.Printf("%+v\n", proto.MarshalTextString(resp))
+//
 // Outputs
+//
 //	n: <
 //	  attribute: "_root_"
 //	  children: <
@@ -672,6 +705,85 @@ func (d *Dgraph) Run(ctx context.Context, req *Req) (*protos.Response, error) {
 	return d.dc[rand.Intn(len(d.dc))].Run(ctx, &req.gr)
 }
 
+// RunStream runs the request in req like Run, but hands the response to fn in parts, as the
+// server builds them. Each part has a _root_ Node in N with the next results of one query block,
+// in the order of the blocks. The first part also has the AssignedUids and the Schema, and the
+// last one, without results, the latency L.
+func (d *Dgraph) RunStream(ctx context.Context, req *Req, fn func(*protos.Response) error) error {
+	stream, err := d.dc[rand.Intn(len(d.dc))].RunStream(ctx, &req.gr)
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// Export streams an export of the data and schema of the database to fn, a chunk at a time. The
+// offset of every chunk handled by fn is kept in req, so that calling Export again with the same req
+// after an error resumes the export after the last chunk handled.
+func (d *Dgraph) Export(ctx context.Context, req *protos.ExportRequest,
+	fn func(*protos.ExportChunk) error) error {
+	stream, err := d.dc[rand.Intn(len(d.dc))].Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		setOffset(req, chunk.Offset)
+	}
+}
+
+// Subscribe streams the changes committed to the predicates of req to fn, an entry of the changelog
+// of a group at a time, until ctx is done or fn fails. Only the changes of the groups served by the
+// server the request is sent to are streamed. To resume after an error, subscribe again with Since
+// set to the earliest of the CommitTs of the last events handled for each group, and skip the
+// events sent again by their group and index.
+func (d *Dgraph) Subscribe(ctx context.Context, req *protos.SubscribeRequest,
+	fn func(*protos.ChangeEvent) error) error {
+	stream, err := d.dc[rand.Intn(len(d.dc))].Subscribe(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+}
+
+func setOffset(req *protos.ExportRequest, off *protos.ExportOffset) {
+	for i, o := range req.Offsets {
+		if o.GroupId == off.GroupId {
+			req.Offsets[i] = off
+			return
+		}
+	}
+	req.Offsets = append(req.Offsets, off)
+}
+
 // Counter returns the current state of the BatchMutation.
 func (d *Dgraph) Counter() Counter {
 	return Counter{
@@ -699,6 +811,16 @@ You can get the latest version from https://docs.dgraph.io
 	}
 }
 
+// NegotiateAPIVersion returns the latest API version supported by both the server and the
+// client, to set in requests with Req.SetAPIVersion.
+func (d *Dgraph) NegotiateAPIVersion(ctx context.Context) (uint32, error) {
+	v, err := d.dc[rand.Intn(len(d.dc))].CheckVersion(ctx, &protos.Check{ApiVersion: APIVersion})
+	if err != nil {
+		return 0, err
+	}
+	return v.ApiVersion, nil
+}
+
 // NodeUid creates a Node from the given uint64.
 func (d *Dgraph) NodeUid(uid uint64) Node {
 	return Node{uid: uid}
@@ -729,7 +851,9 @@ func (d *Dgraph) NodeBlank(varname string) (Node, error) {
 
 // NodeXid creates or returns a Node given a string name for an XID node. An XID node identifies a
 // node with an edge xid, as in
-// 	node --- xid ---> XID string
+//
+//	node --- xid ---> XID string
+//
 // See https://docs.dgraph.io/query-language/#external-ids If the XID has already been allocated
 // in this client session the allocated UID is returned, otherwise a new UID is allocated
 // for xid and returned.
@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// httpIdentity returns the identity to run a /query request with, from the bearer token or the
+// basic authentication of r.
+func httpIdentity(r *http.Request) (dgraph.Identity, error) {
+	user, password, _ := r.BasicAuth()
+	return dgraph.Authorize(r.Header.Get("Authorization"), user, password)
+}
+
+// authChallenge returns the WWW-Authenticate header of requests refused by httpIdentity.
+func authChallenge() string {
+	if dgraph.JWTEnabled() {
+		return `Bearer realm="dgraph"`
+	}
+	return `Basic realm="dgraph"`
+}
+
+// aclAdmin checks that r is an admin request on a server with access control lists, and writes
+// the error to w otherwise.
+func aclAdmin(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return false
+	}
+	if !dgraph.ACLEnabled() {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "Access control lists (--acl) aren't enabled")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	w.Write(js)
+}
+
+// aclUsersHandler lists the users with their groups on GET, adds the user of the name parameter
+// on PUT, with the password and groups of the body, or changes them, and removes it on DELETE.
+// The password of a user is kept if the body has none.
+func aclUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if !aclAdmin(w, r) {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, dgraph.ACLUsers())
+	case http.MethodPut:
+		defer r.Body.Close()
+		var user struct {
+			Password string   `json:"password"`
+			Groups   []string `json:"groups"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		if err := dgraph.SetUser(name, user.Password, user.Groups); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Set user "+name)
+	case http.MethodDelete:
+		if err := dgraph.DeleteUser(name); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Deleted user "+name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
+
+// aclGroupsHandler lists the groups with the permissions they grant on GET, adds the group of
+// the name parameter on PUT, with the permissions on predicates of the body, or replaces them,
+// and removes it on DELETE.
+func aclGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	if !aclAdmin(w, r) {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, dgraph.ACLGroups())
+	case http.MethodPut:
+		defer r.Body.Close()
+		var rules map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		if err := dgraph.SetGroup(name, rules); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Set group "+name)
+	case http.MethodDelete:
+		if err := dgraph.DeleteGroup(name); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Deleted group "+name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
+
+// aclFiltersHandler lists the filters of the groups on GET, sets the filter of the group of the
+// group parameter to the body on PUT, and removes it on DELETE.
+func aclFiltersHandler(w http.ResponseWriter, r *http.Request) {
+	if !aclAdmin(w, r) {
+		return
+	}
+	group := r.URL.Query().Get("group")
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, dgraph.ACLFilters())
+	case http.MethodPut:
+		defer r.Body.Close()
+		filter, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		if err := dgraph.SetGroupFilter(group, strings.TrimSpace(string(filter))); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Set filter of group "+group)
+	case http.MethodDelete:
+		if err := dgraph.DeleteGroupFilter(group); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Deleted filter of group "+group)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
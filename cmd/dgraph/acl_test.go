@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+)
+
+func runAsUser(t *testing.T, user, password, q string) (int, string) {
+	req, err := http.NewRequest("POST", "/query", bytes.NewBufferString(q))
+	require.NoError(t, err)
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	rr := httptest.NewRecorder()
+	dgraph.WrapHTTP("/query", http.HandlerFunc(queryHandler)).ServeHTTP(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func TestACL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(file string) { dgraph.Config.ACL = file }(dgraph.Config.ACL)
+	dgraph.Config.ACL = filepath.Join(dir, "acl.json")
+
+	require.Error(t, dgraph.SetGroup("dev", map[string]string{"acl.name": "rx"}))
+	require.NoError(t, dgraph.SetGroup("admin", map[string]string{"*": "rwm"}))
+	require.NoError(t, dgraph.SetGroup("dev", map[string]string{"*": "r", "acl.name": "rw",
+		"acl.salary": ""}))
+	require.NoError(t, dgraph.SetUser("root", "rootpw", []string{"admin"}))
+	require.NoError(t, dgraph.SetUser("alice", "alicepw", []string{"dev"}))
+	require.NoError(t, dgraph.LoadACL())
+	require.Equal(t, map[string][]string{"root": {"admin"}, "alice": {"dev"}}, dgraph.ACLUsers())
+
+	code, res := runAsUser(t, "root", "rootpw", `mutation {
+		schema { acl.name: string @index(exact) . }
+		set {
+			<0x5001> <acl.name> "Alice" .
+			<0x5001> <acl.salary> "100" .
+		}
+	}`)
+	require.Equal(t, http.StatusOK, code, res)
+
+	code, _ = runAsUser(t, "", "", `{ me(func: uid(0x5001)) { acl.name } }`)
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = runAsUser(t, "alice", "rootpw", `{ me(func: uid(0x5001)) { acl.name } }`)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	_, res = runAsUser(t, "alice", "alicepw", `{ me(func: eq(acl.name, "Alice")) { acl.name } }`)
+	require.JSONEq(t, `{"data": {"me": [{"acl.name": "Alice"}]}}`, res)
+	code, res = runAsUser(t, "alice", "alicepw", `{ me(func: uid(0x5001)) { acl.salary } }`)
+	require.Equal(t, http.StatusForbidden, code, res)
+	code, _ = runAsUser(t, "alice", "alicepw",
+		`{ me(func: uid(0x5001)) @filter(eq(acl.salary, "100")) { acl.name } }`)
+	require.Equal(t, http.StatusForbidden, code)
+	// expand() leaves out the predicates which can't be read.
+	_, res = runAsUser(t, "alice", "alicepw", `{ me(func: uid(0x5001)) { expand(_all_) } }`)
+	require.Contains(t, res, `"acl.name":"Alice"`)
+	require.NotContains(t, res, "acl.salary")
+
+	code, _ = runAsUser(t, "alice", "alicepw",
+		`mutation { set { <0x5001> <acl.name> "Alicia" . } }`)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = runAsUser(t, "alice", "alicepw",
+		`mutation { set { <0x5001> <acl.salary> "200" . } }`)
+	require.Equal(t, http.StatusForbidden, code)
+	code, _ = runAsUser(t, "alice", "alicepw",
+		`mutation { schema { acl.name: string @index(term) . } }`)
+	require.Equal(t, http.StatusForbidden, code)
+
+	// S * * only deletes the predicates which can be written.
+	_, res = runAsUser(t, "alice", "alicepw", `mutation { delete { <0x5001> * * . } }`)
+	require.Contains(t, res, "Success")
+	_, res = runAsUser(t, "root", "rootpw", `{ me(func: uid(0x5001)) { acl.name acl.salary } }`)
+	require.JSONEq(t, `{"data": {"me": [{"acl.salary": "100"}]}}`, res)
+
+	// Changes to groups apply to the next requests.
+	require.NoError(t, dgraph.DeleteGroup("dev"))
+	code, _ = runAsUser(t, "alice", "alicepw", `{ me(func: uid(0x5001)) { acl.name } }`)
+	require.Equal(t, http.StatusForbidden, code)
+	require.NoError(t, dgraph.DeleteUser("alice"))
+	code, _ = runAsUser(t, "alice", "alicepw", `{ me(func: uid(0x5001)) { acl.name } }`)
+	require.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestACLNodeFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(file string) { dgraph.Config.ACL = file }(dgraph.Config.ACL)
+	dgraph.Config.ACL = filepath.Join(dir, "acl.json")
+
+	require.NoError(t, dgraph.SetGroup("admin", map[string]string{"*": "rwm"}))
+	require.NoError(t, dgraph.SetGroup("acme", map[string]string{"acl.title": "r"}))
+	require.NoError(t, dgraph.SetGroup("owners", map[string]string{"acl.title": "r"}))
+	require.Error(t, dgraph.SetGroupFilter("acme", "uid_in(acl.org"))
+	require.Error(t, dgraph.SetGroupFilter("nobody", "has(acl.title)"))
+	require.NoError(t, dgraph.SetGroupFilter("acme", "uid_in(acl.org, 0x5100)"))
+	require.NoError(t, dgraph.SetGroupFilter("owners", `eq(acl.owner, "$user")`))
+	require.NoError(t, dgraph.SetUser("root", "rootpw", []string{"admin"}))
+	require.NoError(t, dgraph.SetUser("bob", "bobpw", []string{"acme"}))
+	require.NoError(t, dgraph.SetUser("carol", "carolpw", []string{"acme", "owners"}))
+
+	code, res := runAsUser(t, "root", "rootpw", `mutation {
+		schema {
+			acl.org: uid .
+			acl.owner: string @index(exact) .
+			acl.title: string @index(exact) .
+		}
+		set {
+			<0x5101> <acl.title> "Plan" .
+			<0x5101> <acl.org> <0x5100> .
+			<0x5101> <acl.peer> <0x5102> .
+			<0x5102> <acl.title> "Secret" .
+			<0x5102> <acl.org> <0x5200> .
+			<0x5103> <acl.title> "Notes" .
+			<0x5103> <acl.owner> "carol" .
+		}
+	}`)
+	require.Equal(t, http.StatusOK, code, res)
+
+	q := `{ me(func: uid(0x5101, 0x5102, 0x5103), orderasc: acl.title) { acl.title } }`
+	_, res = runAsUser(t, "bob", "bobpw", q)
+	require.JSONEq(t, `{"data": {"me": [{"acl.title": "Plan"}]}}`, res)
+	// Users see the nodes of any of their groups, and the predicates of filters needn't be
+	// readable by them.
+	_, res = runAsUser(t, "carol", "carolpw", q)
+	require.JSONEq(t, `{"data": {"me": [{"acl.title": "Notes"}, {"acl.title": "Plan"}]}}`, res)
+	_, res = runAsUser(t, "root", "rootpw", q)
+	require.JSONEq(t, `{"data": {"me": [{"acl.title": "Notes"}, {"acl.title": "Plan"},
+		{"acl.title": "Secret"}]}}`, res)
+
+	// Edges only lead to the nodes the user sees.
+	_, res = runAsUser(t, "root", "rootpw", `{ me(func: uid(0x5101)) { acl.peer { acl.title } } }`)
+	require.JSONEq(t, `{"data": {"me": [{"acl.peer": [{"acl.title": "Secret"}]}]}}`, res)
+	require.NoError(t, dgraph.SetGroup("acme", map[string]string{"acl.title": "r",
+		"acl.peer": "r"}))
+	code, res = runAsUser(t, "bob", "bobpw", `{ me(func: uid(0x5101)) { acl.peer { acl.title } } }`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.NotContains(t, res, "Secret")
+
+	require.NoError(t, dgraph.DeleteGroupFilter("acme"))
+	_, res = runAsUser(t, "bob", "bobpw", q)
+	require.Contains(t, res, "Secret")
+	require.Equal(t, map[string]string{"owners": `eq(acl.owner, "$user")`}, dgraph.ACLFilters())
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// adminServer serves the Admin gRPC service, for the operations also served under /admin/ on the
+// http port. It runs on its own port, so that it can be bound to another interface than the
+// Dgraph service, and requires the --admin_token of the server, or an admin token, in the
+// auth-token metadata, or the credentials of an admin in the authorization metadata.
+type adminServer struct{}
+
+func adminPort() int {
+	if baseAdminPort == 0 {
+		return 0
+	}
+	return x.Config.PortOffset + baseAdminPort
+}
+
+func isLoopback(addr string) bool {
+	if addr == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
+
+// adminScopes are the scopes of admin tokens which allow the methods of the Admin service, other
+// than those of the admin scope.
+var adminScopes = map[string]string{
+	"/protos.Admin/Alter":  dgraph.ScopeSchema,
+	"/protos.Admin/Export": dgraph.ScopeExport,
+}
+
+// authorizeAdmin checks the auth-token of admin requests, if the server has an --admin_token or
+// --admin_tokens, or their authorization, if it has admin logins. The --admin_token is allowed all
+// methods, and admin tokens and the roles of admins those of their scope.
+func authorizeAdmin(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if adminToken != "" || dgraph.AdminTokensEnabled() || dgraph.AdminLoginEnabled() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokens, auth := md["auth-token"], md["authorization"]
+		scope, ok := adminScopes[info.FullMethod]
+		if !ok {
+			scope = dgraph.ScopeAdmin
+		}
+		var user string
+		var err error
+		switch {
+		case len(tokens) == 0 && len(auth) == 1 && dgraph.AdminLoginEnabled():
+			user, err = dgraph.AuthorizeAdminLogin(auth[0], scope)
+		case len(tokens) != 1:
+			err = dgraph.ErrAdminToken
+		case adminToken != "" &&
+			subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(adminToken)) == 1:
+		default:
+			_, err = dgraph.AuthorizeAdminToken(tokens[0], scope)
+		}
+		if err != nil {
+			code := codes.Unauthenticated
+			if err == dgraph.ErrAdminTokenScope {
+				code = codes.PermissionDenied
+			}
+			err = grpc.Errorf(code, "Invalid credentials for %s: %v", info.FullMethod, err)
+			dgraph.AuditDenied(info.FullMethod, dgraph.GRPCRemote(ctx), user, err)
+			return nil, err
+		}
+	}
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	dgraph.AuditAdminRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func serveAdmin(l net.Listener) {
+	defer func() { dgraph.State.FinishCh <- struct{}{} }()
+	s := grpc.NewServer(grpc.UnaryInterceptor(authorizeAdmin))
+	protos.RegisterAdminServer(s, &adminServer{})
+	err := s.Serve(l)
+	log.Printf("Admin gRpc server stopped : %s", err.Error())
+	s.GracefulStop()
+}
+
+func (s *adminServer) Alter(ctx context.Context, req *protos.AlterRequest) (*protos.Payload,
+	error) {
+	updates, typs, err := schema.ParseWithTypes(req.Schema)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if len(updates) == 0 && len(typs) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Empty schema")
+	}
+	m := &protos.Mutations{Schema: updates, Types: typs}
+	if err := query.ApplyMutations(ctx, m); err != nil {
+		return nil, err
+	}
+	return &protos.Payload{}, nil
+}
+
+func (s *adminServer) Export(ctx context.Context, req *protos.ExportPayload) (*protos.Payload,
+	error) {
+	var err error
+	if req.Backup {
+		err = worker.BackupOverNetwork(ctx, req.FullBackup)
+	} else {
+		err = worker.ExportOverNetwork(ctx, &protos.ExportPayload{
+			Format:  req.Format,
+			Include: req.Include,
+			Exclude: req.Exclude,
+			Uids:    req.Uids,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &protos.Payload{}, nil
+}
+
+func (s *adminServer) Shutdown(ctx context.Context, _ *protos.Payload) (*protos.Payload, error) {
+	shutdownServer()
+	return &protos.Payload{}, nil
+}
+
+func currentConfig() *protos.ServerConfig {
+	posting.Config.Mu.Lock()
+	memoryMB := posting.Config.AllottedMemory
+	posting.Config.Mu.Unlock()
+	return &protos.ServerConfig{
+		MemoryMb:           memoryMB,
+		CompactionPriority: posting.FormatCompactionPriorities(posting.CompactionPriorities()),
+	}
+}
+
+func (s *adminServer) GetConfig(ctx context.Context, _ *protos.Payload) (*protos.ServerConfig,
+	error) {
+	return currentConfig(), nil
+}
+
+// SetConfig changes the memory_mb if it's set, and replaces the compaction priorities if they're
+// set. They can all be reset with a single predicate at normal, as in "name:normal".
+func (s *adminServer) SetConfig(ctx context.Context, req *protos.ServerConfig) (
+	*protos.ServerConfig, error) {
+	if req.MemoryMb != 0 && req.MemoryMb < dgraph.MinAllottedMemory {
+		return nil, grpc.Errorf(codes.InvalidArgument, "memory_mb must be at least %.0f",
+			dgraph.MinAllottedMemory)
+	}
+	var prios map[string]posting.CompactionPriority
+	if req.CompactionPriority != "" {
+		var err error
+		if prios, err = posting.ParseCompactionPriorities(req.CompactionPriority); err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+		}
+	}
+
+	if req.MemoryMb != 0 {
+		posting.Config.Mu.Lock()
+		posting.Config.AllottedMemory = req.MemoryMb
+		posting.Config.Mu.Unlock()
+		worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId, "memory_mb set to %v",
+			req.MemoryMb)
+	}
+	if prios != nil {
+		posting.SetCompactionPriorities(prios)
+		worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId,
+			"compaction_priority set to %s", req.CompactionPriority)
+	}
+	return currentConfig(), nil
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+func TestAdminAuth(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/protos.Admin/Shutdown"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &protos.Payload{}, nil
+	}
+	_, err := authorizeAdmin(context.Background(), &protos.Payload{}, info, handler)
+	require.NoError(t, err)
+
+	adminToken = "secret"
+	defer func() { adminToken = "" }()
+	_, err = authorizeAdmin(context.Background(), &protos.Payload{}, info, handler)
+	require.Error(t, err)
+
+	// Admin tokens are allowed the methods of their scope.
+	dir, err := ioutil.TempDir("", "admintokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c dgraph.Options) { dgraph.Config = c }(dgraph.Config)
+	dgraph.Config.AdminTokens = filepath.Join(dir, "tokens.json")
+	token, _, err := dgraph.CreateAdminToken(dgraph.ScopeExport, time.Hour)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("auth-token", token))
+	_, err = authorizeAdmin(ctx, &protos.Payload{}, info, handler)
+	require.Error(t, err)
+	exportInfo := &grpc.UnaryServerInfo{FullMethod: "/protos.Admin/Export"}
+	_, err = authorizeAdmin(ctx, &protos.ExportPayload{}, exportInfo, handler)
+	require.NoError(t, err)
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("auth-token", "secret"))
+	_, err = authorizeAdmin(ctx, &protos.Payload{}, info, handler)
+	require.NoError(t, err)
+
+	require.True(t, isLoopback("localhost"))
+	require.True(t, isLoopback("127.0.0.1"))
+	require.False(t, isLoopback("0.0.0.0"))
+}
+
+func TestAdminServer(t *testing.T) {
+	s := &adminServer{}
+	ctx := context.Background()
+	_, err := s.Alter(ctx, &protos.AlterRequest{Schema: "admin.name: string @index("})
+	require.Error(t, err)
+	_, err = s.Alter(ctx, &protos.AlterRequest{Schema: "admin.name: string @index(exact) ."})
+	require.NoError(t, err)
+	typ, err := schema.State().TypeOf("admin.name")
+	require.NoError(t, err)
+	require.Equal(t, types.StringID, typ)
+	require.True(t, schema.State().IsIndexed("admin.name"))
+
+	defer posting.SetCompactionPriorities(posting.CompactionPriorities())
+	old, err := s.GetConfig(ctx, &protos.Payload{})
+	require.NoError(t, err)
+	_, err = s.SetConfig(ctx, &protos.ServerConfig{MemoryMb: 1})
+	require.Error(t, err)
+	conf, err := s.SetConfig(ctx, &protos.ServerConfig{CompactionPriority: "admin.name:high"})
+	require.NoError(t, err)
+	require.Equal(t, old.MemoryMb, conf.MemoryMb)
+	require.Equal(t, "admin.name:high", conf.CompactionPriority)
+}
+
+// newAdminTokens creates an admin token of each scope, and returns them by scope, with a func
+// to remove them.
+func newAdminTokens(t *testing.T) (map[string]string, func()) {
+	dir, err := ioutil.TempDir("", "admintokens")
+	require.NoError(t, err)
+	conf := dgraph.Config
+	dgraph.Config.AdminTokens = filepath.Join(dir, "tokens.json")
+	tokens := make(map[string]string)
+	for _, scope := range []string{dgraph.ScopeExport, dgraph.ScopeSchema, dgraph.ScopeAdmin} {
+		token, _, err := dgraph.CreateAdminToken(scope, time.Hour)
+		require.NoError(t, err)
+		tokens[scope] = token
+	}
+	return tokens, func() {
+		dgraph.Config = conf
+		os.RemoveAll(dir)
+	}
+}
+
+// adminStatus returns the status h replies to a request of method to path with, from an address
+// other than a loopback one, with the admin token given, if any.
+func adminStatus(h http.HandlerFunc, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "10.0.0.1:8080"
+	if token != "" {
+		req.Header.Set("X-Admin-Token", token)
+	}
+	rr := httptest.NewRecorder()
+	h(rr, req)
+	return rr.Code
+}
+
+func TestAdminScopes(t *testing.T) {
+	tokens, cleanup := newAdminTokens(t)
+	defer cleanup()
+
+	tests := []struct {
+		method string
+		path   string
+		h      http.HandlerFunc
+		scope  string
+	}{
+		{"GET", "/admin/acl/users", aclUsersHandler, dgraph.ScopeAdmin},
+		{"GET", "/admin/acl/groups", aclGroupsHandler, dgraph.ScopeAdmin},
+		{"GET", "/admin/acl/filters", aclFiltersHandler, dgraph.ScopeAdmin},
+		{"GET", "/admin/namespaces", namespacesHandler, dgraph.ScopeAdmin},
+		{"GET", "/admin/queries", persistedQueriesHandler, dgraph.ScopeSchema},
+		{"GET", "/admin/webhooks", webhooksHandler, dgraph.ScopeAdmin},
+		{"PUT", "/admin/config/compaction_priority", compactionPriorityHandler,
+			dgraph.ScopeAdmin},
+		{"PUT", "/admin/config/retention", retentionHandler, dgraph.ScopeAdmin},
+		{"PUT", "/admin/config/log_levels", logLevelsHandler, dgraph.ScopeAdmin},
+	}
+	for _, tc := range tests {
+		require.Equal(t, http.StatusUnauthorized, adminStatus(tc.h, tc.method, tc.path, ""),
+			tc.path)
+		require.Equal(t, http.StatusUnauthorized,
+			adminStatus(tc.h, tc.method, tc.path, "bad.token"), tc.path)
+		for scope, token := range tokens {
+			code := adminStatus(tc.h, tc.method, tc.path, token)
+			if scope == tc.scope || scope == dgraph.ScopeAdmin {
+				require.NotContains(t, []int{http.StatusUnauthorized, http.StatusForbidden}, code,
+					"%s with scope %s", tc.path, scope)
+			} else {
+				require.Equal(t, http.StatusForbidden, code, "%s with scope %s", tc.path, scope)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// adminAllowed checks that r comes from a loopback address, or has an admin token allowed
+// operations of scope in its X-Admin-Token header, or the credentials of an admin with a role
+// allowed them in its Authorization header, and writes the error to w otherwise.
+func adminAllowed(w http.ResponseWriter, r *http.Request, scope string) bool {
+	if token := r.Header.Get("X-Admin-Token"); token != "" {
+		if _, err := dgraph.AuthorizeAdminToken(token, scope); err != nil {
+			dgraph.AuditDenied(r.URL.Path, r.RemoteAddr, "", err)
+			if err == dgraph.ErrAdminTokenScope {
+				w.WriteHeader(http.StatusForbidden)
+			} else {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			x.SetStatus(w, x.ErrorUnauthorized, err.Error())
+			return false
+		}
+		return true
+	}
+	if auth := r.Header.Get("Authorization"); auth != "" && dgraph.AdminLoginEnabled() {
+		if user, err := dgraph.AuthorizeAdminLogin(auth, scope); err != nil {
+			dgraph.AuditDenied(r.URL.Path, r.RemoteAddr, user, err)
+			if err == dgraph.ErrAdminTokenScope {
+				w.WriteHeader(http.StatusForbidden)
+			} else {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			x.SetStatus(w, x.ErrorUnauthorized, err.Error())
+			return false
+		}
+		return true
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !net.ParseIP(ip).IsLoopback() {
+		if dgraph.AdminLDAPEnabled() {
+			// Have browsers ask for the user and password, to log in to the UI.
+			w.Header().Set("WWW-Authenticate", `Basic realm="Dgraph admin"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		x.SetStatus(w, x.ErrorUnauthorized, "Request from IP: "+ip)
+		return false
+	}
+	return true
+}
+
+type createdToken struct {
+	dgraph.AdminToken
+	Token string `json:"token"`
+}
+
+// adminTokensHandler lists the admin tokens on GET. On POST, it creates a token of the scope
+// parameter, valid for the ttl parameter, or rotates the token of the rotate parameter, and
+// returns it with its secret. It revokes the token of the id parameter on DELETE.
+func adminTokensHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	if !dgraph.AdminTokensEnabled() {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "Admin tokens (--admin_tokens) aren't enabled")
+		return
+	}
+	params := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, dgraph.AdminTokens())
+	case http.MethodPost:
+		var token string
+		var t dgraph.AdminToken
+		var err error
+		if id := params.Get("rotate"); id != "" {
+			token, t, err = dgraph.RotateAdminToken(id)
+		} else {
+			ttl := dgraph.DefaultAdminTokenTTL
+			if s := params.Get("ttl"); s != "" {
+				if ttl, err = time.ParseDuration(s); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+					return
+				}
+			}
+			token, t, err = dgraph.CreateAdminToken(params.Get("scope"), ttl)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		writeJSON(w, createdToken{AdminToken: t, Token: token})
+	case http.MethodDelete:
+		id := params.Get("id")
+		if err := dgraph.RevokeAdminToken(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Revoked admin token "+id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Changes are streamed from /changes as server-sent events, one per changelog entry:
+//   id: 1:1042,2:977
+//   data: {"group":1,"index":1042,"time":"...","changes":["+ <_:uid1> <name> \"Alice\" ."]}
+// The id is a cursor, with the index of the last entry read from each group. Clients resume
+// after it by reconnecting with it in the Last-Event-ID header, or the cursor parameter.
+// /invalidations streams the same entries, with the keys of the data they make stale instead:
+//   data: {"group":1,"index":1042,"keys":[{"predicate":"name","uid":"0x1","commit_ts":"..."}]}
+
+const changesHeartbeat = 15 * time.Second
+
+type changeEvent struct {
+	Group   uint32    `json:"group"`
+	Index   uint64    `json:"index"`
+	Time    time.Time `json:"time"`
+	Changes []string  `json:"changes"`
+}
+
+type invalidationEvent struct {
+	Group uint32                `json:"group"`
+	Index uint64                `json:"index"`
+	Keys  []worker.Invalidation `json:"keys"`
+}
+
+// parseCursor parses a cursor of the form <group>:<index>,...
+func parseCursor(s string) (map[uint32]uint64, error) {
+	cursor := make(map[uint32]uint64)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, x.Errorf("Invalid cursor: %q", s)
+		}
+		gid, err := strconv.ParseUint(kv[0], 10, 32)
+		if err != nil {
+			return nil, x.Errorf("Invalid group in cursor: %q", kv[0])
+		}
+		idx, err := strconv.ParseUint(kv[1], 10, 64)
+		if err != nil {
+			return nil, x.Errorf("Invalid index in cursor: %q", kv[1])
+		}
+		cursor[uint32(gid)] = idx
+	}
+	return cursor, nil
+}
+
+// formatCursor returns the cursor after the entries read. Groups in the cursor the client
+// resumed from, which aren't served here, are kept as they were.
+func formatCursor(cursor map[uint32]uint64, readers map[uint32]*worker.ChangeReader) string {
+	last := make(map[uint32]uint64)
+	for gid, idx := range cursor {
+		last[gid] = idx
+	}
+	for gid, cr := range readers {
+		last[gid] = cr.Last()
+	}
+	var gids []int
+	for gid := range last {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+	var parts []string
+	for _, gid := range gids {
+		parts = append(parts, fmt.Sprintf("%d:%d", gid, last[uint32(gid)]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// changesHandler streams the changes committed to the groups served here, to the predicates
+// matching the pred patterns, since the time given or from the cursor on.
+func changesHandler(w http.ResponseWriter, r *http.Request) {
+	streamChanges(w, r, func(c *worker.Change) (interface{}, error) {
+		return changeEvent{Group: c.Group, Index: c.Index, Time: c.Time, Changes: c.Lines}, nil
+	})
+}
+
+// invalidationsHandler streams the keys of the data made stale by the changes changesHandler
+// would stream, for caches to evict.
+func invalidationsHandler(w http.ResponseWriter, r *http.Request) {
+	streamChanges(w, r, func(c *worker.Change) (interface{}, error) {
+		keys, err := worker.Invalidations(c)
+		if err != nil {
+			return nil, err
+		}
+		return invalidationEvent{Group: c.Group, Index: c.Index, Keys: keys}, nil
+	})
+}
+
+// streamChanges streams the changes asked for by r as server-sent events, with the data event
+// returns for each.
+func streamChanges(w http.ResponseWriter, r *http.Request,
+	event func(c *worker.Change) (interface{}, error)) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+	if !worker.Config.Changelog {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, "The change feed needs the changelog (--changelog).")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		x.SetStatus(w, x.Error, "Streaming isn't supported")
+		return
+	}
+
+	params := r.URL.Query()
+	patterns := splitPatterns(params.Get("pred"))
+	if err := worker.ValidatePatterns(patterns); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	// Without a time, changes are streamed from now on.
+	since := time.Now()
+	if s := params.Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Invalid since, expected an RFC3339 time.")
+			return
+		}
+		since = t
+	}
+	c := params.Get("cursor")
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		c = id
+	}
+	cursor, err := parseCursor(c)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+
+	changed, stop := worker.WatchChanges(patterns)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	readers := make(map[uint32]*worker.ChangeReader)
+	var writeErr error
+	send := func(c *worker.Change) error {
+		e, err := event(c)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(e)
+		x.Check(err)
+		_, writeErr = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", formatCursor(cursor, readers), b)
+		return writeErr
+	}
+	heartbeat := time.NewTicker(changesHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		for _, gid := range worker.ChangeGroups() {
+			cr, ok := readers[gid]
+			if !ok {
+				// Groups in the cursor continue after it, and others start at since.
+				if after, ok := cursor[gid]; ok {
+					cr, err = worker.NewChangeReader(gid, after, time.Time{}, patterns)
+				} else {
+					cr, err = worker.NewChangeReader(gid, 0, since, patterns)
+				}
+				x.Check(err)
+				readers[gid] = cr
+			}
+			if err := cr.Read(send); err != nil {
+				if writeErr != nil {
+					// The client went away.
+					return
+				}
+				x.Printf("Error in change feed of group %d: %v\n", gid, err)
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/worker"
+)
+
+func TestChangeCursor(t *testing.T) {
+	cursor, err := parseCursor("1:1042, 3:7")
+	require.NoError(t, err)
+	require.Equal(t, map[uint32]uint64{1: 1042, 3: 7}, cursor)
+
+	cr, err := worker.NewChangeReader(2, 15, time.Time{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "1:1042,2:15,3:7", formatCursor(cursor, map[uint32]*worker.ChangeReader{2: cr}))
+
+	empty, err := parseCursor("")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+	for _, s := range []string{"1", "a:1", "1:b"} {
+		_, err := parseCursor(s)
+		require.Error(t, err, s)
+	}
+}
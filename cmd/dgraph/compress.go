@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// acceptedEncoding returns the encoding to compress a response with, given the Accept-Encoding
+// header of the request: gzip or deflate, whichever the client prefers, or "" for none.
+func acceptedEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		enc := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if enc == "*" {
+			enc = "gzip"
+		}
+		// gzip wins ties, as the most widely supported.
+		if (enc == "gzip" || enc == "deflate") && q > 0 &&
+			(q > bestQ || (q == bestQ && enc == "gzip")) {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+// decodeBody replaces the body of r with its decompressed content, following Content-Encoding.
+func decodeBody(r *http.Request) error {
+	var zr io.ReadCloser
+	var err error
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+		return nil
+	case "gzip":
+		zr, err = gzip.NewReader(r.Body)
+	case "deflate":
+		zr, err = zlib.NewReader(r.Body)
+	default:
+		return x.Errorf("Unsupported Content-Encoding: %q", enc)
+	}
+	if err != nil {
+		return x.Wrapf(err, "While decompressing the request body")
+	}
+	body := r.Body
+	r.Body = readCloser{Reader: zr, close: func() error {
+		zr.Close()
+		return body.Close()
+	}}
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	w io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	cw.Header().Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	cw.Header().Del("Content-Length")
+	return cw.w.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the connection of cw.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressed wraps h, to decompress request bodies sent with Content-Encoding gzip or deflate,
+// and compress responses for clients which accept it.
+func compressed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := decodeBody(r); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		enc := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		var zw io.WriteCloser
+		switch enc {
+		case "gzip":
+			zw = gzip.NewWriter(w)
+		case "deflate":
+			zw = zlib.NewWriter(w)
+		default:
+			h(w, r)
+			return
+		}
+		w.Header().Set("Content-Encoding", enc)
+		defer zw.Close()
+		h(&compressWriter{ResponseWriter: w, w: zw}, r)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	require.Equal(t, "", acceptedEncoding(""))
+	require.Equal(t, "gzip", acceptedEncoding("gzip, deflate, br"))
+	require.Equal(t, "deflate", acceptedEncoding("deflate"))
+	require.Equal(t, "deflate", acceptedEncoding("gzip;q=0.5, deflate"))
+	require.Equal(t, "", acceptedEncoding("gzip;q=0, br"))
+	require.Equal(t, "gzip", acceptedEncoding("*"))
+}
+
+func TestCompressed(t *testing.T) {
+	echo := compressed(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Write(b)
+	})
+	body := bytes.Repeat([]byte(`{"name": "Alice"}`), 100)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(body)
+	zw.Close()
+	req, err := http.NewRequest("POST", "/query", &gz)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Accept-Encoding", "deflate")
+	rr := httptest.NewRecorder()
+	echo(rr, req)
+	require.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(rr.Body)
+	require.NoError(t, err)
+	out, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, body, out)
+
+	req, err = http.NewRequest("POST", "/query", bytes.NewReader(body))
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	echo(rr, req)
+	require.Equal(t, "", rr.Header().Get("Content-Encoding"))
+	require.Equal(t, body, rr.Body.Bytes())
+
+	req, err = http.NewRequest("POST", "/query", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "br")
+	rr = httptest.NewRecorder()
+	echo(rr, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+}
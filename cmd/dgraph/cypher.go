@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/cypher"
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// cypherHandler runs the Cypher query in the body of POST requests, translated to a query of
+// Dgraph, and returns its result as rows.
+func cypherHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !dgraph.Config.Cypher {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "Cypher queries (--cypher) aren't enabled")
+		return
+	}
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+
+	x.PendingQueries.Add(1)
+	x.NumQueries.Add(1)
+	defer x.PendingQueries.Add(-1)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	defer r.Body.Close()
+	q, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		x.SetStatus(w, x.ErrorInvalidRequest, "Error while reading query")
+		return
+	}
+	parsed, err := cypher.Parse(string(q))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	tr, err := parsed.Translate(cypher.Options{Label: dgraph.Config.CypherLabel})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	if dgraph.Config.DebugMode {
+		x.Printf("Cypher query translated to: %s\n", tr.Query)
+	}
+
+	ctx := context.WithValue(context.Background(), "debug", r.URL.Query().Get("debug"))
+	ctx = context.WithValue(ctx, "mutation_allowed", false)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	js, err := graphqlRunner{}.Query(ctx, tr.Query, tr.Vars)
+	if err == nil {
+		js, err = unwrap(js)
+	}
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	res, err := tr.Rows(js)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	out := struct {
+		Data *cypher.Result `json:"data"`
+	}{res}
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		x.Printf("Error while writing Cypher response: %v\n", err)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+)
+
+func runCypher(t *testing.T, q string) (int, string) {
+	req, err := http.NewRequest("POST", "/cypher", bytes.NewBufferString(q))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	cypherHandler(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func TestCypher(t *testing.T) {
+	code, _ := runCypher(t, `MATCH (a) RETURN a`)
+	require.Equal(t, http.StatusNotFound, code)
+
+	dgraph.Config.Cypher = true
+	dgraph.Config.CypherLabel = "cyphertest_label"
+	defer func() {
+		dgraph.Config.Cypher = false
+		dgraph.Config.CypherLabel = dgraph.DefaultConfig.CypherLabel
+	}()
+	require.NoError(t, runMutation(`mutation {
+		schema {
+			cyphertest_name: string @index(exact) .
+			cyphertest_label: string @index(exact) .
+			cyphertest_age: int @index(int) .
+		}
+		set {
+			<0x6001> <cyphertest_name> "Alice" .
+			<0x6001> <cyphertest_label> "Person" .
+			<0x6001> <cyphertest_KNOWS> <0x6002> .
+			<0x6002> <cyphertest_name> "Bob" .
+			<0x6002> <cyphertest_age> "25" .
+			<0x6002> <cyphertest_KNOWS> <0x6003> .
+			<0x6003> <cyphertest_name> "Carol" .
+			<0x6003> <cyphertest_age> "35" .
+		}
+	}`))
+	// Inequalities read the keys of indexes from the store, which are written asynchronously.
+	posting.CommitLists(10, group.BelongsTo("cyphertest_age"))
+	time.Sleep(100 * time.Millisecond)
+
+	code, res := runCypher(t, `MATCH (a:Person {cyphertest_name: "Alice"})
+		-[:cyphertest_KNOWS*1..2]->(b)
+		RETURN a.cyphertest_name AS name, b.cyphertest_name AS friend ORDER BY friend DESC`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.JSONEq(t, `{"data": {"columns": ["name", "friend"],
+		"rows": [["Alice", "Carol"], ["Alice", "Bob"]]}}`, res)
+
+	code, res = runCypher(t, `MATCH (a:Person)-[:cyphertest_KNOWS*1..2]->(b)
+		WHERE b.cyphertest_age > 30 RETURN b.cyphertest_name`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.JSONEq(t, `{"data": {"columns": ["b.cyphertest_name"], "rows": [["Carol"]]}}`, res)
+
+	code, _ = runCypher(t, `MATCH (a)-[:cyphertest_KNOWS]->(b) RETURN b`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runCypher(t, `CREATE (a:Person)`)
+	require.Equal(t, http.StatusBadRequest, code)
+}
@@ -68,6 +68,7 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"contains",
 		"count",
 		"delete",
+		"disjoint",
 		"eq",
 		"exact",
 		"expand",
@@ -81,10 +82,12 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"le",
 		"mutation",
 		"near",
+		"nearest",
 		"offset",
 		"or",
 		"orderasc",
 		"orderdesc",
+		"overlaps",
 		"recurse",
 		"regex",
 		"reverse",
@@ -94,6 +97,7 @@ func keywordHandler(w http.ResponseWriter, r *http.Request) {
 		"tokenizer",
 		"uid",
 		"within",
+		"withinbox",
 	}
 
 	for _, w := range predefined {
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Formats of /query responses, given in the format parameter or by the Accept header.
+const (
+	// formatJSON is the default, of the data of the response with its extensions.
+	formatJSON = "json"
+	// formatRows has the results of each query block flattened into columns and rows.
+	formatRows = "rows"
+	// formatRaw has the data of the response alone, without the object wrapping it.
+	formatRaw = "raw"
+	// formatProtobuf has a protos.Response, as the Run RPC returns.
+	formatProtobuf = "protobuf"
+)
+
+// formatTypes has the content type of each format.
+var formatTypes = map[string]string{
+	formatJSON:     "application/json",
+	formatRows:     "application/vnd.dgraph.rows+json",
+	formatRaw:      "application/vnd.dgraph.raw+json",
+	formatProtobuf: "application/x-protobuf",
+}
+
+// responseFormat returns the format asked for by r. The format parameter wins over the Accept
+// header, and media types of the header we don't know of fall back to JSON.
+func responseFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		if _, ok := formatTypes[f]; !ok {
+			return "", x.Errorf("Invalid response format: %q", f)
+		}
+		return f, nil
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt := strings.ToLower(strings.TrimSpace(strings.Split(part, ";")[0]))
+		if mt == "application/protobuf" {
+			return formatProtobuf, nil
+		}
+		for f, t := range formatTypes {
+			if mt == t {
+				return f, nil
+			}
+		}
+	}
+	return formatJSON, nil
+}
+
+// writeProtobuf writes res as a protos.Response.
+func writeProtobuf(w http.ResponseWriter, l *query.Latency, res query.ExecuteResult) {
+	nodes, err := query.ToProtocolBuf(l, res.Subgraphs)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	resp := &protos.Response{
+		N:            nodes,
+		AssignedUids: res.Allocations,
+		Schema:       res.SchemaNode,
+		L: &protos.Latency{
+			Parsing:    l.Parsing.String(),
+			Processing: l.Processing.String(),
+			Pb:         l.ProtocolBuffer.String(),
+		},
+	}
+	b, err := resp.Marshal()
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes[formatProtobuf])
+	w.Write(b)
+}
+
+// writeRows writes the results of res as a table per query block, with the uids assigned.
+func writeRows(w http.ResponseWriter, res query.ExecuteResult, ext *query.Extensions) {
+	tables, err := query.ToRows(res.Subgraphs)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	out := struct {
+		Extensions *query.Extensions       `json:"extensions,omitempty"`
+		Data       map[string]*query.Table `json:"data"`
+		Uids       map[string]string       `json:"uids,omitempty"`
+	}{ext, tables, query.ConvertUidsToHex(res.Allocations)}
+	js, err := json.Marshal(out)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes[formatRows])
+	w.Write(js)
+}
+
+// unwrap returns the data of a JSON response without the object wrapping it, for formatRaw.
+func unwrap(js []byte) ([]byte, error) {
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(js, &res); err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
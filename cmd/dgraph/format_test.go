@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func runWithFormat(t *testing.T, params, accept, q string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/query"+params, bytes.NewBufferString(q))
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rr := httptest.NewRecorder()
+	queryHandler(rr, req)
+	return rr
+}
+
+func TestResponseFormats(t *testing.T) {
+	m := `mutation { set {
+		<0x6001> <format.name> "Alice" .
+		<0x6001> <format.friend> <0x6002> .
+		<0x6001> <format.friend> <0x6003> .
+		<0x6002> <format.name> "Bob" .
+		<0x6003> <format.name> "Eve" .
+	} }`
+	rr := runWithFormat(t, "", "", m)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	q := `{ me(func: uid(0x6001)) { format.name format.friend { format.name } } }`
+	rr = runWithFormat(t, "?format=raw", "", q)
+	require.Equal(t, formatTypes[formatRaw], rr.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"me": [{"format.name": "Alice", "format.friend": [
+		{"format.name": "Bob"}, {"format.name": "Eve"}]}]}`, rr.Body.String())
+
+	rr = runWithFormat(t, "", "application/vnd.dgraph.rows+json", q)
+	require.Equal(t, formatTypes[formatRows], rr.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"data": {"me": {
+		"columns": ["format.name", "format.friend.format.name"],
+		"rows": [["Alice", "Bob"], ["Alice", "Eve"]]}}}`, rr.Body.String())
+
+	rr = runWithFormat(t, "", "text/html, application/x-protobuf;q=0.9", q)
+	require.Equal(t, formatTypes[formatProtobuf], rr.Header().Get("Content-Type"))
+	var resp protos.Response
+	require.NoError(t, resp.Unmarshal(rr.Body.Bytes()))
+	require.Len(t, resp.N, 1)
+	require.Equal(t, "me", resp.N[0].Children[0].Attribute)
+
+	rr = runWithFormat(t, "?format=xml", "", q)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/graphql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// graphqlRunner runs the queries and mutations of the GraphQL endpoint like the ones sent
+// to /query.
+type graphqlRunner struct{}
+
+func (graphqlRunner) Schema(ctx context.Context) ([]*protos.SchemaNode, error) {
+	return worker.GetSchemaOverNetwork(ctx, &protos.SchemaRequest{})
+}
+
+func (graphqlRunner) Query(ctx context.Context, q string, vars map[string]string) (
+	[]byte, error) {
+	parsed, err := dgraph.ParseQueryAndMutation(ctx, gql.Request{Str: q, Variables: vars})
+	if err != nil {
+		return nil, err
+	}
+	var l query.Latency
+	l.Start = time.Now()
+	res, err := (&query.QueryRequest{Latency: &l, GqlQuery: &parsed}).ProcessWithMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := query.ToJson(&l, res.Subgraphs, &buf, nil, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (graphqlRunner) Mutate(ctx context.Context, m *protos.Mutation) (map[string]uint64, error) {
+	parsed, err := dgraph.ParseQueryAndMutation(ctx, gql.Request{Mutation: m})
+	if err != nil {
+		return nil, err
+	}
+	var l query.Latency
+	l.Start = time.Now()
+	res, err := (&query.QueryRequest{Latency: &l, GqlQuery: &parsed}).ProcessWithMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Allocations, nil
+}
+
+// graphqlHandler serves GraphQL requests over GET, with the query in the URL, and over POST,
+// with either a JSON body or an application/graphql one.
+func graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+
+	x.PendingQueries.Add(1)
+	x.NumQueries.Add(1)
+	defer x.PendingQueries.Add(-1)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), "debug", r.URL.Query().Get("debug"))
+	var req graphql.Request
+	switch r.Method {
+	case "GET":
+		// Mutations change state, so they aren't allowed over GET.
+		ctx = context.WithValue(ctx, "mutation_allowed", false)
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			dec := json.NewDecoder(strings.NewReader(v))
+			dec.UseNumber()
+			if err := dec.Decode(&req.Variables); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				x.SetStatus(w, x.ErrorInvalidRequest, "Invalid variables: "+err.Error())
+				return
+			}
+		}
+	case "POST":
+		ctx = context.WithValue(ctx, "mutation_allowed", !dgraph.Config.Nomutations)
+		defer r.Body.Close()
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				x.SetStatus(w, x.ErrorInvalidRequest, "Error while reading query")
+				return
+			}
+			req.Query = string(b)
+			break
+		}
+		dec := json.NewDecoder(r.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Invalid request: "+err.Error())
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	resp := graphql.Execute(ctx, graphqlRunner{}, req)
+	if dgraph.Config.DebugMode && len(resp.Errors) > 0 {
+		x.Printf("GraphQL request failed: %v\n", resp.Errors[0])
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		x.Printf("Error while writing GraphQL response: %v\n", err)
+	}
+}
+
+// graphqlSchemaHandler returns the GraphQL schema generated from the Dgraph schema, in the
+// schema definition language.
+func graphqlSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	if r.Method != "GET" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	nodes, err := graphqlRunner{}.Schema(ctx)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(graphql.NewSchema(nodes).SDL()))
+}
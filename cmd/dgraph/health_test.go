@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+)
+
+type probesResult struct {
+	Ok     bool
+	Checks []dgraph.Probe
+}
+
+func TestReady(t *testing.T) {
+	rr := httptest.NewRecorder()
+	readyHandler(rr, httptest.NewRequest("GET", "/ready", nil))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var res probesResult
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.True(t, res.Ok)
+	require.Len(t, res.Checks, 5)
+
+	// Asking for more free space than the disk has fails the disk check alone.
+	minFree := dgraph.Config.MinFreeDiskMB
+	dgraph.Config.MinFreeDiskMB = 1 << 40
+	defer func() { dgraph.Config.MinFreeDiskMB = minFree }()
+	rr = httptest.NewRecorder()
+	readyHandler(rr, httptest.NewRequest("GET", "/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	for _, p := range res.Checks {
+		require.Equal(t, p.Name != "disk", p.Ok, p.Reason)
+	}
+
+	// The process is still up.
+	rr = httptest.NewRecorder()
+	healthCheck(rr, httptest.NewRequest("GET", "/health?verbose=true", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.True(t, res.Ok)
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// indexRebuildHandler returns the geo index rebuilds in progress in the groups served here on
+// GET. On POST, it resumes the rebuild of the index of the predicate parameter, which didn't
+// finish as the server building it stopped. The index is built in the background, after the
+// response, and can be followed and canceled by the id of the request on /admin/progress.
+func indexRebuildHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeSchema) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		rs := worker.IndexRebuilds()
+		if rs == nil {
+			rs = []worker.IndexRebuildState{}
+		}
+		writeJSON(w, rs)
+		return
+	case http.MethodPost:
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	attr := r.URL.Query().Get("predicate")
+	if err := worker.StartIndexRebuild(attr, x.RequestId(r.Context())); err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	x.SetStatus(w, x.Success, fmt.Sprintf("Rebuild of the index of %s resumed.", attr))
+}
@@ -0,0 +1,330 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Live queries are sent over a WebSocket to /live. A client starts one with
+//   {"type": "start", "id": "1", "query": "{ me(func: uid(0x1)) { name } }"}
+// and gets its result, in the format /query returns it,
+//   {"type": "data", "id": "1", "payload": {"data": {"me": [{"name": "Alice"}]}}}
+// straight away, and again each time mutations change it, until it sends
+//   {"type": "stop", "id": "1"}
+// or closes the connection. Queries that fail get {"type": "error", "id": "1", "message": ...}.
+//
+// A live query runs again when mutations touch the predicates it reads for the nodes in its
+// result, or the predicates of its functions, filters and orders, which could bring other nodes
+// in. Results that haven't changed aren't sent again.
+
+const maxLiveQueries = 100
+
+type liveMessage struct {
+	Type      string            `json:"type"`
+	Id        string            `json:"id,omitempty"`
+	Query     string            `json:"query,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Payload   json.RawMessage   `json:"payload,omitempty"`
+	Message   string            `json:"message,omitempty"`
+}
+
+// liveDeps are what the result of a live query depends on.
+type liveDeps struct {
+	// Predicates of functions, filters and orders, changes to which can bring in other nodes.
+	funcs map[string]bool
+	// Predicates read for the nodes in the result.
+	attrs map[string]bool
+	uids  map[uint64]bool
+	// Whether predicates are expanded, so that any of them can be read.
+	expand bool
+}
+
+func newLiveDeps(queries []*gql.GraphQuery, reached *protos.List) *liveDeps {
+	d := &liveDeps{
+		funcs: make(map[string]bool),
+		attrs: make(map[string]bool),
+		uids:  make(map[uint64]bool),
+	}
+	for _, gq := range queries {
+		d.add(gq)
+	}
+	for _, uid := range reached.Uids {
+		d.uids[uid] = true
+	}
+	return d
+}
+
+func (d *liveDeps) add(gq *gql.GraphQuery) {
+	if gq.Attr != "" {
+		d.attrs[strings.TrimPrefix(gq.Attr, "~")] = true
+	}
+	if gq.Expand != "" || gq.Attr == "_predicate_" {
+		d.expand = true
+	}
+	for _, uid := range gq.UID {
+		d.uids[uid] = true
+	}
+	d.addFunc(gq.Func)
+	d.addFilter(gq.Filter)
+	for _, arg := range []string{"orderasc", "orderdesc"} {
+		if attr, ok := gq.Args[arg]; ok {
+			d.funcs[attr] = true
+		}
+	}
+	for _, a := range gq.GroupbyAttrs {
+		d.attrs[a.Attr] = true
+	}
+	for _, child := range gq.Children {
+		d.add(child)
+	}
+}
+
+func (d *liveDeps) addFunc(f *gql.Function) {
+	if f == nil {
+		return
+	}
+	if f.Attr != "" {
+		d.funcs[strings.TrimPrefix(f.Attr, "~")] = true
+	}
+	for _, uid := range f.UID {
+		d.uids[uid] = true
+	}
+}
+
+func (d *liveDeps) addFilter(f *gql.FilterTree) {
+	if f == nil {
+		return
+	}
+	d.addFunc(f.Func)
+	for _, child := range f.Child {
+		d.addFilter(child)
+	}
+}
+
+// affectedBy returns whether a mutation of edge could change the result.
+func (d *liveDeps) affectedBy(edge *protos.DirectedEdge) bool {
+	if d.funcs[edge.Attr] {
+		return true
+	}
+	if !d.attrs[edge.Attr] && !d.expand {
+		return false
+	}
+	// Edges without an entity are schema updates, or deletions of all values of a predicate.
+	return edge.Entity == 0 || d.uids[edge.Entity] || (edge.ValueId != 0 && d.uids[edge.ValueId])
+}
+
+type liveQuery struct {
+	id   string
+	req  gql.Request
+	done chan struct{}
+
+	sync.RWMutex
+	deps *liveDeps
+}
+
+func (lq *liveQuery) affectedBy(edge *protos.DirectedEdge) bool {
+	lq.RLock()
+	defer lq.RUnlock()
+	// Until the first run completes, any mutation could change the result.
+	return lq.deps == nil || lq.deps.affectedBy(edge)
+}
+
+// evaluate runs the query, and returns its result in JSON.
+func (lq *liveQuery) evaluate(ctx context.Context) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	parsed, err := dgraph.ParseQueryAndMutation(ctx, lq.req)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Mutation != nil || parsed.Schema != nil || len(parsed.Query) == 0 {
+		return nil, x.Errorf("Live queries can't have mutations or schema queries")
+	}
+	l := &query.Latency{Start: time.Now()}
+	queryRequest := query.QueryRequest{Latency: l, GqlQuery: &parsed}
+	if err := queryRequest.ProcessQuery(ctx); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := query.ToJson(l, queryRequest.Subgraphs, &buf, nil, false); err != nil {
+		return nil, err
+	}
+	deps := newLiveDeps(parsed.Query, query.ReachedUids(queryRequest.Subgraphs))
+	lq.Lock()
+	lq.deps = deps
+	lq.Unlock()
+	return buf.Bytes(), nil
+}
+
+type liveConn struct {
+	ws *x.WebSocket
+	sync.Mutex
+	queries map[string]*liveQuery
+}
+
+func (c *liveConn) send(m liveMessage) {
+	b, err := json.Marshal(m)
+	x.Check(err)
+	if err := c.ws.WriteMessage(b); err != nil {
+		// The read loop notices the broken connection, and stops the queries.
+		x.Printf("Error while sending live query message: %v\n", err)
+	}
+}
+
+// run evaluates lq once, and then whenever a mutation touches what its result depends on, at
+// most once every --live_query_throttle.
+func (c *liveConn) run(lq *liveQuery) {
+	defer c.remove(lq)
+	changed, stopWatch := worker.WatchMutations(lq.affectedBy)
+	defer stopWatch()
+
+	var last []byte
+	for {
+		started := time.Now()
+		res, err := lq.evaluate(context.Background())
+		switch {
+		case err != nil && last == nil:
+			// The query is invalid, or can't be run at all.
+			c.send(liveMessage{Type: "error", Id: lq.id, Message: err.Error()})
+			return
+		case err != nil:
+			c.send(liveMessage{Type: "error", Id: lq.id, Message: err.Error()})
+		case !bytes.Equal(res, last):
+			c.send(liveMessage{Type: "data", Id: lq.id, Payload: res})
+			last = res
+		}
+
+		select {
+		case <-changed:
+		case <-lq.done:
+			return
+		}
+		if wait := dgraph.Config.LiveQueryThrottle - time.Since(started); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-lq.done:
+				return
+			}
+		}
+		// Changes while waiting are covered by the next run.
+		select {
+		case <-changed:
+		default:
+		}
+	}
+}
+
+func (c *liveConn) start(m liveMessage) {
+	c.Lock()
+	defer c.Unlock()
+	if _, ok := c.queries[m.Id]; ok {
+		c.send(liveMessage{Type: "error", Id: m.Id, Message: "A live query with this id is running"})
+		return
+	}
+	if len(c.queries) >= maxLiveQueries {
+		c.send(liveMessage{Type: "error", Id: m.Id, Message: "Too many live queries"})
+		return
+	}
+	lq := &liveQuery{
+		id:   m.Id,
+		req:  gql.Request{Str: m.Query, Variables: m.Variables},
+		done: make(chan struct{}),
+	}
+	if lq.req.Variables == nil {
+		lq.req.Variables = map[string]string{}
+	}
+	c.queries[m.Id] = lq
+	go c.run(lq)
+}
+
+func (c *liveConn) stop(id string) {
+	c.Lock()
+	defer c.Unlock()
+	if lq, ok := c.queries[id]; ok {
+		close(lq.done)
+		delete(c.queries, id)
+	}
+}
+
+// remove forgets lq once it's ended by itself.
+func (c *liveConn) remove(lq *liveQuery) {
+	c.Lock()
+	defer c.Unlock()
+	if c.queries[lq.id] == lq {
+		delete(c.queries, lq.id)
+	}
+}
+
+func (c *liveConn) stopAll() {
+	c.Lock()
+	defer c.Unlock()
+	for id, lq := range c.queries {
+		close(lq.done)
+		delete(c.queries, id)
+	}
+}
+
+func liveHandler(w http.ResponseWriter, r *http.Request) {
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+	ws, err := x.UpgradeWebSocket(w, r, dgraph.OriginAllowed)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	c := &liveConn{ws: ws, queries: make(map[string]*liveQuery)}
+	defer c.stopAll()
+	for {
+		b, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var m liveMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			c.send(liveMessage{Type: "error", Message: "Invalid message: " + err.Error()})
+			continue
+		}
+		switch m.Type {
+		case "start":
+			c.start(m)
+		case "stop":
+			c.stop(m.Id)
+		default:
+			c.send(liveMessage{Type: "error", Id: m.Id, Message: "Unknown message type: " + m.Type})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// loadBatchSize is how many N-Quads of a /load body are applied in each mutation.
+	loadBatchSize = 1000
+	// maxLoadLine is the size of the longest N-Quad line /load accepts.
+	maxLoadLine = 1 << 20
+	// loadReadTimeout is how long /load waits for each batch of the body to arrive.
+	loadReadTimeout = time.Minute
+)
+
+// loader applies N-Quads in batches. Blank nodes keep the uid they're first assigned across
+// batches.
+type loader struct {
+	ctx    context.Context
+	batch  []*protos.NQuad
+	uids   map[string]uint64
+	loaded int
+}
+
+// uid returns the uid assigned to the blank node id, or id itself if it isn't one or has none.
+func (l *loader) uid(id string) string {
+	if !strings.HasPrefix(id, "_:") {
+		return id
+	}
+	if uid, ok := l.uids[id[2:]]; ok {
+		return fmt.Sprintf("%#x", uid)
+	}
+	return id
+}
+
+func (l *loader) add(nq protos.NQuad) error {
+	nq.Subject = l.uid(nq.Subject)
+	if len(nq.ObjectId) > 0 {
+		nq.ObjectId = l.uid(nq.ObjectId)
+	}
+	l.batch = append(l.batch, &nq)
+	if len(l.batch) < loadBatchSize {
+		return nil
+	}
+	return l.flush()
+}
+
+func (l *loader) flush() error {
+	if len(l.batch) == 0 {
+		return nil
+	}
+	allocs, err := graphqlRunner{}.Mutate(l.ctx, &protos.Mutation{Set: l.batch})
+	if err != nil {
+		return err
+	}
+	for k, v := range allocs {
+		l.uids[k] = v
+	}
+	l.loaded += len(l.batch)
+	l.batch = l.batch[:0]
+	return nil
+}
+
+// loadHandler sets the RDF N-Quads of the body as it's read, in mutations of loadBatchSize
+// N-Quads, so that bodies of any size, chunked or not, are loaded without being held in memory.
+// The response has the number of N-Quads loaded and the uids assigned to blank nodes. On error,
+// the batches before the failing one stay applied.
+func loadHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	w.Header().Set("Content-Type", "application/json")
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+	defer r.Body.Close()
+
+	ctx := context.WithValue(context.Background(), "mutation_allowed", !dgraph.Config.Nomutations)
+	l := &loader{ctx: ctx, uids: make(map[string]uint64)}
+	fail := func(status int, code string, err error) {
+		w.WriteHeader(status)
+		x.SetStatus(w, code, fmt.Sprintf("%v. %d N-Quads were loaded before.", err, l.loaded))
+	}
+
+	// The read timeout of the server is for whole bodies, so it's pushed back for each batch.
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Now().Add(loadReadTimeout))
+	s := bufio.NewScanner(r.Body)
+	s.Buffer(make([]byte, 0, 64<<10), maxLoadLine)
+	for line := 1; s.Scan(); line++ {
+		nq, err := rdf.Parse(s.Text())
+		if err == rdf.ErrEmpty {
+			continue
+		}
+		if err != nil {
+			fail(http.StatusBadRequest, x.ErrorInvalidRequest,
+				errors.Wrapf(err, "While parsing line %d", line))
+			return
+		}
+		if err := l.add(nq); err != nil {
+			fail(http.StatusInternalServerError, x.Error, err)
+			return
+		}
+		if len(l.batch) == 0 {
+			rc.SetReadDeadline(time.Now().Add(loadReadTimeout))
+		}
+	}
+	switch err := s.Err(); {
+	case errors.Cause(err) == dgraph.ErrBodyTooLarge:
+		fail(http.StatusRequestEntityTooLarge, x.ErrorInvalidRequest, err)
+		return
+	case err == bufio.ErrTooLong:
+		fail(http.StatusBadRequest, x.ErrorInvalidRequest,
+			x.Errorf("Lines can't be longer than %d bytes", maxLoadLine))
+		return
+	case err != nil:
+		fail(http.StatusBadRequest, x.ErrorInvalidRequest,
+			errors.Wrapf(err, "While reading the body"))
+		return
+	}
+	if err := l.flush(); err != nil {
+		fail(http.StatusInternalServerError, x.Error, err)
+		return
+	}
+
+	js, err := json.Marshal(map[string]interface{}{
+		"code":   x.Success,
+		"loaded": l.loaded,
+		"uids":   query.ConvertUidsToHex(l.uids),
+	})
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	w.Write(js)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	// The body is written as it's read, over more than one batch.
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < loadBatchSize+10; i++ {
+			fmt.Fprintf(pw, "_:p%d <load.name> \"Person %d\" .\n", i, i)
+			fmt.Fprintf(pw, "_:p%d <load.friend> _:p0 .\n", i)
+		}
+		pw.Close()
+	}()
+	req := httptest.NewRequest("POST", "/load", pr)
+	rr := httptest.NewRecorder()
+	loadHandler(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var res struct {
+		Loaded int
+		Uids   map[string]string
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, 2*(loadBatchSize+10), res.Loaded)
+	require.Len(t, res.Uids, loadBatchSize+10)
+
+	// Blank nodes keep their uid across batches.
+	last := fmt.Sprintf("p%d", loadBatchSize+9)
+	q := fmt.Sprintf(`{ me(func: uid(%s)) { load.friend { _uid_ } } }`, res.Uids[last])
+	require.JSONEq(t, fmt.Sprintf(`{"data": {"me": [{"load.friend": [{"_uid_": "%s"}]}]}}`,
+		res.Uids["p0"]), processToFastJSON(q))
+
+	// The nodes loaded are deleted, as their uids are used by other tests.
+	var del bytes.Buffer
+	for _, uid := range res.Uids {
+		fmt.Fprintf(&del, "<%s> * * .\n", uid)
+	}
+	require.NoError(t, runMutation("mutation { delete { "+del.String()+" } }"))
+
+	rr = httptest.NewRecorder()
+	loadHandler(rr, httptest.NewRequest("POST", "/load",
+		bytes.NewBufferString("_:a <load.name> \"A\" .\n_:a <load.name>\n")))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "line 2")
+}
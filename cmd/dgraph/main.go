@@ -18,18 +18,19 @@
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"crypto/tls"
 	"encoding/gob"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"net"
 	"net/http"
-	_ "net/http/pprof"
 	"os"
 	"os/signal"
 	"path"
@@ -46,6 +47,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/cockroachdb/cmux"
+	"github.com/dgraph-io/dgraph/artifact"
 	"github.com/dgraph-io/dgraph/dgraph"
 	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/group"
@@ -53,9 +55,12 @@ import (
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/query"
 	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tracing"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -64,6 +69,10 @@ var (
 	baseGrpcPort int
 	bindall      bool
 
+	baseAdminPort int
+	adminAddr     string
+	adminToken    string
+
 	exposeTrace  bool
 	cpuprofile   string
 	memprofile   string
@@ -80,6 +89,16 @@ var (
 	tlsSystemCACerts bool
 	tlsMinVersion    string
 	tlsMaxVersion    string
+
+	// Mutual TLS configuration between nodes
+	peerTLSEnabled    bool
+	peerTLSCert       string
+	peerTLSKey        string
+	peerTLSKeyPass    string
+	peerTLSCACerts    string
+	peerTLSServerName string
+
+	serverLog = x.NewLog("server")
 )
 
 func setupConfigOpts() {
@@ -93,21 +112,212 @@ func setupConfigOpts() {
 			"performance respectively.")
 	flag.StringVar(&config.WALDir, "w", defaults.WALDir,
 		"Directory to store raft write-ahead logs.")
+	flag.Int64Var(&config.MinFreeDiskMB, "min_free_disk_mb", defaults.MinFreeDiskMB,
+		"Free space in MB the disks of the posting and WAL directories need for /ready to pass.")
 	flag.BoolVar(&config.Nomutations, "nomutations", defaults.Nomutations,
 		"Don't allow mutations on this server.")
+	flag.DurationVar(&config.LiveQueryThrottle, "live_query_throttle", defaults.LiveQueryThrottle,
+		"Shortest time between runs of a live query, as mutations change its result.")
+	flag.StringVar(&config.PersistedQueries, "persisted_queries", defaults.PersistedQueries,
+		"JSON file to keep persisted queries in, which are run by their id.")
+	flag.BoolVar(&config.PersistedOnly, "persisted_only", defaults.PersistedOnly,
+		"Only run persisted queries on this server.")
+	flag.BoolVar(&config.Cypher, "cypher", defaults.Cypher,
+		"Run the Cypher queries sent to /cypher, of a single MATCH path with WHERE and RETURN.")
+	flag.StringVar(&config.CypherLabel, "cypher_label", defaults.CypherLabel,
+		"Predicate the labels of nodes matched by Cypher queries are the values of.")
+	flag.BoolVar(&config.SPARQL, "sparql", defaults.SPARQL,
+		"Run the SPARQL SELECT queries sent to /sparql, of triple patterns with FILTER and OPTIONAL.")
+	flag.StringVar(&config.UDFPlugins, "udf_plugins", defaults.UDFPlugins,
+		"Comma separated list of Go plugins registering functions queries can call.")
+	flag.DurationVar(&config.UDFTimeout, "udf_timeout", defaults.UDFTimeout,
+		"Longest time a call of a function of plugins can take, or 0 for no limit.")
+	flag.IntVar(&config.UDFConcurrency, "udf_concurrency", defaults.UDFConcurrency,
+		"Most calls of functions of plugins running at once.")
+	flag.StringVar(&config.CorsOrigins, "cors_origins", defaults.CorsOrigins,
+		"Comma separated list of the origins allowed to make cross origin HTTP requests, or * "+
+			"for all.")
+	flag.StringVar(&config.TenantHeader, "tenant_header", defaults.TenantHeader,
+		"HTTP header to select the tenant of requests with.")
+	flag.StringVar(&config.Namespaces, "namespaces", defaults.Namespaces,
+		"JSON file to keep the namespaces of tenants and their tokens in. Queries must then be "+
+			"run in a namespace.")
+	flag.StringVar(&config.IPAllowlist, "ip_allowlist", defaults.IPAllowlist,
+		"Comma separated list of the IP addresses and CIDR networks allowed to send requests to "+
+			"the HTTP and gRPC ports, like \"10.0.0.0/8,192.168.1.10\". All by default.")
+	flag.Float64Var(&config.RateLimit, "rate_limit", defaults.RateLimit,
+		"Requests a second each client can send, counted per user for queries and per IP "+
+			"address otherwise. 0 for no limit.")
+	flag.IntVar(&config.RateBurst, "rate_burst", defaults.RateBurst,
+		"Requests each client can send at once, past --rate_limit. It's rate_limit rounded up "+
+			"if 0.")
+	flag.IntVar(&config.MaxClientQueries, "max_client_queries", defaults.MaxClientQueries,
+		"Queries each client can run at once. 0 for no limit.")
+	flag.StringVar(&config.ACL, "acl", defaults.ACL,
+		"JSON file to keep the access control lists of users and groups in. Requests must then "+
+			"be sent with a user and password.")
+	flag.StringVar(&config.AdminTokens, "admin_tokens", defaults.AdminTokens,
+		"JSON file to keep the scoped admin tokens created through /admin/tokens in. Admin "+
+			"requests can then be sent with them.")
+	flag.StringVar(&config.AdminOIDCIssuer, "admin_oidc_issuer", defaults.AdminOIDCIssuer,
+		"Issuer URL of the OpenID Connect provider whose ID tokens admin requests can be sent "+
+			"with.")
+	flag.StringVar(&config.AdminOIDCClientId, "admin_oidc_client_id", defaults.AdminOIDCClientId,
+		"Client id which the ID tokens of admins must be meant for.")
+	flag.StringVar(&config.AdminGroupsClaim, "admin_groups_claim", defaults.AdminGroupsClaim,
+		"Claim of ID tokens with the groups admins are in.")
+	flag.StringVar(&config.AdminLDAP, "admin_ldap", defaults.AdminLDAP,
+		"ldaps:// or ldap:// URL of the LDAP server which checks the users and passwords admin "+
+			"requests can be sent with.")
+	flag.StringVar(&config.AdminLDAPUserDN, "admin_ldap_user_dn", defaults.AdminLDAPUserDN,
+		"DN of the LDAP entries of admins, with %s for the user, like "+
+			"\"uid=%s,ou=people,dc=example,dc=com\".")
+	flag.StringVar(&config.AdminRoles, "admin_roles", defaults.AdminRoles,
+		"Comma separated list of group=scope pairs, giving the admins in the groups of the "+
+			"identity provider the scopes of admin tokens, like \"dgraph-admins=admin\".")
+	flag.StringVar(&config.JWTKeys, "jwt_keys", defaults.JWTKeys,
+		"PEM file of the RSA and ECDSA public keys which JSON Web Tokens are verified with. "+
+			"Queries must then be sent with a token.")
+	flag.StringVar(&config.JWTSecret, "jwt_secret", defaults.JWTSecret,
+		"File of the secret which JSON Web Tokens signed with HMAC are verified with. Queries "+
+			"must then be sent with a token.")
+	flag.StringVar(&config.JWTIssuers, "jwt_issuers", defaults.JWTIssuers,
+		"Comma separated list of the issuers of JSON Web Tokens to accept. Any by default.")
+	flag.StringVar(&config.JWTAudience, "jwt_audience", defaults.JWTAudience,
+		"Audience which JSON Web Tokens must be meant for.")
+	flag.StringVar(&config.JWTUserClaim, "jwt_user_claim", defaults.JWTUserClaim,
+		"Claim of JSON Web Tokens with the user of access control lists.")
+	flag.StringVar(&config.JWTGroupsClaim, "jwt_groups_claim", defaults.JWTGroupsClaim,
+		"Claim of JSON Web Tokens with the groups of access control lists the user is in.")
+	flag.StringVar(&config.AuditLog, "audit_log", defaults.AuditLog,
+		"File to append the audit log of requests to.")
+	flag.StringVar(&config.AuditLevel, "audit_level", defaults.AuditLevel,
+		"Requests recorded in the audit log: admin, mutations, queries or requests, each "+
+			"recording those of the previous ones, and requests the text of queries too.")
+	flag.Int64Var(&config.AuditLogSize, "audit_log_size", defaults.AuditLogSize,
+		"Size in bytes past which the audit log is rotated.")
+	flag.IntVar(&config.AuditLogFiles, "audit_log_files", defaults.AuditLogFiles,
+		"Number of rotated audit logs to keep.")
+	flag.DurationVar(&config.SlowQueryLatency, "slow_query_latency", defaults.SlowQueryLatency,
+		"Queries taking at least this long are logged as slow. 0 for no limit.")
+	flag.Int64Var(&config.SlowQueryBytes, "slow_query_bytes", defaults.SlowQueryBytes,
+		"Queries scanning at least these many bytes of posting lists are logged as slow. 0 for "+
+			"no limit.")
+	flag.StringVar(&config.SlowQueryLog, "slow_query_log", defaults.SlowQueryLog,
+		"File to append the slow query log to. The last slow queries are on /admin/slow_queries "+
+			"either way.")
+	flag.Int64Var(&config.SlowQueryLogSize, "slow_query_log_size", defaults.SlowQueryLogSize,
+		"Size in bytes past which the slow query log is rotated.")
+	flag.IntVar(&config.SlowQueryLogFiles, "slow_query_log_files", defaults.SlowQueryLogFiles,
+		"Number of rotated slow query logs to keep.")
+	flag.IntVar(&config.QueryStats, "query_stats", defaults.QueryStats,
+		"Number of normalized queries to keep statistics of, for /admin/top_queries. 0 to keep "+
+			"none.")
+	flag.StringVar(&config.BodyLimits, "body_limits", defaults.BodyLimits,
+		"Comma separated list of route:bytes pairs, limiting the size of the HTTP request "+
+			"bodies of routes, like \"/query:1048576,/node/:65536\".")
+	flag.Int64Var(&config.ConnBodyLimit, "conn_body_limit", defaults.ConnBodyLimit,
+		"Bytes of HTTP request bodies a connection can send, after which it's closed and "+
+			"requests get status 413. 0 for no limit.")
+	flag.DurationVar(&config.ValueGCInterval, "value_gc_interval", defaults.ValueGCInterval,
+		"Interval at which older versions of posting lists which aren't retained are deleted,"+
+			" and the value log is garbage collected.")
+	flag.Float64Var(&config.ValueGCThreshold, "value_gc_threshold", defaults.ValueGCThreshold,
+		"Fraction of a value log file which must be stale, before it gets rewritten to reclaim"+
+			" space. Set to zero to never rewrite them.")
+	flag.StringVar(&config.Retention, "retention", defaults.Retention,
+		"Comma separated list of predicate:retention pairs, where retention is a duration like"+
+			" 720h or a number of versions. Older versions of the posting lists of the predicate"+
+			" superseded since then, or the latest that many of them, are kept.")
 
 	flag.IntVar(&config.BaseWorkerPort, "workerport", defaults.BaseWorkerPort,
 		"Port used by worker for internal communication.")
 	flag.StringVar(&config.ExportPath, "export", defaults.ExportPath,
-		"Folder in which to store exports.")
+		"Folder in which to store exports, or an s3:// or gs:// URI.")
+	flag.StringVar(&config.BackupPath, "backup", defaults.BackupPath,
+		"Folder in which to store backups, or an s3:// or gs:// URI.")
+	flag.BoolVar(&config.Changelog, "changelog", defaults.Changelog,
+		"Keep a changelog of mutations in the backup folder, for point in time restores.")
+	flag.StringVar(&config.ChangelogArchive, "changelog_archive", defaults.ChangelogArchive,
+		"Folder, or s3:// or gs:// URI, to which segments of the changelog are copied as they're"+
+			" completed.")
+	flag.DurationVar(&config.ChangelogArchiveLag, "changelog_archive_lag",
+		defaults.ChangelogArchiveLag, "Longest time mutations can take to be archived.")
+	flag.StringVar(&config.CDCKafka, "cdc_kafka", defaults.CDCKafka,
+		"Comma separated list of Kafka brokers, as host:port, to which the leaders of groups "+
+			"publish the mutations committed. Needs --changelog.")
+	flag.StringVar(&config.CDCTopic, "cdc_topic", defaults.CDCTopic,
+		"Kafka topic of the mutations to predicates without a topic in --cdc_topics. If empty, "+
+			"they aren't published.")
+	flag.StringVar(&config.CDCTopics, "cdc_topics", defaults.CDCTopics,
+		"Comma separated list of pattern=topic pairs, like name=people,address.*=places, "+
+			"routing the mutations to predicates to Kafka topics. The first match wins.")
+	flag.StringVar(&config.CDCFormat, "cdc_format", defaults.CDCFormat,
+		"Format of the messages published to Kafka: dgraph, or debezium for the envelope of "+
+			"Debezium, as written by the JSON converter of Kafka Connect.")
+	flag.StringVar(&config.Elastic, "elastic", defaults.Elastic,
+		"Comma separated list of Elasticsearch nodes, like http://localhost:9200, to which the "+
+			"leaders of groups mirror string predicates. Needs --changelog.")
+	flag.StringVar(&config.ElasticIndex, "elastic_index", defaults.ElasticIndex,
+		"Elasticsearch index to which predicates are mirrored, with a document for each node.")
+	flag.StringVar(&config.ElasticPredicates, "elastic_predicates", defaults.ElasticPredicates,
+		"Comma separated list of patterns of the string predicates mirrored to Elasticsearch, or "+
+			"all of them if empty.")
+	flag.StringVar(&config.Webhooks, "webhooks", defaults.Webhooks,
+		"JSON file to keep the webhooks registered in, to which the leaders of groups post the "+
+			"mutations committed. Needs --changelog.")
+	flag.StringVar(&config.ObjectEncryption, "object_sse", defaults.ObjectEncryption,
+		"Server side encryption of exports and backups written to buckets: AES256, aws:kms or"+
+			" aws:kms:<key>.")
+	flag.StringVar(&config.Compression, "compression", defaults.Compression,
+		"Compression of exports, backups and archived changelogs: gzip or none.")
+	flag.IntVar(&config.CompressionLevel, "compression_level", defaults.CompressionLevel,
+		"Level of gzip compression, from 1 to 9.")
+	flag.StringVar(&config.ExportRedact, "export_redact", defaults.ExportRedact,
+		"Comma separated rules redacting predicates in exports, like email:hash,name:mask,"+
+			"location:geo(10),ssn:drop.")
+	flag.StringVar(&config.ExportRedactKey, "export_redact_key", defaults.ExportRedactKey,
+		"File with the key of the HMAC hashing values redacted with hash.")
+	flag.BoolVar(&config.RedactBackups, "redact_backups", defaults.RedactBackups,
+		"Apply the rules of --export_redact to backups too.")
+	flag.StringVar(&config.EncryptionKeyFile, "encryption_key_file", defaults.EncryptionKeyFile,
+		"File with a 256 bit key, to encrypt exports, backups and archived changelogs with.")
+	flag.StringVar(&config.EncryptionKMSKey, "encryption_kms_key", defaults.EncryptionKMSKey,
+		"AWS KMS key to encrypt exports, backups and archived changelogs with.")
 	flag.IntVar(&config.NumPendingProposals, "pending_proposals", defaults.NumPendingProposals,
 		"Number of pending mutation proposals. Useful for rate limiting.")
 	flag.Float64Var(&config.Tracing, "trace", defaults.Tracing,
-		"The ratio of queries to trace.")
+		"The ratio of queries to trace, in /debug/requests and with --trace_collector.")
+	flag.StringVar(&config.TraceCollector, "trace_collector", defaults.TraceCollector,
+		"URL of the Jaeger or Zipkin collector to report the spans of traced queries to, like "+
+			"http://localhost:9411/api/v2/spans.")
+	flag.StringVar(&config.TraceService, "trace_service", defaults.TraceService,
+		"Name of the service spans are reported for.")
+	flag.IntVar(&config.MetricsPredicates, "metrics_predicates", defaults.MetricsPredicates,
+		"Most predicates with their own series in the per predicate metrics of /metrics. Others "+
+			"are counted as _other_.")
+	flag.DurationVar(&config.EventRetention, "event_retention", defaults.EventRetention,
+		"How long events are kept in the event log of /admin/events. Zero keeps them forever.")
+	flag.DurationVar(&config.ProgressThreshold, "progress_threshold", defaults.ProgressThreshold,
+		"How long queries and jobs run before their progress can be followed, and they can be "+
+			"canceled, on /admin/progress.")
+	flag.DurationVar(&config.StallDeadline, "stall_deadline", defaults.StallDeadline,
+		"How long proposals and read tasks run before the server is taken to be stalled, and a "+
+			"diagnostics bundle is written. Zero disables the detection.")
+	flag.StringVar(&config.DiagnosticsPath, "diagnostics", defaults.DiagnosticsPath,
+		"Directory to write diagnostics bundles to.")
+	flag.StringVar(&config.LogFormat, "log_format", defaults.LogFormat,
+		"Format of logs: text, or json or logfmt for structured entries.")
+	flag.StringVar(&config.LogLevels, "log_levels", defaults.LogLevels,
+		"Comma separated list of component=level pairs, like worker=debug, setting the level of "+
+			"the logs of components: debug, info, warning or error. A level alone sets all of them.")
 	flag.StringVar(&config.GroupIds, "groups", defaults.GroupIds,
 		"RAFT groups handled by this server.")
 	flag.StringVar(&config.MyAddr, "my", defaults.MyAddr,
 		"addr:port of this server, so other Dgraph servers can talk to this.")
+	flag.StringVar(&config.ClientAddr, "client_addr", defaults.ClientAddr,
+		"addr:port clients reach the gRPC service of this server at, as told to clients"+
+			" discovering the cluster. Defaults to the host of --my with the gRPC port.")
 	flag.StringVar(&config.PeerAddr, "peer", defaults.PeerAddr,
 		"IP_ADDRESS:PORT of any healthy peer.")
 	flag.Uint64Var(&config.RaftId, "idx", defaults.RaftId,
@@ -117,11 +327,28 @@ func setupConfigOpts() {
 	flag.BoolVar(&config.ExpandEdge, "expand_edge", defaults.ExpandEdge,
 		"Enables the expand() feature. This is very expensive for large data loads because it"+
 			" doubles the number of mutations going on in the system.")
+	flag.StringVar(&config.KindPredicate, "kind_predicate", defaults.KindPredicate,
+		"Predicate the kinds of nodes are values of, for predicates declared @required(kind).")
+	flag.BoolVar(&config.GeoRepair, "geo_repair", defaults.GeoRepair,
+		"Reverse the rings of polygons set as geo values which are wound the wrong way, rather"+
+			" than rejecting them. Outer rings should be counter-clockwise and holes clockwise.")
+	flag.Uint64Var(&config.GeoCacheMB, "geo_cache_mb", defaults.GeoCacheMB,
+		"Size in MB of the cache of geo values decoded by geo queries, which repeated queries over"+
+			" the same values read from. Zero disables it.")
 
 	flag.Float64Var(&config.AllottedMemory, "memory_mb", defaults.AllottedMemory,
 		"Estimated memory the process can take. Actual usage would be slightly more than specified here.")
 	flag.Float64Var(&config.CommitFraction, "gentlecommit", defaults.CommitFraction,
 		"Fraction of dirty posting lists to commit every few seconds.")
+	flag.DurationVar(&config.CommitLatency, "commit_latency", defaults.CommitLatency,
+		"Target p99 latency for committing a batch of dirty posting lists. The batch size is"+
+			" tuned to meet it. Set to zero to use --gentlecommit instead.")
+	flag.StringVar(&config.CompactionPriority, "compaction_priority", defaults.CompactionPriority,
+		"Comma separated list of predicate:priority pairs, where priority is high, normal or low."+
+			" Posting lists of high priority predicates are merged and committed sooner.")
+	flag.IntVar(&config.BlobThreshold, "blob_threshold", defaults.BlobThreshold,
+		"Values of at least these many bytes are stored separately from their posting lists,"+
+			" so reading uids doesn't read them. Set to zero to disable.")
 
 	flag.StringVar(&config.ConfigFile, "config", defaults.ConfigFile,
 		"YAML configuration file containing dgraph settings.")
@@ -138,11 +365,20 @@ func setupConfigOpts() {
 	flag.IntVar(&baseGrpcPort, "grpc_port", 9080, "Port to run gRPC service on.")
 	flag.BoolVar(&bindall, "bindall", false,
 		"Use 0.0.0.0 instead of localhost to bind to all addresses on local machine.")
+	flag.IntVar(&baseAdminPort, "admin_port", 0,
+		"Port to run the admin gRPC service on. It isn't run if 0.")
+	flag.StringVar(&adminAddr, "admin_addr", "localhost",
+		"Address to bind the admin gRPC service to.")
+	flag.StringVar(&adminToken, "admin_token", "",
+		"Token which admin gRPC requests must send as auth-token. Required unless admin_addr is "+
+			"a loopback address.")
 	flag.BoolVar(&exposeTrace, "expose_trace", false,
 		"Allow trace endpoint to be accessible from remote")
 	flag.StringVar(&cpuprofile, "cpu", "", "write cpu profile to file")
 	flag.StringVar(&memprofile, "mem", "", "write memory profile to file")
 	flag.IntVar(&blockRate, "block", 0, "Block profiling rate")
+	flag.DurationVar(&maxProfileDuration, "max_profile_duration", time.Minute,
+		"Longest duration of the profiles captured on /admin/profile and by the Admin service.")
 	flag.StringVar(&dumpSubgraph, "dumpsg", "", "Directory to save subgraph for testing, debugging")
 	// TLS configurations
 	flag.BoolVar(&tlsEnabled, "tls.on", false, "Use TLS connections with clients.")
@@ -154,6 +390,17 @@ func setupConfigOpts() {
 	flag.BoolVar(&tlsSystemCACerts, "tls.use_system_ca", false, "Include System CA into CA Certs.")
 	flag.StringVar(&tlsMinVersion, "tls.min_version", "TLS11", "TLS min version.")
 	flag.StringVar(&tlsMaxVersion, "tls.max_version", "TLS12", "TLS max version.")
+	flag.BoolVar(&peerTLSEnabled, "peer_tls.on", false,
+		"Use mutual TLS on the connections between nodes.")
+	flag.StringVar(&peerTLSCert, "peer_tls.cert", "",
+		"Certificate file path of the node, for both its server and client sides.")
+	flag.StringVar(&peerTLSKey, "peer_tls.cert_key", "", "Certificate key file path of the node.")
+	flag.StringVar(&peerTLSKeyPass, "peer_tls.cert_key_passphrase", "",
+		"Certificate key passphrase of the node.")
+	flag.StringVar(&peerTLSCACerts, "peer_tls.ca_certs", "",
+		"CA Certs file path, which the certificates of all nodes must be signed by.")
+	flag.StringVar(&peerTLSServerName, "peer_tls.server_name", "",
+		"Server name the certificates of nodes are verified against, instead of their hosts.")
 
 	flag.Parse()
 	if !flag.Parsed() {
@@ -169,6 +416,13 @@ func setupConfigOpts() {
 	// SetConfiguration.
 	x.PrintVersionOnly()
 
+	if len(config.ClientAddr) == 0 {
+		host := "localhost"
+		if h, _, err := net.SplitHostPort(config.MyAddr); err == nil && len(h) > 0 {
+			host = h
+		}
+		config.ClientAddr = net.JoinHostPort(host, strconv.Itoa(grpcPort()))
+	}
 	dgraph.SetConfiguration(config)
 }
 
@@ -206,8 +460,9 @@ func stopProfiling() {
 	}
 }
 
+// addCorsHeaders adds the CORS headers of handlers. Access-Control-Allow-Origin is set by
+// dgraph.WrapHTTP, following --cors_origins.
 func addCorsHeaders(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers",
 		"Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token,"+
@@ -218,6 +473,11 @@ func addCorsHeaders(w http.ResponseWriter) {
 
 func healthCheck(w http.ResponseWriter, r *http.Request) {
 	addCorsHeaders(w)
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); verbose {
+		probes, _ := dgraph.Readiness()
+		writeProbes(w, x.HealthCheck() == nil, probes)
+		return
+	}
 	if err := x.HealthCheck(); err == nil {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
@@ -226,6 +486,31 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// readyHandler tells whether the server can serve queries, which needs more than the process
+// being up as /health tells: the schema loaded, Raft caught up, memberships synced with group
+// zero and free disk space. The reasons of failed checks are in the response.
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	probes, ready := dgraph.Readiness()
+	writeProbes(w, ready, probes)
+}
+
+// writeProbes writes probes as JSON, with status 200 if ok and 503 otherwise.
+func writeProbes(w http.ResponseWriter, ok bool, probes []dgraph.Probe) {
+	js, err := json.Marshal(map[string]interface{}{"ok": ok, "checks": probes})
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(js)
+}
+
 func queryHandler(w http.ResponseWriter, r *http.Request) {
 	addCorsHeaders(w)
 	w.Header().Set("Content-Type", "application/json")
@@ -250,7 +535,8 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Lets add the value of the debug query parameter to the context.
-	ctx := context.WithValue(context.Background(), "debug", r.URL.Query().Get("debug"))
+	ctx := x.WithRequestId(context.Background(), x.RequestId(r.Context()))
+	ctx = context.WithValue(ctx, "debug", r.URL.Query().Get("debug"))
 	ctx = context.WithValue(ctx, "mutation_allowed", !dgraph.Config.Nomutations)
 
 	if rand.Float64() < worker.Config.Tracing {
@@ -259,6 +545,10 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		defer tr.Finish()
 		ctx = trace.NewContext(ctx, tr)
 	}
+	span, ctx := tracing.StartRoot(ctx, "query", r.Header.Get(tracing.Header))
+	defer span.Finish()
+	ctx, progress := worker.StartProgress(ctx, worker.RunQuery, x.RequestId(ctx))
+	progress.SetPhase("parsing")
 
 	invalidRequest := func(err error, msg string) {
 		if tr, ok := trace.FromContext(ctx); ok {
@@ -271,8 +561,57 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 	l.Start = time.Now()
 	defer r.Body.Close()
 	req, err := ioutil.ReadAll(r.Body)
-	q := string(req)
-	if err != nil || len(q) == 0 {
+	defer func() { progress.Finish(err) }()
+	if errors.Cause(err) == dgraph.ErrBodyTooLarge {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	if err != nil {
+		invalidRequest(err, "Error while reading query")
+		return
+	}
+	gr, err := persistedRequest(r.URL.Query().Get("persisted"), req)
+	if err != nil {
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	ns, err := httpNamespace(r)
+	if err != nil {
+		dgraph.AuditDenied("/query", r.RemoteAddr, "", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		x.SetStatus(w, x.ErrorUnauthorized, err.Error())
+		return
+	}
+	id, err := httpIdentity(r)
+	if err != nil {
+		dgraph.AuditDenied("/query", r.RemoteAddr, id.User, err)
+		w.Header().Set("WWW-Authenticate", authChallenge())
+		w.WriteHeader(http.StatusUnauthorized)
+		x.SetStatus(w, x.ErrorUnauthorized, err.Error())
+		return
+	}
+	done, err := dgraph.StartQuery(dgraph.ClientKey(id.User, r.RemoteAddr))
+	if err != nil {
+		dgraph.WriteThrottled(w, err)
+		return
+	}
+	defer done()
+	version, err := httpAPIVersion(w, r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	format, err := responseFormat(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	q := gr.Str
+	if len(q) == 0 {
 		invalidRequest(err, "Error while reading query")
 		return
 	}
@@ -281,11 +620,7 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("Received query: %+v\n", q)
 	}
 	parseStart := time.Now()
-	parsed, err := dgraph.ParseQueryAndMutation(ctx, gql.Request{
-		Str:       q,
-		Variables: map[string]string{},
-		Http:      true,
-	})
+	parsed, err := dgraph.ParseQueryAndMutation(ctx, gr)
 	l.Parsing += time.Since(parseStart)
 	if err != nil {
 		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
@@ -303,11 +638,29 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 	// After execution starts according to the GraphQL spec data key must be returned. It would be
 	// null if any error is encountered, else non-null.
 	var res query.ExecuteResult
-	var queryRequest = query.QueryRequest{Latency: &l, GqlQuery: &parsed}
-	if res, err = queryRequest.ProcessWithMutation(ctx); err != nil {
+	var queryRequest = query.QueryRequest{Latency: &l, GqlQuery: &parsed, Namespace: ns,
+		Access: id.Access, NodeFilter: id.NodeFilter}
+	audit := dgraph.AuditRequest("/query", r.RemoteAddr, id.User, ns, q, &parsed)
+	var stats *worker.TaskStats
+	if debug, _ := strconv.ParseBool(r.URL.Query().Get("debug")); debug {
+		// Debug queries have the resources their tasks used in their extensions.
+		ctx, stats = worker.WithTaskStats(ctx)
+	}
+	ctx, slow := dgraph.StartSlowQuery(ctx, "/query", r.RemoteAddr, id.User, ns, gr, &l)
+	serverLog.Debugf(ctx, "Running query from %s", r.RemoteAddr)
+	progress.SetPhase("processing")
+	res, err = queryRequest.ProcessWithMutation(ctx)
+	audit.Done(err)
+	slow.Done(err)
+	span.SetError(err)
+	if err != nil {
+		serverLog.Warningf(ctx, "Error while processing query: %v", err)
 		switch errors.Cause(err).(type) {
 		case *query.InvalidRequestError:
 			x.SetStatusWithData(w, x.ErrorInvalidRequest, err.Error())
+		case *query.PermissionDeniedError:
+			w.WriteHeader(http.StatusForbidden)
+			x.SetStatusWithData(w, x.ErrorUnauthorized, err.Error())
 		default: // internalError or other
 			if tr, ok := trace.FromContext(ctx); ok {
 				tr.LazyPrintf("Error while handling mutations: %+v", err)
@@ -316,20 +669,47 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	serverLog.Debugf(ctx, "Processed query in %v", time.Since(l.Start))
+	progress.SetPhase("encoding")
 
 	var addLatency bool
 	// If there is an error parsing, then addLatency would remain false.
 	addLatency, _ = strconv.ParseBool(r.URL.Query().Get("latency"))
 	debug, _ := strconv.ParseBool(r.URL.Query().Get("debug"))
 	addLatency = addLatency || debug
+	var deprecated []*protos.Deprecation
+	extensions := func() *query.Extensions {
+		warnDeprecations(w, deprecated)
+		if !addLatency && len(deprecated) == 0 && stats == nil {
+			return nil
+		}
+		e := &query.Extensions{Deprecations: deprecated}
+		if addLatency {
+			e.Latency = l.ToMap()
+		}
+		if stats != nil {
+			r := stats.Resources()
+			e.Resources = &r
+		}
+		return e
+	}
+
+	if format == formatProtobuf {
+		extensions()
+		writeProtobuf(w, &l, res)
+		return
+	}
 
 	newUids := query.ConvertUidsToHex(res.Allocations)
 	if len(parsed.Query) == 0 {
 		schemaRes := map[string]interface{}{}
 		mp := map[string]interface{}{}
 		if parsed.Mutation != nil {
-			mp["code"] = x.Success
-			mp["message"] = "Done"
+			if d := dgraph.Deprecated("mutation_status", version); d != nil {
+				mp["code"] = x.Success
+				mp["message"] = "Done"
+				deprecated = append(deprecated, d)
+			}
 			mp["uids"] = newUids
 		}
 		// Either Schema or query can be specified
@@ -346,13 +726,17 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		schemaRes["data"] = mp
-		if addLatency {
-			e := query.Extensions{
-				Latency: l.ToMap(),
-			}
+		if e := extensions(); e != nil {
 			schemaRes["extensions"] = e
 		}
-		if js, err := json.Marshal(schemaRes); err == nil {
+		var js []byte
+		if format == formatRaw {
+			js, err = json.Marshal(mp)
+		} else {
+			js, err = json.Marshal(schemaRes)
+		}
+		if err == nil {
+			w.Header().Set("Content-Type", formatTypes[format])
 			w.Write(js)
 		} else {
 			x.SetStatusWithData(w, x.Error, "Unable to marshal schema")
@@ -373,8 +757,24 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	err = query.ToJson(&l, res.Subgraphs, w,
-		query.ConvertUidsToHex(res.Allocations), addLatency)
+	switch format {
+	case formatRows:
+		writeRows(w, res, extensions())
+		return
+	case formatRaw:
+		var buf bytes.Buffer
+		err = query.ToJsonWithExtensions(res.Subgraphs, &buf, newUids, extensions())
+		var js []byte
+		if err == nil {
+			js, err = unwrap(buf.Bytes())
+		}
+		if err == nil {
+			w.Header().Set("Content-Type", formatTypes[formatRaw])
+			_, err = w.Write(js)
+		}
+	default:
+		err = query.ToJsonWithExtensions(res.Subgraphs, w, newUids, extensions())
+	}
 	if err != nil {
 		// since we performed w.Write in ToJson above,
 		// calling WriteHeader with 500 code will be ignored.
@@ -468,23 +868,18 @@ func storeStatsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("</pre>"))
 }
 
-// handlerInit does some standard checks. Returns false if something is wrong.
-func handlerInit(w http.ResponseWriter, r *http.Request) bool {
+// handlerInit does some standard checks, and that the request is allowed operations of scope.
+// Returns false if something is wrong.
+func handlerInit(w http.ResponseWriter, r *http.Request, scope string) bool {
 	if r.Method != http.MethodGet {
 		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
 		return false
 	}
-
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil || !net.ParseIP(ip).IsLoopback() {
-		x.SetStatus(w, x.ErrorUnauthorized, fmt.Sprintf("Request from IP: %v", ip))
-		return false
-	}
-	return true
+	return adminAllowed(w, r, scope)
 }
 
 func shutDownHandler(w http.ResponseWriter, r *http.Request) {
-	if !handlerInit(w, r) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
 		return
 	}
 
@@ -503,21 +898,46 @@ func shutdownServer() {
 	go func() {
 		defer func() { dgraph.State.ShutdownCh <- struct{}{} }()
 
-		// wait for grpc, http and http2 servers to stop
+		// wait for grpc, http and http2 servers to stop, and the admin server if it runs
 		<-dgraph.State.FinishCh
 		<-dgraph.State.FinishCh
 		<-dgraph.State.FinishCh
+		if adminPort() != 0 {
+			<-dgraph.State.FinishCh
+		}
 
 		worker.BlockingStop()
 	}()
 }
 
+// exportHandler exports the data of the cluster as RDF, or as JSON if the format parameter is set
+// to json. The include and exclude parameters take comma separated patterns of the predicates to
+// export, and the query parameter takes a query; only the nodes reached by it are exported. With
+// geojson set to true, the geo values are also exported as GeoJSON.
 func exportHandler(w http.ResponseWriter, r *http.Request) {
-	if !handlerInit(w, r) {
+	if !handlerInit(w, r, dgraph.ScopeExport) {
 		return
 	}
-	ctx := context.Background()
-	if err := worker.ExportOverNetwork(ctx); err != nil {
+	ctx, progress := worker.StartProgress(context.Background(), worker.RunExport,
+		x.RequestId(r.Context()))
+	var err error
+	defer func() { progress.Finish(err) }()
+	params := r.URL.Query()
+	req := &protos.ExportPayload{
+		Format:    params.Get("format"),
+		Include:   splitPatterns(params.Get("include")),
+		Exclude:   splitPatterns(params.Get("exclude")),
+		Namespace: params.Get("namespace"),
+		GeoJson:   params.Get("geojson") == "true",
+	}
+	if q := params.Get("query"); q != "" {
+		progress.SetPhase("querying")
+		if req.Uids, err = reachedUids(ctx, q, req.Namespace); err != nil {
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+	}
+	if err = worker.ExportOverNetwork(ctx, req); err != nil {
 		x.SetStatus(w, err.Error(), "Export failed.")
 		return
 	}
@@ -525,6 +945,315 @@ func exportHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"code": "Success", "message": "Export completed."}`))
 }
 
+func splitPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// reachedUids runs the query q in namespace ns, and returns all the nodes it reached.
+func reachedUids(ctx context.Context, q, ns string) (*protos.List, error) {
+	parsed, err := dgraph.ParseQueryAndMutation(ctx, gql.Request{
+		Str:       q,
+		Variables: map[string]string{},
+		Http:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Mutation != nil || len(parsed.Query) == 0 {
+		return nil, x.Errorf("Export takes a query without mutations")
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	queryRequest := query.QueryRequest{Latency: &query.Latency{}, GqlQuery: &parsed, Namespace: ns}
+	er, err := queryRequest.ProcessWithMutation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return query.ReachedUids(er.Subgraphs), nil
+}
+
+// backupHandler takes an incremental backup of the cluster, or a full one if the full parameter
+// is set to true.
+func backupHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeExport) {
+		return
+	}
+	full := r.URL.Query().Get("full") == "true"
+	ctx, progress := worker.StartProgress(context.Background(), worker.RunBackup,
+		x.RequestId(r.Context()))
+	err := worker.BackupOverNetwork(ctx, full)
+	progress.Finish(err)
+	if err != nil {
+		x.SetStatus(w, err.Error(), "Backup failed.")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"code": "Success", "message": "Backup completed."}`))
+}
+
+// encryptionKeyHandler returns the version of the key exports and backups are encrypted with, and
+// the versions in the key file, on GET. On POST, it rotates the key, adding a new version to the
+// key file, or reads the key file again if the reload parameter is set to true.
+func encryptionKeyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var err error
+		if r.URL.Query().Get("reload") == "true" {
+			err = artifact.ReloadKeys()
+		} else {
+			_, err = artifact.RotateKey()
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+	current, versions, err := artifact.KeyVersions()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	writeJSON(w, map[string]interface{}{"version": current, "versions": versions})
+}
+
+func purgeHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	stats, err := posting.Purge()
+	if err != nil {
+		x.SetStatus(w, err.Error(), "Purge failed.")
+		return
+	}
+	x.Printf("Purge done. Merged %d lists. Removed %d keys, %d bytes.\n",
+		stats.ListsMerged, stats.KeysRemoved, stats.Bytes)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"code": "Success", "message": "Purge completed.", "lists_merged": %d, `+
+		`"keys_removed": %d, "bytes_reclaimed": %d}`,
+		stats.ListsMerged, stats.KeysRemoved, stats.Bytes)
+}
+
+// statsHandler outputs the storage stats of a predicate given by the predicate parameter, or of
+// all predicates on this server.
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	stats := posting.CollectStats(r.URL.Query().Get("predicate"))
+	res, err := json.Marshal(stats)
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal stats")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// memoryHandler returns the breakdown of the memory in use by subsystem.
+func memoryHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	res, err := json.Marshal(x.ReportMemory())
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal memory report")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+func slowQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	res, err := json.Marshal(dgraph.SlowQueries())
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal slow queries")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// topQueriesHandler returns the statistics of the n queries, 20 by default, with the most of sort:
+// total, the default, count, mean, p95 or scanned, on GET. On DELETE, it removes them all.
+func topQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		dgraph.ResetQueryStats()
+		w.Write([]byte(`{"code": "Success", "message": "Query statistics removed."}`))
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+	params := r.URL.Query()
+	by, n := "total", 20
+	if s := params.Get("sort"); s != "" {
+		by = s
+	}
+	if s := params.Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Invalid number of queries: "+s)
+			return
+		}
+	}
+	stats, err := dgraph.TopQueries(by, n)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	res, err := json.Marshal(stats)
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal query statistics")
+		return
+	}
+	w.Write(res)
+}
+
+// eventTime parses the bound of a range of events, as an RFC 3339 time or a duration before now.
+func eventTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, x.Errorf("Invalid time: %q. Expected an RFC 3339 time or a duration", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// eventsHandler returns the events of the event log between since and until, of the types in
+// type and of group if set, the last limit of them, 1000 by default.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	invalid := func(msg string) {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, msg)
+	}
+	params := r.URL.Query()
+	q := worker.EventQuery{Limit: 1000}
+	var err error
+	if s := params.Get("since"); s != "" {
+		if q.Since, err = eventTime(s); err != nil {
+			invalid(err.Error())
+			return
+		}
+	}
+	if s := params.Get("until"); s != "" {
+		if q.Until, err = eventTime(s); err != nil {
+			invalid(err.Error())
+			return
+		}
+	}
+	if s := params.Get("type"); s != "" {
+		q.Types = strings.Split(s, ",")
+	}
+	if s := params.Get("group"); s != "" {
+		gid, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			invalid("Invalid group: " + s)
+			return
+		}
+		q.Group = uint32(gid)
+	}
+	if s := params.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil || q.Limit < 0 {
+			invalid("Invalid limit: " + s)
+			return
+		}
+	}
+	events, err := worker.Events(q)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+	res, err := json.Marshal(events)
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal events")
+		return
+	}
+	w.Write(res)
+}
+
+// diagnosticsHandler lists the diagnostics bundles written on GET, or downloads the one of the name
+// parameter. On POST, it writes a bundle now.
+func diagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		name, err := worker.WriteDiagnostics("Requested from " + r.RemoteAddr)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		writeJSON(w, map[string]string{"name": name})
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		bundles, err := worker.DiagnosticsBundles()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		writeJSON(w, bundles)
+		return
+	}
+	f, err := worker.OpenDiagnostics(name)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, err.Error())
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	io.Copy(w, f)
+}
+
 func memoryLimitHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -556,6 +1285,8 @@ func memoryLimitPutHandler(w http.ResponseWriter, r *http.Request) {
 	posting.Config.Mu.Lock()
 	posting.Config.AllottedMemory = memoryMB
 	posting.Config.Mu.Unlock()
+	worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId, "memory_mb set to %v",
+		memoryMB)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -569,6 +1300,114 @@ func memoryLimitGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func compactionPriorityHandler(w http.ResponseWriter, r *http.Request) {
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		prios := posting.FormatCompactionPriorities(posting.CompactionPriorities())
+		if _, err := fmt.Fprintln(w, prios); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		prios, err := posting.ParseCompactionPriorities(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		posting.SetCompactionPriorities(prios)
+		worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId,
+			"compaction_priority set to %s", body)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func retentionHandler(w http.ResponseWriter, r *http.Request) {
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := fmt.Fprintln(w, posting.FormatRetentions(posting.Retentions())); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		retentions, err := posting.ParseRetentions(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		posting.SetRetentions(retentions)
+		worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId,
+			"retention set to %s", body)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// versionsHandler returns the older versions of the posting list of a predicate and uid, which
+// are kept by the retention of the predicate.
+func versionsHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	attr := r.URL.Query().Get("predicate")
+	uid, err := gql.ParseUid(r.URL.Query().Get("uid"))
+	if attr == "" || err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, "Both predicate and uid must be given")
+		return
+	}
+	res, err := json.Marshal(posting.Versions(attr, uid))
+	if err != nil {
+		x.SetStatus(w, x.Error, "Unable to marshal versions")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+func logLevelsHandler(w http.ResponseWriter, r *http.Request) {
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if _, err := fmt.Fprintln(w, x.LogLevels()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := x.SetLogLevels(string(body)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		worker.RecordEvent(worker.EventConfig, 0, worker.Config.RaftId, "log_levels set to %s",
+			body)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func hasGraphOps(mu *protos.Mutation) bool {
 	return len(mu.Set) > 0 || len(mu.Del) > 0 || len(mu.Schema) > 0
 }
@@ -644,12 +1483,66 @@ func setupListener(addr string, port int) (listener net.Listener, err error) {
 	return listener, err
 }
 
+// setupPeerTLS sets up the mutual TLS credentials of the connections between nodes. Like those of
+// the listeners, they're reloaded on SIGHUP.
+func setupPeerTLS() error {
+	if !peerTLSEnabled {
+		return nil
+	}
+	config := x.TLSHelperConfig{
+		CertRequired:  true,
+		Cert:          peerTLSCert,
+		Key:           peerTLSKey,
+		KeyPassphrase: peerTLSKeyPass,
+		MinVersion:    tlsMinVersion,
+		MaxVersion:    tlsMaxVersion,
+	}
+	serverConfig := config
+	serverConfig.ConfigType = x.TLSServerConfig
+	serverConfig.ClientAuth = "REQUIREANDVERIFY"
+	serverConfig.ClientCACerts = peerTLSCACerts
+	serverCreds, err := x.NewTLSCredentials(serverConfig)
+	if err != nil {
+		return err
+	}
+	clientConfig := config
+	clientConfig.ConfigType = x.TLSClientConfig
+	clientConfig.RootCACerts = peerTLSCACerts
+	clientConfig.ServerName = peerTLSServerName
+	clientCreds, err := x.NewTLSCredentials(clientConfig)
+	if err != nil {
+		return err
+	}
+	worker.Config.PeerServerCreds = serverCreds
+	worker.Config.PeerClientCreds = clientCreds
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGHUP)
+		for range sigChan {
+			reloaded := true
+			for _, creds := range []*x.TLSCredentials{serverCreds, clientCreds} {
+				if err := creds.Reload(); err != nil {
+					log.Printf("Error reloading TLS between nodes: %v. Using current ones.", err)
+					reloaded = false
+				}
+			}
+			if reloaded {
+				log.Println("TLS certificates and CAs between nodes reloaded")
+			}
+		}
+	}()
+	return nil
+}
+
 func serveGRPC(l net.Listener) {
 	defer func() { dgraph.State.FinishCh <- struct{}{} }()
 	s := grpc.NewServer(grpc.CustomCodec(&query.Codec{}),
 		grpc.MaxRecvMsgSize(x.GrpcMaxSize),
 		grpc.MaxSendMsgSize(x.GrpcMaxSize),
-		grpc.MaxConcurrentStreams(1000))
+		grpc.MaxConcurrentStreams(1000),
+		grpc.UnaryInterceptor(dgraph.AllowlistUnary),
+		grpc.StreamInterceptor(dgraph.AllowlistStream))
 	protos.RegisterDgraphServer(s, &dgraph.Server{})
 	err := s.Serve(l)
 	log.Printf("gRpc server stopped : %s", err.Error())
@@ -662,6 +1555,7 @@ func serveHTTP(l net.Listener) {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 600 * time.Second,
 		IdleTimeout:  2 * time.Minute,
+		ConnContext:  dgraph.ConnContext,
 	}
 
 	err := srv.Serve(l)
@@ -675,6 +1569,11 @@ func serveHTTP(l net.Listener) {
 	}
 }
 
+// handle registers h for route, wrapped by the HTTP middleware.
+func handle(route string, h http.HandlerFunc) {
+	http.Handle(route, dgraph.WrapHTTP(route, h))
+}
+
 func setupServer(che chan error) {
 	// By default Go GRPC traces all requests.
 	grpc.EnableTracing = false
@@ -695,38 +1594,97 @@ func setupServer(che chan error) {
 		log.Fatal(err)
 	}
 
+	var adminListener net.Listener
+	if adminPort() != 0 {
+		if adminToken == "" && !dgraph.AdminTokensEnabled() && !dgraph.AdminLoginEnabled() &&
+			!isLoopback(adminAddr) {
+			log.Fatal("--admin_token, --admin_tokens, --admin_oidc_issuer or --admin_ldap is "+
+				"required to bind the admin service to ", adminAddr)
+		}
+		if adminListener, err = setupListener(adminAddr, adminPort()); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	httpMux := cmux.New(httpListener)
 	httpl := httpMux.Match(cmux.HTTP1Fast())
 	http2 := httpMux.Match(cmux.HTTP2())
 
-	http.HandleFunc("/health", healthCheck)
-	http.HandleFunc("/query", queryHandler)
-	http.HandleFunc("/share", shareHandler)
-	http.HandleFunc("/debug/store", storeStatsHandler)
-	http.HandleFunc("/admin/shutdown", shutDownHandler)
-	http.HandleFunc("/admin/export", exportHandler)
-	http.HandleFunc("/admin/config/memory_mb", memoryLimitHandler)
+	handle("/health", healthCheck)
+	handle("/ready", readyHandler)
+	handle("/version", versionHandler)
+	handle("/query", compressed(queryHandler))
+	handle("/graphql", notRestricted(notPersistedOnly(compressed(graphqlHandler))))
+	handle("/graphql/schema", graphqlSchemaHandler)
+	handle("/cypher", notRestricted(notPersistedOnly(compressed(cypherHandler))))
+	handle("/sparql", notRestricted(notPersistedOnly(compressed(sparqlHandler))))
+	handle("/live", notRestricted(notPersistedOnly(liveHandler)))
+	handle("/changes", notRestricted(changesHandler))
+	handle("/invalidations", notRestricted(invalidationsHandler))
+	handle("/node", notRestricted(notPersistedOnly(compressed(nodeHandler))))
+	handle("/node/", notRestricted(notPersistedOnly(compressed(nodeHandler))))
+	handle("/load", notRestricted(notPersistedOnly(compressed(loadHandler))))
+	handle("/share", notRestricted(shareHandler))
+	handle("/debug/store", storeStatsHandler)
+	handle("/metrics", prometheus.Handler().ServeHTTP)
+	handle("/admin/shutdown", shutDownHandler)
+	handle("/admin/export", exportHandler)
+	handle("/admin/backup", backupHandler)
+	handle("/admin/encryption_key", encryptionKeyHandler)
+	handle("/admin/purge", purgeHandler)
+	handle("/admin/stats", statsHandler)
+	handle("/admin/versions", versionsHandler)
+	handle("/admin/memory", memoryHandler)
+	handle("/admin/slow_queries", slowQueriesHandler)
+	handle("/admin/top_queries", topQueriesHandler)
+	handle("/admin/events", eventsHandler)
+	handle("/admin/progress", progressHandler)
+	handle("/admin/diagnostics", diagnosticsHandler)
+	handle("/admin/profile", profileHandler)
+	handle("/admin/queries", persistedQueriesHandler)
+	handle("/admin/webhooks", webhooksHandler)
+	handle("/admin/namespaces", namespacesHandler)
+	handle("/admin/migrations", migrationsHandler)
+	handle("/admin/rename", renameHandler)
+	handle("/admin/index_rebuild", indexRebuildHandler)
+	handle("/admin/acl/users", aclUsersHandler)
+	handle("/admin/acl/groups", aclGroupsHandler)
+	handle("/admin/acl/filters", aclFiltersHandler)
+	handle("/admin/tokens", adminTokensHandler)
+	handle("/admin/config/memory_mb", memoryLimitHandler)
+	handle("/admin/config/compaction_priority", compactionPriorityHandler)
+	handle("/admin/config/retention", retentionHandler)
+	handle("/admin/config/log_levels", logLevelsHandler)
 
 	// UI related API's.
 	// Share urls have a hex string as the shareId. So if
 	// our url path matches it, we wan't to serve index.html.
 	reg := regexp.MustCompile(`\/0[xX][0-9a-fA-F]+`)
-	http.Handle("/", homeHandler(http.FileServer(http.Dir(uiDir)), reg))
-	http.HandleFunc("/ui/keywords", keywordHandler)
+	http.Handle("/", dgraph.WrapHTTP("/", homeHandler(http.FileServer(http.Dir(uiDir)), reg)))
+	handle("/ui/keywords", keywordHandler)
 
 	// Initilize the servers.
 	go serveGRPC(grpcListener)
 	go serveHTTP(httpl)
 	go serveHTTP(http2)
+	if adminListener != nil {
+		go serveAdmin(adminListener)
+	}
 
 	go func() {
 		<-dgraph.State.ShutdownCh
 		// Stops grpc/http servers; Already accepted connections are not closed.
 		grpcListener.Close()
 		httpListener.Close()
+		if adminListener != nil {
+			adminListener.Close()
+		}
 	}()
 
 	log.Println("gRPC server started.  Listening on port", grpcPort())
+	if adminListener != nil {
+		log.Println("Admin gRPC server started.  Listening on", adminListener.Addr())
+	}
 	log.Println("HTTP server started.  Listening on port", httpPort())
 
 	err = httpMux.Serve()     // Start cmux serving. blocking call
@@ -764,8 +1722,21 @@ func main() {
 	// schema before calling posting.Init().
 	schema.Init(dgraph.State.Pstore)
 	posting.Init(dgraph.State.Pstore)
+	posting.BuildKeyFilters()
 	worker.Config.InMemoryComm = false
+	x.Checkf(setupPeerTLS(), "While setting up TLS between nodes.")
 	worker.Init(dgraph.State.Pstore)
+	x.Checkf(dgraph.LoadPersistedQueries(), "While loading persisted queries.")
+	x.Checkf(udf.Load(dgraph.Config.UDFPlugins), "While loading plugins of functions.")
+	x.Checkf(dgraph.LoadWebhooks(), "While loading webhooks.")
+	x.Checkf(dgraph.LoadNamespaces(), "While loading namespaces.")
+	x.Checkf(dgraph.LoadACL(), "While loading access control lists.")
+	x.Checkf(dgraph.LoadJWTKeys(), "While loading JWT keys.")
+	x.Checkf(dgraph.LoadAdminTokens(), "While loading admin tokens.")
+	x.Checkf(dgraph.OpenAuditLog(), "While opening audit log.")
+	defer dgraph.CloseAuditLog()
+	x.Checkf(dgraph.OpenSlowQueryLog(), "While opening slow query log.")
+	defer dgraph.CloseSlowQueryLog()
 
 	// setup shutdown os signal handler
 	sdCh := make(chan os.Signal, 3)
@@ -1466,6 +1466,253 @@ func TestListTypeSchemaChange(t *testing.T) {
 
 }
 
+func TestRequired(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				kind: [string] .
+				reqtest_email: string @required(Person, Company) .
+				reqtest_employer: uid @required(Person) .
+			}
+		}
+	`))
+
+	// Nodes of other kinds, or without any, don't need the predicates.
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9001> <kind> "Film" .
+				<0x9002> <reqtest_employer> <0x9003> .
+			}
+		}
+	`))
+	err := runMutation(`
+		mutation {
+			set {
+				<0x9003> <kind> "Company" .
+				<0x9003> <name> "Acme" .
+			}
+		}
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Node 0x9003 of kind Company must have a value of reqtest_email")
+	res, err := runQuery(`{ me(func: uid(0x9003)) { name } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {}}`, res)
+
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9003> <kind> "Company" .
+				<0x9003> <reqtest_email> "info@acme.com" .
+				<0x9004> <kind> "Person" .
+				<0x9004> <reqtest_email> "alice@acme.com" .
+				<0x9004> <reqtest_employer> <0x9003> .
+			}
+		}
+	`))
+	// Values can be replaced, but not deleted.
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9004> <reqtest_email> "alice@example.com" .
+			}
+		}
+	`))
+	err = runMutation(`
+		mutation {
+			delete {
+				<0x9004> <reqtest_employer> <0x9003> .
+			}
+		}
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must have a value of reqtest_employer")
+	err = runMutation(`
+		mutation {
+			set {
+				<0x9001> <kind> "Person" .
+			}
+		}
+	`)
+	require.Error(t, err)
+	err = runMutation(`
+		mutation {
+			delete {
+				* <reqtest_email> * .
+			}
+		}
+	`)
+	require.Error(t, err)
+
+	// Nodes stop needing them with their kind, or all their predicates, deleted.
+	require.NoError(t, runMutation(`
+		mutation {
+			delete {
+				<0x9003> <kind> "Company" .
+				<0x9003> <reqtest_email> * .
+				<0x9004> * * .
+			}
+		}
+	`))
+}
+
+func TestDefault(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				deftest_status: string @default("active") .
+				deftest_rank: int @default("3") .
+			}
+		}
+	`))
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9101> <name> "Alice" .
+				<0x9101> <deftest_status> "retired" .
+				<0x9102> <name> "Bob" .
+				<0x9102> <deftest_status> "absent"@en .
+			}
+		}
+	`))
+
+	res, err := runQuery(`
+		{
+			me(func: uid(0x9101, 0x9102)) {
+				name
+				deftest_status
+				deftest_rank
+				deftest_status@en
+			}
+		}
+	`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me":[
+		{"name":"Alice","deftest_status":"retired","deftest_rank":3},
+		{"name":"Bob","deftest_status":"active","deftest_rank":3,"deftest_status@en":"absent"}
+	]}}`, res)
+
+	res, err = runQuery(`schema(pred: deftest_rank) { default }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data":{"schema":[{"predicate":"deftest_rank","default":"3"}]}}`, res)
+}
+
+func TestNoreplace(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				nrtest_ssn: string @index(exact) @noreplace .
+				nrtest_nick: string .
+			}
+			set {
+				<0x9501> <nrtest_ssn> "123" .
+				<0x9501> <nrtest_nick> "al" .
+			}
+		}
+	`))
+
+	// Setting the same value again is fine, and predicates which aren't @noreplace are replaced.
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9501> <nrtest_ssn> "123" .
+				<0x9501> <nrtest_ssn> "un"@fr .
+				<0x9501> <nrtest_nick> "ally" .
+			}
+		}
+	`))
+	err := runMutation(`mutation { set { <0x9501> <nrtest_ssn> "456" . } }`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"Node 0x9501 already has another value of nrtest_ssn, which can't be replaced")
+	err = runMutation(`mutation { set { <0x9501> <nrtest_ssn> "deux"@fr . } }`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "value of nrtest_ssn@fr")
+
+	// Values can be set again once they're deleted.
+	require.NoError(t, runMutation(`
+		mutation {
+			delete {
+				<0x9501> <nrtest_ssn> * .
+			}
+		}
+	`))
+	require.NoError(t, runMutation(`mutation { set { <0x9501> <nrtest_ssn> "456" . } }`))
+
+	res, err := runQuery(`{
+		me(func: eq(nrtest_ssn, "456")) { nrtest_ssn nrtest_nick }
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [{"nrtest_ssn": "456", "nrtest_nick": "ally"}]}}`, res)
+
+	res, err = runQuery(`schema(pred: nrtest_ssn) { noreplace }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data":{"schema":[{"predicate":"nrtest_ssn","noreplace":true}]}}`, res)
+	require.Error(t, runMutation(`mutation { schema { nrtest_tags: [string] @noreplace . } }`))
+}
+
+func TestSchemaStats(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				sstest_name: string @index(exact) .
+				sstest_loc: geo @index(geo) .
+				sstest_age: int .
+			}
+			set {
+				<0x9701> <sstest_name> "Alice" .
+				<0x9701> <sstest_name> "Alicia"@es .
+				<0x9702> <sstest_name> "Bob" .
+				<0x9701> <sstest_loc> "{'type':'Point','coordinates':[-122.4,37.7]}"^^<geo:geojson> .
+			}
+		}
+	`))
+	// The stats are of the keys in the store, which are written asynchronously.
+	posting.CommitLists(10, group.BelongsTo("sstest_name"))
+	time.Sleep(100 * time.Millisecond)
+
+	res, err := runQuery(`schema(pred: [sstest_name, sstest_loc, sstest_age]) {
+		type lang geo cardinality size
+	}`)
+	require.NoError(t, err)
+	var out struct {
+		Data struct {
+			Schema []*protos.SchemaNode
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(res), &out))
+	nodes := make(map[string]*protos.SchemaNode)
+	for _, n := range out.Data.Schema {
+		nodes[n.Predicate] = n
+	}
+	require.Len(t, nodes, 3)
+	name, loc, age := nodes["sstest_name"], nodes["sstest_loc"], nodes["sstest_age"]
+	require.True(t, name.Lang)
+	require.Nil(t, name.Geo)
+	require.EqualValues(t, 2, name.Cardinality)
+	require.NotZero(t, name.Size_)
+	require.False(t, loc.Lang)
+	require.Equal(t, &protos.GeoIndex{MinLevel: types.MinCellLevel, MaxLevel: types.MaxCellLevel,
+		MaxCells: types.MaxCells}, loc.Geo)
+	require.EqualValues(t, 1, loc.Cardinality)
+	// Indexes take space too.
+	require.True(t, loc.Size_ > name.Size_, "%d <= %d", loc.Size_, name.Size_)
+	require.False(t, age.Lang)
+	require.Zero(t, age.Cardinality)
+	require.Zero(t, age.Size_)
+
+	// The fields are only returned when asked for.
+	res, err = runQuery(`schema(pred: sstest_loc) {}`)
+	require.NoError(t, err)
+	require.NotContains(t, res, "cardinality")
+	require.NotContains(t, res, `"geo":`)
+}
+
 func TestMain(m *testing.M) {
 	dc := dgraph.DefaultConfig
 	dc.AllottedMemory = 2048.0
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// migrationsHandler returns the state of the migrations of the cluster on GET. On POST, it applies
+// the JSON array of migrations in the body, resumes the migration which didn't complete if the
+// resume parameter is set to true, or rolls the cluster back to the version of the rollback
+// parameter. Migrations run until done, even if the client goes away, and can be canceled by the
+// id of their request on /admin/progress.
+func migrationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeSchema) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		st, err := dgraph.Migrations(r.Context())
+		if err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		writeJSON(w, st)
+		return
+	case http.MethodPost:
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	params := r.URL.Query()
+	var ms []*dgraph.Migration
+	var version int
+	switch {
+	case params.Get("resume") == "true":
+	case params.Get("rollback") != "":
+		var err error
+		if version, err = strconv.Atoi(params.Get("rollback")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Invalid version to roll back to: "+
+				params.Get("rollback"))
+			return
+		}
+	default:
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "While reading migrations: "+err.Error())
+			return
+		}
+	}
+
+	ctx, progress := worker.StartProgress(context.Background(), worker.RunMigration,
+		x.RequestId(r.Context()))
+	var st *dgraph.MigrationState
+	var err error
+	switch {
+	case params.Get("resume") == "true":
+		st, err = dgraph.ResumeMigration(ctx, progress)
+	case params.Get("rollback") != "":
+		st, err = dgraph.RollbackMigrations(ctx, version, progress)
+	default:
+		st, err = dgraph.ApplyMigrations(ctx, ms, progress)
+	}
+	progress.Finish(err)
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	writeJSON(w, st)
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/schema"
+)
+
+func runMigrations(t *testing.T, method, params, body string) string {
+	req := httptest.NewRequest(method, "/admin/migrations"+params, strings.NewReader(body))
+	req.RemoteAddr = "127.0.0.1:8080"
+	rr := httptest.NewRecorder()
+	migrationsHandler(rr, req)
+	return rr.Body.String()
+}
+
+func migrationState(t *testing.T) *dgraph.MigrationState {
+	st := new(dgraph.MigrationState)
+	require.NoError(t, json.Unmarshal([]byte(runMigrations(t, "GET", "", "")), st))
+	return st
+}
+
+const testBackfill = `{ u as var(func: eq(migtest_name, \"Bob\")) } ` +
+	`mutation { set { uid(u) <migtest_nick> \"bobby\" . } }`
+
+const testMigrations = `[
+	{"version": 1, "description": "Index names",
+	 "up": [{"schema": "migtest_name: string @index(exact) ."}],
+	 "down": [{"schema": "migtest_name: string ."}]},
+	{"version": 2, "description": "Nick Bob",
+	 "up": [{"backfill": "` + testBackfill + `"}]},
+	{"version": 3, "description": "Rename names",
+	 "up": [{"rename": {"from": "migtest_name", "to": "migtest_fullname"}}],
+	 "down": [{"rename": {"from": "migtest_fullname", "to": "migtest_name"}}]}
+]`
+
+func TestMigrations(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				migtest_name: string .
+			}
+			set {
+				<0x9101> <migtest_name> "Alice" .
+				<0x9101> <migtest_name> "Alicia"@es .
+				<0x9102> <migtest_name> "Bob" .
+			}
+		}
+	`))
+	require.Equal(t, 0, migrationState(t).Version)
+
+	require.Contains(t, runMigrations(t, "POST", "", `[{"version": 1, "up": [{}]}]`),
+		"Steps must have one of schema, backfill or rename")
+	require.Contains(t, runMigrations(t, "POST", "", `[{"version": 1, "up": [{"backfill": "{}"}]}]`),
+		"Backfills must have a mutation")
+
+	res := runMigrations(t, "POST", "", testMigrations)
+	require.Contains(t, res, `"version":3`)
+	st := migrationState(t)
+	require.Equal(t, 3, st.Version)
+	require.Len(t, st.Applied, 3)
+	require.Nil(t, st.Run)
+
+	out, err := runQuery(`{
+		me(func: eq(migtest_fullname, "Bob")) { migtest_fullname migtest_nick migtest_name }
+		you(func: uid(0x9101)) { migtest_fullname migtest_fullname@es migtest_name }
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {
+		"me": [{"migtest_fullname": "Bob", "migtest_nick": "bobby"}],
+		"you": [{"migtest_fullname": "Alice", "migtest_fullname@es": "Alicia"}]
+	}}`, out)
+
+	// Migrations already applied are skipped.
+	runMigrations(t, "POST", "", testMigrations)
+	require.Equal(t, 3, migrationState(t).Version)
+
+	require.Contains(t, runMigrations(t, "POST", "?rollback=4", ""), "Can't roll back to version 4")
+	runMigrations(t, "POST", "?rollback=2", "")
+	require.Equal(t, 2, migrationState(t).Version)
+	out, err = runQuery(`{ me(func: eq(migtest_name, "Bob")) { migtest_name migtest_fullname } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [{"migtest_name": "Bob"}]}}`, out)
+	require.Contains(t, runMigrations(t, "POST", "?rollback=0", ""),
+		"Migration 2 has no down steps")
+
+	// A failed run keeps the step it stopped at, and is resumed from it.
+	res = runMigrations(t, "POST", "", `[{"version": 5,
+		"up": [
+			{"schema": "migtest_age: int ."},
+			{"rename": {"from": "migtest_years", "to": "migtest_age"}}
+		],
+		"down": [{"rename": {"from": "migtest_age", "to": "migtest_years"}}]}]`)
+	require.Contains(t, res, "Step 2 of migration 5 up failed")
+	st = migrationState(t)
+	require.Equal(t, 2, st.Version)
+	require.NotNil(t, st.Run)
+	require.Equal(t, dgraph.MigrationFailed, st.Run.Status)
+	require.Equal(t, 1, st.Run.Step)
+	require.Contains(t, st.Run.Error, "No predicate migtest_years to rename")
+	require.Contains(t, runMigrations(t, "POST", "", testMigrations), "Migration 5 didn't complete")
+
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				migtest_years: int .
+			}
+			set {
+				<0x9101> <migtest_years> "30" .
+			}
+		}
+	`))
+	runMigrations(t, "POST", "?resume=true", "")
+	st = migrationState(t)
+	require.Equal(t, 5, st.Version)
+	require.Nil(t, st.Run)
+	out, err = runQuery(`{ me(func: uid(0x9101)) { migtest_age migtest_years } }`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [{"migtest_age": 30}]}}`, out)
+	require.Contains(t, runMigrations(t, "POST", "?resume=true", ""), "No migration to resume")
+}
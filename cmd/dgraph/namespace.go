@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// httpNamespace returns the namespace to run a /query request in, from the --tenant_header and
+// the X-Auth-Token of r.
+func httpNamespace(r *http.Request) (string, error) {
+	return dgraph.AuthorizeNamespace(dgraph.Tenant(r.Context()), r.Header.Get("X-Auth-Token"))
+}
+
+// notRestricted wraps h, to refuse its requests on servers with namespaces, access control lists
+// or JWT keys, as it doesn't run them in a namespace, nor check their permissions or tokens.
+func notRestricted(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dgraph.NamespacesEnabled() || dgraph.ACLEnabled() || dgraph.JWTEnabled() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			x.SetStatus(w, x.ErrorUnauthorized,
+				"Only /query can be used with namespaces, access control lists or tokens")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// namespacesHandler lists the namespaces on GET, adds the namespace of the name parameter with
+// the token in the body on PUT, or changes its token, and drops it with all of its data on DELETE.
+func namespacesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	if !dgraph.NamespacesEnabled() {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "Namespaces (--namespaces) aren't enabled")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	switch r.Method {
+	case http.MethodGet:
+		js, err := json.Marshal(dgraph.Namespaces())
+		if err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		w.Write(js)
+	case http.MethodPut:
+		defer r.Body.Close()
+		token, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		if err := dgraph.SetNamespace(name, string(token)); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Set namespace "+name)
+	case http.MethodDelete:
+		if !hasNamespace(name) {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, "No namespace: "+name)
+			return
+		}
+		// The namespace is removed first, so that nothing is written to it while it's dropped.
+		if err := dgraph.DeleteNamespace(name); err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		if err := dgraph.DropNamespace(context.Background(), name); err != nil {
+			x.SetStatus(w, x.Error, "While dropping the data of namespace "+name+": "+err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Dropped namespace "+name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
+
+func hasNamespace(name string) bool {
+	for _, ns := range dgraph.Namespaces() {
+		if ns == name {
+			return true
+		}
+	}
+	return false
+}
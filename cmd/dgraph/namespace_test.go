@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+)
+
+func runInNamespace(t *testing.T, ns, token, q string) (int, string) {
+	req, err := http.NewRequest("POST", "/query", bytes.NewBufferString(q))
+	require.NoError(t, err)
+	req.Header.Set("X-Tenant", ns)
+	req.Header.Set("X-Auth-Token", token)
+	rr := httptest.NewRecorder()
+	dgraph.WrapHTTP("/query", http.HandlerFunc(queryHandler)).ServeHTTP(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func TestNamespaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "namespaces")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(file, header string) {
+		dgraph.Config.Namespaces, dgraph.Config.TenantHeader = file, header
+	}(dgraph.Config.Namespaces, dgraph.Config.TenantHeader)
+	dgraph.Config.Namespaces = filepath.Join(dir, "namespaces.json")
+	dgraph.Config.TenantHeader = "X-Tenant"
+
+	require.Error(t, dgraph.SetNamespace("a::b", "secret"))
+	require.NoError(t, dgraph.SetNamespace("acme", "secret"))
+	require.NoError(t, dgraph.SetNamespace("beta", "token"))
+	require.NoError(t, dgraph.LoadNamespaces())
+	require.Equal(t, []string{"acme", "beta"}, dgraph.Namespaces())
+
+	code, res := runInNamespace(t, "acme", "secret", `mutation {
+		schema { ns.name: string @index(exact) . }
+		set {
+			<0x4001> <ns.name> "Alice" .
+			<0x4001> <ns.friend> <0x4002> .
+		}
+	}`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.Contains(t, res, "Success")
+	code, res = runInNamespace(t, "beta", "token",
+		`mutation { set { <0x4001> <ns.name> "Bob" . } }`)
+	require.Equal(t, http.StatusOK, code, res)
+
+	q := `{ me(func: eq(ns.name, "Alice")) { ns.name count(ns.friend) } }`
+	_, res = runInNamespace(t, "acme", "secret", q)
+	require.JSONEq(t, `{"data": {"me": [{"ns.name": "Alice", "count(ns.friend)": 1}]}}`, res)
+	_, res = runInNamespace(t, "beta", "token",
+		`{ me(func: uid(0x4001)) { ns.name ns.friend { _uid_ } } }`)
+	require.JSONEq(t, `{"data": {"me": [{"ns.name": "Bob"}]}}`, res)
+	_, res = runInNamespace(t, "beta", "token", `{ me(func: uid(0x4001)) { expand(_all_) } }`)
+	require.JSONEq(t, `{"data": {"me": [{"ns.name": "Bob"}]}}`, res)
+	_, res = runInNamespace(t, "acme", "secret", `schema(pred: [ns.name]) { type index }`)
+	require.JSONEq(t, `{"data": {"schema": [{"predicate": "ns.name", "type": "string",
+		"index": true}]}}`, res)
+	_, res = runInNamespace(t, "acme", "secret", `schema {}`)
+	require.Contains(t, res, `"ns.friend"`)
+	require.NotContains(t, res, "persisted.name")
+	require.NotContains(t, res, "::")
+
+	code, _ = runInNamespace(t, "acme", "token", q)
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = runInNamespace(t, "", "", q)
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, res = runInNamespace(t, "acme", "secret", `{ me(func: uid(0x4001)) { _predicate_ } }`)
+	require.Contains(t, res, "ErrorInvalidRequest")
+	rr := httptest.NewRecorder()
+	notRestricted(nodeHandler)(rr, httptest.NewRequest("GET", "/node/0x4001", nil))
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	// S * * only deletes the predicates of the namespace.
+	_, res = runInNamespace(t, "beta", "token", `mutation { delete { <0x4001> * * . } }`)
+	require.Contains(t, res, "Success")
+	_, res = runInNamespace(t, "acme", "secret", `{ me(func: uid(0x4001)) { ns.name } }`)
+	require.JSONEq(t, `{"data": {"me": [{"ns.name": "Alice"}]}}`, res)
+
+	require.NoError(t, dgraph.DeleteNamespace("acme"))
+	require.NoError(t, dgraph.DropNamespace(context.Background(), "acme"))
+	require.NoError(t, dgraph.SetNamespace("acme", "secret"))
+	_, res = runInNamespace(t, "acme", "secret", `{ me(func: uid(0x4001)) { ns.name } }`)
+	require.JSONEq(t, `{"data": {}}`, res)
+}
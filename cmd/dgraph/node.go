@@ -0,0 +1,405 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The /node endpoints read and write single nodes as JSON objects, with a key per predicate:
+//   {"_uid_": "0x1", "name": "Alice", "name@fr": "Alice", "friend": [{"_uid_": "0x2"}]}
+// GET /node/<uid> returns the node, with the values in the languages of the lang parameter,
+// POST /node adds a new one, PUT /node/<uid> replaces the values of the predicates given, or
+// deletes them if given null, and DELETE /node/<uid> deletes all of its predicates.
+
+// nodePredicates returns the schema of the predicates of the node uid.
+func nodePredicates(ctx context.Context, uid uint64) (map[string]*protos.SchemaNode, error) {
+	vals, err := query.GetNodePredicates(ctx, &protos.List{Uids: []uint64{uid}})
+	if err != nil {
+		return nil, err
+	}
+	var preds []string
+	for _, vl := range vals {
+		for _, v := range vl.Values {
+			if len(v.Val) > 0 {
+				preds = append(preds, string(v.Val))
+			}
+		}
+	}
+	return predicateSchema(ctx, preds)
+}
+
+// predicateSchema returns the schema of preds, with nil for those which have none, and so no
+// values yet.
+func predicateSchema(ctx context.Context, preds []string) (map[string]*protos.SchemaNode, error) {
+	schema := make(map[string]*protos.SchemaNode)
+	if len(preds) == 0 {
+		return schema, nil
+	}
+	nodes, err := worker.GetSchemaOverNetwork(ctx, &protos.SchemaRequest{
+		Predicates: preds,
+		Fields:     []string{"type", "list"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range preds {
+		schema[p] = nil
+	}
+	for _, n := range nodes {
+		schema[n.Predicate] = n
+	}
+	return schema, nil
+}
+
+// getNode returns the node uid as JSON, or nil if it has no predicates. Values of string
+// predicates in langs are returned too, under <predicate>@<lang>.
+func getNode(ctx context.Context, uid uint64, langs []string) ([]byte, error) {
+	schema, err := nodePredicates(ctx, uid)
+	if err != nil || len(schema) == 0 {
+		return nil, err
+	}
+	var preds []string
+	for p := range schema {
+		preds = append(preds, p)
+	}
+	sort.Strings(preds)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "{\n  node(func: uid(%#x)) {\n    _uid_\n", uid)
+	for _, p := range preds {
+		if p == "_predicate_" {
+			continue
+		}
+		sn := schema[p]
+		if sn != nil && sn.Type == "uid" {
+			fmt.Fprintf(&buf, "    <%s> { _uid_ }\n", p)
+			continue
+		}
+		fmt.Fprintf(&buf, "    <%s>\n", p)
+		if sn != nil && (sn.Type == "string" || sn.Type == "default") {
+			for _, lang := range langs {
+				fmt.Fprintf(&buf, "    <%s>@%s\n", p, lang)
+			}
+		}
+	}
+	buf.WriteString("  }\n}")
+	res, err := graphqlRunner{}.Query(ctx, buf.String(), map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Data struct {
+			Node []json.RawMessage `json:"node"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Data.Node) == 0 {
+		return nil, nil
+	}
+	return out.Data.Node[0], nil
+}
+
+// nodeObject returns the uid of a node referred to in a patch, as {"_uid_": "0x2"} or "0x2".
+func nodeObject(v interface{}) (string, error) {
+	if obj, ok := v.(map[string]interface{}); ok {
+		v = obj["_uid_"]
+		if len(obj) != 1 || v == nil {
+			return "", x.Errorf("Nodes are referred to by their _uid_ only")
+		}
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", x.Errorf("Invalid uid: %v", v)
+	}
+	uid, err := gql.ParseUid(s)
+	if err != nil {
+		return "", x.Errorf("Invalid uid: %q", s)
+	}
+	return fmt.Sprintf("%#x", uid), nil
+}
+
+// setValue sets the value of nq to v, with the type the client would give it.
+func setValue(nq *protos.NQuad, typ string, v interface{}) error {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && typ != "float" {
+			nq.ObjectValue = &protos.Value{Val: &protos.Value_IntVal{IntVal: i}}
+			nq.ObjectType = int32(types.IntID)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		nq.ObjectValue = &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: f}}
+		nq.ObjectType = int32(types.FloatID)
+		return nil
+	case bool:
+		nq.ObjectValue = &protos.Value{Val: &protos.Value_BoolVal{BoolVal: v}}
+		nq.ObjectType = int32(types.BoolID)
+		return nil
+	case string:
+		// Strings are left for Dgraph to convert to the type of the predicate.
+		nq.ObjectValue = &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: v}}
+		return nil
+	case map[string]interface{}:
+		if typ == "geo" {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			nq.ObjectValue = &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: string(b)}}
+			return nil
+		}
+	}
+	return x.Errorf("Invalid value: %v", v)
+}
+
+// patchMutations returns the mutations which apply patch to the node subject: first the
+// deletions of the values replaced, then the new values. Setting a value replaces the one there,
+// except for uid and list predicates, whose values are deleted first.
+func patchMutations(ctx context.Context, subject string,
+	patch map[string]interface{}) (del, set []*protos.NQuad, err error) {
+	keys := make([]string, 0, len(patch))
+	var preds []string
+	for key := range patch {
+		pred := strings.SplitN(key, "@", 2)[0]
+		if pred == "_predicate_" || pred == "" {
+			return nil, nil, x.Errorf("Invalid predicate: %q", key)
+		}
+		if pred != "_uid_" {
+			keys = append(keys, key)
+			preds = append(preds, pred)
+		}
+	}
+	sort.Strings(keys)
+	schema, err := predicateSchema(ctx, preds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, key := range keys {
+		parts := strings.SplitN(key, "@", 2)
+		pred, lang := parts[0], ""
+		if len(parts) == 2 {
+			lang = parts[1]
+		}
+		sn, v := schema[pred], patch[key]
+		if v == nil && lang != "" {
+			return nil, nil, x.Errorf("Values in a language can't be deleted by themselves: %q",
+				key)
+		}
+		// Predicates without a schema have no values to delete.
+		if sn != nil && lang == "" && !strings.HasPrefix(subject, "_:") &&
+			(v == nil || sn.Type == "uid" || sn.List) {
+			del = append(del, &protos.NQuad{
+				Subject:     subject,
+				Predicate:   pred,
+				ObjectValue: &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: x.Star}},
+			})
+		}
+		if v == nil {
+			continue
+		}
+		vals, ok := v.([]interface{})
+		if !ok {
+			vals = []interface{}{v}
+		}
+		typ := ""
+		if sn != nil {
+			typ = sn.Type
+		}
+		for _, val := range vals {
+			nq := &protos.NQuad{Subject: subject, Predicate: pred, Lang: lang}
+			if typ == "uid" || (typ == "" && isNodeObject(val)) {
+				if nq.ObjectId, err = nodeObject(val); err != nil {
+					return nil, nil, x.Wrapf(err, "For predicate %q", key)
+				}
+				nq.Lang = ""
+			} else if err = setValue(nq, typ, val); err != nil {
+				return nil, nil, x.Wrapf(err, "For predicate %q", key)
+			}
+			set = append(set, nq)
+		}
+	}
+	return del, set, nil
+}
+
+func validLang(lang string) bool {
+	for _, r := range lang {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+			return false
+		}
+	}
+	return lang != ""
+}
+
+func isNodeObject(v interface{}) bool {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = obj["_uid_"]
+	return ok
+}
+
+func nodeHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Content-Type", "application/json")
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctx = context.WithValue(ctx, "mutation_allowed", !dgraph.Config.Nomutations)
+
+	var uid uint64
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/node"), "/")
+	if (id == "") != (r.Method == "POST") {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, "Nodes are added with POST /node, and others "+
+			"take /node/<uid>")
+		return
+	}
+	if id != "" {
+		var err error
+		if uid, err = gql.ParseUid(id); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, fmt.Sprintf("Invalid uid: %q", id))
+			return
+		}
+	}
+
+	var langs []string
+	if l := r.URL.Query().Get("lang"); l != "" {
+		langs = strings.Split(l, ",")
+	}
+	for _, lang := range langs {
+		if !validLang(lang) {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, fmt.Sprintf("Invalid language: %q", lang))
+			return
+		}
+	}
+
+	var patch map[string]interface{}
+	if r.Method == "POST" || r.Method == "PUT" {
+		dec := json.NewDecoder(r.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Expected a JSON object: "+err.Error())
+			return
+		}
+	}
+
+	switch r.Method {
+	case "GET":
+	case "POST", "PUT":
+		subject := "_:node"
+		if uid != 0 {
+			subject = fmt.Sprintf("%#x", uid)
+		}
+		del, set, err := patchMutations(ctx, subject, patch)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidMutation, err.Error())
+			return
+		}
+		if len(del) > 0 {
+			if _, err := (graphqlRunner{}).Mutate(ctx, &protos.Mutation{Del: del}); err != nil {
+				x.SetStatus(w, x.Error, err.Error())
+				return
+			}
+		}
+		if len(set) > 0 {
+			allocs, err := graphqlRunner{}.Mutate(ctx, &protos.Mutation{Set: set})
+			if err != nil {
+				x.SetStatus(w, x.Error, err.Error())
+				return
+			}
+			if uid == 0 {
+				uid = allocs["node"]
+			}
+		}
+		if uid == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidMutation, "A new node needs at least one value")
+			return
+		}
+	case "DELETE":
+		del := []*protos.NQuad{{
+			Subject:     fmt.Sprintf("%#x", uid),
+			Predicate:   x.Star,
+			ObjectValue: &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: x.Star}},
+		}}
+		if _, err := (graphqlRunner{}).Mutate(ctx, &protos.Mutation{Del: del}); err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		w.Write([]byte(`{"code": "Success", "message": "Done"}`))
+		return
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	// The node is returned as it is after the change.
+	node, err := getNode(ctx, uid, langs)
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	if node == nil {
+		if r.Method == "GET" {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, fmt.Sprintf("Node %#x has no predicates", uid))
+			return
+		}
+		node = []byte(fmt.Sprintf(`{"_uid_": "%#x"}`, uid))
+	}
+	w.Write(node)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runNode(t *testing.T, method, path, body string) (int, string) {
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	nodeHandler(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func TestNodeEndpoints(t *testing.T) {
+	code, res := runNode(t, "POST", "/node",
+		`{"nodetest.name": "Alice", "nodetest.name@fr": "Alicia", "nodetest.age": 29}`)
+	require.Equal(t, http.StatusOK, code, res)
+	var alice map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(res), &alice))
+	uid := alice["_uid_"].(string)
+	require.Equal(t, "Alice", alice["nodetest.name"])
+
+	code, res = runNode(t, "POST", "/node", `{"nodetest.name": "Bob"}`)
+	require.Equal(t, http.StatusOK, code, res)
+	var bob map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(res), &bob))
+	bobUid := bob["_uid_"].(string)
+
+	// Values given replace the ones there, and null deletes them.
+	code, res = runNode(t, "PUT", "/node/"+uid, `{"nodetest.age": null, "nodetest.name": "Al",
+		"nodetest.friend": [{"_uid_": "`+bobUid+`"}]}`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.NotContains(t, res, "errors")
+	code, res = runNode(t, "GET", "/node/"+uid+"?lang=fr", "")
+	require.Equal(t, http.StatusOK, code, res)
+	require.JSONEq(t, `{"_uid_": "`+uid+`", "nodetest.name": "Al", "nodetest.name@fr": "Alicia",
+		"nodetest.friend": [{"_uid_": "`+bobUid+`"}]}`, res)
+
+	code, res = runNode(t, "PUT", "/node/"+uid, `{"nodetest.friend": [{"nodetest.name": "Eve"}]}`)
+	require.Equal(t, http.StatusBadRequest, code, res)
+	code, _ = runNode(t, "PUT", "/node/"+uid, `["nodetest.name"]`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runNode(t, "PUT", "/node", `{"nodetest.name": "Al"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runNode(t, "GET", "/node/alice", "")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runNode(t, "GET", "/node/"+uid+"?lang=fr>", "")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runNode(t, "PUT", "/node/"+uid, `{"nodetest.name@fr": null}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, res = runNode(t, "DELETE", "/node/"+uid, "")
+	require.Equal(t, http.StatusOK, code, res)
+	require.NotContains(t, res, "errors")
+	code, res = runNode(t, "GET", "/node/"+uid, "")
+	require.Equal(t, http.StatusNotFound, code, res)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/schema"
+)
+
+func TestOndelete(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				odtest_name: string .
+				odtest_owns: uid @ondelete(cascade) .
+				odtest_friend: uid @reverse @ondelete(detach) .
+				odtest_parent: uid @reverse @ondelete(restrict) .
+			}
+			set {
+				<0x9601> <odtest_name> "Alice" .
+				<0x9601> <odtest_owns> <0x9602> .
+				<0x9602> <odtest_name> "Car" .
+				<0x9602> <odtest_owns> <0x9603> .
+				<0x9603> <odtest_name> "Wheel" .
+				<0x9604> <odtest_name> "Bob" .
+				<0x9604> <odtest_friend> <0x9601> .
+				<0x9604> <odtest_friend> <0x9605> .
+				<0x9605> <odtest_name> "Carol" .
+				<0x9606> <odtest_name> "Dave" .
+				<0x9606> <odtest_parent> <0x9605> .
+			}
+		}
+	`))
+
+	err := runMutation(`mutation { delete { <0x9605> * * . } }`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"Node 0x9605 can't be deleted, as predicate odtest_parent of node 0x9606 points to it")
+
+	require.NoError(t, runMutation(`mutation { delete { <0x9601> * * . } }`))
+	out, err := runQuery(`{
+		me(func: uid(0x9601, 0x9602, 0x9603, 0x9604)) {
+			odtest_name
+			odtest_friend { _uid_ }
+		}
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [
+		{"odtest_name": "Bob", "odtest_friend": [{"_uid_": "0x9605"}]}
+	]}}`, out)
+
+	// Nodes pointing to a node deleted along with them don't keep it from being deleted.
+	require.NoError(t, runMutation(`
+		mutation {
+			delete {
+				<0x9605> * * .
+				<0x9606> * * .
+			}
+		}
+	`))
+	out, err = runQuery(`{
+		me(func: uid(0x9604, 0x9605, 0x9606)) { odtest_name odtest_friend { _uid_ } }
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [{"odtest_name": "Bob"}]}}`, out)
+}
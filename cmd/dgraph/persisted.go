@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// persistedRequest returns the request for a /query body. With the id of a persisted query, the
+// body is an optional JSON object of the values of its variables.
+func persistedRequest(id string, body []byte) (gql.Request, error) {
+	if id == "" {
+		q, err := dgraph.ResolveQuery(string(body), "")
+		return gql.Request{Str: q, Variables: map[string]string{}, Http: true}, err
+	}
+	vars := make(map[string]string)
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &vars); err != nil {
+			return gql.Request{}, x.Wrapf(err, "While reading the variables of persisted query %q",
+				id)
+		}
+	}
+	q, err := dgraph.ResolveQuery("", id)
+	return gql.Request{Str: q, Variables: vars}, err
+}
+
+// notPersistedOnly wraps h, to refuse its requests when only persisted queries can be run.
+func notPersistedOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dgraph.Config.PersistedOnly {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			x.SetStatus(w, x.ErrorUnauthorized, dgraph.ErrPersistedOnly.Error())
+			return
+		}
+		h(w, r)
+	}
+}
+
+// persistedQueriesHandler lists the persisted queries on GET, or the one of the id parameter,
+// persists the query in the body under id on PUT, and removes it on DELETE.
+func persistedQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeSchema) {
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	switch r.Method {
+	case http.MethodGet:
+		var res interface{} = dgraph.PersistedQueries()
+		if id != "" {
+			q, ok := dgraph.PersistedQuery(id)
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				x.SetStatus(w, x.ErrorNoData, "No persisted query: "+id)
+				return
+			}
+			res = map[string]string{id: q}
+		}
+		js, err := json.Marshal(res)
+		if err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		w.Write(js)
+	case http.MethodPut:
+		defer r.Body.Close()
+		q, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		if err := dgraph.SetPersistedQuery(id, string(q)); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Persisted query "+id)
+	case http.MethodDelete:
+		if _, ok := dgraph.PersistedQuery(id); !ok {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, "No persisted query: "+id)
+			return
+		}
+		if err := dgraph.DeletePersistedQuery(id); err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Deleted persisted query "+id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
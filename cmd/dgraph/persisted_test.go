@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+)
+
+func runPersisted(t *testing.T, id, vars string) string {
+	req, err := http.NewRequest("POST", "/query?persisted="+id, bytes.NewBufferString(vars))
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	queryHandler(rr, req)
+	return rr.Body.String()
+}
+
+func TestPersistedQueries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persisted")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(file string) { dgraph.Config.PersistedQueries = file }(dgraph.Config.PersistedQueries)
+	dgraph.Config.PersistedQueries = filepath.Join(dir, "queries.json")
+
+	_, err = runQuery(`mutation { set { <0x3001> <persisted.name> "Alice" . } }`)
+	require.NoError(t, err)
+	require.Error(t, dgraph.SetPersistedQuery("by id", "{}"))
+	require.NoError(t, dgraph.SetPersistedQuery("byId", `query byId($id: string!) {
+		me(func: uid($id)) { persisted.name }
+	}`))
+	require.NoError(t, dgraph.LoadPersistedQueries())
+	_, ok := dgraph.PersistedQuery("byId")
+	require.True(t, ok)
+
+	res := runPersisted(t, "byId", `{"$id": "0x3001"}`)
+	require.JSONEq(t, `{"data": {"me": [{"persisted.name": "Alice"}]}}`, res)
+	require.Contains(t, runPersisted(t, "byId", `{"$id": 1}`), "ErrorInvalidRequest")
+	require.Contains(t, runPersisted(t, "other", ""), "No persisted query")
+
+	dgraph.Config.PersistedOnly = true
+	defer func() { dgraph.Config.PersistedOnly = false }()
+	_, err = runQuery(`{ me(func: uid(0x3001)) { persisted.name } }`)
+	require.Error(t, err)
+	require.Contains(t, runPersisted(t, "byId", `{"$id": "0x3001"}`), "Alice")
+	rr := httptest.NewRecorder()
+	notPersistedOnly(nodeHandler)(rr, httptest.NewRequest("GET", "/node/0x3001", nil))
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	require.NoError(t, dgraph.DeletePersistedQuery("byId"))
+	require.NoError(t, dgraph.LoadPersistedQueries())
+	require.Empty(t, dgraph.PersistedQueries())
+}
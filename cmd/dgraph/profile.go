@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Profiles are captured on request by admins, on /admin/profile and with the Profile method of
+// the Admin service, instead of serving /debug/pprof to anyone who can reach the http port.
+
+// defaultCPUProfile is how long cpu profiles last if no duration is given.
+const defaultCPUProfile = 30 * time.Second
+
+var (
+	// maxProfileDuration bounds how long profiles captured on request last.
+	maxProfileDuration time.Duration
+	// profiling is 1 while a profile is captured, so that only one is at a time.
+	profiling int32
+
+	errProfiling = x.Errorf("A profile is already being captured")
+)
+
+// checkProfile returns an error if there's no profile of kind.
+func checkProfile(kind string) error {
+	if kind != "cpu" && pprof.Lookup(kind) == nil {
+		return x.Errorf("Invalid profile: %q. Expected cpu, heap, goroutine, block, mutex or "+
+			"threadcreate", kind)
+	}
+	return nil
+}
+
+func waitProfile(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// captureProfile writes the profile of kind to w, in the text format if text is set and kind
+// isn't cpu. The cpu profile, and the sampling of the block and mutex profiles if it's off, last
+// for d, bounded by --max_profile_duration, or until ctx is done.
+func captureProfile(ctx context.Context, w io.Writer, kind string, d time.Duration,
+	text bool) error {
+	if err := checkProfile(kind); err != nil {
+		return err
+	}
+	if kind == "cpu" && d <= 0 {
+		d = defaultCPUProfile
+	}
+	if d > maxProfileDuration {
+		d = maxProfileDuration
+	}
+	if !atomic.CompareAndSwapInt32(&profiling, 0, 1) {
+		return errProfiling
+	}
+	defer atomic.StoreInt32(&profiling, 0)
+
+	switch {
+	case kind == "cpu":
+		if err := pprof.StartCPUProfile(w); err != nil {
+			return err
+		}
+		err := waitProfile(ctx, d)
+		pprof.StopCPUProfile()
+		return err
+	case kind == "block" && blockRate == 0 && d > 0:
+		runtime.SetBlockProfileRate(1)
+		err := waitProfile(ctx, d)
+		runtime.SetBlockProfileRate(0)
+		if err != nil {
+			return err
+		}
+	case kind == "mutex" && d > 0:
+		if prev := runtime.SetMutexProfileFraction(1); prev != 0 {
+			// Sampling was already on.
+			runtime.SetMutexProfileFraction(prev)
+			break
+		}
+		err := waitProfile(ctx, d)
+		runtime.SetMutexProfileFraction(0)
+		if err != nil {
+			return err
+		}
+	}
+	debug := 0
+	if text {
+		debug = 1
+	}
+	return pprof.Lookup(kind).WriteTo(w, debug)
+}
+
+// profileHandler captures the profile of the kind parameter, for the seconds parameter if it's
+// timed, and returns it in the pprof format, or in the text format if text is set to true.
+func profileHandler(w http.ResponseWriter, r *http.Request) {
+	if !handlerInit(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	params := r.URL.Query()
+	kind := params.Get("kind")
+	if err := checkProfile(kind); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	var seconds int
+	if s := params.Get("seconds"); s != "" {
+		var err error
+		if seconds, err = strconv.Atoi(s); err != nil || seconds < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "Invalid seconds: "+s)
+			return
+		}
+	}
+	text := params.Get("text") == "true"
+
+	var buf bytes.Buffer
+	err := captureProfile(r.Context(), &buf, kind, time.Duration(seconds)*time.Second, text)
+	switch {
+	case err == errProfiling:
+		w.WriteHeader(http.StatusConflict)
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	if text {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition",
+			`attachment; filename="`+kind+`.pprof"`)
+	}
+	w.Write(buf.Bytes())
+}
+
+func (s *adminServer) Profile(ctx context.Context, req *protos.ProfileRequest) (*protos.Profile,
+	error) {
+	if err := checkProfile(req.Kind); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	var buf bytes.Buffer
+	err := captureProfile(ctx, &buf, req.Kind, time.Duration(req.Seconds)*time.Second, req.Text)
+	if err == errProfiling {
+		return nil, grpc.Errorf(codes.ResourceExhausted, "%v", err)
+	} else if err != nil {
+		return nil, err
+	}
+	return &protos.Profile{Data: buf.Bytes()}, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestCaptureProfile(t *testing.T) {
+	defer func(d time.Duration) { maxProfileDuration = d }(maxProfileDuration)
+	maxProfileDuration = 500 * time.Millisecond
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	require.NoError(t, captureProfile(ctx, &buf, "goroutine", 0, true))
+	require.True(t, strings.HasPrefix(buf.String(), "goroutine profile:"), buf.String())
+
+	// Cpu profiles are bounded by --max_profile_duration.
+	buf.Reset()
+	start := time.Now()
+	require.NoError(t, captureProfile(ctx, &buf, "cpu", time.Hour, false))
+	require.True(t, time.Since(start) < 10*time.Second)
+	require.NotEmpty(t, buf.Bytes())
+
+	// Only one profile is captured at a time.
+	done := make(chan error)
+	go func() { done <- captureProfile(ctx, &bytes.Buffer{}, "block", time.Second, false) }()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, errProfiling, captureProfile(ctx, &buf, "heap", 0, false))
+	require.NoError(t, <-done)
+
+	s := &adminServer{}
+	_, err := s.Profile(ctx, &protos.ProfileRequest{Kind: "disk"})
+	require.Equal(t, codes.InvalidArgument, grpc.Code(err))
+	p, err := s.Profile(ctx, &protos.ProfileRequest{Kind: "heap"})
+	require.NoError(t, err)
+	require.NotEmpty(t, p.Data)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The queries and jobs running for longer than --progress_threshold are listed by /admin/progress.
+// With the id parameter, the id of the request which started the run, the state of that run is
+// returned, or streamed as server-sent events with stream=true until it finishes:
+//   data: {"id":"...","kind":"query","phase":"processing","tasks":12,"tasks_done":9,...}
+// A DELETE with the id cancels the run.
+
+const progressInterval = time.Second
+
+func progressHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	id := r.URL.Query().Get("id")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		if !worker.CancelProgress(id) {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, "No long running query or job with id "+id)
+			return
+		}
+		x.SetStatus(w, x.Success, "Canceled "+id)
+		return
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	if id == "" {
+		writeJSON(w, worker.RunningProgress())
+		return
+	}
+	p, ok := worker.LookupProgress(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "No long running query or job with id "+id)
+		return
+	}
+	if r.URL.Query().Get("stream") != "true" {
+		writeJSON(w, p.Report())
+		return
+	}
+	streamProgress(w, r, p)
+}
+
+// streamProgress sends the state of p every progressInterval, and once it finishes.
+func streamProgress(w http.ResponseWriter, r *http.Request, p *worker.Progress) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		x.SetStatus(w, x.Error, "Streaming isn't supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	tick := time.NewTicker(progressInterval)
+	defer tick.Stop()
+	for {
+		report := p.Report()
+		b, err := json.Marshal(report)
+		x.Check(err)
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return
+		}
+		flusher.Flush()
+		if report.Finished {
+			return
+		}
+
+		select {
+		case <-tick.C:
+		case <-p.Finished():
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
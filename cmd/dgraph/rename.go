@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// renameHandler returns the renames in progress in the groups served here on GET. On POST, it
+// renames the predicate of the from parameter to that of the to parameter. The edges are copied
+// in the background, after the response, and the predicate can be read under both names until
+// they all are. The copy can be followed and canceled by the id of the request on
+// /admin/progress, and a rename which didn't finish is resumed by posting it again.
+func renameHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeSchema) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		rs := worker.Renames()
+		if rs == nil {
+			rs = []worker.RenameState{}
+		}
+		writeJSON(w, rs)
+		return
+	case http.MethodPost:
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if err := worker.StartRename(r.Context(), from, to); err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	ctx, progress := worker.StartProgress(context.Background(), worker.RunRename,
+		x.RequestId(r.Context()))
+	go func() {
+		err := worker.CopyRenamed(ctx, from, to, progress)
+		progress.Finish(err)
+		if err != nil {
+			log.Printf("Error while renaming %s to %s: %v\n", from, to, err)
+		}
+	}()
+	x.SetStatus(w, x.Success, fmt.Sprintf("Rename of %s to %s started.", from, to))
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/worker"
+)
+
+func runRename(t *testing.T, method, params string) string {
+	req := httptest.NewRequest(method, "/admin/rename"+params, strings.NewReader(""))
+	req.RemoteAddr = "127.0.0.1:8080"
+	rr := httptest.NewRecorder()
+	renameHandler(rr, req)
+	return rr.Body.String()
+}
+
+func waitForRenames(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if runRename(t, "GET", "") == "[]" {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("Renames didn't finish")
+}
+
+func TestRename(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				rentest_name: string @index(exact) .
+				rentest_friend: uid @reverse .
+			}
+			set {
+				<0x9201> <rentest_name> "Alice" .
+				<0x9201> <rentest_name> "Alicia"@es .
+				<0x9202> <rentest_name> "Bob" .
+				<0x9201> <rentest_friend> <0x9202> (since=2006) .
+			}
+		}
+	`))
+
+	require.Contains(t, runRename(t, "POST", "?from=rentest_age&to=rentest_years"),
+		"No predicate rentest_age to rename")
+	require.Contains(t, runRename(t, "POST", "?from=rentest_name&to=_predicate_"),
+		"Predicate _predicate_ can't be renamed")
+	require.Contains(t, runRename(t, "POST", "?from=rentest_name&to=rentest_friend"),
+		"Predicate rentest_friend already has data")
+
+	require.Contains(t, runRename(t, "POST", "?from=rentest_friend&to=rentest_knows"),
+		"Rename of rentest_friend to rentest_knows started.")
+	waitForRenames(t)
+	out, err := runQuery(`{
+		me(func: uid(0x9201)) { rentest_knows @facets { ~rentest_knows { _uid_ } } rentest_friend }
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {"me": [{"rentest_knows": [{"@facets": {"_": {"since": 2006}},
+		"~rentest_knows": [{"_uid_": "0x9201"}]}]}]}}`, out)
+
+	// Until the edges are copied, both names are read from the old one, and written under both.
+	require.NoError(t, worker.StartRename(context.Background(), "rentest_name", "rentest_fullname"))
+	require.Contains(t, runRename(t, "GET", ""), `"from":"rentest_name","to":"rentest_fullname"`)
+	require.Contains(t, runRename(t, "POST", "?from=rentest_name&to=rentest_fullname"),
+		"Predicate rentest_name is already being copied to its new name")
+	require.Error(t, runMutation(`mutation { schema { rentest_fullname: string . } }`))
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9203> <rentest_fullname> "Carol" .
+			}
+		}
+	`))
+	query := `{
+		me(func: eq(rentest_fullname, "Bob")) { rentest_fullname }
+		you(func: uid(0x9201, 0x9203)) { rentest_name rentest_fullname rentest_fullname@es }
+	}`
+	out, err = runQuery(query)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {
+		"me": [{"rentest_fullname": "Bob"}],
+		"you": [
+			{"rentest_name": "Alice", "rentest_fullname": "Alice", "rentest_fullname@es": "Alicia"},
+			{"rentest_name": "Carol", "rentest_fullname": "Carol"}
+		]
+	}}`, out)
+
+	require.NoError(t, worker.CopyRenamed(context.Background(), "rentest_name", "rentest_fullname",
+		nil))
+	require.Equal(t, "[]", runRename(t, "GET", ""))
+	out, err = runQuery(query)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {
+		"me": [{"rentest_fullname": "Bob"}],
+		"you": [
+			{"rentest_fullname": "Alice", "rentest_fullname@es": "Alicia"},
+			{"rentest_fullname": "Carol"}
+		]
+	}}`, out)
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/sparql"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// sparqlSchema returns the schema of the predicates of q, for its translation.
+func sparqlSchema(ctx context.Context, q *sparql.Query) (map[string]sparql.Predicate, error) {
+	schema := make(map[string]sparql.Predicate)
+	preds := q.Predicates()
+	nodes, err := worker.GetSchemaOverNetwork(ctx, &protos.SchemaRequest{
+		Predicates: preds,
+		Fields:     []string{"type", "index", "tokenizer"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		p := sparql.Predicate{Uid: n.Type == "uid", Indexed: n.Index}
+		for _, name := range n.Tokenizer {
+			if t, ok := tok.GetTokenizer(name); ok && t.IsSortable() {
+				p.Sortable = true
+			}
+		}
+		schema[n.Predicate] = p
+	}
+	return schema, nil
+}
+
+// sparqlQuery returns the SPARQL query of r, sent as in the SPARQL protocol: in the query
+// parameter of GET requests, or in the body of POST requests, form encoded or as is.
+func sparqlQuery(r *http.Request) (string, error) {
+	if r.Method == "GET" {
+		return r.URL.Query().Get("query"), nil
+	}
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return "", err
+		}
+		return r.PostForm.Get("query"), nil
+	}
+	defer r.Body.Close()
+	q, err := ioutil.ReadAll(r.Body)
+	return string(q), err
+}
+
+// sparqlHandler runs SPARQL SELECT queries, translated to queries of Dgraph, and returns their
+// results in the SPARQL JSON results format.
+func sparqlHandler(w http.ResponseWriter, r *http.Request) {
+	addCorsHeaders(w)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Content-Type", "application/json")
+
+	if !dgraph.Config.SPARQL {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "SPARQL queries (--sparql) aren't enabled")
+		return
+	}
+	if err := x.HealthCheck(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		x.SetStatus(w, x.ErrorServiceUnavailable, err.Error())
+		return
+	}
+
+	x.PendingQueries.Add(1)
+	x.NumQueries.Add(1)
+	defer x.PendingQueries.Add(-1)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != "GET" && r.Method != "POST" {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+		return
+	}
+
+	q, err := sparqlQuery(r)
+	if err != nil {
+		x.SetStatus(w, x.ErrorInvalidRequest, "Error while reading query")
+		return
+	}
+	parsed, err := sparql.Parse(q)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), "debug", r.URL.Query().Get("debug"))
+	ctx = context.WithValue(ctx, "mutation_allowed", false)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	schema, err := sparqlSchema(ctx, parsed)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	tr, err := parsed.Translate(sparql.Options{Schema: schema})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	if dgraph.Config.DebugMode {
+		x.Printf("SPARQL query translated to: %s\n", tr.Query)
+	}
+
+	js, err := graphqlRunner{}.Query(ctx, tr.Query, tr.Vars)
+	if err == nil {
+		js, err = unwrap(js)
+	}
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	res, err := tr.Results(js)
+	if err != nil {
+		x.SetStatusWithData(w, x.Error, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/sparql-results+json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		x.Printf("Error while writing SPARQL response: %v\n", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+)
+
+func runSPARQL(t *testing.T, q string) (int, string) {
+	req, err := http.NewRequest("GET", "/sparql?query="+url.QueryEscape(q), nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	sparqlHandler(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func TestSPARQL(t *testing.T) {
+	code, _ := runSPARQL(t, `SELECT * { ?s <name> ?n }`)
+	require.Equal(t, http.StatusNotFound, code)
+
+	dgraph.Config.SPARQL = true
+	defer func() { dgraph.Config.SPARQL = false }()
+	require.NoError(t, runMutation(`mutation {
+		schema {
+			<http://example.org/name>: string @index(exact) .
+			<http://example.org/age>: int @index(int) .
+		}
+		set {
+			<0x6101> <http://example.org/name> "Alice" .
+			<0x6101> <http://example.org/knows> <0x6102> .
+			<0x6101> <http://example.org/knows> <0x6103> .
+			<0x6102> <http://example.org/name> "Bob" .
+			<0x6102> <http://example.org/age> "25" .
+			<0x6103> <http://example.org/name> "Carol" .
+			<0x6103> <http://example.org/age> "35" .
+		}
+	}`))
+
+	code, res := runSPARQL(t, `PREFIX ex: <http://example.org/>
+		SELECT ?fn ?age WHERE {
+			?p ex:name "Alice" ; ex:knows ?f .
+			?f ex:name ?fn .
+			OPTIONAL { ?f ex:age ?age }
+			FILTER (?age > 30)
+		}`)
+	require.Equal(t, http.StatusOK, code, res)
+	require.JSONEq(t, `{"head": {"vars": ["fn", "age"]}, "results": {"bindings": [
+		{"fn": {"type": "literal", "value": "Carol"},
+			"age": {"type": "literal", "value": "35",
+				"datatype": "http://www.w3.org/2001/XMLSchema#integer"}}
+	]}}`, res)
+
+	code, _ = runSPARQL(t, `SELECT * { ?a <http://example.org/name> ?n . ?b <name> ?m }`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = runSPARQL(t, `CONSTRUCT { ?s <name> ?n } WHERE { ?s <name> ?n }`)
+	require.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/schema"
+)
+
+func TestTypes(t *testing.T) {
+	schema.ParseBytes([]byte(""), 1)
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				kind: [string] @index(exact) .
+				typetest_name: string .
+				typetest_age: int .
+				type TypetestPerson {
+					typetest_name, typetest_age
+				}
+				type TypetestFilm { typetest_name }
+			}
+			set {
+				<0x9301> <kind> "TypetestPerson" .
+				<0x9301> <typetest_name> "Alice" .
+				<0x9301> <typetest_age> "30" .
+				<0x9302> <kind> "TypetestFilm" .
+				<0x9302> <typetest_name> "Up" .
+			}
+		}
+	`))
+
+	// Nodes of declared types only have the fields of their types.
+	err := runMutation(`mutation { set { <0x9302> <typetest_age> "9" . } }`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"Predicate typetest_age isn't a field of type TypetestFilm of node 0x9302")
+	err = runMutation(`
+		mutation {
+			set {
+				<0x9303> <kind> "TypetestFilm" .
+				<0x9303> <typetest_age> "9" .
+			}
+		}
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "of node 0x9303")
+	// Nodes without a declared type can have any predicate.
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9304> <kind> "TypetestBook" .
+				<0x9304> <typetest_age> "9" .
+				<0x9305> <typetest_age> "3" .
+			}
+		}
+	`))
+
+	out, err := runQuery(`{
+		people(func: type(TypetestPerson)) { expand(TypetestPerson) }
+		films(func: uid(0x9301, 0x9302, 0x9304)) @filter(type(TypetestFilm)) { typetest_name }
+	}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data": {
+		"people": [{"typetest_name": "Alice", "typetest_age": 30}],
+		"films": [{"typetest_name": "Up"}]
+	}}`, out)
+
+	_, err = runQuery(`{ me(func: uid(0x9301)) { expand(TypetestShow) } }`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Type TypetestShow isn't declared")
+
+	// Types are declared again to change their fields.
+	require.NoError(t, runMutation(`
+		mutation {
+			schema {
+				type TypetestFilm { typetest_name, typetest_age }
+			}
+		}
+	`))
+	require.NoError(t, runMutation(`
+		mutation {
+			set {
+				<0x9302> <typetest_age> "9" .
+			}
+		}
+	`))
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// apiVersionHeader gives the API version of HTTP requests, which can also be given in the
+// api_version parameter. Responses have the version they were run with in it.
+const apiVersionHeader = "X-Dgraph-Api-Version"
+
+// requestedAPIVersion returns the API version asked for by r, or 0 if it doesn't ask for one.
+func requestedAPIVersion(r *http.Request) (uint32, error) {
+	s := r.URL.Query().Get("api_version")
+	if s == "" {
+		s = r.Header.Get(apiVersionHeader)
+	}
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, x.Errorf("Invalid API version: %q", s)
+	}
+	return uint32(v), nil
+}
+
+// httpAPIVersion returns the API version to run r with, and sets it in the response headers.
+func httpAPIVersion(w http.ResponseWriter, r *http.Request) (uint32, error) {
+	v, err := requestedAPIVersion(r)
+	if err != nil {
+		return 0, err
+	}
+	if v, err = dgraph.CheckAPIVersion(v); err != nil {
+		return 0, err
+	}
+	w.Header().Set(apiVersionHeader, strconv.FormatUint(uint64(v), 10))
+	return v, nil
+}
+
+// warnDeprecations adds a Warning header to the response for each of deps, so that they're seen
+// by clients which don't read the extensions of responses.
+func warnDeprecations(w http.ResponseWriter, deps []*protos.Deprecation) {
+	for _, d := range deps {
+		w.Header().Add("Warning", "299 dgraph "+strconv.Quote(d.Message))
+	}
+}
+
+// versionHandler returns the version of the server, with the API version negotiated with a
+// client supporting up to the one asked for.
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	v, err := requestedAPIVersion(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	version, err := dgraph.NegotiateAPIVersion(v)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+		return
+	}
+	js, err := json.Marshal(version)
+	if err != nil {
+		x.SetStatus(w, x.Error, err.Error())
+		return
+	}
+	w.Write(js)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func runWithAPIVersion(t *testing.T, version, q string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("POST", "/query", bytes.NewBufferString(q))
+	require.NoError(t, err)
+	if version != "" {
+		req.Header.Set(apiVersionHeader, version)
+	}
+	rr := httptest.NewRecorder()
+	queryHandler(rr, req)
+	return rr
+}
+
+func TestAPIVersion(t *testing.T) {
+	m := `mutation { set { <0x5001> <version.name> "Alice" . } }`
+	rr := runWithAPIVersion(t, "", m)
+	require.Equal(t, "1", rr.Header().Get(apiVersionHeader))
+	require.Contains(t, rr.Header().Get("Warning"), "299 dgraph")
+	var res struct {
+		Data       map[string]interface{}
+		Extensions struct{ Deprecations []*protos.Deprecation }
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, "Success", res.Data["code"])
+	require.Len(t, res.Extensions.Deprecations, 1)
+	require.Equal(t, "mutation_status", res.Extensions.Deprecations[0].Feature)
+
+	rr = runWithAPIVersion(t, "2", m)
+	require.Equal(t, "2", rr.Header().Get(apiVersionHeader))
+	require.Empty(t, rr.Header().Get("Warning"))
+	require.JSONEq(t, `{"data": {"uids": {}}}`, rr.Body.String())
+
+	rr = runWithAPIVersion(t, "3", m)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Unsupported API version")
+
+	rr = httptest.NewRecorder()
+	versionHandler(rr, httptest.NewRequest("GET", "/version?api_version=7", nil))
+	var v protos.Version
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v))
+	require.EqualValues(t, 2, v.ApiVersion)
+	require.EqualValues(t, 1, v.MinApiVersion)
+	require.Contains(t, v.Capabilities, "persisted-queries")
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// webhooksHandler lists the webhooks on GET, or the one of the id parameter, registers the
+// webhook in the body under id on PUT, and removes it on DELETE.
+func webhooksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !adminAllowed(w, r, dgraph.ScopeAdmin) {
+		return
+	}
+	if dgraph.Config.Webhooks == "" {
+		w.WriteHeader(http.StatusNotFound)
+		x.SetStatus(w, x.ErrorNoData, "Webhooks (--webhooks) aren't enabled")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	webhooks := dgraph.Webhooks()
+	switch r.Method {
+	case http.MethodGet:
+		var res interface{} = webhooks
+		if id != "" {
+			h, ok := webhooks[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				x.SetStatus(w, x.ErrorNoData, "No webhook: "+id)
+				return
+			}
+			res = map[string]worker.Webhook{id: h}
+		}
+		js, err := json.Marshal(res)
+		if err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		w.Write(js)
+	case http.MethodPut:
+		defer r.Body.Close()
+		var h worker.Webhook
+		if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, "While reading webhook: "+err.Error())
+			return
+		}
+		if err := dgraph.SetWebhook(id, h); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			x.SetStatus(w, x.ErrorInvalidRequest, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Registered webhook "+id)
+	case http.MethodDelete:
+		if _, ok := webhooks[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			x.SetStatus(w, x.ErrorNoData, "No webhook: "+id)
+			return
+		}
+		if err := dgraph.DeleteWebhook(id); err != nil {
+			x.SetStatus(w, x.Error, err.Error())
+			return
+		}
+		x.SetStatus(w, x.Success, "Deleted webhook "+id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		x.SetStatus(w, x.ErrorInvalidMethod, "Invalid method")
+	}
+}
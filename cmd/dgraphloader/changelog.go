@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var (
+	changelogDir   = flag.String("changelog", "", "Backup directory of a group with a changelog to replay")
+	changelogAfter = flag.Uint64("changelog_after", 0, "Replay changelog entries after this index")
+	changelogUntil = flag.String("changelog_until", "", "Replay changelog entries up to this time")
+)
+
+type segment struct {
+	path  string
+	index uint64
+}
+
+// changelogSegments returns the changelog segments in dir, in the order of their first entry.
+// Segments copied to an archive can be compressed and encrypted.
+func changelogSegments(dir string) ([]segment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "changelog-*-*.rdf"))
+	if err != nil {
+		return nil, err
+	}
+	archived, err := filepath.Glob(filepath.Join(dir, "changelog-*-*.rdf.*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range archived {
+		if artifact.HasExt(p) {
+			paths = append(paths, p)
+		}
+	}
+	var segs []segment
+	for _, p := range paths {
+		name := strings.TrimSuffix(artifact.TrimExt(filepath.Base(p)), ".rdf")
+		idx, err := strconv.ParseUint(name[strings.LastIndex(name, "-")+1:], 10, 64)
+		if err != nil {
+			return nil, x.Wrapf(err, "Invalid changelog segment name: %v", p)
+		}
+		segs = append(segs, segment{path: p, index: idx})
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].index < segs[j].index })
+	return segs, nil
+}
+
+type changelogEntry struct {
+	index  uint64
+	schema []string
+	req    *client.Req
+	empty  bool
+}
+
+func (e *changelogEntry) run(ctx context.Context, dgraphClient *client.Dgraph) error {
+	for _, s := range e.schema {
+		if err := dgraphClient.SetSchemaBlocking(ctx, s); err != nil {
+			return err
+		}
+	}
+	if e.empty {
+		return nil
+	}
+	_, err := dgraphClient.Run(ctx, e.req)
+	return err
+}
+
+func parseChangelogHeader(line string) (uint64, time.Time, error) {
+	var idx uint64
+	var ts string
+	if _, err := fmt.Sscanf(line, "# %d %s", &idx, &ts); err != nil {
+		return 0, time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	return idx, t, err
+}
+
+func (e *changelogEntry) add(line string, dgraphClient *client.Dgraph) error {
+	if strings.HasPrefix(line, "schema ") {
+		e.schema = append(e.schema, line[len("schema "):])
+		return nil
+	}
+	if len(line) < 2 || (line[0] != '+' && line[0] != '-') {
+		return x.Errorf("Invalid changelog line: %q", line)
+	}
+	nq, err := rdf.Parse(line[2:])
+	if err != nil {
+		return err
+	}
+	if nq.Subject != x.Star {
+		if nq.Subject, err = Node(nq.Subject, dgraphClient); err != nil {
+			return err
+		}
+	}
+	if len(nq.ObjectId) > 0 {
+		if nq.ObjectId, err = Node(nq.ObjectId, dgraphClient); err != nil {
+			return err
+		}
+	}
+	e.empty = false
+	if line[0] == '-' {
+		return e.req.Delete(client.NewEdge(nq))
+	}
+	return e.req.Set(client.NewEdge(nq))
+}
+
+// replayChangelog runs the mutations of the changelog of a group in dir after index after, which
+// were applied until the time until, one entry at a time in order. The index of the last entry
+// replayed is checkpointed, and replaying the same changelog again continues after it.
+func replayChangelog(ctx context.Context, dir string, after uint64, until time.Time,
+	dgraphClient *client.Dgraph) error {
+	segs, err := changelogSegments(dir)
+	if err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	mark := "changelog-" + absDir
+	checkpoint, err := dgraphClient.Checkpoint(mark)
+	if err != nil {
+		return err
+	}
+	if checkpoint > after {
+		fmt.Printf("\nFound checkpoint for changelog: %s. Replaying after index %d.\n", dir,
+			checkpoint)
+		after = checkpoint
+	}
+	// Entries can be found in multiple segments, if written after a restart or by another
+	// server. Only the first occurrence of an index is replayed.
+	last := after
+	var count int
+	defer func() {
+		if last > after {
+			if err := dgraphClient.SaveCheckpoint(mark, last); err != nil {
+				fmt.Printf("Error while writing checkpoint for changelog: %v\n", err)
+			}
+		}
+	}()
+	for _, seg := range segs {
+		done, err := replaySegment(ctx, seg.path, &last, until, dgraphClient, &count)
+		if err == context.Canceled {
+			return err
+		} else if err != nil {
+			return x.Wrapf(err, "While replaying changelog segment: %v", seg.path)
+		}
+		if done {
+			break
+		}
+	}
+	fmt.Printf("\nReplayed %d changelog entries, up to index %d\n", count, last)
+	return nil
+}
+
+func replaySegment(ctx context.Context, fpath string, last *uint64, until time.Time,
+	dgraphClient *client.Dgraph, count *int) (bool, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	rd, err := artifact.NewReader(f, fpath)
+	if err != nil {
+		return false, err
+	}
+	r := bufio.NewReader(rd)
+
+	var entry *changelogEntry
+	flush := func() error {
+		if entry == nil {
+			return nil
+		}
+		e := entry
+		entry = nil
+		if err := e.run(ctx, dgraphClient); err != nil {
+			return err
+		}
+		*last = e.index
+		*count++
+		return nil
+	}
+
+	var buf bytes.Buffer
+	skip := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		err := readLine(r, &buf)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return false, err
+		}
+		line := buf.String()
+		buf.Reset()
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] == '#' {
+			if err := flush(); err != nil {
+				return false, err
+			}
+			idx, t, err := parseChangelogHeader(line)
+			if err != nil {
+				return false, x.Wrapf(err, "Invalid changelog header: %q", line)
+			}
+			if !until.IsZero() && t.After(until) {
+				return true, nil
+			}
+			skip = idx <= *last
+			if !skip {
+				entry = &changelogEntry{index: idx, req: new(client.Req), empty: true}
+			}
+			continue
+		}
+		if skip {
+			continue
+		}
+		if entry == nil {
+			return false, x.Errorf("Changelog line without a header: %q", line)
+		}
+		if err := entry.add(line, dgraphClient); err != nil {
+			return false, err
+		}
+	}
+	return false, flush()
+}
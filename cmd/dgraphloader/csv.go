@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var (
+	csvFiles   = flag.String("csv", "", "Location of CSV or TSV files to load")
+	csvMapFile = flag.String("csv_map", "", "Location of the mapping of CSV columns to predicates")
+)
+
+// csvMapping says how the rows of CSV files are turned into edges. Every row is a node, keyed by
+// the subject column, and every column mapping adds an edge of the node, like
+//   {
+//     "subject": {"column": "id", "prefix": "_:person"},
+//     "columns": [
+//       {"column": "name", "predicate": "name", "type": "string", "lang": "en"},
+//       {"column": "age", "predicate": "age", "type": "int"},
+//       {"column": "friend", "predicate": "friend", "type": "uid", "prefix": "_:person",
+//        "facets": {"since": "friends_since"}},
+//       {"lon": "longitude", "lat": "latitude", "predicate": "loc"}
+//     ]
+//   }
+// Columns are named by the header row of the files, or by their index if there's none.
+type csvMapping struct {
+	Delimiter string `json:"delimiter"` // Defaults to a tab for .tsv files, and a comma otherwise.
+	NoHeader  bool   `json:"no_header"`
+	Subject   struct {
+		Column string `json:"column"`
+		// Prepended to the values of the column, which are then taken like the subjects of
+		// N-Quads: as a uid, a blank node if starting with _:, or an XID.
+		Prefix string `json:"prefix"`
+	} `json:"subject"`
+	Columns []csvColumn `json:"columns"`
+}
+
+type csvColumn struct {
+	Column    string `json:"column"`
+	Predicate string `json:"predicate"`
+	// Name of a scalar type, or uid for an edge to the node keyed by the value, with prefix.
+	Type   string `json:"type"`
+	Prefix string `json:"prefix"`
+	Lang   string `json:"lang"`
+	// Columns with the coordinates of a point, instead of column.
+	Lon string `json:"lon"`
+	Lat string `json:"lat"`
+	// Facets of the edge, from their keys to the columns with their values.
+	Facets map[string]string `json:"facets"`
+}
+
+func readCSVMapping(file string) (*csvMapping, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var m csvMapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, x.Wrapf(err, "While parsing CSV mapping: %v", file)
+	}
+	if m.Subject.Column == "" {
+		return nil, x.Errorf("CSV mapping has no subject column")
+	}
+	for _, c := range m.Columns {
+		if c.Predicate == "" {
+			return nil, x.Errorf("CSV mapping of column %q has no predicate", c.Column)
+		}
+		if (c.Lon == "") != (c.Lat == "") {
+			return nil, x.Errorf("CSV mapping of %q needs both lon and lat columns", c.Predicate)
+		}
+		if c.Lon == "" && c.Column == "" {
+			return nil, x.Errorf("CSV mapping of %q has no column", c.Predicate)
+		}
+		if c.Type != "" && c.Type != "uid" {
+			if _, ok := types.TypeForName(c.Type); !ok {
+				return nil, x.Errorf("CSV mapping of %q has invalid type: %q", c.Predicate, c.Type)
+			}
+		}
+	}
+	return &m, nil
+}
+
+// csvRow maps the columns of a mapping to the fields of a row.
+type csvRow struct {
+	index    map[string]int
+	fields   []string
+	noHeader bool
+}
+
+// newRow returns the row the records of cr are read into, with the columns named by the header
+// read from cr, or by their index if there's none.
+func (m *csvMapping) newRow(cr *csv.Reader) (*csvRow, error) {
+	r := &csvRow{index: make(map[string]int), noHeader: m.NoHeader}
+	if !m.NoHeader {
+		header, err := cr.Read()
+		if err != nil {
+			return nil, x.Wrapf(err, "While reading header")
+		}
+		for i, name := range header {
+			r.index[strings.TrimSpace(name)] = i
+		}
+	}
+	return r, nil
+}
+
+// read reads the next record of cr into the row.
+func (r *csvRow) read(cr *csv.Reader) error {
+	fields, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	if r.noHeader {
+		for i := len(r.index); i < len(fields); i++ {
+			r.index[strconv.Itoa(i)] = i
+		}
+	}
+	r.fields = fields
+	return nil
+}
+
+func (r *csvRow) get(column string) (string, error) {
+	i, ok := r.index[column]
+	if !ok {
+		return "", x.Errorf("Unknown CSV column: %q", column)
+	}
+	if i >= len(r.fields) {
+		return "", nil
+	}
+	return strings.TrimSpace(r.fields[i]), nil
+}
+
+// objectValue converts val, as read from a file, to a value of type tid.
+func objectValue(tid types.TypeID, val string) (*protos.Value, error) {
+	if tid == types.DefaultID || tid == types.StringID {
+		return types.ObjectValue(tid, val)
+	}
+	src := types.Val{Tid: types.StringID, Value: []byte(val)}
+	dst, err := types.Convert(src, tid)
+	if err != nil {
+		return nil, err
+	}
+	return types.ObjectValue(tid, dst.Value)
+}
+
+// facetValue returns val the way facets are sent to the server, which types them: strings are
+// quoted, and anything else is taken as a number, a bool or a time where it parses as one.
+func facetValue(val string, str bool) string {
+	if !str {
+		if _, err := facets.FacetFor("", val); err == nil {
+			return val
+		}
+	}
+	return `"` + val + `"`
+}
+
+// toEdges returns the edges of the node of row r, with node resolving the keys of nodes.
+func (m *csvMapping) toEdges(r *csvRow, node func(string) (string, error)) ([]client.Edge, error) {
+	key, err := r.get(m.Subject.Column)
+	if err != nil || key == "" {
+		return nil, err
+	}
+	subject, err := node(m.Subject.Prefix + key)
+	if err != nil {
+		return nil, err
+	}
+	var edges []client.Edge
+	for _, c := range m.Columns {
+		nq := protos.NQuad{Subject: subject, Predicate: c.Predicate, Lang: c.Lang}
+		if c.Lon != "" {
+			lon, err := r.get(c.Lon)
+			if err != nil {
+				return nil, err
+			}
+			lat, err := r.get(c.Lat)
+			if err != nil {
+				return nil, err
+			}
+			if lon == "" || lat == "" {
+				continue
+			}
+			var coords [2]float64
+			for i, s := range []string{lon, lat} {
+				if coords[i], err = strconv.ParseFloat(s, 64); err != nil {
+					return nil, x.Wrapf(err, "Invalid coordinate for %q", c.Predicate)
+				}
+			}
+			p, err := geom.NewPoint(geom.XY).SetCoords(geom.Coord{coords[0], coords[1]})
+			if err != nil {
+				return nil, err
+			}
+			if nq.ObjectValue, err = types.ObjectValue(types.GeoID, p); err != nil {
+				return nil, err
+			}
+			nq.ObjectType = int32(types.GeoID)
+		} else {
+			val, err := r.get(c.Column)
+			if err != nil {
+				return nil, err
+			}
+			if val == "" {
+				continue
+			}
+			if c.Type == "uid" {
+				if nq.ObjectId, err = node(c.Prefix + val); err != nil {
+					return nil, err
+				}
+			} else {
+				tid := types.DefaultID
+				if c.Type != "" {
+					tid, _ = types.TypeForName(c.Type)
+				}
+				if nq.ObjectValue, err = objectValue(tid, val); err != nil {
+					return nil, x.Wrapf(err, "Invalid value for %q", c.Predicate)
+				}
+				nq.ObjectType = int32(tid)
+			}
+		}
+		for key, col := range c.Facets {
+			val, err := r.get(col)
+			if err != nil {
+				return nil, err
+			}
+			if val != "" {
+				nq.Facets = append(nq.Facets, &protos.Facet{Key: key, Val: facetValue(val, false)})
+			}
+		}
+		edges = append(edges, client.NewEdge(nq))
+	}
+	return edges, nil
+}
+
+func (m *csvMapping) newReader(file string, r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	switch {
+	case m.Delimiter != "":
+		cr.Comma = []rune(m.Delimiter)[0]
+	case strings.HasSuffix(artifact.TrimExt(strings.ToLower(file)), ".tsv"):
+		cr.Comma = '\t'
+		cr.LazyQuotes = true
+	}
+	return cr
+}
+
+// processCSVFile sends mutations for the rows of a CSV file, mapped to edges by m.
+func processCSVFile(ctx context.Context, file string, m *csvMapping,
+	dgraphClient *client.Dgraph) error {
+	fmt.Printf("\nProcessing %s\n", file)
+	fr, f := fileReader(file)
+	defer f.Close()
+	cr := m.newReader(file, fr)
+
+	absPath, err := filepath.Abs(file)
+	x.Check(err)
+	checkpoint, err := dgraphClient.Checkpoint(absPath)
+	x.Check(err)
+	if checkpoint != 0 {
+		fmt.Printf("\nFound checkpoint for: %s. Skipping: %v rows.\n", file, checkpoint)
+	}
+
+	row, err := m.newRow(cr)
+	if err != nil {
+		return x.Wrapf(err, "While reading %v", file)
+	}
+
+	node := func(key string) (string, error) {
+		return Node(key, dgraphClient)
+	}
+	var line uint64
+	r := new(client.Req)
+	var batchSize int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := row.read(cr); err == io.EOF {
+			break
+		} else if err != nil {
+			return x.Wrapf(err, "While reading %v", file)
+		}
+		line++
+		if line <= checkpoint {
+			continue
+		}
+		edges, err := m.toEdges(row, node)
+		if err != nil {
+			return x.Wrapf(err, "While mapping row %d of %v", line, file)
+		}
+		for _, e := range edges {
+			if err := r.Set(e); err != nil {
+				return err
+			}
+			batchSize++
+		}
+		if batchSize >= *numRdf {
+			if err = dgraphClient.BatchSetWithMark(r, absPath, line); err != nil {
+				return err
+			}
+			batchSize = 0
+			r = new(client.Req)
+		}
+	}
+	if batchSize > 0 {
+		return dgraphClient.BatchSetWithMark(r, absPath, line)
+	}
+	return nil
+}
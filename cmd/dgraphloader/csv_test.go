@@ -0,0 +1,184 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+func testCSVMapping(t *testing.T, mapping string) (*csvMapping, error) {
+	f, err := ioutil.TempFile("", "csv_map")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(mapping)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return readCSVMapping(f.Name())
+}
+
+// csvNQuads returns the N-Quads of the rows of data, read like file, with the keys of nodes as
+// their subjects and objects.
+func csvNQuads(t *testing.T, m *csvMapping, file, data string) ([]*protos.NQuad, error) {
+	cr := m.newReader(file, strings.NewReader(data))
+	row, err := m.newRow(cr)
+	if err != nil {
+		return nil, err
+	}
+	node := func(key string) (string, error) {
+		return key, nil
+	}
+	r := new(client.Req)
+	for {
+		if err := row.read(cr); err == io.EOF {
+			return r.Request().GetMutation().GetSet(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		edges, err := m.toEdges(row, node)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			require.NoError(t, r.Set(e))
+		}
+	}
+}
+
+func TestCSVMapping(t *testing.T) {
+	m, err := testCSVMapping(t, `{"subject": {"column": "id", "prefix": "_:p"},
+		"columns": [{"column": "name", "predicate": "name"}]}`)
+	require.NoError(t, err)
+	require.Equal(t, "id", m.Subject.Column)
+	require.Equal(t, "_:p", m.Subject.Prefix)
+
+	for _, mapping := range []string{
+		`{"subject": {"column": "id"}`,
+		`{"columns": [{"column": "name", "predicate": "name"}]}`,
+		`{"subject": {"column": "id"}, "columns": [{"column": "name"}]}`,
+		`{"subject": {"column": "id"}, "columns": [{"predicate": "name"}]}`,
+		`{"subject": {"column": "id"}, "columns": [{"lon": "x", "predicate": "loc"}]}`,
+		`{"subject": {"column": "id"},
+			"columns": [{"column": "age", "predicate": "age", "type": "integer"}]}`,
+	} {
+		_, err := testCSVMapping(t, mapping)
+		require.Error(t, err, mapping)
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	m := &csvMapping{Columns: []csvColumn{{Column: "name", Predicate: "name"}}}
+	m.Subject.Column = "id"
+	m.Subject.Prefix = "_:p"
+	want := []*protos.NQuad{
+		valueNQuad(t, "_:p1", "name", types.DefaultID, "Alice"),
+		valueNQuad(t, "_:p2", "name", types.DefaultID, "Bob"),
+	}
+
+	// Columns are found by name, whatever their order and the spaces around them.
+	nqs, err := csvNQuads(t, m, "people.csv", " name , id\nAlice,1\n Bob ,2\n")
+	require.NoError(t, err)
+	require.Equal(t, want, nqs)
+
+	nqs, err = csvNQuads(t, m, "people.tsv.gz", "id\tname\n1\tAlice\n2\tBob\n")
+	require.NoError(t, err)
+	require.Equal(t, want, nqs)
+
+	m.Delimiter = ";"
+	nqs, err = csvNQuads(t, m, "people.csv", "id;name\n1;Alice\n2;Bob\n")
+	require.NoError(t, err)
+	require.Equal(t, want, nqs)
+
+	// Without a header, columns are named by their index.
+	m = &csvMapping{NoHeader: true, Columns: []csvColumn{{Column: "1", Predicate: "name"}}}
+	m.Subject.Column = "0"
+	m.Subject.Prefix = "_:p"
+	nqs, err = csvNQuads(t, m, "people.csv", "1,Alice\n2,Bob\n")
+	require.NoError(t, err)
+	require.Equal(t, want, nqs)
+
+	m = &csvMapping{Columns: []csvColumn{{Column: "nickname", Predicate: "nickname"}}}
+	m.Subject.Column = "id"
+	_, err = csvNQuads(t, m, "people.csv", "id,name\n1,Alice\n")
+	require.Error(t, err)
+
+	_, err = csvNQuads(t, m, "people.csv", "")
+	require.Error(t, err)
+}
+
+func TestCSVTypes(t *testing.T) {
+	m, err := testCSVMapping(t, `{
+		"subject": {"column": "id", "prefix": "_:p"},
+		"columns": [
+			{"column": "name", "predicate": "name", "type": "string", "lang": "en"},
+			{"column": "age", "predicate": "age", "type": "int"},
+			{"column": "height", "predicate": "height", "type": "float"},
+			{"column": "alive", "predicate": "alive", "type": "bool"},
+			{"column": "born", "predicate": "born", "type": "datetime"},
+			{"column": "friend", "predicate": "friend", "type": "uid", "prefix": "_:p",
+			 "facets": {"since": "since"}},
+			{"lon": "lon", "lat": "lat", "predicate": "loc"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	nqs, err := csvNQuads(t, m, "people.csv",
+		"id,name,age,height,alive,born,friend,since,lon,lat\n"+
+			"1,Alice,42,1.7,true,2017-01-02,2,2010,-122.08,37.42\n"+
+			"2,,,,,,1,,,\n")
+	require.NoError(t, err)
+
+	name := valueNQuad(t, "_:p1", "name", types.StringID, "Alice")
+	name.Lang = "en"
+	born := valueNQuad(t, "_:p1", "born", types.DateTimeID,
+		time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC))
+	loc := valueNQuad(t, "_:p1", "loc", types.GeoID,
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.08, 37.42}))
+	require.Equal(t, []*protos.NQuad{
+		name,
+		valueNQuad(t, "_:p1", "age", types.IntID, int64(42)),
+		valueNQuad(t, "_:p1", "height", types.FloatID, 1.7),
+		valueNQuad(t, "_:p1", "alive", types.BoolID, true),
+		born,
+		{Subject: "_:p1", Predicate: "friend", ObjectId: "_:p2",
+			Facets: []*protos.Facet{{Key: "since", Val: "2010"}}},
+		loc,
+		// Empty cells have no edges.
+		{Subject: "_:p2", Predicate: "friend", ObjectId: "_:p1"},
+	}, nqs)
+}
+
+func TestCSVBadRows(t *testing.T) {
+	m, err := testCSVMapping(t, `{
+		"subject": {"column": "id"},
+		"columns": [
+			{"column": "age", "predicate": "age", "type": "int"},
+			{"lon": "lon", "lat": "lat", "predicate": "loc"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	// Rows without a subject are skipped, and short rows have empty cells.
+	nqs, err := csvNQuads(t, m, "people.csv", "id,age,lon,lat\n,42,1,2\n1\n")
+	require.NoError(t, err)
+	require.Empty(t, nqs)
+
+	for _, data := range []string{
+		"id,age,lon,lat\n1,forty,,\n",
+		"id,age,lon,lat\n1,4.2,,\n",
+		"id,age,lon,lat\n1,42,east,2\n",
+		"id,age,lon,lat\n1,42,1,2,3\n2,\"42,1,2\n",
+	} {
+		_, err := csvNQuads(t, m, "people.csv", data)
+		require.Error(t, err, data)
+	}
+}
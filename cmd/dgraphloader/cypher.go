@@ -0,0 +1,506 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/cypher"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The Cypher scripts loaded are the ones Neo4j dumps or exports with APOC, which create the graph
+// with CREATE, MATCH and MERGE clauses of node and relationship patterns, and SET clauses of
+// properties and labels, like
+//   CREATE (:`Person` {`name`:"Alice", `UNIQUE IMPORT ID`:0});
+//   MATCH (n1:`UNIQUE IMPORT LABEL`{`UNIQUE IMPORT ID`:0}), (n2:`UNIQUE IMPORT LABEL`{`UNIQUE IMPORT ID`:1})
+//     CREATE (n1)-[r:`KNOWS` {`since`:2010}]->(n2);
+// or
+//   create (_0:`Person` {`name`:"Alice"})
+//   create (_0)-[:`KNOWS`]->(_1)
+// Statements about the schema, like CREATE INDEX, are skipped. Other clauses are rejected.
+
+const (
+	importLabel = "UNIQUE IMPORT LABEL"
+	importId    = "UNIQUE IMPORT ID"
+)
+
+type cypherNode struct {
+	name   string
+	labels []string
+	props  []neo4jProp
+	id     string // The UNIQUE IMPORT ID of APOC exports.
+}
+
+type cypherParser struct {
+	lex  *cypher.Lexer
+	tok  cypher.Token
+	l    *neo4jLoader
+	vars map[string]string // Keys of the nodes bound to variables in the current statement.
+	anon int
+}
+
+func newCypherParser(r io.Reader, l *neo4jLoader) *cypherParser {
+	return &cypherParser{
+		lex:  cypher.NewLexer(r),
+		l:    l,
+		vars: make(map[string]string),
+	}
+}
+
+func (p *cypherParser) next() error {
+	var err error
+	if p.tok, err = p.lex.Next(); err != nil {
+		return x.Wrapf(err, "On line %d", p.lex.Line())
+	}
+	return nil
+}
+
+func (p *cypherParser) errorf(format string, args ...interface{}) error {
+	return x.Errorf("On line %d: %s", p.lex.Line(), fmt.Sprintf(format, args...))
+}
+
+func (p *cypherParser) is(punct string) bool {
+	return p.tok.Kind == cypher.TokPunct && p.tok.Text == punct
+}
+
+func (p *cypherParser) keyword(kw string) bool {
+	return p.tok.Kind == cypher.TokIdent && strings.EqualFold(p.tok.Text, kw)
+}
+
+func (p *cypherParser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.errorf("Expected %s, got %q", punct, p.tok.Text)
+	}
+	return p.next()
+}
+
+func (p *cypherParser) ident() (string, error) {
+	if p.tok.Kind != cypher.TokIdent && p.tok.Kind != cypher.TokQuoted {
+		return "", p.errorf("Expected a name, got %q", p.tok.Text)
+	}
+	name := p.tok.Text
+	return name, p.next()
+}
+
+// skipStatement skips the tokens up to the end of the statement.
+func (p *cypherParser) skipStatement() error {
+	for p.tok.Kind != cypher.TokEOF && !p.is(";") {
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *cypherParser) parse(ctx context.Context) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	for p.tok.Kind != cypher.TokEOF {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var err error
+		switch {
+		case p.is(";"):
+			p.vars = make(map[string]string)
+			err = p.next()
+		case p.is(":"):
+			// Commands of cypher-shell, like :begin.
+			if err = p.next(); err == nil {
+				_, err = p.ident()
+			}
+		case p.keyword("begin"), p.keyword("commit"), p.keyword("rollback"):
+			err = p.next()
+		case p.keyword("drop"), p.keyword("call"), p.keyword("schema"):
+			err = p.skipStatement()
+		case p.keyword("create"), p.keyword("match"), p.keyword("merge"), p.keyword("set"):
+			err = p.clause()
+		default:
+			err = p.errorf("Unsupported clause: %q", p.tok.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *cypherParser) clause() error {
+	kw := strings.ToLower(p.tok.Text)
+	if err := p.next(); err != nil {
+		return err
+	}
+	if kw == "create" && (p.keyword("index") || p.keyword("constraint")) {
+		return p.skipStatement()
+	}
+	if err := p.l.next(); err != nil {
+		return err
+	}
+	if kw == "set" {
+		return p.set()
+	}
+	for {
+		if err := p.path(kw == "match"); err == errCleanup {
+			// The clean up of APOC exports, which removes the import labels and ids.
+			return p.skipStatement()
+		} else if err != nil {
+			return err
+		}
+		if !p.is(",") {
+			return nil
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+}
+
+var errCleanup = x.Errorf("Clean up of import labels")
+
+// path parses a pattern of nodes and the relationships between them. The nodes of MATCH clauses
+// must have been created before.
+func (p *cypherParser) path(match bool) error {
+	from, err := p.node(match)
+	if err != nil {
+		return err
+	}
+	for p.is("-") || p.is("<") {
+		reverse := p.is("<")
+		if reverse {
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+		if err := p.expect("-"); err != nil {
+			return err
+		}
+		if err := p.expect("["); err != nil {
+			return err
+		}
+		if p.tok.Kind == cypher.TokIdent || p.tok.Kind == cypher.TokQuoted {
+			// The variable of the relationship isn't needed.
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		typ, err := p.ident()
+		if err != nil {
+			return err
+		}
+		var props []neo4jProp
+		if p.is("{") {
+			if props, _, err = p.props(); err != nil {
+				return err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+		if err := p.expect("-"); err != nil {
+			return err
+		}
+		if !reverse {
+			if err := p.expect(">"); err != nil {
+				return err
+			}
+		}
+		to, err := p.node(match)
+		if err != nil {
+			return err
+		}
+		if match {
+			return p.errorf("Relationships can't be matched")
+		}
+		if reverse {
+			err = p.l.rel(to, from, typ, props)
+		} else {
+			err = p.l.rel(from, to, typ, props)
+		}
+		if err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// node parses a node pattern and returns the key of the node, sending its labels and properties
+// unless matched.
+func (p *cypherParser) node(match bool) (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var n cypherNode
+	var err error
+	if p.tok.Kind == cypher.TokIdent || p.tok.Kind == cypher.TokQuoted {
+		if n.name, err = p.ident(); err != nil {
+			return "", err
+		}
+	}
+	for p.is(":") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+		label, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		if label != importLabel {
+			n.labels = append(n.labels, label)
+		}
+	}
+	if p.is("{") {
+		if n.props, n.id, err = p.props(); err != nil {
+			return "", err
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return "", err
+	}
+
+	var key string
+	_, bound := p.vars[n.name]
+	switch {
+	case n.id != "":
+		key = neo4jKey("", n.id)
+	case n.name != "" && bound:
+		key = p.vars[n.name]
+	case match && len(n.props) == 0:
+		return "", errCleanup
+	case match:
+		return "", p.errorf("Nodes can only be matched by their %s", importId)
+	case n.name != "":
+		// Variables of Neo4j dumps name the same nodes across statements.
+		key = "_:neo4j-var/" + n.name
+	default:
+		p.anon++
+		key = fmt.Sprintf("_:neo4j-anon/%s/%d", p.l.file, p.anon)
+	}
+	if n.name != "" {
+		p.vars[n.name] = key
+	}
+	if match {
+		return key, nil
+	}
+	return key, p.l.node(key, n.labels, n.props)
+}
+
+// props parses a map of properties, and returns the UNIQUE IMPORT ID among them separately.
+func (p *cypherParser) props() ([]neo4jProp, string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, "", err
+	}
+	var props []neo4jProp
+	var id string
+	for !p.is("}") {
+		key, err := p.ident()
+		if err != nil {
+			return nil, "", err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, "", err
+		}
+		vals, err := p.value()
+		if err != nil {
+			return nil, "", err
+		}
+		if key == importId && len(vals) == 1 {
+			id = vals[0].val
+		} else {
+			props = append(props, neo4jProp{key: key, vals: vals})
+		}
+		if p.is(",") {
+			if err := p.next(); err != nil {
+				return nil, "", err
+			}
+		} else if !p.is("}") {
+			return nil, "", p.errorf("Expected , or }, got %q", p.tok.Text)
+		}
+	}
+	return props, id, p.next()
+}
+
+// value parses a literal, which has no values if it's null, and several if it's a list.
+func (p *cypherParser) value() ([]neo4jValue, error) {
+	tok := p.tok
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	switch tok.Kind {
+	case cypher.TokString:
+		return []neo4jValue{{tid: types.StringID, val: tok.Text}}, nil
+	case cypher.TokNumber:
+		return []neo4jValue{number(tok.Text)}, nil
+	case cypher.TokPunct:
+		switch tok.Text {
+		case "-":
+			if p.tok.Kind != cypher.TokNumber {
+				return nil, p.errorf("Expected a number, got %q", p.tok.Text)
+			}
+			v := number("-" + p.tok.Text)
+			return []neo4jValue{v}, p.next()
+		case "[":
+			var vals []neo4jValue
+			for !p.is("]") {
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, v...)
+				if p.is(",") {
+					if err := p.next(); err != nil {
+						return nil, err
+					}
+				} else if !p.is("]") {
+					return nil, p.errorf("Expected , or ], got %q", p.tok.Text)
+				}
+			}
+			return vals, p.next()
+		}
+	case cypher.TokIdent:
+		switch strings.ToLower(tok.Text) {
+		case "null":
+			return nil, nil
+		case "true", "false":
+			return []neo4jValue{{tid: types.BoolID, val: strings.ToLower(tok.Text)}}, nil
+		case "date", "datetime", "localdatetime":
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			if p.tok.Kind != cypher.TokString {
+				return nil, p.errorf("Expected a string, got %q", p.tok.Text)
+			}
+			v := neo4jValue{tid: types.DateTimeID, val: p.tok.Text}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			return []neo4jValue{v}, p.expect(")")
+		case "point":
+			return p.point()
+		}
+	}
+	return nil, p.errorf("Unsupported value: %q", tok.Text)
+}
+
+func number(s string) neo4jValue {
+	if strings.ContainsAny(s, ".eE") {
+		return neo4jValue{tid: types.FloatID, val: s}
+	}
+	return neo4jValue{tid: types.IntID, val: s}
+}
+
+// point parses the arguments of a point, with either longitude and latitude or x and y.
+func (p *cypherParser) point() ([]neo4jValue, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	props, _, err := p.props()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	coords := make(map[string]float64)
+	for _, prop := range props {
+		if len(prop.vals) != 1 {
+			return nil, p.errorf("Invalid point coordinate: %q", prop.key)
+		}
+		if coords[prop.key], err = strconv.ParseFloat(prop.vals[0].val, 64); err != nil {
+			return nil, p.errorf("Invalid point coordinate: %q", prop.key)
+		}
+	}
+	lon, okLon := coords["longitude"]
+	lat, okLat := coords["latitude"]
+	if !okLon || !okLat {
+		lon, okLon = coords["x"]
+		lat, okLat = coords["y"]
+	}
+	if !okLon || !okLat {
+		return nil, p.errorf("Point needs longitude and latitude, or x and y")
+	}
+	g, err := geom.NewPoint(geom.XY).SetCoords(geom.Coord{lon, lat})
+	if err != nil {
+		return nil, err
+	}
+	return []neo4jValue{{tid: types.GeoID, geo: g}}, nil
+}
+
+// set parses the items of a SET clause: properties of nodes, like n.name = "Alice" or
+// n += {name: "Alice"}, and labels, like n:Person.
+func (p *cypherParser) set() error {
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		key, ok := p.vars[name]
+		if !ok {
+			return p.errorf("Unknown variable: %q", name)
+		}
+		var labels []string
+		var props []neo4jProp
+		switch {
+		case p.is("."):
+			if err := p.next(); err != nil {
+				return err
+			}
+			prop, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			vals, err := p.value()
+			if err != nil {
+				return err
+			}
+			props = append(props, neo4jProp{key: prop, vals: vals})
+		case p.is("+"), p.is("="):
+			if p.is("+") {
+				if err := p.next(); err != nil {
+					return err
+				}
+			}
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			if props, _, err = p.props(); err != nil {
+				return err
+			}
+		case p.is(":"):
+			for p.is(":") {
+				if err := p.next(); err != nil {
+					return err
+				}
+				label, err := p.ident()
+				if err != nil {
+					return err
+				}
+				labels = append(labels, label)
+			}
+		default:
+			return p.errorf("Unsupported SET item: %q", p.tok.Text)
+		}
+		if err := p.l.node(key, labels, props); err != nil {
+			return err
+		}
+		if !p.is(",") {
+			return nil
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+}
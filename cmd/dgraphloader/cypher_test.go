@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+// testNeo4jLoader returns a loader which keeps the edges in its request, with the keys of nodes
+// as their subjects and objects.
+func testNeo4jLoader(t *testing.T) *neo4jLoader {
+	m, err := readNeo4jMapping("")
+	require.NoError(t, err)
+	return &neo4jLoader{
+		m: m,
+		r: new(client.Req),
+		resolve: func(key string) (string, error) {
+			return key, nil
+		},
+	}
+}
+
+func valueNQuad(t *testing.T, subject, pred string, tid types.TypeID,
+	val interface{}) *protos.NQuad {
+	v, err := types.ObjectValue(tid, val)
+	require.NoError(t, err)
+	return &protos.NQuad{Subject: subject, Predicate: pred, ObjectValue: v,
+		ObjectType: int32(tid)}
+}
+
+func TestCypher(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   func(t *testing.T) []*protos.NQuad
+	}{
+		{
+			name:   "empty",
+			script: "// Nothing to load.\n",
+			want:   func(t *testing.T) []*protos.NQuad { return nil },
+		},
+		{
+			name: "quoting and escaping",
+			script: "CREATE (:`Person``s` {`full name`:\"Alice \\\"Al\\\"\\n\", " +
+				"nick:'O\\'Neil', `UNIQUE IMPORT ID`:0});",
+			want: func(t *testing.T) []*protos.NQuad {
+				return []*protos.NQuad{
+					valueNQuad(t, "_:neo4j//0", "label", types.StringID, "Person`s"),
+					valueNQuad(t, "_:neo4j//0", "full name", types.StringID, "Alice \"Al\"\n"),
+					valueNQuad(t, "_:neo4j//0", "nick", types.StringID, "O'Neil"),
+				}
+			},
+		},
+		{
+			name: "labels",
+			script: `create (n:Person:Employee:` + "`UNIQUE IMPORT LABEL`" + ` {age: 42})
+				set n:Manager, n.active = true;`,
+			want: func(t *testing.T) []*protos.NQuad {
+				return []*protos.NQuad{
+					valueNQuad(t, "_:neo4j-var/n", "label", types.StringID, "Person"),
+					valueNQuad(t, "_:neo4j-var/n", "label", types.StringID, "Employee"),
+					valueNQuad(t, "_:neo4j-var/n", "age", types.IntID, int64(42)),
+					valueNQuad(t, "_:neo4j-var/n", "label", types.StringID, "Manager"),
+					valueNQuad(t, "_:neo4j-var/n", "active", types.BoolID, true),
+				}
+			},
+		},
+		{
+			name: "outgoing relationship",
+			script: "MATCH (n1:`UNIQUE IMPORT LABEL`{`UNIQUE IMPORT ID`:0}), " +
+				"(n2:`UNIQUE IMPORT LABEL`{`UNIQUE IMPORT ID`:1}) " +
+				"CREATE (n1)-[r:`KNOWS` {`since`:2010, note:\"met at work\"}]->(n2);",
+			want: func(t *testing.T) []*protos.NQuad {
+				return []*protos.NQuad{{
+					Subject:   "_:neo4j//0",
+					Predicate: "KNOWS",
+					ObjectId:  "_:neo4j//1",
+					Facets: []*protos.Facet{
+						{Key: "since", Val: "2010"},
+						{Key: "note", Val: `"met at work"`},
+					},
+				}}
+			},
+		},
+		{
+			name:   "incoming and chained relationships",
+			script: "create (a)<-[:FOLLOWS]-(b)-[:LIKES]->(c)",
+			want: func(t *testing.T) []*protos.NQuad {
+				return []*protos.NQuad{
+					{Subject: "_:neo4j-var/b", Predicate: "FOLLOWS", ObjectId: "_:neo4j-var/a"},
+					{Subject: "_:neo4j-var/b", Predicate: "LIKES", ObjectId: "_:neo4j-var/c"},
+				}
+			},
+		},
+		{
+			name: "skipped statements",
+			script: `:begin
+				CREATE INDEX ON :Person(name);
+				:commit
+				MATCH (n:` + "`UNIQUE IMPORT LABEL`" + `) WITH n LIMIT 20000
+				REMOVE n:` + "`UNIQUE IMPORT LABEL`" + `;`,
+			want: func(t *testing.T) []*protos.NQuad { return nil },
+		},
+	}
+
+	for _, tc := range tests {
+		l := testNeo4jLoader(t)
+		err := newCypherParser(strings.NewReader(tc.script), l).parse(context.Background())
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.want(t), l.r.Request().GetMutation().GetSet(), tc.name)
+	}
+}
+
+func TestCypherMalformed(t *testing.T) {
+	for _, script := range []string{
+		`create (n {name: "Alice})`,
+		"create (:`Person)",
+		`create (n {name: 'Alice\`,
+		`create (n:Person`,
+		`create (n {name:`,
+		`create (n {name: "Alice"`,
+		`create (n {name "Alice"})`,
+		`create (n {tags: ["a", "b"})`,
+		`create (n {tags: ["a" "b"]})`,
+		`create (n {age: -"1"})`,
+		`create (n {age: 1e})`,
+		`create (n {name: Alice})`,
+		`create (n {born: date(2010)})`,
+		`create (n {loc: point({x: 1})})`,
+		`create (n {loc: point({x: "a", y: 1})})`,
+		`create (n) / (m)`,
+		`create (a)-[:KNOWS]-(b)`,
+		`create (a)<-[:KNOWS]->(b)`,
+		`create (a)-[]->(b)`,
+		`create (a)-[:KNOWS->(b)`,
+		`create (a)-[:KNOWS]->`,
+		`create (a)-[:KNOWS {at: point({x: 1, y: 2})}]->(b)`,
+		"match (a {`UNIQUE IMPORT ID`:0})-[:KNOWS]->(b {`UNIQUE IMPORT ID`:1})",
+		`match (a {name: "Alice"})`,
+		`create (n) set m.name = "Bob"`,
+		`create (n) set n - 1`,
+		`delete n`,
+		`return 1`,
+	} {
+		l := testNeo4jLoader(t)
+		err := newCypherParser(strings.NewReader(script), l).parse(context.Background())
+		require.Error(t, err, script)
+	}
+}
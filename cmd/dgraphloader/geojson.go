@@ -3,28 +3,220 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
 
-	"github.com/dgraph-io/dgraph/client"
 	"github.com/twpayne/go-geom/encoding/geojson"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
 )
 
-func toMutations(f *geojson.Feature, c *client.Dgraph) error {
-	n, err := c.NodeBlank("")
+var geoMapFile = flag.String("geo_map", "",
+	"Location of the mapping of the properties of GeoJSON features to predicates")
+
+// geoMapping says how the features of GeoJSON FeatureCollection files are turned into edges.
+// Every feature is a node, keyed by the subject property or by the id of the feature, or a new
+// node if it has neither. Its geometry is the value of the geometry predicate, and every property
+// mapping adds an edge of the node, like
+//   {
+//     "subject": {"property": "osm_id", "prefix": "_:place"},
+//     "geometry": "loc",
+//     "properties": [
+//       {"property": "name", "predicate": "name", "type": "string", "lang": "en"},
+//       {"property": "population", "predicate": "population", "type": "int"},
+//       {"property": "country", "predicate": "country", "type": "uid", "prefix": "_:country"},
+//       {"property": "address.city", "predicate": "city"}
+//     ]
+//   }
+// Properties of nested objects are named by their path, like address.city. Without a mapping,
+// features only have their geometry, under -geopred.
+type geoMapping struct {
+	Subject struct {
+		Property string `json:"property"`
+		// Whether the node is keyed by the id of the feature, rather than by a property.
+		ID bool `json:"id"`
+		// Prepended to the key, which is then taken like the subjects of N-Quads: as a uid, a
+		// blank node if starting with _:, or an XID.
+		Prefix string `json:"prefix"`
+	} `json:"subject"`
+	// The predicate of the geometry, -geopred by default.
+	Geometry   string        `json:"geometry"`
+	Properties []geoProperty `json:"properties"`
+}
+
+type geoProperty struct {
+	Property  string `json:"property"`
+	Predicate string `json:"predicate"`
+	// Name of a scalar type, or uid for an edge to the node keyed by the value, with prefix.
+	Type   string `json:"type"`
+	Prefix string `json:"prefix"`
+	Lang   string `json:"lang"`
+}
+
+func readGeoMapping(file string) (*geoMapping, error) {
+	m := &geoMapping{}
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, x.Wrapf(err, "While parsing GeoJSON mapping: %v", file)
+		}
+	}
+	if m.Subject.ID && m.Subject.Property != "" {
+		return nil, x.Errorf("GeoJSON mapping can't key nodes by both a property and the id")
+	}
+	if m.Geometry == "" {
+		m.Geometry = *geoPredicate
+	}
+	for _, p := range m.Properties {
+		if p.Property == "" {
+			return nil, x.Errorf("GeoJSON mapping of %q has no property", p.Predicate)
+		}
+		if p.Predicate == "" {
+			return nil, x.Errorf("GeoJSON mapping of property %q has no predicate", p.Property)
+		}
+		if p.Type != "" && p.Type != "uid" {
+			if _, ok := types.TypeForName(p.Type); !ok {
+				return nil, x.Errorf("GeoJSON mapping of %q has invalid type: %q", p.Predicate,
+					p.Type)
+			}
+		}
+	}
+	return m, nil
+}
+
+// geoFeature is a GeoJSON feature, whose id can be a string or a number.
+type geoFeature struct {
+	Type       string                 `json:"type"`
+	ID         json.RawMessage        `json:"id"`
+	Geometry   *geojson.Geometry      `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// propertyValue returns the value of a property as text, which is empty for null. Objects and
+// arrays are kept as JSON.
+func propertyValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// property returns the value of the property name in props, where names like address.city are
+// the properties of nested objects, unless there's a property by that name.
+func property(props map[string]interface{}, name string) interface{} {
+	v, ok := props[name]
+	i := strings.IndexByte(name, '.')
+	if ok || i < 0 {
+		return v
+	}
+	nested, ok := props[name[:i]].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return property(nested, name[i+1:])
+}
+
+// key returns the key of the node of feature f, which is empty if it has none.
+func (m *geoMapping) key(f *geoFeature) (string, error) {
+	if m.Subject.ID {
+		if len(f.ID) == 0 {
+			return "", nil
+		}
+		var id interface{}
+		if err := json.Unmarshal(f.ID, &id); err != nil {
+			return "", err
+		}
+		if s, ok := id.(string); ok {
+			return s, nil
+		}
+		return string(f.ID), nil
+	}
+	if m.Subject.Property == "" {
+		return "", nil
+	}
+	return propertyValue(property(f.Properties, m.Subject.Property))
+}
+
+// toEdges returns the edges of the node of feature f, with node resolving the keys of nodes.
+func (m *geoMapping) toEdges(f *geoFeature,
+	node func(string) (string, error)) ([]client.Edge, error) {
+	if f.Type != "Feature" {
+		return nil, x.Errorf("Expected a Feature but got %q", f.Type)
+	}
+	key, err := m.key(f)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// Features without a key are new nodes, as a blank node without a name is.
+	subject, err := node("_:")
+	if key != "" {
+		subject, err = node(m.Subject.Prefix + key)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	e := n.Edge(*geoPredicate)
-	if err = e.SetValueGeoGeometry(f.Geometry); err != nil {
-		return err
+	var edges []client.Edge
+	if f.Geometry != nil {
+		if f.Geometry.Coordinates == nil {
+			return nil, x.Errorf("Geometry of type %q has no coordinates", f.Geometry.Type)
+		}
+		g, err := f.Geometry.Decode()
+		if err != nil {
+			return nil, x.Wrapf(err, "Invalid geometry")
+		}
+		nq := protos.NQuad{Subject: subject, Predicate: m.Geometry,
+			ObjectType: int32(types.GeoID)}
+		if nq.ObjectValue, err = types.ObjectValue(types.GeoID, g); err != nil {
+			return nil, err
+		}
+		edges = append(edges, client.NewEdge(nq))
 	}
-	if err = c.BatchSet(e); err != nil {
-		log.Fatal("While adding mutation to batch: ", err)
+	for _, p := range m.Properties {
+		val, err := propertyValue(property(f.Properties, p.Property))
+		if err != nil {
+			return nil, err
+		}
+		if val == "" {
+			continue
+		}
+		nq := protos.NQuad{Subject: subject, Predicate: p.Predicate, Lang: p.Lang}
+		if p.Type == "uid" {
+			if nq.ObjectId, err = node(p.Prefix + val); err != nil {
+				return nil, err
+			}
+		} else {
+			tid := types.DefaultID
+			if p.Type != "" {
+				tid, _ = types.TypeForName(p.Type)
+			}
+			if nq.ObjectValue, err = objectValue(tid, val); err != nil {
+				return nil, x.Wrapf(err, "Invalid value for %q", p.Predicate)
+			}
+			nq.ObjectType = int32(tid)
+		}
+		edges = append(edges, client.NewEdge(nq))
 	}
-	return nil
+	return edges, nil
 }
 
 func findFeatureArray(dec *json.Decoder) error {
@@ -60,26 +252,68 @@ func findFeatureArray(dec *json.Decoder) error {
 	return nil
 }
 
-func processGeoFile(ctx context.Context, file string, dgraphClient *client.Dgraph) error {
+// processGeoFile sends mutations for the features of a GeoJSON FeatureCollection file, mapped to
+// edges by m.
+func processGeoFile(ctx context.Context, file string, m *geoMapping,
+	dgraphClient *client.Dgraph) error {
 	fmt.Printf("\nProcessing %s\n", file)
 	r, f := fileReader(file)
 	defer f.Close()
 	dec := json.NewDecoder(r)
+	// Numbers are kept as they're written, for ids and values which aren't floats.
+	dec.UseNumber()
 	err := findFeatureArray(dec)
 	if err != nil {
 		return err
 	}
 
+	absPath, err := filepath.Abs(file)
+	x.Check(err)
+	checkpoint, err := dgraphClient.Checkpoint(absPath)
+	x.Check(err)
+	if checkpoint != 0 {
+		fmt.Printf("\nFound checkpoint for: %s. Skipping: %v features.\n", file, checkpoint)
+	}
+
+	node := func(key string) (string, error) {
+		return Node(key, dgraphClient)
+	}
+	var n uint64
+	req := new(client.Req)
+	var batchSize int
 	// Read the features one at a time.
 	for dec.More() {
-		var f geojson.Feature
-		err := dec.Decode(&f)
-		if err != nil {
+		if err := ctx.Err(); err != nil {
 			return err
 		}
-		if err = toMutations(&f, dgraphClient); err != nil {
-			return err
+		var feature geoFeature
+		if err := dec.Decode(&feature); err != nil {
+			return x.Wrapf(err, "While reading feature %d of %v", n+1, file)
 		}
+		n++
+		if n <= checkpoint {
+			continue
+		}
+		edges, err := m.toEdges(&feature, node)
+		if err != nil {
+			return x.Wrapf(err, "While mapping feature %d of %v", n, file)
+		}
+		for _, e := range edges {
+			if err := req.Set(e); err != nil {
+				return err
+			}
+			batchSize++
+		}
+		if batchSize >= *numRdf {
+			if err = dgraphClient.BatchSetWithMark(req, absPath, n); err != nil {
+				return err
+			}
+			batchSize = 0
+			req = new(client.Req)
+		}
+	}
+	if batchSize > 0 {
+		return dgraphClient.BatchSetWithMark(req, absPath, n)
 	}
 	return nil
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+func testGeoMapping(t *testing.T, mapping string) (*geoMapping, error) {
+	f, err := ioutil.TempFile("", "geo_map")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(mapping)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return readGeoMapping(f.Name())
+}
+
+// geoNQuads returns the N-Quads of the features of the collection, with the keys of nodes as
+// their subjects and objects.
+func geoNQuads(t *testing.T, m *geoMapping, collection string) ([]*protos.NQuad, error) {
+	dec := json.NewDecoder(strings.NewReader(collection))
+	dec.UseNumber()
+	if err := findFeatureArray(dec); err != nil {
+		return nil, err
+	}
+	node := func(key string) (string, error) {
+		return key, nil
+	}
+	r := new(client.Req)
+	for dec.More() {
+		var f geoFeature
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		edges, err := m.toEdges(&f, node)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			require.NoError(t, r.Set(e))
+		}
+	}
+	return r.Request().GetMutation().GetSet(), nil
+}
+
+func geoNQuad(t *testing.T, subject string, coords ...float64) *protos.NQuad {
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord(coords))
+	return valueNQuad(t, subject, "loc", types.GeoID, p)
+}
+
+func TestGeoJSONMapping(t *testing.T) {
+	m, err := readGeoMapping("")
+	require.NoError(t, err)
+	require.Equal(t, *geoPredicate, m.Geometry)
+
+	for _, mapping := range []string{
+		`{"subject": {"property": "name"`,
+		`{"subject": {"property": "name", "id": true}}`,
+		`{"properties": [{"predicate": "name"}]}`,
+		`{"properties": [{"property": "name"}]}`,
+		`{"properties": [{"property": "age", "predicate": "age", "type": "integer"}]}`,
+	} {
+		_, err := testGeoMapping(t, mapping)
+		require.Error(t, err, mapping)
+	}
+}
+
+func TestGeoJSONProperties(t *testing.T) {
+	m, err := testGeoMapping(t, `{
+		"subject": {"property": "osm_id", "prefix": "_:place"},
+		"properties": [
+			{"property": "name", "predicate": "name", "type": "string", "lang": "en"},
+			{"property": "population", "predicate": "population", "type": "int"},
+			{"property": "area", "predicate": "area", "type": "float"},
+			{"property": "capital", "predicate": "capital", "type": "bool"},
+			{"property": "founded", "predicate": "founded", "type": "datetime"},
+			{"property": "country", "predicate": "country", "type": "uid", "prefix": "_:country"},
+			{"property": "address.city", "predicate": "city"},
+			{"property": "address.zip", "predicate": "zip"},
+			{"property": "address.geo.alt", "predicate": "alt", "type": "int"},
+			{"property": "tags", "predicate": "tags"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	nqs, err := geoNQuads(t, m, `{"type": "FeatureCollection", "features": [{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [2.35, 48.85]},
+		"properties": {
+			"osm_id": 71525, "name": "Paris", "population": 2148000, "area": 105.4,
+			"capital": true, "founded": "2017-01-02", "country": "FR",
+			"address": {"city": "Paris", "zip": null, "geo": {"alt": 35}},
+			"tags": {"b": [1, 2], "a": "x"}
+		}
+	}]}`)
+	require.NoError(t, err)
+
+	name := valueNQuad(t, "_:place71525", "name", types.StringID, "Paris")
+	name.Lang = "en"
+	require.Equal(t, []*protos.NQuad{
+		geoNQuad(t, "_:place71525", 2.35, 48.85),
+		name,
+		valueNQuad(t, "_:place71525", "population", types.IntID, int64(2148000)),
+		valueNQuad(t, "_:place71525", "area", types.FloatID, 105.4),
+		valueNQuad(t, "_:place71525", "capital", types.BoolID, true),
+		valueNQuad(t, "_:place71525", "founded", types.DateTimeID,
+			time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)),
+		{Subject: "_:place71525", Predicate: "country", ObjectId: "_:countryFR"},
+		valueNQuad(t, "_:place71525", "city", types.DefaultID, "Paris"),
+		valueNQuad(t, "_:place71525", "alt", types.IntID, int64(35)),
+		// Objects are kept as JSON.
+		valueNQuad(t, "_:place71525", "tags", types.DefaultID, `{"a":"x","b":[1,2]}`),
+	}, nqs)
+
+	// Properties named with dots are found before nested ones.
+	nqs, err = geoNQuads(t, m, `{"features": [{"type": "Feature", "properties": {
+		"osm_id": "1", "address.city": "Lyon", "address": {"city": "Paris"}}}]}`)
+	require.NoError(t, err)
+	require.Equal(t, []*protos.NQuad{
+		valueNQuad(t, "_:place1", "city", types.DefaultID, "Lyon"),
+	}, nqs)
+
+	for _, props := range []string{
+		`{"population": "many"}`,
+		`{"population": 2.5}`,
+		`{"capital": "yes"}`,
+		`{"founded": "yesterday"}`,
+	} {
+		_, err := geoNQuads(t, m, `{"features": [{"type": "Feature", "properties": `+props+`}]}`)
+		require.Error(t, err, props)
+	}
+}
+
+func TestGeoJSONNoProperties(t *testing.T) {
+	m, err := testGeoMapping(t, `{
+		"subject": {"property": "osm_id", "prefix": "_:place"},
+		"geometry": "loc",
+		"properties": [{"property": "name", "predicate": "name"}]
+	}`)
+	require.NoError(t, err)
+
+	// Features without a key are new nodes, and only have their geometry.
+	nqs, err := geoNQuads(t, m, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [3, 4]},
+		 "properties": null},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [5, 6]},
+		 "properties": {}},
+		{"type": "Feature", "geometry": null, "properties": {"osm_id": 7, "name": null}}
+	]}`)
+	require.NoError(t, err)
+	require.Equal(t, []*protos.NQuad{
+		geoNQuad(t, "_:", 1, 2),
+		geoNQuad(t, "_:", 3, 4),
+		geoNQuad(t, "_:", 5, 6),
+	}, nqs)
+
+	// Keyed by the ids of the features, which can be strings or numbers.
+	m, err = testGeoMapping(t, `{"subject": {"id": true, "prefix": "_:place"}, "geometry": "loc"}`)
+	require.NoError(t, err)
+	nqs, err = geoNQuads(t, m, `{"features": [
+		{"type": "Feature", "id": "paris", "geometry": {"type": "Point", "coordinates": [1, 2]}},
+		{"type": "Feature", "id": 7, "geometry": {"type": "Point", "coordinates": [3, 4]}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [5, 6]}}
+	]}`)
+	require.NoError(t, err)
+	require.Equal(t, []*protos.NQuad{
+		geoNQuad(t, "_:placeparis", 1, 2),
+		geoNQuad(t, "_:place7", 3, 4),
+		geoNQuad(t, "_:", 5, 6),
+	}, nqs)
+}
+
+func TestGeoJSONMalformed(t *testing.T) {
+	m, err := readGeoMapping("")
+	require.NoError(t, err)
+	for _, collection := range []string{
+		`{"type": "FeatureCollection"}`,
+		`{"type": "FeatureCollection", "features": []}`,
+		`{"type": "FeatureCollection", "features": {}}`,
+		`{"features": [{"type": "Point", "coordinates": [1, 2]}]}`,
+		`{"features": [{"type": "Feature", "geometry": {"type": "Point"}}]}`,
+		`{"features": [{"type": "Feature", "geometry": {"type": "Circle", "coordinates": [1]}}]}`,
+		`{"features": [{"type": "Feature", "properties": []}]}`,
+		`{"features": [{"type": "Feature"`,
+	} {
+		_, err := geoNQuads(t, m, collection)
+		require.Error(t, err, collection)
+	}
+}
@@ -8,12 +8,10 @@ package main
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"flag"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
@@ -29,6 +27,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
+	"github.com/dgraph-io/dgraph/artifact"
 	"github.com/dgraph-io/dgraph/client"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/rdf"
@@ -40,6 +39,7 @@ import (
 
 var (
 	files        = flag.String("r", "", "Location of rdf files to load")
+	delFiles     = flag.String("del", "", "Location of rdf files with N-Quads to delete first")
 	schemaFile   = flag.String("s", "", "Location of schema file")
 	dgraph       = flag.String("d", "127.0.0.1:9080", "Dgraph gRPC server address")
 	concurrent   = flag.Int("c", 100, "Number of concurrent requests to make to Dgraph")
@@ -61,6 +61,8 @@ var (
 	tlsSystemCACerts = flag.Bool("tls.use_system_ca", false, "Include System CA into CA Certs.")
 	tlsMinVersion    = flag.String("tls.min_version", "TLS11", "TLS min version.")
 	tlsMaxVersion    = flag.String("tls.max_version", "TLS12", "TLS max version.")
+
+	keyFile = flag.String("key_file", "", "File with the key to decrypt encrypted files with")
 )
 
 // Reads a single line from a buffered reader. The line is read into the
@@ -83,28 +85,6 @@ func readLine(r *bufio.Reader, buf *bytes.Buffer) error {
 	return err
 }
 
-// processSchemaFile process schema for a given gz file.
-func processSchemaFile(ctx context.Context, file string, dgraphClient *client.Dgraph) error {
-	fmt.Printf("\nProcessing %s\n", file)
-	f, err := os.Open(file)
-	x.Check(err)
-	defer f.Close()
-
-	var reader io.Reader
-	if strings.HasSuffix(strings.ToLower(file), ".gz") {
-		reader, err = gzip.NewReader(f)
-		x.Check(err)
-	} else {
-		reader = f
-	}
-
-	b, err := ioutil.ReadAll(reader)
-	if err != nil {
-		x.Checkf(err, "Error while reading file")
-	}
-	return dgraphClient.SetSchemaBlocking(ctx, string(b))
-}
-
 func Node(val string, c *client.Dgraph) (string, error) {
 	if uid, err := strconv.ParseUint(val, 0, 64); err == nil {
 		return c.NodeUid(uid).String(), nil
@@ -127,13 +107,8 @@ func fileReader(file string) (io.Reader, *os.File) {
 	f, err := os.Open(file)
 	x.Check(err)
 
-	var r io.Reader
-	if filepath.Ext(file) == ".gz" {
-		r, err = gzip.NewReader(f)
-		x.Check(err)
-	} else {
-		r = bufio.NewReader(f)
-	}
+	r, err := artifact.NewReader(bufio.NewReader(prog.reader(f)), file)
+	x.Check(err)
 	return r, f
 }
 
@@ -210,6 +185,82 @@ func processFile(ctx context.Context, file string, dgraphClient *client.Dgraph)
 	return nil
 }
 
+// processDeleteFile deletes the N-Quads in a given file. Batches are sent one at a time, so that
+// all the deletes are done before anything is loaded. The last line deleted is checkpointed after
+// every batch, as deleting again after data was loaded would lose it.
+func processDeleteFile(ctx context.Context, file string, dgraphClient *client.Dgraph) error {
+	fmt.Printf("\nProcessing deletes in %s\n", file)
+	gr, f := fileReader(file)
+	defer f.Close()
+	bufReader := bufio.NewReader(gr)
+
+	absPath, err := filepath.Abs(file)
+	x.Check(err)
+	mark := "deletes-" + absPath
+	checkpoint, err := dgraphClient.Checkpoint(mark)
+	x.Check(err)
+	if checkpoint != 0 {
+		fmt.Printf("\nFound checkpoint for: %s. Skipping: %v lines.\n", file, checkpoint)
+	}
+
+	var buf bytes.Buffer
+	var line uint64
+	r := new(client.Req)
+	var batchSize int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := readLine(bufReader, &buf)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		line++
+		if line <= checkpoint {
+			buf.Reset()
+			continue
+		}
+		nq, err := rdf.Parse(buf.String())
+		if err == rdf.ErrEmpty {
+			buf.Reset()
+			continue
+		} else if err != nil {
+			log.Fatalf("Error while parsing RDF: %v, on line:%v %v", err, line, buf.String())
+		}
+		buf.Reset()
+
+		if nq.Subject, err = Node(nq.Subject, dgraphClient); err != nil {
+			return err
+		}
+		if len(nq.ObjectId) > 0 {
+			if nq.ObjectId, err = Node(nq.ObjectId, dgraphClient); err != nil {
+				return err
+			}
+		}
+		if err := r.Delete(client.NewEdge(nq)); err != nil {
+			return err
+		}
+		if batchSize++; batchSize >= *numRdf {
+			if _, err := dgraphClient.Run(ctx, r); err != nil {
+				return err
+			}
+			if err := dgraphClient.SaveCheckpoint(mark, line); err != nil {
+				return err
+			}
+			batchSize = 0
+			r = new(client.Req)
+		}
+	}
+	if batchSize > 0 {
+		if _, err := dgraphClient.Run(ctx, r); err != nil {
+			return err
+		}
+	}
+	return dgraphClient.SaveCheckpoint(mark, line)
+}
+
 func setupConnection(host string) (*grpc.ClientConn, error) {
 	if !*tlsEnabled {
 		return grpc.Dial(host,
@@ -252,6 +303,7 @@ func fileList(files string) []string {
 func main() {
 	flag.Parse()
 	x.Init()
+	artifact.Config.KeyFile = *keyFile
 	runtime.SetBlockProfileRate(*blockRate)
 
 	interruptChan := make(chan os.Signal)
@@ -295,6 +347,7 @@ func main() {
 	}
 	dgraphClient := client.NewDgraphClient(conns, bmOpts, *clientDir)
 	defer dgraphClient.Close()
+	http.HandleFunc("/progress", progressHandler(dgraphClient))
 
 	{
 		ctxTimeout, cancelTimeout := context.WithTimeout(ctx, 1*time.Minute)
@@ -312,8 +365,18 @@ func main() {
 			log.Fatal("While adding schema to batch ", err)
 		}
 	}
+	var indexSteps []schemaStep
 	if len(*schemaFile) > 0 {
-		if err := processSchemaFile(ctx, *schemaFile, dgraphClient); err != nil {
+		prog.startPhase("schema")
+		fmt.Printf("\nProcessing %s\n", *schemaFile)
+		updates, typs, err := readSchemaFile(*schemaFile)
+		x.Check(err)
+		steps := schemaSteps(updates, *deferIndex)
+		if *deferIndex {
+			steps, indexSteps = steps[:1], steps[1:]
+		}
+		steps = append(steps, typeStep(typs)...)
+		if err := applySchemaSteps(ctx, steps, dgraphClient); err != nil {
 			if err == context.Canceled {
 				log.Println("Interrupted while processing schema file")
 			} else {
@@ -323,14 +386,69 @@ func main() {
 		}
 	}
 
+	var until time.Time
+	if len(*changelogUntil) > 0 {
+		var err error
+		until, err = time.Parse(time.RFC3339, *changelogUntil)
+		x.Checkf(err, "While parsing -changelog_until")
+	}
+
 	filesList := fileList(*files)
 	geoFilesList := fileList(*geoFiles)
-	totalFiles := len(filesList) + len(geoFilesList)
-	if totalFiles == 0 {
+	var geoMap *geoMapping
+	if len(geoFilesList) > 0 {
+		var err error
+		geoMap, err = readGeoMapping(*geoMapFile)
+		x.Checkf(err, "While reading -geo_map")
+	}
+	csvFilesList := fileList(*csvFiles)
+	var csvMap *csvMapping
+	if len(csvFilesList) > 0 {
+		if len(*csvMapFile) == 0 {
+			log.Fatal("Loading CSV files needs a mapping, given with -csv_map")
+		}
+		var err error
+		csvMap, err = readCSVMapping(*csvMapFile)
+		x.Checkf(err, "While reading -csv_map")
+	}
+	neo4jFilesList := fileList(*neo4jFiles)
+	var neo4jMap *neo4jMapping
+	if len(neo4jFilesList) > 0 {
+		var err error
+		neo4jMap, err = readNeo4jMapping(*neo4jMapFile)
+		x.Checkf(err, "While reading -neo4j_map")
+	}
+	prog.addFiles(fileList(*delFiles))
+	prog.addFiles(filesList)
+	prog.addFiles(geoFilesList)
+	prog.addFiles(csvFilesList)
+	prog.addFiles(neo4jFilesList)
+
+	if len(*delFiles) > 0 {
+		prog.startPhase("deletes")
+	}
+	for _, file := range fileList(*delFiles) {
+		if err := processDeleteFile(ctx, strings.Trim(file, " \t"), dgraphClient); err != nil {
+			if err == context.Canceled {
+				log.Println("Interrupted while processing delete file")
+			} else {
+				log.Println(err)
+			}
+			return
+		}
+	}
+
+	totalFiles := len(filesList) + len(geoFilesList) + len(csvFilesList) + len(neo4jFilesList)
+	if totalFiles == 0 && len(*changelogDir) == 0 {
 		os.Exit(0)
 	}
 
-	x.Check(dgraphClient.NewSyncMarks(filesList))
+	markedFiles := append(append(append(filesList, geoFilesList...), csvFilesList...),
+		neo4jFilesList...)
+	x.Check(dgraphClient.NewSyncMarks(markedFiles))
+	if totalFiles > 0 {
+		prog.startPhase("load")
+	}
 	errCh := make(chan error, totalFiles)
 	for _, file := range filesList {
 		file = strings.Trim(file, " \t")
@@ -341,7 +459,19 @@ func main() {
 	for _, file := range geoFilesList {
 		file = strings.Trim(file, " \t")
 		go func(file string) {
-			errCh <- processGeoFile(ctx, file, dgraphClient)
+			errCh <- processGeoFile(ctx, file, geoMap, dgraphClient)
+		}(file)
+	}
+	for _, file := range csvFilesList {
+		file = strings.Trim(file, " \t")
+		go func(file string) {
+			errCh <- processCSVFile(ctx, file, csvMap, dgraphClient)
+		}(file)
+	}
+	for _, file := range neo4jFilesList {
+		file = strings.Trim(file, " \t")
+		go func(file string) {
+			errCh <- processNeo4jFile(ctx, file, neo4jMap, dgraphClient)
 		}(file)
 	}
 	interrupted := false
@@ -355,6 +485,16 @@ func main() {
 		}
 	}
 
+	if len(*restoreDir) > 0 && !interrupted {
+		prog.startPhase("restore")
+		err := restore(ctx, *restoreDir, dgraphClient)
+		if err == context.Canceled {
+			interrupted = true
+		} else if err != nil {
+			log.Fatal("While restoring ", err)
+		}
+	}
+
 	{
 		if err := dgraphClient.BatchFlush(); err != nil {
 			if err == context.Canceled {
@@ -365,6 +505,32 @@ func main() {
 		}
 	}
 
+	// The changelog is replayed over everything loaded.
+	if len(*changelogDir) > 0 && !interrupted {
+		prog.startPhase("changelog")
+		err := replayChangelog(ctx, *changelogDir, *changelogAfter, until, dgraphClient)
+		if err == context.Canceled {
+			interrupted = true
+		} else if err != nil {
+			log.Fatal("While replaying changelog ", err)
+		}
+	}
+
+	// The indexes are built once all of the data is in.
+	if len(indexSteps) > 0 && !interrupted {
+		prog.startPhase("indexes")
+		err := applySchemaSteps(ctx, indexSteps, dgraphClient)
+		if err == context.Canceled {
+			interrupted = true
+		} else if err != nil {
+			log.Fatal("While building indexes ", err)
+		}
+	}
+	if len(*schemaFile) > 0 && !interrupted {
+		x.Check(clearSchemaSteps())
+	}
+
+	prog.startPhase("")
 	c := dgraphClient.Counter()
 	var rate uint64
 	if c.Elapsed.Seconds() < 1 {
@@ -377,7 +543,7 @@ func main() {
 	fmt.Printf("%100s\r", "")
 
 	if interrupted {
-		fmt.Println("Interrupted.")
+		fmt.Println("Interrupted. Run again with the same files and -cd to resume.")
 	}
 	fmt.Printf("Number of mutations run   : %d\n", c.Mutations)
 	fmt.Printf("Number of RDFs processed  : %d\n", c.Rdfs)
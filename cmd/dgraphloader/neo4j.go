@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var (
+	neo4jFiles   = flag.String("neo4j", "", "Location of Neo4j CSV exports or Cypher scripts to load")
+	neo4jMapFile = flag.String("neo4j_map", "",
+		"Location of the mapping of Neo4j labels, relationship types and properties to predicates")
+)
+
+// neo4jMapping says how a Neo4j graph is turned into edges. The labels of nodes are the values of
+// the label predicate, relationships are edges with the predicates of their types, and the
+// properties of nodes are values with the predicates of their keys. The properties of
+// relationships are kept as facets of their edges.
+type neo4jMapping struct {
+	Label      string            `json:"label"` // Defaults to label.
+	Labels     map[string]string `json:"labels"`
+	Types      map[string]string `json:"types"`
+	Properties map[string]string `json:"properties"`
+}
+
+func readNeo4jMapping(file string) (*neo4jMapping, error) {
+	m := &neo4jMapping{}
+	if len(file) > 0 {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, x.Wrapf(err, "While parsing Neo4j mapping: %v", file)
+		}
+	}
+	if m.Label == "" {
+		m.Label = "label"
+	}
+	return m, nil
+}
+
+func rename(names map[string]string, name string) string {
+	if n, ok := names[name]; ok {
+		return n
+	}
+	return name
+}
+
+// Nodes of Neo4j are blank nodes named after their ids. Both the CSV exports and the Cypher
+// scripts of APOC give the ids of nodes, in different ID spaces for neo4j-admin imports.
+func neo4jKey(space, id string) string {
+	return "_:neo4j/" + space + "/" + id
+}
+
+type neo4jValue struct {
+	tid types.TypeID
+	val string
+	geo geom.T
+}
+
+func (v neo4jValue) object() (*protos.Value, error) {
+	if v.tid == types.GeoID {
+		return types.ObjectValue(types.GeoID, v.geo)
+	}
+	return objectValue(v.tid, v.val)
+}
+
+type neo4jProp struct {
+	key  string
+	vals []neo4jValue
+}
+
+// neo4jLoader sends the edges of the nodes and relationships of a file to the server, in batches
+// marked with the position in the file, so that loads can resume from their checkpoint.
+type neo4jLoader struct {
+	m          *neo4jMapping
+	c          *client.Dgraph
+	resolve    func(string) (string, error) // Resolves the keys of nodes.
+	file       string
+	checkpoint uint64
+	line       uint64
+	r          *client.Req
+	size       int
+}
+
+func newNeo4jLoader(file string, m *neo4jMapping, c *client.Dgraph) (*neo4jLoader, error) {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := c.Checkpoint(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != 0 {
+		fmt.Printf("\nFound checkpoint for: %s. Skipping: %v entries.\n", file, checkpoint)
+	}
+	resolve := func(key string) (string, error) {
+		return Node(key, c)
+	}
+	return &neo4jLoader{m: m, c: c, resolve: resolve, file: absPath, checkpoint: checkpoint,
+		r: new(client.Req)}, nil
+}
+
+// next moves to the next entry of the file, sending the batch if it's full. Entries up to the
+// checkpoint are read again, but not sent.
+func (l *neo4jLoader) next() error {
+	if l.size >= *numRdf {
+		if err := l.c.BatchSetWithMark(l.r, l.file, l.line); err != nil {
+			return err
+		}
+		l.size = 0
+		l.r = new(client.Req)
+	}
+	l.line++
+	return nil
+}
+
+func (l *neo4jLoader) flush() error {
+	if l.size == 0 {
+		return nil
+	}
+	return l.c.BatchSetWithMark(l.r, l.file, l.line)
+}
+
+func (l *neo4jLoader) set(nq protos.NQuad) error {
+	if l.line <= l.checkpoint {
+		return nil
+	}
+	var err error
+	if nq.Subject, err = l.resolve(nq.Subject); err != nil {
+		return err
+	}
+	if len(nq.ObjectId) > 0 {
+		if nq.ObjectId, err = l.resolve(nq.ObjectId); err != nil {
+			return err
+		}
+	}
+	if err := l.r.Set(client.NewEdge(nq)); err != nil {
+		return err
+	}
+	l.size++
+	return nil
+}
+
+func (l *neo4jLoader) node(key string, labels []string, props []neo4jProp) error {
+	for _, label := range labels {
+		v, err := types.ObjectValue(types.StringID, rename(l.m.Labels, label))
+		if err != nil {
+			return err
+		}
+		if err := l.set(protos.NQuad{Subject: key, Predicate: l.m.Label, ObjectValue: v,
+			ObjectType: int32(types.StringID)}); err != nil {
+			return err
+		}
+	}
+	for _, p := range props {
+		attr := rename(l.m.Properties, p.key)
+		for _, v := range p.vals {
+			ov, err := v.object()
+			if err != nil {
+				return x.Wrapf(err, "Invalid value of property %q", p.key)
+			}
+			if err := l.set(protos.NQuad{Subject: key, Predicate: attr, ObjectValue: ov,
+				ObjectType: int32(v.tid)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *neo4jLoader) rel(from, to, typ string, props []neo4jProp) error {
+	nq := protos.NQuad{Subject: from, Predicate: rename(l.m.Types, typ), ObjectId: to}
+	for _, p := range props {
+		if len(p.vals) == 0 {
+			continue
+		}
+		if len(p.vals) > 1 || p.vals[0].tid == types.GeoID {
+			return x.Errorf("Property %q of relationship %q can't be a facet", p.key, typ)
+		}
+		v := p.vals[0]
+		nq.Facets = append(nq.Facets, &protos.Facet{
+			Key: rename(l.m.Properties, p.key),
+			Val: facetValue(v.val, v.tid == types.StringID),
+		})
+	}
+	return l.set(nq)
+}
+
+// processNeo4jFile loads a Neo4j CSV export if the file has a .csv extension, and a Cypher script
+// otherwise.
+func processNeo4jFile(ctx context.Context, file string, m *neo4jMapping,
+	dgraphClient *client.Dgraph) error {
+	fmt.Printf("\nProcessing %s\n", file)
+	r, f := fileReader(file)
+	defer f.Close()
+	l, err := newNeo4jLoader(file, m, dgraphClient)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(artifact.TrimExt(strings.ToLower(file)), ".csv") {
+		err = loadNeo4jCSV(ctx, r, l)
+	} else {
+		err = newCypherParser(r, l).parse(ctx)
+	}
+	if err != nil {
+		return x.Wrapf(err, "While loading %v", file)
+	}
+	return l.flush()
+}
+
+const (
+	neo4jProperty = iota
+	neo4jId
+	neo4jLabels
+	neo4jStart
+	neo4jEnd
+	neo4jType
+	neo4jIgnore
+)
+
+type neo4jColumn struct {
+	kind  int
+	key   string
+	space string
+	tid   types.TypeID
+	array bool
+}
+
+var neo4jTypes = map[string]types.TypeID{
+	"int":           types.IntID,
+	"long":          types.IntID,
+	"short":         types.IntID,
+	"byte":          types.IntID,
+	"float":         types.FloatID,
+	"double":        types.FloatID,
+	"boolean":       types.BoolID,
+	"string":        types.StringID,
+	"char":          types.StringID,
+	"date":          types.DateTimeID,
+	"datetime":      types.DateTimeID,
+	"localdatetime": types.DateTimeID,
+}
+
+// neo4jColumnFor parses a column of the header of a CSV export of APOC, like _id or name, or of a
+// file for neo4j-admin import, like personId:ID(Person), age:int or :START_ID(Person).
+func neo4jColumnFor(header string) (neo4jColumn, error) {
+	switch header {
+	case "_id":
+		return neo4jColumn{kind: neo4jId}, nil
+	case "_labels":
+		return neo4jColumn{kind: neo4jLabels}, nil
+	case "_start":
+		return neo4jColumn{kind: neo4jStart}, nil
+	case "_end":
+		return neo4jColumn{kind: neo4jEnd}, nil
+	case "_type":
+		return neo4jColumn{kind: neo4jType}, nil
+	}
+	idx := strings.LastIndex(header, ":")
+	if idx < 0 {
+		return neo4jColumn{key: header, tid: types.DefaultID}, nil
+	}
+	c := neo4jColumn{key: header[:idx]}
+	spec := header[idx+1:]
+	if i := strings.Index(spec, "("); i >= 0 && strings.HasSuffix(spec, ")") {
+		c.space = spec[i+1 : len(spec)-1]
+		spec = spec[:i]
+	}
+	switch strings.ToUpper(spec) {
+	case "ID":
+		c.kind = neo4jId
+	case "LABEL":
+		c.kind = neo4jLabels
+	case "START_ID":
+		c.kind = neo4jStart
+	case "END_ID":
+		c.kind = neo4jEnd
+	case "TYPE":
+		c.kind = neo4jType
+	case "IGNORE":
+		c.kind = neo4jIgnore
+	default:
+		c.array = strings.HasSuffix(spec, "[]")
+		tid, ok := neo4jTypes[strings.ToLower(strings.TrimSuffix(spec, "[]"))]
+		if !ok {
+			return c, x.Errorf("Unknown type of column %q", header)
+		}
+		c.tid = tid
+	}
+	return c, nil
+}
+
+// values returns the values of a cell of a property column. Arrays are separated by ; in files for
+// neo4j-admin import, and are JSON in exports of APOC.
+func (c neo4jColumn) values(cell string) []neo4jValue {
+	vals := []string{cell}
+	var arr []json.RawMessage
+	if c.array {
+		vals = strings.Split(cell, ";")
+	} else if strings.HasPrefix(cell, "[") && json.Unmarshal([]byte(cell), &arr) == nil {
+		vals = vals[:0]
+		for _, raw := range arr {
+			var s string
+			if json.Unmarshal(raw, &s) != nil {
+				s = string(raw)
+			}
+			vals = append(vals, s)
+		}
+	}
+	res := make([]neo4jValue, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, neo4jValue{tid: c.tid, val: v})
+	}
+	return res
+}
+
+// loadNeo4jCSV loads a CSV export of nodes and relationships. Every row is a node, if it has an id,
+// or a relationship, if it has a start and an end.
+func loadNeo4jCSV(ctx context.Context, r io.Reader, l *neo4jLoader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return x.Wrapf(err, "While reading header")
+	}
+	cols := make([]neo4jColumn, len(header))
+	for i, h := range header {
+		if cols[i], err = neo4jColumnFor(strings.TrimSpace(h)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := l.next(); err != nil {
+			return err
+		}
+		var id, start, end, typ string
+		var labels []string
+		var props []neo4jProp
+		for i, cell := range fields {
+			if i >= len(cols) || cell == "" {
+				continue
+			}
+			c := cols[i]
+			switch c.kind {
+			case neo4jId:
+				id = neo4jKey(c.space, cell)
+				if c.key != "" {
+					props = append(props, neo4jProp{key: c.key, vals: c.values(cell)})
+				}
+			case neo4jLabels:
+				labels = append(labels, strings.FieldsFunc(cell, func(r rune) bool {
+					return r == ':' || r == ';'
+				})...)
+			case neo4jStart:
+				start = neo4jKey(c.space, cell)
+			case neo4jEnd:
+				end = neo4jKey(c.space, cell)
+			case neo4jType:
+				typ = cell
+			case neo4jProperty:
+				props = append(props, neo4jProp{key: c.key, vals: c.values(cell)})
+			}
+		}
+		switch {
+		case start != "" && end != "":
+			if typ == "" {
+				return x.Errorf("Relationship on row %d has no type", l.line)
+			}
+			err = l.rel(start, end, typ, props)
+		case id != "":
+			err = l.node(id, labels, props)
+		}
+		if err != nil {
+			return x.Wrapf(err, "On row %d", l.line)
+		}
+	}
+}
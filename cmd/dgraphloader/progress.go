@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/dgraph/client"
+)
+
+// progress keeps track of the phases of a load, and of how much of the input files has been read,
+// to report on /progress.
+type progress struct {
+	sync.Mutex
+	start  time.Time
+	phase  string
+	began  time.Time
+	phases []phaseTiming
+
+	bytesRead  int64
+	bytesTotal int64
+
+	// Steps of the schema applied, each building the indexes of a predicate.
+	schemaSteps     int
+	schemaStepsDone int
+}
+
+type phaseTiming struct {
+	Phase string `json:"phase"`
+	Took  string `json:"took"`
+}
+
+var prog = &progress{start: time.Now()}
+
+// startPhase ends the current phase, and starts the one with the given name.
+func (p *progress) startPhase(name string) {
+	p.Lock()
+	defer p.Unlock()
+	now := time.Now()
+	if p.phase != "" {
+		took := now.Sub(p.began)
+		p.phases = append(p.phases, phaseTiming{Phase: p.phase, Took: took.String()})
+		fmt.Printf("\nFinished %s in %v\n", p.phase, took)
+	}
+	p.phase, p.began = name, now
+}
+
+// addFiles adds the sizes of files to the total size of the input.
+func (p *progress) addFiles(files []string) {
+	for _, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			atomic.AddInt64(&p.bytesTotal, fi.Size())
+		}
+	}
+}
+
+// addSchemaSteps adds n steps to apply of the schema.
+func (p *progress) addSchemaSteps(n int) {
+	p.Lock()
+	defer p.Unlock()
+	p.schemaSteps += n
+}
+
+func (p *progress) schemaStepDone() {
+	p.Lock()
+	defer p.Unlock()
+	p.schemaStepsDone++
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// reader counts the bytes of the input read through r.
+func (p *progress) reader(r io.Reader) io.Reader {
+	return countingReader{r: r, n: &p.bytesRead}
+}
+
+type progressReport struct {
+	Phase      string        `json:"phase"`
+	Elapsed    string        `json:"elapsed"`
+	Mutations  uint64        `json:"mutations"`
+	Rdfs       uint64        `json:"rdfs"`
+	RdfsPerSec uint64        `json:"rdfs_per_sec"`
+	BytesRead  int64         `json:"bytes_read"`
+	BytesTotal int64         `json:"bytes_total"`
+	ETA        string        `json:"eta,omitempty"`
+	Phases     []phaseTiming `json:"phases"`
+
+	SchemaSteps     int `json:"schema_steps"`
+	SchemaStepsDone int `json:"schema_steps_done"`
+}
+
+func (p *progress) report(c client.Counter) progressReport {
+	p.Lock()
+	defer p.Unlock()
+	r := progressReport{
+		Phase:      p.phase,
+		Elapsed:    time.Since(p.start).String(),
+		Mutations:  c.Mutations,
+		Rdfs:       c.Rdfs,
+		BytesRead:  atomic.LoadInt64(&p.bytesRead),
+		BytesTotal: atomic.LoadInt64(&p.bytesTotal),
+		Phases:     append([]phaseTiming{}, p.phases...),
+
+		SchemaSteps:     p.schemaSteps,
+		SchemaStepsDone: p.schemaStepsDone,
+	}
+	if secs := c.Elapsed.Seconds(); secs >= 1 {
+		r.RdfsPerSec = uint64(float64(c.Rdfs) / secs)
+	}
+	// The rest of the input is assumed to be loaded at the rate of the current phase so far.
+	if p.phase == "load" && r.BytesRead > 0 && r.BytesTotal > r.BytesRead {
+		took := time.Since(p.began)
+		eta := time.Duration(float64(took) * float64(r.BytesTotal-r.BytesRead) /
+			float64(r.BytesRead))
+		r.ETA = ((eta / time.Second) * time.Second).String()
+	}
+	return r
+}
+
+// progressHandler serves the progress of the load as JSON, on the debug port of the loader.
+func progressHandler(dgraphClient *client.Dgraph) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(prog.report(dgraphClient.Counter()))
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/client"
+)
+
+func TestProgressPhases(t *testing.T) {
+	p := &progress{start: time.Now()}
+	r := p.report(client.Counter{})
+	require.Equal(t, "", r.Phase)
+	require.Empty(t, r.Phases)
+
+	p.startPhase("schema")
+	p.addSchemaSteps(3)
+	p.schemaStepDone()
+	p.startPhase("load")
+	r = p.report(client.Counter{Rdfs: 5000, Mutations: 5, Elapsed: 2 * time.Second})
+	require.Equal(t, "load", r.Phase)
+	require.Len(t, r.Phases, 1)
+	require.Equal(t, "schema", r.Phases[0].Phase)
+	require.Equal(t, 3, r.SchemaSteps)
+	require.Equal(t, 1, r.SchemaStepsDone)
+	require.Equal(t, uint64(5000), r.Rdfs)
+	require.Equal(t, uint64(5), r.Mutations)
+	require.Equal(t, uint64(2500), r.RdfsPerSec)
+
+	// Ending the last phase records it too.
+	p.startPhase("")
+	r = p.report(client.Counter{})
+	require.Equal(t, "", r.Phase)
+	require.Len(t, r.Phases, 2)
+	require.Equal(t, "load", r.Phases[1].Phase)
+}
+
+func TestProgressBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "data.rdf")
+	require.NoError(t, ioutil.WriteFile(file, []byte(strings.Repeat("a", 300)), 0644))
+
+	p := &progress{start: time.Now()}
+	// Files which can't be read are left out.
+	p.addFiles([]string{file, filepath.Join(dir, "missing.rdf")})
+	p.startPhase("load")
+
+	b := make([]byte, 100)
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = p.reader(f).Read(b)
+	require.NoError(t, err)
+
+	r := p.report(client.Counter{})
+	require.Equal(t, int64(100), r.BytesRead)
+	require.Equal(t, int64(300), r.BytesTotal)
+	require.NotEmpty(t, r.ETA)
+}
+
+func TestProgressETA(t *testing.T) {
+	p := &progress{start: time.Now()}
+	p.startPhase("load")
+	p.began = time.Now().Add(-10 * time.Second)
+	p.bytesRead, p.bytesTotal = 100, 300
+
+	// The other two thirds of the input take twice as long as the first third.
+	r := p.report(client.Counter{})
+	require.Equal(t, "20s", r.ETA)
+
+	// There's only an estimate while loading, and before all of the input is read.
+	p.bytesRead = 300
+	require.Empty(t, p.report(client.Counter{}).ETA)
+	p.bytesRead = 100
+	p.startPhase("indexes")
+	require.Empty(t, p.report(client.Counter{}).ETA)
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var (
+	restoreDir = flag.String("restore", "",
+		"Backup folder of a group, to restore predicates from into a running cluster")
+	restorePreds = flag.String("restore_predicates", "",
+		"Predicates to restore, comma separated, or all of those in the backup")
+	conflict = flag.String("conflict", "merge",
+		"What to do with existing data of restored predicates: merge, overwrite, keep or fail")
+)
+
+// A partial restore loads the predicates of a chain of backups into the cluster it was taken of,
+// onto the nodes they were on, leaving the other predicates as they are. The state of a posting
+// list is the one in the latest backup which has it, or which deletes it, so the files are first
+// read from the newest backup back to the latest full one to find which backup each posting list
+// is restored from, and then the data is set under the conflict policy:
+//   merge:     the restored edges are set over the existing ones.
+//   overwrite: the existing edges of restored posting lists are deleted first, and so are the
+//              posting lists deleted in the chain.
+//   keep:      the posting lists of nodes which already have the predicate are skipped.
+//   fail:      nothing is restored if any node already has a restored predicate.
+// Restoring again is safe, so interrupted restores are just run again.
+
+const (
+	restoreRemoved = -1
+	restoreSkipped = -2
+)
+
+type restoreBackup struct {
+	Full    bool   `json:"full"`
+	Data    string `json:"data"`
+	Schema  string `json:"schema"`
+	Deletes string `json:"deletes"`
+}
+
+type restoreKey struct {
+	subject string
+	pred    string
+}
+
+// restoreCluster is the cluster the data is restored into.
+type restoreCluster interface {
+	// node returns the node of the cluster for a node of the backup.
+	node(n string) (string, error)
+	// has returns the uids of the nodes among uids which have the predicate pred.
+	has(ctx context.Context, pred string, uids []string) ([]uint64, error)
+	// run runs the mutations of req.
+	run(ctx context.Context, req *client.Req) error
+	// set adds the edge e to the batch of mutations sent to the cluster.
+	set(e client.Edge) error
+}
+
+type dgraphCluster struct {
+	c *client.Dgraph
+}
+
+// node maps the nodes of the backup, which have their uids, to the same uids.
+func (d dgraphCluster) node(n string) (string, error) {
+	if strings.HasPrefix(n, "_:uid") {
+		uid, err := strconv.ParseUint(n[len("_:uid"):], 16, 64)
+		if err == nil {
+			return d.c.NodeUid(uid).String(), nil
+		}
+	}
+	return Node(n, d.c)
+}
+
+func (d dgraphCluster) has(ctx context.Context, pred string, uids []string) ([]uint64, error) {
+	req := new(client.Req)
+	req.SetQuery(fmt.Sprintf("{ q(func: uid(%s)) @filter(has(%s)) { _uid_ } }",
+		strings.Join(uids, ","), pred))
+	resp, err := d.c.Run(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Q []struct {
+			Uid uint64 `dgraph:"_uid_"`
+		} `dgraph:"q"`
+	}
+	if err := client.Unmarshal(resp.N, &res); err != nil {
+		return nil, err
+	}
+	has := make([]uint64, 0, len(res.Q))
+	for _, n := range res.Q {
+		has = append(has, n.Uid)
+	}
+	return has, nil
+}
+
+func (d dgraphCluster) run(ctx context.Context, req *client.Req) error {
+	_, err := d.c.Run(ctx, req)
+	return err
+}
+
+func (d dgraphCluster) set(e client.Edge) error {
+	return d.c.BatchSet(e)
+}
+
+type restorer struct {
+	dir     string
+	backups []restoreBackup
+	preds   map[string]bool
+	policy  string
+	// The backup each posting list is restored from, or restoreRemoved or restoreSkipped.
+	keys    map[restoreKey]int
+	cluster restoreCluster
+}
+
+func newRestorer(dir string, cluster restoreCluster) (*restorer, error) {
+	switch *conflict {
+	case "merge", "overwrite", "keep", "fail":
+	default:
+		return nil, x.Errorf("Invalid conflict policy: %q. Use merge, overwrite, keep or fail",
+			*conflict)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, x.Wrapf(err, "While reading the backup manifest")
+	}
+	var m struct {
+		Backups []restoreBackup `json:"backups"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, x.Wrapf(err, "While parsing the backup manifest")
+	}
+	// Only the backups from the latest full one are needed.
+	start := -1
+	for i, b := range m.Backups {
+		if b.Full {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, x.Errorf("No full backup in: %v", dir)
+	}
+	r := &restorer{
+		dir:     dir,
+		backups: m.Backups[start:],
+		policy:  *conflict,
+		keys:    make(map[restoreKey]int),
+		cluster: cluster,
+	}
+	if len(*restorePreds) > 0 {
+		r.preds = make(map[string]bool)
+		for _, p := range strings.Split(*restorePreds, ",") {
+			r.preds[strings.TrimSpace(p)] = true
+		}
+	}
+	return r, nil
+}
+
+// scan calls fn with the N-Quads of the restored predicates in the file name of the backup.
+func (r *restorer) scan(ctx context.Context, name string, fn func(nq protos.NQuad) error) error {
+	f, err := os.Open(filepath.Join(r.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ar, err := artifact.NewReader(bufio.NewReader(prog.reader(f)), name)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReader(ar)
+	var buf bytes.Buffer
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := readLine(br, &buf)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		nq, err := rdf.Parse(buf.String())
+		buf.Reset()
+		if err == rdf.ErrEmpty {
+			continue
+		} else if err != nil {
+			return x.Wrapf(err, "While parsing %s", name)
+		}
+		if r.preds != nil && !r.preds[nq.Predicate] {
+			continue
+		}
+		if err := fn(nq); err != nil {
+			return err
+		}
+	}
+}
+
+// findKeys finds the backup each posting list is restored from.
+func (r *restorer) findKeys(ctx context.Context) error {
+	for i := len(r.backups) - 1; i >= 0; i-- {
+		b := r.backups[i]
+		cur := make(map[restoreKey]int)
+		if err := r.scan(ctx, b.Data, func(nq protos.NQuad) error {
+			cur[restoreKey{nq.Subject, nq.Predicate}] = i
+			return nil
+		}); err != nil {
+			return err
+		}
+		if b.Deletes != "" {
+			if err := r.scan(ctx, b.Deletes, func(nq protos.NQuad) error {
+				k := restoreKey{nq.Subject, nq.Predicate}
+				if _, ok := cur[k]; !ok {
+					cur[k] = restoreRemoved
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		for k, v := range cur {
+			if _, ok := r.keys[k]; !ok {
+				r.keys[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// deleteExisting deletes the existing edges of the restored posting lists.
+func (r *restorer) deleteExisting(ctx context.Context) error {
+	req := new(client.Req)
+	var n int
+	for k, v := range r.keys {
+		if v == restoreSkipped {
+			continue
+		}
+		s, err := r.cluster.node(k.subject)
+		if err != nil {
+			return err
+		}
+		nq := protos.NQuad{
+			Subject:     s,
+			Predicate:   k.pred,
+			ObjectValue: &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: x.Star}},
+		}
+		if err := req.Delete(client.NewEdge(nq)); err != nil {
+			return err
+		}
+		if n++; n >= *numRdf {
+			if err := r.cluster.run(ctx, req); err != nil {
+				return err
+			}
+			req, n = new(client.Req), 0
+		}
+	}
+	if n > 0 {
+		return r.cluster.run(ctx, req)
+	}
+	return nil
+}
+
+// existing returns the restored posting lists of nodes which already have the predicate.
+func (r *restorer) existing(ctx context.Context) ([]restoreKey, error) {
+	nodes := make(map[string][]restoreKey)
+	for k, v := range r.keys {
+		if v >= 0 {
+			nodes[k.pred] = append(nodes[k.pred], k)
+		}
+	}
+	var existing []restoreKey
+	for pred, keys := range nodes {
+		for len(keys) > 0 {
+			batch := keys
+			if len(batch) > *numRdf {
+				batch = batch[:*numRdf]
+			}
+			keys = keys[len(batch):]
+
+			uids := make([]string, 0, len(batch))
+			byUid := make(map[uint64]restoreKey)
+			for _, k := range batch {
+				s, err := r.cluster.node(k.subject)
+				if err != nil {
+					return nil, err
+				}
+				uid, err := strconv.ParseUint(s, 0, 64)
+				if err != nil {
+					continue
+				}
+				uids = append(uids, s)
+				byUid[uid] = k
+			}
+			if len(uids) == 0 {
+				continue
+			}
+			has, err := r.cluster.has(ctx, pred, uids)
+			if err != nil {
+				return nil, err
+			}
+			for _, uid := range has {
+				if k, ok := byUid[uid]; ok {
+					existing = append(existing, k)
+				}
+			}
+		}
+	}
+	return existing, nil
+}
+
+// skipExisting skips the posting lists of nodes which already have the predicate.
+func (r *restorer) skipExisting(ctx context.Context) error {
+	existing, err := r.existing(ctx)
+	if err != nil {
+		return err
+	}
+	for _, k := range existing {
+		r.keys[k] = restoreSkipped
+	}
+	return nil
+}
+
+// failExisting fails if any node already has a restored predicate.
+func (r *restorer) failExisting(ctx context.Context) error {
+	existing, err := r.existing(ctx)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return x.Errorf("%d restored posting lists already exist, like %s of %s",
+			len(existing), existing[0].pred, existing[0].subject)
+	}
+	return nil
+}
+
+// applySchema applies the schema of the restored predicates in the latest backup.
+func (r *restorer) applySchema(ctx context.Context, c *client.Dgraph) error {
+	updates, typs, err := readSchemaFile(filepath.Join(r.dir, r.backups[len(r.backups)-1].Schema))
+	if err != nil {
+		return err
+	}
+	var restored []*protos.SchemaUpdate
+	for _, u := range updates {
+		if r.preds == nil || r.preds[u.Predicate] {
+			restored = append(restored, u)
+		}
+	}
+	steps := schemaSteps(restored, false)
+	if r.preds == nil {
+		// Types are only declared again by restores of all the predicates.
+		steps = append(steps, typeStep(typs)...)
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	return applySchemaSteps(ctx, steps, c)
+}
+
+func (r *restorer) setData(ctx context.Context) error {
+	for i, b := range r.backups {
+		fmt.Printf("\nRestoring from %s\n", b.Data)
+		if err := r.scan(ctx, b.Data, func(nq protos.NQuad) error {
+			if r.keys[restoreKey{nq.Subject, nq.Predicate}] != i {
+				return nil
+			}
+			var err error
+			if nq.Subject, err = r.cluster.node(nq.Subject); err != nil {
+				return err
+			}
+			if len(nq.ObjectId) > 0 {
+				if nq.ObjectId, err = r.cluster.node(nq.ObjectId); err != nil {
+					return err
+				}
+			}
+			return r.cluster.set(client.NewEdge(nq))
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepare finds where each posting list is restored from, and deals with the existing data under
+// the conflict policy.
+func (r *restorer) prepare(ctx context.Context) error {
+	if err := r.findKeys(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("\nRestoring %d posting lists from %d backups\n", len(r.keys), len(r.backups))
+	switch r.policy {
+	case "overwrite":
+		return r.deleteExisting(ctx)
+	case "keep":
+		return r.skipExisting(ctx)
+	case "fail":
+		return r.failExisting(ctx)
+	}
+	return nil
+}
+
+// restore restores the chosen predicates from the backups of a group.
+func restore(ctx context.Context, dir string, c *client.Dgraph) error {
+	r, err := newRestorer(dir, dgraphCluster{c})
+	if err != nil {
+		return err
+	}
+	// The data is read twice, first to find where each posting list is restored from.
+	for _, b := range r.backups {
+		prog.addFiles([]string{filepath.Join(dir, b.Data), filepath.Join(dir, b.Data)})
+		if b.Deletes != "" {
+			prog.addFiles([]string{filepath.Join(dir, b.Deletes)})
+		}
+	}
+	if err := r.prepare(ctx); err != nil {
+		return err
+	}
+	if err := r.applySchema(ctx, c); err != nil {
+		return err
+	}
+	return r.setData(ctx)
+}
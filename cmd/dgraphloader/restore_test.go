@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// testCluster is a cluster with the posting lists in existing, as the uid and predicate of each,
+// which records the posting lists deleted and the edges set by restores.
+type testCluster struct {
+	existing map[string]bool
+	deleted  []string
+	sets     []string
+}
+
+func (c *testCluster) node(n string) (string, error) {
+	uid, err := strconv.ParseUint(strings.TrimPrefix(n, "_:uid"), 16, 64)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%#x", uid), nil
+}
+
+func (c *testCluster) has(ctx context.Context, pred string, uids []string) ([]uint64, error) {
+	var has []uint64
+	for _, s := range uids {
+		if c.existing[s+" "+pred] {
+			uid, err := strconv.ParseUint(s, 0, 64)
+			if err != nil {
+				return nil, err
+			}
+			has = append(has, uid)
+		}
+	}
+	return has, nil
+}
+
+func (c *testCluster) run(ctx context.Context, req *client.Req) error {
+	for _, nq := range req.Request().GetMutation().GetDel() {
+		c.deleted = append(c.deleted, nq.Subject+" "+nq.Predicate)
+	}
+	return nil
+}
+
+func (c *testCluster) set(e client.Edge) error {
+	req := new(client.Req)
+	if err := req.Set(e); err != nil {
+		return err
+	}
+	nq := req.Request().Mutation.Set[0]
+	obj := nq.ObjectId
+	switch v := nq.ObjectValue.GetVal().(type) {
+	case *protos.Value_DefaultVal:
+		obj = v.DefaultVal
+	case *protos.Value_IntVal:
+		obj = strconv.FormatInt(v.IntVal, 10)
+	}
+	c.sets = append(c.sets, nq.Subject+" "+nq.Predicate+" "+obj)
+	return nil
+}
+
+func testRestore(t *testing.T, policy, preds string, c *testCluster) error {
+	defer func(c, p string) { *conflict, *restorePreds = c, p }(*conflict, *restorePreds)
+	*conflict, *restorePreds = policy, preds
+
+	r, err := newRestorer("testdata/backup", c)
+	if err != nil {
+		return err
+	}
+	// Only the backups from the latest full one are read.
+	require.Len(t, r.backups, 2)
+	if err := r.prepare(context.Background()); err != nil {
+		return err
+	}
+	if err := r.setData(context.Background()); err != nil {
+		return err
+	}
+	sort.Strings(c.deleted)
+	sort.Strings(c.sets)
+	return nil
+}
+
+func TestRestoreMerge(t *testing.T) {
+	c := &testCluster{existing: map[string]bool{"0x1 name": true, "0x3 name": true}}
+	require.NoError(t, testRestore(t, "merge", "name, friend", c))
+	require.Empty(t, c.deleted)
+	require.Equal(t, []string{"0x1 name Alicia", "0x2 friend 0x1", "0x2 name Bob",
+		"0x4 name Dave"}, c.sets)
+
+	// All of the predicates in the backups are restored if none are chosen.
+	c = &testCluster{}
+	require.NoError(t, testRestore(t, "merge", "", c))
+	require.Equal(t, []string{"0x1 age 30", "0x1 name Alicia", "0x2 friend 0x1", "0x2 name Bob",
+		"0x4 name Dave"}, c.sets)
+}
+
+func TestRestoreOverwrite(t *testing.T) {
+	c := &testCluster{existing: map[string]bool{"0x1 name": true, "0x3 name": true}}
+	require.NoError(t, testRestore(t, "overwrite", "name, friend", c))
+	// The posting lists deleted in the chain of backups are deleted too.
+	require.Equal(t, []string{"0x1 name", "0x2 friend", "0x2 name", "0x3 name", "0x4 name"},
+		c.deleted)
+	require.Equal(t, []string{"0x1 name Alicia", "0x2 friend 0x1", "0x2 name Bob",
+		"0x4 name Dave"}, c.sets)
+}
+
+func TestRestoreKeep(t *testing.T) {
+	c := &testCluster{existing: map[string]bool{"0x1 name": true, "0x2 age": true}}
+	require.NoError(t, testRestore(t, "keep", "", c))
+	require.Empty(t, c.deleted)
+	require.Equal(t, []string{"0x1 age 30", "0x2 friend 0x1", "0x2 name Bob", "0x4 name Dave"},
+		c.sets)
+}
+
+func TestRestoreFail(t *testing.T) {
+	c := &testCluster{existing: map[string]bool{"0x4 name": true}}
+	err := testRestore(t, "fail", "name", c)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "name of _:uid4")
+	require.Empty(t, c.deleted)
+	require.Empty(t, c.sets)
+
+	// Nodes which have predicates that aren't restored, or which the backups delete, don't fail.
+	c = &testCluster{existing: map[string]bool{"0x1 age": true, "0x3 name": true}}
+	require.NoError(t, testRestore(t, "fail", "name", c))
+	require.Equal(t, []string{"0x1 name Alicia", "0x2 name Bob", "0x4 name Dave"}, c.sets)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	require.Error(t, testRestore(t, "replace", "", &testCluster{}))
+
+	defer func(c string) { *conflict = c }(*conflict)
+	*conflict = "merge"
+	_, err := newRestorer("testdata", &testCluster{})
+	require.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/client"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var deferIndex = flag.Bool("defer_index", false,
+	"Apply only the types of the schema before loading, and build its indexes after the data")
+
+// The schema is applied a predicate at a time, so that the indexes it asks for are built one after
+// the other, with progress, rather than in a single request which blocks until all of them are.
+// Reverse edges are built first, as the count index of a reversed predicate counts them too, then
+// the tokenizer indexes and last the count indexes.
+//
+// With -defer_index, only the types are applied before the data is loaded, and the indexes are
+// built once it is, which is quicker than keeping them up to date with every mutation. The steps
+// done are recorded in the client directory, so that a resumed load doesn't drop the indexes
+// already built. The types of nodes declared by the schema are declared in a step of their own,
+// before the data is loaded.
+
+const schemaStepsFile = "schema-steps"
+
+const (
+	buildTypes = iota
+	buildReverse
+	buildIndex
+	buildCount
+	declareTypes
+)
+
+var buildNames = []string{"types", "reverse edges", "index", "count index", "type declarations"}
+
+type schemaStep struct {
+	kind  int
+	preds []string
+	line  string
+}
+
+// schemaLine returns the schema of predicate u, with the directives built up to step kind.
+func schemaLine(u *protos.SchemaUpdate, kind int) string {
+	var buf bytes.Buffer
+	if strings.ContainsRune(u.Predicate, ':') {
+		buf.WriteString("<" + u.Predicate + ">")
+	} else {
+		buf.WriteString(u.Predicate)
+	}
+	buf.WriteByte(':')
+	name := types.TypeID(u.ValueType).Name()
+	if u.List {
+		name = "[" + name + "]"
+	}
+	buf.WriteString(name)
+	if u.Directive == protos.SchemaUpdate_REVERSE && kind >= buildReverse {
+		buf.WriteString(" @reverse")
+	}
+	if u.Directive == protos.SchemaUpdate_INDEX && len(u.Tokenizer) > 0 && kind >= buildIndex {
+		buf.WriteString(" @index(" + strings.Join(schema.Tokenizers(u.Tokenizer, u.Geo), ",") + ")")
+	}
+	if u.Count && kind >= buildCount {
+		buf.WriteString(" @count")
+	}
+	buf.WriteString(" .")
+	return buf.String()
+}
+
+// schemaSteps returns the steps which apply the schema updates in order. If deferred, they start
+// with one applying the types of all of the predicates, without any index.
+func schemaSteps(updates []*protos.SchemaUpdate, deferred bool) []schemaStep {
+	var steps []schemaStep
+	if deferred {
+		s := schemaStep{kind: buildTypes}
+		var lines []string
+		for _, u := range updates {
+			s.preds = append(s.preds, u.Predicate)
+			lines = append(lines, schemaLine(u, buildTypes))
+		}
+		s.line = strings.Join(lines, "\n")
+		steps = append(steps, s)
+	}
+	for kind := buildReverse; kind <= buildCount; kind++ {
+		for _, u := range updates {
+			var needed bool
+			switch kind {
+			case buildReverse:
+				needed = u.Directive == protos.SchemaUpdate_REVERSE
+			case buildIndex:
+				needed = u.Directive == protos.SchemaUpdate_INDEX && len(u.Tokenizer) > 0
+			case buildCount:
+				needed = u.Count
+			}
+			if needed {
+				steps = append(steps, schemaStep{
+					kind:  kind,
+					preds: []string{u.Predicate},
+					line:  schemaLine(u, kind),
+				})
+			}
+		}
+	}
+	// Predicates without any index are applied in a single step.
+	if !deferred {
+		s := schemaStep{kind: buildTypes}
+		var lines []string
+		for _, u := range updates {
+			if u.Directive == protos.SchemaUpdate_NONE && !u.Count {
+				s.preds = append(s.preds, u.Predicate)
+				lines = append(lines, schemaLine(u, buildTypes))
+			}
+		}
+		if len(lines) > 0 {
+			s.line = strings.Join(lines, "\n")
+			steps = append([]schemaStep{s}, steps...)
+		}
+	}
+	return steps
+}
+
+// typeStep returns the step declaring the types typs, if there are any.
+func typeStep(typs []*protos.TypeUpdate) []schemaStep {
+	if len(typs) == 0 {
+		return nil
+	}
+	s := schemaStep{kind: declareTypes}
+	var lines []string
+	for _, typ := range typs {
+		s.preds = append(s.preds, typ.TypeName)
+		lines = append(lines, schema.TypeLine(typ))
+	}
+	s.line = strings.Join(lines, "\n")
+	return []schemaStep{s}
+}
+
+// readSchemaFile parses the schema in file, which can be gzipped and encrypted, and returns the
+// schema of its predicates and the types it declares.
+func readSchemaFile(file string) ([]*protos.SchemaUpdate, []*protos.TypeUpdate, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	reader, err := artifact.NewReader(f, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, x.Wrapf(err, "Error while reading file")
+	}
+	updates, typs, err := schema.ParseWithTypes(string(b))
+	if err != nil {
+		return nil, nil, x.Wrapf(err, "While parsing schema file: %v", file)
+	}
+	// The steps of a predicate defined twice would build indexes only to drop them.
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		if seen[u.Predicate] {
+			return nil, nil, x.Errorf("Predicate %s defined twice in schema file: %v",
+				u.Predicate, file)
+		}
+		seen[u.Predicate] = true
+	}
+	return updates, typs, nil
+}
+
+// schemaStepsDone returns the lines of the steps already applied, as recorded in the client
+// directory.
+func schemaStepsDone() (map[string]bool, error) {
+	done := make(map[string]bool)
+	f, err := os.Open(filepath.Join(*clientDir, schemaStepsFile))
+	if os.IsNotExist(err) {
+		return done, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		done[strings.Replace(scanner.Text(), `\n`, "\n", -1)] = true
+	}
+	return done, scanner.Err()
+}
+
+func recordSchemaStep(s schemaStep) error {
+	if err := os.MkdirAll(*clientDir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(*clientDir, schemaStepsFile),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, strings.Replace(s.line, "\n", `\n`, -1)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// applySchemaSteps applies the steps to Dgraph in order, blocking until each is done, and skips
+// the ones recorded as done.
+func applySchemaSteps(ctx context.Context, steps []schemaStep, dgraphClient *client.Dgraph) error {
+	done, err := schemaStepsDone()
+	if err != nil {
+		return err
+	}
+	prog.addSchemaSteps(len(steps))
+	for i, s := range steps {
+		what := buildNames[s.kind]
+		if s.kind == buildTypes {
+			fmt.Printf("Applying the types of %d predicates (%d/%d)\n", len(s.preds), i+1, len(steps))
+		} else if s.kind == declareTypes {
+			fmt.Printf("Declaring %d types of nodes (%d/%d)\n", len(s.preds), i+1, len(steps))
+		} else {
+			fmt.Printf("Building %s of %s (%d/%d)\n", what, s.preds[0], i+1, len(steps))
+		}
+		if !done[s.line] {
+			if err := dgraphClient.SetSchemaBlocking(ctx, s.line); err != nil {
+				return err
+			}
+			if err := recordSchemaStep(s); err != nil {
+				return err
+			}
+		}
+		prog.schemaStepDone()
+	}
+	return nil
+}
+
+// clearSchemaSteps forgets the steps done, once the whole schema has been applied.
+func clearSchemaSteps() error {
+	err := os.Remove(filepath.Join(*clientDir, schemaStepsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+name: string @index(exact, term) @count .
+friend: uid @reverse @count .
+loc: geo @index(geo(minlevel=8, maxlevel=20, maxcells=18)) .
+age: int .
+<http://schema.org/name>: string .
+type Person {
+	name
+	friend
+}
+`
+
+// writeSchemaFile writes s to the file name in dir, gzipped if name ends with .gz.
+func writeSchemaFile(t *testing.T, dir, name, s string) string {
+	file := filepath.Join(dir, name)
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+	if filepath.Ext(name) == ".gz" {
+		w := gzip.NewWriter(f)
+		_, err = w.Write([]byte(s))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	} else {
+		_, err = f.WriteString(s)
+		require.NoError(t, err)
+	}
+	return file
+}
+
+func stepLines(steps []schemaStep) []string {
+	var lines []string
+	for _, s := range steps {
+		lines = append(lines, s.line)
+	}
+	return lines
+}
+
+func TestSchemaSteps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"dgraph.schema", "dgraph.schema.gz"} {
+		updates, typs, err := readSchemaFile(writeSchemaFile(t, dir, name, testSchema))
+		require.NoError(t, err, name)
+
+		// Reverse edges come before the indexes, and count indexes last.
+		require.Equal(t, []string{
+			"age:int .\n<http://schema.org/name>:string .",
+			"friend:uid @reverse .",
+			"name:string @index(exact,term) .",
+			"loc:geo @index(geo(minlevel=8,maxlevel=20,maxcells=18)) .",
+			"name:string @index(exact,term) @count .",
+			"friend:uid @reverse @count .",
+		}, stepLines(schemaSteps(updates, false)), name)
+
+		steps := schemaSteps(updates, true)
+		require.Equal(t, buildTypes, steps[0].kind)
+		require.Equal(t, []string{"name", "friend", "loc", "age", "http://schema.org/name"},
+			steps[0].preds)
+		require.Equal(t, []string{
+			"name:string .\nfriend:uid .\nloc:geo .\nage:int .\n<http://schema.org/name>:string .",
+			"friend:uid @reverse .",
+			"name:string @index(exact,term) .",
+			"loc:geo @index(geo(minlevel=8,maxlevel=20,maxcells=18)) .",
+			"name:string @index(exact,term) @count .",
+			"friend:uid @reverse @count .",
+		}, stepLines(steps), name)
+
+		require.Equal(t, []string{"type Person { name, friend }"}, stepLines(typeStep(typs)))
+	}
+}
+
+func TestSchemaConflicting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, s := range []string{
+		"name: string .\nname: int .",
+		"name: string @index(exact) .\nname: string @index(term) .",
+		"friend: string @reverse .",
+		"friend: uid @index(exact) .",
+		"age: int @index(term) .",
+		"name: string @index(exact, exact) .",
+		"born: dateTime @index(year, month) .",
+		"type Person { name }\ntype Person { age }",
+		"type Person { name, name }",
+	} {
+		_, _, err := readSchemaFile(writeSchemaFile(t, dir, "dgraph.schema", s))
+		require.Error(t, err, s)
+	}
+}
+
+func TestSchemaMalformed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, s := range []string{
+		"name string .",
+		"name: strin .",
+		"name: string @index(exact .",
+		"name: string @index .",
+		"name: string @unknown .",
+		"name: [string .",
+		"123: string .",
+		"loc: geo @index(geo(maxlevel=twenty)) .",
+		"type Person {",
+		"type Person { }",
+	} {
+		_, _, err := readSchemaFile(writeSchemaFile(t, dir, "dgraph.schema", s))
+		require.Error(t, err, s)
+	}
+
+	// Files which aren't gzipped, though named like they are.
+	_, _, err = readSchemaFile(writeSchemaFile(t, dir, "dgraph.schema", "name: string ."))
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(filepath.Join(dir, "dgraph.schema"),
+		filepath.Join(dir, "plain.schema.gz")))
+	_, _, err = readSchemaFile(filepath.Join(dir, "plain.schema.gz"))
+	require.Error(t, err)
+
+	_, _, err = readSchemaFile(filepath.Join(dir, "missing.schema"))
+	require.Error(t, err)
+}
+
+func TestSchemaStepsDone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(d string) { *clientDir = d }(*clientDir)
+	*clientDir = filepath.Join(dir, "c")
+
+	done, err := schemaStepsDone()
+	require.NoError(t, err)
+	require.Empty(t, done)
+
+	steps := []schemaStep{
+		{kind: buildTypes, line: "age:int .\nname:string ."},
+		{kind: buildIndex, line: "name:string @index(exact) ."},
+	}
+	for _, s := range steps {
+		require.NoError(t, recordSchemaStep(s))
+	}
+	done, err = schemaStepsDone()
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{steps[0].line: true, steps[1].line: true}, done)
+
+	require.NoError(t, clearSchemaSteps())
+	require.NoError(t, clearSchemaSteps())
+	done, err = schemaStepsDone()
+	require.NoError(t, err)
+	require.Empty(t, done)
+}
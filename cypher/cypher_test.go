@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cypher
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/gql"
+)
+
+func TestLexerNumbers(t *testing.T) {
+	l := NewLexer(strings.NewReader("1..3 2.5 1e-3 0x1f"))
+	var toks []string
+	for {
+		tok, err := l.Next()
+		require.NoError(t, err)
+		if tok.Kind == TokEOF {
+			break
+		}
+		toks = append(toks, tok.Text)
+	}
+	require.Equal(t, []string{"1", ".", ".", "3", "2.5", "1e-3", "0x1f"}, toks)
+}
+
+func translate(t *testing.T, q string) *Translation {
+	query, err := Parse(q)
+	require.NoError(t, err)
+	tr, err := query.Translate(Options{Label: "label"})
+	require.NoError(t, err)
+	// The translation has to be a valid query.
+	_, err = gql.Parse(gql.Request{Str: tr.Query, Variables: tr.Vars})
+	require.NoError(t, err, tr.Query)
+	return tr
+}
+
+func TestTranslate(t *testing.T) {
+	tr := translate(t, `MATCH (a:Person {name: "Alice"})-[:KNOWS*1..2]->(b)
+		WHERE b.age >= 30 AND NOT b.name IN ['Bob', "Carol"] RETURN a.name, b.name AS friend`)
+	require.Equal(t, `query cypher($v0: string, $v1: string, $v2: string, $v3: string, $v4: string) {
+  cypher(func: eq(name, $v0)) @filter(eq(label, $v1)) {
+    _uid_
+    p0_0 : name
+    r0_1 : KNOWS @filter(ge(age, $v2) and not (eq(name, $v3) or eq(name, $v4))) {
+      _uid_
+      p1_0 : name
+    }
+    h0_2_1 : KNOWS {
+      _uid_
+      r0_2 : KNOWS @filter(ge(age, $v2) and not (eq(name, $v3) or eq(name, $v4))) {
+        _uid_
+        p1_0 : name
+      }
+    }
+  }
+}
+`, tr.Query)
+	require.Equal(t, map[string]string{"$v0": "Alice", "$v1": "Person", "$v2": "30",
+		"$v3": "Bob", "$v4": "Carol"}, tr.Vars)
+}
+
+func TestTranslateRoot(t *testing.T) {
+	tr := translate(t, `MATCH (a)<-[:OWNS]-(b) WHERE id(a) = 0x1f AND a.name IS NOT NULL
+		RETURN b`)
+	require.Equal(t, `{
+  cypher(func: uid(0x1f)) @filter(has(name)) {
+    _uid_
+    r0_1 : ~OWNS {
+      _uid_
+      expand(_all_)
+    }
+  }
+}
+`, tr.Query)
+
+	tr = translate(t, `MATCH (a:Person)-[:KNOWS]->(b) WHERE a.age > 30 RETURN b.name`)
+	require.Contains(t, tr.Query, "cypher(func: eq(label, $v0)) @filter(gt(age, $v1))")
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{
+		`MATCH (a), (b) RETURN a`,
+		`OPTIONAL MATCH (a) RETURN a`,
+		`MATCH (a)-[:KNOWS]-(b) RETURN a`,
+		`MATCH (a)-->(b) RETURN a`,
+		`MATCH (a)-[:KNOWS*]->(b) RETURN a`,
+		`MATCH (a)-[:KNOWS*1..11]->(b) RETURN a`,
+		`MATCH (a)-[r:KNOWS {since: 2010}]->(b) RETURN a`,
+		`MATCH (a)-[:KNOWS]->(a) RETURN a`,
+		`MATCH (a) RETURN b`,
+		`MATCH (a) RETURN a ORDER BY a.name`,
+		`MATCH (a) WHERE a.name =~ "A.*" RETURN a`,
+		`MATCH (a)-[:KNOWS]->(b) WHERE a.age = b.age RETURN a`,
+		`MATCH (a) RETURN a UNION MATCH (b) RETURN b`,
+	} {
+		_, err := Parse(q)
+		require.Error(t, err, q)
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	for _, q := range []string{
+		// The first node needs a condition an index can be used for.
+		`MATCH (a)-[:KNOWS]->(b {name: "Bob"}) RETURN a`,
+		`MATCH (a:Person)-[:KNOWS]->(b) WHERE a.age = 30 OR b.age = 30 RETURN a`,
+		`MATCH (a:Person)-[:KNOWS*1..10]->()-[:KNOWS*1..10]->()-[:KNOWS*1..2]->(b) RETURN b`,
+	} {
+		query, err := Parse(q)
+		require.NoError(t, err)
+		_, err = query.Translate(Options{Label: "label"})
+		require.Error(t, err, q)
+	}
+}
+
+func TestRows(t *testing.T) {
+	tr := translate(t, `MATCH (a {name: "Alice"})-[:KNOWS*1..2]->(b) RETURN DISTINCT a.name,
+		b.name AS friend, b.age ORDER BY b.age DESC, friend SKIP 1 LIMIT 2`)
+	res, err := tr.Rows([]byte(`{"cypher":[{"_uid_":"0x1","p0_0":"Alice",
+		"r0_1":[{"_uid_":"0x2","p1_0":"Bob","p1_1":30},{"_uid_":"0x3","p1_0":"Carol"}],
+		"h0_2_1":[{"_uid_":"0x2","r0_2":[{"_uid_":"0x4","p1_0":"Dave","p1_1":40},
+			{"_uid_":"0x5","p1_0":"Eve","p1_1":30}]},
+			{"_uid_":"0x3","r0_2":[{"_uid_":"0x5","p1_0":"Eve","p1_1":30}]}]}]}`))
+	require.NoError(t, err)
+	js, err := json.Marshal(res)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"columns":["a.name","friend","b.age"],
+		"rows":[["Alice","Dave",40],["Alice","Bob",30]]}`, string(js))
+
+	tr = translate(t, `MATCH (a {name: "Alice"})-[:KNOWS]->(b) RETURN b`)
+	res, err = tr.Rows([]byte(`{"cypher":[{"_uid_":"0x1",
+		"r0_1":[{"_uid_":"0x2","name":"Bob"}]}]}`))
+	require.NoError(t, err)
+	js, err = json.Marshal(res)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"columns":["b"],"rows":[[{"_uid_":"0x2","name":"Bob"}]]}`, string(js))
+
+	res, err = tr.Rows([]byte(`{}`))
+	require.NoError(t, err)
+	require.Empty(t, res.Rows)
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cypher
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"unicode"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	TokEOF = iota
+	TokIdent
+	TokQuoted // An identifier in backquotes.
+	TokString
+	TokNumber
+	TokPunct
+)
+
+// Token is a token of Cypher. Punctuation is a token per character.
+type Token struct {
+	Kind int
+	Text string
+}
+
+// Lexer splits Cypher into tokens, skipping spaces and comments.
+type Lexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func NewLexer(r io.Reader) *Lexer {
+	return &Lexer{r: bufio.NewReader(r), line: 1}
+}
+
+// Line returns the line of the last token read.
+func (l *Lexer) Line() int {
+	return l.line
+}
+
+func (l *Lexer) read() (rune, error) {
+	c, _, err := l.r.ReadRune()
+	if c == '\n' {
+		l.line++
+	}
+	return c, err
+}
+
+func (l *Lexer) unread(c rune) {
+	if c == '\n' {
+		l.line--
+	}
+	x.Check(l.r.UnreadRune())
+}
+
+func (l *Lexer) skipSpace() error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return err
+		}
+		if unicode.IsSpace(c) {
+			continue
+		}
+		if c != '/' {
+			l.unread(c)
+			return nil
+		}
+		n, err := l.read()
+		if err != nil {
+			return err
+		}
+		switch n {
+		case '/':
+			for c != '\n' {
+				if c, err = l.read(); err != nil {
+					return err
+				}
+			}
+		case '*':
+			for prev := rune(0); prev != '*' || c != '/'; {
+				prev = c
+				if c, err = l.read(); err != nil {
+					return err
+				}
+			}
+		default:
+			l.unread(n)
+			return x.Errorf("Unexpected /")
+		}
+	}
+}
+
+// Next returns the next token, with kind TokEOF at the end of the input.
+func (l *Lexer) Next() (Token, error) {
+	if err := l.skipSpace(); err == io.EOF {
+		return Token{Kind: TokEOF}, nil
+	} else if err != nil {
+		return Token{}, err
+	}
+	c, err := l.read()
+	if err != nil {
+		return Token{}, err
+	}
+	var buf bytes.Buffer
+	switch {
+	case c == '`':
+		for {
+			if c, err = l.read(); err != nil {
+				return Token{}, x.Errorf("Unterminated identifier")
+			}
+			if c == '`' {
+				// Backquotes are escaped by doubling them.
+				if n, err := l.read(); err != nil || n != '`' {
+					if err == nil {
+						l.unread(n)
+					}
+					return Token{Kind: TokQuoted, Text: buf.String()}, nil
+				}
+			}
+			buf.WriteRune(c)
+		}
+	case c == '"' || c == '\'':
+		quote := c
+		for {
+			if c, err = l.read(); err != nil {
+				return Token{}, x.Errorf("Unterminated string")
+			}
+			if c == quote {
+				return Token{Kind: TokString, Text: buf.String()}, nil
+			}
+			if c == '\\' {
+				if c, err = l.escape(); err != nil {
+					return Token{}, err
+				}
+			}
+			buf.WriteRune(c)
+		}
+	case unicode.IsDigit(c):
+		return Token{Kind: TokNumber, Text: l.number(c)}, nil
+	case unicode.IsLetter(c) || c == '_' || c == '$':
+		return Token{Kind: TokIdent, Text: l.word(c, func(c rune) bool {
+			return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '$'
+		})}, nil
+	}
+	return Token{Kind: TokPunct, Text: string(c)}, nil
+}
+
+// word reads the runes from c on for which in returns true.
+func (l *Lexer) word(c rune, in func(rune) bool) string {
+	var buf bytes.Buffer
+	for {
+		buf.WriteRune(c)
+		var err error
+		if c, err = l.read(); err != nil {
+			return buf.String()
+		}
+		if !in(c) {
+			l.unread(c)
+			return buf.String()
+		}
+	}
+}
+
+// number reads a number starting with the digit c, in decimal or hexadecimal, like 0x1f. A dot
+// is only part of it if a digit follows, so that ranges like 1..3 are a number, two dots and
+// another number.
+func (l *Lexer) number(c rune) string {
+	if b, _ := l.r.Peek(1); c == '0' && len(b) == 1 && (b[0] == 'x' || b[0] == 'X') {
+		return l.word(c, func(c rune) bool {
+			return unicode.IsDigit(c) || c == 'x' || c == 'X' || 'a' <= c && c <= 'f' ||
+				'A' <= c && c <= 'F'
+		})
+	}
+	var buf bytes.Buffer
+	buf.WriteRune(c)
+	for {
+		b, _ := l.r.Peek(2)
+		if len(b) == 0 {
+			return buf.String()
+		}
+		last := buf.Bytes()[buf.Len()-1]
+		switch {
+		case '0' <= b[0] && b[0] <= '9':
+		case b[0] == '.' && len(b) == 2 && '0' <= b[1] && b[1] <= '9':
+		case b[0] == 'e' || b[0] == 'E':
+		case (b[0] == '-' || b[0] == '+') && (last == 'e' || last == 'E'):
+		default:
+			return buf.String()
+		}
+		r, _ := l.read()
+		buf.WriteRune(r)
+	}
+}
+
+func (l *Lexer) escape() (rune, error) {
+	c, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'u':
+		var hex [4]rune
+		for i := range hex {
+			if hex[i], err = l.read(); err != nil {
+				return 0, err
+			}
+		}
+		r, err := strconv.ParseUint(string(hex[:]), 16, 32)
+		if err != nil {
+			return 0, x.Errorf("Invalid escape: \\u%s", string(hex[:]))
+		}
+		return rune(r), nil
+	}
+	return c, nil
+}
@@ -0,0 +1,762 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package cypher reads Cypher, the query language of Neo4j and openCypher. Its lexer is shared
+// by the loader of Cypher scripts, and it translates a subset of Cypher queries, of a MATCH clause
+// of one path, with depth-bounded variable length relationships, a WHERE clause and a RETURN
+// clause, into queries of Dgraph, like
+//
+//	MATCH (a:Person {name: "Alice"})-[:KNOWS*1..2]->(b)
+//	WHERE b.age > 30 RETURN a.name, b.name AS friend ORDER BY friend LIMIT 10
+//
+// The labels of nodes are the values of a label predicate, the types of relationships are
+// predicates, and the properties of nodes are predicates, like for graphs loaded from Neo4j.
+package cypher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// maxDepth is the most hops a variable length relationship can have.
+	maxDepth = 10
+)
+
+// literal is a value of a query: a string without its quotes, or a number or boolean as written.
+type literal struct {
+	val string
+}
+
+type prop struct {
+	key string
+	val literal
+}
+
+type nodePattern struct {
+	name   string
+	labels []string
+	props  []prop
+}
+
+// relPattern is a relationship of type typ, from the node before it in the path to the one after
+// it, or the other way around if reverse, of between min and max hops.
+type relPattern struct {
+	name     string
+	typ      string
+	reverse  bool
+	min, max int
+}
+
+// Operators of expressions.
+const (
+	opAnd     = "and"
+	opOr      = "or"
+	opNot     = "not"
+	opEq      = "eq"
+	opNe      = "ne"
+	opLt      = "lt"
+	opLe      = "le"
+	opGt      = "gt"
+	opGe      = "ge"
+	opIn      = "in"
+	opNull    = "null"
+	opNotNull = "notnull"
+	opLabel   = "label"
+	opId      = "id"
+)
+
+// expr is a condition of a WHERE clause. Operators other than and, or and not apply to the
+// property prop of the node of variable name, or to its labels or id.
+type expr struct {
+	op   string
+	args []*expr
+	name string
+	prop string
+	vals []literal
+}
+
+// vars returns the variables expression e is about.
+func (e *expr) vars(vars map[string]bool) {
+	if e.name != "" {
+		vars[e.name] = true
+	}
+	for _, a := range e.args {
+		a.vars(vars)
+	}
+}
+
+type returnItem struct {
+	name   string
+	prop   string // Empty if the node itself is returned.
+	column string
+}
+
+type orderItem struct {
+	item int // Index of the returned item.
+	desc bool
+}
+
+// Query is a Cypher query of the subset translated.
+type Query struct {
+	nodes    []*nodePattern
+	rels     []*relPattern // rels[i] is between nodes[i] and nodes[i+1].
+	where    *expr
+	distinct bool
+	items    []returnItem
+	order    []orderItem
+	skip     int
+	limit    int // Negative without a limit.
+}
+
+type parser struct {
+	lex *Lexer
+	tok Token
+}
+
+func (p *parser) next() error {
+	var err error
+	if p.tok, err = p.lex.Next(); err != nil {
+		return x.Wrapf(err, "On line %d", p.lex.Line())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return x.Errorf("On line %d: %s", p.lex.Line(), fmt.Sprintf(format, args...))
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.Kind == TokPunct && p.tok.Text == punct
+}
+
+func (p *parser) keyword(kw string) bool {
+	return p.tok.Kind == TokIdent && strings.EqualFold(p.tok.Text, kw)
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.errorf("Expected %s, got %q", punct, p.tok.Text)
+	}
+	return p.next()
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.keyword(kw) {
+		return p.errorf("Expected %s, got %q", strings.ToUpper(kw), p.tok.Text)
+	}
+	return p.next()
+}
+
+func (p *parser) isName() bool {
+	return p.tok.Kind == TokIdent || p.tok.Kind == TokQuoted
+}
+
+func (p *parser) ident() (string, error) {
+	if !p.isName() {
+		return "", p.errorf("Expected a name, got %q", p.tok.Text)
+	}
+	name := p.tok.Text
+	return name, p.next()
+}
+
+func (p *parser) integer() (int, error) {
+	if p.tok.Kind != TokNumber {
+		return 0, p.errorf("Expected a number, got %q", p.tok.Text)
+	}
+	n, err := strconv.Atoi(p.tok.Text)
+	if err != nil || n < 0 {
+		return 0, p.errorf("Invalid number: %q", p.tok.Text)
+	}
+	return n, p.next()
+}
+
+// Parse parses the Cypher query q.
+func Parse(q string) (*Query, error) {
+	p := &parser{lex: NewLexer(strings.NewReader(q))}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.keyword("optional") {
+		return nil, p.errorf("OPTIONAL MATCH isn't supported")
+	}
+	if err := p.expectKeyword("match"); err != nil {
+		return nil, err
+	}
+	query := &Query{limit: -1}
+	if err := p.path(query); err != nil {
+		return nil, err
+	}
+	if p.is(",") {
+		return nil, p.errorf("Only one path can be matched")
+	}
+	if p.keyword("where") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		var err error
+		if query.where, err = p.or(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.returns(query); err != nil {
+		return nil, err
+	}
+	if p.is(";") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.Kind != TokEOF {
+		return nil, p.errorf("Unsupported clause: %q", p.tok.Text)
+	}
+	return query, query.check()
+}
+
+// check checks that the variables of q are used as they can be.
+func (q *Query) check() error {
+	nodes := make(map[string]bool)
+	for _, n := range q.nodes {
+		if n.name == "" {
+			continue
+		}
+		if nodes[n.name] {
+			return x.Errorf("Variable %s is used by two nodes of the path", n.name)
+		}
+		nodes[n.name] = true
+	}
+	for _, r := range q.rels {
+		if r.name != "" && nodes[r.name] {
+			return x.Errorf("Variable %s is used by a node and a relationship", r.name)
+		}
+	}
+	vars := make(map[string]bool)
+	if q.where != nil {
+		q.where.vars(vars)
+	}
+	for _, it := range q.items {
+		vars[it.name] = true
+	}
+	for v := range vars {
+		if !nodes[v] {
+			return x.Errorf("Unknown node variable: %s", v)
+		}
+	}
+	return nil
+}
+
+func (p *parser) path(q *Query) error {
+	n, err := p.node()
+	if err != nil {
+		return err
+	}
+	q.nodes = append(q.nodes, n)
+	for p.is("-") || p.is("<") {
+		r, err := p.rel()
+		if err != nil {
+			return err
+		}
+		if n, err = p.node(); err != nil {
+			return err
+		}
+		q.rels = append(q.rels, r)
+		q.nodes = append(q.nodes, n)
+	}
+	return nil
+}
+
+func (p *parser) node() (*nodePattern, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	n := &nodePattern{}
+	var err error
+	if p.isName() {
+		if n.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	for p.is(":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		label, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		n.labels = append(n.labels, label)
+	}
+	if p.is("{") {
+		if n.props, err = p.props(); err != nil {
+			return nil, err
+		}
+	}
+	return n, p.expect(")")
+}
+
+func (p *parser) rel() (*relPattern, error) {
+	r := &relPattern{min: 1, max: 1}
+	if p.is("<") {
+		r.reverse = true
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("-"); err != nil {
+		return nil, err
+	}
+	if !p.is("[") {
+		return nil, p.errorf("Relationships need a type")
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.isName() {
+		if r.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.is(":") {
+		return nil, p.errorf("Relationships need a type")
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if r.typ, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if p.is("|") {
+		return nil, p.errorf("Relationships can only have one type")
+	}
+	if p.is("*") {
+		if err := p.length(r); err != nil {
+			return nil, err
+		}
+	}
+	if p.is("{") {
+		return nil, p.errorf("Properties of relationships can't be matched")
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	if err := p.expect("-"); err != nil {
+		return nil, err
+	}
+	if !r.reverse {
+		if !p.is(">") {
+			return nil, p.errorf("Relationships need a direction")
+		}
+		return r, p.next()
+	}
+	if p.is(">") {
+		return nil, p.errorf("Relationships can only have one direction")
+	}
+	return r, nil
+}
+
+// length parses the number of hops of a variable length relationship, like *2, *1..3 or *..3.
+func (p *parser) length(r *relPattern) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	var err error
+	r.max = -1
+	if p.tok.Kind == TokNumber {
+		if r.min, err = p.integer(); err != nil {
+			return err
+		}
+		r.max = r.min
+	}
+	if p.is(".") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if err := p.expect("."); err != nil {
+			return err
+		}
+		r.max = -1
+		if p.tok.Kind == TokNumber {
+			if r.max, err = p.integer(); err != nil {
+				return err
+			}
+		}
+	}
+	switch {
+	case r.max < 0:
+		return p.errorf("Variable length relationships need a maximum length, of at most %d",
+			maxDepth)
+	case r.min < 1:
+		return p.errorf("Variable length relationships need a minimum length of at least 1")
+	case r.max < r.min:
+		return p.errorf("Invalid length of relationship: *%d..%d", r.min, r.max)
+	case r.max > maxDepth:
+		return p.errorf("Variable length relationships can have at most %d hops", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) props() ([]prop, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var props []prop
+	for !p.is("}") {
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		val, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, prop{key: key, val: val})
+		if p.is(",") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		} else if !p.is("}") {
+			return nil, p.errorf("Expected , or }, got %q", p.tok.Text)
+		}
+	}
+	return props, p.next()
+}
+
+func (p *parser) literal() (literal, error) {
+	tok := p.tok
+	if err := p.next(); err != nil {
+		return literal{}, err
+	}
+	switch {
+	case tok.Kind == TokString:
+		return literal{val: tok.Text}, nil
+	case tok.Kind == TokNumber:
+		return literal{val: tok.Text}, nil
+	case tok.Kind == TokPunct && tok.Text == "-" && p.tok.Kind == TokNumber:
+		l := literal{val: "-" + p.tok.Text}
+		return l, p.next()
+	case tok.Kind == TokIdent && (strings.EqualFold(tok.Text, "true") ||
+		strings.EqualFold(tok.Text, "false")):
+		return literal{val: strings.ToLower(tok.Text)}, nil
+	}
+	return literal{}, p.errorf("Unsupported value: %q", tok.Text)
+}
+
+func (p *parser) or() (*expr, error) {
+	e, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		e = &expr{op: opOr, args: []*expr{e, r}}
+	}
+	return e, nil
+}
+
+func (p *parser) and() (*expr, error) {
+	e, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		e = &expr{op: opAnd, args: []*expr{e, r}}
+	}
+	return e, nil
+}
+
+func (p *parser) not() (*expr, error) {
+	if !p.keyword("not") {
+		return p.atom()
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	e, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	return &expr{op: opNot, args: []*expr{e}}, nil
+}
+
+var comparisons = map[string]string{
+	"=": opEq, "<>": opNe, "<": opLt, "<=": opLe, ">": opGt, ">=": opGe,
+}
+
+// comparison parses a comparison operator, made of one or two punctuation tokens.
+func (p *parser) comparison() (string, error) {
+	if p.tok.Kind != TokPunct {
+		return "", p.errorf("Expected a comparison, got %q", p.tok.Text)
+	}
+	op := p.tok.Text
+	if err := p.next(); err != nil {
+		return "", err
+	}
+	if (op == "<" || op == ">") && (p.is("=") || (op == "<" && p.is(">"))) {
+		op += p.tok.Text
+		if err := p.next(); err != nil {
+			return "", err
+		}
+	}
+	if comparisons[op] == "" {
+		return "", p.errorf("Unsupported comparison: %q", op)
+	}
+	return comparisons[op], nil
+}
+
+func (p *parser) atom() (*expr, error) {
+	if p.is("(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.EqualFold(name, "id") && p.is("("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e := &expr{op: opId}
+		if e.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		val, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		e.vals = []literal{val}
+		return e, nil
+	case p.is(":"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		label, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		return &expr{op: opLabel, name: name, vals: []literal{{val: label}}}, nil
+	}
+	if err := p.expect("."); err != nil {
+		return nil, err
+	}
+	e := &expr{name: name}
+	if e.prop, err = p.ident(); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.keyword("is"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e.op = opNull
+		if p.keyword("not") {
+			e.op = opNotNull
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+		return e, p.expectKeyword("null")
+	case p.keyword("in"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e.op = opIn
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		for !p.is("]") {
+			val, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+			e.vals = append(e.vals, val)
+			if p.is(",") {
+				if err := p.next(); err != nil {
+					return nil, err
+				}
+			} else if !p.is("]") {
+				return nil, p.errorf("Expected , or ], got %q", p.tok.Text)
+			}
+		}
+		if len(e.vals) == 0 {
+			return nil, p.errorf("Empty list of values of IN")
+		}
+		return e, p.next()
+	}
+	if e.op, err = p.comparison(); err != nil {
+		return nil, err
+	}
+	val, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	e.vals = []literal{val}
+	return e, nil
+}
+
+func (p *parser) returns(q *Query) error {
+	if err := p.expectKeyword("return"); err != nil {
+		return err
+	}
+	if p.keyword("distinct") {
+		q.distinct = true
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	for {
+		var it returnItem
+		var err error
+		if it.name, err = p.ident(); err != nil {
+			return err
+		}
+		it.column = it.name
+		if p.is(".") {
+			if err := p.next(); err != nil {
+				return err
+			}
+			if it.prop, err = p.ident(); err != nil {
+				return err
+			}
+			it.column += "." + it.prop
+		}
+		if p.keyword("as") {
+			if err := p.next(); err != nil {
+				return err
+			}
+			if it.column, err = p.ident(); err != nil {
+				return err
+			}
+		}
+		q.items = append(q.items, it)
+		if !p.is(",") {
+			break
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+
+	if p.keyword("order") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if err := p.expectKeyword("by"); err != nil {
+			return err
+		}
+		for {
+			o, err := p.orderItem(q)
+			if err != nil {
+				return err
+			}
+			q.order = append(q.order, o)
+			if !p.is(",") {
+				break
+			}
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+	}
+	var err error
+	if p.keyword("skip") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if q.skip, err = p.integer(); err != nil {
+			return err
+		}
+	}
+	if p.keyword("limit") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if q.limit, err = p.integer(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderItem parses an item of ORDER BY, which must be one of the items returned, by column or
+// expression.
+func (p *parser) orderItem(q *Query) (orderItem, error) {
+	name, err := p.ident()
+	if err != nil {
+		return orderItem{}, err
+	}
+	var prop string
+	if p.is(".") {
+		if err := p.next(); err != nil {
+			return orderItem{}, err
+		}
+		if prop, err = p.ident(); err != nil {
+			return orderItem{}, err
+		}
+	}
+	o := orderItem{item: -1}
+	for i, it := range q.items {
+		if (prop == "" && it.column == name) || (it.name == name && it.prop == prop) {
+			o.item = i
+			break
+		}
+	}
+	if o.item < 0 {
+		return o, p.errorf("Only the items returned can be ordered by")
+	}
+	switch {
+	case p.keyword("desc"), p.keyword("descending"):
+		o.desc = true
+		err = p.next()
+	case p.keyword("asc"), p.keyword("ascending"):
+		err = p.next()
+	}
+	return o, err
+}
@@ -0,0 +1,541 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cypher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// maxBranches is the most ways a path can be matched, over all the lengths of its variable
+	// length relationships. Each is a branch of the query.
+	maxBranches = 100
+	// block is the name of the query block of translated queries.
+	block = "cypher"
+)
+
+// Options are the options of the translation of queries.
+type Options struct {
+	// Label is the predicate the labels of nodes are the values of.
+	Label string
+}
+
+// Translation is a Cypher query translated to a query of Dgraph.
+type Translation struct {
+	// Query is the query of Dgraph, in GraphQL+-, with the values of the Cypher query passed
+	// as the variables in Vars.
+	Query string
+	Vars  map[string]string
+
+	q     *Query
+	index map[string]int // Indexes of the nodes of the path, by variable.
+	props [][]string     // props[i] are the properties of node i returned, aliased p<i>_<j>.
+	whole []bool         // whole[i] is true if node i itself is returned.
+}
+
+// Result is the result of a Cypher query, with a row per match of its path.
+type Result struct {
+	Columns []string            `json:"columns"`
+	Rows    [][]json.RawMessage `json:"rows"`
+}
+
+// validName returns an error if name can't be written in a query as a predicate.
+func validName(name string) error {
+	for i, c := range name {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '_':
+		case i > 0 && ('0' <= c && c <= '9' || c == '.'):
+		default:
+			return x.Errorf("Unsupported name of predicate: %q", name)
+		}
+	}
+	if name == "" {
+		return x.Errorf("Empty name of predicate")
+	}
+	return nil
+}
+
+// conjuncts returns the conditions e is the conjunction of.
+func conjuncts(e *expr) []*expr {
+	if e == nil {
+		return nil
+	}
+	if e.op == opAnd {
+		return append(conjuncts(e.args[0]), conjuncts(e.args[1])...)
+	}
+	return []*expr{e}
+}
+
+type translator struct {
+	t       *Translation
+	opts    Options
+	conds   [][]*expr // conds[i] are the conditions of node i.
+	filters []string  // filters[i] is the @filter directive of node i, if it has conditions.
+	buf     bytes.Buffer
+}
+
+// Translate translates q to a query of Dgraph. The first node of the path of q is where the query
+// starts from, so it needs a condition an index can be used for: an id, a property equal to a
+// value or a label.
+func (q *Query) Translate(opts Options) (*Translation, error) {
+	if err := validName(opts.Label); err != nil {
+		return nil, err
+	}
+	tr := &translator{
+		t: &Translation{
+			Vars:  make(map[string]string),
+			q:     q,
+			index: make(map[string]int),
+			props: make([][]string, len(q.nodes)),
+			whole: make([]bool, len(q.nodes)),
+		},
+		opts:  opts,
+		conds: make([][]*expr, len(q.nodes)),
+	}
+
+	index := tr.t.index
+	branches := 1
+	for i, n := range q.nodes {
+		if n.name != "" {
+			index[n.name] = i
+		}
+		for _, p := range n.props {
+			tr.conds[i] = append(tr.conds[i],
+				&expr{op: opEq, prop: p.key, vals: []literal{p.val}})
+		}
+		for _, l := range n.labels {
+			tr.conds[i] = append(tr.conds[i],
+				&expr{op: opLabel, vals: []literal{{val: l}}})
+		}
+		if i < len(q.rels) {
+			if err := validName(q.rels[i].typ); err != nil {
+				return nil, err
+			}
+			if branches *= q.rels[i].max - q.rels[i].min + 1; branches > maxBranches {
+				return nil, x.Errorf("The lengths of the relationships of the path allow more "+
+					"than %d ways to match it", maxBranches)
+			}
+		}
+	}
+	for _, c := range conjuncts(q.where) {
+		vars := make(map[string]bool)
+		c.vars(vars)
+		if len(vars) != 1 {
+			return nil, x.Errorf("Conditions of WHERE can only be about one node")
+		}
+		for v := range vars {
+			tr.conds[index[v]] = append(tr.conds[index[v]], c)
+		}
+	}
+	for _, it := range q.items {
+		i := index[it.name]
+		if it.prop == "" {
+			tr.t.whole[i] = true
+			continue
+		}
+		found := false
+		for _, p := range tr.t.props[i] {
+			found = found || p == it.prop
+		}
+		if !found {
+			tr.t.props[i] = append(tr.t.props[i], it.prop)
+		}
+	}
+
+	root, err := tr.root()
+	if err != nil {
+		return nil, err
+	}
+	// Nodes after variable length relationships have a block per length, with the same filter.
+	for i := range q.nodes {
+		f, err := tr.filter(i)
+		if err != nil {
+			return nil, err
+		}
+		tr.filters = append(tr.filters, f)
+	}
+	if err := tr.node(0, root, "  "); err != nil {
+		return nil, err
+	}
+
+	var query bytes.Buffer
+	if len(tr.t.Vars) > 0 {
+		vars := make([]string, 0, len(tr.t.Vars))
+		for i := range tr.t.Vars {
+			vars = append(vars, i)
+		}
+		sort.Strings(vars)
+		query.WriteString("query " + block + "(")
+		for i, v := range vars {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(v + ": string")
+		}
+		query.WriteString(") ")
+	}
+	query.WriteString("{\n")
+	query.Write(tr.buf.Bytes())
+	query.WriteString("}\n")
+	tr.t.Query = query.String()
+	return tr.t, nil
+}
+
+// root returns the function of the query block, taking its condition out of the ones of the
+// first node.
+func (tr *translator) root() (string, error) {
+	conds := tr.conds[0]
+	best := -1
+	for _, op := range []string{opId, opEq, opLabel} {
+		for i, c := range conds {
+			if c.op == op {
+				best = i
+				break
+			}
+		}
+		if best >= 0 {
+			break
+		}
+	}
+	if best < 0 {
+		return "", x.Errorf("The first node of the path needs an id, a property equal to a " +
+			"value or a label")
+	}
+	f, err := tr.expr(conds[best])
+	if err != nil {
+		return "", err
+	}
+	tr.conds[0] = append(conds[:best:best], conds[best+1:]...)
+	return block + "(func: " + f + ")", nil
+}
+
+// value adds the value of l to the variables of the query and returns its name.
+func (tr *translator) value(l literal) string {
+	name := fmt.Sprintf("$v%d", len(tr.t.Vars))
+	tr.t.Vars[name] = l.val
+	return name
+}
+
+func (tr *translator) expr(e *expr) (string, error) {
+	switch e.op {
+	case opAnd, opOr:
+		a, err := tr.expr(e.args[0])
+		if err != nil {
+			return "", err
+		}
+		b, err := tr.expr(e.args[1])
+		if err != nil {
+			return "", err
+		}
+		return "(" + a + " " + e.op + " " + b + ")", nil
+	case opNot:
+		a, err := tr.expr(e.args[0])
+		return "not " + a, err
+	case opLabel:
+		return "eq(" + tr.opts.Label + ", " + tr.value(e.vals[0]) + ")", nil
+	case opId:
+		uid, err := strconv.ParseUint(e.vals[0].val, 0, 64)
+		if err != nil || uid == 0 {
+			return "", x.Errorf("Invalid id: %q", e.vals[0].val)
+		}
+		return fmt.Sprintf("uid(%#x)", uid), nil
+	}
+
+	if err := validName(e.prop); err != nil {
+		return "", err
+	}
+	switch e.op {
+	case opNull:
+		return "not has(" + e.prop + ")", nil
+	case opNotNull:
+		return "has(" + e.prop + ")", nil
+	case opNe:
+		return "not eq(" + e.prop + ", " + tr.value(e.vals[0]) + ")", nil
+	case opIn:
+		fs := make([]string, 0, len(e.vals))
+		for _, v := range e.vals {
+			fs = append(fs, "eq("+e.prop+", "+tr.value(v)+")")
+		}
+		if len(fs) == 1 {
+			return fs[0], nil
+		}
+		return "(" + strings.Join(fs, " or ") + ")", nil
+	}
+	return e.op + "(" + e.prop + ", " + tr.value(e.vals[0]) + ")", nil
+}
+
+// filter returns the @filter directive of the conditions of node i, if it has any.
+func (tr *translator) filter(i int) (string, error) {
+	if len(tr.conds[i]) == 0 {
+		return "", nil
+	}
+	fs := make([]string, 0, len(tr.conds[i]))
+	for _, c := range tr.conds[i] {
+		f, err := tr.expr(c)
+		if err != nil {
+			return "", err
+		}
+		fs = append(fs, f)
+	}
+	return " @filter(" + strings.Join(fs, " and ") + ")", nil
+}
+
+// node writes the block of node i of the path, as head, followed by the blocks of the rest of
+// the path.
+func (tr *translator) node(i int, head, indent string) error {
+	tr.buf.WriteString(indent + head + tr.filters[i] + " {\n")
+	in := indent + "  "
+	tr.buf.WriteString(in + "_uid_\n")
+	if tr.t.whole[i] {
+		tr.buf.WriteString(in + "expand(_all_)\n")
+	}
+	for j, p := range tr.t.props[i] {
+		if err := validName(p); err != nil {
+			return err
+		}
+		tr.buf.WriteString(fmt.Sprintf("%sp%d_%d : %s\n", in, i, j, p))
+	}
+	if i < len(tr.t.q.rels) {
+		r := tr.t.q.rels[i]
+		pred := r.typ
+		if r.reverse {
+			pred = "~" + pred
+		}
+		for d := r.min; d <= r.max; d++ {
+			// The hops before the last one of a relationship of length d only have their uids.
+			hop := in
+			for k := 1; k < d; k++ {
+				tr.buf.WriteString(fmt.Sprintf("%sh%d_%d_%d : %s {\n", hop, i, d, k, pred))
+				hop += "  "
+				tr.buf.WriteString(hop + "_uid_\n")
+			}
+			if err := tr.node(i+1, fmt.Sprintf("r%d_%d : %s", i, d, pred), hop); err != nil {
+				return err
+			}
+			for k := d - 1; k >= 1; k-- {
+				hop = hop[2:]
+				tr.buf.WriteString(hop + "}\n")
+			}
+		}
+	}
+	tr.buf.WriteString(indent + "}\n")
+	return nil
+}
+
+// objects returns the objects of the key of the objects objs.
+func objects(objs []map[string]interface{}, key string) []map[string]interface{} {
+	var res []map[string]interface{}
+	for _, o := range objs {
+		switch v := o[key].(type) {
+		case map[string]interface{}:
+			res = append(res, v)
+		case []interface{}:
+			for _, c := range v {
+				if m, ok := c.(map[string]interface{}); ok {
+					res = append(res, m)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Rows returns the rows of the result of the query of t, from the data of its JSON response,
+// like {"cypher":[...]}.
+func (t *Translation) Rows(data []byte) (*Result, error) {
+	var out map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, x.Wrapf(err, "While reading result of query")
+	}
+
+	res := &Result{Rows: [][]json.RawMessage{}}
+	for _, it := range t.q.items {
+		res.Columns = append(res.Columns, it.column)
+	}
+	var rows [][]interface{}
+	bound := make([]map[string]interface{}, len(t.q.nodes))
+	var match func(i int, obj map[string]interface{})
+	match = func(i int, obj map[string]interface{}) {
+		bound[i] = obj
+		if i == len(t.q.rels) {
+			rows = append(rows, t.row(bound))
+			return
+		}
+		r := t.q.rels[i]
+		for d := r.min; d <= r.max; d++ {
+			objs := []map[string]interface{}{obj}
+			for k := 1; k < d; k++ {
+				objs = objects(objs, fmt.Sprintf("h%d_%d_%d", i, d, k))
+			}
+			for _, o := range objects(objs, fmt.Sprintf("r%d_%d", i, d)) {
+				match(i+1, o)
+			}
+		}
+	}
+	for _, o := range objects([]map[string]interface{}{out}, block) {
+		match(0, o)
+	}
+
+	if len(t.q.order) > 0 {
+		sort.SliceStable(rows, func(a, b int) bool {
+			for _, o := range t.q.order {
+				c := compare(rows[a][o.item], rows[b][o.item])
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	seen := make(map[string]bool)
+	skip := t.q.skip
+	for _, row := range rows {
+		if t.q.limit >= 0 && len(res.Rows) == t.q.limit {
+			break
+		}
+		vals := make([]json.RawMessage, len(row))
+		var key bytes.Buffer
+		for i, v := range row {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = b
+			key.Write(b)
+			key.WriteByte(0)
+		}
+		if t.q.distinct {
+			if seen[key.String()] {
+				continue
+			}
+			seen[key.String()] = true
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		res.Rows = append(res.Rows, vals)
+	}
+	return res, nil
+}
+
+// row returns the values of the items returned, for the objects bound to the nodes of the path.
+func (t *Translation) row(bound []map[string]interface{}) []interface{} {
+	row := make([]interface{}, 0, len(t.q.items))
+	for _, it := range t.q.items {
+		i := t.index[it.name]
+		obj := bound[i]
+		if it.prop != "" {
+			for j, p := range t.props[i] {
+				if p == it.prop {
+					row = append(row, obj[fmt.Sprintf("p%d_%d", i, j)])
+				}
+			}
+			continue
+		}
+		// The node without the blocks of the query which aren't its properties.
+		node := make(map[string]interface{})
+		for k, v := range obj {
+			if !t.internal(i, k) {
+				node[k] = v
+			}
+		}
+		row = append(row, node)
+	}
+	return row
+}
+
+// internal returns true if key is the alias of a block of the query in the object of node i.
+func (t *Translation) internal(i int, key string) bool {
+	for j := range t.props[i] {
+		if key == fmt.Sprintf("p%d_%d", i, j) {
+			return true
+		}
+	}
+	if i == len(t.q.rels) {
+		return false
+	}
+	return strings.HasPrefix(key, fmt.Sprintf("r%d_", i)) ||
+		strings.HasPrefix(key, fmt.Sprintf("h%d_", i))
+}
+
+// rank orders the kinds of values: numbers, strings, booleans, others and then nulls, which
+// are last like in Cypher.
+func rank(v interface{}) int {
+	switch v.(type) {
+	case json.Number:
+		return 0
+	case string:
+		return 1
+	case bool:
+		return 2
+	case nil:
+		return 4
+	}
+	return 3
+}
+
+// compare compares the values a and b of a column, returning -1, 0 or 1.
+func compare(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch a := a.(type) {
+	case json.Number:
+		fa, _ := a.Float64()
+		fb, _ := b.(json.Number).Float64()
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case !a:
+			return -1
+		}
+		return 1
+	case nil:
+		return 0
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Compare(ja, jb)
+}
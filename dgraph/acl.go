@@ -0,0 +1,466 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Access control lists give users permissions on predicates. Users are in groups, and each group
+// has the permissions it grants on predicates, as a string of r (read), w (write) and m (modify
+// the schema). The permissions of a group on predicates it doesn't name are those it has for "*".
+// Users have all the permissions of their groups. They're kept in the JSON file of --acl, like:
+//
+//	{
+//	  "users": {"alice": {"password": "<bcrypt hash>", "groups": ["dev"]}},
+//	  "groups": {"dev": {"*": "r", "name": "rw"}}
+//	}
+//
+// With access control lists, the user of a request and its password are given with basic
+// authentication over HTTP, and in the user and password metadata over gRPC.
+//
+// Groups can also restrict the nodes their users see, with a filter injected into every block of
+// their queries, under "filters". The filter of a user matches the nodes matched by the filter of
+// any of its groups, with $user replaced by its name:
+//
+//	"filters": {"acme": "uid_in(org, 0x10) or eq(owner, \"$user\")"}
+
+var (
+	ErrNoUser     = errors.New("Requests must be sent with a user and password on this server")
+	ErrUserDenied = errors.New("Invalid user or password")
+	ErrACLExport  = errors.New("Exports are only taken by admins with access control lists or tokens")
+	ErrACLChanges = errors.New("Changes can't be subscribed to with namespaces, access control " +
+		"lists or tokens")
+)
+
+// ACLUser is a user of the access control lists.
+type ACLUser struct {
+	Password string   `json:"password,omitempty"` // Hashed with bcrypt.
+	Groups   []string `json:"groups"`
+}
+
+type aclFile struct {
+	Users   map[string]*ACLUser          `json:"users"`
+	Groups  map[string]map[string]string `json:"groups"`
+	Filters map[string]string            `json:"filters,omitempty"`
+}
+
+// Identity is who a request is run for, as authorized by its credentials.
+type Identity struct {
+	User   string
+	Access query.Access
+	// NodeFilter is injected into the query blocks of its requests, if it's not empty.
+	NodeFilter string
+}
+
+var acl = struct {
+	sync.RWMutex
+	aclFile
+	// verified has a hash of the password last verified for each user, not to run bcrypt for
+	// every request.
+	verified map[string][sha256.Size]byte
+}{
+	aclFile: aclFile{
+		Users:   make(map[string]*ACLUser),
+		Groups:  make(map[string]map[string]string),
+		Filters: make(map[string]string),
+	},
+	verified: make(map[string][sha256.Size]byte),
+}
+
+// ACLEnabled returns whether the server has --acl.
+func ACLEnabled() bool {
+	return Config.ACL != ""
+}
+
+// ParsePerms parses a string of permissions made of r, w and m.
+func ParsePerms(s string) (int, error) {
+	var perms int
+	for _, c := range s {
+		switch c {
+		case 'r':
+			perms |= query.PermRead
+		case 'w':
+			perms |= query.PermWrite
+		case 'm':
+			perms |= query.PermModify
+		default:
+			return 0, x.Errorf("Invalid permissions: %q. Expected r, w and m", s)
+		}
+	}
+	return perms, nil
+}
+
+func validateFilter(group, filter string) error {
+	if _, err := gql.ParseFilter(substituteUser(filter, "user")); err != nil {
+		return x.Wrapf(err, "In the filter of group %q", group)
+	}
+	return nil
+}
+
+// substituteUser replaces $user in filter with user, escaped to be used in quotes.
+func substituteUser(filter, user string) string {
+	user = strings.Replace(user, `\`, `\\`, -1)
+	user = strings.Replace(user, `"`, `\"`, -1)
+	return strings.Replace(filter, "$user", user, -1)
+}
+
+func validateGroup(name string, rules map[string]string) error {
+	if name == "" {
+		return x.Errorf("Empty group name")
+	}
+	for _, perms := range rules {
+		if _, err := ParsePerms(perms); err != nil {
+			return x.Wrapf(err, "In group %q", name)
+		}
+	}
+	return nil
+}
+
+// LoadACL reads the access control lists from the file of --acl.
+func LoadACL() error {
+	if Config.ACL == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(Config.ACL)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var f aclFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return x.Wrapf(err, "While reading access control lists from %v", Config.ACL)
+	}
+	for name, rules := range f.Groups {
+		if err := validateGroup(name, rules); err != nil {
+			return err
+		}
+	}
+	for group, filter := range f.Filters {
+		if err := validateFilter(group, filter); err != nil {
+			return err
+		}
+	}
+	if f.Users == nil {
+		f.Users = make(map[string]*ACLUser)
+	}
+	if f.Groups == nil {
+		f.Groups = make(map[string]map[string]string)
+	}
+	if f.Filters == nil {
+		f.Filters = make(map[string]string)
+	}
+	acl.Lock()
+	acl.aclFile = f
+	acl.verified = make(map[string][sha256.Size]byte)
+	acl.Unlock()
+	return nil
+}
+
+// ACLUsers returns the groups of each user.
+func ACLUsers() map[string][]string {
+	acl.RLock()
+	defer acl.RUnlock()
+	users := make(map[string][]string, len(acl.Users))
+	for name, u := range acl.Users {
+		users[name] = append([]string{}, u.Groups...)
+	}
+	return users
+}
+
+// ACLGroups returns the permissions each group grants on predicates.
+func ACLGroups() map[string]map[string]string {
+	acl.RLock()
+	defer acl.RUnlock()
+	groups := make(map[string]map[string]string, len(acl.Groups))
+	for name, rules := range acl.Groups {
+		groups[name] = make(map[string]string, len(rules))
+		for attr, perms := range rules {
+			groups[name][attr] = perms
+		}
+	}
+	return groups
+}
+
+// ACLFilters returns the filter of each group which has one.
+func ACLFilters() map[string]string {
+	acl.RLock()
+	defer acl.RUnlock()
+	filters := make(map[string]string, len(acl.Filters))
+	for group, filter := range acl.Filters {
+		filters[group] = filter
+	}
+	return filters
+}
+
+// saveACL writes the access control lists to the file of --acl. The caller holds the lock, and
+// undoes its change if it fails.
+func saveACL() error {
+	if err := writeJSONFile(Config.ACL, acl.aclFile); err != nil {
+		return x.Wrapf(err, "While saving access control lists")
+	}
+	return nil
+}
+
+// SetUser adds the user name, or changes its groups, and its password unless it's empty.
+func SetUser(name, password string, groups []string) error {
+	if !validTenant(name) {
+		return x.Errorf("Invalid user: %q. Users are made of letters, digits, '_', '-' and '.'",
+			name)
+	}
+	acl.Lock()
+	defer acl.Unlock()
+	old, had := acl.Users[name]
+	u := &ACLUser{Groups: groups}
+	switch {
+	case password != "":
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		u.Password = string(hash)
+	case had:
+		u.Password = old.Password
+	default:
+		return x.Errorf("Empty password for user: %q", name)
+	}
+	sort.Strings(u.Groups)
+	acl.Users[name] = u
+	if err := saveACL(); err != nil {
+		if had {
+			acl.Users[name] = old
+		} else {
+			delete(acl.Users, name)
+		}
+		return err
+	}
+	delete(acl.verified, name)
+	return nil
+}
+
+// DeleteUser removes the user name.
+func DeleteUser(name string) error {
+	acl.Lock()
+	defer acl.Unlock()
+	old, ok := acl.Users[name]
+	if !ok {
+		return x.Errorf("No user: %q", name)
+	}
+	delete(acl.Users, name)
+	if err := saveACL(); err != nil {
+		acl.Users[name] = old
+		return err
+	}
+	delete(acl.verified, name)
+	return nil
+}
+
+// SetGroup adds the group name, or replaces the permissions it grants.
+func SetGroup(name string, rules map[string]string) error {
+	if err := validateGroup(name, rules); err != nil {
+		return err
+	}
+	acl.Lock()
+	defer acl.Unlock()
+	old, had := acl.Groups[name]
+	acl.Groups[name] = rules
+	if err := saveACL(); err != nil {
+		if had {
+			acl.Groups[name] = old
+		} else {
+			delete(acl.Groups, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteGroup removes the group name, with its filter. Its users keep the permissions of their
+// other groups.
+func DeleteGroup(name string) error {
+	acl.Lock()
+	defer acl.Unlock()
+	old, ok := acl.Groups[name]
+	if !ok {
+		return x.Errorf("No group: %q", name)
+	}
+	filter, filtered := acl.Filters[name]
+	delete(acl.Groups, name)
+	delete(acl.Filters, name)
+	if err := saveACL(); err != nil {
+		acl.Groups[name] = old
+		if filtered {
+			acl.Filters[name] = filter
+		}
+		return err
+	}
+	return nil
+}
+
+// SetGroupFilter sets the filter of the nodes the users of group see.
+func SetGroupFilter(group, filter string) error {
+	if err := validateFilter(group, filter); err != nil {
+		return err
+	}
+	acl.Lock()
+	defer acl.Unlock()
+	if _, ok := acl.Groups[group]; !ok {
+		return x.Errorf("No group: %q", group)
+	}
+	old, had := acl.Filters[group]
+	acl.Filters[group] = filter
+	if err := saveACL(); err != nil {
+		if had {
+			acl.Filters[group] = old
+		} else {
+			delete(acl.Filters, group)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteGroupFilter removes the filter of group, so that its users see all nodes again, unless
+// another of their groups has a filter.
+func DeleteGroupFilter(group string) error {
+	acl.Lock()
+	defer acl.Unlock()
+	old, ok := acl.Filters[group]
+	if !ok {
+		return x.Errorf("No filter for group: %q", group)
+	}
+	delete(acl.Filters, group)
+	if err := saveACL(); err != nil {
+		acl.Filters[group] = old
+		return err
+	}
+	return nil
+}
+
+// userPerms returns the permissions on the predicate attr of user, with those of groups on top of
+// its own groups.
+func userPerms(user string, groups []string, attr string) int {
+	acl.RLock()
+	defer acl.RUnlock()
+	if u, ok := acl.Users[user]; ok {
+		groups = append(groups[:len(groups):len(groups)], u.Groups...)
+	}
+	var perms int
+	for _, g := range groups {
+		rules := acl.Groups[g]
+		p, ok := rules[attr]
+		if !ok {
+			p = rules["*"]
+		}
+		// The permissions were validated when the group was set.
+		bits, _ := ParsePerms(p)
+		perms |= bits
+	}
+	return perms
+}
+
+// nodeFilter returns the filter of the nodes user sees, with groups on top of its own groups.
+func nodeFilter(user string, groups []string) string {
+	acl.RLock()
+	defer acl.RUnlock()
+	if u, ok := acl.Users[user]; ok {
+		groups = append(groups[:len(groups):len(groups)], u.Groups...)
+	}
+	var filters []string
+	for _, g := range x.RemoveDuplicates(append([]string{}, groups...)) {
+		if f, ok := acl.Filters[g]; ok {
+			filters = append(filters, "("+substituteUser(f, user)+")")
+		}
+	}
+	return strings.Join(filters, " or ")
+}
+
+func userIdentity(user string, groups []string) Identity {
+	return Identity{
+		User: user,
+		Access: func(attr string, perm int) bool {
+			return userPerms(user, groups, attr)&perm != 0
+		},
+		NodeFilter: nodeFilter(user, groups),
+	}
+}
+
+// AuthorizeUser returns the identity to run a request with, given the user and password it was
+// sent with. Its permissions are nil on servers without access control lists. Changes to the
+// permissions of the groups of the user apply to requests being run.
+func AuthorizeUser(user, password string) (Identity, error) {
+	if !ACLEnabled() {
+		return Identity{}, nil
+	}
+	if user == "" {
+		return Identity{}, ErrNoUser
+	}
+	sum := sha256.Sum256([]byte(password))
+	acl.RLock()
+	u, ok := acl.Users[user]
+	verified := ok && acl.verified[user] == sum
+	acl.RUnlock()
+	if !verified {
+		if !ok || bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+			return Identity{User: user}, ErrUserDenied
+		}
+		acl.Lock()
+		// The user could have changed since it was read.
+		if acl.Users[user] == u {
+			acl.verified[user] = sum
+		}
+		acl.Unlock()
+	}
+	return userIdentity(user, nil), nil
+}
+
+// grpcIdentity returns the identity to run a gRPC request with, from the authorization, or user
+// and password metadata of ctx.
+func grpcIdentity(ctx context.Context) (Identity, error) {
+	if !ACLEnabled() && !JWTEnabled() {
+		return Identity{}, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var auth, user, password string
+	if v := md["authorization"]; len(v) == 1 {
+		auth = v[0]
+	}
+	if v := md["user"]; len(v) == 1 {
+		user = v[0]
+	}
+	if v := md["password"]; len(v) == 1 {
+		password = v[0]
+	}
+	return Authorize(auth, user, password)
+}
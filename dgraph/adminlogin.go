@@ -0,0 +1,307 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Admins can log in with the accounts of an identity provider, instead of admin tokens: with the
+// ID tokens of the OpenID Connect provider of --admin_oidc_issuer, as bearer tokens, or with their
+// users and passwords in the LDAP directory of --admin_ldap, with basic authentication. Both are
+// sent in the Authorization header over HTTP, and in the authorization metadata of the Admin gRPC
+// service. The groups admins are in are given the scopes of admin tokens by --admin_roles.
+
+var ErrAdminLogin = errors.New("Invalid admin credentials")
+
+// oidcRefresh is the least time between two fetches of the keys of the OIDC provider.
+const oidcRefresh = time.Minute
+
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+var oidcKeys = struct {
+	sync.Mutex
+	keys    map[string]crypto.PublicKey // By key id.
+	fetched time.Time
+}{}
+
+// adminRoles has the scopes of the groups of --admin_roles, by lower case group name.
+var adminRoles map[string][]string
+
+// AdminLoginEnabled returns whether the server has --admin_oidc_issuer or --admin_ldap.
+func AdminLoginEnabled() bool {
+	return Config.AdminOIDCIssuer != "" || Config.AdminLDAP != ""
+}
+
+// AdminLDAPEnabled returns whether the server has --admin_ldap.
+func AdminLDAPEnabled() bool {
+	return Config.AdminLDAP != ""
+}
+
+// ParseAdminRoles parses a comma separated list of group=scope pairs, like
+// "dgraph-admins=admin,data-team=export". A group can be given several scopes.
+func ParseAdminRoles(s string) (map[string][]string, error) {
+	roles := make(map[string][]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.LastIndex(entry, "=")
+		if idx <= 0 {
+			return nil, x.Errorf("Invalid admin role: %q. Expected <group>=<scope>", entry)
+		}
+		group, scope := strings.ToLower(strings.TrimSpace(entry[:idx])), entry[idx+1:]
+		if !validScope(scope) {
+			return nil, x.Errorf("Invalid scope of admin role %q. Expected export, schema or admin",
+				entry)
+		}
+		roles[group] = append(roles[group], scope)
+	}
+	return roles, nil
+}
+
+// groupNames returns the names group is known by in --admin_roles: itself, and the value of its
+// first attribute if it's the DN of an LDAP group, like admins for cn=admins,ou=groups.
+func groupNames(group string) []string {
+	names := []string{strings.ToLower(group)}
+	first := strings.SplitN(group, ",", 2)[0]
+	if idx := strings.Index(first, "="); idx > 0 && idx < len(first)-1 {
+		names = append(names, strings.ToLower(strings.TrimSpace(first[idx+1:])))
+	}
+	return names
+}
+
+// rolesAllow returns whether any of groups has a role allowed operations of scope.
+func rolesAllow(groups []string, scope string) bool {
+	for _, g := range groups {
+		for _, name := range groupNames(g) {
+			for _, s := range adminRoles[name] {
+				if s == ScopeAdmin || s == scope {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := oidcClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return x.Errorf("Got status %s from %s", resp.Status, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func jwkInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil || len(b) == 0 {
+		return nil, x.Errorf("Invalid number in JSON web key")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the RSA or ECDSA public key of k.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := jwkInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, x.Errorf("Unsupported curve of JSON web key: %q", k.Crv)
+		}
+		px, err := jwkInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		py, err := jwkInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: px, Y: py}, nil
+	}
+	return nil, x.Errorf("Unsupported type of JSON web key: %q", k.Kty)
+}
+
+// fetchOIDCKeys fetches the signing keys of the OIDC provider, from the jwks_uri of its discovery
+// document.
+func fetchOIDCKeys() (map[string]crypto.PublicKey, error) {
+	issuer := strings.TrimSuffix(Config.AdminOIDCIssuer, "/")
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, x.Wrapf(err, "While fetching the OIDC discovery document")
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer || discovery.JWKSURI == "" {
+		return nil, x.Errorf("Invalid OIDC discovery document for issuer %v", issuer)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(discovery.JWKSURI, &set); err != nil {
+		return nil, x.Wrapf(err, "While fetching the OIDC keys")
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of other types are ignored.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// oidcKeysFor returns the keys of the OIDC provider with id kid, or all of them without one. The
+// keys are fetched again when there's none with id kid, at most once every oidcRefresh.
+func oidcKeysFor(kid string) []crypto.PublicKey {
+	oidcKeys.Lock()
+	defer oidcKeys.Unlock()
+	_, ok := oidcKeys.keys[kid]
+	if (oidcKeys.keys == nil || (kid != "" && !ok)) && time.Since(oidcKeys.fetched) > oidcRefresh {
+		oidcKeys.fetched = time.Now()
+		keys, err := fetchOIDCKeys()
+		if err != nil {
+			x.Printf("Error while fetching the keys of the OIDC provider: %v\n", err)
+		} else {
+			oidcKeys.keys = keys
+		}
+	}
+	if kid != "" {
+		if key, ok := oidcKeys.keys[kid]; ok {
+			return []crypto.PublicKey{key}
+		}
+		return nil
+	}
+	keys := make([]crypto.PublicKey, 0, len(oidcKeys.keys))
+	for _, key := range oidcKeys.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func verifyOIDC(alg, kid string, signed, sig []byte) bool {
+	// Tokens signed with a secret aren't accepted, since there's none shared with the provider.
+	return verifyJWT(alg, signed, sig, nil, oidcKeysFor(kid))
+}
+
+// authorizeOIDC verifies the ID token of an admin, and returns the admin and its groups.
+func authorizeOIDC(token string) (string, []string, error) {
+	claims, err := parseJWT(token, time.Now(), verifyOIDC)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := claims["exp"].(float64); !ok {
+		return "", nil, x.Wrapf(ErrInvalidToken, "Token without expiry")
+	}
+	iss, _ := claims["iss"].(string)
+	if strings.TrimSuffix(iss, "/") != strings.TrimSuffix(Config.AdminOIDCIssuer, "/") {
+		return "", nil, x.Wrapf(ErrInvalidToken, "Issuer %q not allowed", iss)
+	}
+	found := false
+	for _, aud := range claimStrings(claims["aud"]) {
+		found = found || aud == Config.AdminOIDCClientId
+	}
+	if !found {
+		return "", nil, x.Wrapf(ErrInvalidToken, "Token not meant for %q", Config.AdminOIDCClientId)
+	}
+	user, _ := claims["email"].(string)
+	if user == "" {
+		user, _ = claims["sub"].(string)
+	}
+	return user, claimStrings(claims[Config.AdminGroupsClaim]), nil
+}
+
+// AuthorizeAdminLogin checks the credentials in authorization, either the bearer ID token of an
+// OIDC provider or the basic user and password of an LDAP directory, and that the groups of the
+// admin have a role allowed operations of scope. It returns the admin.
+func AuthorizeAdminLogin(authorization, scope string) (string, error) {
+	var user string
+	var groups []string
+	var err error
+	switch {
+	case strings.HasPrefix(authorization, "Bearer ") && Config.AdminOIDCIssuer != "":
+		user, groups, err = authorizeOIDC(strings.TrimPrefix(authorization, "Bearer "))
+	case strings.HasPrefix(authorization, "Basic ") && Config.AdminLDAP != "":
+		b, derr := base64.StdEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+		parts := strings.SplitN(string(b), ":", 2)
+		if derr != nil || len(parts) != 2 {
+			return "", ErrAdminLogin
+		}
+		user = parts[0]
+		groups, err = ldapLogin(user, parts[1])
+	default:
+		return "", ErrAdminLogin
+	}
+	if err != nil {
+		return user, err
+	}
+	if !rolesAllow(groups, scope) {
+		return user, ErrAdminTokenScope
+	}
+	return user, nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdminRoles(t *testing.T) {
+	_, err := ParseAdminRoles("admins")
+	require.Error(t, err)
+	_, err = ParseAdminRoles("admins=root")
+	require.Error(t, err)
+
+	roles, err := ParseAdminRoles("Admins=admin, data=export,data=schema")
+	require.NoError(t, err)
+	defer func(r map[string][]string) { adminRoles = r }(adminRoles)
+	adminRoles = roles
+	require.True(t, rolesAllow([]string{"admins"}, ScopeSchema))
+	require.True(t, rolesAllow([]string{"cn=data,ou=groups,dc=example,dc=com"}, ScopeExport))
+	require.False(t, rolesAllow([]string{"data"}, ScopeAdmin))
+	require.False(t, rolesAllow([]string{"other"}, ScopeExport))
+}
+
+func TestAuthorizeOIDC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter,
+		r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jwk{{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   enc.EncodeToString(key.X.Bytes()),
+			Y:   enc.EncodeToString(key.Y.Bytes()),
+		}}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	defer func(c Options, r map[string][]string) { Config, adminRoles = c, r }(Config, adminRoles)
+	Config.AdminOIDCIssuer, Config.AdminOIDCClientId = issuer, "dgraph"
+	Config.AdminGroupsClaim = DefaultConfig.AdminGroupsClaim
+	adminRoles = map[string][]string{"ops": {ScopeExport}}
+
+	claims := map[string]interface{}{
+		"sub":    "alice",
+		"iss":    issuer,
+		"aud":    "dgraph",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"groups": []string{"ops"},
+	}
+	user, err := AuthorizeAdminLogin("Bearer "+signToken(t, claims, nil, key), ScopeExport)
+	require.NoError(t, err)
+	require.Equal(t, "alice", user)
+	_, err = AuthorizeAdminLogin("Bearer "+signToken(t, claims, nil, key), ScopeSchema)
+	require.Equal(t, ErrAdminTokenScope, err)
+
+	// Tokens signed with a secret, or for another client, aren't accepted.
+	_, err = AuthorizeAdminLogin("Bearer "+signToken(t, claims, []byte("s"), nil), ScopeExport)
+	require.Error(t, err)
+	claims["aud"] = "other"
+	_, err = AuthorizeAdminLogin("Bearer "+signToken(t, claims, nil, key), ScopeExport)
+	require.Error(t, err)
+	_, err = AuthorizeAdminLogin("Basic YWxpY2U6cHc=", ScopeExport)
+	require.Equal(t, ErrAdminLogin, err)
+}
+
+// serveLDAP serves the binds of user with password, and searches of the memberOf attribute of
+// its entry, giving groups.
+func serveLDAP(t *testing.T, l net.Listener, dn, password string, groups []string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			c := &ldapConn{conn: conn, r: bufio.NewReader(conn)}
+			reply := func(id int, op []byte) {
+				conn.Write(berEncode(berSequence, berInt(berInteger, id), op))
+			}
+			result := func(id byte, code int) []byte {
+				return berEncode(id, berInt(berEnumerated, code), berString(berOctetString, ""),
+					berString(berOctetString, ""))
+			}
+			for id := 1; ; id++ {
+				op, err := c.receive()
+				if err != nil {
+					return
+				}
+				switch op.id {
+				case ldapBindRequest:
+					vals, err := berParseAll(op.content, berInteger, berOctetString,
+						ldapSimplePassword)
+					require.NoError(t, err)
+					code := ldapInvalidCredentials
+					if string(vals[1].content) == dn && string(vals[2].content) == password {
+						code = 0
+					}
+					reply(id, result(ldapBindResponse, code))
+				case ldapSearchRequest:
+					var vals [][]byte
+					for _, g := range groups {
+						vals = append(vals, berString(berOctetString, g))
+					}
+					reply(id, berEncode(ldapSearchEntry, berString(berOctetString, dn),
+						berEncode(berSequence, berEncode(berSequence,
+							berString(berOctetString, "memberOf"), berEncode(berSet, vals...)))))
+					reply(id, result(ldapSearchDone, 0))
+				default:
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+func TestLDAPLogin(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go serveLDAP(t, l, `uid=bob\,jr,ou=people,dc=example,dc=com`, "pw",
+		[]string{"cn=ops,ou=groups,dc=example,dc=com", "cn=other,ou=groups,dc=example,dc=com"})
+
+	defer func(c Options, r map[string][]string) { Config, adminRoles = c, r }(Config, adminRoles)
+	Config.AdminLDAP = "ldap://" + l.Addr().String()
+	Config.AdminLDAPUserDN = "uid=%s,ou=people,dc=example,dc=com"
+	adminRoles = map[string][]string{"ops": {ScopeAdmin}}
+
+	basic := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+	user, err := AuthorizeAdminLogin(basic("bob,jr", "pw"), ScopeSchema)
+	require.NoError(t, err)
+	require.Equal(t, "bob,jr", user)
+	_, err = AuthorizeAdminLogin(basic("bob,jr", "wrong"), ScopeSchema)
+	require.Equal(t, ErrAdminLogin, err)
+	// Binds without a password would be anonymous ones.
+	_, err = AuthorizeAdminLogin(basic("bob,jr", ""), ScopeSchema)
+	require.Equal(t, ErrAdminLogin, err)
+
+	adminRoles = map[string][]string{"other": {ScopeExport}}
+	_, err = AuthorizeAdminLogin(basic("bob,jr", "pw"), ScopeSchema)
+	require.Equal(t, ErrAdminTokenScope, err)
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Admin tokens authorize admin requests for a scope, until they expire. They're created, rotated
+// and revoked through the admin API while the server runs, and kept in the JSON file of
+// --admin_tokens, with a hash of their secrets. A token is given as "<id>.<secret>", in the
+// X-Admin-Token header over HTTP, and in the auth-token metadata of the Admin gRPC service.
+
+// Scopes of admin tokens. Tokens of the admin scope are allowed everything.
+const (
+	ScopeExport = "export" // Exports and backups.
+	ScopeSchema = "schema" // Schema changes.
+	ScopeAdmin  = "admin"  // All admin operations, and managing admin tokens.
+)
+
+const (
+	// DefaultAdminTokenTTL is how long tokens are valid for, if they're created without a ttl.
+	DefaultAdminTokenTTL = 24 * time.Hour
+	// adminTokenGrace is how long rotated tokens stay valid for, for clients to switch over.
+	adminTokenGrace = time.Minute
+)
+
+var (
+	ErrAdminToken      = errors.New("Invalid or expired admin token")
+	ErrAdminTokenScope = errors.New("Admin token not allowed for this operation")
+)
+
+// AdminToken is an admin token, without its secret.
+type AdminToken struct {
+	Id      string    `json:"id"`
+	Scope   string    `json:"scope"`
+	Hash    string    `json:"hash,omitempty"` // Hex of the sha256 of the secret.
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+var adminTokens = struct {
+	sync.RWMutex
+	tokens map[string]*AdminToken
+}{tokens: make(map[string]*AdminToken)}
+
+// AdminTokensEnabled returns whether the server has --admin_tokens.
+func AdminTokensEnabled() bool {
+	return Config.AdminTokens != ""
+}
+
+func validScope(scope string) bool {
+	return scope == ScopeExport || scope == ScopeSchema || scope == ScopeAdmin
+}
+
+// LoadAdminTokens reads the admin tokens from the file of --admin_tokens.
+func LoadAdminTokens() error {
+	if Config.AdminTokens == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(Config.AdminTokens)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var tokens []*AdminToken
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return x.Wrapf(err, "While reading admin tokens from %v", Config.AdminTokens)
+	}
+	m := make(map[string]*AdminToken, len(tokens))
+	for _, t := range tokens {
+		if !validScope(t.Scope) {
+			return x.Errorf("Invalid scope of admin token %q: %q", t.Id, t.Scope)
+		}
+		m[t.Id] = t
+	}
+	adminTokens.Lock()
+	adminTokens.tokens = m
+	adminTokens.Unlock()
+	return nil
+}
+
+// saveAdminTokens drops the expired tokens, and writes the others. It's called with adminTokens
+// locked.
+func saveAdminTokens(now time.Time) error {
+	tokens := make([]*AdminToken, 0, len(adminTokens.tokens))
+	for id, t := range adminTokens.tokens {
+		if now.After(t.Expires) {
+			delete(adminTokens.tokens, id)
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created.Before(tokens[j].Created) })
+	return writeJSONFile(Config.AdminTokens, tokens)
+}
+
+func hashSecret(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// AdminTokens returns the admin tokens which haven't expired, oldest first.
+func AdminTokens() []AdminToken {
+	adminTokens.RLock()
+	defer adminTokens.RUnlock()
+	now := time.Now()
+	var tokens []AdminToken
+	for _, t := range adminTokens.tokens {
+		if now.After(t.Expires) {
+			continue
+		}
+		c := *t
+		c.Hash = ""
+		tokens = append(tokens, c)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created.Before(tokens[j].Created) })
+	return tokens
+}
+
+// newAdminToken adds a token of scope valid for ttl, and returns it with its secret. It's called
+// with adminTokens locked.
+func newAdminToken(scope string, ttl time.Duration, now time.Time) (string, AdminToken, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", AdminToken{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", AdminToken{}, err
+	}
+	t := &AdminToken{
+		Id:      id,
+		Scope:   scope,
+		Hash:    hashSecret(secret),
+		Created: now,
+		Expires: now.Add(ttl),
+	}
+	adminTokens.tokens[id] = t
+	c := *t
+	c.Hash = ""
+	return id + "." + secret, c, nil
+}
+
+// CreateAdminToken creates a token of scope, valid for ttl, and returns it with its secret. The
+// secret can't be read back afterwards.
+func CreateAdminToken(scope string, ttl time.Duration) (string, AdminToken, error) {
+	if !AdminTokensEnabled() {
+		return "", AdminToken{}, x.Errorf("Admin tokens (--admin_tokens) aren't enabled")
+	}
+	if !validScope(scope) {
+		return "", AdminToken{}, x.Errorf("Invalid scope: %q. Expected export, schema or admin",
+			scope)
+	}
+	if ttl <= 0 {
+		return "", AdminToken{}, x.Errorf("The ttl of admin tokens must be positive")
+	}
+	adminTokens.Lock()
+	defer adminTokens.Unlock()
+	now := time.Now()
+	token, t, err := newAdminToken(scope, ttl, now)
+	if err != nil {
+		return "", AdminToken{}, err
+	}
+	if err := saveAdminTokens(now); err != nil {
+		delete(adminTokens.tokens, t.Id)
+		return "", AdminToken{}, err
+	}
+	return token, t, nil
+}
+
+// RotateAdminToken replaces the token id with a new one of the same scope and ttl, and returns it
+// with its secret. The token replaced stays valid for a minute, for clients to switch over.
+func RotateAdminToken(id string) (string, AdminToken, error) {
+	adminTokens.Lock()
+	defer adminTokens.Unlock()
+	now := time.Now()
+	old, ok := adminTokens.tokens[id]
+	if !ok || now.After(old.Expires) {
+		return "", AdminToken{}, x.Errorf("No admin token %q", id)
+	}
+	token, t, err := newAdminToken(old.Scope, old.Expires.Sub(old.Created), now)
+	if err != nil {
+		return "", AdminToken{}, err
+	}
+	expires := old.Expires
+	if grace := now.Add(adminTokenGrace); grace.Before(expires) {
+		old.Expires = grace
+	}
+	if err := saveAdminTokens(now); err != nil {
+		old.Expires = expires
+		delete(adminTokens.tokens, t.Id)
+		return "", AdminToken{}, err
+	}
+	return token, t, nil
+}
+
+// RevokeAdminToken removes the token id, which can't be used from then on.
+func RevokeAdminToken(id string) error {
+	adminTokens.Lock()
+	defer adminTokens.Unlock()
+	t, ok := adminTokens.tokens[id]
+	if !ok {
+		return x.Errorf("No admin token %q", id)
+	}
+	delete(adminTokens.tokens, id)
+	if err := saveAdminTokens(time.Now()); err != nil {
+		adminTokens.tokens[id] = t
+		return err
+	}
+	return nil
+}
+
+// AuthorizeAdminToken checks that token is valid, and allowed for operations of scope. It returns
+// the id of the token.
+func AuthorizeAdminToken(token, scope string) (string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return "", ErrAdminToken
+	}
+	adminTokens.RLock()
+	t, ok := adminTokens.tokens[parts[0]]
+	adminTokens.RUnlock()
+	if !ok || time.Now().After(t.Expires) ||
+		subtle.ConstantTimeCompare([]byte(hashSecret(parts[1])), []byte(t.Hash)) != 1 {
+		return "", ErrAdminToken
+	}
+	if t.Scope != ScopeAdmin && t.Scope != scope {
+		return t.Id, ErrAdminTokenScope
+	}
+	return t.Id, nil
+}
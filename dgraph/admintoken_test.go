@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "admintokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+
+	_, _, err = CreateAdminToken(ScopeAdmin, time.Hour)
+	require.Error(t, err)
+	Config.AdminTokens = filepath.Join(dir, "tokens.json")
+	_, _, err = CreateAdminToken("everything", time.Hour)
+	require.Error(t, err)
+
+	export, et, err := CreateAdminToken(ScopeExport, time.Hour)
+	require.NoError(t, err)
+	admin, at, err := CreateAdminToken(ScopeAdmin, time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, et.Hash)
+
+	id, err := AuthorizeAdminToken(export, ScopeExport)
+	require.NoError(t, err)
+	require.Equal(t, et.Id, id)
+	_, err = AuthorizeAdminToken(export, ScopeSchema)
+	require.Equal(t, ErrAdminTokenScope, err)
+	_, err = AuthorizeAdminToken(admin, ScopeSchema)
+	require.NoError(t, err)
+	_, err = AuthorizeAdminToken(et.Id+".wrong", ScopeExport)
+	require.Equal(t, ErrAdminToken, err)
+
+	// Tokens are kept with the hashes of their secrets only.
+	b, err := ioutil.ReadFile(Config.AdminTokens)
+	require.NoError(t, err)
+	require.False(t, strings.Contains(string(b), strings.SplitN(admin, ".", 2)[1]))
+	require.NoError(t, RevokeAdminToken(at.Id))
+	require.NoError(t, LoadAdminTokens())
+	require.Len(t, AdminTokens(), 1)
+	_, err = AuthorizeAdminToken(admin, ScopeAdmin)
+	require.Equal(t, ErrAdminToken, err)
+	_, err = AuthorizeAdminToken(export, ScopeExport)
+	require.NoError(t, err)
+
+	// The rotated token stays valid until the grace period is over.
+	rotated, rt, err := RotateAdminToken(et.Id)
+	require.NoError(t, err)
+	require.Equal(t, ScopeExport, rt.Scope)
+	require.Equal(t, time.Hour, rt.Expires.Sub(rt.Created))
+	_, err = AuthorizeAdminToken(rotated, ScopeExport)
+	require.NoError(t, err)
+	_, err = AuthorizeAdminToken(export, ScopeExport)
+	require.NoError(t, err)
+	adminTokens.tokens[et.Id].Expires = time.Now().Add(-time.Second)
+	_, err = AuthorizeAdminToken(export, ScopeExport)
+	require.Equal(t, ErrAdminToken, err)
+	require.Len(t, AdminTokens(), 1)
+	require.Error(t, RevokeAdminToken(et.Id+"x"))
+}
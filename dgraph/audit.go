@@ -0,0 +1,288 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The audit log records who ran which requests, when, from where and on which predicates, as a
+// JSON object per line in the file of --audit_log. The file is only appended to, and is rotated
+// once past --audit_log_size, keeping the --audit_log_files last ones. Entries are also given to
+// the sinks added with AddAuditSink, to send them elsewhere.
+
+// Audit levels, from the least to the most detailed. Each records what the previous ones do.
+const (
+	AuditAdmin     = iota + 1 // Admin operations, and requests refused for their credentials.
+	AuditMutations            // Mutations and schema changes.
+	AuditQueries              // Queries.
+	AuditRequests             // The text of queries and mutations.
+)
+
+var auditLevels = map[string]int{
+	"admin":     AuditAdmin,
+	"mutations": AuditMutations,
+	"queries":   AuditQueries,
+	"requests":  AuditRequests,
+}
+
+// AuditEntry is an entry of the audit log.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// User is the user of access control lists or tokens the request was sent with, if any.
+	User      string `json:"user,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Remote    string `json:"remote"`
+	// Endpoint is the HTTP route or the gRPC method of the request.
+	Endpoint string `json:"endpoint"`
+	Method   string `json:"method,omitempty"`
+	// Operation is admin, auth, query or mutation.
+	Operation string   `json:"operation"`
+	Read      []string `json:"read,omitempty"`
+	Written   []string `json:"written,omitempty"`
+	Edges     int      `json:"edges,omitempty"`
+	// Request is the text of queries and mutations, or the URI of admin requests.
+	Request string `json:"request,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency,omitempty"`
+
+	start time.Time
+}
+
+// AuditSink gets the entries of the audit log. It's called for every entry, and shouldn't block.
+type AuditSink func(e *AuditEntry)
+
+var audit = struct {
+	sync.Mutex
+	level int
+	f     *logFile
+	sinks []AuditSink
+}{}
+
+// AddAuditSink adds a sink which gets the entries of the audit log. It must be called before the
+// server starts.
+func AddAuditSink(s AuditSink) {
+	audit.Lock()
+	defer audit.Unlock()
+	audit.sinks = append(audit.sinks, s)
+}
+
+// ParseAuditLevel parses the level of --audit_level.
+func ParseAuditLevel(s string) (int, error) {
+	level, ok := auditLevels[strings.ToLower(s)]
+	if !ok {
+		return 0, x.Errorf("Invalid audit level: %q. Expected admin, mutations, queries or requests",
+			s)
+	}
+	return level, nil
+}
+
+// OpenAuditLog opens the file of --audit_log, and sets the level of the audit log. Entries are
+// only recorded once it's called, with --audit_log or sinks.
+func OpenAuditLog() error {
+	level, err := ParseAuditLevel(Config.AuditLevel)
+	if err != nil {
+		return err
+	}
+	audit.Lock()
+	defer audit.Unlock()
+	if Config.AuditLog != "" {
+		f, err := openLogFile(Config.AuditLog, Config.AuditLogSize, Config.AuditLogFiles)
+		if err != nil {
+			return err
+		}
+		audit.f = f
+	}
+	if audit.f != nil || len(audit.sinks) > 0 {
+		audit.level = level
+	}
+	return nil
+}
+
+// CloseAuditLog closes the file of the audit log.
+func CloseAuditLog() error {
+	audit.Lock()
+	defer audit.Unlock()
+	audit.level = 0
+	if audit.f == nil {
+		return nil
+	}
+	err := audit.f.close()
+	audit.f = nil
+	return err
+}
+
+func auditing(level int) bool {
+	audit.Lock()
+	defer audit.Unlock()
+	return audit.level >= level
+}
+
+func writeAudit(e *AuditEntry) {
+	if !e.start.IsZero() {
+		e.Latency = time.Since(e.start).String()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		x.Printf("Error while encoding audit entry: %v\n", err)
+		return
+	}
+	b = append(b, '\n')
+
+	audit.Lock()
+	sinks := audit.sinks
+	if audit.f != nil {
+		if err := audit.f.write(b); err != nil {
+			x.Printf("Error while writing audit log: %v\n", err)
+		}
+	}
+	audit.Unlock()
+	for _, s := range sinks {
+		s(e)
+	}
+}
+
+// AuditRequest starts the entry of a query or mutation sent by user from remote to endpoint, with
+// its text and its parsed res, before it's run. It's recorded by Done once it's run, and is nil if
+// requests like it aren't audited.
+func AuditRequest(endpoint, remote, user, ns, text string, res *gql.Result) *AuditEntry {
+	e := &AuditEntry{
+		Time:      time.Now(),
+		User:      user,
+		Namespace: ns,
+		Remote:    remote,
+		Endpoint:  endpoint,
+		Operation: "query",
+		start:     time.Now(),
+	}
+	level := AuditQueries
+	if m := res.Mutation; m != nil && (m.HasOps() || len(m.Schema) > 0) {
+		e.Operation, level = "mutation", AuditMutations
+		e.Edges = len(m.Set) + len(m.Del)
+	}
+	if !auditing(level) {
+		return nil
+	}
+	e.Read, e.Written = query.Predicates(res)
+	if auditing(AuditRequests) {
+		e.Request = text
+	}
+	return e
+}
+
+// Done records e, with the error its request failed with, if any.
+func (e *AuditEntry) Done(err error) {
+	if e == nil {
+		return
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	writeAudit(e)
+}
+
+// AuditDenied records a request to endpoint refused for the credentials of user.
+func AuditDenied(endpoint, remote, user string, err error) {
+	if !auditing(AuditAdmin) {
+		return
+	}
+	writeAudit(&AuditEntry{
+		Time:      time.Now(),
+		User:      user,
+		Remote:    remote,
+		Endpoint:  endpoint,
+		Operation: "auth",
+		Error:     err.Error(),
+	})
+}
+
+// AuditAdminRPC records an admin operation of the gRPC method, which started at start.
+func AuditAdminRPC(ctx context.Context, method string, start time.Time, err error) {
+	if !auditing(AuditAdmin) {
+		return
+	}
+	e := &AuditEntry{
+		Time:      start,
+		Remote:    GRPCRemote(ctx),
+		Endpoint:  method,
+		Operation: "admin",
+		start:     start,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	writeAudit(e)
+}
+
+// GRPCRemote returns the address of the client of a gRPC request.
+func GRPCRemote(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// auditHTTP wraps h of the admin route, to record its requests.
+func auditHTTP(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auditing(AuditAdmin) || r.Method == http.MethodOptions {
+			h.ServeHTTP(w, r)
+			return
+		}
+		e := &AuditEntry{
+			Time:      time.Now(),
+			Remote:    r.RemoteAddr,
+			Endpoint:  route,
+			Method:    r.Method,
+			Operation: "admin",
+			// Admin requests keep their secrets, like tokens and passwords, in their bodies.
+			Request: r.URL.RequestURI(),
+			start:   time.Now(),
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		e.Status = rec.status
+		if rec.status >= http.StatusBadRequest {
+			e.Error = fmt.Sprintf("Status %d", rec.status)
+		}
+		writeAudit(e)
+	})
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/gql"
+)
+
+func readAudit(t *testing.T, path string) []AuditEntry {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var entries []AuditEntry
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e AuditEntry
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, s.Err())
+	return entries
+}
+
+func auditQuery(t *testing.T, user, q string, err error) {
+	res, perr := gql.Parse(gql.Request{Str: q})
+	require.NoError(t, perr)
+	AuditRequest("/query", "127.0.0.1:1234", user, "", q, &res).Done(err)
+}
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+
+	Config.AuditLog = filepath.Join(dir, "audit.log")
+	Config.AuditLevel = "mutations"
+	require.NoError(t, OpenAuditLog())
+	defer CloseAuditLog()
+
+	// Queries are left out at the mutations level.
+	auditQuery(t, "alice", `{ me(func: eq(name, "Alice")) { name friend { age } } }`, nil)
+	auditQuery(t, "bob", `mutation {
+		schema { age: int . }
+		set {
+			<0x1> <name> "Bob" .
+			<0x1> <friend> <0x2> .
+		}
+	}`, errors.New("Permission denied"))
+	AuditDenied("/query", "127.0.0.1:1234", "eve", ErrUserDenied)
+
+	rr := httptest.NewRecorder()
+	WrapHTTP("/admin/export", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})).ServeHTTP(rr, httptest.NewRequest("GET", "/admin/export?format=json", nil))
+
+	entries := readAudit(t, Config.AuditLog)
+	require.Len(t, entries, 3)
+	m := entries[0]
+	require.Equal(t, "bob", m.User)
+	require.Equal(t, "mutation", m.Operation)
+	require.Equal(t, []string{"age", "friend", "name"}, m.Written)
+	require.Equal(t, 2, m.Edges)
+	require.Equal(t, "Permission denied", m.Error)
+	require.Empty(t, m.Request)
+	require.Equal(t, "auth", entries[1].Operation)
+	require.Equal(t, "eve", entries[1].User)
+	require.Equal(t, "/admin/export", entries[2].Endpoint)
+	require.Equal(t, "/admin/export?format=json", entries[2].Request)
+	require.Equal(t, http.StatusNotFound, entries[2].Status)
+
+	// The requests level has queries, with their text.
+	require.NoError(t, CloseAuditLog())
+	Config.AuditLevel = "requests"
+	require.NoError(t, OpenAuditLog())
+	q := `{ me(func: eq(name, "Alice")) @filter(gt(age, 20)) { name ~friend { age } } }`
+	auditQuery(t, "alice", q, nil)
+	entries = readAudit(t, Config.AuditLog)
+	require.Len(t, entries, 4)
+	require.Equal(t, []string{"age", "friend", "name"}, entries[3].Read)
+	require.Equal(t, q, entries[3].Request)
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+
+	Config.AuditLog = filepath.Join(dir, "audit.log")
+	Config.AuditLevel = "admin"
+	Config.AuditLogSize = 200
+	Config.AuditLogFiles = 2
+	require.NoError(t, OpenAuditLog())
+	defer CloseAuditLog()
+
+	var sunk int
+	AddAuditSink(func(e *AuditEntry) { sunk++ })
+	defer func() { audit.sinks = nil }()
+	for i := 0; i < 10; i++ {
+		AuditDenied("/query", "127.0.0.1:1234", "eve", ErrUserDenied)
+	}
+	require.Equal(t, 10, sunk)
+
+	rotated, err := filepath.Glob(Config.AuditLog + ".*")
+	require.NoError(t, err)
+	require.Len(t, rotated, 2)
+	fi, err := os.Stat(Config.AuditLog)
+	require.NoError(t, err)
+	require.True(t, fi.Size() <= Config.AuditLogSize)
+	for _, path := range rotated {
+		require.NotEmpty(t, readAudit(t, path))
+	}
+}
@@ -17,9 +17,17 @@
 package dgraph
 
 import (
+	"compress/gzip"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/objstore"
 	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/tracing"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -28,22 +36,112 @@ type Options struct {
 	PostingDir    string
 	PostingTables string
 	WALDir        string
+	MinFreeDiskMB int64
 	Nomutations   bool
 
-	AllottedMemory float64
-	CommitFraction float64
+	LiveQueryThrottle time.Duration
+	PersistedQueries  string
+	PersistedOnly     bool
+	Cypher            bool
+	CypherLabel       string
+	SPARQL            bool
+	UDFPlugins        string
+	UDFTimeout        time.Duration
+	UDFConcurrency    int
+
+	CorsOrigins   string
+	TenantHeader  string
+	BodyLimits    string
+	ConnBodyLimit int64
+	Namespaces    string
+	IPAllowlist   string
+	ACL           string
+	AdminTokens   string
+
+	AdminOIDCIssuer   string
+	AdminOIDCClientId string
+	AdminGroupsClaim  string
+	AdminLDAP         string
+	AdminLDAPUserDN   string
+	AdminRoles        string
+
+	JWTKeys        string
+	JWTSecret      string
+	JWTIssuers     string
+	JWTAudience    string
+	JWTUserClaim   string
+	JWTGroupsClaim string
+
+	AuditLog      string
+	AuditLevel    string
+	AuditLogSize  int64
+	AuditLogFiles int
+
+	SlowQueryLatency  time.Duration
+	SlowQueryBytes    int64
+	SlowQueryLog      string
+	SlowQueryLogSize  int64
+	SlowQueryLogFiles int
+	QueryStats        int
+
+	RateLimit        float64
+	RateBurst        int
+	MaxClientQueries int
+
+	ValueGCInterval  time.Duration
+	ValueGCThreshold float64
+	Retention        string
+
+	AllottedMemory     float64
+	CommitFraction     float64
+	CommitLatency      time.Duration
+	CompactionPriority string
+	BlobThreshold      int
 
 	BaseWorkerPort      int
 	ExportPath          string
+	BackupPath          string
+	Changelog           bool
+	ChangelogArchive    string
+	ChangelogArchiveLag time.Duration
+	CDCKafka            string
+	CDCTopic            string
+	CDCTopics           string
+	CDCFormat           string
+	Elastic             string
+	ElasticIndex        string
+	ElasticPredicates   string
+	Webhooks            string
+	ObjectEncryption    string
+	Compression         string
+	CompressionLevel    int
+	EncryptionKeyFile   string
+	EncryptionKMSKey    string
+	ExportRedact        string
+	ExportRedactKey     string
+	RedactBackups       bool
 	NumPendingProposals int
 	Tracing             float64
+	TraceCollector      string
+	TraceService        string
+	MetricsPredicates   int
+	LogFormat           string
+	LogLevels           string
 	GroupIds            string
 	MyAddr              string
+	ClientAddr          string
 	PeerAddr            string
 	RaftId              uint64
 	MaxPendingCount     uint64
 	ExpandEdge          bool
+	KindPredicate       string
+	GeoRepair           bool
+	GeoCacheMB          uint64
 	InMemoryComm        bool
+	EventRetention      time.Duration
+	ProgressThreshold   time.Duration
+	StallDeadline       time.Duration
+	DiagnosticsPath     string
 
 	ConfigFile string
 	DebugMode  bool
@@ -56,23 +154,113 @@ var DefaultConfig = Options{
 	PostingDir:    "p",
 	PostingTables: "loadtoram",
 	WALDir:        "w",
+	MinFreeDiskMB: 256,
 	Nomutations:   false,
 
+	LiveQueryThrottle: 500 * time.Millisecond,
+	PersistedQueries:  "",
+	PersistedOnly:     false,
+	Cypher:            false,
+	CypherLabel:       "label",
+	SPARQL:            false,
+	UDFPlugins:        "",
+	UDFTimeout:        udf.DefaultLimits.Timeout,
+	UDFConcurrency:    udf.DefaultLimits.Concurrency,
+
+	CorsOrigins:   "*",
+	TenantHeader:  "",
+	BodyLimits:    "",
+	ConnBodyLimit: 0,
+	Namespaces:    "",
+	IPAllowlist:   "",
+	ACL:           "",
+	AdminTokens:   "",
+
+	AdminOIDCIssuer:   "",
+	AdminOIDCClientId: "",
+	AdminGroupsClaim:  "groups",
+	AdminLDAP:         "",
+	AdminLDAPUserDN:   "",
+	AdminRoles:        "",
+
+	JWTKeys:        "",
+	JWTSecret:      "",
+	JWTIssuers:     "",
+	JWTAudience:    "",
+	JWTUserClaim:   "sub",
+	JWTGroupsClaim: "groups",
+
+	AuditLog:      "",
+	AuditLevel:    "mutations",
+	AuditLogSize:  100 << 20,
+	AuditLogFiles: 10,
+
+	SlowQueryLatency:  0,
+	SlowQueryBytes:    0,
+	SlowQueryLog:      "",
+	SlowQueryLogSize:  100 << 20,
+	SlowQueryLogFiles: 10,
+	QueryStats:        0,
+
+	RateLimit:        0,
+	RateBurst:        0,
+	MaxClientQueries: 0,
+
+	ValueGCInterval:  10 * time.Minute,
+	ValueGCThreshold: 0.5,
+	Retention:        "",
+
 	// User must specify this.
-	AllottedMemory: -1.0,
-	CommitFraction: 0.10,
+	AllottedMemory:     -1.0,
+	CommitFraction:     0.10,
+	CommitLatency:      500 * time.Millisecond,
+	CompactionPriority: "",
+	BlobThreshold:      64 << 10,
 
 	BaseWorkerPort:      12345,
 	ExportPath:          "export",
+	BackupPath:          "backup",
+	Changelog:           false,
+	ChangelogArchive:    "",
+	ChangelogArchiveLag: time.Minute,
+	CDCKafka:            "",
+	CDCTopic:            "dgraph",
+	CDCTopics:           "",
+	CDCFormat:           "dgraph",
+	Elastic:             "",
+	ElasticIndex:        "dgraph",
+	ElasticPredicates:   "",
+	Webhooks:            "",
+	ObjectEncryption:    "",
+	Compression:         "gzip",
+	CompressionLevel:    gzip.BestCompression,
+	EncryptionKeyFile:   "",
+	EncryptionKMSKey:    "",
+	ExportRedact:        "",
+	ExportRedactKey:     "",
+	RedactBackups:       false,
 	NumPendingProposals: 2000,
 	Tracing:             0.0,
+	TraceCollector:      "",
+	TraceService:        "dgraph",
+	MetricsPredicates:   100,
+	LogFormat:           "text",
+	LogLevels:           "",
 	GroupIds:            "0,1",
 	MyAddr:              "",
+	ClientAddr:          "",
 	PeerAddr:            "",
 	RaftId:              1,
 	MaxPendingCount:     1000,
 	ExpandEdge:          true,
+	KindPredicate:       "kind",
+	GeoRepair:           false,
+	GeoCacheMB:          types.DefaultGeoCacheSize >> 20,
 	InMemoryComm:        false,
+	EventRetention:      7 * 24 * time.Hour,
+	ProgressThreshold:   time.Second,
+	StallDeadline:       10 * time.Minute,
+	DiagnosticsPath:     "diagnostics",
 
 	ConfigFile: "",
 	DebugMode:  false,
@@ -88,21 +276,82 @@ func SetConfiguration(newConfig Options) {
 	posting.Config.Mu.Unlock()
 
 	posting.Config.CommitFraction = Config.CommitFraction
+	posting.Config.CommitLatency = Config.CommitLatency
+	prios, err := posting.ParseCompactionPriorities(Config.CompactionPriority)
+	x.Checkf(err, "While parsing --compaction_priority")
+	posting.SetCompactionPriorities(prios)
+	posting.Config.BlobThreshold = Config.BlobThreshold
+	posting.Config.ValueGCInterval = Config.ValueGCInterval
+	retentions, err := posting.ParseRetentions(Config.Retention)
+	x.Checkf(err, "While parsing --retention")
+	posting.SetRetentions(retentions)
+	limits, err := ParseBodyLimits(Config.BodyLimits)
+	x.Checkf(err, "While parsing --body_limits")
+	bodyLimits = limits
+	nets, err := ParseAllowlist(Config.IPAllowlist)
+	x.Checkf(err, "While parsing --ip_allowlist")
+	allowlist = nets
+	roles, err := ParseAdminRoles(Config.AdminRoles)
+	x.Checkf(err, "While parsing --admin_roles")
+	adminRoles = roles
 
 	worker.Config.BaseWorkerPort = Config.BaseWorkerPort
 	worker.Config.ExportPath = Config.ExportPath
+	worker.Config.BackupPath = Config.BackupPath
+	worker.Config.Changelog = Config.Changelog
+	worker.Config.ChangelogArchive = Config.ChangelogArchive
+	worker.Config.ChangelogArchiveLag = Config.ChangelogArchiveLag
+	worker.Config.CDCKafka = Config.CDCKafka
+	worker.Config.CDCTopic = Config.CDCTopic
+	worker.Config.CDCTopics = Config.CDCTopics
+	worker.Config.CDCFormat = Config.CDCFormat
+	worker.Config.Elastic = Config.Elastic
+	worker.Config.ElasticIndex = Config.ElasticIndex
+	worker.Config.ElasticPredicates = Config.ElasticPredicates
+	worker.Config.Webhooks = Config.Webhooks != ""
+	worker.Config.ExportRedact = Config.ExportRedact
+	worker.Config.ExportRedactKey = Config.ExportRedactKey
+	worker.Config.RedactBackups = Config.RedactBackups
+	objstore.Config.Encryption = Config.ObjectEncryption
+	udf.SetLimits(udf.Limits{Timeout: Config.UDFTimeout, Concurrency: Config.UDFConcurrency})
+	artifact.Config = Config.artifactOptions()
 	worker.Config.NumPendingProposals = Config.NumPendingProposals
 	worker.Config.Tracing = Config.Tracing
+	tracing.Config = tracing.Options{
+		Collector: Config.TraceCollector,
+		Service:   Config.TraceService,
+		Sample:    Config.Tracing,
+	}
 	worker.Config.GroupIds = Config.GroupIds
 	worker.Config.MyAddr = Config.MyAddr
+	worker.Config.ClientAddr = Config.ClientAddr
 	worker.Config.PeerAddr = Config.PeerAddr
 	worker.Config.RaftId = Config.RaftId
 	worker.Config.MaxPendingCount = Config.MaxPendingCount
 	worker.Config.ExpandEdge = Config.ExpandEdge
+	worker.Config.KindPredicate = Config.KindPredicate
+	worker.Config.GeoRepair = Config.GeoRepair
+	types.SetGeoCacheSize(Config.GeoCacheMB << 20)
 	worker.Config.InMemoryComm = Config.InMemoryComm
+	worker.Config.EventRetention = Config.EventRetention
+	worker.Config.ProgressThreshold = Config.ProgressThreshold
+	worker.Config.StallDeadline = Config.StallDeadline
+	worker.Config.DiagnosticsPath = Config.DiagnosticsPath
 
 	x.Config.ConfigFile = Config.ConfigFile
 	x.Config.DebugMode = Config.DebugMode
+	x.Config.MetricsPredicates = Config.MetricsPredicates
+	x.Checkf(x.SetLogFormat(Config.LogFormat), "While setting --log_format")
+	x.Checkf(x.SetLogLevels(Config.LogLevels), "While parsing --log_levels")
+}
+
+func (o *Options) artifactOptions() artifact.Options {
+	return artifact.Options{
+		Compression: o.Compression,
+		Level:       o.CompressionLevel,
+		KeyFile:     o.EncryptionKeyFile,
+		KMSKey:      o.EncryptionKMSKey,
+	}
 }
 
 const MinAllottedMemory = 1024.0
@@ -117,4 +366,80 @@ func (o *Options) validate() {
 		"Allotted memory (--memory_mb) must be specified, with value greater than 1024 MB")
 	x.AssertTruef(o.AllottedMemory >= MinAllottedMemory,
 		"Allotted memory (--memory_mb) must be at least %.0f MB. Currently set to: %f", MinAllottedMemory, o.AllottedMemory)
+	x.AssertTruef(o.ValueGCThreshold >= 0.0 && o.ValueGCThreshold <= 1.0,
+		"Value GC threshold (--value_gc_threshold) must be between 0 and 1. Currently set to: %f",
+		o.ValueGCThreshold)
+	x.Check(objstore.ValidateEncryption(o.ObjectEncryption))
+	x.Checkf(artifact.Validate(o.artifactOptions()),
+		"While checking the compression and encryption of exports and backups")
+	x.Checkf(worker.ValidateRedactRules(o.ExportRedact), "While parsing --export_redact")
+	x.AssertTruef(!o.RedactBackups || o.ExportRedact != "",
+		"Redacting backups (--redact_backups) needs redaction rules (--export_redact).")
+	x.AssertTruef(!o.Changelog || !objstore.IsURI(o.BackupPath),
+		"The changelog (--changelog) can only be kept in a local backup folder (--backup).")
+	x.AssertTruef(o.ChangelogArchive == "" || o.Changelog,
+		"Archiving the changelog (--changelog_archive) needs the changelog (--changelog) on.")
+	x.AssertTruef(o.CDCKafka == "" || o.Changelog,
+		"Publishing changes to Kafka (--cdc_kafka) needs the changelog (--changelog) on.")
+	x.Checkf(worker.ValidateCDCTopics(o.CDCTopics), "While parsing --cdc_topics")
+	x.Checkf(worker.ValidateCDCFormat(o.CDCFormat), "While parsing --cdc_format")
+	x.AssertTruef(o.Elastic == "" || o.Changelog,
+		"Mirroring predicates to Elasticsearch (--elastic) needs the changelog (--changelog) on.")
+	x.AssertTruef(o.Elastic == "" || o.ElasticIndex != "",
+		"Mirroring predicates to Elasticsearch (--elastic) needs an index (--elastic_index).")
+	if o.ElasticPredicates != "" {
+		x.Checkf(worker.ValidatePatterns(strings.Split(o.ElasticPredicates, ",")),
+			"While parsing --elastic_predicates")
+	}
+	x.AssertTruef(o.Webhooks == "" || o.Changelog,
+		"Posting changes to webhooks (--webhooks) needs the changelog (--changelog) on.")
+	x.AssertTruef(o.LiveQueryThrottle >= 0,
+		"The live query throttle (--live_query_throttle) can't be negative.")
+	x.AssertTruef(!o.PersistedOnly || o.PersistedQueries != "",
+		"Running only persisted queries (--persisted_only) needs a file (--persisted_queries) "+
+			"to keep them in.")
+	x.AssertTruef(!o.Cypher || o.CypherLabel != "",
+		"Cypher queries (--cypher) need the predicate of the labels of nodes (--cypher_label).")
+	x.AssertTruef(o.UDFTimeout >= 0,
+		"The timeout of functions (--udf_timeout) can't be negative.")
+	x.AssertTruef(o.UDFConcurrency > 0,
+		"The calls of functions running at once (--udf_concurrency) must be positive.")
+	x.AssertTruef(o.Namespaces == "" || o.TenantHeader != "",
+		"Namespaces (--namespaces) are selected with the tenant header (--tenant_header), "+
+			"which must be set.")
+	x.AssertTruef(o.ChangelogArchiveLag > 0,
+		"The changelog archive lag (--changelog_archive_lag) must be positive.")
+	x.AssertTruef(o.RateLimit >= 0 && o.RateBurst >= 0 && o.MaxClientQueries >= 0,
+		"The limits of clients (--rate_limit, --rate_burst and --max_client_queries) can't be "+
+			"negative.")
+	x.AssertTruef(o.AdminOIDCIssuer == "" || o.AdminOIDCClientId != "",
+		"Admin logins with OIDC (--admin_oidc_issuer) need the client id (--admin_oidc_client_id) "+
+			"their ID tokens are meant for.")
+	x.AssertTruef(o.AdminLDAP == "" || strings.Contains(o.AdminLDAPUserDN, "%s"),
+		"Admin logins with LDAP (--admin_ldap) need the DN of users (--admin_ldap_user_dn), with "+
+			"%%s for the user.")
+	x.AssertTruef((o.AdminOIDCIssuer == "" && o.AdminLDAP == "") || o.AdminRoles != "",
+		"Admin logins (--admin_oidc_issuer or --admin_ldap) need the roles of groups "+
+			"(--admin_roles).")
+	x.AssertTruef(o.TraceCollector == "" || strings.HasPrefix(o.TraceCollector, "http://") ||
+		strings.HasPrefix(o.TraceCollector, "https://"),
+		"The trace collector (--trace_collector) must be an http:// or https:// URL.")
+	x.AssertTruef(o.SlowQueryLatency >= 0 && o.SlowQueryBytes >= 0,
+		"The thresholds of slow queries (--slow_query_latency and --slow_query_bytes) can't be "+
+			"negative.")
+	x.AssertTruef(o.SlowQueryLog == "" || o.SlowQueryLatency > 0 || o.SlowQueryBytes > 0,
+		"The slow query log (--slow_query_log) needs a threshold (--slow_query_latency or "+
+			"--slow_query_bytes).")
+	x.AssertTruef(o.QueryStats >= 0,
+		"The number of queries with statistics (--query_stats) can't be negative.")
+	x.AssertTruef(o.MetricsPredicates >= 0,
+		"The number of predicates in metrics (--metrics_predicates) can't be negative.")
+	x.AssertTruef(o.EventRetention >= 0,
+		"The retention of the event log (--event_retention) can't be negative.")
+	x.AssertTruef(o.ProgressThreshold >= 0,
+		"The threshold of the progress of queries (--progress_threshold) can't be negative.")
+	x.AssertTruef(o.StallDeadline >= 0,
+		"The deadline of stalled operations (--stall_deadline) can't be negative.")
+	x.AssertTruef(o.TraceCollector == "" || o.TraceService != "",
+		"Reporting traces (--trace_collector) needs the name of the service (--trace_service).")
 }
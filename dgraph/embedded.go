@@ -36,9 +36,9 @@ func GetDefaultEmbeddedConfig() Options {
 	return config
 }
 
-func NewEmbeddedDgraphClient(config Options, opts client.BatchMutationOptions,
-	clientDir string) *client.Dgraph {
-
+// StartEmbedded starts a single node Dgraph in this process, configured with config, without the
+// gRPC and HTTP servers. DisposeEmbeddedDgraph stops it.
+func StartEmbedded(config Options) {
 	SetConfiguration(config)
 
 	x.Init()
@@ -46,9 +46,15 @@ func NewEmbeddedDgraphClient(config Options, opts client.BatchMutationOptions,
 	group.ParseGroupConfig("") // this ensures that only one group is used
 	schema.Init(State.Pstore)
 	posting.Init(State.Pstore)
+	posting.BuildKeyFilters()
 	worker.Init(State.Pstore)
 	worker.StartRaftNodes(State.WALstore, false)
+}
+
+func NewEmbeddedDgraphClient(config Options, opts client.BatchMutationOptions,
+	clientDir string) *client.Dgraph {
 
+	StartEmbedded(config)
 	embedded := &inmemoryClient{&Server{}}
 	return client.NewClient([]protos.DgraphClient{embedded}, opts, clientDir)
 }
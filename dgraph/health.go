@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"syscall"
+
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Probe is the outcome of one of the checks of whether the server can serve queries, with the
+// reason it failed.
+type Probe struct {
+	Name   string `json:"name"`
+	Ok     bool   `json:"ok"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Readiness runs the checks of whether the server can serve queries, and returns their probes
+// with whether all of them passed.
+func Readiness() ([]Probe, bool) {
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{"health", x.HealthCheck},
+		{"schema", worker.CheckSchema},
+		{"raft", worker.CheckRaft},
+		{"membership", worker.CheckMembership},
+		{"disk", checkDisk},
+	}
+	probes := make([]Probe, 0, len(checks))
+	ready := true
+	for _, c := range checks {
+		p := Probe{Name: c.name, Ok: true}
+		if err := c.check(); err != nil {
+			p.Ok, p.Reason = false, err.Error()
+			ready = false
+		}
+		probes = append(probes, p)
+	}
+	return probes, ready
+}
+
+// checkDisk returns an error if the disk of the posting or WAL directory has less than
+// Config.MinFreeDiskMB free.
+func checkDisk() error {
+	for _, dir := range []string{Config.PostingDir, Config.WALDir} {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(dir, &st); err != nil {
+			return x.Wrapf(err, "While checking the free space of %s", dir)
+		}
+		if free := int64(st.Bavail) * int64(st.Bsize) >> 20; free < Config.MinFreeDiskMB {
+			return x.Errorf("Only %d MB free on the disk of %s, below %d MB", free, dir,
+				Config.MinFreeDiskMB)
+		}
+	}
+	return nil
+}
@@ -17,11 +17,14 @@
 package dgraph
 
 import (
+	"io"
+
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
 
 	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/worker"
 )
 
 // inmemoryClient implements protos.DgraphClient (it's equivalent of default grpc client, but for
@@ -44,3 +47,127 @@ func (i *inmemoryClient) AssignUids(ctx context.Context, in *protos.Num,
 	_ ...grpc.CallOption) (*protos.AssignedIds, error) {
 	return i.srv.AssignUids(ctx, in)
 }
+
+func (i *inmemoryClient) Members(ctx context.Context, in *protos.MembersRequest,
+	_ ...grpc.CallOption) (*protos.MemberList, error) {
+	return i.srv.Members(ctx, in)
+}
+
+// inmemoryExportStream hands the chunks of an export from the server to the client over a
+// channel. It only implements Recv of the methods of a grpc.ClientStream.
+type inmemoryExportStream struct {
+	grpc.ClientStream
+	chunks chan *protos.ExportChunk
+	err    error
+}
+
+func (s *inmemoryExportStream) Recv() (*protos.ExportChunk, error) {
+	if chunk, ok := <-s.chunks; ok {
+		return chunk, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, io.EOF
+}
+
+func (i *inmemoryClient) Export(ctx context.Context, in *protos.ExportRequest,
+	_ ...grpc.CallOption) (protos.Dgraph_ExportClient, error) {
+	s := &inmemoryExportStream{chunks: make(chan *protos.ExportChunk)}
+	go func() {
+		s.err = worker.StreamExportOverNetwork(ctx, in, func(chunk *protos.ExportChunk) error {
+			select {
+			case s.chunks <- chunk:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(s.chunks)
+	}()
+	return s, nil
+}
+
+// inmemorySubscribeStream hands the changes of a subscription from the server to the client over a
+// channel. It only implements Recv of the methods of a grpc.ClientStream.
+type inmemorySubscribeStream struct {
+	grpc.ClientStream
+	events chan *protos.ChangeEvent
+	err    error
+}
+
+func (s *inmemorySubscribeStream) Recv() (*protos.ChangeEvent, error) {
+	if ev, ok := <-s.events; ok {
+		return ev, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, io.EOF
+}
+
+func (i *inmemoryClient) Subscribe(ctx context.Context, in *protos.SubscribeRequest,
+	_ ...grpc.CallOption) (protos.Dgraph_SubscribeClient, error) {
+	s := &inmemorySubscribeStream{events: make(chan *protos.ChangeEvent)}
+	go func() {
+		s.err = worker.SubscribeChanges(ctx, in, func(ev *protos.ChangeEvent) error {
+			select {
+			case s.events <- ev:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(s.events)
+	}()
+	return s, nil
+}
+
+// inmemoryRunStream hands the responses of RunStream from the server to the client over a
+// channel. It only implements Recv of the methods of a grpc.ClientStream.
+type inmemoryRunStream struct {
+	grpc.ClientStream
+	resps chan *protos.Response
+	err   error
+}
+
+func (s *inmemoryRunStream) Recv() (*protos.Response, error) {
+	if resp, ok := <-s.resps; ok {
+		return resp, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, io.EOF
+}
+
+// inmemoryRunServer is the server side of an inmemoryRunStream. It only implements Send and
+// Context of the methods of a grpc.ServerStream.
+type inmemoryRunServer struct {
+	grpc.ServerStream
+	ctx   context.Context
+	resps chan *protos.Response
+}
+
+func (s *inmemoryRunServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *inmemoryRunServer) Send(resp *protos.Response) error {
+	select {
+	case s.resps <- resp:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (i *inmemoryClient) RunStream(ctx context.Context, in *protos.Request,
+	_ ...grpc.CallOption) (protos.Dgraph_RunStreamClient, error) {
+	s := &inmemoryRunStream{resps: make(chan *protos.Response)}
+	go func() {
+		s.err = i.srv.RunStream(in, &inmemoryRunServer{ctx: ctx, resps: s.resps})
+		close(s.resps)
+	}()
+	return s, nil
+}
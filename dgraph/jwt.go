@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Requests can be authenticated with JSON Web Tokens, signed by an identity provider, which are
+// given as bearer tokens in the Authorization header over HTTP, and in the authorization metadata
+// over gRPC. Tokens are verified with the public keys of --jwt_keys, for RS* and ES* signatures,
+// or the secret of --jwt_secret, for HS* ones. On servers with access control lists, the user of
+// a token is its --jwt_user_claim, and it has the groups of its --jwt_groups_claim on top of those
+// of the user in the lists, if any. Tokens give all permissions on servers without them.
+
+var (
+	ErrNoToken      = errors.New("Requests must be sent with a bearer token on this server")
+	ErrInvalidToken = errors.New("Invalid token")
+)
+
+// jwtLeeway is the clock skew allowed when checking the times of tokens.
+const jwtLeeway = time.Minute
+
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+var jwtKeys struct {
+	sync.RWMutex
+	secret []byte
+	public []crypto.PublicKey
+}
+
+// JWTEnabled returns whether the server has --jwt_keys or --jwt_secret.
+func JWTEnabled() bool {
+	return Config.JWTKeys != "" || Config.JWTSecret != ""
+}
+
+// LoadJWTKeys reads the keys tokens are verified with, from the files of --jwt_keys and
+// --jwt_secret.
+func LoadJWTKeys() error {
+	var secret []byte
+	var public []crypto.PublicKey
+	if Config.JWTSecret != "" {
+		b, err := ioutil.ReadFile(Config.JWTSecret)
+		if err != nil {
+			return err
+		}
+		if secret = bytes.TrimSpace(b); len(secret) == 0 {
+			return x.Errorf("Empty JWT secret in %v", Config.JWTSecret)
+		}
+	}
+	if Config.JWTKeys != "" {
+		b, err := ioutil.ReadFile(Config.JWTKeys)
+		if err != nil {
+			return err
+		}
+		for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return x.Wrapf(err, "While reading JWT keys from %v", Config.JWTKeys)
+			}
+			public = append(public, key)
+		}
+		if len(public) == 0 {
+			return x.Errorf("No PEM encoded public keys in %v", Config.JWTKeys)
+		}
+	}
+	jwtKeys.Lock()
+	jwtKeys.secret, jwtKeys.public = secret, public
+	jwtKeys.Unlock()
+	return nil
+}
+
+// verifyJWT verifies the signature sig of signed, made with the algorithm alg, with secret for
+// HS* algorithms and any of the keys of public for the others.
+func verifyJWT(alg string, signed, sig, secret []byte, public []crypto.PublicKey) bool {
+	if len(alg) != 5 {
+		return false
+	}
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		return false
+	}
+	if alg[:2] == "HS" {
+		if len(secret) == 0 {
+			return false
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+	for _, key := range public {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			if alg[:2] == "RS" && rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil {
+				return true
+			}
+		case *ecdsa.PublicKey:
+			// The signature is r and s, each the size of the curve.
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if alg[:2] != "ES" || len(sig) != 2*size {
+				continue
+			}
+			r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(key, digest, r, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func verifySignature(alg, kid string, signed, sig []byte) bool {
+	jwtKeys.RLock()
+	defer jwtKeys.RUnlock()
+	return verifyJWT(alg, signed, sig, jwtKeys.secret, jwtKeys.public)
+}
+
+// claimStrings returns the strings of the claim v, which is either a string or a list of them.
+func claimStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// parseJWT checks the signature of token with verify, given the algorithm and key id of its
+// header, and its times, and returns its claims.
+func parseJWT(token string, now time.Time,
+	verify func(alg, kid string, signed, sig []byte) bool) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	var claims map[string]interface{}
+	for i, v := range []interface{}{&header, &claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !verify(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), sig) {
+		return nil, x.Wrapf(ErrInvalidToken, "Signature can't be verified")
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return nil, x.Wrapf(ErrInvalidToken, "Token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-jwtLeeway)) {
+		return nil, x.Wrapf(ErrInvalidToken, "Token not valid yet")
+	}
+	return claims, nil
+}
+
+// ParseToken verifies the signature, times, issuer and audience of token, and returns its claims.
+func ParseToken(token string, now time.Time) (map[string]interface{}, error) {
+	claims, err := parseJWT(token, now, verifySignature)
+	if err != nil {
+		return nil, err
+	}
+	if Config.JWTIssuers != "" {
+		iss, _ := claims["iss"].(string)
+		found := false
+		for _, allowed := range strings.Split(Config.JWTIssuers, ",") {
+			found = found || iss == strings.TrimSpace(allowed)
+		}
+		if !found {
+			return nil, x.Wrapf(ErrInvalidToken, "Issuer %q not allowed", iss)
+		}
+	}
+	if Config.JWTAudience != "" {
+		found := false
+		for _, aud := range claimStrings(claims["aud"]) {
+			found = found || aud == Config.JWTAudience
+		}
+		if !found {
+			return nil, x.Wrapf(ErrInvalidToken, "Token not meant for %q", Config.JWTAudience)
+		}
+	}
+	return claims, nil
+}
+
+// AuthorizeToken returns the identity to run a request with, given the token it was sent with.
+func AuthorizeToken(token string) (Identity, error) {
+	claims, err := ParseToken(token, time.Now())
+	if err != nil {
+		return Identity{}, err
+	}
+	user, _ := claims[Config.JWTUserClaim].(string)
+	if !ACLEnabled() {
+		return Identity{User: user}, nil
+	}
+	if user == "" {
+		return Identity{}, x.Wrapf(ErrInvalidToken, "No %q claim", Config.JWTUserClaim)
+	}
+	var groups []string
+	if Config.JWTGroupsClaim != "" {
+		groups = claimStrings(claims[Config.JWTGroupsClaim])
+	}
+	return userIdentity(user, groups), nil
+}
+
+// Authorize returns the identity to run a request with, given the Authorization it was sent with,
+// or else its user and password. Bearer tokens are used on servers with JWT keys, and users and
+// passwords on those with access control lists.
+func Authorize(authorization, user, password string) (Identity, error) {
+	if JWTEnabled() {
+		if token := strings.TrimPrefix(authorization, "Bearer "); token != authorization {
+			return AuthorizeToken(token)
+		}
+		if !ACLEnabled() || user == "" {
+			return Identity{User: user}, ErrNoToken
+		}
+	}
+	return AuthorizeUser(user, password)
+}
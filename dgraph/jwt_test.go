@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/query"
+)
+
+// signToken returns a token of claims, signed with HS256 by secret, or ES256 by key.
+func signToken(t *testing.T, claims map[string]interface{}, secret []byte,
+	key *ecdsa.PrivateKey) string {
+	alg := "HS256"
+	if key != nil {
+		alg = "ES256"
+	}
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	if key == nil {
+		mac := hmac.New(crypto.SHA256.New, secret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	} else {
+		h := crypto.SHA256.New()
+		h.Write([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+		require.NoError(t, err)
+		// r and s are padded to the size of the curve.
+		sig = make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestParseToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+
+	secret := []byte("s3cret")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	Config.JWTSecret = filepath.Join(dir, "secret")
+	Config.JWTKeys = filepath.Join(dir, "keys.pem")
+	require.NoError(t, ioutil.WriteFile(Config.JWTSecret, append(secret, '\n'), 0600))
+	require.NoError(t, ioutil.WriteFile(Config.JWTKeys,
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	require.NoError(t, LoadJWTKeys())
+	Config.JWTIssuers = "https://id.example.com, https://other.example.com"
+	Config.JWTAudience = "dgraph"
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"sub": "alice",
+		"iss": "https://id.example.com",
+		"aud": []string{"dgraph", "web"},
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for _, k := range []*ecdsa.PrivateKey{nil, key} {
+		parsed, err := ParseToken(signToken(t, claims, secret, k), now)
+		require.NoError(t, err)
+		require.Equal(t, "alice", parsed["sub"])
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = ParseToken(signToken(t, claims, []byte("wrong"), nil), now)
+	require.Error(t, err)
+	_, err = ParseToken(signToken(t, claims, nil, other), now)
+	require.Error(t, err)
+	_, err = ParseToken("a.b", now)
+	require.Error(t, err)
+
+	_, err = ParseToken(signToken(t, claims, secret, nil), now.Add(2*time.Hour))
+	require.Error(t, err)
+	claims["iss"] = "https://evil.example.com"
+	_, err = ParseToken(signToken(t, claims, secret, nil), now)
+	require.Error(t, err)
+	claims["iss"], claims["aud"] = "https://other.example.com", "web"
+	_, err = ParseToken(signToken(t, claims, secret, nil), now)
+	require.Error(t, err)
+}
+
+func TestAuthorizeToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+
+	Config.JWTSecret = filepath.Join(dir, "secret")
+	require.NoError(t, ioutil.WriteFile(Config.JWTSecret, []byte("s3cret"), 0600))
+	require.NoError(t, LoadJWTKeys())
+	Config.JWTUserClaim, Config.JWTGroupsClaim = "preferred_username", "roles"
+	Config.ACL = filepath.Join(dir, "acl.json")
+	require.NoError(t, SetGroup("readers", map[string]string{"*": "r"}))
+	require.NoError(t, SetGroup("writers", map[string]string{"name": "w"}))
+	require.NoError(t, SetUser("alice", "pw", []string{"readers"}))
+
+	_, err = Authorize("", "", "")
+	require.Equal(t, ErrNoToken, err)
+	_, err = Authorize("Bearer nope", "", "")
+	require.Error(t, err)
+
+	// The groups of the token add up to those of the user.
+	token := signToken(t, map[string]interface{}{
+		"preferred_username": "alice",
+		"roles":              []string{"writers"},
+	}, []byte("s3cret"), nil)
+	id, err := Authorize("Bearer "+token, "", "")
+	require.NoError(t, err)
+	require.Equal(t, "alice", id.User)
+	require.True(t, id.Access("age", query.PermRead))
+	require.True(t, id.Access("name", query.PermWrite))
+	require.False(t, id.Access("age", query.PermWrite))
+
+	// Users and passwords are still accepted with access control lists.
+	id, err = Authorize("", "alice", "pw")
+	require.NoError(t, err)
+	require.False(t, id.Access("name", query.PermWrite))
+}
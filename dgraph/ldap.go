@@ -0,0 +1,375 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A minimal LDAP client, which checks the passwords of users by binding as them, and reads the
+// groups they're in from the memberOf attribute of their entries. LDAP messages are encoded with
+// BER, of which only the definite length forms used by LDAP are handled.
+
+const (
+	berSequence    = 0x30
+	berSet         = 0x31
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berBoolean     = 0x01
+
+	ldapBindRequest    = 0x60
+	ldapBindResponse   = 0x61
+	ldapUnbindRequest  = 0x42
+	ldapSearchRequest  = 0x63
+	ldapSearchEntry    = 0x64
+	ldapSearchDone     = 0x65
+	ldapSimplePassword = 0x80
+	ldapFilterPresent  = 0x87
+
+	// ldapInvalidCredentials is the result code of binds with a wrong user or password.
+	ldapInvalidCredentials = 49
+	ldapTimeout            = 10 * time.Second
+	ldapMaxMessage         = 1 << 20
+)
+
+type berValue struct {
+	id      byte
+	content []byte
+}
+
+// berEncode returns the value of type id with the concatenation of content.
+func berEncode(id byte, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	var buf bytes.Buffer
+	buf.WriteByte(id)
+	if n := len(body); n < 0x80 {
+		buf.WriteByte(byte(n))
+	} else {
+		var l []byte
+		for ; n > 0; n >>= 8 {
+			l = append([]byte{byte(n)}, l...)
+		}
+		buf.WriteByte(0x80 | byte(len(l)))
+		buf.Write(l)
+	}
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+func berInt(id byte, n int) []byte {
+	b := []byte{byte(n)}
+	for n >>= 8; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return berEncode(id, b)
+}
+
+func berString(id byte, s string) []byte {
+	return berEncode(id, []byte(s))
+}
+
+// berParse returns the first value of b, and what follows it.
+func berParse(b []byte) (berValue, []byte, error) {
+	if len(b) < 2 {
+		return berValue{}, nil, x.Errorf("Truncated LDAP message")
+	}
+	v := berValue{id: b[0]}
+	n, b := int(b[1]), b[2:]
+	if n&0x80 != 0 {
+		k := n & 0x7f
+		if k == 0 || k > 4 || len(b) < k {
+			return berValue{}, nil, x.Errorf("Invalid length in LDAP message")
+		}
+		n = 0
+		for _, c := range b[:k] {
+			n = n<<8 | int(c)
+		}
+		b = b[k:]
+	}
+	if n < 0 || len(b) < n {
+		return berValue{}, nil, x.Errorf("Truncated LDAP message")
+	}
+	v.content = b[:n]
+	return v, b[n:], nil
+}
+
+// berParseAll returns the values of b, checking that the first ones are of the types of ids.
+func berParseAll(b []byte, ids ...byte) ([]berValue, error) {
+	var vals []berValue
+	for len(b) > 0 {
+		v, rest, err := berParse(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(vals) < len(ids) && v.id != ids[len(vals)] {
+			return nil, x.Errorf("Unexpected type 0x%x in LDAP message", v.id)
+		}
+		vals = append(vals, v)
+		b = rest
+	}
+	if len(vals) < len(ids) {
+		return nil, x.Errorf("Truncated LDAP message")
+	}
+	return vals, nil
+}
+
+func (v berValue) int() int {
+	n := 0
+	for _, c := range v.content {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+// ldapEscape escapes the special characters of the value s of a DN.
+func ldapEscape(s string) string {
+	var buf bytes.Buffer
+	for i, c := range []byte(s) {
+		switch {
+		case strings.IndexByte(",+\"\\<>;=", c) >= 0,
+			(c == ' ' || c == '#') && i == 0, c == ' ' && i == len(s)-1:
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < 0x20:
+			fmt.Fprintf(&buf, "\\%02X", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
+
+type ldapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	id   int
+}
+
+// dialLDAP connects to the server of the ldap:// or ldaps:// URL addr.
+func dialLDAP(addr string) (*ldapConn, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	d := &net.Dialer{Timeout: ldapTimeout}
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		conn, err = tls.DialWithDialer(d, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = d.Dial("tcp", host)
+	default:
+		return nil, x.Errorf("Invalid LDAP URL: %q. Expected ldap:// or ldaps://", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(ldapTimeout))
+	return &ldapConn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (c *ldapConn) send(op []byte) error {
+	c.id++
+	_, err := c.conn.Write(berEncode(berSequence, berInt(berInteger, c.id), op))
+	return err
+}
+
+// receive returns the operation of the next message from the server.
+func (c *ldapConn) receive() (berValue, error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, head); err != nil {
+		return berValue{}, err
+	}
+	if head[0] != berSequence {
+		return berValue{}, x.Errorf("Invalid LDAP message")
+	}
+	msg := head
+	n := int(head[1])
+	if n&0x80 != 0 {
+		l := make([]byte, n&0x7f)
+		if len(l) == 0 || len(l) > 4 {
+			return berValue{}, x.Errorf("Invalid length in LDAP message")
+		}
+		if _, err := io.ReadFull(c.r, l); err != nil {
+			return berValue{}, err
+		}
+		n = 0
+		for _, b := range l {
+			n = n<<8 | int(b)
+		}
+		msg = append(msg, l...)
+	}
+	if n > ldapMaxMessage {
+		return berValue{}, x.Errorf("LDAP message too large: %d bytes", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return berValue{}, err
+	}
+	v, _, err := berParse(append(msg, body...))
+	if err != nil {
+		return berValue{}, err
+	}
+	vals, err := berParseAll(v.content, berInteger)
+	if err != nil {
+		return berValue{}, err
+	}
+	if len(vals) < 2 {
+		return berValue{}, x.Errorf("LDAP message without an operation")
+	}
+	return vals[1], nil
+}
+
+// ldapResult returns the code and the message of the LDAP result op.
+func ldapResult(op berValue) (int, string, error) {
+	vals, err := berParseAll(op.content, berEnumerated, berOctetString, berOctetString)
+	if err != nil {
+		return 0, "", err
+	}
+	return vals[0].int(), string(vals[2].content), nil
+}
+
+// bind authenticates the connection as the user dn.
+func (c *ldapConn) bind(dn, password string) error {
+	err := c.send(berEncode(ldapBindRequest, berInt(berInteger, 3), berString(berOctetString, dn),
+		berString(ldapSimplePassword, password)))
+	if err != nil {
+		return err
+	}
+	op, err := c.receive()
+	if err != nil {
+		return err
+	}
+	if op.id != ldapBindResponse {
+		return x.Errorf("Unexpected LDAP response 0x%x to bind", op.id)
+	}
+	code, msg, err := ldapResult(op)
+	switch {
+	case err != nil:
+		return err
+	case code == ldapInvalidCredentials:
+		return ErrAdminLogin
+	case code != 0:
+		return x.Errorf("LDAP bind failed with code %d: %s", code, msg)
+	}
+	return nil
+}
+
+// values returns the values of the attribute attr of the entry dn.
+func (c *ldapConn) values(dn, attr string) ([]string, error) {
+	err := c.send(berEncode(ldapSearchRequest,
+		berString(berOctetString, dn),
+		berInt(berEnumerated, 0), // Base object.
+		berInt(berEnumerated, 0), // Never dereference aliases.
+		berInt(berInteger, 0),
+		berInt(berInteger, int(ldapTimeout/time.Second)),
+		berEncode(berBoolean, []byte{0}),
+		berString(ldapFilterPresent, "objectClass"),
+		berEncode(berSequence, berString(berOctetString, attr))))
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		op, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch op.id {
+		case ldapSearchEntry:
+			vals, err := berParseAll(op.content, berOctetString, berSequence)
+			if err != nil {
+				return nil, err
+			}
+			attrs, err := berParseAll(vals[1].content)
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range attrs {
+				av, err := berParseAll(a.content, berOctetString, berSet)
+				if err != nil {
+					return nil, err
+				}
+				if !strings.EqualFold(string(av[0].content), attr) {
+					continue
+				}
+				vs, err := berParseAll(av[1].content)
+				if err != nil {
+					return nil, err
+				}
+				for _, v := range vs {
+					values = append(values, string(v.content))
+				}
+			}
+		case ldapSearchDone:
+			code, msg, err := ldapResult(op)
+			if err != nil {
+				return nil, err
+			}
+			if code != 0 {
+				return nil, x.Errorf("LDAP search failed with code %d: %s", code, msg)
+			}
+			return values, nil
+		}
+	}
+}
+
+func (c *ldapConn) close() {
+	c.send(berEncode(ldapUnbindRequest))
+	c.conn.Close()
+}
+
+// ldapLogin checks the password of user with the server of --admin_ldap, and returns the groups
+// the user is in.
+func ldapLogin(user, password string) ([]string, error) {
+	// Binds without a password are anonymous, and always succeed.
+	if user == "" || password == "" {
+		return nil, ErrAdminLogin
+	}
+	c, err := dialLDAP(Config.AdminLDAP)
+	if err != nil {
+		return nil, x.Wrapf(err, "While connecting to LDAP server")
+	}
+	defer c.close()
+	dn := strings.Replace(Config.AdminLDAPUserDN, "%s", ldapEscape(user), -1)
+	if err := c.bind(dn, password); err != nil {
+		return nil, err
+	}
+	return c.values(dn, "memberOf")
+}
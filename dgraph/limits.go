@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Clients are limited at the front door of the server. Only the addresses of --ip_allowlist, if
+// it's set, can connect to the HTTP and gRPC ports. Each client can send --rate_limit requests a
+// second, in bursts of up to --rate_burst, and run --max_client_queries queries at once. Queries
+// count for the user they're run for, and other requests for the address they're sent from.
+// Requests refused get status 429 over HTTP, and code ResourceExhausted over gRPC, and are counted
+// in the dgraph_throttled_requests_total metric.
+
+// ThrottleError is returned for the requests of a client past its rate or concurrency limits.
+type ThrottleError struct {
+	Client string
+	// Reason is rate or concurrency.
+	Reason string
+	// RetryAfter is how long until the client can send a request again.
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	if e.Reason == "concurrency" {
+		return fmt.Sprintf("Client %s is running more than %d queries at once", e.Client,
+			Config.MaxClientQueries)
+	}
+	return fmt.Sprintf("Client %s is sending more than %g requests a second", e.Client,
+		Config.RateLimit)
+}
+
+// maxIdleClients is the number of clients tracked past which those idle are dropped.
+const maxIdleClients = 4096
+
+// allowlist has the networks of --ip_allowlist.
+var allowlist []*net.IPNet
+
+type clientLimit struct {
+	tokens  float64
+	last    time.Time
+	running int
+}
+
+var clients = struct {
+	sync.Mutex
+	m map[string]*clientLimit
+}{m: make(map[string]*clientLimit)}
+
+// ParseAllowlist parses a comma separated list of IP addresses and CIDR networks, like
+// "10.0.0.0/8,192.168.1.10".
+func ParseAllowlist(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, x.Errorf("Invalid IP address in allowlist: %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, x.Errorf("Invalid network in allowlist: %q", entry)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func remoteHost(remote string) string {
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+// AllowedIP returns whether the client at the address remote, with or without a port, is allowed
+// by --ip_allowlist. All of them are if it isn't set.
+func AllowedIP(remote string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	ip := net.ParseIP(remoteHost(remote))
+	if ip == nil {
+		return false
+	}
+	for _, n := range allowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientKey returns the client which the limits of a request sent by user from the address remote
+// count for: the user if there's one, or else the host of the address.
+func ClientKey(user, remote string) string {
+	if user != "" {
+		return "user:" + user
+	}
+	return "ip:" + remoteHost(remote)
+}
+
+func rateBurst() float64 {
+	if Config.RateBurst > 0 {
+		return float64(Config.RateBurst)
+	}
+	return math.Max(1, math.Ceil(Config.RateLimit))
+}
+
+// dropIdleClients drops the clients which aren't running queries and are back to a full burst.
+// It's called with clients locked.
+func dropIdleClients(now time.Time) {
+	burst := rateBurst()
+	for key, c := range clients.m {
+		if c.running == 0 && c.tokens+now.Sub(c.last).Seconds()*Config.RateLimit >= burst {
+			delete(clients.m, key)
+		}
+	}
+}
+
+// throttle takes a request of client from its rate limit, and counts it in the queries it runs if
+// query is set. It's called with clients locked.
+func throttle(client string, query bool, now time.Time) error {
+	c, ok := clients.m[client]
+	if !ok {
+		if len(clients.m) >= maxIdleClients {
+			dropIdleClients(now)
+		}
+		c = &clientLimit{tokens: rateBurst(), last: now}
+		clients.m[client] = c
+	}
+	if query && Config.MaxClientQueries > 0 && c.running >= Config.MaxClientQueries {
+		x.ThrottledRequests.Add("concurrency", 1)
+		return &ThrottleError{Client: client, Reason: "concurrency", RetryAfter: time.Second}
+	}
+	if Config.RateLimit > 0 {
+		c.tokens = math.Min(rateBurst(), c.tokens+now.Sub(c.last).Seconds()*Config.RateLimit)
+		c.last = now
+		if c.tokens < 1 {
+			x.ThrottledRequests.Add("rate", 1)
+			wait := time.Duration((1 - c.tokens) / Config.RateLimit * float64(time.Second))
+			return &ThrottleError{Client: client, Reason: "rate", RetryAfter: wait}
+		}
+		c.tokens--
+	}
+	if query {
+		c.running++
+	}
+	return nil
+}
+
+// ThrottleRequest takes a request of client from its rate limit, or returns a *ThrottleError if
+// it's past it.
+func ThrottleRequest(client string) error {
+	if Config.RateLimit <= 0 {
+		return nil
+	}
+	clients.Lock()
+	defer clients.Unlock()
+	return throttle(client, false, time.Now())
+}
+
+// StartQuery takes a query of client from its rate limit and the queries it can run at once, or
+// returns a *ThrottleError if it's past them. done must be called once the query has run.
+func StartQuery(client string) (done func(), err error) {
+	if Config.RateLimit <= 0 && Config.MaxClientQueries <= 0 {
+		return func() {}, nil
+	}
+	clients.Lock()
+	defer clients.Unlock()
+	if err := throttle(client, true, time.Now()); err != nil {
+		return nil, err
+	}
+	return func() {
+		clients.Lock()
+		if c, ok := clients.m[client]; ok {
+			c.running--
+		}
+		clients.Unlock()
+	}, nil
+}
+
+// WriteThrottled refuses an HTTP request for err, returned by ThrottleRequest or StartQuery.
+func WriteThrottled(w http.ResponseWriter, err error) {
+	if te, ok := err.(*ThrottleError); ok {
+		secs := int(math.Ceil(te.RetryAfter.Seconds()))
+		if secs < 1 {
+			secs = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+	refuse(w, http.StatusTooManyRequests, x.ErrorTooManyRequests, err.Error())
+}
+
+// GRPCThrottled returns the gRPC error of err, returned by ThrottleRequest or StartQuery.
+func GRPCThrottled(err error) error {
+	return grpc.Errorf(codes.ResourceExhausted, "%v", err)
+}
+
+func allowedGRPC(ctx context.Context) error {
+	if remote := GRPCRemote(ctx); !AllowedIP(remote) {
+		x.ThrottledRequests.Add("allowlist", 1)
+		return grpc.Errorf(codes.PermissionDenied, "Requests from %s aren't allowed",
+			remoteHost(remote))
+	}
+	return nil
+}
+
+// AllowlistUnary is a gRPC interceptor refusing the unary requests of clients not allowed by
+// --ip_allowlist.
+func AllowlistUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if err := allowedGRPC(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AllowlistStream is a gRPC interceptor refusing the streams of clients not allowed by
+// --ip_allowlist.
+func AllowlistStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	if err := allowedGRPC(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
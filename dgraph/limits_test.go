@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlist(t *testing.T) {
+	defer func() { allowlist = nil }()
+	_, err := ParseAllowlist("10.0.0.0/33")
+	require.Error(t, err)
+	_, err = ParseAllowlist("10.0.0")
+	require.Error(t, err)
+
+	require.True(t, AllowedIP("8.8.8.8:1234"))
+	allowlist, err = ParseAllowlist(" 10.0.0.0/8, 192.168.1.10,::1")
+	require.NoError(t, err)
+	require.True(t, AllowedIP("10.1.2.3:1234"))
+	require.True(t, AllowedIP("192.168.1.10"))
+	require.True(t, AllowedIP("[::1]:1234"))
+	require.False(t, AllowedIP("192.168.1.11:1234"))
+	require.False(t, AllowedIP("127.0.0.1:1234"))
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/health", nil)
+	r.RemoteAddr = "192.168.1.11:1234"
+	WrapHTTP("/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})).ServeHTTP(rr, r)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestThrottle(t *testing.T) {
+	defer func(c Options) { Config = c }(Config)
+	defer func() { clients.m = make(map[string]*clientLimit) }()
+	Config.RateLimit, Config.RateBurst = 2, 3
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, throttle("ip:10.0.0.1", false, now))
+	}
+	err := throttle("ip:10.0.0.1", false, now)
+	require.Error(t, err)
+	te := err.(*ThrottleError)
+	require.Equal(t, "rate", te.Reason)
+	require.Equal(t, 500*time.Millisecond, te.RetryAfter)
+	// Other clients have their own limits, and tokens come back at the rate.
+	require.NoError(t, throttle("user:alice", false, now))
+	require.NoError(t, throttle("ip:10.0.0.1", false, now.Add(500*time.Millisecond)))
+	require.Error(t, throttle("ip:10.0.0.1", false, now.Add(500*time.Millisecond)))
+
+	Config.RateLimit, Config.MaxClientQueries = 0, 2
+	done1, err := StartQuery("user:bob")
+	require.NoError(t, err)
+	done2, err := StartQuery("user:bob")
+	require.NoError(t, err)
+	_, err = StartQuery("user:bob")
+	require.Equal(t, "concurrency", err.(*ThrottleError).Reason)
+	done1()
+	done3, err := StartQuery("user:bob")
+	require.NoError(t, err)
+	done2()
+	done3()
+
+	rr := httptest.NewRecorder()
+	WriteThrottled(rr, &ThrottleError{Client: "ip:10.0.0.1", Reason: "rate",
+		RetryAfter: 1500 * time.Millisecond})
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "2", rr.Header().Get("Retry-After"))
+	require.Contains(t, rr.Body.String(), "ErrorTooManyRequests")
+}
+
+func TestClientKey(t *testing.T) {
+	require.Equal(t, "user:alice", ClientKey("alice", "10.0.0.1:1234"))
+	require.Equal(t, "ip:10.0.0.1", ClientKey("", "10.0.0.1:1234"))
+}
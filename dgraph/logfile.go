@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// logFile is a file which is only appended to, like the audit log. It's rotated once past
+// maxSize bytes, if maxSize isn't 0, keeping the maxFiles last ones. It isn't safe for concurrent
+// use.
+type logFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	f    *os.File
+	size int64
+}
+
+func openLogFile(path string, maxSize int64, maxFiles int) (*logFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &logFile{path: path, maxSize: maxSize, maxFiles: maxFiles, f: f, size: fi.Size()}, nil
+}
+
+// rotate moves the file aside, under the time it was rotated at, and removes the oldest ones past
+// maxFiles.
+func (l *logFile) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+	rotated := l.path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(l.path, rotated); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	l.f, l.size = f, 0
+
+	old, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return err
+	}
+	// The names of rotated files sort by the time they were rotated at.
+	sort.Strings(old)
+	for len(old) > l.maxFiles {
+		if err := os.Remove(old[0]); err != nil {
+			return err
+		}
+		old = old[1:]
+	}
+	return nil
+}
+
+// write appends the entry b, rotating the file first if b would take it past maxSize. Entries are
+// still written to the file if it can't be rotated.
+func (l *logFile) write(b []byte) error {
+	var rerr error
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			rerr = x.Wrapf(err, "While rotating %v", l.path)
+		}
+	}
+	if l.f == nil {
+		return rerr
+	}
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return rerr
+}
+
+func (l *logFile) close() error {
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The HTTP routes of a server are wrapped by WrapHTTP, which applies the policies of the config:
+// the IP allowlist and rate limits of clients, the origins allowed for CORS, the tenant header and
+// the body limits of routes, and then the auth funcs and middleware added by programs embedding
+// Dgraph. It gives each request an id, the one of its X-Request-Id header if valid, which is sent
+// back in the same header and carried by the context of the request.
+
+// HTTPAuthFunc authorizes an HTTP request, or returns why it's refused.
+type HTTPAuthFunc func(r *http.Request) error
+
+var httpHooks struct {
+	sync.RWMutex
+	auth       []HTTPAuthFunc
+	middleware []func(http.Handler) http.Handler
+}
+
+// bodyLimits has the largest request bodies of routes, in bytes, from --body_limits.
+var bodyLimits map[string]int64
+
+// ErrBodyTooLarge is returned by reads of a request body past the limit of its route, or past
+// --conn_body_limit with the bodies read before it on its connection.
+var ErrBodyTooLarge = errors.New("Request body is too large")
+
+// AddHTTPAuth adds f to the funcs authorizing HTTP requests. A request is served only if all of
+// them return nil. OPTIONS requests, sent by browsers before cross origin requests, aren't
+// authorized.
+func AddHTTPAuth(f HTTPAuthFunc) {
+	httpHooks.Lock()
+	httpHooks.auth = append(httpHooks.auth, f)
+	httpHooks.Unlock()
+}
+
+// UseHTTPMiddleware adds m to the middleware wrapping the HTTP routes, inside that added
+// before it.
+func UseHTTPMiddleware(m func(http.Handler) http.Handler) {
+	httpHooks.Lock()
+	httpHooks.middleware = append(httpHooks.middleware, m)
+	httpHooks.Unlock()
+}
+
+// Tenant returns the tenant selected by the --tenant_header of the request of ctx, or "".
+func Tenant(ctx context.Context) string {
+	t, _ := ctx.Value("tenant").(string)
+	return t
+}
+
+// ParseBodyLimits parses a comma separated list of route:bytes pairs like
+// "/query:1048576,/node/:65536".
+func ParseBodyLimits(s string) (map[string]int64, error) {
+	m := make(map[string]int64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			return nil, x.Errorf("Invalid body limit: %q. Expected route:bytes", pair)
+		}
+		route := strings.TrimSpace(pair[:idx])
+		n, err := strconv.ParseInt(strings.TrimSpace(pair[idx+1:]), 10, 64)
+		if err != nil || n <= 0 {
+			return nil, x.Errorf("Invalid body limit for route %s: %q", route, pair[idx+1:])
+		}
+		m[route] = n
+	}
+	return m, nil
+}
+
+// ConnContext adds the count of the request body bytes read on c to ctx, for the Server of the
+// HTTP routes to enforce --conn_body_limit.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, "conn_body_bytes", new(int64))
+}
+
+// limitedBody is a request body failing with ErrBodyTooLarge once more than limit bytes of it
+// are read, or more than Config.ConnBodyLimit bytes of bodies on its connection, counted in conn.
+// The connection is then closed once the response is sent, as the rest of the body isn't read.
+type limitedBody struct {
+	io.ReadCloser
+	w        http.ResponseWriter
+	read     int64
+	limit    int64
+	conn     *int64
+	tooLarge bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.tooLarge {
+		return 0, ErrBodyTooLarge
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	b.tooLarge = b.limit > 0 && b.read > b.limit
+	if b.conn != nil && atomic.AddInt64(b.conn, int64(n)) > Config.ConnBodyLimit {
+		b.tooLarge = true
+	}
+	if b.tooLarge {
+		b.w.Header().Set("Connection", "close")
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func corsOrigin(origin string) string {
+	for _, o := range strings.Split(Config.CorsOrigins, ",") {
+		o = strings.TrimSpace(o)
+		if o == "*" {
+			return "*"
+		}
+		if origin != "" && o == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+// OriginAllowed returns whether origin is allowed by --cors_origins.
+func OriginAllowed(origin string) bool {
+	return corsOrigin(origin) != ""
+}
+
+func validTenant(t string) bool {
+	for _, r := range t {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '_' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return t != ""
+}
+
+func refuse(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	x.SetStatus(w, code, msg)
+}
+
+// WrapHTTP wraps the handler h of route with the HTTP policies and hooks.
+func WrapHTTP(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(x.RequestIdHeader)
+		if !x.ValidRequestId(id) {
+			id = x.NewRequestId()
+		}
+		w.Header().Set(x.RequestIdHeader, id)
+		r = r.WithContext(x.WithRequestId(r.Context(), id))
+
+		if !AllowedIP(r.RemoteAddr) {
+			x.ThrottledRequests.Add("allowlist", 1)
+			refuse(w, http.StatusForbidden, x.ErrorUnauthorized,
+				fmt.Sprintf("Requests from %s aren't allowed", remoteHost(r.RemoteAddr)))
+			return
+		}
+		// Queries are limited once their user is known, and health checks aren't.
+		if route != "/query" && route != "/health" && route != "/ready" {
+			if err := ThrottleRequest(ClientKey("", r.RemoteAddr)); err != nil {
+				WriteThrottled(w, err)
+				return
+			}
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if origin := corsOrigin(r.Header.Get("Origin")); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		body := &limitedBody{ReadCloser: r.Body, w: w, limit: bodyLimits[route]}
+		if body.limit > 0 && r.ContentLength > body.limit {
+			refuse(w, http.StatusRequestEntityTooLarge, x.ErrorInvalidRequest,
+				fmt.Sprintf("Request body is larger than %d bytes", body.limit))
+			return
+		}
+		if conn, ok := r.Context().Value("conn_body_bytes").(*int64); ok &&
+			Config.ConnBodyLimit > 0 {
+			if r.ContentLength > Config.ConnBodyLimit-atomic.LoadInt64(conn) {
+				w.Header().Set("Connection", "close")
+				refuse(w, http.StatusRequestEntityTooLarge, x.ErrorInvalidRequest,
+					fmt.Sprintf("Request bodies on this connection are past %d bytes",
+						Config.ConnBodyLimit))
+				return
+			}
+			body.conn = conn
+		}
+		if r.Body != nil {
+			r.Body = body
+		}
+
+		if Config.TenantHeader != "" {
+			if t := r.Header.Get(Config.TenantHeader); t != "" {
+				if !validTenant(t) {
+					refuse(w, http.StatusBadRequest, x.ErrorInvalidRequest,
+						fmt.Sprintf("Invalid tenant: %q", t))
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), "tenant", t))
+			}
+		}
+
+		httpHooks.RLock()
+		auth, middleware := httpHooks.auth, httpHooks.middleware
+		httpHooks.RUnlock()
+		if r.Method != http.MethodOptions {
+			for _, f := range auth {
+				if err := f(r); err != nil {
+					refuse(w, http.StatusUnauthorized, x.ErrorUnauthorized, err.Error())
+					return
+				}
+			}
+		}
+		next := h
+		if strings.HasPrefix(route, "/admin/") {
+			next = auditHTTP(route, h)
+		}
+		for i := len(middleware) - 1; i >= 0; i-- {
+			next = middleware[i](next)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
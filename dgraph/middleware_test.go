@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestParseBodyLimits(t *testing.T) {
+	limits, err := ParseBodyLimits(" /query:1024, /node/:64")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"/query": 1024, "/node/": 64}, limits)
+	_, err = ParseBodyLimits("/query")
+	require.Error(t, err)
+	_, err = ParseBodyLimits("/query:-1")
+	require.Error(t, err)
+}
+
+func TestWrapHTTP(t *testing.T) {
+	defer func(c Options) { Config = c }(Config)
+	defer func() { bodyLimits = nil }()
+	Config.CorsOrigins = "https://a.example, https://b.example"
+	Config.TenantHeader = "X-Tenant"
+	bodyLimits = map[string]int64{"/query": 8}
+
+	h := WrapHTTP("/query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.Write([]byte(Tenant(r.Context()) + ":" + string(b)))
+	}))
+	serve := func(body string, headers ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/query", bytes.NewBufferString(body))
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		return rr
+	}
+
+	rr := serve("{}", "Origin", "https://b.example", "X-Tenant", "acme")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "https://b.example", rr.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "acme:{}", rr.Body.String())
+	rr = serve("{}", "Origin", "https://c.example")
+	require.Equal(t, "", rr.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, ":{}", rr.Body.String())
+	require.True(t, OriginAllowed("https://a.example"))
+	require.False(t, OriginAllowed("https://c.example"))
+	require.Equal(t, http.StatusBadRequest, serve("{}", "X-Tenant", "a/b").Code)
+	require.Equal(t, "q-42", serve("{}", "X-Request-Id", "q-42").Header().Get("X-Request-Id"))
+	id := serve("{}", "X-Request-Id", "not valid").Header().Get("X-Request-Id")
+	require.True(t, x.ValidRequestId(id), id)
+	require.Equal(t, http.StatusRequestEntityTooLarge, serve("0123456789").Code)
+
+	AddHTTPAuth(func(r *http.Request) error {
+		if Tenant(r.Context()) != "acme" {
+			return errors.New("Unknown tenant")
+		}
+		return nil
+	})
+	defer func() { httpHooks.auth = nil }()
+	UseHTTPMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Wrapped", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	defer func() { httpHooks.middleware = nil }()
+	require.Equal(t, http.StatusUnauthorized, serve("{}").Code)
+	rr = serve("{}", "X-Tenant", "acme")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "true", rr.Header().Get("X-Wrapped"))
+}
+
+func TestConnBodyLimit(t *testing.T) {
+	defer func(c Options) { Config = c }(Config)
+	Config.ConnBodyLimit = 10
+
+	h := WrapHTTP("/query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err == ErrBodyTooLarge {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		}
+	}))
+	// The requests are sent over the same connection.
+	ctx := ConnContext(context.Background(), nil)
+	serve := func(body string, chunked bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/query", bytes.NewBufferString(body))
+		if chunked {
+			r.ContentLength = -1
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r.WithContext(ctx))
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, serve("0123456", false).Code)
+	// Bodies of unknown length are refused once they're read past the limit.
+	rr := serve("0123456", true)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Equal(t, "close", rr.Header().Get("Connection"))
+	// The connection has no room left, so bodies of known length are refused before they're read.
+	require.Equal(t, http.StatusRequestEntityTooLarge, serve("0", false).Code)
+}
@@ -0,0 +1,520 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Migrations take the schema and the data of the cluster from one version to the next. The
+// version of the cluster is that of the last migration applied to it, or 0. A migration has up
+// steps, run in order to apply it, and down steps, run in order to roll it back. The state of the
+// migrations is stored in the cluster before every step, so that a run which failed, or whose
+// server went down, can be resumed from the step it stopped at. The last step of an interrupted
+// run may have been done without being recorded, so steps should do no harm when run twice, like
+// backfills only setting predicates on the nodes without them. Runs are only kept from running
+// at the same time on the same server, so migrations should all be run on one server.
+
+// Directions of migration runs.
+const (
+	MigrateUp   = "up"
+	MigrateDown = "down"
+)
+
+// Statuses of migration runs which haven't completed.
+const (
+	MigrationRunning = "running"
+	MigrationFailed  = "failed"
+)
+
+// MigrationStep is a step of a migration. It has exactly one of its fields set.
+type MigrationStep struct {
+	// Schema is applied like a schema mutation, to add an index or change other directives.
+	Schema string `json:"schema,omitempty"`
+	// Backfill is a request with a query and a mutation, run to set a predicate on the nodes
+	// selected by the query, from its variables.
+	Backfill string `json:"backfill,omitempty"`
+	// Rename moves all the edges of a predicate to another.
+	Rename *Rename `json:"rename,omitempty"`
+}
+
+// Rename renames the predicate From to To. To gets the schema of From, unless it has one already,
+// and the edges of From, with their languages and facets. The schema of From is left.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Migration takes the cluster to Version, from the version of the migration before it.
+type Migration struct {
+	Version     int              `json:"version"`
+	Description string           `json:"description,omitempty"`
+	Up          []*MigrationStep `json:"up"`
+	// Down undoes Up. Migrations without down steps can't be rolled back.
+	Down []*MigrationStep `json:"down,omitempty"`
+}
+
+// MigrationRun is the run of a migration, up or down, which didn't complete.
+type MigrationRun struct {
+	Migration *Migration `json:"migration"`
+	Direction string     `json:"direction"`
+	// Step is the number of steps done.
+	Step    int       `json:"step"`
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+}
+
+// MigrationState is the state of the migrations of the cluster.
+type MigrationState struct {
+	Version int `json:"version"`
+	// Applied are the migrations applied, in order, kept to roll them back.
+	Applied []*Migration  `json:"applied,omitempty"`
+	Run     *MigrationRun `json:"run,omitempty"`
+}
+
+var migrating = struct {
+	sync.Mutex
+	running bool
+}{}
+
+func startMigrations() error {
+	migrating.Lock()
+	defer migrating.Unlock()
+	if migrating.running {
+		return x.Errorf("Migrations are already running on this server")
+	}
+	migrating.running = true
+	return nil
+}
+
+func stopMigrations() {
+	migrating.Lock()
+	migrating.running = false
+	migrating.Unlock()
+}
+
+func (s *MigrationStep) validate() error {
+	var n int
+	for _, set := range []bool{s.Schema != "", s.Backfill != "", s.Rename != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return x.Errorf("Steps must have one of schema, backfill or rename")
+	}
+	switch {
+	case s.Schema != "":
+		updates, typs, err := schema.ParseWithTypes(s.Schema)
+		if err != nil {
+			return x.Wrapf(err, "While parsing the schema of a step")
+		}
+		if len(updates) == 0 && len(typs) == 0 {
+			return x.Errorf("Empty schema in a step")
+		}
+	case s.Backfill != "":
+		res, err := gql.Parse(gql.Request{Str: s.Backfill, Variables: map[string]string{},
+			Http: true})
+		if err != nil {
+			return x.Wrapf(err, "While parsing a backfill")
+		}
+		if res.Mutation == nil || !res.Mutation.HasOps() {
+			return x.Errorf("Backfills must have a mutation")
+		}
+	default:
+		if s.Rename.From == "" || s.Rename.To == "" {
+			return x.Errorf("Renames must have the predicates from and to")
+		}
+		if s.Rename.From == s.Rename.To {
+			return x.Errorf("Can't rename predicate %s to itself", s.Rename.From)
+		}
+	}
+	return nil
+}
+
+func (m *Migration) validate() error {
+	if m.Version <= 0 {
+		return x.Errorf("Migration versions must be positive, got %d", m.Version)
+	}
+	if len(m.Up) == 0 {
+		return x.Errorf("Migration %d has no up steps", m.Version)
+	}
+	for _, steps := range [][]*MigrationStep{m.Up, m.Down} {
+		for i, s := range steps {
+			if s == nil {
+				return x.Errorf("Step %d of migration %d is null", i+1, m.Version)
+			}
+			if err := s.validate(); err != nil {
+				return x.Wrapf(err, "In migration %d", m.Version)
+			}
+		}
+	}
+	return nil
+}
+
+// Migrations returns the state of the migrations of the cluster.
+func Migrations(ctx context.Context) (*MigrationState, error) {
+	b, err := worker.MigrationState(ctx)
+	if err != nil {
+		return nil, x.Wrapf(err, "While reading the state of migrations")
+	}
+	st := new(MigrationState)
+	if len(b) == 0 {
+		return st, nil
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, x.Wrapf(err, "While reading the state of migrations")
+	}
+	return st, nil
+}
+
+func saveMigrations(ctx context.Context, st *MigrationState) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return x.Wrapf(worker.SetMigrationState(ctx, b), "While storing the state of migrations")
+}
+
+// ApplyMigrations applies the migrations of ms with versions after that of the cluster, in the
+// order of their versions. It returns the state of the migrations once done, or at the step which
+// failed.
+func ApplyMigrations(ctx context.Context, ms []*Migration,
+	progress *worker.Progress) (*MigrationState, error) {
+	if err := startMigrations(); err != nil {
+		return nil, err
+	}
+	defer stopMigrations()
+
+	for _, m := range ms {
+		if m == nil {
+			return nil, x.Errorf("Null migration")
+		}
+		if err := m.validate(); err != nil {
+			return nil, err
+		}
+	}
+	ms = append([]*Migration(nil), ms...)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i := 1; i < len(ms); i++ {
+		if ms[i].Version == ms[i-1].Version {
+			return nil, x.Errorf("Migration %d is given twice", ms[i].Version)
+		}
+	}
+
+	st, err := Migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st.Run != nil {
+		return st, x.Errorf("Migration %d didn't complete. Resume it, or roll it back, first",
+			st.Run.Migration.Version)
+	}
+	for _, m := range ms {
+		if m.Version <= st.Version {
+			continue
+		}
+		now := time.Now()
+		st.Run = &MigrationRun{Migration: m, Direction: MigrateUp, Started: now, Updated: now}
+		if err := runMigration(ctx, st, progress); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+// ResumeMigration resumes the run of the migration which didn't complete, from the step it
+// stopped at.
+func ResumeMigration(ctx context.Context, progress *worker.Progress) (*MigrationState, error) {
+	if err := startMigrations(); err != nil {
+		return nil, err
+	}
+	defer stopMigrations()
+
+	st, err := Migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st.Run == nil {
+		return st, x.Errorf("No migration to resume")
+	}
+	return st, runMigration(ctx, st, progress)
+}
+
+// RollbackMigrations rolls the cluster back to version, running the down steps of the migrations
+// applied after it, the last one first. A migration which didn't complete is rolled back first,
+// from wherever its up steps stopped.
+func RollbackMigrations(ctx context.Context, version int,
+	progress *worker.Progress) (*MigrationState, error) {
+	if err := startMigrations(); err != nil {
+		return nil, err
+	}
+	defer stopMigrations()
+
+	st, err := Migrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version < 0 || version > st.Version {
+		return st, x.Errorf("Can't roll back to version %d from version %d", version, st.Version)
+	}
+	var ms []*Migration
+	if st.Run != nil {
+		if st.Run.Direction == MigrateDown {
+			return st, x.Errorf("Rollback of migration %d didn't complete. Resume it first",
+				st.Run.Migration.Version)
+		}
+		ms = append(ms, st.Run.Migration)
+	}
+	for i := len(st.Applied) - 1; i >= 0 && st.Applied[i].Version > version; i-- {
+		ms = append(ms, st.Applied[i])
+	}
+	for _, m := range ms {
+		if len(m.Down) == 0 {
+			return st, x.Errorf("Migration %d has no down steps, so it can't be rolled back",
+				m.Version)
+		}
+	}
+	for _, m := range ms {
+		now := time.Now()
+		st.Run = &MigrationRun{Migration: m, Direction: MigrateDown, Started: now, Updated: now}
+		if err := runMigration(ctx, st, progress); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+// runMigration runs the steps of st.Run left, storing st before each, and updates the version of
+// st once they're all done.
+func runMigration(ctx context.Context, st *MigrationState, progress *worker.Progress) error {
+	run := st.Run
+	m := run.Migration
+	steps := m.Up
+	if run.Direction == MigrateDown {
+		steps = m.Down
+	}
+	run.Status = MigrationRunning
+	for run.Step < len(steps) {
+		progress.SetPhase(fmt.Sprintf("migration %d %s, step %d of %d", m.Version,
+			run.Direction, run.Step+1, len(steps)))
+		run.Updated = time.Now()
+		if err := saveMigrations(ctx, st); err != nil {
+			return err
+		}
+		if err := runMigrationStep(ctx, steps[run.Step]); err != nil {
+			run.Status, run.Error, run.Updated = MigrationFailed, err.Error(), time.Now()
+			if err := saveMigrations(ctx, st); err != nil {
+				return err
+			}
+			return x.Wrapf(err, "Step %d of migration %d %s failed", run.Step+1, m.Version,
+				run.Direction)
+		}
+		run.Step++
+		run.Error = ""
+	}
+
+	if run.Direction == MigrateUp {
+		st.Applied = append(st.Applied, m)
+	} else if n := len(st.Applied); n > 0 && st.Applied[n-1].Version == m.Version {
+		st.Applied = st.Applied[:n-1]
+	}
+	st.Version = 0
+	if n := len(st.Applied); n > 0 {
+		st.Version = st.Applied[n-1].Version
+	}
+	st.Run = nil
+	return saveMigrations(ctx, st)
+}
+
+func runMigrationStep(ctx context.Context, s *MigrationStep) error {
+	switch {
+	case s.Schema != "":
+		return applySchema(ctx, s.Schema)
+	case s.Backfill != "":
+		res, err := ParseQueryAndMutation(ctx, gql.Request{
+			Str:       s.Backfill,
+			Variables: map[string]string{},
+			Http:      true,
+		})
+		if err != nil {
+			return err
+		}
+		ctx = context.WithValue(ctx, "mutation_allowed", !Config.Nomutations)
+		queryRequest := query.QueryRequest{Latency: &query.Latency{}, GqlQuery: &res}
+		_, err = queryRequest.ProcessWithMutation(ctx)
+		return err
+	default:
+		return renamePredicate(ctx, s.Rename.From, s.Rename.To)
+	}
+}
+
+func applySchema(ctx context.Context, s string) error {
+	updates, typs, err := schema.ParseWithTypes(s)
+	if err != nil {
+		return err
+	}
+	return query.ApplyMutations(ctx, &protos.Mutations{Schema: updates, Types: typs})
+}
+
+// schemaLine returns the schema of the predicate pred, as n.
+func schemaLine(pred string, n *protos.SchemaNode) string {
+	var buf bytes.Buffer
+	typ := n.Type
+	if n.List {
+		typ = "[" + typ + "]"
+	}
+	fmt.Fprintf(&buf, "%s: %s", pred, typ)
+	if len(n.Tokenizer) > 0 {
+		fmt.Fprintf(&buf, " @index(%s)", strings.Join(schema.Tokenizers(n.Tokenizer, n.Geo), ", "))
+	}
+	if n.Reverse {
+		buf.WriteString(" @reverse")
+	}
+	if n.Count {
+		buf.WriteString(" @count")
+	}
+	if n.Noreplace {
+		buf.WriteString(" @noreplace")
+	}
+	if n.Ondelete != "" {
+		fmt.Fprintf(&buf, " @ondelete(%s)", n.Ondelete)
+	}
+	if len(n.Required) > 0 {
+		fmt.Fprintf(&buf, " @required(%s)", strings.Join(n.Required, ", "))
+	}
+	if n.Default != "" {
+		fmt.Fprintf(&buf, " @default(%s)", schema.Quote(n.Default))
+	}
+	buf.WriteString(" .")
+	return buf.String()
+}
+
+// patternOf returns the pattern of export filters matching only the predicate attr.
+func patternOf(attr string) string {
+	var buf bytes.Buffer
+	for _, r := range attr {
+		if strings.ContainsRune(`*?[\`, r) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// exportedUid returns the uid of the node exported as the blank node id.
+func exportedUid(id string) string {
+	if strings.HasPrefix(id, "_:uid") {
+		return "0x" + strings.TrimPrefix(id, "_:uid")
+	}
+	return id
+}
+
+// renamePredicate copies the edges of from to to, as they're streamed in an export, and then
+// deletes them from from.
+func renamePredicate(ctx context.Context, from, to string) error {
+	nodes, err := worker.GetSchemaOverNetwork(ctx, &protos.SchemaRequest{
+		Predicates: []string{from, to},
+		Fields: []string{"type", "index", "tokenizer", "reverse", "count", "list", "required",
+			"default", "noreplace", "ondelete", "geo"},
+	})
+	if err != nil {
+		return err
+	}
+	var fromNode *protos.SchemaNode
+	var hasTo bool
+	for _, n := range nodes {
+		switch n.Predicate {
+		case from:
+			fromNode = n
+		case to:
+			hasTo = true
+		}
+	}
+	if fromNode == nil {
+		return x.Errorf("No predicate %s to rename", from)
+	}
+	if fromNode.Type == "password" {
+		// Passwords are exported hashed, and would be hashed again.
+		return x.Errorf("Predicate %s of type password can't be renamed", from)
+	}
+	if redacted, err := worker.ExportRedacted(from); err != nil {
+		return err
+	} else if redacted {
+		return x.Errorf("Predicate %s is redacted in exports, so it can't be renamed", from)
+	}
+	if !hasTo {
+		if err := applySchema(ctx, schemaLine(to, fromNode)); err != nil {
+			return x.Wrapf(err, "While copying the schema of %s to %s", from, to)
+		}
+	}
+
+	req := &protos.ExportRequest{Format: "rdf", Include: []string{patternOf(from)}}
+	err = worker.StreamExportOverNetwork(ctx, req, func(chunk *protos.ExportChunk) error {
+		var edges []*protos.DirectedEdge
+		for _, line := range strings.Split(string(chunk.Data), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			nq, err := rdf.Parse(line)
+			if err != nil {
+				return x.Wrapf(err, "While parsing exported edge: %q", line)
+			}
+			nq.Subject = exportedUid(nq.Subject)
+			if nq.ObjectId != "" {
+				nq.ObjectId = exportedUid(nq.ObjectId)
+			}
+			nq.Predicate = to
+			edge, err := gql.NQuad{NQuad: &nq}.ToEdgeUsing(nil)
+			if err != nil {
+				return err
+			}
+			edges = append(edges, edge)
+		}
+		if len(edges) == 0 {
+			return nil
+		}
+		return query.ApplyMutations(ctx, &protos.Mutations{Edges: edges})
+	})
+	if err != nil {
+		return x.Wrapf(err, "While copying the edges of %s to %s", from, to)
+	}
+	return query.ApplyMutations(ctx, &protos.Mutations{Edges: []*protos.DirectedEdge{{
+		Attr:  from,
+		Value: []byte(x.Star),
+		Op:    protos.DirectedEdge_DEL,
+	}}})
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Namespaces isolate the data of tenants sharing a server. The predicates of a namespace are
+// stored under names prefixed with it, and requests run in a namespace only see those, under
+// their names in the namespace. They're kept in the JSON file of --namespaces, as an object from
+// namespaces to their tokens. With namespaces, queries must run in one: its name is given in
+// the --tenant_header of HTTP requests, or the namespace metadata of gRPC ones, and its token in
+// the X-Auth-Token header, or the auth-token metadata.
+
+var (
+	ErrNoNamespace     = errors.New("Requests must be run in a namespace on this server")
+	ErrNamespaceDenied = errors.New("Invalid namespace or auth token")
+)
+
+var namespaces = struct {
+	sync.RWMutex
+	tokens map[string]string
+}{tokens: make(map[string]string)}
+
+// NamespacesEnabled returns whether the server has --namespaces.
+func NamespacesEnabled() bool {
+	return Config.Namespaces != ""
+}
+
+// LoadNamespaces reads the namespaces from the file of --namespaces.
+func LoadNamespaces() error {
+	if Config.Namespaces == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(Config.Namespaces)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	tokens := make(map[string]string)
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return x.Wrapf(err, "While reading namespaces from %v", Config.Namespaces)
+	}
+	for ns, token := range tokens {
+		if !validTenant(ns) || token == "" {
+			return x.Errorf("Invalid namespace: %q", ns)
+		}
+	}
+	namespaces.Lock()
+	namespaces.tokens = tokens
+	namespaces.Unlock()
+	return nil
+}
+
+// Namespaces returns the names of the namespaces, sorted.
+func Namespaces() []string {
+	namespaces.RLock()
+	defer namespaces.RUnlock()
+	names := make([]string, 0, len(namespaces.tokens))
+	for ns := range namespaces.tokens {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetNamespace adds the namespace ns, or changes its token.
+func SetNamespace(ns, token string) error {
+	if !validTenant(ns) {
+		return x.Errorf("Invalid namespace: %q. Namespaces are made of letters, digits, "+
+			"'_', '-' and '.'", ns)
+	}
+	if token == "" {
+		return x.Errorf("Empty token for namespace: %q", ns)
+	}
+	namespaces.Lock()
+	defer namespaces.Unlock()
+	old, had := namespaces.tokens[ns]
+	namespaces.tokens[ns] = token
+	if err := writeJSONFile(Config.Namespaces, namespaces.tokens); err != nil {
+		if had {
+			namespaces.tokens[ns] = old
+		} else {
+			delete(namespaces.tokens, ns)
+		}
+		return x.Wrapf(err, "While saving namespaces")
+	}
+	return nil
+}
+
+// DeleteNamespace removes the namespace ns. Its data is kept, see DropNamespace.
+func DeleteNamespace(ns string) error {
+	namespaces.Lock()
+	defer namespaces.Unlock()
+	old, ok := namespaces.tokens[ns]
+	if !ok {
+		return x.Errorf("No namespace: %q", ns)
+	}
+	delete(namespaces.tokens, ns)
+	if err := writeJSONFile(Config.Namespaces, namespaces.tokens); err != nil {
+		namespaces.tokens[ns] = old
+		return x.Wrapf(err, "While saving namespaces")
+	}
+	return nil
+}
+
+// AuthorizeNamespace returns the namespace to run a request in, given the namespace and token it
+// was sent with. It's "" on servers without namespaces.
+func AuthorizeNamespace(ns, token string) (string, error) {
+	if !NamespacesEnabled() {
+		return "", nil
+	}
+	if ns == "" {
+		return "", ErrNoNamespace
+	}
+	namespaces.RLock()
+	want, ok := namespaces.tokens[ns]
+	namespaces.RUnlock()
+	// The token is compared even for unknown namespaces, not to tell them apart by timing.
+	if subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 || !ok {
+		return "", ErrNamespaceDenied
+	}
+	return ns, nil
+}
+
+// grpcNamespace returns the namespace to run a gRPC request in, from the namespace and
+// auth-token metadata of ctx.
+func grpcNamespace(ctx context.Context) (string, error) {
+	if !NamespacesEnabled() {
+		return "", nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var ns, token string
+	if v := md["namespace"]; len(v) == 1 {
+		ns = v[0]
+	}
+	if v := md["auth-token"]; len(v) == 1 {
+		token = v[0]
+	}
+	return AuthorizeNamespace(ns, token)
+}
+
+// DropNamespace deletes all of the data and schema of the predicates of namespace ns.
+func DropNamespace(ctx context.Context, ns string) error {
+	nodes, err := worker.GetSchemaOverNetwork(ctx, &protos.SchemaRequest{})
+	if err != nil {
+		return x.Wrapf(err, "While fetching the schema")
+	}
+	var edges []*protos.DirectedEdge
+	for _, n := range nodes {
+		if attrNs, _ := x.ParseNamespacedAttr(n.Predicate); attrNs == ns {
+			edges = append(edges, &protos.DirectedEdge{
+				Attr:  n.Predicate,
+				Value: []byte(x.Star),
+				Op:    protos.DirectedEdge_DEL,
+			})
+		}
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+	return query.ApplyMutations(ctx, &protos.Mutations{Edges: edges})
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Persisted queries are registered on a server under an id, and run by giving the id and the
+// values of their variables. They're kept in the JSON file of --persisted_queries, as an object
+// from ids to queries. With --persisted_only, only persisted queries can be run.
+
+var ErrPersistedOnly = errors.New("Only persisted queries can be run on this server")
+
+var persisted = struct {
+	sync.RWMutex
+	queries map[string]string
+}{queries: make(map[string]string)}
+
+// LoadPersistedQueries reads the persisted queries from the file of --persisted_queries.
+func LoadPersistedQueries() error {
+	if Config.PersistedQueries == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(Config.PersistedQueries)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	queries := make(map[string]string)
+	if err := json.Unmarshal(b, &queries); err != nil {
+		return x.Wrapf(err, "While reading persisted queries from %v", Config.PersistedQueries)
+	}
+	for id := range queries {
+		if !validQueryId(id) {
+			return x.Errorf("Invalid persisted query id: %q", id)
+		}
+	}
+	persisted.Lock()
+	persisted.queries = queries
+	persisted.Unlock()
+	return nil
+}
+
+// savePersistedQueries writes the persisted queries. It's called with persisted locked.
+func savePersistedQueries() error {
+	if Config.PersistedQueries == "" {
+		return nil
+	}
+	return writeJSONFile(Config.PersistedQueries, persisted.queries)
+}
+
+// writeJSONFile writes v as JSON to the file at path, replacing the file at once, so that it's
+// never left written in part.
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func validQueryId(id string) bool {
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '_' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return id != ""
+}
+
+// PersistedQuery returns the query persisted under id.
+func PersistedQuery(id string) (string, bool) {
+	persisted.RLock()
+	defer persisted.RUnlock()
+	q, ok := persisted.queries[id]
+	return q, ok
+}
+
+// PersistedQueries returns all of the persisted queries, by id.
+func PersistedQueries() map[string]string {
+	persisted.RLock()
+	defer persisted.RUnlock()
+	queries := make(map[string]string, len(persisted.queries))
+	for id, q := range persisted.queries {
+		queries[id] = q
+	}
+	return queries
+}
+
+// SetPersistedQuery persists the query q under id, replacing any query there.
+func SetPersistedQuery(id, q string) error {
+	if !validQueryId(id) {
+		return x.Errorf("Invalid persisted query id: %q. Ids are made of letters, digits, "+
+			"'_', '-' and '.'", id)
+	}
+	if q == "" {
+		return x.Errorf("Empty persisted query: %q", id)
+	}
+	persisted.Lock()
+	defer persisted.Unlock()
+	old, had := persisted.queries[id]
+	persisted.queries[id] = q
+	if err := savePersistedQueries(); err != nil {
+		if had {
+			persisted.queries[id] = old
+		} else {
+			delete(persisted.queries, id)
+		}
+		return x.Wrapf(err, "While saving persisted queries")
+	}
+	return nil
+}
+
+// DeletePersistedQuery removes the query persisted under id.
+func DeletePersistedQuery(id string) error {
+	persisted.Lock()
+	defer persisted.Unlock()
+	old, ok := persisted.queries[id]
+	if !ok {
+		return x.Errorf("No persisted query: %q", id)
+	}
+	delete(persisted.queries, id)
+	if err := savePersistedQueries(); err != nil {
+		persisted.queries[id] = old
+		return x.Wrapf(err, "While saving persisted queries")
+	}
+	return nil
+}
+
+// ResolveQuery returns the query to run for a request, which has either the text of a query, or
+// the id of a persisted one.
+func ResolveQuery(query, id string) (string, error) {
+	if id == "" {
+		if Config.PersistedOnly {
+			return "", ErrPersistedOnly
+		}
+		return query, nil
+	}
+	if query != "" {
+		return "", x.Errorf("A request can't have both a query and a persisted query id")
+	}
+	q, ok := PersistedQuery(id)
+	if !ok {
+		return "", x.Errorf("No persisted query: %q", id)
+	}
+	return q, nil
+}
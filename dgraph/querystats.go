@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Statistics of queries are aggregated by their fingerprint, their normalized text, for the
+// --query_stats fingerprints seen last. The latencies of the last runs of each are kept in a ring
+// buffer, for their percentiles.
+
+// latencySamples is the number of the last latencies kept for each fingerprint.
+const latencySamples = 128
+
+type queryStat struct {
+	count     int64
+	total     time.Duration
+	scanned   int64
+	latencies [latencySamples]time.Duration
+	next      int // Index of the next latency in latencies.
+	lastSeen  time.Time
+}
+
+// QueryStat is the statistics of the runs of a query fingerprint.
+type QueryStat struct {
+	Query        string    `json:"query"`
+	Count        int64     `json:"count"`
+	TotalLatency string    `json:"total_latency"`
+	MeanLatency  string    `json:"mean_latency"`
+	P95Latency   string    `json:"p95_latency"`
+	Scanned      int64     `json:"scanned_bytes"`
+	MeanScanned  int64     `json:"mean_scanned_bytes"`
+	LastSeen     time.Time `json:"last_seen"`
+
+	total time.Duration
+	mean  time.Duration
+	p95   time.Duration
+}
+
+var queryStats = struct {
+	sync.Mutex
+	m map[string]*queryStat
+}{m: make(map[string]*queryStat)}
+
+// QueryStatsEnabled returns whether the server keeps the statistics of queries.
+func QueryStatsEnabled() bool {
+	return Config.QueryStats > 0
+}
+
+// recordQueryStat adds a run of the query fingerprint, which took latency and scanned bytes.
+func recordQueryStat(fingerprint string, latency time.Duration, scanned int64) {
+	queryStats.Lock()
+	defer queryStats.Unlock()
+	s, ok := queryStats.m[fingerprint]
+	if !ok {
+		if len(queryStats.m) >= Config.QueryStats {
+			// Make room for it by evicting the fingerprint seen the longest ago.
+			var oldest string
+			var seen time.Time
+			for f, s := range queryStats.m {
+				if oldest == "" || s.lastSeen.Before(seen) {
+					oldest, seen = f, s.lastSeen
+				}
+			}
+			delete(queryStats.m, oldest)
+		}
+		s = new(queryStat)
+		queryStats.m[fingerprint] = s
+	}
+	s.count++
+	s.total += latency
+	s.scanned += scanned
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % latencySamples
+	s.lastSeen = time.Now()
+}
+
+// ResetQueryStats removes the statistics of all queries.
+func ResetQueryStats() {
+	queryStats.Lock()
+	queryStats.m = make(map[string]*queryStat)
+	queryStats.Unlock()
+}
+
+func (s *queryStat) export(fingerprint string) QueryStat {
+	n := int64(latencySamples)
+	if s.count < n {
+		n = s.count
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, s.latencies[:n])
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	// The nearest rank of the 95th percentile.
+	p95 := samples[(95*n+99)/100-1]
+	mean := s.total / time.Duration(s.count)
+	return QueryStat{
+		Query:        fingerprint,
+		Count:        s.count,
+		TotalLatency: x.Round(s.total).String(),
+		MeanLatency:  x.Round(mean).String(),
+		P95Latency:   x.Round(p95).String(),
+		Scanned:      s.scanned,
+		MeanScanned:  s.scanned / s.count,
+		LastSeen:     s.lastSeen,
+		total:        s.total,
+		mean:         mean,
+		p95:          p95,
+	}
+}
+
+var queryStatOrders = map[string]func(a, b *QueryStat) bool{
+	"total":   func(a, b *QueryStat) bool { return a.total > b.total },
+	"count":   func(a, b *QueryStat) bool { return a.Count > b.Count },
+	"mean":    func(a, b *QueryStat) bool { return a.mean > b.mean },
+	"p95":     func(a, b *QueryStat) bool { return a.p95 > b.p95 },
+	"scanned": func(a, b *QueryStat) bool { return a.Scanned > b.Scanned },
+}
+
+// TopQueries returns the n query fingerprints with the most of by: total, count, mean, p95 or
+// scanned. Total is the time taken by all the runs of a fingerprint, the load it causes.
+func TopQueries(by string, n int) ([]QueryStat, error) {
+	less, ok := queryStatOrders[by]
+	if !ok {
+		return nil, x.Errorf("Invalid order of queries: %q. Expected total, count, mean, p95 or "+
+			"scanned", by)
+	}
+	queryStats.Lock()
+	stats := make([]QueryStat, 0, len(queryStats.m))
+	for f, s := range queryStats.m {
+		stats = append(stats, s.export(f))
+	}
+	queryStats.Unlock()
+	sort.Slice(stats, func(i, j int) bool { return less(&stats[i], &stats[j]) })
+	if n >= 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats, nil
+}
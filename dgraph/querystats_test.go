@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopQueries(t *testing.T) {
+	defer func(c Options) { Config = c }(Config)
+	defer ResetQueryStats()
+	Config.QueryStats = 2
+
+	// A fast query run often, and a slow one run once.
+	for i := 1; i <= 100; i++ {
+		recordQueryStat("{ fast }", time.Duration(i)*time.Millisecond, 10)
+	}
+	recordQueryStat("{ slow }", 2*time.Second, 1000)
+
+	stats, err := TopQueries("total", 10)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, "{ fast }", stats[0].Query)
+	require.Equal(t, int64(100), stats[0].Count)
+	require.Equal(t, "5.1s", stats[0].TotalLatency)
+	require.Equal(t, "51ms", stats[0].MeanLatency)
+	require.Equal(t, "95ms", stats[0].P95Latency)
+	require.Equal(t, int64(1000), stats[0].Scanned)
+	require.Equal(t, int64(10), stats[0].MeanScanned)
+
+	stats, err = TopQueries("mean", 1)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, "{ slow }", stats[0].Query)
+	require.Equal(t, "2s", stats[0].P95Latency)
+
+	_, err = TopQueries("latency", 1)
+	require.Error(t, err)
+
+	// Past the limit, the fingerprint seen the longest ago is dropped.
+	recordQueryStat("{ other }", time.Millisecond, 0)
+	stats, err = TopQueries("total", 10)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, "{ slow }", stats[0].Query)
+	require.Equal(t, "{ other }", stats[1].Query)
+}
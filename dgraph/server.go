@@ -24,12 +24,15 @@ import (
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/badger/table"
 	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/tracing"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/pkg/errors"
@@ -46,6 +49,11 @@ type ServerState struct {
 // TODO(tzdybal) - remove global
 var State ServerState
 
+var serverLog = x.NewLog("server")
+
+// streamPartSize is about how many bytes of results each response of RunStream carries.
+const streamPartSize = 1 << 20
+
 func NewServerState() (state ServerState) {
 	Config.validate()
 
@@ -78,6 +86,9 @@ func (s *ServerState) initStorage() {
 	opt.SyncWrites = true
 	opt.Dir = Config.PostingDir
 	opt.ValueDir = Config.PostingDir
+	// Older versions of posting lists stay in the value log, until it gets garbage collected.
+	opt.ValueGCRunInterval = Config.ValueGCInterval
+	opt.ValueGCThreshold = Config.ValueGCThreshold
 	switch Config.PostingTables {
 	case "memorymap":
 		opt.MapTablesTo = table.MemoryMap
@@ -129,37 +140,164 @@ func (s *Server) Run(ctx context.Context, req *protos.Request) (resp *protos.Res
 		defer tr.Finish()
 		ctx = trace.NewContext(ctx, tr)
 	}
+	span, ctx := tracing.StartRoot(ctx, "grpc.Run", tracing.Incoming(ctx))
+	defer span.Finish()
+	ctx = requestId(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(x.RequestIdHeader, x.RequestId(ctx)))
+	ctx, progress := worker.StartProgress(ctx, worker.RunQuery, x.RequestId(ctx))
+	defer func() { progress.Finish(err) }()
+
+	resp = new(protos.Response)
+	var l query.Latency
+	er, err := s.execute(ctx, req, &l, progress)
+	span.SetError(err)
+	if err != nil {
+		return resp, err
+	}
+	resp.AssignedUids = er.Allocations
+	resp.Schema = er.SchemaNode
+	progress.SetPhase("encoding")
+
+	nodes, err := query.ToProtocolBuf(&l, er.Subgraphs)
+	if err != nil {
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("Error while converting to protocol buffer: %+v", err)
+		}
+		return resp, err
+	}
+	resp.N = nodes
+	resp.L = protoLatency(&l)
+	return resp, err
+}
+
+// RunStream runs a request like Run, but sends the results of each query block as they are
+// converted to protocol buffers, in parts of about streamPartSize bytes. The first response also
+// has the uids assigned and the schema, and the last one the latency.
+func (s *Server) RunStream(req *protos.Request,
+	stream protos.Dgraph_RunStreamServer) (err error) {
+	ctx := stream.Context()
+	if err := x.HealthCheck(); err != nil {
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("Request rejected %v", err)
+		}
+		return err
+	}
+
+	x.PendingQueries.Add(1)
+	x.NumQueries.Add(1)
+	defer x.PendingQueries.Add(-1)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if rand.Float64() < worker.Config.Tracing {
+		tr := trace.New("Dgraph", "GrpcQueryStream")
+		tr.SetMaxEvents(1000)
+		defer tr.Finish()
+		ctx = trace.NewContext(ctx, tr)
+	}
+	span, ctx := tracing.StartRoot(ctx, "grpc.RunStream", tracing.Incoming(ctx))
+	defer span.Finish()
+	ctx = requestId(ctx)
+	stream.SetHeader(metadata.Pairs(x.RequestIdHeader, x.RequestId(ctx)))
+	ctx, progress := worker.StartProgress(ctx, worker.RunQuery, x.RequestId(ctx))
+	defer func() { progress.Finish(err) }()
+
+	var l query.Latency
+	er, err := s.execute(ctx, req, &l, progress)
+	span.SetError(err)
+	if err != nil {
+		return err
+	}
+	progress.SetPhase("encoding")
+	first := &protos.Response{AssignedUids: er.Allocations, Schema: er.SchemaNode}
+	send := func(resp *protos.Response) error {
+		if first != nil {
+			resp.AssignedUids, resp.Schema = first.AssignedUids, first.Schema
+			first = nil
+		}
+		return stream.Send(resp)
+	}
+	if err := query.StreamProtocolBuf(&l, er.Subgraphs, streamPartSize,
+		func(n *protos.Node) error {
+			return send(&protos.Response{N: []*protos.Node{n}})
+		}); err != nil {
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("Error while streaming protocol buffers: %+v", err)
+		}
+		return err
+	}
+	return send(&protos.Response{L: protoLatency(&l)})
+}
+
+// requestId returns ctx carrying the id of its request, the one sent by the client if valid, or
+// a new one.
+func requestId(ctx context.Context) context.Context {
+	if ctx = x.IncomingRequestId(ctx); x.RequestId(ctx) == "" {
+		ctx = x.WithRequestId(ctx, x.NewRequestId())
+	}
+	return ctx
+}
 
+// execute parses and runs req, with its mutations, for Run and RunStream. It sets the phases of
+// the run on progress.
+func (s *Server) execute(ctx context.Context, req *protos.Request, l *query.Latency,
+	progress *worker.Progress) (er query.ExecuteResult, err error) {
 	// Sanitize the context of the keys used for internal purposes only
 	ctx = context.WithValue(ctx, "_share_", nil)
 	ctx = context.WithValue(ctx, "mutation_allowed", isMutationAllowed(ctx))
 
-	resp = new(protos.Response)
 	emptyMutation := len(req.Mutation.GetSet()) == 0 && len(req.Mutation.GetDel()) == 0 &&
 		len(req.Mutation.GetSchema()) == 0
-	if len(req.Query) == 0 && emptyMutation && req.Schema == nil {
+	if len(req.Query) == 0 && len(req.QueryId) == 0 && emptyMutation && req.Schema == nil {
 		if tr, ok := trace.FromContext(ctx); ok {
 			tr.LazyPrintf("Empty query and mutation.")
 		}
-		return resp, fmt.Errorf("empty query and mutation.")
+		return er, fmt.Errorf("empty query and mutation.")
+	}
+	q, err := ResolveQuery(req.Query, req.QueryId)
+	if err != nil {
+		return er, err
+	}
+	if Config.PersistedOnly && (!emptyMutation || req.Schema != nil) {
+		// Mutations and schema requests can only be part of persisted queries.
+		return er, ErrPersistedOnly
+	}
+	ns, err := grpcNamespace(ctx)
+	if err != nil {
+		AuditDenied("Run", GRPCRemote(ctx), "", err)
+		return er, err
+	}
+	id, err := grpcIdentity(ctx)
+	if err != nil {
+		AuditDenied("Run", GRPCRemote(ctx), id.User, err)
+		return er, err
+	}
+	done, err := StartQuery(ClientKey(id.User, GRPCRemote(ctx)))
+	if err != nil {
+		return er, GRPCThrottled(err)
+	}
+	defer done()
+	if _, err := CheckAPIVersion(req.ApiVersion); err != nil {
+		return er, err
 	}
 
 	if Config.DebugMode {
 		x.Printf("Received query: %+v, mutation: %+v\n", req.Query, req.Mutation)
 	}
-	var l query.Latency
 	l.Start = time.Now()
 	if tr, ok := trace.FromContext(ctx); ok {
 		tr.LazyPrintf("Query received: %v, variables: %v", req.Query, req.Vars)
 	}
+	progress.SetPhase("parsing")
 	res, err := ParseQueryAndMutation(ctx, gql.Request{
-		Str:       req.Query,
+		Str:       q,
 		Mutation:  req.Mutation,
 		Variables: req.Vars,
 		Http:      false,
 	})
 	if err != nil {
-		return resp, err
+		return er, err
 	}
 
 	var cancel context.CancelFunc
@@ -171,7 +309,7 @@ func (s *Server) Run(ctx context.Context, req *protos.Request) (resp *protos.Res
 	}
 
 	if req.Schema != nil && res.Schema != nil {
-		return resp, x.Errorf("Multiple schema blocks found")
+		return er, x.Errorf("Multiple schema blocks found")
 	}
 	// Schema Block and Mutation can be part of query string or request
 	if res.Schema == nil {
@@ -179,37 +317,40 @@ func (s *Server) Run(ctx context.Context, req *protos.Request) (resp *protos.Res
 	}
 
 	var queryRequest = query.QueryRequest{
-		Latency:  &l,
-		GqlQuery: &res,
+		Latency:    l,
+		GqlQuery:   &res,
+		Namespace:  ns,
+		Access:     id.Access,
+		NodeFilter: id.NodeFilter,
 	}
 	if req.Mutation != nil && len(req.Mutation.Schema) > 0 {
 		queryRequest.SchemaUpdate = req.Mutation.Schema
 	}
 
-	var er query.ExecuteResult
-	if er, err = queryRequest.ProcessWithMutation(ctx); err != nil {
-		if tr, ok := trace.FromContext(ctx); ok {
-			tr.LazyPrintf("Error while processing query: %+v", err)
-		}
-		return resp, x.Wrap(err)
-	}
-	resp.AssignedUids = er.Allocations
-	resp.Schema = er.SchemaNode
-
-	nodes, err := query.ToProtocolBuf(&l, er.Subgraphs)
+	entry := AuditRequest("Run", GRPCRemote(ctx), id.User, ns, q, &res)
+	ctx, slow := StartSlowQuery(ctx, "Run", GRPCRemote(ctx), id.User, ns,
+		gql.Request{Str: q, Variables: req.Vars}, l)
+	serverLog.Debugf(ctx, "Running request from %s", GRPCRemote(ctx))
+	progress.SetPhase("processing")
+	er, err = queryRequest.ProcessWithMutation(ctx)
+	entry.Done(err)
+	slow.Done(err)
 	if err != nil {
 		if tr, ok := trace.FromContext(ctx); ok {
-			tr.LazyPrintf("Error while converting to protocol buffer: %+v", err)
+			tr.LazyPrintf("Error while processing query: %+v", err)
 		}
-		return resp, err
+		serverLog.Warningf(ctx, "Error while processing query: %v", err)
+		return er, x.Wrap(err)
 	}
-	resp.N = nodes
+	serverLog.Debugf(ctx, "Processed request in %v", time.Since(l.Start))
+	return er, nil
+}
 
+func protoLatency(l *query.Latency) *protos.Latency {
 	gl := new(protos.Latency)
 	gl.Parsing, gl.Processing, gl.Pb = l.Parsing.String(), l.Processing.String(),
 		l.ProtocolBuffer.String()
-	resp.L = gl
-	return resp, err
+	return gl
 }
 
 func (s *Server) CheckVersion(ctx context.Context, c *protos.Check) (v *protos.Version, err error) {
@@ -220,9 +361,7 @@ func (s *Server) CheckVersion(ctx context.Context, c *protos.Check) (v *protos.V
 		return v, err
 	}
 
-	v = new(protos.Version)
-	v.Tag = x.Version()
-	return v, nil
+	return NegotiateAPIVersion(c.ApiVersion)
 }
 
 func (s *Server) AssignUids(ctx context.Context, num *protos.Num) (*protos.AssignedIds, error) {
@@ -235,6 +374,38 @@ func (s *Server) AssignUids(ctx context.Context, num *protos.Num) (*protos.Assig
 	return worker.AssignUidsOverNetwork(ctx, num)
 }
 
+// Members returns the servers of the cluster, with the groups serving the predicates of req, for
+// clients to balance their requests across.
+func (s *Server) Members(ctx context.Context, req *protos.MembersRequest) (*protos.MemberList,
+	error) {
+	if err := x.HealthCheck(); err != nil {
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("request rejected %v", err)
+		}
+		return &protos.MemberList{}, err
+	}
+	return worker.Members(req.Predicates), nil
+}
+
+// Export streams an export of the cluster to the client, resuming from the offsets in req.
+func (s *Server) Export(req *protos.ExportRequest, stream protos.Dgraph_ExportServer) error {
+	if ACLEnabled() || JWTEnabled() {
+		// The export has all of the predicates, whatever the permissions of the user.
+		return ErrACLExport
+	}
+	return worker.StreamExportOverNetwork(stream.Context(), req, stream.Send)
+}
+
+// Subscribe streams the changes committed to the groups served here, to the predicates asked for.
+func (s *Server) Subscribe(req *protos.SubscribeRequest,
+	stream protos.Dgraph_SubscribeServer) error {
+	if NamespacesEnabled() || ACLEnabled() || JWTEnabled() {
+		// Changes are sent for all the predicates, like on /changes.
+		return ErrACLChanges
+	}
+	return worker.SubscribeChanges(stream.Context(), req, stream.Send)
+}
+
 //-------------------------------------------------------------------------------------------------
 // HELPER FUNCTIONS
 //-------------------------------------------------------------------------------------------------
@@ -255,6 +426,8 @@ func ParseQueryAndMutation(ctx context.Context, r gql.Request) (res gql.Result,
 	if tr, ok := trace.FromContext(ctx); ok {
 		tr.LazyPrintf("Query received: %v", r.Str)
 	}
+	span, _ := tracing.Start(ctx, "parse")
+	defer span.Finish()
 	errc := make(chan error, 1)
 
 	go func() {
@@ -274,6 +447,7 @@ func ParseQueryAndMutation(ctx context.Context, r gql.Request) (res gql.Result,
 			if tr, ok := trace.FromContext(ctx); ok {
 				tr.LazyPrintf("Error while parsing query: %+v", err)
 			}
+			span.SetError(err)
 			return res, err
 		}
 		if tr, ok := trace.FromContext(ctx); ok {
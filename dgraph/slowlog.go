@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The slow query log records the queries which took longer than --slow_query_latency, or scanned
+// more than --slow_query_bytes of posting lists, with how each of their tasks ran. Entries are
+// written as a JSON object per line to the file of --slow_query_log, rotated like the audit log,
+// and the last ones are kept for /admin/slow_queries.
+
+// slowQueriesKept is the number of the last slow queries kept in memory.
+const slowQueriesKept = 100
+
+// SlowTask is how a task of a slow query ran.
+type SlowTask struct {
+	worker.TaskStat
+	Latency string `json:"latency"`
+}
+
+// SlowQuery is an entry of the slow query log.
+type SlowQuery struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Remote    string    `json:"remote"`
+	Endpoint  string    `json:"endpoint"`
+	// Query is the normalized text of the query, with its literals replaced by ?.
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Latency   map[string]string `json:"latency"`
+	Scanned   int64             `json:"scanned_bytes"`
+	Tasks     []SlowTask        `json:"tasks"`
+	Error     string            `json:"error,omitempty"`
+
+	text  string
+	l     *query.Latency
+	stats *worker.TaskStats
+}
+
+var slowLog = struct {
+	sync.Mutex
+	f    *logFile
+	last []*SlowQuery
+}{}
+
+// SlowQueryLogEnabled returns whether the server has --slow_query_latency or --slow_query_bytes.
+func SlowQueryLogEnabled() bool {
+	return Config.SlowQueryLatency > 0 || Config.SlowQueryBytes > 0
+}
+
+// OpenSlowQueryLog opens the file of --slow_query_log, if any.
+func OpenSlowQueryLog() error {
+	if Config.SlowQueryLog == "" || !SlowQueryLogEnabled() {
+		return nil
+	}
+	f, err := openLogFile(Config.SlowQueryLog, Config.SlowQueryLogSize, Config.SlowQueryLogFiles)
+	if err != nil {
+		return err
+	}
+	slowLog.Lock()
+	slowLog.f = f
+	slowLog.Unlock()
+	return nil
+}
+
+// CloseSlowQueryLog closes the file of the slow query log.
+func CloseSlowQueryLog() error {
+	slowLog.Lock()
+	defer slowLog.Unlock()
+	if slowLog.f == nil {
+		return nil
+	}
+	err := slowLog.f.close()
+	slowLog.f = nil
+	return err
+}
+
+// SlowQueries returns the last slow queries, from the oldest.
+func SlowQueries() []*SlowQuery {
+	slowLog.Lock()
+	defer slowLog.Unlock()
+	return append([]*SlowQuery(nil), slowLog.last...)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z')
+}
+
+// normalizeQuery returns q without comments, with runs of spaces collapsed, and with its string
+// and number literals replaced by ?, so that queries differing only by them look the same.
+func normalizeQuery(q string) string {
+	var buf bytes.Buffer
+	space := false
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+		}
+		if space && buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case c == '"':
+			for i++; i < len(q) && q[i] != '"'; i++ {
+				if q[i] == '\\' {
+					i++
+				}
+			}
+			i++
+			buf.WriteByte('?')
+		case '0' <= c && c <= '9' && (buf.Len() == 0 || !isIdentByte(buf.Bytes()[buf.Len()-1])):
+			for i < len(q) && isIdentByte(q[i]) {
+				i++
+			}
+			buf.WriteByte('?')
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	return buf.String()
+}
+
+// StartSlowQuery starts the entry of the query r sent by user from remote to endpoint, whose
+// latency is timed by l. It returns the context to run the query with, in which its tasks are
+// recorded, and nil if slow queries aren't logged and the statistics of queries aren't kept.
+func StartSlowQuery(ctx context.Context, endpoint, remote, user, ns string, r gql.Request,
+	l *query.Latency) (context.Context, *SlowQuery) {
+	if !SlowQueryLogEnabled() && !QueryStatsEnabled() {
+		return ctx, nil
+	}
+	ctx, stats := worker.WithTaskStats(ctx)
+	text, vars := r.Text()
+	return ctx, &SlowQuery{
+		User:      user,
+		Namespace: ns,
+		Remote:    remote,
+		Endpoint:  endpoint,
+		Variables: vars,
+		text:      text,
+		l:         l,
+		stats:     stats,
+	}
+}
+
+// isSlow returns whether a query which took latency and scanned bytes is logged.
+func isSlow(latency time.Duration, scanned int64) bool {
+	return (Config.SlowQueryLatency > 0 && latency >= Config.SlowQueryLatency) ||
+		(Config.SlowQueryBytes > 0 && scanned >= Config.SlowQueryBytes)
+}
+
+// Done adds the query of e to the statistics of queries, and records it if it's slow, with the
+// error it failed with, if any.
+func (e *SlowQuery) Done(err error) {
+	if e == nil {
+		return
+	}
+	total := time.Since(e.l.Start)
+	e.Scanned = e.stats.Scanned()
+	e.Query = normalizeQuery(e.text)
+	if QueryStatsEnabled() {
+		recordQueryStat(e.Query, total, e.Scanned)
+	}
+	if !isSlow(total, e.Scanned) {
+		return
+	}
+	e.Time = e.l.Start
+	e.Latency = map[string]string{
+		"parsing":    x.Round(e.l.Parsing).String(),
+		"processing": x.Round(e.l.Processing).String(),
+		"total":      x.Round(total).String(),
+	}
+	for _, t := range e.stats.Tasks() {
+		e.Tasks = append(e.Tasks, SlowTask{TaskStat: t, Latency: x.Round(t.Latency).String()})
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	writeSlowQuery(e)
+}
+
+func writeSlowQuery(e *SlowQuery) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		x.Printf("Error while encoding slow query: %v\n", err)
+		return
+	}
+	b = append(b, '\n')
+
+	slowLog.Lock()
+	defer slowLog.Unlock()
+	if len(slowLog.last) == slowQueriesKept {
+		slowLog.last = slowLog.last[1:]
+	}
+	slowLog.last = append(slowLog.last, e)
+	if slowLog.f != nil {
+		if err := slowLog.f.write(b); err != nil {
+			x.Printf("Error while writing slow query log: %v\n", err)
+		}
+	}
+}
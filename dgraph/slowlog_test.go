@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/query"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	q := `{
+		# Films of a director.
+		me(func: eq(name@en, "Steven \"Spielberg\""), first: 10) @filter(uid(0x1f)) {
+			name2
+			film(offset: $offset) { count(genre) }
+		}
+	}`
+	require.Equal(t, `{ me(func: eq(name@en, ?), first: ?) @filter(uid(?)) { name2 `+
+		`film(offset: $offset) { count(genre) } } }`, normalizeQuery(q))
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slowlog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(c Options) { Config = c }(Config)
+	defer func() { slowLog.last = nil }()
+
+	Config.SlowQueryLatency = time.Second
+	Config.SlowQueryLog = filepath.Join(dir, "slow.log")
+	require.NoError(t, OpenSlowQueryLog())
+	defer CloseSlowQueryLog()
+
+	run := func(q string, took time.Duration, err error) {
+		l := &query.Latency{Start: time.Now().Add(-took), Processing: took}
+		r := gql.Request{Str: q, Variables: map[string]string{"$name": "Alice"}}
+		_, slow := StartSlowQuery(context.Background(), "/query", "127.0.0.1:1234", "alice", "",
+			r, l)
+		require.NotNil(t, slow)
+		slow.Done(err)
+	}
+	run(`{ me(func: eq(name, $name)) { name } }`, time.Millisecond, nil)
+	run(`{ me(func: anyofterms(name, "Alice Bob")) { name } }`, 2*time.Second,
+		errors.New("Query timed out"))
+
+	slow := SlowQueries()
+	require.Len(t, slow, 1)
+	require.Equal(t, `{ me(func: anyofterms(name, ?)) { name } }`, slow[0].Query)
+	require.Equal(t, "Alice", slow[0].Variables["$name"])
+	require.Equal(t, "Query timed out", slow[0].Error)
+	require.Equal(t, "2s", slow[0].Latency["processing"])
+
+	b, err := ioutil.ReadFile(Config.SlowQueryLog)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 1)
+	var e SlowQuery
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	require.Equal(t, "alice", e.User)
+	require.Equal(t, slow[0].Query, e.Query)
+
+	// Slow queries aren't logged without thresholds.
+	Config.SlowQueryLatency = 0
+	_, entry := StartSlowQuery(context.Background(), "/query", "", "", "", gql.Request{}, nil)
+	require.Nil(t, entry)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Requests run with an API version, which clients pick among those the server supports. Releases
+// changing what requests mean add an API version, and keep serving the older ones for a while.
+// The features a version removes keep working with older versions, but responses then tell of
+// their deprecation.
+
+const (
+	// MinAPIVersion is the oldest API version served, which requests run with unless they give one.
+	MinAPIVersion = 1
+	// MaxAPIVersion is the latest API version served.
+	MaxAPIVersion = 2
+)
+
+// deprecations has the deprecated features, by name.
+var deprecations = map[string]*protos.Deprecation{
+	"mutation_status": {
+		Feature: "mutation_status",
+		Message: "The code and message in the data of mutation responses are deprecated. From " +
+			"API version 2 on, the data only has the uids assigned.",
+		RemovedIn: 2,
+	},
+}
+
+// Capabilities returns the optional features requests can use on this server.
+func Capabilities() []string {
+	caps := []string{"compression", "graphql", "live-queries", "persisted-queries",
+		"response-formats", "run-stream"}
+	if Config.PersistedOnly {
+		caps = append(caps, "persisted-only")
+	}
+	if NamespacesEnabled() {
+		caps = append(caps, "namespaces")
+	}
+	return caps
+}
+
+// CheckAPIVersion returns the API version to run a request asking for version v with.
+func CheckAPIVersion(v uint32) (uint32, error) {
+	if v == 0 {
+		return MinAPIVersion, nil
+	}
+	if v < MinAPIVersion || v > MaxAPIVersion {
+		return 0, x.Errorf("Unsupported API version: %d. This server supports versions %d to %d",
+			v, MinAPIVersion, MaxAPIVersion)
+	}
+	return v, nil
+}
+
+// NegotiateAPIVersion returns the server version, with the latest API version supported by both
+// the server and a client supporting up to version v.
+func NegotiateAPIVersion(v uint32) (*protos.Version, error) {
+	if v == 0 {
+		v = MinAPIVersion
+	}
+	if v < MinAPIVersion {
+		return nil, x.Errorf("Unsupported API version: %d. This server supports versions %d to %d",
+			v, MinAPIVersion, MaxAPIVersion)
+	}
+	if v > MaxAPIVersion {
+		v = MaxAPIVersion
+	}
+	return &protos.Version{
+		Tag:           x.Version(),
+		ApiVersion:    v,
+		MinApiVersion: MinAPIVersion,
+		MaxApiVersion: MaxAPIVersion,
+		Capabilities:  Capabilities(),
+	}, nil
+}
+
+// Deprecated returns the deprecation of feature, if it's still there in API version v.
+func Deprecated(feature string, v uint32) *protos.Deprecation {
+	d, ok := deprecations[feature]
+	if !ok || v >= d.RemovedIn {
+		return nil
+	}
+	return d
+}
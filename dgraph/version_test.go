@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersions(t *testing.T) {
+	v, err := CheckAPIVersion(0)
+	require.NoError(t, err)
+	require.EqualValues(t, MinAPIVersion, v)
+	_, err = CheckAPIVersion(MaxAPIVersion + 1)
+	require.Error(t, err)
+
+	version, err := NegotiateAPIVersion(MaxAPIVersion + 5)
+	require.NoError(t, err)
+	require.EqualValues(t, MaxAPIVersion, version.ApiVersion)
+	require.Contains(t, version.Capabilities, "run-stream")
+	version, err = NegotiateAPIVersion(1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, version.ApiVersion)
+}
+
+func TestDeprecated(t *testing.T) {
+	d := Deprecated("mutation_status", 1)
+	require.NotNil(t, d)
+	require.EqualValues(t, 2, d.RemovedIn)
+	require.Nil(t, Deprecated("mutation_status", 2))
+	require.Nil(t, Deprecated("other", 1))
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dgraph
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Webhooks are registered on a server under an id, and the leaders of the groups it serves post
+// the changes committed to them to the webhooks. They're kept in the JSON file of --webhooks, as
+// an object from ids to webhooks. Each server has its own webhooks, so they're registered on all
+// the servers of a cluster to get the changes of all its groups.
+
+var hooks = struct {
+	sync.RWMutex
+	webhooks map[string]worker.Webhook
+}{webhooks: make(map[string]worker.Webhook)}
+
+// LoadWebhooks reads the webhooks from the file of --webhooks.
+func LoadWebhooks() error {
+	if Config.Webhooks == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(Config.Webhooks)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	webhooks := make(map[string]worker.Webhook)
+	if err := json.Unmarshal(b, &webhooks); err != nil {
+		return x.Wrapf(err, "While reading webhooks from %v", Config.Webhooks)
+	}
+	for id, h := range webhooks {
+		if !validQueryId(id) {
+			return x.Errorf("Invalid webhook id: %q", id)
+		}
+		if err := worker.ValidateWebhook(h); err != nil {
+			return x.Wrapf(err, "While reading webhook %q", id)
+		}
+	}
+	hooks.Lock()
+	hooks.webhooks = webhooks
+	worker.SetWebhooks(webhooks)
+	hooks.Unlock()
+	return nil
+}
+
+// saveWebhooks writes the webhooks, and has the changes posted to them. It's called with hooks
+// locked.
+func saveWebhooks() error {
+	if Config.Webhooks != "" {
+		if err := writeJSONFile(Config.Webhooks, hooks.webhooks); err != nil {
+			return err
+		}
+	}
+	worker.SetWebhooks(hooks.webhooks)
+	return nil
+}
+
+// Webhooks returns all of the webhooks, by id.
+func Webhooks() map[string]worker.Webhook {
+	hooks.RLock()
+	defer hooks.RUnlock()
+	webhooks := make(map[string]worker.Webhook, len(hooks.webhooks))
+	for id, h := range hooks.webhooks {
+		webhooks[id] = h
+	}
+	return webhooks
+}
+
+// SetWebhook registers the webhook h under id, replacing any webhook there.
+func SetWebhook(id string, h worker.Webhook) error {
+	if !validQueryId(id) {
+		return x.Errorf("Invalid webhook id: %q. Ids are made of letters, digits, '_', '-' "+
+			"and '.'", id)
+	}
+	if err := worker.ValidateWebhook(h); err != nil {
+		return err
+	}
+	hooks.Lock()
+	defer hooks.Unlock()
+	old, had := hooks.webhooks[id]
+	hooks.webhooks[id] = h
+	if err := saveWebhooks(); err != nil {
+		if had {
+			hooks.webhooks[id] = old
+		} else {
+			delete(hooks.webhooks, id)
+		}
+		return x.Wrapf(err, "While saving webhooks")
+	}
+	return nil
+}
+
+// DeleteWebhook removes the webhook registered under id.
+func DeleteWebhook(id string) error {
+	hooks.Lock()
+	defer hooks.Unlock()
+	old, ok := hooks.webhooks[id]
+	if !ok {
+		return x.Errorf("No webhook: %q", id)
+	}
+	delete(hooks.webhooks, id)
+	if err := saveWebhooks(); err != nil {
+		hooks.webhooks[id] = old
+		return x.Wrapf(err, "While saving webhooks")
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package elastic is a minimal client for the bulk API of Elasticsearch. It updates the fields of
+// documents, creating those which don't exist, and is meant for Elasticsearch 7 on, where indices
+// don't have mapping types.
+package elastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Update sets the fields of the document Id of Index to the values of Fields. Fields with a nil
+// value are set to null, which leaves them out of searches.
+type Update struct {
+	Index  string
+	Id     string
+	Fields map[string]interface{}
+}
+
+// Client sends requests to the nodes of a cluster, trying the next one when a node can't be
+// reached. It's safe for concurrent use.
+type Client struct {
+	urls []string
+	hc   *http.Client
+}
+
+// NewClient returns a client for the cluster of nodes, the base URLs of some of its nodes, like
+// http://localhost:9200. Requests time out after timeout.
+func NewClient(urls []string, timeout time.Duration) *Client {
+	c := &Client{hc: &http.Client{Timeout: timeout}}
+	for _, u := range urls {
+		c.urls = append(c.urls, strings.TrimRight(u, "/"))
+	}
+	return c
+}
+
+type bulkAction struct {
+	Update struct {
+		Index string `json:"_index"`
+		Id    string `json:"_id"`
+	} `json:"update"`
+}
+
+type bulkDoc struct {
+	Doc    map[string]interface{} `json:"doc"`
+	Upsert bool                   `json:"doc_as_upsert"`
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Id     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+func bulkBody(updates []Update) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, u := range updates {
+		var a bulkAction
+		a.Update.Index, a.Update.Id = u.Index, u.Id
+		if err := enc.Encode(a); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(bulkDoc{Doc: u.Fields, Upsert: true}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Bulk applies the updates, in order, in a single request. It returns an error if any of them
+// failed, although the others are applied.
+func (c *Client) Bulk(updates []Update) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	body, err := bulkBody(updates)
+	if err != nil {
+		return err
+	}
+	for i, u := range c.urls {
+		err = c.bulk(u, body)
+		if _, ok := err.(*bulkError); ok || err == nil || i == len(c.urls)-1 {
+			break
+		}
+	}
+	return err
+}
+
+// bulkError is the error of a request which reached a node.
+type bulkError struct {
+	msg string
+}
+
+func (e *bulkError) Error() string {
+	return e.msg
+}
+
+func (c *Client) bulk(url string, body []byte) error {
+	resp, err := c.hc.Post(url+"/_bulk", "application/x-ndjson", bytes.NewReader(body))
+	if err != nil {
+		return x.Wrapf(err, "While sending bulk request to %s", url)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return x.Wrapf(err, "While reading bulk response from %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &bulkError{fmt.Sprintf("Bulk request to %s failed with %s: %s", url, resp.Status,
+			bytes.TrimSpace(b))}
+	}
+	var res bulkResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return x.Wrapf(err, "While parsing bulk response from %s", url)
+	}
+	if !res.Errors {
+		return nil
+	}
+	for _, item := range res.Items {
+		for _, r := range item {
+			if r.Status >= 300 {
+				return &bulkError{fmt.Sprintf("Update of document %s failed with %d: %s: %s", r.Id,
+					r.Status, r.Error.Type, r.Error.Reason)}
+			}
+		}
+	}
+	return &bulkError{"Bulk request failed"}
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package elastic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulk(t *testing.T) {
+	var body string
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_bulk", r.URL.Path)
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(b)
+		if fail {
+			fmt.Fprint(w, `{"errors":true,"items":[{"update":{"_id":"0x1","status":200}},`+
+				`{"update":{"_id":"0x2","status":400,"error":{"type":"mapper_parsing_exception",`+
+				`"reason":"failed to parse"}}}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"errors":false,"items":[]}`)
+	}))
+	defer srv.Close()
+
+	// The first node can't be reached, so the request goes to the next one.
+	c := NewClient([]string{"http://127.0.0.1:1", srv.URL + "/"}, time.Second)
+	require.NoError(t, c.Bulk([]Update{
+		{Index: "dgraph", Id: "0x1", Fields: map[string]interface{}{"name": "Alice"}},
+		{Index: "dgraph", Id: "0x2", Fields: map[string]interface{}{"name": nil}},
+	}))
+	require.Equal(t, strings.Join([]string{
+		`{"update":{"_index":"dgraph","_id":"0x1"}}`,
+		`{"doc":{"name":"Alice"},"doc_as_upsert":true}`,
+		`{"update":{"_index":"dgraph","_id":"0x2"}}`,
+		`{"doc":{"name":null},"doc_as_upsert":true}`,
+	}, "\n")+"\n", body)
+
+	fail = true
+	err := c.Bulk([]Update{{Index: "dgraph", Id: "0x2", Fields: map[string]interface{}{}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "0x2 failed with 400")
+}
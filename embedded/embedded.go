@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package embedded runs a single node Dgraph as a library, in the process of the program using
+// it, for tests, edge deployments and command line tools which want a graph store without running
+// a server. Queries and mutations are run directly, without going through gRPC or HTTP.
+//
+// The state of Dgraph is global, so only one DB can be open in a process at a time.
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/dgraph"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/query"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type Options struct {
+	// Dir is the folder the data is kept in, with the posting lists in its p folder and the write
+	// ahead log in its w folder.
+	Dir string
+	// MemoryMB is the memory allotted to Dgraph, like --memory_mb. It's 1024 by default.
+	MemoryMB float64
+}
+
+// DB is a Dgraph running in this process.
+type DB struct {
+	closed bool
+}
+
+var running struct {
+	sync.Mutex
+	db *DB
+}
+
+// Open starts Dgraph with the data in opts.Dir, creating it if needed. Errors while opening the
+// stores of the data end the process, like they do for the server.
+func Open(opts Options) (*DB, error) {
+	if opts.Dir == "" {
+		return nil, x.Errorf("No folder given for the data of embedded Dgraph")
+	}
+	if opts.MemoryMB == 0 {
+		opts.MemoryMB = dgraph.MinAllottedMemory
+	}
+	if opts.MemoryMB < dgraph.MinAllottedMemory {
+		return nil, x.Errorf("Embedded Dgraph needs at least %.0f MB of memory",
+			dgraph.MinAllottedMemory)
+	}
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	running.Lock()
+	defer running.Unlock()
+	if running.db != nil {
+		return nil, x.Errorf("Embedded Dgraph is already open in this process")
+	}
+	config := dgraph.GetDefaultEmbeddedConfig()
+	config.PostingDir = filepath.Join(opts.Dir, "p")
+	config.WALDir = filepath.Join(opts.Dir, "w")
+	config.AllottedMemory = opts.MemoryMB
+	dgraph.StartEmbedded(config)
+	running.db = &DB{}
+	return running.db, nil
+}
+
+// Close stops Dgraph, after which the DB can't be used anymore.
+func (db *DB) Close() error {
+	running.Lock()
+	defer running.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	running.db = nil
+	dgraph.DisposeEmbeddedDgraph()
+	return nil
+}
+
+// process runs the query or mutation res.
+func (db *DB) process(ctx context.Context, res *gql.Result) (query.ExecuteResult, error) {
+	running.Lock()
+	closed := db.closed
+	running.Unlock()
+	if closed {
+		return query.ExecuteResult{}, x.Errorf("Embedded Dgraph is closed")
+	}
+	ctx = context.WithValue(ctx, "mutation_allowed", true)
+	l := query.Latency{}
+	qr := query.QueryRequest{Latency: &l, GqlQuery: res}
+	return qr.ProcessWithMutation(ctx)
+}
+
+// Alter adds the predicates of schema, in the format of schema files, to the schema, or changes
+// them.
+func (db *DB) Alter(ctx context.Context, schema string) error {
+	_, err := db.process(ctx, &gql.Result{Mutation: &gql.Mutation{Schema: schema}})
+	return err
+}
+
+// parseNQuads parses the N-Quads of the lines of s, skipping empty lines and comments.
+func parseNQuads(s string) ([]*protos.NQuad, error) {
+	var nqs []*protos.NQuad
+	for _, line := range strings.Split(s, "\n") {
+		nq, err := rdf.Parse(line)
+		if err == rdf.ErrEmpty {
+			continue
+		} else if err != nil {
+			return nil, x.Wrapf(err, "While parsing N-Quad: %q", line)
+		}
+		nqs = append(nqs, &nq)
+	}
+	return nqs, nil
+}
+
+// Mutate sets the edges of the N-Quads of set and deletes those of del, one per line, and returns
+// the uids assigned to their blank nodes, by name, like 0x1f.
+func (db *DB) Mutate(ctx context.Context, set, del string) (map[string]string, error) {
+	m := &gql.Mutation{}
+	var err error
+	if m.Set, err = parseNQuads(set); err != nil {
+		return nil, err
+	}
+	if m.Del, err = parseNQuads(del); err != nil {
+		return nil, err
+	}
+	if len(m.Set) == 0 && len(m.Del) == 0 {
+		return nil, x.Errorf("Empty mutation")
+	}
+	er, err := db.process(ctx, &gql.Result{Mutation: m})
+	if err != nil {
+		return nil, err
+	}
+	return query.ConvertUidsToHex(er.Allocations), nil
+}
+
+// Query runs q, with the values of its variables in vars, and returns its result as the JSON
+// object the data of the responses of /query is, like {"me":[{"name":"Alice"}]}.
+func (db *DB) Query(ctx context.Context, q string, vars map[string]string) ([]byte, error) {
+	res, err := gql.Parse(gql.Request{Str: q, Variables: vars})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Query) == 0 {
+		return nil, x.Errorf("No query blocks in: %q", q)
+	}
+	er, err := db.process(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := query.ToJson(&query.Latency{}, er.Subgraphs, &buf, nil, false); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
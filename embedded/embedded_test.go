@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package embedded
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := Open(Options{Dir: dir})
+	require.NoError(t, err)
+	_, err = Open(Options{Dir: dir})
+	require.Error(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Alter(ctx, "name: string @index(exact) ."))
+	uids, err := db.Mutate(ctx, `
+		_:alice <name> "Alice" .
+		_:bob <name> "Bob" .
+		# Alice knows Bob.
+		_:alice <friend> _:bob .`, "")
+	require.NoError(t, err)
+	require.Len(t, uids, 2)
+
+	q := `query me($name: string) {
+		me(func: eq(name, $name)) {
+			name
+			friend { name }
+		}
+	}`
+	js, err := db.Query(ctx, q, map[string]string{"$name": "Alice"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"me":[{"name":"Alice","friend":[{"name":"Bob"}]}]}`, string(js))
+
+	_, err = db.Mutate(ctx, "", "<"+uids["alice"]+"> <friend> * .")
+	require.NoError(t, err)
+	js, err = db.Query(ctx, `{ me(func: eq(name, "Alice")) { name friend { name } } }`, nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"me":[{"name":"Alice"}]}`, string(js))
+
+	_, err = db.Mutate(ctx, "<alice> <name> .", "")
+	require.Error(t, err)
+	require.NoError(t, db.Close())
+	_, err = db.Query(ctx, `{ me(func: eq(name, "Alice")) { name } }`, nil)
+	require.Error(t, err)
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/dgraph-io/dgraph/lex"
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -64,7 +65,8 @@ type MathTree struct {
 
 func isUnary(f string) bool {
 	return f == "exp" || f == "ln" || f == "u-" || f == "sqrt" ||
-		f == "floor" || f == "ceil" || f == "since"
+		f == "floor" || f == "ceil" || f == "since" || f == "area" || f == "length" ||
+		udf.Arity(f) == 1
 }
 
 func isBinaryMath(f string) bool {
@@ -72,7 +74,7 @@ func isBinaryMath(f string) bool {
 }
 
 func isTernary(f string) bool {
-	return f == "cond"
+	return f == "cond" || udf.Arity(f) == 3
 }
 
 func evalMathStack(opStack, valueStack *mathTreeStack) error {
@@ -119,7 +121,18 @@ func isMathFunc(f string) bool {
 		f == "==" || f == "!=" ||
 		f == "min" || f == "max" || f == "sqrt" ||
 		f == "pow" || f == "logbase" || f == "floor" || f == "ceil" ||
-		f == "since"
+		f == "since" || f == "area" || f == "length"
+}
+
+// udfPrecedence is the precedence of user defined scalar functions, below those of the functions
+// of math blocks and above their operators.
+const udfPrecedence = 80
+
+func mathPrecedence(op string) int {
+	if udf.Arity(op) > 0 {
+		return udfPrecedence
+	}
+	return mathOpPrecedence[op]
 }
 
 func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
@@ -140,7 +153,13 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 	for it.Next() {
 		item := it.Item()
 		lval := strings.ToLower(item.Val)
-		if isMathFunc(lval) {
+		isFunc := isMathFunc(lval)
+		if !isFunc && item.Typ == itemName && udf.Arity(lval) > 0 {
+			// Only calls are of user defined functions, so that variables can have their names.
+			peekIt, err := it.Peek(1)
+			isFunc = err == nil && peekIt[0].Typ == itemLeftRound
+		}
+		if isFunc {
 			op := lval
 			it.Prev()
 			lastItem := it.Item()
@@ -149,12 +168,12 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 				(lastItem.Val == "(" || lastItem.Val == "," || isBinaryMath(lastItem.Val)) {
 				op = "u-" // This is a unary -
 			}
-			opPred := mathOpPrecedence[op]
+			opPred := mathPrecedence(op)
 			x.AssertTruef(opPred > 0, "Expected opPred > 0 for %v: %d", op, opPred)
 			// Evaluate the stack until we see an operator with strictly lower pred.
 			for !opStack.empty() {
 				topOp := opStack.peek()
-				if mathOpPrecedence[topOp.Fn] < opPred {
+				if mathPrecedence(topOp.Fn) < opPred {
 					break
 				}
 				err := evalMathStack(opStack, valueStack)
@@ -316,10 +335,11 @@ func (t *MathTree) stringHelper(buf *bytes.Buffer) {
 	switch t.Fn {
 	case "+", "-", "/", "*", "%", "exp", "ln", "cond", "min",
 		"sqrt", "max", "<", ">", "<=", ">=", "==", "!=", "u-",
-		"logbase", "pow":
+		"logbase", "pow", "area", "length":
 		buf.WriteString(t.Fn)
 	default:
-		x.Fatalf("Unknown operator: %q", t.Fn)
+		x.AssertTruef(udf.Arity(t.Fn) > 0, "Unknown operator: %q", t.Fn)
+		buf.WriteString(t.Fn)
 	}
 
 	for _, c := range t.Child {
@@ -50,6 +50,7 @@ type GraphQuery struct {
 	NeedsVar   []VarContext
 	Func       *Function
 	Expand     string // Which variable to expand with.
+	ExpandType bool   // Whether Expand is the name of a type rather than a variable.
 
 	Args         map[string]string
 	Children     []*GraphQuery
@@ -81,6 +82,10 @@ type GraphQuery struct {
 type AttrLang struct {
 	Attr  string
 	Langs []string
+	// Cell is set for cell(attr, level) in @groupby, which groups the geo values of Attr by the S2
+	// cell of level CellLevel they fall in.
+	Cell      bool
+	CellLevel int
 }
 
 // pair denotes the key value pair that is part of the GraphQL query root in parenthesis.
@@ -161,6 +166,8 @@ func init() {
 		"floor":   105,
 		"ceil":    104,
 		"since":   103,
+		"area":    102,
+		"length":  101,
 		"exp":     100,
 		"ln":      99,
 		"sqrt":    98,
@@ -194,6 +201,12 @@ func (f *Function) IsPasswordVerifier() bool {
 	return f.Name == "checkpwd"
 }
 
+// IsDistance returns if f is a near function in a block, which gives the distances of the nodes of
+// the block as a value variable.
+func (f *Function) IsDistance() bool {
+	return f.Name == "near"
+}
+
 // DebugPrint is useful for debugging.
 func (gq *GraphQuery) DebugPrint(prefix string) {
 	x.Printf("%s[%x %q %q]\n", prefix, gq.UID, gq.Attr, gq.Alias)
@@ -338,6 +351,20 @@ func parseQueryWithGqlVars(r Request) (string, varMap, error) {
 	return q.Query, vm, nil
 }
 
+// Text returns the text of the query of r and its variables, without parsing the query. Requests
+// over HTTP can send both in a JSON object.
+func (r Request) Text() (string, map[string]string) {
+	q, vm, err := parseQueryWithGqlVars(r)
+	if err != nil {
+		return r.Str, r.Variables
+	}
+	vars := make(map[string]string, len(vm))
+	for k, v := range vm {
+		vars[k] = v.Value
+	}
+	return q, vars
+}
+
 func checkValueType(vm varMap) error {
 	for k, v := range vm {
 		typ := v.Type
@@ -596,6 +623,21 @@ func Parse(r Request) (res Result, rerr error) {
 	return res, nil
 }
 
+// ParseFilter parses the filter s, as given in @filter(s).
+func ParseFilter(s string) (*FilterTree, error) {
+	// The filter is parsed in a query of its own, which it can't break out of.
+	res, err := Parse(Request{Str: "{ f(func: uid(0x1)) @filter(" + s + ") { _uid_ } }"})
+	if err != nil {
+		return nil, x.Wrapf(err, "Invalid filter: %q", s)
+	}
+	if len(res.Query) != 1 || res.Mutation != nil || res.Schema != nil ||
+		len(res.Query[0].Children) != 1 || res.Query[0].Children[0].Attr != "_uid_" ||
+		res.Query[0].Filter == nil {
+		return nil, x.Errorf("Invalid filter: %q", s)
+	}
+	return res.Query[0].Filter, nil
+}
+
 func flatten(vl []*Vars) (needs []string, defines []string) {
 	needs, defines = make([]string, 0, 10), make([]string, 0, 10)
 	for _, it := range vl {
@@ -778,7 +820,9 @@ L:
 				gq.Cascade = true
 			case "groupby":
 				gq.IsGroupby = true
-				parseGroupby(it, gq)
+				if err := parseGroupby(it, gq); err != nil {
+					return nil, err
+				}
 			case "ignorereflex":
 				gq.IgnoreReflex = true
 			default:
@@ -1371,7 +1415,8 @@ func validFuncName(name string) bool {
 	}
 
 	switch name {
-	case "regexp", "anyofterms", "allofterms", "alloftext", "anyoftext", "has", "uid", "uid_in":
+	case "regexp", "anyofterms", "allofterms", "alloftext", "anyoftext", "has", "uid", "uid_in",
+		"type":
 		return true
 	}
 	return false
@@ -1427,6 +1472,19 @@ L:
 					if len(f.NeedsVar) > 1 {
 						return nil, x.Errorf("Multiple variables not allowed in a function")
 					}
+					if isGeoFunc(g.Name) && len(g.Attr) > 0 {
+						// E.g. within(loc, val(a)), which takes the geometries of a in place of
+						// a literal one.
+						if !isGeoJoinFunc(g.Name) {
+							return nil, x.Errorf("Value variable not allowed in function %s. Only "+
+								"within, contains and intersects can take one.", g.Name)
+						}
+						// The name of val was taken for an argument before we knew it's a function.
+						g.Args[len(g.Args)-1] = f.NeedsVar[0].Name
+						g.NeedsVar = append(g.NeedsVar, f.NeedsVar...)
+						expectArg = false
+						continue
+					}
 					g.Attr = value
 					g.Args = append(g.Args, f.NeedsVar[0].Name)
 					g.NeedsVar = append(g.NeedsVar, f.NeedsVar...)
@@ -1784,9 +1842,25 @@ func parseGroupby(it *lex.ItemIterator, gq *GraphQuery) error {
 			if !expectArg {
 				return x.Errorf("Expected a comma or right round but got: %v", item.Val)
 			}
+			items, err := it.Peek(1)
+			if err == nil && items[0].Typ == itemLeftRound && strings.ToLower(item.Val) == "cell" {
+				attrLang, err := parseGroupbyCell(it)
+				if err != nil {
+					return err
+				}
+				for _, a := range gq.GroupbyAttrs {
+					if a.Cell && a.Attr == attrLang.Attr {
+						return x.Errorf("Only one cell of %s allowed in groupby", a.Attr)
+					}
+				}
+				gq.GroupbyAttrs = append(gq.GroupbyAttrs, attrLang)
+				count++
+				expectArg = false
+				continue
+			}
 			attr := collectName(it, item.Val)
 			var langs []string
-			items, err := it.Peek(1)
+			items, err = it.Peek(1)
 			if err == nil && items[0].Typ == itemAt {
 				it.Next() // consume '@'
 				it.Next() // move forward
@@ -1813,6 +1887,34 @@ func parseGroupby(it *lex.ItemIterator, gq *GraphQuery) error {
 	return nil
 }
 
+// parseGroupbyCell parses cell(attr, level) in the groupby directive, after the name of the function.
+func parseGroupbyCell(it *lex.ItemIterator) (AttrLang, error) {
+	var items []lex.Item
+	it.Next() // consume '('
+	for it.Next() {
+		item := it.Item()
+		if item.Typ == itemRightRound {
+			break
+		}
+		if item.Typ != itemComma {
+			items = append(items, item)
+		}
+	}
+	if len(items) != 2 || items[0].Typ != itemName || items[1].Typ != itemName {
+		return AttrLang{}, x.Errorf("Expected a predicate and a level in cell() of groupby")
+	}
+	level, err := strconv.Atoi(items[1].Val)
+	if err != nil || level < 0 || level > 30 {
+		return AttrLang{}, x.Errorf("Level of cell() in groupby should be an integer from 0 to 30."+
+			" Got: %v", items[1].Val)
+	}
+	return AttrLang{
+		Attr:      items[0].Val,
+		Cell:      true,
+		CellLevel: level,
+	}, nil
+}
+
 // parseFilter parses the filter directive to produce a QueryFilter / parse tree.
 func parseFilter(it *lex.ItemIterator) (*FilterTree, error) {
 	it.Next()
@@ -2022,7 +2124,9 @@ func parseDirective(it *lex.ItemIterator, curp *GraphQuery) error {
 			curp.Filter = filter
 		case "groupby":
 			curp.IsGroupby = true
-			parseGroupby(it, curp)
+			if err := parseGroupby(it, curp); err != nil {
+				return err
+			}
 		default:
 			return x.Errorf("Unknown directive [%s]", item.Val)
 		}
@@ -2345,6 +2449,23 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 				gq.Children = append(gq.Children, child)
 				curp = nil
 				continue
+			} else if valLower == "near" {
+				if varName == "" {
+					return x.Errorf("Function near should be used with a variable")
+				}
+				child := &GraphQuery{
+					Args: make(map[string]string),
+					Var:  varName,
+				}
+				varName, alias = "", ""
+				it.Prev()
+				if child.Func, err = parseFunction(it, gq); err != nil {
+					return err
+				}
+				child.Attr = child.Func.Attr
+				gq.Children = append(gq.Children, child)
+				curp = nil
+				continue
 			} else if isAggregator(valLower) {
 				child := &GraphQuery{
 					Attr:       value,
@@ -2449,6 +2570,10 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 					child.Expand = child.NeedsVar[len(child.NeedsVar)-1].Name
 				} else if item.Val == "_all_" {
 					child.Expand = "_all_"
+				} else if item.Typ == itemName {
+					// The fields of a declared type, like expand(Person).
+					child.Expand = item.Val
+					child.ExpandType = true
 				} else {
 					return x.Errorf("Invalid argument %v in expand()", item.Val)
 				}
@@ -2626,7 +2751,14 @@ func isMathBlock(name string) bool {
 }
 
 func isGeoFunc(name string) bool {
-	return name == "near" || name == "contains" || name == "within" || name == "intersects"
+	return name == "near" || name == "nearest" || name == "contains" || name == "within" ||
+		name == "withinbox" || name == "intersects" || name == "disjoint" || name == "overlaps"
+}
+
+// isGeoJoinFunc returns if the geo function can take a value variable of geometries, to match the
+// nodes related to any of them.
+func isGeoJoinFunc(name string) bool {
+	return name == "within" || name == "contains" || name == "intersects"
 }
 
 func isInequalityFn(name string) bool {
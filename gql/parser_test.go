@@ -393,6 +393,28 @@ func TestParseQueryWithVarValAggNested4(t *testing.T) {
 		res.Query[1].Children[0].Children[3].MathExp.debugString())
 }
 
+func TestParseQueryWithVarValAggGeo(t *testing.T) {
+	query := `
+	{
+		me(func: uid(L), orderdesc: val(d)) {
+			name
+		}
+
+		var(func: uid(0x0a)) {
+			L as parcels {
+				a as boundary
+				b as road
+				d as math(area(a) / 10000 + length(b))
+			}
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.EqualValues(t, "(+ (/ (area a) 1E+04) (length b))",
+		res.Query[1].Children[0].Children[2].MathExp.debugString())
+}
+
 func TestParseQueryWithVarValAggLogSqrt(t *testing.T) {
 	query := `
 	{
@@ -2351,6 +2373,35 @@ func TestParseFilter_Geo1(t *testing.T) {
 	require.Equal(t, []string{"[-1.12,2.0123]", "100.123"}, resp.Query[0].Children[0].Filter.Func.Args)
 }
 
+func TestParseNearVar(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x0a)) {
+			d as near(loc, [-1.12 , 2.0123 ], 100.123 )
+			name
+			dist: val(d)
+		}
+	}
+`
+	resp, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	child := resp.Query[0].Children[0]
+	require.Equal(t, "d", child.Var)
+	require.Equal(t, "loc", child.Attr)
+	require.True(t, child.Func.IsDistance())
+	require.Equal(t, []string{"[-1.12,2.0123]", "100.123"}, child.Func.Args)
+
+	query = `
+	query {
+		me(func: uid(0x0a)) {
+			near(loc, [-1.12 , 2.0123 ], 100.123 )
+		}
+	}
+`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+}
+
 func TestParseFilter_Geo2(t *testing.T) {
 	query := `
 	query {
@@ -2403,6 +2454,39 @@ func TestParseFilter_Geo4(t *testing.T) {
 	require.Contains(t, err.Error(), "\"]\"")
 }
 
+func TestParseFilter_GeoVar(t *testing.T) {
+	query := `
+	{
+		var(func: anyofterms(name, "Bay")) {
+			region as loc
+		}
+		me(func: within(loc, val(region))) {
+			name
+		}
+	}
+`
+	resp, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	f := resp.Query[1].Func
+	require.Equal(t, "loc", f.Attr)
+	require.Equal(t, []string{"region"}, f.Args)
+	require.Equal(t, []VarContext{{Name: "region", Typ: VALUE_VAR}}, f.NeedsVar)
+
+	query = `
+	{
+		var(func: anyofterms(name, "Bay")) {
+			center as loc
+		}
+		me(func: near(loc, val(center), 1000)) {
+			name
+		}
+	}
+`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Value variable not allowed in function near")
+}
+
 // Test if empty brackets will lead to errors.
 func TestParseFilter_emptyargument(t *testing.T) {
 	query := `
@@ -2815,6 +2899,50 @@ func TestMutationSchema(t *testing.T) {
 	require.Equal(t, res.Mutation.Schema, "\n           name: string @index(exact)\n\t\t")
 }
 
+func TestMutationSchemaTypes(t *testing.T) {
+	query := `
+	mutation {
+		schema {
+			name: string @index(exact) .
+			type Person { name, friend }
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.Equal(t, "\n\t\t\tname: string @index(exact) .\n\t\t\ttype Person { name, friend }\n\t\t",
+		res.Mutation.Schema)
+
+	query = `
+	mutation {
+		set {
+			<0x1> <name> { .
+		}
+	}
+	`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+}
+
+func TestParseTypeFunctions(t *testing.T) {
+	query := `
+	{
+		me(func: type(Person)) @filter(type(Company)) {
+			expand(Person)
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	gq := res.Query[0]
+	require.Equal(t, "type", gq.Func.Name)
+	require.Equal(t, "Person", gq.Func.Attr)
+	require.Equal(t, "type", gq.Filter.Func.Name)
+	require.Equal(t, "Company", gq.Filter.Func.Attr)
+	require.Equal(t, "Person", gq.Children[0].Expand)
+	require.True(t, gq.Children[0].ExpandType)
+}
+
 func TestLangs(t *testing.T) {
 	query := `
 	query {
@@ -4157,3 +4285,52 @@ func TestEmptyFunction(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "Got empty attr for function: [allofterms]")
 }
+
+func TestParseFilter(t *testing.T) {
+	ft, err := ParseFilter(`uid_in(org, 0x10) or eq(owner, "alice")`)
+	require.NoError(t, err)
+	require.Equal(t, "or", ft.Op)
+	require.Equal(t, 2, len(ft.Child))
+	require.Equal(t, "org", ft.Child[0].Func.Attr)
+	require.Equal(t, "owner", ft.Child[1].Func.Attr)
+
+	_, err = ParseFilter(`eq(owner, "alice"`)
+	require.Error(t, err)
+	_, err = ParseFilter(`has(a)) { _uid_ } } { g(func: uid(0x1)) @filter(has(b)`)
+	require.Error(t, err)
+}
+
+func TestParseGroupbyCell(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x1)) {
+			friends @groupby(cell(loc, 12), name) {
+				count(_uid_)
+			}
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	attrs := res.Query[0].Children[0].GroupbyAttrs
+	require.Equal(t, []AttrLang{{Attr: "loc", Cell: true, CellLevel: 12}, {Attr: "name"}}, attrs)
+}
+
+func TestParseGroupbyCellError(t *testing.T) {
+	for q, msg := range map[string]string{
+		"cell(loc)":                   "Expected a predicate and a level",
+		"cell(loc, 31)":               "from 0 to 30",
+		"cell(loc, 8), cell(loc, 10)": "Only one cell of loc",
+	} {
+		query := `
+		query {
+			me(func: uid(0x1)) @groupby(` + q + `) {
+				count(_uid_)
+			}
+		}
+	`
+		_, err := Parse(Request{Str: query, Http: true})
+		require.Error(t, err, q)
+		require.Contains(t, err.Error(), msg, q)
+	}
+}
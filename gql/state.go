@@ -18,7 +18,11 @@
 // Package gql is responsible for lexing and parsing a GraphQL query/mutation.
 package gql
 
-import "github.com/dgraph-io/dgraph/lex"
+import (
+	"strings"
+
+	"github.com/dgraph-io/dgraph/lex"
+)
 
 const (
 	leftCurl    = '{'
@@ -397,6 +401,12 @@ func lexNameMutation(l *lex.Lexer) lex.StateFn {
 	return l.Mode
 }
 
+// inSchemaMutation returns whether the mutation text being lexed is that of a schema operation,
+// whose declarations of types have blocks of their own.
+func inSchemaMutation(l *lex.Lexer) bool {
+	return strings.HasSuffix(strings.TrimRight(l.Input[:l.Start], " \t\r\n{"), "schema")
+}
+
 // lexTextMutation lexes and absorbs the text inside a mutation operation block.
 func lexTextMutation(l *lex.Lexer) lex.StateFn {
 	for {
@@ -408,12 +418,21 @@ func lexTextMutation(l *lex.Lexer) lex.StateFn {
 			return lexMutationValue
 		}
 		if r == leftCurl {
-			return l.Errorf("Invalid character '{' inside mutation text")
+			if !inSchemaMutation(l) {
+				return l.Errorf("Invalid character '{' inside mutation text")
+			}
+			l.Depth++
+			continue
 		}
 		if r != rightCurl {
 			// Absorb everything until we find '}'.
 			continue
 		}
+		if l.Depth > 2 {
+			// The end of a block of the text.
+			l.Depth--
+			continue
+		}
 		l.Backup()
 		l.Emit(itemMutationContent)
 		break
@@ -0,0 +1,689 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package graphql serves standard GraphQL, with a schema generated from the Dgraph schema.
+// Operations are translated to GraphQL+- queries and mutations, which are run by a Runner.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// Runner runs the queries and mutations that GraphQL operations are translated to.
+type Runner interface {
+	// Schema returns the schema of all predicates.
+	Schema(ctx context.Context) ([]*protos.SchemaNode, error)
+	// Query runs a GraphQL+- query with the given variables, and returns its JSON response.
+	Query(ctx context.Context, query string, vars map[string]string) ([]byte, error)
+	// Mutate runs a mutation, and returns the uids assigned to its blank nodes.
+	Mutate(ctx context.Context, m *protos.Mutation) (map[string]uint64, error)
+}
+
+// Request is a GraphQL request, as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is a GraphQL response. Data is left out if the request couldn't be executed.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(loc location, format string, args ...interface{}) *Error {
+	e := &Error{Message: fmt.Sprintf(format, args...)}
+	if loc.Line > 0 {
+		e.Locations = []location{loc}
+	}
+	return e
+}
+
+// object is a JSON object which keeps the order of its keys, as fields are in the order they
+// were selected in.
+type object struct {
+	keys []string
+	vals []interface{}
+}
+
+func (o *object) set(key string, val interface{}) {
+	o.keys = append(o.keys, key)
+	o.vals = append(o.vals, val)
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(o.vals[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(kb)
+		b.WriteByte(':')
+		b.Write(vb)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+type executor struct {
+	ctx  context.Context
+	r    Runner
+	s    *Schema
+	doc  *document
+	vars map[string]interface{}
+	// The variables declared by the operation.
+	declared map[string]bool
+	errs     []*Error
+}
+
+// Execute runs a GraphQL request.
+func Execute(ctx context.Context, r Runner, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return errorResponse(err)
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return errorResponse(err)
+	}
+	nodes, err := r.Schema(ctx)
+	if err != nil {
+		return errorResponse(err)
+	}
+	e := &executor{ctx: ctx, r: r, s: NewSchema(nodes), doc: doc}
+	if e.vars, err = e.coerceVars(op, req.Variables); err != nil {
+		return errorResponse(err)
+	}
+
+	var root *typeDef
+	switch op.kind {
+	case "query":
+		root = e.s.query
+	case "mutation":
+		root = e.s.mutation
+	default:
+		return errorResponse(newError(op.loc, "Subscriptions aren't supported."))
+	}
+	groups, err := e.collect(root, op.sel)
+	if err != nil {
+		return errorResponse(err)
+	}
+	// Selections are checked before anything runs, so that mutations don't run in part.
+	if err := e.validate(root, groups); err != nil {
+		return errorResponse(err)
+	}
+	var data *object
+	if op.kind == "query" {
+		data = e.query(groups)
+	} else {
+		data = e.mutation(groups)
+	}
+	return &Response{Data: data, Errors: e.errs}
+}
+
+func errorResponse(err error) *Response {
+	if e, ok := err.(*Error); ok {
+		return &Response{Errors: []*Error{e}}
+	}
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+// addError records an error of the field at path.
+func (e *executor) addError(err error, path []interface{}) {
+	ge, ok := err.(*Error)
+	if !ok {
+		ge = &Error{Message: err.Error()}
+	}
+	if ge.Path == nil {
+		ge.Path = append([]interface{}(nil), path...)
+	}
+	e.errs = append(e.errs, ge)
+}
+
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, newError(location{}, "An operation name is required, as the "+
+				"document has several operations.")
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, newError(location{}, "Unknown operation named %q.", name)
+}
+
+// fieldGroup is the fields selected with the same response key, which are merged.
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+func (g *fieldGroup) first() *field {
+	return g.fields[0]
+}
+
+func (g *fieldGroup) sel() []selection {
+	var sel []selection
+	for _, f := range g.fields {
+		sel = append(sel, f.sel...)
+	}
+	return sel
+}
+
+// collect collects the fields selected on an object of type t, as the spec says.
+func (e *executor) collect(t *typeDef, sel []selection) ([]*fieldGroup, error) {
+	var groups []*fieldGroup
+	index := make(map[string]*fieldGroup)
+	err := e.collectInto(t, sel, &groups, index, make(map[string]bool))
+	return groups, err
+}
+
+func (e *executor) collectInto(t *typeDef, sel []selection, groups *[]*fieldGroup,
+	index map[string]*fieldGroup, visited map[string]bool) error {
+	for _, s := range sel {
+		switch s := s.(type) {
+		case *field:
+			if ok, err := e.included(s.directives); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			g, ok := index[s.key()]
+			if !ok {
+				g = &fieldGroup{key: s.key()}
+				index[g.key] = g
+				*groups = append(*groups, g)
+			} else if g.first().name != s.name {
+				return newError(s.loc, "Fields %q conflict because %s and %s are different "+
+					"fields.", g.key, g.first().name, s.name)
+			}
+			g.fields = append(g.fields, s)
+		case *fragmentSpread:
+			if ok, err := e.included(s.directives); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if visited[s.name] {
+				continue
+			}
+			visited[s.name] = true
+			f, ok := e.doc.fragments[s.name]
+			if !ok {
+				return newError(s.loc, "Unknown fragment %q.", s.name)
+			}
+			if ok, err := e.applies(f.on, f.loc, t); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if err := e.collectInto(t, f.sel, groups, index, visited); err != nil {
+				return err
+			}
+		case *inlineFragment:
+			if ok, err := e.included(s.directives); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if ok, err := e.applies(s.on, s.loc, t); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if err := e.collectInto(t, s.sel, groups, index, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applies returns whether a fragment on the given type applies to an object of type t.
+func (e *executor) applies(on string, loc location, t *typeDef) (bool, error) {
+	if on == "" || on == t.name {
+		return true, nil
+	}
+	if _, ok := e.s.types[on]; !ok {
+		return false, newError(loc, "Unknown type %q.", on)
+	}
+	return false, nil
+}
+
+// included evaluates the @skip and @include directives.
+func (e *executor) included(ds []*directive) (bool, error) {
+	for _, d := range ds {
+		if d.name != "skip" && d.name != "include" {
+			return false, newError(d.loc, "Unknown directive %q.", d.name)
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return false, newError(d.loc, "Directive %q takes one argument, if.", d.name)
+		}
+		v, err := e.literal(d.args[0].val, nonNull(booleanType))
+		if err != nil {
+			return false, err
+		}
+		if v.(bool) == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// validate checks that the fields selected on an object of type t exist, along with their
+// arguments and sub selections.
+func (e *executor) validate(t *typeDef, groups []*fieldGroup) error {
+	for _, g := range groups {
+		f := g.first()
+		if f.name == "__typename" {
+			if len(f.sel) > 0 {
+				return newError(f.loc, "Field \"__typename\" can't have a selection.")
+			}
+			continue
+		}
+		def := t.field(f.name)
+		if def == nil && t == e.s.query {
+			switch f.name {
+			case "__schema":
+				def = schemaField
+			case "__type":
+				def = typeField
+			}
+		}
+		if def == nil {
+			return newError(f.loc, "Cannot query field %q on type %q.", f.name, t.name)
+		}
+		for _, gf := range g.fields {
+			if _, err := e.args(gf, def); err != nil {
+				return err
+			}
+		}
+		named := def.typ.named()
+		if named.kind != kindObject {
+			if len(f.sel) > 0 {
+				return newError(f.loc, "Field %q of type %q can't have a selection.",
+					f.name, def.typ)
+			}
+			continue
+		}
+		if len(f.sel) == 0 {
+			return newError(f.loc, "Field %q of type %q must have a selection of subfields.",
+				f.name, def.typ)
+		}
+		// The types of introspection objects are known as they are resolved.
+		if named == typeIntro && f.name != "__type" {
+			continue
+		}
+		sub, err := e.collect(named, g.sel())
+		if err != nil {
+			return err
+		}
+		if err := e.validate(named, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inputType returns the type of a variable, which has to be an input type.
+func (e *executor) inputType(r *typeRef, loc location) (*typeDef, error) {
+	var t *typeDef
+	if r.elem != nil {
+		elem, err := e.inputType(r.elem, loc)
+		if err != nil {
+			return nil, err
+		}
+		t = listOf(elem)
+	} else {
+		t = e.s.types[r.name]
+		if t == nil {
+			return nil, newError(loc, "Unknown type %q.", r.name)
+		}
+		if t.kind == kindObject {
+			return nil, newError(loc, "Type %q isn't an input type.", r.name)
+		}
+	}
+	if r.nonNull {
+		t = nonNull(t)
+	}
+	return t, nil
+}
+
+// coerceVars checks the values of variables against the types declared for them.
+func (e *executor) coerceVars(op *operation, in map[string]interface{}) (
+	map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	e.declared = make(map[string]bool)
+	for _, v := range op.vars {
+		if e.declared[v.name] {
+			return nil, newError(v.loc, "There can be only one variable named \"$%s\".", v.name)
+		}
+		e.declared[v.name] = true
+		t, err := e.inputType(v.typ, v.loc)
+		if err != nil {
+			return nil, err
+		}
+		val, ok := in[v.name]
+		if !ok && v.def != nil {
+			val, err = e.literal(v.def, t)
+			if err != nil {
+				return nil, err
+			}
+			vars[v.name] = val
+			continue
+		}
+		if !ok {
+			if t.kind == kindNonNull {
+				return nil, newError(v.loc, "Variable \"$%s\" of type %q is required.",
+					v.name, v.typ)
+			}
+			continue
+		}
+		if vars[v.name], err = coerce(val, t); err != nil {
+			return nil, newError(v.loc, "Variable \"$%s\" got invalid value: %v", v.name, err)
+		}
+	}
+	return vars, nil
+}
+
+// args returns the arguments of a field, coerced to their types.
+func (e *executor) args(f *field, def *fieldDef) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	for _, a := range f.args {
+		var ad *inputValue
+		for _, d := range def.args {
+			if d.name == a.name {
+				ad = d
+			}
+		}
+		if ad == nil {
+			return nil, newError(a.loc, "Unknown argument %q on field %q.", a.name, f.name)
+		}
+		v, err := e.literal(a.val, ad.typ)
+		if err != nil {
+			return nil, newError(a.loc, "Argument %q of field %q has an invalid value: %v",
+				a.name, f.name, err)
+		}
+		if v != nil {
+			args[a.name] = v
+		}
+	}
+	for _, d := range def.args {
+		if _, ok := args[d.name]; !ok && d.typ.kind == kindNonNull {
+			return nil, newError(f.loc, "Argument %q of type %q is required on field %q.",
+				d.name, d.typ, f.name)
+		}
+	}
+	return args, nil
+}
+
+// literal returns the value of v, with variables substituted, coerced to type t.
+func (e *executor) literal(v *value, t *typeDef) (interface{}, error) {
+	val, err := e.toGo(v)
+	if err != nil {
+		return nil, err
+	}
+	return coerce(val, t)
+}
+
+func (e *executor) toGo(v *value) (interface{}, error) {
+	switch v.kind {
+	case valVar:
+		if !e.declared[v.raw] {
+			return nil, newError(v.loc, "Variable \"$%s\" is not defined.", v.raw)
+		}
+		return e.vars[v.raw], nil
+	case valInt:
+		i, err := strconv.ParseInt(v.raw, 10, 64)
+		if err != nil {
+			return nil, newError(v.loc, "Integer %s is out of range.", v.raw)
+		}
+		return i, nil
+	case valFloat:
+		return strconv.ParseFloat(v.raw, 64)
+	case valString, valEnum:
+		return v.raw, nil
+	case valBool:
+		return v.raw == "true", nil
+	case valNull:
+		return nil, nil
+	case valList:
+		l := []interface{}{}
+		for _, elem := range v.list {
+			ev, err := e.toGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, ev)
+		}
+		return l, nil
+	}
+	m := make(map[string]interface{})
+	for _, f := range v.fields {
+		fv, err := e.toGo(f.val)
+		if err != nil {
+			return nil, err
+		}
+		m[f.name] = fv
+	}
+	return m, nil
+}
+
+// coerce coerces an input value, as decoded from JSON or converted from a literal, to type t.
+// Ints are int64, Floats float64, and IDs, Strings, DateTimes and enums strings.
+func coerce(v interface{}, t *typeDef) (interface{}, error) {
+	if v == nil {
+		if t.kind == kindNonNull {
+			return nil, fmt.Errorf("expected a value of type %s, got null", t)
+		}
+		return nil, nil
+	}
+	switch t.kind {
+	case kindNonNull:
+		return coerce(v, t.of)
+	case kindList:
+		l, ok := v.([]interface{})
+		if !ok {
+			l = []interface{}{v}
+		}
+		out := make([]interface{}, 0, len(l))
+		for _, elem := range l {
+			ev, err := coerce(elem, t.of)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ev)
+		}
+		return out, nil
+	case kindInput:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object of type %s", t.name)
+		}
+		out := make(map[string]interface{})
+		for k, fv := range m {
+			in := t.input(k)
+			if in == nil {
+				return nil, fmt.Errorf("unknown field %q of type %s", k, t.name)
+			}
+			cv, err := coerce(fv, in.typ)
+			if err != nil {
+				return nil, fmt.Errorf("in field %q: %v", k, err)
+			}
+			if cv != nil {
+				out[k] = cv
+			}
+		}
+		for _, in := range t.inputs {
+			if _, ok := out[in.name]; !ok && in.typ.kind == kindNonNull {
+				return nil, fmt.Errorf("field %q of type %s is required", in.name, t.name)
+			}
+		}
+		return out, nil
+	case kindEnum:
+		s, ok := v.(string)
+		if ok {
+			for _, ev := range t.enums {
+				if ev.name == s {
+					return s, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("expected a value of enum %s, got %v", t.name, v)
+	}
+
+	switch t {
+	case intType:
+		f, ok := number(v)
+		if !ok || f != math.Trunc(f) {
+			return nil, fmt.Errorf("expected an Int, got %v", v)
+		}
+		if i, ok := v.(int64); ok {
+			return i, nil
+		}
+		return int64(f), nil
+	case floatType:
+		if f, ok := number(v); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a Float, got %v", v)
+	case booleanType:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a Boolean, got %v", v)
+	case idType:
+		switch id := v.(type) {
+		case string:
+			return id, nil
+		case int64:
+			return strconv.FormatInt(id, 10), nil
+		case json.Number:
+			return id.String(), nil
+		case float64:
+			if id == math.Trunc(id) {
+				return strconv.FormatFloat(id, 'f', -1, 64), nil
+			}
+		}
+		return nil, fmt.Errorf("expected an ID, got %v", v)
+	case geoType:
+		return v, nil
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("expected a %s, got %v", t.name, v)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// completeIntrospection completes the value of an introspection field of type t.
+func (e *executor) completeIntrospection(v interface{}, t *typeDef, sel []selection,
+	path []interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch t.kind {
+	case kindNonNull:
+		return e.completeIntrospection(v, t.of, sel, path)
+	case kindList:
+		out := []interface{}{}
+		for i, elem := range v.([]interface{}) {
+			out = append(out, e.completeIntrospection(elem, t.of, sel, append(path, i)))
+		}
+		return out
+	case kindObject:
+	default:
+		return v
+	}
+
+	it := introspectionType(v)
+	groups, err := e.collect(it, sel)
+	if err != nil {
+		e.addError(err, path)
+		return nil
+	}
+	res := &object{}
+	for _, g := range groups {
+		name := g.first().name
+		if name == "__typename" {
+			res.set(g.key, it.name)
+			continue
+		}
+		def := it.field(name)
+		if def == nil {
+			e.addError(newError(g.first().loc, "Cannot query field %q on type %q.", name,
+				it.name), append(path, g.key))
+			res.set(g.key, nil)
+			continue
+		}
+		if len(g.sel()) == 0 && def.typ.named().kind == kindObject {
+			e.addError(newError(g.first().loc, "Field %q must have a selection of subfields.",
+				name), append(path, g.key))
+			res.set(g.key, nil)
+			continue
+		}
+		fv := e.s.resolveIntrospection(v, name)
+		res.set(g.key, e.completeIntrospection(fv, def.typ, g.sel(), append(path, g.key)))
+	}
+	return res
+}
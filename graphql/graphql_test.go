@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+var testSchema = []*protos.SchemaNode{
+	{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}},
+	{Predicate: "age", Type: "int", Index: true, Tokenizer: []string{"int"}},
+	{Predicate: "friend", Type: "uid", Reverse: true, Count: true},
+	{Predicate: "loc", Type: "geo", Index: true, Tokenizer: []string{"geo"}},
+	{Predicate: "film.nick", Type: "string", List: true},
+	{Predicate: "_predicate_", Type: "string", List: true},
+}
+
+type fakeRunner struct {
+	queries []string
+	vars    []map[string]string
+	results []string
+	muts    []*protos.Mutation
+	allocs  map[string]uint64
+}
+
+func (r *fakeRunner) Schema(ctx context.Context) ([]*protos.SchemaNode, error) {
+	return testSchema, nil
+}
+
+func (r *fakeRunner) Query(ctx context.Context, q string, vars map[string]string) (
+	[]byte, error) {
+	r.queries = append(r.queries, q)
+	r.vars = append(r.vars, vars)
+	res := r.results[0]
+	r.results = r.results[1:]
+	return []byte(res), nil
+}
+
+func (r *fakeRunner) Mutate(ctx context.Context, m *protos.Mutation) (map[string]uint64, error) {
+	r.muts = append(r.muts, m)
+	return r.allocs, nil
+}
+
+func execute(t *testing.T, r Runner, query string, vars map[string]interface{}) string {
+	resp := Execute(context.Background(), r, Request{Query: query, Variables: vars})
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSDL(t *testing.T) {
+	sdl := NewSchema(testSchema).SDL()
+	require.Contains(t, sdl, "type Node {\n  uid: ID!\n")
+	require.Contains(t, sdl, "  age: Int\n")
+	require.Contains(t, sdl, "  film_nick(lang: String): [String]\n")
+	require.Contains(t, sdl, "  friend(filter: NodeFilter, orderasc: NodeOrderable, "+
+		"orderdesc: NodeOrderable, first: Int, offset: Int, after: ID): [Node!]\n")
+	require.Contains(t, sdl, "  friendCount: Int\n")
+	require.Contains(t, sdl, "  reverse_friend(filter: NodeFilter")
+	require.Contains(t, sdl, "input NodeNameFilter {\n  eq: String\n  le: String\n  lt: String\n"+
+		"  ge: String\n  gt: String\n  allofterms: String\n  anyofterms: String\n}\n")
+	require.Contains(t, sdl, "input NodeLocFilter {\n  near: NearFilter\n  within: Geo\n")
+	require.Contains(t, sdl, "  addNode(input: NodeInput!): Node\n")
+	require.NotContains(t, sdl, "_predicate_")
+	// Predicates without indexes can't be filtered on.
+	require.NotContains(t, sdl, "NodeFilm_nickFilter")
+}
+
+func TestQuery(t *testing.T) {
+	r := &fakeRunner{results: []string{`{"data": {"gqb0": [{
+		"_uid_": "0x1", "gq1": "Alice", "gq3": 2, "gq4": 30,
+		"gq2": [{"_uid_": "0x2", "gq0": "Bob"}, {"_uid_": "0x3"}]
+	}]}}`}}
+	out := execute(t, r, `
+		query People($name: String!) {
+			people: nodes(filter: {name: {eq: $name}, age: {ge: 18}}, first: 2) {
+				uid
+				name
+				friends: friend(orderasc: name) { name }
+				...counts
+			}
+		}
+		fragment counts on Node {
+			friendCount
+			... @include(if: true) { age }
+		}`, map[string]interface{}{"name": `Alice "A"`})
+
+	// Filters are applied in the order of the fields of NodeFilter.
+	require.Equal(t, []string{`query gql($gv0: string) {
+  gqb0(func: ge(age, 18), first: 2) @filter((ge(age, 18) AND eq(name, $gv0))) {
+    _uid_
+    gq1: name
+    gq2: friend (orderasc: name) {
+      _uid_
+      gq0: name
+    }
+    gq3: count(friend)
+    gq4: age
+  }
+}`}, r.queries)
+	require.Equal(t, map[string]string{"$gv0": `Alice "A"`}, r.vars[0])
+	require.JSONEq(t, `{"data": {"people": [{
+		"uid": "0x1", "name": "Alice", "friendCount": 2, "age": 30,
+		"friends": [{"name": "Bob"}, {"name": null}]
+	}]}}`, out)
+}
+
+func TestQueryUnion(t *testing.T) {
+	r := &fakeRunner{results: []string{`{"data": {}}`}}
+	out := execute(t, r, `{ nodes(filter: {or: [{has: [age]}, {name: {allofterms: "a b"}}]}) {
+		loc film_nick reverse_friend { uid } } }`, nil)
+	require.Equal(t, `{
+  gqv0 as var(func: has(age))
+  gqv1 as var(func: allofterms(name, $gv0))
+  gqb0(func: uid(gqv0, gqv1)) @filter((has(age) OR allofterms(name, $gv1))) {
+    _uid_
+    gq0: loc
+    gq1: film.nick
+    gq2: ~friend {
+      _uid_
+    }
+  }
+}`, r.queries[0][len("query gql($gv0: string, $gv1: string) "):])
+	require.JSONEq(t, `{"data": {"nodes": []}}`, out)
+}
+
+func TestQueryErrors(t *testing.T) {
+	r := &fakeRunner{}
+	for q, msg := range map[string]string{
+		`{ nodes(filter: {not: {has: [age]}}) { uid } }`:            "needs uids",
+		`{ node(uid: "0x1") { unknown } }`:                          `Cannot query field "unknown" on type "Node"`,
+		`{ node(uid: "0x1") { friend } }`:                           "must have a selection",
+		`{ node(uid: "0x1") { name { uid } } }`:                     "can't have a selection",
+		`{ node { uid } }`:                                          `Argument "uid" of type "ID!" is required`,
+		`{ node(uid: "0x1") { friend(first: "a") { uid } } }`:       "expected an Int",
+		`{ node(uid: $id) { uid } }`:                                `Variable "$id" is not defined`,
+		`{ node(uid: "0x1") { ...f } } fragment f on Node { ...f }`: "can't spread itself",
+		`subscription { node(uid: "0x1") { uid } }`:                 "aren't supported",
+	} {
+		resp := Execute(context.Background(), r, Request{Query: q})
+		require.Len(t, resp.Errors, 1, q)
+		require.Contains(t, resp.Errors[0].Message, msg, q)
+	}
+	require.Empty(t, r.queries)
+
+	resp := Execute(context.Background(), r, Request{Query: "{ node(uid: \"0x1\") {\n uid  ]"})
+	require.Nil(t, resp.Data)
+	require.Equal(t, []location{{Line: 2, Column: 7}}, resp.Errors[0].Locations)
+}
+
+func TestIntrospection(t *testing.T) {
+	out := execute(t, &fakeRunner{}, `{
+		__schema { queryType { name } mutationType { name } subscriptionType { name } }
+		__type(name: "NodeInput") {
+			kind
+			inputFields { name type { kind name ofType { kind name } } }
+		}
+		missing: __type(name: "Missing") { name }
+		__typename
+	}`, nil)
+	var res struct {
+		Data struct {
+			Schema  map[string]interface{} `json:"__schema"`
+			Type    map[string]interface{} `json:"__type"`
+			Missing interface{}            `json:"missing"`
+			Name    string                 `json:"__typename"`
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &res), out)
+	require.Equal(t, map[string]interface{}{
+		"queryType":        map[string]interface{}{"name": "Query"},
+		"mutationType":     map[string]interface{}{"name": "Mutation"},
+		"subscriptionType": nil,
+	}, res.Data.Schema)
+	require.Equal(t, "INPUT_OBJECT", res.Data.Type["kind"])
+	fields := res.Data.Type["inputFields"].([]interface{})
+	require.Equal(t, map[string]interface{}{
+		"name": "friend",
+		"type": map[string]interface{}{"kind": "LIST", "name": nil,
+			"ofType": map[string]interface{}{"kind": "NON_NULL", "name": nil}},
+	}, fields[3])
+	require.Nil(t, res.Data.Missing)
+	require.Equal(t, "Query", res.Data.Name)
+}
+
+func TestMutation(t *testing.T) {
+	r := &fakeRunner{
+		results: []string{`{"data": {"gqb0": [{"_uid_": "0x10", "gq0": "Alice"}]}}`},
+		allocs:  map[string]uint64{"gq0": 0x10, "gq1": 0x11},
+	}
+	out := execute(t, r, `mutation {
+		addNode(input: {name: "Alice", age: 30, friend: [{name: "Bob"}, {uid: "0x5"}],
+			loc: {type: "Point", coordinates: [1.5, 2]}}) { name }
+		deleteNode(uid: "0x7")
+	}`, nil)
+	require.JSONEq(t, `{"data": {"addNode": {"name": "Alice"}, "deleteNode": "0x7"}}`, out)
+
+	require.Len(t, r.muts, 2)
+	var set []string
+	for _, nq := range r.muts[0].Set {
+		obj := nq.ObjectId
+		if nq.ObjectValue != nil {
+			obj = nq.ObjectValue.String()
+		}
+		set = append(set, nq.Subject+" "+nq.Predicate+" "+obj)
+	}
+	require.Equal(t, []string{
+		`_:gq0 age int_val:30 `,
+		`_:gq1 name default_val:"Bob" `,
+		`_:gq0 friend _:gq1`,
+		`_:gq0 friend 0x5`,
+		`_:gq0 loc default_val:"{\"coordinates\":[1.5,2],\"type\":\"Point\"}" `,
+		`_:gq0 name default_val:"Alice" `,
+	}, set)
+	require.Equal(t, int32(types.IntID), r.muts[0].Set[0].ObjectType)
+	require.Equal(t, "0x7", r.muts[1].Del[0].Subject)
+	require.Contains(t, r.queries[0], "gqb0(func: uid(0x10))")
+}
+
+func TestUpdate(t *testing.T) {
+	r := &fakeRunner{results: []string{`{"data": {"gqb0": [{"_uid_": "0x3"}]}}`}}
+	out := execute(t, r, `mutation($set: NodeInput) {
+		updateNode(uid: "0x3", set: $set, remove: [film_nick]) { uid }
+	}`, map[string]interface{}{"set": map[string]interface{}{"film_nick": "x", "age": 4.0}})
+	require.JSONEq(t, `{"data": {"updateNode": {"uid": "0x3"}}}`, out)
+	require.Len(t, r.muts, 2)
+	require.Equal(t, "film.nick", r.muts[0].Del[0].Predicate)
+	require.Len(t, r.muts[1].Set, 2)
+}
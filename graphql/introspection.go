@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"sort"
+	"strings"
+)
+
+type directiveDef struct {
+	name      string
+	desc      string
+	locations []string
+	args      []*inputValue
+}
+
+var directives = []*directiveDef{{
+	name:      "include",
+	desc:      "Includes the field or fragment only when the argument is true.",
+	locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+	args:      []*inputValue{{name: "if", typ: nonNull(booleanType)}},
+}, {
+	name:      "skip",
+	desc:      "Skips the field or fragment when the argument is true.",
+	locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+	args:      []*inputValue{{name: "if", typ: nonNull(booleanType)}},
+}, {
+	name: "deprecated",
+	desc: "Marks an element of the schema as no longer supported.",
+	locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION",
+		"ENUM_VALUE"},
+	args: []*inputValue{{name: "reason", typ: stringType, def: `"No longer supported"`}},
+}}
+
+// introspectionTypes are the types of the __schema and __type fields of Query.
+var introspectionTypes []*typeDef
+
+func enumOf(name string, values ...string) *typeDef {
+	t := &typeDef{kind: kindEnum, name: name}
+	for _, v := range values {
+		t.enums = append(t.enums, &enumValue{name: v})
+	}
+	return t
+}
+
+func rootField(name string, typ *typeDef, args ...*inputValue) *fieldDef {
+	return &fieldDef{name: name, typ: typ, args: args, kind: fieldRoot}
+}
+
+var (
+	schemaIntro     = &typeDef{kind: kindObject, name: "__Schema"}
+	typeIntro       = &typeDef{kind: kindObject, name: "__Type"}
+	fieldIntro      = &typeDef{kind: kindObject, name: "__Field"}
+	inputValueIntro = &typeDef{kind: kindObject, name: "__InputValue"}
+	enumValueIntro  = &typeDef{kind: kindObject, name: "__EnumValue"}
+	directiveIntro  = &typeDef{kind: kindObject, name: "__Directive"}
+	typeKindIntro   = enumOf("__TypeKind", kindScalar, kindObject, "INTERFACE", "UNION",
+		kindEnum, kindInput, kindList, kindNonNull)
+	locationIntro = enumOf("__DirectiveLocation", "QUERY", "MUTATION", "SUBSCRIPTION",
+		"FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT",
+		"VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+		"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT",
+		"INPUT_FIELD_DEFINITION")
+
+	schemaField = rootField("__schema", nonNull(schemaIntro))
+	typeField   = rootField("__type", typeIntro,
+		&inputValue{name: "name", typ: nonNull(stringType)})
+	includeDeprecated = &inputValue{name: "includeDeprecated", typ: booleanType, def: "false"}
+)
+
+func init() {
+	list := func(t *typeDef) *typeDef { return listOf(nonNull(t)) }
+	deprecation := []*fieldDef{
+		rootField("isDeprecated", nonNull(booleanType)),
+		rootField("deprecationReason", stringType),
+	}
+	schemaIntro.fields = []*fieldDef{
+		rootField("description", stringType),
+		rootField("types", nonNull(list(typeIntro))),
+		rootField("queryType", nonNull(typeIntro)),
+		rootField("mutationType", typeIntro),
+		rootField("subscriptionType", typeIntro),
+		rootField("directives", nonNull(list(directiveIntro))),
+	}
+	typeIntro.fields = []*fieldDef{
+		rootField("kind", nonNull(typeKindIntro)),
+		rootField("name", stringType),
+		rootField("description", stringType),
+		rootField("specifiedByURL", stringType),
+		rootField("fields", list(fieldIntro), includeDeprecated),
+		rootField("interfaces", list(typeIntro)),
+		rootField("possibleTypes", list(typeIntro)),
+		rootField("enumValues", list(enumValueIntro), includeDeprecated),
+		rootField("inputFields", list(inputValueIntro), includeDeprecated),
+		rootField("ofType", typeIntro),
+	}
+	fieldIntro.fields = append([]*fieldDef{
+		rootField("name", nonNull(stringType)),
+		rootField("description", stringType),
+		rootField("args", nonNull(list(inputValueIntro)), includeDeprecated),
+		rootField("type", nonNull(typeIntro)),
+	}, deprecation...)
+	inputValueIntro.fields = append([]*fieldDef{
+		rootField("name", nonNull(stringType)),
+		rootField("description", stringType),
+		rootField("type", nonNull(typeIntro)),
+		rootField("defaultValue", stringType),
+	}, deprecation...)
+	enumValueIntro.fields = append([]*fieldDef{
+		rootField("name", nonNull(stringType)),
+		rootField("description", stringType),
+	}, deprecation...)
+	directiveIntro.fields = []*fieldDef{
+		rootField("name", nonNull(stringType)),
+		rootField("description", stringType),
+		rootField("locations", nonNull(list(locationIntro))),
+		rootField("args", nonNull(list(inputValueIntro)), includeDeprecated),
+		rootField("isRepeatable", nonNull(booleanType)),
+	}
+	introspectionTypes = []*typeDef{schemaIntro, typeIntro, fieldIntro, inputValueIntro,
+		enumValueIntro, directiveIntro, typeKindIntro, locationIntro}
+}
+
+// introspectionType returns the type of an object returned by resolveIntrospection.
+func introspectionType(obj interface{}) *typeDef {
+	switch obj.(type) {
+	case *Schema:
+		return schemaIntro
+	case *typeDef:
+		return typeIntro
+	case *fieldDef:
+		return fieldIntro
+	case *inputValue:
+		return inputValueIntro
+	case *enumValue:
+		return enumValueIntro
+	case *directiveDef:
+		return directiveIntro
+	}
+	return nil
+}
+
+func optString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// resolveIntrospection returns the value of a field of an introspection object. Objects are
+// returned as is, and lists as []interface{}.
+func (s *Schema) resolveIntrospection(obj interface{}, name string) interface{} {
+	switch o := obj.(type) {
+	case *Schema:
+		switch name {
+		case "types":
+			var names []string
+			for name := range s.types {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			var types []interface{}
+			for _, name := range names {
+				types = append(types, s.types[name])
+			}
+			return types
+		case "queryType":
+			return s.query
+		case "mutationType":
+			return s.mutation
+		case "directives":
+			var ds []interface{}
+			for _, d := range directives {
+				ds = append(ds, d)
+			}
+			return ds
+		}
+	case *typeDef:
+		switch name {
+		case "kind":
+			return o.kind
+		case "name":
+			return optString(o.name)
+		case "description":
+			return optString(o.desc)
+		case "fields":
+			if o.kind != kindObject {
+				return nil
+			}
+			fields := []interface{}{}
+			for _, f := range o.fields {
+				if !strings.HasPrefix(f.name, "__") {
+					fields = append(fields, f)
+				}
+			}
+			return fields
+		case "interfaces":
+			if o.kind != kindObject {
+				return nil
+			}
+			return []interface{}{}
+		case "enumValues":
+			if o.kind != kindEnum {
+				return nil
+			}
+			values := []interface{}{}
+			for _, v := range o.enums {
+				values = append(values, v)
+			}
+			return values
+		case "inputFields":
+			if o.kind != kindInput {
+				return nil
+			}
+			return inputList(o.inputs)
+		case "ofType":
+			if o.of == nil {
+				return nil
+			}
+			return o.of
+		}
+	case *fieldDef:
+		switch name {
+		case "name":
+			return o.name
+		case "description":
+			return optString(o.desc)
+		case "args":
+			return inputList(o.args)
+		case "type":
+			return o.typ
+		case "isDeprecated":
+			return false
+		}
+	case *inputValue:
+		switch name {
+		case "name":
+			return o.name
+		case "description":
+			return optString(o.desc)
+		case "type":
+			return o.typ
+		case "defaultValue":
+			return optString(o.def)
+		case "isDeprecated":
+			return false
+		}
+	case *enumValue:
+		switch name {
+		case "name":
+			return o.name
+		case "description":
+			return optString(o.desc)
+		case "isDeprecated":
+			return false
+		}
+	case *directiveDef:
+		switch name {
+		case "name":
+			return o.name
+		case "description":
+			return optString(o.desc)
+		case "locations":
+			var locs []interface{}
+			for _, l := range o.locations {
+				locs = append(locs, l)
+			}
+			return locs
+		case "args":
+			return inputList(o.args)
+		case "isRepeatable":
+			return false
+		}
+	}
+	return nil
+}
+
+func inputList(in []*inputValue) []interface{} {
+	l := []interface{}{}
+	for _, v := range in {
+		l = append(l, v)
+	}
+	return l
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// nquads builds the N-Quads which set the values of nodes.
+type nquads struct {
+	s     *Schema
+	set   []*protos.NQuad
+	blank int
+}
+
+// node adds the N-Quads for a NodeInput, and returns the subject of the node. A node without a
+// uid is a new one, and its subject is a blank node.
+func (n *nquads) node(in map[string]interface{}) (string, error) {
+	var subject string
+	if id, ok := in["uid"]; ok {
+		uid, err := parseUid(id.(string))
+		if err != nil {
+			return "", err
+		}
+		subject = fmt.Sprintf("%#x", uid)
+	} else {
+		subject = fmt.Sprintf("_:gq%d", n.blank)
+		n.blank++
+	}
+	return subject, n.values(subject, in)
+}
+
+func (n *nquads) values(subject string, in map[string]interface{}) error {
+	input := n.s.types["NodeInput"]
+	for _, iv := range input.inputs {
+		v, ok := in[iv.name]
+		if !ok || iv.pred == nil {
+			continue
+		}
+		vals, isList := v.([]interface{})
+		if !isList {
+			vals = []interface{}{v}
+		}
+		for _, val := range vals {
+			nq := &protos.NQuad{Subject: subject, Predicate: iv.pred.name}
+			if iv.pred.typ == "uid" {
+				obj, err := n.node(val.(map[string]interface{}))
+				if err != nil {
+					return err
+				}
+				nq.ObjectId = obj
+			} else {
+				ov, err := objectValue(iv.pred, val)
+				if err != nil {
+					return x.Wrapf(err, "In field %q", iv.name)
+				}
+				nq.ObjectValue = ov
+				nq.ObjectType = objectType(ov)
+			}
+			n.set = append(n.set, nq)
+		}
+	}
+	return nil
+}
+
+// objectValue returns the value of a predicate. Strings are left for Dgraph to convert to the
+// type of the predicate.
+func objectValue(p *predicate, v interface{}) (*protos.Value, error) {
+	switch v := v.(type) {
+	case int64:
+		if p.typ == "float" {
+			return &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: float64(v)}}, nil
+		}
+		return &protos.Value{Val: &protos.Value_IntVal{IntVal: v}}, nil
+	case float64:
+		return &protos.Value{Val: &protos.Value_DoubleVal{DoubleVal: v}}, nil
+	case bool:
+		return &protos.Value{Val: &protos.Value_BoolVal{BoolVal: v}}, nil
+	case string:
+		return &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: v}}, nil
+	}
+	if p.typ == "geo" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: string(b)}}, nil
+	}
+	return nil, x.Errorf("Invalid value: %v", v)
+}
+
+func starValue() *protos.Value {
+	return &protos.Value{Val: &protos.Value_DefaultVal{DefaultVal: x.Star}}
+}
+
+// mutation runs the fields of a mutation operation, one after another.
+func (e *executor) mutation(groups []*fieldGroup) *object {
+	data := &object{}
+	for _, g := range groups {
+		f := g.first()
+		if f.name == "__typename" {
+			data.set(g.key, "Mutation")
+			continue
+		}
+		v, err := e.mutate(g)
+		if err != nil {
+			e.addError(err, []interface{}{g.key})
+			v = nil
+		}
+		data.set(g.key, v)
+	}
+	return data
+}
+
+func (e *executor) mutate(g *fieldGroup) (interface{}, error) {
+	f := g.first()
+	args, err := e.args(f, e.s.mutation.field(f.name))
+	if err != nil {
+		return nil, err
+	}
+	n := &nquads{s: e.s}
+	var uid string
+	switch f.name {
+	case "addNode":
+		subject, err := n.node(args["input"].(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		allocs, err := e.r.Mutate(e.ctx, &protos.Mutation{Set: n.set})
+		if err != nil {
+			return nil, err
+		}
+		uid = subject
+		if strings.HasPrefix(subject, "_:") {
+			uid = fmt.Sprintf("%#x", allocs[subject[2:]])
+		}
+
+	case "updateNode":
+		id, err := parseUid(args["uid"].(string))
+		if err != nil {
+			return nil, err
+		}
+		uid = fmt.Sprintf("%#x", id)
+		if remove, ok := args["remove"]; ok {
+			var del []*protos.NQuad
+			for _, field := range remove.([]interface{}) {
+				del = append(del, &protos.NQuad{
+					Subject:     uid,
+					Predicate:   e.s.node.field(field.(string)).pred.name,
+					ObjectValue: starValue(),
+				})
+			}
+			// Values are removed before the new ones are set.
+			if _, err := e.r.Mutate(e.ctx, &protos.Mutation{Del: del}); err != nil {
+				return nil, err
+			}
+		}
+		if set, ok := args["set"]; ok {
+			if err := n.values(uid, set.(map[string]interface{})); err != nil {
+				return nil, err
+			}
+			if len(n.set) > 0 {
+				if _, err := e.r.Mutate(e.ctx, &protos.Mutation{Set: n.set}); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+	case "deleteNode":
+		id, err := parseUid(args["uid"].(string))
+		if err != nil {
+			return nil, err
+		}
+		uid = fmt.Sprintf("%#x", id)
+		del := []*protos.NQuad{{Subject: uid, Predicate: x.Star, ObjectValue: starValue()}}
+		if _, err := e.r.Mutate(e.ctx, &protos.Mutation{Del: del}); err != nil {
+			return nil, err
+		}
+		return uid, nil
+	}
+
+	// The node is fetched with the fields selected, as if by the node query.
+	q := newDQLQuery()
+	node := &fieldGroup{key: g.key, fields: []*field{{
+		name: "node",
+		args: []*argument{{name: "uid", val: &value{kind: valString, raw: uid}}},
+		sel:  g.sel(),
+		loc:  f.loc,
+	}}}
+	plans, err := e.rootBlock(q, "gqb0", node)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.run(q)
+	if err != nil {
+		return nil, err
+	}
+	nodes := completeNodes(plans, res["gqb0"])
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return nodes[0], nil
+}
+
+// objectType returns the type of a value, which Dgraph converts it from, as the Go client sets it.
+func objectType(v *protos.Value) int32 {
+	switch v.Val.(type) {
+	case *protos.Value_IntVal:
+		return int32(types.IntID)
+	case *protos.Value_DoubleVal:
+		return int32(types.FloatID)
+	case *protos.Value_BoolVal:
+		return int32(types.BoolID)
+	}
+	return int32(types.DefaultID)
+}
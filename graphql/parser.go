@@ -0,0 +1,552 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+// document is a parsed GraphQL executable document.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	// query, mutation or subscription.
+	kind       string
+	name       string
+	vars       []*varDef
+	directives []*directive
+	sel        []selection
+	loc        location
+}
+
+type varDef struct {
+	name string
+	typ  *typeRef
+	def  *value
+	loc  location
+}
+
+// typeRef is a type as written in a document, either a named type or a list of one.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  location
+}
+
+type argument struct {
+	name string
+	val  *value
+	loc  location
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment.
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	sel        []selection
+	loc        location
+}
+
+// key is the name of the field in the response.
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        location
+}
+
+type inlineFragment struct {
+	on         string
+	directives []*directive
+	sel        []selection
+	loc        location
+}
+
+type fragment struct {
+	name       string
+	on         string
+	directives []*directive
+	sel        []selection
+	loc        location
+}
+
+type valueKind int
+
+const (
+	valVar valueKind = iota
+	valInt
+	valFloat
+	valString
+	valBool
+	valNull
+	valEnum
+	valList
+	valObject
+)
+
+type value struct {
+	kind valueKind
+	// Name of a variable or an enum value, or the text of a scalar.
+	raw    string
+	list   []*value
+	fields []*objectField
+	loc    location
+}
+
+type objectField struct {
+	name string
+	val  *value
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) advance() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) is(kind tokenKind, val string) bool {
+	t := p.peek()
+	return t.kind == kind && (val == "" || t.val == val)
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return newError(t.loc, "Syntax Error: Unexpected end of document")
+	}
+	return newError(t.loc, "Syntax Error: Unexpected %q", t.val)
+}
+
+func (p *parser) expect(kind tokenKind, val string) (token, error) {
+	if !p.is(kind, val) {
+		return token{}, p.unexpected()
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) skip(val string) bool {
+	if p.is(tokPunct, val) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) name() (string, error) {
+	t, err := p.expect(tokName, "")
+	return t.val, err
+}
+
+// parse parses an executable document. Type system definitions aren't accepted.
+func parse(src string) (*document, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	doc := &document{fragments: make(map[string]*fragment)}
+	for !p.is(tokEOF, "") {
+		t := p.peek()
+		switch {
+		case t.kind == tokPunct && t.val == "{":
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", sel: sel, loc: t.loc})
+		case t.kind == tokName && (t.val == "query" || t.val == "mutation" ||
+			t.val == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.kind == tokName && t.val == "fragment":
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[f.name]; ok {
+				return nil, newError(f.loc, "There can be only one fragment named %q.", f.name)
+			}
+			doc.fragments[f.name] = f
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, newError(location{1, 1}, "The document has no operations.")
+	}
+	for _, f := range doc.fragments {
+		if err := doc.checkCycle(f, f.sel, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// checkCycle returns an error if the fragment f spreads itself, directly or through others.
+func (d *document) checkCycle(f *fragment, sel []selection, seen map[string]bool) error {
+	for _, s := range sel {
+		switch s := s.(type) {
+		case *field:
+			if err := d.checkCycle(f, s.sel, seen); err != nil {
+				return err
+			}
+		case *inlineFragment:
+			if err := d.checkCycle(f, s.sel, seen); err != nil {
+				return err
+			}
+		case *fragmentSpread:
+			if s.name == f.name {
+				return newError(s.loc, "Fragment %q can't spread itself.", f.name)
+			}
+			if seen[s.name] {
+				continue
+			}
+			seen[s.name] = true
+			if spread, ok := d.fragments[s.name]; ok {
+				if err := d.checkCycle(f, spread.sel, seen); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	t := p.advance()
+	op := &operation{kind: t.val, loc: t.loc}
+	if p.is(tokName, "") {
+		op.name = p.advance().val
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			v, err := p.varDef()
+			if err != nil {
+				return nil, err
+			}
+			op.vars = append(op.vars, v)
+		}
+	}
+	var err error
+	if op.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if op.sel, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) varDef() (*varDef, error) {
+	t, err := p.expect(tokPunct, "$")
+	if err != nil {
+		return nil, err
+	}
+	v := &varDef{loc: t.loc}
+	if v.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokPunct, ":"); err != nil {
+		return nil, err
+	}
+	if v.typ, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if p.skip("=") {
+		if v.def, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	// Directives on variables are allowed, but none are defined.
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	if p.skip("[") {
+		var err error
+		if t.elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokPunct, "]"); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if t.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	t.nonNull = p.skip("!")
+	return t, nil
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var ds []*directive
+	for p.is(tokPunct, "@") {
+		t := p.advance()
+		d := &directive{loc: t.loc}
+		var err error
+		if d.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+func (p *parser) arguments() ([]*argument, error) {
+	if !p.skip("(") {
+		return nil, nil
+	}
+	var args []*argument
+	for !p.skip(")") {
+		t := p.peek()
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokPunct, ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range args {
+			if a.name == name {
+				return nil, newError(t.loc, "There can be only one argument named %q.", name)
+			}
+		}
+		args = append(args, &argument{name: name, val: v, loc: t.loc})
+	}
+	if len(args) == 0 {
+		return nil, p.unexpected()
+	}
+	return args, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if _, err := p.expect(tokPunct, "{"); err != nil {
+		return nil, err
+	}
+	var sel []selection
+	for !p.skip("}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, s)
+	}
+	if len(sel) == 0 {
+		return nil, p.unexpected()
+	}
+	return sel, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	t := p.peek()
+	if p.skip("...") {
+		if p.is(tokName, "") && p.peek().val != "on" {
+			fs := &fragmentSpread{name: p.advance().val, loc: t.loc}
+			var err error
+			fs.directives, err = p.directives()
+			return fs, err
+		}
+		f := &inlineFragment{loc: t.loc}
+		var err error
+		if p.is(tokName, "on") {
+			p.advance()
+			if f.on, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if f.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if f.sel, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	f := &field{loc: t.loc}
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.skip(":") {
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.is(tokPunct, "{") {
+		if f.sel, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	t := p.advance()
+	f := &fragment{loc: t.loc}
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.name == "on" {
+		return nil, newError(t.loc, "Syntax Error: A fragment can't be named \"on\"")
+	}
+	if _, err := p.expect(tokName, "on"); err != nil {
+		return nil, err
+	}
+	if f.on, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if f.sel, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// value parses a value. Constant values, such as defaults of variables, can't have variables.
+func (p *parser) value(constant bool) (*value, error) {
+	t := p.peek()
+	v := &value{loc: t.loc}
+	switch t.kind {
+	case tokInt:
+		v.kind, v.raw = valInt, p.advance().val
+	case tokFloat:
+		v.kind, v.raw = valFloat, p.advance().val
+	case tokString:
+		v.kind, v.raw = valString, p.advance().val
+	case tokName:
+		p.advance()
+		switch t.val {
+		case "true", "false":
+			v.kind = valBool
+		case "null":
+			v.kind = valNull
+		default:
+			v.kind = valEnum
+		}
+		v.raw = t.val
+	case tokPunct:
+		switch t.val {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			p.advance()
+			v.kind = valVar
+			var err error
+			if v.raw, err = p.name(); err != nil {
+				return nil, err
+			}
+		case "[":
+			p.advance()
+			v.kind = valList
+			for !p.skip("]") {
+				e, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.list = append(v.list, e)
+			}
+		case "{":
+			p.advance()
+			v.kind = valObject
+			for !p.skip("}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if _, err := p.expect(tokPunct, ":"); err != nil {
+					return nil, err
+				}
+				e, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				v.fields = append(v.fields, &objectField{name: name, val: e})
+			}
+		default:
+			return nil, p.unexpected()
+		}
+	default:
+		return nil, p.unexpected()
+	}
+	return v, nil
+}
@@ -0,0 +1,562 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// Predicates have no types of nodes in Dgraph, so the schema has a single Node type with a field
+// for every predicate. A uid predicate is a list of nodes, which can be filtered, ordered and
+// paginated, along with a count of them, and a reverse edge if it has @reverse. Filters have the
+// functions that the indexes of each predicate allow.
+
+const (
+	kindScalar  = "SCALAR"
+	kindObject  = "OBJECT"
+	kindInput   = "INPUT_OBJECT"
+	kindEnum    = "ENUM"
+	kindList    = "LIST"
+	kindNonNull = "NON_NULL"
+)
+
+type typeDef struct {
+	kind   string
+	name   string
+	desc   string
+	fields []*fieldDef
+	inputs []*inputValue
+	enums  []*enumValue
+	// The wrapped type of LIST and NON_NULL.
+	of *typeDef
+}
+
+func (t *typeDef) field(name string) *fieldDef {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (t *typeDef) input(name string) *inputValue {
+	for _, in := range t.inputs {
+		if in.name == name {
+			return in
+		}
+	}
+	return nil
+}
+
+// named returns the type without its LIST and NON_NULL wrappers.
+func (t *typeDef) named() *typeDef {
+	for t.of != nil {
+		t = t.of
+	}
+	return t
+}
+
+func (t *typeDef) String() string {
+	switch t.kind {
+	case kindList:
+		return "[" + t.of.String() + "]"
+	case kindNonNull:
+		return t.of.String() + "!"
+	}
+	return t.name
+}
+
+func listOf(t *typeDef) *typeDef {
+	return &typeDef{kind: kindList, of: t}
+}
+
+func nonNull(t *typeDef) *typeDef {
+	return &typeDef{kind: kindNonNull, of: t}
+}
+
+type fieldKind int
+
+const (
+	fieldUid fieldKind = iota
+	fieldValue
+	fieldEdge
+	fieldCount
+	fieldReverse
+	fieldReverseCount
+	// Fields of the Query, Mutation and introspection types.
+	fieldRoot
+)
+
+type fieldDef struct {
+	name string
+	desc string
+	args []*inputValue
+	typ  *typeDef
+	kind fieldKind
+	pred *predicate
+}
+
+type inputValue struct {
+	name string
+	desc string
+	typ  *typeDef
+	// Default value, as GraphQL text.
+	def  string
+	pred *predicate
+}
+
+type enumValue struct {
+	name string
+	desc string
+	pred *predicate
+}
+
+// predicate is a predicate of the Dgraph schema, and its field in Node.
+type predicate struct {
+	name       string
+	field      string
+	typ        string
+	list       bool
+	reverse    bool
+	tokenizers []string
+}
+
+func (p *predicate) hasTokenizer(prefix string) bool {
+	for _, t := range p.tokenizers {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema is the GraphQL schema generated from the Dgraph schema.
+type Schema struct {
+	types    map[string]*typeDef
+	query    *typeDef
+	mutation *typeDef
+	node     *typeDef
+	preds    []*predicate
+}
+
+var (
+	idType       = &typeDef{kind: kindScalar, name: "ID", desc: "A uid, such as 0x1a."}
+	stringType   = &typeDef{kind: kindScalar, name: "String"}
+	intType      = &typeDef{kind: kindScalar, name: "Int"}
+	floatType    = &typeDef{kind: kindScalar, name: "Float"}
+	booleanType  = &typeDef{kind: kindScalar, name: "Boolean"}
+	dateTimeType = &typeDef{kind: kindScalar, name: "DateTime",
+		desc: "A date and time, in RFC 3339 format."}
+	geoType = &typeDef{kind: kindScalar, name: "Geo",
+		desc: "A GeoJSON geometry: a Point, Polygon or MultiPolygon."}
+)
+
+func scalarType(typ string) *typeDef {
+	switch typ {
+	case "int":
+		return intType
+	case "float":
+		return floatType
+	case "bool":
+		return booleanType
+	case "datetime", "date":
+		return dateTimeType
+	case "geo":
+		return geoType
+	}
+	return stringType
+}
+
+// fieldName returns a valid GraphQL name for a predicate.
+func fieldName(pred string) string {
+	b := []byte(pred)
+	for i, c := range b {
+		if !isNameStart(c) && !isDigit(c) {
+			b[i] = '_'
+		}
+	}
+	name := string(b)
+	if isDigit(name[0]) || strings.HasPrefix(name, "__") {
+		name = "p" + name
+	}
+	// Names of fields are also values of enums, which can't be these.
+	if name == "true" || name == "false" || name == "null" {
+		name += "_"
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// NewSchema generates the GraphQL schema for the predicates of a Dgraph schema.
+func NewSchema(nodes []*protos.SchemaNode) *Schema {
+	s := &Schema{types: make(map[string]*typeDef)}
+	nodes = append([]*protos.SchemaNode(nil), nodes...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Predicate < nodes[j].Predicate })
+
+	s.node = &typeDef{kind: kindObject, name: "Node", desc: "A node of the graph."}
+	taken := map[string]bool{"uid": true, "__typename": true}
+	for _, n := range nodes {
+		// Internal predicates, such as _predicate_, and passwords aren't exposed.
+		if n.Predicate == "" || (strings.HasPrefix(n.Predicate, "_") &&
+			strings.HasSuffix(n.Predicate, "_")) || n.Type == "password" {
+			continue
+		}
+		p := &predicate{
+			name:       n.Predicate,
+			typ:        n.Type,
+			list:       n.List,
+			reverse:    n.Reverse && n.Type == "uid",
+			tokenizers: n.Tokenizer,
+		}
+		p.field = fieldName(p.name)
+		for i := 2; taken[p.field]; i++ {
+			p.field = fmt.Sprintf("%s_%d", fieldName(p.name), i)
+		}
+		taken[p.field] = true
+		s.preds = append(s.preds, p)
+	}
+
+	for _, t := range []*typeDef{idType, stringType, intType, floatType, booleanType,
+		dateTimeType, geoType} {
+		s.types[t.name] = t
+	}
+	for _, t := range introspectionTypes {
+		s.types[t.name] = t
+	}
+	s.types[s.node.name] = s.node
+
+	fieldEnum := &typeDef{kind: kindEnum, name: "NodeField", desc: "A field of Node."}
+	orderEnum := &typeDef{kind: kindEnum, name: "NodeOrderable",
+		desc: "A field of Node which nodes can be ordered by."}
+	for _, p := range s.preds {
+		fieldEnum.enums = append(fieldEnum.enums, &enumValue{name: p.field, pred: p})
+		if p.typ != "uid" && p.typ != "geo" {
+			orderEnum.enums = append(orderEnum.enums, &enumValue{name: p.field, pred: p})
+		}
+	}
+	// Enums with no values aren't valid, so fields which would take them are left out.
+	if len(fieldEnum.enums) > 0 {
+		s.types[fieldEnum.name] = fieldEnum
+	} else {
+		fieldEnum = nil
+	}
+	if len(orderEnum.enums) > 0 {
+		s.types[orderEnum.name] = orderEnum
+	} else {
+		orderEnum = nil
+	}
+
+	filter := s.filterType(fieldEnum)
+	listArgs := func() []*inputValue {
+		args := []*inputValue{{name: "filter", typ: filter}}
+		if orderEnum != nil {
+			args = append(args,
+				&inputValue{name: "orderasc", typ: orderEnum},
+				&inputValue{name: "orderdesc", typ: orderEnum})
+		}
+		return append(args,
+			&inputValue{name: "first", typ: intType},
+			&inputValue{name: "offset", typ: intType},
+			&inputValue{name: "after", typ: idType, desc: "Only nodes with a greater uid."})
+	}
+
+	nodeList := listOf(nonNull(s.node))
+	s.node.fields = append(s.node.fields, &fieldDef{
+		name: "uid",
+		typ:  nonNull(idType),
+		kind: fieldUid,
+	})
+	for _, p := range s.preds {
+		f := &fieldDef{name: p.field, pred: p, desc: fmt.Sprintf("The %s predicate.", p.name)}
+		if p.typ == "uid" {
+			f.kind, f.typ, f.args = fieldEdge, nodeList, listArgs()
+			s.node.fields = append(s.node.fields, f)
+			s.addField(taken, &fieldDef{name: p.field + "Count", typ: intType, kind: fieldCount,
+				pred: p, desc: fmt.Sprintf("The number of %s edges.", p.name)})
+			if p.reverse {
+				s.addField(taken, &fieldDef{name: "reverse_" + p.field, typ: nodeList,
+					args: listArgs(), kind: fieldReverse, pred: p,
+					desc: fmt.Sprintf("The nodes with %s edges to this one.", p.name)})
+				s.addField(taken, &fieldDef{name: "reverse_" + p.field + "Count", typ: intType,
+					kind: fieldReverseCount, pred: p,
+					desc: fmt.Sprintf("The number of %s edges to this node.", p.name)})
+			}
+			continue
+		}
+		f.kind, f.typ = fieldValue, scalarType(p.typ)
+		if p.list {
+			f.typ = listOf(f.typ)
+		}
+		if f.typ.named() == stringType {
+			f.args = []*inputValue{{name: "lang", typ: stringType,
+				desc: "Language of the value, such as en. Several can be given, as en:fr."}}
+		}
+		s.node.fields = append(s.node.fields, f)
+	}
+
+	input := &typeDef{kind: kindInput, name: "NodeInput",
+		desc: "The values of a node. A uid refers to an existing node, instead of a new one."}
+	input.inputs = append(input.inputs, &inputValue{name: "uid", typ: idType})
+	for _, p := range s.preds {
+		var t *typeDef
+		if p.typ == "uid" {
+			t = listOf(nonNull(input))
+		} else if t = scalarType(p.typ); p.list {
+			t = listOf(nonNull(t))
+		}
+		input.inputs = append(input.inputs, &inputValue{name: p.field, typ: t, pred: p})
+	}
+	s.types[input.name] = input
+
+	s.query = &typeDef{kind: kindObject, name: "Query"}
+	s.query.fields = []*fieldDef{{
+		name: "node",
+		desc: "The node with the given uid.",
+		args: []*inputValue{{name: "uid", typ: nonNull(idType)}},
+		typ:  s.node,
+		kind: fieldRoot,
+	}, {
+		name: "nodes",
+		desc: "The nodes with the given uids, or those matching the filter.",
+		args: append([]*inputValue{{name: "uids", typ: listOf(nonNull(idType))}}, listArgs()...),
+		typ:  nodeList,
+		kind: fieldRoot,
+	}}
+	s.types[s.query.name] = s.query
+
+	s.mutation = &typeDef{kind: kindObject, name: "Mutation"}
+	update := &fieldDef{
+		name: "updateNode",
+		desc: "Sets values of a node, after removing all of the values of the given fields.",
+		args: []*inputValue{
+			{name: "uid", typ: nonNull(idType)},
+			{name: "set", typ: input},
+		},
+		typ:  s.node,
+		kind: fieldRoot,
+	}
+	if fieldEnum != nil {
+		update.args = append(update.args, &inputValue{name: "remove",
+			typ: listOf(nonNull(fieldEnum))})
+	}
+	s.mutation.fields = []*fieldDef{{
+		name: "addNode",
+		desc: "Adds a node, along with the new nodes it has edges to.",
+		args: []*inputValue{{name: "input", typ: nonNull(input)}},
+		typ:  s.node,
+		kind: fieldRoot,
+	}, update, {
+		name: "deleteNode",
+		desc: "Deletes all of the values and edges of a node, and returns its uid.",
+		args: []*inputValue{{name: "uid", typ: nonNull(idType)}},
+		typ:  idType,
+		kind: fieldRoot,
+	}}
+	s.types[s.mutation.name] = s.mutation
+	return s
+}
+
+// addField adds a generated field to Node, unless a predicate already has its name.
+func (s *Schema) addField(taken map[string]bool, f *fieldDef) {
+	if taken[f.name] {
+		return
+	}
+	taken[f.name] = true
+	s.node.fields = append(s.node.fields, f)
+}
+
+// filterType returns the NodeFilter type, with a field for every indexed predicate.
+func (s *Schema) filterType(fieldEnum *typeDef) *typeDef {
+	filter := &typeDef{kind: kindInput, name: "NodeFilter",
+		desc: "Nodes matching all of the given conditions."}
+	s.types[filter.name] = filter
+	filter.inputs = []*inputValue{
+		{name: "and", typ: listOf(nonNull(filter))},
+		{name: "or", typ: listOf(nonNull(filter)), desc: "Nodes matching any of the filters."},
+		{name: "not", typ: filter},
+		{name: "uid", typ: listOf(nonNull(idType))},
+	}
+	if fieldEnum != nil {
+		filter.inputs = append(filter.inputs, &inputValue{name: "has",
+			typ: listOf(nonNull(fieldEnum)), desc: "Nodes with all of the given fields."})
+	}
+
+	near := &typeDef{kind: kindInput, name: "NearFilter", inputs: []*inputValue{
+		{name: "longitude", typ: nonNull(floatType)},
+		{name: "latitude", typ: nonNull(floatType)},
+		{name: "distance", typ: nonNull(floatType), desc: "In metres."},
+	}}
+	for _, p := range s.preds {
+		ops := filterOps(p)
+		if len(ops) == 0 {
+			continue
+		}
+		t := &typeDef{kind: kindInput, name: "Node" + upperFirst(p.field) + "Filter"}
+		for i := 2; s.types[t.name] != nil; i++ {
+			t.name = fmt.Sprintf("Node%sFilter%d", upperFirst(p.field), i)
+		}
+		for _, op := range ops {
+			in := &inputValue{name: op, typ: scalarType(p.typ), pred: p}
+			switch op {
+			case "near":
+				in.typ = near
+				s.types[near.name] = near
+			case "allofterms", "anyofterms", "alloftext", "anyoftext":
+				in.typ = stringType
+			case "regexp":
+				in.typ = stringType
+				in.desc = "A regular expression, which can be given as /re/i to ignore case."
+			}
+			t.inputs = append(t.inputs, in)
+		}
+		s.types[t.name] = t
+		filter.inputs = append(filter.inputs, &inputValue{name: p.field, typ: t, pred: p})
+	}
+	return filter
+}
+
+// filterOps returns the functions that the indexes of a predicate allow.
+func filterOps(p *predicate) []string {
+	compare := []string{"eq", "le", "lt", "ge", "gt"}
+	var ops []string
+	switch p.typ {
+	case "uid":
+		return nil
+	case "geo":
+		if p.hasTokenizer("geo") {
+			ops = []string{"near", "within", "contains", "intersects"}
+		}
+		return ops
+	case "bool":
+		if len(p.tokenizers) > 0 {
+			ops = []string{"eq"}
+		}
+		return ops
+	case "int", "float", "datetime", "date":
+		if len(p.tokenizers) > 0 {
+			ops = compare
+		}
+		return ops
+	}
+	if p.hasTokenizer("exact") {
+		ops = append(ops, compare...)
+	} else if p.hasTokenizer("hash") {
+		ops = append(ops, "eq")
+	}
+	if p.hasTokenizer("term") {
+		ops = append(ops, "allofterms", "anyofterms")
+	}
+	if p.hasTokenizer("fulltext") {
+		ops = append(ops, "alloftext", "anyoftext")
+	}
+	if p.hasTokenizer("trigram") {
+		ops = append(ops, "regexp")
+	}
+	return ops
+}
+
+func writeDesc(b *bytes.Buffer, indent, desc string) {
+	if desc != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(desc))
+	}
+}
+
+func writeArgs(b *bytes.Buffer, args []*inputValue) {
+	if len(args) == 0 {
+		return
+	}
+	b.WriteByte('(')
+	for i, a := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%s: %s", a.name, a.typ)
+		if a.def != "" {
+			fmt.Fprintf(b, " = %s", a.def)
+		}
+	}
+	b.WriteByte(')')
+}
+
+// SDL returns the schema in the GraphQL schema definition language.
+func (s *Schema) SDL() string {
+	var names []string
+	for name, t := range s.types {
+		if !strings.HasPrefix(name, "__") && !isBuiltin(t) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		t := s.types[name]
+		writeDesc(&b, "", t.desc)
+		switch t.kind {
+		case kindScalar:
+			fmt.Fprintf(&b, "scalar %s\n", t.name)
+		case kindEnum:
+			fmt.Fprintf(&b, "enum %s {\n", t.name)
+			for _, e := range t.enums {
+				fmt.Fprintf(&b, "  %s\n", e.name)
+			}
+			b.WriteString("}\n")
+		case kindInput:
+			fmt.Fprintf(&b, "input %s {\n", t.name)
+			for _, in := range t.inputs {
+				writeDesc(&b, "  ", in.desc)
+				fmt.Fprintf(&b, "  %s: %s\n", in.name, in.typ)
+			}
+			b.WriteString("}\n")
+		case kindObject:
+			fmt.Fprintf(&b, "type %s {\n", t.name)
+			for _, f := range t.fields {
+				writeDesc(&b, "  ", f.desc)
+				fmt.Fprintf(&b, "  %s", f.name)
+				writeArgs(&b, f.args)
+				fmt.Fprintf(&b, ": %s\n", f.typ)
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func isBuiltin(t *typeDef) bool {
+	switch t {
+	case idType, stringType, intType, floatType, booleanType:
+		return true
+	}
+	return false
+}
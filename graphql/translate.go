@@ -0,0 +1,603 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The root fields of a query are run as blocks of a single GraphQL+- query. Fields are given
+// aliases by their position, gq0, gq1 and so on, so that the names chosen by clients don't
+// matter, and the results are shaped into the response by walking the same plan. Strings are
+// passed as variables of the query, instead of being quoted into it.
+
+// dqlQuery is a GraphQL+- query being built.
+type dqlQuery struct {
+	decls  []string
+	vars   map[string]string
+	blocks bytes.Buffer
+	// Number of uid variables defined.
+	uidVars int
+}
+
+func newDQLQuery() *dqlQuery {
+	return &dqlQuery{vars: make(map[string]string)}
+}
+
+// param returns a variable of the query with the value s.
+func (q *dqlQuery) param(s string) string {
+	name := fmt.Sprintf("$gv%d", len(q.decls))
+	q.decls = append(q.decls, name+": string")
+	q.vars[name] = s
+	return name
+}
+
+func (q *dqlQuery) String() string {
+	if len(q.decls) == 0 {
+		return "{\n" + q.blocks.String() + "}"
+	}
+	return fmt.Sprintf("query gql(%s) {\n%s}", strings.Join(q.decls, ", "), q.blocks.String())
+}
+
+// plan is how a field of Node is fetched, and shaped into the response.
+type plan struct {
+	key   string
+	alias string
+	def   *fieldDef
+	loc   location
+	sub   []*plan
+}
+
+func parseUid(id string) (uint64, error) {
+	uid, err := strconv.ParseUint(id, 0, 64)
+	if err != nil || uid == 0 {
+		return 0, x.Errorf("Invalid uid: %q", id)
+	}
+	return uid, nil
+}
+
+func uidList(ids []interface{}) (string, error) {
+	var uids []string
+	for _, id := range ids {
+		uid, err := parseUid(id.(string))
+		if err != nil {
+			return "", err
+		}
+		uids = append(uids, fmt.Sprintf("%#x", uid))
+	}
+	return strings.Join(uids, ", "), nil
+}
+
+// listArgs returns the pagination and ordering arguments of a list of nodes.
+func (e *executor) listArgs(args map[string]interface{}) ([]string, error) {
+	var out []string
+	for _, name := range []string{"orderasc", "orderdesc"} {
+		if v, ok := args[name]; ok {
+			out = append(out, fmt.Sprintf("%s: %s", name, e.s.node.field(v.(string)).pred.name))
+		}
+	}
+	for _, name := range []string{"first", "offset"} {
+		if v, ok := args[name]; ok {
+			out = append(out, fmt.Sprintf("%s: %d", name, v.(int64)))
+		}
+	}
+	if v, ok := args["after"]; ok {
+		uid, err := parseUid(v.(string))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fmt.Sprintf("after: %#x", uid))
+	}
+	return out, nil
+}
+
+// nodeSelection writes the selection of fields of Node, and returns its plan.
+func (e *executor) nodeSelection(q *dqlQuery, b *bytes.Buffer, groups []*fieldGroup,
+	indent string) ([]*plan, error) {
+	var plans []*plan
+	b.WriteString(indent + "_uid_\n")
+	for i, g := range groups {
+		f := g.first()
+		p := &plan{key: g.key, alias: fmt.Sprintf("gq%d", i), loc: f.loc}
+		plans = append(plans, p)
+		if f.name == "__typename" {
+			continue
+		}
+		p.def = e.s.node.field(f.name)
+		args, err := e.args(f, p.def)
+		if err != nil {
+			return nil, err
+		}
+		pred := p.def.pred
+		switch p.def.kind {
+		case fieldValue:
+			fmt.Fprintf(b, "%s%s: %s", indent, p.alias, pred.name)
+			if lang, ok := args["lang"]; ok {
+				fmt.Fprintf(b, "@%s", lang)
+			}
+			b.WriteByte('\n')
+		case fieldCount:
+			fmt.Fprintf(b, "%s%s: count(%s)\n", indent, p.alias, pred.name)
+		case fieldReverseCount:
+			fmt.Fprintf(b, "%s%s: count(~%s)\n", indent, p.alias, pred.name)
+		case fieldEdge, fieldReverse:
+			name := pred.name
+			if p.def.kind == fieldReverse {
+				name = "~" + name
+			}
+			fmt.Fprintf(b, "%s%s: %s", indent, p.alias, name)
+			la, err := e.listArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(la) > 0 {
+				fmt.Fprintf(b, " (%s)", strings.Join(la, ", "))
+			}
+			if fv, ok := args["filter"]; ok {
+				filter, err := e.filter(q, fv.(map[string]interface{}))
+				if err != nil {
+					return nil, err
+				}
+				if filter != "" {
+					fmt.Fprintf(b, " @filter(%s)", filter)
+				}
+			}
+			b.WriteString(" {\n")
+			sub, err := e.collect(e.s.node, g.sel())
+			if err != nil {
+				return nil, err
+			}
+			if p.sub, err = e.nodeSelection(q, b, sub, indent+"  "); err != nil {
+				return nil, err
+			}
+			b.WriteString(indent + "}\n")
+		}
+	}
+	return plans, nil
+}
+
+// rootFuncs returns functions whose union of results has all of the nodes matching the filter,
+// which can be used as the root of a query. There are none if the filter only has conditions
+// under not.
+func (e *executor) rootFuncs(q *dqlQuery, filter map[string]interface{}) ([]string, error) {
+	for _, in := range e.s.types["NodeFilter"].inputs {
+		v, ok := filter[in.name]
+		if !ok {
+			continue
+		}
+		switch in.name {
+		case "and":
+			for _, sub := range v.([]interface{}) {
+				fns, err := e.rootFuncs(q, sub.(map[string]interface{}))
+				if err != nil || len(fns) > 0 {
+					return fns, err
+				}
+			}
+		case "or":
+			var all []string
+			subs := v.([]interface{})
+			for _, sub := range subs {
+				fns, err := e.rootFuncs(q, sub.(map[string]interface{}))
+				if err != nil {
+					return nil, err
+				}
+				if len(fns) == 0 {
+					all = nil
+					break
+				}
+				all = append(all, fns...)
+			}
+			if len(all) > 0 {
+				return all, nil
+			}
+		case "not":
+		case "uid":
+			uids, err := uidList(v.([]interface{}))
+			return []string{"uid(" + uids + ")"}, err
+		case "has":
+			field := v.([]interface{})
+			if len(field) > 0 {
+				return []string{fmt.Sprintf("has(%s)", e.s.node.field(field[0].(string)).pred.name)},
+					nil
+			}
+		default:
+			ops := v.(map[string]interface{})
+			for _, op := range in.typ.inputs {
+				if ov, ok := ops[op.name]; ok {
+					fn, err := e.fn(q, in.pred, op.name, ov)
+					return []string{fn}, err
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// filter returns the GraphQL+- filter for a NodeFilter.
+func (e *executor) filter(q *dqlQuery, filter map[string]interface{}) (string, error) {
+	var conds []string
+	for _, in := range e.s.types["NodeFilter"].inputs {
+		v, ok := filter[in.name]
+		if !ok {
+			continue
+		}
+		switch in.name {
+		case "and", "or":
+			var subs []string
+			for _, sub := range v.([]interface{}) {
+				f, err := e.filter(q, sub.(map[string]interface{}))
+				if err != nil {
+					return "", err
+				}
+				if f != "" {
+					subs = append(subs, f)
+				}
+			}
+			if len(subs) == 1 {
+				conds = append(conds, subs[0])
+			} else if len(subs) > 1 {
+				op := " AND "
+				if in.name == "or" {
+					op = " OR "
+				}
+				conds = append(conds, "("+strings.Join(subs, op)+")")
+			}
+		case "not":
+			f, err := e.filter(q, v.(map[string]interface{}))
+			if err != nil {
+				return "", err
+			}
+			if f != "" {
+				conds = append(conds, "NOT "+f)
+			}
+		case "uid":
+			uids, err := uidList(v.([]interface{}))
+			if err != nil {
+				return "", err
+			}
+			conds = append(conds, "uid("+uids+")")
+		case "has":
+			for _, field := range v.([]interface{}) {
+				conds = append(conds,
+					fmt.Sprintf("has(%s)", e.s.node.field(field.(string)).pred.name))
+			}
+		default:
+			ops := v.(map[string]interface{})
+			for _, op := range in.typ.inputs {
+				ov, ok := ops[op.name]
+				if !ok {
+					continue
+				}
+				fn, err := e.fn(q, in.pred, op.name, ov)
+				if err != nil {
+					return "", err
+				}
+				conds = append(conds, fn)
+			}
+		}
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	} else if len(conds) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", nil
+}
+
+// fn returns the GraphQL+- function for the operation op of a filter on the predicate p.
+func (e *executor) fn(q *dqlQuery, p *predicate, op string, v interface{}) (string, error) {
+	switch op {
+	case "near":
+		m := v.(map[string]interface{})
+		return fmt.Sprintf("near(%s, [%s, %s], %s)", p.name, formatFloat(m["longitude"]),
+			formatFloat(m["latitude"]), formatFloat(m["distance"])), nil
+	case "within", "contains", "intersects":
+		coords, err := geoCoordinates(v)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s, %s)", op, p.name, coords), nil
+	case "regexp":
+		re := v.(string)
+		if !strings.HasPrefix(re, "/") || strings.LastIndex(re, "/") == 0 {
+			re = "/" + strings.Replace(re, "/", `\/`, -1) + "/"
+		}
+		return fmt.Sprintf("regexp(%s, %s)", p.name, re), nil
+	}
+	var arg string
+	switch v := v.(type) {
+	case int64:
+		arg = strconv.FormatInt(v, 10)
+	case float64:
+		arg = formatFloat(v)
+	case bool:
+		arg = strconv.FormatBool(v)
+	case string:
+		arg = q.param(v)
+	}
+	return fmt.Sprintf("%s(%s, %s)", op, p.name, arg), nil
+}
+
+func formatFloat(v interface{}) string {
+	return strconv.FormatFloat(v.(float64), 'f', -1, 64)
+}
+
+// geoCoordinates returns the coordinates of a GeoJSON geometry, which can be given as an object,
+// as a string of one, or as just its coordinates.
+func geoCoordinates(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return "", x.Wrapf(err, "Invalid GeoJSON")
+		}
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		v = m["coordinates"]
+	}
+	if err := checkCoordinates(v); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// checkCoordinates checks that the coordinates are only numbers, in lists, so that they can be
+// written into a query as they are.
+func checkCoordinates(v interface{}) error {
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			return x.Errorf("Invalid GeoJSON: empty coordinates")
+		}
+		for _, c := range v {
+			if err := checkCoordinates(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if _, ok := number(v); ok {
+			return nil
+		}
+	}
+	return x.Errorf("Invalid GeoJSON: coordinates should be lists of numbers")
+}
+
+// rootBlock writes the block of the node or nodes field of Query.
+func (e *executor) rootBlock(q *dqlQuery, name string, g *fieldGroup) ([]*plan, error) {
+	f := g.first()
+	args, err := e.args(f, e.s.query.field(f.name))
+	if err != nil {
+		return nil, err
+	}
+	var root string
+	var filter map[string]interface{}
+	if id, ok := args["uid"]; ok {
+		uid, err := parseUid(id.(string))
+		if err != nil {
+			return nil, err
+		}
+		root = fmt.Sprintf("uid(%#x)", uid)
+	} else if ids, ok := args["uids"]; ok {
+		uids, err := uidList(ids.([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		root = "uid(" + uids + ")"
+	}
+	if fv, ok := args["filter"]; ok {
+		filter = fv.(map[string]interface{})
+	}
+	if root == "" {
+		var fns []string
+		if filter != nil {
+			if fns, err = e.rootFuncs(q, filter); err != nil {
+				return nil, err
+			}
+		}
+		if len(fns) == 0 {
+			return nil, newError(f.loc, "Field %q needs uids, or a filter with a condition on "+
+				"an indexed field, has or uid outside of not.", f.name)
+		}
+		if len(fns) == 1 {
+			root = fns[0]
+		} else {
+			// The union of several functions is taken with variables.
+			var vars []string
+			for _, fn := range fns {
+				v := fmt.Sprintf("gqv%d", q.uidVars)
+				q.uidVars++
+				fmt.Fprintf(&q.blocks, "  %s as var(func: %s)\n", v, fn)
+				vars = append(vars, v)
+			}
+			root = "uid(" + strings.Join(vars, ", ") + ")"
+		}
+	}
+
+	la, err := e.listArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&q.blocks, "  %s(func: %s", name, root)
+	for _, a := range la {
+		q.blocks.WriteString(", " + a)
+	}
+	q.blocks.WriteByte(')')
+	if filter != nil {
+		fs, err := e.filter(q, filter)
+		if err != nil {
+			return nil, err
+		}
+		if fs != "" {
+			fmt.Fprintf(&q.blocks, " @filter(%s)", fs)
+		}
+	}
+	q.blocks.WriteString(" {\n")
+	groups, err := e.collect(e.s.node, g.sel())
+	if err != nil {
+		return nil, err
+	}
+	plans, err := e.nodeSelection(q, &q.blocks, groups, "    ")
+	if err != nil {
+		return nil, err
+	}
+	q.blocks.WriteString("  }\n")
+	return plans, nil
+}
+
+// run runs a query, and returns its results by the names of its blocks.
+func (e *executor) run(q *dqlQuery) (map[string]interface{}, error) {
+	resp, err := e.r.Query(e.ctx, q.String(), q.vars)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	d := json.NewDecoder(bytes.NewReader(resp))
+	d.UseNumber()
+	if err := d.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// completeNode shapes a node of the results of a query into the fields of its plan.
+func completeNode(plans []*plan, node map[string]interface{}) *object {
+	res := &object{}
+	for _, p := range plans {
+		if p.def == nil {
+			res.set(p.key, "Node")
+			continue
+		}
+		v := node[p.alias]
+		switch p.def.kind {
+		case fieldUid:
+			res.set(p.key, node["_uid_"])
+		case fieldValue:
+			l, isList := v.([]interface{})
+			if p.def.typ.kind == kindList {
+				if !isList && v != nil {
+					v = []interface{}{v}
+				}
+			} else if isList {
+				v = nil
+				if len(l) > 0 {
+					v = l[0]
+				}
+			}
+			res.set(p.key, v)
+		case fieldCount, fieldReverseCount:
+			if v == nil {
+				v = 0
+			}
+			res.set(p.key, v)
+		case fieldEdge, fieldReverse:
+			res.set(p.key, completeNodes(p.sub, v))
+		}
+	}
+	return res
+}
+
+func completeNodes(plans []*plan, v interface{}) []interface{} {
+	nodes := []interface{}{}
+	l, _ := v.([]interface{})
+	for _, n := range l {
+		if m, ok := n.(map[string]interface{}); ok {
+			nodes = append(nodes, completeNode(plans, m))
+		}
+	}
+	return nodes
+}
+
+// query runs the fields of a query operation.
+func (e *executor) query(groups []*fieldGroup) *object {
+	q := newDQLQuery()
+	blocks := make(map[*fieldGroup]string)
+	plans := make(map[*fieldGroup][]*plan)
+	for i, g := range groups {
+		switch g.first().name {
+		case "node", "nodes":
+			name := fmt.Sprintf("gqb%d", i)
+			p, err := e.rootBlock(q, name, g)
+			if err != nil {
+				e.addError(err, []interface{}{g.key})
+				continue
+			}
+			blocks[g], plans[g] = name, p
+		}
+	}
+
+	var results map[string]interface{}
+	if len(blocks) > 0 {
+		var err error
+		if results, err = e.run(q); err != nil {
+			for g := range blocks {
+				e.addError(err, []interface{}{g.key})
+			}
+			blocks = nil
+		}
+	}
+
+	data := &object{}
+	for _, g := range groups {
+		f := g.first()
+		switch f.name {
+		case "__typename":
+			data.set(g.key, "Query")
+		case "__schema":
+			data.set(g.key, e.completeIntrospection(e.s, schemaField.typ, g.sel(),
+				[]interface{}{g.key}))
+		case "__type":
+			args, _ := e.args(f, typeField)
+			var t interface{}
+			if td, ok := e.s.types[args["name"].(string)]; ok {
+				t = td
+			}
+			data.set(g.key, e.completeIntrospection(t, typeField.typ, g.sel(),
+				[]interface{}{g.key}))
+		case "node":
+			name, ok := blocks[g]
+			if !ok {
+				data.set(g.key, nil)
+				continue
+			}
+			nodes := completeNodes(plans[g], results[name])
+			if len(nodes) == 0 {
+				data.set(g.key, nil)
+			} else {
+				data.set(g.key, nodes[0])
+			}
+		case "nodes":
+			name, ok := blocks[g]
+			if !ok {
+				data.set(g.key, nil)
+				continue
+			}
+			data.set(g.key, completeNodes(plans[g], results[name]))
+		}
+	}
+	return data
+}
@@ -0,0 +1,446 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package kafka is a minimal producer for Kafka. It publishes messages with version 2 of the
+// produce request, which brokers understand from 0.10 on, and waits for all the in-sync replicas
+// of a partition to have them. Messages go to the partition of their key, picked like the default
+// partitioner of the Java client does, so that the messages of a key stay in order.
+package kafka
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	apiProduce  = 0
+	apiMetadata = 3
+
+	// Error codes of the responses of brokers which are fixed by refreshing the metadata.
+	errUnknownTopicOrPartition = 3
+	errLeaderNotAvailable      = 5
+	errNotLeaderForPartition   = 6
+
+	retries = 3
+)
+
+// Message is a message to publish.
+type Message struct {
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// Producer publishes messages to the brokers of a cluster. It's safe for concurrent use.
+type Producer struct {
+	brokers  []string
+	clientId string
+	timeout  time.Duration
+
+	sync.Mutex
+	correlation int32
+	conns       map[string]*conn
+	addrs       map[int32]string   // Addresses of the brokers, by id.
+	leaders     map[string][]int32 // Leaders of the partitions of topics, by partition.
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewProducer returns a producer for the cluster of brokers, the host:port addresses of some of
+// its brokers. Requests to brokers time out after timeout.
+func NewProducer(brokers []string, clientId string, timeout time.Duration) *Producer {
+	return &Producer{
+		brokers:  brokers,
+		clientId: clientId,
+		timeout:  timeout,
+		conns:    make(map[string]*conn),
+		addrs:    make(map[int32]string),
+		leaders:  make(map[string][]int32),
+	}
+}
+
+// Close closes the connections to the brokers.
+func (p *Producer) Close() {
+	p.Lock()
+	defer p.Unlock()
+	for addr, c := range p.conns {
+		c.Close()
+		delete(p.conns, addr)
+	}
+}
+
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) int8(v int8)   { e.WriteByte(byte(v)) }
+func (e *encoder) int16(v int16) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) int32(v int32) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) int64(v int64) { binary.Write(e, binary.BigEndian, v) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.WriteString(s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.Write(b)
+}
+
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b) < n {
+		d.err = x.Errorf("Truncated response from Kafka broker")
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	return string(d.next(int(d.int16())))
+}
+
+// array calls fn for each element of an array.
+func (d *decoder) array(fn func()) {
+	n := d.int32()
+	for i := int32(0); i < n && d.err == nil; i++ {
+		fn()
+	}
+}
+
+func (p *Producer) dial(addr string) (*conn, error) {
+	if c, ok := p.conns[addr]; ok {
+		return c, nil
+	}
+	nc, err := net.DialTimeout("tcp", addr, p.timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	p.conns[addr] = c
+	return c, nil
+}
+
+// request sends the request of api with body to the broker at addr, and returns the body of its
+// response. The connection is closed on errors.
+func (p *Producer) request(addr string, api, version int16, body []byte) ([]byte, error) {
+	c, err := p.dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	p.correlation++
+	var e encoder
+	e.int32(0) // The size, set once known.
+	e.int16(api)
+	e.int16(version)
+	e.int32(p.correlation)
+	e.string(p.clientId)
+	e.Write(body)
+	req := e.Bytes()
+	binary.BigEndian.PutUint32(req, uint32(len(req)-4))
+
+	resp, err := func() ([]byte, error) {
+		c.SetDeadline(time.Now().Add(p.timeout))
+		if _, err := c.Write(req); err != nil {
+			return nil, err
+		}
+		var size int32
+		if err := binary.Read(c.r, binary.BigEndian, &size); err != nil {
+			return nil, err
+		}
+		if size < 4 {
+			return nil, x.Errorf("Invalid response size from Kafka broker: %d", size)
+		}
+		resp := make([]byte, size)
+		if _, err := io.ReadFull(c.r, resp); err != nil {
+			return nil, err
+		}
+		if id := int32(binary.BigEndian.Uint32(resp)); id != p.correlation {
+			return nil, x.Errorf("Response %d from Kafka broker, expected %d", id, p.correlation)
+		}
+		return resp[4:], nil
+	}()
+	if err != nil {
+		c.Close()
+		delete(p.conns, addr)
+		return nil, x.Wrapf(err, "While sending request to Kafka broker %s", addr)
+	}
+	return resp, nil
+}
+
+// refreshMetadata gets the brokers of the cluster and the leaders of the partitions of topic from
+// the first broker which answers.
+func (p *Producer) refreshMetadata(topic string) error {
+	var e encoder
+	e.int32(1)
+	e.string(topic)
+	var addrs []string
+	addrs = append(addrs, p.brokers...)
+	for _, addr := range p.addrs {
+		addrs = append(addrs, addr)
+	}
+	var err error
+	for _, addr := range addrs {
+		var resp []byte
+		if resp, err = p.request(addr, apiMetadata, 0, e.Bytes()); err != nil {
+			continue
+		}
+		d := &decoder{b: resp}
+		brokers := make(map[int32]string)
+		d.array(func() {
+			id := d.int32()
+			host := d.string()
+			port := d.int32()
+			brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		})
+		leaders := make(map[string][]int32)
+		var terr error
+		d.array(func() {
+			code := d.int16()
+			name := d.string()
+			var parts []int32
+			d.array(func() {
+				d.int16()
+				id := d.int32()
+				leader := d.int32()
+				d.array(func() { d.int32() })
+				d.array(func() { d.int32() })
+				for int(id) >= len(parts) {
+					parts = append(parts, -1)
+				}
+				parts[id] = leader
+			})
+			if code != 0 {
+				terr = x.Errorf("Error %d getting metadata of Kafka topic %q", code, name)
+			}
+			leaders[name] = parts
+		})
+		if d.err != nil {
+			err = d.err
+			continue
+		}
+		if terr != nil {
+			return terr
+		}
+		if len(leaders[topic]) == 0 {
+			return x.Errorf("Kafka topic %q has no partitions", topic)
+		}
+		p.addrs = brokers
+		p.leaders[topic] = leaders[topic]
+		return nil
+	}
+	if err == nil {
+		err = x.Errorf("No Kafka brokers to get metadata from")
+	}
+	return err
+}
+
+// murmur2 is the hash of keys of the default partitioner of the Java client.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// partition returns the partition of key, among n.
+func partition(key []byte, n int) int32 {
+	return (murmur2(key) & 0x7fffffff) % int32(n)
+}
+
+// messageSet encodes msgs as a message set, with version 1 of the message format.
+func messageSet(msgs []Message) []byte {
+	var set encoder
+	for _, msg := range msgs {
+		var m encoder
+		m.int32(0) // The crc, set once known.
+		m.int8(1)  // Magic.
+		m.int8(0)  // Attributes, without compression.
+		m.int64(msg.Time.UnixNano() / int64(time.Millisecond))
+		m.bytes(msg.Key)
+		m.bytes(msg.Value)
+		b := m.Bytes()
+		binary.BigEndian.PutUint32(b, crc32.ChecksumIEEE(b[4:]))
+
+		set.int64(0) // The offset, assigned by the broker.
+		set.int32(int32(len(b)))
+		set.Write(b)
+	}
+	return set.Bytes()
+}
+
+// Produce publishes msgs to topic, and returns once all the in-sync replicas of their partitions
+// have them. On errors, some of msgs may have been published, and publishing them again publishes
+// them twice.
+func (p *Producer) Produce(topic string, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	p.Lock()
+	defer p.Unlock()
+	var err error
+	for i := 0; i < retries; i++ {
+		if i > 0 || len(p.leaders[topic]) == 0 {
+			if err = p.refreshMetadata(topic); err != nil {
+				continue
+			}
+		}
+		if err = p.produce(topic, msgs); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (p *Producer) produce(topic string, msgs []Message) error {
+	leaders := p.leaders[topic]
+	parts := make(map[int32][]Message)
+	for _, msg := range msgs {
+		part := partition(msg.Key, len(leaders))
+		parts[part] = append(parts[part], msg)
+	}
+	// The partitions of each broker are sent in one request.
+	byBroker := make(map[int32][]int32)
+	for part := range parts {
+		leader := leaders[part]
+		if leader < 0 {
+			return x.Errorf("Partition %d of Kafka topic %q has no leader", part, topic)
+		}
+		byBroker[leader] = append(byBroker[leader], part)
+	}
+	for broker, ids := range byBroker {
+		addr, ok := p.addrs[broker]
+		if !ok {
+			return x.Errorf("Unknown Kafka broker %d", broker)
+		}
+		var e encoder
+		e.int16(-1) // Acks from all the in-sync replicas.
+		e.int32(int32(p.timeout / time.Millisecond))
+		e.int32(1)
+		e.string(topic)
+		e.int32(int32(len(ids)))
+		for _, id := range ids {
+			e.int32(id)
+			e.bytes(messageSet(parts[id]))
+		}
+		resp, err := p.request(addr, apiProduce, 2, e.Bytes())
+		if err != nil {
+			return err
+		}
+		d := &decoder{b: resp}
+		var perr error
+		d.array(func() {
+			d.string()
+			d.array(func() {
+				id := d.int32()
+				code := d.int16()
+				d.int64()
+				d.int64()
+				if code != 0 && perr == nil {
+					switch code {
+					case errUnknownTopicOrPartition, errLeaderNotAvailable,
+						errNotLeaderForPartition:
+						delete(p.leaders, topic)
+					}
+					perr = x.Errorf("Error %d publishing to partition %d of Kafka topic %q", code,
+						id, topic)
+				}
+			})
+		})
+		if d.err != nil {
+			return d.err
+		}
+		if perr != nil {
+			return perr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kafka
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMurmur2(t *testing.T) {
+	// The hashes of the tests of the Java client.
+	for _, c := range []struct {
+		s string
+		h int32
+	}{
+		{"21", -973932308},
+		{"foobar", -790332482},
+		{"a-little-bit-long-string", -985981536},
+		{"a-little-bit-longer-string", -1486304829},
+		{"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", -58897971},
+		{"abc", 479470107},
+	} {
+		require.Equal(t, c.h, murmur2([]byte(c.s)), c.s)
+	}
+}
+
+type published struct {
+	partition  int32
+	key, value string
+}
+
+// broker is a broker with a topic of two partitions, which it leads.
+type broker struct {
+	l     net.Listener
+	topic string
+	// notLeader is the number of produce requests answered with errNotLeaderForPartition.
+	notLeader int
+
+	sync.Mutex
+	msgs []published
+}
+
+func (b *broker) serve(t *testing.T) {
+	for {
+		c, err := b.l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer c.Close()
+			for {
+				var size int32
+				if err := binary.Read(c, binary.BigEndian, &size); err != nil {
+					return
+				}
+				req := make([]byte, size)
+				if _, err := io.ReadFull(c, req); err != nil {
+					return
+				}
+				d := &decoder{b: req}
+				api, _, id := d.int16(), d.int16(), d.int32()
+				d.string()
+				var resp encoder
+				resp.int32(0)
+				resp.int32(id)
+				switch api {
+				case apiMetadata:
+					b.metadata(&resp)
+				case apiProduce:
+					b.produce(t, d, &resp)
+				}
+				out := resp.Bytes()
+				binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+				if _, err := c.Write(out); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (b *broker) metadata(resp *encoder) {
+	host, port, _ := net.SplitHostPort(b.l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	resp.int32(1)
+	resp.int32(7)
+	resp.string(host)
+	resp.int32(int32(p))
+	resp.int32(1)
+	resp.int16(0)
+	resp.string(b.topic)
+	resp.int32(2)
+	for i := int32(0); i < 2; i++ {
+		resp.int16(0)
+		resp.int32(i)
+		resp.int32(7)
+		resp.int32(1)
+		resp.int32(7)
+		resp.int32(1)
+		resp.int32(7)
+	}
+}
+
+func (b *broker) produce(t *testing.T, d *decoder, resp *encoder) {
+	b.Lock()
+	defer b.Unlock()
+	require.Equal(t, int16(-1), d.int16())
+	d.int32()
+	var results []int32
+	d.array(func() {
+		require.Equal(t, b.topic, d.string())
+		d.array(func() {
+			part := d.int32()
+			set := &decoder{b: d.next(int(d.int32()))}
+			results = append(results, part)
+			if b.notLeader > 0 {
+				return
+			}
+			for len(set.b) > 0 && set.err == nil {
+				set.int64()
+				m := set.next(int(set.int32()))
+				require.Equal(t, binary.BigEndian.Uint32(m), crc32.ChecksumIEEE(m[4:]))
+				md := &decoder{b: m[4:]}
+				require.Equal(t, byte(1), md.next(1)[0])
+				md.next(1)
+				md.int64()
+				key := md.next(int(md.int32()))
+				value := md.next(int(md.int32()))
+				require.NoError(t, md.err)
+				b.msgs = append(b.msgs, published{part, string(key), string(value)})
+			}
+			require.NoError(t, set.err)
+		})
+	})
+	require.NoError(t, d.err)
+
+	code := int16(0)
+	if b.notLeader > 0 {
+		b.notLeader--
+		code = errNotLeaderForPartition
+	}
+	resp.int32(1)
+	resp.string(b.topic)
+	resp.int32(int32(len(results)))
+	for _, part := range results {
+		resp.int32(part)
+		resp.int16(code)
+		resp.int64(0)
+		resp.int64(-1)
+	}
+	resp.int32(0)
+}
+
+func TestProduce(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	b := &broker{l: l, topic: "changes", notLeader: 1}
+	go b.serve(t)
+
+	p := NewProducer([]string{l.Addr().String()}, "test", 5*time.Second)
+	defer p.Close()
+	var msgs []Message
+	for _, k := range []string{"0x1", "0x2", "0x1", "0x3"} {
+		msgs = append(msgs, Message{Key: []byte(k), Value: []byte("value of " + k),
+			Time: time.Now()})
+	}
+	// The first request is refused by the leader, and sent again after refreshing the metadata.
+	require.NoError(t, p.Produce("changes", msgs))
+
+	b.Lock()
+	defer b.Unlock()
+	require.Len(t, b.msgs, 4)
+	parts := make(map[string]int32)
+	for _, m := range b.msgs {
+		require.Equal(t, "value of "+m.key, m.value)
+		require.Equal(t, partition([]byte(m.key), 2), m.partition)
+		if part, ok := parts[m.key]; ok {
+			require.Equal(t, part, m.partition)
+		}
+		parts[m.key] = m.partition
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package objstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const azureVersion = "2019-12-12"
+
+// container is a container of Azure Blob Storage, accessed with the shared key of its account.
+type container struct {
+	name     string
+	account  string
+	key      []byte
+	endpoint string
+}
+
+func openAzure(rest string) (BlobStore, string, error) {
+	name, key, err := splitBucket(rest)
+	if err != nil {
+		return nil, "", err
+	}
+	c := &container{
+		name:     name,
+		account:  os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		endpoint: os.Getenv("AZURE_STORAGE_ENDPOINT"),
+	}
+	secret := os.Getenv("AZURE_STORAGE_KEY")
+	if c.account == "" || secret == "" {
+		return nil, "", x.Errorf("Missing credentials: AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY")
+	}
+	if c.key, err = base64.StdEncoding.DecodeString(secret); err != nil {
+		return nil, "", x.Errorf("Invalid AZURE_STORAGE_KEY: it should be base64 encoded")
+	}
+	if c.endpoint == "" {
+		c.endpoint = "https://" + c.account + ".blob.core.windows.net"
+	}
+	return c, key, nil
+}
+
+func (c *container) url(key string, query url.Values) string {
+	u := strings.TrimSuffix(c.endpoint, "/") + "/" + c.name + "/" + escapePath(key)
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+	return u
+}
+
+// signAzure signs req with the shared key of account, over all the x-ms- headers already set on
+// req.
+func signAzure(req *http.Request, account string, key []byte, t time.Time) {
+	req.Header.Set("X-Ms-Date", t.UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureVersion)
+
+	var length string
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+	h := req.Header
+	var buf bytes.Buffer
+	for _, v := range []string{req.Method, h.Get("Content-Encoding"), h.Get("Content-Language"),
+		length, h.Get("Content-MD5"), h.Get("Content-Type"), "", h.Get("If-Modified-Since"),
+		h.Get("If-Match"), h.Get("If-None-Match"), h.Get("If-Unmodified-Since"), h.Get("Range")} {
+		buf.WriteString(v + "\n")
+	}
+	headers := make(map[string]string)
+	for k, v := range h {
+		if lk := strings.ToLower(k); strings.HasPrefix(lk, "x-ms-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		buf.WriteString(k + ":" + headers[k] + "\n")
+	}
+
+	buf.WriteString("/" + account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		vals := append([]string(nil), query[k]...)
+		sort.Strings(vals)
+		buf.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(vals, ","))
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(buf.Bytes())
+	req.Header.Set("Authorization",
+		"SharedKey "+account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// encryptionHeaders sets the encryption scope of a new blob, given as the key of
+// Config.Encryption. Blobs are always encrypted with Microsoft managed keys otherwise.
+func (c *container) encryptionHeaders(h http.Header) {
+	if strings.HasPrefix(Config.Encryption, "aws:kms:") {
+		h.Set("X-Ms-Encryption-Scope", Config.Encryption[len("aws:kms:"):])
+	}
+}
+
+// do runs a request for key, retrying in case of network errors and server errors. The response
+// body has to be closed by the caller, if err is nil.
+func (c *container) do(method, key string, query url.Values, header http.Header,
+	body []byte) (*http.Response, error) {
+	backoff := 100 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt <= Config.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		req, err := http.NewRequest(method, c.url(key, query), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		signAzure(req, c.account, c.key, time.Now())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusNotFound {
+			return resp, nil
+		}
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		lastErr = x.Errorf("%s of blob %q in container %q failed with status %s: %s",
+			method, key, c.name, resp.Status, msg)
+		if !retryable(resp.StatusCode) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// Create returns a writer uploading the blob at key in blocks of Config.PartSize.
+func (c *container) Create(key string) (io.WriteCloser, error) {
+	return &azureWriter{c: c, key: key}, nil
+}
+
+func (c *container) Open(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	return resp.Body, nil
+}
+
+func (c *container) Put(key string, data []byte) error {
+	h := http.Header{"X-Ms-Blob-Type": {"BlockBlob"}}
+	c.encryptionHeaders(h)
+	resp, err := c.do(http.MethodPut, key, nil, h, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return x.Errorf("Container %q not found", c.name)
+	}
+	return nil
+}
+
+type blockList struct {
+	XMLName xml.Name `xml:"BlockList"`
+	Latest  []string `xml:"Latest"`
+}
+
+// azureWriter uploads a blob in blocks of Config.PartSize, committed together on Close. Blobs
+// smaller than a block are uploaded with a single request on Close. Blocks which aren't committed
+// are deleted by Azure after a week.
+type azureWriter struct {
+	c      *container
+	key    string
+	buf    bytes.Buffer
+	blocks []string
+	closed bool
+	err    error
+}
+
+func (w *azureWriter) putBlock(data []byte) error {
+	// The ids of the blocks of a blob all have the same length.
+	id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", len(w.blocks))))
+	h := make(http.Header)
+	w.c.encryptionHeaders(h)
+	q := url.Values{"comp": {"block"}, "blockid": {id}}
+	resp, err := w.c.do(http.MethodPut, w.key, q, h, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return x.Errorf("Container %q not found", w.c.name)
+	}
+	w.blocks = append(w.blocks, id)
+	return nil
+}
+
+func (w *azureWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf.Write(p)
+	for w.buf.Len() >= Config.PartSize {
+		if w.err = w.putBlock(w.buf.Next(Config.PartSize)); w.err != nil {
+			return 0, w.err
+		}
+		// Next leaves the read data behind in the buffer.
+		rest := append([]byte(nil), w.buf.Bytes()...)
+		w.buf.Reset()
+		w.buf.Write(rest)
+	}
+	return len(p), nil
+}
+
+func (w *azureWriter) Close() error {
+	if w.err != nil || w.closed {
+		return w.err
+	}
+	w.closed = true
+	if len(w.blocks) == 0 {
+		w.err = w.c.Put(w.key, w.buf.Bytes())
+		return w.err
+	}
+	if w.buf.Len() > 0 {
+		if w.err = w.putBlock(w.buf.Bytes()); w.err != nil {
+			return w.err
+		}
+	}
+	body, err := xml.Marshal(blockList{Latest: w.blocks})
+	x.Check(err)
+	h := make(http.Header)
+	w.c.encryptionHeaders(h)
+	resp, err := w.c.do(http.MethodPut, w.key, url.Values{"comp": {"blocklist"}}, h,
+		append([]byte(xml.Header), body...))
+	if err != nil {
+		w.err = err
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		w.err = x.Errorf("Container %q not found", w.c.name)
+	}
+	return w.err
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package objstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Data keys for encrypting files are generated and decrypted by AWS KMS, over its JSON API, with
+// the same credentials as S3. KMS_ENDPOINT can point at a KMS compatible service.
+
+func kmsCall(target string, in, out interface{}) error {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	keyID, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if keyID == "" || secret == "" {
+		return x.Errorf("Missing credentials for KMS")
+	}
+	endpoint := os.Getenv("KMS_ENDPOINT")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", region)
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	payloadHash := sha256Hex(body)
+	backoff := 100 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt <= Config.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", "TrentService."+target)
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		sign(req, payloadHash, keyID, secret, region, "kms", time.Now())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode/100 == 2 {
+			err := json.NewDecoder(resp.Body).Decode(out)
+			resp.Body.Close()
+			return err
+		}
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		lastErr = x.Errorf("KMS %s failed with status %s: %s", target, resp.Status, msg)
+		if !retryable(resp.StatusCode) {
+			break
+		}
+	}
+	return lastErr
+}
+
+// GenerateDataKey returns a new 256 bit key, along with the key encrypted by the KMS key with the
+// given id, ARN or alias.
+func GenerateDataKey(kmsKey string) (key, encrypted []byte, rerr error) {
+	var out struct {
+		CiphertextBlob []byte
+		Plaintext      []byte
+	}
+	in := map[string]string{"KeyId": kmsKey, "KeySpec": "AES_256"}
+	if err := kmsCall("GenerateDataKey", in, &out); err != nil {
+		return nil, nil, err
+	}
+	if len(out.Plaintext) != 32 || len(out.CiphertextBlob) == 0 {
+		return nil, nil, x.Errorf("Invalid data key from KMS")
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+// DecryptDataKey decrypts a key returned by GenerateDataKey. The encrypted key says which KMS key
+// it was encrypted with.
+func DecryptDataKey(encrypted []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte
+	}
+	in := map[string][]byte{"CiphertextBlob": encrypted}
+	if err := kmsCall("Decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package objstore
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// local is the store of local files, with their paths as keys.
+type local struct{}
+
+func (local) Create(key string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(key), 0700); err != nil {
+		return nil, err
+	}
+	return os.Create(key)
+}
+
+func (local) Open(key string) (io.ReadCloser, error) {
+	f, err := os.Open(key)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Put writes data to a temporary file first, so that a failed write doesn't lose the file.
+func (local) Put(key string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(key), 0700); err != nil {
+		return err
+	}
+	tmp := key + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, key)
+}
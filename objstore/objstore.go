@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package objstore reads and writes objects in blob stores: local folders, S3, GCS and Azure
+// buckets, and any other store registered with RegisterBlobStore. Objects in buckets are given by
+// URIs like s3://bucket/path/to/object, gs://bucket/path/to/object and az://container/path, and
+// any other path is a local file, so that exports, backups and changelog archives can be written
+// to object storage directly. S3 and GCS are accessed over the S3 XML API, which GCS supports with
+// HMAC keys.
+//
+// Credentials are taken from the environment: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// optionally AWS_SESSION_TOKEN and AWS_REGION for S3, GCS_ACCESS_KEY_ID and
+// GCS_SECRET_ACCESS_KEY for GCS, and AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY for Azure.
+// S3_ENDPOINT can point s3:// URIs at an S3 compatible store, and AZURE_STORAGE_ENDPOINT az://
+// URIs at an Azure compatible one.
+package objstore
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type Options struct {
+	// Server side encryption of written objects. Either empty, "AES256", "aws:kms", or
+	// "aws:kms:<key id>".
+	Encryption string
+	// Size of the parts of multipart uploads.
+	PartSize int
+	// Number of times a request is retried before giving up.
+	Retries int
+}
+
+var Config = Options{
+	PartSize: 16 << 20,
+	Retries:  5,
+}
+
+// ErrNotFound is returned by Open if the object doesn't exist.
+var ErrNotFound = errors.New("Object not found")
+
+// BlobStore keeps objects under keys, like the files of a folder.
+type BlobStore interface {
+	// Create returns a writer for the object at key, which only exists once the writer has been
+	// closed without error.
+	Create(key string) (io.WriteCloser, error)
+	// Open returns a reader for the object at key, or ErrNotFound.
+	Open(key string) (io.ReadCloser, error)
+	// Put replaces the object at key with data at once.
+	Put(key string, data []byte) error
+}
+
+// OpenFunc returns the store of the rest of a URI after its scheme, and the key of the object in
+// it.
+type OpenFunc func(rest string) (BlobStore, string, error)
+
+var stores = struct {
+	sync.RWMutex
+	m map[string]OpenFunc
+}{m: make(map[string]OpenFunc)}
+
+// RegisterBlobStore has the URIs starting with scheme://, like s3://bucket/key, refer to objects
+// of the stores returned by open.
+func RegisterBlobStore(scheme string, open OpenFunc) {
+	stores.Lock()
+	defer stores.Unlock()
+	x.AssertTruef(stores.m[scheme] == nil, "Blob store %s registered twice", scheme)
+	stores.m[scheme] = open
+}
+
+func init() {
+	RegisterBlobStore("s3", openS3)
+	RegisterBlobStore("gs", openGCS)
+	RegisterBlobStore("az", openAzure)
+}
+
+// scheme returns the scheme of the URI p, or an empty string if p is a local path.
+func scheme(p string) string {
+	idx := strings.Index(p, "://")
+	if idx <= 0 {
+		return ""
+	}
+	stores.RLock()
+	defer stores.RUnlock()
+	if stores.m[p[:idx]] == nil {
+		return ""
+	}
+	return p[:idx]
+}
+
+// IsURI returns whether p refers to an object in a bucket, rather than to a local file.
+func IsURI(p string) bool {
+	return scheme(p) != ""
+}
+
+// Join joins dir and name, like path.Join does for local paths.
+func Join(dir, name string) string {
+	if !IsURI(dir) {
+		return path.Join(dir, name)
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + strings.TrimPrefix(name, "/")
+}
+
+// ValidateEncryption returns an error if the given server side encryption isn't supported.
+func ValidateEncryption(enc string) error {
+	if enc == "" || enc == "AES256" || enc == "aws:kms" || strings.HasPrefix(enc, "aws:kms:") {
+		return nil
+	}
+	return x.Errorf("Invalid server side encryption: %q. Use AES256, aws:kms or aws:kms:<key>",
+		enc)
+}
+
+// splitBucket splits the rest of a URI after its scheme into a bucket and a key.
+func splitBucket(rest string) (string, string, error) {
+	idx := strings.IndexByte(rest, '/')
+	if idx <= 0 || idx == len(rest)-1 {
+		return "", "", x.Errorf("Object URI should be of the form scheme://bucket/key")
+	}
+	return rest[:idx], rest[idx+1:], nil
+}
+
+// open returns the store of the object at p, and its key in it.
+func open(p string) (BlobStore, string, error) {
+	s := scheme(p)
+	if s == "" {
+		return local{}, p, nil
+	}
+	stores.RLock()
+	f := stores.m[s]
+	stores.RUnlock()
+	bs, key, err := f(p[len(s)+len("://"):])
+	if err != nil {
+		return nil, "", x.Wrapf(err, "While opening %q", p)
+	}
+	return bs, key, nil
+}
+
+// Create returns a writer for the object or file at p. Objects in buckets are uploaded in parts
+// as they're being written, and only exist once the writer has been closed without error.
+func Create(p string) (io.WriteCloser, error) {
+	bs, key, err := open(p)
+	if err != nil {
+		return nil, err
+	}
+	return bs.Create(key)
+}
+
+// Open returns a reader for the object or file at p, or ErrNotFound.
+func Open(p string) (io.ReadCloser, error) {
+	bs, key, err := open(p)
+	if err != nil {
+		return nil, err
+	}
+	return bs.Open(key)
+}
+
+// Put replaces the object or file at p with data at once.
+func Put(p string, data []byte) error {
+	bs, key, err := open(p)
+	if err != nil {
+		return err
+	}
+	return bs.Put(key, data)
+}
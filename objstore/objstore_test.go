@@ -0,0 +1,304 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package objstore
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// The get-vanilla case of the AWS signature version 4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	sign(req, sha256Hex(nil), "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	require.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/"+
+		"aws4_request, SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestJoin(t *testing.T) {
+	require.Equal(t, "s3://bucket/backup/group-1", Join("s3://bucket/backup/", "group-1"))
+	require.Equal(t, "backup/group-1", Join("backup", "group-1"))
+	require.True(t, IsURI("gs://bucket/x"))
+	require.False(t, IsURI("/tmp/x"))
+}
+
+// fakeStore is an S3 endpoint keeping objects in memory, which fails every other request.
+type fakeStore struct {
+	sync.Mutex
+	objects map[string][]byte
+	parts   map[string][]byte
+	fail    bool
+	sse     []string
+}
+
+func (s *fakeStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if s.fail = !s.fail; s.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	q := r.URL.Query()
+	key := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && q.Get("uploadId") == "" && r.URL.RawQuery == "uploads=":
+		s.sse = append(s.sse, r.Header.Get("X-Amz-Server-Side-Encryption"))
+		fmt.Fprint(w, "<InitiateMultipartUploadResult><UploadId>up</UploadId>"+
+			"</InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && q.Get("uploadId") == "up":
+		part := key + "#" + q.Get("partNumber")
+		s.parts[part] = body
+		w.Header().Set("ETag", `"`+part+`"`)
+	case r.Method == http.MethodPost && q.Get("uploadId") == "up":
+		var c completeUpload
+		if err := xml.Unmarshal(body, &c); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var obj []byte
+		for _, p := range c.Parts {
+			obj = append(obj, s.parts[strings.Trim(p.ETag, `"`)]...)
+		}
+		s.objects[key] = obj
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodPut:
+		s.sse = append(s.sse, r.Header.Get("X-Amz-Server-Side-Encryption"))
+		s.objects[key] = body
+	case r.Method == http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(obj)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	store := &fakeStore{objects: make(map[string][]byte), parts: make(map[string][]byte)}
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+
+	for k, v := range map[string]string{
+		"S3_ENDPOINT": srv.URL, "AWS_ACCESS_KEY_ID": "key", "AWS_SECRET_ACCESS_KEY": "secret"} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	Config.PartSize = 5
+	Config.Encryption = "AES256"
+	defer func() {
+		Config.PartSize = 16 << 20
+		Config.Encryption = ""
+	}()
+
+	w, err := Create("s3://bucket/export/big")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("hello "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("world!"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, "hello world!", string(store.objects["/bucket/export/big"]))
+
+	require.NoError(t, Put("s3://bucket/export/small", []byte("hi")))
+	r, err := Open("s3://bucket/export/small")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	r.Close()
+	require.Equal(t, "hi", string(data))
+	require.Equal(t, []string{"AES256", "AES256"}, store.sse)
+
+	_, err = Open("s3://bucket/export/missing")
+	require.Equal(t, ErrNotFound, err)
+}
+
+// memStore is a blob store keeping objects in memory.
+type memStore map[string][]byte
+
+type memWriter struct {
+	bytes.Buffer
+	s   memStore
+	key string
+}
+
+func (w *memWriter) Close() error {
+	w.s[w.key] = w.Bytes()
+	return nil
+}
+
+func (s memStore) Create(key string) (io.WriteCloser, error) {
+	return &memWriter{s: s, key: key}, nil
+}
+
+func (s memStore) Open(key string) (io.ReadCloser, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s memStore) Put(key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func TestRegisterBlobStore(t *testing.T) {
+	store := make(memStore)
+	RegisterBlobStore("mem", func(rest string) (BlobStore, string, error) {
+		return store, rest, nil
+	})
+	require.True(t, IsURI("mem://backup"))
+	require.False(t, IsURI("other://backup"))
+	require.Equal(t, "mem://backup/group-1", Join("mem://backup", "group-1"))
+
+	w, err := Create("mem://backup/manifest")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("hi"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, "hi", string(store["backup/manifest"]))
+	_, err = Open("mem://backup/missing")
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "objstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := Join(dir, "group-1/manifest")
+	require.NoError(t, Put(fpath, []byte("old")))
+	require.NoError(t, Put(fpath, []byte("new")))
+	r, err := Open(fpath)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	r.Close()
+	require.Equal(t, "new", string(data))
+	_, err = Open(Join(dir, "missing"))
+	require.Equal(t, ErrNotFound, err)
+}
+
+// fakeAzure is an Azure Blob Storage endpoint keeping blobs in memory.
+type fakeAzure struct {
+	sync.Mutex
+	blobs  map[string][]byte
+	blocks map[string][]byte
+}
+
+func (s *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey account:") ||
+		r.Header.Get("X-Ms-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	q := r.URL.Query()
+	key := r.URL.Path
+	switch {
+	case r.Method == http.MethodPut && q.Get("comp") == "block":
+		s.blocks[key+"#"+q.Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && q.Get("comp") == "blocklist":
+		var l blockList
+		if err := xml.Unmarshal(body, &l); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var blob []byte
+		for _, id := range l.Latest {
+			blob = append(blob, s.blocks[key+"#"+id]...)
+		}
+		s.blobs[key] = blob
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.Header.Get("X-Ms-Blob-Type") == "BlockBlob":
+		s.blobs[key] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet:
+		blob, ok := s.blobs[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestAzureUpload(t *testing.T) {
+	store := &fakeAzure{blobs: make(map[string][]byte), blocks: make(map[string][]byte)}
+	srv := httptest.NewServer(store)
+	defer srv.Close()
+
+	for k, v := range map[string]string{"AZURE_STORAGE_ENDPOINT": srv.URL,
+		"AZURE_STORAGE_ACCOUNT": "account", "AZURE_STORAGE_KEY": "c2VjcmV0"} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	Config.PartSize = 5
+	defer func() { Config.PartSize = 16 << 20 }()
+
+	w, err := Create("az://container/export/big")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("hello world!"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, "hello world!", string(store.blobs["/container/export/big"]))
+	require.Len(t, store.blocks, 3)
+
+	require.NoError(t, Put("az://container/export/small", []byte("hi")))
+	r, err := Open("az://container/export/small")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	r.Close()
+	require.Equal(t, "hi", string(data))
+
+	_, err = Open("az://container/export/missing")
+	require.Equal(t, ErrNotFound, err)
+	_, err = Open("az://container")
+	require.Error(t, err)
+}
@@ -0,0 +1,424 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package objstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var client = &http.Client{Timeout: 10 * time.Minute}
+
+type bucket struct {
+	name     string
+	gcs      bool
+	endpoint string // Path style endpoint, or empty for virtual hosted style S3.
+	region   string
+	keyID    string
+	secret   string
+	token    string
+}
+
+func openS3(rest string) (BlobStore, string, error) {
+	name, key, err := splitBucket(rest)
+	if err != nil {
+		return nil, "", err
+	}
+	b := &bucket{
+		name:     name,
+		region:   os.Getenv("AWS_REGION"),
+		keyID:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secret:   os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:    os.Getenv("AWS_SESSION_TOKEN"),
+		endpoint: os.Getenv("S3_ENDPOINT"),
+	}
+	if b.region == "" {
+		b.region = "us-east-1"
+	}
+	if b.keyID == "" || b.secret == "" {
+		return nil, "", x.Errorf("Missing credentials: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return b, key, nil
+}
+
+func openGCS(rest string) (BlobStore, string, error) {
+	name, key, err := splitBucket(rest)
+	if err != nil {
+		return nil, "", err
+	}
+	b := &bucket{
+		name:     name,
+		gcs:      true,
+		region:   "auto",
+		keyID:    os.Getenv("GCS_ACCESS_KEY_ID"),
+		secret:   os.Getenv("GCS_SECRET_ACCESS_KEY"),
+		endpoint: "https://storage.googleapis.com",
+	}
+	if b.keyID == "" || b.secret == "" {
+		return nil, "", x.Errorf("Missing credentials: GCS_ACCESS_KEY_ID and GCS_SECRET_ACCESS_KEY")
+	}
+	return b, key, nil
+}
+
+func (b *bucket) url(key string, query url.Values) string {
+	var u string
+	if b.endpoint != "" {
+		u = strings.TrimSuffix(b.endpoint, "/") + "/" + b.name + "/" + escapePath(key)
+	} else {
+		u = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.name, b.region, escapePath(key))
+	}
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+	return u
+}
+
+func encode(s string, slash bool) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (slash && c == '/') {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func escapePath(p string) string {
+	return encode(p, true)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), query[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, encode(k, false)+"="+encode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign signs req with AWS signature version 4, over the host header and all the x-amz- headers
+// already set on req.
+func sign(req *http.Request, payloadHash, keyID, secret, region, service string, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") || strings.HasPrefix(lk, "x-goog-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders bytes.Buffer
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+	canonReq := strings.Join([]string{
+		req.Method,
+		uri,
+		canonicalQuery(req.URL.Query()),
+		canonHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonReq))
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		keyID, scope, signedHeaders, sig))
+}
+
+// encryptionHeaders sets the headers for server side encryption of a new object.
+func (b *bucket) encryptionHeaders(h http.Header) {
+	enc := Config.Encryption
+	switch {
+	case enc == "":
+	case b.gcs && strings.HasPrefix(enc, "aws:kms:"):
+		h.Set("X-Goog-Encryption-Kms-Key-Name", enc[len("aws:kms:"):])
+	case b.gcs:
+		// Objects in GCS are always encrypted with Google managed keys.
+	case strings.HasPrefix(enc, "aws:kms:"):
+		h.Set("X-Amz-Server-Side-Encryption", "aws:kms")
+		h.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", enc[len("aws:kms:"):])
+	default:
+		h.Set("X-Amz-Server-Side-Encryption", enc)
+	}
+}
+
+func retryable(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests
+}
+
+// do runs a request for key, retrying in case of network errors and server errors. The response
+// body has to be closed by the caller, if err is nil.
+func (b *bucket) do(method, key string, query url.Values, header http.Header,
+	body []byte) (*http.Response, error) {
+	payloadHash := sha256Hex(body)
+	backoff := 100 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt <= Config.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		req, err := http.NewRequest(method, b.url(key, query), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+		if b.token != "" {
+			req.Header.Set("X-Amz-Security-Token", b.token)
+		}
+		sign(req, payloadHash, b.keyID, b.secret, b.region, "s3", time.Now())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusNotFound {
+			return resp, nil
+		}
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		lastErr = x.Errorf("%s of object %q in bucket %q failed with status %s: %s",
+			method, key, b.name, resp.Status, msg)
+		if !retryable(resp.StatusCode) {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// Create returns a writer uploading the object at key in parts of Config.PartSize.
+func (b *bucket) Create(key string) (io.WriteCloser, error) {
+	return &writer{b: b, key: key}, nil
+}
+
+func (b *bucket) Open(key string) (io.ReadCloser, error) {
+	resp, err := b.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	return resp.Body, nil
+}
+
+func (b *bucket) Put(key string, data []byte) error {
+	h := make(http.Header)
+	b.encryptionHeaders(h)
+	resp, err := b.do(http.MethodPut, key, nil, h, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return x.Errorf("Bucket %q not found", b.name)
+	}
+	return nil
+}
+
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+type completeUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// writer uploads an object in parts of Config.PartSize. Objects smaller than a part are uploaded
+// with a single request on Close.
+type writer struct {
+	b        *bucket
+	key      string
+	buf      bytes.Buffer
+	uploadID string
+	parts    []completedPart
+	closed   bool
+	err      error
+}
+
+func (w *writer) initiate() error {
+	h := make(http.Header)
+	w.b.encryptionHeaders(h)
+	resp, err := w.b.do(http.MethodPost, w.key, url.Values{"uploads": {""}}, h, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		UploadId string
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return x.Wrapf(err, "While starting upload of object %q", w.key)
+	}
+	if res.UploadId == "" {
+		return x.Errorf("No upload id for object %q in bucket %q", w.key, w.b.name)
+	}
+	w.uploadID = res.UploadId
+	return nil
+}
+
+func (w *writer) uploadPart(data []byte) error {
+	if w.uploadID == "" {
+		if err := w.initiate(); err != nil {
+			return err
+		}
+	}
+	num := len(w.parts) + 1
+	q := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {w.uploadID}}
+	resp, err := w.b.do(http.MethodPut, w.key, q, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode == http.StatusNotFound || etag == "" {
+		return x.Errorf("Upload of part %d of object %q failed", num, w.key)
+	}
+	w.parts = append(w.parts, completedPart{PartNumber: num, ETag: etag})
+	return nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf.Write(p)
+	for w.buf.Len() >= Config.PartSize {
+		if w.err = w.uploadPart(w.buf.Next(Config.PartSize)); w.err != nil {
+			w.abort()
+			return 0, w.err
+		}
+		// Next leaves the read data behind in the buffer.
+		rest := append([]byte(nil), w.buf.Bytes()...)
+		w.buf.Reset()
+		w.buf.Write(rest)
+	}
+	return len(p), nil
+}
+
+func (w *writer) abort() {
+	if w.uploadID == "" {
+		return
+	}
+	resp, err := w.b.do(http.MethodDelete, w.key, url.Values{"uploadId": {w.uploadID}}, nil, nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (w *writer) Close() error {
+	if w.err != nil || w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.uploadID == "" {
+		w.err = w.b.Put(w.key, w.buf.Bytes())
+		return w.err
+	}
+	if w.buf.Len() > 0 {
+		if w.err = w.uploadPart(w.buf.Bytes()); w.err != nil {
+			w.abort()
+			return w.err
+		}
+	}
+	body, err := xml.Marshal(completeUpload{Parts: w.parts})
+	x.Check(err)
+	resp, err := w.b.do(http.MethodPost, w.key, url.Values{"uploadId": {w.uploadID}}, nil, body)
+	if err != nil {
+		w.abort()
+		w.err = err
+		return err
+	}
+	defer resp.Body.Close()
+	// Completing an upload can fail after a 200 status, with an error in the body.
+	var res struct {
+		XMLName xml.Name
+		Message string
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err == nil && res.XMLName.Local == "Error" {
+		w.err = x.Errorf("Upload of object %q failed: %s", w.key, res.Message)
+	}
+	return w.err
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package parquet is a minimal writer of Parquet files. Files have flat schemas of required or
+// optional columns, and rows are buffered into row groups, with a single data page per column
+// chunk, PLAIN encoded and compressed with gzip, which every reader supports. The metadata is
+// encoded with the compact protocol of Thrift, as the format defines.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Type is the physical type of the values of a column.
+type Type int32
+
+const (
+	Boolean   Type = 0
+	Int64     Type = 2
+	Double    Type = 5
+	ByteArray Type = 6
+)
+
+// Logical is how the values of a column are meant to be read.
+type Logical int
+
+const (
+	None Logical = iota
+	UTF8
+	JSON
+	TimestampMillis
+)
+
+// Converted types of the format, by logical type.
+var convertedTypes = map[Logical]int32{
+	UTF8:            0,
+	TimestampMillis: 9,
+	JSON:            19,
+}
+
+const (
+	magic = "PAR1"
+
+	encodingPlain = 0
+	encodingRLE   = 3
+	codecGzip     = 2
+	pageData      = 0
+	required      = 0
+	optional      = 1
+)
+
+// Column is a column of a file.
+type Column struct {
+	Name     string
+	Type     Type
+	Logical  Logical
+	Optional bool
+}
+
+type chunk struct {
+	levels []bool // Whether each value is defined, for optional columns.
+	values bytes.Buffer
+	bits   uint  // Booleans in the last byte of values.
+	n      int32 // Values, nulls included.
+}
+
+type rowGroup struct {
+	numRows int64
+	size    int64
+	chunks  []chunkMeta
+}
+
+type chunkMeta struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+	numValues        int64
+}
+
+// Writer writes a file to an io.Writer. It isn't safe for concurrent use.
+type Writer struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	rowGroupSize int
+	chunks       []chunk
+	rows         int
+	groups       []rowGroup
+	err          error
+}
+
+// NewWriter returns a writer of a file with the given columns to w, with row groups of up to
+// rowGroupSize rows.
+func NewWriter(w io.Writer, columns []Column, rowGroupSize int) *Writer {
+	pw := &Writer{
+		w:            w,
+		columns:      columns,
+		rowGroupSize: rowGroupSize,
+		chunks:       make([]chunk, len(columns)),
+	}
+	pw.write([]byte(magic))
+	return pw
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	var n int
+	n, w.err = w.w.Write(b)
+	w.offset += int64(n)
+}
+
+// Write adds a row, with a value for each column: a bool, int64, float64, or string or []byte
+// depending on its type, or nil for a null in an optional column.
+func (w *Writer) Write(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return x.Errorf("Row has %d values, expected %d", len(row), len(w.columns))
+	}
+	// Rows are checked before any of their values is added, so that a bad row isn't half written.
+	for i, col := range w.columns {
+		if err := check(col, row[i]); err != nil {
+			return err
+		}
+	}
+	for i, col := range w.columns {
+		w.chunks[i].add(col, row[i])
+	}
+	if w.rows++; w.rows >= w.rowGroupSize {
+		return w.flush()
+	}
+	return w.err
+}
+
+func check(col Column, v interface{}) error {
+	var ok bool
+	switch v.(type) {
+	case nil:
+		if !col.Optional {
+			return x.Errorf("Null value in required column %s", col.Name)
+		}
+		return nil
+	case bool:
+		ok = col.Type == Boolean
+	case int64:
+		ok = col.Type == Int64
+	case float64:
+		ok = col.Type == Double
+	case string, []byte:
+		ok = col.Type == ByteArray
+	}
+	if !ok {
+		return x.Errorf("Invalid value %v of type %T in column %s", v, v, col.Name)
+	}
+	return nil
+}
+
+func (c *chunk) add(col Column, v interface{}) {
+	c.n++
+	if col.Optional {
+		c.levels = append(c.levels, v != nil)
+	}
+	switch v := v.(type) {
+	case bool:
+		if c.bits%8 == 0 {
+			c.values.WriteByte(0)
+		}
+		if v {
+			c.values.Bytes()[c.values.Len()-1] |= 1 << (c.bits % 8)
+		}
+		c.bits++
+	case int64:
+		binary.Write(&c.values, binary.LittleEndian, v)
+	case float64:
+		binary.Write(&c.values, binary.LittleEndian, math.Float64bits(v))
+	case string:
+		binary.Write(&c.values, binary.LittleEndian, uint32(len(v)))
+		c.values.WriteString(v)
+	case []byte:
+		binary.Write(&c.values, binary.LittleEndian, uint32(len(v)))
+		c.values.Write(v)
+	}
+}
+
+// rle encodes levels, with a bit width of 1, with the RLE hybrid encoding, in runs of equal
+// values, after the length of the encoded runs.
+func rle(levels []bool) []byte {
+	var runs bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		runs.Write(tmp[:binary.PutUvarint(tmp[:], uint64(j-i)<<1)])
+		if levels[i] {
+			runs.WriteByte(1)
+		} else {
+			runs.WriteByte(0)
+		}
+		i = j
+	}
+	b := make([]byte, 4, 4+runs.Len())
+	binary.LittleEndian.PutUint32(b, uint32(runs.Len()))
+	return append(b, runs.Bytes()...)
+}
+
+// flush writes the buffered rows as a row group.
+func (w *Writer) flush() error {
+	if w.rows == 0 || w.err != nil {
+		return w.err
+	}
+	rg := rowGroup{numRows: int64(w.rows)}
+	for i, col := range w.columns {
+		c := &w.chunks[i]
+		var page bytes.Buffer
+		if col.Optional {
+			page.Write(rle(c.levels))
+		}
+		page.Write(c.values.Bytes())
+
+		var data bytes.Buffer
+		gz := gzip.NewWriter(&data)
+		if _, err := gz.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		var header encoder
+		header.pageHeader(int32(page.Len()), int32(data.Len()), c.n)
+
+		meta := chunkMeta{
+			offset:           w.offset,
+			uncompressedSize: int64(header.Len() + page.Len()),
+			compressedSize:   int64(header.Len() + data.Len()),
+			numValues:        int64(c.n),
+		}
+		w.write(header.Bytes())
+		w.write(data.Bytes())
+		rg.chunks = append(rg.chunks, meta)
+		rg.size += meta.uncompressedSize
+		*c = chunk{}
+	}
+	w.groups = append(w.groups, rg)
+	w.rows = 0
+	return w.err
+}
+
+// Close writes the remaining rows and the footer of the file. It doesn't close the io.Writer.
+func (w *Writer) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	var footer encoder
+	footer.fileMetaData(w.columns, w.groups)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(footer.Len()))
+	w.write(footer.Bytes())
+	w.write(size[:])
+	w.write([]byte(magic))
+	return w.err
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// decoder reads structs written with the compact protocol into maps of their fields, by id.
+type decoder struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (d *decoder) varint() uint64 {
+	v, err := binary.ReadUvarint(d.r)
+	require.NoError(d.t, err)
+	return v
+}
+
+func (d *decoder) zigzag() int64 {
+	v := d.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) value(typ byte) interface{} {
+	switch typ {
+	case tI32, tI64:
+		return d.zigzag()
+	case tBinary:
+		b := make([]byte, d.varint())
+		_, err := d.r.Read(b)
+		require.NoError(d.t, err)
+		return string(b)
+	case tList:
+		h, err := d.r.ReadByte()
+		require.NoError(d.t, err)
+		n := uint64(h >> 4)
+		if n == 15 {
+			n = d.varint()
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			l[i] = d.value(h & 0xf)
+		}
+		return l
+	case tStruct:
+		return d.strct()
+	}
+	d.t.Fatalf("Unexpected type %d", typ)
+	return nil
+}
+
+func (d *decoder) strct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		h, err := d.r.ReadByte()
+		require.NoError(d.t, err)
+		if h == 0 {
+			return fields
+		}
+		if delta := int16(h >> 4); delta > 0 {
+			id += delta
+		} else {
+			id = int16(d.zigzag())
+		}
+		fields[id] = d.value(h & 0xf)
+	}
+}
+
+func get(v interface{}, ids ...int16) interface{} {
+	for _, id := range ids {
+		v = v.(map[int16]interface{})[id]
+	}
+	return v
+}
+
+func TestWriter(t *testing.T) {
+	cols := []Column{
+		{Name: "uid", Type: Int64},
+		{Name: "value", Type: ByteArray, Logical: UTF8, Optional: true},
+		{Name: "flag", Type: Boolean, Optional: true},
+		{Name: "score", Type: Double},
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf, cols, 2)
+	rows := [][]interface{}{
+		{int64(1), "Alice", true, 1.5},
+		{int64(2), nil, nil, 2.5},
+		{int64(3), []byte("Carol"), false, 3.5},
+	}
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.Error(t, w.Write([]interface{}{int64(4)}))
+	require.Error(t, w.Write([]interface{}{nil, nil, nil, 4.5}))
+	require.NoError(t, w.Close())
+
+	b := buf.Bytes()
+	require.Equal(t, magic, string(b[:4]))
+	require.Equal(t, magic, string(b[len(b)-4:]))
+	size := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	d := &decoder{t: t, r: bytes.NewReader(b[len(b)-8-size : len(b)-8])}
+	meta := d.strct()
+	require.EqualValues(t, 3, meta[3])
+	schema := meta[2].([]interface{})
+	require.Len(t, schema, 5)
+	require.Equal(t, "schema", get(schema[0], 4))
+	require.EqualValues(t, 4, get(schema[0], 5))
+	require.Equal(t, "value", get(schema[2], 4))
+	require.EqualValues(t, ByteArray, get(schema[2], 1))
+	require.EqualValues(t, optional, get(schema[2], 3))
+	require.EqualValues(t, 0, get(schema[2], 6))
+	require.Nil(t, get(schema[1], 6))
+
+	// The rows are in a group of two, and one of one.
+	groups := meta[4].([]interface{})
+	require.Len(t, groups, 2)
+	require.EqualValues(t, 2, get(groups[0], 3))
+	require.EqualValues(t, 1, get(groups[1], 3))
+
+	// The values and definition levels of the first page of the value column.
+	chunk := get(groups[0], 1).([]interface{})[1]
+	require.Equal(t, []interface{}{"value"}, get(chunk, 3, 3))
+	require.EqualValues(t, 2, get(chunk, 3, 5))
+	off := get(chunk, 3, 9).(int64)
+	d = &decoder{t: t, r: bytes.NewReader(b[off:])}
+	header := d.strct()
+	require.EqualValues(t, 2, get(header, 5, 1))
+	data := make([]byte, header[3].(int64))
+	_, err := d.r.Read(data)
+	require.NoError(t, err)
+	require.EqualValues(t, get(chunk, 3, 7), int64(len(b[off:]))-int64(d.r.Len()))
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	page, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.EqualValues(t, header[2], len(page))
+	// Runs of one defined value and one null, then the value.
+	require.Equal(t, []byte{4, 0, 0, 0, 2, 1, 2, 0, 5, 0, 0, 0, 'A', 'l', 'i', 'c', 'e'}, page)
+
+	// The booleans of the second group are bit packed, after their levels.
+	chunk = get(groups[1], 1).([]interface{})[2]
+	off = get(chunk, 3, 9).(int64)
+	d = &decoder{t: t, r: bytes.NewReader(b[off:])}
+	header = d.strct()
+	data = make([]byte, header[3].(int64))
+	d.r.Read(data)
+	gz, err = gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	page, err = ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2, 0, 0, 0, 2, 1, 0}, page)
+
+	chunk = get(groups[1], 1).([]interface{})[3]
+	off = get(chunk, 3, 9).(int64)
+	d = &decoder{t: t, r: bytes.NewReader(b[off:])}
+	header = d.strct()
+	data = make([]byte, header[3].(int64))
+	d.r.Read(data)
+	gz, err = gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	page, err = ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, 3.5, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+}
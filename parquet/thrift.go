@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of fields in the compact protocol.
+const (
+	tI32    = 5
+	tI64    = 6
+	tBinary = 8
+	tList   = 9
+	tStruct = 12
+)
+
+// encoder writes structs of the Parquet metadata with the compact protocol. Structs are written
+// with fields, which must be in increasing order of id, between begin and end.
+type encoder struct {
+	bytes.Buffer
+	last []int16 // Id of the last field written, of each struct being written.
+}
+
+func (e *encoder) varint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	e.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func (e *encoder) zigzag(v int64) {
+	e.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (e *encoder) begin() {
+	e.last = append(e.last, 0)
+}
+
+func (e *encoder) end() {
+	e.WriteByte(0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *encoder) field(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.WriteByte(typ)
+		e.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (e *encoder) i32(id int16, v int32) {
+	e.field(id, tI32)
+	e.zigzag(int64(v))
+}
+
+func (e *encoder) i64(id int16, v int64) {
+	e.field(id, tI64)
+	e.zigzag(v)
+}
+
+func (e *encoder) string(id int16, s string) {
+	e.field(id, tBinary)
+	e.varint(uint64(len(s)))
+	e.WriteString(s)
+}
+
+func (e *encoder) list(id int16, typ byte, n int) {
+	e.field(id, tList)
+	if n < 15 {
+		e.WriteByte(byte(n)<<4 | typ)
+		return
+	}
+	e.WriteByte(0xf0 | typ)
+	e.varint(uint64(n))
+}
+
+func (e *encoder) pageHeader(uncompressed, compressed, numValues int32) {
+	e.begin()
+	e.i32(1, pageData)
+	e.i32(2, uncompressed)
+	e.i32(3, compressed)
+	e.field(5, tStruct)
+	e.begin()
+	e.i32(1, numValues)
+	e.i32(2, encodingPlain)
+	e.i32(3, encodingRLE)
+	e.i32(4, encodingRLE)
+	e.end()
+	e.end()
+}
+
+func (e *encoder) fileMetaData(columns []Column, groups []rowGroup) {
+	var rows int64
+	for _, rg := range groups {
+		rows += rg.numRows
+	}
+	e.begin()
+	e.i32(1, 1)
+	e.list(2, tStruct, len(columns)+1)
+	e.begin()
+	e.string(4, "schema")
+	e.i32(5, int32(len(columns)))
+	e.end()
+	for _, col := range columns {
+		e.begin()
+		e.i32(1, int32(col.Type))
+		if col.Optional {
+			e.i32(3, optional)
+		} else {
+			e.i32(3, required)
+		}
+		e.string(4, col.Name)
+		if ct, ok := convertedTypes[col.Logical]; ok {
+			e.i32(6, ct)
+		}
+		e.end()
+	}
+	e.i64(3, rows)
+	e.list(4, tStruct, len(groups))
+	for _, rg := range groups {
+		e.begin()
+		e.list(1, tStruct, len(rg.chunks))
+		for i, c := range rg.chunks {
+			e.begin()
+			e.i64(2, c.offset)
+			e.field(3, tStruct)
+			e.begin()
+			e.i32(1, int32(columns[i].Type))
+			e.list(2, tI32, 2)
+			e.zigzag(encodingPlain)
+			e.zigzag(encodingRLE)
+			e.list(3, tBinary, 1)
+			e.varint(uint64(len(columns[i].Name)))
+			e.WriteString(columns[i].Name)
+			e.i32(4, codecGzip)
+			e.i64(5, c.numValues)
+			e.i64(6, c.uncompressedSize)
+			e.i64(7, c.compressedSize)
+			e.i64(9, c.offset)
+			e.end()
+			e.end()
+		}
+		e.i64(2, rg.size)
+		e.i64(3, rg.numRows)
+		e.end()
+	}
+	e.string(6, "dgraph")
+	e.end()
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"sort"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Values of at least Config.BlobThreshold bytes are stored out of line, when a data posting list
+// is written to the store. Each such value is written as a posting list with a single posting
+// under its own x.BlobKey, and the posting left behind in the list has ValueRef set and no value.
+// This way reading a list, say to iterate over its uids, doesn't have to read all its values.
+// In memory, postings which were merged in since the list was read keep their values.
+
+func isBigValue(p *protos.Posting) bool {
+	return Config.BlobThreshold > 0 && len(p.Value) >= Config.BlobThreshold
+}
+
+// splitBlobs returns the posting list to store for pl, with big values replaced by references.
+// It also returns the entries to write for these values, and the uids of all postings in the
+// returned list which refer to a blob.
+func splitBlobs(pk *x.ParsedKey, pl *protos.PostingList) (*protos.PostingList,
+	[]*badger.Entry, []uint64) {
+	if pk == nil || !pk.IsData() {
+		return pl, nil, nil
+	}
+	var entries []*badger.Entry
+	var refs []uint64
+	var out *protos.PostingList
+	for i, p := range pl.Postings {
+		if p.ValueRef {
+			refs = append(refs, p.Uid)
+			continue
+		}
+		if !isBigValue(p) {
+			continue
+		}
+		blob := &protos.PostingList{Postings: []*protos.Posting{p}}
+		val, err := blob.Marshal()
+		x.Checkf(err, "Unable to marshal blob")
+		entries = badger.EntriesSet(entries, x.BlobKey(pk.Attr, pk.Uid, p.Uid), val)
+		refs = append(refs, p.Uid)
+
+		if out == nil {
+			// Copy on first write, so that pl can still be used in memory with all the values.
+			out = &protos.PostingList{
+				Postings: make([]*protos.Posting, len(pl.Postings)),
+				Checksum: pl.Checksum,
+				Commit:   pl.Commit,
+				Uids:     pl.Uids,
+			}
+			copy(out.Postings, pl.Postings)
+		}
+		ref := *p
+		ref.Value = nil
+		ref.ValueRef = true
+		out.Postings[i] = &ref
+	}
+	if out == nil {
+		return pl, entries, refs
+	}
+	return out, entries, refs
+}
+
+// staleBlobs returns the entries to delete the blobs which were referred to by prev, but aren't
+// referred to by cur anymore. Both prev and cur must be sorted.
+func staleBlobs(pk *x.ParsedKey, prev, cur []uint64) []*badger.Entry {
+	var entries []*badger.Entry
+	for _, uid := range prev {
+		idx := sort.Search(len(cur), func(i int) bool { return cur[i] >= uid })
+		if idx < len(cur) && cur[idx] == uid {
+			continue
+		}
+		entries = badger.EntriesDelete(entries, x.BlobKey(pk.Attr, pk.Uid, uid))
+	}
+	return entries
+}
+
+func blobRefs(pl *protos.PostingList) []uint64 {
+	var refs []uint64
+	for _, p := range pl.Postings {
+		if p.ValueRef {
+			refs = append(refs, p.Uid)
+		}
+	}
+	return refs
+}
+
+// readBlob returns the posting p with its value, reading it from the store if it's stored out of
+// line. pk is the parsed key of the posting list p belongs to.
+func readBlob(pk *x.ParsedKey, p *protos.Posting) *protos.Posting {
+	if !p.ValueRef || pk == nil {
+		return p
+	}
+	var item badger.KVItem
+	if err := pstore.Get(x.BlobKey(pk.Attr, pk.Uid, p.Uid), &item); err != nil {
+		x.Printf("Error while reading blob for posting %d of key %v: %v\n", p.Uid, pk, err)
+		return p
+	}
+	val := item.Value()
+	x.BytesRead.Add(int64(len(val)))
+
+	var blob protos.PostingList
+	UnmarshalWithCopy(val, item.UserMeta(), &blob)
+	if len(blob.Postings) != 1 {
+		x.Printf("Missing blob for posting %d of key %v\n", p.Uid, pk)
+		return p
+	}
+	return blob.Postings[0]
+}
+
+func (l *List) readBlob(p *protos.Posting) *protos.Posting {
+	if !p.ValueRef {
+		return p
+	}
+	return readBlob(x.Parse(l.key), p)
+}
+
+// ReadBlobs replaces the postings in pl, which was read from the store for key, having their
+// values stored out of line with the complete postings.
+func ReadBlobs(key []byte, pl *protos.PostingList) {
+	var pk *x.ParsedKey
+	for i, p := range pl.Postings {
+		if !p.ValueRef {
+			continue
+		}
+		if pk == nil {
+			pk = x.Parse(key)
+		}
+		pl.Postings[i] = readBlob(pk, p)
+	}
+}
+
+// IterateValues is Iterate over all the postings of l, with their values stored out of line read.
+func (l *List) IterateValues(f func(p *protos.Posting) bool) {
+	l.RLock()
+	defer l.RUnlock()
+	l.iterate(0, func(p *protos.Posting) bool {
+		return f(l.readBlob(p))
+	})
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// waitForBlob waits for the async write of the list to make the blob exist, or not.
+func waitForBlob(t *testing.T, key []byte, exists bool) {
+	for i := 0; i < 100; i++ {
+		found, err := ps.Exists(key)
+		require.NoError(t, err)
+		if found == exists {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Blob existence for key %q should be: %v", key, exists)
+}
+
+func TestBlobValue(t *testing.T) {
+	schema.ParseBytes([]byte("blob:string ."), 1)
+	Config.BlobThreshold = 100
+	defer func() { Config.BlobThreshold = 0 }()
+
+	key := x.DataKey("blob", 10)
+	blobKey := x.BlobKey("blob", 10, ^uint64(0))
+	big := bytes.Repeat([]byte("a"), 1000)
+
+	ol := getNew(key, ps)
+	edge := &protos.DirectedEdge{Attr: "blob", Value: big}
+	addMutation(t, ol, edge, Set)
+	_, err := ol.SyncIfDirty(false)
+	require.NoError(t, err)
+	checkValue(t, ol, string(big))
+	waitForBlob(t, blobKey, true)
+
+	// Reading the list back from the store only gives a reference to the value.
+	ol = getNew(key, ps)
+	require.Len(t, ol.plist.Postings, 1)
+	require.True(t, ol.plist.Postings[0].ValueRef)
+	require.Nil(t, ol.plist.Postings[0].Value)
+	val, err := ol.Value()
+	require.NoError(t, err)
+	require.EqualValues(t, big, val.Value)
+
+	var item badger.KVItem
+	require.NoError(t, ps.Get(key, &item))
+	require.True(t, len(item.Value()) < 100)
+
+	// Replacing it with a small value gets rid of the blob.
+	edge = &protos.DirectedEdge{Attr: "blob", Value: []byte("small")}
+	addMutation(t, ol, edge, Set)
+	_, err = ol.SyncIfDirty(false)
+	require.NoError(t, err)
+	waitForBlob(t, blobKey, false)
+
+	ol = getNew(key, ps)
+	require.False(t, ol.plist.Postings[0].ValueRef)
+	checkValue(t, ol, "small")
+
+	deletePl(t)
+	ps.Delete(key)
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// CompactionPriority decides how eagerly the mutation layer of the posting lists of a
+// predicate (data, index, reverse and count keys alike) is merged into the immutable layer
+// and written out to the store.
+type CompactionPriority int
+
+const (
+	NormalPriority CompactionPriority = iota
+	// HighPriority is meant for latency critical predicates. Their lists are committed sooner
+	// and are allowed only small mutation layers, so reads have less to merge.
+	HighPriority
+	// LowPriority is meant for bulk, analytical predicates. They tolerate bigger mutation
+	// layers (and hence more read amplification) in exchange for fewer writes.
+	LowPriority
+)
+
+var priorityNames = map[CompactionPriority]string{
+	NormalPriority: "normal",
+	HighPriority:   "high",
+	LowPriority:    "low",
+}
+
+func (p CompactionPriority) String() string {
+	return priorityNames[p]
+}
+
+// commitDelay is the time a dirty list has to wait, after its last mutation, before
+// gentleCommit picks it up.
+func (p CompactionPriority) commitDelay() time.Duration {
+	switch p {
+	case HighPriority:
+		return time.Second
+	case LowPriority:
+		return 30 * time.Second
+	}
+	return 5 * time.Second
+}
+
+// mutationLayerLimit returns the number of postings that the mutation layer of a list with
+// numUids postings in its immutable layer can hold, before it gets merged as part of a mutation.
+func (p CompactionPriority) mutationLayerLimit(numUids int) int {
+	percent, min := 5, 3000
+	switch p {
+	case HighPriority:
+		percent, min = 1, 500
+	case LowPriority:
+		percent, min = 20, 20000
+	}
+	limit := numUids * percent / 100
+	if limit < min {
+		limit = min
+	}
+	return limit
+}
+
+type compactionPriorities struct {
+	sync.RWMutex
+	m map[string]CompactionPriority
+}
+
+var priorities = compactionPriorities{m: make(map[string]CompactionPriority)}
+
+// SetCompactionPriorities replaces the compaction priorities of all predicates. Predicates
+// not present in the map get NormalPriority.
+func SetCompactionPriorities(m map[string]CompactionPriority) {
+	pm := make(map[string]CompactionPriority, len(m))
+	for attr, p := range m {
+		if p != NormalPriority {
+			pm[attr] = p
+		}
+	}
+	priorities.Lock()
+	priorities.m = pm
+	priorities.Unlock()
+}
+
+// CompactionPriorities returns a copy of the predicates which don't have NormalPriority.
+func CompactionPriorities() map[string]CompactionPriority {
+	priorities.RLock()
+	defer priorities.RUnlock()
+	m := make(map[string]CompactionPriority, len(priorities.m))
+	for attr, p := range priorities.m {
+		m[attr] = p
+	}
+	return m
+}
+
+// CompactionPriorityFor returns the compaction priority for the given predicate.
+func CompactionPriorityFor(attr string) CompactionPriority {
+	priorities.RLock()
+	defer priorities.RUnlock()
+	return priorities.m[attr]
+}
+
+// priorityForKey returns the compaction priority for the predicate the key belongs to. It
+// avoids parsing the key, if no priorities have been set.
+func priorityForKey(key string) CompactionPriority {
+	priorities.RLock()
+	empty := len(priorities.m) == 0
+	priorities.RUnlock()
+	if empty {
+		return NormalPriority
+	}
+	pk := x.Parse([]byte(key))
+	if pk == nil {
+		return NormalPriority
+	}
+	return CompactionPriorityFor(pk.Attr)
+}
+
+// ParseCompactionPriorities parses a comma separated list of predicate:priority pairs like
+// "name:high,description:low". Valid priorities are high, normal and low.
+func ParseCompactionPriorities(s string) (map[string]CompactionPriority, error) {
+	m := make(map[string]CompactionPriority)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			return nil, x.Errorf("Invalid compaction priority: %q. Expected predicate:priority",
+				pair)
+		}
+		attr, name := strings.TrimSpace(pair[:idx]), strings.TrimSpace(pair[idx+1:])
+		var found bool
+		for p, pname := range priorityNames {
+			if pname == strings.ToLower(name) {
+				m[attr] = p
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, x.Errorf("Invalid compaction priority %q for predicate: %s", name, attr)
+		}
+	}
+	return m, nil
+}
+
+// FormatCompactionPriorities is the inverse of ParseCompactionPriorities. The output is sorted
+// by predicate.
+func FormatCompactionPriorities(m map[string]CompactionPriority) string {
+	attrs := make([]string, 0, len(m))
+	for attr := range m {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var buf bytes.Buffer
+	for i, attr := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(attr)
+		buf.WriteByte(':')
+		buf.WriteString(m[attr].String())
+	}
+	return buf.String()
+}
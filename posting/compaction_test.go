@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestParseCompactionPriorities(t *testing.T) {
+	m, err := ParseCompactionPriorities(" name:high, description:LOW,age:normal ")
+	require.NoError(t, err)
+	require.Equal(t, map[string]CompactionPriority{
+		"name":        HighPriority,
+		"description": LowPriority,
+		"age":         NormalPriority,
+	}, m)
+
+	m, err = ParseCompactionPriorities("")
+	require.NoError(t, err)
+	require.Empty(t, m)
+
+	_, err = ParseCompactionPriorities("name")
+	require.Error(t, err)
+	_, err = ParseCompactionPriorities("name:urgent")
+	require.Error(t, err)
+}
+
+func TestFormatCompactionPriorities(t *testing.T) {
+	s := "description:low,name:high"
+	m, err := ParseCompactionPriorities(s)
+	require.NoError(t, err)
+	require.Equal(t, s, FormatCompactionPriorities(m))
+}
+
+func TestMutationLayerLimit(t *testing.T) {
+	require.Equal(t, 3000, NormalPriority.mutationLayerLimit(100))
+	require.Equal(t, 5000, NormalPriority.mutationLayerLimit(100000))
+	require.Equal(t, 1000, HighPriority.mutationLayerLimit(100000))
+	require.Equal(t, 20000, LowPriority.mutationLayerLimit(100000))
+}
+
+func TestGentleCommitPriority(t *testing.T) {
+	SetCompactionPriorities(map[string]CompactionPriority{
+		"fast": HighPriority,
+		"slow": LowPriority,
+	})
+	defer SetCompactionPriorities(nil)
+	require.Equal(t, HighPriority, CompactionPriorityFor("fast"))
+	require.Equal(t, NormalPriority, CompactionPriorityFor("other"))
+
+	fast := string(x.DataKey("fast", 1))
+	fastIdx := string(x.IndexKey("fast", "term"))
+	other := string(x.DataKey("other", 1))
+	slow := string(x.DataKey("slow", 1))
+
+	ts := time.Now().Add(-2 * time.Second)
+	dirtyMap := map[string]time.Time{fast: ts, fastIdx: ts, other: ts, slow: ts}
+	pending := make(chan struct{}, 1)
+	gentleCommit(dirtyMap, pending, 1.0)
+
+	// Only the keys of the high priority predicate are old enough to be committed.
+	require.Len(t, dirtyMap, 2)
+	require.Contains(t, dirtyMap, other)
+	require.Contains(t, dirtyMap, slow)
+}
@@ -16,13 +16,25 @@
  */
 package posting
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type Options struct {
 	Mu             sync.Mutex
 	AllottedMemory float64
 
 	CommitFraction float64
+	// Target p99 latency of a batch of gentle commits. If set, the batch size is tuned to meet it,
+	// instead of committing CommitFraction of the dirty posting lists.
+	CommitLatency time.Duration
+	// Values of at least these many bytes are stored out of line of their posting lists.
+	// Zero disables it.
+	BlobThreshold int
+	// Interval at which the older versions of posting lists which aren't retained anymore are
+	// deleted. Zero disables it.
+	ValueGCInterval time.Duration
 }
 
 var Config Options
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"sync"
+
+	"github.com/AndreasBriese/bbloom"
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// Minimum number of keys a filter is sized for. Filters of predicates which grow way beyond
+	// the size they were created with give more false positives, until they're rebuilt on restart.
+	minFilterKeys        = 1 << 16
+	filterFalsePositives = 0.01
+)
+
+// keyFilters hold a bloom filter per predicate over all the posting list keys (data, index,
+// reverse and count) of that predicate in the store. They allow getNew to skip reading keys
+// which definitely don't exist, which is what most lookups for has() and for intersections with
+// a small set of uids run into.
+type keyFilters struct {
+	sync.RWMutex
+	ready bool
+	m     map[string]*bbloom.Bloom
+}
+
+var filters = keyFilters{m: make(map[string]*bbloom.Bloom)}
+
+func newFilter(numKeys int) *bbloom.Bloom {
+	if numKeys < minFilterKeys {
+		numKeys = minFilterKeys
+	}
+	b := bbloom.New(float64(numKeys), filterFalsePositives)
+	return &b
+}
+
+func filterable(pk *x.ParsedKey) bool {
+	return pk != nil && !pk.IsSchema() && !pk.IsBlob() && !pk.IsVersion()
+}
+
+// BuildKeyFilters goes over all the keys in the store, and builds the filters used to avoid
+// reading keys which don't exist. Until this is called, all keys are read from the store. It must
+// be called after Init, and before anything else gets written to the store.
+func BuildKeyFilters() {
+	iterOpt := badger.DefaultIteratorOptions
+	iterOpt.FetchValues = false
+
+	// The first pass counts the keys per predicate, so the filters can be sized accordingly.
+	counts := make(map[string]int)
+	it := pstore.NewIterator(iterOpt)
+	for it.Rewind(); it.Valid(); it.Next() {
+		if pk := x.Parse(it.Item().Key()); filterable(pk) {
+			counts[pk.Attr]++
+		}
+	}
+	it.Close()
+
+	m := make(map[string]*bbloom.Bloom, len(counts))
+	for attr, n := range counts {
+		// Leave room for the predicate to grow.
+		m[attr] = newFilter(2 * n)
+	}
+	it = pstore.NewIterator(iterOpt)
+	for it.Rewind(); it.Valid(); it.Next() {
+		key := it.Item().Key()
+		if pk := x.Parse(key); filterable(pk) {
+			m[pk.Attr].Add(key)
+		}
+	}
+	it.Close()
+
+	filters.Lock()
+	filters.m = m
+	filters.ready = true
+	filters.Unlock()
+}
+
+// AddToKeyFilter must be called for any posting list key written to the store, other than
+// via a posting list.
+func AddToKeyFilter(key []byte) {
+	filters.RLock()
+	ready := filters.ready
+	filters.RUnlock()
+	if !ready {
+		return
+	}
+	pk := x.Parse(key)
+	if !filterable(pk) {
+		return
+	}
+
+	filters.Lock()
+	defer filters.Unlock()
+	b, ok := filters.m[pk.Attr]
+	if !ok {
+		b = newFilter(0)
+		filters.m[pk.Attr] = b
+	}
+	b.Add(key)
+}
+
+// mayExist returns false if the key definitely doesn't exist in the store.
+func mayExist(key []byte) bool {
+	filters.RLock()
+	defer filters.RUnlock()
+	if !filters.ready {
+		return true
+	}
+	pk := x.Parse(key)
+	if !filterable(pk) {
+		return true
+	}
+	b, ok := filters.m[pk.Attr]
+	if !ok {
+		return false
+	}
+	return b.Has(key)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"testing"
+
+	"github.com/AndreasBriese/bbloom"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestKeyFilters(t *testing.T) {
+	existing := x.DataKey("filter", 1)
+	require.NoError(t, ps.Set(existing, []byte("nothing"), 0x00))
+	defer ps.Delete(existing)
+
+	BuildKeyFilters()
+	defer func() {
+		filters.Lock()
+		filters.ready = false
+		filters.m = make(map[string]*bbloom.Bloom)
+		filters.Unlock()
+	}()
+
+	require.True(t, mayExist(existing))
+	require.True(t, mayExist(x.SchemaKey("filter")))
+	require.False(t, mayExist(x.DataKey("filter", 2)))
+	require.False(t, mayExist(x.DataKey("unknown", 1)))
+
+	skips := x.KeyFilterSkips.Value()
+	key := x.DataKey("filter", 2)
+	ol := getNew(key, ps)
+	require.Equal(t, skips+1, x.KeyFilterSkips.Value())
+	require.Equal(t, 0, ol.Length(0))
+
+	// Writing the list makes it show up in the filter.
+	addMutation(t, ol, &protos.DirectedEdge{ValueId: 10, Label: "filter"}, Set)
+	_, err := ol.SyncIfDirty(false)
+	require.NoError(t, err)
+	require.True(t, mayExist(key))
+
+	AddToKeyFilter(x.IndexKey("filter", "term"))
+	require.True(t, mayExist(x.IndexKey("filter", "term")))
+
+	deletePl(t)
+	ps.Delete(key)
+}
@@ -50,14 +50,18 @@ func IndexTokens(attr, lang string, src types.Val) ([]string, error) {
 	if !schema.State().IsIndexed(attr) {
 		return nil, x.Errorf("Attribute %s is not indexed.", attr)
 	}
-	s := schemaType
+	// Schema will know the mapping from attr to tokenizer.
+	return indexTokens(schemaType, schema.State().Tokenizer(attr), lang, src)
+}
+
+// indexTokens returns the tokens of the value src of type s for the index with tokenizers.
+func indexTokens(s types.TypeID, tokenizers []tok.Tokenizer, lang string,
+	src types.Val) ([]string, error) {
 	sv, err := types.Convert(src, s)
 	if err != nil {
 		return nil, err
 	}
-	// Schema will know the mapping from attr to tokenizer.
 	var tokens []string
-	tokenizers := schema.State().Tokenizer(attr)
 	for _, it := range tokenizers {
 		if tok.FtsTokenizerName("") == it.Name() && len(lang) > 0 {
 			newTokenizer, ok := tok.GetTokenizer(tok.FtsTokenizerName(lang))
@@ -85,24 +89,79 @@ func addIndexMutations(ctx context.Context, t *protos.DirectedEdge, p types.Val,
 	attr := t.Attr
 	uid := t.Entity
 	x.AssertTrue(uid != 0)
-	tokens, err := IndexTokens(attr, t.GetLang(), p)
+	schemaType, err := schema.State().TypeOf(attr)
+	if err != nil || !schemaType.IsScalar() {
+		return x.Errorf("Cannot index attribute %s of type object.", attr)
+	}
+	// Besides its own index, the values are added to the one being rebuilt online, if any.
+	for _, index := range schema.State().Indexes(attr) {
+		tokens, err := indexTokens(schemaType, index.Tokenizers, t.GetLang(), p)
+		if err != nil {
+			// This data is not indexable
+			return err
+		}
 
-	if err != nil {
-		// This data is not indexable
-		return err
+		// Create a value token -> uid edge.
+		edge := &protos.DirectedEdge{
+			ValueId: uid,
+			Attr:    index.Attr,
+			Op:      op,
+		}
+
+		for _, token := range tokens {
+			if err := addIndexMutation(ctx, edge, token); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	// Create a value token -> uid edge.
+// AddToRebuiltIndex adds the values of attr of the nodes uids to the geo index being rebuilt
+// online for attr, but not to its own.
+func AddToRebuiltIndex(ctx context.Context, attr string, uids []uint64, gid uint32) error {
+	s, ok := schema.State().Get(attr)
+	if !ok || s.Rebuild == nil {
+		return x.Errorf("The index of %s isn't being rebuilt", attr)
+	}
+	tokenizers := schema.IndexTokenizers(s.Rebuild)
 	edge := &protos.DirectedEdge{
-		ValueId: uid,
-		Attr:    attr,
-		Op:      op,
+		Attr: schema.IndexAttr(attr, s.Rebuild),
+		Op:   protos.DirectedEdge_SET,
 	}
-
-	for _, token := range tokens {
-		if err := addIndexMutation(ctx, edge, token); err != nil {
+	addValue := func(val types.Val) error {
+		tokens, err := indexTokens(types.TypeID(s.ValueType), tokenizers, "", val)
+		if err != nil {
 			return err
 		}
+		for _, token := range tokens {
+			if err := addIndexMutation(ctx, edge, token); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, uid := range uids {
+		l := GetOrCreate(x.DataKey(attr, uid), gid)
+		// The values can't change while they're added.
+		l.index.Lock()
+		var vals []types.Val
+		l.IterateValues(func(p *protos.Posting) bool {
+			vals = append(vals, types.Val{Tid: types.TypeID(p.ValType), Value: p.Value})
+			return true
+		})
+		edge.ValueId = uid
+		var err error
+		for _, val := range vals {
+			if err = addValue(val); err != nil {
+				break
+			}
+		}
+		l.index.Unlock()
+		if err != nil {
+			return x.Wrapf(err, "While adding the values of %s of node %#x to its index", attr,
+				uid)
+		}
 	}
 	return nil
 }
@@ -200,7 +259,7 @@ func addReverseMutation(ctx context.Context, t *protos.DirectedEdge) error {
 }
 func (l *List) handleDeleteAll(ctx context.Context, t *protos.DirectedEdge) error {
 	isReversed := schema.State().IsReversed(t.Attr)
-	isIndexed := schema.State().IsIndexed(t.Attr)
+	isIndexed := schema.State().UpdatesIndex(t.Attr)
 	hasCount := schema.State().HasCount(t.Attr)
 	delEdge := &protos.DirectedEdge{
 		Attr:   t.Attr,
@@ -222,6 +281,7 @@ func (l *List) handleDeleteAll(ctx context.Context, t *protos.DirectedEdge) erro
 			return true
 		} else if isIndexed {
 			// Delete index edge of each posting.
+			p = l.readBlob(p)
 			p := types.Val{
 				Tid:   types.TypeID(p.ValType),
 				Value: p.Value,
@@ -319,7 +379,7 @@ func (l *List) AddMutationWithIndex(ctx context.Context, t *protos.DirectedEdge)
 		return l.handleDeleteAll(ctx, t)
 	}
 
-	doUpdateIndex := pstore != nil && (t.Value != nil) && schema.State().IsIndexed(t.Attr)
+	doUpdateIndex := pstore != nil && (t.Value != nil) && schema.State().UpdatesIndex(t.Attr)
 	{
 		t1 = time.Now()
 		l.Lock()
@@ -634,15 +694,28 @@ func RebuildReverseEdges(ctx context.Context, attr string) error {
 	return nil
 }
 
+// DeleteIndex deletes the index of attr, whichever of attr and its name when rebuilt online its
+// keys are stored under, along with any index being rebuilt.
 func DeleteIndex(ctx context.Context, attr string) error {
+	for _, name := range []string{attr, schema.RebuildAttr(attr, attr)} {
+		if err := DeleteIndexKeys(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteIndexKeys deletes the index keys stored under the predicate name, which can hold the
+// index of another predicate rebuilt online.
+func DeleteIndexKeys(ctx context.Context, name string) error {
 	err := lcache.clear(func(key []byte) bool {
-		return compareAttrAndType(key, attr, x.ByteIndex)
+		return compareAttrAndType(key, name, x.ByteIndex)
 	})
 	if err != nil {
 		return err
 	}
 	// Delete index entries from data store.
-	pk := x.ParsedKey{Attr: attr}
+	pk := x.ParsedKey{Attr: name}
 	prefix := pk.IndexPrefix()
 	if err := deleteEntries(prefix); err != nil {
 		return err
@@ -714,6 +787,7 @@ func RebuildIndex(ctx context.Context, attr string) error {
 
 		// Posting list contains only values or only UIDs.
 		if len(pl.Postings) != 0 && postingType(pl.Postings[0]) != x.ValueUid {
+			ReadBlobs(key, &pl)
 			ch <- item{
 				uid:  pki.Uid,
 				list: &pl,
@@ -744,9 +818,16 @@ func DeletePredicate(ctx context.Context, attr string) error {
 	if err := deleteEntries(prefix); err != nil {
 		return err
 	}
+	// Along with the values stored out of line, and the older versions retained.
+	if err := deleteEntries(pk.BlobPrefix()); err != nil {
+		return err
+	}
+	if err := deleteEntries(pk.VersionPrefix()); err != nil {
+		return err
+	}
 
 	// TODO - We will still have the predicate present in <uid, _predicate_> posting lists.
-	indexed := schema.State().IsIndexed(attr)
+	indexed := schema.State().UpdatesIndex(attr)
 	reversed := schema.State().IsReversed(attr)
 	if indexed {
 		if err := DeleteIndex(ctx, attr); err != nil {
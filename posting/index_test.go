@@ -20,6 +20,7 @@ package posting
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -335,3 +336,77 @@ func TestRebuildReverseEdges(t *testing.T) {
 	require.EqualValues(t, 2, uids0[1])
 	require.EqualValues(t, 1, uids1[0])
 }
+
+// addGeoEdge adds the point lon, lat as the value of attr of src, with its index entries if
+// indexed.
+func addGeoEdge(t *testing.T, attr string, src uint64, lon, lat float64, indexed bool) {
+	g, err := types.Convert(types.Val{Tid: types.StringID,
+		Value: []byte(fmt.Sprintf(`{"type":"Point","coordinates":[%v,%v]}`, lon, lat))},
+		types.GeoID)
+	require.NoError(t, err)
+	b := types.ValueForType(types.BinaryID)
+	require.NoError(t, types.Marshal(g, &b))
+	edge := &protos.DirectedEdge{
+		Value:     b.Value.([]byte),
+		ValueType: uint32(types.GeoID),
+		Attr:      attr,
+		Entity:    src,
+		Op:        protos.DirectedEdge_SET,
+	}
+	l := GetOrCreate(x.DataKey(attr, src), 1)
+	if indexed {
+		require.NoError(t, l.AddMutationWithIndex(context.Background(), edge))
+		return
+	}
+	_, err = l.AddMutation(context.Background(), edge)
+	require.NoError(t, err)
+}
+
+// indexedUids returns the uids in the index stored under the predicate name.
+func indexedUids(t *testing.T, name string) map[uint64]bool {
+	CommitLists(10, 1)
+	time.Sleep(100 * time.Millisecond)
+	pk := x.ParsedKey{Attr: name}
+	prefix := pk.IndexPrefix()
+	it := ps.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	out := make(map[uint64]bool)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		pl := new(protos.PostingList)
+		UnmarshalWithCopy(it.Item().Value(), it.Item().UserMeta(), pl)
+		for _, uid := range uids(pl) {
+			out[uid] = true
+		}
+	}
+	return out
+}
+
+func TestAddToRebuiltIndex(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte("loc:geo @index(geo) ."), 1))
+	live, ok := schema.State().Get("loc")
+	require.True(t, ok)
+	require.Error(t, AddToRebuiltIndex(context.Background(), "loc", []uint64{1}, 1))
+
+	addGeoEdge(t, "loc", 1, -122.4, 37.7, true)
+	target := live
+	target.Geo = &protos.GeoIndex{MinLevel: 8, MaxLevel: 12, MaxCells: 10}
+	target.IndexAttr = schema.RebuildAttr("loc", live.IndexAttr)
+	live.Rebuild = &target
+	schema.State().Set("loc", live)
+	require.True(t, schema.State().UpdatesIndex("loc"))
+	require.Len(t, schema.State().Indexes("loc"), 2)
+
+	// The values written are added to both indexes, while those before are only added to the
+	// rebuilt one by AddToRebuiltIndex.
+	addGeoEdge(t, "loc", 2, -122.3, 37.6, true)
+	require.Equal(t, map[uint64]bool{1: true, 2: true}, indexedUids(t, "loc"))
+	require.Equal(t, map[uint64]bool{2: true}, indexedUids(t, "loc@rebuild"))
+	require.NoError(t, AddToRebuiltIndex(context.Background(), "loc", []uint64{1, 2}, 1))
+	require.Equal(t, map[uint64]bool{1: true, 2: true}, indexedUids(t, "loc@rebuild"))
+	require.Equal(t, map[uint64]bool{1: true, 2: true}, indexedUids(t, "loc"))
+
+	require.NoError(t, DeleteIndex(context.Background(), "loc"))
+	require.Empty(t, indexedUids(t, "loc"))
+	require.Empty(t, indexedUids(t, "loc@rebuild"))
+	deletePl(t)
+}
@@ -75,6 +75,8 @@ type List struct {
 	deleteMe      int32 // Using atomic for this, to avoid expensive SetForDeletion operation.
 	deleteAll     int32
 	estimatedSize uint32
+	storedSize    int64    // Size of the value last read from or written to the store.
+	blobs         []uint64 // Uids of postings whose values are stored out of line.
 
 	water   *x.WaterMark
 	pending []uint64
@@ -159,6 +161,13 @@ func getNew(key []byte, pstore *badger.KV) *List {
 	l.Lock()
 	defer l.Unlock()
 
+	if !mayExist(l.key) {
+		x.KeyFilterSkips.Add(1)
+		l.plist = new(protos.PostingList)
+		atomic.StoreUint32(&l.estimatedSize, l.calculateSize())
+		return l
+	}
+
 	var item badger.KVItem
 	var err error
 	for i := 0; i < 10; i++ {
@@ -172,12 +181,14 @@ func getNew(key []byte, pstore *badger.KV) *List {
 	}
 	val := item.Value()
 	x.BytesRead.Add(int64(len(val)))
+	l.storedSize = int64(len(val))
 
 	l.plist = new(protos.PostingList)
 	if item.UserMeta() == bitUidPostings {
 		l.plist.Uids = val
 	} else if val != nil {
 		x.Checkf(l.plist.Unmarshal(val), "Unable to Unmarshal PostingList from store")
+		l.blobs = blobRefs(l.plist)
 	}
 	atomic.StoreUint32(&l.estimatedSize, l.calculateSize())
 	return l
@@ -333,7 +344,7 @@ func (l *List) updateMutationLayer(mpost *protos.Posting) bool {
 		pp := pitr.Posting()
 		puid := pp.Uid
 		uidFound = mpost.Uid == puid
-		psame = samePosting(pp, mpost)
+		psame = samePosting(l.readBlob(pp), mpost)
 	}
 
 	if mpost.Op == Set {
@@ -427,11 +438,9 @@ func (l *List) addMutation(ctx context.Context, t *protos.DirectedEdge) (bool, e
 	if rv, ok := ctx.Value("raft").(x.RaftValue); ok {
 		index = rv.Index
 	}
-	// Calculate 5% of immutable layer
-	numUids := (bp128.NumIntegers(l.plist.Uids) * 5) / 100
-	if numUids < 3000 {
-		numUids = 3000
-	}
+	// The size of the mutation layer allowed depends upon the compaction priority of the
+	// predicate, by default it's 5% of immutable layer.
+	numUids := CompactionPriorityFor(t.Attr).mutationLayerLimit(bp128.NumIntegers(l.plist.Uids))
 	if len(l.mlayer) > numUids ||
 		// All proposals are kept in before until they are snapshotted, this ensures that
 		// we don't have too many pending proposals.
@@ -618,16 +627,41 @@ func (l *List) Length(afterUid uint64) int {
 	return l.length(afterUid)
 }
 
-func doAsyncWrite(key []byte, data []byte, uidOnlyPosting bool, f func(error)) {
+func doAsyncWrite(key []byte, data []byte, uidOnlyPosting bool, blobs []*badger.Entry,
+	f func(error)) {
 	var meta byte
 	if uidOnlyPosting {
 		meta = bitUidPostings
 	}
+	if len(blobs) == 0 {
+		if data == nil {
+			pstore.DeleteAsync(key, f)
+		} else {
+			pstore.SetAsync(key, data, meta, f)
+		}
+		return
+	}
+
+	// Write the blobs and older versions in the same batch as the posting list they belong to.
+	entries := make([]*badger.Entry, 0, len(blobs)+1)
+	for _, b := range blobs {
+		e := *b
+		entries = append(entries, &e)
+	}
 	if data == nil {
-		pstore.DeleteAsync(key, f)
+		entries = badger.EntriesDelete(entries, key)
 	} else {
-		pstore.SetAsync(key, data, meta, f)
+		entries = append(entries, &badger.Entry{Key: key, Value: data, UserMeta: meta})
 	}
+	pstore.BatchSetAsync(entries, func(err error) {
+		for _, e := range entries {
+			if err != nil {
+				break
+			}
+			err = e.Error
+		}
+		f(err)
+	})
 }
 
 func (l *List) SyncIfDirty(delFromCache bool) (committed bool, err error) {
@@ -674,13 +708,31 @@ func (l *List) syncIfDirty(delFromCache bool) (committed bool, err error) {
 		bp.WriteTo(final.Uids)
 	}
 
+	// Big values are written out of line, and blobs no longer referred to are deleted.
+	stored := final
+	var blobs []*badger.Entry
+	if len(final.Postings) > 0 || len(l.blobs) > 0 {
+		pk := x.Parse(l.key)
+		var refs []uint64
+		stored, blobs, refs = splitBlobs(pk, final)
+		blobs = append(blobs, staleBlobs(pk, l.blobs, refs)...)
+		l.blobs = refs
+	}
+
+	// The version being superseded is kept, if the retention of the predicate says so.
+	var retainedSize int64
+	if e := l.versionEntry(time.Now()); e != nil {
+		retainedSize = int64(len(e.Key) + len(e.Value))
+		blobs = append(blobs, e)
+	}
+
 	var data []byte
 	var uidOnlyPosting bool
 	if len(final.Uids) == 0 {
 		// This means we should delete the key from store during SyncIfDirty.
 		data = nil
 	} else if len(final.Postings) > 0 {
-		data, err = final.Marshal()
+		data, err = stored.Marshal()
 		x.Checkf(err, "Unable to marshal posting list")
 	} else {
 		data = final.Uids
@@ -705,6 +757,7 @@ func (l *List) syncIfDirty(delFromCache bool) (committed bool, err error) {
 	// l.pending would have been modified by the time the callback is called hence we hold a
 	// reference to pending.
 	pending := l.pending
+	l.storedSize = int64(len(data))
 	var f func(error)
 	f = func(err error) {
 		if err != nil {
@@ -715,11 +768,14 @@ func (l *List) syncIfDirty(delFromCache bool) (committed bool, err error) {
 			}
 			// Error from badger should be temporary, so we can retry.
 			retries += 1
-			doAsyncWrite(l.key, data, uidOnlyPosting, f)
+			doAsyncWrite(l.key, data, uidOnlyPosting, blobs, f)
 			return
 		}
 		x.BytesWrite.Add(int64(len(data)))
 		x.PostingWrites.Add(1)
+		if retainedSize > 0 {
+			x.RetainedBytes.Add(x.Parse(l.key).Attr, retainedSize)
+		}
 		if l.water != nil {
 			l.water.DoneMany(pending)
 		}
@@ -729,7 +785,10 @@ func (l *List) syncIfDirty(delFromCache bool) (committed bool, err error) {
 		}
 	}
 
-	doAsyncWrite(l.key, data, uidOnlyPosting, f)
+	if data != nil {
+		AddToKeyFilter(l.key)
+	}
+	doAsyncWrite(l.key, data, uidOnlyPosting, blobs, f)
 	// Now reset the mutation variables.
 	l.pending = make([]uint64, 0, 3)
 	l.mlayer = l.mlayer[:0]
@@ -804,6 +863,7 @@ func (l *List) AllValues() (vals []types.Val, rerr error) {
 	defer l.RUnlock()
 
 	l.iterate(0, func(p *protos.Posting) bool {
+		p = l.readBlob(p)
 		vals = append(vals, types.Val{
 			Tid:   types.TypeID(p.ValType),
 			Value: p.Value,
@@ -835,7 +895,7 @@ func (l *List) ValueFor(langs []string) (rval types.Val, rerr error) {
 	if err != nil {
 		return rval, err
 	}
-	return valueToTypesVal(p), nil
+	return valueToTypesVal(l.readBlob(p)), nil
 }
 
 func (l *List) postingFor(langs []string) (p *protos.Posting, rerr error) {
@@ -851,7 +911,7 @@ func (l *List) ValueForTag(tag string) (rval types.Val, rerr error) {
 	if err != nil {
 		return rval, err
 	}
-	return valueToTypesVal(p), nil
+	return valueToTypesVal(l.readBlob(p)), nil
 }
 
 func valueToTypesVal(p *protos.Posting) (rval types.Val) {
@@ -923,7 +983,7 @@ func (l *List) findValue(uid uint64) (rval types.Val, found bool) {
 		return rval, found
 	}
 
-	return valueToTypesVal(p), true
+	return valueToTypesVal(l.readBlob(p)), true
 }
 
 func (l *List) findPosting(uid uint64) (found bool, pos *protos.Posting) {
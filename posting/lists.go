@@ -25,6 +25,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -77,6 +78,12 @@ func init() {
 		x.Check(err)
 	})
 	elog = trace.NewEventLog("Memory", "")
+	x.RegisterMemory("posting_cache", func() int64 {
+		if lcache == nil {
+			return 0
+		}
+		return int64(lcache.Stats().Size)
+	})
 }
 
 func (g *syncMarks) create(group uint32) *x.WaterMark {
@@ -122,12 +129,19 @@ func gentleCommit(dirtyMap map[string]time.Time, pending chan struct{},
 
 	// NOTE: No need to acquire read lock for stopTheWorld. This portion is being run
 	// serially alongside aggressive commit.
-	n := int(float64(len(dirtyMap)) * commitFraction)
-	if n < 1000 {
-		// Have a min value of n, so we can merge small number of dirty PLs fast.
-		n = 1000
+	var n int
+	if Config.CommitLatency > 0 {
+		n = tuner.batchSize()
+	} else {
+		n = int(float64(len(dirtyMap)) * commitFraction)
+		if n < 1000 {
+			// Have a min value of n, so we can merge small number of dirty PLs fast.
+			n = 1000
+		}
 	}
 	keysBuffer := make([]string, 0, n)
+	// Lists of high priority predicates are committed first, and don't count towards n.
+	var urgent []string
 
 	// Convert map to list.
 	var loops int
@@ -136,23 +150,30 @@ func gentleCommit(dirtyMap map[string]time.Time, pending chan struct{},
 		if loops > 3*n {
 			break
 		}
-		if time.Since(ts) < 5*time.Second {
+		prio := priorityForKey(key)
+		if time.Since(ts) < prio.commitDelay() {
 			continue
 		}
 
 		delete(dirtyMap, key)
+		if prio == HighPriority {
+			urgent = append(urgent, key)
+			continue
+		}
 		keysBuffer = append(keysBuffer, key)
 		if len(keysBuffer) >= n {
 			// We don't want to process the entire dirtyMap in one go.
 			break
 		}
 	}
+	keysBuffer = append(urgent, keysBuffer...)
 
 	go func(keys []string) {
 		defer func() { <-pending }()
 		if len(keys) == 0 {
 			return
 		}
+		start := time.Now()
 		for _, key := range keys {
 			l := lcache.Get(key)
 			if l == nil {
@@ -162,6 +183,9 @@ func gentleCommit(dirtyMap map[string]time.Time, pending chan struct{},
 			// where another caller re-creates the posting list before a commit happens.
 			commitOne(l)
 		}
+		if Config.CommitLatency > 0 {
+			tuner.record(len(keys), time.Since(start), Config.CommitLatency)
+		}
 	}(keysBuffer)
 }
 
@@ -304,6 +328,9 @@ func Init(ps *badger.KV) {
 
 	go periodicCommit()
 	go updateMemoryMetrics()
+	if Config.ValueGCInterval > 0 {
+		go periodicVersionGC(Config.ValueGCInterval)
+	}
 }
 
 // GetOrCreate stores the List corresponding to key, if it's not there already.
@@ -317,10 +344,17 @@ func Init(ps *badger.KV) {
 // And watermark stuff would have to be located outside worker pkg, maybe in x.
 // That way, we don't have a dependency conflict.
 func GetOrCreate(key []byte, group uint32) (rlist *List) {
+	rlist, _ = GetOrCreateRead(key, group)
+	return rlist
+}
+
+// GetOrCreateRead is GetOrCreate, which also returns the bytes read from the store for the list,
+// zero if it was in the cache.
+func GetOrCreateRead(key []byte, group uint32) (rlist *List, read int64) {
 	lp := lcache.Get(string(key))
 	if lp != nil {
 		x.CacheHit.Add(1)
-		return lp
+		return lp, 0
 	}
 	x.CacheMiss.Add(1)
 
@@ -328,6 +362,7 @@ func GetOrCreate(key []byte, group uint32) (rlist *List) {
 	// to the map, any other goroutine can retrieve it.
 	l := getNew(key, pstore)
 	l.water = marks.Get(group)
+	read = l.storedSize
 
 	// We are always going to return lp to caller, whether it is l or not
 	lp = lcache.PutIfMissing(string(key), l)
@@ -345,7 +380,7 @@ func GetOrCreate(key []byte, group uint32) (rlist *List) {
 		}
 	}
 
-	return lp
+	return lp, read
 }
 
 // Get takes a key and a groupID. It checks if the in-memory map has an
@@ -417,3 +452,32 @@ func EvictGroup(gid uint32) {
 	})
 	x.Checkf(err, "Error while evicting group %d", gid)
 }
+
+// DataUids returns the sorted uids of the nodes with data postings for attr, both those in
+// the store and those only in memory, in lists which haven't been synced yet. Some of the lists
+// may be empty, so callers should read the values through GetOrCreate.
+func DataUids(attr string) []uint64 {
+	seen := make(map[uint64]struct{})
+	lcache.Each(func(k []byte, l *List) {
+		if pk := x.Parse(k); pk != nil && pk.Attr == attr && pk.IsData() {
+			seen[pk.Uid] = struct{}{}
+		}
+	})
+
+	iterOpt := badger.DefaultIteratorOptions
+	iterOpt.FetchValues = false
+	it := pstore.NewIterator(iterOpt)
+	defer it.Close()
+	pk := x.ParsedKey{Attr: attr}
+	prefix := pk.DataPrefix()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		seen[x.Parse(it.Item().Key()).Uid] = struct{}{}
+	}
+
+	uids := make([]uint64, 0, len(seen))
+	for uid := range seen {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	return uids
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type PurgeStats struct {
+	ListsMerged int   // Dirty posting lists merged, dropping their deleted postings.
+	KeysRemoved int   // Empty posting lists and orphaned blobs removed from the store.
+	Bytes       int64 // Bytes of the keys and values removed.
+}
+
+// Purge physically removes deleted data held by this server. Deleted postings linger in the
+// mutation layers of posting lists until these are merged, so all dirty lists get merged first.
+// Then the store is scanned for posting lists left empty by deletions and for blobs no longer
+// referred to, which are removed. Older versions of rewritten lists are reclaimed by the value
+// log GC.
+func Purge() (PurgeStats, error) {
+	var stats PurgeStats
+	var lists []*List
+	lcache.Each(func(k []byte, l *List) {
+		lists = append(lists, l)
+	})
+	for _, l := range lists {
+		if committed, err := l.SyncIfDirty(false); err != nil {
+			return stats, err
+		} else if committed {
+			stats.ListsMerged++
+		}
+	}
+
+	it := pstore.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		key := item.Key()
+		pk := x.Parse(key)
+		if pk == nil || pk.IsSchema() {
+			continue
+		}
+		val := item.Value()
+		if pk.IsBlob() {
+			if blobReferred(pk, binary.BigEndian.Uint64(key[len(key)-8:])) {
+				continue
+			}
+		} else if !isEmptyList(val, item.UserMeta()) {
+			continue
+		}
+		if lcache.Get(string(key)) != nil {
+			// The list is in use, and would be written again.
+			continue
+		}
+		// Only delete the key if it hasn't been written to since we read it.
+		kdup := make([]byte, len(key))
+		copy(kdup, key)
+		if err := pstore.CompareAndDelete(kdup, item.Counter()); err == badger.ErrCasMismatch {
+			continue
+		} else if err != nil {
+			return stats, x.Wrapf(err, "While purging key: %q", kdup)
+		}
+		stats.KeysRemoved++
+		stats.Bytes += int64(len(key) + len(val))
+	}
+	return stats, nil
+}
+
+func isEmptyList(val []byte, meta byte) bool {
+	if len(val) == 0 {
+		return true
+	}
+	if meta == bitUidPostings {
+		return false
+	}
+	var pl protos.PostingList
+	if err := pl.Unmarshal(val); err != nil {
+		return false
+	}
+	return len(pl.Uids) == 0 && len(pl.Postings) == 0
+}
+
+// blobReferred returns whether the data posting list of the blob key still refers to it.
+func blobReferred(pk *x.ParsedKey, postingUid uint64) bool {
+	l := Get(x.DataKey(pk.Attr, pk.Uid))
+	l.RLock()
+	defer l.RUnlock()
+	idx := sort.Search(len(l.blobs), func(i int) bool { return l.blobs[i] >= postingUid })
+	if idx < len(l.blobs) && l.blobs[idx] == postingUid {
+		return true
+	}
+	// Big values which were merged in memory, but whose list isn't written yet.
+	for _, p := range l.mlayer {
+		if p.Uid == postingUid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestPurge(t *testing.T) {
+	empty := x.IndexKey("purge", "empty")
+	orphan := x.BlobKey("purge", 1, 10)
+	live := x.DataKey("purge", 2)
+
+	blob := &protos.PostingList{Postings: []*protos.Posting{{Uid: 10, Value: []byte("big")}}}
+	val, err := blob.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, ps.Set(empty, nil, 0x00))
+	require.NoError(t, ps.Set(orphan, val, 0x00))
+
+	ol := getNew(live, ps)
+	addMutation(t, ol, &protos.DirectedEdge{ValueId: 5, Label: "purge", Attr: "purge"}, Set)
+	_, err = ol.SyncIfDirty(false)
+	require.NoError(t, err)
+	waitForBlob(t, live, true)
+
+	stats, err := Purge()
+	require.NoError(t, err)
+	// Other tests may leave empty lists behind in the store.
+	require.True(t, stats.KeysRemoved >= 2)
+	require.True(t, stats.Bytes >= int64(len(empty)+len(orphan)+len(val)))
+
+	for _, key := range [][]byte{empty, orphan} {
+		found, err := ps.Exists(key)
+		require.NoError(t, err)
+		require.False(t, found)
+	}
+	found, err := ps.Exists(live)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	deletePl(t)
+	ps.Delete(live)
+}
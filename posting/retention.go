@@ -0,0 +1,387 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"expvar"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/bp128"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The store keeps a single version of each key. When a data posting list of a predicate with a
+// retention is committed, the version it supersedes is also written under its own x.VersionKey,
+// with all its values in line. CollectVersions deletes the versions which aren't retained
+// anymore, leaving their space to be reclaimed by the value log GC.
+
+// Retention decides which older versions of the data posting lists of a predicate are kept.
+// Only one of Age and Versions is set. The zero Retention keeps none.
+type Retention struct {
+	// Versions superseded at most this long ago are kept.
+	Age time.Duration
+	// The latest these many versions of each list are kept.
+	Versions int
+}
+
+func (r Retention) String() string {
+	if r.Versions > 0 {
+		return strconv.Itoa(r.Versions)
+	}
+	return r.Age.String()
+}
+
+func (r Retention) enabled() bool {
+	return r.Age > 0 || r.Versions > 0
+}
+
+// keeps returns whether a version, with newer versions of its list after it, which was
+// superseded age ago is retained.
+func (r Retention) keeps(newer int, age time.Duration) bool {
+	if r.Versions > 0 {
+		return newer < r.Versions
+	}
+	return age <= r.Age
+}
+
+type retentions struct {
+	sync.RWMutex
+	m map[string]Retention
+}
+
+var retained = retentions{m: make(map[string]Retention)}
+
+// SetRetentions replaces the retentions of all predicates. Predicates not present in the map
+// don't keep older versions, and those they have are deleted by the next CollectVersions.
+func SetRetentions(m map[string]Retention) {
+	rm := make(map[string]Retention, len(m))
+	for attr, r := range m {
+		if r.enabled() {
+			rm[attr] = r
+		}
+	}
+	retained.Lock()
+	retained.m = rm
+	retained.Unlock()
+}
+
+// Retentions returns a copy of the retentions of predicates which keep older versions.
+func Retentions() map[string]Retention {
+	retained.RLock()
+	defer retained.RUnlock()
+	m := make(map[string]Retention, len(retained.m))
+	for attr, r := range retained.m {
+		m[attr] = r
+	}
+	return m
+}
+
+// RetentionFor returns the retention of the given predicate.
+func RetentionFor(attr string) Retention {
+	retained.RLock()
+	defer retained.RUnlock()
+	return retained.m[attr]
+}
+
+// ParseRetentions parses a comma separated list of predicate:retention pairs like
+// "name:720h,friend:5", where a duration keeps the versions superseded since then, and a number
+// keeps that many of the latest versions of each posting list.
+func ParseRetentions(s string) (map[string]Retention, error) {
+	m := make(map[string]Retention)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		idx := strings.LastIndex(pair, ":")
+		if idx <= 0 {
+			return nil, x.Errorf("Invalid retention: %q. Expected predicate:retention", pair)
+		}
+		attr, val := strings.TrimSpace(pair[:idx]), strings.TrimSpace(pair[idx+1:])
+		var r Retention
+		if n, err := strconv.Atoi(val); err == nil {
+			r.Versions = n
+		} else if d, err := time.ParseDuration(val); err == nil {
+			r.Age = d
+		} else {
+			return nil, x.Errorf("Invalid retention %q for predicate: %s. Expected a duration "+
+				"or a number of versions", val, attr)
+		}
+		if r.Age < 0 || r.Versions < 0 {
+			return nil, x.Errorf("Retention of predicate %s can't be negative: %s", attr, val)
+		}
+		m[attr] = r
+	}
+	return m, nil
+}
+
+// FormatRetentions is the inverse of ParseRetentions. The output is sorted by predicate.
+func FormatRetentions(m map[string]Retention) string {
+	attrs := make([]string, 0, len(m))
+	for attr := range m {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var buf bytes.Buffer
+	for i, attr := range attrs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(attr)
+		buf.WriteByte(':')
+		buf.WriteString(m[attr].String())
+	}
+	return buf.String()
+}
+
+// versionEntry returns the entry to keep the committed version of l, which is about to be
+// superseded at ts, or nil if the retention of its predicate doesn't keep older versions.
+func (l *List) versionEntry(ts time.Time) *badger.Entry {
+	if l.storedSize == 0 {
+		return nil
+	}
+	retained.RLock()
+	empty := len(retained.m) == 0
+	retained.RUnlock()
+	if empty {
+		return nil
+	}
+	pk := x.Parse(l.key)
+	if pk == nil || !pk.IsData() || !RetentionFor(pk.Attr).enabled() {
+		return nil
+	}
+
+	e := &badger.Entry{Key: x.VersionKey(pk.Attr, pk.Uid, uint64(ts.UnixNano()))}
+	if len(l.plist.Postings) == 0 {
+		e.Value = l.plist.Uids
+		e.UserMeta = bitUidPostings
+		return e
+	}
+	// The blobs of the version may be deleted along with it being superseded, so their values
+	// are kept in line.
+	pl := &protos.PostingList{
+		Postings: make([]*protos.Posting, len(l.plist.Postings)),
+		Uids:     l.plist.Uids,
+	}
+	for i, p := range l.plist.Postings {
+		pl.Postings[i] = readBlob(pk, p)
+	}
+	val, err := pl.Marshal()
+	x.Checkf(err, "Unable to marshal version of posting list")
+	e.Value = val
+	return e
+}
+
+// VersionValue is a value of a version of a posting list.
+type VersionValue struct {
+	Value string `json:"value"`
+	Lang  string `json:"lang,omitempty"`
+}
+
+// Version is an older version of a data posting list, kept by the retention of its predicate.
+type Version struct {
+	Superseded time.Time      `json:"superseded"`
+	Uids       []uint64       `json:"uids,omitempty"`
+	Values     []VersionValue `json:"values,omitempty"`
+}
+
+func newVersion(key, val []byte, meta byte) Version {
+	ts := binary.BigEndian.Uint64(key[len(key)-8:])
+	v := Version{Superseded: time.Unix(0, int64(ts))}
+
+	var pl protos.PostingList
+	UnmarshalWithCopy(val, meta, &pl)
+	var bi bp128.BPackIterator
+	bi.Init(pl.Uids, 0)
+	for ; bi.Valid(); bi.Next() {
+		v.Uids = append(v.Uids, bi.Uids()...)
+	}
+	for _, p := range pl.Postings {
+		if postingType(p) == x.ValueUid {
+			continue
+		}
+		val := types.Val{Tid: types.TypeID(p.ValType), Value: p.Value}
+		sv, err := types.Convert(val, types.StringID)
+		if err != nil {
+			sv.Value = string(p.Value)
+		}
+		v.Values = append(v.Values, VersionValue{Value: sv.Value.(string), Lang: string(p.Metadata)})
+	}
+	if len(v.Values) > 0 {
+		// Values have no uids worth showing.
+		v.Uids = nil
+	}
+	return v
+}
+
+// Versions returns the older versions of the data posting list of (attr, uid) which are kept,
+// oldest first.
+func Versions(attr string, uid uint64) []Version {
+	prefix := x.VersionKey(attr, uid, 0)
+	prefix = prefix[:len(prefix)-8]
+
+	var versions []Version
+	it := pstore.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+		versions = append(versions, newVersion(item.Key(), item.Value(), item.UserMeta()))
+	}
+	return versions
+}
+
+// VersionStats are the older versions of posting lists kept and deleted by CollectVersions.
+type VersionStats struct {
+	Kept    int   `json:"kept"`
+	Deleted int   `json:"deleted"`
+	Bytes   int64 `json:"bytes"` // Bytes of the deleted versions.
+}
+
+type storedVersion struct {
+	key  []byte
+	size int64
+	ts   time.Time
+}
+
+// CollectVersions deletes the older versions of data posting lists which the retention of their
+// predicates doesn't keep anymore, as of now, and updates the bytes retained per predicate.
+func CollectVersions(now time.Time) (VersionStats, error) {
+	var stats VersionStats
+	var dels []*badger.Entry
+	flush := func() error {
+		if len(dels) == 0 {
+			return nil
+		}
+		err := pstore.BatchSet(dels)
+		for _, e := range dels {
+			if err != nil {
+				break
+			}
+			err = e.Error
+		}
+		dels = dels[:0]
+		return x.Wrapf(err, "While deleting older versions of posting lists")
+	}
+
+	seen := make(map[string]bool)
+	// collect deletes the versions of a list, oldest first, which aren't retained.
+	collect := func(r Retention, list []storedVersion) error {
+		for i, v := range list {
+			if r.keeps(len(list)-1-i, now.Sub(v.ts)) {
+				stats.Kept++
+				continue
+			}
+			stats.Deleted++
+			stats.Bytes += v.size
+			dels = badger.EntriesDelete(dels, v.key)
+		}
+		if len(dels) >= 1000 {
+			return flush()
+		}
+		return nil
+	}
+
+	it := pstore.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	// Do NOT go to next by default. Each predicate is visited once, seeking to its versions.
+	for it.Rewind(); it.Valid(); {
+		pk := x.Parse(it.Item().Key())
+		if pk == nil {
+			it.Next()
+			continue
+		}
+		if pk.IsSchema() {
+			it.Seek(pk.SkipSchema())
+			continue
+		}
+		seen[pk.Attr] = true
+
+		r := RetentionFor(pk.Attr)
+		prefix := pk.VersionPrefix()
+		deleted := stats.Bytes
+		var total int64
+		var list []storedVersion
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			key := make([]byte, len(item.Key()))
+			copy(key, item.Key())
+			// The versions of a list share their key, but for the timestamp.
+			if len(list) > 0 && !bytes.Equal(list[0].key[:len(key)-8], key[:len(key)-8]) {
+				if err := collect(r, list); err != nil {
+					return stats, err
+				}
+				list = list[:0]
+			}
+			ts := binary.BigEndian.Uint64(key[len(key)-8:])
+			size := int64(len(key) + len(item.Value()))
+			list = append(list, storedVersion{key: key, size: size, ts: time.Unix(0, int64(ts))})
+			total += size
+		}
+		if err := collect(r, list); err != nil {
+			return stats, err
+		}
+		if total > 0 || x.RetainedBytes.Get(pk.Attr) != nil {
+			setRetainedBytes(pk.Attr, total-(stats.Bytes-deleted))
+		}
+		it.Seek(pk.SkipPredicate())
+	}
+	if err := flush(); err != nil {
+		return stats, err
+	}
+
+	// Predicates without any keys left have nothing retained.
+	x.RetainedBytes.Do(func(kv expvar.KeyValue) {
+		if !seen[kv.Key] {
+			setRetainedBytes(kv.Key, 0)
+		}
+	})
+	return stats, nil
+}
+
+func setRetainedBytes(attr string, n int64) {
+	v := new(expvar.Int)
+	v.Set(n)
+	x.RetainedBytes.Set(attr, v)
+}
+
+// periodicVersionGC runs CollectVersions every interval.
+func periodicVersionGC(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for now := range ticker.C {
+		stats, err := CollectVersions(now)
+		if err != nil {
+			x.Printf("Error while collecting older versions of posting lists: %v\n", err)
+			continue
+		}
+		if stats.Deleted > 0 {
+			x.Printf("Deleted %d older versions of posting lists, of %d bytes. Kept %d.\n",
+				stats.Deleted, stats.Bytes, stats.Kept)
+		}
+	}
+}
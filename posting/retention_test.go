@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"bytes"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestParseRetentions(t *testing.T) {
+	m, err := ParseRetentions(" name:720h, friend:5,age:0 ")
+	require.NoError(t, err)
+	require.Equal(t, map[string]Retention{
+		"name":   {Age: 720 * time.Hour},
+		"friend": {Versions: 5},
+		"age":    {},
+	}, m)
+
+	m, err = ParseRetentions("")
+	require.NoError(t, err)
+	require.Empty(t, m)
+
+	for _, s := range []string{"name", "name:forever", "name:-1", "name:-1h", ":5"} {
+		_, err = ParseRetentions(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestFormatRetentions(t *testing.T) {
+	m, err := ParseRetentions("name:1h30m,friend:5")
+	require.NoError(t, err)
+	require.Equal(t, "friend:5,name:1h30m0s", FormatRetentions(m))
+
+	SetRetentions(m)
+	defer SetRetentions(nil)
+	m["age"] = Retention{}
+	SetRetentions(m)
+	require.Equal(t, "friend:5,name:1h30m0s", FormatRetentions(Retentions()))
+}
+
+func TestRetentionKeeps(t *testing.T) {
+	r := Retention{Versions: 2}
+	require.True(t, r.keeps(0, 100*time.Hour))
+	require.True(t, r.keeps(1, 0))
+	require.False(t, r.keeps(2, 0))
+
+	r = Retention{Age: time.Hour}
+	require.True(t, r.keeps(10, time.Hour))
+	require.False(t, r.keeps(0, time.Hour+1))
+}
+
+// commitValue sets the value of l to val, and writes it to the store.
+func commitValue(t *testing.T, l *List, attr string, val []byte) {
+	addMutation(t, l, &protos.DirectedEdge{Attr: attr, Value: val}, Set)
+	_, err := l.SyncIfDirty(false)
+	require.NoError(t, err)
+}
+
+// waitForVersions waits for n versions of the list of (attr, uid) to be kept, and returns them.
+func waitForVersions(t *testing.T, attr string, uid uint64, n int) []Version {
+	var versions []Version
+	for i := 0; i < 100; i++ {
+		if versions = Versions(attr, uid); len(versions) == n {
+			return versions
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d versions of (%s, %d), got %d", n, attr, uid, len(versions))
+	return nil
+}
+
+func versionValues(versions []Version) []string {
+	var vals []string
+	for _, v := range versions {
+		for _, val := range v.Values {
+			vals = append(vals, val.Value)
+		}
+	}
+	return vals
+}
+
+// storedVersionBytes returns the bytes of the versions of attr in the store.
+func storedVersionBytes(attr string) int64 {
+	prefix := x.ParsedKey{Attr: attr}.VersionPrefix()
+	it := ps.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	var n int64
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		n += int64(len(it.Item().Key()) + len(it.Item().Value()))
+	}
+	return n
+}
+
+func retainedBytes(attr string) int64 {
+	v := x.RetainedBytes.Get(attr)
+	if v == nil {
+		return -1
+	}
+	return v.(*expvar.Int).Value()
+}
+
+func TestRetainVersions(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte("retain:string .\nretain_uid:uid ."), 1))
+	SetRetentions(map[string]Retention{"retain": {Versions: 2}, "retain_uid": {Versions: 1}})
+	defer SetRetentions(nil)
+
+	key := x.DataKey("retain", 1)
+	l := getNew(key, ps)
+	for _, val := range []string{"a", "b", "c", "d"} {
+		commitValue(t, l, "retain", []byte(val))
+	}
+	// Each commit keeps the version it supersedes, whatever the retention, until collected.
+	versions := waitForVersions(t, "retain", 1, 3)
+	require.Equal(t, []string{"a", "b", "c"}, versionValues(versions))
+	require.True(t, versions[0].Superseded.Before(versions[2].Superseded))
+	checkValue(t, l, "d")
+
+	// Lists of uids keep their uids.
+	ul := getNew(x.DataKey("retain_uid", 1), ps)
+	for _, uid := range []uint64{2, 3} {
+		addMutation(t, ul, &protos.DirectedEdge{Attr: "retain_uid", ValueId: uid}, Set)
+		_, err := ul.SyncIfDirty(false)
+		require.NoError(t, err)
+	}
+	uversions := waitForVersions(t, "retain_uid", 1, 1)
+	require.Equal(t, []uint64{2}, uversions[0].Uids)
+
+	stats, err := CollectVersions(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Deleted)
+	require.Equal(t, 3, stats.Kept)
+	require.True(t, stats.Bytes > 0)
+	require.Equal(t, []string{"b", "c"}, versionValues(Versions("retain", 1)))
+	require.Equal(t, storedVersionBytes("retain"), retainedBytes("retain"))
+	require.True(t, retainedBytes("retain") > 0)
+
+	// Dropping the retention of a predicate deletes its versions, and stops keeping new ones.
+	SetRetentions(map[string]Retention{"retain": {Versions: 2}})
+	addMutation(t, ul, &protos.DirectedEdge{Attr: "retain_uid", ValueId: 4}, Set)
+	_, err = ul.SyncIfDirty(false)
+	require.NoError(t, err)
+	stats, err = CollectVersions(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Deleted)
+	require.Empty(t, Versions("retain_uid", 1))
+	require.Equal(t, int64(0), retainedBytes("retain_uid"))
+	require.Len(t, Versions("retain", 1), 2)
+
+	SetRetentions(nil)
+	_, err = CollectVersions(time.Now())
+	require.NoError(t, err)
+	require.Empty(t, Versions("retain", 1))
+	require.Equal(t, int64(0), retainedBytes("retain"))
+
+	deletePl(t)
+	ps.Delete(key)
+	ps.Delete(ul.key)
+}
+
+func TestRetainVersionsAge(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte("retain:string ."), 1))
+	SetRetentions(map[string]Retention{"retain": {Age: time.Hour}})
+	defer SetRetentions(nil)
+
+	key := x.DataKey("retain", 2)
+	l := getNew(key, ps)
+	for _, val := range []string{"a", "b", "c"} {
+		commitValue(t, l, "retain", []byte(val))
+	}
+	waitForVersions(t, "retain", 2, 2)
+
+	stats, err := CollectVersions(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 0, stats.Deleted)
+	require.Equal(t, 2, stats.Kept)
+
+	// Once they're older than the retention, all the versions go.
+	stats, err = CollectVersions(time.Now().Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.Deleted)
+	require.Equal(t, 0, stats.Kept)
+	require.Empty(t, Versions("retain", 2))
+	require.Equal(t, int64(0), retainedBytes("retain"))
+
+	deletePl(t)
+	ps.Delete(key)
+}
+
+func TestRetainVersionsBlob(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte("retain:string ."), 1))
+	SetRetentions(map[string]Retention{"retain": {Versions: 1}})
+	defer SetRetentions(nil)
+	Config.BlobThreshold = 100
+	defer func() { Config.BlobThreshold = 0 }()
+
+	key := x.DataKey("retain", 3)
+	blobKey := x.BlobKey("retain", 3, ^uint64(0))
+	big := bytes.Repeat([]byte("a"), 1000)
+	l := getNew(key, ps)
+	commitValue(t, l, "retain", big)
+	waitForBlob(t, blobKey, true)
+
+	// The list read back only refers to the big value, which the version keeps in line, as the
+	// blob is deleted once it's superseded.
+	l = getNew(key, ps)
+	commitValue(t, l, "retain", []byte("small"))
+	waitForBlob(t, blobKey, false)
+	versions := waitForVersions(t, "retain", 3, 1)
+	require.Equal(t, []string{string(big)}, versionValues(versions))
+
+	SetRetentions(nil)
+	_, err := CollectVersions(time.Now())
+	require.NoError(t, err)
+	deletePl(t)
+	ps.Delete(key)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"sort"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/bp128"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// KeyStats is the number of keys of one kind, and their size in the store.
+type KeyStats struct {
+	Keys  int   `json:"keys"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (s *KeyStats) add(key, val []byte) {
+	s.Keys++
+	s.Bytes += int64(len(key) + len(val))
+}
+
+// PredicateStats describe how a predicate is stored on this server.
+type PredicateStats struct {
+	Predicate string `json:"predicate"`
+	KeyStats
+	// Number of postings over all data posting lists, and the average per list.
+	Postings         int     `json:"postings"`
+	AvgPostingLength float64 `json:"avg_posting_length"`
+	// Versions of the posting lists held. There's one in the store per key, and one more for each
+	// list with mutations which are yet to be merged.
+	Versions int `json:"versions"`
+
+	Data    KeyStats `json:"data"`
+	Index   KeyStats `json:"index"`
+	Reverse KeyStats `json:"reverse"`
+	Count   KeyStats `json:"count"`
+	Blob    KeyStats `json:"blob"`
+	// Older versions of data posting lists kept by the retention of the predicate.
+	Retained KeyStats `json:"retained"`
+}
+
+func numPostings(val []byte, meta byte) int {
+	if meta == bitUidPostings {
+		return bp128.NumIntegers(val)
+	}
+	var pl protos.PostingList
+	if len(val) == 0 || pl.Unmarshal(val) != nil {
+		return 0
+	}
+	return bp128.NumIntegers(pl.Uids)
+}
+
+// CollectStats goes over the store, and returns the stats of the predicate attr, or of all the
+// predicates sorted by name if attr is empty. This reads all the keys of the predicates, so it's
+// as expensive as an export.
+func CollectStats(attr string) []*PredicateStats {
+	stats := make(map[string]*PredicateStats)
+	get := func(attr string) *PredicateStats {
+		s, ok := stats[attr]
+		if !ok {
+			s = &PredicateStats{Predicate: attr}
+			stats[attr] = s
+		}
+		return s
+	}
+
+	it := pstore.NewIterator(badger.DefaultIteratorOptions)
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		key := item.Key()
+		pk := x.Parse(key)
+		if pk == nil || pk.IsSchema() || (attr != "" && pk.Attr != attr) {
+			continue
+		}
+		val := item.Value()
+		s := get(pk.Attr)
+		s.add(key, val)
+		switch {
+		case pk.IsData():
+			s.Data.add(key, val)
+			s.Postings += numPostings(val, item.UserMeta())
+		case pk.IsIndex():
+			s.Index.add(key, val)
+		case pk.IsReverse():
+			s.Reverse.add(key, val)
+		case pk.IsCount():
+			s.Count.add(key, val)
+		case pk.IsBlob():
+			s.Blob.add(key, val)
+		case pk.IsVersion():
+			s.Retained.add(key, val)
+		}
+		if !pk.IsBlob() {
+			s.Versions++
+		}
+	}
+	it.Close()
+
+	var lists []*List
+	lcache.Each(func(key []byte, l *List) {
+		lists = append(lists, l)
+	})
+	for _, l := range lists {
+		pk := x.Parse(l.key)
+		if pk == nil || (attr != "" && pk.Attr != attr) {
+			continue
+		}
+		l.RLock()
+		dirty := len(l.mlayer) > 0
+		l.RUnlock()
+		if dirty {
+			get(pk.Attr).Versions++
+		}
+	}
+
+	res := make([]*PredicateStats, 0, len(stats))
+	for _, s := range stats {
+		if s.Data.Keys > 0 {
+			s.AvgPostingLength = float64(s.Postings) / float64(s.Data.Keys)
+		}
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Predicate < res[j].Predicate })
+	return res
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestCollectStats(t *testing.T) {
+	for uid := uint64(1); uid <= 2; uid++ {
+		key := x.DataKey("stats", uid)
+		ol := getNew(key, ps)
+		for i := uint64(0); i < uid*2; i++ {
+			addMutation(t, ol, &protos.DirectedEdge{ValueId: 10 + i, Attr: "stats"}, Set)
+		}
+		_, err := ol.SyncIfDirty(false)
+		require.NoError(t, err)
+		waitForBlob(t, key, true)
+	}
+	require.NoError(t, ps.Set(x.IndexKey("stats", "term"), []byte("index"), 0x00))
+
+	stats := CollectStats("stats")
+	require.Len(t, stats, 1)
+	s := stats[0]
+	require.Equal(t, "stats", s.Predicate)
+	require.Equal(t, 3, s.Keys)
+	require.Equal(t, 2, s.Data.Keys)
+	require.Equal(t, 1, s.Index.Keys)
+	require.Equal(t, 6, s.Postings)
+	require.Equal(t, 3.0, s.AvgPostingLength)
+	require.Equal(t, 3, s.Versions)
+	require.Equal(t, s.Data.Bytes+s.Index.Bytes, s.Bytes)
+
+	deletePl(t)
+	ps.Delete(x.DataKey("stats", 1))
+	ps.Delete(x.DataKey("stats", 2))
+	ps.Delete(x.IndexKey("stats", "term"))
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	minCommitBatch = 100
+	maxCommitBatch = 100000
+	// Number of batches over which the p99 latency is calculated.
+	tunerWindow = 100
+)
+
+// commitTuner picks the number of dirty posting lists gentleCommit merges in one batch. It grows
+// the batch while batches come back full and well within Config.CommitLatency, to keep up with
+// heavy write loads, and halves it as soon as the p99 latency of recent batches exceeds it.
+type commitTuner struct {
+	sync.Mutex
+	size      int
+	latencies []time.Duration
+	idx       int
+}
+
+var tuner = newCommitTuner()
+
+func newCommitTuner() *commitTuner {
+	return &commitTuner{
+		size:      1000,
+		latencies: make([]time.Duration, 0, tunerWindow),
+	}
+}
+
+func (t *commitTuner) batchSize() int {
+	t.Lock()
+	defer t.Unlock()
+	return t.size
+}
+
+func (t *commitTuner) p99() time.Duration {
+	lat := make([]time.Duration, len(t.latencies))
+	copy(lat, t.latencies)
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	return lat[(len(lat)*99)/100]
+}
+
+// record is called with the number of lists committed in a batch and the time it took.
+func (t *commitTuner) record(n int, dur time.Duration, target time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.latencies) < tunerWindow {
+		t.latencies = append(t.latencies, dur)
+	} else {
+		t.latencies[t.idx] = dur
+		t.idx = (t.idx + 1) % tunerWindow
+	}
+
+	switch {
+	case t.p99() > target:
+		t.size /= 2
+		if t.size < minCommitBatch {
+			t.size = minCommitBatch
+		}
+		// Latencies seen so far were for bigger batches.
+		t.latencies = t.latencies[:0]
+		t.idx = 0
+	case n >= t.size && dur < target/2:
+		t.size += t.size / 4
+		if t.size > maxCommitBatch {
+			t.size = maxCommitBatch
+		}
+	}
+	x.CommitBatchSize.Set(int64(t.size))
+}
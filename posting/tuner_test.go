@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitTuner(t *testing.T) {
+	target := 100 * time.Millisecond
+	ct := newCommitTuner()
+	require.Equal(t, 1000, ct.batchSize())
+
+	// Full and fast batches grow the batch size.
+	ct.record(1000, time.Millisecond, target)
+	require.Equal(t, 1250, ct.batchSize())
+
+	// Batches which aren't full don't.
+	ct.record(10, time.Millisecond, target)
+	require.Equal(t, 1250, ct.batchSize())
+
+	// A slow batch halves it.
+	ct.record(1250, time.Second, target)
+	require.Equal(t, 625, ct.batchSize())
+
+	for i := 0; i < 100; i++ {
+		ct.record(ct.batchSize(), time.Second, target)
+	}
+	require.Equal(t, minCommitBatch, ct.batchSize())
+
+	for i := 0; i < 100; i++ {
+		ct.record(ct.batchSize(), time.Millisecond, target)
+	}
+	require.Equal(t, maxCommitBatch, ct.batchSize())
+}
@@ -20,6 +20,119 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type ExportRequest struct {
+	Format  string   `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Include []string `protobuf:"bytes,2,rep,name=include" json:"include,omitempty"`
+	Exclude []string `protobuf:"bytes,3,rep,name=exclude" json:"exclude,omitempty"`
+	// Offsets of the last chunks received for groups, to resume an export.
+	Offsets []*ExportOffset `protobuf:"bytes,4,rep,name=offsets" json:"offsets,omitempty"`
+}
+
+func (m *ExportRequest) Reset()                    { *m = ExportRequest{} }
+func (m *ExportRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportRequest) ProtoMessage()               {}
+func (*ExportRequest) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{0} }
+
+func (m *ExportRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ExportRequest) GetInclude() []string {
+	if m != nil {
+		return m.Include
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetExclude() []string {
+	if m != nil {
+		return m.Exclude
+	}
+	return nil
+}
+
+func (m *ExportRequest) GetOffsets() []*ExportOffset {
+	if m != nil {
+		return m.Offsets
+	}
+	return nil
+}
+
+type ExportOffset struct {
+	GroupId uint32 `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	After   []byte `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+	Done    bool   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+}
+
+func (m *ExportOffset) Reset()                    { *m = ExportOffset{} }
+func (m *ExportOffset) String() string            { return proto.CompactTextString(m) }
+func (*ExportOffset) ProtoMessage()               {}
+func (*ExportOffset) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{1} }
+
+func (m *ExportOffset) GetGroupId() uint32 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *ExportOffset) GetAfter() []byte {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *ExportOffset) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+type ExportChunk struct {
+	GroupId uint32        `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Data    []byte        `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Schema  []byte        `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Offset  *ExportOffset `protobuf:"bytes,4,opt,name=offset" json:"offset,omitempty"`
+}
+
+func (m *ExportChunk) Reset()                    { *m = ExportChunk{} }
+func (m *ExportChunk) String() string            { return proto.CompactTextString(m) }
+func (*ExportChunk) ProtoMessage()               {}
+func (*ExportChunk) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{2} }
+
+func (m *ExportChunk) GetGroupId() uint32 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *ExportChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ExportChunk) GetSchema() []byte {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+func (m *ExportChunk) GetOffset() *ExportOffset {
+	if m != nil {
+		return m.Offset
+	}
+	return nil
+}
+
 type Num struct {
 	Val uint64 `protobuf:"varint,1,opt,name=val,proto3" json:"val,omitempty"`
 }
@@ -27,7 +140,7 @@ type Num struct {
 func (m *Num) Reset()                    { *m = Num{} }
 func (m *Num) String() string            { return proto.CompactTextString(m) }
 func (*Num) ProtoMessage()               {}
-func (*Num) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{0} }
+func (*Num) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{3} }
 
 func (m *Num) GetVal() uint64 {
 	if m != nil {
@@ -44,7 +157,7 @@ type AssignedIds struct {
 func (m *AssignedIds) Reset()                    { *m = AssignedIds{} }
 func (m *AssignedIds) String() string            { return proto.CompactTextString(m) }
 func (*AssignedIds) ProtoMessage()               {}
-func (*AssignedIds) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{1} }
+func (*AssignedIds) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{4} }
 
 func (m *AssignedIds) GetStartId() uint64 {
 	if m != nil {
@@ -76,7 +189,7 @@ type NQuad struct {
 func (m *NQuad) Reset()                    { *m = NQuad{} }
 func (m *NQuad) String() string            { return proto.CompactTextString(m) }
 func (*NQuad) ProtoMessage()               {}
-func (*NQuad) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{2} }
+func (*NQuad) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{5} }
 
 func (m *NQuad) GetSubject() string {
 	if m != nil {
@@ -167,7 +280,7 @@ type Value struct {
 func (m *Value) Reset()                    { *m = Value{} }
 func (m *Value) String() string            { return proto.CompactTextString(m) }
 func (*Value) ProtoMessage()               {}
-func (*Value) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{3} }
+func (*Value) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{6} }
 
 type isValue_Val interface {
 	isValue_Val()
@@ -515,7 +628,7 @@ type Mutation struct {
 func (m *Mutation) Reset()                    { *m = Mutation{} }
 func (m *Mutation) String() string            { return proto.CompactTextString(m) }
 func (*Mutation) ProtoMessage()               {}
-func (*Mutation) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{4} }
+func (*Mutation) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{7} }
 
 func (m *Mutation) GetSet() []*NQuad {
 	if m != nil {
@@ -539,16 +652,18 @@ func (m *Mutation) GetSchema() []*SchemaUpdate {
 }
 
 type Request struct {
-	Query    string            `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	Mutation *Mutation         `protobuf:"bytes,2,opt,name=mutation" json:"mutation,omitempty"`
-	Schema   *SchemaRequest    `protobuf:"bytes,3,opt,name=schema" json:"schema,omitempty"`
-	Vars     map[string]string `protobuf:"bytes,4,rep,name=vars" json:"vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Query      string            `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Mutation   *Mutation         `protobuf:"bytes,2,opt,name=mutation" json:"mutation,omitempty"`
+	Schema     *SchemaRequest    `protobuf:"bytes,3,opt,name=schema" json:"schema,omitempty"`
+	Vars       map[string]string `protobuf:"bytes,4,rep,name=vars" json:"vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	QueryId    string            `protobuf:"bytes,5,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
+	ApiVersion uint32            `protobuf:"varint,6,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
 }
 
 func (m *Request) Reset()                    { *m = Request{} }
 func (m *Request) String() string            { return proto.CompactTextString(m) }
 func (*Request) ProtoMessage()               {}
-func (*Request) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{5} }
+func (*Request) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{8} }
 
 func (m *Request) GetQuery() string {
 	if m != nil {
@@ -578,6 +693,20 @@ func (m *Request) GetVars() map[string]string {
 	return nil
 }
 
+func (m *Request) GetQueryId() string {
+	if m != nil {
+		return m.QueryId
+	}
+	return ""
+}
+
+func (m *Request) GetApiVersion() uint32 {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return 0
+}
+
 type Latency struct {
 	Parsing    string `protobuf:"bytes,1,opt,name=parsing,proto3" json:"parsing,omitempty"`
 	Processing string `protobuf:"bytes,2,opt,name=processing,proto3" json:"processing,omitempty"`
@@ -587,7 +716,7 @@ type Latency struct {
 func (m *Latency) Reset()                    { *m = Latency{} }
 func (m *Latency) String() string            { return proto.CompactTextString(m) }
 func (*Latency) ProtoMessage()               {}
-func (*Latency) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{6} }
+func (*Latency) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{9} }
 
 func (m *Latency) GetParsing() string {
 	if m != nil {
@@ -618,7 +747,7 @@ type Property struct {
 func (m *Property) Reset()                    { *m = Property{} }
 func (m *Property) String() string            { return proto.CompactTextString(m) }
 func (*Property) ProtoMessage()               {}
-func (*Property) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{7} }
+func (*Property) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{10} }
 
 func (m *Property) GetProp() string {
 	if m != nil {
@@ -643,7 +772,7 @@ type Node struct {
 func (m *Node) Reset()                    { *m = Node{} }
 func (m *Node) String() string            { return proto.CompactTextString(m) }
 func (*Node) ProtoMessage()               {}
-func (*Node) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{8} }
+func (*Node) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{11} }
 
 func (m *Node) GetAttribute() string {
 	if m != nil {
@@ -676,7 +805,7 @@ type Response struct {
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{9} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{12} }
 
 func (m *Response) GetN() []*Node {
 	if m != nil {
@@ -706,22 +835,68 @@ func (m *Response) GetSchema() []*SchemaNode {
 	return nil
 }
 
+// Deprecation tells of a deprecated feature used by a request, which is gone from API version
+// removed_in on.
+type Deprecation struct {
+	Feature   string `protobuf:"bytes,1,opt,name=feature,proto3" json:"feature,omitempty"`
+	Message   string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	RemovedIn uint32 `protobuf:"varint,3,opt,name=removed_in,json=removedIn,proto3" json:"removed_in,omitempty"`
+}
+
+func (m *Deprecation) Reset()                    { *m = Deprecation{} }
+func (m *Deprecation) String() string            { return proto.CompactTextString(m) }
+func (*Deprecation) ProtoMessage()               {}
+func (*Deprecation) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{13} }
+
+func (m *Deprecation) GetFeature() string {
+	if m != nil {
+		return m.Feature
+	}
+	return ""
+}
+
+func (m *Deprecation) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Deprecation) GetRemovedIn() uint32 {
+	if m != nil {
+		return m.RemovedIn
+	}
+	return 0
+}
+
 type Check struct {
+	ApiVersion uint32 `protobuf:"varint,1,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
 }
 
 func (m *Check) Reset()                    { *m = Check{} }
 func (m *Check) String() string            { return proto.CompactTextString(m) }
 func (*Check) ProtoMessage()               {}
-func (*Check) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{10} }
+func (*Check) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{14} }
+
+func (m *Check) GetApiVersion() uint32 {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return 0
+}
 
 type Version struct {
-	Tag string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Tag           string   `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	ApiVersion    uint32   `protobuf:"varint,2,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	MinApiVersion uint32   `protobuf:"varint,3,opt,name=min_api_version,json=minApiVersion,proto3" json:"min_api_version,omitempty"`
+	MaxApiVersion uint32   `protobuf:"varint,4,opt,name=max_api_version,json=maxApiVersion,proto3" json:"max_api_version,omitempty"`
+	Capabilities  []string `protobuf:"bytes,5,rep,name=capabilities" json:"capabilities,omitempty"`
 }
 
 func (m *Version) Reset()                    { *m = Version{} }
 func (m *Version) String() string            { return proto.CompactTextString(m) }
 func (*Version) ProtoMessage()               {}
-func (*Version) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{11} }
+func (*Version) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{15} }
 
 func (m *Version) GetTag() string {
 	if m != nil {
@@ -730,7 +905,240 @@ func (m *Version) GetTag() string {
 	return ""
 }
 
+func (m *Version) GetApiVersion() uint32 {
+	if m != nil {
+		return m.ApiVersion
+	}
+	return 0
+}
+
+func (m *Version) GetMinApiVersion() uint32 {
+	if m != nil {
+		return m.MinApiVersion
+	}
+	return 0
+}
+
+func (m *Version) GetMaxApiVersion() uint32 {
+	if m != nil {
+		return m.MaxApiVersion
+	}
+	return 0
+}
+
+func (m *Version) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+// MembersRequest asks for the members of the cluster, and the groups serving the predicates.
+type MembersRequest struct {
+	Predicates []string `protobuf:"bytes,1,rep,name=predicates" json:"predicates,omitempty"`
+}
+
+func (m *MembersRequest) Reset()                    { *m = MembersRequest{} }
+func (m *MembersRequest) String() string            { return proto.CompactTextString(m) }
+func (*MembersRequest) ProtoMessage()               {}
+func (*MembersRequest) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{16} }
+
+func (m *MembersRequest) GetPredicates() []string {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+// Member is a server of the cluster serving a group, at the address clients reach it at.
+type Member struct {
+	Id      uint64 `protobuf:"fixed64,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupId uint32 `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Addr    string `protobuf:"bytes,3,opt,name=addr,proto3" json:"addr,omitempty"`
+	Leader  bool   `protobuf:"varint,4,opt,name=leader,proto3" json:"leader,omitempty"`
+}
+
+func (m *Member) Reset()                    { *m = Member{} }
+func (m *Member) String() string            { return proto.CompactTextString(m) }
+func (*Member) ProtoMessage()               {}
+func (*Member) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{17} }
+
+func (m *Member) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Member) GetGroupId() uint32 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *Member) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *Member) GetLeader() bool {
+	if m != nil {
+		return m.Leader
+	}
+	return false
+}
+
+type MemberList struct {
+	Members []*Member `protobuf:"bytes,1,rep,name=members" json:"members,omitempty"`
+	// The groups serving the predicates of the request, in their order.
+	Groups []uint32 `protobuf:"varint,2,rep,packed,name=groups" json:"groups,omitempty"`
+}
+
+func (m *MemberList) Reset()                    { *m = MemberList{} }
+func (m *MemberList) String() string            { return proto.CompactTextString(m) }
+func (*MemberList) ProtoMessage()               {}
+func (*MemberList) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{18} }
+
+func (m *MemberList) GetMembers() []*Member {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+func (m *MemberList) GetGroups() []uint32 {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+// SubscribeRequest asks for the changes to the predicates matching patterns, like name or
+// address.*, or to all predicates without any.
+type SubscribeRequest struct {
+	Predicates []string `protobuf:"bytes,1,rep,name=predicates" json:"predicates,omitempty"`
+	// Only edges whose values match all the filters of their predicate are sent.
+	Filters []*ChangeFilter `protobuf:"bytes,2,rep,name=filters" json:"filters,omitempty"`
+	// Unix time in nanoseconds to send changes from, or now if 0.
+	Since int64 `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+}
+
+func (m *SubscribeRequest) Reset()                    { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string            { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()               {}
+func (*SubscribeRequest) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{19} }
+
+func (m *SubscribeRequest) GetPredicates() []string {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+func (m *SubscribeRequest) GetFilters() []*ChangeFilter {
+	if m != nil {
+		return m.Filters
+	}
+	return nil
+}
+
+func (m *SubscribeRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+type ChangeFilter struct {
+	Predicate string `protobuf:"bytes,1,opt,name=predicate,proto3" json:"predicate,omitempty"`
+	// eq, lt, le, gt or ge.
+	Op    string `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`
+	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *ChangeFilter) Reset()                    { *m = ChangeFilter{} }
+func (m *ChangeFilter) String() string            { return proto.CompactTextString(m) }
+func (*ChangeFilter) ProtoMessage()               {}
+func (*ChangeFilter) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{20} }
+
+func (m *ChangeFilter) GetPredicate() string {
+	if m != nil {
+		return m.Predicate
+	}
+	return ""
+}
+
+func (m *ChangeFilter) GetOp() string {
+	if m != nil {
+		return m.Op
+	}
+	return ""
+}
+
+func (m *ChangeFilter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// ChangeEvent is an entry of the changelog of a group, with the edges it set and deleted.
+type ChangeEvent struct {
+	GroupId uint32 `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Index   uint64 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// Unix time in nanoseconds.
+	CommitTs int64    `protobuf:"varint,3,opt,name=commit_ts,json=commitTs,proto3" json:"commit_ts,omitempty"`
+	Set      []*NQuad `protobuf:"bytes,4,rep,name=set" json:"set,omitempty"`
+	Del      []*NQuad `protobuf:"bytes,5,rep,name=del" json:"del,omitempty"`
+}
+
+func (m *ChangeEvent) Reset()                    { *m = ChangeEvent{} }
+func (m *ChangeEvent) String() string            { return proto.CompactTextString(m) }
+func (*ChangeEvent) ProtoMessage()               {}
+func (*ChangeEvent) Descriptor() ([]byte, []int) { return fileDescriptorGraphresponse, []int{21} }
+
+func (m *ChangeEvent) GetGroupId() uint32 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *ChangeEvent) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *ChangeEvent) GetCommitTs() int64 {
+	if m != nil {
+		return m.CommitTs
+	}
+	return 0
+}
+
+func (m *ChangeEvent) GetSet() []*NQuad {
+	if m != nil {
+		return m.Set
+	}
+	return nil
+}
+
+func (m *ChangeEvent) GetDel() []*NQuad {
+	if m != nil {
+		return m.Del
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterType((*ExportRequest)(nil), "protos.ExportRequest")
+	proto.RegisterType((*ExportOffset)(nil), "protos.ExportOffset")
+	proto.RegisterType((*ExportChunk)(nil), "protos.ExportChunk")
 	proto.RegisterType((*Num)(nil), "protos.Num")
 	proto.RegisterType((*AssignedIds)(nil), "protos.AssignedIds")
 	proto.RegisterType((*NQuad)(nil), "protos.NQuad")
@@ -741,8 +1149,15 @@ func init() {
 	proto.RegisterType((*Property)(nil), "protos.Property")
 	proto.RegisterType((*Node)(nil), "protos.Node")
 	proto.RegisterType((*Response)(nil), "protos.Response")
+	proto.RegisterType((*Deprecation)(nil), "protos.Deprecation")
 	proto.RegisterType((*Check)(nil), "protos.Check")
 	proto.RegisterType((*Version)(nil), "protos.Version")
+	proto.RegisterType((*MembersRequest)(nil), "protos.MembersRequest")
+	proto.RegisterType((*Member)(nil), "protos.Member")
+	proto.RegisterType((*MemberList)(nil), "protos.MemberList")
+	proto.RegisterType((*SubscribeRequest)(nil), "protos.SubscribeRequest")
+	proto.RegisterType((*ChangeFilter)(nil), "protos.ChangeFilter")
+	proto.RegisterType((*ChangeEvent)(nil), "protos.ChangeEvent")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -757,8 +1172,15 @@ const _ = grpc.SupportPackageIsVersion4
 
 type DgraphClient interface {
 	Run(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	// RunStream returns the results of the query in parts, as they are built.
+	RunStream(ctx context.Context, in *Request, opts ...grpc.CallOption) (Dgraph_RunStreamClient, error)
 	CheckVersion(ctx context.Context, in *Check, opts ...grpc.CallOption) (*Version, error)
 	AssignUids(ctx context.Context, in *Num, opts ...grpc.CallOption) (*AssignedIds, error)
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Dgraph_ExportClient, error)
+	// Members returns the servers of the cluster, for clients to balance requests across.
+	Members(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*MemberList, error)
+	// Subscribe streams the changes committed to predicates, from the changelog.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Dgraph_SubscribeClient, error)
 }
 
 type dgraphClient struct {
@@ -778,6 +1200,38 @@ func (c *dgraphClient) Run(ctx context.Context, in *Request, opts ...grpc.CallOp
 	return out, nil
 }
 
+func (c *dgraphClient) RunStream(ctx context.Context, in *Request, opts ...grpc.CallOption) (Dgraph_RunStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Dgraph_serviceDesc.Streams[0], c.cc, "/protos.Dgraph/RunStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dgraphRunStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Dgraph_RunStreamClient interface {
+	Recv() (*Response, error)
+	grpc.ClientStream
+}
+
+type dgraphRunStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *dgraphRunStreamClient) Recv() (*Response, error) {
+	m := new(Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *dgraphClient) CheckVersion(ctx context.Context, in *Check, opts ...grpc.CallOption) (*Version, error) {
 	out := new(Version)
 	err := grpc.Invoke(ctx, "/protos.Dgraph/CheckVersion", in, out, c.cc, opts...)
@@ -796,12 +1250,92 @@ func (c *dgraphClient) AssignUids(ctx context.Context, in *Num, opts ...grpc.Cal
 	return out, nil
 }
 
-// Server API for Dgraph service
+func (c *dgraphClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Dgraph_ExportClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Dgraph_serviceDesc.Streams[1], c.cc, "/protos.Dgraph/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dgraphExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Dgraph_ExportClient interface {
+	Recv() (*ExportChunk, error)
+	grpc.ClientStream
+}
+
+type dgraphExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *dgraphExportClient) Recv() (*ExportChunk, error) {
+	m := new(ExportChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dgraphClient) Members(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*MemberList, error) {
+	out := new(MemberList)
+	err := grpc.Invoke(ctx, "/protos.Dgraph/Members", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dgraphClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Dgraph_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Dgraph_serviceDesc.Streams[2], c.cc, "/protos.Dgraph/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dgraphSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Dgraph_SubscribeClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type dgraphSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *dgraphSubscribeClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Dgraph service
 
 type DgraphServer interface {
 	Run(context.Context, *Request) (*Response, error)
+	// RunStream returns the results of the query in parts, as they are built.
+	RunStream(*Request, Dgraph_RunStreamServer) error
 	CheckVersion(context.Context, *Check) (*Version, error)
 	AssignUids(context.Context, *Num) (*AssignedIds, error)
+	Export(*ExportRequest, Dgraph_ExportServer) error
+	// Members returns the servers of the cluster, for clients to balance requests across.
+	Members(context.Context, *MembersRequest) (*MemberList, error)
+	// Subscribe streams the changes committed to predicates, from the changelog.
+	Subscribe(*SubscribeRequest, Dgraph_SubscribeServer) error
 }
 
 func RegisterDgraphServer(s *grpc.Server, srv DgraphServer) {
@@ -826,6 +1360,27 @@ func _Dgraph_Run_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dgraph_RunStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DgraphServer).RunStream(m, &dgraphRunStreamServer{stream})
+}
+
+type Dgraph_RunStreamServer interface {
+	Send(*Response) error
+	grpc.ServerStream
+}
+
+type dgraphRunStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *dgraphRunStreamServer) Send(m *Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Dgraph_CheckVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Check)
 	if err := dec(in); err != nil {
@@ -862,6 +1417,66 @@ func _Dgraph_AssignUids_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dgraph_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DgraphServer).Export(m, &dgraphExportServer{stream})
+}
+
+type Dgraph_ExportServer interface {
+	Send(*ExportChunk) error
+	grpc.ServerStream
+}
+
+type dgraphExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *dgraphExportServer) Send(m *ExportChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Dgraph_Members_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DgraphServer).Members(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Dgraph/Members",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DgraphServer).Members(ctx, req.(*MembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Dgraph_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DgraphServer).Subscribe(m, &dgraphSubscribeServer{stream})
+}
+
+type Dgraph_SubscribeServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type dgraphSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *dgraphSubscribeServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Dgraph_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Dgraph",
 	HandlerType: (*DgraphServer)(nil),
@@ -878,11 +1493,181 @@ var _Dgraph_serviceDesc = grpc.ServiceDesc{
 			MethodName: "AssignUids",
 			Handler:    _Dgraph_AssignUids_Handler,
 		},
+		{
+			MethodName: "Members",
+			Handler:    _Dgraph_Members_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunStream",
+			Handler:       _Dgraph_RunStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _Dgraph_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Dgraph_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "graphresponse.proto",
 }
 
+func (m *ExportRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Format) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Format)))
+		i += copy(dAtA[i:], m.Format)
+	}
+	if len(m.Include) > 0 {
+		for _, s := range m.Include {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Exclude) > 0 {
+		for _, s := range m.Exclude {
+			dAtA[i] = 0x1a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Offsets) > 0 {
+		for _, msg := range m.Offsets {
+			dAtA[i] = 0x22
+			i++
+			i = encodeVarintGraphresponse(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *ExportOffset) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportOffset) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.GroupId))
+	}
+	if len(m.After) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.After)))
+		i += copy(dAtA[i:], m.After)
+	}
+	if m.Done {
+		dAtA[i] = 0x18
+		i++
+		if m.Done {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *ExportChunk) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExportChunk) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.GroupId))
+	}
+	if len(m.Data) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
+	}
+	if len(m.Schema) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Schema)))
+		i += copy(dAtA[i:], m.Schema)
+	}
+	if m.Offset != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Offset.Size()))
+		n1, err := m.Offset.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	return i, nil
+}
+
 func (m *Num) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -971,11 +1756,11 @@ func (m *NQuad) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x22
 		i++
 		i = encodeVarintGraphresponse(dAtA, i, uint64(m.ObjectValue.Size()))
-		n1, err := m.ObjectValue.MarshalTo(dAtA[i:])
+		n2, err := m.ObjectValue.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n1
+		i += n2
 	}
 	if len(m.Label) > 0 {
 		dAtA[i] = 0x2a
@@ -1037,11 +1822,11 @@ func (m *Value) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.Val != nil {
-		nn2, err := m.Val.MarshalTo(dAtA[i:])
+		nn3, err := m.Val.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += nn2
+		i += nn3
 	}
 	return i, nil
 }
@@ -1222,21 +2007,21 @@ func (m *Request) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Mutation.Size()))
-		n3, err := m.Mutation.MarshalTo(dAtA[i:])
+		n4, err := m.Mutation.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n3
+		i += n4
 	}
 	if m.Schema != nil {
 		dAtA[i] = 0x1a
 		i++
 		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Schema.Size()))
-		n4, err := m.Schema.MarshalTo(dAtA[i:])
+		n5, err := m.Schema.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n4
+		i += n5
 	}
 	if len(m.Vars) > 0 {
 		for k, _ := range m.Vars {
@@ -1255,6 +2040,17 @@ func (m *Request) MarshalTo(dAtA []byte) (int, error) {
 			i += copy(dAtA[i:], v)
 		}
 	}
+	if len(m.QueryId) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.QueryId)))
+		i += copy(dAtA[i:], m.QueryId)
+	}
+	if m.ApiVersion != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.ApiVersion))
+	}
 	return i, nil
 }
 
@@ -1319,11 +2115,11 @@ func (m *Property) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Value.Size()))
-		n5, err := m.Value.MarshalTo(dAtA[i:])
+		n6, err := m.Value.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n5
+		i += n6
 	}
 	return i, nil
 }
@@ -1407,11 +2203,11 @@ func (m *Response) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintGraphresponse(dAtA, i, uint64(m.L.Size()))
-		n6, err := m.L.MarshalTo(dAtA[i:])
+		n7, err := m.L.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n6
+		i += n7
 	}
 	if len(m.AssignedUids) > 0 {
 		for k, _ := range m.AssignedUids {
@@ -1444,6 +2240,41 @@ func (m *Response) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Deprecation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Deprecation) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Feature) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Feature)))
+		i += copy(dAtA[i:], m.Feature)
+	}
+	if len(m.Message) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Message)))
+		i += copy(dAtA[i:], m.Message)
+	}
+	if m.RemovedIn != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.RemovedIn))
+	}
+	return i, nil
+}
+
 func (m *Check) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1459,6 +2290,11 @@ func (m *Check) MarshalTo(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ApiVersion != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.ApiVersion))
+	}
 	return i, nil
 }
 
@@ -1483,25 +2319,322 @@ func (m *Version) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Tag)))
 		i += copy(dAtA[i:], m.Tag)
 	}
+	if m.ApiVersion != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.ApiVersion))
+	}
+	if m.MinApiVersion != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.MinApiVersion))
+	}
+	if m.MaxApiVersion != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.MaxApiVersion))
+	}
+	if len(m.Capabilities) > 0 {
+		for _, s := range m.Capabilities {
+			dAtA[i] = 0x2a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
-func encodeFixed64Graphresponse(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	dAtA[offset+4] = uint8(v >> 32)
-	dAtA[offset+5] = uint8(v >> 40)
-	dAtA[offset+6] = uint8(v >> 48)
-	dAtA[offset+7] = uint8(v >> 56)
-	return offset + 8
+func (m *MembersRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
 }
-func encodeFixed32Graphresponse(dAtA []byte, offset int, v uint32) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
+
+func (m *MembersRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Predicates) > 0 {
+		for _, s := range m.Predicates {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *Member) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Member) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Id != 0 {
+		dAtA[i] = 0x9
+		i++
+		i = encodeFixed64Graphresponse(dAtA, i, uint64(m.Id))
+	}
+	if m.GroupId != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.GroupId))
+	}
+	if len(m.Addr) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Addr)))
+		i += copy(dAtA[i:], m.Addr)
+	}
+	if m.Leader {
+		dAtA[i] = 0x20
+		i++
+		if m.Leader {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *MemberList) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MemberList) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Members) > 0 {
+		for _, msg := range m.Members {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintGraphresponse(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Groups) > 0 {
+		dAtA2 := make([]byte, len(m.Groups)*10)
+		var j1 int
+		for _, num := range m.Groups {
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(j1))
+		i += copy(dAtA[i:], dAtA2[:j1])
+	}
+	return i, nil
+}
+
+func (m *SubscribeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Predicates) > 0 {
+		for _, s := range m.Predicates {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Filters) > 0 {
+		for _, msg := range m.Filters {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintGraphresponse(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Since != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Since))
+	}
+	return i, nil
+}
+
+func (m *ChangeFilter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChangeFilter) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Predicate) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Predicate)))
+		i += copy(dAtA[i:], m.Predicate)
+	}
+	if len(m.Op) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Op)))
+		i += copy(dAtA[i:], m.Op)
+	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	return i, nil
+}
+
+func (m *ChangeEvent) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChangeEvent) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.GroupId))
+	}
+	if m.Index != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.Index))
+	}
+	if m.CommitTs != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintGraphresponse(dAtA, i, uint64(m.CommitTs))
+	}
+	if len(m.Set) > 0 {
+		for _, msg := range m.Set {
+			dAtA[i] = 0x22
+			i++
+			i = encodeVarintGraphresponse(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Del) > 0 {
+		for _, msg := range m.Del {
+			dAtA[i] = 0x2a
+			i++
+			i = encodeVarintGraphresponse(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64Graphresponse(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Graphresponse(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
 	return offset + 4
 }
 func encodeVarintGraphresponse(dAtA []byte, offset int, v uint64) int {
@@ -1513,6 +2646,71 @@ func encodeVarintGraphresponse(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	return offset + 1
 }
+func (m *ExportRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Format)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if len(m.Include) > 0 {
+		for _, s := range m.Include {
+			l = len(s)
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if len(m.Exclude) > 0 {
+		for _, s := range m.Exclude {
+			l = len(s)
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if len(m.Offsets) > 0 {
+		for _, e := range m.Offsets {
+			l = e.Size()
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ExportOffset) Size() (n int) {
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		n += 1 + sovGraphresponse(uint64(m.GroupId))
+	}
+	l = len(m.After)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if m.Done {
+		n += 2
+	}
+	return n
+}
+
+func (m *ExportChunk) Size() (n int) {
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		n += 1 + sovGraphresponse(uint64(m.GroupId))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	l = len(m.Schema)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if m.Offset != nil {
+		l = m.Offset.Size()
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	return n
+}
+
 func (m *Num) Size() (n int) {
 	var l int
 	_ = l
@@ -1718,6 +2916,13 @@ func (m *Request) Size() (n int) {
 			n += mapEntrySize + 1 + sovGraphresponse(uint64(mapEntrySize))
 		}
 	}
+	l = len(m.QueryId)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if m.ApiVersion != 0 {
+		n += 1 + sovGraphresponse(uint64(m.ApiVersion))
+	}
 	return n
 }
 
@@ -1805,9 +3010,29 @@ func (m *Response) Size() (n int) {
 	return n
 }
 
+func (m *Deprecation) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Feature)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if m.RemovedIn != 0 {
+		n += 1 + sovGraphresponse(uint64(m.RemovedIn))
+	}
+	return n
+}
+
 func (m *Check) Size() (n int) {
 	var l int
 	_ = l
+	if m.ApiVersion != 0 {
+		n += 1 + sovGraphresponse(uint64(m.ApiVersion))
+	}
 	return n
 }
 
@@ -1818,6 +3043,137 @@ func (m *Version) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovGraphresponse(uint64(l))
 	}
+	if m.ApiVersion != 0 {
+		n += 1 + sovGraphresponse(uint64(m.ApiVersion))
+	}
+	if m.MinApiVersion != 0 {
+		n += 1 + sovGraphresponse(uint64(m.MinApiVersion))
+	}
+	if m.MaxApiVersion != 0 {
+		n += 1 + sovGraphresponse(uint64(m.MaxApiVersion))
+	}
+	if len(m.Capabilities) > 0 {
+		for _, s := range m.Capabilities {
+			l = len(s)
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *MembersRequest) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Predicates) > 0 {
+		for _, s := range m.Predicates {
+			l = len(s)
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Member) Size() (n int) {
+	var l int
+	_ = l
+	if m.Id != 0 {
+		n += 9
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovGraphresponse(uint64(m.GroupId))
+	}
+	l = len(m.Addr)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	if m.Leader {
+		n += 2
+	}
+	return n
+}
+
+func (m *MemberList) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Members) > 0 {
+		for _, e := range m.Members {
+			l = e.Size()
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if len(m.Groups) > 0 {
+		l = 0
+		for _, e := range m.Groups {
+			l += sovGraphresponse(uint64(e))
+		}
+		n += 1 + sovGraphresponse(uint64(l)) + l
+	}
+	return n
+}
+
+func (m *SubscribeRequest) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Predicates) > 0 {
+		for _, s := range m.Predicates {
+			l = len(s)
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if len(m.Filters) > 0 {
+		for _, e := range m.Filters {
+			l = e.Size()
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if m.Since != 0 {
+		n += 1 + sovGraphresponse(uint64(m.Since))
+	}
+	return n
+}
+
+func (m *ChangeFilter) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Predicate)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	l = len(m.Op)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovGraphresponse(uint64(l))
+	}
+	return n
+}
+
+func (m *ChangeEvent) Size() (n int) {
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		n += 1 + sovGraphresponse(uint64(m.GroupId))
+	}
+	if m.Index != 0 {
+		n += 1 + sovGraphresponse(uint64(m.Index))
+	}
+	if m.CommitTs != 0 {
+		n += 1 + sovGraphresponse(uint64(m.CommitTs))
+	}
+	if len(m.Set) > 0 {
+		for _, e := range m.Set {
+			l = e.Size()
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
+	if len(m.Del) > 0 {
+		for _, e := range m.Del {
+			l = e.Size()
+			n += 1 + l + sovGraphresponse(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1834,7 +3190,7 @@ func sovGraphresponse(x uint64) (n int) {
 func sozGraphresponse(x uint64) (n int) {
 	return sovGraphresponse(uint64((x << 1) ^ uint64((int64(x) >> 63))))
 }
-func (m *Num) Unmarshal(dAtA []byte) error {
+func (m *ExportRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1857,17 +3213,17 @@ func (m *Num) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Num: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Num: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Format", wireType)
 			}
-			m.Val = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -1877,11 +3233,110 @@ func (m *Num) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Val |= (uint64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Format = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Include", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Include = append(m.Include, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exclude", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Exclude = append(m.Exclude, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offsets", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Offsets = append(m.Offsets, &ExportOffset{})
+			if err := m.Offsets[len(m.Offsets)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -1903,7 +3358,7 @@ func (m *Num) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AssignedIds) Unmarshal(dAtA []byte) error {
+func (m *ExportOffset) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1926,17 +3381,17 @@ func (m *AssignedIds) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AssignedIds: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportOffset: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AssignedIds: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportOffset: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
 			}
-			m.StartId = 0
+			m.GroupId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -1946,16 +3401,47 @@ func (m *AssignedIds) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.StartId |= (uint64(b) & 0x7F) << shift
+				m.GroupId |= (uint32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field After", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.After = append(m.After[:0], dAtA[iNdEx:postIndex]...)
+			if m.After == nil {
+				m.After = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EndId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Done", wireType)
 			}
-			m.EndId = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -1965,11 +3451,12 @@ func (m *AssignedIds) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.EndId |= (uint64(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Done = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -1991,7 +3478,7 @@ func (m *AssignedIds) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *NQuad) Unmarshal(dAtA []byte) error {
+func (m *ExportChunk) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2014,17 +3501,17 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: NQuad: wiretype end group for non-group")
+			return fmt.Errorf("proto: ExportChunk: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: NQuad: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ExportChunk: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Subject", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
 			}
-			var stringLen uint64
+			m.GroupId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2034,26 +3521,16 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.GroupId |= (uint32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthGraphresponse
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Subject = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Predicate", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2063,26 +3540,28 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Predicate = string(dAtA[iNdEx:postIndex])
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ObjectId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Schema", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2092,24 +3571,26 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ObjectId = string(dAtA[iNdEx:postIndex])
+			m.Schema = append(m.Schema[:0], dAtA[iNdEx:postIndex]...)
+			if m.Schema == nil {
+				m.Schema = []byte{}
+			}
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ObjectValue", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2133,68 +3614,68 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.ObjectValue == nil {
-				m.ObjectValue = &Value{}
+			if m.Offset == nil {
+				m.Offset = &ExportOffset{}
 			}
-			if err := m.ObjectValue.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Offset.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 5:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Label", wireType)
-			}
-			var stringLen uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGraphresponse
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Label = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 6:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ObjectType", wireType)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Num) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
 			}
-			var v int32
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGraphresponse
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				v |= (int32(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
-			v = int32((uint32(v) >> 1) ^ uint32(((v&1)<<31)>>31))
-			m.ObjectType = v
-		case 7:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Lang", wireType)
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-			var stringLen uint64
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Num: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Num: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Val", wireType)
+			}
+			m.Val = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2204,57 +3685,66 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Val |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Lang = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 8:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Facets", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGraphresponse
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthGraphresponse
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AssignedIds) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
 			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
+			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Facets = append(m.Facets, &Facet{})
-			if err := m.Facets[len(m.Facets)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-			iNdEx = postIndex
-		case 9:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SubjectVar", wireType)
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AssignedIds: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AssignedIds: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartId", wireType)
 			}
-			var stringLen uint64
+			m.StartId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2264,26 +3754,16 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.StartId |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthGraphresponse
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.SubjectVar = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 10:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ObjectVar", wireType)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndId", wireType)
 			}
-			var stringLen uint64
+			m.EndId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2293,21 +3773,11 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.EndId |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthGraphresponse
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.ObjectVar = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -2329,7 +3799,7 @@ func (m *NQuad) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Value) Unmarshal(dAtA []byte) error {
+func (m *NQuad) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2352,15 +3822,15 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Value: wiretype end group for non-group")
+			return fmt.Errorf("proto: NQuad: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Value: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: NQuad: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DefaultVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Subject", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2385,13 +3855,13 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Val = &Value_DefaultVal{string(dAtA[iNdEx:postIndex])}
+			m.Subject = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BytesVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Predicate", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2401,27 +3871,26 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			v := make([]byte, postIndex-iNdEx)
-			copy(v, dAtA[iNdEx:postIndex])
-			m.Val = &Value_BytesVal{v}
+			m.Predicate = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IntVal", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectId", wireType)
 			}
-			var v int64
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2431,17 +3900,26 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= (int64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Val = &Value_IntVal{v}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ObjectId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field BoolVal", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectValue", wireType)
 			}
-			var v int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2451,16 +3929,28 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			b := bool(v != 0)
-			m.Val = &Value_BoolVal{b}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ObjectValue == nil {
+				m.ObjectValue = &Value{}
+			}
+			if err := m.ObjectValue.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StrVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Label", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2485,31 +3975,13 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Val = &Value_StrVal{string(dAtA[iNdEx:postIndex])}
+			m.Label = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 6:
-			if wireType != 1 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DoubleVal", wireType)
-			}
-			var v uint64
-			if (iNdEx + 8) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += 8
-			v = uint64(dAtA[iNdEx-8])
-			v |= uint64(dAtA[iNdEx-7]) << 8
-			v |= uint64(dAtA[iNdEx-6]) << 16
-			v |= uint64(dAtA[iNdEx-5]) << 24
-			v |= uint64(dAtA[iNdEx-4]) << 32
-			v |= uint64(dAtA[iNdEx-3]) << 40
-			v |= uint64(dAtA[iNdEx-2]) << 48
-			v |= uint64(dAtA[iNdEx-1]) << 56
-			m.Val = &Value_DoubleVal{float64(math.Float64frombits(v))}
-		case 7:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GeoVal", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectType", wireType)
 			}
-			var byteLen int
+			var v int32
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2519,27 +3991,18 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				v |= (int32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthGraphresponse
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			v := make([]byte, postIndex-iNdEx)
-			copy(v, dAtA[iNdEx:postIndex])
-			m.Val = &Value_GeoVal{v}
-			iNdEx = postIndex
-		case 8:
+			v = int32((uint32(v) >> 1) ^ uint32(((v&1)<<31)>>31))
+			m.ObjectType = v
+		case 7:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DateVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Lang", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2549,27 +4012,26 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			v := make([]byte, postIndex-iNdEx)
-			copy(v, dAtA[iNdEx:postIndex])
-			m.Val = &Value_DateVal{v}
+			m.Lang = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 9:
+		case 8:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DatetimeVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Facets", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2579,25 +4041,26 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= (int(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			v := make([]byte, postIndex-iNdEx)
-			copy(v, dAtA[iNdEx:postIndex])
-			m.Val = &Value_DatetimeVal{v}
+			m.Facets = append(m.Facets, &Facet{})
+			if err := m.Facets[len(m.Facets)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 10:
+		case 9:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PasswordVal", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SubjectVar", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2622,13 +4085,13 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Val = &Value_PasswordVal{string(dAtA[iNdEx:postIndex])}
+			m.SubjectVar = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 11:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field UidVal", wireType)
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObjectVar", wireType)
 			}
-			var v uint64
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2638,12 +4101,21 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= (uint64(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.Val = &Value_UidVal{v}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ObjectVar = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -2665,7 +4137,7 @@ func (m *Value) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Mutation) Unmarshal(dAtA []byte) error {
+func (m *Value) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2688,17 +4160,17 @@ func (m *Mutation) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Mutation: wiretype end group for non-group")
+			return fmt.Errorf("proto: Value: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Mutation: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Value: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Set", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field DefaultVal", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2708,28 +4180,26 @@ func (m *Mutation) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Set = append(m.Set, &NQuad{})
-			if err := m.Set[len(m.Set)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Val = &Value_DefaultVal{string(dAtA[iNdEx:postIndex])}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Del", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field BytesVal", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2739,28 +4209,68 @@ func (m *Mutation) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Del = append(m.Del, &NQuad{})
-			if err := m.Del[len(m.Del)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Val = &Value_BytesVal{v}
 			iNdEx = postIndex
 		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IntVal", wireType)
+			}
+			var v int64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Val = &Value_IntVal{v}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BoolVal", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			b := bool(v != 0)
+			m.Val = &Value_BoolVal{b}
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Schema", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field StrVal", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2770,78 +4280,44 @@ func (m *Mutation) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Schema = append(m.Schema, &SchemaUpdate{})
-			if err := m.Schema[len(m.Schema)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Val = &Value_StrVal{string(dAtA[iNdEx:postIndex])}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipGraphresponse(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthGraphresponse
+		case 6:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DoubleVal", wireType)
 			}
-			if (iNdEx + skippy) > l {
+			var v uint64
+			if (iNdEx + 8) > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Request) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowGraphresponse
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= (uint64(b) & 0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: Request: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Request: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			iNdEx += 8
+			v = uint64(dAtA[iNdEx-8])
+			v |= uint64(dAtA[iNdEx-7]) << 8
+			v |= uint64(dAtA[iNdEx-6]) << 16
+			v |= uint64(dAtA[iNdEx-5]) << 24
+			v |= uint64(dAtA[iNdEx-4]) << 32
+			v |= uint64(dAtA[iNdEx-3]) << 40
+			v |= uint64(dAtA[iNdEx-2]) << 48
+			v |= uint64(dAtA[iNdEx-1]) << 56
+			m.Val = &Value_DoubleVal{float64(math.Float64frombits(v))}
+		case 7:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GeoVal", wireType)
 			}
-			var stringLen uint64
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2851,26 +4327,27 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Query = string(dAtA[iNdEx:postIndex])
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Val = &Value_GeoVal{v}
 			iNdEx = postIndex
-		case 2:
+		case 8:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Mutation", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field DateVal", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2880,30 +4357,27 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Mutation == nil {
-				m.Mutation = &Mutation{}
-			}
-			if err := m.Mutation.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Val = &Value_DateVal{v}
 			iNdEx = postIndex
-		case 3:
+		case 9:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Schema", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field DatetimeVal", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2913,30 +4387,27 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				byteLen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Schema == nil {
-				m.Schema = &SchemaRequest{}
-			}
-			if err := m.Schema.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			v := make([]byte, postIndex-iNdEx)
+			copy(v, dAtA[iNdEx:postIndex])
+			m.Val = &Value_DatetimeVal{v}
 			iNdEx = postIndex
-		case 4:
+		case 10:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Vars", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field PasswordVal", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2946,34 +4417,26 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var keykey uint64
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGraphresponse
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				keykey |= (uint64(b) & 0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			m.Val = &Value_PasswordVal{string(dAtA[iNdEx:postIndex])}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UidVal", wireType)
 			}
-			var stringLenmapkey uint64
+			var v uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -2983,71 +4446,12 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLenmapkey |= (uint64(b) & 0x7F) << shift
+				v |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLenmapkey := int(stringLenmapkey)
-			if intStringLenmapkey < 0 {
-				return ErrInvalidLengthGraphresponse
-			}
-			postStringIndexmapkey := iNdEx + intStringLenmapkey
-			if postStringIndexmapkey > l {
-				return io.ErrUnexpectedEOF
-			}
-			mapkey := string(dAtA[iNdEx:postStringIndexmapkey])
-			iNdEx = postStringIndexmapkey
-			if m.Vars == nil {
-				m.Vars = make(map[string]string)
-			}
-			if iNdEx < postIndex {
-				var valuekey uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowGraphresponse
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					valuekey |= (uint64(b) & 0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				var stringLenmapvalue uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowGraphresponse
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					stringLenmapvalue |= (uint64(b) & 0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				intStringLenmapvalue := int(stringLenmapvalue)
-				if intStringLenmapvalue < 0 {
-					return ErrInvalidLengthGraphresponse
-				}
-				postStringIndexmapvalue := iNdEx + intStringLenmapvalue
-				if postStringIndexmapvalue > l {
-					return io.ErrUnexpectedEOF
-				}
-				mapvalue := string(dAtA[iNdEx:postStringIndexmapvalue])
-				iNdEx = postStringIndexmapvalue
-				m.Vars[mapkey] = mapvalue
-			} else {
-				var mapvalue string
-				m.Vars[mapkey] = mapvalue
-			}
-			iNdEx = postIndex
+			m.Val = &Value_UidVal{v}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -3069,7 +4473,7 @@ func (m *Request) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Latency) Unmarshal(dAtA []byte) error {
+func (m *Mutation) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3092,17 +4496,17 @@ func (m *Latency) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Latency: wiretype end group for non-group")
+			return fmt.Errorf("proto: Mutation: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Latency: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Mutation: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Parsing", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Set", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -3112,26 +4516,28 @@ func (m *Latency) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Parsing = string(dAtA[iNdEx:postIndex])
+			m.Set = append(m.Set, &NQuad{})
+			if err := m.Set[len(m.Set)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Processing", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Del", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -3141,26 +4547,28 @@ func (m *Latency) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Processing = string(dAtA[iNdEx:postIndex])
+			m.Del = append(m.Del, &NQuad{})
+			if err := m.Del[len(m.Del)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Pb", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Schema", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -3170,20 +4578,22 @@ func (m *Latency) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				msglen |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Pb = string(dAtA[iNdEx:postIndex])
+			m.Schema = append(m.Schema, &SchemaUpdate{})
+			if err := m.Schema[len(m.Schema)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3206,7 +4616,7 @@ func (m *Latency) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Property) Unmarshal(dAtA []byte) error {
+func (m *Request) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3229,15 +4639,15 @@ func (m *Property) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Property: wiretype end group for non-group")
+			return fmt.Errorf("proto: Request: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Property: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Request: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Prop", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3262,11 +4672,11 @@ func (m *Property) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Prop = string(dAtA[iNdEx:postIndex])
+			m.Query = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Mutation", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -3290,13 +4700,210 @@ func (m *Property) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Value == nil {
-				m.Value = &Value{}
+			if m.Mutation == nil {
+				m.Mutation = &Mutation{}
 			}
-			if err := m.Value.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Mutation.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Schema", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Schema == nil {
+				m.Schema = &SchemaRequest{}
+			}
+			if err := m.Schema.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Vars", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var keykey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				keykey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			var stringLenmapkey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLenmapkey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLenmapkey := int(stringLenmapkey)
+			if intStringLenmapkey < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postStringIndexmapkey := iNdEx + intStringLenmapkey
+			if postStringIndexmapkey > l {
+				return io.ErrUnexpectedEOF
+			}
+			mapkey := string(dAtA[iNdEx:postStringIndexmapkey])
+			iNdEx = postStringIndexmapkey
+			if m.Vars == nil {
+				m.Vars = make(map[string]string)
+			}
+			if iNdEx < postIndex {
+				var valuekey uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowGraphresponse
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					valuekey |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				var stringLenmapvalue uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowGraphresponse
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				intStringLenmapvalue := int(stringLenmapvalue)
+				if intStringLenmapvalue < 0 {
+					return ErrInvalidLengthGraphresponse
+				}
+				postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+				if postStringIndexmapvalue > l {
+					return io.ErrUnexpectedEOF
+				}
+				mapvalue := string(dAtA[iNdEx:postStringIndexmapvalue])
+				iNdEx = postStringIndexmapvalue
+				m.Vars[mapkey] = mapvalue
+			} else {
+				var mapvalue string
+				m.Vars[mapkey] = mapvalue
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QueryId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.QueryId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ApiVersion", wireType)
+			}
+			m.ApiVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ApiVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -3318,7 +4925,7 @@ func (m *Property) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Node) Unmarshal(dAtA []byte) error {
+func (m *Latency) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3341,15 +4948,15 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Node: wiretype end group for non-group")
+			return fmt.Errorf("proto: Latency: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Node: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Latency: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Attribute", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Parsing", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3374,13 +4981,13 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Attribute = string(dAtA[iNdEx:postIndex])
+			m.Parsing = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Properties", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Processing", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -3390,28 +4997,26 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Properties = append(m.Properties, &Property{})
-			if err := m.Properties[len(m.Properties)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Processing = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Children", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Pb", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGraphresponse
@@ -3421,22 +5026,20 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				stringLen |= (uint64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGraphresponse
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Children = append(m.Children, &Node{})
-			if err := m.Children[len(m.Children)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Pb = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3459,7 +5062,7 @@ func (m *Node) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Response) Unmarshal(dAtA []byte) error {
+func (m *Property) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3482,7 +5085,260 @@ func (m *Response) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Response: wiretype end group for non-group")
+			return fmt.Errorf("proto: Property: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Property: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prop", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Prop = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Value == nil {
+				m.Value = &Value{}
+			}
+			if err := m.Value.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Node) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Node: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Node: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attribute", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Attribute = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Properties", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Properties = append(m.Properties, &Property{})
+			if err := m.Properties[len(m.Properties)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Children", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Children = append(m.Children, &Node{})
+			if err := m.Children[len(m.Children)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Response) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Response: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
 			return fmt.Errorf("proto: Response: illegal tag %d (wire type %d)", fieldNum, wire)
@@ -3710,7 +5566,7 @@ func (m *Response) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Check) Unmarshal(dAtA []byte) error {
+func (m *Deprecation) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3733,12 +5589,158 @@ func (m *Check) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Check: wiretype end group for non-group")
+			return fmt.Errorf("proto: Deprecation: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Check: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Deprecation: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Feature", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Feature = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemovedIn", wireType)
+			}
+			m.RemovedIn = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RemovedIn |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Check) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Check: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Check: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ApiVersion", wireType)
+			}
+			m.ApiVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ApiVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -3818,6 +5820,884 @@ func (m *Version) Unmarshal(dAtA []byte) error {
 			}
 			m.Tag = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ApiVersion", wireType)
+			}
+			m.ApiVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ApiVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinApiVersion", wireType)
+			}
+			m.MinApiVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinApiVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxApiVersion", wireType)
+			}
+			m.MaxApiVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxApiVersion |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capabilities", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Capabilities = append(m.Capabilities, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MembersRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MembersRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MembersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Predicates", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Predicates = append(m.Predicates, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Member) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Member: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Member: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			m.Id = 0
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += 8
+			m.Id = uint64(dAtA[iNdEx-8])
+			m.Id |= uint64(dAtA[iNdEx-7]) << 8
+			m.Id |= uint64(dAtA[iNdEx-6]) << 16
+			m.Id |= uint64(dAtA[iNdEx-5]) << 24
+			m.Id |= uint64(dAtA[iNdEx-4]) << 32
+			m.Id |= uint64(dAtA[iNdEx-3]) << 40
+			m.Id |= uint64(dAtA[iNdEx-2]) << 48
+			m.Id |= uint64(dAtA[iNdEx-1]) << 56
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Leader", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Leader = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MemberList) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MemberList: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MemberList: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Members", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Members = append(m.Members, &Member{})
+			if err := m.Members[len(m.Members)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowGraphresponse
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= (int(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthGraphresponse
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				for iNdEx < postIndex {
+					var v uint32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowGraphresponse
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= (uint32(b) & 0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Groups = append(m.Groups, v)
+				}
+			} else if wireType == 0 {
+				var v uint32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowGraphresponse
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= (uint32(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Groups = append(m.Groups, v)
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Groups", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SubscribeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubscribeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubscribeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Predicates", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Predicates = append(m.Predicates, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Filters", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Filters = append(m.Filters, &ChangeFilter{})
+			if err := m.Filters[len(m.Filters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Since", wireType)
+			}
+			m.Since = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Since |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ChangeFilter) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChangeFilter: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChangeFilter: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Predicate", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Predicate = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Op = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGraphresponse(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ChangeEvent) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGraphresponse
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChangeEvent: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChangeEvent: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommitTs", wireType)
+			}
+			m.CommitTs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CommitTs |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Set", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Set = append(m.Set, &NQuad{})
+			if err := m.Set[len(m.Set)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Del", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGraphresponse
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGraphresponse
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Del = append(m.Del, &NQuad{})
+			if err := m.Del[len(m.Del)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGraphresponse(dAtA[iNdEx:])
@@ -3947,64 +6827,97 @@ var (
 func init() { proto.RegisterFile("graphresponse.proto", fileDescriptorGraphresponse) }
 
 var fileDescriptorGraphresponse = []byte{
-	// 937 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x55, 0xdd, 0x8e, 0xdc, 0x34,
-	0x14, 0x9e, 0xcc, 0x5f, 0x32, 0x27, 0x03, 0xdd, 0xba, 0x45, 0xa4, 0xb3, 0xec, 0xec, 0x92, 0x0a,
-	0x69, 0x55, 0xb5, 0xab, 0x6a, 0xb9, 0x00, 0x21, 0x21, 0x44, 0x0b, 0xd5, 0xac, 0x04, 0x2b, 0x70,
-	0xe9, 0xdc, 0x56, 0xce, 0xd8, 0x9d, 0x0d, 0xcd, 0x24, 0xa9, 0xed, 0x2c, 0x1a, 0xae, 0x78, 0x8c,
-	0xbe, 0x0d, 0xb7, 0x5c, 0xf2, 0x08, 0xb0, 0xf0, 0x14, 0x5c, 0x21, 0x1f, 0xdb, 0xd9, 0x4c, 0x8b,
-	0xc4, 0x55, 0x7c, 0xbe, 0xef, 0x3b, 0x3f, 0x3e, 0x3e, 0x76, 0xe0, 0xd6, 0x5a, 0xb2, 0xfa, 0x42,
-	0x0a, 0x55, 0x57, 0xa5, 0x12, 0x27, 0xb5, 0xac, 0x74, 0x45, 0xc6, 0xf8, 0x51, 0xb3, 0xe9, 0x0b,
-	0xb6, 0x12, 0x5a, 0x59, 0x74, 0x36, 0x55, 0xab, 0x0b, 0xb1, 0x61, 0xd6, 0x4a, 0xdf, 0x87, 0xc1,
-	0x79, 0xb3, 0x21, 0x7b, 0x30, 0xb8, 0x64, 0x45, 0x12, 0x1c, 0x05, 0xc7, 0x43, 0x6a, 0x96, 0xe9,
-	0xe7, 0x10, 0x7f, 0xa9, 0x54, 0xbe, 0x2e, 0x05, 0x3f, 0xe3, 0x8a, 0x24, 0x10, 0x2a, 0xcd, 0xa4,
-	0x3e, 0xe3, 0x4e, 0xe4, 0x4d, 0x72, 0x1b, 0x46, 0xa2, 0xe4, 0x67, 0x3c, 0xe9, 0x23, 0x6e, 0x8d,
-	0xf4, 0xd7, 0x3e, 0x8c, 0xce, 0xbf, 0x6f, 0x18, 0x47, 0xcf, 0x26, 0xfb, 0x51, 0xac, 0x34, 0x7a,
-	0x4e, 0xa8, 0x37, 0xc9, 0x07, 0x30, 0xa9, 0xa5, 0xe0, 0xf9, 0x8a, 0x69, 0x81, 0xde, 0x13, 0x7a,
-	0x0d, 0x90, 0x7d, 0x98, 0x54, 0xa8, 0x7b, 0x9e, 0xf3, 0x64, 0x80, 0x6c, 0x64, 0x81, 0x33, 0x4e,
-	0x1e, 0xc2, 0xd4, 0x91, 0x97, 0xac, 0x68, 0x44, 0x32, 0x3c, 0x0a, 0x8e, 0xe3, 0xd3, 0x77, 0xec,
-	0xa6, 0xd4, 0xc9, 0xd2, 0x80, 0x34, 0xb6, 0x12, 0x34, 0x4c, 0x99, 0x05, 0xcb, 0x44, 0x91, 0x8c,
-	0x30, 0x94, 0x35, 0xc8, 0x1c, 0xc0, 0x8a, 0x7e, 0xd8, 0xd6, 0x22, 0x19, 0x1f, 0x05, 0xc7, 0x37,
-	0x69, 0x07, 0x21, 0x04, 0x86, 0x05, 0x2b, 0xd7, 0x49, 0x88, 0x4e, 0xb8, 0x26, 0x1f, 0xc1, 0xd8,
-	0x36, 0x34, 0x89, 0x8e, 0x06, 0xdd, 0xac, 0x4f, 0x0c, 0x4a, 0x1d, 0x49, 0x0e, 0x21, 0x76, 0x1b,
-	0x7d, 0x7e, 0xc9, 0x64, 0x32, 0xc1, 0x08, 0xe0, 0xa0, 0x25, 0x93, 0xe4, 0xc0, 0xe7, 0x46, 0x1e,
-	0xec, 0xfe, 0x7d, 0xc9, 0x32, 0xfd, 0xb3, 0x0f, 0x23, 0x5b, 0xfa, 0x87, 0x10, 0x73, 0xf1, 0x82,
-	0x35, 0x05, 0xee, 0xd6, 0x76, 0x71, 0xd1, 0xa3, 0xe0, 0xc0, 0x25, 0x2b, 0xc8, 0x01, 0x4c, 0xb2,
-	0xad, 0x16, 0x0a, 0x05, 0xa6, 0x95, 0xd3, 0x45, 0x8f, 0x46, 0x08, 0x19, 0xfa, 0x0e, 0x84, 0x79,
-	0x69, 0xbd, 0x4d, 0x27, 0x07, 0x8b, 0x1e, 0x1d, 0xe7, 0x25, 0x7a, 0xee, 0x43, 0x94, 0x55, 0x55,
-	0x81, 0x9c, 0xe9, 0x62, 0xb4, 0xe8, 0xd1, 0xd0, 0x20, 0xce, 0x4f, 0x69, 0x89, 0xdc, 0xc8, 0x65,
-	0x1d, 0x2b, 0x2d, 0x0d, 0x75, 0x08, 0xc0, 0xab, 0x26, 0x2b, 0x04, 0xb2, 0xa6, 0x73, 0xc1, 0xa2,
-	0x47, 0x27, 0x16, 0x73, 0xbe, 0x6b, 0x51, 0x21, 0x1b, 0xba, 0x82, 0xc6, 0x6b, 0x51, 0xb9, 0x9c,
-	0x9c, 0x69, 0xeb, 0x19, 0x39, 0x2e, 0x34, 0x88, 0x21, 0xef, 0xc2, 0xd4, 0x2c, 0x75, 0xbe, 0xb1,
-	0x82, 0x89, 0x13, 0xc4, 0x1e, 0x75, 0xa2, 0x9a, 0x29, 0xf5, 0x53, 0x25, 0x39, 0x8a, 0xc0, 0x55,
-	0x17, 0x7b, 0xd4, 0x55, 0xd0, 0xe4, 0x96, 0x8f, 0xcd, 0x6c, 0x9a, 0x0a, 0x9a, 0xdc, 0x50, 0x8f,
-	0x46, 0x38, 0xef, 0xe9, 0xcf, 0x10, 0x7d, 0xdb, 0x68, 0xa6, 0xf3, 0xaa, 0x24, 0x87, 0x30, 0x50,
-	0xc2, 0xcc, 0xe8, 0xce, 0x99, 0xe2, 0x0c, 0x53, 0xc3, 0x18, 0x01, 0x17, 0xa6, 0xbb, 0xff, 0x25,
-	0xe0, 0xa2, 0x20, 0xf7, 0x61, 0x6c, 0xef, 0x56, 0x32, 0x40, 0xcd, 0x6d, 0xaf, 0x79, 0x8a, 0xe8,
-	0xb3, 0xda, 0xec, 0x80, 0x3a, 0x4d, 0xfa, 0x77, 0x00, 0x21, 0x15, 0xaf, 0x1a, 0xa1, 0xb4, 0x19,
-	0xce, 0x57, 0x8d, 0x90, 0x5b, 0x77, 0x43, 0xac, 0x41, 0xee, 0x43, 0xb4, 0x71, 0xd5, 0xe1, 0x99,
-	0xc6, 0xa7, 0x7b, 0x3e, 0xa2, 0xaf, 0x9a, 0xb6, 0x0a, 0xf2, 0xa0, 0x93, 0xdd, 0x68, 0xdf, 0xdb,
-	0xcd, 0xee, 0x52, 0xf9, 0xf4, 0xe4, 0x01, 0x0c, 0x2f, 0x99, 0x54, 0xc9, 0x10, 0x4b, 0xbd, 0xe3,
-	0xc5, 0x4e, 0x76, 0xb2, 0x64, 0x52, 0x7d, 0x5d, 0x6a, 0xb9, 0xa5, 0x28, 0x9b, 0x7d, 0x02, 0x93,
-	0x16, 0x32, 0xaf, 0xc5, 0x4b, 0xe1, 0x8b, 0x35, 0x4b, 0xb3, 0x01, 0x7b, 0x11, 0xed, 0x35, 0xb6,
-	0xc6, 0x67, 0xfd, 0x4f, 0x83, 0xf4, 0x29, 0x84, 0xdf, 0x30, 0x2d, 0xca, 0xd5, 0xd6, 0xbc, 0x04,
-	0x35, 0x93, 0x2a, 0x2f, 0xd7, 0xfe, 0x25, 0x70, 0xa6, 0xb9, 0x86, 0xb5, 0xac, 0x56, 0x42, 0x21,
-	0x69, 0x63, 0x74, 0x10, 0xf2, 0x2e, 0xf4, 0xeb, 0xcc, 0x3d, 0x02, 0xfd, 0x3a, 0x4b, 0x1f, 0x43,
-	0xf4, 0x9d, 0xac, 0x6a, 0x21, 0xf5, 0xd6, 0x5c, 0xd1, 0x5a, 0x56, 0xb5, 0x0b, 0x89, 0x6b, 0x72,
-	0xb7, 0x5b, 0xce, 0x5b, 0xef, 0x82, 0xe5, 0xd2, 0x5f, 0x02, 0x18, 0x9e, 0x57, 0x5c, 0x98, 0x77,
-	0x88, 0x69, 0x2d, 0xf3, 0xac, 0xd1, 0xc2, 0x85, 0xb9, 0x06, 0xc8, 0x43, 0xac, 0xcd, 0xe4, 0xca,
-	0x85, 0x72, 0xa7, 0xdf, 0x9e, 0x83, 0xaf, 0x82, 0x76, 0x34, 0xe4, 0x18, 0xa2, 0xd5, 0x45, 0x5e,
-	0x70, 0x29, 0x4a, 0x37, 0x09, 0xd3, 0x76, 0x5a, 0x2a, 0x2e, 0x68, 0xcb, 0xa6, 0xff, 0x04, 0x10,
-	0x51, 0xf7, 0x68, 0x93, 0x19, 0x04, 0xa5, 0x1b, 0xbf, 0x5d, 0x7d, 0x50, 0x92, 0x03, 0x08, 0x0a,
-	0xb7, 0x99, 0x1b, 0x9e, 0x73, 0x6d, 0xa5, 0x41, 0x41, 0x9e, 0xc0, 0xd4, 0x3f, 0xd6, 0xcf, 0x72,
-	0xae, 0x5c, 0xd6, 0xf4, 0xfa, 0x50, 0xdd, 0x7f, 0xa1, 0x2b, 0xb2, 0xa7, 0xbb, 0xe3, 0x47, 0xee,
-	0xb5, 0x33, 0x64, 0xc7, 0x82, 0xec, 0xce, 0x10, 0x56, 0xe3, 0x14, 0xb3, 0x2f, 0xe0, 0xe6, 0x5b,
-	0xe1, 0xfe, 0x6f, 0x32, 0x86, 0xdd, 0xc9, 0x08, 0x61, 0xf4, 0xf8, 0x42, 0xac, 0x5e, 0xa6, 0xfb,
-	0x10, 0x2e, 0x85, 0x54, 0x66, 0x88, 0xf7, 0x60, 0xa0, 0x99, 0x1f, 0x0f, 0xb3, 0x3c, 0x7d, 0x1d,
-	0xc0, 0xf8, 0x2b, 0xfc, 0xbb, 0x91, 0x7b, 0x30, 0xa0, 0x4d, 0x49, 0x6e, 0xbc, 0x31, 0xab, 0xb3,
-	0xbd, 0x37, 0xf7, 0x99, 0xf6, 0xcc, 0x0f, 0x02, 0x83, 0xfb, 0xc0, 0xed, 0x08, 0x20, 0x3a, 0x6b,
-	0x63, 0x38, 0x1e, 0x3d, 0xc0, 0xee, 0x07, 0x3b, 0x11, 0xb7, 0x27, 0xd0, 0x6c, 0x66, 0xb7, 0xbc,
-	0xd1, 0xf9, 0x23, 0xa6, 0xbd, 0x47, 0x7b, 0xbf, 0x5d, 0xcd, 0x83, 0xdf, 0xaf, 0xe6, 0xc1, 0x1f,
-	0x57, 0xf3, 0xe0, 0xf5, 0x5f, 0xf3, 0x5e, 0x66, 0xff, 0xb8, 0x1f, 0xff, 0x1b, 0x00, 0x00, 0xff,
-	0xff, 0x2c, 0xfc, 0xe7, 0x56, 0x8f, 0x07, 0x00, 0x00,
+	// 1472 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8d, 0x57, 0xcd, 0x6e, 0x1c, 0x45,
+	0x10, 0xf6, 0xec, 0xff, 0xd6, 0xee, 0xc6, 0x4e, 0x27, 0x81, 0xcd, 0x06, 0x92, 0x30, 0x08, 0x64,
+	0x21, 0x12, 0x45, 0xe6, 0x90, 0x08, 0x09, 0x50, 0xe2, 0x24, 0xc2, 0x52, 0x62, 0xa0, 0x9d, 0xe4,
+	0x6a, 0x7a, 0x67, 0x7a, 0xed, 0x21, 0xb3, 0x33, 0xc3, 0xfc, 0x18, 0x9b, 0x13, 0xe2, 0xca, 0x0b,
+	0x70, 0x41, 0xe2, 0x11, 0x78, 0x03, 0xae, 0x1c, 0x79, 0x04, 0x7e, 0xde, 0x82, 0x0b, 0x54, 0x55,
+	0x77, 0xaf, 0x77, 0xd6, 0x0a, 0xc9, 0x61, 0xe5, 0xae, 0xfa, 0xaa, 0xab, 0xaa, 0x7b, 0xbe, 0xaa,
+	0x2e, 0xc3, 0x85, 0x83, 0x5c, 0x65, 0x87, 0xb9, 0x2e, 0xb2, 0x34, 0x29, 0xf4, 0xcd, 0x2c, 0x4f,
+	0xcb, 0x54, 0x74, 0xf8, 0x4f, 0x31, 0x19, 0xce, 0x54, 0xa0, 0xcb, 0xc2, 0x68, 0x27, 0xc3, 0x22,
+	0x38, 0xd4, 0x73, 0x65, 0x24, 0xff, 0x07, 0x0f, 0x46, 0x0f, 0x8e, 0xb3, 0x34, 0x2f, 0xa5, 0xfe,
+	0xba, 0xd2, 0x45, 0x29, 0x5e, 0x83, 0xce, 0x2c, 0xcd, 0xe7, 0xaa, 0x1c, 0x7b, 0xd7, 0xbd, 0xcd,
+	0xbe, 0xb4, 0x92, 0x18, 0x43, 0x37, 0x4a, 0x82, 0xb8, 0x0a, 0xf5, 0xb8, 0x71, 0xbd, 0x89, 0x80,
+	0x13, 0x09, 0xd1, 0xc7, 0x06, 0x69, 0x1a, 0xc4, 0x8a, 0xe2, 0x26, 0x74, 0xd3, 0xd9, 0xac, 0xc0,
+	0xe0, 0xe3, 0x16, 0x22, 0x83, 0xad, 0x8b, 0x26, 0x6c, 0x71, 0xd3, 0xc4, 0xfc, 0x8c, 0x41, 0xe9,
+	0x8c, 0xfc, 0x3d, 0x18, 0x2e, 0x03, 0xe2, 0x32, 0xf4, 0x0e, 0xf2, 0xb4, 0xca, 0xf6, 0xa3, 0x90,
+	0xb3, 0x19, 0xc9, 0x2e, 0xcb, 0x3b, 0xa1, 0xb8, 0x08, 0x6d, 0x35, 0x2b, 0x75, 0x8e, 0xc9, 0x78,
+	0x9b, 0x43, 0x69, 0x04, 0x21, 0xa0, 0x15, 0xa6, 0x09, 0xe5, 0xe1, 0x6d, 0xf6, 0x24, 0xaf, 0xfd,
+	0xef, 0x3d, 0x18, 0x18, 0xaf, 0xdb, 0x87, 0x55, 0xf2, 0xfc, 0xff, 0x9c, 0xd2, 0x76, 0x55, 0x2a,
+	0xeb, 0x93, 0xd7, 0x74, 0x1f, 0xe6, 0xc6, 0xd8, 0xe9, 0x50, 0x5a, 0x49, 0xbc, 0x0f, 0x1d, 0x93,
+	0x36, 0x1e, 0xcd, 0x7b, 0xe1, 0xd1, 0xac, 0x8d, 0xff, 0x3a, 0x34, 0x77, 0xab, 0xb9, 0xd8, 0x80,
+	0xe6, 0x91, 0x8a, 0x39, 0x6c, 0x4b, 0xd2, 0xd2, 0xff, 0x08, 0x06, 0x77, 0x8b, 0x22, 0x3a, 0x48,
+	0x74, 0xb8, 0x13, 0x16, 0x74, 0x97, 0x45, 0xa9, 0xf2, 0x72, 0x27, 0xb4, 0x46, 0x4e, 0xa4, 0x03,
+	0xeb, 0x04, 0x6d, 0x38, 0xb9, 0x96, 0x34, 0x82, 0xff, 0x6b, 0x03, 0xda, 0xbb, 0x5f, 0x54, 0x2a,
+	0xe4, 0x9d, 0xd5, 0xf4, 0x2b, 0x1d, 0xb8, 0x0f, 0xe7, 0x44, 0xf1, 0x06, 0xf4, 0xb3, 0x5c, 0x87,
+	0x51, 0xa0, 0x4a, 0xcd, 0xbb, 0xfb, 0xf2, 0x54, 0x21, 0xae, 0x40, 0x3f, 0x65, 0x3b, 0xba, 0x8f,
+	0x26, 0xa3, 0x3d, 0xa3, 0xc0, 0xa0, 0xb7, 0x60, 0x68, 0x41, 0xcc, 0xb5, 0xd2, 0xf6, 0xa8, 0x23,
+	0x77, 0xd4, 0x67, 0xa4, 0x94, 0x03, 0x63, 0xc2, 0x02, 0xa5, 0x19, 0xab, 0xa9, 0x8e, 0xc7, 0x6d,
+	0x76, 0x65, 0x04, 0x71, 0x15, 0xc0, 0x18, 0x3d, 0x39, 0xc9, 0xf4, 0xb8, 0x83, 0xd0, 0x79, 0xb9,
+	0xa4, 0xa1, 0x8b, 0x8f, 0x55, 0x72, 0x30, 0xee, 0xf2, 0x26, 0x5e, 0x8b, 0x77, 0x90, 0x88, 0x4c,
+	0xdc, 0x71, 0x8f, 0xb9, 0xb3, 0x88, 0xfa, 0x90, 0xb4, 0xd2, 0x82, 0xe2, 0x1a, 0x0c, 0xec, 0x41,
+	0x31, 0xc7, 0x7c, 0xdc, 0x67, 0x0f, 0x60, 0x55, 0xcf, 0x54, 0x2e, 0xde, 0x74, 0xb1, 0x19, 0x07,
+	0x73, 0x7e, 0x97, 0x72, 0xee, 0xff, 0x89, 0x37, 0x68, 0x52, 0x7f, 0x0b, 0x06, 0xa1, 0x9e, 0xa9,
+	0x2a, 0xe6, 0xd3, 0x9a, 0x5b, 0xfc, 0x74, 0x4d, 0x82, 0x55, 0xa2, 0x11, 0xfa, 0xea, 0x4f, 0x4f,
+	0x4a, 0x5d, 0xb0, 0x01, 0xb3, 0x04, 0x0d, 0x7a, 0xac, 0x22, 0xf8, 0x32, 0xd5, 0x88, 0xd9, 0x4d,
+	0x37, 0xd9, 0x44, 0xb0, 0x83, 0x0a, 0x82, 0xae, 0x40, 0x6f, 0x9a, 0xa6, 0x31, 0x63, 0x74, 0x8b,
+	0x3d, 0xc4, 0xba, 0xa4, 0xb1, 0xfb, 0x8a, 0x32, 0x67, 0xac, 0x6d, 0xa3, 0x76, 0x50, 0x41, 0xd0,
+	0x35, 0x80, 0x30, 0xad, 0xa6, 0xb1, 0x66, 0x94, 0x6e, 0xce, 0x43, 0xb4, 0x6f, 0x74, 0x76, 0xef,
+	0x81, 0x4e, 0x19, 0xed, 0xda, 0x84, 0x3a, 0xa8, 0xb0, 0x31, 0x91, 0xc2, 0x66, 0x67, 0xcf, 0x62,
+	0x5d, 0xd2, 0x10, 0xf8, 0x36, 0x0c, 0x69, 0x59, 0x46, 0x73, 0x63, 0xd0, 0xb7, 0x06, 0x03, 0xa7,
+	0xb5, 0x46, 0x99, 0x2a, 0x8a, 0x6f, 0xd2, 0x3c, 0x64, 0x23, 0xb0, 0xd9, 0x0d, 0x9c, 0xd6, 0x66,
+	0x50, 0x45, 0x06, 0x1f, 0x10, 0x37, 0x29, 0x03, 0x54, 0x20, 0x74, 0xaf, 0xcd, 0x7c, 0xf7, 0xbf,
+	0x85, 0xde, 0xe3, 0xaa, 0x54, 0x65, 0x94, 0x26, 0x78, 0xa0, 0x26, 0x15, 0x8d, 0x57, 0xff, 0xa6,
+	0xcc, 0x61, 0x49, 0x08, 0x19, 0x84, 0x3a, 0xe6, 0x26, 0x73, 0xd6, 0x00, 0x11, 0xaa, 0xbc, 0x45,
+	0x45, 0xd6, 0x9a, 0xca, 0x1e, 0x6b, 0x9f, 0x66, 0x74, 0x02, 0x57, 0xa7, 0xfe, 0xcf, 0x0d, 0xe8,
+	0xba, 0xde, 0x86, 0xe4, 0xc4, 0x45, 0x7e, 0x62, 0x2b, 0xc4, 0x08, 0xe8, 0xaf, 0x37, 0xb7, 0xd9,
+	0xf1, 0x37, 0x1d, 0x6c, 0x6d, 0x38, 0x8f, 0x2e, 0x6b, 0xb9, 0xb0, 0x10, 0x37, 0x6a, 0xfd, 0x60,
+	0xb0, 0x75, 0xa9, 0x1e, 0xdd, 0x86, 0x5a, 0xb4, 0x89, 0x1b, 0xd0, 0x42, 0xda, 0xb9, 0xfe, 0x77,
+	0xd9, 0x19, 0x5b, 0x33, 0xac, 0xa0, 0xbc, 0x78, 0x90, 0x94, 0xf9, 0x89, 0x64, 0x33, 0x6a, 0x4e,
+	0x9c, 0x14, 0x15, 0xa3, 0xa9, 0xa0, 0x2e, 0xcb, 0x58, 0x8b, 0x48, 0x74, 0x95, 0x45, 0xfb, 0x47,
+	0x3a, 0x2f, 0x28, 0xd3, 0x0e, 0xb7, 0x2e, 0x40, 0xd5, 0x33, 0xa3, 0x99, 0xdc, 0x86, 0xfe, 0xc2,
+	0x1d, 0x75, 0x9a, 0xe7, 0xda, 0x1d, 0x94, 0x96, 0x74, 0x78, 0x53, 0xc4, 0xa6, 0x05, 0x18, 0xe1,
+	0xc3, 0xc6, 0x1d, 0x0f, 0xdb, 0x6e, 0xf7, 0x11, 0x5e, 0x59, 0x12, 0x9c, 0x50, 0x17, 0xc9, 0xd0,
+	0x47, 0x84, 0xb5, 0x68, 0xbb, 0x88, 0x15, 0xa9, 0x84, 0x31, 0xf7, 0x40, 0x17, 0x0c, 0x1a, 0x1f,
+	0x4b, 0x1a, 0x71, 0x0e, 0x1a, 0xd9, 0xd4, 0x36, 0x10, 0x5c, 0xf9, 0xdb, 0xd0, 0xfb, 0x3c, 0x4f,
+	0x33, 0x9d, 0x97, 0x27, 0x54, 0xde, 0x68, 0x99, 0x59, 0x97, 0xbc, 0x46, 0x6a, 0x2d, 0xa5, 0x73,
+	0xa6, 0xa7, 0x18, 0xcc, 0xff, 0xce, 0x83, 0xd6, 0x6e, 0x8a, 0x2f, 0x09, 0xf6, 0x30, 0x55, 0x96,
+	0x79, 0x34, 0xad, 0xb0, 0x87, 0x19, 0x37, 0xa7, 0x0a, 0x6c, 0x53, 0x94, 0x09, 0xc5, 0x8a, 0x74,
+	0x61, 0x99, 0xb3, 0xf8, 0x86, 0x2e, 0x0b, 0xb9, 0x64, 0x23, 0x36, 0xa1, 0x17, 0x1c, 0x46, 0x71,
+	0x98, 0xeb, 0xc4, 0xb2, 0x68, 0xb8, 0x60, 0x1a, 0xc6, 0x93, 0x0b, 0xd4, 0xff, 0xc7, 0x83, 0x9e,
+	0xb4, 0x0f, 0xab, 0x98, 0x80, 0x97, 0x58, 0xea, 0xd6, 0xed, 0xbd, 0x04, 0x7b, 0x83, 0x17, 0xdb,
+	0xc3, 0xac, 0x3b, 0xcc, 0x5e, 0xab, 0xf4, 0x62, 0xf1, 0x10, 0x86, 0xae, 0xd1, 0x3f, 0x8d, 0xc2,
+	0xc2, 0x46, 0xf5, 0x4f, 0x09, 0x61, 0xdf, 0xee, 0x65, 0x23, 0xc3, 0x8c, 0xda, 0x3e, 0xf1, 0xde,
+	0x82, 0x7f, 0x86, 0x52, 0xa2, 0xce, 0x3f, 0xce, 0xc6, 0x5a, 0x4c, 0x3e, 0x81, 0xf3, 0x67, 0xdc,
+	0xbd, 0x8c, 0x19, 0xad, 0x65, 0x66, 0x7c, 0x09, 0x83, 0xfb, 0x1a, 0xdf, 0x8a, 0xc0, 0x70, 0x1f,
+	0xd9, 0x31, 0xd3, 0xaa, 0xac, 0x72, 0xf7, 0x0d, 0x9c, 0x48, 0xc8, 0x1c, 0x89, 0xa0, 0x0e, 0x1c,
+	0xbd, 0x9c, 0x48, 0xed, 0x37, 0xd7, 0xf3, 0xf4, 0x48, 0x87, 0xfb, 0x51, 0xc2, 0xfc, 0x18, 0xc9,
+	0xbe, 0xd5, 0xec, 0x24, 0xfe, 0x26, 0xb4, 0xb7, 0x0f, 0x75, 0xf0, 0x7c, 0x95, 0xde, 0xde, 0x2a,
+	0xbd, 0xfd, 0x5f, 0x3c, 0xe8, 0xda, 0x35, 0x9d, 0xa1, 0x54, 0x8e, 0xa2, 0xb4, 0x5c, 0xdd, 0xde,
+	0x58, 0xdd, 0x2e, 0xde, 0x85, 0xf5, 0x79, 0x94, 0xec, 0x2f, 0x1b, 0x99, 0x64, 0x46, 0xa8, 0xbe,
+	0x5b, 0xb7, 0x53, 0xc7, 0x35, 0xbb, 0x96, 0xb5, 0x53, 0xc7, 0x4b, 0x76, 0x3e, 0x0c, 0x03, 0x95,
+	0xa9, 0x69, 0x14, 0x47, 0xcc, 0xba, 0x36, 0x8f, 0x3e, 0x35, 0x9d, 0x7f, 0x0b, 0xce, 0x3d, 0xd6,
+	0xf3, 0x29, 0xee, 0x70, 0x1d, 0x88, 0xab, 0xc8, 0x3e, 0xbd, 0x05, 0x33, 0x89, 0xab, 0xc8, 0x69,
+	0xfc, 0x7d, 0xe8, 0x98, 0x1d, 0x54, 0x4f, 0x76, 0x40, 0xe9, 0x48, 0x5c, 0xd5, 0xc6, 0x96, 0xc6,
+	0x99, 0xb1, 0x45, 0x85, 0x61, 0x6e, 0x8b, 0x8f, 0xd7, 0x34, 0xb6, 0xc4, 0x5a, 0x85, 0x38, 0x20,
+	0xf1, 0x6b, 0x23, 0xad, 0xe4, 0xef, 0x02, 0x98, 0x00, 0x8f, 0x22, 0x4c, 0x67, 0x93, 0x3e, 0x1b,
+	0x27, 0x68, 0x59, 0x7d, 0x6e, 0xd1, 0xf9, 0x58, 0x2d, 0x1d, 0x4c, 0xfe, 0x38, 0x9c, 0x29, 0xaf,
+	0x91, 0xb4, 0x92, 0x7f, 0x0c, 0x1b, 0x7b, 0xd5, 0xb4, 0x08, 0xb0, 0x12, 0xf5, 0x2b, 0x1e, 0x92,
+	0xc6, 0xc2, 0x59, 0x14, 0x97, 0x14, 0xb5, 0x51, 0xef, 0xe0, 0xdb, 0x87, 0xf8, 0xf2, 0xeb, 0x87,
+	0x0c, 0x4a, 0x67, 0x44, 0xfc, 0xc4, 0x16, 0x13, 0x98, 0xb1, 0xae, 0x29, 0x8d, 0xe0, 0x4b, 0x18,
+	0x2e, 0x9b, 0xd7, 0xc7, 0x1c, 0x6f, 0x75, 0xcc, 0xc1, 0xeb, 0xc4, 0x06, 0x64, 0xb8, 0x89, 0xab,
+	0x53, 0xce, 0x37, 0x97, 0xba, 0xa1, 0xff, 0x13, 0xce, 0x8a, 0xc6, 0xe9, 0x83, 0x23, 0x9d, 0xbc,
+	0x6c, 0x00, 0x8d, 0x92, 0x50, 0x1f, 0xbb, 0xa2, 0x61, 0x81, 0xa6, 0xa9, 0x20, 0x9d, 0xcf, 0xa3,
+	0x72, 0xbf, 0x2c, 0x6c, 0xba, 0x3d, 0xa3, 0x78, 0x52, 0xb8, 0xa7, 0xaf, 0xf5, 0xb2, 0xa7, 0xaf,
+	0xfd, 0xa2, 0xa7, 0x6f, 0xeb, 0xdf, 0x06, 0x74, 0xee, 0xf3, 0xac, 0x8f, 0x7d, 0xa0, 0x29, 0xab,
+	0x44, 0xac, 0xaf, 0xbc, 0x28, 0x93, 0x8d, 0xd5, 0x8e, 0xe2, 0xaf, 0x89, 0x2d, 0xe8, 0xa3, 0xed,
+	0x5e, 0x99, 0x6b, 0x35, 0x7f, 0xa5, 0x1d, 0xb7, 0x3c, 0x1a, 0xfd, 0xb8, 0x30, 0x1d, 0xdf, 0x47,
+	0xa7, 0xdf, 0x08, 0xb5, 0x93, 0x85, 0x17, 0x57, 0x9e, 0x6b, 0xd4, 0x85, 0x4d, 0xb7, 0xe1, 0x3e,
+	0x35, 0x58, 0xa4, 0x5f, 0xcd, 0x27, 0x17, 0x9c, 0xb0, 0x34, 0xeb, 0xe2, 0x8e, 0x3b, 0xd0, 0x31,
+	0xd3, 0xb2, 0xb8, 0x54, 0x9f, 0x9e, 0x5d, 0x6a, 0x17, 0xea, 0x6a, 0x1e, 0xe0, 0x39, 0xbb, 0xdb,
+	0xd0, 0x7d, 0xec, 0x98, 0x59, 0xa7, 0xac, 0x2b, 0xb5, 0x89, 0xa8, 0xeb, 0x89, 0xef, 0x18, 0xf2,
+	0x63, 0xe8, 0x2f, 0xf8, 0x2a, 0xc6, 0x8b, 0xde, 0xb9, 0x42, 0xe1, 0xd3, 0xc0, 0x4b, 0x6c, 0xa0,
+	0xc0, 0xf7, 0x36, 0x7e, 0xfb, 0xeb, 0xaa, 0xf7, 0x3b, 0xfe, 0xfe, 0xc0, 0xdf, 0x8f, 0x7f, 0x5f,
+	0x5d, 0x9b, 0x9a, 0x7f, 0xb3, 0x3e, 0xf8, 0x0f, 0xa9, 0xcc, 0x23, 0xd1, 0x84, 0x0d, 0x00, 0x00,
 }
@@ -69,9 +69,21 @@ func (m *Payload) GetData() []byte {
 // When used in request, groups represents the list of groups that need to be backed up.
 // When used in response, groups represent the list of groups that were backed up.
 type ExportPayload struct {
-	ReqId   uint64               `protobuf:"varint,1,opt,name=req_id,json=reqId,proto3" json:"req_id,omitempty"`
-	GroupId uint32               `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
-	Status  ExportPayload_Status `protobuf:"varint,3,opt,name=status,proto3,enum=protos.ExportPayload_Status" json:"status,omitempty"`
+	ReqId      uint64               `protobuf:"varint,1,opt,name=req_id,json=reqId,proto3" json:"req_id,omitempty"`
+	GroupId    uint32               `protobuf:"varint,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Status     ExportPayload_Status `protobuf:"varint,3,opt,name=status,proto3,enum=protos.ExportPayload_Status" json:"status,omitempty"`
+	Backup     bool                 `protobuf:"varint,4,opt,name=backup,proto3" json:"backup,omitempty"`
+	FullBackup bool                 `protobuf:"varint,5,opt,name=full_backup,json=fullBackup,proto3" json:"full_backup,omitempty"`
+	Format     string               `protobuf:"bytes,6,opt,name=format,proto3" json:"format,omitempty"`
+	// Only export predicates matching one of include, if any, and none of exclude.
+	Include []string `protobuf:"bytes,7,rep,name=include" json:"include,omitempty"`
+	Exclude []string `protobuf:"bytes,8,rep,name=exclude" json:"exclude,omitempty"`
+	// If set, only export the edges between, and the values of these nodes.
+	Uids *List `protobuf:"bytes,9,opt,name=uids" json:"uids,omitempty"`
+	// If set, only export the predicates of this namespace, under their names in it.
+	Namespace string `protobuf:"bytes,10,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Also write the values of geo predicates as a GeoJSON FeatureCollection.
+	GeoJson bool `protobuf:"varint,11,opt,name=geo_json,json=geoJson,proto3" json:"geo_json,omitempty"`
 }
 
 func (m *ExportPayload) Reset()                    { *m = ExportPayload{} }
@@ -100,6 +112,62 @@ func (m *ExportPayload) GetStatus() ExportPayload_Status {
 	return ExportPayload_NONE
 }
 
+func (m *ExportPayload) GetBackup() bool {
+	if m != nil {
+		return m.Backup
+	}
+	return false
+}
+
+func (m *ExportPayload) GetFullBackup() bool {
+	if m != nil {
+		return m.FullBackup
+	}
+	return false
+}
+
+func (m *ExportPayload) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ExportPayload) GetInclude() []string {
+	if m != nil {
+		return m.Include
+	}
+	return nil
+}
+
+func (m *ExportPayload) GetExclude() []string {
+	if m != nil {
+		return m.Exclude
+	}
+	return nil
+}
+
+func (m *ExportPayload) GetUids() *List {
+	if m != nil {
+		return m.Uids
+	}
+	return nil
+}
+
+func (m *ExportPayload) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *ExportPayload) GetGeoJson() bool {
+	if m != nil {
+		return m.GeoJson
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*Payload)(nil), "protos.Payload")
 	proto.RegisterType((*ExportPayload)(nil), "protos.ExportPayload")
@@ -131,6 +199,7 @@ type WorkerClient interface {
 	JoinCluster(ctx context.Context, in *RaftContext, opts ...grpc.CallOption) (*Payload, error)
 	UpdateMembership(ctx context.Context, in *MembershipUpdate, opts ...grpc.CallOption) (*MembershipUpdate, error)
 	Export(ctx context.Context, in *ExportPayload, opts ...grpc.CallOption) (*ExportPayload, error)
+	StreamExport(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Worker_StreamExportClient, error)
 }
 
 type workerClient struct {
@@ -262,6 +331,38 @@ func (c *workerClient) Export(ctx context.Context, in *ExportPayload, opts ...gr
 	return out, nil
 }
 
+func (c *workerClient) StreamExport(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Worker_StreamExportClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Worker_serviceDesc.Streams[1], c.cc, "/protos.Worker/StreamExport", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &workerStreamExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Worker_StreamExportClient interface {
+	Recv() (*ExportChunk, error)
+	grpc.ClientStream
+}
+
+type workerStreamExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *workerStreamExportClient) Recv() (*ExportChunk, error) {
+	m := new(ExportChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Worker service
 
 type WorkerServer interface {
@@ -279,6 +380,7 @@ type WorkerServer interface {
 	JoinCluster(context.Context, *RaftContext) (*Payload, error)
 	UpdateMembership(context.Context, *MembershipUpdate) (*MembershipUpdate, error)
 	Export(context.Context, *ExportPayload) (*ExportPayload, error)
+	StreamExport(*ExportRequest, Worker_StreamExportServer) error
 }
 
 func RegisterWorkerServer(s *grpc.Server, srv WorkerServer) {
@@ -491,6 +593,27 @@ func _Worker_Export_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Worker_StreamExport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WorkerServer).StreamExport(m, &workerStreamExportServer{stream})
+}
+
+type Worker_StreamExportServer interface {
+	Send(*ExportChunk) error
+	grpc.ServerStream
+}
+
+type workerStreamExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *workerStreamExportServer) Send(m *ExportChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Worker_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Worker",
 	HandlerType: (*WorkerServer)(nil),
@@ -543,6 +666,11 @@ var _Worker_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "StreamExport",
+			Handler:       _Worker_StreamExport_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "payload.proto",
 }
@@ -601,6 +729,88 @@ func (m *ExportPayload) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintPayload(dAtA, i, uint64(m.Status))
 	}
+	if m.Backup {
+		dAtA[i] = 0x20
+		i++
+		if m.Backup {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.FullBackup {
+		dAtA[i] = 0x28
+		i++
+		if m.FullBackup {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Format) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintPayload(dAtA, i, uint64(len(m.Format)))
+		i += copy(dAtA[i:], m.Format)
+	}
+	if len(m.Include) > 0 {
+		for _, s := range m.Include {
+			dAtA[i] = 0x3a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Exclude) > 0 {
+		for _, s := range m.Exclude {
+			dAtA[i] = 0x42
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.Uids != nil {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintPayload(dAtA, i, uint64(m.Uids.Size()))
+		n1, err := m.Uids.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if len(m.Namespace) > 0 {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintPayload(dAtA, i, uint64(len(m.Namespace)))
+		i += copy(dAtA[i:], m.Namespace)
+	}
+	if m.GeoJson {
+		dAtA[i] = 0x58
+		i++
+		if m.GeoJson {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -653,6 +863,39 @@ func (m *ExportPayload) Size() (n int) {
 	if m.Status != 0 {
 		n += 1 + sovPayload(uint64(m.Status))
 	}
+	if m.Backup {
+		n += 2
+	}
+	if m.FullBackup {
+		n += 2
+	}
+	l = len(m.Format)
+	if l > 0 {
+		n += 1 + l + sovPayload(uint64(l))
+	}
+	if len(m.Include) > 0 {
+		for _, s := range m.Include {
+			l = len(s)
+			n += 1 + l + sovPayload(uint64(l))
+		}
+	}
+	if len(m.Exclude) > 0 {
+		for _, s := range m.Exclude {
+			l = len(s)
+			n += 1 + l + sovPayload(uint64(l))
+		}
+	}
+	if m.Uids != nil {
+		l = m.Uids.Size()
+		n += 1 + l + sovPayload(uint64(l))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovPayload(uint64(l))
+	}
+	if m.GeoJson {
+		n += 2
+	}
 	return n
 }
 
@@ -836,6 +1079,215 @@ func (m *ExportPayload) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Backup", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Backup = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FullBackup", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FullBackup = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Format", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPayload
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Format = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Include", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPayload
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Include = append(m.Include, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exclude", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPayload
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Exclude = append(m.Exclude, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uids", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPayload
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Uids == nil {
+				m.Uids = &List{}
+			}
+			if err := m.Uids.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPayload
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GeoJson", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPayload
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.GeoJson = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPayload(dAtA[iNdEx:])
@@ -965,38 +1417,46 @@ var (
 func init() { proto.RegisterFile("payload.proto", fileDescriptorPayload) }
 
 var fileDescriptorPayload = []byte{
-	// 523 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x53, 0x5d, 0x6e, 0xd3, 0x40,
-	0x10, 0xb6, 0x5b, 0xe3, 0xb6, 0x93, 0xa6, 0x98, 0x29, 0x45, 0xc5, 0x82, 0x28, 0xf2, 0x53, 0x84,
-	0x50, 0xd4, 0x16, 0x10, 0x08, 0x89, 0x87, 0x90, 0x18, 0x08, 0x6d, 0x42, 0xb0, 0x1b, 0x78, 0x44,
-	0xdb, 0x78, 0x48, 0xac, 0x24, 0x5e, 0x67, 0x77, 0x8d, 0x9a, 0x9b, 0x70, 0x0f, 0x2e, 0xc1, 0x23,
-	0x17, 0x40, 0x42, 0xe1, 0x22, 0x28, 0x76, 0x9c, 0xd0, 0x2a, 0x48, 0x3c, 0x79, 0xbf, 0x9f, 0x9d,
-	0x6f, 0x3d, 0x3b, 0x0b, 0xc5, 0x98, 0x4d, 0x47, 0x9c, 0x05, 0xd5, 0x58, 0x70, 0xc5, 0xd1, 0x4c,
-	0x3f, 0xd2, 0xde, 0xef, 0x0b, 0x16, 0x0f, 0x04, 0xc9, 0x98, 0x47, 0x92, 0x32, 0xd1, 0xde, 0x95,
-	0xbd, 0x01, 0x8d, 0xd9, 0x02, 0x81, 0x62, 0x72, 0x98, 0xad, 0x9d, 0xfb, 0xb0, 0xd5, 0xc9, 0xea,
-	0x20, 0x82, 0xd1, 0x60, 0x8a, 0x1d, 0xea, 0x65, 0xbd, 0xb2, 0xeb, 0xa5, 0x6b, 0xe7, 0x9b, 0x0e,
-	0x45, 0xf7, 0x32, 0xe6, 0x42, 0xe5, 0xae, 0x03, 0x30, 0x05, 0x4d, 0x3e, 0x85, 0x41, 0xea, 0x33,
-	0xbc, 0x1b, 0x82, 0x26, 0xcd, 0x00, 0xef, 0xc2, 0x76, 0x5f, 0xf0, 0x24, 0x9e, 0x0b, 0x1b, 0x65,
-	0xbd, 0x52, 0xf4, 0xb6, 0x52, 0xdc, 0x0c, 0xf0, 0x31, 0x98, 0x52, 0x31, 0x95, 0xc8, 0xc3, 0xcd,
-	0xb2, 0x5e, 0xd9, 0x3b, 0xb9, 0x97, 0x45, 0xcb, 0xea, 0x95, 0xc2, 0x55, 0x3f, 0xf5, 0x78, 0x0b,
-	0xaf, 0xf3, 0x1c, 0xcc, 0x8c, 0xc1, 0x6d, 0x30, 0xda, 0xef, 0xda, 0xae, 0xa5, 0x61, 0x01, 0xb6,
-	0xfc, 0x6e, 0xbd, 0xee, 0xfa, 0xbe, 0xa5, 0x63, 0x11, 0x76, 0x1a, 0xdd, 0xce, 0x59, 0xb3, 0x5e,
-	0x3b, 0x77, 0xad, 0x0d, 0x04, 0x30, 0x5f, 0xd5, 0x9a, 0x67, 0x6e, 0xc3, 0xda, 0x3c, 0xf9, 0x69,
-	0x80, 0xf9, 0x91, 0x8b, 0x21, 0x09, 0x7c, 0x00, 0x86, 0xdb, 0x1b, 0x70, 0xbc, 0x99, 0x87, 0x2e,
-	0xe2, 0xec, 0xeb, 0x84, 0xa3, 0xe1, 0x11, 0x40, 0x4d, 0xca, 0xb0, 0x1f, 0x75, 0xc3, 0x40, 0x62,
-	0x21, 0x37, 0xb4, 0x93, 0xb1, 0xbd, 0x9f, 0x83, 0xcc, 0x40, 0x41, 0x33, 0x90, 0x8e, 0x86, 0x55,
-	0x30, 0x5b, 0x89, 0x62, 0x8a, 0xf0, 0x56, 0x6e, 0x48, 0x71, 0xc8, 0x23, 0xb9, 0x2e, 0xe1, 0x21,
-	0xec, 0xf8, 0x24, 0xbe, 0xd0, 0x39, 0x93, 0x43, 0x2c, 0xe6, 0xfa, 0xfb, 0x84, 0xc4, 0xd4, 0xde,
-	0xcb, 0xa1, 0x47, 0x32, 0x19, 0x29, 0x47, 0xc3, 0x17, 0x70, 0xa7, 0x23, 0x28, 0x08, 0x7b, 0x4c,
-	0x51, 0x2d, 0x0a, 0xfc, 0xf4, 0x0e, 0xe7, 0xd7, 0xb2, 0x4a, 0x7b, 0x3d, 0xef, 0xf1, 0x29, 0x4d,
-	0xa5, 0x0d, 0x39, 0x75, 0xfa, 0xc1, 0xd1, 0x2a, 0xfa, 0x91, 0x8e, 0xc7, 0x60, 0xf8, 0x5c, 0x28,
-	0x5c, 0x9e, 0x7d, 0x8e, 0x5a, 0x24, 0x25, 0xeb, 0x93, 0x8d, 0x7f, 0x93, 0xcb, 0xc4, 0xa7, 0x60,
-	0x66, 0x29, 0x78, 0xb0, 0xd4, 0x53, 0xec, 0xd1, 0x24, 0x21, 0xa9, 0xec, 0xdb, 0xd7, 0xe9, 0xc5,
-	0xc6, 0x63, 0x28, 0x78, 0xec, 0x73, 0x5e, 0xfd, 0xbf, 0xba, 0xfd, 0x04, 0x0a, 0x6f, 0x79, 0x18,
-	0xd5, 0x47, 0x89, 0x54, 0x24, 0x56, 0xa7, 0x9c, 0xd7, 0xa9, 0xf3, 0x48, 0xd1, 0xa5, 0x5a, 0xb7,
-	0xed, 0x0d, 0x58, 0xdd, 0x38, 0x60, 0x8a, 0x5a, 0x34, 0xbe, 0x20, 0x21, 0x07, 0x61, 0x8c, 0x87,
-	0xcb, 0xe6, 0x2f, 0xb9, 0xcc, 0x63, 0xff, 0x53, 0x71, 0x34, 0x7c, 0x06, 0x66, 0x36, 0x81, 0xab,
-	0x9f, 0xbd, 0x32, 0x91, 0xf6, 0x7a, 0xda, 0xd1, 0x5e, 0x5a, 0xdf, 0x67, 0x25, 0xfd, 0xc7, 0xac,
-	0xa4, 0xff, 0x9a, 0x95, 0xf4, 0xaf, 0xbf, 0x4b, 0xda, 0x45, 0xf6, 0xfa, 0x1e, 0xfd, 0x09, 0x00,
-	0x00, 0xff, 0xff, 0x2f, 0x06, 0xbb, 0x58, 0x95, 0x03, 0x00, 0x00,
+	// 646 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xdd, 0x6e, 0xd3, 0x30,
+	0x14, 0x8e, 0xb7, 0x2c, 0x6d, 0x4f, 0xdb, 0x51, 0x3c, 0x36, 0x85, 0x68, 0x94, 0x28, 0x57, 0x11,
+	0x42, 0xd5, 0x36, 0x40, 0x20, 0x24, 0x90, 0xba, 0xae, 0x40, 0xd9, 0x0f, 0x23, 0x59, 0xe1, 0x72,
+	0xf2, 0x9a, 0xb3, 0x36, 0x6a, 0x9b, 0x64, 0xb6, 0x83, 0xb6, 0x37, 0xe1, 0x86, 0xf7, 0xe1, 0x92,
+	0x47, 0x40, 0xe3, 0x25, 0xb8, 0x44, 0xf9, 0x6b, 0xd9, 0x54, 0x24, 0xae, 0xe2, 0xef, 0xc7, 0xe7,
+	0x3b, 0x3e, 0xb2, 0x03, 0xf5, 0x88, 0x5d, 0x4d, 0x42, 0xe6, 0xb5, 0x22, 0x1e, 0xca, 0x90, 0x6a,
+	0xe9, 0x47, 0x18, 0x6b, 0x43, 0xce, 0xa2, 0x11, 0x47, 0x11, 0x85, 0x81, 0xc0, 0x4c, 0x34, 0x6a,
+	0x62, 0x30, 0xc2, 0x29, 0xcb, 0x11, 0x48, 0x26, 0xc6, 0xd9, 0xda, 0x7a, 0x00, 0xa5, 0xe3, 0xac,
+	0x0e, 0xa5, 0xa0, 0xee, 0x31, 0xc9, 0x74, 0x62, 0x12, 0xbb, 0xe6, 0xa4, 0x6b, 0xeb, 0xf7, 0x12,
+	0xd4, 0xbb, 0x97, 0x51, 0xc8, 0x65, 0xe1, 0x5a, 0x07, 0x8d, 0xe3, 0xc5, 0xa9, 0xef, 0xa5, 0x3e,
+	0xd5, 0x59, 0xe1, 0x78, 0xd1, 0xf3, 0xe8, 0x7d, 0x28, 0x0f, 0x79, 0x18, 0x47, 0x89, 0xb0, 0x64,
+	0x12, 0xbb, 0xee, 0x94, 0x52, 0xdc, 0xf3, 0xe8, 0x53, 0xd0, 0x84, 0x64, 0x32, 0x16, 0xfa, 0xb2,
+	0x49, 0xec, 0xd5, 0x9d, 0xcd, 0x2c, 0x5a, 0xb4, 0x6e, 0x14, 0x6e, 0xb9, 0xa9, 0xc7, 0xc9, 0xbd,
+	0x74, 0x03, 0xb4, 0x33, 0x36, 0x18, 0xc7, 0x91, 0xae, 0x9a, 0xc4, 0x2e, 0x3b, 0x39, 0xa2, 0x0f,
+	0xa1, 0x7a, 0x1e, 0x4f, 0x26, 0xa7, 0xb9, 0xb8, 0x92, 0x8a, 0x90, 0x50, 0xbb, 0x99, 0x61, 0x03,
+	0xb4, 0xf3, 0x90, 0x4f, 0x99, 0xd4, 0x35, 0x93, 0xd8, 0x15, 0x27, 0x47, 0x54, 0x87, 0x92, 0x1f,
+	0x0c, 0x26, 0xb1, 0x87, 0x7a, 0xc9, 0x5c, 0xb6, 0x2b, 0x4e, 0x01, 0x13, 0x05, 0x2f, 0x33, 0xa5,
+	0x9c, 0x29, 0x39, 0xa4, 0x26, 0xa8, 0xb1, 0xef, 0x09, 0xbd, 0x62, 0x12, 0xbb, 0xba, 0x53, 0x2b,
+	0x1a, 0x3f, 0xf0, 0x85, 0x74, 0x52, 0x85, 0x6e, 0x42, 0x25, 0x60, 0x53, 0x14, 0x11, 0x1b, 0xa0,
+	0x0e, 0x69, 0xe0, 0x9c, 0xb0, 0x5e, 0x82, 0x96, 0x1d, 0x8b, 0x96, 0x41, 0x3d, 0xfa, 0x70, 0xd4,
+	0x6d, 0x28, 0xb4, 0x0a, 0x25, 0xb7, 0xdf, 0xe9, 0x74, 0x5d, 0xb7, 0x41, 0x68, 0x1d, 0x2a, 0x7b,
+	0xfd, 0xe3, 0x83, 0x5e, 0xa7, 0x7d, 0xd2, 0x6d, 0x2c, 0x51, 0x00, 0xed, 0x4d, 0xbb, 0x77, 0xd0,
+	0xdd, 0x6b, 0x2c, 0xef, 0x7c, 0x5b, 0x01, 0xed, 0x73, 0xc8, 0xc7, 0xc8, 0xe9, 0x23, 0x50, 0xbb,
+	0x83, 0x51, 0x48, 0xef, 0x14, 0x0d, 0xe4, 0x33, 0x33, 0x6e, 0x13, 0x96, 0x42, 0xb7, 0x00, 0xda,
+	0x42, 0xf8, 0xc3, 0xa0, 0x9f, 0xb4, 0x57, 0x2d, 0x0c, 0x47, 0xf1, 0xd4, 0x58, 0x2b, 0x40, 0x66,
+	0x40, 0xaf, 0xe7, 0x09, 0x4b, 0xa1, 0x2d, 0xd0, 0x0e, 0x63, 0xc9, 0x24, 0xd2, 0xbb, 0x85, 0x21,
+	0xc5, 0x7e, 0x18, 0x88, 0x45, 0x09, 0x8f, 0xa1, 0xe2, 0x22, 0xff, 0x82, 0x27, 0x4c, 0x8c, 0x69,
+	0xbd, 0xd0, 0x3f, 0xc6, 0xc8, 0xaf, 0x8c, 0xd5, 0x02, 0x3a, 0x28, 0xe2, 0x89, 0xb4, 0x14, 0xfa,
+	0x0a, 0x36, 0x8e, 0x39, 0x7a, 0xfe, 0x80, 0x49, 0x6c, 0x07, 0x9e, 0x9b, 0x5e, 0xc4, 0xe4, 0x6e,
+	0xcd, 0xd3, 0xde, 0x26, 0x17, 0x65, 0x1f, 0xaf, 0x84, 0x01, 0x05, 0xb5, 0xff, 0xc9, 0x52, 0x6c,
+	0xb2, 0x45, 0xe8, 0x36, 0xa8, 0x6e, 0xc8, 0x25, 0x9d, 0xf5, 0x9e, 0xa0, 0x43, 0x14, 0x82, 0x0d,
+	0xd1, 0xa0, 0x7f, 0x93, 0xb3, 0xc4, 0xe7, 0xa0, 0x65, 0x29, 0x74, 0x7d, 0xa6, 0xa7, 0xd8, 0xc1,
+	0x8b, 0x18, 0x85, 0x34, 0xee, 0xdd, 0xa6, 0xf3, 0x8d, 0xdb, 0x50, 0x75, 0xd8, 0x79, 0x51, 0xfd,
+	0xbf, 0xa6, 0xfd, 0x0c, 0xaa, 0xef, 0x43, 0x3f, 0xe8, 0x4c, 0x62, 0x21, 0x91, 0xcf, 0xbb, 0x4c,
+	0xea, 0x74, 0xc2, 0x40, 0xe2, 0xa5, 0x5c, 0xb4, 0xed, 0x1d, 0x34, 0xfa, 0x91, 0xc7, 0x24, 0x1e,
+	0xe2, 0xf4, 0x0c, 0xb9, 0x18, 0xf9, 0x11, 0xd5, 0x67, 0xc3, 0x9f, 0x71, 0x99, 0xc7, 0xf8, 0xa7,
+	0x62, 0x29, 0xf4, 0x05, 0x68, 0xd9, 0x33, 0x9a, 0x1f, 0xf6, 0xc6, 0xb3, 0x32, 0x16, 0xd3, 0x96,
+	0x42, 0x5f, 0x43, 0xcd, 0x95, 0x1c, 0xd9, 0x74, 0xf1, 0xfe, 0x62, 0x58, 0x6b, 0x37, 0xe9, 0xce,
+	0x28, 0x0e, 0xc6, 0x96, 0xb2, 0x45, 0x76, 0x1b, 0xdf, 0xaf, 0x9b, 0xe4, 0xc7, 0x75, 0x93, 0xfc,
+	0xbc, 0x6e, 0x92, 0xaf, 0xbf, 0x9a, 0xca, 0x59, 0xf6, 0x0b, 0x7a, 0xf2, 0x67, 0x00, 0xa9, 0x39,
+	0x1f, 0x38, 0x9a, 0x04, 0x00, 0x00,
 }
@@ -46,6 +46,8 @@ type SchemaRequest struct {
 	Predicates []string `protobuf:"bytes,2,rep,name=predicates" json:"predicates,omitempty"`
 	// fields can be on of type, index, reverse or tokenizer
 	Fields []string `protobuf:"bytes,3,rep,name=fields" json:"fields,omitempty"`
+	// Names of the types to return when fields is only types, all of them if empty.
+	Types []string `protobuf:"bytes,4,rep,name=types" json:"types,omitempty"`
 }
 
 func (m *SchemaRequest) Reset()                    { *m = SchemaRequest{} }
@@ -74,8 +76,16 @@ func (m *SchemaRequest) GetFields() []string {
 	return nil
 }
 
+func (m *SchemaRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
 type SchemaResult struct {
 	Schema []*SchemaNode `protobuf:"bytes,1,rep,name=schema" json:"schema,omitempty"`
+	Types  []*TypeUpdate `protobuf:"bytes,2,rep,name=types" json:"types,omitempty"`
 }
 
 func (m *SchemaResult) Reset()                    { *m = SchemaResult{} }
@@ -90,6 +100,13 @@ func (m *SchemaResult) GetSchema() []*SchemaNode {
 	return nil
 }
 
+func (m *SchemaResult) GetTypes() []*TypeUpdate {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
 type SchemaNode struct {
 	Predicate string   `protobuf:"bytes,1,opt,name=predicate,proto3" json:"predicate,omitempty"`
 	Type      string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
@@ -98,6 +115,18 @@ type SchemaNode struct {
 	Reverse   bool     `protobuf:"varint,5,opt,name=reverse,proto3" json:"reverse,omitempty"`
 	Count     bool     `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
 	List      bool     `protobuf:"varint,7,opt,name=list,proto3" json:"list,omitempty"`
+	Required  []string `protobuf:"bytes,8,rep,name=required" json:"required,omitempty"`
+	Default   string   `protobuf:"bytes,9,opt,name=default,proto3" json:"default,omitempty"`
+	Noreplace bool     `protobuf:"varint,10,opt,name=noreplace,proto3" json:"noreplace,omitempty"`
+	Ondelete  string   `protobuf:"bytes,11,opt,name=ondelete,proto3" json:"ondelete,omitempty"`
+	// Whether values of the predicate can have languages.
+	Lang bool `protobuf:"varint,12,opt,name=lang,proto3" json:"lang,omitempty"`
+	// Parameters of the geo index of the predicate, if it has one.
+	Geo *GeoIndex `protobuf:"bytes,13,opt,name=geo" json:"geo,omitempty"`
+	// Approximate number of nodes with a value of the predicate, and bytes of its data, indexes
+	// and reverse edges on disk.
+	Cardinality uint64 `protobuf:"varint,14,opt,name=cardinality,proto3" json:"cardinality,omitempty"`
+	Size_       uint64 `protobuf:"varint,15,opt,name=size,proto3" json:"size,omitempty"`
 }
 
 func (m *SchemaNode) Reset()                    { *m = SchemaNode{} }
@@ -154,6 +183,62 @@ func (m *SchemaNode) GetList() bool {
 	return false
 }
 
+func (m *SchemaNode) GetRequired() []string {
+	if m != nil {
+		return m.Required
+	}
+	return nil
+}
+
+func (m *SchemaNode) GetDefault() string {
+	if m != nil {
+		return m.Default
+	}
+	return ""
+}
+
+func (m *SchemaNode) GetNoreplace() bool {
+	if m != nil {
+		return m.Noreplace
+	}
+	return false
+}
+
+func (m *SchemaNode) GetOndelete() string {
+	if m != nil {
+		return m.Ondelete
+	}
+	return ""
+}
+
+func (m *SchemaNode) GetLang() bool {
+	if m != nil {
+		return m.Lang
+	}
+	return false
+}
+
+func (m *SchemaNode) GetGeo() *GeoIndex {
+	if m != nil {
+		return m.Geo
+	}
+	return nil
+}
+
+func (m *SchemaNode) GetCardinality() uint64 {
+	if m != nil {
+		return m.Cardinality
+	}
+	return 0
+}
+
+func (m *SchemaNode) GetSize_() uint64 {
+	if m != nil {
+		return m.Size_
+	}
+	return 0
+}
+
 type SchemaUpdate struct {
 	Predicate string                 `protobuf:"bytes,1,opt,name=predicate,proto3" json:"predicate,omitempty"`
 	ValueType uint32                 `protobuf:"varint,2,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
@@ -161,6 +246,25 @@ type SchemaUpdate struct {
 	Tokenizer []string               `protobuf:"bytes,4,rep,name=tokenizer" json:"tokenizer,omitempty"`
 	Count     bool                   `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
 	List      bool                   `protobuf:"varint,6,opt,name=list,proto3" json:"list,omitempty"`
+	// Kinds of nodes which must have a value of the predicate.
+	Required []string `protobuf:"bytes,7,rep,name=required" json:"required,omitempty"`
+	// Value of the predicate on nodes without one, as a string.
+	Default string `protobuf:"bytes,8,opt,name=default,proto3" json:"default,omitempty"`
+	// The predicate this one is being renamed to, or from.
+	RenamedTo   string `protobuf:"bytes,9,opt,name=renamed_to,json=renamedTo,proto3" json:"renamed_to,omitempty"`
+	RenamedFrom string `protobuf:"bytes,10,opt,name=renamed_from,json=renamedFrom,proto3" json:"renamed_from,omitempty"`
+	// Whether setting a value on a node which has another one fails, instead of replacing it.
+	Noreplace bool `protobuf:"varint,11,opt,name=noreplace,proto3" json:"noreplace,omitempty"`
+	// What deleting a node does to the edges of the predicate from or to it: cascade, detach or
+	// restrict.
+	Ondelete string `protobuf:"bytes,12,opt,name=ondelete,proto3" json:"ondelete,omitempty"`
+	// Parameters of the cells covering the values in the geo index, the default ones if not set.
+	Geo *GeoIndex `protobuf:"bytes,13,opt,name=geo" json:"geo,omitempty"`
+	// The predicate the index keys of this one are stored under, if not its own, as geo indexes
+	// rebuilt online are built under another one and swapped in.
+	IndexAttr string `protobuf:"bytes,14,opt,name=index_attr,json=indexAttr,proto3" json:"index_attr,omitempty"`
+	// The schema the geo index of the predicate is being rebuilt for online, under its index_attr.
+	Rebuild *SchemaUpdate `protobuf:"bytes,15,opt,name=rebuild" json:"rebuild,omitempty"`
 }
 
 func (m *SchemaUpdate) Reset()                    { *m = SchemaUpdate{} }
@@ -210,11 +314,143 @@ func (m *SchemaUpdate) GetList() bool {
 	return false
 }
 
+func (m *SchemaUpdate) GetRequired() []string {
+	if m != nil {
+		return m.Required
+	}
+	return nil
+}
+
+func (m *SchemaUpdate) GetDefault() string {
+	if m != nil {
+		return m.Default
+	}
+	return ""
+}
+
+func (m *SchemaUpdate) GetRenamedTo() string {
+	if m != nil {
+		return m.RenamedTo
+	}
+	return ""
+}
+
+func (m *SchemaUpdate) GetRenamedFrom() string {
+	if m != nil {
+		return m.RenamedFrom
+	}
+	return ""
+}
+
+func (m *SchemaUpdate) GetNoreplace() bool {
+	if m != nil {
+		return m.Noreplace
+	}
+	return false
+}
+
+func (m *SchemaUpdate) GetOndelete() string {
+	if m != nil {
+		return m.Ondelete
+	}
+	return ""
+}
+
+func (m *SchemaUpdate) GetGeo() *GeoIndex {
+	if m != nil {
+		return m.Geo
+	}
+	return nil
+}
+
+func (m *SchemaUpdate) GetIndexAttr() string {
+	if m != nil {
+		return m.IndexAttr
+	}
+	return ""
+}
+
+func (m *SchemaUpdate) GetRebuild() *SchemaUpdate {
+	if m != nil {
+		return m.Rebuild
+	}
+	return nil
+}
+
+// A type of nodes, declared with the predicates its nodes can have.
+type TypeUpdate struct {
+	TypeName string   `protobuf:"bytes,1,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	Fields   []string `protobuf:"bytes,2,rep,name=fields" json:"fields,omitempty"`
+}
+
+func (m *TypeUpdate) Reset()                    { *m = TypeUpdate{} }
+func (m *TypeUpdate) String() string            { return proto.CompactTextString(m) }
+func (*TypeUpdate) ProtoMessage()               {}
+func (*TypeUpdate) Descriptor() ([]byte, []int) { return fileDescriptorSchema, []int{4} }
+
+func (m *TypeUpdate) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *TypeUpdate) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// Parameters of the cells covering the values of a geo index.
+type GeoIndex struct {
+	MinLevel uint32 `protobuf:"varint,1,opt,name=min_level,json=minLevel,proto3" json:"min_level,omitempty"`
+	MaxLevel uint32 `protobuf:"varint,2,opt,name=max_level,json=maxLevel,proto3" json:"max_level,omitempty"`
+	MaxCells uint32 `protobuf:"varint,3,opt,name=max_cells,json=maxCells,proto3" json:"max_cells,omitempty"`
+	// The length of the geohashes of a geohash index, which has no cell levels.
+	Precision uint32 `protobuf:"varint,4,opt,name=precision,proto3" json:"precision,omitempty"`
+}
+
+func (m *GeoIndex) Reset()                    { *m = GeoIndex{} }
+func (m *GeoIndex) String() string            { return proto.CompactTextString(m) }
+func (*GeoIndex) ProtoMessage()               {}
+func (*GeoIndex) Descriptor() ([]byte, []int) { return fileDescriptorSchema, []int{5} }
+
+func (m *GeoIndex) GetMinLevel() uint32 {
+	if m != nil {
+		return m.MinLevel
+	}
+	return 0
+}
+
+func (m *GeoIndex) GetMaxLevel() uint32 {
+	if m != nil {
+		return m.MaxLevel
+	}
+	return 0
+}
+
+func (m *GeoIndex) GetMaxCells() uint32 {
+	if m != nil {
+		return m.MaxCells
+	}
+	return 0
+}
+
+func (m *GeoIndex) GetPrecision() uint32 {
+	if m != nil {
+		return m.Precision
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*SchemaRequest)(nil), "protos.SchemaRequest")
 	proto.RegisterType((*SchemaResult)(nil), "protos.SchemaResult")
 	proto.RegisterType((*SchemaNode)(nil), "protos.SchemaNode")
 	proto.RegisterType((*SchemaUpdate)(nil), "protos.SchemaUpdate")
+	proto.RegisterType((*TypeUpdate)(nil), "protos.TypeUpdate")
+	proto.RegisterType((*GeoIndex)(nil), "protos.GeoIndex")
 	proto.RegisterEnum("protos.SchemaUpdate_Directive", SchemaUpdate_Directive_name, SchemaUpdate_Directive_value)
 }
 func (m *SchemaRequest) Marshal() (dAtA []byte, err error) {
@@ -267,6 +503,21 @@ func (m *SchemaRequest) MarshalTo(dAtA []byte) (int, error) {
 			i += copy(dAtA[i:], s)
 		}
 	}
+	if len(m.Types) > 0 {
+		for _, s := range m.Types {
+			dAtA[i] = 0x22
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -297,6 +548,18 @@ func (m *SchemaResult) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.Types) > 0 {
+		for _, msg := range m.Types {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintSchema(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
@@ -382,6 +645,73 @@ func (m *SchemaNode) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if len(m.Required) > 0 {
+		for _, s := range m.Required {
+			dAtA[i] = 0x42
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.Default) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.Default)))
+		i += copy(dAtA[i:], m.Default)
+	}
+	if m.Noreplace {
+		dAtA[i] = 0x50
+		i++
+		if m.Noreplace {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Ondelete) > 0 {
+		dAtA[i] = 0x5a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.Ondelete)))
+		i += copy(dAtA[i:], m.Ondelete)
+	}
+	if m.Lang {
+		dAtA[i] = 0x60
+		i++
+		if m.Lang {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Geo != nil {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Geo.Size()))
+		n, err := m.Geo.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	if m.Cardinality != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Cardinality))
+	}
+	if m.Size_ != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Size_))
+	}
 	return i, nil
 }
 
@@ -451,55 +781,213 @@ func (m *SchemaUpdate) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
-	return i, nil
-}
-
-func encodeFixed64Schema(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	dAtA[offset+4] = uint8(v >> 32)
-	dAtA[offset+5] = uint8(v >> 40)
-	dAtA[offset+6] = uint8(v >> 48)
-	dAtA[offset+7] = uint8(v >> 56)
-	return offset + 8
-}
-func encodeFixed32Schema(dAtA []byte, offset int, v uint32) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	return offset + 4
-}
-func encodeVarintSchema(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
-	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
-}
-func (m *SchemaRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.GroupId != 0 {
-		n += 1 + sovSchema(uint64(m.GroupId))
-	}
-	if len(m.Predicates) > 0 {
-		for _, s := range m.Predicates {
+	if len(m.Required) > 0 {
+		for _, s := range m.Required {
+			dAtA[i] = 0x3a
+			i++
 			l = len(s)
-			n += 1 + l + sovSchema(uint64(l))
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
 		}
 	}
-	if len(m.Fields) > 0 {
-		for _, s := range m.Fields {
-			l = len(s)
-			n += 1 + l + sovSchema(uint64(l))
+	if len(m.Default) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.Default)))
+		i += copy(dAtA[i:], m.Default)
+	}
+	if len(m.RenamedTo) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.RenamedTo)))
+		i += copy(dAtA[i:], m.RenamedTo)
+	}
+	if len(m.RenamedFrom) > 0 {
+		dAtA[i] = 0x52
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.RenamedFrom)))
+		i += copy(dAtA[i:], m.RenamedFrom)
+	}
+	if m.Noreplace {
+		dAtA[i] = 0x58
+		i++
+		if m.Noreplace {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
+		i++
 	}
-	return n
+	if len(m.Ondelete) > 0 {
+		dAtA[i] = 0x62
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.Ondelete)))
+		i += copy(dAtA[i:], m.Ondelete)
+	}
+	if m.Geo != nil {
+		dAtA[i] = 0x6a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Geo.Size()))
+		n, err := m.Geo.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	if len(m.IndexAttr) > 0 {
+		dAtA[i] = 0x72
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.IndexAttr)))
+		i += copy(dAtA[i:], m.IndexAttr)
+	}
+	if m.Rebuild != nil {
+		dAtA[i] = 0x7a
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Rebuild.Size()))
+		n, err := m.Rebuild.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	return i, nil
+}
+
+func (m *TypeUpdate) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TypeUpdate) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.TypeName) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(len(m.TypeName)))
+		i += copy(dAtA[i:], m.TypeName)
+	}
+	if len(m.Fields) > 0 {
+		for _, s := range m.Fields {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *GeoIndex) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GeoIndex) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.MinLevel != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.MinLevel))
+	}
+	if m.MaxLevel != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.MaxLevel))
+	}
+	if m.MaxCells != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.MaxCells))
+	}
+	if m.Precision != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintSchema(dAtA, i, uint64(m.Precision))
+	}
+	return i, nil
+}
+
+func encodeFixed64Schema(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Schema(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintSchema(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *SchemaRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.GroupId != 0 {
+		n += 1 + sovSchema(uint64(m.GroupId))
+	}
+	if len(m.Predicates) > 0 {
+		for _, s := range m.Predicates {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	if len(m.Fields) > 0 {
+		for _, s := range m.Fields {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	if len(m.Types) > 0 {
+		for _, s := range m.Types {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	return n
 }
 
 func (m *SchemaResult) Size() (n int) {
@@ -511,6 +999,12 @@ func (m *SchemaResult) Size() (n int) {
 			n += 1 + l + sovSchema(uint64(l))
 		}
 	}
+	if len(m.Types) > 0 {
+		for _, e := range m.Types {
+			l = e.Size()
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -543,6 +1037,36 @@ func (m *SchemaNode) Size() (n int) {
 	if m.List {
 		n += 2
 	}
+	if len(m.Required) > 0 {
+		for _, s := range m.Required {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	l = len(m.Default)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Noreplace {
+		n += 2
+	}
+	l = len(m.Ondelete)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Lang {
+		n += 2
+	}
+	if m.Geo != nil {
+		l = m.Geo.Size()
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Cardinality != 0 {
+		n += 1 + sovSchema(uint64(m.Cardinality))
+	}
+	if m.Size_ != 0 {
+		n += 1 + sovSchema(uint64(m.Size_))
+	}
 	return n
 }
 
@@ -571,6 +1095,77 @@ func (m *SchemaUpdate) Size() (n int) {
 	if m.List {
 		n += 2
 	}
+	if len(m.Required) > 0 {
+		for _, s := range m.Required {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	l = len(m.Default)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	l = len(m.RenamedTo)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	l = len(m.RenamedFrom)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Noreplace {
+		n += 2
+	}
+	l = len(m.Ondelete)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Geo != nil {
+		l = m.Geo.Size()
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	l = len(m.IndexAttr)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if m.Rebuild != nil {
+		l = m.Rebuild.Size()
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	return n
+}
+
+func (m *TypeUpdate) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.TypeName)
+	if l > 0 {
+		n += 1 + l + sovSchema(uint64(l))
+	}
+	if len(m.Fields) > 0 {
+		for _, s := range m.Fields {
+			l = len(s)
+			n += 1 + l + sovSchema(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GeoIndex) Size() (n int) {
+	var l int
+	_ = l
+	if m.MinLevel != 0 {
+		n += 1 + sovSchema(uint64(m.MinLevel))
+	}
+	if m.MaxLevel != 0 {
+		n += 1 + sovSchema(uint64(m.MaxLevel))
+	}
+	if m.MaxCells != 0 {
+		n += 1 + sovSchema(uint64(m.MaxCells))
+	}
+	if m.Precision != 0 {
+		n += 1 + sovSchema(uint64(m.Precision))
+	}
 	return n
 }
 
@@ -693,6 +1288,35 @@ func (m *SchemaRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Fields = append(m.Fields, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Types", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Types = append(m.Types, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSchema(dAtA[iNdEx:])
@@ -774,6 +1398,37 @@ func (m *SchemaResult) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Types", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Types = append(m.Types, &TypeUpdate{})
+			if err := m.Types[len(m.Types)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSchema(dAtA[iNdEx:])
@@ -991,13 +1646,211 @@ func (m *SchemaNode) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.List = bool(v != 0)
-		default:
-			iNdEx = preIndex
-			skippy, err := skipSchema(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Required", wireType)
 			}
-			if skippy < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Required = append(m.Required, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Default", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Default = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Noreplace", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Noreplace = bool(v != 0)
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ondelete", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ondelete = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lang", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Lang = bool(v != 0)
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Geo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Geo == nil {
+				m.Geo = &GeoIndex{}
+			}
+			if err := m.Geo.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cardinality", wireType)
+			}
+			m.Cardinality = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Cardinality |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Size_", wireType)
+			}
+			m.Size_ = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Size_ |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSchema(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthSchema
 			}
 			if (iNdEx + skippy) > l {
@@ -1177,6 +2030,500 @@ func (m *SchemaUpdate) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.List = bool(v != 0)
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Required", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Required = append(m.Required, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Default", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Default = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RenamedTo", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RenamedTo = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RenamedFrom", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RenamedFrom = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Noreplace", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Noreplace = bool(v != 0)
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ondelete", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ondelete = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Geo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Geo == nil {
+				m.Geo = &GeoIndex{}
+			}
+			if err := m.Geo.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexAttr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IndexAttr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rebuild", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Rebuild == nil {
+				m.Rebuild = &SchemaUpdate{}
+			}
+			if err := m.Rebuild.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSchema(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSchema
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TypeUpdate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSchema
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TypeUpdate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TypeUpdate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TypeName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fields", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSchema
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Fields = append(m.Fields, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSchema(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSchema
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GeoIndex) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSchema
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GeoIndex: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GeoIndex: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinLevel", wireType)
+			}
+			m.MinLevel = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinLevel |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxLevel", wireType)
+			}
+			m.MaxLevel = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxLevel |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxCells", wireType)
+			}
+			m.MaxCells = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxCells |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Precision", wireType)
+			}
+			m.Precision = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSchema
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Precision |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSchema(dAtA[iNdEx:])
@@ -36,6 +36,51 @@ func (x DirectedEdge_Op) String() string {
 }
 func (DirectedEdge_Op) EnumDescriptor() ([]byte, []int) { return fileDescriptorTask, []int{10, 0} }
 
+type Rename_Op int32
+
+const (
+	Rename_START  Rename_Op = 0
+	Rename_COPY   Rename_Op = 1
+	Rename_FINISH Rename_Op = 2
+)
+
+var Rename_Op_name = map[int32]string{
+	0: "START",
+	1: "COPY",
+	2: "FINISH",
+}
+var Rename_Op_value = map[string]int32{
+	"START":  0,
+	"COPY":   1,
+	"FINISH": 2,
+}
+
+func (x Rename_Op) String() string {
+	return proto.EnumName(Rename_Op_name, int32(x))
+}
+func (Rename_Op) EnumDescriptor() ([]byte, []int) { return fileDescriptorTask, []int{16, 0} }
+
+type IndexRebuild_Op int32
+
+const (
+	IndexRebuild_BUILD  IndexRebuild_Op = 0
+	IndexRebuild_FINISH IndexRebuild_Op = 1
+)
+
+var IndexRebuild_Op_name = map[int32]string{
+	0: "BUILD",
+	1: "FINISH",
+}
+var IndexRebuild_Op_value = map[string]int32{
+	"BUILD":  0,
+	"FINISH": 1,
+}
+
+func (x IndexRebuild_Op) String() string {
+	return proto.EnumName(IndexRebuild_Op_name, int32(x))
+}
+func (IndexRebuild_Op) EnumDescriptor() ([]byte, []int) { return fileDescriptorTask, []int{17, 0} }
+
 type List struct {
 	Uids []uint64 `protobuf:"fixed64,1,rep,packed,name=uids" json:"uids,omitempty"`
 }
@@ -336,6 +381,7 @@ type Membership struct {
 	Leader     bool   `protobuf:"varint,4,opt,name=leader,proto3" json:"leader,omitempty"`
 	AmDead     bool   `protobuf:"varint,5,opt,name=am_dead,json=amDead,proto3" json:"am_dead,omitempty"`
 	LastUpdate uint64 `protobuf:"varint,6,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"`
+	ClientAddr string `protobuf:"bytes,7,opt,name=client_addr,json=clientAddr,proto3" json:"client_addr,omitempty"`
 }
 
 func (m *Membership) Reset()                    { *m = Membership{} }
@@ -385,6 +431,13 @@ func (m *Membership) GetLastUpdate() uint64 {
 	return 0
 }
 
+func (m *Membership) GetClientAddr() string {
+	if m != nil {
+		return m.ClientAddr
+	}
+	return ""
+}
+
 // MembershipUpdate is used to pack together the current membership state of all the nodes
 // in the caller server; and the membership updates recorded by the callee server since
 // the provided lastUpdate.
@@ -512,6 +565,10 @@ type Mutations struct {
 	GroupId uint32          `protobuf:"varint,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
 	Edges   []*DirectedEdge `protobuf:"bytes,2,rep,name=edges" json:"edges,omitempty"`
 	Schema  []*SchemaUpdate `protobuf:"bytes,3,rep,name=schema" json:"schema,omitempty"`
+	Rename  *Rename         `protobuf:"bytes,4,opt,name=rename" json:"rename,omitempty"`
+	Types   []*TypeUpdate   `protobuf:"bytes,5,rep,name=types" json:"types,omitempty"`
+	// A step of the online rebuild of the geo index of a predicate.
+	IndexRebuild *IndexRebuild `protobuf:"bytes,6,opt,name=index_rebuild,json=indexRebuild" json:"index_rebuild,omitempty"`
 }
 
 func (m *Mutations) Reset()                    { *m = Mutations{} }
@@ -540,6 +597,27 @@ func (m *Mutations) GetSchema() []*SchemaUpdate {
 	return nil
 }
 
+func (m *Mutations) GetRename() *Rename {
+	if m != nil {
+		return m.Rename
+	}
+	return nil
+}
+
+func (m *Mutations) GetTypes() []*TypeUpdate {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *Mutations) GetIndexRebuild() *IndexRebuild {
+	if m != nil {
+		return m.IndexRebuild
+	}
+	return nil
+}
+
 type Proposal struct {
 	Id         uint32      `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Mutations  *Mutations  `protobuf:"bytes,2,opt,name=mutations" json:"mutations,omitempty"`
@@ -644,6 +722,84 @@ func (m *GroupKeys) GetKeys() []*KC {
 	return nil
 }
 
+// A step of the rename of the predicate from to to, applied in order with the mutations of their
+// group.
+type Rename struct {
+	From string    `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   string    `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Op   Rename_Op `protobuf:"varint,3,opt,name=op,proto3,enum=protos.Rename_Op" json:"op,omitempty"`
+	// The nodes whose edges of from are copied to to, for COPY.
+	Uids *List `protobuf:"bytes,4,opt,name=uids" json:"uids,omitempty"`
+}
+
+func (m *Rename) Reset()                    { *m = Rename{} }
+func (m *Rename) String() string            { return proto.CompactTextString(m) }
+func (*Rename) ProtoMessage()               {}
+func (*Rename) Descriptor() ([]byte, []int) { return fileDescriptorTask, []int{16} }
+
+func (m *Rename) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Rename) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *Rename) GetOp() Rename_Op {
+	if m != nil {
+		return m.Op
+	}
+	return Rename_START
+}
+
+func (m *Rename) GetUids() *List {
+	if m != nil {
+		return m.Uids
+	}
+	return nil
+}
+
+// A step of the online rebuild of the geo index of the predicate attr, applied in order with the
+// mutations of its group.
+type IndexRebuild struct {
+	Attr string          `protobuf:"bytes,1,opt,name=attr,proto3" json:"attr,omitempty"`
+	Op   IndexRebuild_Op `protobuf:"varint,2,opt,name=op,proto3,enum=protos.IndexRebuild_Op" json:"op,omitempty"`
+	// The nodes whose values are added to the index being rebuilt, for BUILD.
+	Uids *List `protobuf:"bytes,3,opt,name=uids" json:"uids,omitempty"`
+}
+
+func (m *IndexRebuild) Reset()                    { *m = IndexRebuild{} }
+func (m *IndexRebuild) String() string            { return proto.CompactTextString(m) }
+func (*IndexRebuild) ProtoMessage()               {}
+func (*IndexRebuild) Descriptor() ([]byte, []int) { return fileDescriptorTask, []int{17} }
+
+func (m *IndexRebuild) GetAttr() string {
+	if m != nil {
+		return m.Attr
+	}
+	return ""
+}
+
+func (m *IndexRebuild) GetOp() IndexRebuild_Op {
+	if m != nil {
+		return m.Op
+	}
+	return IndexRebuild_BUILD
+}
+
+func (m *IndexRebuild) GetUids() *List {
+	if m != nil {
+		return m.Uids
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*List)(nil), "protos.List")
 	proto.RegisterType((*TaskValue)(nil), "protos.TaskValue")
@@ -661,7 +817,11 @@ func init() {
 	proto.RegisterType((*KV)(nil), "protos.KV")
 	proto.RegisterType((*KC)(nil), "protos.KC")
 	proto.RegisterType((*GroupKeys)(nil), "protos.GroupKeys")
+	proto.RegisterType((*Rename)(nil), "protos.Rename")
+	proto.RegisterType((*IndexRebuild)(nil), "protos.IndexRebuild")
 	proto.RegisterEnum("protos.DirectedEdge_Op", DirectedEdge_Op_name, DirectedEdge_Op_value)
+	proto.RegisterEnum("protos.Rename_Op", Rename_Op_name, Rename_Op_value)
+	proto.RegisterEnum("protos.IndexRebuild_Op", IndexRebuild_Op_name, IndexRebuild_Op_value)
 }
 func (m *List) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -1144,6 +1304,12 @@ func (m *Membership) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintTask(dAtA, i, uint64(m.LastUpdate))
 	}
+	if len(m.ClientAddr) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(len(m.ClientAddr)))
+		i += copy(dAtA[i:], m.ClientAddr)
+	}
 	return i, nil
 }
 
@@ -1316,6 +1482,38 @@ func (m *Mutations) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.Rename != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.Rename.Size()))
+		n, err := m.Rename.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	if len(m.Types) > 0 {
+		for _, msg := range m.Types {
+			dAtA[i] = 0x2a
+			i++
+			i = encodeVarintTask(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.IndexRebuild != nil {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.IndexRebuild.Size()))
+		n, err := m.IndexRebuild.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
 	return i, nil
 }
 
@@ -1457,6 +1655,90 @@ func (m *GroupKeys) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Rename) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Rename) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.From) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(len(m.From)))
+		i += copy(dAtA[i:], m.From)
+	}
+	if len(m.To) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(len(m.To)))
+		i += copy(dAtA[i:], m.To)
+	}
+	if m.Op != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.Op))
+	}
+	if m.Uids != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.Uids.Size()))
+		n, err := m.Uids.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	return i, nil
+}
+
+func (m *IndexRebuild) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IndexRebuild) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Attr) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(len(m.Attr)))
+		i += copy(dAtA[i:], m.Attr)
+	}
+	if m.Op != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.Op))
+	}
+	if m.Uids != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintTask(dAtA, i, uint64(m.Uids.Size()))
+		n, err := m.Uids.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	return i, nil
+}
+
 func encodeFixed64Task(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	dAtA[offset+1] = uint8(v >> 8)
@@ -1676,6 +1958,10 @@ func (m *Membership) Size() (n int) {
 	if m.LastUpdate != 0 {
 		n += 1 + sovTask(uint64(m.LastUpdate))
 	}
+	l = len(m.ClientAddr)
+	if l > 0 {
+		n += 1 + l + sovTask(uint64(l))
+	}
 	return n
 }
 
@@ -1759,6 +2045,20 @@ func (m *Mutations) Size() (n int) {
 			n += 1 + l + sovTask(uint64(l))
 		}
 	}
+	if m.Rename != nil {
+		l = m.Rename.Size()
+		n += 1 + l + sovTask(uint64(l))
+	}
+	if len(m.Types) > 0 {
+		for _, e := range m.Types {
+			l = e.Size()
+			n += 1 + l + sovTask(uint64(l))
+		}
+	}
+	if m.IndexRebuild != nil {
+		l = m.IndexRebuild.Size()
+		n += 1 + l + sovTask(uint64(l))
+	}
 	return n
 }
 
@@ -1822,6 +2122,44 @@ func (m *GroupKeys) Size() (n int) {
 	return n
 }
 
+func (m *Rename) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.From)
+	if l > 0 {
+		n += 1 + l + sovTask(uint64(l))
+	}
+	l = len(m.To)
+	if l > 0 {
+		n += 1 + l + sovTask(uint64(l))
+	}
+	if m.Op != 0 {
+		n += 1 + sovTask(uint64(m.Op))
+	}
+	if m.Uids != nil {
+		l = m.Uids.Size()
+		n += 1 + l + sovTask(uint64(l))
+	}
+	return n
+}
+
+func (m *IndexRebuild) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Attr)
+	if l > 0 {
+		n += 1 + l + sovTask(uint64(l))
+	}
+	if m.Op != 0 {
+		n += 1 + sovTask(uint64(m.Op))
+	}
+	if m.Uids != nil {
+		l = m.Uids.Size()
+		n += 1 + l + sovTask(uint64(l))
+	}
+	return n
+}
+
 func sovTask(x uint64) (n int) {
 	for {
 		n++
@@ -3188,6 +3526,35 @@ func (m *Membership) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClientAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ClientAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTask(dAtA[iNdEx:])
@@ -3739,20 +4106,117 @@ func (m *Mutations) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTask(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthTask
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rename", wireType)
 			}
-			iNdEx += skippy
-		}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Rename == nil {
+				m.Rename = &Rename{}
+			}
+			if err := m.Rename.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Types", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Types = append(m.Types, &TypeUpdate{})
+			if err := m.Types[len(m.Types)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IndexRebuild", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.IndexRebuild == nil {
+				m.IndexRebuild = &IndexRebuild{}
+			}
+			if err := m.IndexRebuild.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTask(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTask
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
 
 	if iNdEx > l {
@@ -4219,6 +4683,299 @@ func (m *GroupKeys) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Rename) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTask
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Rename: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Rename: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.From = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field To", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.To = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			m.Op = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Op |= (Rename_Op(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uids", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Uids == nil {
+				m.Uids = &List{}
+			}
+			if err := m.Uids.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTask(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTask
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (m *IndexRebuild) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTask
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IndexRebuild: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IndexRebuild: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Attr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			m.Op = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Op |= (IndexRebuild_Op(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Uids", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTask
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTask
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Uids == nil {
+				m.Uids = &List{}
+			}
+			if err := m.Uids.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTask(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTask
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 func skipTask(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -4327,69 +5084,69 @@ var (
 func init() { proto.RegisterFile("task.proto", fileDescriptorTask) }
 
 var fileDescriptorTask = []byte{
-	// 1023 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x56, 0x4d, 0x6f, 0xdb, 0x46,
-	0x13, 0x0e, 0xa9, 0x2f, 0x72, 0x24, 0x19, 0x7e, 0xf7, 0x35, 0x12, 0xda, 0x45, 0x5d, 0x81, 0x45,
-	0x10, 0xb5, 0x0d, 0x5c, 0xc0, 0xfd, 0x3e, 0xb6, 0xfe, 0x08, 0x02, 0xc7, 0x48, 0xba, 0x76, 0x72,
-	0x25, 0xd6, 0xda, 0x91, 0x4c, 0x88, 0x12, 0x89, 0xdd, 0xa5, 0x11, 0x9d, 0xda, 0x9f, 0xd1, 0x43,
-	0x7b, 0xe9, 0xa1, 0xe7, 0xfe, 0x8c, 0x1e, 0xfb, 0x13, 0x5a, 0xf7, 0x8f, 0x14, 0x3b, 0x4b, 0xd2,
-	0x56, 0x6a, 0x04, 0x68, 0x4f, 0xda, 0x67, 0x38, 0xbb, 0x33, 0xf3, 0xcc, 0xb3, 0xb3, 0x02, 0x30,
-	0x42, 0xcf, 0xf7, 0x0a, 0x95, 0x9b, 0x9c, 0x75, 0xe9, 0x47, 0xef, 0x0c, 0xa6, 0x62, 0x82, 0x46,
-	0x3b, 0xeb, 0xce, 0x40, 0x4f, 0x2e, 0x71, 0x21, 0x2a, 0xf4, 0xff, 0x99, 0x12, 0xc5, 0xa5, 0x42,
-	0x5d, 0xe4, 0x4b, 0x8d, 0xce, 0x18, 0xef, 0x40, 0xfb, 0x59, 0xaa, 0x0d, 0x63, 0xd0, 0x2e, 0x53,
-	0xa9, 0x23, 0x6f, 0xd4, 0x1a, 0x77, 0x39, 0xad, 0xe3, 0x2f, 0x21, 0x3c, 0x17, 0x7a, 0xfe, 0x4a,
-	0x64, 0x25, 0xb2, 0x4d, 0x68, 0x5d, 0x89, 0x2c, 0xf2, 0x46, 0xde, 0x78, 0xc0, 0xed, 0x92, 0x6d,
-	0x43, 0x70, 0x25, 0xb2, 0xc4, 0xac, 0x0a, 0x8c, 0xfc, 0x91, 0x37, 0xee, 0xf0, 0xde, 0x95, 0xc8,
-	0xce, 0x57, 0x05, 0xc6, 0xbf, 0xfa, 0xd0, 0xf9, 0xb6, 0x44, 0xb5, 0xb2, 0xe7, 0x0a, 0x63, 0x14,
-	0xed, 0x0b, 0x39, 0xad, 0xd9, 0x16, 0x74, 0x32, 0xb1, 0x9c, 0xe9, 0xc8, 0x1f, 0xb5, 0xc6, 0x21,
-	0x77, 0x80, 0xbd, 0x03, 0xa1, 0x98, 0x1a, 0x54, 0x49, 0x99, 0xca, 0xa8, 0x35, 0xf2, 0xc6, 0x5d,
-	0x1e, 0x90, 0xe1, 0x65, 0x2a, 0x6d, 0x2c, 0x99, 0x27, 0x93, 0xbc, 0x5c, 0x9a, 0xa8, 0x3d, 0xf2,
-	0xc6, 0x01, 0xef, 0xc9, 0xfc, 0xc0, 0x42, 0xf6, 0x08, 0x82, 0x32, 0x95, 0x49, 0x96, 0x6a, 0x13,
-	0x75, 0x46, 0xde, 0xb8, 0xbf, 0x3f, 0x70, 0xb5, 0xe9, 0x3d, 0x5b, 0x19, 0xef, 0x95, 0xa9, 0xa4,
-	0x12, 0xb7, 0x21, 0xd0, 0x6a, 0x92, 0x4c, 0xcb, 0xe5, 0x24, 0xea, 0x52, 0xe4, 0x9e, 0x56, 0x93,
-	0xe3, 0x72, 0x39, 0x61, 0x11, 0xf4, 0x14, 0x5e, 0xa1, 0xd2, 0x18, 0xf5, 0xdc, 0xe9, 0x15, 0x64,
-	0x7b, 0xd0, 0x27, 0x4a, 0x93, 0x42, 0x28, 0xb1, 0x88, 0x02, 0x0a, 0x30, 0xac, 0x03, 0xbc, 0xb0,
-	0x46, 0x0e, 0xe4, 0x41, 0x6b, 0xf6, 0x05, 0x0c, 0x5d, 0x0b, 0x92, 0x69, 0x9a, 0x19, 0x54, 0x51,
-	0x48, 0x3b, 0x58, 0xbd, 0xe3, 0x98, 0xac, 0xe7, 0x0a, 0x91, 0x57, 0xbd, 0x72, 0x96, 0xf8, 0x73,
-	0x08, 0x89, 0x68, 0x4a, 0xf5, 0x03, 0xe8, 0x5e, 0x59, 0xe0, 0xfa, 0xd1, 0xdf, 0xff, 0x5f, 0xbd,
-	0xbd, 0xe9, 0x07, 0xaf, 0x1c, 0xe2, 0x3f, 0x3d, 0xe8, 0x72, 0xd4, 0x65, 0x66, 0xd8, 0x47, 0x00,
-	0x96, 0x89, 0x85, 0x30, 0x2a, 0x7d, 0x5d, 0xed, 0x5c, 0xe7, 0x22, 0x2c, 0x53, 0x79, 0x4a, 0x9f,
-	0xd9, 0xa7, 0x30, 0xa0, 0x13, 0x6a, 0x77, 0x7f, 0x3d, 0x50, 0x93, 0x0b, 0xef, 0x93, 0x5b, 0xb5,
-	0xeb, 0x3e, 0x74, 0xa9, 0x09, 0x3a, 0x6a, 0x8d, 0x5a, 0xe3, 0x21, 0xaf, 0x10, 0x7b, 0x08, 0x1b,
-	0xe9, 0xd2, 0x58, 0xc6, 0x26, 0x26, 0x91, 0xa8, 0xeb, 0x2e, 0x0d, 0x1b, 0xeb, 0x21, 0x6a, 0xc3,
-	0x3e, 0x03, 0x57, 0x74, 0x1d, 0xb4, 0x43, 0x41, 0x6f, 0xc8, 0x21, 0x42, 0x5c, 0x54, 0xf2, 0x73,
-	0x51, 0xe3, 0x5f, 0x3c, 0xe8, 0x9f, 0xe5, 0xca, 0x9c, 0xa2, 0xd6, 0x62, 0x86, 0xff, 0x42, 0x54,
-	0xeb, 0x94, 0xb4, 0xde, 0x4e, 0xc9, 0x16, 0x74, 0x6e, 0x14, 0xd6, 0xe1, 0x0e, 0xd8, 0x92, 0xf3,
-	0xe9, 0x54, 0xa3, 0x53, 0x57, 0x87, 0x57, 0xc8, 0x26, 0x21, 0x51, 0x5b, 0x29, 0xd9, 0x42, 0x69,
-	0x1d, 0x7f, 0x05, 0x60, 0xf3, 0xfc, 0x0f, 0xfd, 0x88, 0x9f, 0x40, 0x9f, 0x8b, 0xa9, 0x39, 0xc8,
-	0x97, 0x06, 0x5f, 0x1b, 0xb6, 0x01, 0x7e, 0x2a, 0xa9, 0xc0, 0x2e, 0xf7, 0x53, 0x69, 0x73, 0x9b,
-	0xa9, 0xbc, 0x2c, 0xe8, 0xa6, 0x0d, 0xb9, 0x03, 0x44, 0x84, 0x94, 0x8a, 0xae, 0x8b, 0x25, 0x42,
-	0x4a, 0x15, 0xff, 0xe4, 0x01, 0x9c, 0xe2, 0xe2, 0x02, 0x95, 0xbe, 0x4c, 0x8b, 0x7f, 0x1c, 0xb4,
-	0x0d, 0x01, 0xed, 0x4d, 0x52, 0x59, 0x9d, 0xd5, 0x23, 0xfc, 0x54, 0xde, 0x75, 0x9a, 0xad, 0x3e,
-	0x43, 0x21, 0x51, 0x55, 0x0d, 0xad, 0x10, 0x7b, 0x00, 0x3d, 0xb1, 0x48, 0x24, 0x0a, 0x49, 0xb4,
-	0x04, 0xbc, 0x2b, 0x16, 0x87, 0x28, 0x24, 0x7b, 0x0f, 0xfa, 0x99, 0xd0, 0x26, 0x29, 0x0b, 0x29,
-	0x0c, 0x12, 0x3b, 0x6d, 0x0e, 0xd6, 0xf4, 0x92, 0x2c, 0xf1, 0xcf, 0x1e, 0x6c, 0xde, 0xe4, 0xe7,
-	0x8c, 0xec, 0x31, 0xf4, 0x16, 0xce, 0x56, 0xf1, 0xd4, 0x68, 0xe2, 0xc6, 0x95, 0xd7, 0x2e, 0x6f,
-	0xc6, 0xf0, 0xdf, 0x8c, 0xc1, 0x76, 0x20, 0x50, 0x28, 0x53, 0x85, 0x13, 0x43, 0xd5, 0x04, 0xbc,
-	0xc1, 0xec, 0x7d, 0x18, 0xd6, 0xeb, 0x84, 0xca, 0x6d, 0x53, 0xb9, 0x83, 0xda, 0xf8, 0xb5, 0x25,
-	0xf1, 0x47, 0x1f, 0x06, 0x87, 0x04, 0x51, 0x1e, 0xc9, 0x19, 0x5a, 0x1e, 0x70, 0x69, 0x52, 0xb3,
-	0xaa, 0xa8, 0xac, 0x50, 0x23, 0x45, 0x7f, 0x5d, 0x8a, 0x74, 0x67, 0x28, 0xf4, 0x80, 0x3b, 0xc0,
-	0xde, 0x05, 0x70, 0x17, 0x8e, 0x06, 0x66, 0x9b, 0xa8, 0x0f, 0xc9, 0x62, 0x47, 0x66, 0x35, 0x4d,
-	0x4b, 0xb4, 0x7d, 0xe9, 0x50, 0x88, 0x1e, 0xe1, 0xa7, 0xd2, 0x49, 0xfb, 0x02, 0x33, 0x22, 0x93,
-	0xa4, 0x7d, 0x81, 0x99, 0x8d, 0x6c, 0x35, 0x4e, 0x03, 0x2b, 0xe4, 0xb4, 0x66, 0x8f, 0xc0, 0xcf,
-	0x0b, 0x1a, 0x52, 0x1b, 0xfb, 0x0f, 0x6a, 0x06, 0x6f, 0xd7, 0xb1, 0xf7, 0xbc, 0xe0, 0x7e, 0x5e,
-	0xb0, 0x87, 0xd0, 0x75, 0xd3, 0x27, 0x0a, 0x89, 0xee, 0xe1, 0xda, 0x15, 0xe4, 0xd5, 0xc7, 0xf8,
-	0x3e, 0xf8, 0xcf, 0x0b, 0xd6, 0x83, 0xd6, 0xd9, 0xd1, 0xf9, 0xe6, 0x3d, 0xbb, 0x38, 0x3c, 0x7a,
-	0xb6, 0xe9, 0xc5, 0xdf, 0x7b, 0x10, 0x9e, 0x96, 0x46, 0x98, 0x34, 0x5f, 0xea, 0x35, 0x49, 0x79,
-	0xeb, 0x92, 0xfa, 0x10, 0x3a, 0x28, 0x67, 0xa8, 0xab, 0xf1, 0xb2, 0x75, 0x57, 0x4e, 0xdc, 0xb9,
-	0xb0, 0xc7, 0xd0, 0x75, 0xef, 0x55, 0x75, 0x4f, 0x1b, 0xe7, 0x33, 0xb2, 0xba, 0xd6, 0xf2, 0xca,
-	0x27, 0xfe, 0x0e, 0x82, 0x17, 0x2a, 0x2f, 0x72, 0x2d, 0xb2, 0x5b, 0x1a, 0x1f, 0x92, 0xc6, 0x3f,
-	0x86, 0x70, 0x51, 0x67, 0x47, 0x9d, 0xb9, 0x35, 0xd8, 0x9a, 0xb4, 0xf9, 0x8d, 0x0f, 0xdb, 0x07,
-	0x58, 0x34, 0x3a, 0xa3, 0xb6, 0xdd, 0xad, 0xc0, 0x5b, 0x5e, 0xf1, 0x18, 0xfc, 0x93, 0x57, 0xf6,
-	0x59, 0x9c, 0xe3, 0xaa, 0x7e, 0x16, 0xe7, 0xb8, 0xaa, 0x1f, 0x4a, 0xbf, 0x79, 0x28, 0xe3, 0x7d,
-	0xf0, 0x4f, 0x0e, 0xee, 0xf0, 0xdc, 0x81, 0x60, 0x72, 0x89, 0x93, 0xb9, 0x2e, 0x17, 0x95, 0x7b,
-	0x83, 0xe3, 0x63, 0x08, 0x9f, 0x58, 0x0e, 0x4f, 0x70, 0xf5, 0x56, 0x82, 0x77, 0xa1, 0x3d, 0xc7,
-	0x55, 0xcd, 0x2f, 0xd4, 0x39, 0x9f, 0x1c, 0x70, 0xb2, 0x7f, 0xb3, 0xf9, 0xdb, 0xf5, 0xae, 0xf7,
-	0xfb, 0xf5, 0xae, 0xf7, 0xc7, 0xf5, 0xae, 0xf7, 0xc3, 0x5f, 0xbb, 0xf7, 0x2e, 0xdc, 0x5f, 0x85,
-	0x4f, 0xfe, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x22, 0xdf, 0xb3, 0x83, 0x3f, 0x08, 0x00, 0x00,
+	// 1010 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x9d, 0x56, 0x4b, 0x6f, 0xdb, 0x46,
+	0x10, 0x0e, 0x29, 0x89, 0x14, 0x47, 0x92, 0xa1, 0x6e, 0x83, 0x84, 0x75, 0x51, 0xb7, 0x60, 0x50,
+	0xd4, 0x7d, 0xc0, 0x05, 0xd4, 0xf7, 0xb1, 0xf5, 0x23, 0x08, 0x1c, 0x23, 0xe9, 0xda, 0xc9, 0x95,
+	0x58, 0x8b, 0x2b, 0x9b, 0x30, 0x29, 0x12, 0xdc, 0xa5, 0x11, 0x9d, 0xda, 0x9f, 0xd1, 0x43, 0x4f,
+	0x3d, 0xf4, 0xdc, 0x5f, 0xd0, 0x73, 0x8f, 0xfd, 0x09, 0x7d, 0xfc, 0x91, 0xce, 0xce, 0x92, 0x94,
+	0xd5, 0x18, 0x01, 0xda, 0x83, 0xc0, 0xfd, 0x66, 0x67, 0x77, 0x66, 0xbe, 0x79, 0xac, 0x00, 0xb4,
+	0x50, 0x57, 0x7b, 0x65, 0x55, 0xe8, 0x82, 0x79, 0xf4, 0x51, 0xdb, 0xe3, 0x85, 0x98, 0x4b, 0xad,
+	0xac, 0x74, 0x7b, 0xac, 0xe6, 0x97, 0x32, 0x17, 0x0d, 0x7a, 0xfd, 0xa2, 0x12, 0xe5, 0x65, 0x25,
+	0x55, 0x59, 0x2c, 0x95, 0xb4, 0xc2, 0x68, 0x1b, 0xfa, 0x8f, 0x53, 0xa5, 0x19, 0x83, 0x7e, 0x9d,
+	0x26, 0x2a, 0x74, 0xde, 0xe9, 0xed, 0x7a, 0x9c, 0xd6, 0xd1, 0x97, 0x10, 0x9c, 0xa1, 0x89, 0xe7,
+	0x22, 0xab, 0x25, 0x9b, 0x42, 0xef, 0x5a, 0x64, 0xb8, 0xef, 0xec, 0x8e, 0xb9, 0x59, 0xb2, 0x37,
+	0x60, 0x88, 0x9f, 0x58, 0xaf, 0x4a, 0x19, 0xba, 0x28, 0x1e, 0x70, 0x1f, 0xf1, 0x19, 0xc2, 0xe8,
+	0x17, 0x17, 0x06, 0xdf, 0xd6, 0xb2, 0x5a, 0x99, 0x7b, 0x85, 0xd6, 0x15, 0x9d, 0x0b, 0x38, 0xad,
+	0xd9, 0x5d, 0x18, 0x64, 0x62, 0x79, 0xa1, 0xf0, 0x54, 0x0f, 0x85, 0x16, 0xb0, 0x37, 0x21, 0x10,
+	0x0b, 0x2d, 0xab, 0x18, 0x6d, 0x87, 0x3d, 0x54, 0xf7, 0xf8, 0x90, 0x04, 0xcf, 0xd2, 0xc4, 0xd8,
+	0x4a, 0x8a, 0x78, 0x5e, 0xd4, 0x4b, 0x1d, 0xf6, 0x71, 0x6f, 0xc8, 0xfd, 0xa4, 0xd8, 0x37, 0x90,
+	0xbd, 0x07, 0x43, 0x3c, 0x11, 0x67, 0x18, 0x45, 0x38, 0xc0, 0xad, 0xd1, 0x6c, 0x6c, 0x63, 0x53,
+	0x7b, 0x26, 0x32, 0xee, 0xe3, 0x2e, 0x85, 0x88, 0x77, 0xa8, 0x6a, 0x1e, 0x2f, 0xea, 0xe5, 0x3c,
+	0xf4, 0xc8, 0xb2, 0x8f, 0xf8, 0x08, 0x21, 0x0b, 0xc1, 0xaf, 0xe4, 0xb5, 0xac, 0x94, 0x0c, 0x7d,
+	0x7b, 0x7b, 0x03, 0xd9, 0x1e, 0x8c, 0x88, 0xd2, 0xb8, 0x14, 0x95, 0xc8, 0xc3, 0x21, 0x19, 0x98,
+	0xb4, 0x06, 0x9e, 0x1a, 0x21, 0x07, 0xd2, 0xa0, 0x35, 0xfb, 0x02, 0x26, 0x36, 0x05, 0xf1, 0x22,
+	0xcd, 0xd0, 0xf9, 0x30, 0xa0, 0x13, 0xac, 0x3d, 0x71, 0x44, 0xd2, 0xb3, 0x4a, 0x4a, 0xde, 0xe4,
+	0xca, 0x4a, 0xa2, 0xcf, 0x21, 0x20, 0xa2, 0xc9, 0xd5, 0xf7, 0xc1, 0xbb, 0x36, 0xc0, 0xe6, 0x63,
+	0x34, 0x7b, 0xad, 0x3d, 0xde, 0xe5, 0x83, 0x37, 0x0a, 0xd1, 0x9f, 0x0e, 0x78, 0x5c, 0xaa, 0x3a,
+	0xd3, 0xec, 0x43, 0x00, 0xc3, 0x44, 0x2e, 0x74, 0x95, 0xbe, 0x68, 0x4e, 0x6e, 0x72, 0x11, 0xe0,
+	0xfe, 0x09, 0x6d, 0xb3, 0x4f, 0x61, 0x4c, 0x37, 0xb4, 0xea, 0xee, 0xa6, 0xa1, 0xce, 0x17, 0x3e,
+	0x22, 0xb5, 0xe6, 0xd4, 0x3d, 0xf0, 0x28, 0x09, 0x0a, 0x33, 0xd4, 0xdb, 0x9d, 0xf0, 0x06, 0xb1,
+	0x77, 0x61, 0x2b, 0x5d, 0x6a, 0xc3, 0xd8, 0x5c, 0xc7, 0x89, 0x54, 0x6d, 0x96, 0x26, 0x9d, 0xf4,
+	0x00, 0x85, 0xec, 0x33, 0xb0, 0x41, 0xb7, 0x46, 0x07, 0x64, 0x74, 0x4d, 0x0e, 0x11, 0x62, 0xad,
+	0x92, 0x9e, 0xb5, 0x1a, 0xfd, 0xec, 0xc0, 0xe8, 0xb4, 0xa8, 0xf4, 0x89, 0x54, 0x4a, 0x5c, 0xc8,
+	0xff, 0x50, 0x54, 0x9b, 0x94, 0xf4, 0x5e, 0x4d, 0x09, 0x5e, 0xb1, 0xae, 0xb0, 0x01, 0xb7, 0xc0,
+	0x84, 0x5c, 0x2c, 0x16, 0x4a, 0xda, 0xea, 0x1a, 0xf0, 0x06, 0x19, 0x27, 0x30, 0x50, 0x53, 0x4a,
+	0x26, 0x50, 0x5a, 0x47, 0x5f, 0x01, 0x18, 0x3f, 0xff, 0x47, 0x3e, 0xa2, 0x87, 0x30, 0xe2, 0x58,
+	0xee, 0xfb, 0x05, 0x12, 0xf6, 0x42, 0xb3, 0x2d, 0x70, 0xb1, 0x0d, 0x1c, 0x6a, 0x03, 0x5c, 0x19,
+	0xdf, 0x2e, 0xaa, 0xa2, 0x2e, 0xa9, 0xd3, 0x26, 0xdc, 0x02, 0x22, 0x22, 0x49, 0x2a, 0x6a, 0x17,
+	0x43, 0x04, 0xae, 0xa3, 0x5f, 0x1d, 0x80, 0x13, 0x99, 0x9f, 0x23, 0xed, 0x97, 0x69, 0xf9, 0xd2,
+	0x45, 0xd8, 0x05, 0x74, 0x36, 0x46, 0xa9, 0xbd, 0xcb, 0x27, 0xfc, 0x28, 0xb9, 0xed, 0x36, 0x13,
+	0x7d, 0x26, 0x45, 0x82, 0x85, 0x6c, 0x13, 0xda, 0x20, 0x76, 0x1f, 0x7c, 0x91, 0x63, 0xa6, 0x45,
+	0x42, 0xb4, 0xe0, 0x86, 0xc8, 0x0f, 0x10, 0xb1, 0xb7, 0x61, 0x94, 0x09, 0xa5, 0xe3, 0xba, 0x4c,
+	0x84, 0x96, 0xc4, 0x4e, 0x9f, 0x83, 0x11, 0x3d, 0x23, 0x89, 0x51, 0x98, 0x67, 0xa9, 0x5c, 0xea,
+	0x98, 0x8c, 0xf9, 0x64, 0x0c, 0xac, 0xe8, 0x6b, 0x13, 0xc0, 0x4f, 0x0e, 0x4c, 0xd7, 0x01, 0x34,
+	0xa7, 0x3e, 0x02, 0x3f, 0xb7, 0xb2, 0x86, 0xc8, 0xae, 0x68, 0xd6, 0xaa, 0xbc, 0x55, 0xf9, 0xb7,
+	0x13, 0xee, 0x4b, 0x4e, 0x6c, 0xc3, 0xb0, 0x92, 0x49, 0x5a, 0x61, 0x61, 0x52, 0xb8, 0x43, 0xde,
+	0x61, 0xf6, 0x00, 0x26, 0xed, 0xda, 0xba, 0xd8, 0x27, 0x17, 0xc7, 0xad, 0x90, 0x9c, 0xfc, 0xd1,
+	0x85, 0xf1, 0x01, 0x41, 0x99, 0x1c, 0x26, 0x58, 0x93, 0x48, 0x14, 0x06, 0x90, 0xea, 0x55, 0xc3,
+	0x75, 0x83, 0xba, 0x5a, 0x75, 0x37, 0x6b, 0x95, 0x9a, 0x8a, 0x4c, 0x8f, 0xb9, 0x05, 0xec, 0x2d,
+	0x00, 0xdb, 0x91, 0x34, 0x51, 0xfb, 0x94, 0x9b, 0x80, 0x24, 0x66, 0xa6, 0x36, 0xe3, 0x16, 0xb7,
+	0x53, 0x4b, 0xb9, 0x47, 0xe3, 0xb6, 0x96, 0x8f, 0x12, 0x5b, 0xfb, 0xe7, 0x32, 0x23, 0xb6, 0xa9,
+	0xf6, 0x11, 0x18, 0xcb, 0xa6, 0x09, 0x1a, 0x86, 0x69, 0x8d, 0xc3, 0xd2, 0x2d, 0x4a, 0x9a, 0x62,
+	0x5b, 0xb3, 0xfb, 0x2d, 0x83, 0x37, 0xe3, 0xd8, 0x7b, 0x52, 0x72, 0x54, 0xc1, 0x86, 0xf6, 0xec,
+	0x78, 0xc2, 0x01, 0xd6, 0xbb, 0x39, 0xf2, 0xa8, 0x47, 0x79, 0xb3, 0x19, 0xdd, 0x03, 0xf7, 0x49,
+	0xc9, 0x7c, 0xe8, 0x9d, 0x1e, 0x9e, 0x4d, 0xef, 0x98, 0xc5, 0xc1, 0xe1, 0xe3, 0xa9, 0x13, 0x7d,
+	0xef, 0x40, 0x70, 0x52, 0x6b, 0xa1, 0x53, 0x7c, 0x6b, 0x36, 0x6a, 0xce, 0xd9, 0xac, 0xb9, 0x0f,
+	0x60, 0x20, 0xd1, 0xac, 0x6a, 0xe6, 0xcf, 0xdd, 0xdb, 0x7c, 0xe2, 0x56, 0x05, 0x6b, 0xc0, 0xb3,
+	0x0f, 0x5a, 0xd3, 0xc8, 0x9d, 0xf2, 0x29, 0x49, 0x6d, 0x6a, 0x79, 0xa3, 0x13, 0x7d, 0x07, 0xc3,
+	0xa7, 0x55, 0x51, 0x16, 0x0a, 0x9f, 0xaa, 0x75, 0x13, 0x4c, 0xa8, 0x09, 0x3e, 0x86, 0x20, 0x6f,
+	0xbd, 0xa3, 0xcc, 0xdc, 0x98, 0x7c, 0x9d, 0xdb, 0x7c, 0xad, 0xc3, 0x66, 0x00, 0x79, 0x57, 0x67,
+	0x94, 0xb6, 0xdb, 0x2b, 0xf0, 0x86, 0x56, 0xb4, 0x0b, 0xee, 0xf1, 0x73, 0xf3, 0x6e, 0x5e, 0xc9,
+	0x55, 0xfb, 0x6e, 0xe2, 0xb2, 0x7d, 0x49, 0xdd, 0xee, 0x25, 0x8d, 0x66, 0xa8, 0xb9, 0x7f, 0x8b,
+	0x26, 0x56, 0x29, 0xc6, 0x32, 0xbf, 0x52, 0x75, 0xde, 0xa8, 0x77, 0x38, 0x3a, 0x82, 0xe0, 0xa1,
+	0xe1, 0xf0, 0x58, 0xae, 0x5e, 0x49, 0xf0, 0x0e, 0xf4, 0xf1, 0xaa, 0x96, 0x5f, 0x68, 0x7d, 0x3e,
+	0xde, 0xe7, 0x24, 0xff, 0x66, 0xfa, 0xdb, 0x5f, 0x3b, 0xce, 0xef, 0xf8, 0xfb, 0x03, 0x7f, 0x3f,
+	0xfc, 0xbd, 0x73, 0xe7, 0xdc, 0xfe, 0x97, 0xf8, 0xe4, 0x1f, 0x4a, 0x5f, 0x4d, 0xa3, 0x60, 0x08,
+	0x00, 0x00,
 }
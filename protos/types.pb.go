@@ -93,6 +93,7 @@ type Posting struct {
 	Label       string              `protobuf:"bytes,6,opt,name=label,proto3" json:"label,omitempty"`
 	Commit      uint64              `protobuf:"varint,7,opt,name=commit,proto3" json:"commit,omitempty"`
 	Facets      []*Facet            `protobuf:"bytes,8,rep,name=facets" json:"facets,omitempty"`
+	ValueRef    bool                `protobuf:"varint,9,opt,name=value_ref,json=valueRef,proto3" json:"value_ref,omitempty"`
 	// TODO: op is only used temporarily. See if we can remove it from here.
 	Op uint32 `protobuf:"varint,12,opt,name=op,proto3" json:"op,omitempty"`
 }
@@ -158,6 +159,13 @@ func (m *Posting) GetFacets() []*Facet {
 	return nil
 }
 
+func (m *Posting) GetValueRef() bool {
+	if m != nil {
+		return m.ValueRef
+	}
+	return false
+}
+
 func (m *Posting) GetOp() uint32 {
 	if m != nil {
 		return m.Op
@@ -276,6 +284,16 @@ func (m *Posting) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if m.ValueRef {
+		dAtA[i] = 0x48
+		i++
+		if m.ValueRef {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.Op != 0 {
 		dAtA[i] = 0x60
 		i++
@@ -391,6 +409,9 @@ func (m *Posting) Size() (n int) {
 			n += 1 + l + sovTypes(uint64(l))
 		}
 	}
+	if m.ValueRef {
+		n += 2
+	}
 	if m.Op != 0 {
 		n += 1 + sovTypes(uint64(m.Op))
 	}
@@ -658,6 +679,26 @@ func (m *Posting) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValueRef", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ValueRef = bool(v != 0)
 		case 12:
 			if wireType != 0 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
@@ -968,33 +1009,35 @@ var (
 func init() { proto.RegisterFile("types.proto", fileDescriptorTypes) }
 
 var fileDescriptorTypes = []byte{
-	// 448 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0x52, 0xd1, 0x8a, 0xd3, 0x40,
-	0x14, 0xed, 0x24, 0x69, 0x92, 0xde, 0x64, 0xeb, 0x70, 0x11, 0x1d, 0x56, 0x28, 0xa1, 0x20, 0x04,
-	0x84, 0x82, 0xf5, 0x5d, 0x48, 0x69, 0x5a, 0x02, 0xb1, 0x59, 0xa6, 0xe9, 0x8a, 0x4f, 0x4b, 0xb6,
-	0x8d, 0x1a, 0x4c, 0x4d, 0x30, 0x69, 0x61, 0x7d, 0xf4, 0x2b, 0xfc, 0x24, 0x1f, 0x7d, 0xf1, 0x5d,
-	0xea, 0x8f, 0xc8, 0x24, 0x63, 0xdc, 0xed, 0xd3, 0x9c, 0x33, 0x77, 0xce, 0xc9, 0xbd, 0xe7, 0x06,
-	0xac, 0xfa, 0xae, 0x4c, 0xab, 0x49, 0xf9, 0xa5, 0xa8, 0x0b, 0xd4, 0x9b, 0xa3, 0xba, 0xb4, 0xdf,
-	0x27, 0xdb, 0xb4, 0x96, 0xb7, 0xe3, 0x5f, 0x2a, 0x18, 0x57, 0x45, 0x55, 0x67, 0x9f, 0x3f, 0x20,
-	0x05, 0xf5, 0x90, 0xed, 0x18, 0x71, 0x88, 0xab, 0x73, 0x01, 0xf1, 0x31, 0xf4, 0x8f, 0x49, 0x7e,
-	0x48, 0x99, 0xe2, 0x10, 0xd7, 0xe6, 0x2d, 0xc1, 0x29, 0x98, 0xc7, 0x24, 0xbf, 0x11, 0xe6, 0x4c,
-	0x75, 0x88, 0x3b, 0x9c, 0x3e, 0x6d, 0xdd, 0xaa, 0x89, 0xb4, 0x9a, 0x5c, 0x27, 0x79, 0x7c, 0x57,
-	0xa6, 0xdc, 0x38, 0xb6, 0x00, 0x5f, 0x83, 0x5d, 0xb6, 0xb5, 0x56, 0xa7, 0x35, 0xba, 0x67, 0xe7,
-	0x3a, 0x79, 0x36, 0x5a, 0xab, 0xfc, 0x4f, 0xf0, 0x12, 0xcc, 0x7d, 0x5a, 0x27, 0xbb, 0xa4, 0x4e,
-	0x58, 0xbf, 0x69, 0xa6, 0xe3, 0xa2, 0xcb, 0x3c, 0xb9, 0x4d, 0x73, 0xa6, 0x3b, 0xc4, 0x1d, 0xf0,
-	0x96, 0xe0, 0x13, 0xd0, 0xb7, 0xc5, 0x7e, 0x9f, 0xd5, 0xcc, 0x70, 0x88, 0xab, 0x71, 0xc9, 0xf0,
-	0x39, 0xe8, 0x6d, 0x02, 0xcc, 0x74, 0x54, 0xd7, 0x9a, 0x5e, 0xfc, 0xeb, 0x61, 0x21, 0x6e, 0xb9,
-	0x2c, 0xe2, 0x10, 0x94, 0xa2, 0x64, 0xb6, 0x43, 0xdc, 0x0b, 0xae, 0x14, 0xe5, 0xf8, 0x2b, 0x18,
-	0x72, 0x28, 0xb4, 0xc0, 0x98, 0xfb, 0x0b, 0x6f, 0x13, 0xc6, 0xb4, 0x87, 0x00, 0xfa, 0x2c, 0x58,
-	0x79, 0xfc, 0x1d, 0x25, 0x68, 0x80, 0x1a, 0xac, 0x62, 0xaa, 0xe0, 0x00, 0xfa, 0x8b, 0x30, 0xf2,
-	0x62, 0xaa, 0xa2, 0x09, 0xda, 0x2c, 0x8a, 0x42, 0xaa, 0xa1, 0x0d, 0xe6, 0xdc, 0x8b, 0xfd, 0x38,
-	0x78, 0xe3, 0xd3, 0xbe, 0x78, 0xbb, 0xf4, 0x23, 0xaa, 0x0b, 0xb0, 0x09, 0xe6, 0xd4, 0x10, 0xf5,
-	0x2b, 0x6f, 0xbd, 0x7e, 0x1b, 0xf1, 0x39, 0x35, 0x85, 0xef, 0x3a, 0xe6, 0xc1, 0x6a, 0x49, 0x07,
-	0xe3, 0x97, 0x60, 0xdd, 0x0b, 0x46, 0x28, 0xb8, 0xbf, 0xa0, 0x3d, 0xf1, 0x99, 0x6b, 0x2f, 0xdc,
-	0xf8, 0x94, 0xe0, 0x10, 0xa0, 0x81, 0x37, 0xa1, 0xb7, 0x5a, 0x52, 0x65, 0xfc, 0x8d, 0x74, 0x9a,
-	0x30, 0xab, 0x6a, 0x7c, 0x01, 0xa6, 0x8c, 0xb3, 0x62, 0xa4, 0x99, 0xfb, 0xd1, 0x59, 0xf6, 0xbc,
-	0x7b, 0x20, 0xc2, 0xde, 0x7e, 0x4c, 0xb7, 0x9f, 0xaa, 0xc3, 0x5e, 0x6e, 0xbe, 0xe3, 0xf7, 0x62,
-	0x55, 0x1f, 0xc4, 0x8a, 0xa0, 0x1d, 0xb2, 0x5d, 0xd5, 0x2c, 0xd6, 0xe6, 0x0d, 0x9e, 0xd1, 0x1f,
-	0xa7, 0x11, 0xf9, 0x79, 0x1a, 0x91, 0xdf, 0xa7, 0x11, 0xf9, 0xfe, 0x67, 0xd4, 0xbb, 0x6d, 0x7f,
-	0xc2, 0x57, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x0e, 0xdf, 0x9b, 0x65, 0x9a, 0x02, 0x00, 0x00,
+	// 465 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x52, 0xdd, 0x8a, 0xd3, 0x40,
+	0x14, 0xee, 0x34, 0x69, 0x7e, 0x4e, 0xb2, 0x75, 0x38, 0x88, 0x0e, 0xbb, 0x50, 0x42, 0x41, 0x08,
+	0x08, 0x05, 0xeb, 0xbd, 0x90, 0xd2, 0xb4, 0x04, 0x62, 0xb3, 0x4c, 0xd3, 0x15, 0xaf, 0x4a, 0xb6,
+	0x4d, 0x35, 0x98, 0x9a, 0xb0, 0x49, 0x0b, 0xeb, 0xa5, 0x4f, 0xe1, 0x23, 0x79, 0xe9, 0x23, 0x48,
+	0x7d, 0x03, 0x9f, 0x40, 0x66, 0x12, 0xeb, 0xba, 0x57, 0xf3, 0x7d, 0xe7, 0xcc, 0x77, 0xe6, 0xcc,
+	0x77, 0x0e, 0x58, 0xf5, 0x7d, 0x99, 0x56, 0xa3, 0xf2, 0xae, 0xa8, 0x0b, 0xd4, 0xe4, 0x51, 0x5d,
+	0xda, 0xbb, 0x64, 0x93, 0xd6, 0x6d, 0x74, 0xf8, 0x5b, 0x01, 0xfd, 0xba, 0xa8, 0xea, 0xec, 0xf3,
+	0x07, 0xa4, 0xa0, 0x1c, 0xb2, 0x2d, 0x23, 0x0e, 0x71, 0x35, 0x2e, 0x20, 0x3e, 0x85, 0xde, 0x31,
+	0xc9, 0x0f, 0x29, 0xeb, 0x3a, 0xc4, 0xb5, 0x79, 0x43, 0x70, 0x0c, 0xc6, 0x31, 0xc9, 0xd7, 0xa2,
+	0x38, 0x53, 0x1c, 0xe2, 0xf6, 0xc7, 0xcf, 0x9b, 0x6a, 0xd5, 0xa8, 0x2d, 0x35, 0xba, 0x49, 0xf2,
+	0xf8, 0xbe, 0x4c, 0xb9, 0x7e, 0x6c, 0x00, 0xbe, 0x01, 0xbb, 0x6c, 0x72, 0x8d, 0x4e, 0x95, 0xba,
+	0xab, 0xc7, 0xba, 0xf6, 0x94, 0x5a, 0xab, 0xfc, 0x47, 0xf0, 0x12, 0x8c, 0x7d, 0x5a, 0x27, 0xdb,
+	0xa4, 0x4e, 0x58, 0x4f, 0x36, 0x73, 0xe6, 0xa2, 0xcb, 0x3c, 0xb9, 0x4d, 0x73, 0xa6, 0x39, 0xc4,
+	0x35, 0x79, 0x43, 0xf0, 0x19, 0x68, 0x9b, 0x62, 0xbf, 0xcf, 0x6a, 0xa6, 0x3b, 0xc4, 0x55, 0x79,
+	0xcb, 0xf0, 0x05, 0x68, 0x8d, 0x03, 0xcc, 0x70, 0x14, 0xd7, 0x1a, 0x5f, 0xfc, 0xed, 0x61, 0x26,
+	0xa2, 0xbc, 0x4d, 0xe2, 0x15, 0x98, 0xf2, 0xb7, 0xeb, 0xbb, 0x74, 0xc7, 0x4c, 0x87, 0xb8, 0x06,
+	0x37, 0x64, 0x80, 0xa7, 0x3b, 0xec, 0x43, 0xb7, 0x28, 0x99, 0xed, 0x10, 0xf7, 0x82, 0x77, 0x8b,
+	0x72, 0xf8, 0x05, 0xf4, 0xf6, 0xc7, 0x68, 0x81, 0x3e, 0xf5, 0x67, 0xde, 0x2a, 0x8c, 0x69, 0x07,
+	0x01, 0xb4, 0x49, 0xb0, 0xf0, 0xf8, 0x7b, 0x4a, 0x50, 0x07, 0x25, 0x58, 0xc4, 0xb4, 0x8b, 0x26,
+	0xf4, 0x66, 0x61, 0xe4, 0xc5, 0x54, 0x41, 0x03, 0xd4, 0x49, 0x14, 0x85, 0x54, 0x45, 0x1b, 0x8c,
+	0xa9, 0x17, 0xfb, 0x71, 0xf0, 0xd6, 0xa7, 0x3d, 0x71, 0x77, 0xee, 0x47, 0x54, 0x13, 0x60, 0x15,
+	0x4c, 0xa9, 0x2e, 0xf2, 0xd7, 0xde, 0x72, 0xf9, 0x2e, 0xe2, 0x53, 0x6a, 0x88, 0xba, 0xcb, 0x98,
+	0x07, 0x8b, 0x39, 0x35, 0x87, 0xaf, 0xc0, 0x7a, 0xe0, 0x9a, 0x50, 0x70, 0x7f, 0x46, 0x3b, 0xe2,
+	0x99, 0x1b, 0x2f, 0x5c, 0xf9, 0x94, 0x60, 0x1f, 0x40, 0xc2, 0x75, 0xe8, 0x2d, 0xe6, 0xb4, 0x3b,
+	0xfc, 0x4a, 0xce, 0x9a, 0x30, 0xab, 0x6a, 0x7c, 0x09, 0x46, 0xeb, 0x75, 0xc5, 0x88, 0x34, 0xe5,
+	0xc9, 0xa3, 0xc1, 0xf0, 0xf3, 0x05, 0x31, 0x89, 0xcd, 0xc7, 0x74, 0xf3, 0xa9, 0x3a, 0xec, 0xdb,
+	0xb5, 0x38, 0xf3, 0x07, 0x9e, 0x2b, 0xff, 0x79, 0x8e, 0xa0, 0x1e, 0xb2, 0x6d, 0x25, 0xa7, 0x6e,
+	0x73, 0x89, 0x27, 0xf4, 0xfb, 0x69, 0x40, 0x7e, 0x9c, 0x06, 0xe4, 0xe7, 0x69, 0x40, 0xbe, 0xfd,
+	0x1a, 0x74, 0x6e, 0x9b, 0x0d, 0x7d, 0xfd, 0x67, 0x00, 0x60, 0x24, 0x09, 0xf7, 0xb7, 0x02, 0x00,
+	0x00,
 }
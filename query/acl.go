@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Permissions on predicates, which access control lists grant to users.
+const (
+	PermRead   = 1 << iota // Querying the predicate.
+	PermWrite              // Setting and deleting its edges.
+	PermModify             // Altering its schema.
+)
+
+var permNames = map[int]string{PermRead: "read", PermWrite: "write", PermModify: "modify"}
+
+// Access returns whether a request has the permission perm on the predicate attr, named as in
+// its namespace. Requests with a nil Access have all permissions. The Access of a request is kept
+// in the context, under "access", for expand() and S * * deletions.
+type Access func(attr string, perm int) bool
+
+// PermissionDeniedError is returned for requests lacking a permission they need.
+type PermissionDeniedError struct {
+	Attr string
+	Perm int
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("Permission denied: can't %s predicate %s", permNames[e.Perm], e.Attr)
+}
+
+func accessOf(ctx context.Context) Access {
+	access, _ := ctx.Value("access").(Access)
+	return access
+}
+
+// can returns whether access has the permission perm on the stored predicate attr.
+func (access Access) can(attr string, perm int) bool {
+	if access == nil {
+		return true
+	}
+	_, name := x.ParseNamespacedAttr(attr)
+	return access(name, perm)
+}
+
+func (access Access) check(attr string, perm int) error {
+	switch attr {
+	case "", "_uid_", "uid", "val", x.Star:
+		return nil
+	}
+	attr = strings.TrimPrefix(attr, "~")
+	if !access(attr, perm) {
+		return x.Wrap(&PermissionDeniedError{Attr: attr, Perm: perm})
+	}
+	return nil
+}
+
+func (access Access) checkFilter(ft *gql.FilterTree) error {
+	if ft == nil {
+		return nil
+	}
+	if ft.Func != nil && ft.Func.Name != "uid" {
+		if err := access.check(ft.Func.Attr, PermRead); err != nil {
+			return err
+		}
+	}
+	for _, ch := range ft.Child {
+		if err := access.checkFilter(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (access Access) checkQuery(gq *gql.GraphQuery) error {
+	if !gq.IsInternal {
+		if err := access.check(gq.Attr, PermRead); err != nil {
+			return err
+		}
+	}
+	if gq.Func != nil && gq.Func.Name != "uid" {
+		if err := access.check(gq.Func.Attr, PermRead); err != nil {
+			return err
+		}
+	}
+	if err := access.checkFilter(gq.Filter); err != nil {
+		return err
+	}
+	for _, key := range []string{"orderasc", "orderdesc"} {
+		if v, ok := gq.Args[key]; ok && !strings.HasPrefix(v, "val(") {
+			if err := access.check(v, PermRead); err != nil {
+				return err
+			}
+		}
+	}
+	for _, attr := range gq.GroupbyAttrs {
+		if err := access.check(attr.Attr, PermRead); err != nil {
+			return err
+		}
+	}
+	for _, ch := range gq.Children {
+		if err := access.checkQuery(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchema returns an error if access can't modify the predicates of updates.
+func (access Access) checkSchema(updates []*protos.SchemaUpdate) error {
+	for _, su := range updates {
+		if err := access.check(su.Predicate, PermModify); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAccess returns an error if qr.Access lacks a permission the request needs, before it's
+// rewritten to its namespace. The predicates expanded by expand() and deleted by S * * are only
+// known once it's run, so those it lacks permissions on are left out then. Its schema mutations
+// are checked once parsed, in prepareMutation.
+func (qr *QueryRequest) checkAccess() error {
+	if err := qr.Access.checkResult(qr.GqlQuery); err != nil {
+		return err
+	}
+	return qr.Access.checkSchema(qr.SchemaUpdate)
+}
+
+func (access Access) checkResult(res *gql.Result) error {
+	for _, gq := range res.Query {
+		if err := access.checkQuery(gq); err != nil {
+			return err
+		}
+	}
+	if res.Mutation != nil {
+		for _, nq := range append(res.Mutation.Set, res.Mutation.Del...) {
+			if err := access.check(nq.Predicate, PermWrite); err != nil {
+				return err
+			}
+		}
+	}
+	if res.Schema != nil {
+		for _, attr := range res.Schema.Predicates {
+			if err := access.check(attr, PermRead); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Predicates returns the predicates which res reads, and those it writes or alters the schema
+// of, sorted. It's given res before it's run, as the predicates are then rewritten to their
+// namespace.
+func Predicates(res *gql.Result) (read, written []string) {
+	perms := make(map[string]int)
+	record := Access(func(attr string, perm int) bool {
+		perms[attr] |= perm
+		return true
+	})
+	record.checkResult(res)
+	if res.Mutation != nil && len(res.Mutation.Schema) > 0 {
+		// Schema errors are returned when it's run.
+		if updates, err := schema.Parse(res.Mutation.Schema); err == nil {
+			record.checkSchema(updates)
+		}
+	}
+	for attr, p := range perms {
+		if p&PermRead != 0 {
+			read = append(read, attr)
+		}
+		if p&(PermWrite|PermModify) != 0 {
+			written = append(written, attr)
+		}
+	}
+	sort.Strings(read)
+	sort.Strings(written)
+	return read, written
+}
+
+// filterNodes injects qr.NodeFilter into every block of the queries of the request which has
+// nodes. It's injected once the permissions of the request are checked, as the predicates of the
+// filter needn't be readable by the user, and before it's rewritten to its namespace.
+func (qr *QueryRequest) filterNodes() error {
+	var inject func(gq *gql.GraphQuery, root bool) error
+	inject = func(gq *gql.GraphQuery, root bool) error {
+		filtered := len(gq.Children) > 0 && gq.Expand == ""
+		if root {
+			// Shortest path blocks get their nodes from their path, which is filtered.
+			filtered = gq.Alias != "shortest"
+		}
+		if filtered {
+			ft, err := gql.ParseFilter(qr.NodeFilter)
+			if err != nil {
+				return x.Wrapf(&InternalError{err: err}, "While injecting node filter")
+			}
+			if gq.Filter != nil {
+				ft = &gql.FilterTree{Op: "and", Child: []*gql.FilterTree{gq.Filter, ft}}
+			}
+			gq.Filter = ft
+		}
+		for _, ch := range gq.Children {
+			if err := inject(ch, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, gq := range qr.GqlQuery.Query {
+		if err := inject(gq, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readableSchema returns the schema of the predicates in nodes which access can read.
+func readableSchema(access Access, nodes []*protos.SchemaNode) []*protos.SchemaNode {
+	if access == nil {
+		return nodes
+	}
+	out := nodes[:0]
+	for _, n := range nodes {
+		if access(n.Predicate, PermRead) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
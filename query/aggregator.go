@@ -25,6 +25,8 @@ import (
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
+
+	geom "github.com/twpayne/go-geom"
 )
 
 type aggregator struct {
@@ -35,7 +37,7 @@ type aggregator struct {
 
 func isUnary(f string) bool {
 	return f == "ln" || f == "exp" || f == "u-" || f == "sqrt" ||
-		f == "floor" || f == "ceil" || f == "since"
+		f == "floor" || f == "ceil" || f == "since" || f == "area" || f == "length"
 }
 
 func isBinaryBoolean(f string) bool {
@@ -173,6 +175,24 @@ func (ag *aggregator) ApplyVal(v types.Val) error {
 				return x.Errorf("Wrong type encountered for func %v", ag.name)
 			}
 			res = v
+		case "area":
+			if v.Tid != types.GeoID {
+				return x.Errorf("Wrong type encountered for func %v", ag.name)
+			}
+			a, err := types.GeoArea(v.Value.(geom.T))
+			if err != nil {
+				return err
+			}
+			res = types.Val{Tid: types.FloatID, Value: float64(a)}
+		case "length":
+			if v.Tid != types.GeoID {
+				return x.Errorf("Wrong type encountered for func %v", ag.name)
+			}
+			l, err := types.GeoLength(v.Value.(geom.T))
+			if err != nil {
+				return err
+			}
+			res = types.Val{Tid: types.FloatID, Value: float64(l)}
 		}
 		ag.result = res
 		return nil
@@ -26,6 +26,8 @@ import (
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
+
+	geom "github.com/twpayne/go-geom"
 )
 
 type groupPair struct {
@@ -54,7 +56,7 @@ func (grp *groupResult) aggregateChild(child *SubGraph) error {
 		return nil
 	}
 	if len(child.SrcFunc) > 0 && isAggregatorFn(child.SrcFunc[0]) {
-		fieldName := fmt.Sprintf("%s(%s)", child.SrcFunc[0], child.Attr)
+		fieldName := fmt.Sprintf("%s(%s)", child.SrcFunc[0], child.outputAttr(child.Attr))
 		finalVal, err := aggregateGroup(grp, child)
 		if err != nil {
 			return err
@@ -67,6 +69,30 @@ func (grp *groupResult) aggregateChild(child *SubGraph) error {
 	return nil
 }
 
+// cellGroup has the geo values of a predicate grouped by cell, to find the centroids of the groups.
+type cellGroup struct {
+	centroidAttr string
+	geoms        map[uint64]geom.T
+}
+
+// addCentroids adds the centroid of the values of the group for each of its keys which is a cell.
+func (grp *groupResult) addCentroids(cells map[string]cellGroup) {
+	for _, key := range grp.keys {
+		cg, ok := cells[key.attr]
+		if !ok {
+			continue
+		}
+		gs := make([]geom.T, 0, len(grp.uids))
+		for _, uid := range grp.uids {
+			gs = append(gs, cg.geoms[uid])
+		}
+		grp.aggregates = append(grp.aggregates, groupPair{
+			attr: cg.centroidAttr,
+			key:  types.Val{Tid: types.GeoID, Value: types.Centroid(gs)},
+		})
+	}
+}
+
 type groupResults struct {
 	group []*groupResult
 }
@@ -196,6 +222,7 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 	_ = mp
 	var dedupMap dedup
 	var pathNode *SubGraph
+	cells := make(map[string]cellGroup)
 	for _, child := range sg.Children {
 		if !child.Params.ignoreResult {
 			continue
@@ -206,10 +233,35 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 				srcUid := child.SrcUIDs.Uids[i]
 				ul := child.uidMatrix[i]
 				for _, uid := range ul.Uids {
-					dedupMap.addValue(child.Attr, types.Val{Tid: types.UidID, Value: uid}, srcUid)
+					dedupMap.addValue(child.outputAttr(child.Attr), types.Val{Tid: types.UidID, Value: uid}, srcUid)
 				}
 			}
 			pathNode = child
+		} else if child.Params.groupbyCell {
+			// It's a geo node, grouped by the cells its values are in.
+			cg := cellGroup{
+				centroidAttr: fmt.Sprintf("centroid(%s)", child.outputAttr(child.Attr)),
+				geoms:        make(map[uint64]geom.T),
+			}
+			cellAttr := fmt.Sprintf("cell(%s)", child.outputAttr(child.Attr))
+			for i, v := range child.valueMatrix {
+				srcUid := child.SrcUIDs.Uids[i]
+				val, err := convertTo(v.Values[0])
+				if err != nil {
+					continue
+				}
+				if val.Tid != types.GeoID {
+					return x.Errorf("Only geo predicates can be grouped by cell. Got: %s of type %s",
+						child.Attr, val.Tid.Name())
+				}
+				g := val.Value.(geom.T)
+				cg.geoms[srcUid] = g
+				dedupMap.addValue(cellAttr, types.Val{
+					Tid:   types.StringID,
+					Value: types.GeoCell(g, child.Params.cellLevel),
+				}, srcUid)
+			}
+			cells[cellAttr] = cg
 		} else {
 			// It's a value node.
 			for i, v := range child.valueMatrix {
@@ -218,7 +270,7 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 				if err != nil {
 					continue
 				}
-				dedupMap.addValue(child.Attr, val, srcUid)
+				dedupMap.addValue(child.outputAttr(child.Attr), val, srcUid)
 			}
 		}
 	}
@@ -226,6 +278,9 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 	// Create all the groups here.
 	res := new(groupResults)
 	res.formGroups(dedupMap, &protos.List{}, []groupPair{})
+	for _, grp := range res.group {
+		grp.addCentroids(cells)
+	}
 
 	// Go over the groups and aggregate the values.
 	for _, child := range sg.Children {
@@ -1,7 +1,10 @@
 package query
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -93,7 +96,7 @@ func processBinary(mNode *mathTree) (err error) {
 }
 
 // processUnary handles the unary operands like
-// u-, log, exp, since, floor, ceil
+// u-, log, exp, since, floor, ceil, area, length
 func processUnary(mNode *mathTree) (err error) {
 	destMap := make(map[uint64]types.Val)
 	srcMap := mNode.Child[0].Val
@@ -195,6 +198,56 @@ func processTernary(mNode *mathTree) (err error) {
 	return nil
 }
 
+// processScalar calls the user defined scalar function of mNode, for the uids which have values
+// for all of its variables.
+func processScalar(mNode *mathTree) error {
+	var uids map[uint64]types.Val
+	for _, ch := range mNode.Child {
+		if ch.Const.Value == nil && (uids == nil || len(ch.Val) < len(uids)) {
+			uids = ch.Val
+		}
+	}
+	args := func(k uint64) ([]types.Val, bool) {
+		vals := make([]types.Val, 0, len(mNode.Child))
+		for _, ch := range mNode.Child {
+			if ch.Const.Value != nil {
+				vals = append(vals, ch.Const)
+				continue
+			}
+			v, ok := ch.Val[k]
+			if !ok {
+				return nil, false
+			}
+			vals = append(vals, v)
+		}
+		return vals, true
+	}
+
+	// Math blocks aren't run with the context of the query, so calls are only limited by udf.
+	ctx := context.Background()
+	if uids == nil {
+		// All the arguments are constants.
+		vals, _ := args(0)
+		var err error
+		mNode.Const, err = udf.CallScalar(ctx, mNode.Fn, vals)
+		return err
+	}
+	destMap := make(map[uint64]types.Val)
+	for k := range uids {
+		vals, ok := args(k)
+		if !ok {
+			continue
+		}
+		val, err := udf.CallScalar(ctx, mNode.Fn, vals)
+		if err != nil {
+			return err
+		}
+		destMap[k] = val
+	}
+	mNode.Val = destMap
+	return nil
+}
+
 func evalMathTree(mNode *mathTree) (err error) {
 	if mNode.Const.Value != nil {
 		return nil
@@ -216,6 +269,14 @@ func evalMathTree(mNode *mathTree) (err error) {
 	}
 
 	aggName := mNode.Fn
+	if arity := udf.Arity(aggName); arity > 0 {
+		if len(mNode.Child) != arity {
+			return x.Errorf("Function %v expects %d arguments. But got: %v", aggName, arity,
+				len(mNode.Child))
+		}
+		return processScalar(mNode)
+	}
+
 	if isUnary(aggName) {
 		if len(mNode.Child) != 1 {
 			return x.Errorf("Function %v expects 1 argument. But got: %v", aggName,
@@ -40,7 +40,46 @@ func ApplyMutations(ctx context.Context, m *protos.Mutations) error {
 	return nil
 }
 
+// addOnDeleteEdges adds to m the deletions of nodes and edges called for by the @ondelete actions of
+// the predicates of the nodes it deletes with S * *.
+func addOnDeleteEdges(ctx context.Context, m *protos.Mutations) error {
+	var uids []uint64
+	seen := make(map[uint64]bool)
+	for _, mu := range m.Edges {
+		if mu.Op == protos.DirectedEdge_DEL && mu.Attr == x.Star && !seen[mu.Entity] {
+			seen[mu.Entity] = true
+			uids = append(uids, mu.Entity)
+		}
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+	// Like S * *, the actions only apply to the predicates which can be written.
+	ns, access := namespaceOf(ctx), accessOf(ctx)
+	deleted, edges, err := worker.OnDelete(ctx, uids, func(attr string) bool {
+		return belongsTo(attr, ns) && access.can(attr, PermWrite)
+	})
+	if err != nil {
+		return err
+	}
+	for _, uid := range deleted {
+		if !seen[uid] {
+			m.Edges = append(m.Edges, &protos.DirectedEdge{
+				Op:     protos.DirectedEdge_DEL,
+				Entity: uid,
+				Attr:   x.Star,
+				Value:  []byte(x.Star),
+			})
+		}
+	}
+	m.Edges = append(m.Edges, edges...)
+	return nil
+}
+
 func addInternalEdge(ctx context.Context, m *protos.Mutations) error {
+	if err := addOnDeleteEdges(ctx, m); err != nil {
+		return err
+	}
 	newEdges := make([]*protos.DirectedEdge, 0, 2*len(m.Edges))
 	for _, mu := range m.Edges {
 		x.AssertTrue(mu.Op == protos.DirectedEdge_DEL || mu.Op == protos.DirectedEdge_SET)
@@ -62,14 +101,40 @@ func addInternalEdge(ctx context.Context, m *protos.Mutations) error {
 					return err
 				}
 				val := mu.GetValue()
+				ns, access := namespaceOf(ctx), accessOf(ctx)
+				// Unless all of the predicates are deleted, the _predicate_ values of those
+				// deleted are deleted one by one.
+				some := ns != "" || access != nil
+				var attrs []string
 				for _, pred := range preds {
+					for _, v := range pred.Values {
+						attrs = append(attrs, string(v.Val))
+					}
+				}
+				for _, attr := range attrs {
+					if !belongsTo(attr, ns) || !access.can(attr, PermWrite) {
+						continue
+					}
 					edge := &protos.DirectedEdge{
 						Op:     protos.DirectedEdge_DEL,
 						Entity: mu.GetEntity(),
-						Attr:   string(pred.Values[0].Val),
+						Attr:   attr,
 						Value:  val,
 					}
 					newEdges = append(newEdges, edge)
+					if some {
+						// The predicates of other namespaces, or which can't be written, are
+						// kept, and so is their _predicate_ value.
+						newEdges = append(newEdges, &protos.DirectedEdge{
+							Op:     protos.DirectedEdge_DEL,
+							Entity: mu.GetEntity(),
+							Attr:   "_predicate_",
+							Value:  []byte(attr),
+						})
+					}
+				}
+				if some {
+					continue
 				}
 				edge := &protos.DirectedEdge{
 					Op:     protos.DirectedEdge_DEL,
@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A request run in a namespace only reads and writes the predicates of the namespace. They're
+// stored under x.NamespacedAttr names, which the request is rewritten to, and output under their
+// names within the namespace. The namespace is kept in the context, under "namespace", for
+// expand(), S * * deletions, and the output.
+
+func namespaceOf(ctx context.Context) string {
+	ns, _ := ctx.Value("namespace").(string)
+	return ns
+}
+
+func inNamespace(ns, attr string) string {
+	switch attr {
+	case "", "_uid_", "uid", "val", x.Star:
+		return attr
+	}
+	return x.NamespacedAttr(ns, attr)
+}
+
+// outputAttr returns the name attr is output under, in the namespace of sg.
+func (sg *SubGraph) outputAttr(attr string) string {
+	if sg.Params.namespace == "" {
+		return attr
+	}
+	_, name := x.ParseNamespacedAttr(attr)
+	return name
+}
+
+// belongsTo returns whether the stored predicate attr can be seen from namespace ns.
+func belongsTo(attr, ns string) bool {
+	if ns == "" {
+		return true
+	}
+	attrNs, _ := x.ParseNamespacedAttr(attr)
+	return attrNs == ns
+}
+
+func namespaceFunc(ns string, f *gql.Function) {
+	if f != nil && f.Name != "uid" {
+		f.Attr = inNamespace(ns, f.Attr)
+	}
+}
+
+func namespaceFilter(ns string, ft *gql.FilterTree) {
+	if ft == nil {
+		return
+	}
+	namespaceFunc(ns, ft.Func)
+	for _, ch := range ft.Child {
+		namespaceFilter(ns, ch)
+	}
+}
+
+func namespaceQuery(ns string, gq *gql.GraphQuery) error {
+	if gq.Attr == "_predicate_" {
+		return x.Errorf("_predicate_ can't be queried in a namespace")
+	}
+	if !gq.IsInternal {
+		gq.Attr = inNamespace(ns, gq.Attr)
+	}
+	namespaceFunc(ns, gq.Func)
+	namespaceFilter(ns, gq.Filter)
+	for _, key := range []string{"orderasc", "orderdesc"} {
+		if v, ok := gq.Args[key]; ok && v != "val" && !strings.HasPrefix(v, "val(") {
+			gq.Args[key] = inNamespace(ns, v)
+		}
+	}
+	for i := range gq.GroupbyAttrs {
+		gq.GroupbyAttrs[i].Attr = inNamespace(ns, gq.GroupbyAttrs[i].Attr)
+	}
+	for _, ch := range gq.Children {
+		if err := namespaceQuery(ns, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inNamespace rewrites the predicates of the request to those of qr.Namespace.
+func (qr *QueryRequest) inNamespace() error {
+	ns, res := qr.Namespace, qr.GqlQuery
+	for _, gq := range res.Query {
+		if err := namespaceQuery(ns, gq); err != nil {
+			return x.Wrap(&InvalidRequestError{err: err})
+		}
+	}
+	if res.Mutation != nil {
+		for _, nq := range append(res.Mutation.Set, res.Mutation.Del...) {
+			if nq.Predicate == "_predicate_" {
+				return x.Wrap(&InvalidRequestError{
+					err: x.Errorf("_predicate_ can't be mutated in a namespace")})
+			}
+			nq.Predicate = inNamespace(ns, nq.Predicate)
+		}
+	}
+	if res.Schema != nil {
+		for i, attr := range res.Schema.Predicates {
+			res.Schema.Predicates[i] = inNamespace(ns, attr)
+		}
+	}
+	// The schema of gRPC requests comes parsed. That of queries is rewritten once parsed, in
+	// prepareMutation.
+	for _, su := range qr.SchemaUpdate {
+		su.Predicate = x.NamespacedAttr(ns, su.Predicate)
+	}
+	return nil
+}
+
+// schemaInNamespace returns the schema of the predicates of namespace ns in nodes, under their
+// names in the namespace.
+func schemaInNamespace(ns string, nodes []*protos.SchemaNode) []*protos.SchemaNode {
+	if ns == "" {
+		return nodes
+	}
+	out := nodes[:0]
+	for _, n := range nodes {
+		if attrNs, name := x.ParseNamespacedAttr(n.Predicate); attrNs == ns {
+			n.Predicate = name
+			out = append(out, n)
+		}
+	}
+	return out
+}
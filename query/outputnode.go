@@ -28,11 +28,11 @@ import (
 	"time"
 
 	geom "github.com/twpayne/go-geom"
-	"github.com/twpayne/go-geom/encoding/geojson"
 
 	"github.com/dgraph-io/dgraph/algo"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -57,9 +57,36 @@ func ToProtocolBuf(l *Latency, sgl []*SubGraph) ([]*protos.Node, error) {
 	return resNode, nil
 }
 
+// StreamProtocolBuf hands the result of each query block to fn like ToProtocolBuf, but in parts,
+// each of a _root_ node with the next results of the block, of about maxSize bytes. Each part is
+// handed to fn as it's built, so the whole result never has to be held as protocol buffers.
+func StreamProtocolBuf(l *Latency, sgl []*SubGraph, maxSize int,
+	fn func(*protos.Node) error) error {
+	for _, sg := range sgl {
+		if sg.Params.Alias == "var" || sg.Params.Alias == "shortest" {
+			continue
+		}
+		if err := sg.streamProtocolBuffer(l, maxSize, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ToJson converts the list of subgraph into a JSON response by calling toFastJSON.
 func ToJson(l *Latency, sgl []*SubGraph, w io.Writer, allocIds map[string]string,
 	addLatency bool) error {
+	var ext *Extensions
+	if addLatency {
+		ext = &Extensions{Latency: l.ToMap()}
+	}
+	return ToJsonWithExtensions(sgl, w, allocIds, ext)
+}
+
+// ToJsonWithExtensions converts the list of subgraph into a JSON response like ToJson, with ext
+// under the extensions key, if it isn't nil.
+func ToJsonWithExtensions(sgl []*SubGraph, w io.Writer, allocIds map[string]string,
+	ext *Extensions) error {
 	sgr := &SubGraph{}
 	for _, sg := range sgl {
 		if sg.Params.Alias == "var" || sg.Params.Alias == "shortest" {
@@ -70,7 +97,69 @@ func ToJson(l *Latency, sgl []*SubGraph, w io.Writer, allocIds map[string]string
 		}
 		sgr.Children = append(sgr.Children, sg)
 	}
-	return sgr.toFastJSON(l, w, allocIds, addLatency)
+	return sgr.toFastJSON(w, allocIds, ext)
+}
+
+// Table is the result of a query block flattened into rows, for tabular consumers. Columns are
+// the paths of the scalar values, like friend.name, and nested results are joined with the ones
+// they are nested in, as @normalize does. Values missing from a row are null.
+type Table struct {
+	Columns []string            `json:"columns"`
+	Rows    [][]json.RawMessage `json:"rows"`
+}
+
+// ToRows converts the list of subgraph into a Table per query block, by alias.
+func ToRows(sgl []*SubGraph) (map[string]*Table, error) {
+	var seedNode *fastJsonNode
+	n := seedNode.New("_root_").(*fastJsonNode)
+	for _, sg := range sgl {
+		if sg.Params.Alias == "var" || sg.Params.Alias == "shortest" {
+			continue
+		}
+		if err := processNodeUids(n, sg); err != nil {
+			return nil, err
+		}
+	}
+
+	tables := make(map[string]*Table)
+	for _, a := range n.attrs {
+		t, ok := tables[a.attr]
+		if !ok {
+			t = &Table{Columns: []string{}, Rows: [][]json.RawMessage{}}
+			tables[a.attr] = t
+		}
+		rows, err := a.flatten("")
+		if err != nil {
+			return nil, err
+		}
+		t.addRows(rows)
+	}
+	return tables, nil
+}
+
+func (t *Table) addRows(rows [][]*fastJsonNode) {
+	for _, row := range rows {
+		vals := make([]json.RawMessage, len(t.Columns))
+		for _, v := range row {
+			i := 0
+			for i < len(t.Columns) && t.Columns[i] != v.attr {
+				i++
+			}
+			if i == len(t.Columns) {
+				t.Columns = append(t.Columns, v.attr)
+				vals = append(vals, nil)
+			}
+			vals[i] = json.RawMessage(v.scalarVal)
+		}
+		t.Rows = append(t.Rows, vals)
+	}
+	// Rows added before a column was found are shorter than the others.
+	for i, r := range t.Rows {
+		for len(r) < len(t.Columns) {
+			r = append(r, nil)
+		}
+		t.Rows[i] = r
+	}
 }
 
 // outputNode is the generic output / writer for preTraverse.
@@ -273,24 +362,53 @@ func (n *protoNode) addAggregations(sg *SubGraph) error {
 // used postorder traversal before, but preorder seems simpler and faster for
 // most cases.
 func (sg *SubGraph) ToProtocolBuffer(l *Latency) (*protos.Node, error) {
+	var res *protos.Node
+	err := sg.streamProtocolBuffer(l, 0, func(n *protos.Node) error {
+		res = n
+		return nil
+	})
+	return res, err
+}
+
+// streamProtocolBuffer builds the proto buffer like ToProtocolBuffer, but hands it to fn in parts,
+// each a _root_ node with the next results, once they reach maxSize bytes. A maxSize of 0 builds
+// a single part.
+func (sg *SubGraph) streamProtocolBuffer(l *Latency, maxSize int,
+	fn func(*protos.Node) error) error {
 	var seedNode *protoNode
 	n := seedNode.New("_root_")
 	if sg.Params.IsEmpty {
 		n1 := seedNode.New(sg.Params.Alias)
 		if err := n1.(*protoNode).addAggregations(sg); err != nil {
-			return n.(*protoNode).Node, err
+			return err
 		}
 		n.AddListChild(sg.Params.Alias, n1)
-		return n.(*protoNode).Node, nil
+		return fn(n.(*protoNode).Node)
 	}
 	if sg.uidMatrix == nil {
-		return seedNode.New(sg.Params.Alias).(*protoNode).Node, nil
+		return fn(seedNode.New(sg.Params.Alias).(*protoNode).Node)
 	}
 
 	if sg.Params.uidCount != "" {
 		n.addCountAtRoot(sg)
 	}
 
+	var size, parts int
+	add := func(child outputNode) error {
+		n.AddListChild(sg.Params.Alias, child)
+		if maxSize == 0 {
+			return nil
+		}
+		if size += child.(*protoNode).Size(); size < maxSize {
+			return nil
+		}
+		if err := fn(n.(*protoNode).Node); err != nil {
+			return err
+		}
+		n, size = seedNode.New("_root_"), 0
+		parts++
+		return nil
+	}
 	if sg.Params.isGroupBy {
 		n.addGroupby(sg, sg.Params.Alias)
 	} else {
@@ -306,28 +424,36 @@ func (sg *SubGraph) ToProtocolBuffer(l *Latency) (*protos.Node, error) {
 				if rerr.Error() == "_INV_" {
 					continue
 				}
-				return n.(*protoNode).Node, rerr
+				return rerr
 			}
 			if n1.IsEmpty() {
 				continue
 			}
 			if !sg.Params.Normalize {
-				n.AddListChild(sg.Params.Alias, n1)
+				if err := add(n1); err != nil {
+					return err
+				}
 				continue
 			}
 
 			// Lets normalize the response now.
 			normalized, err := n1.(*protoNode).normalize()
 			if err != nil {
-				return nil, err
+				return err
 			}
 			for _, c := range normalized {
-				n.AddListChild(sg.Params.Alias, &protoNode{&protos.Node{Properties: c}})
+				if err := add(&protoNode{&protos.Node{Properties: c}}); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	l.ProtocolBuffer = time.Since(l.Start) - l.Parsing - l.Processing
-	return n.(*protoNode).Node, nil
+	// The last part is empty if the results ended with a full part.
+	if parts > 0 && n.IsEmpty() {
+		return nil
+	}
+	return fn(n.(*protoNode).Node)
 }
 
 func makeScalarNode(attr string, isChild bool, val []byte) *fastJsonNode {
@@ -426,7 +552,7 @@ func valToBytes(v types.Val) ([]byte, error) {
 	case types.DateTimeID:
 		return v.Value.(time.Time).MarshalJSON()
 	case types.GeoID:
-		return geojson.Marshal(v.Value.(geom.T))
+		return types.MarshalGeoJSON(v.Value.(geom.T))
 	case types.UidID:
 		return []byte(fmt.Sprintf("\"%#x\"", v.Value)), nil
 	case types.PasswordID:
@@ -627,6 +753,43 @@ func (n *fastJsonNode) normalize() ([][]*fastJsonNode, error) {
 	return parentSlice, nil
 }
 
+// flatten returns the rows of the scalar values of n and of the nodes nested in it, with their
+// attributes prefixed by their path from n.
+func (n *fastJsonNode) flatten(prefix string) ([][]*fastJsonNode, error) {
+	var scalars []*fastJsonNode
+	for _, a := range n.attrs {
+		if len(a.attrs) == 0 {
+			scalars = append(scalars, makeScalarNode(prefix+a.attr, false, a.scalarVal))
+		}
+	}
+	parentSlice := [][]*fastJsonNode{scalars}
+
+	// Nested nodes with the same attribute follow each other, as in normalize.
+	for ci := 0; ci < len(n.attrs); {
+		childNode := n.attrs[ci]
+		if len(childNode.attrs) == 0 {
+			ci++
+			continue
+		}
+		childSlice := make([][]*fastJsonNode, 0, 5)
+		for ci < len(n.attrs) && childNode.attr == n.attrs[ci].attr {
+			if len(n.attrs[ci].attrs) > 0 {
+				flat, err := n.attrs[ci].flatten(prefix + childNode.attr + ".")
+				if err != nil {
+					return nil, err
+				}
+				childSlice = append(childSlice, flat...)
+			}
+			ci++
+		}
+		var err error
+		if parentSlice, err = merge(parentSlice, childSlice); err != nil {
+			return nil, err
+		}
+	}
+	return parentSlice, nil
+}
+
 type attrVal struct {
 	attr string
 	val  *fastJsonNode
@@ -727,10 +890,13 @@ func processNodeUids(n *fastJsonNode, sg *SubGraph) error {
 }
 
 type Extensions struct {
-	Latency map[string]string `json:"server_latency"`
+	Latency      map[string]string     `json:"server_latency,omitempty"`
+	Deprecations []*protos.Deprecation `json:"deprecations,omitempty"`
+	// Resources is what the tasks of the query used, for debug queries.
+	Resources *worker.Resources `json:"resources,omitempty"`
 }
 
-func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]string, addLatency bool) error {
+func (sg *SubGraph) toFastJSON(w io.Writer, allocIds map[string]string, ext *Extensions) error {
 	var seedNode *fastJsonNode
 	var err error
 	n := seedNode.New("_root_")
@@ -752,11 +918,8 @@ func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]stri
 	}
 
 	var lb []byte
-	if addLatency {
-		e := Extensions{
-			Latency: l.ToMap(),
-		}
-		if lb, err = json.Marshal(e); err != nil {
+	if ext != nil {
+		if lb, err = json.Marshal(ext); err != nil {
 			return err
 		}
 	}
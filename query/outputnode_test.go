@@ -19,6 +19,7 @@ package query
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"runtime"
@@ -190,3 +191,32 @@ func TestNormalizeJSONUid2(t *testing.T) {
 	buf.Flush()
 	require.Equal(t, `{"alias":[{"___attr1":"","___attr2":"","_uid_":"0x3","attr3":""}]}`, b.String())
 }
+
+func TestFlattenRows(t *testing.T) {
+	str := func(s string) types.Val {
+		v := types.ValueForType(types.StringID)
+		v.Value = s
+		return v
+	}
+	n := (&fastJsonNode{}).New("me")
+	n.AddValue("name", str("Alice"))
+	for _, name := range []string{"Bob", "Eve"} {
+		friend := n.New("friend")
+		friend.AddValue("name", str(name))
+		if name == "Bob" {
+			pet := friend.New("pet")
+			pet.AddValue("name", str("Rex"))
+			friend.AddListChild("pet", pet)
+		}
+		n.AddListChild("friend", friend)
+	}
+
+	rows, err := n.(*fastJsonNode).flatten("")
+	require.NoError(t, err)
+	table := &Table{}
+	table.addRows(rows)
+	b, err := json.Marshal(table)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"columns": ["name", "friend.name", "friend.pet.name"],
+		"rows": [["Alice", "Bob", "Rex"], ["Alice", "Eve", null]]}`, string(b))
+}
@@ -32,13 +32,17 @@ import (
 
 	"google.golang.org/grpc/metadata"
 
+	geom "github.com/twpayne/go-geom"
+
 	"github.com/dgraph-io/dgraph/algo"
 	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/task"
+	"github.com/dgraph-io/dgraph/tracing"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -148,12 +152,19 @@ type params struct {
 	isInternal     bool   // Determines if processTask has to be called or not.
 	ignoreResult   bool   // Node results are ignored.
 	Expand         string // Var to use for expand.
+	expandType     bool   // Whether Expand is the name of a type.
+	byDistance     bool   // The uids of the root are ordered by distance, by nearest.
+	isDistance     bool   // The node is a near function, giving the distances as a variable.
 	isGroupBy      bool
 	groupbyAttrs   []gql.AttrLang
+	groupbyCell    bool // Geo values are grouped by the S2 cell of level cellLevel they are in.
+	cellLevel      int
 	uidCount       string
 	numPaths       int
 	parentIds      []uint64 // This is a stack that is maintained and passed down to children.
 	IsEmpty        bool     // Won't have any SrcUids or DestUids. Only used to get aggregated vars
+	namespace      string   // Namespace the predicates are output in.
+	access         Access   // Permissions on the predicates to expand.
 }
 
 // SubGraph is the way to represent data internally. It contains both the
@@ -192,6 +203,25 @@ func (sg *SubGraph) IsInternal() bool {
 	return sg.Params.isInternal
 }
 
+// ReachedUids returns the uids of all the nodes reached by the queries in sgs, at their roots and
+// through their children, in sorted order.
+func ReachedUids(sgs []*SubGraph) *protos.List {
+	var lists []*protos.List
+	var walk func(sg *SubGraph)
+	walk = func(sg *SubGraph) {
+		if sg.DestUIDs != nil {
+			lists = append(lists, sg.DestUIDs)
+		}
+		for _, child := range sg.Children {
+			walk(child)
+		}
+	}
+	for _, sg := range sgs {
+		walk(sg)
+	}
+	return algo.MergeSorted(lists)
+}
+
 // DebugPrint prints out the SubGraph tree in a nice format for debugging purposes.
 func (sg *SubGraph) DebugPrint(prefix string) {
 	var src, dst int
@@ -285,7 +315,7 @@ func (sg *SubGraph) isSimilar(ssg *SubGraph) bool {
 }
 
 func (sg *SubGraph) fieldName() string {
-	fieldName := sg.Attr
+	fieldName := sg.outputAttr(sg.Attr)
 	if sg.Params.Alias != "" {
 		fieldName = sg.Params.Alias
 	}
@@ -295,7 +325,7 @@ func (sg *SubGraph) fieldName() string {
 func addCount(pc *SubGraph, count uint64, dst outputNode) {
 	c := types.ValueForType(types.IntID)
 	c.Value = int64(count)
-	fieldName := fmt.Sprintf("count(%s)", pc.Attr)
+	fieldName := fmt.Sprintf("count(%s)", pc.outputAttr(pc.Attr))
 	if pc.Params.Alias != "" {
 		fieldName = pc.Params.Alias
 	}
@@ -335,9 +365,10 @@ func addInternalNode(pc *SubGraph, uid uint64, dst outputNode) error {
 func addCheckPwd(pc *SubGraph, val *protos.TaskValue, dst outputNode) {
 	c := types.ValueForType(types.BoolID)
 	c.Value = task.ToBool(val)
-	uc := dst.New(pc.Attr)
+	attr := pc.outputAttr(pc.Attr)
+	uc := dst.New(attr)
 	uc.AddValue("checkpwd", c)
-	dst.AddListChild(pc.Attr, uc)
+	dst.AddListChild(attr, uc)
 }
 
 func alreadySeen(parentIds []uint64, uid uint64) bool {
@@ -673,8 +704,11 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 			GetUid:         sg.Params.GetUid,
 			Var:            gchild.Var,
 			Normalize:      sg.Params.Normalize,
+			namespace:      sg.Params.namespace,
+			access:         sg.Params.access,
 			isInternal:     gchild.IsInternal,
 			Expand:         gchild.Expand,
+			expandType:     gchild.ExpandType,
 			isGroupBy:      gchild.IsGroupby,
 			groupbyAttrs:   gchild.GroupbyAttrs,
 			FacetVar:       gchild.FacetVar,
@@ -741,6 +775,16 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 			dst.SrcFunc = append(dst.SrcFunc, gchild.Func.Lang)
 			dst.SrcFunc = append(dst.SrcFunc, gchild.Func.Args...)
 		}
+		if gchild.Func != nil && gchild.Func.IsDistance() {
+			if len(gchild.Children) != 0 || gchild.Filter != nil {
+				return x.Errorf("Node with %q can't have child attributes or filters",
+					gchild.Func.Name)
+			}
+			dst.Params.isDistance = true
+			dst.Params.ignoreResult = true
+			dst.SrcFunc = append(dst.SrcFunc, gchild.Func.Name, gchild.Func.Lang)
+			dst.SrcFunc = append(dst.SrcFunc, gchild.Func.Args...)
+		}
 
 		if gchild.Filter != nil {
 			dstf := &SubGraph{}
@@ -876,6 +920,8 @@ func newGraph(ctx context.Context, gq *gql.GraphQuery) (*SubGraph, error) {
 		uidCount:     gq.UidCount,
 		IgnoreReflex: gq.IgnoreReflex,
 		IsEmpty:      gq.IsEmpty,
+		namespace:    namespaceOf(ctx),
+		access:       accessOf(ctx),
 	}
 	if gq.Facets != nil {
 		args.Facet = &protos.Param{gq.Facets.AllKeys, gq.Facets.Keys}
@@ -1291,7 +1337,7 @@ func (sg *SubGraph) updateFacetMatrix() {
 func (sg *SubGraph) updateUidMatrix() {
 	sg.updateFacetMatrix()
 	for _, l := range sg.uidMatrix {
-		if sg.Params.Order != "" {
+		if sg.Params.Order != "" || sg.Params.byDistance {
 			// We can't do intersection directly as the list is not sorted by UIDs.
 			// So do filter.
 			algo.ApplyFilter(l, func(uid uint64, idx int) bool {
@@ -1397,6 +1443,19 @@ func (sg *SubGraph) populateUidValVar(doneVars map[string]varValue, sgPath []*Su
 			strList: sg.valueMatrix,
 			path:    sgPath,
 		}
+	} else if sg.Params.isDistance {
+		// The distances of the nodes matched by near.
+		doneVars[sg.Params.Var] = varValue{
+			Vals: make(map[uint64]types.Val),
+			path: sgPath,
+		}
+		for idx, uid := range sg.SrcUIDs.Uids {
+			val, err := convertTo(sg.valueMatrix[idx].Values[0])
+			if err != nil {
+				continue
+			}
+			doneVars[sg.Params.Var].Vals[uid] = val
+		}
 	} else if len(sg.counts) > 0 {
 		// This implies it is a value variable.
 		doneVars[sg.Params.Var] = varValue{
@@ -1581,6 +1640,60 @@ func (sg *SubGraph) ApplyIneqFunc() error {
 	return nil
 }
 
+// isGeoJoin returns if sg is a geo function taking the geometries of a value variable, like
+// within(loc, val(a)), instead of a literal one.
+func (sg *SubGraph) isGeoJoin() bool {
+	if len(sg.SrcFunc) < 3 || !types.IsGeoFunc(sg.SrcFunc[0]) {
+		return false
+	}
+	for _, v := range sg.Params.NeedsVar {
+		if v.Typ == gql.VALUE_VAR && v.Name == sg.SrcFunc[2] {
+			return true
+		}
+	}
+	return false
+}
+
+// geoJoin runs the geo function of sg once for each distinct geometry of its value variable, each
+// looking up the index and filtering the values found for it, and returns the nodes matching any
+// of them.
+func (sg *SubGraph) geoJoin(ctx context.Context) (*protos.Result, error) {
+	uids := make([]uint64, 0, len(sg.Params.uidToVal))
+	for uid := range sg.Params.uidToVal {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	res := &protos.Result{}
+	seen := make(map[string]bool)
+	for _, uid := range uids {
+		g, ok := sg.Params.uidToVal[uid].Value.(geom.T)
+		if !ok {
+			return nil, x.Errorf("Value variable %s of function %s should have geometries",
+				sg.SrcFunc[2], sg.SrcFunc[0])
+		}
+		arg, err := types.MarshalGeoJSON(g)
+		if err != nil {
+			return nil, err
+		}
+		if seen[string(arg)] {
+			continue
+		}
+		seen[string(arg)] = true
+
+		q := createTaskQuery(sg)
+		q.SrcFunc = append([]string{}, q.SrcFunc...)
+		q.SrcFunc[2] = string(arg)
+		r, err := worker.ProcessTaskOverNetwork(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		// Like the lists of the tokens of a single geometry, those of each one are merged.
+		res.UidMatrix = append(res.UidMatrix, r.UidMatrix...)
+	}
+	return res, nil
+}
+
 func (sg *SubGraph) appendDummyValues() {
 	if sg.SrcUIDs == nil {
 		return
@@ -1660,8 +1773,13 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 				return
 			}
 		} else {
-			taskQuery := createTaskQuery(sg)
-			result, err := worker.ProcessTaskOverNetwork(ctx, taskQuery)
+			var result *protos.Result
+			var err error
+			if sg.isGeoJoin() {
+				result, err = sg.geoJoin(ctx)
+			} else {
+				result, err = worker.ProcessTaskOverNetwork(ctx, createTaskQuery(sg))
+			}
 			if err != nil {
 				if tr, ok := trace.FromContext(ctx); ok {
 					tr.LazyPrintf("Error while processing task: %+v", err)
@@ -1674,6 +1792,9 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			sg.valueMatrix = result.ValueMatrix
 			sg.facetsMatrix = result.FacetMatrix
 			sg.counts = result.Counts
+			if sg.Params.isDistance {
+				sg.spreadDistances(result)
+			}
 
 			if sg.Params.DoCount {
 				if len(sg.Filters) == 0 {
@@ -1696,6 +1817,13 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			if parent == nil {
 				// I'm root. We reach here if root had a function.
 				sg.uidMatrix = []*protos.List{sg.DestUIDs}
+				if len(sg.SrcFunc) > 0 && sg.SrcFunc[0] == "nearest" &&
+					len(sg.Params.Order) == 0 && len(sg.Params.FacetOrder) == 0 {
+					if err = sg.orderByDistance(result); err != nil {
+						rch <- err
+						return
+					}
+				}
 			}
 		}
 	}
@@ -1823,10 +1951,21 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 					rch <- err
 					return
 				}
+			} else if child.Params.expandType {
+				child.ExpandPreds, err = typeFields(ctx, child.Params.namespace,
+					child.Params.Expand)
+				if err != nil {
+					rch <- err
+					return
+				}
 			}
 
 			up := uniquePreds(child.ExpandPreds)
 			for k, _ := range up {
+				if !belongsTo(k, child.Params.namespace) ||
+					!child.Params.access.can(k, PermRead) {
+					continue
+				}
 				temp := new(SubGraph)
 				*temp = *child
 				temp.Params.isInternal = false
@@ -1859,6 +1998,8 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 				Params: params{
 					ignoreResult: true,
 					Langs:        it.Langs,
+					groupbyCell:  it.Cell,
+					cellLevel:    it.CellLevel,
 				},
 			})
 		}
@@ -1911,7 +2052,59 @@ func (sg *SubGraph) applyPagination(ctx context.Context) error {
 		sg.uidMatrix[i].Uids = sg.uidMatrix[i].Uids[start:end]
 	}
 	// Re-merge the UID matrix.
-	sg.DestUIDs = algo.MergeSorted(sg.uidMatrix)
+	if sg.Params.byDistance {
+		sg.updateDestUids(ctx)
+	} else {
+		sg.DestUIDs = algo.MergeSorted(sg.uidMatrix)
+	}
+	return nil
+}
+
+// spreadDistances lays out the distances of the nodes matched by a near function in a block, which
+// the result has as the values of its single list of uids, as the values of the source nodes.
+func (sg *SubGraph) spreadDistances(result *protos.Result) {
+	sg.uidMatrix = make([]*protos.List, len(sg.SrcUIDs.Uids))
+	sg.valueMatrix = make([]*protos.ValueList, len(sg.SrcUIDs.Uids))
+	for i := range sg.SrcUIDs.Uids {
+		sg.uidMatrix[i] = &protos.List{}
+		sg.valueMatrix[i] = &protos.ValueList{Values: []*protos.TaskValue{{Val: x.Nilbyte}}}
+	}
+	if len(result.UidMatrix) != 1 || len(result.ValueMatrix) != 1 {
+		return
+	}
+	vals := result.ValueMatrix[0].Values
+	for i, uid := range result.UidMatrix[0].Uids {
+		if idx := algo.IndexOf(sg.SrcUIDs, uid); idx >= 0 && i < len(vals) {
+			sg.valueMatrix[idx].Values = []*protos.TaskValue{vals[i]}
+		}
+	}
+}
+
+// orderByDistance orders the uids found by the nearest function at root by their distance, which
+// the result has as the values of its single list of uids.
+func (sg *SubGraph) orderByDistance(result *protos.Result) error {
+	if len(result.UidMatrix) != 1 || len(result.ValueMatrix) != 1 {
+		return nil
+	}
+	uids := result.UidMatrix[0].Uids
+	vals := result.ValueMatrix[0].Values
+	if len(uids) != len(vals) {
+		return x.Errorf("Expected a distance for each of the %d nearest nodes, but got %d",
+			len(uids), len(vals))
+	}
+	dists := make(map[uint64]float64, len(uids))
+	for i, uid := range uids {
+		v, err := convertTo(vals[i])
+		if err != nil {
+			return err
+		}
+		dists[uid] = v.Value.(float64)
+	}
+	o := make([]uint64, len(uids))
+	copy(o, uids)
+	sort.SliceStable(o, func(i, j int) bool { return dists[o[i]] < dists[o[j]] })
+	sg.uidMatrix = []*protos.List{{o}}
+	sg.Params.byDistance = true
 	return nil
 }
 
@@ -2078,7 +2271,7 @@ func isValidFuncName(f string) bool {
 		"has", "uid", "uid_in":
 		return true
 	}
-	return isCompareFn(f) || types.IsGeoFunc(f)
+	return isCompareFn(f) || types.IsGeoFunc(f) || udf.IsFilter(f)
 }
 
 func isCompareFn(f string) bool {
@@ -2206,6 +2399,31 @@ type QueryRequest struct {
 
 	vars         map[string]varValue
 	SchemaUpdate []*protos.SchemaUpdate
+	// Types are the types declared by the schema mutation of the request.
+	Types []*protos.TypeUpdate
+
+	// Namespace is the namespace the request is run in, if any.
+	Namespace string
+	// Access has the permissions of the user the request is run for, if there are access
+	// control lists.
+	Access Access
+	// NodeFilter restricts the nodes the user sees, if it's not empty. It's injected into every
+	// query block of the request.
+	NodeFilter string
+}
+
+// blockLabel returns how the query block gq selects its nodes, as a label of metrics: the type of
+// its root function, or uid, var, shortest or recurse.
+func blockLabel(gq *gql.GraphQuery) string {
+	switch {
+	case gq.Alias == "shortest" || gq.Alias == "recurse":
+		return gq.Alias
+	case gq.Func != nil:
+		return worker.FuncLabel(gq.Func.Name)
+	case len(gq.UID) > 0:
+		return "uid"
+	}
+	return "var"
 }
 
 // ProcessQuery processes query part of the request (without mutations).
@@ -2213,9 +2431,19 @@ type QueryRequest struct {
 func (req *QueryRequest) ProcessQuery(ctx context.Context) error {
 	var err error
 
+	// The results of tasks are held until the query is processed.
+	ctx, mem := worker.WithQueryMemory(ctx)
+	defer mem.Release()
+
+	if err = req.rewriteTypeFuncs(); err != nil {
+		return err
+	}
 	// doneVars stores the processed variables.
 	req.vars = make(map[string]varValue)
 	loopStart := time.Now()
+	// Spans are only reported once, so the deferred calls are no-ops when they're finished.
+	planSpan, _ := tracing.Start(ctx, "plan")
+	defer planSpan.Finish()
 	queries := req.GqlQuery.Query
 	for i := 0; i < len(queries); i++ {
 		gq := queries[i]
@@ -2238,8 +2466,11 @@ func (req *QueryRequest) ProcessQuery(ctx context.Context) error {
 		req.Subgraphs = append(req.Subgraphs, sg)
 	}
 	req.Latency.Parsing += time.Since(loopStart)
+	planSpan.Finish()
 
 	execStart := time.Now()
+	execSpan, ctx := tracing.Start(ctx, "execute")
+	defer execSpan.Finish()
 	hasExecuted := make([]bool, len(req.Subgraphs))
 	numQueriesDone := 0
 
@@ -2294,18 +2525,29 @@ func (req *QueryRequest) ProcessQuery(ctx context.Context) error {
 				continue
 			}
 
+			start := time.Now()
+			label := blockLabel(req.GqlQuery.Query[idx])
+			done := func(err error) {
+				x.QueryLatency.WithLabelValues(label).Observe(time.Since(start).Seconds())
+				errChan <- err
+			}
 			if sg.Params.Alias == "shortest" {
 				// We allow only one shortest path block per query.
 				go func() {
+					var err error
 					shortestSg, err = ShortestPath(ctx, sg)
-					errChan <- err
+					done(err)
 				}()
 			} else if sg.Params.Alias == "recurse" {
 				go func() {
-					errChan <- Recurse(ctx, sg)
+					done(Recurse(ctx, sg))
 				}()
 			} else {
-				go ProcessGraph(ctx, sg, nil, errChan)
+				go func() {
+					rch := make(chan error, 1)
+					ProcessGraph(ctx, sg, nil, rch)
+					done(<-rch)
+				}()
 			}
 			if tr, ok := trace.FromContext(ctx); ok {
 				tr.LazyPrintf("Graph processed")
@@ -2323,6 +2565,7 @@ func (req *QueryRequest) ProcessQuery(ctx context.Context) error {
 			}
 		}
 		if ferr != nil {
+			execSpan.SetError(ferr)
 			return ferr
 		}
 
@@ -2376,9 +2619,29 @@ func (e *InternalError) Error() string {
 
 func (qr *QueryRequest) prepareMutation() (err error) {
 	if len(qr.GqlQuery.Mutation.Schema) > 0 {
-		if qr.SchemaUpdate, err = schema.Parse(qr.GqlQuery.Mutation.Schema); err != nil {
+		qr.SchemaUpdate, qr.Types, err = schema.ParseWithTypes(qr.GqlQuery.Mutation.Schema)
+		if err != nil {
 			return x.Wrapf(&InvalidRequestError{err: err}, "failed to parse schema")
 		}
+		if qr.Access != nil {
+			if err = qr.Access.checkSchema(qr.SchemaUpdate); err != nil {
+				return err
+			}
+			if err = qr.Access.checkTypes(qr.Types); err != nil {
+				return err
+			}
+		}
+		if qr.Namespace != "" {
+			for _, su := range qr.SchemaUpdate {
+				su.Predicate = x.NamespacedAttr(qr.Namespace, su.Predicate)
+			}
+			for _, typ := range qr.Types {
+				typ.TypeName = x.NamespacedAttr(qr.Namespace, typ.TypeName)
+				for i, f := range typ.Fields {
+					typ.Fields[i] = x.NamespacedAttr(qr.Namespace, f)
+				}
+			}
+		}
 	}
 	if err = parseFacetsInMutation(qr.GqlQuery.Mutation); err != nil {
 		return err
@@ -2397,7 +2660,7 @@ func (qr *QueryRequest) processNquads(ctx context.Context, nquads gql.NQuads, ne
 	if tr, ok := trace.FromContext(ctx); ok {
 		tr.LazyPrintf("converted nquads to directed edges")
 	}
-	m := protos.Mutations{Edges: mr.Edges, Schema: qr.SchemaUpdate}
+	m := protos.Mutations{Edges: mr.Edges, Schema: qr.SchemaUpdate, Types: qr.Types}
 	if err = ApplyMutations(ctx, &m); err != nil {
 		return x.Wrapf(&InternalError{err: err}, "failed to apply mutations")
 	}
@@ -2416,6 +2679,26 @@ func (qr *QueryRequest) ProcessWithMutation(ctx context.Context) (er ExecuteResu
 	if !ok {
 		mutationAllowed = false
 	}
+	if err = qr.rewriteTypeFuncs(); err != nil {
+		return er, err
+	}
+	if qr.Access != nil {
+		if err = qr.checkAccess(); err != nil {
+			return er, err
+		}
+		ctx = context.WithValue(ctx, "access", qr.Access)
+	}
+	if qr.NodeFilter != "" {
+		if err = qr.filterNodes(); err != nil {
+			return er, err
+		}
+	}
+	if qr.Namespace != "" {
+		if err = qr.inNamespace(); err != nil {
+			return er, err
+		}
+		ctx = context.WithValue(ctx, "namespace", qr.Namespace)
+	}
 
 	var depSet, indepSet, depDel, indepDel gql.NQuads
 	var newUids map[string]uint64
@@ -2469,6 +2752,7 @@ func (qr *QueryRequest) ProcessWithMutation(ctx context.Context) (er ExecuteResu
 		if er.SchemaNode, err = worker.GetSchemaOverNetwork(ctx, qr.GqlQuery.Schema); err != nil {
 			return er, x.Wrapf(&InternalError{err: err}, "error while fetching schema")
 		}
+		er.SchemaNode = readableSchema(qr.Access, schemaInNamespace(qr.Namespace, er.SchemaNode))
 	}
 	return er, nil
 }
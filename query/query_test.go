@@ -1098,6 +1098,45 @@ func TestQueryVarValOrderDescMissing(t *testing.T) {
 	require.JSONEq(t, `{"data": {}}`, js)
 }
 
+func TestStreamProtocolBuf(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1, 23, 24, 25, 31)) {
+			name
+			friend { name }
+		}
+		you(func: uid(1)) {
+			count(friend)
+		}
+	}
+	`
+	res, err := gql.Parse(gql.Request{Str: query})
+	require.NoError(t, err)
+	queryRequest := QueryRequest{Latency: &Latency{}, GqlQuery: &res}
+	require.NoError(t, queryRequest.ProcessQuery(context.Background()))
+	pb, err := ToProtocolBuf(queryRequest.Latency, queryRequest.Subgraphs)
+	require.NoError(t, err)
+	require.Len(t, pb, 2)
+
+	// With parts of a byte, each result is a part, and no part is left empty.
+	var rootCount []int
+	var results []*protos.Node
+	require.NoError(t, StreamProtocolBuf(queryRequest.Latency, queryRequest.Subgraphs, 1,
+		func(n *protos.Node) error {
+			require.Equal(t, "_root_", n.Attribute)
+			rootCount = append(rootCount, len(n.Children))
+			results = append(results, n.Children...)
+			return nil
+		}))
+	require.Equal(t, []int{1, 1, 1, 1, 1, 1}, rootCount)
+	var expected []*protos.Node
+	for _, n := range pb {
+		expected = append(expected, n.Children...)
+	}
+	require.Equal(t, expected, results)
+}
+
 func TestGroupByRootProto(t *testing.T) {
 	populateGraph(t)
 	query := `
@@ -1323,6 +1362,35 @@ func TestGroupByMulti(t *testing.T) {
 		js)
 }
 
+func TestGroupByCell(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(5101, 5102, 5103)) @groupby(cell(geometry, 10)) {
+				count(_uid_)
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"@groupby":[{"cell(geometry)":"808fa3","centroid(geometry)":{"type":"Point","coordinates":[-122.25274279999996,37.513653]},"count":1},{"cell(geometry)":"808fb9","centroid(geometry)":{"type":"Point","coordinates":[-122.08158701105454,37.425852403557045]},"count":2}]}]}}`,
+		js)
+}
+
+func TestGroupByCellNotGeo(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1, 23, 24)) @groupby(cell(age, 10)) {
+				count(_uid_)
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Only geo predicates can be grouped by cell")
+}
+
 func TestMultiEmptyBlocks(t *testing.T) {
 	populateGraph(t)
 	query := `
@@ -3299,8 +3367,11 @@ func TestFilterRegex1(t *testing.T) {
     }
 `
 
-	_, err := processToFastJsonReq(t, query)
-	require.Error(t, err)
+	// Without trigrams, the names of the friends are all matched, skipping the ones without any.
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Michonne", "friend":[{"name":"Rick Grimes"},
+		{"name":"Glenn Rhee"}, {"name":"Daryl Dixon"}, {"name":"Andrea"}]}]}}`, js)
 }
 
 func TestFilterRegex2(t *testing.T) {
@@ -3316,8 +3387,10 @@ func TestFilterRegex2(t *testing.T) {
     }
 `
 
-	_, err := processToFastJsonReq(t, query)
-	require.Error(t, err)
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Michonne", "friend":[{"name":"Rick Grimes"},
+		{"name":"Glenn Rhee"}]}]}}`, js)
 }
 
 func TestFilterRegex3(t *testing.T) {
@@ -3564,6 +3637,56 @@ func TestFilterRegex16(t *testing.T) {
 		js)
 }
 
+func TestFilterRegex17(t *testing.T) {
+	populateGraph(t)
+	query := `
+    {
+	  me(func: uid(0x1234)) {
+		pattern @filter(regexp(value, /^[st]/)) {
+			value
+		}
+      }
+    }
+`
+
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"pattern":[{"value":"transmission"}, {"value":"synopsis"},
+		{"value":"subsensuously"}, {"value":"submission"}, {"value":"subcommission"}]}]}}`, js)
+}
+
+// At root, patterns without trigrams go over all the values of the predicate.
+func TestRegexRootNoTrigrams(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: regexp(value, /^z/)) {
+				value
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"value":"zipped"}, {"value":"zurich"}]}}`, js)
+}
+
+func TestRegexRootUncommitted(t *testing.T) {
+	populateGraph(t)
+	// The value is only in the list in memory, and is matched before being synced.
+	addEdgeToValue(t, "value", 0x2fff, "zoology", nil)
+	defer delEdgeToLangValue(t, "value", 0x2fff, "zoology", "")
+	query := `
+		{
+			me(func: regexp(value, /^z/)) {
+				value
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"value":"zipped"}, {"value":"zurich"}, {"value":"zoology"}]}}`, js)
+}
+
 func TestToFastJSONFilterUID(t *testing.T) {
 	populateGraph(t)
 	query := `
@@ -6132,6 +6255,90 @@ func TestNearPoint(t *testing.T) {
 	require.JSONEq(t, expected, js)
 }
 
+func TestNearestPoints(t *testing.T) {
+	populateGraph(t)
+	// The nearest point is Shoreline Amphitheater itself, then the Googleplex. San Carlos Airport is
+	// further than the first caps looked up.
+	query := `{
+		me(func: nearest(geometry, [-122.080668, 37.426753], 3)) {
+			name
+		}
+	}`
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Shoreline Amphitheater"},{"name":"Googleplex"},
+		{"name":"San Carlos Airport"}]}}`
+	require.JSONEq(t, expected, js)
+
+	query = `{
+		me(func: nearest(geometry, [-122.080668, 37.426753], 10), first: 2) {
+			name
+		}
+	}`
+	js = processToFastJSON(t, query)
+	expected = `{"data": {"me":[{"name":"Shoreline Amphitheater"},{"name":"Googleplex"}]}}`
+	require.JSONEq(t, expected, js)
+
+	query = `{
+		me(func: nearest(geometry, [-122.2527428, 37.513653], 1)) {
+			name
+		}
+	}`
+	js = processToFastJSON(t, query)
+	expected = `{"data": {"me":[{"name":"San Carlos Airport"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestNearestError(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: nearest(geometry, [-122.080668, 37.426753], 0)) {
+			name
+		}
+	}`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+}
+
+func TestNearDistanceVar(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		var(func: uid(5101, 5102, 5103)) {
+			d as near(geometry, [-122.082506, 37.4249518], 1000)
+		}
+		me(func: uid(d), orderdesc: val(d)) {
+			name
+			dist: val(d)
+		}
+	}`
+	js := processToFastJSON(t, query)
+	var res struct {
+		Data struct {
+			Me []struct {
+				Name string
+				Dist float64
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(js), &res))
+	// San Carlos Airport is further than 1000 metres.
+	require.Len(t, res.Data.Me, 2)
+	require.Equal(t, "Shoreline Amphitheater", res.Data.Me[0].Name)
+	require.InDelta(t, 250, res.Data.Me[0].Dist, 10)
+	require.Equal(t, "Googleplex", res.Data.Me[1].Name)
+	require.InDelta(t, 0, res.Data.Me[1].Dist, 1e-6)
+}
+
+func TestNearDistanceVarRequired(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: uid(5101)) {
+			near(geometry, [-122.082506, 37.4249518], 1000)
+		}
+	}`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+}
+
 func TestWithinPolygon(t *testing.T) {
 	populateGraph(t)
 	query := `{
@@ -6144,6 +6351,110 @@ func TestWithinPolygon(t *testing.T) {
 	require.JSONEq(t, expected, js)
 }
 
+func TestWithinPolygonWKT(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: within(geometry, "POLYGON((-122.06 37.37, -122.1 37.36, -122.12 37.4, -122.11 37.43, -122.04 37.43, -122.06 37.37))")) {
+			name
+		}
+	}`
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Googleplex"},{"name":"Shoreline Amphitheater"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestNearPointWKT(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: near(geometry, "wkt:POINT(-122.082506 37.4249518)", 1000)) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Googleplex"},{"name":"Shoreline Amphitheater"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestWithinBox(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: withinbox(geometry, [-122.13, 37.35], [-122.03, 37.44])) {
+			name
+		}
+	}`
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Googleplex"},{"name":"Shoreline Amphitheater"},{"name":"Mountain View"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestMathGeoArea(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(5101, 5104, 5105, 5106)) {
+				g as geometry
+				a as math(floor(area(g) / 1000000))
+			}
+
+			me(func: uid(a), orderdesc: val(a)) {
+				name
+				val(a)
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"SF Bay area","val(a)":6756.000000},{"name":"Mountain View","val(a)":39.000000},{"name":"San Carlos","val(a)":4.000000},{"name":"Googleplex","val(a)":0.000000}]}}`,
+		js)
+}
+
+func TestMathGeoAreaNotGeo(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(1)) {
+				g as age
+				a as math(area(g))
+			}
+
+			me(func: uid(a)) {
+				val(a)
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Wrong type encountered for func area")
+}
+
+func TestWithinVar(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		var(func: uid(5105, 5106)) {
+			region as geometry
+		}
+		me(func: within(geometry, val(region))) {
+			name
+		}
+	}`
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Googleplex"},{"name":"Shoreline Amphitheater"},{"name":"San Carlos Airport"}]}}`
+	require.JSONEq(t, expected, js)
+
+	query = `{
+		var(func: uid(5105, 5106)) {
+			region as geometry
+		}
+		me(func: uid(5101, 5103, 5104)) @filter(within(geometry, val(region))) {
+			name
+		}
+	}`
+	js = processToFastJSON(t, query)
+	expected = `{"data": {"me":[{"name":"Googleplex"},{"name":"San Carlos Airport"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
 func TestContainsPoint(t *testing.T) {
 	populateGraph(t)
 	query := `{
@@ -6157,6 +6468,19 @@ func TestContainsPoint(t *testing.T) {
 	require.JSONEq(t, expected, js)
 }
 
+func TestNearRing(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: near(geometry, [-122.082506, 37.4249518], 100, 1000)) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	expected := `{"data": {"me":[{"name":"Shoreline Amphitheater"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
 func TestNearPoint2(t *testing.T) {
 	populateGraph(t)
 	query := `{
@@ -6184,6 +6508,71 @@ func TestIntersectsPolygon1(t *testing.T) {
 	require.JSONEq(t, expected, js)
 }
 
+func TestDisjointPolygon(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: disjoint(geometry, [[[-122.06, 37.37], [-122.1, 37.36], [-122.12, 37.4], [-122.11, 37.43], [-122.04, 37.43], [-122.06, 37.37]]])) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	expected := `{"data" : {"me":[{"name":"San Carlos Airport"},{"name":"San Carlos"},
+		{"name":"New York"}]}}`
+	require.JSONEq(t, expected, js)
+
+	query = `{
+		me(func: uid(1, 5101, 5103, 5104)) @filter(disjoint(geometry, [[[-122.06, 37.37], [-122.1, 37.36], [-122.12, 37.4], [-122.11, 37.43], [-122.04, 37.43], [-122.06, 37.37]]])) {
+			name
+		}
+	}`
+	js = processToFastJSON(t, query)
+	expected = `{"data" : {"me":[{"name":"San Carlos Airport"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestDisjointPolygonUncommitted(t *testing.T) {
+	populateGraph(t)
+	// Reno is only in the lists in memory, and is found before being synced.
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-119.8138, 39.5296})
+	addGeoData(t, ps, 5109, p, "Reno")
+	defer func() {
+		val := types.ValueForType(types.BinaryID)
+		src := types.Val{Tid: types.GeoID, Value: p}
+		require.NoError(t, types.Marshal(src, &val))
+		for _, e := range []*protos.DirectedEdge{
+			{Attr: "geometry", Value: val.Value.([]byte), ValueType: uint32(types.GeoID)},
+			{Attr: "name", Value: []byte("Reno"), ValueType: uint32(types.StringID)},
+		} {
+			e.Entity, e.Label, e.Op = 5109, "testing", protos.DirectedEdge_DEL
+			addEdge(t, e.Attr, 5109, e)
+		}
+	}()
+	query := `{
+		me(func: disjoint(geometry, [[[-122.06, 37.37], [-122.1, 37.36], [-122.12, 37.4], [-122.11, 37.43], [-122.04, 37.43], [-122.06, 37.37]]])) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	expected := `{"data" : {"me":[{"name":"San Carlos Airport"},{"name":"San Carlos"},
+		{"name":"New York"},{"name":"Reno"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
+func TestOverlapsPolygon(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: overlaps(geometry, [[[-122.06, 37.37], [-122.1, 37.36], [-122.12, 37.4], [-122.11, 37.43], [-122.04, 37.43], [-122.06, 37.37]]])) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	expected := `{"data" : {"me":[{"name":"Mountain View"}]}}`
+	require.JSONEq(t, expected, js)
+}
+
 func TestIntersectsPolygon2(t *testing.T) {
 	populateGraph(t)
 	query := `{
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// type(Name) matches the nodes of the type Name, which are those with Name among their values of
+// the kind predicate. It's run as eq(<kind predicate>, "Name"), which it's rewritten to before the
+// permissions of the request are checked, and before it's rewritten to its namespace.
+
+// typeFunc rewrites f to the function it stands for, if it's type().
+func typeFunc(f *gql.Function) error {
+	if f == nil || f.Name != "type" {
+		return nil
+	}
+	if len(f.Args) > 0 {
+		return x.Errorf("type() takes the name of a single type")
+	}
+	if worker.Config.KindPredicate == "" {
+		return x.Errorf("type() needs a kind predicate")
+	}
+	f.Name, f.Attr, f.Args = "eq", worker.Config.KindPredicate, []string{f.Attr}
+	return nil
+}
+
+func typeFilter(ft *gql.FilterTree) error {
+	if ft == nil {
+		return nil
+	}
+	if err := typeFunc(ft.Func); err != nil {
+		return err
+	}
+	for _, ch := range ft.Child {
+		if err := typeFilter(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func typeQuery(gq *gql.GraphQuery) error {
+	if err := typeFunc(gq.Func); err != nil {
+		return err
+	}
+	if err := typeFilter(gq.Filter); err != nil {
+		return err
+	}
+	for _, ch := range gq.Children {
+		if err := typeQuery(ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteTypeFuncs rewrites the type() functions of the queries of the request.
+func (qr *QueryRequest) rewriteTypeFuncs() error {
+	for _, gq := range qr.GqlQuery.Query {
+		if gq == nil {
+			continue
+		}
+		if err := typeQuery(gq); err != nil {
+			return x.Wrap(&InvalidRequestError{err: err})
+		}
+	}
+	return nil
+}
+
+// typeFields returns the fields of the type name of namespace ns, as the predicates expanded by
+// expand(name).
+func typeFields(ctx context.Context, ns, name string) ([]*protos.ValueList, error) {
+	typs, err := worker.GetTypesOverNetwork(ctx, []string{x.NamespacedAttr(ns, name)})
+	if err != nil {
+		return nil, err
+	}
+	if len(typs) == 0 {
+		return nil, x.Errorf("Type %s isn't declared", name)
+	}
+	vl := &protos.ValueList{}
+	for _, f := range typs[0].Fields {
+		vl.Values = append(vl.Values, &protos.TaskValue{Val: []byte(f)})
+	}
+	return []*protos.ValueList{vl}, nil
+}
+
+// checkTypes returns an error if access can't modify the fields of the types typs.
+func (access Access) checkTypes(typs []*protos.TypeUpdate) error {
+	for _, typ := range typs {
+		for _, f := range typ.Fields {
+			if err := access.check(f, PermModify); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/udf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func init() {
+	udf.RegisterFilter("querytest_older", func(vals []types.Val, args []string) (bool, error) {
+		min, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return vals[0].Value.(int64) > min, nil
+	})
+	udf.RegisterScalar("querytest_twice", 1, func(args []types.Val) (types.Val, error) {
+		switch v := args[0].Value.(type) {
+		case int64:
+			return types.Val{Tid: types.IntID, Value: 2 * v}, nil
+		case float64:
+			return types.Val{Tid: types.FloatID, Value: 2 * v}, nil
+		}
+		return types.Val{}, x.Errorf("Expected a number. Got: %v", args[0].Value)
+	})
+}
+
+func TestUDF(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(0x01)) {
+				f as friend @filter(querytest_older(age, "16")) {
+					a as age
+					b as math(querytest_twice(a) + 1)
+				}
+			}
+
+			me(func: uid(f)) {
+				name
+				val(b)
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Daryl Dixon","val(b)":35},{"name":"Andrea","val(b)":39}]}}`, js)
+
+	_, err := processToFastJsonReq(t, `
+		{
+			me(func: uid(0x01)) {
+				friend @filter(querytest_older(age, "x")) {
+					name
+				}
+			}
+		}
+	`)
+	require.Error(t, err)
+}
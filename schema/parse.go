@@ -18,6 +18,8 @@
 package schema
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/dgraph-io/dgraph/lex"
@@ -32,17 +34,25 @@ func From(s *protos.SchemaUpdate) protos.SchemaUpdate {
 		return protos.SchemaUpdate{
 			ValueType: s.ValueType,
 			Directive: protos.SchemaUpdate_REVERSE,
-			Count:     s.Count}
+			Count:     s.Count,
+			Required:  s.Required,
+			Default:   s.Default,
+			Ondelete:  s.Ondelete}
 	} else if s.Directive == protos.SchemaUpdate_INDEX {
 		return protos.SchemaUpdate{
 			ValueType: s.ValueType,
 			Directive: protos.SchemaUpdate_INDEX,
 			Tokenizer: s.Tokenizer,
+			Geo:       s.Geo,
 			Count:     s.Count,
 			List:      s.List,
+			Required:  s.Required,
+			Default:   s.Default,
+			Noreplace: s.Noreplace,
 		}
 	}
-	return protos.SchemaUpdate{ValueType: s.ValueType, Count: s.Count, List: s.List}
+	return protos.SchemaUpdate{ValueType: s.ValueType, Count: s.Count, List: s.List,
+		Required: s.Required, Default: s.Default, Noreplace: s.Noreplace, Ondelete: s.Ondelete}
 }
 
 // ParseBytes parses the byte array which holds the schema. We will reset
@@ -53,7 +63,7 @@ func ParseBytes(s []byte, gid uint32) (rerr error) {
 		reset()
 	}
 	pstate.m = make(map[uint32]*stateGroup)
-	updates, err := Parse(string(s))
+	updates, typs, err := ParseWithTypes(string(s))
 	if err != nil {
 		return err
 	}
@@ -61,6 +71,9 @@ func ParseBytes(s []byte, gid uint32) (rerr error) {
 	for _, update := range updates {
 		State().Set(update.Predicate, From(update))
 	}
+	for _, typ := range typs {
+		State().SetType(*typ)
+	}
 	State().Set("_predicate_", protos.SchemaUpdate{
 		ValueType: uint32(types.StringID),
 		List:      true,
@@ -81,14 +94,39 @@ func parseDirective(it *lex.ItemIterator, schema *protos.SchemaUpdate, t types.T
 		}
 		schema.Directive = protos.SchemaUpdate_REVERSE
 	case "index":
-		if tokenizer, err := parseIndexDirective(it, schema.Predicate, t); err != nil {
+		if tokenizer, geo, err := parseIndexDirective(it, schema.Predicate, t); err != nil {
 			return err
 		} else {
 			schema.Directive = protos.SchemaUpdate_INDEX
 			schema.Tokenizer = tokenizer
+			schema.Geo = geo
 		}
 	case "count":
 		schema.Count = true
+	case "required":
+		kinds, err := parseRequiredDirective(it, schema.Predicate)
+		if err != nil {
+			return err
+		}
+		schema.Required = kinds
+	case "default":
+		def, err := parseDefaultDirective(it, schema.Predicate, t)
+		if err != nil {
+			return err
+		}
+		schema.Default = def
+	case "noreplace":
+		if t == types.UidID || schema.List {
+			return x.Errorf("Cannot use @noreplace on pred %s, which doesn't have a single value",
+				schema.Predicate)
+		}
+		schema.Noreplace = true
+	case "ondelete":
+		action, err := parseOndeleteDirective(it, schema.Predicate, t)
+		if err != nil {
+			return err
+		}
+		schema.Ondelete = action
 	default:
 		return x.Errorf("Invalid index specification")
 	}
@@ -151,14 +189,8 @@ func parseScalarPair(it *lex.ItemIterator, predicate string) (*protos.SchemaUpda
 		}
 		next = it.Item()
 	}
-	if next.Typ == itemAt {
-		if err := parseDirective(it, schema, t); err != nil {
-			return nil, err
-		}
-		next = it.Item()
-	}
-	// Check for another directive, we could have @count too.
-	if next.Typ == itemAt {
+	// Check for directives, we could have @count and @required too.
+	for next.Typ == itemAt {
 		if err := parseDirective(it, schema, t); err != nil {
 			return nil, err
 		}
@@ -167,6 +199,12 @@ func parseScalarPair(it *lex.ItemIterator, predicate string) (*protos.SchemaUpda
 	if next.Typ != itemDot {
 		return nil, x.Errorf("Invalid ending")
 	}
+	// Nodes referring to a deleted one are found by the reverse edges.
+	if (schema.Ondelete == OndeleteDetach || schema.Ondelete == OndeleteRestrict) &&
+		schema.Directive != protos.SchemaUpdate_REVERSE {
+		return nil, x.Errorf("@ondelete(%s) needs @reverse on pred: %s", schema.Ondelete,
+			predicate)
+	}
 	it.Next()
 	next = it.Item()
 	if next.Typ == lex.ItemEOF {
@@ -179,25 +217,27 @@ func parseScalarPair(it *lex.ItemIterator, predicate string) (*protos.SchemaUpda
 	return schema, nil
 }
 
-// parseIndexDirective works on "@index" or "@index(customtokenizer)".
+// parseIndexDirective works on "@index" or "@index(customtokenizer)". The geo tokenizer can be
+// given the parameters of its cells, as in "@index(geo(minlevel=8, maxlevel=16))".
 func parseIndexDirective(it *lex.ItemIterator, predicate string,
-	typ types.TypeID) ([]string, error) {
+	typ types.TypeID) ([]string, *protos.GeoIndex, error) {
 	var tokenizers []string
+	var geo *protos.GeoIndex
 	var seen = make(map[string]bool)
 	var seenSortableTok bool
 
 	if typ == types.UidID || typ == types.DefaultID || typ == types.PasswordID {
-		return tokenizers, x.Errorf("Indexing not allowed on predicate %s of type %s",
+		return tokenizers, nil, x.Errorf("Indexing not allowed on predicate %s of type %s",
 			predicate, typ.Name())
 	}
 	if !it.Next() {
 		// Nothing to read.
-		return []string{}, x.Errorf("Invalid ending.")
+		return []string{}, nil, x.Errorf("Invalid ending.")
 	}
 	next := it.Item()
 	if next.Typ != itemLeftRound {
 		it.Prev() // Backup.
-		return []string{}, x.Errorf("Require type of tokenizer for pred: %s for indexing.",
+		return []string{}, nil, x.Errorf("Require type of tokenizer for pred: %s for indexing.",
 			predicate)
 	}
 
@@ -211,43 +251,315 @@ func parseIndexDirective(it *lex.ItemIterator, predicate string,
 		}
 		if next.Typ == itemComma {
 			if expectArg {
-				return nil, x.Errorf("Expected a tokenizer but got comma")
+				return nil, nil, x.Errorf("Expected a tokenizer but got comma")
 			}
 			expectArg = true
 			continue
 		}
 		if next.Typ != itemText {
-			return tokenizers, x.Errorf("Expected directive arg but got: %v", next.Val)
+			return tokenizers, nil, x.Errorf("Expected directive arg but got: %v", next.Val)
 		}
 		if !expectArg {
-			return tokenizers, x.Errorf("Expected a comma but got: %v", next)
+			return tokenizers, nil, x.Errorf("Expected a comma but got: %v", next)
 		}
 		// Look for custom tokenizer.
 		tokenizer, has := tok.GetTokenizer(strings.ToLower(next.Val))
 		if !has {
-			return tokenizers, x.Errorf("Invalid tokenizer %s", next.Val)
+			return tokenizers, nil, x.Errorf("Invalid tokenizer %s", next.Val)
 		}
 		if tokenizer.Type() != typ {
-			return tokenizers,
+			return tokenizers, nil,
 				x.Errorf("Tokenizer: %s isn't valid for predicate: %s of type: %s",
 					tokenizer.Name(), predicate, typ.Name())
 		}
 		if _, found := seen[tokenizer.Name()]; found {
-			return tokenizers, x.Errorf("Duplicate tokenizers defined for pred %v",
+			return tokenizers, nil, x.Errorf("Duplicate tokenizers defined for pred %v",
 				predicate)
 		}
 		if tokenizer.IsSortable() {
 			if seenSortableTok {
-				return nil, x.Errorf("More than one sortable index encountered for: %v",
+				return nil, nil, x.Errorf("More than one sortable index encountered for: %v",
 					predicate)
 			}
 			seenSortableTok = true
 		}
+		isGeo := tokenizer.Name() == "geo" || tokenizer.Name() == "geohash"
+		if isGeo && (seen["geo"] || seen["geohash"]) {
+			return nil, nil, x.Errorf("Only one of the geo and geohash indexes can be set for "+
+				"pred: %s", predicate)
+		}
+		if item, ok := it.PeekOne(); ok && item.Typ == itemLeftRound {
+			if !isGeo {
+				return nil, nil, x.Errorf("Tokenizer %s of pred %s doesn't take parameters",
+					tokenizer.Name(), predicate)
+			}
+			var err error
+			if geo, err = parseGeoIndex(it, tokenizer.Name(), predicate); err != nil {
+				return nil, nil, err
+			}
+		} else if tokenizer.Name() == "geohash" {
+			// The precision of geohash indexes is always set, as it tells them from S2 ones.
+			geo = &protos.GeoIndex{Precision: types.DefaultGeohashPrecision}
+		}
 		tokenizers = append(tokenizers, tokenizer.Name())
 		seen[tokenizer.Name()] = true
 		expectArg = false
 	}
-	return tokenizers, nil
+	return tokenizers, geo, nil
+}
+
+// parseGeoIndex works on the parameters of the geo tokenizer, "(minlevel=8, maxlevel=16,
+// maxcells=18)", or of the geohash one, "(precision=7)", any of which can be left out for its
+// default.
+func parseGeoIndex(it *lex.ItemIterator, tokenizer, predicate string) (*protos.GeoIndex, error) {
+	it.Next() // Left round.
+	geo := &protos.GeoIndex{
+		MinLevel: types.MinCellLevel,
+		MaxLevel: types.MaxCellLevel,
+		MaxCells: types.MaxCells,
+	}
+	if tokenizer == "geohash" {
+		geo = &protos.GeoIndex{Precision: types.DefaultGeohashPrecision}
+	}
+	seen := make(map[string]bool)
+	for {
+		var items []lex.Item
+		for i := 0; i < 4; i++ {
+			if !it.Next() {
+				return nil, x.Errorf("Invalid ending.")
+			}
+			items = append(items, it.Item())
+		}
+		if items[0].Typ != itemText || items[1].Typ != itemEqual || items[2].Typ != itemText ||
+			(items[3].Typ != itemComma && items[3].Typ != itemRightRound) {
+			return nil, x.Errorf("Require parameters like minlevel=8 for geo index of pred: %s",
+				predicate)
+		}
+		name := strings.ToLower(items[0].Val)
+		if seen[name] {
+			return nil, x.Errorf("Duplicate parameter %s for geo index of pred: %s", name,
+				predicate)
+		}
+		seen[name] = true
+		v, err := strconv.ParseUint(items[2].Val, 10, 32)
+		if err != nil {
+			return nil, x.Errorf("Invalid value %s of %s for geo index of pred: %s", items[2].Val,
+				name, predicate)
+		}
+		switch {
+		case name == "minlevel" && tokenizer == "geo":
+			geo.MinLevel = uint32(v)
+		case name == "maxlevel" && tokenizer == "geo":
+			geo.MaxLevel = uint32(v)
+		case name == "maxcells" && tokenizer == "geo":
+			geo.MaxCells = uint32(v)
+		case name == "precision" && tokenizer == "geohash" && v > 0:
+			geo.Precision = uint32(v)
+		default:
+			return nil, x.Errorf("Invalid parameter %s=%s for %s index of pred: %s", items[0].Val,
+				items[2].Val, tokenizer, predicate)
+		}
+		if items[3].Typ == itemRightRound {
+			break
+		}
+	}
+	if err := types.ValidateGeoIndex(geo); err != nil {
+		return nil, x.Wrapf(err, "Invalid geo index of pred: %s", predicate)
+	}
+	return geo, nil
+}
+
+// parseRequiredDirective works on "@required(kind, ...)".
+func parseRequiredDirective(it *lex.ItemIterator, predicate string) ([]string, error) {
+	if !it.Next() || it.Item().Typ != itemLeftRound {
+		return nil, x.Errorf("Require kinds of nodes for @required on pred: %s", predicate)
+	}
+	var kinds []string
+	seen := make(map[string]bool)
+	expectArg := true
+	for {
+		if !it.Next() {
+			return nil, x.Errorf("Invalid ending.")
+		}
+		next := it.Item()
+		if next.Typ == itemRightRound {
+			break
+		}
+		if next.Typ == itemComma {
+			if expectArg {
+				return nil, x.Errorf("Expected a kind but got comma")
+			}
+			expectArg = true
+			continue
+		}
+		if next.Typ != itemText {
+			return nil, x.Errorf("Expected directive arg but got: %v", next.Val)
+		}
+		if !expectArg {
+			return nil, x.Errorf("Expected a comma but got: %v", next)
+		}
+		if seen[next.Val] {
+			return nil, x.Errorf("Duplicate kinds defined for pred %v", predicate)
+		}
+		seen[next.Val] = true
+		kinds = append(kinds, next.Val)
+		expectArg = false
+	}
+	if expectArg {
+		return nil, x.Errorf("Require kinds of nodes for @required on pred: %s", predicate)
+	}
+	return kinds, nil
+}
+
+// Actions of @ondelete, run on the edges of a predicate when the nodes they're from or to are
+// deleted.
+const (
+	// OndeleteCascade deletes the nodes the edges of deleted nodes point to.
+	OndeleteCascade = "cascade"
+	// OndeleteDetach deletes the edges pointing to deleted nodes.
+	OndeleteDetach = "detach"
+	// OndeleteRestrict fails the deletion of nodes which edges point to.
+	OndeleteRestrict = "restrict"
+)
+
+// parseOndeleteDirective works on "@ondelete(action)".
+func parseOndeleteDirective(it *lex.ItemIterator, predicate string,
+	typ types.TypeID) (string, error) {
+	if typ != types.UidID {
+		return "", x.Errorf("@ondelete not allowed on predicate %s of type %s", predicate,
+			typ.Name())
+	}
+	var items []lex.Item
+	for i := 0; i < 3; i++ {
+		if !it.Next() {
+			return "", x.Errorf("Invalid ending.")
+		}
+		items = append(items, it.Item())
+	}
+	if items[0].Typ != itemLeftRound || items[1].Typ != itemText ||
+		items[2].Typ != itemRightRound {
+		return "", x.Errorf("Require an action for @ondelete on pred: %s", predicate)
+	}
+	switch action := strings.ToLower(items[1].Val); action {
+	case OndeleteCascade, OndeleteDetach, OndeleteRestrict:
+		return action, nil
+	default:
+		return "", x.Errorf("Invalid action %s for @ondelete on pred: %s", items[1].Val,
+			predicate)
+	}
+}
+
+// parseDefaultDirective works on "@default("value")".
+func parseDefaultDirective(it *lex.ItemIterator, predicate string,
+	typ types.TypeID) (string, error) {
+	if typ == types.UidID || typ == types.PasswordID {
+		return "", x.Errorf("Default values not allowed on predicate %s of type %s",
+			predicate, typ.Name())
+	}
+	var items []lex.Item
+	for i := 0; i < 3; i++ {
+		if !it.Next() {
+			return "", x.Errorf("Invalid ending.")
+		}
+		items = append(items, it.Item())
+	}
+	if items[0].Typ != itemLeftRound || items[1].Typ != itemQuotedText ||
+		items[2].Typ != itemRightRound {
+		return "", x.Errorf("Require a quoted value for @default on pred: %s", predicate)
+	}
+	def, err := unquote(items[1].Val)
+	if err != nil {
+		return "", err
+	}
+	if def == "" {
+		return "", x.Errorf("Empty default value of pred: %s", predicate)
+	}
+	src := types.Val{Tid: types.StringID, Value: []byte(def)}
+	if _, err := types.Convert(src, typ); err != nil {
+		return "", x.Wrapf(err, "Invalid default value of pred %s of type %s", predicate,
+			typ.Name())
+	}
+	return def, nil
+}
+
+var escapes = map[byte]byte{'t': '\t', 'b': '\b', 'n': '\n', 'r': '\r', 'f': '\f'}
+
+// unquote returns the string of the quoted string s, with its escapes replaced.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", x.Errorf("Invalid quoted string: %s", s)
+	}
+	s = s[1 : len(s)-1]
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			i++
+			c = s[i]
+			if e, ok := escapes[c]; ok {
+				c = e
+			}
+		}
+		buf = append(buf, c)
+	}
+	return string(buf), nil
+}
+
+// Quote returns s as a quoted string of a schema, like the value of @default.
+func Quote(s string) string {
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(append(buf, '"'))
+}
+
+// TypeLine returns the declaration of the type typ, as a line of a schema.
+func TypeLine(typ *protos.TypeUpdate) string {
+	name := func(s string) string {
+		if strings.ContainsRune(s, ':') {
+			return "<" + s + ">"
+		}
+		return s
+	}
+	fields := make([]string, 0, len(typ.Fields))
+	for _, f := range typ.Fields {
+		fields = append(fields, name(f))
+	}
+	return "type " + name(typ.TypeName) + " { " + strings.Join(fields, ", ") + " }"
+}
+
+// Tokenizers returns the tokenizers of an @index, with the parameters geo of the geo or geohash
+// tokenizer, as in "geo(minlevel=8,maxlevel=16,maxcells=18)" or "geohash(precision=7)".
+func Tokenizers(tokenizers []string, geo *protos.GeoIndex) []string {
+	out := make([]string, 0, len(tokenizers))
+	for _, t := range tokenizers {
+		if t == "geo" && geo != nil {
+			t = fmt.Sprintf("geo(minlevel=%d,maxlevel=%d,maxcells=%d)", geo.MinLevel,
+				geo.MaxLevel, geo.MaxCells)
+		} else if t == "geohash" && geo != nil {
+			t = fmt.Sprintf("geohash(precision=%d)", geo.Precision)
+		}
+		out = append(out, t)
+	}
+	return out
 }
 
 // resolveTokenizers resolves default tokenizers and verifies tokenizers definitions.
@@ -300,9 +612,64 @@ func resolveTokenizers(updates []*protos.SchemaUpdate) error {
 	return nil
 }
 
-// Parse parses a schema string and returns the schema representation for it.
+// parseTypeDeclaration works on "type Name { field, ... }", whose fields can also be separated by
+// new lines.
+func parseTypeDeclaration(it *lex.ItemIterator) (*protos.TypeUpdate, error) {
+	if !it.Next() || it.Item().Typ != itemText {
+		return nil, x.Errorf("Missing type name")
+	}
+	typ := &protos.TypeUpdate{TypeName: it.Item().Val}
+	if !it.Next() || it.Item().Typ != itemLeftCurl {
+		return nil, x.Errorf("Missing { after type %s", typ.TypeName)
+	}
+	seen := make(map[string]bool)
+	for {
+		if !it.Next() {
+			return nil, x.Errorf("Unclosed { of type %s", typ.TypeName)
+		}
+		next := it.Item()
+		switch next.Typ {
+		case itemRightCurl:
+			if len(typ.Fields) == 0 {
+				return nil, x.Errorf("Type %s has no fields", typ.TypeName)
+			}
+			return typ, nil
+		case itemComma, itemNewLine:
+		case itemText:
+			if seen[next.Val] {
+				return nil, x.Errorf("Duplicate field %s in type %s", next.Val, typ.TypeName)
+			}
+			seen[next.Val] = true
+			typ.Fields = append(typ.Fields, next.Val)
+		default:
+			return nil, x.Errorf("Unexpected %v in type %s", next.Val, typ.TypeName)
+		}
+	}
+}
+
+// isTypeDeclaration returns true if the item at it starts the declaration of a type, rather than
+// the schema of a predicate named type.
+func isTypeDeclaration(it *lex.ItemIterator) bool {
+	if it.Item().Val != "type" {
+		return false
+	}
+	next, ok := it.PeekOne()
+	return ok && next.Typ == itemText
+}
+
+// Parse parses a schema string and returns the schema representation for it. The declarations of
+// types in s are skipped, see ParseWithTypes.
 func Parse(s string) ([]*protos.SchemaUpdate, error) {
+	schemas, _, err := ParseWithTypes(s)
+	return schemas, err
+}
+
+// ParseWithTypes parses a schema string, which can also declare types of nodes, and returns the
+// schema of its predicates and its types.
+func ParseWithTypes(s string) ([]*protos.SchemaUpdate, []*protos.TypeUpdate, error) {
 	var schemas []*protos.SchemaUpdate
+	var typs []*protos.TypeUpdate
+	seen := make(map[string]bool)
 	l := lex.Lexer{Input: s}
 	l.Run(lexText)
 	it := l.NewIterator()
@@ -311,22 +678,34 @@ func Parse(s string) ([]*protos.SchemaUpdate, error) {
 		switch item.Typ {
 		case lex.ItemEOF:
 			if err := resolveTokenizers(schemas); err != nil {
-				return nil, x.Wrapf(err, "failed to enrich schema")
+				return nil, nil, x.Wrapf(err, "failed to enrich schema")
 			}
-			return schemas, nil
+			return schemas, typs, nil
 		case itemText:
+			if isTypeDeclaration(it) {
+				typ, err := parseTypeDeclaration(it)
+				if err != nil {
+					return nil, nil, err
+				}
+				if seen[typ.TypeName] {
+					return nil, nil, x.Errorf("Type %s declared twice", typ.TypeName)
+				}
+				seen[typ.TypeName] = true
+				typs = append(typs, typ)
+				continue
+			}
 			if schema, err := parseScalarPair(it, item.Val); err != nil {
-				return nil, err
+				return nil, nil, err
 			} else {
 				schemas = append(schemas, schema)
 			}
 		case lex.ItemError:
-			return nil, x.Errorf(item.Val)
+			return nil, nil, x.Errorf(item.Val)
 		case itemNewLine:
 			// pass empty line
 		default:
-			return nil, x.Errorf("Unexpected token: %v", item)
+			return nil, nil, x.Errorf("Unexpected token: %v", item)
 		}
 	}
-	return nil, x.Errorf("Shouldn't reach here")
+	return nil, nil, x.Errorf("Shouldn't reach here")
 }
@@ -270,6 +270,17 @@ func TestParse8_Error(t *testing.T) {
 	require.Nil(t, schemas)
 }
 
+func TestParse9_Error(t *testing.T) {
+	reset()
+	schemas, err := Parse("123: string .")
+	require.Error(t, err)
+	require.Nil(t, schemas)
+
+	schemas, err = Parse("name: string @index(exact) 8 .")
+	require.Error(t, err)
+	require.Nil(t, schemas)
+}
+
 func TestParseScalarList(t *testing.T) {
 	reset()
 	schemas, err := Parse(`
@@ -300,6 +311,201 @@ func TestParseScalarList(t *testing.T) {
 	}, schemas[2])
 }
 
+func TestParseRequired(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		name: string @index(exact) @count @required(Person, Company) .
+		friend: uid @reverse @required(Person) .
+	`)
+	require.NoError(t, err)
+	require.EqualValues(t, &protos.SchemaUpdate{
+		Predicate: "name",
+		ValueType: 9,
+		Directive: protos.SchemaUpdate_INDEX,
+		Tokenizer: []string{"exact"},
+		Count:     true,
+		Required:  []string{"Person", "Company"},
+	}, schemas[0])
+	require.Equal(t, []string{"Person"}, schemas[1].Required)
+
+	for _, s := range []string{
+		"name: string @required .",
+		"name: string @required() .",
+		"name: string @required(Person,) .",
+		"name: string @required(Person, Person) .",
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseNoreplace(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		ssn: string @index(exact) @noreplace .
+		age: int @noreplace @default("1") .
+	`)
+	require.NoError(t, err)
+	require.True(t, schemas[0].Noreplace)
+	require.Equal(t, []string{"exact"}, schemas[0].Tokenizer)
+	require.True(t, schemas[1].Noreplace)
+	require.Equal(t, "1", schemas[1].Default)
+
+	for _, s := range []string{
+		`tags: [string] @noreplace .`,
+		`friend: uid @noreplace .`,
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseOndelete(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		owns: uid @ondelete(cascade) .
+		friend: uid @ondelete(Detach) @reverse .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, OndeleteCascade, schemas[0].Ondelete)
+	require.Equal(t, OndeleteDetach, schemas[1].Ondelete)
+	require.Equal(t, protos.SchemaUpdate_REVERSE, schemas[1].Directive)
+
+	for _, s := range []string{
+		`friend: uid @ondelete(detach) .`,
+		`parent: uid @ondelete(restrict) .`,
+		`owns: uid @ondelete(nullify) .`,
+		`owns: uid @ondelete .`,
+		`name: string @ondelete(cascade) .`,
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseGeoIndex(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		loc: geo @index(geo(minlevel=8, maxlevel=20)) .
+		area: geo @index(geo(MaxCells=40)) .
+		route: geo @index(geo) .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"geo"}, schemas[0].Tokenizer)
+	require.Equal(t, &protos.GeoIndex{MinLevel: 8, MaxLevel: 20, MaxCells: types.MaxCells},
+		schemas[0].Geo)
+	require.Equal(t, &protos.GeoIndex{MinLevel: types.MinCellLevel,
+		MaxLevel: types.MaxCellLevel, MaxCells: 40}, schemas[1].Geo)
+	require.Nil(t, schemas[2].Geo)
+	require.Equal(t, []string{"geo(minlevel=8,maxlevel=20,maxcells=18)"},
+		Tokenizers(schemas[0].Tokenizer, schemas[0].Geo))
+
+	for _, s := range []string{
+		`loc: geo @index(geo(minlevel=17)) .`,
+		`loc: geo @index(geo(maxlevel=31)) .`,
+		`loc: geo @index(geo(maxcells=0)) .`,
+		`loc: geo @index(geo(minlevel=-1)) .`,
+		`loc: geo @index(geo(level=8)) .`,
+		`loc: geo @index(geo(minlevel=8, minlevel=9)) .`,
+		`loc: geo @index(geo(minlevel)) .`,
+		`loc: geo @index(geo()) .`,
+		`name: string @index(exact(minlevel=8)) .`,
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseGeohashIndex(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		loc: geo @index(geohash(precision=9)) .
+		area: geo @index(geohash) .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"geohash"}, schemas[0].Tokenizer)
+	require.Equal(t, &protos.GeoIndex{Precision: 9}, schemas[0].Geo)
+	require.Equal(t, &protos.GeoIndex{Precision: types.DefaultGeohashPrecision}, schemas[1].Geo)
+	require.Equal(t, []string{"geohash(precision=9)"},
+		Tokenizers(schemas[0].Tokenizer, schemas[0].Geo))
+
+	for _, s := range []string{
+		`loc: geo @index(geohash(precision=13)) .`,
+		`loc: geo @index(geohash(precision=0)) .`,
+		`loc: geo @index(geohash(minlevel=8)) .`,
+		`loc: geo @index(geo(precision=8)) .`,
+		`loc: geo @index(geo, geohash) .`,
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseDefault(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		status: string @index(exact) @default("say \"hi\"\n") .
+		rank: int @default("3") .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, "say \"hi\"\n", schemas[0].Default)
+	require.Equal(t, []string{"exact"}, schemas[0].Tokenizer)
+	require.Equal(t, "3", schemas[1].Default)
+	require.Equal(t, `"say \"hi\"\n"`, Quote(schemas[0].Default))
+
+	for _, s := range []string{
+		`rank: int @default("three") .`,
+		`rank: int @default(3) .`,
+		`status: string @default("") .`,
+		`friend: uid @default("0x1") .`,
+	} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	reset()
+	schemas, typs, err := ParseWithTypes(`
+		name: string @index(exact) .
+		type: string .
+		type Person {
+			name, age
+			friend
+		}
+		type Company { name }
+	`)
+	require.NoError(t, err)
+	require.Len(t, schemas, 2)
+	require.Equal(t, "type", schemas[1].Predicate)
+	require.Equal(t, []*protos.TypeUpdate{
+		{TypeName: "Person", Fields: []string{"name", "age", "friend"}},
+		{TypeName: "Company", Fields: []string{"name"}},
+	}, typs)
+
+	require.Equal(t, "type Person { name, age, friend }", TypeLine(typs[0]))
+	_, parsed, err := ParseWithTypes(TypeLine(&protos.TypeUpdate{TypeName: "Film",
+		Fields: []string{"http://schema.org/name"}}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://schema.org/name"}, parsed[0].Fields)
+
+	schemas, err = Parse("type Person { name }\nage: int .")
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+
+	for _, s := range []string{
+		"type Person name, age",
+		"type Person { name, age",
+		"type Person { }",
+		"type Person { name, name }",
+		"type Person { name: string }",
+		"type Person { name }\ntype Person { age }",
+	} {
+		_, _, err := ParseWithTypes(s)
+		require.Error(t, err, s)
+	}
+}
+
 func TestParseScalarListError1(t *testing.T) {
 	reset()
 	schemas, err := Parse(`
@@ -47,11 +47,14 @@ type stateGroup struct {
 	sync.RWMutex // x.SafeMutex is slow.
 	// Map containing predicate to type information.
 	predicate map[string]*protos.SchemaUpdate
-	elog      trace.EventLog
+	// Map containing the types declared in the group of x.TypesAttr to their fields.
+	types map[string]*protos.TypeUpdate
+	elog  trace.EventLog
 }
 
 func (s *stateGroup) init(group uint32) {
 	s.predicate = make(map[string]*protos.SchemaUpdate)
+	s.types = make(map[string]*protos.TypeUpdate)
 	s.elog = trace.NewEventLog("Dynamic Schema", fmt.Sprintf("%d", group))
 }
 
@@ -95,6 +98,9 @@ func State() *state {
 // Update updates the schema in memory and sends an entry to syncCh so that it can be
 // committed later
 func (s *state) Update(se SyncEntry) {
+	if se.Type != nil {
+		se.Attr = x.TypesAttr
+	}
 	s.get(group.BelongsTo(se.Attr)).update(se)
 }
 
@@ -102,6 +108,14 @@ func (s *stateGroup) update(se SyncEntry) {
 	s.Lock()
 	defer s.Unlock()
 
+	if se.Type != nil {
+		s.types[se.Type.TypeName] = se.Type
+		se.Water.Begin(se.Index)
+		syncCh <- se
+		s.elog.Printf("Setting type %s: %v\n", se.Type.TypeName, se.Type.Fields)
+		x.Printf("Setting type %s: %v\n", se.Type.TypeName, se.Type.Fields)
+		return
+	}
 	s.predicate[se.Attr] = &se.Schema
 	se.Water.Begin(se.Index)
 	syncCh <- se
@@ -132,6 +146,49 @@ func (s *stateGroup) set(pred string, schema protos.SchemaUpdate) {
 	s.elog.Printf(logUpdate(schema, pred))
 }
 
+// SetType sets the declaration of a type in memory. Like schema mutations, type declarations must
+// flow through Update to be synced to db.
+func (s *state) SetType(typ protos.TypeUpdate) {
+	s.get(group.BelongsTo(x.TypesAttr)).setType(typ)
+}
+
+func (s *stateGroup) setType(typ protos.TypeUpdate) {
+	s.Lock()
+	defer s.Unlock()
+	s.types[typ.TypeName] = &typ
+}
+
+// GetType returns the declaration of the type name, if this server serves the group of
+// x.TypesAttr and it's declared.
+func (s *state) GetType(name string) (protos.TypeUpdate, bool) {
+	return s.get(group.BelongsTo(x.TypesAttr)).getType(name)
+}
+
+func (s *stateGroup) getType(name string) (protos.TypeUpdate, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	typ, ok := s.types[name]
+	if !ok {
+		return protos.TypeUpdate{}, false
+	}
+	return *typ, true
+}
+
+// Types returns the names of the types declared in the group of x.TypesAttr.
+func (s *state) Types() []string {
+	return s.get(group.BelongsTo(x.TypesAttr)).typeNames()
+}
+
+func (s *stateGroup) typeNames() []string {
+	s.RLock()
+	defer s.RUnlock()
+	out := make([]string, 0, len(s.types))
+	for k := range s.types {
+		out = append(out, k)
+	}
+	return out
+}
+
 // Get gets the schema for given predicate
 func (s *state) Get(pred string) (protos.SchemaUpdate, bool) {
 	return s.get(group.BelongsTo(pred)).get(pred)
@@ -175,6 +232,12 @@ func (s *stateGroup) isIndexed(pred string) bool {
 	return false
 }
 
+// UpdatesIndex returns whether the values of the predicate are added to an index, its own or
+// the one being rebuilt online.
+func (s *state) UpdatesIndex(pred string) bool {
+	return len(s.Indexes(pred)) > 0
+}
+
 // IndexedFields returns the list of indexed fields
 func (s *state) IndexedFields(gid uint32) []string {
 	return s.get(gid).indexedFields()
@@ -217,15 +280,110 @@ func (s *stateGroup) tokenizer(pred string) []tok.Tokenizer {
 	defer s.RUnlock()
 	schema, ok := s.predicate[pred]
 	x.AssertTruef(ok, "schema state not found for %s", pred)
+	return IndexTokenizers(schema)
+}
+
+// IndexTokenizers returns the tokenizers of the index of a predicate with the given schema.
+func IndexTokenizers(schema *protos.SchemaUpdate) []tok.Tokenizer {
 	var tokenizers []tok.Tokenizer
 	for _, it := range schema.Tokenizer {
 		t, has := tok.GetTokenizer(it)
 		x.AssertTruef(has, "Invalid tokenizer %s", it)
+		switch t.(type) {
+		case tok.GeoTokenizer:
+			t = tok.GeoTokenizer{Index: schema.Geo}
+		case tok.GeohashTokenizer:
+			t = tok.GeohashTokenizer{Index: geohashIndex(schema.Geo)}
+		}
 		tokenizers = append(tokenizers, t)
 	}
 	return tokenizers
 }
 
+// Index is an index the values of a predicate are added to, with its tokenizers, under the keys
+// of the predicate Attr.
+type Index struct {
+	Attr       string
+	Tokenizers []tok.Tokenizer
+}
+
+// Indexes returns the indexes the values of pred are added to: its own if it's indexed, and the
+// geo index being rebuilt online for it.
+func (s *state) Indexes(pred string) []Index {
+	return s.get(group.BelongsTo(pred)).indexes(pred)
+}
+
+func (s *stateGroup) indexes(pred string) []Index {
+	s.RLock()
+	defer s.RUnlock()
+	schema, ok := s.predicate[pred]
+	if !ok {
+		return nil
+	}
+	var out []Index
+	if len(schema.Tokenizer) > 0 {
+		out = append(out, Index{Attr: IndexAttr(pred, schema), Tokenizers: IndexTokenizers(schema)})
+	}
+	if r := schema.Rebuild; r != nil {
+		out = append(out, Index{Attr: IndexAttr(pred, r), Tokenizers: IndexTokenizers(r)})
+	}
+	return out
+}
+
+// IndexAttr returns the predicate the index keys of pred with the schema s are stored under.
+func IndexAttr(pred string, s *protos.SchemaUpdate) string {
+	if s.IndexAttr != "" {
+		return s.IndexAttr
+	}
+	return pred
+}
+
+// RebuildAttr returns the predicate the geo index of pred is built under when rebuilt online, if
+// its index is stored under indexAttr: the one of pred and pred@rebuild which isn't indexAttr.
+func RebuildAttr(pred, indexAttr string) string {
+	if indexAttr == "" || indexAttr == pred {
+		return pred + "@rebuild"
+	}
+	return pred
+}
+
+// IndexAttr returns the predicate the index keys of pred are stored under, which after its geo
+// index was rebuilt online isn't pred itself.
+func (s *state) IndexAttr(pred string) string {
+	schema, _ := s.Get(pred)
+	return IndexAttr(pred, &schema)
+}
+
+// GeoIndex returns the parameters of the geo index of the predicate, or nil for the default ones.
+// Those of a geohash index have its precision.
+func (s *state) GeoIndex(pred string) *protos.GeoIndex {
+	return s.get(group.BelongsTo(pred)).geoIndex(pred)
+}
+
+func (s *stateGroup) geoIndex(pred string) *protos.GeoIndex {
+	s.RLock()
+	defer s.RUnlock()
+	schema, ok := s.predicate[pred]
+	if !ok {
+		return nil
+	}
+	for _, t := range schema.Tokenizer {
+		if t == "geohash" {
+			return geohashIndex(schema.Geo)
+		}
+	}
+	return schema.Geo
+}
+
+// geohashIndex returns the parameters geo of a geohash index, with the default precision if
+// they don't set one.
+func geohashIndex(geo *protos.GeoIndex) *protos.GeoIndex {
+	if types.IsGeohashIndex(geo) {
+		return geo
+	}
+	return &protos.GeoIndex{Precision: types.DefaultGeohashPrecision}
+}
+
 // Tokenizer returns the tokenizer names for given predicate
 func (s *state) TokenizerNames(pred string) []string {
 	return s.get(group.BelongsTo(pred)).tokenizerNames(pred)
@@ -287,6 +445,61 @@ func (s *stateGroup) isList(pred string) bool {
 	return false
 }
 
+// IsNoreplace returns whether setting a value of the predicate on a node which has another one
+// fails, instead of replacing it.
+func (s *state) IsNoreplace(pred string) bool {
+	return s.get(group.BelongsTo(pred)).isNoreplace(pred)
+}
+
+func (s *stateGroup) isNoreplace(pred string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if schema, ok := s.predicate[pred]; ok {
+		return schema.Noreplace
+	}
+	return false
+}
+
+// Required returns the kinds of nodes which must have a value of the predicate.
+func (s *state) Required(pred string) []string {
+	return s.get(group.BelongsTo(pred)).required(pred)
+}
+
+func (s *stateGroup) required(pred string) []string {
+	s.RLock()
+	defer s.RUnlock()
+	if schema, ok := s.predicate[pred]; ok {
+		return schema.Required
+	}
+	return nil
+}
+
+// Default returns the value of the predicate on nodes without one, in its binary form, if it has
+// a default value.
+func (s *state) Default(pred string) (types.Val, bool) {
+	return s.get(group.BelongsTo(pred)).defaultValue(pred)
+}
+
+func (s *stateGroup) defaultValue(pred string) (types.Val, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	schema, ok := s.predicate[pred]
+	if !ok || schema.Default == "" {
+		return types.Val{}, false
+	}
+	src := types.Val{Tid: types.StringID, Value: []byte(schema.Default)}
+	val, err := types.Convert(src, types.TypeID(schema.ValueType))
+	if err != nil {
+		return types.Val{}, false
+	}
+	// Values are read from posting lists in their binary form.
+	data := types.ValueForType(types.BinaryID)
+	if err := types.Marshal(val, &data); err != nil {
+		return types.Val{}, false
+	}
+	return types.Val{Tid: val.Tid, Value: data.Value}, true
+}
+
 func Init(ps *badger.KV) {
 	pstore = ps
 	syncCh = make(chan SyncEntry, syncChCapacity)
@@ -306,7 +519,16 @@ func LoadFromDb(gid uint32) error {
 		if !bytes.HasPrefix(key, prefix) {
 			break
 		}
-		attr := x.Parse(key).Attr
+		pk := x.Parse(key)
+		attr := pk.Attr
+		if pk.IsTypeDef() {
+			var typ protos.TypeUpdate
+			x.Checkf(typ.Unmarshal(item.Value()), "Error while loading types from db")
+			if group.BelongsTo(attr) == gid {
+				State().SetType(typ)
+			}
+			continue
+		}
 		var s protos.SchemaUpdate
 		x.Checkf(s.Unmarshal(item.Value()), "Error while loading schema from db")
 		if group.BelongsTo(attr) != gid {
@@ -330,8 +552,10 @@ func reset() {
 type SyncEntry struct {
 	Attr   string
 	Schema protos.SchemaUpdate
-	Water  *x.WaterMark
-	Index  uint64
+	// Type is the declaration of a type, set instead of Attr and Schema.
+	Type  *protos.TypeUpdate
+	Water *x.WaterMark
+	Index uint64
 }
 
 func addToEntriesMap(entriesMap map[*x.WaterMark][]uint64, entries []SyncEntry) {
@@ -365,6 +589,12 @@ func batchSync() {
 		loop++
 		State().elog.Printf("[%4d] Writing schema batch of size: %v\n", loop, len(entries))
 		for _, e := range entries {
+			if e.Type != nil {
+				val, err := e.Type.Marshal()
+				x.Checkf(err, "Error while marshalling type declaration")
+				wb = badger.EntriesSet(wb, x.TypeKey(e.Type.TypeName), val)
+				continue
+			}
 			val, err := e.Schema.Marshal()
 			x.Checkf(err, "Error while marshalling schema description")
 			wb = badger.EntriesSet(wb, x.SchemaKey(e.Attr), val)
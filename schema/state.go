@@ -36,6 +36,8 @@ const (
 	itemUnderscore
 	itemLeftSquare
 	itemRightSquare
+	itemQuotedText // quoted string
+	itemEqual
 )
 
 func lexText(l *lex.Lexer) lex.StateFn {
@@ -44,7 +46,8 @@ Loop:
 		switch r := l.Next(); {
 		case r == lex.EOF:
 			break Loop
-		case isNameBegin(r):
+		case isNameBegin(r), isDigit(r) && l.ArgDepth > 0:
+			// Only the arguments of directives, like @index(geo(maxlevel=20)), begin with digits.
 			l.Backup()
 			return lexWord
 		case isSpace(r):
@@ -64,11 +67,15 @@ Loop:
 		case r == '}':
 			l.Emit(itemRightCurl)
 		case r == '(':
+			l.ArgDepth++
 			l.Emit(itemLeftRound)
 		case r == ')':
+			l.ArgDepth--
 			l.Emit(itemRightRound)
 		case r == ':':
 			l.Emit(itemColon)
+		case r == '=':
+			l.Emit(itemEqual)
 		case r == '@':
 			l.Emit(itemAt)
 		case r == '[':
@@ -78,6 +85,11 @@ Loop:
 		case r == '_':
 			// Predicates can start with _.
 			return lexWord
+		case r == '"':
+			if err := l.LexQuotedString(); err != nil {
+				return l.Errorf("Invalid schema: %v", err)
+			}
+			l.Emit(itemQuotedText)
 		default:
 			return l.Errorf("Invalid schema. Unexpected %s", l.Input[l.Start:l.Pos])
 		}
@@ -115,11 +127,13 @@ func isNameBegin(r rune) bool {
 	}
 }
 
+// isDigit returns true if the rune is a digit, which begins the numbers of directive arguments.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
 func isNameSuffix(r rune) bool {
-	if isNameBegin(r) {
-		return true
-	}
-	if r >= '0' && r <= '9' {
+	if isNameBegin(r) || isDigit(r) {
 		return true
 	}
 	if r == '_' || r == '.' || r == '-' { // Use by freebase.
@@ -0,0 +1,215 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sparql
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	tokEOF = iota
+	tokIdent
+	tokVar   // A variable, without its ? or $, or a blank node, with its _:.
+	tokIRI   // An IRI, without its angle brackets.
+	tokPName // A prefixed name, like foaf:name.
+	tokString
+	tokNumber
+	tokPunct // Punctuation, and the operators &&, ||, !=, <= and >=.
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+type lexer struct {
+	in   []rune
+	pos  int
+	line int
+}
+
+func newLexer(s string) *lexer {
+	return &lexer{in: []rune(s), line: 1}
+}
+
+func (l *lexer) peek(i int) rune {
+	if l.pos+i >= len(l.in) {
+		return 0
+	}
+	return l.in[l.pos+i]
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.in) {
+		c := l.in[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+		case c == '#':
+			for l.pos < len(l.in) && l.in[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		case !unicode.IsSpace(c):
+			return
+		}
+		l.pos++
+	}
+}
+
+func isNameChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '-'
+}
+
+// word reads the name characters from the position on. Dots are part of names, but not at
+// their end, where they end triples.
+func (l *lexer) word() string {
+	start := l.pos
+	for l.pos < len(l.in) && (isNameChar(l.in[l.pos]) ||
+		(l.in[l.pos] == '.' && isNameChar(l.peek(1)))) {
+		l.pos++
+	}
+	return string(l.in[start:l.pos])
+}
+
+// iri returns the length of the IRI starting at the position, or 0 if there is none, so that
+// < is a comparison.
+func (l *lexer) iri() int {
+	for i := l.pos + 1; i < len(l.in); i++ {
+		switch c := l.in[i]; {
+		case c == '>':
+			return i - l.pos + 1
+		case unicode.IsSpace(c) || strings.ContainsRune("<\"{}|^`\\", c):
+			return 0
+		}
+	}
+	return 0
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos == len(l.in) {
+		return token{kind: tokEOF}, nil
+	}
+	c := l.in[l.pos]
+	switch {
+	case c == '?' || c == '$':
+		l.pos++
+		name := l.word()
+		if name == "" {
+			return token{}, x.Errorf("Expected the name of a variable after %c", c)
+		}
+		return token{kind: tokVar, text: name}, nil
+	case c == '_' && l.peek(1) == ':':
+		l.pos += 2
+		return token{kind: tokVar, text: "_:" + l.word()}, nil
+	case c == '<' && l.iri() > 0:
+		n := l.iri()
+		t := token{kind: tokIRI, text: string(l.in[l.pos+1 : l.pos+n-1])}
+		l.pos += n
+		return t, nil
+	case c == '"' || c == '\'':
+		return l.str(c)
+	case unicode.IsDigit(c) || (c == '.' && unicode.IsDigit(l.peek(1))):
+		return l.number(), nil
+	case unicode.IsLetter(c) || c == ':':
+		prefix := l.word()
+		if l.peek(0) != ':' {
+			return token{kind: tokIdent, text: prefix}, nil
+		}
+		l.pos++
+		return token{kind: tokPName, text: prefix + ":" + l.word()}, nil
+	}
+	l.pos++
+	if op := string(c) + string(l.peek(0)); op == "&&" || op == "||" || op == "!=" ||
+		op == "<=" || op == ">=" || op == "^^" {
+		l.pos++
+		return token{kind: tokPunct, text: op}, nil
+	}
+	return token{kind: tokPunct, text: string(c)}, nil
+}
+
+func (l *lexer) number() token {
+	start := l.pos
+	for l.pos < len(l.in) {
+		c := l.in[l.pos]
+		switch {
+		case unicode.IsDigit(c):
+		case c == '.' && unicode.IsDigit(l.peek(1)):
+		case c == 'e' || c == 'E':
+		case (c == '-' || c == '+') && (l.in[l.pos-1] == 'e' || l.in[l.pos-1] == 'E'):
+		default:
+			return token{kind: tokNumber, text: string(l.in[start:l.pos])}
+		}
+		l.pos++
+	}
+	return token{kind: tokNumber, text: string(l.in[start:l.pos])}
+}
+
+func (l *lexer) str(quote rune) (token, error) {
+	l.pos++
+	var buf bytes.Buffer
+	for {
+		if l.pos == len(l.in) || l.in[l.pos] == '\n' {
+			return token{}, x.Errorf("Unterminated string")
+		}
+		c := l.in[l.pos]
+		l.pos++
+		if c == quote {
+			return token{kind: tokString, text: buf.String()}, nil
+		}
+		if c != '\\' {
+			buf.WriteRune(c)
+			continue
+		}
+		if l.pos == len(l.in) {
+			return token{}, x.Errorf("Unterminated string")
+		}
+		c = l.in[l.pos]
+		l.pos++
+		switch c {
+		case 'n':
+			buf.WriteRune('\n')
+		case 't':
+			buf.WriteRune('\t')
+		case 'r':
+			buf.WriteRune('\r')
+		case 'b':
+			buf.WriteRune('\b')
+		case 'f':
+			buf.WriteRune('\f')
+		case 'u':
+			if l.pos+4 > len(l.in) {
+				return token{}, x.Errorf("Invalid escape in string")
+			}
+			r, err := strconv.ParseUint(string(l.in[l.pos:l.pos+4]), 16, 32)
+			if err != nil {
+				return token{}, x.Errorf("Invalid escape: \\u%s", string(l.in[l.pos:l.pos+4]))
+			}
+			l.pos += 4
+			buf.WriteRune(rune(r))
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
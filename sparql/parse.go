@@ -0,0 +1,647 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package sparql translates a subset of SPARQL SELECT queries into queries of Dgraph: basic graph
+// patterns, OPTIONAL groups, FILTER expressions and LIMIT and OFFSET, like
+//
+//	PREFIX : <>
+//	SELECT ?name ?friend WHERE {
+//	  ?p :name ?name ; :friend ?f .
+//	  ?f :name ?friend .
+//	  OPTIONAL { ?f :age ?age }
+//	  FILTER (!bound(?age) || ?age > 30)
+//	} LIMIT 10
+//
+// Predicates are the IRIs of the predicates of triple patterns, and nodes are the IRIs of their
+// uids, like <0x1f>. The patterns are matched with a single query, from the subject the others
+// hang off, and joined, with the filters, in the results.
+package sparql
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const xsd = "http://www.w3.org/2001/XMLSchema#"
+
+// iri is the value of a node, the IRI of its uid.
+type iri string
+
+// term is a term of a triple pattern or of an expression. Literals are strings, json.Number
+// numbers or booleans.
+type term struct {
+	variable string
+	iri      iri
+	lit      interface{}
+}
+
+func (t term) key() string {
+	if t.variable != "" {
+		return "?" + t.variable
+	}
+	if t.iri != "" {
+		return "<" + string(t.iri) + ">"
+	}
+	return ""
+}
+
+type triple struct {
+	s    term
+	pred string
+	o    term
+}
+
+// Operators of expressions other than comparisons.
+const (
+	opOr    = "||"
+	opAnd   = "&&"
+	opNot   = "!"
+	opTerm  = "term"
+	opBound = "bound"
+	opRegex = "regex"
+)
+
+type expr struct {
+	op   string
+	args []*expr
+	t    term
+	re   *regexp.Regexp // The pattern of regex.
+}
+
+// Query is a SPARQL SELECT query of the subset translated.
+type Query struct {
+	vars     []string // The variables selected, in order.
+	distinct bool
+	triples  []triple
+	optional [][]triple // The triples of each OPTIONAL group.
+	filters  []*expr
+	limit    int // Negative without a limit.
+	offset   int
+}
+
+type parser struct {
+	lex      *lexer
+	tok      token
+	prefixes map[string]string
+}
+
+func (p *parser) next() error {
+	var err error
+	if p.tok, err = p.lex.next(); err != nil {
+		return x.Wrapf(err, "On line %d", p.lex.line)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return x.Errorf("On line %d: %s", p.lex.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.text == punct
+}
+
+func (p *parser) keyword(kw string) bool {
+	return p.tok.kind == tokIdent && strings.EqualFold(p.tok.text, kw)
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.errorf("Expected %s, got %q", punct, p.tok.text)
+	}
+	return p.next()
+}
+
+func (p *parser) integer() (int, error) {
+	if p.tok.kind != tokNumber {
+		return 0, p.errorf("Expected a number, got %q", p.tok.text)
+	}
+	n, err := strconv.Atoi(p.tok.text)
+	if err != nil || n < 0 {
+		return 0, p.errorf("Invalid number: %q", p.tok.text)
+	}
+	return n, p.next()
+}
+
+// Parse parses the SPARQL query q.
+func Parse(q string) (*Query, error) {
+	p := &parser{lex: newLexer(q), prefixes: make(map[string]string)}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	for p.keyword("prefix") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokPName || !strings.HasSuffix(p.tok.text, ":") {
+			return nil, p.errorf("Expected a prefix, got %q", p.tok.text)
+		}
+		prefix := p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokIRI {
+			return nil, p.errorf("Expected the IRI of prefix %s, got %q", prefix, p.tok.text)
+		}
+		p.prefixes[prefix] = p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.keyword("select") {
+		return nil, p.errorf("Only SELECT queries are supported")
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	query := &Query{limit: -1}
+	if p.keyword("distinct") || p.keyword("reduced") {
+		query.distinct = p.keyword("distinct")
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	all := p.is("*")
+	if all {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	for !all && p.tok.kind == tokVar {
+		query.vars = append(query.vars, p.tok.text)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if !all && len(query.vars) == 0 {
+		return nil, p.errorf("Expected * or variables to select, got %q", p.tok.text)
+	}
+
+	if p.keyword("where") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.group(query); err != nil {
+		return nil, err
+	}
+	if err := p.modifiers(query); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("Unsupported clause: %q", p.tok.text)
+	}
+	if len(query.triples) == 0 {
+		return nil, x.Errorf("The WHERE clause needs a triple pattern outside of OPTIONAL")
+	}
+	if all {
+		query.vars = query.patternVars()
+	}
+	return query, nil
+}
+
+// patternVars returns the variables of the triple patterns of q, in order, without the blank
+// nodes.
+func (q *Query) patternVars() []string {
+	var vars []string
+	seen := make(map[string]bool)
+	add := func(t term) {
+		if t.variable != "" && !strings.HasPrefix(t.variable, "_:") && !seen[t.variable] {
+			seen[t.variable] = true
+			vars = append(vars, t.variable)
+		}
+	}
+	triples := q.triples
+	for _, opt := range q.optional {
+		triples = append(triples[:len(triples):len(triples)], opt...)
+	}
+	for _, t := range triples {
+		add(t.s)
+		add(t.o)
+	}
+	return vars
+}
+
+func (p *parser) modifiers(q *Query) error {
+	if p.keyword("order") || p.keyword("group") || p.keyword("having") {
+		return p.errorf("%s isn't supported", strings.ToUpper(p.tok.text))
+	}
+	for p.keyword("limit") || p.keyword("offset") {
+		limit := p.keyword("limit")
+		if err := p.next(); err != nil {
+			return err
+		}
+		n, err := p.integer()
+		if err != nil {
+			return err
+		}
+		if limit {
+			q.limit = n
+		} else {
+			q.offset = n
+		}
+	}
+	return nil
+}
+
+// group parses the group of the WHERE clause, with OPTIONAL groups of triples in it.
+func (p *parser) group(q *Query) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.is("}") {
+		switch {
+		case p.tok.kind == tokEOF:
+			return p.errorf("Expected }")
+		case p.is("."):
+			if err := p.next(); err != nil {
+				return err
+			}
+		case p.keyword("filter"):
+			if err := p.next(); err != nil {
+				return err
+			}
+			e, err := p.constraint()
+			if err != nil {
+				return err
+			}
+			q.filters = append(q.filters, e)
+		case p.keyword("optional"):
+			if err := p.next(); err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			var triples []triple
+			for !p.is("}") {
+				if p.is(".") {
+					if err := p.next(); err != nil {
+						return err
+					}
+					continue
+				}
+				if p.keyword("optional") || p.keyword("filter") || p.is("{") {
+					return p.errorf("OPTIONAL groups can only hold triple patterns")
+				}
+				if err := p.triples(&triples); err != nil {
+					return err
+				}
+			}
+			if len(triples) == 0 {
+				return p.errorf("Empty OPTIONAL group")
+			}
+			q.optional = append(q.optional, triples)
+			if err := p.next(); err != nil {
+				return err
+			}
+		case p.is("{") || p.keyword("union") || p.keyword("minus") || p.keyword("graph") ||
+			p.keyword("bind") || p.keyword("values") || p.keyword("service"):
+			return p.errorf("%s isn't supported", strings.ToUpper(p.tok.text))
+		default:
+			if err := p.triples(&q.triples); err != nil {
+				return err
+			}
+		}
+	}
+	return p.next()
+}
+
+// triples parses the triple patterns of a subject, with the predicates separated by ; and the
+// objects of a predicate by ,.
+func (p *parser) triples(triples *[]triple) error {
+	s, err := p.term()
+	if err != nil {
+		return err
+	}
+	if s.lit != nil {
+		return p.errorf("Subjects can't be literals")
+	}
+	for {
+		pred, err := p.predicate()
+		if err != nil {
+			return err
+		}
+		for {
+			o, err := p.term()
+			if err != nil {
+				return err
+			}
+			*triples = append(*triples, triple{s: s, pred: pred, o: o})
+			if !p.is(",") {
+				break
+			}
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+		if !p.is(";") {
+			break
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+		if p.is(".") || p.is("}") {
+			break
+		}
+	}
+	if p.is("}") || p.keyword("filter") || p.keyword("optional") {
+		return nil
+	}
+	return p.expect(".")
+}
+
+func (p *parser) expand(pname string) (string, error) {
+	i := strings.Index(pname, ":")
+	prefix, ok := p.prefixes[pname[:i+1]]
+	if !ok && pname[:i+1] == "xsd:" {
+		prefix, ok = xsd, true
+	}
+	if !ok {
+		return "", p.errorf("Unknown prefix: %s", pname[:i+1])
+	}
+	return prefix + pname[i+1:], nil
+}
+
+func (p *parser) predicate() (string, error) {
+	var pred string
+	var err error
+	switch p.tok.kind {
+	case tokIRI:
+		pred = p.tok.text
+	case tokPName:
+		if pred, err = p.expand(p.tok.text); err != nil {
+			return "", err
+		}
+	case tokVar:
+		return "", p.errorf("Variables can't be predicates")
+	default:
+		if p.keyword("a") {
+			return "", p.errorf("rdf:type (a) isn't supported")
+		}
+		return "", p.errorf("Expected a predicate, got %q", p.tok.text)
+	}
+	if pred == "" {
+		return "", p.errorf("Empty predicate")
+	}
+	return pred, p.next()
+}
+
+// node returns the term of the node of the IRI s, which is its uid.
+func (p *parser) node(s string) (term, error) {
+	uid, err := strconv.ParseUint(s, 0, 64)
+	if err != nil || uid == 0 {
+		return term{}, p.errorf("IRIs of nodes are their uids, like <0x1f>, got <%s>", s)
+	}
+	return term{iri: iri(fmt.Sprintf("%#x", uid))}, nil
+}
+
+// term parses a variable, a node or a literal.
+func (p *parser) term() (term, error) {
+	tok := p.tok
+	if err := p.next(); err != nil {
+		return term{}, err
+	}
+	switch tok.kind {
+	case tokVar:
+		return term{variable: tok.text}, nil
+	case tokIRI:
+		return p.node(tok.text)
+	case tokPName:
+		s, err := p.expand(tok.text)
+		if err != nil {
+			return term{}, err
+		}
+		return p.node(s)
+	case tokNumber:
+		return term{lit: json.Number(tok.text)}, nil
+	case tokString:
+		return p.typed(tok.text)
+	}
+	switch {
+	case tok.kind == tokPunct && (tok.text == "-" || tok.text == "+") && p.tok.kind == tokNumber:
+		n := strings.TrimPrefix(tok.text, "+") + p.tok.text
+		return term{lit: json.Number(n)}, p.next()
+	case tok.kind == tokIdent && (tok.text == "true" || tok.text == "false"):
+		return term{lit: tok.text == "true"}, nil
+	}
+	return term{}, p.errorf("Unexpected %q", tok.text)
+}
+
+// typed returns the literal of the string s, with the datatype following it, if any.
+func (p *parser) typed(s string) (term, error) {
+	if p.is("@") {
+		return term{}, p.errorf("Language tags aren't supported")
+	}
+	if !p.is("^^") {
+		return term{lit: s}, nil
+	}
+	if err := p.next(); err != nil {
+		return term{}, err
+	}
+	var typ string
+	var err error
+	switch p.tok.kind {
+	case tokIRI:
+		typ = p.tok.text
+	case tokPName:
+		if typ, err = p.expand(p.tok.text); err != nil {
+			return term{}, err
+		}
+	default:
+		return term{}, p.errorf("Expected a datatype, got %q", p.tok.text)
+	}
+	if err := p.next(); err != nil {
+		return term{}, err
+	}
+	switch strings.TrimPrefix(typ, xsd) {
+	case "integer", "int", "long", "short", "decimal", "double", "float":
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return term{}, p.errorf("Invalid number: %q", s)
+		}
+		return term{lit: json.Number(s)}, nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return term{}, p.errorf("Invalid boolean: %q", s)
+		}
+		return term{lit: b}, nil
+	}
+	return term{lit: s}, nil
+}
+
+// constraint parses the constraint of a FILTER: an expression in parentheses, or a call.
+func (p *parser) constraint() (*expr, error) {
+	if p.is("(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+	return p.call()
+}
+
+func (p *parser) or() (*expr, error) {
+	e, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("||") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		e = &expr{op: opOr, args: []*expr{e, r}}
+	}
+	return e, nil
+}
+
+func (p *parser) and() (*expr, error) {
+	e, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("&&") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		e = &expr{op: opAnd, args: []*expr{e, r}}
+	}
+	return e, nil
+}
+
+var comparisons = map[string]bool{"=": true, "!=": true, "<": true, "<=": true, ">": true,
+	">=": true}
+
+func (p *parser) comparison() (*expr, error) {
+	e, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokPunct || !comparisons[p.tok.text] {
+		return e, nil
+	}
+	op := p.tok.text
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	r, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &expr{op: op, args: []*expr{e, r}}, nil
+}
+
+func (p *parser) unary() (*expr, error) {
+	switch {
+	case p.is("!"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &expr{op: opNot, args: []*expr{e}}, nil
+	case p.is("("):
+		return p.constraint()
+	case p.tok.kind == tokIdent && p.tok.text != "true" && p.tok.text != "false":
+		return p.call()
+	}
+	t, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	return &expr{op: opTerm, t: t}, nil
+}
+
+// call parses a call of bound or regex.
+func (p *parser) call() (*expr, error) {
+	if p.tok.kind != tokIdent {
+		return nil, p.errorf("Expected an expression, got %q", p.tok.text)
+	}
+	name := strings.ToLower(p.tok.text)
+	if name != opBound && name != opRegex {
+		return nil, p.errorf("Unsupported function: %s", p.tok.text)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	e := &expr{op: name}
+	if name == opBound {
+		if p.tok.kind != tokVar {
+			return nil, p.errorf("bound needs a variable")
+		}
+		e.t = term{variable: p.tok.text}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	arg, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	e.args = []*expr{arg}
+	var pattern, flags string
+	for i, s := range []*string{&pattern, &flags} {
+		if i == 1 && p.is(")") {
+			break
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokString {
+			return nil, p.errorf("The patterns and flags of regex need to be strings")
+		}
+		*s = p.tok.text
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if strings.Trim(flags, "ims") != "" {
+		return nil, p.errorf("Unsupported flags of regex: %q", flags)
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	if e.re, err = regexp.Compile(pattern); err != nil {
+		return nil, p.errorf("Invalid regex: %v", err)
+	}
+	return e, p.expect(")")
+}
@@ -0,0 +1,395 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sparql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Term is the value of a variable in the results of a query.
+type Term struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Datatype string `json:"datatype,omitempty"`
+}
+
+// Results are the results of a query, in the SPARQL 1.1 Query Results JSON Format.
+type Results struct {
+	Head struct {
+		Vars []string `json:"vars"`
+	} `json:"head"`
+	Results struct {
+		Bindings []map[string]Term `json:"bindings"`
+	} `json:"results"`
+}
+
+// binding has the values of the variables of a solution of the patterns of a query.
+type binding map[string]interface{}
+
+// merge returns the binding with the variables of a and b, or false if they have different
+// values for a variable.
+func merge(a, b binding) (binding, bool) {
+	m := make(binding, len(a)+len(b))
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		if mv, ok := m[k]; ok && !reflect.DeepEqual(mv, v) {
+			return nil, false
+		}
+		m[k] = v
+	}
+	return m, true
+}
+
+func cross(a, b []binding) []binding {
+	var res []binding
+	for _, x := range a {
+		for _, y := range b {
+			if m, ok := merge(x, y); ok {
+				res = append(res, m)
+			}
+		}
+	}
+	return res
+}
+
+// leftJoin joins a and b, keeping the bindings of a which don't match any of b, like OPTIONAL.
+func leftJoin(a, b []binding) []binding {
+	var res []binding
+	for _, x := range a {
+		matched := false
+		for _, y := range b {
+			if m, ok := merge(x, y); ok {
+				res = append(res, m)
+				matched = true
+			}
+		}
+		if !matched {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+// objects returns the objects of v, a list of objects or an object.
+func objects(v interface{}) []map[string]interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []interface{}:
+		var res []map[string]interface{}
+		for _, c := range v {
+			if m, ok := c.(map[string]interface{}); ok {
+				res = append(res, m)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// values returns the values of leaf l in v, the nodes of a uid predicate or scalar values.
+func values(v interface{}, l *leaf) []interface{} {
+	if l.uid {
+		var res []interface{}
+		for _, o := range objects(v) {
+			if uid, ok := o["_uid_"].(string); ok {
+				res = append(res, iri(uid))
+			}
+		}
+		return res
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+	return []interface{}{v}
+}
+
+// solve returns the solutions of the patterns of node n and the nodes under it, for the object
+// obj of the results bound to it.
+func (t *Translation) solve(n *node, obj map[string]interface{}) []binding {
+	uid, _ := obj["_uid_"].(string)
+	b := binding{}
+	if n.term.variable != "" {
+		b[n.term.variable] = iri(uid)
+	} else if iri(uid) != n.term.iri {
+		return nil
+	}
+	sols := t.join([]binding{b}, n, obj, n.group)
+	if n.group != 0 {
+		return sols
+	}
+	for g := range t.q.optional {
+		sols = leftJoin(sols, t.join([]binding{{}}, n, obj, g+1))
+	}
+	return sols
+}
+
+// join joins sols with the solutions of the leaves and children of node n in group.
+func (t *Translation) join(sols []binding, n *node, obj map[string]interface{},
+	group int) []binding {
+	for _, l := range n.leaves {
+		if l.group != group {
+			continue
+		}
+		var vals []binding
+		for _, v := range values(obj[l.alias], l) {
+			if l.want == nil {
+				vals = append(vals, binding{l.variable: v})
+			} else if c, ok := compare(v, value(*l.want)); ok && c == 0 {
+				vals = []binding{{}}
+				break
+			}
+		}
+		sols = cross(sols, vals)
+	}
+	for _, c := range n.children {
+		if c.group != group {
+			continue
+		}
+		var vals []binding
+		for _, o := range objects(obj[c.alias]) {
+			vals = append(vals, t.solve(c, o)...)
+		}
+		sols = cross(sols, vals)
+	}
+	return sols
+}
+
+// Results returns the results of the query of t, from the data of its JSON response, like
+// {"sparql":[...]}.
+func (t *Translation) Results(data []byte) (*Results, error) {
+	var out map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, x.Wrapf(err, "While reading result of query")
+	}
+
+	var sols []binding
+	for _, o := range objects(out[block]) {
+		for _, b := range t.solve(t.root, o) {
+			keep := true
+			for _, f := range t.q.filters {
+				if ok, valid := ebv(eval(f, b)); !ok || !valid {
+					keep = false
+					break
+				}
+			}
+			if keep {
+				sols = append(sols, b)
+			}
+		}
+	}
+
+	res := &Results{}
+	res.Head.Vars = t.q.vars
+	res.Results.Bindings = []map[string]Term{}
+	seen := make(map[string]bool)
+	skip := t.q.offset
+	for _, b := range sols {
+		if t.q.limit >= 0 && len(res.Results.Bindings) == t.q.limit {
+			break
+		}
+		row := make(map[string]Term)
+		for _, v := range t.q.vars {
+			if val, ok := b[v]; ok {
+				row[v] = toTerm(val)
+			}
+		}
+		if t.q.distinct {
+			key, err := json.Marshal(row)
+			x.Check(err)
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		res.Results.Bindings = append(res.Results.Bindings, row)
+	}
+	return res, nil
+}
+
+func toTerm(v interface{}) Term {
+	switch v := v.(type) {
+	case iri:
+		return Term{Type: "uri", Value: string(v)}
+	case string:
+		return Term{Type: "literal", Value: v}
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return Term{Type: "literal", Value: string(v), Datatype: xsd + "double"}
+		}
+		return Term{Type: "literal", Value: string(v), Datatype: xsd + "integer"}
+	case bool:
+		t := Term{Type: "literal", Value: "false", Datatype: xsd + "boolean"}
+		if v {
+			t.Value = "true"
+		}
+		return t
+	}
+	// Values like geo ones are given as their JSON.
+	js, err := json.Marshal(v)
+	x.Check(err)
+	return Term{Type: "literal", Value: string(js)}
+}
+
+// value returns the value of the constant t.
+func value(t term) interface{} {
+	if t.iri != "" {
+		return t.iri
+	}
+	return t.lit
+}
+
+// compare compares the values a and b, returning false if they can't be compared.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		fa, err := a.Float64()
+		if err != nil {
+			return 0, false
+		}
+		fb, err := b.Float64()
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		return strings.Compare(a, b), ok
+	case iri:
+		b, ok := b.(iri)
+		return strings.Compare(string(a), string(b)), ok
+	case bool:
+		b, ok := b.(bool)
+		switch {
+		case !ok || a == b:
+			return 0, ok
+		case !a:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// ebv returns the effective boolean value of v, or false if it has none.
+func ebv(v interface{}, valid bool) (bool, bool) {
+	if !valid {
+		return false, false
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		return v != "", true
+	case json.Number:
+		f, err := v.Float64()
+		return f != 0, err == nil
+	}
+	return false, false
+}
+
+// eval evaluates e for binding b, returning false if it raises an error, like for variables
+// which aren't bound.
+func eval(e *expr, b binding) (interface{}, bool) {
+	switch e.op {
+	case opTerm:
+		if e.t.variable == "" {
+			return value(e.t), true
+		}
+		v, ok := b[e.t.variable]
+		return v, ok
+	case opBound:
+		_, ok := b[e.t.variable]
+		return ok, true
+	case opRegex:
+		v, ok := eval(e.args[0], b)
+		s, isString := v.(string)
+		if !ok || !isString {
+			return nil, false
+		}
+		return e.re.MatchString(s), true
+	case opNot:
+		v, ok := ebv(eval(e.args[0], b))
+		return !v, ok
+	case opAnd, opOr:
+		l, lok := ebv(eval(e.args[0], b))
+		r, rok := ebv(eval(e.args[1], b))
+		// An error is only raised if the other side doesn't decide the result.
+		decisive := e.op == opOr
+		switch {
+		case (lok && l == decisive) || (rok && r == decisive):
+			return decisive, true
+		case lok && rok:
+			return !decisive, true
+		}
+		return nil, false
+	}
+
+	l, lok := eval(e.args[0], b)
+	r, rok := eval(e.args[1], b)
+	if !lok || !rok {
+		return nil, false
+	}
+	c, ok := compare(l, r)
+	switch {
+	case !ok && (e.op == "=" || e.op == "!="):
+		// Values of different types aren't equal.
+		return e.op == "!=", true
+	case !ok:
+		return nil, false
+	}
+	switch e.op {
+	case "=":
+		return c == 0, true
+	case "!=":
+		return c != 0, true
+	case "<":
+		return c < 0, true
+	case "<=":
+		return c <= 0, true
+	case ">":
+		return c > 0, true
+	}
+	return c >= 0, true
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sparql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/gql"
+)
+
+var schema = map[string]Predicate{
+	"name":   {Indexed: true},
+	"age":    {Indexed: true, Sortable: true},
+	"friend": {Uid: true},
+}
+
+func translate(t *testing.T, q string) *Translation {
+	query, err := Parse(q)
+	require.NoError(t, err)
+	tr, err := query.Translate(Options{Schema: schema})
+	require.NoError(t, err)
+	// The translation has to be a valid query.
+	_, err = gql.Parse(gql.Request{Str: tr.Query, Variables: tr.Vars})
+	require.NoError(t, err, tr.Query)
+	return tr
+}
+
+func TestTranslate(t *testing.T) {
+	tr := translate(t, `PREFIX : <>
+		SELECT ?n ?fn WHERE {
+			?p :name ?n ; :friend ?f .
+			?f :name ?fn ; :age ?age .
+			OPTIONAL { ?p :email ?e }
+			FILTER (?age > 30 && regex(?fn, "^b", "i"))
+		} LIMIT 10`)
+	require.Equal(t, `{
+  sparql(func: has(<name>)) {
+    _uid_
+    l1 : <name>
+    l5 : <email>
+    n2 : <friend> @filter(gt(<age>, $v0)) {
+      _uid_
+      l3 : <name>
+      l4 : <age>
+    }
+  }
+}
+`, tr.Query[len("query sparql($v0: string) "):])
+	require.Equal(t, map[string]string{"$v0": "30"}, tr.Vars)
+
+	tr = translate(t, `SELECT * { ?p <name> "Alice" ; <friend> <0x5> . FILTER(?p != <0x6>) }`)
+	require.Equal(t, `query sparql($v0: string) {
+  sparql(func: eq(<name>, $v0)) {
+    _uid_
+    l1 : <name>
+    l2 : <friend> @filter(uid(0x5)) { _uid_ }
+  }
+}
+`, tr.Query)
+
+	tr = translate(t, `PREFIX s: <http://schema.org/>
+		SELECT ?n { <0x1f> <friend> ?f . ?f s:name ?n }`)
+	require.Contains(t, tr.Query, "sparql(func: uid(0x1f))")
+	require.Contains(t, tr.Query, "l2 : <http://schema.org/name>")
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, q := range []string{
+		`CONSTRUCT { ?s <name> ?n } WHERE { ?s <name> ?n }`,
+		`SELECT ?s { ?s ?p ?o }`,
+		`SELECT ?s { ?s a <Person> }`,
+		`SELECT ?s { ?s <name> "Alice"@en }`,
+		`SELECT ?s { ?s foaf:name ?n }`,
+		`SELECT ?s { <alice> <name> ?n }`,
+		`SELECT ?s { ?s <name> ?n } ORDER BY ?n`,
+		`SELECT ?s { { ?s <name> ?n } UNION { ?s <title> ?n } }`,
+		`SELECT ?s { OPTIONAL { ?s <name> ?n } }`,
+		`SELECT ?s { ?s <name> ?n FILTER(lang(?n) = "en") }`,
+		`SELECT ?s { ?s <name> ?n FILTER(regex(?n, "[")) }`,
+	} {
+		_, err := Parse(q)
+		require.Error(t, err, q)
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	for _, q := range []string{
+		`SELECT * { ?a <name> ?n . ?b <name> ?m }`,
+		`SELECT * { ?a <friend> ?b . ?a <friend> ?c .
+			OPTIONAL { ?b <name> ?n . ?c <name> ?m } }`,
+	} {
+		query, err := Parse(q)
+		require.NoError(t, err)
+		_, err = query.Translate(Options{Schema: schema})
+		require.Error(t, err, q)
+	}
+}
+
+func results(t *testing.T, tr *Translation, data string) string {
+	res, err := tr.Results([]byte(data))
+	require.NoError(t, err)
+	js, err := json.Marshal(res)
+	require.NoError(t, err)
+	return string(js)
+}
+
+func TestResults(t *testing.T) {
+	tr := translate(t, `SELECT ?n ?fn ?e WHERE {
+			?p <name> ?n ; <friend> ?f .
+			?f <name> ?fn .
+			OPTIONAL { ?p <email> ?e }
+			FILTER (?fn != "Carol")
+		}`)
+	data := `{"sparql": [
+		{"_uid_": "0x1", "l1": "Alice", "l4": "alice@example.com",
+			"n2": [{"_uid_": "0x2", "l3": "Bob"}, {"_uid_": "0x3", "l3": "Carol"}]},
+		{"_uid_": "0x4", "l1": "Dave", "n2": [{"_uid_": "0x5", "l3": "Eve"}]},
+		{"_uid_": "0x6", "l1": "Frank"}]}`
+	require.JSONEq(t, `{"head": {"vars": ["n", "fn", "e"]}, "results": {"bindings": [
+		{"n": {"type": "literal", "value": "Alice"}, "fn": {"type": "literal", "value": "Bob"},
+			"e": {"type": "literal", "value": "alice@example.com"}},
+		{"n": {"type": "literal", "value": "Dave"}, "fn": {"type": "literal", "value": "Eve"}}
+	]}}`, results(t, tr, data))
+
+	// Variables bound twice have to have the same value, and filters on unbound variables fail.
+	tr = translate(t, `SELECT DISTINCT ?f ?age {
+			?p <friend> ?f ; <best> ?f .
+			OPTIONAL { ?f <age> ?age }
+			FILTER (!bound(?age) || ?age >= 30)
+		} OFFSET 1 LIMIT 1`)
+	data = `{"sparql": [
+		{"_uid_": "0x1", "l2": [{"_uid_": "0x2"}], "n1": [{"_uid_": "0x2", "l3": 30},
+			{"_uid_": "0x3", "l3": 40}]},
+		{"_uid_": "0x4", "l2": [{"_uid_": "0x5"}], "n1": [{"_uid_": "0x5"}]},
+		{"_uid_": "0x6", "l2": [{"_uid_": "0x5"}], "n1": [{"_uid_": "0x5"}]}]}`
+	require.JSONEq(t, `{"head": {"vars": ["f", "age"]}, "results": {"bindings": [
+		{"f": {"type": "uri", "value": "0x5"}}
+	]}}`, results(t, tr, data))
+
+	require.JSONEq(t, `{"head": {"vars": ["f", "age"]}, "results": {"bindings": []}}`,
+		results(t, tr, `{}`))
+}
@@ -0,0 +1,383 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sparql
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// block is the name of the query block of translated queries.
+const block = "sparql"
+
+// Predicate is what the translation needs to know of a predicate from the schema.
+type Predicate struct {
+	Uid      bool // If it's a uid predicate, of edges to nodes.
+	Indexed  bool // If it has an index eq can use.
+	Sortable bool // If it has an index inequalities can use.
+}
+
+// Options are the options of the translation of queries.
+type Options struct {
+	// Schema has the predicates of the triple patterns. Predicates missing from it are taken to
+	// be scalar predicates without an index.
+	Schema map[string]Predicate
+}
+
+// cond is a condition an index is used for, on a predicate of a node.
+type cond struct {
+	fn   string
+	pred string
+	val  interface{}
+}
+
+// node is a subject of triple patterns, with the triples of which it's the subject as its leaves,
+// or as its children for objects which are subjects of other triples.
+type node struct {
+	term     term
+	pred     string // The predicate of the edge from the parent.
+	alias    string
+	group    int // The OPTIONAL group of the node, from 1, or 0 if it's required.
+	conds    []cond
+	leaves   []*leaf
+	children []*node
+}
+
+// leaf is a triple pattern of which the object is a variable bound to the values of the
+// predicate, or a constant, which one of its values has to be equal to.
+type leaf struct {
+	variable string
+	want     *term
+	pred     string
+	alias    string
+	uid      bool
+	group    int
+}
+
+// Translation is a SPARQL query translated to a query of Dgraph.
+type Translation struct {
+	// Query is the query of Dgraph, in GraphQL+-, with the literals of the SPARQL query passed
+	// as the variables in Vars.
+	Query string
+	Vars  map[string]string
+
+	q    *Query
+	root *node
+}
+
+// Predicates returns the predicates of the triple patterns of q, for Options.Schema.
+func (q *Query) Predicates() []string {
+	var preds []string
+	seen := make(map[string]bool)
+	triples := q.triples
+	for _, opt := range q.optional {
+		triples = append(triples[:len(triples):len(triples)], opt...)
+	}
+	for _, t := range triples {
+		if !seen[t.pred] {
+			seen[t.pred] = true
+			preds = append(preds, t.pred)
+		}
+	}
+	return preds
+}
+
+type translator struct {
+	q        *Query
+	opts     Options
+	nodes    map[string]*node   // The nodes, by the keys of their terms.
+	leaves   map[string][]*leaf // The leaves of variables, by variable.
+	subjects map[string]bool    // The keys of the subjects of all triples.
+	count    int
+	vars     map[string]string
+	buf      bytes.Buffer
+}
+
+// Translate translates q to a query of Dgraph. The triple patterns must be connected, hanging
+// off a single subject, which the query starts from, and the ones of each OPTIONAL group off a
+// single node of the others.
+func (q *Query) Translate(opts Options) (*Translation, error) {
+	tr := &translator{
+		q:        q,
+		opts:     opts,
+		nodes:    make(map[string]*node),
+		leaves:   make(map[string][]*leaf),
+		subjects: make(map[string]bool),
+		vars:     make(map[string]string),
+	}
+
+	// The root is the first subject which isn't the object of another required triple.
+	objects := make(map[string]bool)
+	for _, t := range q.triples {
+		objects[t.o.key()] = true
+	}
+	root := &node{term: q.triples[0].s}
+	for _, t := range q.triples {
+		if !objects[t.s.key()] {
+			root.term = t.s
+			break
+		}
+	}
+	tr.nodes[root.term.key()] = root
+	for _, opt := range append([][]triple{q.triples}, q.optional...) {
+		for _, t := range opt {
+			tr.subjects[t.s.key()] = true
+		}
+	}
+	if err := tr.attach(q.triples, 0); err != nil {
+		return nil, err
+	}
+	for i, opt := range q.optional {
+		if err := tr.attach(opt, i+1); err != nil {
+			return nil, err
+		}
+	}
+	tr.pushDown(root)
+
+	f, err := tr.root(root)
+	if err != nil {
+		return nil, err
+	}
+	tr.node(root, block+"(func: "+f+")", "  ")
+
+	var query bytes.Buffer
+	if len(tr.vars) > 0 {
+		vars := make([]string, 0, len(tr.vars))
+		for v := range tr.vars {
+			vars = append(vars, v)
+		}
+		sort.Strings(vars)
+		query.WriteString("query " + block + "(")
+		for i, v := range vars {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(v + ": string")
+		}
+		query.WriteString(") ")
+	}
+	query.WriteString("{\n")
+	query.Write(tr.buf.Bytes())
+	query.WriteString("}\n")
+	return &Translation{Query: query.String(), Vars: tr.vars, q: q, root: root}, nil
+}
+
+// attach adds the triples of a group to the nodes their subjects are.
+func (tr *translator) attach(triples []triple, group int) error {
+	done := make([]bool, len(triples))
+	var parent *node
+	for progress := true; progress; {
+		progress = false
+		for i, t := range triples {
+			n, ok := tr.nodes[t.s.key()]
+			if done[i] || !ok {
+				continue
+			}
+			if n.group != 0 && n.group != group {
+				return x.Errorf("OPTIONAL groups can't use the subjects of other OPTIONAL groups")
+			}
+			if n.group != group {
+				if parent != nil && parent != n {
+					return x.Errorf("The triple patterns of an OPTIONAL group need to hang off a " +
+						"single node")
+				}
+				parent = n
+			}
+			done[i], progress = true, true
+			tr.count++
+
+			k := t.o.key()
+			if _, ok := tr.nodes[k]; k != "" && !ok && tr.subjects[k] {
+				c := &node{term: t.o, pred: t.pred, alias: fmt.Sprintf("n%d", tr.count),
+					group: group}
+				n.children = append(n.children, c)
+				tr.nodes[k] = c
+				continue
+			}
+			l := &leaf{pred: t.pred, alias: fmt.Sprintf("l%d", tr.count), group: group}
+			switch {
+			case t.o.variable != "":
+				_, joined := tr.nodes[k]
+				l.variable = t.o.variable
+				l.uid = tr.opts.Schema[t.pred].Uid || joined
+				tr.leaves[l.variable] = append(tr.leaves[l.variable], l)
+			case t.o.iri != "":
+				l.want, l.uid = &triples[i].o, true
+			default:
+				l.want = &triples[i].o
+			}
+			n.leaves = append(n.leaves, l)
+		}
+	}
+	for i, t := range triples {
+		if !done[i] {
+			return x.Errorf("The triple pattern of subject %s and predicate <%s> isn't connected "+
+				"to the others", t.s.key(), t.pred)
+		}
+	}
+	return nil
+}
+
+// conjuncts returns the expressions e is the conjunction of.
+func conjuncts(e *expr) []*expr {
+	if e.op == opAnd {
+		return append(conjuncts(e.args[0]), conjuncts(e.args[1])...)
+	}
+	return []*expr{e}
+}
+
+var (
+	functions = map[string]string{"=": "eq", "<": "lt", "<=": "le", ">": "gt", ">=": "ge"}
+	flipped   = map[string]string{"=": "=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}
+)
+
+// pushDown adds the conditions which can use indexes to the nodes of the required leaves they
+// are about, so that Dgraph only returns the nodes which can match. The conditions are still
+// checked in the results, as the values of predicates can be lists.
+func (tr *translator) pushDown(root *node) {
+	var visit func(n *node)
+	visit = func(n *node) {
+		for _, l := range n.leaves {
+			if l.group == 0 && l.want != nil && !l.uid && tr.opts.Schema[l.pred].Indexed {
+				n.conds = append(n.conds, cond{fn: "eq", pred: l.pred, val: l.want.lit})
+			}
+		}
+		for _, c := range n.children {
+			if c.group == 0 {
+				visit(c)
+			}
+		}
+	}
+	visit(root)
+
+	for _, f := range tr.q.filters {
+		for _, e := range conjuncts(f) {
+			if functions[e.op] == "" || e.args[0].op != opTerm || e.args[1].op != opTerm {
+				continue
+			}
+			v, lit, op := e.args[0].t, e.args[1].t, e.op
+			if v.variable == "" {
+				v, lit, op = lit, v, flipped[op]
+			}
+			if v.variable == "" || lit.lit == nil {
+				continue
+			}
+			for _, l := range tr.leaves[v.variable] {
+				p := tr.opts.Schema[l.pred]
+				if l.group != 0 || l.uid || !p.Indexed || (op != "=" && !p.Sortable) {
+					continue
+				}
+				n := tr.parent(l)
+				n.conds = append(n.conds, cond{fn: functions[op], pred: l.pred, val: lit.lit})
+			}
+		}
+	}
+}
+
+// parent returns the node of leaf l.
+func (tr *translator) parent(l *leaf) *node {
+	for _, n := range tr.nodes {
+		for _, nl := range n.leaves {
+			if nl == l {
+				return n
+			}
+		}
+	}
+	x.AssertTruef(false, "Leaf without a node: %s", l.alias)
+	return nil
+}
+
+func (tr *translator) value(v interface{}) string {
+	name := fmt.Sprintf("$v%d", len(tr.vars))
+	tr.vars[name] = fmt.Sprint(v)
+	return name
+}
+
+func (tr *translator) cond(c cond) string {
+	return c.fn + "(<" + c.pred + ">, " + tr.value(c.val) + ")"
+}
+
+// root returns the function of the query block, taking the first condition of the root node
+// out of its filter, or has of its first required predicate.
+func (tr *translator) root(n *node) (string, error) {
+	if n.term.iri != "" {
+		return "uid(" + string(n.term.iri) + ")", nil
+	}
+	if len(n.conds) > 0 {
+		c := n.conds[0]
+		n.conds = n.conds[1:]
+		return tr.cond(c), nil
+	}
+	for _, l := range n.leaves {
+		if l.group == 0 {
+			return "has(<" + l.pred + ">)", nil
+		}
+	}
+	for _, c := range n.children {
+		if c.group == 0 {
+			return "has(<" + c.pred + ">)", nil
+		}
+	}
+	return "", x.Errorf("No triple pattern of subject %s outside of OPTIONAL", n.term.key())
+}
+
+// filter returns the @filter directive of conds, and of the uid of a node, if any.
+func (tr *translator) filter(conds []cond, uid iri) string {
+	var fs []string
+	if uid != "" {
+		fs = append(fs, "uid("+string(uid)+")")
+	}
+	for _, c := range conds {
+		fs = append(fs, tr.cond(c))
+	}
+	if len(fs) == 0 {
+		return ""
+	}
+	return " @filter(" + strings.Join(fs, " and ") + ")"
+}
+
+// node writes the block of node n, as head, with the blocks of its leaves and children.
+func (tr *translator) node(n *node, head, indent string) {
+	uid := n.term.iri
+	if n.alias == "" {
+		// The uid of the root is the function of the block.
+		uid = ""
+	}
+	tr.buf.WriteString(indent + head + tr.filter(n.conds, uid) + " {\n")
+	in := indent + "  "
+	tr.buf.WriteString(in + "_uid_\n")
+	for _, l := range n.leaves {
+		tr.buf.WriteString(in + l.alias + " : <" + l.pred + ">")
+		if l.uid {
+			var want iri
+			if l.want != nil {
+				want = l.want.iri
+			}
+			tr.buf.WriteString(tr.filter(nil, want) + " { _uid_ }")
+		}
+		tr.buf.WriteString("\n")
+	}
+	for _, c := range n.children {
+		tr.node(c, c.alias+" : <"+c.pred+">", in)
+	}
+	tr.buf.WriteString(indent + "}\n")
+}
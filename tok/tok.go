@@ -24,6 +24,7 @@ import (
 	farm "github.com/dgryski/go-farm"
 	geom "github.com/twpayne/go-geom"
 
+	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -59,6 +60,7 @@ var (
 
 func init() {
 	RegisterTokenizer(GeoTokenizer{})
+	RegisterTokenizer(GeohashTokenizer{})
 	RegisterTokenizer(IntTokenizer{})
 	RegisterTokenizer(FloatTokenizer{})
 	RegisterTokenizer(YearTokenizer{})
@@ -101,19 +103,49 @@ func RegisterTokenizer(t Tokenizer) {
 	tokenizers[name] = t
 }
 
-type GeoTokenizer struct{}
+// GeoTokenizer covers geometries with S2 cells of the levels of Index, or of the default ones if
+// it's nil.
+type GeoTokenizer struct {
+	Index *protos.GeoIndex
+}
 
 func (t GeoTokenizer) Name() string       { return "geo" }
 func (t GeoTokenizer) Type() types.TypeID { return types.GeoID }
 func (t GeoTokenizer) Tokens(sv types.Val) ([]string, error) {
-	tokens, err := types.IndexGeoTokens(sv.Value.(geom.T))
-	EncodeGeoTokens(tokens)
+	tokens, err := types.IndexGeoTokens(sv.Value.(geom.T), t.Index)
+	EncodeGeoTokens(tokens, t.Index)
 	return tokens, err
 }
 func (t GeoTokenizer) Identifier() byte { return 0x5 }
 func (t GeoTokenizer) IsSortable() bool { return false }
 func (t GeoTokenizer) IsLossy() bool    { return true }
 
+// GeohashTokenizer covers geometries with geohashes of the precision of Index, or of the default
+// one if it's nil, for the index keys to be those of systems keyed by geohash.
+type GeohashTokenizer struct {
+	Index *protos.GeoIndex
+}
+
+func (t GeohashTokenizer) Name() string       { return "geohash" }
+func (t GeohashTokenizer) Type() types.TypeID { return types.GeoID }
+func (t GeohashTokenizer) Tokens(sv types.Val) ([]string, error) {
+	tokens, err := types.IndexGeohashTokens(sv.Value.(geom.T), t.Index)
+	encodeTokens(tokens, t.Identifier())
+	return tokens, err
+}
+func (t GeohashTokenizer) Identifier() byte { return 0xC }
+func (t GeohashTokenizer) IsSortable() bool { return false }
+func (t GeohashTokenizer) IsLossy() bool    { return true }
+
+// GeoIndexTokenizer returns the tokenizer of a geo index with the parameters gi, the geohash one
+// if they have a precision, and the S2 one otherwise.
+func GeoIndexTokenizer(gi *protos.GeoIndex) Tokenizer {
+	if types.IsGeohashIndex(gi) {
+		return GeohashTokenizer{Index: gi}
+	}
+	return GeoTokenizer{Index: gi}
+}
+
 type IntTokenizer struct{}
 
 func (t IntTokenizer) Name() string       { return "int" }
@@ -271,9 +303,14 @@ func encodeToken(tok string, typ byte) string {
 	return string(typ) + tok
 }
 
-func EncodeGeoTokens(tokens []string) {
+// EncodeGeoTokens encodes the tokens of a geo query for the geo index with the parameters gi.
+func EncodeGeoTokens(tokens []string, gi *protos.GeoIndex) {
+	encodeTokens(tokens, GeoIndexTokenizer(gi).Identifier())
+}
+
+func encodeTokens(tokens []string, typ byte) {
 	for i := 0; i < len(tokens); i++ {
-		tokens[i] = encodeToken(tokens[i], GeoTokenizer{}.Identifier())
+		tokens[i] = encodeToken(tokens[i], typ)
 	}
 }
 
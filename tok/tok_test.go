@@ -23,8 +23,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
 )
 
 type encL struct {
@@ -166,6 +168,21 @@ func TestTermTokenizer(t *testing.T) {
 	require.Equal(t, []string{encodeToken("tokenizer", id), encodeToken("works", id)}, tokens)
 }
 
+func TestGeohashTokenizer(t *testing.T) {
+	tokenizer, has := GetTokenizer("geohash")
+	require.True(t, has)
+	val := types.ValueForType(types.GeoID)
+	val.Value = geom.NewPointFlat(geom.XY, []float64{10.40744, 57.64911})
+	tokens, err := GeohashTokenizer{Index: &protos.GeoIndex{Precision: 3}}.Tokens(val)
+	require.NoError(t, err)
+	id := tokenizer.Identifier()
+	require.Equal(t, []string{encodeToken("p/u4p", id), encodeToken("p/u4", id),
+		encodeToken("p/u", id), encodeToken("c/u4p", id)}, tokens)
+
+	require.Equal(t, "geohash", GeoIndexTokenizer(&protos.GeoIndex{Precision: 3}).Name())
+	require.Equal(t, "geo", GeoIndexTokenizer(nil).Name())
+}
+
 func TestTrigramTokenizer(t *testing.T) {
 	tokenizer, has := GetTokenizer("trigram")
 	require.True(t, has)
@@ -0,0 +1,361 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package tracing records the spans of distributed traces of requests, and reports them to a
+// Jaeger or Zipkin collector, in the JSON format of Zipkin v2.
+//
+// The context of a trace is propagated in the Jaeger format, as
+// "<trace id>:<span id>:<parent span id>:<flags>" in hex, in the uber-trace-id header over HTTP
+// and metadata over gRPC. Requests coming with a sampled context continue its trace, and others
+// start a new one for the fraction of requests given by Options.Sample. Spans are only recorded
+// for sampled traces, and everything is a no-op on nil spans, so that code can start spans
+// without checking whether the request is traced.
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type Options struct {
+	// URL spans are posted to, like http://jaeger:9411/api/v2/spans. Spans aren't recorded if
+	// it's empty.
+	Collector string
+	// Service is the name of the service which spans are reported for.
+	Service string
+	// Sample is the fraction of requests without a trace context which start a trace.
+	Sample float64
+}
+
+var Config = Options{Service: "dgraph"}
+
+// Header is the HTTP header, and the gRPC metadata, carrying the context of traces.
+const Header = "uber-trace-id"
+
+const (
+	flagSampled = 1
+
+	maxPendingSpans = 10000
+	reportBatch     = 500
+	reportInterval  = time.Second
+)
+
+type spanKey struct{}
+
+// Span is a timed operation of a trace.
+type Span struct {
+	TraceId  uint64
+	Id       uint64
+	ParentId uint64
+	Name     string
+	Start    time.Time
+
+	mu   sync.Mutex
+	tags map[string]string
+	done bool
+}
+
+var (
+	rmu sync.Mutex
+	rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randomId() uint64 {
+	rmu.Lock()
+	defer rmu.Unlock()
+	for {
+		if id := uint64(rng.Int63())<<1 | uint64(rng.Int63()&1); id != 0 {
+			return id
+		}
+	}
+}
+
+// Enabled returns whether spans are reported.
+func Enabled() bool {
+	return Config.Collector != ""
+}
+
+// FromContext returns the span of ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// NewContext returns ctx with the span s.
+func NewContext(ctx context.Context, s *Span) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// ParseHeader parses the trace context of header. ok is false if it's invalid, and sampled
+// whether the trace is.
+func ParseHeader(header string) (traceId, spanId uint64, sampled, ok bool) {
+	parts := strings.Split(header, ":")
+	if len(parts) != 4 {
+		return 0, 0, false, false
+	}
+	// Trace ids can have 128 bits, of which the low 64 are kept.
+	tid := parts[0]
+	if len(tid) > 16 {
+		tid = tid[len(tid)-16:]
+	}
+	var err error
+	if traceId, err = strconv.ParseUint(tid, 16, 64); err != nil || traceId == 0 {
+		return 0, 0, false, false
+	}
+	if spanId, err = strconv.ParseUint(parts[1], 16, 64); err != nil || spanId == 0 {
+		return 0, 0, false, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return 0, 0, false, false
+	}
+	return traceId, spanId, flags&flagSampled != 0, true
+}
+
+// StartRoot starts the span name of a request which came with the trace context header, which
+// may be empty. It returns a nil span if the request isn't traced.
+func StartRoot(ctx context.Context, name, header string) (*Span, context.Context) {
+	if !Enabled() {
+		return nil, ctx
+	}
+	s := &Span{Name: name, Start: time.Now()}
+	if traceId, spanId, sampled, ok := ParseHeader(header); ok {
+		if !sampled {
+			return nil, ctx
+		}
+		s.TraceId, s.ParentId = traceId, spanId
+	} else {
+		rmu.Lock()
+		sample := rng.Float64() < Config.Sample
+		rmu.Unlock()
+		if !sample {
+			return nil, ctx
+		}
+		s.TraceId = randomId()
+	}
+	s.Id = randomId()
+	return s, NewContext(ctx, s)
+}
+
+// Start starts the span name as a child of the span of ctx. It returns a nil span if ctx has
+// none.
+func Start(ctx context.Context, name string) (*Span, context.Context) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return nil, ctx
+	}
+	s := &Span{
+		TraceId:  parent.TraceId,
+		Id:       randomId(),
+		ParentId: parent.Id,
+		Name:     name,
+		Start:    time.Now(),
+	}
+	return s, NewContext(ctx, s)
+}
+
+// SetTag sets the tag key of s to the value v.
+func (s *Span) SetTag(key string, v interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.tags == nil {
+		s.tags = make(map[string]string)
+	}
+	s.tags[key] = fmt.Sprint(v)
+	s.mu.Unlock()
+}
+
+// SetError tags s with err, if it's not nil.
+func (s *Span) SetError(err error) {
+	if err != nil {
+		s.SetTag("error", err.Error())
+	}
+}
+
+// Header returns the trace context of s, to propagate it to another service.
+func (s *Span) Header() string {
+	return fmt.Sprintf("%x:%x:%x:%x", s.TraceId, s.Id, s.ParentId, flagSampled)
+}
+
+// Finish ends s, and queues it to be reported. Spans finished more than once are only reported
+// the first time.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	tags := s.tags
+	s.mu.Unlock()
+	report(zipkinSpan{
+		TraceId:   fmt.Sprintf("%016x", s.TraceId),
+		Id:        fmt.Sprintf("%016x", s.Id),
+		ParentId:  parentId(s.ParentId),
+		Name:      s.Name,
+		Timestamp: s.Start.UnixNano() / 1e3,
+		Duration:  int64(time.Since(s.Start)/time.Microsecond) + 1,
+		Endpoint:  endpoint{Service: Config.Service},
+		Tags:      tags,
+	})
+}
+
+func parentId(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", id)
+}
+
+// Outgoing returns ctx with the trace context of its span in the metadata of outgoing gRPC
+// requests.
+func Outgoing(ctx context.Context) context.Context {
+	s := FromContext(ctx)
+	if s == nil {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md[Header] = []string{s.Header()}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// Incoming returns the trace context in the metadata of the gRPC request of ctx, if any.
+func Incoming(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md[Header]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// UnaryClientInterceptor propagates the trace context of requests to the servers they're sent to.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(Outgoing(ctx), method, req, reply, cc, opts...)
+}
+
+// UnaryServerInterceptor continues the traces of requests sent with a trace context, with a span
+// for the method. Requests without one aren't traced, as they're only sent between servers.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	header := Incoming(ctx)
+	if header == "" {
+		return handler(ctx, req)
+	}
+	s, ctx := StartRoot(ctx, info.FullMethod, header)
+	resp, err := handler(ctx, req)
+	s.SetError(err)
+	s.Finish()
+	return resp, err
+}
+
+type endpoint struct {
+	Service string `json:"serviceName"`
+}
+
+type zipkinSpan struct {
+	TraceId   string            `json:"traceId"`
+	Id        string            `json:"id"`
+	ParentId  string            `json:"parentId,omitempty"`
+	Name      string            `json:"name"`
+	Timestamp int64             `json:"timestamp"`
+	Duration  int64             `json:"duration"`
+	Endpoint  endpoint          `json:"localEndpoint"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+var pending = make(chan zipkinSpan, maxPendingSpans)
+
+var reporter sync.Once
+
+func report(s zipkinSpan) {
+	reporter.Do(func() { go reportSpans() })
+	select {
+	case pending <- s:
+	default:
+		x.DroppedSpans.Add(1)
+	}
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// post sends spans to the collector.
+func post(spans []zipkinSpan) error {
+	b, err := json.Marshal(spans)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(Config.Collector, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return x.Errorf("Got status %s from the trace collector", resp.Status)
+	}
+	return nil
+}
+
+// reportSpans posts the spans finished to the collector, in batches, every reportInterval.
+func reportSpans() {
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	var batch []zipkinSpan
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := post(batch); err != nil {
+			x.DroppedSpans.Add(int64(len(batch)))
+			x.Printf("Error while reporting %d spans: %v\n", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case s := <-pending:
+			batch = append(batch, s)
+			if len(batch) >= reportBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestParseHeader(t *testing.T) {
+	// 128 bit trace ids are truncated to their low 64 bits.
+	header := "80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1"
+	traceId, spanId, sampled, ok := ParseHeader(header)
+	require.True(t, ok)
+	require.True(t, sampled)
+	require.Equal(t, uint64(0x64fe8b2a57d3eff7), traceId)
+	require.Equal(t, uint64(0xe457b5a2e4d86bd1), spanId)
+
+	_, _, sampled, ok = ParseHeader("1:2:0:0")
+	require.True(t, ok)
+	require.False(t, sampled)
+
+	for _, h := range []string{"", "1:2:0", "0:2:0:1", "1:0:0:1", "x:2:0:1", "1:2:0:x"} {
+		_, _, _, ok := ParseHeader(h)
+		require.False(t, ok, h)
+	}
+
+	s := &Span{TraceId: 0xabc, Id: 0xdef, ParentId: 0x12}
+	traceId, spanId, sampled, ok = ParseHeader(s.Header())
+	require.True(t, ok && sampled)
+	require.Equal(t, s.TraceId, traceId)
+	require.Equal(t, s.Id, spanId)
+}
+
+func TestUntraced(t *testing.T) {
+	defer func(c Options) { Config = c }(Config)
+	Config.Collector = ""
+	span, ctx := StartRoot(context.Background(), "query", "1:2:0:1")
+	require.Nil(t, span)
+	child, _ := Start(ctx, "task")
+	require.Nil(t, child)
+	// Nil spans can be used without checks.
+	child.SetTag("attr", "name")
+	child.SetError(context.Canceled)
+	child.Finish()
+
+	Config.Collector, Config.Sample = "http://localhost", 0
+	span, _ = StartRoot(context.Background(), "query", "")
+	require.Nil(t, span)
+	span, _ = StartRoot(context.Background(), "query", "1:2:0:0")
+	require.Nil(t, span)
+}
+
+func TestReport(t *testing.T) {
+	received := make(chan []zipkinSpan, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var spans []zipkinSpan
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&spans))
+		received <- spans
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	defer func(c Options) { Config = c }(Config)
+	Config = Options{Collector: server.URL, Service: "test", Sample: 1}
+
+	root, ctx := StartRoot(context.Background(), "query", "")
+	require.NotNil(t, root)
+	require.Equal(t, uint64(0), root.ParentId)
+	child, ctx := Start(ctx, "task")
+	require.Equal(t, root.TraceId, child.TraceId)
+	require.Equal(t, root.Id, child.ParentId)
+	require.Equal(t, child, FromContext(ctx))
+	child.SetTag("attr", "name")
+	child.Finish()
+	child.Finish()
+	root.Finish()
+
+	var spans []zipkinSpan
+	timeout := time.After(5 * time.Second)
+	for len(spans) < 2 {
+		select {
+		case s := <-received:
+			spans = append(spans, s...)
+		case <-timeout:
+			t.Fatalf("Got %d spans, expected 2", len(spans))
+		}
+	}
+	require.Len(t, spans, 2)
+	require.Equal(t, "task", spans[0].Name)
+	require.Equal(t, map[string]string{"attr": "name"}, spans[0].Tags)
+	require.Equal(t, spans[1].Id, spans[0].ParentId)
+	require.Equal(t, spans[1].TraceId, spans[0].TraceId)
+	require.Equal(t, "test", spans[1].Endpoint.Service)
+	require.Empty(t, spans[1].ParentId)
+}
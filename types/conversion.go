@@ -25,8 +25,6 @@ import (
 	"time"
 
 	geom "github.com/twpayne/go-geom"
-	"github.com/twpayne/go-geom/encoding/geojson"
-	"github.com/twpayne/go-geom/encoding/wkb"
 
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/x"
@@ -77,7 +75,7 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				}
 				*res = t
 			case GeoID:
-				w, err := wkb.Unmarshal(data)
+				w, err := UnmarshalWKB(data)
 				if err != nil {
 					return to, err
 				}
@@ -125,7 +123,7 @@ func Convert(from Val, toID TypeID) (Val, error) {
 			case GeoID:
 				var g geom.T
 				text := bytes.Replace([]byte(vc), []byte("'"), []byte("\""), -1)
-				if err := geojson.Unmarshal(text, &g); err != nil {
+				if err := UnmarshalGeoJSON(text, &g); err != nil {
 					return to, err
 				}
 				*res = g
@@ -278,7 +276,7 @@ func Convert(from Val, toID TypeID) (Val, error) {
 		}
 	case GeoID:
 		{
-			vc, err := wkb.Unmarshal(data)
+			vc, err := UnmarshalWKB(data)
 			if err != nil {
 				return to, err
 			}
@@ -287,13 +285,13 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				*res = vc
 			case BinaryID:
 				// Marshal Binary
-				r, err := wkb.Marshal(vc, binary.LittleEndian)
+				r, err := MarshalWKB(vc)
 				if err != nil {
 					return to, err
 				}
 				*res = r
 			case StringID, DefaultID:
-				val, err := geojson.Marshal(vc)
+				val, err := MarshalGeoJSON(vc)
 				if err != nil {
 					return to, nil
 				}
@@ -422,13 +420,13 @@ func Marshal(from Val, to *Val) error {
 		switch toID {
 		case BinaryID:
 			// Marshal Binary
-			r, err := wkb.Marshal(vc, binary.LittleEndian)
+			r, err := MarshalWKB(vc)
 			if err != nil {
 				return err
 			}
 			*res = r
 		case StringID, DefaultID:
-			val, err := geojson.Marshal(vc)
+			val, err := MarshalGeoJSON(vc)
 			if err != nil {
 				return nil
 			}
@@ -544,7 +542,7 @@ func (v Val) MarshalJSON() ([]byte, error) {
 	case DateTimeID:
 		return json.Marshal(v.Value.(time.Time))
 	case GeoID:
-		return geojson.Marshal(v.Value.(geom.T))
+		return MarshalGeoJSON(v.Value.(geom.T))
 	case StringID, DefaultID:
 		return json.Marshal(v.Value.(string))
 	case PasswordID:
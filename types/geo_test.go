@@ -25,6 +25,9 @@ func TestParse(t *testing.T) {
 	array := []string{
 		`{'type':'Point','coordinates':[1,2]}`,
 		`{'type':'MultiLineString','coordinates':[[[1,2,3],[4,5,6],[7,8,9],[1,2,3]]]}`,
+		`{'type':'MultiPoint','coordinates':[[1,2],[3,4]]}`,
+		`{'type':'GeometryCollection','geometries':[{'type':'Point','coordinates':[1,2]},` +
+			`{'type':'GeometryCollection','geometries':[{'type':'LineString','coordinates':[[1,2],[3,4]]}]}]}`,
 	}
 	for _, v := range array {
 		src := Val{StringID, []byte(v)}
@@ -61,6 +64,7 @@ func TestParseGeoJsonErrors(t *testing.T) {
 		`{"type":"Curve","coordinates":[1,2]}`,
 		`{"type":"Feature","geometry":{"type":"Point","coordinates":[125.6,10.1]},"properties":{"name":"Dinagat Islands"}}`,
 		`{}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Curve","coordinates":[1,2]}]}`,
 		`thisisntjson`,
 	}
 	for _, v := range array {
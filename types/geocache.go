@@ -0,0 +1,226 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+
+	farm "github.com/dgryski/go-farm"
+	"github.com/golang/geo/s2"
+	geom "github.com/twpayne/go-geom"
+)
+
+// DefaultGeoCacheSize is the size in bytes of the cache of decoded geo values, unless set with
+// SetGeoCacheSize.
+const DefaultGeoCacheSize = 64 << 20
+
+// geoCache is an LRU cache of the geometries decoded from geo values, along with the s2 polygons
+// and polylines they convert to, so that geo queries matching the same values over and over don't
+// decode and convert them each time. Entries are keyed by the fingerprint of the value bytes, so a
+// mutated value is a different key and never finds the geometry of the old one, which is evicted
+// once unused.
+type geoCache struct {
+	sync.Mutex
+
+	maxSize uint64
+	curSize uint64
+	hits    uint64
+	misses  uint64
+	ll      *list.List
+	cache   map[uint64]*list.Element
+	// geoms maps the geometries of the entries back to them, for the shapes of a geometry given to
+	// a query to be found.
+	geoms map[geom.T]*geoEntry
+}
+
+type geoEntry struct {
+	key  uint64
+	val  []byte
+	g    geom.T
+	size uint64
+
+	// The s2 shapes of g are converted the first time a query needs them.
+	once  sync.Once
+	polys []*polygon
+	lines []*s2.Polyline
+	err   error
+}
+
+var gcache = newGeoCache(DefaultGeoCacheSize)
+
+func newGeoCache(maxSize uint64) *geoCache {
+	return &geoCache{
+		maxSize: maxSize,
+		ll:      list.New(),
+		cache:   make(map[uint64]*list.Element),
+		geoms:   make(map[geom.T]*geoEntry),
+	}
+}
+
+// SetGeoCacheSize sets the size in bytes of the cache of decoded geo values, evicting entries
+// if it shrinks. Zero disables the cache.
+func SetGeoCacheSize(size uint64) {
+	gcache.Lock()
+	defer gcache.Unlock()
+	gcache.maxSize = size
+	gcache.removeOldest()
+}
+
+// decodeGeo returns the geometry of the WKB encoded geo value val, from the cache if it was
+// decoded before.
+func decodeGeo(val []byte) (geom.T, error) {
+	return gcache.get(val)
+}
+
+func (c *geoCache) get(val []byte) (geom.T, error) {
+	key := farm.Fingerprint64(val)
+	c.Lock()
+	if ele, ok := c.cache[key]; ok {
+		// Fingerprints can collide, in which case the value is decoded and replaces the entry.
+		if e := ele.Value.(*geoEntry); bytes.Equal(e.val, val) {
+			c.ll.MoveToFront(ele)
+			c.hits++
+			c.Unlock()
+			return e.g, nil
+		}
+	}
+	c.misses++
+	c.Unlock()
+
+	// Values are decoded outside the lock, so that goroutines filtering different shards of values
+	// don't wait on each other.
+	g, err := UnmarshalWKB(val)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, val, g)
+	return g, nil
+}
+
+func (c *geoCache) put(key uint64, val []byte, g geom.T) {
+	// Besides the value, the flat coordinates of the geometry take about as much memory, and the
+	// s2 points they convert to a bit more.
+	size := uint64(4 * len(val))
+	if size < 100 {
+		size = 100
+	}
+	c.Lock()
+	defer c.Unlock()
+	if size > c.maxSize {
+		return
+	}
+	if ele, ok := c.cache[key]; ok {
+		c.remove(ele)
+	}
+	e := &geoEntry{key: key, val: append([]byte(nil), val...), g: g, size: size}
+	c.cache[key] = c.ll.PushFront(e)
+	c.geoms[g] = e
+	c.curSize += size
+	c.removeOldest()
+}
+
+func (c *geoCache) removeOldest() {
+	for c.curSize > c.maxSize {
+		ele := c.ll.Back()
+		if ele == nil {
+			c.curSize = 0
+			return
+		}
+		c.remove(ele)
+	}
+}
+
+func (c *geoCache) remove(ele *list.Element) {
+	e := ele.Value.(*geoEntry)
+	c.ll.Remove(ele)
+	delete(c.cache, e.key)
+	delete(c.geoms, e.g)
+	c.curSize -= e.size
+}
+
+// entry returns the entry of the geometry g if it's in the cache.
+func (c *geoCache) entry(g geom.T) *geoEntry {
+	c.Lock()
+	defer c.Unlock()
+	return c.geoms[g]
+}
+
+// shapes returns the s2 polygons and polylines of the geometry of the entry, converting them the
+// first time.
+func (e *geoEntry) shapes() ([]*polygon, []*s2.Polyline, error) {
+	e.once.Do(func() {
+		e.polys, e.lines, e.err = convertShapes(e.g)
+	})
+	return e.polys, e.lines, e.err
+}
+
+// convertShapes converts the polygons of a geom.Polygon or geom.MultiPolygon, or the lines of a
+// geom.LineString or geom.MultiLineString, to s2.
+func convertShapes(g geom.T) ([]*polygon, []*s2.Polyline, error) {
+	switch v := g.(type) {
+	case *geom.Polygon:
+		p, err := polygonFromPolygon(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*polygon{p}, nil, nil
+	case *geom.MultiPolygon:
+		polys := make([]*polygon, 0, v.NumPolygons())
+		for i := 0; i < v.NumPolygons(); i++ {
+			p, err := polygonFromPolygon(v.Polygon(i))
+			if err != nil {
+				return nil, nil, err
+			}
+			polys = append(polys, p)
+		}
+		return polys, nil, nil
+	case *geom.LineString:
+		p, err := polylineFromLineString(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, []*s2.Polyline{p}, nil
+	case *geom.MultiLineString:
+		lines, err := polylinesFromMultiLineString(v)
+		return nil, lines, err
+	}
+	return nil, nil, nil
+}
+
+// s2Polygons returns the polygons of a geom.Polygon or geom.MultiPolygon converted to s2, which
+// for geometries from the cache are only converted once.
+func s2Polygons(g geom.T) ([]*polygon, error) {
+	if e := gcache.entry(g); e != nil {
+		polys, _, err := e.shapes()
+		return polys, err
+	}
+	polys, _, err := convertShapes(g)
+	return polys, err
+}
+
+// s2Polylines returns the lines of a geom.LineString or geom.MultiLineString converted to s2,
+// which for geometries from the cache are only converted once.
+func s2Polylines(g geom.T) ([]*s2.Polyline, error) {
+	if e := gcache.entry(g); e != nil {
+		_, lines, err := e.shapes()
+		return lines, err
+	}
+	_, lines, err := convertShapes(g)
+	return lines, err
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/dgraph-io/dgraph/protos"
+	farm "github.com/dgryski/go-farm"
+	"github.com/stretchr/testify/require"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+func squareWKB(t testing.TB, x0, y0, side float64) []byte {
+	p := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{x0, y0}, {x0 + side, y0}, {x0 + side, y0 + side}, {x0, y0 + side}, {x0, y0}},
+	})
+	d, err := wkb.Marshal(p, binary.LittleEndian)
+	require.NoError(t, err)
+	return d
+}
+
+func TestGeoCacheGet(t *testing.T) {
+	c := newGeoCache(1 << 20)
+	val := squareWKB(t, -122.4, 37.7, 0.1)
+	g1, err := c.get(val)
+	require.NoError(t, err)
+	g2, err := c.get(append([]byte(nil), val...))
+	require.NoError(t, err)
+	require.True(t, g1 == g2, "the geometry should come from the cache")
+	require.Equal(t, uint64(1), c.hits)
+	require.Equal(t, uint64(1), c.misses)
+
+	// A mutated value is another key.
+	g3, err := c.get(squareWKB(t, -122.4, 37.7, 0.2))
+	require.NoError(t, err)
+	require.False(t, g1 == g3)
+	require.Equal(t, 2, c.ll.Len())
+
+	_, err = c.get([]byte("not wkb"))
+	require.Error(t, err)
+	require.Equal(t, 2, c.ll.Len())
+}
+
+func TestGeoCacheEvict(t *testing.T) {
+	val := squareWKB(t, -122.4, 37.7, 0.1)
+	size := uint64(4 * len(val))
+	c := newGeoCache(2 * size)
+	for i := 0; i < 3; i++ {
+		_, err := c.get(squareWKB(t, -122.4, 37.7, float64(i+1)/10))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, c.ll.Len())
+	require.Equal(t, 2*size, c.curSize)
+	require.Len(t, c.geoms, 2)
+	// The least recently used one was evicted.
+	_, ok := c.cache[farm.Fingerprint64(squareWKB(t, -122.4, 37.7, 0.1))]
+	require.False(t, ok)
+
+	// Values bigger than the cache aren't kept.
+	c = newGeoCache(0)
+	_, err := c.get(val)
+	require.NoError(t, err)
+	require.Equal(t, 0, c.ll.Len())
+}
+
+func TestGeoCacheShapes(t *testing.T) {
+	g, err := decodeGeo(squareWKB(t, -122.4, 37.7, 0.1))
+	require.NoError(t, err)
+	p1, err := s2Polygons(g)
+	require.NoError(t, err)
+	p2, err := s2Polygons(g)
+	require.NoError(t, err)
+	require.Len(t, p1, 1)
+	require.True(t, p1[0] == p2[0], "the polygon should only be converted once")
+
+	// Geometries which aren't cached are converted each time.
+	u := geom.NewPolygon(geom.XY).MustSetCoords(g.(*geom.Polygon).Coords())
+	p3, err := s2Polygons(u)
+	require.NoError(t, err)
+	require.False(t, p1[0] == p3[0])
+	require.Equal(t, p1[0].loop.Vertices(), p3[0].loop.Vertices())
+}
+
+func TestFilterGeoUidsCached(t *testing.T) {
+	var values []*protos.TaskValue
+	for i := 0; i < 20; i++ {
+		x0 := -122.5 + float64(i)/20
+		values = append(values, &protos.TaskValue{Val: squareWKB(t, x0, 37.5, 0.02),
+			ValType: int32(GeoID)})
+	}
+	uids := &protos.List{}
+	for i := range values {
+		uids.Uids = append(uids.Uids, uint64(i+1))
+	}
+	_, qd, err := GetGeoTokens([]string{"within", "loc",
+		"[[[-122.4, 37.4], [-122.1, 37.4], [-122.1, 37.6], [-122.4, 37.6], [-122.4, 37.4]]]"}, nil)
+	require.NoError(t, err)
+
+	defer SetGeoCacheSize(DefaultGeoCacheSize)
+	SetGeoCacheSize(0)
+	want, _ := FilterGeoUids(uids, values, qd)
+	require.NotEmpty(t, want.Uids)
+	SetGeoCacheSize(DefaultGeoCacheSize)
+	for i := 0; i < 2; i++ {
+		got, _ := FilterGeoUids(uids, values, qd)
+		require.Equal(t, want.Uids, got.Uids)
+	}
+}
+
+func BenchmarkFilterGeoUidsPolygons(b *testing.B) {
+	var values []*protos.TaskValue
+	uids := &protos.List{}
+	for i := 0; i < 10000; i++ {
+		x0, y0 := -122.5+float64(i%100)/200, 37.2+float64(i/100)/200
+		values = append(values, &protos.TaskValue{Val: squareWKB(b, x0, y0, 0.004),
+			ValType: int32(GeoID)})
+		uids.Uids = append(uids.Uids, uint64(i+1))
+	}
+	_, qd, err := GetGeoTokens([]string{"intersects", "loc",
+		"[[[-122.4, 37.3], [-122.1, 37.3], [-122.1, 37.6], [-122.4, 37.6], [-122.4, 37.3]]]"}, nil)
+	require.NoError(b, err)
+	defer SetGeoCacheSize(DefaultGeoCacheSize)
+	for _, size := range []uint64{0, DefaultGeoCacheSize} {
+		SetGeoCacheSize(size)
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				filterGeoUids(uids, values, qd, 1)
+			}
+		})
+	}
+}
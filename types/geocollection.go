@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// GeometryCollection is a geom.T made of other geometries. go-geom doesn't have one, so we
+// encode it to and from WKB and GeoJSON ourselves, with the functions below which handle all the
+// geometries we store.
+type GeometryCollection struct {
+	geoms []geom.T
+}
+
+// NewGeometryCollection returns a GeometryCollection of the geometries geoms.
+func NewGeometryCollection(geoms ...geom.T) *GeometryCollection {
+	return &GeometryCollection{geoms: geoms}
+}
+
+// Geoms returns the geometries of the collection.
+func (gc *GeometryCollection) Geoms() []geom.T {
+	return gc.geoms
+}
+
+// Layout returns the layout of the geometries of the collection, which should all have the same.
+func (gc *GeometryCollection) Layout() geom.Layout {
+	if len(gc.geoms) == 0 {
+		return geom.NoLayout
+	}
+	return gc.geoms[0].Layout()
+}
+
+// Stride returns the stride of the layout of the collection.
+func (gc *GeometryCollection) Stride() int {
+	return gc.Layout().Stride()
+}
+
+// Bounds returns the bounds of all the geometries of the collection.
+func (gc *GeometryCollection) Bounds() *geom.Bounds {
+	b := geom.NewBounds(gc.Layout())
+	for _, g := range gc.geoms {
+		b.Extend(g)
+	}
+	return b
+}
+
+// FlatCoords returns the coordinates of all the geometries of the collection.
+func (gc *GeometryCollection) FlatCoords() []float64 {
+	var coords []float64
+	for _, g := range gc.geoms {
+		coords = append(coords, g.FlatCoords()...)
+	}
+	return coords
+}
+
+// Ends returns nil, as the coordinates of a collection aren't made of rings or lines.
+func (gc *GeometryCollection) Ends() []int {
+	return nil
+}
+
+// Endss returns nil, as the coordinates of a collection aren't made of polygons.
+func (gc *GeometryCollection) Endss() [][]int {
+	return nil
+}
+
+// SRID returns 0, as we don't keep the SRID of geometries.
+func (gc *GeometryCollection) SRID() int {
+	return 0
+}
+
+// MarshalWKB returns the little endian WKB encoding of the geometry g.
+func MarshalWKB(g geom.T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeWKB(&buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeWKB(w io.Writer, g geom.T) error {
+	gc, ok := g.(*GeometryCollection)
+	if !ok {
+		return wkb.Write(w, wkb.NDR, g)
+	}
+	if err := wkbcommon.WriteByte(w, wkbcommon.NDRID); err != nil {
+		return err
+	}
+	if err := wkbcommon.WriteUInt32(w, wkb.NDR, wkbcommon.GeometryCollectionID); err != nil {
+		return err
+	}
+	if err := wkbcommon.WriteUInt32(w, wkb.NDR, uint32(len(gc.geoms))); err != nil {
+		return err
+	}
+	for _, m := range gc.geoms {
+		if err := writeWKB(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalWKB decodes the WKB encoded geometry data.
+func UnmarshalWKB(data []byte) (geom.T, error) {
+	return readWKB(bytes.NewReader(data))
+}
+
+func readWKB(r *bytes.Reader) (geom.T, error) {
+	// Look at the byte order and type of the geometry, and leave all but collections to wkb.
+	start := r.Len()
+	order, err := wkbcommon.ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	var byteOrder binary.ByteOrder
+	switch order {
+	case wkbcommon.XDRID:
+		byteOrder = wkb.XDR
+	case wkbcommon.NDRID:
+		byteOrder = wkb.NDR
+	default:
+		return nil, wkbcommon.ErrUnknownByteOrder(order)
+	}
+	typ, err := wkbcommon.ReadUInt32(r, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if typ%1000 != wkbcommon.GeometryCollectionID {
+		if _, err := r.Seek(int64(r.Len()-start), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+		return wkb.Read(r)
+	}
+	n, err := wkbcommon.ReadUInt32(r, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > r.Len() {
+		return nil, x.Errorf("Invalid WKB: collection of %d geometries in %d bytes", n, r.Len())
+	}
+	geoms := make([]geom.T, 0, n)
+	for i := uint32(0); i < n; i++ {
+		g, err := readWKB(r)
+		if err != nil {
+			return nil, err
+		}
+		geoms = append(geoms, g)
+	}
+	return NewGeometryCollection(geoms...), nil
+}
+
+// geoJSONCollection is the GeoJSON object of a GeometryCollection.
+type geoJSONCollection struct {
+	Type       string            `json:"type"`
+	Geometries []json.RawMessage `json:"geometries"`
+}
+
+const geoJSONCollectionType = "GeometryCollection"
+
+// MarshalGeoJSON returns the GeoJSON encoding of the geometry g.
+func MarshalGeoJSON(g geom.T) ([]byte, error) {
+	gc, ok := g.(*GeometryCollection)
+	if !ok {
+		return geojson.Marshal(g)
+	}
+	c := geoJSONCollection{
+		Type:       geoJSONCollectionType,
+		Geometries: make([]json.RawMessage, 0, len(gc.geoms)),
+	}
+	for _, m := range gc.geoms {
+		b, err := MarshalGeoJSON(m)
+		if err != nil {
+			return nil, err
+		}
+		c.Geometries = append(c.Geometries, b)
+	}
+	return json.Marshal(c)
+}
+
+// UnmarshalGeoJSON decodes the GeoJSON encoded geometry data into g.
+func UnmarshalGeoJSON(data []byte, g *geom.T) error {
+	var c geoJSONCollection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	if c.Type != geoJSONCollectionType {
+		return geojson.Unmarshal(data, g)
+	}
+	geoms := make([]geom.T, 0, len(c.Geometries))
+	for _, raw := range c.Geometries {
+		var m geom.T
+		if err := UnmarshalGeoJSON(raw, &m); err != nil {
+			return err
+		}
+		geoms = append(geoms, m)
+	}
+	*g = NewGeometryCollection(geoms...)
+	return nil
+}
@@ -18,9 +18,15 @@ package types
 
 import (
 	"bytes"
+	"math"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 	"github.com/twpayne/go-geom"
 
@@ -34,26 +40,50 @@ type QueryType byte
 const (
 	// QueryTypeWithin finds all points that are within the given geometry
 	QueryTypeWithin QueryType = iota
-	// QueryTypeContains finds all polygons that contain the given point
+	// QueryTypeContains finds all polygons that contain the given point, polygon or line
 	QueryTypeContains
 	// QueryTypeIntersects finds all objects that intersect the given geometry
 	QueryTypeIntersects
 	// QueryTypeNear finds all points that are within the given distance from the given point.
 	QueryTypeNear
+	// QueryTypeNearest finds the k points closest to the given point.
+	QueryTypeNearest
+	// QueryTypeWithinBox finds all objects that are within the given box of latitudes and
+	// longitudes.
+	QueryTypeWithinBox
+	// QueryTypeDisjoint finds all objects that don't intersect the given geometry.
+	QueryTypeDisjoint
+	// QueryTypeOverlaps finds all objects that intersect the given polygon, without being within
+	// it or containing it.
+	QueryTypeOverlaps
+)
+
+const (
+	// nearestRadius is the radius in metres of the first cap looked up by a nearest query.
+	nearestRadius = 1000
+	// nearestGrowth is how many times larger the radius of the cap of a nearest query gets, each
+	// time fewer than k points are found within it.
+	nearestGrowth = 4
 )
 
 // GeoQueryData is internal data used by the geo query filter to additionally filter the geometries.
 type GeoQueryData struct {
-	pt    *s2.Point  // If not nil, the input data was a point
-	loops []*s2.Loop // If not empty, the input data was a polygon/multipolygon.
-	cap   *s2.Cap    // If not nil, the cap to be used for a near query
+	pt    *s2.Point        // If not nil, the input data was a point
+	polys []*polygon       // If not empty, the input data was a polygon/multipolygon.
+	lines []*s2.Polyline   // If not empty, the input data was a linestring/multilinestring.
+	cap   *s2.Cap          // If not nil, the cap to be used for a near or nearest query
+	inner *s2.Cap          // If not nil, the cap of a near query's min distance, to be excluded
+	rect  *s2.Rect         // If not nil, the box to be used for a withinbox query
+	k     int              // The number of points a nearest query returns.
+	index *protos.GeoIndex // The parameters of the geo index the cap is covered for.
 	qtype QueryType
 }
 
 // IsGeoFunc returns if a function is of geo type.
 func IsGeoFunc(str string) bool {
 	switch str {
-	case "near", "contains", "within", "intersects":
+	case "near", "nearest", "contains", "within", "withinbox", "intersects", "disjoint",
+		"overlaps":
 		return true
 	}
 
@@ -61,28 +91,66 @@ func IsGeoFunc(str string) bool {
 }
 
 // GetGeoTokens returns the corresponding index keys based on the type
-// of function.
-func GetGeoTokens(funcArgs []string) ([]string, *GeoQueryData, error) {
+// of function, for a geo index with the parameters gi, which are the default ones if gi is nil.
+func GetGeoTokens(funcArgs []string, gi *protos.GeoIndex) ([]string, *GeoQueryData, error) {
 	x.AssertTruef(len(funcArgs) > 1, "Invalid function")
 	funcName := strings.ToLower(funcArgs[0])
 	switch funcName {
 	case "near":
+		// The distance can be preceded by a minimum distance, for the ring between the two.
+		if len(funcArgs) != 4 && len(funcArgs) != 5 {
+			return nil, nil, x.Errorf("near function requires 2 or 3 arguments, but got %d",
+				len(funcArgs))
+		}
+		dists := make([]float64, 0, 2)
+		for _, arg := range funcArgs[3:] {
+			d, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, nil, x.Wrapf(err, "Error while converting distance to float")
+			}
+			if d < 0 {
+				return nil, nil, x.Errorf("Distance cannot be negative")
+			}
+			dists = append(dists, d)
+		}
+		maxDist := dists[len(dists)-1]
+		g, err := convertToGeom(funcArgs[2])
+		if err != nil {
+			return nil, nil, err
+		}
+		toks, qd, err := queryTokensGeo(QueryTypeNear, g, maxDist, gi)
+		if err != nil || len(dists) == 1 {
+			return toks, qd, err
+		}
+		if dists[0] >= maxDist {
+			return nil, nil, x.Errorf("Min distance %v should be less than max distance %v",
+				dists[0], maxDist)
+		}
+		inner := s2.CapFromCenterAngle(qd.cap.Center(), EarthAngle(dists[0]))
+		qd.inner = &inner
+		return toks, qd, nil
+	case "nearest":
 		if len(funcArgs) != 4 {
-			return nil, nil, x.Errorf("near function requires 2 arguments, but got %d",
+			return nil, nil, x.Errorf("nearest function requires 2 arguments, but got %d",
 				len(funcArgs))
 		}
-		maxDist, err := strconv.ParseFloat(funcArgs[3], 64)
+		k, err := strconv.Atoi(funcArgs[3])
 		if err != nil {
-			return nil, nil, x.Wrapf(err, "Error while converting distance to float")
+			return nil, nil, x.Wrapf(err, "Error while converting number of points to int")
 		}
-		if maxDist < 0 {
-			return nil, nil, x.Errorf("Distance cannot be negative")
+		if k <= 0 {
+			return nil, nil, x.Errorf("Number of points should be positive")
 		}
 		g, err := convertToGeom(funcArgs[2])
 		if err != nil {
 			return nil, nil, err
 		}
-		return queryTokensGeo(QueryTypeNear, g, maxDist)
+		toks, qd, err := queryTokensGeo(QueryTypeNearest, g, nearestRadius, gi)
+		if err != nil {
+			return nil, nil, err
+		}
+		qd.k = k
+		return toks, qd, nil
 	case "within":
 		if len(funcArgs) != 3 {
 			return nil, nil, x.Errorf("within function requires 1 arguments, but got %d",
@@ -92,7 +160,21 @@ func GetGeoTokens(funcArgs []string) ([]string, *GeoQueryData, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		return queryTokensGeo(QueryTypeWithin, g, 0.0)
+		return queryTokensGeo(QueryTypeWithin, g, 0.0, gi)
+	case "withinbox":
+		if len(funcArgs) != 4 {
+			return nil, nil, x.Errorf("withinbox function requires 2 arguments, but got %d",
+				len(funcArgs))
+		}
+		lo, err := convertToGeom(funcArgs[2])
+		if err != nil {
+			return nil, nil, err
+		}
+		hi, err := convertToGeom(funcArgs[3])
+		if err != nil {
+			return nil, nil, err
+		}
+		return boxQueryKeys(lo, hi, gi)
 	case "contains":
 		if len(funcArgs) != 3 {
 			return nil, nil, x.Errorf("contains function requires 1 arguments, but got %d",
@@ -102,28 +184,39 @@ func GetGeoTokens(funcArgs []string) ([]string, *GeoQueryData, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		return queryTokensGeo(QueryTypeContains, g, 0.0)
-	case "intersects":
+		return queryTokensGeo(QueryTypeContains, g, 0.0, gi)
+	case "intersects", "disjoint", "overlaps":
 		if len(funcArgs) != 3 {
-			return nil, nil, x.Errorf("intersects function requires 1 arguments, but got %d",
+			return nil, nil, x.Errorf("%s function requires 1 arguments, but got %d", funcName,
 				len(funcArgs))
 		}
 		g, err := convertToGeom(funcArgs[2])
 		if err != nil {
 			return nil, nil, err
 		}
-		return queryTokensGeo(QueryTypeIntersects, g, 0.0)
+		qt := QueryTypeIntersects
+		if funcName == "disjoint" {
+			qt = QueryTypeDisjoint
+		} else if funcName == "overlaps" {
+			qt = QueryTypeOverlaps
+		}
+		return queryTokensGeo(qt, g, 0.0, gi)
 	default:
 		return nil, nil, x.Errorf("Invalid geo function")
 	}
 }
 
 // queryTokensGeo returns the tokens to be used to look up the geo index for a given filter.
-// qt is the type of Geo query - near/intersects/contains/within
-// g is the geom.T representation of the input. It could be a point/polygon/multipolygon.
-// maxDistance is distance in metres, only used for near query.
-func queryTokensGeo(qt QueryType, g geom.T, maxDistance float64) ([]string, *GeoQueryData, error) {
-	var loops []*s2.Loop
+// qt is the type of Geo query - near/nearest/intersects/contains/within
+// g is the geom.T representation of the input. It could be a point/polygon/multipolygon or a
+// linestring/multilinestring.
+// maxDistance is distance in metres, only used for near query, and as the radius of the first cap
+// looked up for a nearest query.
+// gi holds the parameters of the geo index looked up, or is nil for the default ones.
+func queryTokensGeo(qt QueryType, g geom.T, maxDistance float64,
+	gi *protos.GeoIndex) ([]string, *GeoQueryData, error) {
+	var polys []*polygon
+	var lines []*s2.Polyline
 	var pt *s2.Point
 	var err error
 	switch v := g.(type) {
@@ -133,84 +226,243 @@ func queryTokensGeo(qt QueryType, g geom.T, maxDistance float64) ([]string, *Geo
 		pt = &p
 
 	case *geom.Polygon:
-		l, err := loopFromPolygon(v)
+		p, err := polygonFromPolygon(v)
 		if err != nil {
 			return nil, nil, err
 		}
-		loops = append(loops, l)
+		polys = append(polys, p)
 
 	case *geom.MultiPolygon:
-		// We get a loop for each polygon.
+		// We get a polygon for each polygon.
 		for i := 0; i < v.NumPolygons(); i++ {
-			l, err := loopFromPolygon(v.Polygon(i))
+			p, err := polygonFromPolygon(v.Polygon(i))
 			if err != nil {
 				return nil, nil, err
 			}
-			loops = append(loops, l)
+			polys = append(polys, p)
+		}
+
+	case *geom.LineString:
+		p, err := polylineFromLineString(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines = append(lines, p)
+
+	case *geom.MultiLineString:
+		lines, err = polylinesFromMultiLineString(v)
+		if err != nil {
+			return nil, nil, err
 		}
 
 	default:
 		return nil, nil, x.Errorf("Cannot query using a geometry of type %T", v)
 	}
 
-	x.AssertTruef(len(loops) > 0 || pt != nil, "We should have a point or a loop.")
+	x.AssertTruef(len(polys) > 0 || len(lines) > 0 || pt != nil,
+		"We should have a point, a loop or a line.")
 
-	parents, cover, err := indexCells(g)
+	var parents, cover s2.CellUnion
+	var geohashes []string
+	if IsGeohashIndex(gi) {
+		geohashes, err = geohashCover(g, GeohashPrecision(gi))
+	} else {
+		parents, cover, err = indexCells(g, gi)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
+	// toks returns the tokens to look up in the index, which for a geohash index are those of all
+	// the objects whose cover intersects that of g, whatever the type of the query.
+	toks := func(s2Tokens []string) []string {
+		if geohashes != nil {
+			return geohashQueryTokens(geohashes)
+		}
+		return s2Tokens
+	}
 
 	switch qt {
 	case QueryTypeWithin:
 		// For a within query we only need to look at the objects whose parents match our cover.
 		// So we take our cover and prefix with the parentPrefix to look in the index.
-		if len(loops) == 0 {
+		if len(polys) == 0 {
 			return nil, nil, x.Errorf("Require a polygon for within query")
 		}
-		toks := createTokens(cover, parentPrefix)
-		return toks, &GeoQueryData{loops: loops, qtype: qt}, nil
+		return toks(createTokens(cover, parentPrefix)), &GeoQueryData{polys: polys, qtype: qt},
+			nil
 
 	case QueryTypeContains:
 		// For a contains query, we only need to look at the objects whose cover matches our
 		// parents. So we take our parents and prefix with the coverPrefix to look in the index.
-		return createTokens(parents, coverPrefix), &GeoQueryData{pt: pt, loops: loops, qtype: qt}, nil
+		return toks(createTokens(parents, coverPrefix)),
+			&GeoQueryData{pt: pt, polys: polys, lines: lines, qtype: qt}, nil
 
-	case QueryTypeNear:
-		if len(loops) > 0 {
+	case QueryTypeNear, QueryTypeNearest:
+		if len(polys) > 0 {
 			return nil, nil, x.Errorf("Cannot use a polygon in a near query")
 		}
-		return nearQueryKeys(*pt, maxDistance)
+		if len(lines) > 0 {
+			return nil, nil, x.Errorf("Cannot use a line in a near query")
+		}
+		return nearQueryKeys(qt, *pt, maxDistance, gi)
 
 	case QueryTypeIntersects:
 		// An intersects query is as the name suggests all the entities which intersect with the
 		// given region. So we look at all the objects whose parents match our cover as well as
 		// all the objects whose cover matches our parents.
-		if len(loops) == 0 {
-			return nil, nil, x.Errorf("Require a polygon for intersects query")
+		if len(polys) == 0 && len(lines) == 0 {
+			return nil, nil, x.Errorf("Require a polygon or a line for intersects query")
+		}
+		return toks(parentCoverTokens(parents, cover)),
+			&GeoQueryData{polys: polys, lines: lines, qtype: qt}, nil
+
+	case QueryTypeOverlaps:
+		// The objects which overlap the polygon intersect it, so they are looked up as for an
+		// intersects query.
+		if len(polys) == 0 {
+			return nil, nil, x.Errorf("Require a polygon for overlaps query")
+		}
+		return toks(parentCoverTokens(parents, cover)), &GeoQueryData{polys: polys, qtype: qt},
+			nil
+
+	case QueryTypeDisjoint:
+		// The index can't give the objects which are disjoint from the region, but it gives those
+		// which may intersect it, as for an intersects query. All the other ones are disjoint.
+		if len(polys) == 0 && len(lines) == 0 {
+			return nil, nil, x.Errorf("Require a polygon or a line for disjoint query")
 		}
-		toks := parentCoverTokens(parents, cover)
-		return toks, &GeoQueryData{loops: loops, qtype: qt}, nil
+		return toks(parentCoverTokens(parents, cover)),
+			&GeoQueryData{polys: polys, lines: lines, qtype: qt}, nil
 
 	default:
 		return nil, nil, x.Errorf("Unknown query type")
 	}
 }
 
-// nearQueryKeys creates a QueryKeys object for a near query.
-func nearQueryKeys(pt s2.Point, d float64) ([]string, *GeoQueryData, error) {
+// nearQueryKeys creates a QueryKeys object for a near or nearest query.
+func nearQueryKeys(qt QueryType, pt s2.Point, d float64,
+	gi *protos.GeoIndex) ([]string, *GeoQueryData, error) {
 	if d <= 0 {
 		return nil, nil, x.Errorf("Invalid max distance specified for a near query")
 	}
 	a := EarthAngle(d)
 	c := s2.CapFromCenterAngle(pt, a)
-	cu := indexCellsForCap(c)
-	// A near query is similar to within, where we are looking for points within the cap. So we need
-	// all objects whose parents match the cover of the cap.
-	return createTokens(cu, parentPrefix), &GeoQueryData{cap: &c, qtype: QueryTypeNear}, nil
+	// A near query is similar to within, where we are looking for points within the cap.
+	return regionTokens(c, gi), &GeoQueryData{cap: &c, index: gi, qtype: qt}, nil
+}
+
+// regionTokens returns the tokens to look up for the objects within the region r, in a geo index
+// with the parameters gi. For an S2 index, those are the objects whose parents match the cover of
+// r, and for a geohash index, those whose cover intersects that of r.
+func regionTokens(r s2.Region, gi *protos.GeoIndex) []string {
+	if IsGeohashIndex(gi) {
+		return geohashQueryTokens(geohashRectCover(r.RectBound(), GeohashPrecision(gi)))
+	}
+	return createTokens(indexCellsForRegion(r, gi), parentPrefix)
+}
+
+// boxQueryKeys returns the tokens to look up for a withinbox query, for the box from the corner lo,
+// [minLon, minLat], to the corner hi, [maxLon, maxLat]. A box whose minLon is more than its maxLon
+// crosses the antimeridian.
+func boxQueryKeys(lo, hi geom.T, gi *protos.GeoIndex) ([]string, *GeoQueryData, error) {
+	p1, ok1 := lo.(*geom.Point)
+	p2, ok2 := hi.(*geom.Point)
+	if !ok1 || !ok2 {
+		return nil, nil, x.Errorf("Require points as the corners of the box for withinbox query")
+	}
+	if p1.Y() > p2.Y() {
+		return nil, nil, x.Errorf("Min latitude %v of box can't be more than its max latitude %v",
+			p1.Y(), p2.Y())
+	}
+	min := s2.LatLngFromDegrees(p1.Y(), p1.X())
+	max := s2.LatLngFromDegrees(p2.Y(), p2.X())
+	r := s2.Rect{
+		Lat: r1.Interval{Lo: min.Lat.Radians(), Hi: max.Lat.Radians()},
+		Lng: s1.IntervalFromEndpoints(min.Lng.Radians(), max.Lng.Radians()),
+	}
+	if !r.IsValid() {
+		return nil, nil, x.Errorf("Invalid box for withinbox query")
+	}
+	// Like a within query, we are looking for objects within the box.
+	return regionTokens(r, gi), &GeoQueryData{rect: &r, qtype: QueryTypeWithinBox}, nil
+}
+
+// MeasuresDistance returns if q is for a near or nearest query, which give the distances of the
+// geometries they match from their point.
+func (q *GeoQueryData) MeasuresDistance() bool {
+	return q.qtype == QueryTypeNear || q.qtype == QueryTypeNearest
+}
+
+// IsNearest returns if q is for a nearest query.
+func (q *GeoQueryData) IsNearest() bool {
+	return q.qtype == QueryTypeNearest
+}
+
+// IsDisjoint returns if q is for a disjoint query, whose tokens give the objects which may
+// intersect the region and don't match it.
+func (q *GeoQueryData) IsDisjoint() bool {
+	return q.qtype == QueryTypeDisjoint
+}
+
+// Index returns the parameters of the geo index a near or nearest query looks up.
+func (q *GeoQueryData) Index() *protos.GeoIndex {
+	return q.index
+}
+
+// K returns the number of points a nearest query returns.
+func (q *GeoQueryData) K() int {
+	return q.k
+}
+
+// ExpandCap grows the cap of a nearest query nearestGrowth times, for when fewer than k points were
+// found within it. It returns the tokens to look up for the grown cap, or false if the cap already
+// covers the whole Earth.
+func (q *GeoQueryData) ExpandCap() ([]string, bool) {
+	x.AssertTruef(q.qtype == QueryTypeNearest, "Only the cap of a nearest query can be expanded.")
+	if q.cap.IsFull() {
+		return nil, false
+	}
+	// The full cap keeps the center, which the distances are measured from.
+	c := s2.CapFromCenterChordAngle(q.cap.Center(), s1.StraightChordAngle)
+	if r := q.cap.Radius() * nearestGrowth; r < s1.Angle(math.Pi) {
+		c = s2.CapFromCenterAngle(q.cap.Center(), r)
+	}
+	q.cap = &c
+	return regionTokens(c, q.index), true
 }
 
 // MatchesFilter applies the query filter to a geo value
 func (q GeoQueryData) MatchesFilter(g geom.T) bool {
+	ok, _ := q.MatchesFilterWithDistance(g)
+	return ok
+}
+
+// MatchesFilterWithDistance applies the query filter to a geo value, and for near and nearest
+// queries also returns its distance in metres from the point of the query.
+func (q GeoQueryData) MatchesFilterWithDistance(g geom.T) (bool, float64) {
+	ok := q.matches(g)
+	if !ok || !q.MeasuresDistance() {
+		return ok, 0
+	}
+	return true, q.distance(g)
+}
+
+// distance returns the distance in metres of g from the center of the cap, which for geometries
+// other than points is that of their nearest vertex.
+func (q GeoQueryData) distance(g geom.T) float64 {
+	center := q.cap.Center()
+	min := s1.InfAngle()
+	coords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i+1 < len(coords); i += stride {
+		ll := s2.LatLngFromDegrees(coords[i+1], coords[i])
+		if d := center.Distance(s2.PointFromLatLng(ll)); d < min {
+			min = d
+		}
+	}
+	return float64(EarthDistance(min))
+}
+
+func (q GeoQueryData) matches(g geom.T) bool {
 	switch q.qtype {
 	case QueryTypeWithin:
 		return q.isWithin(g)
@@ -218,31 +470,153 @@ func (q GeoQueryData) MatchesFilter(g geom.T) bool {
 		return q.contains(g)
 	case QueryTypeIntersects:
 		return q.intersects(g)
+	case QueryTypeDisjoint:
+		return !q.intersects(g)
+	case QueryTypeOverlaps:
+		return q.intersects(g) && !q.isWithin(g) && !q.contains(g)
 	case QueryTypeNear:
 		if q.cap == nil {
 			return false
 		}
-		return q.isWithin(g)
+		return q.isWithin(g) && (q.inner == nil || q.outsideInner(g))
+	case QueryTypeNearest:
+		// Only points are ranked by their distance.
+		p, ok := g.(*geom.Point)
+		return ok && q.cap.ContainsPoint(pointFromPoint(p))
+	case QueryTypeWithinBox:
+		return q.isWithinRect(g)
+	}
+	return false
+}
+
+// returns true if the geometry represented by g is within the box of the query.
+func (q GeoQueryData) isWithinRect(g geom.T) bool {
+	switch v := g.(type) {
+	case *geom.Point:
+		return q.rect.ContainsPoint(pointFromPoint(v))
+	case *geom.Polygon, *geom.MultiPolygon:
+		polys, err := s2Polygons(g)
+		if err != nil {
+			return false
+		}
+		for _, p := range polys {
+			if !q.rect.Contains(p.loop.RectBound()) {
+				return false
+			}
+		}
+		return true
+	case *geom.LineString, *geom.MultiLineString:
+		lines, err := s2Polylines(g)
+		if err != nil {
+			return false
+		}
+		for _, p := range lines {
+			if !q.rect.Contains(p.RectBound()) {
+				return false
+			}
+		}
+		return true
+	case *geom.MultiPoint, *GeometryCollection:
+		return allGeoms(members(g), q.isWithinRect)
 	}
 	return false
 }
 
+// members returns the points of a multipoint or the geometries of a collection, which are each
+// matched against a query.
+func members(g geom.T) []geom.T {
+	switch v := g.(type) {
+	case *geom.MultiPoint:
+		pts := make([]geom.T, v.NumPoints())
+		for i := range pts {
+			pts[i] = v.Point(i)
+		}
+		return pts
+	case *GeometryCollection:
+		return v.Geoms()
+	}
+	return nil
+}
+
+// allGeoms returns true if there are geometries in gs and f returns true for all of them.
+func allGeoms(gs []geom.T, f func(geom.T) bool) bool {
+	for _, g := range gs {
+		if !f(g) {
+			return false
+		}
+	}
+	return len(gs) > 0
+}
+
+// anyGeom returns true if f returns true for one of the geometries in gs.
+func anyGeom(gs []geom.T, f func(geom.T) bool) bool {
+	for _, g := range gs {
+		if f(g) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns true if the geometry represented by g doesn't come within the min distance of a near
+// query, so that it lies in the ring between its two distances.
+func (q GeoQueryData) outsideInner(g geom.T) bool {
+	center := q.inner.Center()
+	switch v := g.(type) {
+	case *geom.Point:
+		return !q.inner.ContainsPoint(pointFromPoint(v))
+	case *geom.MultiPoint, *GeometryCollection:
+		return allGeoms(members(g), q.outsideInner)
+	case *geom.Polygon, *geom.MultiPolygon:
+		polys, err := s2Polygons(g)
+		if err != nil {
+			return false
+		}
+		for _, p := range polys {
+			if p.ContainsPoint(center) {
+				return false
+			}
+		}
+	}
+	// No edge of the polygons or lines should come closer to the center than the min distance.
+	coords, stride := g.FlatCoords(), g.Stride()
+	ends := g.Ends()
+	for _, e := range g.Endss() {
+		ends = append(ends, e...)
+	}
+	if len(ends) == 0 {
+		ends = []int{len(coords)}
+	}
+	start := 0
+	for _, end := range ends {
+		for i := start; i+2*stride <= end; i += stride {
+			a := pointFromCoord(coords[i : i+stride])
+			b := pointFromCoord(coords[i+stride : i+2*stride])
+			if s2.DistanceFromSegment(center, a, b) <= q.inner.Radius() {
+				return false
+			}
+		}
+		start = end
+	}
+	return true
+}
+
 func withinCapPolygon(g1 *s2.Loop, g2 *s2.Cap) bool {
 	return g2.Contains(g1.CapBound())
 }
 
-func loopWithinMultiloops(l *s2.Loop, loops []*s2.Loop) bool {
-	for _, s2loop := range loops {
-		if Contains(s2loop, l) {
+func polygonWithinMultiPolygons(p *polygon, polys []*polygon) bool {
+	for _, poly := range polys {
+		if polygonContains(poly, p) {
 			return true
 		}
 	}
 	return false
 }
 
-// returns true if the geometry represented by g is within the given loop or cap
+// returns true if the geometry represented by g is within the given polygon or cap
 func (q GeoQueryData) isWithin(g geom.T) bool {
-	x.AssertTruef(q.pt != nil || len(q.loops) > 0 || q.cap != nil, "At least a point, loop or cap should be defined.")
+	x.AssertTruef(q.pt != nil || len(q.polys) > 0 || q.cap != nil, "At least a point, polygon or cap should be defined.")
 	switch geometry := g.(type) {
 	case *geom.Point:
 		s2pt := pointFromPoint(geometry)
@@ -250,117 +624,140 @@ func (q GeoQueryData) isWithin(g geom.T) bool {
 			return q.pt.ApproxEqual(s2pt)
 		}
 
-		if len(q.loops) > 0 {
-			for _, l := range q.loops {
-				if l.ContainsPoint(s2pt) {
+		if len(q.polys) > 0 {
+			for _, p := range q.polys {
+				if p.ContainsPoint(s2pt) {
 					return true
 				}
 			}
 			return false
 		}
 		return q.cap.ContainsPoint(s2pt)
-	case *geom.Polygon:
-		s2loop, err := loopFromPolygon(geometry)
-		if err != nil {
+	case *geom.Polygon, *geom.MultiPolygon:
+		// Each polygon of a multipolygon should be within some polygon of q.polys, or the cap.
+		polys, err := s2Polygons(g)
+		if err != nil || (len(q.polys) == 0 && q.cap == nil) {
 			return false
 		}
-		if len(q.loops) > 0 {
-			for _, l := range q.loops {
-				if Contains(l, s2loop) {
-					return true
-				}
+		for _, p := range polys {
+			if len(q.polys) > 0 && !polygonWithinMultiPolygons(p, q.polys) {
+				return false
+			}
+			if len(q.polys) == 0 && !withinCapPolygon(p.loop, q.cap) {
+				return false
 			}
-			return false
 		}
-		if q.cap != nil {
-			return withinCapPolygon(s2loop, q.cap)
+		return true
+	case *geom.LineString, *geom.MultiLineString:
+		// Each line should be within the polygons or the cap.
+		lines, err := s2Polylines(g)
+		if err != nil {
+			return false
 		}
-	case *geom.MultiPolygon:
-		// We check each polygon in the multipolygon should be within some loop of q.loops.
-		if len(q.loops) > 0 {
-			for i := 0; i < geometry.NumPolygons(); i++ {
-				s2loop, err := loopFromPolygon(geometry.Polygon(i))
-				if err != nil {
-					return false
-				}
-				if !loopWithinMultiloops(s2loop, q.loops) {
-					return false
-				}
+		for _, p := range lines {
+			if !q.polylineWithin(p) {
+				return false
 			}
-			return true
 		}
+		return true
+	case *geom.MultiPoint, *GeometryCollection:
+		// Each point or geometry should be within the polygons or the cap.
+		return allGeoms(members(g), q.isWithin)
+	}
+	return false
+}
 
-		if q.cap != nil {
-			for i := 0; i < geometry.NumPolygons(); i++ {
-				p := geometry.Polygon(i)
-				s2loop, err := loopFromPolygon(p)
-				if err != nil {
-					return false
-				}
-				if !withinCapPolygon(s2loop, q.cap) {
-					return false
-				}
+// returns true if the polyline p is within some polygon of q.polys, or within the cap.
+func (q GeoQueryData) polylineWithin(p *s2.Polyline) bool {
+	if len(q.polys) > 0 {
+		for _, poly := range q.polys {
+			if polygonContainsPolyline(poly, p) {
+				return true
 			}
-			return true
 		}
+		return false
+	}
+	if q.cap != nil {
+		return q.cap.Contains(p.CapBound())
 	}
 	return false
 }
 
-func multiPolygonContainsLoop(g *geom.MultiPolygon, l *s2.Loop) bool {
-	for i := 0; i < g.NumPolygons(); i++ {
-		p := g.Polygon(i)
-		s2loop, err := loopFromPolygon(p)
-		if err != nil {
-			return false
+func multiPolygonContainsPolygon(polys []*polygon, p *polygon) bool {
+	for _, s2poly := range polys {
+		if polygonContains(s2poly, p) {
+			return true
 		}
-		if Contains(s2loop, l) {
+	}
+	return false
+}
+
+func multiPolygonContainsPolyline(polys []*polygon, p *s2.Polyline) bool {
+	for _, s2poly := range polys {
+		if polygonContainsPolyline(s2poly, p) {
 			return true
 		}
 	}
 	return false
 }
 
-// returns true if the geometry represented by g contains the given point/polygon.
+// returns true if the geometry represented by g contains the given point/polygon/line.
 // g is the geom.T representation of the value which is the stored in the DB.
 func (q GeoQueryData) contains(g geom.T) bool {
-	x.AssertTruef(q.pt != nil || len(q.loops) > 0, "At least a point or loop should be defined.")
+	x.AssertTruef(q.pt != nil || len(q.polys) > 0 || len(q.lines) > 0,
+		"At least a point, polygon or line should be defined.")
 	switch v := g.(type) {
 	case *geom.Polygon:
-		s2loop, err := loopFromPolygon(v)
+		polys, err := s2Polygons(v)
 		if err != nil {
 			return false
 		}
+		s2poly := polys[0]
 		if q.pt != nil {
-			return s2loop.ContainsPoint(*q.pt)
+			return s2poly.ContainsPoint(*q.pt)
 		}
 
-		// Input could be a multipolygon, in which q.loops would have more than 1 loop. Each loop
-		// in the query should be part of the s2loop.
-		for _, l := range q.loops {
-			if !Contains(s2loop, l) {
+		// Input could be a multipolygon, in which q.polys would have more than 1 polygon. Each
+		// polygon in the query should be part of s2poly.
+		for _, p := range q.polys {
+			if !polygonContains(s2poly, p) {
+				return false
+			}
+		}
+		// Likewise each line of a multilinestring.
+		for _, p := range q.lines {
+			if !polygonContainsPolyline(s2poly, p) {
 				return false
 			}
 		}
 		return true
 	case *geom.MultiPolygon:
+		polys, err := s2Polygons(v)
+		if err != nil {
+			return false
+		}
 		if q.pt != nil {
-			for i := 0; i < v.NumPolygons(); i++ {
-				p := v.Polygon(i)
-				s2loop, err := loopFromPolygon(p)
-				if err != nil {
-					return false
-				}
-				if s2loop.ContainsPoint(*q.pt) {
+			for _, s2poly := range polys {
+				if s2poly.ContainsPoint(*q.pt) {
 					return true
 				}
 			}
 		}
 
-		if len(q.loops) > 0 {
-			// All the loops that are part of the query should be part of some loop of v.
-			for _, l := range q.loops {
-				if !multiPolygonContainsLoop(v, l) {
+		if len(q.polys) > 0 {
+			// All the polygons that are part of the query should be part of some polygon of v.
+			for _, p := range q.polys {
+				if !multiPolygonContainsPolygon(polys, p) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if len(q.lines) > 0 {
+			// All the lines that are part of the query should be part of some polygon of v.
+			for _, p := range q.lines {
+				if !multiPolygonContainsPolyline(polys, p) {
 					return false
 				}
 			}
@@ -368,63 +765,159 @@ func (q GeoQueryData) contains(g geom.T) bool {
 		}
 
 		return false
+	case *GeometryCollection:
+		// A collection contains the query if one of its polygons does.
+		return anyGeom(v.Geoms(), q.contains)
 	default:
 		// We will only consider polygons for contains queries.
 		return false
 	}
 }
 
-// returns true if the geometry represented by uid/attr intersects the given loop or point
+// returns true if the geometry represented by uid/attr intersects the given polygon or line
 func (q GeoQueryData) intersects(g geom.T) bool {
-	x.AssertTruef(len(q.loops) > 0, "Loop should be defined for intersects.")
+	x.AssertTruef(len(q.polys) > 0 || len(q.lines) > 0,
+		"Polygon or line should be defined for intersects.")
 	switch v := g.(type) {
 	case *geom.Point:
 		p := pointFromPoint(v)
-		// else loop is not nil
-		for _, l := range q.loops {
-			if l.ContainsPoint(p) {
+		for _, poly := range q.polys {
+			if poly.ContainsPoint(p) {
+				return true
+			}
+		}
+		for _, line := range q.lines {
+			if PolylineContainsPoint(line, p) {
 				return true
 			}
 		}
 		return false
 
-	case *geom.Polygon:
-		l, err := loopFromPolygon(v)
+	case *geom.Polygon, *geom.MultiPolygon:
+		// We must compare all polygons in g with those in the query.
+		polys, err := s2Polygons(g)
 		if err != nil {
 			return false
 		}
-		for _, loop := range q.loops {
-			if Intersects(l, loop) {
+		for _, p := range polys {
+			if q.intersectsPolygon(p) {
 				return true
 			}
 		}
 		return false
-	case *geom.MultiPolygon:
-		// We must compare all polygons in g with those in the query.
-		for i := 0; i < v.NumPolygons(); i++ {
-			l, err := loopFromPolygon(v.Polygon(i))
-			if err != nil {
-				return false
-			}
-			for _, loop := range q.loops {
-				if Intersects(l, loop) {
-					return true
-				}
+	case *geom.LineString, *geom.MultiLineString:
+		lines, err := s2Polylines(g)
+		if err != nil {
+			return false
+		}
+		for _, p := range lines {
+			if q.intersectsPolyline(p) {
+				return true
 			}
 		}
 		return false
+	case *geom.MultiPoint, *GeometryCollection:
+		return anyGeom(members(g), q.intersects)
 	default:
 		// A type that we don't know how to handle.
 		return false
 	}
 }
 
+func (q GeoQueryData) intersectsPolygon(p *polygon) bool {
+	for _, poly := range q.polys {
+		if polygonIntersects(p, poly) {
+			return true
+		}
+	}
+	for _, line := range q.lines {
+		if polygonIntersectsPolyline(p, line) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q GeoQueryData) intersectsPolyline(p *s2.Polyline) bool {
+	for _, poly := range q.polys {
+		if polygonIntersectsPolyline(poly, p) {
+			return true
+		}
+	}
+	for _, line := range q.lines {
+		if PolylinesIntersect(p, line) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterShardSize is the least number of values each goroutine filtering geo values is given, as
+// fewer aren't worth the overhead.
+const filterShardSize = 4096
+
 // FilterGeoUids filters the uids based on the corresponding values and GeoQueryData.
 // The uids are obtained through the index. This second pass ensures that the values actually
-// match the query criteria.
-func FilterGeoUids(uids *protos.List, values []*protos.TaskValue, q *GeoQueryData) *protos.List {
+// match the query criteria. For near and nearest queries, the distances of the values from their
+// point in metres are returned along with the uids, and for a nearest query only the k nearest
+// points are kept.
+func FilterGeoUids(uids *protos.List, values []*protos.TaskValue,
+	q *GeoQueryData) (*protos.List, []float64) {
+	return filterGeoUids(uids, values, q, runtime.GOMAXPROCS(0))
+}
+
+// filterGeoUids is FilterGeoUids, with the values split in contiguous shards between up to workers
+// goroutines. The shards are merged back in order, so the uids stay sorted.
+func filterGeoUids(uids *protos.List, values []*protos.TaskValue, q *GeoQueryData,
+	workers int) (*protos.List, []float64) {
 	x.AssertTruef(len(values) == len(uids.Uids), "lengths not matching")
+	if n := (len(values) + filterShardSize - 1) / filterShardSize; n < workers {
+		workers = n
+	}
 	rv := &protos.List{}
+	var dists []float64
+	if workers <= 1 {
+		rv.Uids, dists = q.filterValues(uids.Uids, values)
+	} else {
+		type shard struct {
+			uids  []uint64
+			dists []float64
+		}
+		shards := make([]shard, workers)
+		size := (len(values) + workers - 1) / workers
+		var wg sync.WaitGroup
+		for i := range shards {
+			start, end := i*size, (i+1)*size
+			if end > len(values) {
+				end = len(values)
+			}
+			if start > end {
+				start = end
+			}
+			wg.Add(1)
+			go func(sh *shard) {
+				defer wg.Done()
+				sh.uids, sh.dists = q.filterValues(uids.Uids[start:end], values[start:end])
+			}(&shards[i])
+		}
+		wg.Wait()
+		for _, sh := range shards {
+			rv.Uids = append(rv.Uids, sh.uids...)
+			dists = append(dists, sh.dists...)
+		}
+	}
+	if q.IsNearest() && len(rv.Uids) > q.k {
+		rv.Uids, dists = nearestUids(rv.Uids, dists, q.k)
+	}
+	return rv, dists
+}
+
+// filterValues returns the uids whose values match the query, with their distances for near and
+// nearest queries.
+func (q *GeoQueryData) filterValues(uids []uint64, values []*protos.TaskValue) ([]uint64,
+	[]float64) {
+	var out []uint64
+	var dists []float64
 	for i := 0; i < len(values); i++ {
 		valBytes := values[i].Val
 		if bytes.Equal(valBytes, nil) {
@@ -434,20 +927,40 @@ func FilterGeoUids(uids *protos.List, values []*protos.TaskValue, q *GeoQueryDat
 		if TypeID(vType) != GeoID {
 			continue
 		}
-		src := ValueForType(BinaryID)
-		src.Value = valBytes
-		gc, err := Convert(src, GeoID)
+		g, err := decodeGeo(valBytes)
 		if err != nil {
 			continue
 		}
-		g := gc.Value.(geom.T)
 
-		if !q.MatchesFilter(g) {
+		ok, d := q.MatchesFilterWithDistance(g)
+		if !ok {
 			continue
 		}
 
 		// we matched the geo filter, add the uid to the list
-		rv.Uids = append(rv.Uids, uids.Uids[i])
+		out = append(out, uids[i])
+		if q.MeasuresDistance() {
+			dists = append(dists, d)
+		}
+	}
+	return out, dists
+}
+
+// nearestUids returns the k uids with the smallest distances, still sorted by uid, along with
+// their distances. Of equally distant uids, the smaller ones are kept.
+func nearestUids(uids []uint64, dists []float64, k int) ([]uint64, []float64) {
+	idx := make([]int, len(uids))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return dists[idx[i]] < dists[idx[j]] })
+	idx = idx[:k]
+	sort.Ints(idx)
+	out := make([]uint64, k)
+	outDists := make([]float64, k)
+	for i, j := range idx {
+		out[i] = uids[j]
+		outDists[i] = dists[j]
 	}
-	return rv
+	return out, outDists
 }
@@ -18,9 +18,12 @@ package types
 
 import (
 	"encoding/binary"
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/stretchr/testify/require"
 	"github.com/twpayne/go-geom"
@@ -38,7 +41,7 @@ func queryTokens(qt QueryType, data string, maxDistance float64) ([]string, *Geo
 	}
 	g := gc.Value.(geom.T)
 
-	return queryTokensGeo(qt, g, maxDistance)
+	return queryTokensGeo(qt, g, maxDistance, nil)
 }
 
 func formData(t *testing.T, str string) string {
@@ -97,7 +100,7 @@ func TestQueryTokensPolygon(t *testing.T) {
 		}
 		require.NotNil(t, qd)
 		require.Equal(t, qd.qtype, qt)
-		require.NotZero(t, len(qd.loops))
+		require.NotZero(t, len(qd.polys))
 		require.Nil(t, qd.pt)
 		require.Nil(t, qd.cap)
 	}
@@ -130,7 +133,7 @@ func TestQueryTokensPoint(t *testing.T) {
 		}
 		require.NotNil(t, qd)
 		require.Equal(t, qd.qtype, qt)
-		require.Equal(t, 0, len(qd.loops))
+		require.Equal(t, 0, len(qd.polys))
 		require.NotNil(t, qd.pt)
 		require.Nil(t, qd.cap)
 	}
@@ -146,7 +149,7 @@ func TestQueryTokensNear(t *testing.T) {
 	require.Equal(t, len(toks), 15)
 	require.NotNil(t, qd)
 	require.Equal(t, qd.qtype, QueryTypeNear)
-	require.Equal(t, 0, len(qd.loops))
+	require.Equal(t, 0, len(qd.polys))
 	require.Nil(t, qd.pt)
 	require.NotNil(t, qd.cap)
 }
@@ -280,6 +283,63 @@ func TestMatchesFilterIntersectsPoint(t *testing.T) {
 }
 */
 
+func TestMatchesFilterDisjointOverlaps(t *testing.T) {
+	p := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+	})
+	data := formDataPolygon(t, p)
+	toks, disjoint, err := queryTokens(QueryTypeDisjoint, data, 0.0)
+	require.NoError(t, err)
+	require.True(t, disjoint.IsDisjoint())
+	// The candidates which may intersect are looked up.
+	itoks, _, err := queryTokens(QueryTypeIntersects, data, 0.0)
+	require.NoError(t, err)
+	sort.Strings(itoks)
+	sort.Strings(toks)
+	require.Equal(t, itoks, toks)
+	toks, overlaps, err := queryTokens(QueryTypeOverlaps, data, 0.0)
+	require.NoError(t, err)
+	sort.Strings(toks)
+	require.Equal(t, itoks, toks)
+	require.False(t, overlaps.IsDisjoint())
+
+	inside := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.5, 37.5})
+	outside := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-121.5, 37.5})
+	within := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.2, 37.2}, {-122.8, 37.2}, {-122.8, 37.8}, {-122.2, 37.8}, {-122.2, 37.2}},
+	})
+	containing := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-121, 36}, {-124, 36}, {-124, 39}, {-121, 39}, {-121, 36}},
+	})
+	across := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-121.5, 37.5}, {-122.5, 37.5}, {-122.5, 38.5}, {-121.5, 38.5}, {-121.5, 37.5}},
+	})
+	away := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-120, 37}, {-121, 37}, {-121, 38}, {-120, 38}, {-120, 37}},
+	})
+	line := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-121.5, 37.5}, {-122.5, 37.5}})
+
+	require.False(t, disjoint.MatchesFilter(inside))
+	require.True(t, disjoint.MatchesFilter(outside))
+	require.False(t, disjoint.MatchesFilter(within))
+	require.False(t, disjoint.MatchesFilter(containing))
+	require.False(t, disjoint.MatchesFilter(across))
+	require.True(t, disjoint.MatchesFilter(away))
+	require.False(t, disjoint.MatchesFilter(line))
+
+	require.False(t, overlaps.MatchesFilter(inside))
+	require.False(t, overlaps.MatchesFilter(outside))
+	require.False(t, overlaps.MatchesFilter(within))
+	require.False(t, overlaps.MatchesFilter(containing))
+	require.True(t, overlaps.MatchesFilter(across))
+	require.False(t, overlaps.MatchesFilter(away))
+	require.True(t, overlaps.MatchesFilter(line))
+
+	// Overlaps needs a polygon.
+	_, _, err = queryTokens(QueryTypeOverlaps, formDataPolygon(t, line), 0.0)
+	require.Error(t, err)
+}
+
 func TestMatchesFilterIntersectsPolygon(t *testing.T) {
 	p := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
 		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
@@ -376,6 +436,228 @@ func TestMatchesFilterWithinPolygon(t *testing.T) {
 	require.True(t, qd.MatchesFilter(multipoly))
 }
 
+func TestQueryTokensLineString(t *testing.T) {
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.4194, 37.7749}, {-122.2711, 37.8044},
+	})
+	for _, qt := range []QueryType{QueryTypeIntersects, QueryTypeContains} {
+		toks, qd, err := queryTokensGeo(qt, l, 0.0, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, toks)
+		require.Len(t, qd.lines, 1)
+	}
+
+	_, _, err := queryTokensGeo(QueryTypeWithin, l, 0.0, nil)
+	require.Error(t, err)
+	_, _, err = queryTokensGeo(QueryTypeNear, l, 1000.0, nil)
+	require.Error(t, err)
+}
+
+func TestMatchesFilterLineString(t *testing.T) {
+	poly := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+	})
+	data := formDataPolygon(t, poly)
+
+	inside := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.2, 37.2}, {-122.5, 37.5}, {-122.8, 37.2},
+	})
+	crossing := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.5, 37.5}, {-121.5, 37.5},
+	})
+	// Both ends are outside, but the line goes through the polygon.
+	through := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-121.5, 37.5}, {-123.5, 37.5},
+	})
+	outside := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-121.5, 36.5}, {-121, 36},
+	})
+
+	_, qd, err := queryTokens(QueryTypeWithin, data, 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(inside))
+	require.False(t, qd.MatchesFilter(crossing))
+	require.False(t, qd.MatchesFilter(through))
+	require.False(t, qd.MatchesFilter(outside))
+
+	_, qd, err = queryTokens(QueryTypeIntersects, data, 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(inside))
+	require.True(t, qd.MatchesFilter(crossing))
+	require.True(t, qd.MatchesFilter(through))
+	require.False(t, qd.MatchesFilter(outside))
+
+	// A multilinestring is within the polygon if all its lines are, and intersects it if any does.
+	ml := geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.2, 37.2}, {-122.5, 37.5}},
+		{{-121.5, 36.5}, {-121, 36}},
+	})
+	require.True(t, qd.MatchesFilter(ml))
+	_, qd, err = queryTokens(QueryTypeWithin, data, 0.0)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(ml))
+
+	// Lines as the query.
+	_, qd, err = queryTokensGeo(QueryTypeContains, inside, 0.0, nil)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(poly))
+	_, qd, err = queryTokensGeo(QueryTypeContains, crossing, 0.0, nil)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(poly))
+
+	_, qd, err = queryTokensGeo(QueryTypeIntersects, through, 0.0, nil)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(poly))
+	require.True(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.5, 37}, {-122.5, 38},
+	})))
+	require.False(t, qd.MatchesFilter(outside))
+	// A point on the line, and one off it.
+	require.True(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-123.5, 37.5})))
+	require.False(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.5, 37})))
+}
+
+func TestQueryTokensNearest(t *testing.T) {
+	toks, qd, err := GetGeoTokens([]string{"nearest", "loc", "[-122.082506, 37.4249518]", "3"}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, toks)
+	require.True(t, qd.IsNearest())
+	require.Equal(t, 3, qd.K())
+	require.InDelta(t, nearestRadius, float64(EarthDistance(qd.cap.Radius())), 1)
+
+	// The cap grows until it covers the whole Earth.
+	toks, ok := qd.ExpandCap()
+	require.True(t, ok)
+	require.NotEmpty(t, toks)
+	require.InDelta(t, nearestRadius*nearestGrowth, float64(EarthDistance(qd.cap.Radius())), 1)
+	for ok {
+		_, ok = qd.ExpandCap()
+	}
+	require.True(t, qd.cap.IsFull())
+
+	for _, args := range [][]string{
+		{"nearest", "loc", "[-122.082506, 37.4249518]", "0"},
+		{"nearest", "loc", "[-122.082506, 37.4249518]", "1.5"},
+		{"nearest", "loc", "[-122.082506, 37.4249518]"},
+		{"nearest", "loc", "[[[-122, 37], [-123, 37], [-123, 38], [-122, 37]]]", "1"},
+	} {
+		_, _, err := GetGeoTokens(args, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestMatchesFilterWithinBox(t *testing.T) {
+	toks, qd, err := GetGeoTokens([]string{"withinbox", "loc", "[-123, 37]", "[-122, 38]"}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, toks)
+	for _, tok := range toks {
+		require.True(t, strings.HasPrefix(tok, parentPrefix))
+	}
+
+	require.True(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.5, 37.5})))
+	require.False(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-121.5, 37.5})))
+	require.True(t, qd.MatchesFilter(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.2, 37.2}, {-122.8, 37.2}, {-122.8, 37.8}, {-122.2, 37.8}, {-122.2, 37.2}},
+	})))
+	require.False(t, qd.MatchesFilter(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-121.8, 37.2}, {-122.8, 37.2}, {-122.8, 37.8}, {-121.8, 37.8}, {-121.8, 37.2}},
+	})))
+	require.True(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.2, 37.2}, {-122.8, 37.8},
+	})))
+	require.False(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.2, 37.2}, {-122.8, 38.2},
+	})))
+
+	// A box crossing the antimeridian.
+	_, qd, err = GetGeoTokens([]string{"withinbox", "loc", "[170, -20]", "[-170, -10]"}, nil)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{179, -15})))
+	require.True(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-175, -15})))
+	require.False(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0, -15})))
+
+	for _, args := range [][]string{
+		{"withinbox", "loc", "[-123, 37]"},
+		{"withinbox", "loc", "[-123, 38]", "[-122, 37]"},
+		{"withinbox", "loc", "[-123, 37]", "[-122, 95]"},
+		{"withinbox", "loc", "[-123, 37]", "[[[-122, 37], [-123, 37], [-123, 38], [-122, 37]]]"},
+	} {
+		_, _, err := GetGeoTokens(args, nil)
+		require.Error(t, err, "%v", args)
+	}
+}
+
+func TestFilterGeoUidsNearest(t *testing.T) {
+	_, qd, err := GetGeoTokens([]string{"nearest", "loc", "[-122.080668, 37.426753]", "2"}, nil)
+	require.NoError(t, err)
+	for ok := true; ok; _, ok = qd.ExpandCap() {
+	}
+
+	var values []*protos.TaskValue
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.2527428, 37.513653}),
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518}),
+		geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+		}),
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.080668, 37.426753}),
+	} {
+		d, err := wkb.Marshal(g, binary.LittleEndian)
+		require.NoError(t, err)
+		values = append(values, &protos.TaskValue{Val: d, ValType: int32(GeoID)})
+	}
+
+	// Polygons aren't ranked, and only the 2 nearest points are kept, sorted by uid.
+	uids, dists := FilterGeoUids(&protos.List{Uids: []uint64{1, 2, 3, 4}}, values, qd)
+	require.Equal(t, []uint64{2, 4}, uids.Uids)
+	require.Len(t, dists, 2)
+	require.InDelta(t, 255, dists[0], 5)
+	require.InDelta(t, 0, dists[1], 1e-6)
+}
+
+// geoTaskValues returns n points spread over the bay area, as values read from the store.
+func geoTaskValues(t testing.TB, n int) (*protos.List, []*protos.TaskValue) {
+	uids := &protos.List{Uids: make([]uint64, n)}
+	values := make([]*protos.TaskValue, n)
+	for i := range values {
+		p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{
+			-122.5 + float64(i%1000)/2000, 37.2 + float64(i/1000%1000)/2000,
+		})
+		d, err := wkb.Marshal(p, binary.LittleEndian)
+		require.NoError(t, err)
+		uids.Uids[i] = uint64(i + 1)
+		values[i] = &protos.TaskValue{Val: d, ValType: int32(GeoID)}
+	}
+	return uids, values
+}
+
+func TestFilterGeoUidsParallel(t *testing.T) {
+	uids, values := geoTaskValues(t, 3*filterShardSize+10)
+	_, qd, err := GetGeoTokens([]string{"near", "loc", "[-122.3, 37.2]", "5000"}, nil)
+	require.NoError(t, err)
+	want, wantDists := filterGeoUids(uids, values, qd, 1)
+	require.NotEmpty(t, want.Uids)
+	for _, workers := range []int{2, 3, 4, 16} {
+		got, dists := filterGeoUids(uids, values, qd, workers)
+		require.Equal(t, want.Uids, got.Uids, "%d workers", workers)
+		require.Equal(t, wantDists, dists, "%d workers", workers)
+	}
+}
+
+func BenchmarkFilterGeoUids(b *testing.B) {
+	uids, values := geoTaskValues(b, 1000000)
+	_, qd, err := GetGeoTokens([]string{"within", "loc",
+		"[[[-122.4, 37.3], [-122.1, 37.3], [-122.1, 37.6], [-122.4, 37.6], [-122.4, 37.3]]]"}, nil)
+	require.NoError(b, err)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				filterGeoUids(uids, values, qd, workers)
+			}
+		})
+	}
+}
+
 func TestMatchesFilterNearPoint(t *testing.T) {
 	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
 	data := formDataPoint(t, p)
@@ -400,3 +682,168 @@ func TestMatchesFilterNearPoint(t *testing.T) {
 	})
 	require.False(t, qd.MatchesFilter(poly))
 }
+
+func TestMatchesFilterNearRing(t *testing.T) {
+	toks, qd, err := GetGeoTokens([]string{"near", "loc", "[0, 0]", "50000", "300000"}, nil)
+	require.NoError(t, err)
+	// The index is looked up for the outer cap.
+	outer, _, err := GetGeoTokens([]string{"near", "loc", "[0, 0]", "300000"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, outer, toks)
+
+	require.True(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 0})))
+	require.False(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0.1, 0})))
+	require.False(t, qd.MatchesFilter(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{3, 0})))
+
+	require.True(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-1, 1}, {1, 1},
+	})))
+	// Its ends are outside the min distance, but the line passes within it.
+	require.False(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-1, 0.2}, {1, 0.2},
+	})))
+	require.True(t, qd.MatchesFilter(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{1, 1}, {1.2, 1}, {1.2, 1.2}, {1, 1.2}, {1, 1}},
+	})))
+	// A polygon around the center.
+	require.False(t, qd.MatchesFilter(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}, {-1, -1}},
+	})))
+
+	for _, args := range [][]string{
+		{"near", "loc", "[0, 0]", "300000", "50000"},
+		{"near", "loc", "[0, 0]", "50000", "50000"},
+		{"near", "loc", "[0, 0]", "-1", "50000"},
+		{"near", "loc", "[0, 0]", "1", "2", "3"},
+	} {
+		_, _, err := GetGeoTokens(args, nil)
+		require.Error(t, err, "%v", args)
+	}
+}
+
+func TestMatchesFilterPolygonHoles(t *testing.T) {
+	donut := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+		{{-122.4, 37.4}, {-122.4, 37.6}, {-122.6, 37.6}, {-122.6, 37.4}, {-122.4, 37.4}},
+	})
+	data := formDataPolygon(t, donut)
+
+	inHole := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.5, 37.5})
+	inRing := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.2, 37.2})
+	// A polygon within the ring, one covering the hole and one within the hole.
+	ring := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.1, 37.1}, {-122.2, 37.1}, {-122.2, 37.2}, {-122.1, 37.2}, {-122.1, 37.1}},
+	})
+	overHole := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.3, 37.3}, {-122.7, 37.3}, {-122.7, 37.7}, {-122.3, 37.7}, {-122.3, 37.3}},
+	})
+	hole := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.45, 37.45}, {-122.55, 37.45}, {-122.55, 37.55}, {-122.45, 37.55}, {-122.45, 37.45}},
+	})
+
+	_, qd, err := queryTokens(QueryTypeWithin, data, 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(inRing))
+	require.False(t, qd.MatchesFilter(inHole))
+	require.True(t, qd.MatchesFilter(ring))
+	require.False(t, qd.MatchesFilter(overHole))
+	require.False(t, qd.MatchesFilter(hole))
+
+	_, qd, err = queryTokens(QueryTypeIntersects, data, 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(inRing))
+	require.False(t, qd.MatchesFilter(inHole))
+	require.True(t, qd.MatchesFilter(overHole))
+	require.False(t, qd.MatchesFilter(hole))
+	// A line crossing the hole intersects the ring, one within the hole doesn't.
+	require.True(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.3, 37.5}, {-122.7, 37.5},
+	})))
+	require.False(t, qd.MatchesFilter(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.45, 37.5}, {-122.55, 37.5},
+	})))
+
+	// The donut as the stored value.
+	_, qd, err = queryTokens(QueryTypeContains, formDataPoint(t, inRing), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(donut))
+	_, qd, err = queryTokens(QueryTypeContains, formDataPoint(t, inHole), 0.0)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(donut))
+	_, qd, err = queryTokens(QueryTypeContains, formDataPolygon(t, ring), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(donut))
+	_, qd, err = queryTokens(QueryTypeContains, formDataPolygon(t, overHole), 0.0)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(donut))
+
+	_, qd, err = queryTokens(QueryTypeIntersects, formDataPolygon(t, hole), 0.0)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(donut))
+	_, qd, err = queryTokens(QueryTypeIntersects, formDataPolygon(t, overHole), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(donut))
+
+	_, qd, err = queryTokens(QueryTypeWithin, formDataPolygon(t, overHole), 0.0)
+	require.NoError(t, err)
+	require.False(t, qd.MatchesFilter(donut))
+	require.True(t, qd.MatchesFilter(hole))
+}
+
+func TestMatchesFilterCollection(t *testing.T) {
+	square := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+	})
+	inside := geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.2, 37.2}, {-122.8, 37.8},
+	})
+	across := geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.2, 37.2}, {-121.8, 37.8},
+	})
+	outside := geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{
+		{-121.2, 37.2}, {-121.8, 37.8},
+	})
+	small := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.1, 37.1}, {-122.2, 37.1}, {-122.2, 37.2}, {-122.1, 37.2}, {-122.1, 37.1}},
+	})
+	line := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-122.5, 37.5}, {-121.5, 37.5}})
+
+	_, qd, err := queryTokens(QueryTypeWithin, formDataPolygon(t, square), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(inside))
+	require.False(t, qd.MatchesFilter(across))
+	require.True(t, qd.MatchesFilter(NewGeometryCollection(inside, small)))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection(small, line)))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection()))
+
+	_, qd, err = queryTokens(QueryTypeIntersects, formDataPolygon(t, square), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(across))
+	require.False(t, qd.MatchesFilter(outside))
+	require.True(t, qd.MatchesFilter(NewGeometryCollection(outside, line)))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection(outside)))
+
+	// Only the polygons of a collection contain a point.
+	pt := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.15, 37.15})
+	_, qd, err = queryTokens(QueryTypeContains, formDataPoint(t, pt), 0.0)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(NewGeometryCollection(outside, small)))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection(outside, line)))
+	require.False(t, qd.MatchesFilter(geom.NewMultiPoint(geom.XY).MustSetCoords(
+		[]geom.Coord{{-122.15, 37.15}})))
+
+	_, qd, err = GetGeoTokens([]string{"withinbox", "loc", "[-123, 37]", "[-122, 38]"}, nil)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(NewGeometryCollection(inside, small)))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection(small, line)))
+
+	_, qd, err = GetGeoTokens([]string{"near", "loc", "[0, 0]", "50000", "300000"}, nil)
+	require.NoError(t, err)
+	require.True(t, qd.MatchesFilter(geom.NewMultiPoint(geom.XY).MustSetCoords(
+		[]geom.Coord{{1, 0}, {0, 1}})))
+	require.False(t, qd.MatchesFilter(geom.NewMultiPoint(geom.XY).MustSetCoords(
+		[]geom.Coord{{1, 0}, {0.1, 0}})))
+	require.False(t, qd.MatchesFilter(NewGeometryCollection(
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 0}),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-1, 0.2}, {1, 0.2}}))))
+}
@@ -0,0 +1,270 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A geohash index covers values with geohashes instead of S2 cells, for their keys to be those of
+// external systems keyed by geohash. Like S2 cells, a geohash is within the cells of its prefixes.
+// Values are covered by geohashes of the precision of the index, or shorter ones for those which
+// would take more than maxGeohashCells of them. The index has the prefixes of the cover of a value
+// under parentPrefix, and the cover itself under coverPrefix, as a geo index does with the parents
+// and cover of S2 cells. As a value and a query region can be covered by geohashes of different
+// lengths, queries look up all the values whose cover intersects theirs: those with a geohash
+// within one of the cover of the region, and those with one of its prefixes.
+
+const (
+	// DefaultGeohashPrecision is the length of the geohashes of a geohash index, unless set. Its
+	// cells are about 150m across.
+	DefaultGeohashPrecision = 7
+	// MaxGeohashPrecision is the largest length of the geohashes of a geohash index, whose cells
+	// are a few centimeters across.
+	MaxGeohashPrecision = 12
+	// maxGeohashCells is the largest number of geohashes covering a value or a query region.
+	maxGeohashCells = 32
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash returns the geohash of the given length of the point lat, lng, in degrees.
+func Geohash(lat, lng float64, precision int) string {
+	latLo, latHi, lngLo, lngHi := -90.0, 90.0, -180.0, 180.0
+	buf := make([]byte, precision)
+	// The bits of the geohash alternate between longitude and latitude, longitude first.
+	even := true
+	for i := range buf {
+		var c int
+		for b := 0; b < 5; b++ {
+			c <<= 1
+			if even {
+				if mid := (lngLo + lngHi) / 2; lng >= mid {
+					c |= 1
+					lngLo = mid
+				} else {
+					lngHi = mid
+				}
+			} else {
+				if mid := (latLo + latHi) / 2; lat >= mid {
+					c |= 1
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+		buf[i] = geohashBase32[c]
+	}
+	return string(buf)
+}
+
+// GeohashPrecision returns the length of the geohashes of a geohash index with the parameters gi,
+// which is the default one if gi is nil or doesn't set it.
+func GeohashPrecision(gi *protos.GeoIndex) int {
+	if gi.GetPrecision() == 0 {
+		return DefaultGeohashPrecision
+	}
+	return int(gi.Precision)
+}
+
+// IsGeohashIndex returns if gi are the parameters of a geohash index, rather than an S2 one.
+func IsGeohashIndex(gi *protos.GeoIndex) bool {
+	return gi.GetPrecision() > 0
+}
+
+// geohashGrid returns the number of columns and rows of the cells of geohashes of length n, and
+// their width and height in degrees.
+func geohashGrid(n int) (cols, rows int, width, height float64) {
+	bits := uint(5 * n)
+	cols, rows = 1<<((bits+1)/2), 1<<(bits/2)
+	return cols, rows, 360 / float64(cols), 180 / float64(rows)
+}
+
+func gridIndex(v, lo, size float64, n int) int {
+	i := int(math.Floor((v - lo) / size))
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// geohashBoxes returns the boxes, as the degrees minLat, maxLat, minLng, maxLng, making up the rect
+// r, which is split in two if it crosses the antimeridian.
+func geohashBoxes(r s2.Rect) [][4]float64 {
+	lat := [2]float64{s1.Angle(r.Lat.Lo).Degrees(), s1.Angle(r.Lat.Hi).Degrees()}
+	lo, hi := s1.Angle(r.Lng.Lo).Degrees(), s1.Angle(r.Lng.Hi).Degrees()
+	switch {
+	case r.Lng.IsFull():
+		return [][4]float64{{lat[0], lat[1], -180, 180}}
+	case r.Lng.IsInverted():
+		return [][4]float64{{lat[0], lat[1], lo, 180}, {lat[0], lat[1], -180, hi}}
+	}
+	return [][4]float64{{lat[0], lat[1], lo, hi}}
+}
+
+// geohashRectCover returns the geohashes covering the rect r, of the largest length up to
+// precision for which there are at most maxGeohashCells of them.
+func geohashRectCover(r s2.Rect, precision int) []string {
+	boxes := geohashBoxes(r)
+	n := precision
+	for ; n > 1; n-- {
+		cols, rows, w, h := geohashGrid(n)
+		count := 0
+		for _, b := range boxes {
+			count += (gridIndex(b[1], -90, h, rows) - gridIndex(b[0], -90, h, rows) + 1) *
+				(gridIndex(b[3], -180, w, cols) - gridIndex(b[2], -180, w, cols) + 1)
+		}
+		if count <= maxGeohashCells {
+			break
+		}
+	}
+	cols, rows, w, h := geohashGrid(n)
+	var cells []string
+	for _, b := range boxes {
+		for y := gridIndex(b[0], -90, h, rows); y <= gridIndex(b[1], -90, h, rows); y++ {
+			for x := gridIndex(b[2], -180, w, cols); x <= gridIndex(b[3], -180, w, cols); x++ {
+				// The geohash of the center of a cell is the one of the cell.
+				cells = append(cells, Geohash(-90+(float64(y)+0.5)*h,
+					-180+(float64(x)+0.5)*w, n))
+			}
+		}
+	}
+	return cells
+}
+
+// geohashCover returns the geohashes covering the geometry g, which are up to precision long.
+func geohashCover(g geom.T, precision int) ([]string, error) {
+	if g.Stride() != 2 {
+		return nil, x.Errorf("Covering only available for 2D co-ordinates.")
+	}
+	switch v := g.(type) {
+	case *geom.Point:
+		return []string{Geohash(v.Y(), v.X(), precision)}, nil
+	case *geom.Polygon:
+		l, err := loopFromPolygon(v)
+		if err != nil {
+			return nil, err
+		}
+		return geohashRectCover(l.RectBound(), precision), nil
+	case *geom.LineString:
+		p, err := polylineFromLineString(v)
+		if err != nil {
+			return nil, err
+		}
+		return geohashRectCover(p.RectBound(), precision), nil
+	case *geom.MultiPolygon:
+		var cells []string
+		for i := 0; i < v.NumPolygons(); i++ {
+			c, err := geohashCover(v.Polygon(i), precision)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, c...)
+		}
+		return cells, nil
+	case *geom.MultiLineString:
+		lines, err := polylinesFromMultiLineString(v)
+		if err != nil {
+			return nil, err
+		}
+		var cells []string
+		for _, p := range lines {
+			cells = append(cells, geohashRectCover(p.RectBound(), precision)...)
+		}
+		return cells, nil
+	case *geom.MultiPoint, *GeometryCollection:
+		var cells []string
+		for _, m := range members(g) {
+			c, err := geohashCover(m, precision)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, c...)
+		}
+		return cells, nil
+	default:
+		return nil, x.Errorf("Cannot index geometry of type %T", v)
+	}
+}
+
+// geohashPrefixes returns the distinct prefixes of cells, including the cells themselves.
+func geohashPrefixes(cells []string) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, c := range cells {
+		for n := len(c); n > 0; n-- {
+			if seen[c[:n]] {
+				break
+			}
+			seen[c[:n]] = true
+			prefixes = append(prefixes, c[:n])
+		}
+	}
+	return prefixes
+}
+
+func prefixTokens(cells []string, prefix string) []string {
+	toks := make([]string, 0, len(cells))
+	for _, c := range cells {
+		toks = append(toks, prefix+c)
+	}
+	return toks
+}
+
+// IndexGeohashTokens returns the tokens of the geometry g in a geohash index with the parameters
+// gi, which are the default ones if gi is nil.
+func IndexGeohashTokens(g geom.T, gi *protos.GeoIndex) ([]string, error) {
+	cover, err := geohashCover(g, GeohashPrecision(gi))
+	if err != nil {
+		return nil, err
+	}
+	cover = dedupGeohashes(cover)
+	return append(prefixTokens(geohashPrefixes(cover), parentPrefix),
+		prefixTokens(cover, coverPrefix)...), nil
+}
+
+// geohashQueryTokens returns the tokens to look up in a geohash index for the values whose cover
+// intersects the geohashes cover of a query region.
+func geohashQueryTokens(cover []string) []string {
+	cover = dedupGeohashes(cover)
+	return append(prefixTokens(cover, parentPrefix),
+		prefixTokens(geohashPrefixes(cover), coverPrefix)...)
+}
+
+func dedupGeohashes(cells []string) []string {
+	seen := make(map[string]bool, len(cells))
+	out := cells[:0]
+	for _, c := range cells {
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestGeohash(t *testing.T) {
+	require.Equal(t, "u4pruydqqvj", Geohash(57.64911, 10.40744, 11))
+	require.Equal(t, "9q8yy", Geohash(37.7749, -122.4194, 5))
+	require.Equal(t, "s0000", Geohash(0, 0, 5))
+	require.Equal(t, "zzzzz", Geohash(90, 180, 5))
+}
+
+func TestGeohashRectCover(t *testing.T) {
+	r := s2.RectFromLatLng(s2.LatLngFromDegrees(37.7, -122.5))
+	r = r.AddPoint(s2.LatLngFromDegrees(37.8, -122.4))
+	cells := geohashRectCover(r, 9)
+	require.True(t, len(cells) <= maxGeohashCells)
+	for _, c := range cells {
+		require.Equal(t, len(cells[0]), len(c))
+		require.True(t, strings.HasPrefix(c, "9q8"), c)
+	}
+	// The cells cover the corners of the rect.
+	for _, h := range []string{Geohash(37.7, -122.5, 9), Geohash(37.8, -122.4, 9)} {
+		require.True(t, anyPrefix(cells, h), h)
+	}
+
+	// Rects crossing the antimeridian are covered on both sides.
+	r = s2.RectFromLatLng(s2.LatLngFromDegrees(-10, 179))
+	r = r.AddPoint(s2.LatLngFromDegrees(-11, -179))
+	cells = geohashRectCover(r, 5)
+	require.True(t, anyPrefix(cells, Geohash(-10.5, 179.5, 5)))
+	require.True(t, anyPrefix(cells, Geohash(-10.5, -179.5, 5)))
+
+	// The whole Earth takes the geohashes of length 1.
+	require.Len(t, geohashRectCover(s2.FullRect(), 5), 32)
+}
+
+func anyPrefix(cells []string, h string) bool {
+	for _, c := range cells {
+		if strings.HasPrefix(h, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIndexGeohashTokens(t *testing.T) {
+	gi := &protos.GeoIndex{Precision: 4}
+	toks, err := IndexGeohashTokens(geom.NewPointFlat(geom.XY, []float64{10.40744, 57.64911}), gi)
+	require.NoError(t, err)
+	require.Equal(t, []string{"p/u4pr", "p/u4p", "p/u4", "p/u", "c/u4pr"}, toks)
+
+	_, err = IndexGeohashTokens(geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}), gi)
+	require.Error(t, err)
+}
+
+// matchesGeohashQuery returns if the tokens of a query in a geohash index find those of g.
+func matchesGeohashQuery(t *testing.T, g geom.T, args []string, gi *protos.GeoIndex) bool {
+	toks, err := IndexGeohashTokens(g, gi)
+	require.NoError(t, err)
+	qtoks, _, err := GetGeoTokens(args, gi)
+	require.NoError(t, err)
+	for _, q := range qtoks {
+		for _, tok := range toks {
+			if q == tok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestGeohashQueryTokens(t *testing.T) {
+	gi := &protos.GeoIndex{Precision: 8}
+	pt := geom.NewPointFlat(geom.XY, []float64{-122.45, 37.75})
+	far := geom.NewPointFlat(geom.XY, []float64{2.35, 48.85})
+	square := "[[[-122.5, 37.7], [-122.4, 37.7], [-122.4, 37.8], [-122.5, 37.8], [-122.5, 37.7]]]"
+	big := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-123, 37}, {-122, 37}, {-122, 38}, {-123, 38}, {-123, 37}}})
+
+	for _, args := range [][]string{
+		{"within", "loc", square},
+		{"intersects", "loc", square},
+		{"near", "loc", "[-122.45, 37.75]", "1000"},
+		{"nearest", "loc", "[-122.45, 37.75]", "1"},
+		{"withinbox", "loc", "[-122.5, 37.7]", "[-122.4, 37.8]"},
+	} {
+		require.True(t, matchesGeohashQuery(t, pt, args, gi), "%v", args)
+		require.False(t, matchesGeohashQuery(t, far, args, gi), "%v", args)
+	}
+	// The polygon containing the point is covered by shorter geohashes than the point's.
+	require.True(t, matchesGeohashQuery(t, big, []string{"contains", "loc", "[-122.45, 37.75]"},
+		gi))
+	require.True(t, matchesGeohashQuery(t, big, []string{"intersects", "loc", square}, gi))
+
+	_, qd, err := GetGeoTokens([]string{"nearest", "loc", "[-122.45, 37.75]", "1"}, gi)
+	require.NoError(t, err)
+	toks, ok := qd.ExpandCap()
+	require.True(t, ok)
+	for _, tok := range toks {
+		require.True(t, strings.HasPrefix(tok, "p/") || strings.HasPrefix(tok, "c/"), tok)
+	}
+}
+
+func TestValidateGeohashIndex(t *testing.T) {
+	require.NoError(t, ValidateGeoIndex(&protos.GeoIndex{Precision: MaxGeohashPrecision}))
+	require.Error(t, ValidateGeoIndex(&protos.GeoIndex{Precision: MaxGeohashPrecision + 1}))
+	require.Error(t, ValidateGeoIndex(&protos.GeoIndex{Precision: 5, MaxCells: 18}))
+}
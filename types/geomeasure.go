@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// GeoArea returns the area on earth of the polygons of g, less their holes. Points and lines have
+// no area.
+func GeoArea(g geom.T) (Area, error) {
+	switch v := g.(type) {
+	case *geom.Polygon:
+		poly, err := polygonFromPolygon(v)
+		if err != nil {
+			return 0, err
+		}
+		a := poly.loop.Area()
+		for _, h := range poly.holes {
+			a -= h.Area()
+		}
+		return EarthArea(a), nil
+	case *geom.MultiPolygon:
+		var sum Area
+		for i := 0; i < v.NumPolygons(); i++ {
+			a, err := GeoArea(v.Polygon(i))
+			if err != nil {
+				return 0, err
+			}
+			sum += a
+		}
+		return sum, nil
+	case *GeometryCollection:
+		var sum Area
+		for _, m := range v.Geoms() {
+			a, err := GeoArea(m)
+			if err != nil {
+				return 0, err
+			}
+			sum += a
+		}
+		return sum, nil
+	case *geom.Point, *geom.MultiPoint, *geom.LineString, *geom.MultiLineString:
+		return 0, nil
+	default:
+		return 0, x.Errorf("Geometry of type %T is not supported", v)
+	}
+}
+
+// GeoLength returns the length on earth of the lines of g. Points and polygons have no length.
+func GeoLength(g geom.T) (Length, error) {
+	switch v := g.(type) {
+	case *geom.LineString:
+		l, err := polylineFromLineString(v)
+		if err != nil {
+			return 0, err
+		}
+		return EarthDistance(l.Length()), nil
+	case *geom.MultiLineString:
+		var sum Length
+		for i := 0; i < v.NumLineStrings(); i++ {
+			l, err := GeoLength(v.LineString(i))
+			if err != nil {
+				return 0, err
+			}
+			sum += l
+		}
+		return sum, nil
+	case *GeometryCollection:
+		var sum Length
+		for _, m := range v.Geoms() {
+			l, err := GeoLength(m)
+			if err != nil {
+				return 0, err
+			}
+			sum += l
+		}
+		return sum, nil
+	case *geom.Point, *geom.MultiPoint, *geom.Polygon, *geom.MultiPolygon:
+		return 0, nil
+	default:
+		return 0, x.Errorf("Geometry of type %T is not supported", v)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestGeoArea(t *testing.T) {
+	// A square of 0.01 degrees at the equator is about 1.11km wide.
+	square := [][]geom.Coord{{{0, 0}, {0.01, 0}, {0.01, 0.01}, {0, 0.01}, {0, 0}}}
+	p := geom.NewPolygon(geom.XY).MustSetCoords(square)
+	a, err := GeoArea(p)
+	require.NoError(t, err)
+	require.InDelta(t, 1236000, float64(a), 1000)
+
+	// The orientation of the ring doesn't matter.
+	cw := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {0, 0.01}, {0.01, 0.01}, {0.01, 0}, {0, 0}},
+	})
+	a2, err := GeoArea(cw)
+	require.NoError(t, err)
+	require.InDelta(t, float64(a), float64(a2), 1)
+
+	// Holes are taken out.
+	holed := geom.NewPolygon(geom.XY).MustSetCoords(append(square,
+		[]geom.Coord{{0.002, 0.002}, {0.002, 0.007}, {0.007, 0.007}, {0.007, 0.002}, {0.002, 0.002}}))
+	a2, err = GeoArea(holed)
+	require.NoError(t, err)
+	require.InDelta(t, float64(a)*0.75, float64(a2), 1000)
+
+	mp := geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{square,
+		{{{1, 1}, {1.01, 1}, {1.01, 1.01}, {1, 1.01}, {1, 1}}}})
+	a2, err = GeoArea(mp)
+	require.NoError(t, err)
+	require.InDelta(t, 2*float64(a), float64(a2), 1000)
+
+	a2, err = GeoArea(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0, 0}))
+	require.NoError(t, err)
+	require.Zero(t, a2)
+}
+
+func TestGeoLength(t *testing.T) {
+	// A degree of the equator is about 111km.
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}})
+	d, err := GeoLength(l)
+	require.NoError(t, err)
+	require.InDelta(t, 2*111195, float64(d), 10)
+
+	ml := geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {1, 0}}, {{0, 1}, {0, 2}},
+	})
+	d, err = GeoLength(ml)
+	require.NoError(t, err)
+	require.InDelta(t, 2*111195, float64(d), 10)
+
+	coll := NewGeometryCollection(l, geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0, 0}))
+	d, err = GeoLength(coll)
+	require.NoError(t, err)
+	require.InDelta(t, 2*111195, float64(d), 10)
+
+	d, err = GeoLength(geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+	}))
+	require.NoError(t, err)
+	require.Zero(t, d)
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/s2"
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// ValidateGeo returns an error if the geometry g shouldn't be stored, as it has coordinates out of
+// range, or polygons whose rings aren't closed, cross themselves or are wound the wrong way. As
+// per the right-hand rule of GeoJSON, the outer ring of a polygon is counter-clockwise and its
+// holes clockwise, which OrientGeo makes them.
+func ValidateGeo(g geom.T) error {
+	if err := validateCoords(g); err != nil {
+		return err
+	}
+	switch v := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return nil
+	case *geom.LineString:
+		if v.NumCoords() < 2 {
+			return x.Errorf("Line has %d coordinates, but needs at least 2", v.NumCoords())
+		}
+		return nil
+	case *geom.MultiLineString:
+		for i := 0; i < v.NumLineStrings(); i++ {
+			if err := ValidateGeo(v.LineString(i)); err != nil {
+				return x.Wrapf(err, "Invalid line %d of multilinestring", i)
+			}
+		}
+		return nil
+	case *geom.Polygon:
+		return validatePolygon(v)
+	case *geom.MultiPolygon:
+		for i := 0; i < v.NumPolygons(); i++ {
+			if err := validatePolygon(v.Polygon(i)); err != nil {
+				return x.Wrapf(err, "Invalid polygon %d of multipolygon", i)
+			}
+		}
+		return nil
+	case *GeometryCollection:
+		for i, m := range v.Geoms() {
+			if err := ValidateGeo(m); err != nil {
+				return x.Wrapf(err, "Invalid geometry %d of collection", i)
+			}
+		}
+		return nil
+	default:
+		return x.Errorf("Geometry of type %T is not supported", v)
+	}
+}
+
+// validateCoords returns an error for the first coordinate of g which isn't a valid longitude and
+// latitude.
+func validateCoords(g geom.T) error {
+	coords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i+1 < len(coords); i += stride {
+		lng, lat := coords[i], coords[i+1]
+		if math.IsNaN(lng) || math.IsNaN(lat) || math.Abs(lng) > 180 || math.Abs(lat) > 90 {
+			return x.Errorf("Coordinate [%v, %v] is out of range: coordinates are given as "+
+				"[longitude, latitude], within [-180, 180] and [-90, 90]", lng, lat)
+		}
+	}
+	return nil
+}
+
+func ringName(i int) string {
+	if i == 0 {
+		return "outer ring"
+	}
+	return fmt.Sprintf("hole %d", i)
+}
+
+// validatePolygon returns an error if a ring of the polygon p isn't closed, crosses itself or is
+// wound the wrong way.
+func validatePolygon(p *geom.Polygon) error {
+	if p.NumLinearRings() == 0 {
+		return x.Errorf("Polygon has no rings")
+	}
+	for i := 0; i < p.NumLinearRings(); i++ {
+		r := p.LinearRing(i)
+		if err := validateRing(r); err != nil {
+			return x.Wrapf(err, "Invalid %s of polygon", ringName(i))
+		}
+		if isClockwise(r) != (i > 0) {
+			want, got := "counter-clockwise", "clockwise"
+			if i > 0 {
+				want, got = got, want
+			}
+			return x.Errorf("The %s of polygon is %s, but should be %s. Reverse the order of its "+
+				"coordinates, or have the server reorient it with --geo_repair", ringName(i), got,
+				want)
+		}
+	}
+	return nil
+}
+
+// validateRing returns an error if the ring r has less than 4 coordinates, isn't closed or two of
+// its edges cross.
+func validateRing(r *geom.LinearRing) error {
+	n := r.NumCoords()
+	if n < 4 {
+		return x.Errorf("Ring has %d coordinates, but needs at least 4", n)
+	}
+	first, last := r.Coord(0), r.Coord(n-1)
+	if first.X() != last.X() || first.Y() != last.Y() {
+		return x.Errorf("Ring isn't closed, its last coordinate %v should be the same as its first %v",
+			[]float64(last), []float64(first))
+	}
+	// The last coordinate repeats the first one, so edge i goes from vertex i to i+1 of the m
+	// vertices.
+	m := n - 1
+	pts := make([]s2.Point, m)
+	for i := range pts {
+		pts[i] = pointFromCoord(r.Coord(i))
+	}
+	for i := 0; i < m; i++ {
+		// Edges next to each other share a vertex, so we start at the edge after the next one, and
+		// the first edge isn't compared with the last.
+		for j := i + 2; j < m && !(i == 0 && j == m-1); j++ {
+			if s2.CrossingSign(pts[i], pts[i+1], pts[j], pts[(j+1)%m]) == s2.Cross {
+				return x.Errorf("Ring crosses itself, its edge from %v to %v crosses the one from "+
+					"%v to %v", []float64(r.Coord(i)), []float64(r.Coord(i+1)),
+					[]float64(r.Coord(j)), []float64(r.Coord(j+1)))
+			}
+		}
+	}
+	return nil
+}
+
+// OrientGeo returns the geometry g with the rings of its polygons which are wound the wrong way
+// for ValidateGeo reversed, and whether there were any.
+func OrientGeo(g geom.T) (geom.T, bool) {
+	switch v := g.(type) {
+	case *geom.Polygon:
+		flat := append([]float64(nil), v.FlatCoords()...)
+		if !orientRings(flat, 0, v.Ends(), v.Layout()) {
+			return g, false
+		}
+		return geom.NewPolygonFlat(v.Layout(), flat, v.Ends()), true
+	case *geom.MultiPolygon:
+		flat := append([]float64(nil), v.FlatCoords()...)
+		changed, start := false, 0
+		for _, ends := range v.Endss() {
+			if orientRings(flat, start, ends, v.Layout()) {
+				changed = true
+			}
+			if len(ends) > 0 {
+				start = ends[len(ends)-1]
+			}
+		}
+		if !changed {
+			return g, false
+		}
+		return geom.NewMultiPolygonFlat(v.Layout(), flat, v.Endss()), true
+	case *GeometryCollection:
+		geoms := make([]geom.T, len(v.Geoms()))
+		changed := false
+		for i, m := range v.Geoms() {
+			var ok bool
+			if geoms[i], ok = OrientGeo(m); ok {
+				changed = true
+			}
+		}
+		if !changed {
+			return g, false
+		}
+		return NewGeometryCollection(geoms...), true
+	}
+	return g, false
+}
+
+// orientRings reverses in place the rings of a polygon in flat, from start up to each of ends,
+// which are wound the wrong way. It returns whether there were any.
+func orientRings(flat []float64, start int, ends []int, layout geom.Layout) bool {
+	stride := layout.Stride()
+	changed := false
+	for i, end := range ends {
+		ring := flat[start:end]
+		start = end
+		// Rings too short to be valid are left to ValidateGeo.
+		if len(ring) < 4*stride || isClockwise(geom.NewLinearRingFlat(layout, ring)) == (i > 0) {
+			continue
+		}
+		for a, b := 0, len(ring)-stride; a < b; a, b = a+stride, b-stride {
+			for k := 0; k < stride; k++ {
+				ring[a+k], ring[b+k] = ring[b+k], ring[a+k]
+			}
+		}
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestValidateGeo(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.4, 37.7}),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}}),
+		geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+			{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+		}),
+		NewGeometryCollection(geom.NewMultiPoint(geom.XY).MustSetCoords(
+			[]geom.Coord{{180, 90}, {-180, -90}})),
+	} {
+		require.NoError(t, ValidateGeo(g), "%#v", g)
+	}
+
+	for _, tc := range []struct {
+		g   geom.T
+		err string
+	}{
+		{geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{37.7, -122.4}), "out of range"},
+		{geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}}), "at least 2"},
+		{geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {0, 0}},
+		}), "at least 4"},
+		{geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+		}), "isn't closed"},
+		// A bow tie.
+		{geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {0, 4}, {4, 4}, {0, 0}},
+		}), "crosses itself"},
+		{geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+		}), "outer ring of polygon is clockwise"},
+		{geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}},
+		}), "hole 1 of polygon is counter-clockwise"},
+		{NewGeometryCollection(geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+			{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}},
+			{{{5, 5}, {6, 5}, {6, 6}, {5, 5.5}}},
+		})), "Invalid polygon 1 of multipolygon"},
+	} {
+		err := ValidateGeo(tc.g)
+		require.Error(t, err, "%#v", tc.g)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestOrientGeo(t *testing.T) {
+	ccw := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	})
+	g, ok := OrientGeo(ccw)
+	require.False(t, ok)
+	require.Equal(t, ccw, g)
+
+	// Both rings wound the wrong way.
+	cw := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}},
+	})
+	g, ok = OrientGeo(cw)
+	require.True(t, ok)
+	require.NoError(t, ValidateGeo(g))
+	require.Equal(t, [][]geom.Coord{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	}, g.(*geom.Polygon).Coords())
+	// The polygon given isn't modified.
+	require.Equal(t, geom.Coord{0, 4}, cw.Coords()[0][1])
+
+	mp := geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+		ccw.Coords(), cw.Coords(),
+	})
+	g, ok = OrientGeo(NewGeometryCollection(mp))
+	require.True(t, ok)
+	require.NoError(t, ValidateGeo(g))
+	oriented := g.(*GeometryCollection).Geoms()[0].(*geom.MultiPolygon)
+	require.Equal(t, ccw.Coords(), oriented.Polygon(0).Coords())
+	require.Equal(t, ccw.Coords(), oriented.Polygon(1).Coords())
+}
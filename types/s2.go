@@ -130,12 +130,165 @@ func Intersects(l1 *s2.Loop, l2 *s2.Loop) bool {
 	return intersects(l1, l2)
 }
 
+// polygon is a polygon with holes. Its interior is that of loop, except for those of its holes.
+type polygon struct {
+	loop  *s2.Loop
+	holes []*s2.Loop
+}
+
+// ContainsPoint returns true if the point is inside the polygon, but not inside any of its holes.
+func (p *polygon) ContainsPoint(pt s2.Point) bool {
+	if !p.loop.ContainsPoint(pt) {
+		return false
+	}
+	for _, h := range p.holes {
+		if h.ContainsPoint(pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// inHole returns true if the loop l is inside a hole of the polygon p.
+func (p *polygon) inHole(l *s2.Loop) bool {
+	for _, h := range p.holes {
+		if Contains(h, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonContains checks whether polygon a contains polygon b. Besides the outer loop of a
+// containing b's, each hole of a must be outside of b or inside one of its holes.
+func polygonContains(a *polygon, b *polygon) bool {
+	if !Contains(a.loop, b.loop) {
+		return false
+	}
+	for _, h := range a.holes {
+		if Intersects(h, b.loop) && !b.inHole(h) {
+			return false
+		}
+	}
+	return true
+}
+
+// polygonIntersects returns true if the two polygons intersect. Polygons which only intersect
+// within a hole of the other don't.
+func polygonIntersects(a *polygon, b *polygon) bool {
+	return Intersects(a.loop, b.loop) && !a.inHole(b.loop) && !b.inHole(a.loop)
+}
+
+// polygonContainsPolyline checks whether polygon p contains polyline l, which can't cross into
+// any of its holes.
+func polygonContainsPolyline(p *polygon, l *s2.Polyline) bool {
+	if !ContainsPolyline(p.loop, l) {
+		return false
+	}
+	for _, h := range p.holes {
+		if IntersectsPolyline(h, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// polygonIntersectsPolyline returns true if the polyline l intersects polygon p, which it doesn't
+// when it's inside one of its holes.
+func polygonIntersectsPolyline(p *polygon, l *s2.Polyline) bool {
+	if !IntersectsPolyline(p.loop, l) {
+		return false
+	}
+	for _, h := range p.holes {
+		if ContainsPolyline(h, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// edgesCrossPolyline returns true if an edge of the polyline p crosses an edge of the loop l.
+func edgesCrossPolyline(l *s2.Loop, p *s2.Polyline) bool {
+	pts := *p
+	for i := 0; i+1 < len(pts); i++ {
+		crosser := s2.NewChainEdgeCrosser(pts[i], pts[i+1], l.Vertex(0))
+		for j := 1; j <= l.NumEdges(); j++ {
+			if crosser.EdgeOrVertexChainCrossing(l.Vertex(j)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsPolyline checks whether loop l contains polyline p, which is when all its vertices are
+// inside the loop and none of its edges cross the loop's.
+func ContainsPolyline(l *s2.Loop, p *s2.Polyline) bool {
+	if !l.RectBound().Contains(p.RectBound()) {
+		return false
+	}
+	for _, v := range *p {
+		if !l.ContainsPoint(v) {
+			return false
+		}
+	}
+	return !edgesCrossPolyline(l, p)
+}
+
+// IntersectsPolyline returns true if the polyline p intersects the loop l.
+func IntersectsPolyline(l *s2.Loop, p *s2.Polyline) bool {
+	if !l.RectBound().Intersects(p.RectBound()) {
+		return false
+	}
+	for _, v := range *p {
+		if l.ContainsPoint(v) {
+			return true
+		}
+	}
+	return edgesCrossPolyline(l, p)
+}
+
+// PolylinesIntersect returns true if an edge of polyline a crosses or touches an edge of b.
+func PolylinesIntersect(a *s2.Polyline, b *s2.Polyline) bool {
+	if !a.RectBound().Intersects(b.RectBound()) {
+		return false
+	}
+	pa, pb := *a, *b
+	for i := 0; i+1 < len(pa); i++ {
+		crosser := s2.NewChainEdgeCrosser(pa[i], pa[i+1], pb[0])
+		for j := 1; j < len(pb); j++ {
+			if crosser.ChainCrossingSign(pb[j]) != s2.DoNotCross {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lineTolerance is how far a point can be from a polyline and still be on it, as points seldom
+// lie exactly on the great circle arcs of its edges.
+var lineTolerance = EarthAngle(1)
+
+// PolylineContainsPoint returns true if the point pt lies on the polyline p.
+func PolylineContainsPoint(p *s2.Polyline, pt s2.Point) bool {
+	pts := *p
+	for i := 0; i+1 < len(pts); i++ {
+		if s2.DistanceFromSegment(pt, pts[i], pts[i+1]) <= lineTolerance {
+			return true
+		}
+	}
+	return false
+}
+
 func closed(coords []geom.Coord) bool {
 	l := len(coords)
 	return coords[0][0] == coords[l-1][0] && coords[0][1] == coords[l-1][1]
 }
 
 func convertToGeom(str string) (geom.T, error) {
+	if ok, wkt := isWKT(str); ok {
+		return parseWKT(wkt)
+	}
 	s := x.WhiteSpace.Replace(str)
 	if len(s) < 5 { // [1,2]
 		return nil, x.Errorf("Invalid coordinates")
@@ -144,6 +297,15 @@ func convertToGeom(str string) (geom.T, error) {
 	var m json.RawMessage
 	var err error
 
+	if s[0] == '{' {
+		// A GeoJSON geometry object, like the values of a variable given to a geo function.
+		var g1 geom.T
+		if err := UnmarshalGeoJSON([]byte(s), &g1); err != nil {
+			return nil, x.Wrapf(err, "Invalid GeoJSON")
+		}
+		return g1, nil
+	}
+
 	if s[0:4] == "[[[[" {
 		g.Type = "MultiPolygon"
 		err = m.UnmarshalJSON([]byte(s))
@@ -53,3 +53,13 @@ func TestConvertToGeoJson_PolyError2(t *testing.T) {
 	_, err := convertToGeom(s)
 	require.Error(t, err)
 }
+
+func TestConvertToGeoJson_Object(t *testing.T) {
+	s := `{"type": "LineString", "coordinates": [[1, 2], [3, 4]]}`
+	b, err := convertToGeom(s)
+	require.NoError(t, err)
+	require.Equal(t, []geom.Coord{{1, 2}, {3, 4}}, b.(*geom.LineString).Coords())
+
+	_, err = convertToGeom(`{"type": "Curve", "coordinates": [1, 2]}`)
+	require.Error(t, err)
+}
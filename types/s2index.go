@@ -22,6 +22,7 @@ import (
 	"github.com/golang/geo/s2"
 	"github.com/twpayne/go-geom"
 
+	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -37,23 +38,25 @@ func parentCoverTokens(parents s2.CellUnion, cover s2.CellUnion) []string {
 	return tokens
 }
 
-// IndexTokens returns the tokens to be used in a geospatial index for the given geometry. If the
-// geometry is not supported it returns an error.
-func IndexGeoTokens(g geom.T) ([]string, error) {
-	parents, cover, err := indexCells(g)
+// IndexTokens returns the tokens to be used in a geospatial index with the parameters gi, which are
+// the default ones if gi is nil, for the given geometry. If the geometry is not supported it
+// returns an error.
+func IndexGeoTokens(g geom.T, gi *protos.GeoIndex) ([]string, error) {
+	parents, cover, err := indexCells(g, gi)
 	if err != nil {
 		return nil, err
 	}
 	return parentCoverTokens(parents, cover), nil
 }
 
-// IndexKeysForCap returns the keys to be used in a geospatial index for a Cap.
-func indexCellsForCap(c s2.Cap) s2.CellUnion {
+// indexCellsForRegion returns the cells covering a Cap or a Rect, to look up in a geospatial index.
+func indexCellsForRegion(c s2.Region, gi *protos.GeoIndex) s2.CellUnion {
+	minLevel, maxLevel, maxCells := GeoLevels(gi)
 	rc := &s2.RegionCoverer{
-		MinLevel: MinCellLevel,
-		MaxLevel: MaxCellLevel,
+		MinLevel: minLevel,
+		MaxLevel: maxLevel,
 		LevelMod: 0,
-		MaxCells: MaxCells,
+		MaxCells: maxCells,
 	}
 	return rc.Covering(c)
 }
@@ -67,22 +70,24 @@ const (
 // the min level that contain this geometry. The second is the cover, which are the smallest
 // possible cells required to cover the region. This makes it easier at query time to query only the
 // parents or only the cover or both depending on whether it is a within, contains or intersects
-// query.
-func indexCells(g geom.T) (parents, cover s2.CellUnion, err error) {
+// query. The cells are of the levels of a geo index with the parameters gi, or the default ones if
+// gi is nil.
+func indexCells(g geom.T, gi *protos.GeoIndex) (parents, cover s2.CellUnion, err error) {
 	if g.Stride() != 2 {
 		return nil, nil, x.Errorf("Covering only available for 2D co-ordinates.")
 	}
+	minLevel, maxLevel, maxCells := GeoLevels(gi)
 	switch v := g.(type) {
 	case *geom.Point:
-		p, c := indexCellsForPoint(v, MinCellLevel, MaxCellLevel)
+		p, c := indexCellsForPoint(v, minLevel, maxLevel)
 		return p, c, nil
 	case *geom.Polygon:
 		l, err := loopFromPolygon(v)
 		if err != nil {
 			return nil, nil, err
 		}
-		cover := coverLoop(l, MinCellLevel, MaxCellLevel, MaxCells)
-		parents := getParentCells(cover, MinCellLevel)
+		cover := coverLoop(l, minLevel, maxLevel, maxCells)
+		parents := getParentCells(cover, minLevel)
 		return parents, cover, nil
 	case *geom.MultiPolygon:
 		var cover s2.CellUnion
@@ -93,10 +98,49 @@ func indexCells(g geom.T) (parents, cover s2.CellUnion, err error) {
 			if err != nil {
 				return nil, nil, err
 			}
-			cover = append(cover, coverLoop(l, MinCellLevel, MaxCellLevel, MaxCells)...)
+			cover = append(cover, coverLoop(l, minLevel, maxLevel, maxCells)...)
 		}
 		// Get parents for all cells in cover.
-		parents := getParentCells(cover, MinCellLevel)
+		parents := getParentCells(cover, minLevel)
+		return parents, cover, nil
+	case *geom.LineString:
+		p, err := polylineFromLineString(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		cover := coverPolyline(p, minLevel, maxLevel, maxCells)
+		parents := getParentCells(cover, minLevel)
+		return parents, cover, nil
+	case *geom.MultiLineString:
+		lines, err := polylinesFromMultiLineString(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		var cover s2.CellUnion
+		for _, p := range lines {
+			cover = append(cover, coverPolyline(p, minLevel, maxLevel, maxCells)...)
+		}
+		parents := getParentCells(cover, minLevel)
+		return parents, cover, nil
+	case *geom.MultiPoint:
+		// Each point is covered by its cell of the max level, as a single point is.
+		cover := make(s2.CellUnion, 0, v.NumPoints())
+		for i := 0; i < v.NumPoints(); i++ {
+			_, c := indexCellsForPoint(v.Point(i), minLevel, maxLevel)
+			cover = append(cover, c...)
+		}
+		parents := getParentCells(cover, minLevel)
+		return parents, cover, nil
+	case *GeometryCollection:
+		var cover s2.CellUnion
+		for _, m := range v.Geoms() {
+			_, c, err := indexCells(m, gi)
+			if err != nil {
+				return nil, nil, err
+			}
+			cover = append(cover, c...)
+		}
+		parents := getParentCells(cover, minLevel)
 		return parents, cover, nil
 	default:
 		return nil, nil, x.Errorf("Cannot index geometry of type %T", v)
@@ -110,8 +154,45 @@ const (
 	MaxCellLevel = 16 // Approx 120m x 180m
 	// MaxCells is the maximum number of cells to use when indexing regions.
 	MaxCells = 18
+	// MaxGeoLevel is the largest level of S2 cells.
+	MaxGeoLevel = 30
 )
 
+// GeoLevels returns the smallest and largest cell levels, and the maximum number of cells, of the
+// covers of a geo index with the parameters gi, which are the default ones if gi is nil.
+func GeoLevels(gi *protos.GeoIndex) (minLevel, maxLevel, maxCells int) {
+	if gi == nil {
+		return MinCellLevel, MaxCellLevel, MaxCells
+	}
+	return int(gi.MinLevel), int(gi.MaxLevel), int(gi.MaxCells)
+}
+
+// ValidateGeoIndex returns an error if the parameters gi can't be used for a geo index.
+func ValidateGeoIndex(gi *protos.GeoIndex) error {
+	if IsGeohashIndex(gi) {
+		if gi.MinLevel != 0 || gi.MaxLevel != 0 || gi.MaxCells != 0 {
+			return x.Errorf("Geohash index can't have cell levels")
+		}
+		if gi.Precision > MaxGeohashPrecision {
+			return x.Errorf("Precision of geohash index can't be more than %d, but got %d",
+				MaxGeohashPrecision, gi.Precision)
+		}
+		return nil
+	}
+	if gi.MaxLevel > MaxGeoLevel {
+		return x.Errorf("Max level of geo index can't be more than %d, but got %d", MaxGeoLevel,
+			gi.MaxLevel)
+	}
+	if gi.MinLevel > gi.MaxLevel {
+		return x.Errorf("Min level of geo index can't be more than its max level %d, but got %d",
+			gi.MaxLevel, gi.MinLevel)
+	}
+	if gi.MaxCells == 0 {
+		return x.Errorf("Max cells of geo index should be positive")
+	}
+	return nil
+}
+
 func pointFromCoord(r geom.Coord) s2.Point {
 	// The geojson spec says that coordinates are specified as [long, lat]
 	// We assume that any data encoded in the database follows that format.
@@ -119,17 +200,75 @@ func pointFromCoord(r geom.Coord) s2.Point {
 	return s2.PointFromLatLng(ll)
 }
 
+// CoarsePoint returns the center of the S2 cell of the given level, from 0 to 30, that the center
+// of the bounds of g falls in. It stands for g with the precision of cells of that level.
+func CoarsePoint(g geom.T, level int) *geom.Point {
+	c := s2.CellIDFromLatLng(boundsCenter(g)).Parent(level).LatLng()
+	return geom.NewPointFlat(geom.XY, []float64{c.Lng.Degrees(), c.Lat.Degrees()})
+}
+
+// GeoCell returns the token of the S2 cell of the given level, from 0 to 30, that the center of the
+// bounds of g falls in. Geometries are grouped by it for cell() in @groupby.
+func GeoCell(g geom.T, level int) string {
+	return s2.CellIDFromLatLng(boundsCenter(g)).Parent(level).ToToken()
+}
+
+// Centroid returns the centroid on the sphere of the centers of the bounds of gs, which is nil if
+// there are none.
+func Centroid(gs []geom.T) *geom.Point {
+	if len(gs) == 0 {
+		return nil
+	}
+	var sum s2.Point
+	for _, g := range gs {
+		sum = s2.Point{Vector: sum.Add(s2.PointFromLatLng(boundsCenter(g)).Vector)}
+	}
+	ll := boundsCenter(gs[0])
+	// Points on opposite sides of the sphere have no centroid, we take the first one then.
+	if sum.Norm() != 0 {
+		ll = s2.LatLngFromPoint(sum)
+	}
+	return geom.NewPointFlat(geom.XY, []float64{ll.Lng.Degrees(), ll.Lat.Degrees()})
+}
+
+func boundsCenter(g geom.T) s2.LatLng {
+	b := g.Bounds()
+	return s2.LatLngFromDegrees((b.Min(1)+b.Max(1))/2, (b.Min(0)+b.Max(0))/2)
+}
+
 // PointFromPoint converts a geom.Point to a s2.Point
 func pointFromPoint(p *geom.Point) s2.Point {
 	return pointFromCoord(p.Coords())
 }
 
-// loopFromPolygon converts a geom.Polygon to a s2.Loop. We use loops instead of s2.Polygon as the
-// s2.Polygon implemention is incomplete.
+// loopFromPolygon converts the outer ring of a geom.Polygon to a s2.Loop, which covers the whole
+// polygon. The holes of polygons are only taken into account by polygonFromPolygon.
 func loopFromPolygon(p *geom.Polygon) (*s2.Loop, error) {
-	// go implementation of s2 does not support more than one loop (and will panic if the size of
-	// the loops array > 1). So we will skip the holes in the polygon and just use the outer loop.
-	r := p.LinearRing(0)
+	return loopFromLinearRing(p.LinearRing(0))
+}
+
+// polygonFromPolygon converts a geom.Polygon to a polygon, with a s2.Loop for each of its rings. We
+// don't use s2.Polygon as the go implementation of s2 does not support more than one loop (and will
+// panic if the size of the loops array > 1).
+func polygonFromPolygon(p *geom.Polygon) (*polygon, error) {
+	l, err := loopFromPolygon(p)
+	if err != nil {
+		return nil, err
+	}
+	poly := &polygon{loop: l}
+	for i := 1; i < p.NumLinearRings(); i++ {
+		h, err := loopFromLinearRing(p.LinearRing(i))
+		if err != nil {
+			return nil, err
+		}
+		poly.holes = append(poly.holes, h)
+	}
+	return poly, nil
+}
+
+// loopFromLinearRing converts a ring of a geom.Polygon to a s2.Loop, whose interior is the area
+// the ring encloses.
+func loopFromLinearRing(r *geom.LinearRing) (*s2.Loop, error) {
 	n := r.NumCoords()
 	if n < 4 {
 		return nil, x.Errorf("Can't convert ring with less than 4 pts")
@@ -148,6 +287,32 @@ func loopFromPolygon(p *geom.Polygon) (*s2.Loop, error) {
 	return l, nil
 }
 
+// polylineFromLineString converts a geom.LineString to a s2.Polyline.
+func polylineFromLineString(l *geom.LineString) (*s2.Polyline, error) {
+	n := l.NumCoords()
+	if n < 2 {
+		return nil, x.Errorf("Can't convert line with less than 2 pts")
+	}
+	pts := make(s2.Polyline, n)
+	for i := 0; i < n; i++ {
+		pts[i] = pointFromCoord(l.Coord(i))
+	}
+	return &pts, nil
+}
+
+// polylinesFromMultiLineString converts each line of a geom.MultiLineString to a s2.Polyline.
+func polylinesFromMultiLineString(ml *geom.MultiLineString) ([]*s2.Polyline, error) {
+	lines := make([]*s2.Polyline, 0, ml.NumLineStrings())
+	for i := 0; i < ml.NumLineStrings(); i++ {
+		p, err := polylineFromLineString(ml.LineString(i))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, p)
+	}
+	return lines, nil
+}
+
 // Checks if a ring is clockwise or counter-clockwise. Note: This uses the algorithm for planar
 // polygons and doesn't work for spherical polygons that contain the poles or the antimeridan
 // discontinuity. We use this as a fast approximation instead.
@@ -222,6 +387,16 @@ func coverLoop(l *s2.Loop, minLevel int, maxLevel int, maxCells int) s2.CellUnio
 	return rc.Covering(l)
 }
 
+func coverPolyline(p *s2.Polyline, minLevel int, maxLevel int, maxCells int) s2.CellUnion {
+	rc := &s2.RegionCoverer{
+		MinLevel: minLevel,
+		MaxLevel: maxLevel,
+		LevelMod: 0,
+		MaxCells: maxCells,
+	}
+	return rc.Covering(p)
+}
+
 // appendTokens creates tokens with a certain prefix and append.
 func createTokens(cu s2.CellUnion, prefix string) (toks []string) {
 	for _, c := range cu {
@@ -29,6 +29,8 @@ import (
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
 	"github.com/twpayne/go-geom/encoding/wkb"
+
+	"github.com/dgraph-io/dgraph/protos"
 )
 
 func loadPolygon(name string) (geom.T, error) {
@@ -52,7 +54,7 @@ func loadPolygon(name string) (geom.T, error) {
 
 func TestIndexCellsPoint(t *testing.T) {
 	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
-	parents, cover, err := indexCells(p)
+	parents, cover, err := indexCells(p, nil)
 	require.NoError(t, err)
 	require.Len(t, parents, MaxCellLevel-MinCellLevel+1)
 	c := parents[0]
@@ -100,7 +102,7 @@ func printCells(cu s2.CellUnion) {
 func TestIndexCellsPolygon(t *testing.T) {
 	p, err := loadPolygon("testdata/zip.json")
 	require.NoError(t, err)
-	parents, cover, err := indexCells(p)
+	parents, cover, err := indexCells(p, nil)
 	require.NoError(t, err)
 	if len(cover) > MaxCells {
 		t.Errorf("Expected less than %d cells. Got %d instead.", MaxCells, len(cover))
@@ -114,6 +116,64 @@ func TestIndexCellsPolygon(t *testing.T) {
 	require.True(t, len(parents) > len(cover))
 }
 
+func TestIndexCellsLineString(t *testing.T) {
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.4194, 37.7749}, {-122.2711, 37.8044}, {-121.8863, 37.3382},
+	})
+	parents, cover, err := indexCells(l, nil)
+	require.NoError(t, err)
+	require.True(t, len(cover) > 0 && len(cover) <= MaxCells)
+	for _, c := range cover {
+		if c.Level() > MaxCellLevel || c.Level() < MinCellLevel {
+			t.Errorf("Invalid cell level %d.", c.Level())
+		}
+		require.Contains(t, parents, c)
+	}
+
+	ml := geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.4194, 37.7749}, {-122.2711, 37.8044}},
+		{{-118.2437, 34.0522}, {-117.1611, 32.7157}},
+	})
+	parents, cover, err = indexCells(ml, nil)
+	require.NoError(t, err)
+	for _, c := range cover {
+		require.Contains(t, parents, c)
+	}
+
+	_, _, err = indexCells(geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}}), nil)
+	require.Error(t, err)
+}
+
+func TestIndexCellsCollection(t *testing.T) {
+	mp := geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.082506, 37.4249518}, {-118.2437, 34.0522},
+	})
+	parents, cover, err := indexCells(mp, nil)
+	require.NoError(t, err)
+	require.Len(t, cover, 2)
+	require.Equal(t, "808fb9f81", cover[0].ToToken())
+	for _, c := range cover {
+		require.Equal(t, MaxCellLevel, c.Level())
+		require.Contains(t, parents, c)
+	}
+
+	p, err := loadPolygon("testdata/zip.json")
+	require.NoError(t, err)
+	_, pcover, err := indexCells(p, nil)
+	require.NoError(t, err)
+	gc := NewGeometryCollection(mp, p)
+	parents, cover, err = indexCells(gc, nil)
+	require.NoError(t, err)
+	require.Len(t, cover, 2+len(pcover))
+	for _, c := range cover {
+		require.Contains(t, parents, c)
+	}
+
+	_, _, err = indexCells(NewGeometryCollection(p,
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}})), nil)
+	require.Error(t, err)
+}
+
 func TestKeyGeneratorPoint(t *testing.T) {
 	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
 	data, err := wkb.Marshal(p, binary.LittleEndian)
@@ -125,11 +185,35 @@ func TestKeyGeneratorPoint(t *testing.T) {
 	require.NoError(t, err)
 	g := gc.Value.(geom.T)
 
-	keys, err := IndexGeoTokens(g)
+	keys, err := IndexGeoTokens(g, nil)
 	require.NoError(t, err)
 	require.Len(t, keys, MaxCellLevel-MinCellLevel+1+1) // +1 for the cover
 }
 
+func TestIndexCellsGeoIndex(t *testing.T) {
+	gi := &protos.GeoIndex{MinLevel: 8, MaxLevel: 20, MaxCells: 4}
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
+	parents, cover, err := indexCells(p, gi)
+	require.NoError(t, err)
+	require.Len(t, parents, 13)
+	require.Equal(t, 8, parents[0].Level())
+	require.Len(t, cover, 1)
+	require.Equal(t, 20, cover[0].Level())
+
+	poly, err := loadPolygon("testdata/zip.json")
+	require.NoError(t, err)
+	_, cover, err = indexCells(poly, gi)
+	require.NoError(t, err)
+	for _, c := range cover {
+		require.True(t, c.Level() >= 8 && c.Level() <= 20)
+	}
+
+	require.NoError(t, ValidateGeoIndex(gi))
+	require.Error(t, ValidateGeoIndex(&protos.GeoIndex{MinLevel: 10, MaxLevel: 8, MaxCells: 4}))
+	require.Error(t, ValidateGeoIndex(&protos.GeoIndex{MinLevel: 8, MaxLevel: 31, MaxCells: 4}))
+	require.Error(t, ValidateGeoIndex(&protos.GeoIndex{MinLevel: 8, MaxLevel: 20}))
+}
+
 func TestKeyGeneratorPolygon(t *testing.T) {
 	p, err := loadPolygon("testdata/zip.json")
 	require.NoError(t, err)
@@ -142,7 +226,7 @@ func TestKeyGeneratorPolygon(t *testing.T) {
 	require.NoError(t, err)
 	g := gc.Value.(geom.T)
 
-	keys, err := IndexGeoTokens(g)
+	keys, err := IndexGeoTokens(g, nil)
 	require.NoError(t, err)
 	require.Len(t, keys, 66)
 }
@@ -229,7 +313,7 @@ func BenchmarkKeyGeneratorPoint(b *testing.B) {
 
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		IndexGeoTokens(g)
+		IndexGeoTokens(g, nil)
 	}
 }
 
@@ -251,7 +335,7 @@ func BenchmarkKeyGeneratorPolygon(b *testing.B) {
 
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		IndexGeoTokens(g)
+		IndexGeoTokens(g, nil)
 	}
 }
 
@@ -279,3 +363,40 @@ func benchToLoop(b *testing.B, file string) {
 		_, _ = loopFromPolygon(p.(*geom.Polygon))
 	}
 }
+
+func TestCoarsePoint(t *testing.T) {
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
+	c := CoarsePoint(p, 10)
+	// The coarse point is the center of the level 10 cell of p.
+	cell := s2.CellIDFromLatLng(s2.LatLngFromPoint(pointFromPoint(p))).Parent(10)
+	require.Equal(t, cell, s2.CellIDFromLatLng(s2.LatLngFromPoint(pointFromPoint(c))).Parent(10))
+	require.NotEqual(t, p.Coords(), c.Coords())
+	again := CoarsePoint(c, 10)
+	require.InDelta(t, c.X(), again.X(), 1e-9)
+	require.InDelta(t, c.Y(), again.Y(), 1e-9)
+}
+
+func TestGeoCell(t *testing.T) {
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
+	require.Equal(t, "808fb9f81", GeoCell(p, MaxCellLevel))
+	require.Equal(t, "808c", GeoCell(p, 5))
+	// A polygon is in the cell of the center of its bounds.
+	poly := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.1, 37.4}, {-122.06, 37.4}, {-122.06, 37.45}, {-122.1, 37.4}},
+	})
+	c := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.08, 37.425})
+	require.Equal(t, GeoCell(c, 12), GeoCell(poly, 12))
+}
+
+func TestCentroid(t *testing.T) {
+	require.Nil(t, Centroid(nil))
+	a := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122, 37})
+	b := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-121, 37})
+	c := Centroid([]geom.T{a, b})
+	require.InDelta(t, -121.5, c.X(), 1e-9)
+	// The centroid of points on the same parallel is a little closer to the pole.
+	require.True(t, c.Y() > 37 && c.Y() < 37.01, "%v", c.Y())
+	c = Centroid([]geom.T{a})
+	require.InDelta(t, -122, c.X(), 1e-9)
+	require.InDelta(t, 37, c.Y(), 1e-9)
+}
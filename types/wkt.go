@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// wktPrefix may be put before a geo argument to have it read as Well-Known Text, which is
+// otherwise detected by it starting with a letter.
+const wktPrefix = "wkt:"
+
+// isWKT returns true if the geo argument s is given as Well-Known Text, and the text without
+// wktPrefix.
+func isWKT(s string) (bool, string) {
+	s = strings.TrimSpace(s)
+	if len(s) >= len(wktPrefix) && strings.EqualFold(s[:len(wktPrefix)], wktPrefix) {
+		return true, s[len(wktPrefix):]
+	}
+	return len(s) > 0 && unicode.IsLetter(rune(s[0])), s
+}
+
+// wktParser reads the two-dimensional geometries of Well-Known Text that we can index, i.e.
+// points, polygons, multipolygons, linestrings and multilinestrings.
+type wktParser struct {
+	s   string
+	pos int
+}
+
+// parseWKT converts the Well-Known Text s to a geom.T.
+func parseWKT(s string) (geom.T, error) {
+	p := &wktParser{s: s}
+	g, err := p.geometry()
+	if err != nil {
+		return nil, x.Wrapf(err, "Invalid WKT")
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, x.Errorf("Invalid WKT: unexpected %q after geometry", p.s[p.pos:])
+	}
+	return g, nil
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && unicode.IsLetter(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+func (p *wktParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return x.Errorf("expected %q at offset %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// more consumes a comma and returns true if there is another element in the list, or returns
+// false at the closing parenthesis of the list.
+func (p *wktParser) more() (bool, error) {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == ',' {
+		p.pos++
+		return true, nil
+	}
+	return false, p.expect(')')
+}
+
+func (p *wktParser) number() (float64, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, x.Errorf("expected a number at offset %d", start)
+	}
+	return f, nil
+}
+
+func (p *wktParser) coord() (geom.Coord, error) {
+	lng, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	lat, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	return geom.Coord{lng, lat}, nil
+}
+
+// coords reads a parenthesized list of coordinates.
+func (p *wktParser) coords() ([]geom.Coord, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var cs []geom.Coord
+	for {
+		c, err := p.coord()
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+		if ok, err := p.more(); err != nil {
+			return nil, err
+		} else if !ok {
+			return cs, nil
+		}
+	}
+}
+
+// rings reads a parenthesized list of lists of coordinates, i.e. the rings of a polygon or the
+// lines of a multilinestring.
+func (p *wktParser) rings() ([][]geom.Coord, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var rs [][]geom.Coord
+	for {
+		r, err := p.coords()
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+		if ok, err := p.more(); err != nil {
+			return nil, err
+		} else if !ok {
+			return rs, nil
+		}
+	}
+}
+
+// polygon reads the rings of a polygon, each of which should be closed.
+func (p *wktParser) polygon() ([][]geom.Coord, error) {
+	rs, err := p.rings()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		if !closed(r) {
+			return nil, x.Errorf("Last coord not same as first")
+		}
+	}
+	return rs, nil
+}
+
+func (p *wktParser) geometry() (geom.T, error) {
+	switch typ := p.word(); typ {
+	case "POINT":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		c, err := p.coord()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return geom.NewPoint(geom.XY).SetCoords(c)
+	case "LINESTRING":
+		cs, err := p.coords()
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineString(geom.XY).SetCoords(cs)
+	case "MULTILINESTRING":
+		rs, err := p.rings()
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiLineString(geom.XY).SetCoords(rs)
+	case "POLYGON":
+		rs, err := p.polygon()
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygon(geom.XY).SetCoords(rs)
+	case "MULTIPOLYGON":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		var ps [][][]geom.Coord
+		for {
+			rs, err := p.polygon()
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, rs)
+			if ok, err := p.more(); err != nil {
+				return nil, err
+			} else if !ok {
+				break
+			}
+		}
+		return geom.NewMultiPolygon(geom.XY).SetCoords(ps)
+	case "":
+		return nil, x.Errorf("expected a geometry type at offset %d", p.pos)
+	default:
+		return nil, x.Errorf("geometry type %s is not supported", typ)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestConvertToGeomWKT(t *testing.T) {
+	g, err := convertToGeom(`POINT (1.5 -2)`)
+	require.NoError(t, err)
+	require.Equal(t, geom.Coord{1.5, -2}, g.(*geom.Point).Coords())
+
+	// The prefix is optional, and the type is case insensitive.
+	g, err = convertToGeom(` wkt:linestring(1 2, 3 4,5 6)`)
+	require.NoError(t, err)
+	require.Equal(t, []geom.Coord{{1, 2}, {3, 4}, {5, 6}}, g.(*geom.LineString).Coords())
+
+	g, err = convertToGeom(`MULTILINESTRING((1 2, 3 4), (5 6, 7 8))`)
+	require.NoError(t, err)
+	require.Equal(t, [][]geom.Coord{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+		g.(*geom.MultiLineString).Coords())
+
+	g, err = convertToGeom(`POLYGON((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 2, 2 2, 1 1))`)
+	require.NoError(t, err)
+	require.Equal(t, [][]geom.Coord{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {1, 1}},
+	}, g.(*geom.Polygon).Coords())
+
+	g, err = convertToGeom(`MULTIPOLYGON(((0 0, 1 0, 1 1, 0 0)), ((2 2, 3 2, 3 3, 2 2)))`)
+	require.NoError(t, err)
+	require.Equal(t, 2, g.(*geom.MultiPolygon).NumPolygons())
+}
+
+func TestConvertToGeomWKTError(t *testing.T) {
+	for _, s := range []string{
+		`wkt:`,
+		`POINT(1)`,
+		`POINT(1 2`,
+		`POINT(1 2) POINT(3 4)`,
+		`POINT(a b)`,
+		`POLYGON((0 0, 1 0, 1 1, 0 1))`,
+		`POLYGON((0 0, 1 0, 1 1, 0 0), (0.1 0.1, 0.2 0.1, 0.2 0.2))`,
+		`MULTIPOINT((1 2), (3 4))`,
+		`GEOMETRYCOLLECTION(POINT(1 2))`,
+	} {
+		_, err := convertToGeom(s)
+		require.Error(t, err, s)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package udf
+
+import (
+	"plugin"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Load loads the Go plugins of the comma separated list of paths, built with
+// go build -buildmode=plugin against the same sources as Dgraph. Plugins register their functions
+// in the init functions of their packages, which are run as they're loaded.
+func Load(paths string) error {
+	for _, p := range strings.Split(paths, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := plugin.Open(p); err != nil {
+			return x.Wrapf(err, "While loading plugin %s", p)
+		}
+		x.Printf("Loaded plugin of functions %s\n", p)
+	}
+	return nil
+}
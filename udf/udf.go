@@ -0,0 +1,230 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package udf keeps the user defined functions queries can call: filter functions, which
+// filter nodes by their values of a predicate, like
+//
+//	@filter(score(rating, "0.5"))
+//
+// and scalar functions of the values of variables in math blocks, like math(boost(r, d)). They're
+// registered by name, by programs embedding Dgraph or by plugins loaded with Load, and called with
+// the limits set with SetLimits.
+package udf
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Filter is a filter function. It's given the values of the predicate of a node, converted to the
+// type of the predicate in the schema, and the other arguments of the call, and returns whether
+// the node is kept.
+type Filter func(vals []types.Val, args []string) (bool, error)
+
+// Scalar is a scalar function. It's given the values of its arguments for a node, of the types of
+// their variables, like int64 or float64 for numbers, and returns its value for the node.
+type Scalar func(args []types.Val) (types.Val, error)
+
+type scalar struct {
+	arity int
+	fn    Scalar
+}
+
+var funcs = struct {
+	sync.RWMutex
+	filters map[string]Filter
+	scalars map[string]scalar
+}{filters: make(map[string]Filter), scalars: make(map[string]scalar)}
+
+// reserved are the names of the functions of queries and of math blocks.
+var reserved = map[string]bool{
+	"eq": true, "le": true, "ge": true, "lt": true, "gt": true, "min": true, "max": true,
+	"sum": true, "avg": true, "checkpwd": true, "regexp": true, "alloftext": true,
+	"anyoftext": true, "allofterms": true, "anyofterms": true, "has": true, "uid": true,
+	"uid_in": true, "val": true, "count": true, "near": true, "nearest": true, "within": true,
+	"withinbox": true, "contains": true, "intersects": true, "disjoint": true, "overlaps": true,
+	"exp": true, "ln": true, "sqrt": true,
+	"floor": true, "ceil": true, "since": true, "cond": true, "pow": true, "logbase": true,
+	"math": true,
+}
+
+func checkName(name string) {
+	x.AssertTruef(name != "" && !reserved[name], "Invalid name of function: %q", name)
+	for _, c := range name {
+		x.AssertTruef(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_',
+			"Names of functions are lower case: %q", name)
+	}
+	x.AssertTruef(funcs.filters[name] == nil && funcs.scalars[name].fn == nil,
+		"Function %s registered twice", name)
+}
+
+// RegisterFilter registers f as the filter function name.
+func RegisterFilter(name string, f Filter) {
+	funcs.Lock()
+	defer funcs.Unlock()
+	checkName(name)
+	funcs.filters[name] = f
+}
+
+// RegisterScalar registers f as the scalar function name, of arity arguments, from 1 to 3.
+func RegisterScalar(name string, arity int, f Scalar) {
+	funcs.Lock()
+	defer funcs.Unlock()
+	checkName(name)
+	x.AssertTruef(arity >= 1 && arity <= 3, "Scalar functions have 1 to 3 arguments: %s has %d",
+		name, arity)
+	funcs.scalars[name] = scalar{arity: arity, fn: f}
+}
+
+// IsFilter returns whether name is a filter function.
+func IsFilter(name string) bool {
+	funcs.RLock()
+	defer funcs.RUnlock()
+	return funcs.filters[name] != nil
+}
+
+// Arity returns the number of arguments of the scalar function name, or 0 if there is none.
+func Arity(name string) int {
+	funcs.RLock()
+	defer funcs.RUnlock()
+	return funcs.scalars[name].arity
+}
+
+// Limits limit the resources calls of functions use.
+type Limits struct {
+	// Timeout is the longest time a call can take, or zero for no limit. A call which takes longer
+	// fails, but keeps running until it returns, taking up its place among those of Concurrency.
+	Timeout time.Duration
+	// Concurrency is the most calls running at once. Other calls wait for one of them to return.
+	Concurrency int
+}
+
+// DefaultLimits are the limits of calls until SetLimits is called.
+var DefaultLimits = Limits{Timeout: 100 * time.Millisecond, Concurrency: runtime.NumCPU()}
+
+var limits = struct {
+	sync.RWMutex
+	Limits
+	running chan struct{}
+}{Limits: DefaultLimits, running: make(chan struct{}, DefaultLimits.Concurrency)}
+
+// SetLimits sets the limits of the calls made from then on.
+func SetLimits(l Limits) {
+	x.AssertTruef(l.Timeout >= 0 && l.Concurrency > 0, "Invalid limits of functions: %+v", l)
+	limits.Lock()
+	defer limits.Unlock()
+	limits.Limits = l
+	limits.running = make(chan struct{}, l.Concurrency)
+}
+
+type result struct {
+	val interface{}
+	err error
+}
+
+// call runs f within the limits, returning an error if it panics, takes too long, or ctx is
+// done first.
+func call(ctx context.Context, name string, f func() (interface{}, error)) (interface{}, error) {
+	limits.RLock()
+	timeout, running := limits.Timeout, limits.running
+	limits.RUnlock()
+
+	select {
+	case running <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-running }()
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: x.Errorf("Function %s panicked: %v", name, r)}
+			}
+		}()
+		val, err := f()
+		done <- result{val, err}
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case res := <-done:
+		if res.err != nil {
+			x.UDFErrors.Add(name, 1)
+		}
+		return res.val, res.err
+	case <-expired:
+		x.UDFErrors.Add(name, 1)
+		return nil, x.Errorf("Function %s took longer than %v", name, timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CallFilter calls the filter function name.
+func CallFilter(ctx context.Context, name string, vals []types.Val, args []string) (bool,
+	error) {
+	funcs.RLock()
+	f := funcs.filters[name]
+	funcs.RUnlock()
+	if f == nil {
+		return false, x.Errorf("Unknown filter function: %s", name)
+	}
+	keep, err := call(ctx, name, func() (interface{}, error) {
+		return f(vals, args)
+	})
+	if err != nil {
+		return false, err
+	}
+	return keep.(bool), nil
+}
+
+// CallScalar calls the scalar function name.
+func CallScalar(ctx context.Context, name string, args []types.Val) (types.Val, error) {
+	funcs.RLock()
+	s := funcs.scalars[name]
+	funcs.RUnlock()
+	if s.fn == nil {
+		return types.Val{}, x.Errorf("Unknown scalar function: %s", name)
+	}
+	if len(args) != s.arity {
+		return types.Val{}, x.Errorf("Function %s expects %d arguments. But got: %d", name,
+			s.arity, len(args))
+	}
+	val, err := call(ctx, name, func() (interface{}, error) {
+		val, err := s.fn(args)
+		if err == nil && val.Value == nil {
+			err = x.Errorf("Function %s returned no value", name)
+		}
+		return val, err
+	})
+	if err != nil {
+		return types.Val{}, err
+	}
+	return val.(types.Val), nil
+}
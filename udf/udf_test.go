@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package udf
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/types"
+)
+
+func init() {
+	RegisterFilter("hasprefix", func(vals []types.Val, args []string) (bool, error) {
+		for _, v := range vals {
+			if s := v.Value.(string); len(s) >= len(args[0]) && s[:len(args[0])] == args[0] {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	RegisterScalar("inc", 1, func(args []types.Val) (types.Val, error) {
+		return types.Val{Tid: types.IntID, Value: args[0].Value.(int64) + 1}, nil
+	})
+	RegisterScalar("none", 1, func(args []types.Val) (types.Val, error) {
+		return types.Val{}, nil
+	})
+	RegisterScalar("crash", 1, func(args []types.Val) (types.Val, error) {
+		panic("crash")
+	})
+	RegisterScalar("slow", 1, func(args []types.Val) (types.Val, error) {
+		time.Sleep(args[0].Value.(time.Duration))
+		return args[0], nil
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	require.True(t, IsFilter("hasprefix"))
+	require.False(t, IsFilter("inc"))
+	require.Equal(t, 1, Arity("inc"))
+	require.Equal(t, 0, Arity("hasprefix"))
+	require.Equal(t, 0, Arity("eq"))
+}
+
+func TestCallFilter(t *testing.T) {
+	vals := []types.Val{{Tid: types.StringID, Value: "dgraph"}}
+	keep, err := CallFilter(context.Background(), "hasprefix", vals, []string{"dg"})
+	require.NoError(t, err)
+	require.True(t, keep)
+
+	keep, err = CallFilter(context.Background(), "hasprefix", vals, []string{"graph"})
+	require.NoError(t, err)
+	require.False(t, keep)
+
+	_, err = CallFilter(context.Background(), "inc", vals, nil)
+	require.Error(t, err)
+}
+
+func TestCallScalar(t *testing.T) {
+	v, err := CallScalar(context.Background(), "inc", []types.Val{{Tid: types.IntID, Value: int64(1)}})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), v.Value)
+
+	_, err = CallScalar(context.Background(), "inc", nil)
+	require.Error(t, err)
+	_, err = CallScalar(context.Background(), "none", []types.Val{{}})
+	require.Error(t, err)
+	_, err = CallScalar(context.Background(), "crash", []types.Val{{}})
+	require.Error(t, err)
+}
+
+func TestLimits(t *testing.T) {
+	defer SetLimits(DefaultLimits)
+	SetLimits(Limits{Timeout: 10 * time.Millisecond, Concurrency: 1})
+
+	_, err := CallScalar(context.Background(), "slow", []types.Val{{Value: time.Second}})
+	require.Error(t, err)
+
+	// The call which timed out holds the only place until it returns.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = CallScalar(ctx, "slow", []types.Val{{Value: time.Millisecond}})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	SetLimits(Limits{Timeout: time.Second, Concurrency: 2})
+	var calls int32
+	done := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := CallScalar(context.Background(), "slow",
+				[]types.Val{{Value: 50 * time.Millisecond}})
+			atomic.AddInt32(&calls, 1)
+			done <- err
+		}()
+	}
+	time.Sleep(75 * time.Millisecond)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, <-done)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/objstore"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Backups of a group form a chain, starting with a full backup followed by incremental ones. Each
+// backup writes the schema in full, like an export does. An incremental backup only writes the
+// data posting lists written since the previous backup of the chain, along with a file of deletes
+// to apply before its data: one S P * N-Quad for every posting list which was changed or removed.
+// Lists are told apart by the CAS counter of their key in the store, and removed lists are found
+// by comparing all the data keys of the group with those of the previous backup, which are kept in
+// a keys file. The chain is described by the manifest in the directory of the group.
+//
+// Backups record the version of the encryption key they're encrypted with. Once the key is
+// rotated, the next backup starts a new chain, so that the data is encrypted again with the new
+// version without rewriting the backups taken before.
+
+const manifestFile = "manifest.json"
+
+type backupEntry struct {
+	Full bool      `json:"full"`
+	Time time.Time `json:"time"`
+	// Posting lists written with a CAS counter after Since are in this backup, and those written
+	// after Counter are in the next one.
+	Since   uint64 `json:"since"`
+	Counter uint64 `json:"counter"`
+	// All mutations up to this raft index are in the backup. The changelog is replayed from the
+	// entry after it.
+	Index   uint64 `json:"index"`
+	Data    string `json:"data"`
+	Schema  string `json:"schema"`
+	Deletes string `json:"deletes,omitempty"`
+	Keys    string `json:"keys"`
+	// SHA-256 sums of the files of the backup, by name.
+	Checksums map[string]string `json:"checksums,omitempty"`
+	// Version of the key file the backup is encrypted with, if it is.
+	KeyVersion uint32 `json:"key_version,omitempty"`
+}
+
+type backupManifest struct {
+	Group uint32 `json:"group"`
+	// CAS counters are local to the store of a server, so a chain can only be continued by the
+	// server which started it.
+	Node    uint64         `json:"node"`
+	Backups []*backupEntry `json:"backups"`
+}
+
+func readManifest(fpath string) (*backupManifest, error) {
+	var m backupManifest
+	f, err := openFile(fpath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, x.Wrapf(err, "While reading backup manifest: %v", fpath)
+	}
+	return &m, nil
+}
+
+func writeManifest(fpath string, m *backupManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Manifests are replaced at once, so that a failed write doesn't lose the chain.
+	return objstore.Put(fpath, data)
+}
+
+// lastCounter returns a CAS counter which is no more than those of all the writes to the store
+// from now on. The store persists its last used counter with its head key, which is updated when
+// a memtable is flushed, so this is a lower bound. The difference only makes backups include some
+// posting lists which didn't change.
+func lastCounter() uint64 {
+	var item badger.KVItem
+	if err := pstore.Get([]byte("!badger!head"), &item); err != nil {
+		return 0
+	}
+	return item.Counter()
+}
+
+// delta is given all the data keys of a group in order by exportTo, to write the keys file of a
+// backup, and the deletes of an incremental backup.
+type delta struct {
+	since uint64
+	sums  *checksums
+
+	keys    chan []byte
+	keysBuf bytes.Buffer
+	dels    chan []byte
+	delsBuf bytes.Buffer
+
+	// Keys of the previous backup, and the next one of them.
+	prev     *bufio.Scanner
+	prevNext []byte
+}
+
+// checksums collects the SHA-256 sums of the files written for a backup, as they're written. Its
+// methods do nothing on a nil checksums.
+type checksums struct {
+	sync.Mutex
+	m map[string]string
+}
+
+func newChecksums() *checksums {
+	return &checksums{m: make(map[string]string)}
+}
+
+func (c *checksums) add(fpath string, h hash.Hash) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.m[path.Base(fpath)] = hex.EncodeToString(h.Sum(nil))
+}
+
+func flushTo(ch chan []byte, buf *bytes.Buffer, limit int) {
+	if buf.Len() == 0 || buf.Len() < limit {
+		return
+	}
+	tmp := make([]byte, buf.Len())
+	copy(tmp, buf.Bytes())
+	ch <- tmp
+	buf.Reset()
+}
+
+func (d *delta) nextPrev() {
+	d.prevNext = nil
+	if d.prev == nil || !d.prev.Scan() {
+		return
+	}
+	key, err := hex.DecodeString(d.prev.Text())
+	x.Checkf(err, "Invalid key in backup keys file")
+	d.prevNext = key
+}
+
+func (d *delta) delete(key []byte) {
+	pk := x.Parse(key)
+	d.delsBuf.WriteString("<_:uid")
+	d.delsBuf.WriteString(strconv.FormatUint(pk.Uid, 16))
+	d.delsBuf.WriteString("> <")
+	d.delsBuf.WriteString(pk.Attr)
+	d.delsBuf.WriteString("> * .\n")
+	flushTo(d.dels, &d.delsBuf, 40000)
+}
+
+func (d *delta) visit(key []byte, counter uint64) {
+	d.keysBuf.WriteString(hex.EncodeToString(key))
+	d.keysBuf.WriteByte('\n')
+	flushTo(d.keys, &d.keysBuf, 40000)
+
+	if d.prev == nil {
+		return
+	}
+	// Keys of the previous backup which come before key don't exist anymore.
+	for d.prevNext != nil && bytes.Compare(d.prevNext, key) < 0 {
+		d.delete(d.prevNext)
+		d.nextPrev()
+	}
+	existed := d.prevNext != nil && bytes.Equal(d.prevNext, key)
+	if existed {
+		d.nextPrev()
+	}
+	if existed && counter > d.since {
+		// The list gets written in full, so drop what was restored before.
+		d.delete(key)
+	}
+}
+
+func (d *delta) finish() {
+	for d.prevNext != nil {
+		d.delete(d.prevNext)
+		d.nextPrev()
+	}
+	flushTo(d.keys, &d.keysBuf, 0)
+	flushTo(d.dels, &d.delsBuf, 0)
+	close(d.keys)
+	close(d.dels)
+}
+
+func openKeys(fpath string) (*bufio.Scanner, io.Closer, error) {
+	f, err := openFile(fpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := artifact.NewReader(f, fpath)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return bufio.NewScanner(r), f, nil
+}
+
+// backup writes a backup of the group of node n into a directory for the group under bdir. It's
+// an incremental one, unless full is set or there's no chain of backups to continue. All the
+// mutations up to index must have been applied.
+func backup(n *node, bdir string, full bool, index uint64) error {
+	filter, err := newBackupFilter()
+	if err != nil {
+		return err
+	}
+	gdir := objstore.Join(bdir, fmt.Sprintf("group-%d", n.gid))
+	if err := mkdirAll(gdir); err != nil {
+		return err
+	}
+	mpath := objstore.Join(gdir, manifestFile)
+	m, err := readManifest(mpath)
+	if err != nil {
+		return err
+	}
+	if m != nil && m.Node != n.id {
+		x.Printf("Backups of group %d were taken by node %d. Starting a new chain.\n",
+			n.gid, m.Node)
+		full = true
+	}
+	keyVersion, _, err := artifact.KeyVersions()
+	if err != nil {
+		return err
+	}
+	if m != nil && !full && len(m.Backups) > 0 && m.Backups[0].KeyVersion < keyVersion {
+		x.Printf("Backups of group %d are encrypted with version %d of the key. Starting a new "+
+			"chain with version %d.\n", n.gid, m.Backups[0].KeyVersion, keyVersion)
+		full = true
+	}
+	if m == nil || len(m.Backups) == 0 || full {
+		m = &backupManifest{Group: n.gid, Node: n.id}
+		full = true
+	}
+
+	now := time.Now()
+	ts := now.UTC().Format("2006-01-02T15-04-05")
+	ext := artifact.Ext()
+	kind := "incr"
+	if full {
+		kind = "full"
+	}
+	e := &backupEntry{
+		Full:    full,
+		Time:    now,
+		Counter: lastCounter(),
+		Index:   index,
+		Data:    fmt.Sprintf("%s-%s.rdf%s", kind, ts, ext),
+		Schema:  fmt.Sprintf("%s-%s-schema.rdf%s", kind, ts, ext),
+		Keys:    fmt.Sprintf("%s-%s-keys%s", kind, ts, ext),
+
+		KeyVersion: keyVersion,
+	}
+	d := &delta{
+		sums: newChecksums(),
+		keys: make(chan []byte, 1000),
+		dels: make(chan []byte, 1000),
+	}
+	if !full {
+		prev := m.Backups[len(m.Backups)-1]
+		e.Since = prev.Counter
+		e.Deletes = fmt.Sprintf("%s-%s-deletes.rdf%s", kind, ts, ext)
+		d.since = e.Since
+
+		var c io.Closer
+		if d.prev, c, err = openKeys(objstore.Join(gdir, prev.Keys)); err != nil {
+			return x.Wrapf(err, "While opening keys of the previous backup")
+		}
+		defer c.Close()
+		d.nextPrev()
+	}
+	x.Printf("Backing up group %d to: %v, since counter: %d\n", n.gid, objstore.Join(gdir, e.Data),
+		e.Since)
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- writeToFile(objstore.Join(gdir, e.Keys), d.keys, d.sums)
+	}()
+	go func() {
+		if e.Deletes == "" {
+			for range d.dels {
+			}
+			errCh <- nil
+			return
+		}
+		errCh <- writeToFile(objstore.Join(gdir, e.Deletes), d.dels, d.sums)
+	}()
+
+	err = exportTo(n.gid, objstore.Join(gdir, e.Data), objstore.Join(gdir, e.Schema), "",
+		rdfFormat, filter, d)
+	d.finish()
+	for i := 0; i < 2; i++ {
+		if werr := <-errCh; err == nil {
+			err = werr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if d.prev != nil {
+		if err := d.prev.Err(); err != nil {
+			return x.Wrapf(err, "While reading keys of the previous backup")
+		}
+	}
+
+	e.Checksums = d.sums.m
+	m.Backups = append(m.Backups, e)
+	if err := writeManifest(mpath, m); err != nil {
+		return err
+	}
+	// Segments of the changelog before this one are only needed to restore to a point in time
+	// before the backup.
+	changelogFor(n.gid).rotate()
+	return nil
+}
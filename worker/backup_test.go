@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func readGzLines(t *testing.T, fpath string) []string {
+	f, err := os.Open(fpath)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestBackupDelta(t *testing.T) {
+	var prev bytes.Buffer
+	for _, uid := range []uint64{1, 2, 4} {
+		prev.WriteString(hex.EncodeToString(x.DataKey("friend", uid)))
+		prev.WriteByte('\n')
+	}
+	d := &delta{
+		since: 10,
+		keys:  make(chan []byte, 10),
+		dels:  make(chan []byte, 10),
+		prev:  bufio.NewScanner(&prev),
+	}
+	d.nextPrev()
+
+	d.visit(x.DataKey("friend", 1), 5)  // Unchanged.
+	d.visit(x.DataKey("friend", 3), 20) // Added.
+	d.visit(x.DataKey("friend", 4), 20) // Changed, after 2 got removed.
+	d.visit(x.DataKey("friend", 5), 5)
+	d.finish()
+
+	var dels []byte
+	for b := range d.dels {
+		dels = append(dels, b...)
+	}
+	require.Equal(t, "<_:uid2> <friend> * .\n<_:uid4> <friend> * .\n", string(dels))
+	var keys []byte
+	for b := range d.keys {
+		keys = append(keys, b...)
+	}
+	require.Equal(t, 4, bytes.Count(keys, []byte("\n")))
+}
+
+func TestBackup(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	n := &node{gid: group.BelongsTo("friend"), id: 1}
+	require.NoError(t, backup(n, bdir, false, 0))
+
+	// Remove one list, and add another.
+	require.NoError(t, ps.Delete(x.DataKey("friend", 3)))
+	addEdge(t, &protos.DirectedEdge{Entity: 6, Attr: "friend", ValueId: 5,
+		ValueType: uint32(protos.Posting_UID)}, getOrCreate(x.DataKey("friend", 6)))
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, backup(n, bdir, false, 0))
+
+	gdir := path.Join(bdir, fmt.Sprintf("group-%d", n.gid))
+	m, err := readManifest(path.Join(gdir, manifestFile))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), m.Node)
+	require.Len(t, m.Backups, 2)
+	full, incr := m.Backups[0], m.Backups[1]
+	require.True(t, full.Full)
+	require.False(t, incr.Full)
+	require.Equal(t, full.Counter, incr.Since)
+
+	require.Len(t, readGzLines(t, path.Join(gdir, full.Data)), 4)
+	require.Len(t, readGzLines(t, path.Join(gdir, full.Keys)), 4)
+	require.Len(t, readGzLines(t, path.Join(gdir, incr.Keys)), 4)
+	require.Contains(t, readGzLines(t, path.Join(gdir, incr.Deletes)), "<_:uid3> <friend> * .")
+	require.Contains(t, readGzLines(t, path.Join(gdir, incr.Data)), "<_:uid6> <friend> <_:uid5> .")
+
+	// A full backup starts a new chain.
+	require.NoError(t, backup(n, bdir, true, 0))
+	m, err = readManifest(path.Join(gdir, manifestFile))
+	require.NoError(t, err)
+	require.Len(t, m.Backups, 1)
+}
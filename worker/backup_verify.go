@@ -0,0 +1,282 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/objstore"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Backups are verified without restoring them. The manifest of a group must describe a chain
+// starting with a full backup, with the CAS counters of consecutive backups adjoining. Every file
+// of a backup has to match its checksum in the manifest, decompress in full and parse, and the
+// edges of the data have to agree with the schema of the backup.
+
+const maxVerifyErrors = 20
+
+// BackupReport is the result of verifying the chain of backups of a group.
+type BackupReport struct {
+	Dir     string         `json:"dir"`
+	Group   uint32         `json:"group"`
+	Errors  []string       `json:"errors,omitempty"` // Of the manifest itself.
+	Backups []*BackupCheck `json:"backups"`
+	// The number of backups from the start of the chain which can be restored, in order.
+	Restorable int `json:"restorable"`
+}
+
+// BackupCheck is the result of verifying a backup in a chain.
+type BackupCheck struct {
+	Time time.Time `json:"time"`
+	Full bool      `json:"full"`
+	// Lines parsed in each file.
+	Lines map[string]int `json:"lines"`
+	// Backups taken before checksums were kept can't be checked against them.
+	NoChecksums bool     `json:"no_checksums,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+	numErrors   int
+}
+
+func (c *BackupCheck) addError(format string, args ...interface{}) {
+	c.numErrors++
+	if c.numErrors <= maxVerifyErrors {
+		c.Errors = append(c.Errors, fmt.Sprintf(format, args...))
+	} else if c.numErrors == maxVerifyErrors+1 {
+		c.Errors = append(c.Errors, "Further errors left out")
+	}
+}
+
+// BackupGroupDirs returns the directories of the groups in the backup directory dir, or dir itself
+// if it's the directory of a group. Buckets can't be listed, so URIs are taken to be of groups.
+func BackupGroupDirs(dir string) ([]string, error) {
+	if objstore.IsURI(dir) {
+		return []string{dir}, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+		return []string{dir}, nil
+	}
+	dirs, err := filepath.Glob(filepath.Join(dir, "group-*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, x.Errorf("No backups found in: %v", dir)
+	}
+	return dirs, nil
+}
+
+func checkManifest(m *backupManifest) []string {
+	var errs []string
+	if len(m.Backups) == 0 {
+		return append(errs, "The manifest has no backups")
+	}
+	if !m.Backups[0].Full {
+		errs = append(errs, "The chain doesn't start with a full backup")
+	}
+	for i, e := range m.Backups {
+		if e.Data == "" || e.Schema == "" || e.Keys == "" {
+			errs = append(errs, fmt.Sprintf("Backup %d is missing the names of its files", i))
+		}
+		if i == 0 {
+			continue
+		}
+		prev := m.Backups[i-1]
+		if e.Full {
+			errs = append(errs, fmt.Sprintf("Backup %d is a full backup in the middle of the chain", i))
+			continue
+		}
+		if e.Deletes == "" {
+			errs = append(errs, fmt.Sprintf("Incremental backup %d has no deletes", i))
+		}
+		if e.Since != prev.Counter {
+			errs = append(errs, fmt.Sprintf("Backup %d starts at counter %d, but backup %d ends at %d",
+				i, e.Since, i-1, prev.Counter))
+		}
+		if e.Index < prev.Index {
+			errs = append(errs, fmt.Sprintf("Backup %d is at raft index %d, before backup %d at %d",
+				i, e.Index, i-1, prev.Index))
+		}
+	}
+	return errs
+}
+
+// verifyFile reads the compressed file name of backup e, checking its checksum and passing its first
+// sample lines, or all of them if sample is zero, to fn. The rest of the file is decompressed too.
+func verifyFile(gdir string, e *backupEntry, name string, sample int, c *BackupCheck,
+	fn func(line []byte) error) {
+	f, err := openFile(objstore.Join(gdir, name))
+	if err != nil {
+		c.addError("%s: %v", name, err)
+		return
+	}
+	defer f.Close()
+	h := sha256.New()
+	gr, err := artifact.NewReader(io.TeeReader(f, h), name)
+	if err != nil {
+		c.addError("%s: %v", name, err)
+		return
+	}
+	br := bufio.NewReader(gr)
+	var lines int
+	for sample == 0 || lines < sample {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			lines++
+			if perr := fn(bytes.TrimSpace(line)); perr != nil {
+				c.addError("%s, line %d: %v", name, lines, perr)
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			c.addError("%s: %v", name, err)
+			return
+		}
+	}
+	c.Lines[name] = lines
+	if _, err := io.Copy(ioutil.Discard, br); err != nil {
+		c.addError("%s: %v", name, err)
+		return
+	}
+	if err := gr.Close(); err != nil {
+		c.addError("%s: %v", name, err)
+		return
+	}
+	// Anything after the compressed stream is covered by the checksum too.
+	if _, err := io.Copy(ioutil.Discard, f); err != nil {
+		c.addError("%s: %v", name, err)
+		return
+	}
+	if sum, ok := e.Checksums[name]; ok && sum != hex.EncodeToString(h.Sum(nil)) {
+		c.addError("%s: Checksum mismatch", name)
+	}
+}
+
+func verifyBackupEntry(gdir string, e *backupEntry, sample int) *BackupCheck {
+	c := &BackupCheck{Time: e.Time, Full: e.Full, Lines: make(map[string]int)}
+	c.NoChecksums = len(e.Checksums) == 0
+	if e.Data == "" || e.Schema == "" || e.Keys == "" {
+		c.addError("Missing the names of files")
+		return c
+	}
+
+	// The schema is written in full by every backup, and is always read in full to check the data
+	// against it.
+	sch := make(map[string]*protos.SchemaUpdate)
+	verifyFile(gdir, e, e.Schema, 0, c, func(line []byte) error {
+		if len(line) == 0 {
+			return nil
+		}
+		updates, err := schema.Parse(string(line))
+		if err != nil {
+			return err
+		}
+		for _, u := range updates {
+			sch[u.Predicate] = u
+		}
+		return nil
+	})
+
+	verifyFile(gdir, e, e.Data, sample, c, func(line []byte) error {
+		nq, err := rdf.Parse(string(line))
+		if err == rdf.ErrEmpty {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		s, ok := sch[nq.Predicate]
+		if !ok {
+			return x.Errorf("Predicate %q isn't in the schema", nq.Predicate)
+		}
+		isUid := types.TypeID(s.ValueType) == types.UidID
+		if len(nq.ObjectId) > 0 && !isUid {
+			return x.Errorf("Uid edge of predicate %q, of type %s in the schema", nq.Predicate,
+				types.TypeID(s.ValueType).Name())
+		}
+		if len(nq.ObjectId) == 0 && isUid {
+			return x.Errorf("Value of predicate %q, of type uid in the schema", nq.Predicate)
+		}
+		return nil
+	})
+
+	if e.Deletes != "" {
+		verifyFile(gdir, e, e.Deletes, sample, c, func(line []byte) error {
+			if _, err := rdf.Parse(string(line)); err != nil && err != rdf.ErrEmpty {
+				return err
+			}
+			return nil
+		})
+	}
+
+	var prev []byte
+	verifyFile(gdir, e, e.Keys, sample, c, func(line []byte) error {
+		key, err := hex.DecodeString(string(line))
+		if err != nil {
+			return err
+		}
+		if pk := x.Parse(key); pk == nil || !pk.IsData() {
+			return x.Errorf("Not a data key: %s", line)
+		}
+		if prev != nil && bytes.Compare(prev, key) >= 0 {
+			return x.Errorf("Keys out of order")
+		}
+		prev = key
+		return nil
+	})
+	return c
+}
+
+// VerifyBackup verifies the chain of backups in the directory of a group, parsing the first
+// sample lines of every file, or all of them if sample is zero.
+func VerifyBackup(gdir string, sample int) (*BackupReport, error) {
+	m, err := readManifest(objstore.Join(gdir, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, x.Errorf("No backup manifest in: %v", gdir)
+	}
+	r := &BackupReport{Dir: gdir, Group: m.Group, Errors: checkManifest(m)}
+	for _, e := range m.Backups {
+		r.Backups = append(r.Backups, verifyBackupEntry(gdir, e, sample))
+	}
+	if len(r.Errors) == 0 {
+		for _, c := range r.Backups {
+			if len(c.Errors) > 0 {
+				break
+			}
+			r.Restorable++
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+)
+
+func writeGz(t *testing.T, fpath, data string) {
+	f, err := os.Create(fpath)
+	require.NoError(t, err)
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func TestVerifyBackup(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	n := &node{gid: group.BelongsTo("friend"), id: 1}
+	require.NoError(t, backup(n, bdir, false, 0))
+	require.NoError(t, backup(n, bdir, false, 0))
+
+	dirs, err := BackupGroupDirs(bdir)
+	require.NoError(t, err)
+	gdir := path.Join(bdir, fmt.Sprintf("group-%d", n.gid))
+	require.Equal(t, []string{gdir}, dirs)
+
+	r, err := VerifyBackup(gdir, 0)
+	require.NoError(t, err)
+	require.Empty(t, r.Errors)
+	require.Len(t, r.Backups, 2)
+	require.Equal(t, 2, r.Restorable)
+	full := r.Backups[0]
+	require.Empty(t, full.Errors)
+	require.False(t, full.NoChecksums)
+
+	m, err := readManifest(path.Join(gdir, manifestFile))
+	require.NoError(t, err)
+	require.Equal(t, 4, full.Lines[m.Backups[0].Data])
+	require.Len(t, m.Backups[0].Checksums, 3)
+	require.Len(t, m.Backups[1].Checksums, 4)
+
+	// Sampling parses fewer lines, but still checks the whole files.
+	r, err = VerifyBackup(gdir, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, r.Backups[0].Lines[m.Backups[0].Data])
+	require.Equal(t, 2, r.Restorable)
+
+	// Data of a predicate missing from the schema, which doesn't match the checksum either.
+	writeGz(t, path.Join(gdir, m.Backups[1].Data), "<_:uid1> <unknown> <_:uid2> .\n")
+	r, err = VerifyBackup(gdir, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, r.Restorable)
+	require.Len(t, r.Backups[1].Errors, 2)
+
+	// A chain which doesn't adjoin can't be restored at all.
+	m.Backups[1].Since++
+	require.NoError(t, writeManifest(path.Join(gdir, manifestFile), m))
+	r, err = VerifyBackup(gdir, 0)
+	require.NoError(t, err)
+	require.Len(t, r.Errors, 1)
+	require.Equal(t, 0, r.Restorable)
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"encoding/json"
+	"expvar"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/kafka"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.CDCKafka set, the leader of each group served here publishes the mutations committed
+// to it to Kafka, as they're written to its changelog. Each edge set or deleted is a JSON message,
+// keyed by its subject, on the topic of the first pattern of Config.CDCTopics its predicate
+// matches, or Config.CDCTopic. The index of the last entry published is recorded in a file in the
+// backup folder of the group once its messages are acknowledged, and publishing resumes after it.
+// Messages are delivered at least once: those of an entry are published again if the server
+// stopped before recording it, or by a new leader which hadn't, so consumers skip the ones whose
+// group and index they've seen. With Config.CDCFormat set to "debezium", messages are in the
+// envelope of Debezium instead.
+
+const cdcOffsetFile = "cdc-offset"
+
+// CDCEdge is the message published for an edge.
+type CDCEdge struct {
+	Group     uint32                 `json:"group"`
+	Index     uint64                 `json:"index"`
+	Commit    time.Time              `json:"commit_ts"`
+	Op        string                 `json:"op"`
+	Subject   string                 `json:"subject"`
+	Predicate string                 `json:"predicate"`
+	Object    string                 `json:"object,omitempty"`
+	Value     interface{}            `json:"value,omitempty"`
+	Type      string                 `json:"type,omitempty"`
+	Lang      string                 `json:"lang,omitempty"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+type cdcRoute struct {
+	pattern string
+	topic   string
+}
+
+// parseCDCTopics parses a comma separated list of pattern=topic pairs.
+func parseCDCTopics(s string) ([]cdcRoute, error) {
+	var routes []cdcRoute
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, x.Errorf("Invalid route of predicates to a topic: %q", part)
+		}
+		if err := ValidatePatterns(kv[:1]); err != nil {
+			return nil, err
+		}
+		routes = append(routes, cdcRoute{pattern: kv[0], topic: kv[1]})
+	}
+	return routes, nil
+}
+
+// ValidateCDCTopics checks the routes of predicates to topics of Config.CDCTopics.
+func ValidateCDCTopics(s string) error {
+	_, err := parseCDCTopics(s)
+	return err
+}
+
+// cdcTopic returns the topic of the edges of attr, or "" if they aren't published.
+func cdcTopic(routes []cdcRoute, attr string) string {
+	for _, r := range routes {
+		if matchAny([]string{r.pattern}, attr) {
+			return r.topic
+		}
+	}
+	return Config.CDCTopic
+}
+
+// cdcValue returns v, the value of an N-Quad of type typ, as a value for JSON, with the name of
+// its type.
+func cdcValue(v *protos.Value, typ int32) (interface{}, string, error) {
+	var val types.Val
+	switch v.Val.(type) {
+	case *protos.Value_DefaultVal:
+		if v.GetDefaultVal() == x.Star {
+			// The values deleted by a delete of all the values of a predicate.
+			return "*", "", nil
+		}
+		return v.GetDefaultVal(), types.TypeID(typ).Name(), nil
+	case *protos.Value_StrVal:
+		return v.GetStrVal(), types.StringID.Name(), nil
+	case *protos.Value_IntVal:
+		return v.GetIntVal(), types.IntID.Name(), nil
+	case *protos.Value_BoolVal:
+		return v.GetBoolVal(), types.BoolID.Name(), nil
+	case *protos.Value_DoubleVal:
+		return v.GetDoubleVal(), types.FloatID.Name(), nil
+	case *protos.Value_DatetimeVal:
+		val = types.Val{Tid: types.DateTimeID, Value: v.GetDatetimeVal()}
+	case *protos.Value_GeoVal:
+		val = types.Val{Tid: types.GeoID, Value: v.GetGeoVal()}
+	case *protos.Value_PasswordVal:
+		val = types.Val{Tid: types.PasswordID, Value: []byte(v.GetPasswordVal())}
+	case *protos.Value_BytesVal:
+		return v.GetBytesVal(), types.BinaryID.Name(), nil
+	default:
+		return nil, "", x.Errorf("Unknown type of value: %T", v.Val)
+	}
+	s, err := types.Convert(val, types.StringID)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.Value, val.Tid.Name(), nil
+}
+
+// cdcEdge returns the message of the changelog line of an edge of change, or nil if line is a
+// schema update.
+func cdcEdge(c *Change, line string) (*CDCEdge, error) {
+	if strings.HasPrefix(line, "schema ") {
+		return nil, nil
+	}
+	nq, del, err := parseChangelogEdge(line)
+	if err != nil {
+		return nil, err
+	}
+	e := &CDCEdge{
+		Group:     c.Group,
+		Index:     c.Index,
+		Commit:    c.Time,
+		Op:        "set",
+		Subject:   nq.Subject,
+		Predicate: nq.Predicate,
+		Object:    nq.ObjectId,
+		Lang:      nq.Lang,
+	}
+	if del {
+		e.Op = "del"
+	}
+	if len(nq.ObjectId) == 0 {
+		if e.Value, e.Type, err = cdcValue(nq.ObjectValue, nq.ObjectType); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range nq.Facets {
+		if e.Facets == nil {
+			e.Facets = make(map[string]interface{})
+		}
+		e.Facets[f.Key] = facets.ValFor(f).Value
+	}
+	return e, nil
+}
+
+// cdcMessages returns the messages of change, by topic.
+func cdcMessages(c *Change, routes []cdcRoute) (map[string][]kafka.Message, error) {
+	msgs := make(map[string][]kafka.Message)
+	for _, line := range c.Lines {
+		e, err := cdcEdge(c, line)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			continue
+		}
+		topic := cdcTopic(routes, e.Predicate)
+		if topic == "" {
+			continue
+		}
+		key, b := []byte(e.Subject), []byte(nil)
+		if Config.CDCFormat == cdcFormatDebezium {
+			key, b, err = debeziumMessage(e, topic)
+		} else {
+			b, err = json.Marshal(e)
+		}
+		if err != nil {
+			return nil, err
+		}
+		msgs[topic] = append(msgs[topic], kafka.Message{Key: key, Value: b, Time: c.Time})
+	}
+	return msgs, nil
+}
+
+func offsetPath(gid uint32, file string) string {
+	return path.Join(Config.BackupPath, "group-"+strconv.FormatUint(uint64(gid), 10), file)
+}
+
+// readOffset returns the index of the last entry of group gid recorded in file.
+func readOffset(gid uint32, file string) (uint64, error) {
+	b, err := ioutil.ReadFile(offsetPath(gid, file))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// writeOffset records index as the last entry of group gid in file.
+func writeOffset(gid uint32, file string, index uint64) error {
+	fpath := offsetPath(gid, file)
+	if err := os.MkdirAll(path.Dir(fpath), 0700); err != nil {
+		return err
+	}
+	tmp := fpath + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(index, 10)+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fpath)
+}
+
+// publishChange publishes the messages of change.
+func publishChange(p *kafka.Producer, routes []cdcRoute, c *Change) error {
+	msgs, err := cdcMessages(c, routes)
+	if err != nil {
+		return x.Wrapf(err, "While reading entry %d of changelog of group %d", c.Index, c.Group)
+	}
+	for topic, m := range msgs {
+		if err := p.Produce(topic, m); err != nil {
+			return err
+		}
+		x.CDCMessages.Add(topic, int64(len(m)))
+	}
+	return nil
+}
+
+// syncChanges calls publish with the changes of the groups led here, as they're applied, from
+// after the entry recorded in the offset file of each group, which is updated as they're
+// published. Errors are logged, with the name of what changes are published to, and counted by
+// errs.
+func syncChanges(name, file string, publish func(*Change) error, errs *expvar.Int) {
+	ctx := context.Background()
+	changed, stop := WatchChanges(nil)
+	defer stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	readers := make(map[uint32]*ChangeReader)
+	for {
+		for _, n := range groups().nodes() {
+			gid := n.gid
+			if !n.AmLeader() {
+				// A new leader publishes from the offset it recorded, so it's read again.
+				delete(readers, gid)
+				continue
+			}
+			cr, ok := readers[gid]
+			if !ok {
+				after, err := readOffset(gid, file)
+				if err != nil {
+					workerLog.Errorf(ctx, "Error while reading %s offset of group %d: %v", name,
+						gid, err)
+					continue
+				}
+				if cr, err = NewChangeReader(gid, after, time.Time{}, nil); err != nil {
+					workerLog.Errorf(ctx, "Error while reading changelog of group %d: %v", gid, err)
+					continue
+				}
+				readers[gid] = cr
+			}
+			err := cr.Read(func(c *Change) error {
+				if err := publish(c); err != nil {
+					return err
+				}
+				return writeOffset(c.Group, file, c.Index)
+			})
+			if err != nil {
+				// The reader is past the entry which failed, so it starts again from the offset.
+				delete(readers, gid)
+				errs.Add(1)
+				workerLog.Warningf(ctx, "Error while publishing changes of group %d to %s: %v", gid,
+					name, err)
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishChanges publishes the changes of the groups led here to Kafka, as they're applied.
+func publishChanges() {
+	routes, err := parseCDCTopics(Config.CDCTopics)
+	x.Check(err)
+	producer := kafka.NewProducer(strings.Split(Config.CDCKafka, ","), "dgraph-"+Config.MyAddr,
+		10*time.Second)
+	defer producer.Close()
+
+	syncChanges("Kafka", cdcOffsetFile, func(c *Change) error {
+		return publishChange(producer, routes, c)
+	}, x.CDCErrors)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCDCMessages(t *testing.T) {
+	defer func(topic string) { Config.CDCTopic = topic }(Config.CDCTopic)
+	Config.CDCTopic = "dgraph"
+	routes, err := parseCDCTopics("name=people, address.*=places,internal=")
+	require.Error(t, err)
+	routes, err = parseCDCTopics("name=people, address.*=places")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	c := &Change{Group: 1, Index: 42, Time: now, Lines: []string{
+		"schema name: string @index(exact) .",
+		`+ <_:uid1> <name> "Alice"@en .`,
+		`+ <_:uid1> <friend> <_:uid2a> (close=true) .`,
+		`- <_:uid2a> <address.city> * .`,
+		`+ <_:uid2a> <age> "30"^^<xs:int> .`,
+	}}
+	msgs, err := cdcMessages(c, routes)
+	require.NoError(t, err)
+	require.Len(t, msgs["people"], 1)
+	require.Len(t, msgs["places"], 1)
+	require.Len(t, msgs["dgraph"], 2)
+
+	var e CDCEdge
+	require.NoError(t, json.Unmarshal(msgs["people"][0].Value, &e))
+	require.Equal(t, "0x1", string(msgs["people"][0].Key))
+	require.Equal(t, CDCEdge{Group: 1, Index: 42, Commit: now, Op: "set", Subject: "0x1",
+		Predicate: "name", Value: "Alice", Type: "string", Lang: "en"}, e)
+
+	e = CDCEdge{}
+	require.NoError(t, json.Unmarshal(msgs["dgraph"][0].Value, &e))
+	require.Equal(t, "0x2a", e.Object)
+	require.Equal(t, true, e.Facets["close"])
+	e = CDCEdge{}
+	require.NoError(t, json.Unmarshal(msgs["dgraph"][1].Value, &e))
+	require.Equal(t, float64(30), e.Value)
+	require.Equal(t, "int", e.Type)
+	e = CDCEdge{}
+	require.NoError(t, json.Unmarshal(msgs["places"][0].Value, &e))
+	require.Equal(t, "del", e.Op)
+	require.Equal(t, "*", e.Value)
+
+	// Without a default topic, only the routed predicates are published.
+	Config.CDCTopic = ""
+	msgs, err = cdcMessages(c, routes)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+}
+
+func TestCDCDebezium(t *testing.T) {
+	defer func(f, topic string) {
+		Config.CDCFormat, Config.CDCTopic = f, topic
+	}(Config.CDCFormat, Config.CDCTopic)
+	Config.CDCFormat, Config.CDCTopic = "debezium", "dgraph"
+	require.NoError(t, ValidateCDCFormat("debezium"))
+	require.Error(t, ValidateCDCFormat("avro"))
+
+	now := time.Unix(1500000000, 0)
+	c := &Change{Group: 1, Index: 42, Time: now, Lines: []string{
+		`+ <_:uid1> <age> "30"^^<xs:int> .`,
+		`- <_:uid1> <friend> <_:uid2a> (close=true) .`,
+	}}
+	msgs, err := cdcMessages(c, nil)
+	require.NoError(t, err)
+	require.Len(t, msgs["dgraph"], 2)
+
+	type message struct {
+		Schema struct {
+			Name   string
+			Fields []struct{ Field string }
+		}
+		Payload map[string]interface{}
+	}
+	var key, value message
+	m := msgs["dgraph"][0]
+	require.NoError(t, json.Unmarshal(m.Key, &key))
+	require.Equal(t, map[string]interface{}{"uid": "0x1"}, key.Payload)
+	require.NoError(t, json.Unmarshal(m.Value, &value))
+	require.Equal(t, "dgraph.dgraph.Envelope", value.Schema.Name)
+	require.Len(t, value.Schema.Fields, 5)
+	require.Equal(t, "c", value.Payload["op"])
+	require.Nil(t, value.Payload["before"])
+	require.Equal(t, map[string]interface{}{"uid": "0x1", "predicate": "age", "object": nil,
+		"value": "30", "type": "int", "lang": nil, "facets": nil}, value.Payload["after"])
+	source := value.Payload["source"].(map[string]interface{})
+	require.Equal(t, "dgraph", source["connector"])
+	require.Equal(t, "age", source["table"])
+	require.EqualValues(t, 1500000000000, source["ts_ms"])
+	require.EqualValues(t, 42, source["index"])
+
+	value = message{}
+	require.NoError(t, json.Unmarshal(msgs["dgraph"][1].Value, &value))
+	require.Equal(t, "d", value.Payload["op"])
+	require.Nil(t, value.Payload["after"])
+	require.Equal(t, map[string]interface{}{"uid": "0x1", "predicate": "friend", "object": "0x2a",
+		"value": nil, "type": nil, "lang": nil, "facets": `{"close":true}`}, value.Payload["before"])
+}
+
+func TestCDCOffset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cdc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(p string) { Config.BackupPath = p }(Config.BackupPath)
+	Config.BackupPath = dir
+
+	idx, err := readOffset(1, cdcOffsetFile)
+	require.NoError(t, err)
+	require.Zero(t, idx)
+	require.NoError(t, writeOffset(1, cdcOffsetFile, 42))
+	require.NoError(t, writeOffset(1, cdcOffsetFile, 43))
+	idx, err = readOffset(1, cdcOffsetFile)
+	require.NoError(t, err)
+	require.EqualValues(t, 43, idx)
+}
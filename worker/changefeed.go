@@ -0,0 +1,282 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/rdf"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The change feed reads the changes committed to a group from its changelog, so it's available on
+// the servers of the group with Config.Changelog set. Readers keep how far they've read each
+// segment, so that following the changelog as it grows only reads the new entries.
+
+// Change is an entry of the changelog of a group, with the lines for the predicates asked for.
+type Change struct {
+	Group uint32
+	Index uint64
+	Time  time.Time
+	Lines []string
+}
+
+// ChangeReader reads the entries of the changelog of a group, in the order they were applied.
+type ChangeReader struct {
+	gid      uint32
+	last     uint64
+	since    time.Time
+	patterns []string
+	offsets  map[string]int64
+}
+
+// ValidatePatterns checks that patterns are valid predicate patterns, which are shell patterns
+// like name or address.*.
+func ValidatePatterns(patterns []string) error {
+	for _, pat := range patterns {
+		if _, err := path.Match(pat, ""); err != nil {
+			return x.Errorf("Invalid predicate pattern: %q", pat)
+		}
+	}
+	return nil
+}
+
+// NewChangeReader returns a reader of the changelog of group gid, starting after the entry at
+// index after, with entries applied before since skipped. Only lines for predicates matching one
+// of patterns are read, or all of them if there are no patterns.
+func NewChangeReader(gid uint32, after uint64, since time.Time,
+	patterns []string) (*ChangeReader, error) {
+	if err := ValidatePatterns(patterns); err != nil {
+		return nil, err
+	}
+	return &ChangeReader{
+		gid:      gid,
+		last:     after,
+		since:    since,
+		patterns: patterns,
+		offsets:  make(map[string]int64),
+	}, nil
+}
+
+// Last returns the index of the last entry read, including the one being handed to the reader.
+func (r *ChangeReader) Last() uint64 {
+	return r.last
+}
+
+func (r *ChangeReader) keep(attr string) bool {
+	return len(r.patterns) == 0 || matchAny(r.patterns, attr)
+}
+
+// changelogLineAttr returns the predicate of a changelog line.
+func changelogLineAttr(line string) string {
+	if strings.HasPrefix(line, "schema ") {
+		s := line[len("schema "):]
+		if i := strings.IndexByte(s, ':'); i >= 0 {
+			return strings.TrimSpace(s[:i])
+		}
+		return ""
+	}
+	// + <subject> <predicate> ...
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 3 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(fields[2], "<"), ">")
+}
+
+// parseChangelogEdge parses a changelog line of an edge, set with + or deleted with -, with its
+// nodes written as uids like 0x2a.
+func parseChangelogEdge(line string) (nq protos.NQuad, del bool, err error) {
+	if len(line) < 2 || (line[0] != '+' && line[0] != '-') {
+		return nq, false, x.Errorf("Invalid changelog line: %q", line)
+	}
+	if nq, err = rdf.Parse(line[2:]); err != nil {
+		return nq, false, err
+	}
+	nq.Subject = changelogNode(nq.Subject)
+	nq.ObjectId = changelogNode(nq.ObjectId)
+	return nq, line[0] == '-', nil
+}
+
+// changelogNode returns the uid of a node of a changelog line, written as _:uid<hex>.
+func changelogNode(s string) string {
+	if strings.HasPrefix(s, "_:uid") {
+		return "0x" + s[len("_:uid"):]
+	}
+	return s
+}
+
+type segmentFile struct {
+	path  string
+	index uint64
+}
+
+func changelogSegments(gid uint32) ([]segmentFile, error) {
+	paths, err := filepath.Glob(path.Join(Config.BackupPath, fmt.Sprintf("group-%d", gid),
+		"changelog-*-*.rdf"))
+	if err != nil {
+		return nil, err
+	}
+	var segs []segmentFile
+	for _, p := range paths {
+		name := strings.TrimSuffix(path.Base(p), ".rdf")
+		idx, err := strconv.ParseUint(name[strings.LastIndex(name, "-")+1:], 10, 64)
+		if err != nil {
+			continue
+		}
+		segs = append(segs, segmentFile{path: p, index: idx})
+	}
+	sort.Slice(segs, func(i, j int) bool {
+		if segs[i].index != segs[j].index {
+			return segs[i].index < segs[j].index
+		}
+		return segs[i].path < segs[j].path
+	})
+	return segs, nil
+}
+
+// Read calls fn with the entries written since the last call, which have lines for the predicates
+// asked for. Entries written again, after a restart or by another replica, are skipped.
+func (r *ChangeReader) Read(fn func(c *Change) error) error {
+	segs, err := changelogSegments(r.gid)
+	if err != nil {
+		return err
+	}
+	c := changelogFor(r.gid)
+	for _, seg := range segs {
+		fi, err := os.Stat(seg.path)
+		if err != nil {
+			return err
+		}
+		if fi.Size() <= r.offsets[seg.path] {
+			continue
+		}
+		// The open segment is read with the changelog locked, so that its last entry is complete.
+		c.Lock()
+		open := c.fpath == seg.path
+		if !open {
+			c.Unlock()
+		}
+		err = r.readSegment(seg.path, fn)
+		if open {
+			c.Unlock()
+		}
+		if err != nil {
+			return x.Wrapf(err, "While reading changelog segment: %v", seg.path)
+		}
+	}
+	return nil
+}
+
+func (r *ChangeReader) readSegment(fpath string, fn func(c *Change) error) error {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset := r.offsets[fpath]
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	br := bufio.NewReader(f)
+
+	var change *Change
+	skip := true
+	// flush hands the entry read to fn, and records that the segment was read up to end.
+	flush := func(end int64) error {
+		if change != nil {
+			r.last = change.Index
+			if len(change.Lines) > 0 {
+				if err := fn(change); err != nil {
+					return err
+				}
+			}
+		}
+		change = nil
+		r.offsets[fpath] = end
+		return nil
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			// A line without its newline is written partly, and is read again next time.
+			if len(line) == 0 {
+				return flush(offset)
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+		start := offset
+		offset += int64(len(line))
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "# ") {
+			if err := flush(start); err != nil {
+				return err
+			}
+			var idx uint64
+			var ts string
+			if _, err := fmt.Sscanf(line, "# %d %s", &idx, &ts); err != nil {
+				return x.Wrapf(err, "Invalid changelog header: %q", line)
+			}
+			t, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return x.Wrapf(err, "Invalid changelog header: %q", line)
+			}
+			skip = idx <= r.last
+			if !skip {
+				change = &Change{Group: r.gid, Index: idx, Time: t}
+			}
+			continue
+		}
+		if skip || change == nil || change.Time.Before(r.since) {
+			continue
+		}
+		if r.keep(changelogLineAttr(line)) {
+			change.Lines = append(change.Lines, line)
+		}
+	}
+}
+
+// ChangeGroups returns the groups served here, whose changes can be read from this server.
+func ChangeGroups() []uint32 {
+	var gids []uint32
+	for _, n := range groups().nodes() {
+		gids = append(gids, n.gid)
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	return gids
+}
+
+// WatchChanges signals on the returned channel when mutations to predicates matching patterns are
+// applied, like WatchMutations.
+func WatchChanges(patterns []string) (<-chan struct{}, func()) {
+	return WatchMutations(func(edge *protos.DirectedEdge) bool {
+		return !skipInChangelog(edge.Attr) && (len(patterns) == 0 || matchAny(patterns, edge.Attr))
+	})
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func readChanges(t *testing.T, r *ChangeReader) map[uint64][]string {
+	changes := make(map[uint64][]string)
+	require.NoError(t, r.Read(func(c *Change) error {
+		require.Equal(t, uint32(4), c.Group)
+		changes[c.Index] = c.Lines
+		return nil
+	}))
+	return changes
+}
+
+func TestChangeReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changefeed")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	Config.BackupPath = dir
+	Config.Changelog = true
+	defer func() { Config.Changelog = false }()
+
+	n := &node{gid: 4, id: 1}
+	appendToChangelog(n, 10, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{
+			{Entity: 1, Attr: "friend", ValueId: 2},
+			{Entity: 1, Attr: "name", Value: []byte("Alice")},
+		},
+	})
+	appendToChangelog(n, 11, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 2, Attr: "film.name", Value: []byte("Jaws")}},
+	})
+	changelogFor(n.gid).rotate()
+	defer changelogFor(n.gid).rotate()
+	// A replica wrote some of the same entries.
+	appendToChangelog(&node{gid: 4, id: 2}, 11, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 2, Attr: "film.name", Value: []byte("Jaws")}},
+	})
+	changelogFor(n.gid).rotate()
+	appendToChangelog(n, 12, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 3, Attr: "film.name", Value: []byte("Up")}},
+	})
+
+	r, err := NewChangeReader(4, 0, time.Time{}, []string{"film.*", "friend"})
+	require.NoError(t, err)
+	require.Equal(t, map[uint64][]string{
+		10: {"+ <_:uid1> <friend> <_:uid2> ."},
+		11: {`+ <_:uid2> <film.name> "Jaws" .`},
+		12: {`+ <_:uid3> <film.name> "Up" .`},
+	}, readChanges(t, r))
+	require.Equal(t, uint64(12), r.Last())
+
+	// Following the changelog only reads the new entries.
+	require.Empty(t, readChanges(t, r))
+	appendToChangelog(n, 13, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{
+			{Entity: 1, Attr: "name", Value: []byte("Bob")},
+			{Entity: 1, Attr: "friend", ValueId: 3, Op: protos.DirectedEdge_DEL},
+		},
+	})
+	require.Equal(t, map[uint64][]string{
+		13: {"- <_:uid1> <friend> <_:uid3> ."},
+	}, readChanges(t, r))
+
+	// Resuming from a cursor.
+	r, err = NewChangeReader(4, 11, time.Time{}, nil)
+	require.NoError(t, err)
+	changes := readChanges(t, r)
+	require.Len(t, changes, 2)
+	require.Len(t, changes[13], 2)
+
+	r, err = NewChangeReader(4, 0, time.Now().Add(time.Hour), nil)
+	require.NoError(t, err)
+	require.Empty(t, readChanges(t, r))
+	require.Equal(t, uint64(13), r.Last())
+
+	_, err = NewChangeReader(4, 0, time.Time{}, []string{"[a"})
+	require.Error(t, err)
+}
+
+func TestChangelogLineAttr(t *testing.T) {
+	require.Equal(t, "friend", changelogLineAttr("+ <_:uid1> <friend> <_:uid2> ."))
+	require.Equal(t, "age", changelogLineAttr("- * <age> * ."))
+	require.Equal(t, "film.name", changelogLineAttr("schema film.name:string @index(term) . "))
+}
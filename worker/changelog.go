@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.Changelog set, every server writes the mutations it applies for a group to a
+// changelog in the backup directory of the group, so that a restore of a backup can be rolled
+// forward to any point in time after it. Each entry starts with a header line
+//   # <raft index> <time applied, RFC3339>
+// followed by a line per edge and schema update of the mutation, as an N-Quad in the format of
+// exports, prefixed with its op:
+//   + <_:uid1> <name> "Alice" .
+//   - <_:uid1> <friend> * .
+//   schema name: string @index(exact) .
+// The changelog is split into segments named after the server and the index of their first entry,
+// and a new segment is started after every backup of the group. Entries may be written again
+// after a restart, and by every replica, which readers skip by their index.
+
+type changelog struct {
+	sync.Mutex
+	gid uint32
+	f   *os.File
+	w   *bufio.Writer
+	buf bytes.Buffer
+	// Path of the open segment, and when its first entry was written.
+	fpath   string
+	started time.Time
+}
+
+var changelogs struct {
+	sync.Mutex
+	m map[uint32]*changelog
+}
+
+func changelogFor(gid uint32) *changelog {
+	changelogs.Lock()
+	defer changelogs.Unlock()
+	if changelogs.m == nil {
+		changelogs.m = make(map[uint32]*changelog)
+	}
+	c, ok := changelogs.m[gid]
+	if !ok {
+		c = &changelog{gid: gid}
+		changelogs.m[gid] = c
+	}
+	return c
+}
+
+func changelogSegment(gid uint32, node, index uint64) string {
+	return path.Join(Config.BackupPath, fmt.Sprintf("group-%d", gid),
+		fmt.Sprintf("changelog-%d-%020d.rdf", node, index))
+}
+
+// skipInChangelog returns whether edges of attr are left out of the changelog. These are
+// maintained by the server itself, and are left out of exports too.
+func skipInChangelog(attr string) bool {
+	return attr == "_uid_" || attr == "_predicate_" || attr == "_lease_" ||
+		attr == MigrationsPredicate
+}
+
+func (c *changelog) closeSegment() error {
+	if c.f == nil {
+		return nil
+	}
+	err := c.w.Flush()
+	if cerr := c.f.Close(); err == nil {
+		err = cerr
+	}
+	c.f, c.w, c.fpath = nil, nil, ""
+	return err
+}
+
+// rotate starts a new segment with the next entry.
+func (c *changelog) rotate() {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.closeSegment(); err != nil {
+		x.Printf("Error while closing changelog of group %d: %v\n", c.gid, err)
+	}
+}
+
+func (c *changelog) append(node, index uint64, m *protos.Mutations) error {
+	c.Lock()
+	defer c.Unlock()
+
+	buf := &c.buf
+	buf.Reset()
+	for _, s := range m.Schema {
+		buf.WriteString("schema ")
+		toSchema(buf, &skv{attr: s.Predicate, name: s.Predicate, schema: s})
+	}
+	for _, edge := range m.Edges {
+		if skipInChangelog(edge.Attr) {
+			continue
+		}
+		edgeToRDF(buf, edge)
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	if c.f == nil {
+		fpath := changelogSegment(c.gid, node, index)
+		if err := os.MkdirAll(path.Dir(fpath), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(fpath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		c.f, c.w = f, bufio.NewWriter(f)
+		c.fpath, c.started = fpath, time.Now()
+	}
+	fmt.Fprintf(c.w, "# %d %s\n", index, time.Now().UTC().Format(time.RFC3339Nano))
+	if _, err := c.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	// Hand the entry to the OS, so that it survives the server crashing.
+	return c.w.Flush()
+}
+
+// edgeToRDF writes edge as a changelog line.
+func edgeToRDF(buf *bytes.Buffer, edge *protos.DirectedEdge) {
+	if edge.Op == protos.DirectedEdge_DEL {
+		buf.WriteString("- ")
+	} else {
+		buf.WriteString("+ ")
+	}
+	if edge.Entity == 0 {
+		buf.WriteString("*")
+	} else {
+		buf.WriteString("<_:uid")
+		buf.WriteString(strconv.FormatUint(edge.Entity, 16))
+		buf.WriteByte('>')
+	}
+	buf.WriteString(" <")
+	buf.WriteString(edge.Attr)
+	buf.WriteString("> ")
+	if bytes.Equal(edge.Value, []byte(x.Star)) {
+		buf.WriteString("* .\n")
+		return
+	}
+	p := &protos.Posting{
+		Uid:     edge.ValueId,
+		Value:   edge.Value,
+		ValType: protos.Posting_ValType(edge.ValueType),
+		Label:   edge.Label,
+		Facets:  edge.Facets,
+	}
+	if len(edge.Lang) > 0 {
+		p.PostingType = protos.Posting_VALUE_LANG
+		p.Metadata = []byte(edge.Lang)
+	}
+	postingToRDF(buf, p)
+}
+
+// appendToChangelog writes the mutations applied by node n at index to the changelog of its group.
+func appendToChangelog(n *node, index uint64, m *protos.Mutations) {
+	if !Config.Changelog {
+		return
+	}
+	if err := changelogFor(n.gid).append(n.id, index, m); err != nil {
+		x.Printf("Error while writing changelog of group %d at index %d: %v\n", n.gid, index, err)
+	}
+}
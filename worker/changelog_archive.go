@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/objstore"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.ChangelogArchive set, the segments of the changelog are copied, compressed, to the
+// archive as they're completed, so that a group can be restored to a recent point in time even if
+// its servers are lost. The archive can be a bucket. A segment is completed by a backup, or once its
+// first entry is older than Config.ChangelogArchiveLag, so that every mutation is archived within
+// about that lag of being applied. The archived segments of a group, and their sizes, are recorded
+// in a file in its backup folder, so that they're copied once. A segment which grows after being
+// archived, as entries are written again after a restart, is copied again.
+
+const archivedFile = "changelog-archived"
+
+type archivedSegments map[string]int64
+
+func readArchived(gdir string) (archivedSegments, error) {
+	done := make(archivedSegments)
+	f, err := os.Open(path.Join(gdir, archivedFile))
+	if os.IsNotExist(err) {
+		return done, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		done[fields[0]] = size
+	}
+	return done, scanner.Err()
+}
+
+func recordArchived(gdir, name string, size int64) error {
+	f, err := os.OpenFile(path.Join(gdir, archivedFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%s %d\n", name, size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotateIfOlder completes the open segment if its first entry is older than lag.
+func (c *changelog) rotateIfOlder(lag time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	if c.f == nil || time.Since(c.started) < lag {
+		return
+	}
+	if err := c.closeSegment(); err != nil {
+		x.Printf("Error while closing changelog of group %d: %v\n", c.gid, err)
+	}
+}
+
+// openSegment returns the path of the open segment and when its first entry was written, if
+// there's one.
+func (c *changelog) openSegment() (string, time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	return c.fpath, c.started
+}
+
+// segmentStart returns the time of the first entry of a segment, from its header.
+func segmentStart(fpath string) (time.Time, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return time.Time{}, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != "#" {
+		return time.Time{}, x.Errorf("Invalid changelog header: %q", line)
+	}
+	return time.Parse(time.RFC3339Nano, fields[2])
+}
+
+// archiveSegment copies the first size bytes of the segment at src, compressed and encrypted like
+// backups, to dst.
+func archiveSegment(src, dst string, size int64) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := createFile(dst)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	aw, err := artifact.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(aw, io.LimitReader(f, size)); err != nil {
+		return err
+	}
+	if err := aw.Close(); err != nil {
+		return err
+	}
+	// Closing an object in a bucket completes its upload.
+	return w.Close()
+}
+
+// archiveChangelog copies the completed segments of the changelog of group gid which aren't in the
+// archive yet, and returns the time of the oldest entry left to archive, if any.
+func archiveChangelog(gid uint32) (time.Time, error) {
+	var oldest time.Time
+	c := changelogFor(gid)
+	c.rotateIfOlder(Config.ChangelogArchiveLag)
+	open, started := c.openSegment()
+	if open != "" {
+		oldest = started
+	}
+
+	gname := fmt.Sprintf("group-%d", gid)
+	gdir := path.Join(Config.BackupPath, gname)
+	segs, err := filepath.Glob(path.Join(gdir, "changelog-*-*.rdf"))
+	if err != nil {
+		return oldest, err
+	}
+	sort.Strings(segs)
+	done, err := readArchived(gdir)
+	if err != nil {
+		return oldest, err
+	}
+	adir := objstore.Join(Config.ChangelogArchive, gname)
+	if err := mkdirAll(adir); err != nil {
+		return oldest, err
+	}
+	for _, seg := range segs {
+		if seg == open {
+			continue
+		}
+		fi, err := os.Stat(seg)
+		if err != nil {
+			return oldest, err
+		}
+		name := path.Base(seg)
+		if size, ok := done[name]; ok && size == fi.Size() {
+			continue
+		}
+		if fi.Size() == 0 {
+			continue
+		}
+		err = archiveSegment(seg, objstore.Join(adir, name+artifact.Ext()), fi.Size())
+		if err == nil {
+			err = recordArchived(gdir, name, fi.Size())
+		}
+		if err != nil {
+			if t, serr := segmentStart(seg); serr == nil && (oldest.IsZero() || t.Before(oldest)) {
+				oldest = t
+			}
+			return oldest, x.Wrapf(err, "While archiving changelog segment: %v", seg)
+		}
+	}
+	return oldest, nil
+}
+
+// archiveChangelogs periodically archives the changelogs of all the groups with one in the backup
+// folder.
+func archiveChangelogs() {
+	interval := Config.ChangelogArchiveLag / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		gdirs, err := filepath.Glob(path.Join(Config.BackupPath, "group-*"))
+		if err != nil {
+			x.Printf("Error while listing changelogs: %v\n", err)
+			continue
+		}
+		for _, gdir := range gdirs {
+			gid, err := strconv.ParseUint(strings.TrimPrefix(path.Base(gdir), "group-"), 10, 32)
+			if err != nil {
+				continue
+			}
+			oldest, err := archiveChangelog(uint32(gid))
+			if err != nil {
+				x.Printf("Error while archiving changelog of group %d: %v\n", gid, err)
+			}
+			lag := new(expvar.Int)
+			if !oldest.IsZero() {
+				lag.Set(int64(time.Since(oldest) / time.Second))
+			}
+			x.ChangelogArchiveLag.Set(strconv.FormatUint(gid, 10), lag)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestArchiveChangelog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changelog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	adir, err := ioutil.TempDir("", "archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(adir)
+	Config.BackupPath = dir
+	Config.Changelog = true
+	Config.ChangelogArchive = adir
+	Config.ChangelogArchiveLag = time.Hour
+	defer func() {
+		Config.Changelog = false
+		Config.ChangelogArchive = ""
+	}()
+
+	n := &node{gid: 4, id: 1}
+	appendToChangelog(n, 10, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 1, Attr: "friend", ValueId: 2}},
+	})
+	archived := func() []string {
+		segs, err := filepath.Glob(filepath.Join(adir, "group-4", "*"))
+		require.NoError(t, err)
+		return segs
+	}
+
+	// The open segment isn't archived until it's older than the lag.
+	oldest, err := archiveChangelog(n.gid)
+	require.NoError(t, err)
+	require.False(t, oldest.IsZero())
+	require.Empty(t, archived())
+
+	Config.ChangelogArchiveLag = time.Nanosecond
+	oldest, err = archiveChangelog(n.gid)
+	require.NoError(t, err)
+	require.True(t, oldest.IsZero())
+	segs := archived()
+	require.Len(t, segs, 1)
+	require.Equal(t, "changelog-1-00000000000000000010.rdf.gz", filepath.Base(segs[0]))
+	lines := readGzLines(t, segs[0])
+	require.Len(t, lines, 2)
+	require.Equal(t, "+ <_:uid1> <friend> <_:uid2> .", lines[1])
+
+	// Archived segments aren't copied again, unless they grow.
+	require.NoError(t, os.Remove(segs[0]))
+	_, err = archiveChangelog(n.gid)
+	require.NoError(t, err)
+	require.Empty(t, archived())
+
+	appendToChangelog(n, 10, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 1, Attr: "friend", ValueId: 3}},
+	})
+	changelogFor(n.gid).rotate()
+	_, err = archiveChangelog(n.gid)
+	require.NoError(t, err)
+	segs = archived()
+	require.Len(t, segs, 1)
+	require.Len(t, readGzLines(t, segs[0]), 4)
+}
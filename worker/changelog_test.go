@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+func TestChangelog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changelog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	Config.BackupPath = dir
+	Config.Changelog = true
+	defer func() { Config.Changelog = false }()
+
+	n := &node{gid: 3, id: 2}
+	appendToChangelog(n, 10, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{
+			{Entity: 1, Attr: "friend", ValueId: 2},
+			{Entity: 1, Attr: "_predicate_", Value: []byte("friend")},
+			{Entity: 1, Attr: "name", Value: []byte("Alice"), Lang: "en"},
+		},
+	})
+	appendToChangelog(n, 11, &protos.Mutations{
+		Schema: []*protos.SchemaUpdate{{Predicate: "age", ValueType: uint32(types.IntID)}},
+		Edges: []*protos.DirectedEdge{
+			{Entity: 1, Attr: "friend", Value: []byte(x.Star), Op: protos.DirectedEdge_DEL},
+			{Attr: "age", Value: []byte(x.Star), Op: protos.DirectedEdge_DEL},
+		},
+	})
+	changelogFor(n.gid).rotate()
+	appendToChangelog(n, 12, &protos.Mutations{
+		Edges: []*protos.DirectedEdge{{Entity: 10, Attr: "friend", ValueId: 11}},
+	})
+	changelogFor(n.gid).rotate()
+
+	segs, err := filepath.Glob(filepath.Join(dir, "group-3", "changelog-2-*.rdf"))
+	require.NoError(t, err)
+	require.Len(t, segs, 2)
+	require.True(t, strings.HasSuffix(segs[0], "changelog-2-00000000000000000010.rdf"))
+
+	data, err := ioutil.ReadFile(segs[0])
+	require.NoError(t, err)
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "#") {
+			// Drop the time.
+			line = line[:strings.LastIndex(line, " ")]
+		}
+		lines = append(lines, line)
+	}
+	require.Equal(t, []string{
+		"# 10",
+		"+ <_:uid1> <friend> <_:uid2> .",
+		`+ <_:uid1> <name> "Alice"@en .`,
+		"# 11",
+		"schema age:int . ",
+		"- <_:uid1> <friend> * .",
+		"- * <age> * .",
+	}, lines)
+}
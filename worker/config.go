@@ -16,18 +16,62 @@
  */
 package worker
 
+import (
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
 type Options struct {
 	BaseWorkerPort      int
 	ExportPath          string
+	BackupPath          string
+	Changelog           bool
+	ChangelogArchive    string
+	ChangelogArchiveLag time.Duration
+	// CDCKafka is the comma separated list of the Kafka brokers changes are published to, if any,
+	// on the topics of CDCTopics, or CDCTopic, in CDCFormat.
+	CDCKafka  string
+	CDCTopic  string
+	CDCTopics string
+	CDCFormat string
+	// Elastic is the comma separated list of the Elasticsearch nodes the string predicates matching
+	// ElasticPredicates are mirrored to, if any, in ElasticIndex.
+	Elastic           string
+	ElasticIndex      string
+	ElasticPredicates string
+	// Webhooks is whether changes are posted to the webhooks set with SetWebhooks.
+	Webhooks            bool
+	ExportRedact        string
+	ExportRedactKey     string
+	RedactBackups       bool
 	NumPendingProposals int
 	Tracing             float64
 	GroupIds            string
 	MyAddr              string
+	ClientAddr          string
 	PeerAddr            string
 	RaftId              uint64
 	MaxPendingCount     uint64
 	ExpandEdge          bool
 	InMemoryComm        bool
+	// KindPredicate is the predicate the kinds of nodes are values of, for @required.
+	KindPredicate string
+	// GeoRepair is whether the rings of polygons set as geo values which are wound the wrong way
+	// are reversed, rather than rejected.
+	GeoRepair bool
+	// EventRetention is how long events are kept in the event log, forever if zero.
+	EventRetention time.Duration
+	// ProgressThreshold is how long queries and jobs run before their progress can be followed.
+	ProgressThreshold time.Duration
+	// StallDeadline is how long proposals and read tasks run before the server is taken to be
+	// stalled, and DiagnosticsPath where the bundles written then go.
+	StallDeadline   time.Duration
+	DiagnosticsPath string
+	// PeerServerCreds and PeerClientCreds secure the connections between nodes, on the worker
+	// port, when set.
+	PeerServerCreds credentials.TransportCredentials
+	PeerClientCreds credentials.TransportCredentials
 }
 
 var Config Options
@@ -173,11 +173,16 @@ func testConnection(p *pool) error {
 
 // NewPool creates a new "pool" with one gRPC connection, refcount 0.
 func newPool(addr string) (*pool, error) {
+	security := grpc.WithInsecure()
+	if Config.PeerClientCreds != nil {
+		security = grpc.WithTransportCredentials(Config.PeerClientCreds)
+	}
 	conn, err := grpc.Dial(addr,
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(x.GrpcMaxSize),
 			grpc.MaxCallSendMsgSize(x.GrpcMaxSize)),
-		grpc.WithInsecure())
+		grpc.WithUnaryInterceptor(unaryClientInterceptor),
+		security)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.CDCFormat set to "debezium", the messages published to Kafka are in the envelope of
+// Debezium, as written by the JSON converter of Kafka Connect with schemas, so that its sinks and
+// transforms, like ExtractNewRecordState, take them as they are. Each edge is a row, in before for
+// deletes and in after for sets, with its predicate as the table of its source.
+
+const (
+	cdcFormatDgraph   = "dgraph"
+	cdcFormatDebezium = "debezium"
+)
+
+// ValidateCDCFormat checks the format of the messages of Config.CDCFormat.
+func ValidateCDCFormat(f string) error {
+	if f != cdcFormatDgraph && f != cdcFormatDebezium {
+		return x.Errorf("Invalid format of changes: %q. Valid formats are %s and %s", f,
+			cdcFormatDgraph, cdcFormatDebezium)
+	}
+	return nil
+}
+
+// connectSchema is the schema of a value for the JSON converter of Kafka Connect.
+type connectSchema struct {
+	Type     string          `json:"type"`
+	Optional bool            `json:"optional"`
+	Name     string          `json:"name,omitempty"`
+	Field    string          `json:"field,omitempty"`
+	Fields   []connectSchema `json:"fields,omitempty"`
+}
+
+func connectField(typ, field string, optional bool) connectSchema {
+	return connectSchema{Type: typ, Field: field, Optional: optional}
+}
+
+// connectMessage is a value with its schema, as written by the JSON converter of Kafka Connect.
+type connectMessage struct {
+	Schema  connectSchema `json:"schema"`
+	Payload interface{}   `json:"payload"`
+}
+
+// debeziumRow is the row of an edge. Values are strings, with their type in Type, so that the
+// rows of a table all have the same schema.
+type debeziumRow struct {
+	Uid       string  `json:"uid"`
+	Predicate string  `json:"predicate"`
+	Object    *string `json:"object"`
+	Value     *string `json:"value"`
+	Type      *string `json:"type"`
+	Lang      *string `json:"lang"`
+	Facets    *string `json:"facets"`
+}
+
+type debeziumSource struct {
+	Version   string `json:"version"`
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	Ts        int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	Db        string `json:"db"`
+	Table     string `json:"table"`
+	Group     uint32 `json:"group"`
+	Index     uint64 `json:"index"`
+}
+
+type debeziumEnvelope struct {
+	Before *debeziumRow   `json:"before"`
+	After  *debeziumRow   `json:"after"`
+	Source debeziumSource `json:"source"`
+	Op     string         `json:"op"`
+	Ts     int64          `json:"ts_ms"`
+}
+
+func debeziumSchemas(topic string) (key, value connectSchema) {
+	key = connectSchema{Type: "struct", Name: "dgraph." + topic + ".Key", Fields: []connectSchema{
+		connectField("string", "uid", false),
+	}}
+	row := func(field string) connectSchema {
+		return connectSchema{Type: "struct", Name: "dgraph." + topic + ".Value", Field: field,
+			Optional: true, Fields: []connectSchema{
+				connectField("string", "uid", false),
+				connectField("string", "predicate", false),
+				connectField("string", "object", true),
+				connectField("string", "value", true),
+				connectField("string", "type", true),
+				connectField("string", "lang", true),
+				connectField("string", "facets", true),
+			}}
+	}
+	source := connectSchema{Type: "struct", Name: "io.debezium.connector.dgraph.Source",
+		Field: "source", Fields: []connectSchema{
+			connectField("string", "version", false),
+			connectField("string", "connector", false),
+			connectField("string", "name", false),
+			connectField("int64", "ts_ms", false),
+			connectField("string", "snapshot", true),
+			connectField("string", "db", false),
+			connectField("string", "table", false),
+			connectField("int64", "group", false),
+			connectField("int64", "index", false),
+		}}
+	value = connectSchema{Type: "struct", Name: "dgraph." + topic + ".Envelope",
+		Fields: []connectSchema{
+			row("before"),
+			row("after"),
+			source,
+			connectField("string", "op", false),
+			connectField("int64", "ts_ms", true),
+		}}
+	return key, value
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// debeziumValue returns the value v of an edge as a string.
+func debeziumValue(v interface{}) (*string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &v, nil
+	case []byte:
+		s := base64.StdEncoding.EncodeToString(v)
+		return &s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(b)
+	return &s, nil
+}
+
+// debeziumMessage returns the key and the value of the message of e on topic.
+func debeziumMessage(e *CDCEdge, topic string) ([]byte, []byte, error) {
+	row := &debeziumRow{
+		Uid:       e.Subject,
+		Predicate: e.Predicate,
+		Object:    optionalString(e.Object),
+		Type:      optionalString(e.Type),
+		Lang:      optionalString(e.Lang),
+	}
+	var err error
+	if row.Value, err = debeziumValue(e.Value); err != nil {
+		return nil, nil, err
+	}
+	if len(e.Facets) > 0 {
+		b, err := json.Marshal(e.Facets)
+		if err != nil {
+			return nil, nil, err
+		}
+		row.Facets = optionalString(string(b))
+	}
+
+	env := debeziumEnvelope{
+		Source: debeziumSource{
+			Version:   x.Version(),
+			Connector: "dgraph",
+			Name:      "dgraph",
+			Ts:        e.Commit.UnixNano() / int64(time.Millisecond),
+			Snapshot:  "false",
+			Db:        "dgraph",
+			Table:     e.Predicate,
+			Group:     e.Group,
+			Index:     e.Index,
+		},
+		Op: "c",
+		Ts: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if e.Op == "del" {
+		env.Before, env.Op = row, "d"
+	} else {
+		env.After = row
+	}
+
+	keySchema, valueSchema := debeziumSchemas(topic)
+	key, err := json.Marshal(connectMessage{Schema: keySchema,
+		Payload: map[string]string{"uid": e.Subject}})
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err := json.Marshal(connectMessage{Schema: valueSchema, Payload: env})
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
@@ -24,6 +24,7 @@ import (
 	"log"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/etcd/raft"
@@ -200,6 +201,7 @@ type node struct {
 	messages    chan sendmsg
 	peers       peerPool
 	props       proposals
+	pending     int32 // Proposals of ProposeAndWait waiting to be applied.
 	raftContext *protos.RaftContext
 	store       *raft.MemoryStorage
 	wal         *raftwal.Wal
@@ -376,7 +378,13 @@ func (n *node) ProposeAndWait(ctx context.Context, proposal *protos.Proposal) er
 	// TODO: Should be based on number of edges (amount of work)
 	pendingProposals <- struct{}{}
 	x.PendingProposals.Add(1)
-	defer func() { <-pendingProposals; x.PendingProposals.Add(-1) }()
+	atomic.AddInt32(&n.pending, 1)
+	defer func() {
+		<-pendingProposals
+		x.PendingProposals.Add(-1)
+		atomic.AddInt32(&n.pending, -1)
+	}()
+	defer trackOperation(opProposal, n.gid, proposalDesc(proposal))()
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
@@ -421,6 +429,7 @@ func (n *node) ProposeAndWait(ctx context.Context, proposal *protos.Proposal) er
 	}
 
 	//	we don't timeout on a mutation which has already been proposed.
+	start := time.Now()
 	if err = n.Raft().Propose(ctx, slice[:upto]); err != nil {
 		return x.Wrapf(err, "While proposing")
 	}
@@ -439,6 +448,7 @@ func (n *node) ProposeAndWait(ctx context.Context, proposal *protos.Proposal) er
 	}
 
 	err = <-che
+	x.ProposalLatency.WithLabelValues(fmt.Sprint(n.gid)).Observe(time.Since(start).Seconds())
 	if err != nil {
 		if tr, ok := trace.FromContext(ctx); ok {
 			tr.LazyPrintf(err.Error())
@@ -611,6 +621,7 @@ func (n *node) applyConfChange(e raftpb.Entry) {
 
 func (n *node) processApplyCh() {
 	for e := range n.applyCh {
+		pendingMutations.Add(-int64(len(e.Data)))
 		if len(e.Data) == 0 {
 			n.applied.Done(e.Index)
 			posting.SyncMarkFor(n.gid).Done(e.Index)
@@ -640,7 +651,12 @@ func (n *node) processApplyCh() {
 			n.props.Store(proposal.Id, pctx)
 		}
 		if proposal.Mutations != nil {
+			mirrorRenames(proposal.Mutations)
+			appendToChangelog(n, e.Index, proposal.Mutations)
 			n.sch.schedule(proposal, e.Index)
+			if hasWatchers() {
+				go n.notifyApplied(e.Index, proposal.Mutations)
+			}
 		} else if proposal.Membership != nil {
 			go n.processMembership(e.Index, proposal.Id, proposal.Membership)
 		} else {
@@ -691,11 +707,14 @@ func (n *node) retrieveSnapshot(peerID uint64) {
 	// index greater than this node's last index
 	// Should invalidate/remove pl's to this group only ideally
 	posting.EvictGroup(n.gid)
-	if _, err := populateShard(n.ctx, pstore, pool, n.gid); err != nil {
+	count, err := populateShard(n.ctx, pstore, pool, n.gid)
+	if err != nil {
 		// TODO: We definitely don't want to just fall flat on our face if we can't
 		// retrieve a simple snapshot.
 		log.Fatalf("Cannot retrieve snapshot from peer %v, error: %v\n", peerID, err)
 	}
+	RecordEvent(EventShard, n.gid, n.id, "Node %d copied %d keys of group %d from node %d",
+		n.id, count, n.gid, peerID)
 	// Populate shard stores the streamed data directly into db, so we need to refresh
 	// schema for current group id
 	x.Checkf(schema.LoadFromDb(n.gid), "Error while initilizating schema")
@@ -783,6 +802,7 @@ func (n *node) Run() {
 					// Just queue up to be processed. Don't wait on them.
 					// TODO: Stop accepting requests when applyCh is full
 					// Just queue up to be processed. Don't wait on them.
+					pendingMutations.Add(int64(len(entry.Data)))
 					n.applyCh <- entry
 				}
 			}
@@ -1000,6 +1020,7 @@ func (n *node) InitAndStartNode(wal *raftwal.Wal) {
 	// state information, which isn't persisted.
 	go n.snapshotPeriodically()
 	go n.batchAndSendMessages()
+	go n.reportRaftMetrics()
 }
 
 func (n *node) AmLeader() bool {
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/elastic"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.Elastic set, the leader of each group served here mirrors the string predicates
+// matching Config.ElasticPredicates to Config.ElasticIndex, from the changes written to its
+// changelog, like the changes published to Kafka. Each node is a document, with its uid as id and
+// a field for each predicate, named pred@lang for values with a language. Deleting a value sets
+// its field to null. Predicates of lists aren't mirrored, as a field only holds the last value.
+
+const elasticOffsetFile = "elastic-offset"
+
+// elasticField returns whether the values of attr are mirrored.
+func elasticField(patterns []string, attr string) bool {
+	if len(patterns) > 0 && !matchAny(patterns, attr) {
+		return false
+	}
+	typ, err := schema.State().TypeOf(attr)
+	if err != nil || schema.State().IsList(attr) {
+		return false
+	}
+	return typ == types.StringID || typ == types.DefaultID
+}
+
+// elasticUpdates returns the updates to the documents of the nodes of change.
+func elasticUpdates(c *Change, patterns []string) ([]elastic.Update, error) {
+	var updates []elastic.Update
+	docs := make(map[string]int)
+	for _, line := range c.Lines {
+		e, err := cdcEdge(c, line)
+		if err != nil {
+			return nil, x.Wrapf(err, "While reading entry %d of changelog of group %d",
+				c.Index, c.Group)
+		}
+		if e == nil || len(e.Object) > 0 || !elasticField(patterns, e.Predicate) {
+			continue
+		}
+		field := e.Predicate
+		if len(e.Lang) > 0 {
+			field += "@" + e.Lang
+		}
+		var val interface{}
+		if e.Op == "set" {
+			val = e.Value
+		}
+		// The edges of a node are applied to a single update, in order.
+		i, ok := docs[e.Subject]
+		if !ok {
+			i = len(updates)
+			docs[e.Subject] = i
+			updates = append(updates, elastic.Update{
+				Index:  Config.ElasticIndex,
+				Id:     e.Subject,
+				Fields: make(map[string]interface{}),
+			})
+		}
+		updates[i].Fields[field] = val
+	}
+	return updates, nil
+}
+
+// syncElastic mirrors the changes of the groups led here to Elasticsearch, as they're applied.
+func syncElastic() {
+	var patterns []string
+	if len(Config.ElasticPredicates) > 0 {
+		patterns = strings.Split(Config.ElasticPredicates, ",")
+	}
+	client := elastic.NewClient(strings.Split(Config.Elastic, ","), 30*time.Second)
+	syncChanges("Elasticsearch", elasticOffsetFile, func(c *Change) error {
+		updates, err := elasticUpdates(c, patterns)
+		if err != nil {
+			return err
+		}
+		if err := client.Bulk(updates); err != nil {
+			return err
+		}
+		x.ElasticUpdates.Add(int64(len(updates)))
+		return nil
+	}, x.ElasticErrors)
+}
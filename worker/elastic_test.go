@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/elastic"
+	"github.com/dgraph-io/dgraph/schema"
+)
+
+func TestElasticUpdates(t *testing.T) {
+	defer func(index string) { Config.ElasticIndex = index }(Config.ElasticIndex)
+	Config.ElasticIndex = "dgraph"
+	require.NoError(t, schema.ParseBytes([]byte(`
+		name: string @index(exact) .
+		bio: string .
+		age: int .
+		alias: [string] .
+		friend: uid .`), 1))
+
+	c := &Change{Group: 1, Index: 42, Time: time.Now(), Lines: []string{
+		"schema bio: string .",
+		`+ <_:uid1> <name> "Alice" .`,
+		`+ <_:uid1> <name> "Alicia"@es .`,
+		`+ <_:uid1> <age> "30"^^<xs:int> .`,
+		`+ <_:uid1> <alias> "Al" .`,
+		`+ <_:uid1> <friend> <_:uid2> .`,
+		`+ <_:uid2> <bio> "Bob's bio" .`,
+		`- <_:uid1> <bio> * .`,
+		`+ <_:uid1> <name> "Alice B." .`,
+	}}
+	updates, err := elasticUpdates(c, nil)
+	require.NoError(t, err)
+	require.Equal(t, []elastic.Update{
+		{Index: "dgraph", Id: "0x1", Fields: map[string]interface{}{
+			"name": "Alice B.", "name@es": "Alicia", "bio": nil}},
+		{Index: "dgraph", Id: "0x2", Fields: map[string]interface{}{"bio": "Bob's bio"}},
+	}, updates)
+
+	// Only the predicates matching the patterns are mirrored.
+	updates, err = elasticUpdates(c, []string{"bio"})
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	require.Equal(t, "0x2", updates[0].Id)
+	require.Equal(t, map[string]interface{}{"bio": nil}, updates[1].Fields)
+}
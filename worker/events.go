@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The event log records the changes of the cluster seen by a server: members joining or changing
+// their address, and leaders being elected, for all groups, schema changes and copies of the data
+// of a group, for the groups it serves, and changes of its config. Events are kept in the WAL
+// store, for Config.EventRetention, under keys which can't be those of raft nodes, whose ids
+// aren't zero: eight zero bytes, "ev", and the time of the event in nanoseconds with a sequence
+// number, so that they're sorted by time.
+
+// Types of events.
+const (
+	EventMember = "member"
+	EventLeader = "leader"
+	EventSchema = "schema"
+	EventShard  = "shard"
+	EventConfig = "config"
+)
+
+var eventPrefix = []byte("\x00\x00\x00\x00\x00\x00\x00\x00ev")
+
+// Event is a change of the cluster.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	// Group and Node are those the event is about, if any.
+	Group   uint32 `json:"group,omitempty"`
+	Node    uint64 `json:"node,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	eventStore *badger.KV
+	eventSeq   uint32
+)
+
+func initEvents(kv *badger.KV) {
+	eventStore = kv
+}
+
+func eventKey(t time.Time, seq uint32) []byte {
+	b := make([]byte, len(eventPrefix)+12)
+	copy(b, eventPrefix)
+	binary.BigEndian.PutUint64(b[len(eventPrefix):], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint32(b[len(eventPrefix)+8:], seq)
+	return b
+}
+
+// RecordEvent adds an event of typ about group and node, if any, to the event log.
+func RecordEvent(typ string, group uint32, node uint64, format string, args ...interface{}) {
+	e := Event{
+		Time:    time.Now(),
+		Type:    typ,
+		Group:   group,
+		Node:    node,
+		Message: fmt.Sprintf(format, args...),
+	}
+	x.Events.Add(typ, 1)
+	if eventStore == nil {
+		return
+	}
+	val, err := json.Marshal(e)
+	x.Check(err)
+	key := eventKey(e.Time, atomic.AddUint32(&eventSeq, 1))
+	if err := eventStore.Set(key, val, 0x00); err != nil {
+		x.Printf("Error while recording %s event: %v\n", typ, err)
+	}
+}
+
+// EventQuery selects the events returned by Events.
+type EventQuery struct {
+	// Since and Until bound the time of events, if set. Until is excluded.
+	Since, Until time.Time
+	// Types of the events, all of them if empty.
+	Types []string
+	// Group of the events, any if zero.
+	Group uint32
+	// Limit is the most events returned, the last ones, or all of them if zero.
+	Limit int
+}
+
+func (q *EventQuery) matches(e *Event) bool {
+	if q.Group != 0 && e.Group != q.Group {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if e.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Events returns the events of the event log selected by q, from the oldest.
+func Events(q EventQuery) ([]Event, error) {
+	if eventStore == nil {
+		return nil, x.Errorf("The event log isn't open")
+	}
+	start := eventPrefix
+	if !q.Since.IsZero() {
+		start = eventKey(q.Since, 0)
+	}
+	var end []byte
+	if !q.Until.IsZero() {
+		end = eventKey(q.Until, 0)
+	}
+
+	events := []Event{}
+	it := eventStore.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for it.Seek(start); it.ValidForPrefix(eventPrefix); it.Next() {
+		item := it.Item()
+		if end != nil && bytes.Compare(item.Key(), end) >= 0 {
+			break
+		}
+		var e Event
+		if err := json.Unmarshal(item.Value(), &e); err != nil {
+			return nil, x.Wrapf(err, "While decoding event")
+		}
+		if !q.matches(&e) {
+			continue
+		}
+		events = append(events, e)
+		if q.Limit > 0 && len(events) > q.Limit {
+			events = events[1:]
+		}
+	}
+	return events, nil
+}
+
+// pruneEvents removes the events older than before.
+func pruneEvents(before time.Time) error {
+	end := eventKey(before, 0)
+	var entries []*badger.Entry
+	it := eventStore.NewIterator(badger.IteratorOptions{PrefetchSize: 100})
+	for it.Seek(eventPrefix); it.ValidForPrefix(eventPrefix); it.Next() {
+		key := it.Item().Key()
+		if bytes.Compare(key, end) >= 0 {
+			break
+		}
+		entries = badger.EntriesDelete(entries, append([]byte(nil), key...))
+	}
+	it.Close()
+	if len(entries) == 0 {
+		return nil
+	}
+	return eventStore.BatchSet(entries)
+}
+
+// pruneEventsPeriodically removes the events past Config.EventRetention every hour.
+func pruneEventsPeriodically() {
+	if Config.EventRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := pruneEvents(time.Now().Add(-Config.EventRetention)); err != nil {
+			x.Printf("Error while pruning the event log: %v\n", err)
+		}
+	}
+}
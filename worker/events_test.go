@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "events")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	opt := badger.DefaultOptions
+	opt.Dir = dir
+	opt.ValueDir = dir
+	kv, err := badger.NewKV(&opt)
+	require.NoError(t, err)
+	defer kv.Close()
+	initEvents(kv)
+	defer initEvents(nil)
+
+	start := time.Now()
+	RecordEvent(EventMember, 1, 2, "Node %d joined group %d at %s", 2, 1, "10.0.0.2:12345")
+	RecordEvent(EventLeader, 1, 2, "Node %d became the leader of group %d", 2, 1)
+	RecordEvent(EventSchema, 2, 1, "Schema changed to %s", "name:string @index(term) .")
+	middle := time.Now()
+	RecordEvent(EventConfig, 0, 1, "memory_mb set to %v", 4096)
+
+	events, err := Events(EventQuery{})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, EventMember, events[0].Type)
+	require.Equal(t, "Node 2 joined group 1 at 10.0.0.2:12345", events[0].Message)
+	require.False(t, events[0].Time.Before(start))
+
+	events, err = Events(EventQuery{Group: 1, Types: []string{EventLeader, EventSchema}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventLeader, events[0].Type)
+
+	events, err = Events(EventQuery{Since: middle})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventConfig, events[0].Type)
+
+	events, err = Events(EventQuery{Until: middle, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, EventLeader, events[0].Type)
+	require.Equal(t, EventSchema, events[1].Type)
+
+	require.NoError(t, pruneEvents(middle))
+	events, err = Events(EventQuery{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventConfig, events[0].Type)
+}
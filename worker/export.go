@@ -20,11 +20,11 @@ package worker
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
-	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,7 +34,9 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/dgraph-io/dgraph/artifact"
 	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/objstore"
 	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
@@ -48,12 +50,19 @@ const numExportRoutines = 10
 
 type kv struct {
 	prefix string
+	attr   string
+	name   string // Name of attr in the export.
+	uid    uint64
 	list   *protos.PostingList
+	filter *exportFilter
+	redact *redactRule // Rule redacting the values of attr, if any.
 }
 
 type skv struct {
 	attr   string
+	name   string // Name of attr in the export.
 	schema *protos.SchemaUpdate
+	typ    *protos.TypeUpdate // Declaration of a type, set instead of schema.
 }
 
 // Map from our types to RDF type. Useful when writing storage types
@@ -76,76 +85,95 @@ func toRDF(buf *bytes.Buffer, item kv) {
 	pitr.Init(pl, 0)
 	for ; pitr.Valid(); pitr.Next() {
 		p := pitr.Posting()
-		buf.WriteString(item.prefix)
-		if !bytes.Equal(p.Value, nil) {
-			// Value posting
-			// Convert to appropriate type
-			vID := types.TypeID(p.ValType)
-			src := types.ValueForType(vID)
-			src.Value = p.Value
-			str, err := types.Convert(src, types.StringID)
-			x.Check(err)
-			buf.WriteByte('"')
-			buf.WriteString(str.Value.(string))
-			buf.WriteByte('"')
-			if p.PostingType == protos.Posting_VALUE_LANG {
-				buf.WriteByte('@')
-				buf.WriteString(string(p.Metadata))
-			} else if vID != types.DefaultID {
-				rdfType, ok := rdfTypeMap[vID]
-				x.AssertTruef(ok, "Didn't find RDF type for dgraph type: %+v", vID.Name())
-				buf.WriteString("^^<")
-				buf.WriteString(rdfType)
-				buf.WriteByte('>')
-			}
-		} else {
-			buf.WriteString("<_:uid")
-			buf.WriteString(strconv.FormatUint(p.Uid, 16))
-			buf.WriteByte('>')
+		if !keepPosting(item, p) {
+			continue
 		}
-		// Label
-		if len(p.Label) > 0 {
-			buf.WriteString(" <")
-			buf.WriteString(p.Label)
+		buf.WriteString(item.prefix)
+		postingToRDF(buf, item.redact.apply(p))
+	}
+}
+
+// keepPosting returns whether the posting p of item goes into the export. Edges to nodes left out
+// of it are dropped.
+func keepPosting(item kv, p *protos.Posting) bool {
+	return !bytes.Equal(p.Value, nil) || item.filter.keepUid(p.Uid)
+}
+
+// postingToRDF writes the object, label and facets of the posting p, ending the N-Quad.
+func postingToRDF(buf *bytes.Buffer, p *protos.Posting) {
+	if !bytes.Equal(p.Value, nil) {
+		// Value posting
+		// Convert to appropriate type
+		vID := types.TypeID(p.ValType)
+		src := types.ValueForType(vID)
+		src.Value = p.Value
+		str, err := types.Convert(src, types.StringID)
+		x.Check(err)
+		buf.WriteByte('"')
+		buf.WriteString(str.Value.(string))
+		buf.WriteByte('"')
+		if p.PostingType == protos.Posting_VALUE_LANG {
+			buf.WriteByte('@')
+			buf.WriteString(string(p.Metadata))
+		} else if vID != types.DefaultID {
+			rdfType, ok := rdfTypeMap[vID]
+			x.AssertTruef(ok, "Didn't find RDF type for dgraph type: %+v", vID.Name())
+			buf.WriteString("^^<")
+			buf.WriteString(rdfType)
 			buf.WriteByte('>')
 		}
-		// Facets.
-		fcs := p.Facets
-		if len(fcs) != 0 {
-			buf.WriteString(" (")
-			for i, f := range fcs {
-				if i != 0 {
-					buf.WriteByte(',')
-				}
-				buf.WriteString(f.Key)
-				buf.WriteByte('=')
-				fVal := &types.Val{Tid: types.StringID}
-				x.Check(types.Marshal(facets.ValFor(f), fVal))
-				if facets.TypeIDFor(f) == types.StringID {
-					buf.WriteByte('"')
-					buf.WriteString(fVal.Value.(string))
-					buf.WriteByte('"')
-				} else {
-					buf.WriteString(fVal.Value.(string))
-				}
+	} else {
+		buf.WriteString("<_:uid")
+		buf.WriteString(strconv.FormatUint(p.Uid, 16))
+		buf.WriteByte('>')
+	}
+	// Label
+	if len(p.Label) > 0 {
+		buf.WriteString(" <")
+		buf.WriteString(p.Label)
+		buf.WriteByte('>')
+	}
+	// Facets.
+	fcs := p.Facets
+	if len(fcs) != 0 {
+		buf.WriteString(" (")
+		for i, f := range fcs {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(f.Key)
+			buf.WriteByte('=')
+			fVal := &types.Val{Tid: types.StringID}
+			x.Check(types.Marshal(facets.ValFor(f), fVal))
+			if facets.TypeIDFor(f) == types.StringID {
+				buf.WriteByte('"')
+				buf.WriteString(fVal.Value.(string))
+				buf.WriteByte('"')
+			} else {
+				buf.WriteString(fVal.Value.(string))
 			}
-			buf.WriteByte(')')
 		}
-		// End dot.
-		buf.WriteString(" .\n")
+		buf.WriteByte(')')
 	}
+	// End dot.
+	buf.WriteString(" .\n")
 }
 
 func toSchema(buf *bytes.Buffer, s *skv) {
-	if strings.ContainsRune(s.attr, ':') {
+	if s.typ != nil {
+		buf.WriteString(schema.TypeLine(s.typ))
+		buf.WriteByte('\n')
+		return
+	}
+	if strings.ContainsRune(s.name, ':') {
 		buf.WriteRune('<')
-		buf.WriteString(s.attr)
+		buf.WriteString(s.name)
 		buf.WriteRune('>')
 	} else {
-		buf.WriteString(s.attr)
+		buf.WriteString(s.name)
 	}
 	buf.WriteByte(':')
-	isList := schema.State().IsList(s.attr)
+	isList := s.schema.List || schema.State().IsList(s.attr)
 	if isList {
 		buf.WriteRune('[')
 	}
@@ -157,66 +185,185 @@ func toSchema(buf *bytes.Buffer, s *skv) {
 		buf.WriteString(" @reverse")
 	} else if s.schema.Directive == protos.SchemaUpdate_INDEX && len(s.schema.Tokenizer) > 0 {
 		buf.WriteString(" @index(")
-		buf.WriteString(strings.Join(s.schema.Tokenizer, ","))
+		buf.WriteString(strings.Join(schema.Tokenizers(s.schema.Tokenizer, s.schema.Geo), ","))
 		buf.WriteByte(')')
 	}
 	if s.schema.Count {
 		buf.WriteString(" @count")
 	}
+	if s.schema.Noreplace {
+		buf.WriteString(" @noreplace")
+	}
+	if len(s.schema.Ondelete) > 0 {
+		buf.WriteString(" @ondelete(")
+		buf.WriteString(s.schema.Ondelete)
+		buf.WriteByte(')')
+	}
+	if len(s.schema.Default) > 0 {
+		buf.WriteString(" @default(")
+		buf.WriteString(schema.Quote(s.schema.Default))
+		buf.WriteByte(')')
+	}
+	if len(s.schema.Required) > 0 {
+		buf.WriteString(" @required(")
+		buf.WriteString(strings.Join(s.schema.Required, ","))
+		buf.WriteByte(')')
+	}
 	buf.WriteString(" . \n")
 }
 
-func writeToFile(fpath string, ch chan []byte) error {
-	f, err := os.Create(fpath)
+// createFile creates the file at fpath, which can also be the URI of an object in a bucket.
+func createFile(fpath string) (io.WriteCloser, error) {
+	return objstore.Create(fpath)
+}
+
+// openFile opens the file at fpath, which can also be the URI of an object in a bucket. It
+// returns an error satisfying os.IsNotExist if there's no such file.
+func openFile(fpath string) (io.ReadCloser, error) {
+	r, err := objstore.Open(fpath)
+	if err == objstore.ErrNotFound {
+		return nil, &os.PathError{Op: "open", Path: fpath, Err: os.ErrNotExist}
+	}
+	return r, err
+}
+
+// mkdirAll creates the directory dir, unless it's in a bucket.
+func mkdirAll(dir string) error {
+	if objstore.IsURI(dir) {
+		return nil
+	}
+	return os.MkdirAll(dir, 0700)
+}
+
+// writeToFile writes the contents sent on ch to fpath, compressed and encrypted as configured,
+// adding the checksum of the file to sums if they're being kept.
+func writeToFile(fpath string, ch chan []byte, sums *checksums) error {
+	f, err := createFile(fpath)
 	if err != nil {
 		return err
 	}
 
 	defer f.Close()
 	x.Check(err)
-	w := bufio.NewWriterSize(f, 1000000)
-	gw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	var out io.Writer = f
+	h := sha256.New()
+	if sums != nil {
+		out = io.MultiWriter(f, h)
+	}
+	w := bufio.NewWriterSize(out, 1000000)
+	aw, err := artifact.NewWriter(w)
 	if err != nil {
 		return err
 	}
 
 	for buf := range ch {
-		if _, err := gw.Write(buf); err != nil {
+		if _, err := aw.Write(buf); err != nil {
 			return err
 		}
 	}
-	if err := gw.Flush(); err != nil {
+	if err := aw.Close(); err != nil {
 		return err
 	}
-	if err := gw.Close(); err != nil {
+	if err := w.Flush(); err != nil {
 		return err
 	}
-	return w.Flush()
+	// Closing an object in a bucket completes its upload.
+	if err := f.Close(); err != nil {
+		return err
+	}
+	sums.add(fpath, h)
+	return nil
+}
+
+// exportFormat converts posting lists and schema updates into the lines of export files.
+type exportFormat struct {
+	ext    string
+	data   func(buf *bytes.Buffer, item kv)
+	schema func(buf *bytes.Buffer, s *skv)
+}
+
+var (
+	rdfFormat  = &exportFormat{ext: "rdf", data: toRDF, schema: toSchema}
+	jsonFormat = &exportFormat{ext: "json", data: toJSON, schema: toJSONSchema}
+)
+
+// exportFormatFor returns the export format with the given name. RDF is the default.
+func exportFormatFor(name string) (*exportFormat, error) {
+	switch name {
+	case "", "rdf":
+		return rdfFormat, nil
+	case "json":
+		return jsonFormat, nil
+	}
+	return nil, x.Errorf("Invalid export format: %q. Use rdf or json", name)
 }
 
-// Export creates a export of data by exporting it as an RDF gzip.
-func export(gid uint32, bdir string) error {
-	// Use a goroutine to write to file.
-	err := os.MkdirAll(bdir, 0700)
+// Export creates a export of data by exporting it as compressed RDF or JSON, or as Parquet files,
+// with the format and filters given by req.
+func export(gid uint32, bdir string, req *protos.ExportPayload) error {
+	filter, err := newExportFilter(req)
 	if err != nil {
 		return err
 	}
-	fpath := path.Join(bdir, fmt.Sprintf("dgraph-%d-%s.rdf.gz", gid,
-		time.Now().Format("2006-01-02-15-04")))
-	fspath := path.Join(bdir, fmt.Sprintf("dgraph-schema-%d-%s.rdf.gz", gid,
-		time.Now().Format("2006-01-02-15-04")))
+	if err = mkdirAll(bdir); err != nil {
+		return err
+	}
+	if req.Format == parquetFormat {
+		dir := objstore.Join(bdir, fmt.Sprintf("dgraph-%d-%s.parquet", gid,
+			time.Now().Format("2006-01-02-15-04")))
+		fspath := objstore.Join(bdir, fmt.Sprintf("dgraph-schema-%d-%s.json%s", gid,
+			time.Now().Format("2006-01-02-15-04"), artifact.Ext()))
+		x.Printf("Exporting to: %v, schema at %v\n", dir, fspath)
+		return exportParquet(gid, dir, fspath, filter)
+	}
+	f, err := exportFormatFor(req.Format)
+	if err != nil {
+		return err
+	}
+	fpath := objstore.Join(bdir, fmt.Sprintf("dgraph-%d-%s.%s%s", gid,
+		time.Now().Format("2006-01-02-15-04"), f.ext, artifact.Ext()))
+	fspath := objstore.Join(bdir, fmt.Sprintf("dgraph-schema-%d-%s.%s%s", gid,
+		time.Now().Format("2006-01-02-15-04"), f.ext, artifact.Ext()))
+	var gpath string
+	if req.GeoJson {
+		gpath = objstore.Join(bdir, fmt.Sprintf("dgraph-%d-%s.geojson%s", gid,
+			time.Now().Format("2006-01-02-15-04"), artifact.Ext()))
+		x.Printf("Exporting geo values to: %v\n", gpath)
+	}
 	x.Printf("Exporting to: %v, schema at %v\n", fpath, fspath)
+	return exportTo(gid, fpath, fspath, gpath, f, filter, nil)
+}
+
+// exportTo writes the data of group gid picked by filter to fpath, and its schema to fspath, in
+// format f. If gpath isn't empty, the geo values are also written to it as GeoJSON. If d isn't
+// nil, it's given all the data keys of the group, and only posting lists written after d.since
+// are written.
+func exportTo(gid uint32, fpath, fspath, gpath string, f *exportFormat, filter *exportFilter,
+	d *delta) error {
+	var sums *checksums
+	if d != nil {
+		sums = d.sums
+	}
 	chb := make(chan []byte, 1000)
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3)
 	go func() {
-		errChan <- writeToFile(fpath, chb)
+		errChan <- writeToFile(fpath, chb, sums)
 	}()
 	chsb := make(chan []byte, 1000)
 	go func() {
-		errChan <- writeToFile(fspath, chsb)
+		errChan <- writeToFile(fspath, chsb, sums)
 	}()
+	var chgb chan []byte
+	if gpath != "" {
+		chgb = make(chan []byte, 1000)
+		chg := make(chan []byte, 1)
+		go geoJSONCollection(chgb, chg)
+		go func() {
+			errChan <- writeToFile(gpath, chg, sums)
+		}()
+	}
 
-	// Use a bunch of goroutines to convert to RDF format.
+	// Use a bunch of goroutines to convert to RDF or JSON.
 	chkv := make(chan kv, 1000)
 	var wg sync.WaitGroup
 	wg.Add(numExportRoutines)
@@ -224,20 +371,35 @@ func export(gid uint32, bdir string) error {
 		go func(i int) {
 			buf := new(bytes.Buffer)
 			buf.Grow(50000)
+			gbuf := new(bytes.Buffer)
 			for item := range chkv {
-				toRDF(buf, item)
+				f.data(buf, item)
 				if buf.Len() >= 40000 {
 					tmp := make([]byte, buf.Len())
 					copy(tmp, buf.Bytes())
 					chb <- tmp
 					buf.Reset()
 				}
+				if chgb != nil {
+					toGeoJSON(gbuf, item)
+					if gbuf.Len() >= 40000 {
+						tmp := make([]byte, gbuf.Len())
+						copy(tmp, gbuf.Bytes())
+						chgb <- tmp
+						gbuf.Reset()
+					}
+				}
 			}
 			if buf.Len() > 0 {
 				tmp := make([]byte, buf.Len())
 				copy(tmp, buf.Bytes())
 				chb <- tmp
 			}
+			if gbuf.Len() > 0 {
+				tmp := make([]byte, gbuf.Len())
+				copy(tmp, gbuf.Bytes())
+				chgb <- tmp
+			}
 			wg.Done()
 		}(i)
 	}
@@ -249,7 +411,7 @@ func export(gid uint32, bdir string) error {
 		buf := new(bytes.Buffer)
 		buf.Grow(50000)
 		for item := range chs {
-			toSchema(buf, item)
+			f.schema(buf, item)
 			if buf.Len() >= 40000 {
 				tmp := make([]byte, buf.Len())
 				copy(tmp, buf.Bytes())
@@ -265,36 +427,107 @@ func export(gid uint32, bdir string) error {
 		wg.Done()
 	}()
 
-	// Iterate over rocksdb.
+	err := walkGroup(gid, filter, d, nil, func(key []byte, item *kv, s *skv) error {
+		if item != nil {
+			chkv <- *item
+		} else {
+			chs <- s
+		}
+		return nil
+	})
+	x.Check(err)
+
+	close(chkv) // We have stopped output to chkv.
+	close(chs)  // we have stopped output to chs (schema)
+	wg.Wait()   // Wait for numExportRoutines to finish.
+	close(chb)  // We have stopped output to chb.
+	close(chsb) // we have stopped output to chs (schema)
+	writers := 2
+	if chgb != nil {
+		close(chgb)
+		writers++
+	}
+
+	for i := 0; i < writers; i++ {
+		if err2 := <-errChan; err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// exportedType returns the declaration of a type stored as val, with the fields picked by filter,
+// if it's exported with group gid.
+func exportedType(gid uint32, filter *exportFilter, val []byte) *skv {
+	var typ protos.TypeUpdate
+	x.Check(typ.Unmarshal(val))
+	if group.BelongsTo(x.TypesAttr) != gid || !filter.keepPredicate(typ.TypeName) {
+		return nil
+	}
+	exported := &protos.TypeUpdate{TypeName: filter.exportedName(typ.TypeName)}
+	for _, f := range typ.Fields {
+		if filter.keepPredicate(f) {
+			exported.Fields = append(exported.Fields, filter.exportedName(f))
+		}
+	}
+	if len(exported.Fields) == 0 {
+		return nil
+	}
+	return &skv{attr: x.TypesAttr, name: exported.TypeName, typ: exported}
+}
+
+// walkGroup calls fn with the posting lists and schema of group gid picked by filter, in the order
+// of their keys, starting after the key after if it's not nil. If d isn't nil, it's given all the
+// data keys of the group, and only posting lists written after d.since are passed to fn. It stops
+// at the first error returned by fn.
+func walkGroup(gid uint32, filter *exportFilter, d *delta, after []byte,
+	fn func(key []byte, item *kv, s *skv) error) error {
 	it := pstore.NewIterator(badger.DefaultIteratorOptions)
 	defer it.Close()
 	var lastPred string
 	prefix := new(bytes.Buffer)
 	prefix.Grow(100)
-	var debugCount int
-	for it.Rewind(); it.Valid(); debugCount++ {
+	if after == nil {
+		it.Rewind()
+	} else {
+		it.Seek(after)
+		if it.Valid() && bytes.Equal(it.Item().Key(), after) {
+			it.Next()
+		}
+	}
+	for it.Valid() {
 		item := it.Item()
 		key := item.Key()
 		pk := x.Parse(key)
 
-		if pk.IsIndex() || pk.IsReverse() || pk.IsCount() {
-			// Seek to the end of index, reverse and count keys.
+		if pk.IsIndex() || pk.IsReverse() || pk.IsCount() || pk.IsBlob() || pk.IsVersion() {
+			// Seek to the end of index, reverse, count, blob and version keys.
 			it.Seek(pk.SkipRangeOfSameType())
 			continue
 		}
 		if pk.Attr == "_uid_" || pk.Attr == "_predicate_" ||
-			pk.Attr == "_lease_" {
+			pk.Attr == "_lease_" || pk.Attr == MigrationsPredicate {
 			// Skip the UID mappings.
 			it.Seek(pk.SkipPredicate())
 			continue
 		}
+		if pk.IsTypeDef() {
+			if s := exportedType(gid, filter, item.Value()); s != nil {
+				if err := fn(key, nil, s); err != nil {
+					return err
+				}
+			}
+			it.Next()
+			continue
+		}
 		if pk.IsSchema() {
-			if group.BelongsTo(pk.Attr) == gid {
+			if group.BelongsTo(pk.Attr) == gid && filter.keepPredicate(pk.Attr) {
 				s := &protos.SchemaUpdate{}
 				x.Check(s.Unmarshal(item.Value()))
-				chs <- &skv{
-					attr:   pk.Attr,
-					schema: s,
+				err := fn(key, nil, &skv{attr: pk.Attr, name: filter.exportedName(pk.Attr),
+					schema: filter.redactedSchema(pk.Attr, s)})
+				if err != nil {
+					return err
 				}
 			}
 			// skip predicate
@@ -303,40 +536,54 @@ func export(gid uint32, bdir string) error {
 		}
 		x.AssertTrue(pk.IsData())
 		pred, uid := pk.Attr, pk.Uid
-		if pred != lastPred && group.BelongsTo(pred) != gid {
+		if pred != lastPred && (group.BelongsTo(pred) != gid || !filter.keepPredicate(pred)) {
 			it.Seek(pk.SkipPredicate())
 			continue
 		}
+		lastPred = pred
+		if d != nil {
+			d.visit(key, item.Counter())
+			if item.Counter() <= d.since {
+				// Unchanged since the last backup.
+				it.Next()
+				continue
+			}
+		}
+		if !filter.keepUid(uid) {
+			it.Next()
+			continue
+		}
 
+		prefix.Reset()
 		prefix.WriteString("<_:uid")
 		prefix.WriteString(strconv.FormatUint(uid, 16))
+		name := filter.exportedName(pred)
 		prefix.WriteString("> <")
-		prefix.WriteString(pred)
+		prefix.WriteString(name)
 		prefix.WriteString("> ")
 		pl := &protos.PostingList{}
 		posting.UnmarshalWithCopy(item.Value(), item.UserMeta(), pl)
-		chkv <- kv{
+		posting.ReadBlobs(key, pl)
+		err := fn(key, &kv{
 			prefix: prefix.String(),
+			attr:   pred,
+			name:   name,
+			uid:    uid,
 			list:   pl,
+			filter: filter,
+			redact: filter.redactRuleFor(pred),
+		}, nil)
+		if err != nil {
+			return err
 		}
-		prefix.Reset()
-		lastPred = pred
 		it.Next()
 	}
-
-	close(chkv) // We have stopped output to chkv.
-	close(chs)  // we have stopped output to chs (schema)
-	wg.Wait()   // Wait for numExportRoutines to finish.
-	close(chb)  // We have stopped output to chb.
-	close(chsb) // we have stopped output to chs (schema)
-
-	err = <-errChan
-	err = <-errChan
-	return err
+	return nil
 }
 
 // TODO: How do we want to handle export for group, do we pause mutations, sync all and then export ?
-func handleExportForGroup(ctx context.Context, reqId uint64, gid uint32) *protos.ExportPayload {
+func handleExportForGroup(ctx context.Context, req *protos.ExportPayload) *protos.ExportPayload {
+	reqId, gid := req.ReqId, req.GroupId
 	n := groups().Node(gid)
 	if n != nil && n.AmLeader() {
 		lastIndex, _ := n.store.LastIndex()
@@ -344,7 +591,13 @@ func handleExportForGroup(ctx context.Context, reqId uint64, gid uint32) *protos
 		if tr, ok := trace.FromContext(ctx); ok {
 			tr.LazyPrintf("Leader of group: %d. Running export.", gid)
 		}
-		if err := export(gid, Config.ExportPath); err != nil {
+		var err error
+		if req.Backup {
+			err = backup(n, Config.BackupPath, req.FullBackup, lastIndex)
+		} else {
+			err = export(gid, Config.ExportPath, req)
+		}
+		if err != nil {
 			if tr, ok := trace.FromContext(ctx); ok {
 				tr.LazyPrintf(err.Error())
 			}
@@ -408,11 +661,7 @@ func handleExportForGroup(ctx context.Context, reqId uint64, gid uint32) *protos
 	defer pools().release(pl)
 
 	c := protos.NewWorkerClient(conn)
-	nr := &protos.ExportPayload{
-		ReqId:   reqId,
-		GroupId: gid,
-	}
-	nrep, err := c.Export(ctx, nr)
+	nrep, err := c.Export(ctx, req)
 	if err != nil {
 		if tr, ok := trace.FromContext(ctx); ok {
 			tr.LazyPrintf(err.Error())
@@ -443,7 +692,7 @@ func (w *grpcWorker) Export(ctx context.Context, req *protos.ExportPayload) (*pr
 
 	chb := make(chan *protos.ExportPayload, 1)
 	go func() {
-		chb <- handleExportForGroup(ctx, req.ReqId, req.GroupId)
+		chb <- handleExportForGroup(ctx, req)
 	}()
 
 	select {
@@ -454,7 +703,32 @@ func (w *grpcWorker) Export(ctx context.Context, req *protos.ExportPayload) (*pr
 	}
 }
 
-func ExportOverNetwork(ctx context.Context) error {
+// ExportOverNetwork exports all the groups of the cluster into Config.ExportPath of the servers
+// exporting them, in the format and with the filters set in req.
+func ExportOverNetwork(ctx context.Context, req *protos.ExportPayload) error {
+	if req.Format == parquetFormat {
+		if artifact.Encrypted() {
+			return x.Errorf("Parquet exports can't be encrypted")
+		}
+		if req.GeoJson {
+			return x.Errorf("Parquet exports have geo values as GeoJSON already")
+		}
+	} else if _, err := exportFormatFor(req.Format); err != nil {
+		return err
+	}
+	if _, err := newExportFilter(req); err != nil {
+		return err
+	}
+	return exportOverNetwork(ctx, req)
+}
+
+// BackupOverNetwork backs up all the groups of the cluster into Config.BackupPath of the servers
+// backing them up. Unless full is set, the backup only has what changed since the last backup.
+func BackupOverNetwork(ctx context.Context, full bool) error {
+	return exportOverNetwork(ctx, &protos.ExportPayload{Backup: true, FullBackup: full})
+}
+
+func exportOverNetwork(ctx context.Context, payload *protos.ExportPayload) error {
 	// If we haven't even had a single membership update, don't run export.
 	if err := x.HealthCheck(); err != nil {
 		if tr, ok := trace.FromContext(ctx); ok {
@@ -472,16 +746,28 @@ func ExportOverNetwork(ctx context.Context) error {
 		}
 	}
 
+	progress := progressFrom(ctx)
+	progress.AddTasks(len(gids))
+	progress.SetPhase("exporting")
 	ch := make(chan *protos.ExportPayload, len(gids))
 	for _, gid := range gids {
 		go func(group uint32) {
-			reqId := uint64(rand.Int63())
-			ch <- handleExportForGroup(ctx, reqId, group)
+			req := *payload
+			req.ReqId = uint64(rand.Int63())
+			req.GroupId = group
+			ch <- handleExportForGroup(ctx, &req)
 		}(gid)
 	}
 
 	for i := 0; i < len(gids); i++ {
-		bp := <-ch
+		var bp *protos.ExportPayload
+		select {
+		case bp = <-ch:
+		case <-ctx.Done():
+			// The groups still exporting go on, but the export is reported as failed.
+			return ctx.Err()
+		}
+		progress.TaskDone(0)
 		if bp.Status != protos.ExportPayload_SUCCESS {
 			if tr, ok := trace.FromContext(ctx); ok {
 				tr.LazyPrintf("Export status: %v for group id: %d", bp.Status, bp.GroupId)
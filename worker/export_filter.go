@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"path"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// exportFilter picks the parts of the data of a group that go into an export. Predicates are
+// matched against shell patterns, like name or address.*. Those of an export of a namespace are
+// matched, and exported, under their names in the namespace.
+type exportFilter struct {
+	namespace string
+	include   []string
+	exclude   []string
+	// If not nil, only the nodes in uids are exported, along with the edges between them.
+	uids *protos.List
+	// The rules of --export_redact.
+	redact []*redactRule
+}
+
+// newExportFilter returns the filter for the export requested by req, or nil if the whole group
+// is exported as it is.
+func newExportFilter(req *protos.ExportPayload) (*exportFilter, error) {
+	rules, err := loadRedactRules()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Include) == 0 && len(req.Exclude) == 0 && req.Uids == nil && req.Namespace == "" &&
+		len(rules) == 0 {
+		return nil, nil
+	}
+	for _, pat := range append(req.Include, req.Exclude...) {
+		if _, err := path.Match(pat, ""); err != nil {
+			return nil, x.Errorf("Invalid predicate pattern: %q", pat)
+		}
+	}
+	return &exportFilter{namespace: req.Namespace, include: req.Include, exclude: req.Exclude,
+		uids: req.Uids, redact: rules}, nil
+}
+
+// newBackupFilter returns the filter for backups, which are only redacted with
+// Config.RedactBackups set.
+func newBackupFilter() (*exportFilter, error) {
+	if !Config.RedactBackups {
+		return nil, nil
+	}
+	return newExportFilter(&protos.ExportPayload{})
+}
+
+func matchAny(patterns []string, attr string) bool {
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, attr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *exportFilter) keepPredicate(attr string) bool {
+	if f == nil {
+		return true
+	}
+	if f.namespace != "" {
+		ns, name := x.ParseNamespacedAttr(attr)
+		if ns != f.namespace {
+			return false
+		}
+		attr = name
+	}
+	if len(f.include) > 0 && !matchAny(f.include, attr) {
+		return false
+	}
+	if r := f.redactRuleFor(attr); r != nil && r.action == redactDrop {
+		return false
+	}
+	return !matchAny(f.exclude, attr)
+}
+
+// exportedName returns the name the predicate attr is exported under.
+func (f *exportFilter) exportedName(attr string) string {
+	if f == nil || f.namespace == "" {
+		return attr
+	}
+	_, name := x.ParseNamespacedAttr(attr)
+	return name
+}
+
+func (f *exportFilter) keepUid(uid uint64) bool {
+	return f == nil || f.uids == nil || algo.IndexOf(f.uids, uid) >= 0
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Exports with geo_json set also write the geo values of a group as a GeoJSON FeatureCollection,
+// which GIS tools read as it is. Every value is a feature, like
+//   {"type":"Feature","id":"0x1","geometry":{"type":"Point","coordinates":[-122.08,37.42]},
+//    "properties":{"uid":"0x1","predicate":"loc"}}
+// with the lang, label and facets of the posting in its properties too, if it has them.
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Geometry   json.RawMessage   `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONProperties struct {
+	Uid       string                 `json:"uid"`
+	Predicate string                 `json:"predicate"`
+	Lang      string                 `json:"lang,omitempty"`
+	Label     string                 `json:"label,omitempty"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+// toGeoJSON writes the features of the geo values of item, each preceded by a comma.
+func toGeoJSON(buf *bytes.Buffer, item kv) {
+	var pitr posting.PIterator
+	pitr.Init(item.list, 0)
+	uid := hexUid(item.uid)
+	for ; pitr.Valid(); pitr.Next() {
+		p := pitr.Posting()
+		if types.TypeID(p.ValType) != types.GeoID || bytes.Equal(p.Value, nil) {
+			continue
+		}
+		p = item.redact.apply(p)
+		if types.TypeID(p.ValType) != types.GeoID {
+			// Redacted into a string.
+			continue
+		}
+		g, _ := jsonValue(p)
+		f := geoJSONFeature{
+			Type:       "Feature",
+			ID:         uid,
+			Geometry:   g.(json.RawMessage),
+			Properties: geoJSONProperties{Uid: uid, Predicate: item.name, Label: p.Label},
+		}
+		if p.PostingType == protos.Posting_VALUE_LANG {
+			f.Properties.Lang = string(p.Metadata)
+		}
+		if len(p.Facets) > 0 {
+			f.Properties.Facets = make(map[string]interface{}, len(p.Facets))
+			for _, fc := range p.Facets {
+				f.Properties.Facets[fc.Key] = facets.ValFor(fc).Value
+			}
+		}
+		data, err := json.Marshal(f)
+		x.Check(err)
+		buf.WriteString(",\n")
+		buf.Write(data)
+	}
+}
+
+// geoJSONCollection sends the chunks of features received on in to out, inside a
+// FeatureCollection, and closes out once in is closed.
+func geoJSONCollection(in <-chan []byte, out chan<- []byte) {
+	out <- []byte(`{"type":"FeatureCollection","features":[`)
+	first := true
+	for b := range in {
+		if first {
+			// Only the features after the first one need the comma before them.
+			b, first = b[1:], false
+		}
+		out <- b
+	}
+	out <- []byte("\n]}\n")
+	close(out)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// JSON exports have a document per line for every posting, like
+//   {"uid":"0x1","predicate":"friend","object":"0x5"}
+//   {"uid":"0x1","predicate":"name","value":"Alice","type":"string","lang":"en"}
+// and a document per line for the schema of every predicate.
+
+type jsonPosting struct {
+	Uid       string                 `json:"uid"`
+	Predicate string                 `json:"predicate"`
+	Object    string                 `json:"object,omitempty"`
+	Value     interface{}            `json:"value,omitempty"`
+	Type      string                 `json:"type,omitempty"`
+	Lang      string                 `json:"lang,omitempty"`
+	Label     string                 `json:"label,omitempty"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+type jsonSchema struct {
+	Predicate string   `json:"predicate"`
+	Type      string   `json:"type"`
+	List      bool     `json:"list,omitempty"`
+	Index     bool     `json:"index,omitempty"`
+	Tokenizer []string `json:"tokenizer,omitempty"`
+	Reverse   bool     `json:"reverse,omitempty"`
+	Count     bool     `json:"count,omitempty"`
+}
+
+func hexUid(uid uint64) string {
+	return "0x" + strconv.FormatUint(uid, 16)
+}
+
+// jsonValue returns the value of posting p as a native JSON value where there's one, and its
+// type.
+func jsonValue(p *protos.Posting) (interface{}, string) {
+	vID := types.TypeID(p.ValType)
+	src := types.ValueForType(vID)
+	src.Value = p.Value
+	switch vID {
+	case types.IntID, types.FloatID, types.BoolID:
+		v, err := types.Convert(src, vID)
+		x.Check(err)
+		return v.Value, vID.Name()
+	case types.GeoID:
+		// Geo values converted to strings have single quotes, which isn't JSON.
+		g, err := types.UnmarshalWKB(p.Value)
+		x.Check(err)
+		data, err := types.MarshalGeoJSON(g)
+		x.Check(err)
+		return json.RawMessage(data), vID.Name()
+	}
+	str, err := types.Convert(src, types.StringID)
+	x.Check(err)
+	return str.Value.(string), vID.Name()
+}
+
+func toJSON(buf *bytes.Buffer, item kv) {
+	var pitr posting.PIterator
+	pitr.Init(item.list, 0)
+	uid := hexUid(item.uid)
+	for ; pitr.Valid(); pitr.Next() {
+		p := pitr.Posting()
+		if !keepPosting(item, p) {
+			continue
+		}
+		p = item.redact.apply(p)
+		jp := jsonPosting{Uid: uid, Predicate: item.name, Label: p.Label}
+		if !bytes.Equal(p.Value, nil) {
+			jp.Value, jp.Type = jsonValue(p)
+			if p.PostingType == protos.Posting_VALUE_LANG {
+				jp.Lang = string(p.Metadata)
+			}
+		} else {
+			jp.Object = hexUid(p.Uid)
+		}
+		if len(p.Facets) > 0 {
+			jp.Facets = make(map[string]interface{}, len(p.Facets))
+			for _, f := range p.Facets {
+				jp.Facets[f.Key] = facets.ValFor(f).Value
+			}
+		}
+		data, err := json.Marshal(jp)
+		x.Check(err)
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+}
+
+func toJSONSchema(buf *bytes.Buffer, s *skv) {
+	if s.typ != nil {
+		data, err := json.Marshal(s.typ)
+		x.Check(err)
+		buf.Write(data)
+		buf.WriteByte('\n')
+		return
+	}
+	js := jsonSchema{
+		Predicate: s.name,
+		Type:      types.TypeID(s.schema.ValueType).Name(),
+		List:      s.schema.List || schema.State().IsList(s.attr),
+		Count:     s.schema.Count,
+	}
+	switch s.schema.Directive {
+	case protos.SchemaUpdate_REVERSE:
+		js.Reverse = true
+	case protos.SchemaUpdate_INDEX:
+		js.Index = len(s.schema.Tokenizer) > 0
+		js.Tokenizer = s.schema.Tokenizer
+	}
+	data, err := json.Marshal(js)
+	x.Check(err)
+	buf.Write(data)
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/dgraph-io/dgraph/artifact"
+	"github.com/dgraph-io/dgraph/objstore"
+	"github.com/dgraph-io/dgraph/parquet"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Parquet exports have a folder for each predicate, with files of up to parquetFileRows rows, so
+// that analytics engines like Spark and Presto read each predicate as a table, and split it
+// between their workers. Each posting is a row, with the uid of its node, its value in a column of
+// the type of the predicate, or the uid it points to, its language, its facets as JSON, and the
+// time the group was exported as commit_ts, as the time postings were committed isn't kept.
+
+const (
+	parquetFormat   = "parquet"
+	parquetRowGroup = 1 << 16
+	parquetFileRows = 1 << 20
+)
+
+// parquetColumns returns the columns of the files of a predicate of type typ.
+func parquetColumns(typ types.TypeID) []parquet.Column {
+	val := parquet.Column{Name: "value", Type: parquet.ByteArray, Logical: parquet.UTF8,
+		Optional: true}
+	switch typ {
+	case types.UidID:
+		val = parquet.Column{Name: "object", Type: parquet.Int64, Optional: true}
+	case types.IntID:
+		val.Type, val.Logical = parquet.Int64, parquet.None
+	case types.FloatID:
+		val.Type, val.Logical = parquet.Double, parquet.None
+	case types.BoolID:
+		val.Type, val.Logical = parquet.Boolean, parquet.None
+	case types.DateTimeID:
+		val.Type, val.Logical = parquet.Int64, parquet.TimestampMillis
+	case types.BinaryID:
+		val.Logical = parquet.None
+	case types.GeoID:
+		val.Logical = parquet.JSON
+	}
+	return []parquet.Column{
+		{Name: "uid", Type: parquet.Int64},
+		val,
+		{Name: "lang", Type: parquet.ByteArray, Logical: parquet.UTF8, Optional: true},
+		{Name: "facets", Type: parquet.ByteArray, Logical: parquet.JSON, Optional: true},
+		{Name: "commit_ts", Type: parquet.Int64, Logical: parquet.TimestampMillis},
+	}
+}
+
+// parquetValue returns the value of posting p in the value column of type typ, or nil if it
+// can't be converted to it.
+func parquetValue(p *protos.Posting, typ types.TypeID) interface{} {
+	if typ == types.UidID {
+		if !bytes.Equal(p.Value, nil) {
+			return nil
+		}
+		return int64(p.Uid)
+	}
+	if bytes.Equal(p.Value, nil) {
+		return nil
+	}
+	src := types.ValueForType(types.TypeID(p.ValType))
+	src.Value = p.Value
+	switch typ {
+	case types.IntID, types.FloatID, types.BoolID, types.BinaryID:
+		v, err := types.Convert(src, typ)
+		if err != nil {
+			return nil
+		}
+		return v.Value
+	case types.DateTimeID:
+		v, err := types.Convert(src, typ)
+		if err != nil {
+			return nil
+		}
+		return v.Value.(time.Time).UnixNano() / int64(time.Millisecond)
+	case types.GeoID:
+		if types.TypeID(p.ValType) != types.GeoID {
+			return nil
+		}
+		// Geo values converted to strings have single quotes, which isn't JSON.
+		g, err := types.UnmarshalWKB(p.Value)
+		if err != nil {
+			return nil
+		}
+		data, err := types.MarshalGeoJSON(g)
+		if err != nil {
+			return nil
+		}
+		return string(data)
+	}
+	v, err := types.Convert(src, types.StringID)
+	if err != nil {
+		return nil
+	}
+	return v.Value.(string)
+}
+
+// parquetPredicate writes the postings of a predicate to its folder.
+type parquetPredicate struct {
+	dir     string
+	attr    string
+	typ     types.TypeID
+	columns []parquet.Column
+	ts      int64
+	part    int
+
+	f    io.WriteCloser
+	b    *bufio.Writer
+	w    *parquet.Writer
+	rows int
+}
+
+func newParquetPredicate(dir string, item *kv, ts time.Time) (*parquetPredicate, error) {
+	typ, err := schema.State().TypeOf(item.attr)
+	if err != nil {
+		// Without a schema, the type is that of the first posting.
+		var pitr posting.PIterator
+		pitr.Init(item.list, 0)
+		if !pitr.Valid() {
+			return nil, err
+		}
+		typ = types.UidID
+		if p := pitr.Posting(); !bytes.Equal(p.Value, nil) {
+			typ = types.TypeID(p.ValType)
+		}
+	}
+	typ = types.TypeID(item.filter.redactedSchema(item.attr,
+		&protos.SchemaUpdate{ValueType: uint32(typ)}).ValueType)
+	pp := &parquetPredicate{
+		dir:     objstore.Join(dir, url.PathEscape(item.name)),
+		attr:    item.attr,
+		typ:     typ,
+		columns: parquetColumns(typ),
+		ts:      ts.UnixNano() / int64(time.Millisecond),
+	}
+	return pp, mkdirAll(pp.dir)
+}
+
+func (pp *parquetPredicate) add(item *kv) error {
+	var pitr posting.PIterator
+	pitr.Init(item.list, 0)
+	for ; pitr.Valid(); pitr.Next() {
+		p := pitr.Posting()
+		if !keepPosting(*item, p) {
+			continue
+		}
+		p = item.redact.apply(p)
+		row := []interface{}{int64(item.uid), parquetValue(p, pp.typ), nil, nil, pp.ts}
+		if p.PostingType == protos.Posting_VALUE_LANG {
+			row[2] = string(p.Metadata)
+		}
+		if len(p.Facets) > 0 {
+			fs := make(map[string]interface{}, len(p.Facets))
+			for _, f := range p.Facets {
+				fs[f.Key] = facets.ValFor(f).Value
+			}
+			data, err := json.Marshal(fs)
+			if err != nil {
+				return err
+			}
+			row[3] = data
+		}
+		if err := pp.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pp *parquetPredicate) write(row []interface{}) error {
+	if pp.w == nil {
+		fpath := objstore.Join(pp.dir, fmt.Sprintf("part-%05d.parquet", pp.part))
+		f, err := createFile(fpath)
+		if err != nil {
+			return err
+		}
+		pp.f, pp.b = f, bufio.NewWriterSize(f, 1000000)
+		pp.w = parquet.NewWriter(pp.b, pp.columns, parquetRowGroup)
+		pp.part++
+	}
+	if err := pp.w.Write(row); err != nil {
+		return err
+	}
+	if pp.rows++; pp.rows >= parquetFileRows {
+		return pp.close()
+	}
+	return nil
+}
+
+// close completes the file being written, if any.
+func (pp *parquetPredicate) close() error {
+	if pp.w == nil {
+		return nil
+	}
+	err := pp.w.Close()
+	if err == nil {
+		err = pp.b.Flush()
+	}
+	// Closing an object in a bucket completes its upload.
+	if err2 := pp.f.Close(); err == nil {
+		err = err2
+	}
+	pp.f, pp.b, pp.w, pp.rows = nil, nil, nil, 0
+	return err
+}
+
+// exportParquet writes the data of group gid picked by filter to the folder dir, as Parquet
+// files, and its schema to fspath, as JSON.
+func exportParquet(gid uint32, dir, fspath string, filter *exportFilter) error {
+	if artifact.Encrypted() {
+		return x.Errorf("Parquet exports can't be encrypted")
+	}
+	if err := mkdirAll(dir); err != nil {
+		return err
+	}
+	ts := time.Now()
+	var pp *parquetPredicate
+	var sbuf bytes.Buffer
+	err := walkGroup(gid, filter, nil, nil, func(key []byte, item *kv, s *skv) error {
+		if s != nil {
+			toJSONSchema(&sbuf, s)
+			return nil
+		}
+		if pp == nil || pp.attr != item.attr {
+			if pp != nil {
+				if err := pp.close(); err != nil {
+					return err
+				}
+			}
+			var err error
+			if pp, err = newParquetPredicate(dir, item, ts); err != nil {
+				return err
+			}
+		}
+		return pp.add(item)
+	})
+	if pp != nil {
+		if err2 := pp.close(); err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		return err
+	}
+	ch := make(chan []byte, 1)
+	ch <- sbuf.Bytes()
+	close(ch)
+	return writeToFile(fspath, ch, nil)
+}
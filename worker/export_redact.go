@@ -0,0 +1,242 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The values of some predicates can be redacted in exports, to share them without the personal
+// data they hold. --export_redact takes comma separated rules, like
+//   email:hash,name:mask,location:geo(10),ssn:drop
+// each a predicate pattern and what's done to its values:
+//   hash    replaces them with the hex of their HMAC-SHA256 by --export_redact_key, or their
+//           SHA-256 without a key, which keeps equal values equal.
+//   mask    keeps their first character, and the domain of emails, and stars the rest.
+//   geo(n)  replaces geo values with the center of the S2 cell of level n they're in.
+//   drop    leaves the predicate out, with its schema.
+// The first rule a predicate matches applies. Edges are only affected by drop.
+
+type redactAction int
+
+const (
+	redactDrop redactAction = iota
+	redactHash
+	redactMask
+	redactGeo
+)
+
+// defaultRedactLevel is the level of the cells of geo rules without one, about 10km wide.
+const defaultRedactLevel = 10
+
+type redactRule struct {
+	pattern string
+	action  redactAction
+	level   int    // Level of the S2 cells of geo rules.
+	key     []byte // Key of the HMAC of hash rules, if any.
+}
+
+// parseRedactRules parses the rules of --export_redact.
+func parseRedactRules(s string) ([]*redactRule, error) {
+	var rules []*redactRule
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.LastIndex(entry, ":")
+		if idx <= 0 {
+			return nil, x.Errorf("Invalid redaction rule: %q. Expected <pattern>:<action>", entry)
+		}
+		r := &redactRule{pattern: entry[:idx]}
+		if _, err := path.Match(r.pattern, ""); err != nil {
+			return nil, x.Errorf("Invalid predicate pattern: %q", r.pattern)
+		}
+		switch action := entry[idx+1:]; {
+		case action == "drop":
+			r.action = redactDrop
+		case action == "hash":
+			r.action = redactHash
+		case action == "mask":
+			r.action = redactMask
+		case action == "geo":
+			r.action, r.level = redactGeo, defaultRedactLevel
+		case strings.HasPrefix(action, "geo(") && strings.HasSuffix(action, ")"):
+			level, err := strconv.Atoi(action[len("geo(") : len(action)-1])
+			if err != nil || level < 0 || level > 30 {
+				return nil, x.Errorf("Invalid level of S2 cells in redaction rule: %q. "+
+					"Expected 0 to 30", entry)
+			}
+			r.action, r.level = redactGeo, level
+		default:
+			return nil, x.Errorf("Invalid redaction action in rule: %q. Expected drop, hash, "+
+				"mask or geo(<level>)", entry)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// ValidateRedactRules checks the rules of --export_redact.
+func ValidateRedactRules(s string) error {
+	_, err := parseRedactRules(s)
+	return err
+}
+
+// loadRedactRules returns the rules of Config.ExportRedact, with the key of
+// Config.ExportRedactKey.
+func loadRedactRules() ([]*redactRule, error) {
+	rules, err := parseRedactRules(Config.ExportRedact)
+	if err != nil || len(rules) == 0 || Config.ExportRedactKey == "" {
+		return rules, err
+	}
+	b, err := ioutil.ReadFile(Config.ExportRedactKey)
+	if err != nil {
+		return nil, x.Wrapf(err, "While reading the redaction key")
+	}
+	key := bytes.TrimSpace(b)
+	if len(key) == 0 {
+		return nil, x.Errorf("Empty redaction key in %v", Config.ExportRedactKey)
+	}
+	for _, r := range rules {
+		r.key = key
+	}
+	return rules, nil
+}
+
+// redactRuleFor returns the first rule matching the name the predicate attr is exported under,
+// or nil.
+func (f *exportFilter) redactRuleFor(attr string) *redactRule {
+	if f == nil || len(f.redact) == 0 {
+		return nil
+	}
+	name := f.exportedName(attr)
+	for _, r := range f.redact {
+		if ok, _ := path.Match(r.pattern, name); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// ExportRedacted returns whether the values of the predicate attr are changed or dropped in
+// exports, by a rule of --export_redact.
+func ExportRedacted(attr string) (bool, error) {
+	rules, err := loadRedactRules()
+	if err != nil {
+		return false, err
+	}
+	f := &exportFilter{redact: rules}
+	return f.redactRuleFor(attr) != nil, nil
+}
+
+// redactedSchema returns the schema s of the predicate attr as exported. Hashed and masked values
+// are strings, without the indexes of their former type.
+func (f *exportFilter) redactedSchema(attr string, s *protos.SchemaUpdate) *protos.SchemaUpdate {
+	r := f.redactRuleFor(attr)
+	if r == nil || (r.action != redactHash && r.action != redactMask) {
+		return s
+	}
+	vID := types.TypeID(s.ValueType)
+	if vID == types.StringID || vID == types.DefaultID || vID == types.UidID {
+		return s
+	}
+	c := *s
+	c.ValueType = uint32(types.StringID)
+	if c.Directive == protos.SchemaUpdate_INDEX {
+		c.Directive, c.Tokenizer = protos.SchemaUpdate_NONE, nil
+	}
+	return &c
+}
+
+func (r *redactRule) hash(s string) string {
+	if len(r.key) == 0 {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func mask(s string) string {
+	domain := ""
+	if idx := strings.LastIndex(s, "@"); idx > 0 {
+		s, domain = s[:idx], s[idx:]
+	}
+	var buf bytes.Buffer
+	for i, c := range []rune(s) {
+		if i == 0 {
+			buf.WriteRune(c)
+		} else {
+			buf.WriteByte('*')
+		}
+	}
+	buf.WriteString(domain)
+	return buf.String()
+}
+
+// apply returns the posting p with its value redacted by r. p itself isn't changed.
+func (r *redactRule) apply(p *protos.Posting) *protos.Posting {
+	if r == nil || bytes.Equal(p.Value, nil) {
+		return p
+	}
+	vID := types.TypeID(p.ValType)
+	src := types.ValueForType(vID)
+	src.Value = p.Value
+	c := *p
+	switch r.action {
+	case redactHash, redactMask:
+		str, err := types.Convert(src, types.StringID)
+		x.Check(err)
+		s := str.Value.(string)
+		if r.action == redactHash {
+			s = r.hash(s)
+		} else {
+			s = mask(s)
+		}
+		c.Value = []byte(s)
+		if vID != types.DefaultID {
+			c.ValType = protos.Posting_ValType(types.StringID)
+		}
+	case redactGeo:
+		if vID != types.GeoID {
+			return p
+		}
+		g, err := types.Convert(src, types.GeoID)
+		x.Check(err)
+		out := types.ValueForType(types.BinaryID)
+		pt := types.CoarsePoint(g.Value.(geom.T), r.level)
+		x.Check(types.Marshal(types.Val{Tid: types.GeoID, Value: pt}, &out))
+		c.Value = out.Value.([]byte)
+	}
+	return &c
+}
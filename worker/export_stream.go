@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/trace"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Streamed exports are sent to the client in chunks of lines in the format of an export, instead of
+// being written to files. Each chunk carries the offset of the group it's from: the last key it
+// covers. A client can resume an interrupted export by sending back the last offset it got for
+// every group, and the export continues after those keys, skipping the groups which were done.
+
+const exportChunkSize = 1 << 20
+
+// streamExport streams the export of group gid requested by req, after the key after, to send.
+func streamExport(ctx context.Context, gid uint32, req *protos.ExportRequest, after []byte,
+	send func(*protos.ExportChunk) error) error {
+	f, err := exportFormatFor(req.Format)
+	if err != nil {
+		return err
+	}
+	filter, err := newExportFilter(&protos.ExportPayload{Include: req.Include,
+		Exclude: req.Exclude})
+	if err != nil {
+		return err
+	}
+
+	var data, sch bytes.Buffer
+	last := append([]byte(nil), after...)
+	flush := func(done bool) error {
+		chunk := &protos.ExportChunk{
+			GroupId: gid,
+			Data:    append([]byte(nil), data.Bytes()...),
+			Schema:  append([]byte(nil), sch.Bytes()...),
+			Offset: &protos.ExportOffset{
+				GroupId: gid,
+				After:   append([]byte(nil), last...),
+				Done:    done,
+			},
+		}
+		data.Reset()
+		sch.Reset()
+		return send(chunk)
+	}
+	err = walkGroup(gid, filter, nil, after, func(key []byte, item *kv, s *skv) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if item != nil {
+			f.data(&data, *item)
+		} else {
+			f.schema(&sch, s)
+		}
+		last = append(last[:0], key...)
+		if data.Len()+sch.Len() >= exportChunkSize {
+			return flush(false)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush(true)
+}
+
+// StreamExport streams the export of the group given by the only offset in req.
+func (w *grpcWorker) StreamExport(req *protos.ExportRequest,
+	stream protos.Worker_StreamExportServer) error {
+	if len(req.Offsets) != 1 {
+		return x.Errorf("Expected the offset of one group, got %d", len(req.Offsets))
+	}
+	off := req.Offsets[0]
+	n := groups().Node(off.GroupId)
+	if n == nil {
+		return x.Errorf("Not serving group: %d", off.GroupId)
+	}
+	if off.After == nil && n.AmLeader() {
+		lastIndex, _ := n.store.LastIndex()
+		n.syncAllMarks(n.ctx, lastIndex)
+	}
+	return streamExport(stream.Context(), off.GroupId, req, off.After, stream.Send)
+}
+
+func relayExport(ctx context.Context, req *protos.ExportRequest, off *protos.ExportOffset,
+	send func(*protos.ExportChunk) error) error {
+	_, addr := groups().Leader(off.GroupId)
+	pl, err := pools().get(addr)
+	if err != nil {
+		return err
+	}
+	defer pools().release(pl)
+
+	c := protos.NewWorkerClient(pl.Get())
+	stream, err := c.StreamExport(ctx, &protos.ExportRequest{
+		Format:  req.Format,
+		Include: req.Include,
+		Exclude: req.Exclude,
+		Offsets: []*protos.ExportOffset{off},
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamExportOverNetwork streams the export of all the groups of the cluster to send, one group
+// after the other, resuming from the offsets in req.
+func StreamExportOverNetwork(ctx context.Context, req *protos.ExportRequest,
+	send func(*protos.ExportChunk) error) error {
+	if err := x.HealthCheck(); err != nil {
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("Request rejected %v", err)
+		}
+		return err
+	}
+	if _, err := exportFormatFor(req.Format); err != nil {
+		return err
+	}
+	offsets := make(map[uint32]*protos.ExportOffset)
+	for _, off := range req.Offsets {
+		offsets[off.GroupId] = off
+	}
+
+	gids := groups().KnownGroups()
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	for _, gid := range gids {
+		if gid == 0 {
+			continue
+		}
+		off, ok := offsets[gid]
+		if !ok {
+			off = &protos.ExportOffset{GroupId: gid}
+		}
+		if off.Done {
+			continue
+		}
+		if tr, ok := trace.FromContext(ctx); ok {
+			tr.LazyPrintf("Streaming export of group: %d", gid)
+		}
+		var err error
+		if n := groups().Node(gid); n != nil && n.AmLeader() {
+			if off.After == nil {
+				lastIndex, _ := n.store.LastIndex()
+				n.syncAllMarks(n.ctx, lastIndex)
+			}
+			err = streamExport(ctx, gid, req, off.After, send)
+		} else {
+			err = relayExport(ctx, req, off, send)
+		}
+		if err != nil {
+			return x.Wrapf(err, "While streaming export of group: %d", gid)
+		}
+	}
+	return nil
+}
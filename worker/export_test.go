@@ -21,7 +21,10 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -33,6 +36,7 @@ import (
 	"github.com/stretchr/testify/require"
 	geom "github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/wkb"
+	"golang.org/x/net/context"
 
 	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/group"
@@ -114,11 +118,11 @@ func TestExport(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// We have 4 friend type edges. FP("friends")%10 = 2.
-	err = export(group.BelongsTo("friend"), bdir)
+	err = export(group.BelongsTo("friend"), bdir, &protos.ExportPayload{})
 	require.NoError(t, err)
 
 	// We have 2 name type edges(with index). FP("name")%10 =7.
-	err = export(group.BelongsTo("name"), bdir)
+	err = export(group.BelongsTo("name"), bdir, &protos.ExportPayload{})
 	require.NoError(t, err)
 
 	searchDir := bdir
@@ -234,6 +238,348 @@ func TestExport(t *testing.T) {
 	require.Equal(t, []int{1, 0}, schemaCounts)
 }
 
+func TestExportJSON(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	req := &protos.ExportPayload{Format: "json"}
+	require.NoError(t, export(group.BelongsTo("friend"), bdir, req))
+	require.NoError(t, export(group.BelongsTo("name"), bdir, req))
+	require.Error(t, export(group.BelongsTo("name"), bdir, &protos.ExportPayload{Format: "xml"}))
+
+	files, err := filepath.Glob(filepath.Join(bdir, "dgraph-*.json.gz"))
+	require.NoError(t, err)
+	var docs []jsonPosting
+	var schemas []jsonSchema
+	for _, file := range files {
+		for _, line := range readGzLines(t, file) {
+			if strings.Contains(file, "schema") {
+				var js jsonSchema
+				require.NoError(t, json.Unmarshal([]byte(line), &js))
+				schemas = append(schemas, js)
+				continue
+			}
+			var jp jsonPosting
+			require.NoError(t, json.Unmarshal([]byte(line), &jp))
+			docs = append(docs, jp)
+		}
+	}
+
+	require.Equal(t, 6, len(docs))
+	for _, jp := range docs {
+		require.Contains(t, []string{"0x1", "0x2", "0x3", "0x4"}, jp.Uid)
+		switch jp.Predicate {
+		case "friend":
+			require.Equal(t, "0x5", jp.Object)
+			require.Nil(t, jp.Value)
+		case "name":
+			require.Equal(t, "pho\\ton", jp.Value)
+			if jp.Uid == "0x2" {
+				require.Equal(t, "en", jp.Lang)
+				require.Equal(t, "string", jp.Type)
+			}
+		default:
+			t.Fatalf("Unexpected predicate: %v", jp.Predicate)
+		}
+		if jp.Uid == "0x4" {
+			require.Equal(t, float64(33), jp.Facets["age"])
+			require.Equal(t, true, jp.Facets["close"])
+			require.Equal(t, "football", jp.Facets["game"])
+		}
+		if jp.Uid != "0x3" {
+			require.Equal(t, "author0", jp.Label)
+		}
+	}
+
+	require.Equal(t, 1, len(schemas))
+	require.Equal(t, jsonSchema{Predicate: "friend", Type: "uid"}, schemas[0])
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .\nloc:geo .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i, edge := range []string{
+		`<1> <loc> "{'type':'Point','coordinates':[-122.08,37.42]}"^^<geo:geojson> .`,
+		`<2> <loc> "{'type':'Point','coordinates':[2.35,48.85]}"^^<geo:geojson> <osm> (zoom=12) .`,
+	} {
+		nq, err := rdf.Parse(edge)
+		require.NoError(t, err)
+		e, err := gql.NQuad{&nq}.ToEdgeUsing(map[string]uint64{"1": 1, "2": 2})
+		require.NoError(t, err)
+		// Geo values are stored as WKB, once converted by the mutation.
+		p := geom.NewPoint(geom.XY).MustSetCoords([]geom.Coord{{-122.08, 37.42},
+			{2.35, 48.85}}[i])
+		e.Value, err = wkb.Marshal(p, binary.LittleEndian)
+		require.NoError(t, err)
+		addEdge(t, e, getOrCreate(x.DataKey(e.Attr, e.Entity)))
+	}
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	require.Error(t, ExportOverNetwork(context.Background(),
+		&protos.ExportPayload{Format: parquetFormat, GeoJson: true}))
+	req := &protos.ExportPayload{GeoJson: true}
+	gids := map[uint32]bool{}
+	for _, attr := range []string{"friend", "name", "loc"} {
+		gids[group.BelongsTo(attr)] = true
+	}
+	for gid := range gids {
+		require.NoError(t, export(gid, bdir, req))
+	}
+
+	files, err := filepath.Glob(filepath.Join(bdir, "dgraph-*.geojson.gz"))
+	require.NoError(t, err)
+	require.Equal(t, len(gids), len(files))
+	features := map[string]geoJSONFeature{}
+	for _, file := range files {
+		var fc struct {
+			Type     string           `json:"type"`
+			Features []geoJSONFeature `json:"features"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(strings.Join(readGzLines(t, file), "\n")), &fc))
+		require.Equal(t, "FeatureCollection", fc.Type)
+		for _, f := range fc.Features {
+			features[f.ID] = f
+		}
+	}
+	require.Equal(t, 2, len(features))
+	f := features["0x2"]
+	require.Equal(t, "Feature", f.Type)
+	require.JSONEq(t, `{"type":"Point","coordinates":[2.35,48.85]}`, string(f.Geometry))
+	require.Equal(t, geoJSONProperties{Uid: "0x2", Predicate: "loc", Label: "osm",
+		Facets: map[string]interface{}{"zoom": float64(12)}}, f.Properties)
+	require.Equal(t, "loc", features["0x1"].Properties.Predicate)
+
+	// The geo values are still in the export.
+	files, err = filepath.Glob(filepath.Join(bdir, "dgraph-[0-9]*.rdf.gz"))
+	require.NoError(t, err)
+	var geoLines int
+	for _, file := range files {
+		for _, line := range readGzLines(t, file) {
+			if strings.Contains(line, "<loc>") {
+				geoLines++
+			}
+		}
+	}
+	require.Equal(t, 2, geoLines)
+}
+
+func TestExportParquet(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	req := &protos.ExportPayload{Format: "parquet"}
+	require.NoError(t, export(group.BelongsTo("friend"), bdir, req))
+	require.NoError(t, export(group.BelongsTo("name"), bdir, req))
+
+	// Every predicate has a folder of files.
+	for _, pred := range []string{"friend", "name"} {
+		files, err := filepath.Glob(filepath.Join(bdir, "dgraph-*.parquet", pred, "*.parquet"))
+		require.NoError(t, err)
+		require.Len(t, files, 1, pred)
+		b, err := ioutil.ReadFile(files[0])
+		require.NoError(t, err)
+		require.Equal(t, "PAR1", string(b[:4]))
+		require.Equal(t, "PAR1", string(b[len(b)-4:]))
+	}
+	files, err := filepath.Glob(filepath.Join(bdir, "dgraph-schema-*.json.gz"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	require.Equal(t, int64(5), parquetValue(&protos.Posting{Uid: 5}, types.UidID))
+	require.Nil(t, parquetValue(&protos.Posting{Value: []byte("a")}, types.UidID))
+	p := &protos.Posting{Value: []byte("33"), ValType: protos.Posting_DEFAULT}
+	require.Equal(t, int64(33), parquetValue(p, types.IntID))
+	require.Equal(t, "33", parquetValue(p, types.StringID))
+	p = &protos.Posting{Value: []byte("2005-05-02T15:04:05Z"), ValType: protos.Posting_DEFAULT}
+	require.Equal(t, int64(1115046245000), parquetValue(p, types.DateTimeID))
+	require.Nil(t, parquetValue(p, types.FloatID))
+}
+
+func TestExportFilter(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	require.Error(t, export(1, bdir, &protos.ExportPayload{Include: []string{"[a"}}))
+
+	req := &protos.ExportPayload{
+		Format:  "json",
+		Include: []string{"fr*", "name"},
+		Exclude: []string{"name"},
+		Uids:    &protos.List{Uids: []uint64{1, 2, 3, 4}},
+	}
+	for _, attr := range []string{"friend", "name"} {
+		require.NoError(t, export(group.BelongsTo(attr), bdir, req))
+	}
+	files, err := filepath.Glob(filepath.Join(bdir, "dgraph-[0-9]*.json.gz"))
+	require.NoError(t, err)
+	var lines []string
+	for _, file := range files {
+		lines = append(lines, readGzLines(t, file)...)
+	}
+	// Node 5 isn't exported, so neither are the edges to it, and name is excluded.
+	require.Equal(t, 0, len(lines))
+
+	os.RemoveAll(bdir)
+	req.Uids.Uids = append(req.Uids.Uids, 5)
+	require.NoError(t, export(group.BelongsTo("friend"), bdir, req))
+	files, err = filepath.Glob(filepath.Join(bdir, "dgraph-[0-9]*.json.gz"))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(files))
+	lines = readGzLines(t, files[0])
+	require.Equal(t, 4, len(lines))
+	require.Contains(t, lines[0], `"predicate":"friend"`)
+}
+
+func TestExportRedact(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	bdir, err := ioutil.TempDir("", "export")
+	require.NoError(t, err)
+	defer os.RemoveAll(bdir)
+	defer func(c Options) { Config = c }(Config)
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	for _, rules := range []string{"name", "name:blur", "name:geo(31)", "[a:drop"} {
+		require.Error(t, ValidateRedactRules(rules), rules)
+	}
+
+	exportNames := func(rules string) []jsonPosting {
+		os.RemoveAll(bdir)
+		Config.ExportRedact = rules
+		req := &protos.ExportPayload{Format: "json"}
+		for _, attr := range []string{"friend", "name"} {
+			require.NoError(t, export(group.BelongsTo(attr), bdir, req))
+		}
+		files, err := filepath.Glob(filepath.Join(bdir, "dgraph-[0-9]*.json.gz"))
+		require.NoError(t, err)
+		var docs []jsonPosting
+		for _, file := range files {
+			for _, line := range readGzLines(t, file) {
+				var jp jsonPosting
+				require.NoError(t, json.Unmarshal([]byte(line), &jp))
+				docs = append(docs, jp)
+			}
+		}
+		return docs
+	}
+
+	// Edges are dropped, and names masked.
+	docs := exportNames("fr*:drop, name:mask")
+	require.Equal(t, 2, len(docs))
+	for _, jp := range docs {
+		require.Equal(t, "name", jp.Predicate)
+		require.Equal(t, "p******", jp.Value)
+	}
+
+	h := sha256.Sum256([]byte("pho\\ton"))
+	docs = exportNames("name:hash,name:drop")
+	require.Equal(t, 6, len(docs))
+	for _, jp := range docs {
+		if jp.Predicate == "name" {
+			require.Equal(t, hex.EncodeToString(h[:]), jp.Value)
+		}
+	}
+
+	require.Equal(t, "j***@example.com", mask("jane@example.com"))
+
+	// Geo values are moved to the center of their cell.
+	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
+	data, err := wkb.Marshal(p, binary.LittleEndian)
+	require.NoError(t, err)
+	rules, err := parseRedactRules("location:geo(12)")
+	require.NoError(t, err)
+	redacted := rules[0].apply(&protos.Posting{Value: data, ValType: protos.Posting_ValType(types.GeoID)})
+	g, err := wkb.Unmarshal(redacted.Value)
+	require.NoError(t, err)
+	require.Equal(t, types.CoarsePoint(p, 12).Coords(), g.(*geom.Point).Coords())
+}
+
+func TestStreamExport(t *testing.T) {
+	dir, ps := initTestExport(t, "name:string @index(term) .")
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+
+	for i := 1; i <= 10; i++ {
+		posting.CommitLists(10, uint32(i))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	collect := func(after []byte) ([]*protos.ExportChunk, []string, []string) {
+		var chunks []*protos.ExportChunk
+		var data, sch bytes.Buffer
+		err := streamExport(context.Background(), group.BelongsTo("friend"),
+			&protos.ExportRequest{}, after, func(c *protos.ExportChunk) error {
+				chunks = append(chunks, c)
+				data.Write(c.Data)
+				sch.Write(c.Schema)
+				return nil
+			})
+		require.NoError(t, err)
+		return chunks, strings.Fields(data.String()),
+			strings.Split(strings.TrimSpace(sch.String()), "\n")
+	}
+
+	chunks, data, sch := collect(nil)
+	require.Equal(t, 1, len(chunks))
+	require.True(t, chunks[0].Offset.Done)
+	require.Equal(t, group.BelongsTo("friend"), chunks[0].Offset.GroupId)
+	require.Equal(t, 1, len(sch))
+	require.Contains(t, sch[0], "friend:uid")
+	var subjects []string
+	for _, f := range data {
+		if strings.HasPrefix(f, "<_:uid") && f != "<_:uid5>" {
+			subjects = append(subjects, f)
+		}
+	}
+	require.Equal(t, []string{"<_:uid1>", "<_:uid2>", "<_:uid3>", "<_:uid4>"}, subjects)
+
+	// Resume after the list of node 2.
+	chunks, data, _ = collect(x.DataKey("friend", 2))
+	require.Equal(t, 1, len(chunks))
+	require.True(t, chunks[0].Offset.Done)
+	require.Equal(t, "<_:uid3>", data[0])
+}
+
 func generateBenchValues() []kv {
 	byteInt := make([]byte, 4)
 	binary.LittleEndian.PutUint32(byteInt, 123)
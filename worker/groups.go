@@ -24,12 +24,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
 
 	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/dgraph/group"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/raftwal"
 	"github.com/dgraph-io/dgraph/schema"
@@ -37,11 +39,12 @@ import (
 )
 
 type server struct {
-	NodeId    uint64 // Raft Id associated with the raft node.
-	Addr      string // The public address of the server serving this node.
-	Leader    bool   // Set to true if the node is a leader of the group.
-	RaftIdx   uint64 // The raft index which applied this membership update in group zero.
-	PoolOrNil *pool  // An owned reference to the server's Pool entry (nil if Addr is our own).
+	NodeId     uint64 // Raft Id associated with the raft node.
+	Addr       string // The public address of the server serving this node.
+	ClientAddr string // The address clients reach the gRPC service of the server at.
+	Leader     bool   // Set to true if the node is a leader of the group.
+	RaftIdx    uint64 // The raft index which applied this membership update in group zero.
+	PoolOrNil  *pool  // An owned reference to the server's Pool entry (nil if Addr is our own).
 }
 
 type servers struct {
@@ -63,6 +66,8 @@ type groupi struct {
 	all        map[uint32]*servers
 	num        uint32
 	lastUpdate uint64
+	// lastSync is when memberships were last synced with group zero, if not served here.
+	lastSync time.Time
 }
 
 var gr *groupi
@@ -111,6 +116,9 @@ func StartRaftNodes(walStore *badger.KV, bindall bool) {
 	gr.ctx, gr.cancel = context.WithCancel(context.Background())
 	gr.all = make(map[uint32]*servers)
 	gr.local = make(map[uint32]*node)
+	initEvents(walStore)
+	go pruneEventsPeriodically()
+	go detectStalls()
 
 	if Config.InMemoryComm {
 		Config.MyAddr = "inmemory"
@@ -170,9 +178,22 @@ func StartRaftNodes(walStore *badger.KV, bindall bool) {
 			node.InitAndStartNode(gr.wal)
 		}()
 	}
+	atomic.StoreUint32(&schemaLoaded, 1)
 	wg.Wait()
 	x.UpdateHealthStatus(true)
 	go gr.periodicSyncMemberships() // Now set it to be run periodically.
+	if len(Config.ChangelogArchive) > 0 {
+		go archiveChangelogs()
+	}
+	if len(Config.CDCKafka) > 0 {
+		go publishChanges()
+	}
+	if len(Config.Elastic) > 0 {
+		go syncElastic()
+	}
+	if Config.Webhooks {
+		go postWebhooks()
+	}
 }
 
 func getGroupIds(groups string) ([]uint32, error) {
@@ -351,6 +372,44 @@ func (g *groupi) KnownGroups() (gids []uint32) {
 	return
 }
 
+// Members returns the servers of the cluster at the addresses clients reach them at, with the
+// groups serving preds. Servers which didn't tell their client address are left out.
+func Members(preds []string) *protos.MemberList {
+	g := groups()
+	out := new(protos.MemberList)
+	g.RLock()
+	for gid, sl := range g.all {
+		for _, s := range sl.list {
+			if len(s.ClientAddr) == 0 {
+				continue
+			}
+			out.Members = append(out.Members, &protos.Member{
+				Id:      s.NodeId,
+				GroupId: gid,
+				Addr:    s.ClientAddr,
+				Leader:  s.Leader,
+			})
+		}
+	}
+	// If we start a single node cluster without group zero
+	if len(g.all) == 0 {
+		for gid, n := range g.local {
+			out.Members = append(out.Members, &protos.Member{
+				Id:      n.id,
+				GroupId: gid,
+				Addr:    Config.ClientAddr,
+				Leader:  n.AmLeader(),
+			})
+		}
+	}
+	g.RUnlock()
+
+	for _, pred := range preds {
+		out.Groups = append(out.Groups, group.BelongsTo(pred))
+	}
+	return out
+}
+
 func (g *groupi) nodes() (nodes []*node) {
 	g.RLock()
 	defer g.RUnlock()
@@ -419,10 +478,11 @@ func (g *groupi) syncMemberships() {
 
 			go func(rc *protos.RaftContext, amleader bool) {
 				mm := &protos.Membership{
-					Leader:  amleader,
-					Id:      rc.Id,
-					GroupId: rc.Group,
-					Addr:    rc.Addr,
+					Leader:     amleader,
+					Id:         rc.Id,
+					GroupId:    rc.Group,
+					Addr:       rc.Addr,
+					ClientAddr: Config.ClientAddr,
 				}
 				zero := g.Node(0)
 				x.AssertTruef(zero != nil, "Expected node 0")
@@ -445,10 +505,11 @@ func (g *groupi) syncMemberships() {
 			rc := n.raftContext
 			mu.Members = append(mu.Members,
 				&protos.Membership{
-					Leader:  n.AmLeader(),
-					Id:      rc.Id,
-					GroupId: rc.Group,
-					Addr:    rc.Addr,
+					Leader:     n.AmLeader(),
+					Id:         rc.Id,
+					GroupId:    rc.Group,
+					Addr:       rc.Addr,
+					ClientAddr: Config.ClientAddr,
 				})
 		}
 		mu.LastUpdate = g.lastUpdate
@@ -511,6 +572,9 @@ func (g *groupi) syncMemberships() {
 		}
 	}
 	g.TouchLastUpdate(lu)
+	g.Lock()
+	g.lastSync = time.Now()
+	g.Unlock()
 }
 
 func (g *groupi) periodicSyncMemberships() {
@@ -529,11 +593,12 @@ func (g *groupi) periodicSyncMemberships() {
 // membership update in group zero.
 func (g *groupi) applyMembershipUpdate(raftIdx uint64, mm *protos.Membership) {
 	update := server{
-		NodeId:    mm.Id,
-		Addr:      mm.Addr,
-		Leader:    mm.Leader,
-		RaftIdx:   raftIdx,
-		PoolOrNil: nil,
+		NodeId:     mm.Id,
+		Addr:       mm.Addr,
+		ClientAddr: mm.ClientAddr,
+		Leader:     mm.Leader,
+		RaftIdx:    raftIdx,
+		PoolOrNil:  nil,
 	}
 	if n := g.Node(mm.GroupId); n != nil {
 		// update peer address on address change
@@ -560,6 +625,17 @@ func (g *groupi) applyMembershipUpdate(raftIdx uint64, mm *protos.Membership) {
 		g.all[mm.GroupId] = sl
 	}
 
+	if i, ok := sl.byNodeID[update.NodeId]; !ok {
+		RecordEvent(EventMember, mm.GroupId, mm.Id, "Node %d joined group %d at %s", mm.Id,
+			mm.GroupId, mm.Addr)
+	} else if prev := sl.list[i]; prev.Addr != update.Addr {
+		RecordEvent(EventMember, mm.GroupId, mm.Id, "Node %d of group %d moved from %s to %s",
+			mm.Id, mm.GroupId, prev.Addr, mm.Addr)
+	}
+	if update.Leader && (len(sl.list) == 0 || !sl.list[0].Leader || sl.list[0].NodeId != mm.Id) {
+		RecordEvent(EventLeader, mm.GroupId, mm.Id, "Node %d became the leader of group %d",
+			mm.Id, mm.GroupId)
+	}
 	removeFromServersIfPresent(sl, update.NodeId)
 	addToServers(sl, update)
 
@@ -588,10 +664,11 @@ func (g *groupi) MembershipUpdateAfter(ridx uint64) *protos.MembershipUpdate {
 			}
 			out.Members = append(out.Members,
 				&protos.Membership{
-					Leader:  s.Leader,
-					Id:      s.NodeId,
-					GroupId: gid,
-					Addr:    s.Addr,
+					Leader:     s.Leader,
+					Id:         s.NodeId,
+					GroupId:    gid,
+					Addr:       s.Addr,
+					ClientAddr: s.ClientAddr,
 				})
 		}
 	}
@@ -625,10 +702,11 @@ func (w *grpcWorker) UpdateMembership(ctx context.Context,
 		}
 
 		mmNew := &protos.Membership{
-			Leader:  mm.Leader,
-			Id:      mm.Id,
-			GroupId: mm.GroupId,
-			Addr:    mm.Addr,
+			Leader:     mm.Leader,
+			Id:         mm.Id,
+			GroupId:    mm.GroupId,
+			Addr:       mm.Addr,
+			ClientAddr: mm.ClientAddr,
 		}
 
 		go func(mmNew *protos.Membership) {
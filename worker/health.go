@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	// maxRaftLag is how many committed entries a group can have left to apply, and still be
+	// seen as caught up.
+	maxRaftLag = 1000
+	// maxSyncAge is how long memberships can go without being synced with group zero.
+	maxSyncAge = 30 * time.Second
+)
+
+// schemaLoaded is set once the schema of the groups served here is loaded.
+var schemaLoaded uint32
+
+// CheckSchema returns an error if the schema of the groups served here isn't loaded yet.
+func CheckSchema() error {
+	if atomic.LoadUint32(&schemaLoaded) == 0 {
+		return x.Errorf("Schema isn't loaded yet")
+	}
+	return nil
+}
+
+// CheckRaft returns an error if any group served here has no leader, or is more than maxRaftLag
+// entries behind on applying what was committed.
+func CheckRaft() error {
+	if groups() == nil {
+		return x.Errorf("Raft groups aren't started yet")
+	}
+	for _, n := range groups().nodes() {
+		r := n.Raft()
+		if r == nil {
+			return x.Errorf("Raft node of group %d isn't started yet", n.gid)
+		}
+		st := r.Status()
+		if st.Lead == 0 {
+			return x.Errorf("Group %d has no leader", n.gid)
+		}
+		if applied := n.applied.DoneUntil(); st.Commit > applied+maxRaftLag {
+			return x.Errorf("Group %d has %d committed entries left to apply", n.gid,
+				st.Commit-applied)
+		}
+	}
+	return nil
+}
+
+// CheckMembership returns an error if the memberships of the cluster weren't synced with group
+// zero lately. Servers of group zero have them as they're applied.
+func CheckMembership() error {
+	g := groups()
+	if g == nil {
+		return x.Errorf("Raft groups aren't started yet")
+	}
+	if g.ServesGroup(0) {
+		return nil
+	}
+	if g.LastUpdate() == 0 {
+		return x.Errorf("No membership information received from group zero yet")
+	}
+	g.RLock()
+	last := g.lastSync
+	g.RUnlock()
+	if age := time.Since(last); age > maxSyncAge {
+		return x.Errorf("Memberships were last synced with group zero %v ago", x.Round(age))
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"log"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// The geo index of a predicate is rebuilt online, when a geo predicate gets one or its cells
+// change, instead of blocking the mutations of its group until it's rebuilt. The schema change
+// keeps the index the predicate had serving queries, and records the one to build, whose keys
+// are stored under another predicate: pred@rebuild, or pred itself if the index the predicate
+// had is stored under pred@rebuild. From then on, the values written are added to both indexes.
+// The leader of the group then proposes, in the background:
+//   BUILD   adds the values of a batch of nodes to the index being built.
+//   FINISH  once all the nodes are added, swaps the built index in for queries, with the schema,
+//           and deletes the old one.
+// Adding the values of a node again gives the same index, so a rebuild which didn't finish, as
+// the server running it stopped, is resumed by starting it again.
+
+// IndexRebuildState is an online rebuild of the geo index of a predicate in progress.
+type IndexRebuildState struct {
+	Predicate string `json:"predicate"`
+	Group     uint32 `json:"group"`
+	// Progress is the id the rebuild can be followed by on /admin/progress, if it's running on
+	// this server.
+	Progress string `json:"progress,omitempty"`
+}
+
+// rebuilding holds the progress ids of the predicates whose index is being built by this server.
+var rebuilding = struct {
+	sync.Mutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// IndexRebuilds returns the index rebuilds in progress in the groups served by this server.
+func IndexRebuilds() []IndexRebuildState {
+	rebuilding.Lock()
+	defer rebuilding.Unlock()
+	var rs []IndexRebuildState
+	for _, gid := range groups().KnownGroups() {
+		if !groups().ServesGroup(gid) {
+			continue
+		}
+		for _, attr := range schema.State().Predicates(gid) {
+			if s, ok := schema.State().Get(attr); ok && s.Rebuild != nil {
+				rs = append(rs, IndexRebuildState{Predicate: attr, Group: gid,
+					Progress: rebuilding.m[attr]})
+			}
+		}
+	}
+	return rs
+}
+
+// rebuildsOnline returns whether the index of a predicate whose schema changes from old to
+// current is rebuilt online, which is that of geo predicates staying geo and indexed.
+func rebuildsOnline(old, current protos.SchemaUpdate) bool {
+	return old.ValueType == uint32(types.GeoID) && current.ValueType == old.ValueType &&
+		current.Directive == protos.SchemaUpdate_INDEX
+}
+
+// rebuildingSchema returns the schema of attr while its index is rebuilt online for its schema
+// to change from old to current: current, but with the index of old, and that of current to
+// build.
+func rebuildingSchema(attr string, old, current protos.SchemaUpdate) protos.SchemaUpdate {
+	target := current
+	target.IndexAttr = old.IndexAttr
+	if len(old.Tokenizer) > 0 {
+		// Predicates without an index have no keys to keep under their own name.
+		target.IndexAttr = schema.RebuildAttr(attr, old.IndexAttr)
+	}
+	live := current
+	live.Directive, live.Tokenizer, live.Geo = old.Directive, old.Tokenizer, old.Geo
+	live.IndexAttr = old.IndexAttr
+	live.Rebuild = &target
+	return live
+}
+
+// StartIndexRebuild starts building the geo index being rebuilt for attr in the background,
+// which can be followed on /admin/progress by id. The group of attr must be served by this
+// server, which can't already be building it.
+func StartIndexRebuild(attr, id string) error {
+	gid := group.BelongsTo(attr)
+	if !groups().ServesGroup(gid) {
+		return x.Errorf("The index of %s must be rebuilt on a server of group %d", attr, gid)
+	}
+	if s, ok := schema.State().Get(attr); !ok || s.Rebuild == nil {
+		return x.Errorf("The index of %s isn't being rebuilt", attr)
+	}
+	rebuilding.Lock()
+	defer rebuilding.Unlock()
+	if _, ok := rebuilding.m[attr]; ok {
+		return x.Errorf("The index of %s is already being built", attr)
+	}
+	rebuilding.m[attr] = id
+	ctx, progress := StartProgress(context.Background(), RunIndexRebuild, id)
+	go func() {
+		err := buildIndex(ctx, attr, progress)
+		progress.Finish(err)
+		rebuilding.Lock()
+		delete(rebuilding.m, attr)
+		rebuilding.Unlock()
+		if err != nil {
+			log.Printf("Error while rebuilding the index of %s: %v\n", attr, err)
+		}
+	}()
+	return nil
+}
+
+// startIndexRebuild starts the rebuild of the index of attr by this server after its schema
+// changed.
+func startIndexRebuild(attr string) {
+	if err := StartIndexRebuild(attr, "index_rebuild:"+attr); err != nil {
+		x.Printf("Error while starting to rebuild the index of %s: %v\n", attr, err)
+	}
+}
+
+func proposeIndexRebuild(ctx context.Context, r *protos.IndexRebuild) error {
+	gid := group.BelongsTo(r.Attr)
+	return groups().Node(gid).ProposeAndWait(ctx, &protos.Proposal{
+		Mutations: &protos.Mutations{GroupId: gid, IndexRebuild: r}})
+}
+
+// buildIndex adds the values of all the nodes of attr to the index being rebuilt for it, and
+// swaps it in once they're added.
+func buildIndex(ctx context.Context, attr string, progress *Progress) error {
+	progress.SetPhase("counting")
+	var batches int
+	if err := forEachNode(attr, func([]uint64) error {
+		batches++
+		return nil
+	}); err != nil {
+		return err
+	}
+	progress.AddTasks(batches)
+	progress.SetPhase("building")
+	var done int
+	err := forEachNode(attr, func(uids []uint64) error {
+		// Nodes may be added while the index is built.
+		if done++; done > batches {
+			progress.AddTasks(1)
+		}
+		r := &protos.IndexRebuild{Attr: attr, Op: protos.IndexRebuild_BUILD,
+			Uids: &protos.List{Uids: uids}}
+		if err := proposeIndexRebuild(ctx, r); err != nil {
+			return err
+		}
+		progress.TaskDone(len(uids))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	progress.SetPhase("finishing")
+	return proposeIndexRebuild(ctx, &protos.IndexRebuild{Attr: attr,
+		Op: protos.IndexRebuild_FINISH})
+}
+
+// processIndexRebuild applies the step r of an index rebuild, at index.
+func (n *node) processIndexRebuild(index uint64, r *protos.IndexRebuild) error {
+	ctx := context.WithValue(n.ctx, "raft", x.RaftValue{Group: n.gid, Index: index})
+	// Like schema changes, the steps see the values written before them.
+	n.waitForSyncMark(n.ctx, index-1)
+	switch r.Op {
+	case protos.IndexRebuild_BUILD:
+		return posting.AddToRebuiltIndex(ctx, r.Attr, r.Uids.GetUids(), n.gid)
+	case protos.IndexRebuild_FINISH:
+		return n.finishIndexRebuild(ctx, index, r.Attr)
+	}
+	return x.Errorf("Unknown step %v of the rebuild of the index of %s", r.Op, r.Attr)
+}
+
+func (n *node) finishIndexRebuild(ctx context.Context, index uint64, attr string) error {
+	old, ok := schema.State().Get(attr)
+	if !ok || old.Rebuild == nil {
+		return x.Errorf("The index of %s isn't being rebuilt", attr)
+	}
+	built := *old.Rebuild
+	updateSchema(attr, built, index, n.gid)
+	// Queries use the built index from now on, so the old one can go.
+	if name := schema.IndexAttr(attr, &old); len(old.Tokenizer) > 0 &&
+		name != schema.IndexAttr(attr, &built) {
+		if err := posting.DeleteIndexKeys(ctx, name); err != nil {
+			return err
+		}
+	}
+	RecordEvent(EventSchema, n.gid, Config.RaftId, "Rebuilt the geo index of %s", attr)
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// InvalidateAll is the uid of the keys invalidating a predicate on all nodes, sent when its schema
+// changes.
+const InvalidateAll = "*"
+
+// Invalidation is the key of cached data a change makes stale: the values of Predicate on the
+// node Uid, as of the commit at Commit.
+type Invalidation struct {
+	Predicate string    `json:"predicate"`
+	Uid       string    `json:"uid"`
+	Commit    time.Time `json:"commit_ts"`
+}
+
+// Invalidations returns the keys of the data c makes stale, each once, in the order of its lines.
+// An edge to a node of a predicate with a reverse index makes the reverse edges of its object,
+// ~predicate, stale too. Deletes of a predicate on all nodes, written with a subject of *, give
+// keys with the uid InvalidateAll.
+func Invalidations(c *Change) ([]Invalidation, error) {
+	var keys []Invalidation
+	seen := make(map[Invalidation]bool)
+	add := func(pred, uid string) {
+		k := Invalidation{Predicate: pred, Uid: uid, Commit: c.Time}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, line := range c.Lines {
+		if strings.HasPrefix(line, "schema ") {
+			add(changelogLineAttr(line), InvalidateAll)
+			continue
+		}
+		nq, _, err := parseChangelogEdge(line)
+		if err != nil {
+			return nil, err
+		}
+		if nq.Subject == x.Star {
+			nq.Subject = InvalidateAll
+		}
+		add(nq.Predicate, nq.Subject)
+		if !schema.State().IsReversed(nq.Predicate) {
+			continue
+		}
+		switch {
+		case len(nq.ObjectId) > 0:
+			add("~"+nq.Predicate, nq.ObjectId)
+		case nq.ObjectValue.GetDefaultVal() == x.Star:
+			// The objects of the edges deleted aren't known.
+			add("~"+nq.Predicate, InvalidateAll)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/schema"
+)
+
+func TestInvalidations(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(`
+		name: string @index(exact) .
+		follows: uid @reverse .
+		likes: uid .`), 1))
+
+	now := time.Now()
+	c := &Change{Group: 1, Index: 42, Time: now, Lines: []string{
+		"schema name: string @index(exact) .",
+		`+ <_:uid1> <name> "Alice" .`,
+		`+ <_:uid1> <name> "Alicia"@es .`,
+		`+ <_:uid1> <follows> <_:uid2> .`,
+		`+ <_:uid1> <likes> <_:uid3> .`,
+		`- <_:uid2> <follows> * .`,
+		`- * <likes> * .`,
+	}}
+	keys, err := Invalidations(c)
+	require.NoError(t, err)
+	require.Equal(t, []Invalidation{
+		{Predicate: "name", Uid: InvalidateAll, Commit: now},
+		{Predicate: "name", Uid: "0x1", Commit: now},
+		{Predicate: "follows", Uid: "0x1", Commit: now},
+		{Predicate: "~follows", Uid: "0x2", Commit: now},
+		{Predicate: "likes", Uid: "0x1", Commit: now},
+		{Predicate: "follows", Uid: "0x2", Commit: now},
+		{Predicate: "~follows", Uid: InvalidateAll, Commit: now},
+		{Predicate: "likes", Uid: InvalidateAll, Commit: now},
+	}, keys)
+
+	_, err = Invalidations(&Change{Lines: []string{"<_:uid1> <name> \"Alice\" ."}})
+	require.Error(t, err)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"math"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+var (
+	// pendingMutations holds the committed Raft entries waiting to be applied, and the edges
+	// scheduled and not applied yet.
+	pendingMutations = x.NewMemoryAccount("pending_mutations")
+	// queryResults holds the results of the tasks of the queries being processed.
+	queryResults = x.NewMemoryAccount("query_results")
+	// geoFilters holds the values loaded to check the results of geo functions.
+	geoFilters = x.NewMemoryAccount("geo_filter")
+)
+
+func init() {
+	x.RegisterMemory("raft_entries", raftEntriesSize)
+}
+
+// raftEntriesSize returns the bytes of the entries in the Raft logs kept in memory.
+func raftEntriesSize() int64 {
+	if groups() == nil {
+		return 0
+	}
+	var size int64
+	for _, n := range groups().nodes() {
+		first, err := n.store.FirstIndex()
+		if err != nil {
+			continue
+		}
+		last, err := n.store.LastIndex()
+		if err != nil || last < first {
+			continue
+		}
+		es, err := n.store.Entries(first, last+1, math.MaxUint64)
+		if err != nil {
+			continue
+		}
+		for _, e := range es {
+			size += int64(e.Size())
+		}
+	}
+	return size
+}
+
+// QueryMemory counts the bytes of the results of the tasks of a query, held until it releases
+// them.
+type QueryMemory struct {
+	bytes int64
+}
+
+type queryMemoryKey struct{}
+
+// WithQueryMemory returns ctx with m, which the results of the tasks run with it are counted by.
+func WithQueryMemory(ctx context.Context) (context.Context, *QueryMemory) {
+	m := new(QueryMemory)
+	return context.WithValue(ctx, queryMemoryKey{}, m), m
+}
+
+// holdResult counts result on the QueryMemory of ctx, if it has one.
+func holdResult(ctx context.Context, result *protos.Result) {
+	m, ok := ctx.Value(queryMemoryKey{}).(*QueryMemory)
+	if !ok || result == nil {
+		return
+	}
+	n := int64(result.Size())
+	atomic.AddInt64(&m.bytes, n)
+	queryResults.Add(n)
+}
+
+// Release releases the results counted so far.
+func (m *QueryMemory) Release() {
+	queryResults.Add(-atomic.SwapInt64(&m.bytes, 0))
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+)
+
+// MigrationsPredicate holds the state of the schema migrations of the cluster, on the node 1 like
+// the lease. Like the lease, it's left out of the changelog and exports.
+const MigrationsPredicate = "_migrations_"
+
+// MigrationState returns the state of the schema migrations stored in the cluster, or nil if none
+// was ever stored.
+func MigrationState(ctx context.Context) ([]byte, error) {
+	res, err := ProcessTaskOverNetwork(ctx, &protos.Query{
+		Attr:    MigrationsPredicate,
+		UidList: &protos.List{Uids: []uint64{1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.ValueMatrix) == 0 || len(res.ValueMatrix[0].Values) == 0 {
+		return nil, nil
+	}
+	return res.ValueMatrix[0].Values[0].Val, nil
+}
+
+// SetMigrationState stores state as the state of the schema migrations of the cluster.
+func SetMigrationState(ctx context.Context, state []byte) error {
+	return MutateOverNetwork(ctx, &protos.Mutations{Edges: []*protos.DirectedEdge{{
+		Entity:    1,
+		Attr:      MigrationsPredicate,
+		Value:     state,
+		ValueType: uint32(types.StringID),
+		Op:        protos.DirectedEdge_SET,
+	}}})
+}
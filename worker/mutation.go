@@ -19,12 +19,16 @@ package worker
 
 import (
 	"bytes"
+	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
 
+	geom "github.com/twpayne/go-geom"
+
 	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/dgraph/group"
 	"github.com/dgraph-io/dgraph/posting"
@@ -78,12 +82,64 @@ func runMutation(ctx context.Context, edge *protos.DirectedEdge) error {
 		}
 	}
 
+	if edge.Op == protos.DirectedEdge_SET && schema.State().IsNoreplace(edge.Attr) {
+		if err := checkNoreplace(plist, edge); err != nil {
+			return err
+		}
+	}
 	if err = plist.AddMutationWithIndex(ctx, edge); err != nil {
 		return err // abort applying the rest of them.
 	}
+	op := set
+	if edge.Op == protos.DirectedEdge_DEL {
+		op = del
+	}
+	x.MutationEdges.WithLabelValues(op).Inc()
+	x.PredicateWrites.WithLabelValues(x.PredicateLabel(edge.Attr)).Inc()
 	return nil
 }
 
+// NoreplaceError is returned for edges setting a value of a predicate declared @noreplace on a
+// node which already has another one.
+type NoreplaceError struct {
+	Uid       uint64
+	Predicate string
+	Lang      string
+}
+
+func (e *NoreplaceError) Error() string {
+	pred := e.Predicate
+	if e.Lang != "" {
+		pred += "@" + e.Lang
+	}
+	return fmt.Sprintf("Node %#x already has another value of %s, which can't be replaced",
+		e.Uid, pred)
+}
+
+// checkNoreplace returns an error if edge sets a value other than the one the node has in pl.
+// It's run as the edge is applied, after the edges of the same node and predicate committed before
+// it, so of two mutations setting different values at the same time, the later one fails.
+func checkNoreplace(pl *posting.List, edge *protos.DirectedEdge) error {
+	var val types.Val
+	var err error
+	if edge.Lang == "" {
+		val, err = pl.Value()
+	} else {
+		val, err = pl.ValueForTag(edge.Lang)
+	}
+	if err == posting.ErrNoValue {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if b, ok := val.Value.([]byte); ok && val.Tid == types.TypeID(edge.ValueType) &&
+		bytes.Equal(b, edge.Value) {
+		return nil
+	}
+	_, name := x.ParseNamespacedAttr(edge.Attr)
+	return x.Wrap(&NoreplaceError{Uid: edge.Entity, Predicate: name, Lang: edge.Lang})
+}
+
 // This is serialized with mutations, called after applied watermarks catch up
 // and further mutations are blocked until this is done.
 func runSchemaMutation(ctx context.Context, update *protos.SchemaUpdate) error {
@@ -101,7 +157,25 @@ func runSchemaMutation(ctx context.Context, update *protos.SchemaUpdate) error {
 	}
 	old, ok := schema.State().Get(update.Predicate)
 	current := schema.From(update)
+	changed := current
+	online := ok && needReindexing(old, current) && rebuildsOnline(old, current)
+	if online {
+		current = rebuildingSchema(update.Predicate, old, current)
+	} else if ok && !needReindexing(old, current) {
+		current.IndexAttr = old.IndexAttr
+	}
 	updateSchema(update.Predicate, current, rv.Index, rv.Group)
+	var buf bytes.Buffer
+	toSchema(&buf, &skv{attr: update.Predicate, name: update.Predicate, schema: &changed})
+	RecordEvent(EventSchema, rv.Group, Config.RaftId, "Schema changed to %s",
+		strings.TrimSpace(buf.String()))
+	if online {
+		RecordEvent(EventSchema, rv.Group, Config.RaftId,
+			"Rebuilding the geo index of %s in the background", update.Predicate)
+		if n.AmLeader() {
+			startIndexRebuild(update.Predicate)
+		}
+	}
 
 	// Once we remove index or reverse edges from schema, even though the values
 	// are present in db, they won't be used due to validation in work/task.go
@@ -178,6 +252,16 @@ func needReindexing(old protos.SchemaUpdate, current protos.SchemaUpdate) bool {
 			return true
 		}
 	}
+	// if the cells of the geo index have changed
+	curMin, curMax, curCells := types.GeoLevels(current.Geo)
+	oldMin, oldMax, oldCells := types.GeoLevels(old.Geo)
+	if curMin != oldMin || curMax != oldMax || curCells != oldCells {
+		return true
+	}
+	// or the precision of the geohash index
+	if current.Geo.GetPrecision() != old.Geo.GetPrecision() {
+		return true
+	}
 
 	return false
 }
@@ -236,6 +320,14 @@ func hasEdges(attr string) bool {
 }
 
 func checkSchema(s *protos.SchemaUpdate) error {
+	if old, ok := schema.State().Get(s.Predicate); ok && (old.RenamedTo != "" ||
+		old.RenamedFrom != "") {
+		return x.Errorf("Schema change not allowed while pred: %s is being renamed", s.Predicate)
+	}
+	if old, ok := schema.State().Get(s.Predicate); ok && old.Rebuild != nil {
+		return x.Errorf("Schema change not allowed while the index of pred: %s is being rebuilt",
+			s.Predicate)
+	}
 	typ := types.TypeID(s.ValueType)
 	if typ == types.UidID && s.Directive == protos.SchemaUpdate_INDEX {
 		// index on uid type
@@ -245,6 +337,11 @@ func checkSchema(s *protos.SchemaUpdate) error {
 		// reverse on non-uid type
 		return x.Errorf("Cannot reverse for non-uid type on predicate %s", s.Predicate)
 	}
+	if s.Geo != nil {
+		if err := types.ValidateGeoIndex(s.Geo); err != nil {
+			return x.Wrapf(err, "Invalid geo index of pred: %s", s.Predicate)
+		}
+	}
 	if t, err := schema.State().TypeOf(s.Predicate); err == nil {
 		// schema was defined already
 		if t.IsScalar() == typ.IsScalar() {
@@ -287,7 +384,8 @@ func validateAndConvert(edge *protos.DirectedEdge, schemaType types.TypeID) erro
 		// Both are scalars. Continue.
 	}
 
-	if storageType == schemaType {
+	// Geo values are decoded even when they are of the schema type, to be validated.
+	if storageType == schemaType && schemaType != types.GeoID {
 		return nil
 	}
 
@@ -301,8 +399,15 @@ func validateAndConvert(edge *protos.DirectedEdge, schemaType types.TypeID) erro
 		return err
 	}
 
-	// if storage type was specified skip
-	if storageType != types.DefaultID {
+	repaired := false
+	if schemaType == types.GeoID && edge.Op == protos.DirectedEdge_SET {
+		if repaired, err = checkGeo(edge.Attr, &dst); err != nil {
+			return err
+		}
+	}
+
+	// if storage type was specified skip, unless the value was repaired
+	if storageType != types.DefaultID && !repaired {
 		return nil
 	}
 
@@ -316,6 +421,26 @@ func validateAndConvert(edge *protos.DirectedEdge, schemaType types.TypeID) erro
 	return nil
 }
 
+// checkGeo validates the geo value dst set for the predicate attr. With Config.GeoRepair the rings
+// of its polygons which are wound the wrong way are reversed first, and it returns whether there
+// were any.
+func checkGeo(attr string, dst *types.Val) (bool, error) {
+	g, ok := dst.Value.(geom.T)
+	if !ok {
+		return false, nil
+	}
+	repaired := false
+	if Config.GeoRepair {
+		if g, repaired = types.OrientGeo(g); repaired {
+			dst.Value = g
+		}
+	}
+	if err := types.ValidateGeo(g); err != nil {
+		return false, x.Wrapf(err, "Invalid geo value for predicate %s", attr)
+	}
+	return repaired, nil
+}
+
 // runMutate is used to run the mutations on an instance.
 func proposeOrSend(ctx context.Context, gid uint32, m *protos.Mutations, che chan error) {
 	if groups().ServesGroup(gid) {
@@ -372,11 +497,26 @@ func addToMutationMap(mutationMap map[uint32]*protos.Mutations, m *protos.Mutati
 		}
 		mu.Schema = append(mu.Schema, schema)
 	}
+	if len(m.Types) > 0 {
+		gid := group.BelongsTo(x.TypesAttr)
+		mu := mutationMap[gid]
+		if mu == nil {
+			mu = &protos.Mutations{GroupId: gid}
+			mutationMap[gid] = mu
+		}
+		mu.Types = append(mu.Types, m.Types...)
+	}
 }
 
 // MutateOverNetwork checks which group should be running the mutations
 // according to fingerprint of the predicate and sends it to that instance.
 func MutateOverNetwork(ctx context.Context, m *protos.Mutations) error {
+	if err := checkRequired(ctx, m); err != nil {
+		return err
+	}
+	if err := checkTypes(ctx, m); err != nil {
+		return err
+	}
 	mutationMap := make(map[uint32]*protos.Mutations)
 	addToMutationMap(mutationMap, m)
 
@@ -397,6 +537,14 @@ func MutateOverNetwork(ctx context.Context, m *protos.Mutations) error {
 		}
 	}
 	close(errorCh)
+	if e == nil && hasWatchers() {
+		// Mutations to the groups served here are seen as they're applied.
+		for gid, mu := range mutationMap {
+			if !groups().ServesGroup(gid) {
+				notifyWatchers(mu)
+			}
+		}
+	}
 	return e
 }
 
@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
 
 	"github.com/dgraph-io/dgraph/group"
 	"github.com/dgraph-io/dgraph/protos"
@@ -96,6 +97,45 @@ func TestValidateEdgeTypeError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestValidateGeoEdge(t *testing.T) {
+	cw := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+	})
+	data, err := types.MarshalWKB(cw)
+	require.NoError(t, err)
+	edge := func(op protos.DirectedEdge_Op) *protos.DirectedEdge {
+		return &protos.DirectedEdge{
+			Value:     data,
+			ValueType: uint32(types.GeoID),
+			Attr:      "loc",
+			Op:        op,
+		}
+	}
+
+	err = validateAndConvert(edge(protos.DirectedEdge_SET), types.GeoID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "predicate loc")
+	// Values can be deleted whatever they are.
+	require.NoError(t, validateAndConvert(edge(protos.DirectedEdge_DEL), types.GeoID))
+
+	// Untyped values are validated once converted.
+	err = validateAndConvert(&protos.DirectedEdge{
+		Value: []byte(`{"type":"Point","coordinates":[37.7,-122.4]}`),
+		Attr:  "loc",
+	}, types.GeoID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "out of range")
+
+	Config.GeoRepair = true
+	defer func() { Config.GeoRepair = false }()
+	e := edge(protos.DirectedEdge_SET)
+	require.NoError(t, validateAndConvert(e, types.GeoID))
+	g, err := types.UnmarshalWKB(e.Value)
+	require.NoError(t, err)
+	require.Equal(t, [][]geom.Coord{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}},
+		g.(*geom.Polygon).Coords())
+}
+
 func TestAddToMutationArray(t *testing.T) {
 	group.ParseGroupConfig("")
 	dir, err := ioutil.TempDir("", "storetest_")
@@ -154,6 +194,12 @@ func TestCheckSchema(t *testing.T) {
 
 	s1 = &protos.SchemaUpdate{Predicate: "friend", ValueType: uint32(types.UidID), Directive: protos.SchemaUpdate_REVERSE}
 	require.NoError(t, checkSchema(s1))
+
+	// while the index is rebuilt online
+	schema.State().Set("name", protos.SchemaUpdate{ValueType: uint32(types.GeoID),
+		Rebuild: &protos.SchemaUpdate{ValueType: uint32(types.GeoID)}})
+	s1 = &protos.SchemaUpdate{Predicate: "name", ValueType: uint32(types.GeoID)}
+	require.Error(t, checkSchema(s1))
 }
 
 func TestNeedReindexing(t *testing.T) {
@@ -176,4 +222,48 @@ func TestNeedReindexing(t *testing.T) {
 	s1 = protos.SchemaUpdate{ValueType: uint32(types.StringID), Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"exact"}}
 	s2 = protos.SchemaUpdate{ValueType: uint32(types.FloatID), Directive: protos.SchemaUpdate_NONE}
 	require.True(t, needReindexing(s1, s2))
+
+	s1 = protos.SchemaUpdate{ValueType: uint32(types.GeoID), Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"geo"}}
+	s2 = protos.SchemaUpdate{ValueType: uint32(types.GeoID), Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"geo"},
+		Geo: &protos.GeoIndex{MinLevel: types.MinCellLevel, MaxLevel: types.MaxCellLevel, MaxCells: types.MaxCells}}
+	require.False(t, needReindexing(s1, s2))
+	s2.Geo = &protos.GeoIndex{MinLevel: 8, MaxLevel: types.MaxCellLevel, MaxCells: types.MaxCells}
+	require.True(t, needReindexing(s1, s2))
+
+	s1 = protos.SchemaUpdate{ValueType: uint32(types.GeoID), Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"geohash"},
+		Geo: &protos.GeoIndex{Precision: 7}}
+	s2 = s1
+	require.False(t, needReindexing(s1, s2))
+	s2.Geo = &protos.GeoIndex{Precision: 8}
+	require.True(t, needReindexing(s1, s2))
+}
+
+func TestRebuildingSchema(t *testing.T) {
+	geo := protos.SchemaUpdate{ValueType: uint32(types.GeoID)}
+	indexed := protos.SchemaUpdate{ValueType: uint32(types.GeoID),
+		Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"geo"}}
+	require.True(t, rebuildsOnline(geo, indexed))
+	require.False(t, rebuildsOnline(indexed, geo))
+	str := protos.SchemaUpdate{ValueType: uint32(types.StringID),
+		Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"exact"}}
+	require.False(t, rebuildsOnline(str, str))
+
+	// Predicates without an index build it under their own name.
+	s := rebuildingSchema("loc", geo, indexed)
+	require.Equal(t, protos.SchemaUpdate_NONE, s.Directive)
+	require.Empty(t, s.Tokenizer)
+	require.Equal(t, "", s.Rebuild.IndexAttr)
+	require.Equal(t, []string{"geo"}, s.Rebuild.Tokenizer)
+
+	// Those with one alternate between their name and name@rebuild.
+	changed := indexed
+	changed.Geo = &protos.GeoIndex{MinLevel: 8, MaxLevel: 12, MaxCells: 10}
+	s = rebuildingSchema("loc", indexed, changed)
+	require.Nil(t, s.Geo)
+	require.Equal(t, "", s.IndexAttr)
+	require.Equal(t, "loc@rebuild", s.Rebuild.IndexAttr)
+	require.Equal(t, changed.Geo, s.Rebuild.Geo)
+	s = rebuildingSchema("loc", *s.Rebuild, indexed)
+	require.Equal(t, "loc@rebuild", s.IndexAttr)
+	require.Equal(t, "loc", s.Rebuild.IndexAttr)
 }
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Uid predicates declared with @ondelete(action) act on their edges when nodes are deleted, with
+// S * *. cascade deletes the nodes the edges of a deleted node point to, and the nodes theirs point
+// to in turn. detach deletes the edges of other nodes pointing to a deleted node, and restrict
+// rejects the deletion while there are any, which are both found by the reverse edges. The actions
+// are resolved against the nodes as they are before the deletion is proposed, and their edges are
+// proposed along with it.
+
+// ReferencedError is returned for deletions of the node Uid, which Predicate, declared with
+// @ondelete(restrict), points to from the node By.
+type ReferencedError struct {
+	Uid       uint64
+	Predicate string
+	By        uint64
+}
+
+func (e *ReferencedError) Error() string {
+	return fmt.Sprintf("Node %#x can't be deleted, as predicate %s of node %#x points to it",
+		e.Uid, e.Predicate, e.By)
+}
+
+func sortedUids(uids map[uint64]bool) []uint64 {
+	out := make([]uint64, 0, len(uids))
+	for uid := range uids {
+		out = append(out, uid)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// OnDelete resolves the @ondelete actions of the predicates picked by keep, for the deletion of the
+// nodes uids. It returns the nodes to delete, which are uids and those cascaded to, and the edges
+// pointing to them which are to be deleted.
+func OnDelete(ctx context.Context, uids []uint64,
+	keep func(attr string) bool) ([]uint64, []*protos.DirectedEdge, error) {
+	if len(uids) == 0 {
+		return nil, nil, nil
+	}
+	nodes, err := GetSchemaOverNetwork(ctx, &protos.SchemaRequest{Fields: []string{"ondelete"}})
+	if err != nil {
+		return nil, nil, err
+	}
+	// The predicates of each action.
+	actions := make(map[string][]string)
+	for _, n := range nodes {
+		if n.Ondelete != "" && keep(n.Predicate) {
+			actions[n.Ondelete] = append(actions[n.Ondelete], n.Predicate)
+		}
+	}
+	deleted := make(map[uint64]bool, len(uids))
+	for _, uid := range uids {
+		deleted[uid] = true
+	}
+	if len(actions) == 0 {
+		return sortedUids(deleted), nil, nil
+	}
+
+	// Nodes are cascaded to until no new ones are found.
+	next := sortedUids(deleted)
+	for len(next) > 0 {
+		found := make(map[uint64]bool)
+		for _, attr := range actions[schema.OndeleteCascade] {
+			res, err := ProcessTaskOverNetwork(ctx, &protos.Query{
+				Attr:    attr,
+				UidList: &protos.List{Uids: next},
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, l := range res.UidMatrix {
+				for _, uid := range l.Uids {
+					if !deleted[uid] {
+						deleted[uid] = true
+						found[uid] = true
+					}
+				}
+			}
+		}
+		next = sortedUids(found)
+	}
+
+	all := sortedUids(deleted)
+	var edges []*protos.DirectedEdge
+	for _, action := range []string{schema.OndeleteRestrict, schema.OndeleteDetach} {
+		for _, attr := range actions[action] {
+			res, err := ProcessTaskOverNetwork(ctx, &protos.Query{
+				Attr:    attr,
+				Reverse: true,
+				UidList: &protos.List{Uids: all},
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			for i, l := range res.UidMatrix {
+				for _, by := range l.Uids {
+					// The edges of deleted nodes are deleted with them.
+					if deleted[by] {
+						continue
+					}
+					if action == schema.OndeleteRestrict {
+						_, name := x.ParseNamespacedAttr(attr)
+						return nil, nil, x.Wrap(&ReferencedError{Uid: all[i], Predicate: name,
+							By: by})
+					}
+					edges = append(edges, &protos.DirectedEdge{
+						Op:      protos.DirectedEdge_DEL,
+						Entity:  by,
+						Attr:    attr,
+						ValueId: all[i],
+					})
+				}
+			}
+		}
+	}
+	return all, edges, nil
+}
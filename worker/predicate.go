@@ -23,6 +23,7 @@ import (
 	"crypto/md5"
 	"encoding/binary"
 	"io"
+	"strconv"
 
 	"github.com/dgraph-io/badger"
 	"golang.org/x/net/trace"
@@ -48,6 +49,7 @@ func writeBatch(ctx context.Context, pstore *badger.KV, kv chan *protos.KV, che
 			wb = badger.EntriesDelete(wb, i.Key)
 		} else {
 			wb = badger.EntriesSet(wb, i.Key, i.Val)
+			posting.AddToKeyFilter(i.Key)
 		}
 		batchSize += len(i.Key) + len(i.Val)
 		// We write in batches of size 32MB.
@@ -184,6 +186,14 @@ func populateShard(ctx context.Context, ps *badger.KV, pl *pool, group uint32) (
 	che := make(chan error)
 	go writeBatch(ctx, ps, kvs, che)
 
+	label := strconv.FormatUint(uint64(group), 10)
+	x.SnapshotReceiving.WithLabelValues(label).Set(1)
+	defer x.SnapshotReceiving.WithLabelValues(label).Set(0)
+	receivedKeys := x.SnapshotReceivedKeys.WithLabelValues(label)
+	receivedBytes := x.SnapshotReceivedBytes.WithLabelValues(label)
+	receivedKeys.Set(0)
+	receivedBytes.Set(0)
+
 	// We can use count to check the number of posting lists returned in tests.
 	count := 0
 	for {
@@ -199,6 +209,8 @@ func populateShard(ctx context.Context, ps *badger.KV, pl *pool, group uint32) (
 			return count, err
 		}
 		count++
+		receivedKeys.Inc()
+		receivedBytes.Add(float64(len(kv.Key) + len(kv.Val)))
 
 		// We check for errors, if there are no errors we send value to channel.
 		select {
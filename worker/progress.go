@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Queries and jobs, like exports and backups, are registered under the id of the request which
+// started them while they run. Those running for longer than Config.ProgressThreshold can be
+// looked up by that id, to follow how far they got, and canceled.
+
+// Kinds of runs.
+const (
+	RunQuery     = "query"
+	RunExport    = "export"
+	RunBackup    = "backup"
+	RunMigration = "migration"
+	RunRename    = "rename"
+	// RunIndexRebuild is the online rebuild of a geo index.
+	RunIndexRebuild = "index_rebuild"
+)
+
+// Progress is how far a query or a job has run.
+type Progress struct {
+	id    string
+	kind  string
+	start time.Time
+
+	// Tasks is the number of tasks started, and done is that of those done. Rows is the number
+	// of uids and values returned by the tasks done.
+	tasks int64
+	done  int64
+	rows  int64
+
+	sync.Mutex
+	phase    string
+	err      error
+	finished chan struct{}
+	cancel   context.CancelFunc
+}
+
+// ProgressReport is the state of a run, as reported to clients.
+type ProgressReport struct {
+	Id        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Phase     string    `json:"phase"`
+	Start     time.Time `json:"start"`
+	Elapsed   string    `json:"elapsed"`
+	Tasks     int64     `json:"tasks"`
+	TasksDone int64     `json:"tasks_done"`
+	// Percent is the percentage of the tasks started so far which are done. More tasks may
+	// start as the results of those done come in.
+	Percent  float64 `json:"percent"`
+	Rows     int64   `json:"rows"`
+	Finished bool    `json:"finished"`
+	Error    string  `json:"error,omitempty"`
+}
+
+var runs = struct {
+	sync.Mutex
+	m map[string]*Progress
+}{m: make(map[string]*Progress)}
+
+type progressKey struct{}
+
+// StartProgress registers the run of kind started by the request id, and returns the context to
+// run it with, which CancelProgress cancels. The run isn't registered if id is empty or another
+// run has it, but p can still be used. Finish must be called on p once it's done.
+func StartProgress(ctx context.Context, kind, id string) (context.Context, *Progress) {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Progress{
+		id:       id,
+		kind:     kind,
+		start:    time.Now(),
+		phase:    "starting",
+		finished: make(chan struct{}),
+		cancel:   cancel,
+	}
+	runs.Lock()
+	if _, ok := runs.m[id]; !ok && id != "" {
+		runs.m[id] = p
+	}
+	runs.Unlock()
+	return context.WithValue(ctx, progressKey{}, p), p
+}
+
+func progressFrom(ctx context.Context) *Progress {
+	p, _ := ctx.Value(progressKey{}).(*Progress)
+	return p
+}
+
+// SetPhase sets the phase the run is in, like parsing or processing.
+func (p *Progress) SetPhase(phase string) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	p.phase = phase
+	p.Unlock()
+}
+
+// AddTasks adds n tasks to those started, for jobs which know them in advance.
+func (p *Progress) AddTasks(n int) {
+	if p != nil {
+		atomic.AddInt64(&p.tasks, int64(n))
+	}
+}
+
+// TaskDone marks a task as done, with the rows it produced.
+func (p *Progress) TaskDone(rows int) {
+	if p != nil {
+		atomic.AddInt64(&p.done, 1)
+		atomic.AddInt64(&p.rows, int64(rows))
+	}
+}
+
+// resultRows returns the number of uids and values in r.
+func resultRows(r *protos.Result) int {
+	if r == nil {
+		return 0
+	}
+	var n int
+	for _, l := range r.UidMatrix {
+		n += len(l.Uids)
+	}
+	for _, l := range r.ValueMatrix {
+		n += len(l.Values)
+	}
+	return n
+}
+
+// Finish unregisters the run, which ended with err.
+func (p *Progress) Finish(err error) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	p.err, p.phase = err, "finished"
+	p.Unlock()
+	close(p.finished)
+	p.cancel()
+	runs.Lock()
+	if runs.m[p.id] == p {
+		delete(runs.m, p.id)
+	}
+	runs.Unlock()
+}
+
+// Finished returns a channel closed once the run is done.
+func (p *Progress) Finished() <-chan struct{} {
+	return p.finished
+}
+
+// Report returns the state of the run.
+func (p *Progress) Report() ProgressReport {
+	r := ProgressReport{
+		Id:        p.id,
+		Kind:      p.kind,
+		Start:     p.start,
+		Elapsed:   x.Round(time.Since(p.start)).String(),
+		Tasks:     atomic.LoadInt64(&p.tasks),
+		TasksDone: atomic.LoadInt64(&p.done),
+		Rows:      atomic.LoadInt64(&p.rows),
+	}
+	if r.Tasks > 0 {
+		r.Percent = float64(100*r.TasksDone) / float64(r.Tasks)
+	}
+	select {
+	case <-p.finished:
+		r.Finished = true
+	default:
+	}
+	p.Lock()
+	r.Phase = p.phase
+	if p.err != nil {
+		r.Error = p.err.Error()
+	}
+	p.Unlock()
+	return r
+}
+
+// long returns whether the run has been running for longer than Config.ProgressThreshold.
+func (p *Progress) long() bool {
+	return time.Since(p.start) >= Config.ProgressThreshold
+}
+
+// RunningProgress returns the runs running for longer than Config.ProgressThreshold, from the
+// oldest.
+func RunningProgress() []ProgressReport {
+	runs.Lock()
+	var long []*Progress
+	for _, p := range runs.m {
+		if p.long() {
+			long = append(long, p)
+		}
+	}
+	runs.Unlock()
+	sort.Slice(long, func(i, j int) bool { return long[i].start.Before(long[j].start) })
+	reports := make([]ProgressReport, 0, len(long))
+	for _, p := range long {
+		reports = append(reports, p.Report())
+	}
+	return reports
+}
+
+// LookupProgress returns the run started by the request id, if it has been running for longer
+// than Config.ProgressThreshold.
+func LookupProgress(id string) (*Progress, bool) {
+	runs.Lock()
+	defer runs.Unlock()
+	p, ok := runs.m[id]
+	if !ok || !p.long() {
+		return nil, false
+	}
+	return p, true
+}
+
+// CancelProgress cancels the run started by the request id, and returns whether there was one
+// running for longer than Config.ProgressThreshold. The run stops as its tasks see their context
+// canceled.
+func CancelProgress(id string) bool {
+	p, ok := LookupProgress(id)
+	if !ok {
+		return false
+	}
+	p.SetPhase("canceling")
+	p.cancel()
+	return true
+}
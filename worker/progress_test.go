@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestProgress(t *testing.T) {
+	defer func(d time.Duration) { Config.ProgressThreshold = d }(Config.ProgressThreshold)
+	Config.ProgressThreshold = time.Hour
+
+	ctx, p := StartProgress(context.Background(), RunQuery, "req1")
+	require.Equal(t, p, progressFrom(ctx))
+	// Runs can't be followed before the threshold.
+	_, ok := LookupProgress("req1")
+	require.False(t, ok)
+	require.False(t, CancelProgress("req1"))
+	require.Empty(t, RunningProgress())
+
+	Config.ProgressThreshold = 0
+	p.SetPhase("processing")
+	p.AddTasks(4)
+	p.TaskDone(resultRows(&protos.Result{
+		UidMatrix:   []*protos.List{{Uids: []uint64{1, 2}}, {Uids: []uint64{3}}},
+		ValueMatrix: []*protos.ValueList{{Values: []*protos.TaskValue{{}}}},
+	}))
+	found, ok := LookupProgress("req1")
+	require.True(t, ok)
+	require.Equal(t, p, found)
+	r := p.Report()
+	require.Equal(t, "query", r.Kind)
+	require.Equal(t, "processing", r.Phase)
+	require.EqualValues(t, 4, r.Tasks)
+	require.EqualValues(t, 1, r.TasksDone)
+	require.Equal(t, 25.0, r.Percent)
+	require.EqualValues(t, 4, r.Rows)
+	require.False(t, r.Finished)
+
+	// Another run with the same id isn't registered.
+	_, other := StartProgress(context.Background(), RunExport, "req1")
+	running := RunningProgress()
+	require.Len(t, running, 1)
+	require.Equal(t, "query", running[0].Kind)
+	other.Finish(nil)
+	_, ok = LookupProgress("req1")
+	require.True(t, ok)
+
+	require.True(t, CancelProgress("req1"))
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("The context of the run wasn't canceled")
+	}
+	p.Finish(errors.New("context canceled"))
+	r = p.Report()
+	require.True(t, r.Finished)
+	require.Equal(t, "context canceled", r.Error)
+	_, ok = LookupProgress("req1")
+	require.False(t, ok)
+	require.Empty(t, RunningProgress())
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/raft"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// reportRaftMetrics updates the metrics of the replication of the group of n every second, until
+// it stops.
+func (n *node) reportRaftMetrics() {
+	group := strconv.FormatUint(uint64(n.gid), 10)
+	x.RaftApplyQueueSize.WithLabelValues(group).Set(float64(cap(n.applyCh)))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var peers map[uint64]bool
+	for {
+		select {
+		case <-ticker.C:
+			peers = n.updateRaftMetrics(group, peers)
+		case <-n.done:
+			return
+		}
+	}
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// lag returns how far behind to is from, or 0 if it isn't.
+func lag(from, to uint64) float64 {
+	if to >= from {
+		return 0
+	}
+	return float64(from - to)
+}
+
+// updateRaftMetrics updates the metrics of the group of n, and returns the peers it reported the
+// replication of. Those of peers, the ones reported last time, which aren't anymore are removed.
+func (n *node) updateRaftMetrics(group string, peers map[uint64]bool) map[uint64]bool {
+	status := n.Raft().Status()
+	leader := status.RaftState == raft.StateLeader
+	x.RaftLeader.WithLabelValues(group).Set(boolGauge(leader))
+	x.RaftApplyLag.WithLabelValues(group).Set(lag(status.Commit, n.applied.DoneUntil()))
+	x.RaftApplyQueue.WithLabelValues(group).Set(float64(len(n.applyCh)))
+	x.RaftPendingProposals.WithLabelValues(group).Set(float64(atomic.LoadInt32(&n.pending)))
+
+	reported := make(map[uint64]bool)
+	if last, err := n.store.LastIndex(); leader && err == nil {
+		for id, pr := range status.Progress {
+			if id == n.id {
+				continue
+			}
+			peer := strconv.FormatUint(id, 10)
+			x.RaftReplicationLag.WithLabelValues(group, peer).Set(lag(last, pr.Match))
+			x.RaftPeerSnapshot.WithLabelValues(group, peer).Set(
+				boolGauge(pr.State == raft.ProgressStateSnapshot))
+			reported[id] = true
+		}
+	}
+	for id := range peers {
+		if !reported[id] {
+			peer := strconv.FormatUint(id, 10)
+			x.RaftReplicationLag.DeleteLabelValues(group, peer)
+			x.RaftPeerSnapshot.DeleteLabelValues(group, peer)
+		}
+	}
+	return reported
+}
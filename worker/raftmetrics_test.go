@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"testing"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaftMetrics(t *testing.T) {
+	require.Equal(t, float64(3), lag(10, 7))
+	require.Equal(t, float64(0), lag(7, 10))
+
+	n := newNode(1, 1, "localhost:12345")
+	n.SetRaft(raft.StartNode(n.cfg, []raft.Peer{{ID: 1}}))
+	defer n.Raft().Stop()
+	// Followers don't report the replication of peers, and remove the series they reported as
+	// leaders.
+	peers := n.updateRaftMetrics("1", map[uint64]bool{2: true})
+	require.Empty(t, peers)
+}
@@ -0,0 +1,330 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A predicate is renamed online, in steps proposed to its group, which must also be the group of
+// its new name. Each step is applied in order with the mutations of the group, once those before
+// it are.
+//   START   gives the new name the schema of the old one. From then on, the edges written under
+//           either name are written under both, and reads of the new name are served from the
+//           old one, which has all the data.
+//   COPY    copies the edges of the old name of a batch of nodes to the new name.
+//   FINISH  once all the nodes are copied, deletes the edges of the old name, which keeps its
+//           schema like any deleted predicate, and serves the new name from its own data.
+// Copying the edges of a node again gives the same result, so a rename which didn't finish is
+// resumed by starting it again.
+
+// renameBatch is the number of nodes whose edges are copied by a step.
+const renameBatch = 1000
+
+// RenameState is a rename in progress.
+type RenameState struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Group uint32 `json:"group"`
+}
+
+// Renames returns the renames in progress in the groups served by this server.
+func Renames() []RenameState {
+	var rs []RenameState
+	for _, gid := range groups().KnownGroups() {
+		if !groups().ServesGroup(gid) {
+			continue
+		}
+		for _, attr := range schema.State().Predicates(gid) {
+			if s, ok := schema.State().Get(attr); ok && s.RenamedTo != "" {
+				rs = append(rs, RenameState{From: attr, To: s.RenamedTo, Group: gid})
+			}
+		}
+	}
+	return rs
+}
+
+// servedAttr returns the predicate the reads of attr are served from, which is its old name if
+// it's being renamed.
+func servedAttr(attr string) string {
+	if s, ok := schema.State().Get(attr); ok && s.RenamedFrom != "" {
+		return s.RenamedFrom
+	}
+	return attr
+}
+
+// mirrorRenames adds to m the edges of predicates being renamed under their other name.
+func mirrorRenames(m *protos.Mutations) {
+	var mirrored []*protos.DirectedEdge
+	for _, edge := range m.Edges {
+		s, ok := schema.State().Get(edge.Attr)
+		if !ok || (s.RenamedTo == "" && s.RenamedFrom == "") {
+			continue
+		}
+		e := *edge
+		if e.Attr = s.RenamedTo; e.Attr == "" {
+			e.Attr = s.RenamedFrom
+		}
+		mirrored = append(mirrored, &e)
+	}
+	m.Edges = append(m.Edges, mirrored...)
+}
+
+// running holds the predicates being copied to their new names by this server.
+var running = struct {
+	sync.Mutex
+	m map[string]bool
+}{m: make(map[string]bool)}
+
+// StartRename starts the rename of the predicate from to to, whose group must be served by this
+// server. CopyRenamed must then be called to copy the edges of from, unless it's already being
+// called for the rename of from to to, which was started before and is resumed.
+func StartRename(ctx context.Context, from, to string) error {
+	if from == "" || to == "" || from == to {
+		return x.Errorf("Invalid rename of %q to %q", from, to)
+	}
+	for _, attr := range []string{from, to} {
+		if skipInChangelog(attr) {
+			return x.Errorf("Predicate %s can't be renamed", attr)
+		}
+	}
+	gid := group.BelongsTo(from)
+	if group.BelongsTo(to) != gid {
+		return x.Errorf("Predicate %s belongs to group %d and %s to group %d. Renames must "+
+			"stay in the same group", from, gid, to, group.BelongsTo(to))
+	}
+	if !groups().ServesGroup(gid) {
+		return x.Errorf("Predicate %s must be renamed on a server of group %d", from, gid)
+	}
+	running.Lock()
+	defer running.Unlock()
+	if running.m[from] {
+		return x.Errorf("Predicate %s is already being copied to its new name", from)
+	}
+	r := &protos.Rename{From: from, To: to, Op: protos.Rename_START}
+	if err := proposeRename(ctx, r); err != nil {
+		return err
+	}
+	running.m[from] = true
+	return nil
+}
+
+func proposeRename(ctx context.Context, r *protos.Rename) error {
+	gid := group.BelongsTo(r.From)
+	return groups().Node(gid).ProposeAndWait(ctx, &protos.Proposal{
+		Mutations: &protos.Mutations{GroupId: gid, Rename: r}})
+}
+
+// CopyRenamed copies the edges of from to to, in the background of the mutations and queries of
+// their group, and finishes their rename started by StartRename.
+func CopyRenamed(ctx context.Context, from, to string, progress *Progress) error {
+	defer func() {
+		running.Lock()
+		delete(running.m, from)
+		running.Unlock()
+	}()
+	progress.SetPhase("copying")
+	err := forEachNode(from, func(uids []uint64) error {
+		progress.AddTasks(1)
+		r := &protos.Rename{From: from, To: to, Op: protos.Rename_COPY,
+			Uids: &protos.List{Uids: uids}}
+		if err := proposeRename(ctx, r); err != nil {
+			return err
+		}
+		if err := renameInternalEdges(ctx, from, to, uids); err != nil {
+			return err
+		}
+		progress.TaskDone(len(uids))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	progress.SetPhase("finishing")
+	return proposeRename(ctx, &protos.Rename{From: from, To: to, Op: protos.Rename_FINISH})
+}
+
+// forEachNode calls f with the uids of the nodes having edges of attr stored, renameBatch at a
+// time.
+func forEachNode(attr string, f func(uids []uint64) error) error {
+	iterOpt := badger.DefaultIteratorOptions
+	iterOpt.FetchValues = false
+	it := pstore.NewIterator(iterOpt)
+	defer it.Close()
+	pk := x.ParsedKey{Attr: attr}
+	prefix := pk.DataPrefix()
+	uids := make([]uint64, 0, renameBatch)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		uids = append(uids, x.Parse(it.Item().Key()).Uid)
+		if len(uids) < renameBatch {
+			continue
+		}
+		if err := f(uids); err != nil {
+			return err
+		}
+		uids = make([]uint64, 0, renameBatch)
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+	return f(uids)
+}
+
+// renameInternalEdges replaces from by to in the predicates of the nodes uids, if they're kept.
+func renameInternalEdges(ctx context.Context, from, to string, uids []uint64) error {
+	if !Config.ExpandEdge {
+		return nil
+	}
+	m := new(protos.Mutations)
+	for _, uid := range uids {
+		m.Edges = append(m.Edges, &protos.DirectedEdge{
+			Entity: uid, Attr: "_predicate_", Value: []byte(to), Op: protos.DirectedEdge_SET,
+		}, &protos.DirectedEdge{
+			Entity: uid, Attr: "_predicate_", Value: []byte(from), Op: protos.DirectedEdge_DEL,
+		})
+	}
+	return MutateOverNetwork(ctx, m)
+}
+
+// processRename applies the step r of a rename, at index.
+func (n *node) processRename(index uint64, r *protos.Rename) error {
+	ctx := context.WithValue(n.ctx, "raft", x.RaftValue{Group: n.gid, Index: index})
+	// Like schema changes, the steps see the edges written before them.
+	n.waitForSyncMark(n.ctx, index-1)
+	switch r.Op {
+	case protos.Rename_START:
+		return n.startRename(index, r)
+	case protos.Rename_COPY:
+		return n.copyRenamed(ctx, index, r)
+	case protos.Rename_FINISH:
+		return n.finishRename(ctx, index, r)
+	}
+	return x.Errorf("Unknown step %v of the rename of %s", r.Op, r.From)
+}
+
+func (n *node) startRename(index uint64, r *protos.Rename) error {
+	from, ok := schema.State().Get(r.From)
+	if !ok {
+		return x.Errorf("No predicate %s to rename", r.From)
+	}
+	if from.RenamedTo == r.To {
+		return nil
+	}
+	if from.RenamedTo != "" || from.RenamedFrom != "" {
+		return x.Errorf("Predicate %s is being renamed", r.From)
+	}
+	if from.Rebuild != nil {
+		return x.Errorf("The index of predicate %s is being rebuilt", r.From)
+	}
+	if s, ok := schema.State().Get(r.To); ok && (s.RenamedTo != "" || s.RenamedFrom != "") {
+		return x.Errorf("Predicate %s is being renamed", r.To)
+	}
+	if hasEdges(r.To) {
+		return x.Errorf("Predicate %s already has data", r.To)
+	}
+	to := from
+	to.RenamedFrom = r.From
+	// The new name has its own index, built as the edges are copied.
+	to.IndexAttr, to.Rebuild = "", nil
+	from.RenamedTo = r.To
+	update := to
+	update.Predicate = r.To
+	appendToChangelog(n, index, &protos.Mutations{Schema: []*protos.SchemaUpdate{&update}})
+	updateSchema(r.From, from, index, n.gid)
+	updateSchema(r.To, to, index, n.gid)
+	RecordEvent(EventSchema, n.gid, Config.RaftId, "Renaming %s to %s", r.From, r.To)
+	return nil
+}
+
+// renamedEdge returns the edge of the posting p of the node uid, under attr.
+func renamedEdge(uid uint64, attr string, p *protos.Posting) *protos.DirectedEdge {
+	edge := &protos.DirectedEdge{
+		Entity: uid,
+		Attr:   attr,
+		Label:  p.Label,
+		Facets: p.Facets,
+		Op:     protos.DirectedEdge_SET,
+	}
+	if p.PostingType == protos.Posting_REF {
+		edge.ValueId = p.Uid
+		return edge
+	}
+	edge.Value, edge.ValueType = p.Value, uint32(p.ValType)
+	if p.PostingType == protos.Posting_VALUE_LANG {
+		edge.Lang = string(p.Metadata)
+	}
+	return edge
+}
+
+func (n *node) copyRenamed(ctx context.Context, index uint64, r *protos.Rename) error {
+	if s, ok := schema.State().Get(r.From); !ok || s.RenamedTo != r.To {
+		return x.Errorf("Predicate %s isn't being renamed to %s", r.From, r.To)
+	}
+	var edges []*protos.DirectedEdge
+	for _, uid := range r.Uids.GetUids() {
+		// The edges already copied, or written since, are replaced.
+		edges = append(edges, &protos.DirectedEdge{
+			Entity:    uid,
+			Attr:      r.To,
+			Value:     []byte(x.Star),
+			ValueType: uint32(types.DefaultID),
+			Op:        protos.DirectedEdge_DEL,
+		})
+		pl := posting.GetOrCreate(x.DataKey(r.From, uid), n.gid)
+		pl.IterateValues(func(p *protos.Posting) bool {
+			edges = append(edges, renamedEdge(uid, r.To, p))
+			return true
+		})
+	}
+	appendToChangelog(n, index, &protos.Mutations{Edges: edges})
+	for _, edge := range edges {
+		if err := runMutation(ctx, edge); err != nil {
+			return x.Wrapf(err, "While copying the edges of %s to %s", r.From, r.To)
+		}
+	}
+	return nil
+}
+
+func (n *node) finishRename(ctx context.Context, index uint64, r *protos.Rename) error {
+	from, ok := schema.State().Get(r.From)
+	if !ok || from.RenamedTo != r.To {
+		return x.Errorf("Predicate %s isn't being renamed to %s", r.From, r.To)
+	}
+	to, _ := schema.State().Get(r.To)
+	appendToChangelog(n, index, &protos.Mutations{Edges: []*protos.DirectedEdge{{
+		Attr: r.From, Value: []byte(x.Star), Op: protos.DirectedEdge_DEL,
+	}}})
+	if err := posting.DeletePredicate(ctx, r.From); err != nil {
+		return err
+	}
+	from.RenamedTo, to.RenamedFrom = "", ""
+	updateSchema(r.From, from, index, n.gid)
+	updateSchema(r.To, to, index, n.gid)
+	RecordEvent(EventSchema, n.gid, Config.RaftId, "Renamed %s to %s", r.From, r.To)
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// A predicate declared with @required(Kind, ...) must have a value on all the nodes of those
+// kinds, which are the nodes with the kind among their values of Config.KindPredicate, in the
+// namespace of the predicate. Values with a language don't count. Mutations are checked against
+// the nodes as they are before being proposed, and one which would leave a node of a kind without
+// a predicate it requires is rejected as a whole. Mutations of the same nodes sent at the same
+// time aren't ordered against each other, as each group applies its edges on its own.
+
+// RequiredError is returned for mutations which would leave a node of Kind without a value of
+// Predicate.
+type RequiredError struct {
+	Uid       uint64
+	Kind      string
+	Predicate string
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("Node %#x of kind %s must have a value of %s", e.Uid, e.Kind, e.Predicate)
+}
+
+// nodeValues are the values of a predicate on a node, by edgeValue.
+type nodeValues map[string]bool
+
+// edgeValue returns the value of edge, as it's compared with the values of nodes.
+func edgeValue(edge *protos.DirectedEdge) string {
+	if len(edge.Value) == 0 {
+		return fmt.Sprintf("%#x", edge.ValueId)
+	}
+	return storedValue(edge.Value, edge.ValueType)
+}
+
+func storedValue(val []byte, typ uint32) string {
+	v, err := types.Convert(types.Val{Tid: types.TypeID(typ), Value: val}, types.StringID)
+	if err != nil {
+		return string(val)
+	}
+	return v.Value.(string)
+}
+
+// apply applies the edges of a predicate to the values of a node. Untagged values of predicates
+// which aren't lists all take the same place, so deleting any of them deletes it.
+func (vals nodeValues) apply(edges []*protos.DirectedEdge, list bool) {
+	for _, edge := range edges {
+		if len(edge.Lang) > 0 {
+			continue
+		}
+		switch {
+		case edge.Op == protos.DirectedEdge_SET:
+			if !list && len(edge.Value) > 0 {
+				for v := range vals {
+					delete(vals, v)
+				}
+			}
+			vals[edgeValue(edge)] = true
+		case string(edge.Value) == x.Star, !list && len(edge.Value) > 0:
+			for v := range vals {
+				delete(vals, v)
+			}
+		default:
+			delete(vals, edgeValue(edge))
+		}
+	}
+}
+
+// fetchValues returns the values of attr on the nodes uids, as they are.
+func fetchValues(ctx context.Context, attr string, uids []uint64) (map[uint64]nodeValues, error) {
+	res, err := ProcessTaskOverNetwork(ctx, &protos.Query{
+		Attr:    attr,
+		UidList: &protos.List{Uids: uids},
+	})
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[uint64]nodeValues, len(uids))
+	for i, uid := range uids {
+		nv := make(nodeValues)
+		if i < len(res.UidMatrix) {
+			for _, v := range res.UidMatrix[i].Uids {
+				nv[fmt.Sprintf("%#x", v)] = true
+			}
+		}
+		if i < len(res.ValueMatrix) {
+			for _, v := range res.ValueMatrix[i].Values {
+				if len(v.Val) > 0 {
+					nv[storedValue(v.Val, uint32(v.ValType))] = true
+				}
+			}
+		}
+		vals[uid] = nv
+	}
+	return vals, nil
+}
+
+// checkRequired checks that m doesn't leave nodes without the predicates required by their kinds.
+func checkRequired(ctx context.Context, m *protos.Mutations) error {
+	if len(m.Edges) == 0 || Config.KindPredicate == "" {
+		return nil
+	}
+	// The edges of each node, by predicate. Nodes with all their predicates deleted lose their
+	// kinds, and keep only the edges set.
+	edges := make(map[uint64]map[string][]*protos.DirectedEdge)
+	cleared := make(map[uint64]bool)
+	attrs := make(map[string]bool)
+	// Predicates deleted from all nodes.
+	dropped := make(map[string]bool)
+	var kindChanged bool
+	for _, edge := range m.Edges {
+		if edge.Attr == x.Star {
+			cleared[edge.Entity] = true
+			continue
+		}
+		attrs[edge.Attr] = true
+		if _, name := x.ParseNamespacedAttr(edge.Attr); name == Config.KindPredicate {
+			kindChanged = true
+		}
+		if edge.Entity == 0 {
+			dropped[edge.Attr] = true
+			continue
+		}
+		if edges[edge.Entity] == nil {
+			edges[edge.Entity] = make(map[string][]*protos.DirectedEdge)
+		}
+		edges[edge.Entity][edge.Attr] = append(edges[edge.Entity][edge.Attr], edge)
+	}
+
+	// Nodes given a kind can need any predicate, otherwise only those mutated can be missing.
+	req := &protos.SchemaRequest{Fields: []string{"required", "list"}}
+	if !kindChanged {
+		for attr := range attrs {
+			req.Predicates = append(req.Predicates, attr)
+		}
+	}
+	nodes, err := GetSchemaOverNetwork(ctx, req)
+	if err != nil {
+		return err
+	}
+	// The predicates required by each kind, of each namespace.
+	required := make(map[string]map[string][]string)
+	list := make(map[string]bool)
+	for _, n := range nodes {
+		list[n.Predicate] = n.List
+		if len(n.Required) == 0 {
+			continue
+		}
+		ns, _ := x.ParseNamespacedAttr(n.Predicate)
+		if required[ns] == nil {
+			required[ns] = make(map[string][]string)
+		}
+		for _, kind := range n.Required {
+			required[ns][kind] = append(required[ns][kind], n.Predicate)
+		}
+		if dropped[n.Predicate] {
+			_, name := x.ParseNamespacedAttr(n.Predicate)
+			return x.Errorf("Predicate %s is required for nodes of kind %s", name, n.Required[0])
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+
+	var uids []uint64
+	for uid := range edges {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	// The values of each predicate fetched, by node.
+	fetched := make(map[string]map[uint64]nodeValues)
+	valuesOf := func(attr string, uid uint64) (nodeValues, error) {
+		if fetched[attr] == nil {
+			vals, err := fetchValues(ctx, attr, uids)
+			if err != nil {
+				return nil, err
+			}
+			fetched[attr] = vals
+		}
+		vals := make(nodeValues)
+		if !cleared[uid] {
+			for v := range fetched[attr][uid] {
+				vals[v] = true
+			}
+		}
+		vals.apply(edges[uid][attr], list[attr])
+		return vals, nil
+	}
+
+	for _, uid := range uids {
+		for ns, kinds := range required {
+			kindAttr := x.NamespacedAttr(ns, Config.KindPredicate)
+			nodeKinds, err := valuesOf(kindAttr, uid)
+			if err != nil {
+				return err
+			}
+			for kind, preds := range kinds {
+				if !nodeKinds[kind] {
+					continue
+				}
+				for _, pred := range preds {
+					vals, err := valuesOf(pred, uid)
+					if err != nil {
+						return err
+					}
+					if len(vals) == 0 {
+						_, name := x.ParseNamespacedAttr(pred)
+						return x.Wrap(&RequiredError{Uid: uid, Kind: kind, Predicate: name})
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
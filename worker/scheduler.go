@@ -33,6 +33,7 @@ type task struct {
 	rid  uint64 // raft index corresponding to the task
 	pid  uint32 // proposal id corresponding to the task
 	edge *protos.DirectedEdge
+	size int64 // bytes of the edge, counted as pending mutations until it's applied
 }
 
 type scheduler struct {
@@ -63,6 +64,7 @@ func (s *scheduler) processTasks() {
 			err := s.n.processMutation(nextTask.pid, nextTask.rid, nextTask.edge)
 			n.props.Done(nextTask.pid, err)
 			x.ActiveMutations.Add(-1)
+			pendingMutations.Add(-nextTask.size)
 			nextTask = s.nextTask(nextTask)
 		}
 	}
@@ -100,6 +102,24 @@ func (s *scheduler) schedule(proposal *protos.Proposal, index uint64) error {
 			return err
 		}
 	}
+	if typs := proposal.Mutations.Types; len(typs) > 0 {
+		if err := s.n.processTypes(index, typs); err != nil {
+			s.n.props.Done(proposal.Id, err)
+			return err
+		}
+	}
+	if r := proposal.Mutations.Rename; r != nil {
+		if err := s.n.processRename(index, r); err != nil {
+			s.n.props.Done(proposal.Id, err)
+			return err
+		}
+	}
+	if r := proposal.Mutations.IndexRebuild; r != nil {
+		if err := s.n.processIndexRebuild(index, r); err != nil {
+			s.n.props.Done(proposal.Id, err)
+			return err
+		}
+	}
 	if total == 0 {
 		s.n.props.Done(proposal.Id, nil)
 		return nil
@@ -134,7 +154,9 @@ func (s *scheduler) schedule(proposal *protos.Proposal, index uint64) error {
 			rid:  index,
 			pid:  proposal.Id,
 			edge: edge,
+			size: int64(edge.Size()),
 		}
+		pendingMutations.Add(t.size)
 		if s.register(t) {
 			s.tch <- t
 		}
@@ -21,6 +21,7 @@ import (
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
 
+	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/dgraph/group"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
@@ -39,8 +40,12 @@ type resultErr struct {
 
 // getSchema iterates over all predicates and populates the asked fields, if list of
 // predicates is not specified, then all the predicates belonging to the group
-// are returned
+// are returned. If the only field asked is types, the declarations of types are returned
+// instead.
 func getSchema(ctx context.Context, s *protos.SchemaRequest) (*protos.SchemaResult, error) {
+	if len(s.Fields) == 1 && s.Fields[0] == "types" {
+		return getTypes(s)
+	}
 	var result protos.SchemaResult
 	var predicates []string
 	var fields []string
@@ -52,7 +57,8 @@ func getSchema(ctx context.Context, s *protos.SchemaRequest) (*protos.SchemaResu
 	if len(s.Fields) > 0 {
 		fields = s.Fields
 	} else {
-		fields = []string{"type", "index", "tokenizer", "reverse", "count", "list"}
+		fields = []string{"type", "index", "tokenizer", "reverse", "count", "list", "required",
+			"default", "noreplace", "ondelete"}
 	}
 
 	for _, attr := range predicates {
@@ -77,6 +83,8 @@ func populateSchema(attr string, fields []string) *protos.SchemaNode {
 		return nil
 	}
 	schemaNode.Predicate = attr
+	// Whether the keys of the predicate were scanned for its cardinality and size.
+	var scanned bool
 	for _, field := range fields {
 		switch field {
 		case "type":
@@ -93,6 +101,27 @@ func populateSchema(attr string, fields []string) *protos.SchemaNode {
 			schemaNode.Count = schema.State().HasCount(attr)
 		case "list":
 			schemaNode.List = schema.State().IsList(attr)
+		case "required":
+			schemaNode.Required = schema.State().Required(attr)
+		case "default":
+			if s, ok := schema.State().Get(attr); ok {
+				schemaNode.Default = s.Default
+			}
+		case "noreplace":
+			schemaNode.Noreplace = schema.State().IsNoreplace(attr)
+		case "ondelete":
+			if s, ok := schema.State().Get(attr); ok {
+				schemaNode.Ondelete = s.Ondelete
+			}
+		case "lang":
+			schemaNode.Lang = typ == types.StringID || typ == types.DefaultID
+		case "geo":
+			schemaNode.Geo = geoIndex(attr)
+		case "cardinality", "size":
+			if !scanned {
+				schemaNode.Cardinality, schemaNode.Size_ = predicateStats(attr)
+				scanned = true
+			}
 		default:
 			//pass
 		}
@@ -100,6 +129,51 @@ func populateSchema(attr string, fields []string) *protos.SchemaNode {
 	return &schemaNode
 }
 
+// geoIndex returns the parameters of the geo index of attr, if it has one.
+func geoIndex(attr string) *protos.GeoIndex {
+	if !schema.State().IsIndexed(attr) {
+		return nil
+	}
+	for _, name := range schema.State().TokenizerNames(attr) {
+		if name == "geohash" {
+			return schema.State().GeoIndex(attr)
+		}
+		if name == "geo" {
+			if gi := schema.State().GeoIndex(attr); gi != nil {
+				return gi
+			}
+			return &protos.GeoIndex{
+				MinLevel: types.MinCellLevel,
+				MaxLevel: types.MaxCellLevel,
+				MaxCells: types.MaxCells,
+			}
+		}
+	}
+	return nil
+}
+
+// predicateStats returns the number of nodes with a value of attr, and the bytes of the keys and
+// values of its data, large values, indexes, reverse edges and retained versions, as they're
+// stored. Mutations which aren't synced to disk yet aren't counted, so they're approximate.
+func predicateStats(attr string) (nodes uint64, size uint64) {
+	pk := x.ParsedKey{Attr: attr}
+	ipk := x.ParsedKey{Attr: schema.State().IndexAttr(attr)}
+	prefixes := [][]byte{pk.DataPrefix(), pk.BlobPrefix(), ipk.IndexPrefix(), pk.ReversePrefix(),
+		pk.CountPrefix(false), pk.CountPrefix(true), pk.VersionPrefix()}
+	it := pstore.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	for i, prefix := range prefixes {
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			if i == 0 {
+				nodes++
+			}
+			size += uint64(len(item.Key()) + len(item.Value()))
+		}
+	}
+	return nodes, size
+}
+
 // addToSchemaMap groups the predicates by group id, if list of predicates is
 // empty then it adds all known groups
 func addToSchemaMap(schemaMap map[uint32]*protos.SchemaRequest, schema *protos.SchemaRequest) {
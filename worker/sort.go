@@ -248,7 +248,7 @@ func processSort(ctx context.Context, ts *protos.SortMessage) (*protos.SortResul
 			"Try flipping order and return first few elements instead.", ts.Attr, ts.Count)
 	}
 	attrData := strings.Split(ts.Attr, "@")
-	ts.Attr = attrData[0]
+	ts.Attr = servedAttr(attrData[0])
 	if len(attrData) == 2 {
 		ts.Langs = strings.Split(attrData[1], ":")
 	}
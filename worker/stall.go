@@ -0,0 +1,399 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/raft"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Proposals and read tasks are tracked while they run. Once one has been running for longer than
+// Config.StallDeadline, the server is taken to be stalled, and a diagnostics bundle is written to
+// Config.DiagnosticsPath: a gzipped tar of the goroutines, the state of the raft groups served
+// here and the queues of pending work, for support cases. Bundles are written at most once per
+// deadline, and the last diagnosticsKept are kept.
+
+// diagnosticsKept is the number of the last bundles kept in Config.DiagnosticsPath.
+const diagnosticsKept = 10
+
+// Kinds of operations tracked.
+const (
+	opProposal = "proposal"
+	opTask     = "task"
+)
+
+type operation struct {
+	Kind  string    `json:"kind"`
+	Group uint32    `json:"group"`
+	Desc  string    `json:"desc"`
+	Start time.Time `json:"start"`
+	Age   string    `json:"age"`
+}
+
+var operations = struct {
+	sync.Mutex
+	m    map[uint64]*operation
+	next uint64
+	// last is when the last bundle was written for a stall.
+	last time.Time
+}{m: make(map[uint64]*operation)}
+
+// trackOperation tracks an operation of kind for group until the returned func is called.
+func trackOperation(kind string, group uint32, desc string) func() {
+	operations.Lock()
+	operations.next++
+	id := operations.next
+	operations.m[id] = &operation{Kind: kind, Group: group, Desc: desc, Start: time.Now()}
+	operations.Unlock()
+	return func() {
+		operations.Lock()
+		delete(operations.m, id)
+		operations.Unlock()
+	}
+}
+
+func proposalDesc(p *protos.Proposal) string {
+	switch {
+	case p.Mutations != nil && p.Mutations.Rename != nil:
+		return fmt.Sprintf("rename of %s to %s", p.Mutations.Rename.From, p.Mutations.Rename.To)
+	case p.Mutations != nil && p.Mutations.IndexRebuild != nil:
+		return fmt.Sprintf("rebuild of the index of %s", p.Mutations.IndexRebuild.Attr)
+	case p.Mutations != nil && len(p.Mutations.Types) > 0 && len(p.Mutations.Edges) == 0:
+		return fmt.Sprintf("declaration of %d types", len(p.Mutations.Types))
+	case p.Mutations != nil:
+		return fmt.Sprintf("mutation of %d edges and %d schema updates", len(p.Mutations.Edges),
+			len(p.Mutations.Schema))
+	case p.Membership != nil:
+		return fmt.Sprintf("membership update of node %d", p.Membership.Id)
+	}
+	return "proposal"
+}
+
+func taskDesc(q *protos.Query) string {
+	desc := q.Attr
+	if len(q.SrcFunc) > 0 {
+		desc = fmt.Sprintf("%s(%s)", q.SrcFunc[0], q.Attr)
+	}
+	if q.UidList != nil {
+		desc += fmt.Sprintf(" over %d uids", len(q.UidList.Uids))
+	}
+	return desc
+}
+
+// runningOperations returns the operations running, from the oldest.
+func runningOperations() []operation {
+	operations.Lock()
+	ops := make([]operation, 0, len(operations.m))
+	for _, op := range operations.m {
+		ops = append(ops, *op)
+	}
+	operations.Unlock()
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Start.Before(ops[j].Start) })
+	for i := range ops {
+		ops[i].Age = x.Round(time.Since(ops[i].Start)).String()
+	}
+	return ops
+}
+
+// stalled returns the operations running for longer than Config.StallDeadline.
+func stalled() []operation {
+	var ops []operation
+	for _, op := range runningOperations() {
+		if time.Since(op.Start) >= Config.StallDeadline {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// detectStalls checks for stalled operations every tenth of Config.StallDeadline, and writes a
+// bundle once it finds some, unless one was written within the deadline.
+func detectStalls() {
+	if Config.StallDeadline <= 0 {
+		return
+	}
+	ctx := context.Background()
+	ticker := time.NewTicker(Config.StallDeadline / 10)
+	defer ticker.Stop()
+	for range ticker.C {
+		ops := stalled()
+		if len(ops) == 0 {
+			continue
+		}
+		operations.Lock()
+		recent := time.Since(operations.last) < Config.StallDeadline
+		if !recent {
+			operations.last = time.Now()
+		}
+		operations.Unlock()
+		if recent {
+			continue
+		}
+		x.Stalls.Add(1)
+		reason := fmt.Sprintf("%d operations running for longer than %v, the oldest a %s of "+
+			"group %d: %s", len(ops), Config.StallDeadline, ops[0].Kind, ops[0].Group, ops[0].Desc)
+		workerLog.Errorf(ctx, "Stall detected: %s", reason)
+		if name, err := WriteDiagnostics(reason); err != nil {
+			workerLog.Errorf(ctx, "Error while writing diagnostics bundle: %v", err)
+		} else {
+			workerLog.Warningf(ctx, "Wrote diagnostics bundle %s", name)
+		}
+	}
+}
+
+type raftPeer struct {
+	Id    uint64 `json:"id"`
+	Match uint64 `json:"match"`
+	Next  uint64 `json:"next"`
+	State string `json:"state"`
+}
+
+type raftState struct {
+	Group     uint32     `json:"group"`
+	Id        uint64     `json:"id"`
+	State     string     `json:"state"`
+	Term      uint64     `json:"term"`
+	Leader    uint64     `json:"leader"`
+	Commit    uint64     `json:"commit"`
+	Applied   uint64     `json:"applied"`
+	LastIndex uint64     `json:"last_index"`
+	Peers     []raftPeer `json:"peers,omitempty"`
+}
+
+type queues struct {
+	Group uint32 `json:"group"`
+	// Proposals is the number of proposals of ProposeAndWait waiting to be applied, and Tracked
+	// that of the proposals, from here or others, whose mutations are being applied.
+	Proposals int32 `json:"proposals"`
+	Tracked   int   `json:"tracked_proposals"`
+	// Apply is the number of committed entries waiting to be applied, and Mutations that of the
+	// mutations waiting for the scheduler.
+	Apply     int `json:"apply"`
+	Mutations int `json:"mutations"`
+}
+
+func (n *node) raftState() raftState {
+	status := n.Raft().Status()
+	s := raftState{
+		Group:   n.gid,
+		Id:      n.id,
+		State:   status.RaftState.String(),
+		Term:    status.Term,
+		Leader:  status.Lead,
+		Commit:  status.Commit,
+		Applied: n.applied.DoneUntil(),
+	}
+	s.LastIndex, _ = n.store.LastIndex()
+	if status.RaftState == raft.StateLeader {
+		for id, pr := range status.Progress {
+			s.Peers = append(s.Peers, raftPeer{
+				Id: id, Match: pr.Match, Next: pr.Next, State: pr.State.String(),
+			})
+		}
+		sort.Slice(s.Peers, func(i, j int) bool { return s.Peers[i].Id < s.Peers[j].Id })
+	}
+	return s
+}
+
+func (n *node) queues() queues {
+	n.props.RLock()
+	tracked := len(n.props.ids)
+	n.props.RUnlock()
+	return queues{
+		Group:     n.gid,
+		Proposals: atomic.LoadInt32(&n.pending),
+		Tracked:   tracked,
+		Apply:     len(n.applyCh),
+		Mutations: len(n.sch.tch),
+	}
+}
+
+// diagnostics returns the files of a bundle written for reason.
+func diagnostics(reason string) (map[string][]byte, error) {
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		return nil, err
+	}
+	var rafts []raftState
+	var qs []queues
+	if gr != nil {
+		for _, n := range groups().nodes() {
+			if n.Raft() == nil {
+				continue
+			}
+			rafts = append(rafts, n.raftState())
+			qs = append(qs, n.queues())
+		}
+	}
+	sort.Slice(rafts, func(i, j int) bool { return rafts[i].Group < rafts[j].Group })
+	sort.Slice(qs, func(i, j int) bool { return qs[i].Group < qs[j].Group })
+
+	files := map[string][]byte{"goroutines.txt": goroutines.Bytes()}
+	for name, v := range map[string]interface{}{
+		"info.json": map[string]interface{}{
+			"time":   time.Now(),
+			"reason": reason,
+			"addr":   Config.MyAddr,
+		},
+		"raft.json": rafts,
+		"pending.json": map[string]interface{}{
+			"operations":         runningOperations(),
+			"queues":             qs,
+			"proposal_slots":     len(pendingProposals),
+			"max_proposal_slots": cap(pendingProposals),
+		},
+	} {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[name] = b
+	}
+	return files, nil
+}
+
+// WriteDiagnostics writes a bundle for reason to Config.DiagnosticsPath, and returns its name.
+// The bundles past the last diagnosticsKept are removed.
+func WriteDiagnostics(reason string) (string, error) {
+	files, err := diagnostics(reason)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(Config.DiagnosticsPath, 0700); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("dgraph-diagnostics-%s.tar.gz",
+		time.Now().UTC().Format("2006-01-02T15-04-05.000"))
+	f, err := os.OpenFile(filepath.Join(Config.DiagnosticsPath, name),
+		os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = writeBundle(f, files)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, pruneDiagnostics()
+}
+
+func writeBundle(w io.Writer, files map[string][]byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// DiagnosticsBundle is a bundle in Config.DiagnosticsPath.
+type DiagnosticsBundle struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// DiagnosticsBundles returns the bundles in Config.DiagnosticsPath, from the oldest.
+func DiagnosticsBundles() ([]DiagnosticsBundle, error) {
+	fis, err := ioutil.ReadDir(Config.DiagnosticsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bundles []DiagnosticsBundle
+	for _, fi := range fis {
+		if !isDiagnosticsBundle(fi.Name()) {
+			continue
+		}
+		bundles = append(bundles, DiagnosticsBundle{
+			Name: fi.Name(), Time: fi.ModTime(), Size: fi.Size(),
+		})
+	}
+	// Their names sort by time.
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Name < bundles[j].Name })
+	return bundles, nil
+}
+
+func isDiagnosticsBundle(name string) bool {
+	return strings.HasPrefix(name, "dgraph-diagnostics-") && strings.HasSuffix(name, ".tar.gz") &&
+		filepath.Base(name) == name
+}
+
+// OpenDiagnostics opens the bundle name.
+func OpenDiagnostics(name string) (*os.File, error) {
+	if !isDiagnosticsBundle(name) {
+		return nil, x.Errorf("Invalid diagnostics bundle: %q", name)
+	}
+	return os.Open(filepath.Join(Config.DiagnosticsPath, name))
+}
+
+func pruneDiagnostics() error {
+	bundles, err := DiagnosticsBundles()
+	if err != nil {
+		return err
+	}
+	for len(bundles) > diagnosticsKept {
+		if err := os.Remove(filepath.Join(Config.DiagnosticsPath, bundles[0].Name)); err != nil {
+			return err
+		}
+		bundles = bundles[1:]
+	}
+	return nil
+}
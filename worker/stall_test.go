@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestStalledOperations(t *testing.T) {
+	defer func(d time.Duration) { Config.StallDeadline = d }(Config.StallDeadline)
+	Config.StallDeadline = time.Hour
+
+	done := trackOperation(opTask, 1, taskDesc(&protos.Query{
+		Attr: "name", SrcFunc: []string{"anyofterms", "Alice"},
+	}))
+	require.Empty(t, stalled())
+	Config.StallDeadline = time.Nanosecond
+	ops := stalled()
+	require.Len(t, ops, 1)
+	require.Equal(t, "task", ops[0].Kind)
+	require.Equal(t, "anyofterms(name)", ops[0].Desc)
+	done()
+	require.Empty(t, stalled())
+}
+
+func TestDiagnosticsBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diagnostics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(p string) { Config.DiagnosticsPath = p }(Config.DiagnosticsPath)
+	Config.DiagnosticsPath = dir
+
+	done := trackOperation(opProposal, 1, proposalDesc(&protos.Proposal{
+		Mutations: &protos.Mutations{Edges: []*protos.DirectedEdge{{Attr: "name"}}},
+	}))
+	defer done()
+	name, err := WriteDiagnostics("testing")
+	require.NoError(t, err)
+
+	f, err := OpenDiagnostics(name)
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = b
+	}
+	require.Contains(t, string(files["goroutines.txt"]), "TestDiagnosticsBundle")
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["info.json"], &info))
+	require.Equal(t, "testing", info["reason"])
+	var pending struct {
+		Operations []operation `json:"operations"`
+	}
+	require.NoError(t, json.Unmarshal(files["pending.json"], &pending))
+	require.Len(t, pending.Operations, 1)
+	require.Equal(t, "mutation of 1 edges and 0 schema updates", pending.Operations[0].Desc)
+	require.Contains(t, files, "raft.json")
+
+	// Only the last bundles are kept, and others can't be opened.
+	for i := 0; i < diagnosticsKept; i++ {
+		time.Sleep(2 * time.Millisecond)
+		_, err := WriteDiagnostics("testing")
+		require.NoError(t, err)
+	}
+	bundles, err := DiagnosticsBundles()
+	require.NoError(t, err)
+	require.Len(t, bundles, diagnosticsKept)
+	require.NotEqual(t, name, bundles[0].Name)
+	_, err = OpenDiagnostics("../p/000001.vlog")
+	require.Error(t, err)
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Subscriptions stream the changes committed to the groups served here, read from their changelogs
+// like the change feed, as a ChangeEvent for each entry. Edges of predicates with filters are only
+// sent if their values match all of them. Edges to nodes never match a filter, while deletions of
+// all the values of a predicate always do, so that a view built from the changes can drop them.
+
+// ValidateChangeFilters checks that the filters of a subscription are valid.
+func ValidateChangeFilters(filters []*protos.ChangeFilter) error {
+	for _, f := range filters {
+		if f.Predicate == "" {
+			return x.Errorf("Filter without a predicate")
+		}
+		switch f.Op {
+		case "eq", "lt", "le", "gt", "ge":
+		default:
+			return x.Errorf("Invalid op %q in filter of %s, expected eq, lt, le, gt or ge",
+				f.Op, f.Predicate)
+		}
+	}
+	return nil
+}
+
+// changeVal returns v as a value which can be compared with those of filters.
+func changeVal(v *protos.Value) (types.Val, bool) {
+	switch v.Val.(type) {
+	case *protos.Value_DefaultVal:
+		return types.Val{Tid: types.StringID, Value: v.GetDefaultVal()}, true
+	case *protos.Value_StrVal:
+		return types.Val{Tid: types.StringID, Value: v.GetStrVal()}, true
+	case *protos.Value_IntVal:
+		return types.Val{Tid: types.IntID, Value: v.GetIntVal()}, true
+	case *protos.Value_DoubleVal:
+		return types.Val{Tid: types.FloatID, Value: v.GetDoubleVal()}, true
+	case *protos.Value_BoolVal:
+		return types.Val{Tid: types.BoolID, Value: v.GetBoolVal()}, true
+	case *protos.Value_DatetimeVal:
+		t, err := types.Convert(types.Val{Tid: types.BinaryID, Value: v.GetDatetimeVal()},
+			types.DateTimeID)
+		return t, err == nil
+	}
+	return types.Val{}, false
+}
+
+// matchChangeFilters returns whether the edge of nq matches the filters of its predicate.
+func matchChangeFilters(filters []*protos.ChangeFilter, nq *protos.NQuad) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	if len(nq.ObjectId) > 0 || nq.ObjectValue == nil {
+		return false
+	}
+	if nq.ObjectValue.GetDefaultVal() == x.Star {
+		return true
+	}
+	val, ok := changeVal(nq.ObjectValue)
+	if !ok {
+		return false
+	}
+	for _, f := range filters {
+		ref, err := types.Convert(types.Val{Tid: types.StringID, Value: []byte(f.Value)}, val.Tid)
+		if err != nil || !types.CompareVals(f.Op, val, ref) {
+			return false
+		}
+	}
+	return true
+}
+
+// changeEvent returns the event of change, with the edges matching filters, or nil if there are
+// none.
+func changeEvent(c *Change, filters map[string][]*protos.ChangeFilter) (*protos.ChangeEvent,
+	error) {
+	ev := &protos.ChangeEvent{GroupId: c.Group, Index: c.Index, CommitTs: c.Time.UnixNano()}
+	for _, line := range c.Lines {
+		if strings.HasPrefix(line, "schema ") {
+			continue
+		}
+		nq, del, err := parseChangelogEdge(line)
+		if err != nil {
+			return nil, x.Wrapf(err, "While reading entry %d of changelog of group %d",
+				c.Index, c.Group)
+		}
+		if !matchChangeFilters(filters[nq.Predicate], &nq) {
+			continue
+		}
+		if del {
+			ev.Del = append(ev.Del, &nq)
+		} else {
+			ev.Set = append(ev.Set, &nq)
+		}
+	}
+	if len(ev.Set) == 0 && len(ev.Del) == 0 {
+		return nil, nil
+	}
+	return ev, nil
+}
+
+// SubscribeChanges calls send with the changes committed to the groups served here, to the
+// predicates of req, from its since time on, until ctx is done or send fails.
+func SubscribeChanges(ctx context.Context, req *protos.SubscribeRequest,
+	send func(*protos.ChangeEvent) error) error {
+	if !Config.Changelog {
+		return x.Errorf("Subscriptions need the changelog (--changelog)")
+	}
+	if err := ValidatePatterns(req.Predicates); err != nil {
+		return err
+	}
+	if err := ValidateChangeFilters(req.Filters); err != nil {
+		return err
+	}
+	filters := make(map[string][]*protos.ChangeFilter)
+	for _, f := range req.Filters {
+		filters[f.Predicate] = append(filters[f.Predicate], f)
+	}
+	// Without a time, changes are sent from now on.
+	since := time.Now()
+	if req.Since != 0 {
+		since = time.Unix(0, req.Since)
+	}
+
+	changed, stop := WatchChanges(req.Predicates)
+	defer stop()
+	readers := make(map[uint32]*ChangeReader)
+	for {
+		for _, gid := range ChangeGroups() {
+			cr, ok := readers[gid]
+			if !ok {
+				var err error
+				if cr, err = NewChangeReader(gid, 0, since, req.Predicates); err != nil {
+					return err
+				}
+				readers[gid] = cr
+			}
+			err := cr.Read(func(c *Change) error {
+				ev, err := changeEvent(c, filters)
+				if err != nil || ev == nil {
+					return err
+				}
+				return send(ev)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
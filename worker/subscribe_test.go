@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func TestChangeEvent(t *testing.T) {
+	require.Error(t, ValidateChangeFilters([]*protos.ChangeFilter{{Predicate: "age", Op: "ne"}}))
+	filters := map[string][]*protos.ChangeFilter{
+		"age":  {{Predicate: "age", Op: "ge", Value: "18"}, {Predicate: "age", Op: "lt", Value: "65"}},
+		"name": {{Predicate: "name", Op: "eq", Value: "Alice"}},
+	}
+	require.NoError(t, ValidateChangeFilters(filters["age"]))
+
+	now := time.Now()
+	c := &Change{Group: 1, Index: 42, Time: now, Lines: []string{
+		"schema age: int .",
+		`+ <_:uid1> <name> "Alice"@en .`,
+		`+ <_:uid2> <name> "Bob" .`,
+		`+ <_:uid1> <age> "30"^^<xs:int> .`,
+		`+ <_:uid2> <age> "12"^^<xs:int> .`,
+		`- <_:uid3> <age> * .`,
+		`+ <_:uid1> <friend> <_:uid2> .`,
+	}}
+	ev, err := changeEvent(c, filters)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), ev.GroupId)
+	require.Equal(t, uint64(42), ev.Index)
+	require.Equal(t, now.UnixNano(), ev.CommitTs)
+	var set []string
+	for _, nq := range ev.Set {
+		set = append(set, nq.Subject+" "+nq.Predicate)
+	}
+	require.Equal(t, []string{"0x1 name", "0x1 age", "0x1 friend"}, set)
+	require.Equal(t, "0x2", ev.Set[2].ObjectId)
+	require.Equal(t, int64(30), ev.Set[1].ObjectValue.GetIntVal())
+	// Deletions of all the values of a predicate are sent whatever its filters.
+	require.Len(t, ev.Del, 1)
+	require.Equal(t, "0x3", ev.Del[0].Subject)
+
+	// Entries without edges matching the filters aren't sent.
+	c.Lines = []string{`+ <_:uid2> <age> "70"^^<xs:int> .`}
+	ev, err = changeEvent(c, filters)
+	require.NoError(t, err)
+	require.Nil(t, ev)
+}
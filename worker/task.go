@@ -28,6 +28,8 @@ import (
 	"github.com/dgraph-io/badger"
 	"golang.org/x/net/context"
 	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/dgraph-io/dgraph/algo"
 	"github.com/dgraph-io/dgraph/group"
@@ -36,8 +38,10 @@ import (
 	"github.com/dgraph-io/dgraph/schema"
 	ctask "github.com/dgraph-io/dgraph/task"
 	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/tracing"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/udf"
 	"github.com/dgraph-io/dgraph/x"
 
 	cindex "github.com/google/codesearch/index"
@@ -138,25 +142,68 @@ func ProcessTaskOverNetwork(ctx context.Context, q *protos.Query) (*protos.Resul
 	if tr, ok := trace.FromContext(ctx); ok {
 		tr.LazyPrintf("attr: %v groupId: %v", attr, gid)
 	}
+	span, ctx := tracing.Start(ctx, "task")
+	defer span.Finish()
+	span.SetTag("attr", attr)
+	span.SetTag("group", gid)
+	stats := taskStatsFrom(ctx)
+	start := time.Now()
+	stat := &TaskStat{Attr: attr, Group: gid}
+	if len(q.SrcFunc) > 0 {
+		stat.Func = strings.ToLower(q.SrcFunc[0])
+	}
+	progress := progressFrom(ctx)
+	progress.AddTasks(1)
 
 	if groups().ServesGroup(gid) {
 		// No need for a network call, as this should be run from within this instance.
-		return processTask(ctx, q, gid)
+		span.SetTag("local", true)
+		workerLog.Debugf(ctx, "Processing task for %q of group %d locally", attr, gid)
+		result, err := processTask(context.WithValue(ctx, taskStatKey{}, stat), q, gid)
+		span.SetError(err)
+		if err != nil {
+			workerLog.Warningf(ctx, "Error while processing task for %q: %v", attr, err)
+		}
+		if stats != nil && err == nil {
+			stat.Latency = time.Since(start)
+			stats.add(*stat)
+		}
+		progress.TaskDone(resultRows(result))
+		holdResult(ctx, result)
+		return result, err
 	}
 
+	type taskReply struct {
+		result *protos.Result
+		md     metadata.MD
+	}
+	workerLog.Debugf(ctx, "Sending task for %q to group %d", attr, gid)
 	result, err := processWithBackupRequest(ctx, gid, func(ctx context.Context, c protos.WorkerClient) (interface{}, error) {
-		return c.ServeTask(ctx, q)
+		var md metadata.MD
+		reply, err := c.ServeTask(ctx, q, grpc.Header(&md))
+		return taskReply{reply, md}, err
 	})
 	if err != nil {
 		if tr, ok := trace.FromContext(ctx); ok {
 			tr.LazyPrintf("Error while worker.ServeTask: %v", err)
 		}
+		workerLog.Warningf(ctx, "Error while sending task for %q to group %d: %v", attr, gid, err)
+		span.SetError(err)
+		progress.TaskDone(0)
 		return nil, err
 	}
-	reply := result.(*protos.Result)
+	reply := result.(taskReply).result
+	progress.TaskDone(resultRows(reply))
+	if stats != nil {
+		stat.Remote, stat.Latency = true, time.Since(start)
+		stat.Network = int64(q.Size() + reply.Size())
+		receiveTaskStat(result.(taskReply).md, stat)
+		stats.add(*stat)
+	}
 	if tr, ok := trace.FromContext(ctx); ok {
 		tr.LazyPrintf("Reply from server. length: %v Group: %v Attr: %v", len(reply.UidMatrix), gid, attr)
 	}
+	holdResult(ctx, reply)
 	return reply, nil
 }
 
@@ -212,6 +259,7 @@ const (
 	FullTextSearchFn
 	HasFn
 	UidInFn
+	UdfFn
 	StandardFn = 100
 )
 
@@ -248,10 +296,39 @@ func parseFuncType(arr []string) (FuncType, string) {
 		if types.IsGeoFunc(f) {
 			return GeoFn, f
 		}
+		if udf.IsFilter(f) {
+			return UdfFn, f
+		}
 		return StandardFn, f
 	}
 }
 
+// FuncLabel returns the type of the function name, as a label of metrics.
+func FuncLabel(name string) string {
+	fnType, _ := parseFuncType([]string{name})
+	switch fnType {
+	case AggregatorFn:
+		return "aggregator"
+	case CompareAttrFn, CompareScalarFn:
+		return "compare"
+	case GeoFn:
+		return "geo"
+	case PasswordFn:
+		return "checkpwd"
+	case RegexFn:
+		return "regexp"
+	case FullTextSearchFn:
+		return "fulltext"
+	case HasFn:
+		return "has"
+	case UidInFn:
+		return "uid_in"
+	case UdfFn:
+		return "udf"
+	}
+	return "term"
+}
+
 func needsIndex(fnType FuncType) bool {
 	switch fnType {
 	case CompareAttrFn, GeoFn, RegexFn, FullTextSearchFn, StandardFn:
@@ -291,7 +368,7 @@ type funcArgs struct {
 // The function tells us whether we want to fetch value posting lists or uid posting lists.
 func (srcFn *functionContext) needsValuePostings(typ types.TypeID) (bool, error) {
 	switch srcFn.fnType {
-	case AggregatorFn, PasswordFn:
+	case AggregatorFn, PasswordFn, UdfFn:
 		return true, nil
 	case CompareAttrFn:
 		if len(srcFn.tokens) > 0 {
@@ -321,7 +398,7 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 	out := args.out
 
 	switch srcFn.fnType {
-	case NotAFunction, AggregatorFn, PasswordFn, CompareAttrFn:
+	case NotAFunction, AggregatorFn, PasswordFn, CompareAttrFn, UdfFn:
 	default:
 		return x.Errorf("Unhandled function in handleValuePostings: %s", srcFn.fname)
 	}
@@ -329,6 +406,15 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 	var key []byte
 	var err error
 	listType := schema.State().IsList(attr)
+	// Nodes without a value have the default value of the predicate, unless it's asked in
+	// languages.
+	def, hasDefault := schema.State().Default(attr)
+	if len(q.Langs) > 0 {
+		hasDefault = false
+		for _, lang := range q.Langs {
+			hasDefault = hasDefault || lang == "."
+		}
+	}
 	for i := 0; i < srcFn.n; i++ {
 		select {
 		case <-ctx.Done():
@@ -338,7 +424,9 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 		key = x.DataKey(attr, q.UidList.Uids[i])
 
 		// Get or create the posting list for an entity, attribute combination.
-		pl := posting.GetOrCreate(key, gid)
+		pl, read := posting.GetOrCreateRead(key, gid)
+		srcFn.scanned += int64(pl.EstimatedSize())
+		srcFn.bytesRead += read
 		var vals []types.Val
 		// Even if its a list type and value is asked in a language we return that.
 		if listType && len(q.Langs) == 0 {
@@ -351,12 +439,16 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 			}
 			vals = append(vals, val)
 		}
+		if hasDefault && (err != nil || len(vals) == 0) {
+			vals, err = []types.Val{def}, nil
+		}
 
 		if err != nil || len(vals) == 0 {
 			out.UidMatrix = append(out.UidMatrix, &emptyUIDList)
 			out.ValueMatrix = append(out.ValueMatrix, &emptyValueList)
 			continue
 		}
+		srcFn.valuesDecoded += int64(len(vals))
 
 		valTid := vals[0].Tid
 		newValue := &protos.TaskValue{ValType: int32(valTid), Val: x.Nilbyte}
@@ -376,12 +468,22 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 					uidList.Uids = append(uidList.Uids, q.UidList.Uids[i])
 					break
 				}
-			} else {
+			} else if srcFn.fnType != UdfFn {
 				vl.Values = append(vl.Values, newValue)
 			}
 		}
 		out.ValueMatrix = append(out.ValueMatrix, &vl)
 
+		if srcFn.fnType == UdfFn {
+			keep, err := callFilter(ctx, srcFn, vals, q.SrcFunc[2:])
+			if err != nil {
+				return err
+			}
+			if keep {
+				uidList.Uids = append(uidList.Uids, q.UidList.Uids[i])
+			}
+		}
+
 		if q.FacetsFilter != nil { // else part means isValueEdge
 			// This is Value edge and we are asked to do facet filtering. Not supported.
 			return x.Errorf("Facet filtering is not supported on values.")
@@ -428,6 +530,21 @@ func handleValuePostings(ctx context.Context, args funcArgs) error {
 	return nil
 }
 
+// callFilter calls the user defined filter function of srcFn with the values of a node, converted
+// to the type of the predicate.
+func callFilter(ctx context.Context, srcFn *functionContext, vals []types.Val,
+	args []string) (bool, error) {
+	converted := make([]types.Val, 0, len(vals))
+	for _, val := range vals {
+		cv, err := types.Convert(val, srcFn.atype)
+		if err != nil {
+			return false, err
+		}
+		converted = append(converted, cv)
+	}
+	return udf.CallFilter(ctx, srcFn.fname, converted, args)
+}
+
 // This function handles operations on uid posting lists. Index keys, reverse keys and some data
 // keys store uid posting lists.
 func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOptions) error {
@@ -442,6 +559,8 @@ func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOpti
 		return err
 	}
 
+	// After an online rebuild, the index keys of geo predicates are stored under another one.
+	indexAttr := schema.State().IndexAttr(attr)
 	for i := 0; i < srcFn.n; i++ {
 		select {
 		case <-ctx.Done():
@@ -457,15 +576,19 @@ func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOpti
 				key = x.DataKey(attr, q.UidList.Uids[i])
 			}
 		case GeoFn, RegexFn, FullTextSearchFn, StandardFn:
-			key = x.IndexKey(attr, srcFn.tokens[i])
+			key = x.IndexKey(indexAttr, srcFn.tokens[i])
+			srcFn.tokensRead++
 		case CompareAttrFn:
-			key = x.IndexKey(attr, srcFn.tokens[i])
+			key = x.IndexKey(indexAttr, srcFn.tokens[i])
+			srcFn.tokensRead++
 		default:
 			return x.Errorf("Unhandled function in handleUidPostings: %s", srcFn.fname)
 		}
 
 		// Get or create the posting list for an entity, attribute combination.
-		pl := posting.GetOrCreate(key, gid)
+		pl, read := posting.GetOrCreateRead(key, gid)
+		srcFn.scanned += int64(pl.EstimatedSize())
+		srcFn.bytesRead += read
 
 		// get filtered uids and facets.
 		var filteredRes []*result
@@ -474,6 +597,7 @@ func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOpti
 		var perr error
 		filteredRes = make([]*result, 0, pl.Length(opts.AfterUID))
 		pl.Postings(opts, func(p *protos.Posting) bool {
+			srcFn.postingsRead++
 			res := true
 			res, perr = applyFacetsTree(p.Facets, facetsTree)
 			if perr != nil {
@@ -541,6 +665,11 @@ func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOpti
 
 // processTask processes the query, accumulates and returns the result.
 func processTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.Result, error) {
+	if attr := servedAttr(q.Attr); attr != q.Attr {
+		qc := *q
+		qc.Attr = attr
+		q = &qc
+	}
 	out := new(protos.Result)
 	attr := q.Attr
 
@@ -548,6 +677,9 @@ func processTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.Resu
 	if err != nil {
 		return nil, err
 	}
+	// Filters run after reading the postings decode values too.
+	defer recordTask(ctx, srcFn)
+	defer trackOperation(opTask, gid, taskDesc(q))()
 
 	if q.Reverse && !schema.State().IsReversed(attr) {
 		return nil, x.Errorf("Predicate %s doesn't have reverse edge", attr)
@@ -585,14 +717,18 @@ func processTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.Resu
 	if err != nil {
 		return nil, err
 	}
+	span, _ := tracing.Start(ctx, "read_postings")
 	if needsValPostings {
-		if err = handleValuePostings(ctx, args); err != nil {
-			return nil, err
-		}
+		err = handleValuePostings(ctx, args)
 	} else {
-		if err = handleUidPostings(ctx, args, opts); err != nil {
-			return nil, err
-		}
+		err = handleUidPostings(ctx, args, opts)
+	}
+	span.SetTag("uids", srcFn.n)
+	x.PredicateReads.WithLabelValues(x.PredicateLabel(attr)).Add(float64(srcFn.n))
+	span.SetError(err)
+	span.Finish()
+	if err != nil {
+		return nil, err
 	}
 
 	if srcFn.fnType == HasFn && srcFn.isFuncAtRoot {
@@ -625,7 +761,19 @@ func processTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.Resu
 
 	// If geo filter, do value check for correctness.
 	if srcFn.geoQuery != nil {
-		filterGeoFunction(funcArgs{q, gid, srcFn, out})
+		span, _ := tracing.Start(ctx, "geo_filter")
+		if srcFn.geoQuery.IsDisjoint() {
+			handleDisjointFunction(args)
+		} else {
+			filterGeoFunction(funcArgs{q, gid, srcFn, out})
+		}
+		if srcFn.geoQuery.IsNearest() {
+			err = handleNearestFunction(ctx, args, opts)
+		}
+		span.Finish()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// For string matching functions, check the language.
@@ -699,12 +847,20 @@ func handleRegexFunction(ctx context.Context, arg funcArgs) error {
 	}
 
 	query := cindex.RegexpQuery(arg.srcFn.regex.Syntax)
-	empty := protos.List{}
-	uids, err := uidsForRegex(attr, arg.gid, query, &empty)
+	var uids *protos.List
+	if query.Op == cindex.QAll {
+		// The pattern has no trigrams to look up, like /^a/ or /.+/, so all the values are matched.
+		uids = regexScanUids(arg)
+	} else {
+		empty := protos.List{}
+		uids, err = uidsForRegex(attr, arg.gid, query, &empty)
+	}
 	if uids != nil {
 		arg.out.UidMatrix = append(arg.out.UidMatrix, uids)
 
 		var values []types.Val
+		// The uids with a value, with valUids.Uids[i] the uid of values[i].
+		valUids := new(protos.List)
 		for _, uid := range uids.Uids {
 			select {
 			case <-ctx.Done():
@@ -712,7 +868,8 @@ func handleRegexFunction(ctx context.Context, arg funcArgs) error {
 			default:
 			}
 			key := x.DataKey(attr, uid)
-			pl := posting.GetOrCreate(key, arg.gid)
+			pl, read := posting.GetOrCreateRead(key, arg.gid)
+			arg.srcFn.bytesRead += read
 
 			var val types.Val
 			if len(arg.srcFn.lang) > 0 {
@@ -724,14 +881,16 @@ func handleRegexFunction(ctx context.Context, arg funcArgs) error {
 			if err != nil {
 				continue
 			}
+			arg.srcFn.valuesDecoded++
 			// conver data from binary to appropriate format
 			strVal, err := types.Convert(val, types.StringID)
 			if err == nil {
 				values = append(values, strVal)
+				valUids.Uids = append(valUids.Uids, uid)
 			}
 		}
 
-		filtered := matchRegex(uids, values, arg.srcFn.regex)
+		filtered := matchRegex(valUids, values, arg.srcFn.regex)
 		for i := 0; i < len(arg.out.UidMatrix); i++ {
 			algo.IntersectWith(arg.out.UidMatrix[i], filtered, arg.out.UidMatrix[i])
 		}
@@ -741,6 +900,15 @@ func handleRegexFunction(ctx context.Context, arg funcArgs) error {
 	return nil
 }
 
+// regexScanUids returns the uids whose values are matched against a regex without trigrams: the
+// ones being filtered, or at root all the uids with a value, found by going over the predicate.
+func regexScanUids(arg funcArgs) *protos.List {
+	if arg.q.UidList != nil {
+		return &protos.List{Uids: append([]uint64(nil), arg.q.UidList.Uids...)}
+	}
+	return &protos.List{Uids: posting.DataUids(arg.q.Attr)}
+}
+
 func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 	attr := arg.q.Attr
 	tokenizer, err := pickTokenizer(attr, arg.srcFn.fname)
@@ -773,6 +941,7 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 			default:
 			}
 			algo.ApplyFilter(arg.out.UidMatrix[row], func(uid uint64, i int) bool {
+				arg.srcFn.valuesDecoded++
 				switch arg.srcFn.lang {
 				case "":
 					pl := posting.Get(x.DataKey(attr, uid))
@@ -811,10 +980,13 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 func filterGeoFunction(arg funcArgs) {
 	attr := arg.q.Attr
 	var values []*protos.TaskValue
+	var size int64
+	defer func() { geoFilters.Add(-size) }()
 	uids := algo.MergeSorted(arg.out.UidMatrix)
 	for _, uid := range uids.Uids {
 		key := x.DataKey(attr, uid)
-		pl := posting.GetOrCreate(key, arg.gid)
+		pl, read := posting.GetOrCreateRead(key, arg.gid)
+		arg.srcFn.bytesRead += read
 
 		val, err := pl.Value()
 		newValue := &protos.TaskValue{ValType: int32(val.Tid)}
@@ -824,42 +996,129 @@ func filterGeoFunction(arg funcArgs) {
 			newValue.Val = x.Nilbyte
 		}
 		values = append(values, newValue)
+		n := int64(len(newValue.Val))
+		size += n
+		geoFilters.Add(n)
+	}
+
+	// Each of the values is parsed into a geometry to be checked.
+	arg.srcFn.valuesDecoded += int64(len(values))
+	filtered, dists := types.FilterGeoUids(uids, values, arg.srcFn.geoQuery)
+	if arg.srcFn.geoQuery.MeasuresDistance() {
+		// The nodes found are returned as a single list, with their distances as the values.
+		dl := &protos.ValueList{}
+		for _, d := range dists {
+			data := types.ValueForType(types.BinaryID)
+			x.Check(types.Marshal(types.Val{Tid: types.FloatID, Value: d}, &data))
+			dl.Values = append(dl.Values, &protos.TaskValue{
+				ValType: int32(types.FloatID),
+				Val:     data.Value.([]byte),
+			})
+		}
+		arg.out.UidMatrix = []*protos.List{filtered}
+		arg.out.ValueMatrix = []*protos.ValueList{dl}
+		arg.out.FacetMatrix = nil
+		return
 	}
-
-	filtered := types.FilterGeoUids(uids, values, arg.srcFn.geoQuery)
 	for i := 0; i < len(arg.out.UidMatrix); i++ {
 		algo.IntersectWith(arg.out.UidMatrix[i], filtered, arg.out.UidMatrix[i])
 	}
 }
 
+// handleNearestFunction looks up the index for caps of growing radius, until the k points nearest
+// to the center of the cap are all within it, or it covers the whole Earth.
+func handleNearestFunction(ctx context.Context, arg funcArgs, opts posting.ListOptions) error {
+	q := arg.srcFn.geoQuery
+	for len(arg.out.UidMatrix[0].Uids) < q.K() {
+		toks, ok := q.ExpandCap()
+		if !ok {
+			return nil
+		}
+		tok.EncodeGeoTokens(toks, q.Index())
+		arg.srcFn.tokens = toks
+		arg.srcFn.n = len(toks)
+		arg.out.UidMatrix, arg.out.ValueMatrix = nil, nil
+		if err := handleUidPostings(ctx, arg, opts); err != nil {
+			return err
+		}
+		filterGeoFunction(arg)
+	}
+	return nil
+}
+
+// handleDisjointFunction finds the uids whose values don't intersect the region of a disjoint
+// function. At root, the index only gives the uids whose values may intersect it, so all the other
+// uids with a value are disjoint from it, found by going over the predicate.
+func handleDisjointFunction(arg funcArgs) {
+	if arg.q.UidList != nil {
+		arg.out.UidMatrix = []*protos.List{{Uids: append([]uint64(nil), arg.q.UidList.Uids...)}}
+		arg.out.ValueMatrix = []*protos.ValueList{&emptyValueList}
+		filterGeoFunction(arg)
+		return
+	}
+
+	candidates := algo.MergeSorted(arg.out.UidMatrix)
+	filterGeoFunction(arg)
+	disjoint := algo.MergeSorted(arg.out.UidMatrix)
+
+	others := new(protos.List)
+	for _, uid := range posting.DataUids(arg.q.Attr) {
+		if algo.IndexOf(candidates, uid) >= 0 {
+			continue
+		}
+		pl, read := posting.GetOrCreateRead(x.DataKey(arg.q.Attr, uid), arg.gid)
+		arg.srcFn.bytesRead += read
+		if _, err := pl.Value(); err == nil {
+			others.Uids = append(others.Uids, uid)
+		}
+	}
+	arg.out.UidMatrix = []*protos.List{algo.MergeSorted([]*protos.List{disjoint, others})}
+	arg.out.ValueMatrix = []*protos.ValueList{&emptyValueList}
+}
+
 func filterStringFunction(arg funcArgs) {
 	attr := arg.q.Attr
 	uids := algo.MergeSorted(arg.out.UidMatrix)
+	listType := schema.State().IsList(attr)
+	// The values of the uids, with valUids[i] the uid of values[i]. Nodes of list predicates
+	// have one entry per value, and match if any of them does.
 	var values []types.Val
-	filteredUids := make([]uint64, 0, len(uids.Uids))
+	valUids := make([]uint64, 0, len(uids.Uids))
 	for _, uid := range uids.Uids {
 		key := x.DataKey(attr, uid)
-		pl := posting.GetOrCreate(key, arg.gid)
+		pl, read := posting.GetOrCreateRead(key, arg.gid)
+		arg.srcFn.bytesRead += read
 
-		var val types.Val
+		var vals []types.Val
 		var err error
-		if arg.srcFn.lang == "" {
-			val, err = pl.Value()
-		} else {
+		switch {
+		case arg.srcFn.lang != "":
+			var val types.Val
 			val, err = pl.ValueForTag(arg.srcFn.lang)
+			vals = append(vals, val)
+		case listType:
+			vals, err = pl.AllValues()
+		default:
+			var val types.Val
+			val, err = pl.Value()
+			vals = append(vals, val)
 		}
 		if err != nil {
 			continue
 		}
-		// convert data from binary to appropriate format
-		strVal, err := types.Convert(val, types.StringID)
-		if err == nil {
+		for _, val := range vals {
+			arg.srcFn.valuesDecoded++
+			// convert data from binary to appropriate format
+			strVal, err := types.Convert(val, types.StringID)
+			if err != nil {
+				continue
+			}
 			values = append(values, strVal)
+			valUids = append(valUids, uid)
 		}
-		filteredUids = append(filteredUids, uid)
 	}
 
-	filtered := &protos.List{Uids: filteredUids}
+	filtered := &protos.List{Uids: valUids}
 	filter := stringFilter{
 		funcName: arg.srcFn.fname,
 		funcType: arg.srcFn.fnType,
@@ -878,8 +1137,15 @@ func filterStringFunction(arg funcArgs) {
 		filter.ineqValue = arg.srcFn.ineqValue
 		filter.eqVals = arg.srcFn.eqTokens
 		filter.match = ineqMatch
-		filtered = matchStrings(uids, values, filter)
+		filtered = matchStrings(filtered, values, filter)
 	}
+	// Uids matching by several values of a list are kept once.
+	var prev uint64
+	algo.ApplyFilter(filtered, func(uid uint64, i int) bool {
+		keep := i == 0 || uid != prev
+		prev = uid
+		return keep
+	})
 
 	for i := 0; i < len(arg.out.UidMatrix); i++ {
 		algo.IntersectWith(arg.out.UidMatrix[i], filtered, arg.out.UidMatrix[i])
@@ -918,6 +1184,12 @@ type functionContext struct {
 	isFuncAtRoot   bool
 	isStringFn     bool
 	atype          types.TypeID
+	index          string // Tokenizer of the index used, if any.
+	scanned        int64  // About the bytes of the posting lists read.
+	tokensRead     int64  // Index tokens looked up.
+	postingsRead   int64  // Uid postings iterated over.
+	valuesDecoded  int64  // Values decoded, by the task and its filters.
+	bytesRead      int64  // Bytes read from the store for the lists which weren't cached.
 }
 
 const (
@@ -1013,6 +1285,11 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 		} else {
 			fc.n = len(fc.tokens)
 		}
+		if len(fc.tokens) > 0 {
+			if tokenizer, err := pickTokenizer(attr, f); err == nil {
+				fc.index = tokenizer.Name()
+			}
+		}
 		fc.lang = q.SrcFunc[1]
 		// TODO - See if we can get rid of passing language as part of the SrcFunc
 		// since we already have Lang field in q.
@@ -1030,17 +1307,36 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 		checkRoot(q, fc)
 	case GeoFn:
 		// For geo functions, we get extra information used for filtering.
-		fc.tokens, fc.geoQuery, err = types.GetGeoTokens(q.SrcFunc)
-		tok.EncodeGeoTokens(fc.tokens)
+		// The tokens are those of whichever of the S2 and geohash indexes attr has.
+		gi := schema.State().GeoIndex(attr)
+		fc.tokens, fc.geoQuery, err = types.GetGeoTokens(q.SrcFunc, gi)
+		tok.EncodeGeoTokens(fc.tokens, gi)
 		if err != nil {
 			return nil, err
 		}
 		fc.n = len(fc.tokens)
+		if fc.geoQuery.IsDisjoint() && q.UidList != nil {
+			// As a filter, the values of the uids are matched without looking up the index.
+			fc.n = 0
+		}
+		fc.index = tok.GeoIndexTokenizer(gi).Name()
 	case PasswordFn:
 		if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
 			return nil, err
 		}
 		fc.n = len(q.UidList.Uids)
+	case UdfFn:
+		if q.UidList == nil {
+			return nil, x.Errorf("Function %s can only be used in filters", f)
+		}
+		if t == types.UidID {
+			return nil, x.Errorf("Function %s can't be applied to uid predicate %s", f, attr)
+		}
+		fc.n = len(q.UidList.Uids)
+		fc.lang = q.SrcFunc[1]
+		if len(q.Langs) == 0 && fc.lang != "" {
+			q.Langs = []string{fc.lang}
+		}
 	case StandardFn, FullTextSearchFn:
 		// srcfunc 0th val is func name and and [2:] are args.
 		// we tokenize the arguments of the query.
@@ -1058,6 +1354,7 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 		fc.lang = q.SrcFunc[1]
 		fc.intersectDest = strings.HasPrefix(fnName, "allof") // allofterms and alloftext
 		fc.n = len(fc.tokens)
+		fc.index = required
 	case RegexFn:
 		if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
 			return nil, err
@@ -1120,14 +1417,16 @@ func (w *grpcWorker) ServeTask(ctx context.Context, q *protos.Query) (*protos.Re
 
 	x.AssertTruef(groups().ServesGroup(gid),
 		"attr: %q groupId: %v Request sent to wrong server.", q.Attr, gid)
+	workerLog.Debugf(ctx, "Serving task for %q of group %d with %d uids", q.Attr, gid, numUids)
 
 	type reply struct {
 		result *protos.Result
 		err    error
 	}
 	c := make(chan reply, 1)
+	stat := new(TaskStat)
 	go func() {
-		result, err := processTask(ctx, q, gid)
+		result, err := processTask(context.WithValue(ctx, taskStatKey{}, stat), q, gid)
 		c <- reply{result, err}
 	}()
 
@@ -1135,6 +1434,11 @@ func (w *grpcWorker) ServeTask(ctx context.Context, q *protos.Query) (*protos.Re
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case reply := <-c:
+		if reply.err == nil {
+			sendTaskStat(ctx, stat)
+		} else {
+			workerLog.Warningf(ctx, "Error while serving task for %q: %v", q.Attr, reply.err)
+		}
 		return reply.result, reply.err
 	}
 }
@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Servers send how they ran tasks for other servers in these headers of their replies to
+// ServeTask, since the results of tasks have no room for them.
+const (
+	taskIndexHeader    = "dgraph-task-index"
+	taskScannedHeader  = "dgraph-task-scanned"
+	taskTokensHeader   = "dgraph-task-tokens"
+	taskPostingsHeader = "dgraph-task-postings"
+	taskValuesHeader   = "dgraph-task-values"
+	taskReadHeader     = "dgraph-task-read"
+)
+
+// TaskStat is how a task of a query ran.
+type TaskStat struct {
+	Attr  string `json:"attr"`
+	Func  string `json:"func,omitempty"`
+	Group uint32 `json:"group"`
+	// Index is the tokenizer of the index the task used, if any.
+	Index string `json:"index,omitempty"`
+	// Scanned is about the bytes of the posting lists the task read.
+	Scanned int64 `json:"scanned_bytes"`
+	// Tokens is the number of index tokens the task looked up.
+	Tokens int64 `json:"index_tokens"`
+	// Postings is the number of uid postings the task iterated over.
+	Postings int64 `json:"postings"`
+	// Values is the number of values the task decoded, like the geometries checked by geo
+	// functions.
+	Values int64 `json:"values_decoded"`
+	// Read is the bytes read from the store for the posting lists which weren't cached.
+	Read int64 `json:"read_bytes"`
+	// Network is the bytes of the request and the result of the task, if it ran on another server.
+	Network int64 `json:"network_bytes,omitempty"`
+	Remote  bool  `json:"remote,omitempty"`
+
+	Latency time.Duration `json:"-"`
+}
+
+// TaskStats collects how the tasks of a query ran.
+type TaskStats struct {
+	sync.Mutex
+	tasks []TaskStat
+}
+
+type taskStatsKey struct{}
+type taskStatKey struct{}
+
+// WithTaskStats returns ctx with stats, which the tasks run with it are added to. Those of ctx are
+// reused if it has some.
+func WithTaskStats(ctx context.Context) (context.Context, *TaskStats) {
+	if stats := taskStatsFrom(ctx); stats != nil {
+		return ctx, stats
+	}
+	stats := new(TaskStats)
+	return context.WithValue(ctx, taskStatsKey{}, stats), stats
+}
+
+func taskStatsFrom(ctx context.Context) *TaskStats {
+	stats, _ := ctx.Value(taskStatsKey{}).(*TaskStats)
+	return stats
+}
+
+func (s *TaskStats) add(t TaskStat) {
+	s.Lock()
+	s.tasks = append(s.tasks, t)
+	s.Unlock()
+}
+
+// Tasks returns the tasks added so far.
+func (s *TaskStats) Tasks() []TaskStat {
+	s.Lock()
+	defer s.Unlock()
+	return append([]TaskStat(nil), s.tasks...)
+}
+
+// Scanned returns the bytes scanned by the tasks added so far.
+func (s *TaskStats) Scanned() int64 {
+	s.Lock()
+	defer s.Unlock()
+	var n int64
+	for _, t := range s.tasks {
+		n += t.Scanned
+	}
+	return n
+}
+
+// Resources is what the tasks of a query used, summed over them.
+type Resources struct {
+	Tokens   int64 `json:"index_tokens"`
+	Postings int64 `json:"postings"`
+	Values   int64 `json:"values_decoded"`
+	Scanned  int64 `json:"scanned_bytes"`
+	Read     int64 `json:"read_bytes"`
+	Network  int64 `json:"network_bytes"`
+}
+
+// Resources returns what the tasks added so far used.
+func (s *TaskStats) Resources() Resources {
+	s.Lock()
+	defer s.Unlock()
+	var r Resources
+	for _, t := range s.tasks {
+		r.Tokens += t.Tokens
+		r.Postings += t.Postings
+		r.Values += t.Values
+		r.Scanned += t.Scanned
+		r.Read += t.Read
+		r.Network += t.Network
+	}
+	return r
+}
+
+// recordTask sets the index and what the task of srcFn used on the TaskStat of ctx, if it has
+// one.
+func recordTask(ctx context.Context, srcFn *functionContext) {
+	if t, ok := ctx.Value(taskStatKey{}).(*TaskStat); ok {
+		t.Index, t.Scanned = srcFn.index, srcFn.scanned
+		t.Tokens, t.Postings, t.Values, t.Read = srcFn.tokensRead, srcFn.postingsRead,
+			srcFn.valuesDecoded, srcFn.bytesRead
+	}
+}
+
+// taskCounts are the headers of the counts of a TaskStat, with their field.
+var taskCounts = []struct {
+	header string
+	field  func(t *TaskStat) *int64
+}{
+	{taskScannedHeader, func(t *TaskStat) *int64 { return &t.Scanned }},
+	{taskTokensHeader, func(t *TaskStat) *int64 { return &t.Tokens }},
+	{taskPostingsHeader, func(t *TaskStat) *int64 { return &t.Postings }},
+	{taskValuesHeader, func(t *TaskStat) *int64 { return &t.Values }},
+	{taskReadHeader, func(t *TaskStat) *int64 { return &t.Read }},
+}
+
+// sendTaskStat sends t in the headers of the reply to ServeTask.
+func sendTaskStat(ctx context.Context, t *TaskStat) {
+	md := metadata.Pairs(taskIndexHeader, t.Index)
+	for _, c := range taskCounts {
+		md[c.header] = []string{strconv.FormatInt(*c.field(t), 10)}
+	}
+	grpc.SetHeader(ctx, md)
+}
+
+// receiveTaskStat sets the index and what a task used on t, from the headers md of the reply to
+// ServeTask.
+func receiveTaskStat(md metadata.MD, t *TaskStat) {
+	if v := md[taskIndexHeader]; len(v) > 0 {
+		t.Index = v[0]
+	}
+	for _, c := range taskCounts {
+		if v := md[c.header]; len(v) > 0 {
+			*c.field(t), _ = strconv.ParseInt(v[0], 10, 64)
+		}
+	}
+}
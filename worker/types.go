@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/group"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Types are declared in the schema with the predicates their nodes can have, as in
+// type Person { name, age, friend }. A node is of the types among its values of
+// Config.KindPredicate, in the namespace of its predicates, like for @required. The declarations
+// are stored by the group of x.TypesAttr, and mutations setting predicates on nodes of declared
+// types are checked against them before being proposed. Nodes without a declared type can have any
+// predicate.
+
+// TypeError is returned for mutations setting on the node Uid of Types a predicate which isn't a
+// field of any of them.
+type TypeError struct {
+	Uid       uint64
+	Types     []string
+	Predicate string
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("Predicate %s isn't a field of type %s of node %#x", e.Predicate,
+		strings.Join(e.Types, ", "), e.Uid)
+}
+
+// getTypes returns the declarations of the types named in s, or of all the types if it names none.
+func getTypes(s *protos.SchemaRequest) (*protos.SchemaResult, error) {
+	if !groups().ServesGroup(group.BelongsTo(x.TypesAttr)) {
+		return &emptySchemaResult, x.Errorf("Types aren't served by this instance")
+	}
+	names := s.Types
+	if len(names) == 0 {
+		names = schema.State().Types()
+		sort.Strings(names)
+	}
+	var result protos.SchemaResult
+	for _, name := range names {
+		if typ, ok := schema.State().GetType(name); ok {
+			result.Types = append(result.Types, &typ)
+		}
+	}
+	return &result, nil
+}
+
+// GetTypesOverNetwork returns the declarations of the types names, or of all the types if names is
+// empty, from the group storing them.
+func GetTypesOverNetwork(ctx context.Context, names []string) ([]*protos.TypeUpdate, error) {
+	if err := x.HealthCheck(); err != nil {
+		return nil, err
+	}
+	gid := group.BelongsTo(x.TypesAttr)
+	ch := make(chan resultErr, 1)
+	getSchemaOverNetwork(ctx, gid, &protos.SchemaRequest{
+		GroupId: gid,
+		Fields:  []string{"types"},
+		Types:   names,
+	}, ch)
+	r := <-ch
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.result.Types, nil
+}
+
+// processTypes applies the declarations of types typs, at index.
+func (n *node) processTypes(index uint64, typs []*protos.TypeUpdate) error {
+	// Like schema changes, declarations apply to the mutations after them.
+	n.waitForSyncMark(n.ctx, index-1)
+	if !groups().ServesGroup(group.BelongsTo(x.TypesAttr)) {
+		return x.Errorf("Types aren't served by this instance")
+	}
+	for _, typ := range typs {
+		schema.State().Update(schema.SyncEntry{
+			Type:  typ,
+			Index: index,
+			Water: posting.SyncMarkFor(n.gid),
+		})
+		RecordEvent(EventSchema, n.gid, Config.RaftId, "Type %s declared with fields %s",
+			typ.TypeName, strings.Join(typ.Fields, ", "))
+	}
+	return nil
+}
+
+// checkTypes checks that m sets on nodes of declared types only the fields of their types.
+func checkTypes(ctx context.Context, m *protos.Mutations) error {
+	if len(m.Edges) == 0 || Config.KindPredicate == "" {
+		return nil
+	}
+	// The predicates set on each node, and the edges of their kinds, by predicate.
+	set := make(map[uint64][]string)
+	kindEdges := make(map[uint64]map[string][]*protos.DirectedEdge)
+	cleared := make(map[uint64]bool)
+	kindAttrs := make(map[string]bool)
+	for _, edge := range m.Edges {
+		if edge.Entity == 0 {
+			continue
+		}
+		if edge.Attr == x.Star {
+			cleared[edge.Entity] = true
+			continue
+		}
+		ns, name := x.ParseNamespacedAttr(edge.Attr)
+		if name == Config.KindPredicate {
+			if kindEdges[edge.Entity] == nil {
+				kindEdges[edge.Entity] = make(map[string][]*protos.DirectedEdge)
+			}
+			kindEdges[edge.Entity][edge.Attr] = append(kindEdges[edge.Entity][edge.Attr], edge)
+			continue
+		}
+		if edge.Op != protos.DirectedEdge_SET || name == "_predicate_" {
+			continue
+		}
+		set[edge.Entity] = append(set[edge.Entity], edge.Attr)
+		kindAttrs[x.NamespacedAttr(ns, Config.KindPredicate)] = true
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	typs, err := GetTypesOverNetwork(ctx, nil)
+	if err != nil || len(typs) == 0 {
+		return err
+	}
+	fields := make(map[string]map[string]bool, len(typs))
+	for _, typ := range typs {
+		fields[typ.TypeName] = make(map[string]bool, len(typ.Fields))
+		for _, f := range typ.Fields {
+			fields[typ.TypeName][f] = true
+		}
+	}
+
+	req := &protos.SchemaRequest{Fields: []string{"list"}}
+	for attr := range kindAttrs {
+		req.Predicates = append(req.Predicates, attr)
+	}
+	nodes, err := GetSchemaOverNetwork(ctx, req)
+	if err != nil {
+		return err
+	}
+	list := make(map[string]bool)
+	for _, n := range nodes {
+		list[n.Predicate] = n.List
+	}
+	var uids []uint64
+	for uid := range set {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	// The kinds of the nodes, by namespace, as they are before m.
+	fetched := make(map[string]map[uint64]nodeValues)
+
+	for _, uid := range uids {
+		for _, attr := range set[uid] {
+			ns, name := x.ParseNamespacedAttr(attr)
+			kindAttr := x.NamespacedAttr(ns, Config.KindPredicate)
+			if fetched[kindAttr] == nil {
+				vals, err := fetchValues(ctx, kindAttr, uids)
+				if err != nil {
+					return err
+				}
+				fetched[kindAttr] = vals
+			}
+			kinds := make(nodeValues)
+			if !cleared[uid] {
+				for k := range fetched[kindAttr][uid] {
+					kinds[k] = true
+				}
+			}
+			kinds.apply(kindEdges[uid][kindAttr], list[kindAttr])
+
+			var declared []string
+			allowed := false
+			for kind := range kinds {
+				f, ok := fields[x.NamespacedAttr(ns, kind)]
+				if !ok {
+					continue
+				}
+				declared = append(declared, kind)
+				allowed = allowed || f[attr]
+			}
+			if len(declared) > 0 && !allowed {
+				sort.Strings(declared)
+				return x.Wrap(&TypeError{Uid: uid, Types: declared, Predicate: name})
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// Watchers are told about mutations once they're applied, which live queries use to know when to
+// run again. A server sees the mutations applied to the groups it serves, and the ones sent
+// through it to other groups.
+
+type watcher struct {
+	match func(edge *protos.DirectedEdge) bool
+	ch    chan struct{}
+}
+
+var watchers struct {
+	sync.RWMutex
+	m map[*watcher]struct{}
+	n int32
+}
+
+// WatchMutations calls match with the edges of mutations once they're applied, and schema updates
+// as edges with only their predicate set. When it returns true, a signal is sent on the returned
+// channel, unless one is still pending. match is called concurrently, and must be quick. The
+// returned function ends the watch.
+func WatchMutations(match func(edge *protos.DirectedEdge) bool) (<-chan struct{}, func()) {
+	w := &watcher{match: match, ch: make(chan struct{}, 1)}
+	watchers.Lock()
+	if watchers.m == nil {
+		watchers.m = make(map[*watcher]struct{})
+	}
+	watchers.m[w] = struct{}{}
+	atomic.StoreInt32(&watchers.n, int32(len(watchers.m)))
+	watchers.Unlock()
+
+	var once sync.Once
+	return w.ch, func() {
+		once.Do(func() {
+			watchers.Lock()
+			delete(watchers.m, w)
+			atomic.StoreInt32(&watchers.n, int32(len(watchers.m)))
+			watchers.Unlock()
+		})
+	}
+}
+
+func hasWatchers() bool {
+	return atomic.LoadInt32(&watchers.n) > 0
+}
+
+func (w *watcher) matches(m *protos.Mutations) bool {
+	for _, s := range m.Schema {
+		if w.match(&protos.DirectedEdge{Attr: s.Predicate}) {
+			return true
+		}
+	}
+	for _, edge := range m.Edges {
+		if w.match(edge) {
+			return true
+		}
+	}
+	return false
+}
+
+func notifyWatchers(m *protos.Mutations) {
+	watchers.RLock()
+	defer watchers.RUnlock()
+	for w := range watchers.m {
+		if !w.matches(m) {
+			continue
+		}
+		select {
+		case w.ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyApplied tells the watchers about the mutations at index, once they're applied.
+func (n *node) notifyApplied(index uint64, m *protos.Mutations) {
+	n.applied.WaitForMark(index)
+	notifyWatchers(m)
+}
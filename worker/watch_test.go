@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+func pending(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWatchMutations(t *testing.T) {
+	names, stopNames := WatchMutations(func(edge *protos.DirectedEdge) bool {
+		return edge.Attr == "name"
+	})
+	ages, stopAges := WatchMutations(func(edge *protos.DirectedEdge) bool {
+		return edge.Attr == "age"
+	})
+	defer stopAges()
+	require.True(t, hasWatchers())
+
+	notifyWatchers(&protos.Mutations{Edges: []*protos.DirectedEdge{{Attr: "name", Entity: 1}}})
+	// Signals are coalesced until they're received.
+	notifyWatchers(&protos.Mutations{Edges: []*protos.DirectedEdge{{Attr: "name", Entity: 2}}})
+	require.True(t, pending(names))
+	require.False(t, pending(names))
+	require.False(t, pending(ages))
+
+	notifyWatchers(&protos.Mutations{Schema: []*protos.SchemaUpdate{{Predicate: "age"}}})
+	require.True(t, pending(ages))
+
+	stopNames()
+	stopNames()
+	notifyWatchers(&protos.Mutations{Edges: []*protos.DirectedEdge{{Attr: "name", Entity: 1}}})
+	require.False(t, pending(names))
+	stopAges()
+	require.False(t, hasWatchers())
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// With Config.Webhooks set, the leader of each group served here posts the changes committed to
+// it to the webhooks registered, as they're written to its changelog. Each entry is a batch, with
+// the edges of the predicates matching the patterns of a webhook, as the messages of change data
+// capture. Failed posts are retried with exponential backoff, and batches which still fail are
+// appended to a dead-letter file in the backup folder of the group, so that a webhook which is
+// down doesn't hold up the others for long.
+
+const (
+	webhookOffsetFile     = "webhook-offset"
+	webhookDeadLetterFile = "webhook-dead-letters.json"
+	webhookAttempts       = 5
+)
+
+// webhookBackoff is the time waited after the first failed post of a batch, doubled after each
+// of the following ones.
+var webhookBackoff = time.Second
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Webhook is an endpoint to which changes are posted.
+type Webhook struct {
+	URL string `json:"url"`
+	// Only the changes to predicates matching one of Predicates are posted, or all of them
+	// without any.
+	Predicates []string `json:"predicates,omitempty"`
+	// With a secret, batches are signed with HMAC-SHA256 in the X-Dgraph-Signature header.
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookBatch is what's posted to a webhook for an entry of the changelog.
+type WebhookBatch struct {
+	Webhook string     `json:"webhook"`
+	Group   uint32     `json:"group"`
+	Index   uint64     `json:"index"`
+	Commit  time.Time  `json:"commit_ts"`
+	Edges   []*CDCEdge `json:"edges"`
+}
+
+type deadLetter struct {
+	Time  time.Time     `json:"time"`
+	URL   string        `json:"url"`
+	Error string        `json:"error"`
+	Batch *WebhookBatch `json:"batch"`
+}
+
+var webhooks = struct {
+	sync.RWMutex
+	hooks map[string]Webhook
+}{hooks: make(map[string]Webhook)}
+
+// ValidateWebhook checks that h can be registered.
+func ValidateWebhook(h Webhook) error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return x.Errorf("Invalid webhook URL: %q. Expected an http or https URL", h.URL)
+	}
+	return ValidatePatterns(h.Predicates)
+}
+
+// SetWebhooks replaces the webhooks changes are posted to, by id.
+func SetWebhooks(hooks map[string]Webhook) {
+	m := make(map[string]Webhook, len(hooks))
+	for id, h := range hooks {
+		m[id] = h
+	}
+	webhooks.Lock()
+	webhooks.hooks = m
+	webhooks.Unlock()
+}
+
+// webhookBatches returns the batches of change for the webhooks hooks, by id, leaving out those
+// without any edges.
+func webhookBatches(c *Change, hooks map[string]Webhook) (map[string]*WebhookBatch, error) {
+	batches := make(map[string]*WebhookBatch)
+	for _, line := range c.Lines {
+		e, err := cdcEdge(c, line)
+		if err != nil {
+			return nil, x.Wrapf(err, "While reading entry %d of changelog of group %d",
+				c.Index, c.Group)
+		}
+		if e == nil {
+			continue
+		}
+		for id, h := range hooks {
+			if len(h.Predicates) > 0 && !matchAny(h.Predicates, e.Predicate) {
+				continue
+			}
+			b, ok := batches[id]
+			if !ok {
+				b = &WebhookBatch{Webhook: id, Group: c.Group, Index: c.Index, Commit: c.Time}
+				batches[id] = b
+			}
+			b.Edges = append(b.Edges, e)
+		}
+	}
+	return batches, nil
+}
+
+// postWebhook posts body to h once.
+func postWebhook(h Webhook, body []byte) error {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Dgraph-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return x.Errorf("Webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// deliverBatch posts b to h, retrying failed posts, and writes it to the dead-letter file of its
+// group if they all fail.
+func deliverBatch(h Webhook, b *WebhookBatch) error {
+	body, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	backoff := webhookBackoff
+	for i := 1; ; i++ {
+		if err = postWebhook(h, body); err == nil {
+			x.WebhookPosts.Add(b.Webhook, 1)
+			return nil
+		}
+		if i == webhookAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	x.WebhookFailures.Add(1)
+	workerLog.Warningf(context.Background(), "Error while posting entry %d of group %d to "+
+		"webhook %s, after %d attempts: %v", b.Index, b.Group, b.Webhook, webhookAttempts, err)
+	return writeDeadLetter(b.Group, &deadLetter{Time: time.Now(), URL: h.URL,
+		Error: err.Error(), Batch: b})
+}
+
+// writeDeadLetter appends d to the dead-letter file of group gid.
+func writeDeadLetter(gid uint32, d *deadLetter) error {
+	fpath := offsetPath(gid, webhookDeadLetterFile)
+	if err := os.MkdirAll(path.Dir(fpath), 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fpath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// deliverChange posts the batches of change to the webhooks registered, in the order of their
+// ids.
+func deliverChange(c *Change) error {
+	webhooks.RLock()
+	hooks := webhooks.hooks
+	webhooks.RUnlock()
+	batches, err := webhookBatches(c, hooks)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(batches))
+	for id := range batches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := deliverBatch(hooks[id], batches[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postWebhooks posts the changes of the groups led here to the webhooks registered, as they're
+// applied.
+func postWebhooks() {
+	syncChanges("webhooks", webhookOffsetFile, deliverChange, x.WebhookFailures)
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookBatches(t *testing.T) {
+	c := &Change{Group: 1, Index: 7, Time: time.Now(), Lines: []string{
+		"schema name: string .",
+		`+ <_:uid1> <name> "Alice" .`,
+		`+ <_:uid1> <friend> <_:uid2> .`,
+	}}
+	batches, err := webhookBatches(c, map[string]Webhook{
+		"all":     {URL: "http://localhost/all"},
+		"friends": {URL: "http://localhost/friends", Predicates: []string{"friend"}},
+		"none":    {URL: "http://localhost/none", Predicates: []string{"age"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Len(t, batches["all"].Edges, 2)
+	require.Len(t, batches["friends"].Edges, 1)
+	require.Equal(t, "friend", batches["friends"].Edges[0].Predicate)
+	require.EqualValues(t, 7, batches["friends"].Index)
+
+	require.Error(t, ValidateWebhook(Webhook{URL: "ftp://localhost"}))
+	require.Error(t, ValidateWebhook(Webhook{URL: "http://localhost", Predicates: []string{"["}}))
+}
+
+func TestDeliverBatch(t *testing.T) {
+	defer func(b time.Duration) { webhookBackoff = b }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+	dir, err := ioutil.TempDir("", "webhook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(p string) { Config.BackupPath = p }(Config.BackupPath)
+	Config.BackupPath = dir
+
+	var posts int
+	var body []byte
+	var sig string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first post fails, and the following ones succeed.
+		if posts++; posts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = ioutil.ReadAll(r.Body)
+		sig = r.Header.Get("X-Dgraph-Signature")
+	}))
+	defer srv.Close()
+
+	b := &WebhookBatch{Webhook: "test", Group: 1, Index: 3,
+		Edges: []*CDCEdge{{Op: "set", Subject: "0x1", Predicate: "name", Value: "Alice"}}}
+	h := Webhook{URL: srv.URL, Secret: "secret"}
+	require.NoError(t, deliverBatch(h, b))
+	require.Equal(t, 2, posts)
+	var got WebhookBatch
+	require.NoError(t, json.Unmarshal(body, &got))
+	require.Equal(t, "Alice", got.Edges[0].Value)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), sig)
+
+	// Batches which can't be posted go to the dead-letter file of their group.
+	h.URL = srv.URL + "/missing"
+	srv.Close()
+	require.NoError(t, deliverBatch(h, b))
+	data, err := ioutil.ReadFile(offsetPath(1, webhookDeadLetterFile))
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(data), "\n"))
+	var d deadLetter
+	require.NoError(t, json.Unmarshal(data, &d))
+	require.Equal(t, h.URL, d.URL)
+	require.EqualValues(t, 3, d.Batch.Index)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/dgraph/group"
 	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/tracing"
 	"github.com/dgraph-io/dgraph/x"
 
 	"golang.org/x/net/context"
@@ -47,6 +48,8 @@ var (
 	// are taken
 
 	emptyMembershipUpdate protos.MembershipUpdate
+
+	workerLog = x.NewLog("worker")
 )
 
 func workerPort() int {
@@ -58,14 +61,34 @@ func Init(ps *badger.KV) {
 	// needs to be initialized after group config
 	leaseGid = group.BelongsTo("_lease_")
 	pendingProposals = make(chan struct{}, Config.NumPendingProposals)
+	x.RaftPendingProposalsLimit.Set(float64(Config.NumPendingProposals))
 	if !Config.InMemoryComm {
-		workerServer = grpc.NewServer(
+		opts := []grpc.ServerOption{
 			grpc.MaxRecvMsgSize(x.GrpcMaxSize),
 			grpc.MaxSendMsgSize(x.GrpcMaxSize),
-			grpc.MaxConcurrentStreams(math.MaxInt32))
+			grpc.MaxConcurrentStreams(math.MaxInt32),
+			grpc.UnaryInterceptor(unaryServerInterceptor)}
+		if Config.PeerServerCreds != nil {
+			opts = append(opts, grpc.Creds(Config.PeerServerCreds))
+		}
+		workerServer = grpc.NewServer(opts...)
 	}
 }
 
+// unaryClientInterceptor propagates the id and the trace context of requests to the servers
+// they're sent to.
+func unaryClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return tracing.UnaryClientInterceptor(x.OutgoingRequestId(ctx), method, req, reply, cc,
+		invoker, opts...)
+}
+
+// unaryServerInterceptor continues the requests sent with an id and a trace context.
+func unaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	return tracing.UnaryServerInterceptor(x.IncomingRequestId(ctx), req, info, handler)
+}
+
 // grpcWorker struct implements the gRPC server interface.
 type grpcWorker struct {
 	sync.Mutex
@@ -602,9 +602,72 @@ func TestProcessSortOffsetCount(t *testing.T) {
 }
 */
 
+func TestFuncLabel(t *testing.T) {
+	labels := map[string]string{
+		"eq":         "compare",
+		"GE":         "compare",
+		"near":       "geo",
+		"anyoftext":  "fulltext",
+		"anyofterms": "term",
+		"has":        "has",
+		"min":        "aggregator",
+	}
+	for name, label := range labels {
+		require.Equal(t, label, FuncLabel(name), name)
+	}
+}
+
 func TestMain(m *testing.M) {
 	x.Init()
 	posting.Config.AllottedMemory = 1024.0
 	posting.Config.CommitFraction = 0.10
 	os.Exit(m.Run())
 }
+
+func TestQueryMemory(t *testing.T) {
+	before := queryResults.Bytes()
+	ctx, mem := WithQueryMemory(context.Background())
+	result := &protos.Result{UidMatrix: []*protos.List{{Uids: []uint64{1, 2, 3}}}}
+	holdResult(ctx, result)
+	holdResult(ctx, result)
+	// Results of tasks run without a QueryMemory aren't counted.
+	holdResult(context.Background(), result)
+	require.Equal(t, before+2*int64(result.Size()), queryResults.Bytes())
+	mem.Release()
+	require.Equal(t, before, queryResults.Bytes())
+}
+
+func TestTaskResources(t *testing.T) {
+	dir, ps := initTest(t, `neighbour: uid .
+friend: string @index(term) .`)
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+
+	ctx, stats := WithTaskStats(context.Background())
+	_, same := WithTaskStats(ctx)
+	require.True(t, stats == same)
+
+	run := func(q *protos.Query) TaskStat {
+		stat := new(TaskStat)
+		_, err := processTask(context.WithValue(ctx, taskStatKey{}, stat), q, 1)
+		require.NoError(t, err)
+		stats.add(*stat)
+		return *stat
+	}
+	stat := run(newQuery("neighbour", []uint64{10, 11, 12}, nil))
+	require.Equal(t, int64(7), stat.Postings)
+	require.Equal(t, int64(0), stat.Tokens)
+
+	stat = run(newQuery("friend", nil, []string{"anyofterms", "", "hey photon"}))
+	require.Equal(t, int64(2), stat.Tokens)
+	require.Equal(t, int64(2), stat.Postings)
+
+	stat = run(newQuery("friend", []uint64{10, 12}, nil))
+	require.Equal(t, int64(2), stat.Values)
+
+	r := stats.Resources()
+	require.Equal(t, int64(2), r.Tokens)
+	require.Equal(t, int64(9), r.Postings)
+	require.True(t, r.Values >= 2)
+	require.Equal(t, stats.Scanned(), r.Scanned)
+}
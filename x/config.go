@@ -20,6 +20,8 @@ type Options struct {
 	Version    bool
 	DebugMode  bool
 	PortOffset int
+	// Most predicates with their own series in per predicate metrics.
+	MetricsPredicates int
 }
 
 var Config Options
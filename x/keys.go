@@ -30,9 +30,16 @@ const (
 	ByteReverse  = byte(0x04)
 	ByteCount    = byte(0x08)
 	ByteCountRev = ByteCount | ByteReverse
+	ByteBlob     = byte(0x10)
+	byteTypeDef  = byte(0x20)
+	ByteVersion  = byte(0x40)
 	// same prefix for data, index and reverse keys so that relative order of data doesn't change
 	// keys of same attributes are located together
 	defaultPrefix = byte(0x00)
+
+	// TypesAttr is the predicate whose group stores the declarations of types. It holds no
+	// edges.
+	TypesAttr = "_types_"
 )
 
 func writeAttr(buf []byte, attr string) []byte {
@@ -59,6 +66,21 @@ func SchemaKey(attr string) []byte {
 	return buf
 }
 
+// TypeKey returns the key of the declaration of the type name. Such keys are stored with the
+// schema keys, under TypesAttr.
+func TypeKey(name string) []byte {
+	buf := make([]byte, 2+len(TypesAttr)+2+len(name))
+	buf[0] = byteSchema
+	rest := buf[1:]
+
+	rest = writeAttr(rest, TypesAttr)
+	rest[0] = byteTypeDef
+
+	rest = rest[1:]
+	AssertTrue(len(name) == copy(rest, name))
+	return buf
+}
+
 func DataKey(attr string, uid uint64) []byte {
 	buf := make([]byte, 2+len(attr)+2+8)
 	buf[0] = defaultPrefix
@@ -115,6 +137,39 @@ func CountKey(attr string, count uint32, reverse bool) []byte {
 	return buf
 }
 
+// BlobKey is the key for a large value of the posting with uid postingUid, in the data
+// posting list of (attr, uid). Such values are stored out of line of the posting list.
+func BlobKey(attr string, uid, postingUid uint64) []byte {
+	buf := make([]byte, 2+len(attr)+2+8+8)
+	buf[0] = defaultPrefix
+	rest := buf[1:]
+
+	rest = writeAttr(rest, attr)
+	rest[0] = ByteBlob
+
+	rest = rest[1:]
+	binary.BigEndian.PutUint64(rest, uid)
+	binary.BigEndian.PutUint64(rest[8:], postingUid)
+	return buf
+}
+
+// VersionKey is the key of an older version of the data posting list of (attr, uid), which was
+// superseded at ts, in nanoseconds since the epoch. Such versions are kept as long as the
+// retention of attr says so.
+func VersionKey(attr string, uid, ts uint64) []byte {
+	buf := make([]byte, 2+len(attr)+2+8+8)
+	buf[0] = defaultPrefix
+	rest := buf[1:]
+
+	rest = writeAttr(rest, attr)
+	rest[0] = ByteVersion
+
+	rest = rest[1:]
+	binary.BigEndian.PutUint64(rest, uid)
+	binary.BigEndian.PutUint64(rest[8:], ts)
+	return buf
+}
+
 type ParsedKey struct {
 	byteType   byte
 	Attr       string
@@ -141,8 +196,23 @@ func (p ParsedKey) IsIndex() bool {
 	return p.byteType == ByteIndex
 }
 
+func (p ParsedKey) IsBlob() bool {
+	return p.byteType == ByteBlob
+}
+
+func (p ParsedKey) IsVersion() bool {
+	return p.byteType == ByteVersion
+}
+
+// IsSchema returns true for the keys of the schema of predicates, and of the declarations of
+// types.
 func (p ParsedKey) IsSchema() bool {
-	return p.byteType == byteSchema
+	return p.byteType == byteSchema || p.byteType == byteTypeDef
+}
+
+// IsTypeDef returns true for the keys of the declarations of types, whose name is in Term.
+func (p ParsedKey) IsTypeDef() bool {
+	return p.byteType == byteTypeDef
 }
 
 func (p ParsedKey) IsType(typ byte) bool {
@@ -155,6 +225,10 @@ func (p ParsedKey) IsType(typ byte) bool {
 		return p.IsIndex()
 	case ByteData:
 		return p.IsData()
+	case ByteBlob:
+		return p.IsBlob()
+	case ByteVersion:
+		return p.IsVersion()
 	default:
 	}
 	return false
@@ -208,6 +282,28 @@ func (p ParsedKey) IndexPrefix() []byte {
 	return buf
 }
 
+// BlobPrefix returns the prefix for blob keys.
+func (p ParsedKey) BlobPrefix() []byte {
+	buf := make([]byte, 2+len(p.Attr)+2)
+	buf[0] = p.bytePrefix
+	rest := buf[1:]
+	k := writeAttr(rest, p.Attr)
+	AssertTrue(len(k) == 1)
+	k[0] = ByteBlob
+	return buf
+}
+
+// VersionPrefix returns the prefix for the keys of older versions of data posting lists.
+func (p ParsedKey) VersionPrefix() []byte {
+	buf := make([]byte, 2+len(p.Attr)+2)
+	buf[0] = p.bytePrefix
+	rest := buf[1:]
+	k := writeAttr(rest, p.Attr)
+	AssertTrue(len(k) == 1)
+	k[0] = ByteVersion
+	return buf
+}
+
 // ReversePrefix returns the prefix for index keys.
 func (p ParsedKey) ReversePrefix() []byte {
 	buf := make([]byte, 2+len(p.Attr)+2)
@@ -257,9 +353,9 @@ func Parse(key []byte) *ParsedKey {
 	switch p.byteType {
 	case ByteData:
 		fallthrough
-	case ByteReverse:
+	case ByteReverse, ByteBlob, ByteVersion:
 		p.Uid = binary.BigEndian.Uint64(k)
-	case ByteIndex:
+	case ByteIndex, byteTypeDef:
 		p.Term = string(k)
 	case ByteCount, ByteCountRev:
 		p.Count = binary.BigEndian.Uint32(k)
@@ -17,7 +17,9 @@
 package x
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 	"sort"
 	"testing"
 
@@ -79,6 +81,39 @@ func TestReverseKey(t *testing.T) {
 	}
 }
 
+func TestBlobKey(t *testing.T) {
+	var uid uint64
+	for uid = 0; uid < 1001; uid++ {
+		sattr := fmt.Sprintf("attr:%d", uid)
+
+		key := BlobKey(sattr, uid, math.MaxUint64)
+		pk := Parse(key)
+
+		require.True(t, pk.IsBlob())
+		require.False(t, pk.IsData())
+		require.Equal(t, sattr, pk.Attr)
+		require.Equal(t, uid, pk.Uid)
+		require.True(t, bytes.HasPrefix(key, pk.BlobPrefix()))
+	}
+}
+
+func TestVersionKey(t *testing.T) {
+	var uid uint64
+	for uid = 0; uid < 1001; uid++ {
+		sattr := fmt.Sprintf("attr:%d", uid)
+
+		key := VersionKey(sattr, uid, math.MaxUint64)
+		pk := Parse(key)
+
+		require.True(t, pk.IsVersion())
+		require.False(t, pk.IsData())
+		require.Equal(t, sattr, pk.Attr)
+		require.Equal(t, uid, pk.Uid)
+		require.True(t, bytes.HasPrefix(key, pk.VersionPrefix()))
+		require.False(t, bytes.HasPrefix(key, pk.DataPrefix()))
+	}
+}
+
 func TestSchemaKey(t *testing.T) {
 	var uid uint64
 	for uid = 0; uid < 1001; uid++ {
@@ -91,3 +126,15 @@ func TestSchemaKey(t *testing.T) {
 		require.Equal(t, sattr, pk.Attr)
 	}
 }
+
+func TestTypeKey(t *testing.T) {
+	key := TypeKey("Person")
+	pk := Parse(key)
+
+	require.True(t, pk.IsSchema())
+	require.True(t, pk.IsTypeDef())
+	require.Equal(t, TypesAttr, pk.Attr)
+	require.Equal(t, "Person", pk.Term)
+	require.True(t, bytes.HasPrefix(key, SchemaPrefix()))
+	require.False(t, Parse(SchemaKey("name")).IsTypeDef())
+}
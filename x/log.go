@@ -16,13 +16,30 @@
 package x
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 )
 
 var (
-	Logger = log.New(os.Stderr, "", log.Lshortfile|log.Flags())
+	Logger = log.New(stdWriter{}, "", log.Lshortfile|log.Flags())
 )
 
 // Printf does a log.Printf. We often do printf for debugging but has to keep
@@ -36,3 +53,333 @@ func Printf(format string, args ...interface{}) {
 func Println(args ...interface{}) {
 	Logger.Output(2, fmt.Sprintln(args...))
 }
+
+// Logs are written to stderr, as text by default, or as structured entries, a JSON object or a
+// logfmt line each, with --log_format. The entries of components of the server, logged through
+// their Log, have a level, set per component at runtime, and carry the id of the request they're
+// logged for, if any. The id of requests is propagated through the RPCs between servers, so that
+// the path of a request can be followed across the cluster by grepping its id. Entries of the
+// standard logger and of Printf are at level info, without a component.
+
+// Levels of log entries.
+const (
+	LevelDebug = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warning", "error"}
+
+// ParseLevel returns the level named s: debug, info, warning or error.
+func ParseLevel(s string) (int, error) {
+	for l, name := range levelNames {
+		if s == name {
+			return l, nil
+		}
+	}
+	return 0, Errorf("Invalid log level: %q. Expected debug, info, warning or error", s)
+}
+
+var logOutput = struct {
+	sync.Mutex
+	w      io.Writer
+	format string
+}{w: os.Stderr, format: "text"}
+
+// SetLogFormat sets the format of logs: text, json or logfmt.
+func SetLogFormat(format string) error {
+	switch format {
+	case "text":
+		Logger.SetFlags(log.LstdFlags | log.Lshortfile)
+		log.SetFlags(log.LstdFlags)
+	case "json", "logfmt":
+		// Entries have their own time, and the file and line of lines is taken as their caller.
+		Logger.SetFlags(log.Lshortfile)
+		log.SetFlags(log.Lshortfile)
+	default:
+		return Errorf("Invalid log format: %q. Expected text, json or logfmt", format)
+	}
+	log.SetOutput(stdWriter{})
+	logOutput.Lock()
+	logOutput.format = format
+	logOutput.Unlock()
+	return nil
+}
+
+// LogFormat returns the format of logs.
+func LogFormat() string {
+	logOutput.Lock()
+	defer logOutput.Unlock()
+	return logOutput.format
+}
+
+type logEntry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Component string    `json:"component,omitempty"`
+	Caller    string    `json:"caller,omitempty"`
+	RequestId string    `json:"request_id,omitempty"`
+	Msg       string    `json:"msg"`
+}
+
+func logfmtValue(v string) string {
+	if v == "" || strings.IndexFunc(v, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+func (e *logEntry) encode(format string) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		b, _ := json.Marshal(e)
+		buf.Write(b)
+	case "logfmt":
+		fmt.Fprintf(&buf, "time=%s level=%s", e.Time.UTC().Format(time.RFC3339Nano), e.Level)
+		for _, kv := range [][2]string{{"component", e.Component}, {"caller", e.Caller},
+			{"request_id", e.RequestId}} {
+			if kv[1] != "" {
+				fmt.Fprintf(&buf, " %s=%s", kv[0], logfmtValue(kv[1]))
+			}
+		}
+		fmt.Fprintf(&buf, " msg=%s", logfmtValue(e.Msg))
+	default:
+		buf.WriteString(e.Time.Format("2006/01/02 15:04:05 "))
+		if e.Caller != "" {
+			buf.WriteString(e.Caller + ": ")
+		}
+		buf.WriteString(strings.ToUpper(e.Level) + " ")
+		if e.Component != "" {
+			buf.WriteString(e.Component + ": ")
+		}
+		buf.WriteString(e.Msg)
+		if e.RequestId != "" {
+			buf.WriteString(" request_id=" + e.RequestId)
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func writeEntry(e *logEntry) {
+	logOutput.Lock()
+	defer logOutput.Unlock()
+	logOutput.w.Write(e.encode(logOutput.format))
+}
+
+// stdWriter is the output of Logger and of the standard logger, which passes their lines through
+// as text, and turns them into entries in the other formats.
+type stdWriter struct{}
+
+func (stdWriter) Write(p []byte) (int, error) {
+	logOutput.Lock()
+	if logOutput.format == "text" {
+		defer logOutput.Unlock()
+		return logOutput.w.Write(p)
+	}
+	logOutput.Unlock()
+	e := &logEntry{Time: time.Now(), Level: levelNames[LevelInfo]}
+	e.Msg = strings.TrimSuffix(string(p), "\n")
+	// Lines start with the file and line they were logged from, as in "draft.go:248: ".
+	if i := strings.Index(e.Msg, ": "); i > 0 && strings.Contains(e.Msg[:i], ".go:") {
+		e.Caller, e.Msg = e.Msg[:i], e.Msg[i+2:]
+	}
+	writeEntry(e)
+	return len(p), nil
+}
+
+// Log logs the entries of a component of the server, at its level or above.
+type Log struct {
+	component string
+	level     int32
+}
+
+var logs = struct {
+	sync.Mutex
+	m map[string]*Log
+}{m: make(map[string]*Log)}
+
+// NewLog returns the Log of component, at level info until set otherwise. Packages logging for
+// the same component share its Log.
+func NewLog(component string) *Log {
+	logs.Lock()
+	defer logs.Unlock()
+	l, ok := logs.m[component]
+	if !ok {
+		l = &Log{component: component, level: LevelInfo}
+		logs.m[component] = l
+	}
+	return l
+}
+
+// Enabled returns whether entries of level are logged.
+func (l *Log) Enabled(level int) bool {
+	return int32(level) >= atomic.LoadInt32(&l.level)
+}
+
+func (l *Log) output(ctx context.Context, level int, format string, args []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	e := &logEntry{
+		Time:      time.Now(),
+		Level:     levelNames[level],
+		Component: l.component,
+		RequestId: RequestId(ctx),
+		Msg:       strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"),
+	}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		e.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	writeEntry(e)
+}
+
+// Debugf logs an entry at level debug for the request of ctx.
+func (l *Log) Debugf(ctx context.Context, format string, args ...interface{}) {
+	l.output(ctx, LevelDebug, format, args)
+}
+
+// Infof logs an entry at level info for the request of ctx.
+func (l *Log) Infof(ctx context.Context, format string, args ...interface{}) {
+	l.output(ctx, LevelInfo, format, args)
+}
+
+// Warningf logs an entry at level warning for the request of ctx.
+func (l *Log) Warningf(ctx context.Context, format string, args ...interface{}) {
+	l.output(ctx, LevelWarning, format, args)
+}
+
+// Errorf logs an entry at level error for the request of ctx.
+func (l *Log) Errorf(ctx context.Context, format string, args ...interface{}) {
+	l.output(ctx, LevelError, format, args)
+}
+
+// SetLogLevel sets the level of the logs of component, or of all components if it's empty.
+func SetLogLevel(component, level string) error {
+	return SetLogLevels(component + "=" + level)
+}
+
+// SetLogLevels sets the levels of components from a comma separated list of component=level
+// pairs, like worker=debug,server=warning. A level without a component sets all of them. Nothing
+// is set if any pair is invalid.
+func SetLogLevels(levels string) error {
+	logs.Lock()
+	defer logs.Unlock()
+	set := make(map[*Log]int)
+	for _, s := range strings.Split(levels, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		var component string
+		if i := strings.Index(s, "="); i >= 0 {
+			component, s = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+		}
+		lv, err := ParseLevel(s)
+		if err != nil {
+			return err
+		}
+		if component == "" {
+			for _, l := range logs.m {
+				set[l] = lv
+			}
+			continue
+		}
+		l, ok := logs.m[component]
+		if !ok {
+			return Errorf("Unknown log component: %q", component)
+		}
+		set[l] = lv
+	}
+	for l, lv := range set {
+		atomic.StoreInt32(&l.level, int32(lv))
+	}
+	return nil
+}
+
+// LogLevels returns the level of the logs of each component, as a comma separated list of
+// component=level pairs sorted by component.
+func LogLevels() string {
+	logs.Lock()
+	defer logs.Unlock()
+	levels := make([]string, 0, len(logs.m))
+	for c, l := range logs.m {
+		levels = append(levels, c+"="+levelNames[atomic.LoadInt32(&l.level)])
+	}
+	sort.Strings(levels)
+	return strings.Join(levels, ",")
+}
+
+// RequestIdHeader is the HTTP header, and the gRPC metadata, carrying the id of requests.
+const RequestIdHeader = "X-Request-Id"
+
+// requestIdKey is the key of the id of requests in gRPC metadata, whose keys are lowercase.
+var requestIdKey = strings.ToLower(RequestIdHeader)
+
+type requestIdCtxKey struct{}
+
+// NewRequestId returns a new random request id.
+func NewRequestId() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestId returns whether id, sent by a client, can be used as a request id: up to 64
+// letters, digits, and any of -_.:
+func ValidRequestId(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '-' || r == '_' || r == '.' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestId returns ctx carrying the request id.
+func WithRequestId(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIdCtxKey{}, id)
+}
+
+// RequestId returns the id of the request of ctx, or "" if it has none.
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIdCtxKey{}).(string)
+	return id
+}
+
+// OutgoingRequestId returns ctx with its request id in the metadata of outgoing gRPC requests.
+func OutgoingRequestId(ctx context.Context) context.Context {
+	id := RequestId(ctx)
+	if id == "" {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md[requestIdKey] = []string{id}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// IncomingRequestId returns ctx carrying the request id in the metadata of its gRPC request, if
+// any and valid.
+func IncomingRequestId(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md[requestIdKey]; len(v) > 0 && ValidRequestId(v[0]) {
+		return WithRequestId(ctx, v[0])
+	}
+	return ctx
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// captureLogs makes logs be written in format to the buffer returned, until the func returned is
+// called.
+func captureLogs(t *testing.T, format string) (*bytes.Buffer, func()) {
+	buf := new(bytes.Buffer)
+	logOutput.Lock()
+	logOutput.w = buf
+	logOutput.Unlock()
+	require.NoError(t, SetLogFormat(format))
+	return buf, func() {
+		require.NoError(t, SetLogFormat("text"))
+		logOutput.Lock()
+		logOutput.w = os.Stderr
+		logOutput.Unlock()
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	buf, restore := captureLogs(t, "json")
+	defer restore()
+
+	l := NewLog("test_json")
+	ctx := WithRequestId(context.Background(), "abc123")
+	l.Infof(ctx, "Serving task for %q", "name")
+	Printf("Plain line\n")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var e logEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	require.Equal(t, "info", e.Level)
+	require.Equal(t, "test_json", e.Component)
+	require.Equal(t, "abc123", e.RequestId)
+	require.Equal(t, `Serving task for "name"`, e.Msg)
+	require.True(t, strings.HasPrefix(e.Caller, "log_test.go:"), e.Caller)
+
+	e = logEntry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &e))
+	require.Equal(t, "info", e.Level)
+	require.Equal(t, "", e.Component)
+	require.Equal(t, "Plain line", e.Msg)
+	require.True(t, strings.HasPrefix(e.Caller, "log_test.go:"), e.Caller)
+}
+
+func TestLogfmt(t *testing.T) {
+	buf, restore := captureLogs(t, "logfmt")
+	defer restore()
+
+	NewLog("test_logfmt").Warningf(WithRequestId(context.Background(), "r-1"),
+		"Error while sending task: %v", `bad "value"`)
+	line := buf.String()
+	require.Contains(t, line, " level=warning component=test_logfmt caller=log_test.go:")
+	require.Contains(t, line, ` request_id=r-1 msg="Error while sending task: bad \"value\""`)
+	require.True(t, strings.HasPrefix(line, "time="))
+}
+
+func TestLogLevels(t *testing.T) {
+	buf, restore := captureLogs(t, "text")
+	defer restore()
+
+	a, b := NewLog("test_a"), NewLog("test_b")
+	require.True(t, NewLog("test_a") == a)
+	defer SetLogLevels("info")
+
+	require.NoError(t, SetLogLevels("test_a=debug, test_b=error"))
+	require.Contains(t, LogLevels(), "test_a=debug,test_b=error")
+	a.Debugf(context.Background(), "Shown")
+	b.Warningf(context.Background(), "Hidden")
+	require.Contains(t, buf.String(), "DEBUG test_a: Shown")
+	require.NotContains(t, buf.String(), "Hidden")
+
+	// Invalid levels set nothing.
+	require.Error(t, SetLogLevels("test_a=info,test_b=verbose"))
+	require.Error(t, SetLogLevels("unknown=info"))
+	require.True(t, a.Enabled(LevelDebug))
+
+	require.NoError(t, SetLogLevels("warning"))
+	require.False(t, a.Enabled(LevelInfo))
+	require.True(t, b.Enabled(LevelWarning))
+	require.Error(t, SetLogFormat("xml"))
+}
+
+func TestRequestIdMetadata(t *testing.T) {
+	ctx := WithRequestId(context.Background(), "abc-1")
+	md, ok := metadata.FromOutgoingContext(OutgoingRequestId(ctx))
+	require.True(t, ok)
+
+	in := metadata.NewIncomingContext(context.Background(), md)
+	require.Equal(t, "abc-1", RequestId(IncomingRequestId(in)))
+
+	in = metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIdHeader, "a b"))
+	require.Equal(t, "", RequestId(IncomingRequestId(in)))
+	require.True(t, ValidRequestId(NewRequestId()))
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package x
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// The subsystems of the server account for the memory they hold, so that the memory in use can be
+// attributed to them, rather than only known from the totals of the Go runtime. Accounts are
+// estimates of the bytes of the data held, without the overhead of the structures holding it.
+
+var memoryUsers = struct {
+	sync.Mutex
+	m map[string]func() int64
+}{m: make(map[string]func() int64)}
+
+// RegisterMemory registers f as returning the bytes of memory held by the subsystem name.
+func RegisterMemory(name string, f func() int64) {
+	memoryUsers.Lock()
+	defer memoryUsers.Unlock()
+	AssertTruef(memoryUsers.m[name] == nil, "Memory of %s registered twice", name)
+	memoryUsers.m[name] = f
+}
+
+// MemoryAccount counts the bytes of memory held by a subsystem, which adds to it the bytes it
+// takes and subtracts those it releases.
+type MemoryAccount struct {
+	bytes int64
+}
+
+// NewMemoryAccount returns a new account, registered as the memory of the subsystem name.
+func NewMemoryAccount(name string) *MemoryAccount {
+	a := new(MemoryAccount)
+	RegisterMemory(name, a.Bytes)
+	return a
+}
+
+// Add adds n bytes to the account. n is negative for released bytes.
+func (a *MemoryAccount) Add(n int64) {
+	atomic.AddInt64(&a.bytes, n)
+}
+
+// Bytes returns the bytes held.
+func (a *MemoryAccount) Bytes() int64 {
+	return atomic.LoadInt64(&a.bytes)
+}
+
+// MemoryUsage returns the bytes of memory held by each subsystem.
+func MemoryUsage() map[string]int64 {
+	memoryUsers.Lock()
+	fs := make(map[string]func() int64, len(memoryUsers.m))
+	for name, f := range memoryUsers.m {
+		fs[name] = f
+	}
+	memoryUsers.Unlock()
+
+	usage := make(map[string]int64, len(fs))
+	for name, f := range fs {
+		usage[name] = f()
+	}
+	return usage
+}
+
+// MemoryReport breaks down the memory in use by subsystem.
+type MemoryReport struct {
+	// InUse is the bytes of the heap and the stacks in use.
+	InUse uint64 `json:"in_use"`
+	// Subsystems has the bytes held by each subsystem.
+	Subsystems map[string]int64 `json:"subsystems"`
+	// Unattributed is the bytes in use which no subsystem accounts for. It can be negative, as
+	// the accounts of subsystems are estimates.
+	Unattributed int64 `json:"unattributed"`
+	// Runtime has the totals of the Go runtime.
+	Runtime map[string]uint64 `json:"runtime"`
+}
+
+// ReportMemory returns the breakdown of the memory in use.
+func ReportMemory() MemoryReport {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	r := MemoryReport{
+		InUse:      ms.HeapInuse + ms.StackInuse,
+		Subsystems: MemoryUsage(),
+		Runtime: map[string]uint64{
+			"heap_alloc":    ms.HeapAlloc,
+			"heap_inuse":    ms.HeapInuse,
+			"heap_idle":     ms.HeapIdle,
+			"heap_released": ms.HeapReleased,
+			"stack_inuse":   ms.StackInuse,
+			"sys":           ms.Sys,
+			"num_gc":        uint64(ms.NumGC),
+		},
+	}
+	r.Unattributed = int64(r.InUse)
+	for _, n := range r.Subsystems {
+		r.Unattributed -= n
+	}
+	return r
+}
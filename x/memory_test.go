@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package x
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportMemory(t *testing.T) {
+	a := NewMemoryAccount("test_account")
+	a.Add(100)
+	a.Add(-40)
+	RegisterMemory("test_func", func() int64 { return 1000 })
+
+	r := ReportMemory()
+	require.Equal(t, int64(60), r.Subsystems["test_account"])
+	require.Equal(t, int64(1000), r.Subsystems["test_func"])
+	var sum int64
+	for _, n := range r.Subsystems {
+		sum += n
+	}
+	require.Equal(t, int64(r.InUse)-sum, r.Unattributed)
+	require.Equal(t, r.Runtime["heap_inuse"]+r.Runtime["stack_inuse"], r.InUse)
+}
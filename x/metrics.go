@@ -19,6 +19,7 @@ package x
 import (
 	"expvar"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -35,6 +36,10 @@ var (
 	CacheHit      *expvar.Int
 	CacheMiss     *expvar.Int
 	CacheRace     *expvar.Int
+	// Reads skipped, because the key filters showed that the key doesn't exist.
+	KeyFilterSkips *expvar.Int
+	// Spans of traces which couldn't be reported to the trace collector.
+	DroppedSpans *expvar.Int
 
 	// value at particular point of time
 	PendingQueries   *expvar.Int
@@ -52,14 +57,94 @@ var (
 	ServerHealth     *expvar.Int
 	MaxPlSize        *expvar.Int
 	MaxPlLength      *expvar.Int
+	CommitBatchSize  *expvar.Int
 
 	PredicateStats *expvar.Map
+	// Bytes of the older versions of posting lists kept by the retention of each predicate,
+	// which could be reclaimed by shortening it.
+	RetainedBytes *expvar.Map
+	// Seconds since the oldest change of the changelog of each group which isn't archived yet.
+	ChangelogArchiveLag *expvar.Map
+	// Requests refused by the limits of clients, per reason: allowlist, rate or concurrency.
+	ThrottledRequests *expvar.Map
+	// Events recorded in the event log, per type.
+	Events *expvar.Map
+	// Stalls detected, for which a diagnostics bundle was written.
+	Stalls *expvar.Int
+	// Messages published to Kafka, per topic, and errors publishing them.
+	CDCMessages *expvar.Map
+	CDCErrors   *expvar.Int
+	// Documents updated in Elasticsearch, and errors updating them.
+	ElasticUpdates *expvar.Int
+	ElasticErrors  *expvar.Int
+	// Batches posted to webhooks, per webhook, and batches written to dead-letter files.
+	WebhookPosts    *expvar.Map
+	WebhookFailures *expvar.Int
+	// Calls of user defined functions which failed, per function.
+	UDFErrors *expvar.Map
 
 	MaxPlSz int64
 	// TODO: Request statistics, latencies, 500, timeouts
 
 )
 
+// Metrics which can't be expvars, only exposed to Prometheus.
+var (
+	// Seconds to run the blocks of queries, by the type of their root function.
+	QueryLatency *prometheus.HistogramVec
+	// Seconds from proposing changes to Raft to their being applied, by group.
+	ProposalLatency *prometheus.HistogramVec
+	// Edges mutated, by operation: set or del.
+	MutationEdges *prometheus.CounterVec
+	// Posting lists read and edges written, by predicate. Use PredicateLabel for the predicate.
+	PredicateReads  *prometheus.CounterVec
+	PredicateWrites *prometheus.CounterVec
+
+	// Entries each peer is behind the log of the leader, and whether the leader is waiting for a
+	// snapshot to catch it up, by group and peer. Only reported by leaders.
+	RaftReplicationLag *prometheus.GaugeVec
+	RaftPeerSnapshot   *prometheus.GaugeVec
+	// Whether the server is the leader, the entries committed and not applied yet, those of them
+	// queued to be applied and the size of the queue, and the proposals waiting to be committed,
+	// by group.
+	RaftLeader           *prometheus.GaugeVec
+	RaftApplyLag         *prometheus.GaugeVec
+	RaftApplyQueue       *prometheus.GaugeVec
+	RaftApplyQueueSize   *prometheus.GaugeVec
+	RaftPendingProposals *prometheus.GaugeVec
+	// Most proposals waiting to be committed, across groups.
+	RaftPendingProposalsLimit prometheus.Gauge
+	// Whether a snapshot is being received from a peer, and the keys and bytes received so far,
+	// by group.
+	SnapshotReceiving     *prometheus.GaugeVec
+	SnapshotReceivedKeys  *prometheus.GaugeVec
+	SnapshotReceivedBytes *prometheus.GaugeVec
+)
+
+// otherPredicates is the label of the predicates past Config.MetricsPredicates.
+const otherPredicates = "_other_"
+
+var predicateLabels = struct {
+	sync.Mutex
+	m map[string]struct{}
+}{m: make(map[string]struct{})}
+
+// PredicateLabel returns the label of the predicate attr in per predicate metrics. Only the first
+// Config.MetricsPredicates predicates seen get their own, so that the number of series is bounded,
+// and the others share the label _other_.
+func PredicateLabel(attr string) string {
+	predicateLabels.Lock()
+	defer predicateLabels.Unlock()
+	if _, ok := predicateLabels.m[attr]; ok {
+		return attr
+	}
+	if len(predicateLabels.m) >= Config.MetricsPredicates {
+		return otherPredicates
+	}
+	predicateLabels.m[attr] = struct{}{}
+	return attr
+}
+
 func init() {
 	PostingReads = expvar.NewInt("dgraph_posting_reads_total")
 	PostingWrites = expvar.NewInt("dgraph_posting_writes_total")
@@ -80,11 +165,86 @@ func init() {
 	TotalOSMemory = expvar.NewInt("dgraph_proc_memory_bytes")
 	ActiveMutations = expvar.NewInt("dgraph_active_mutations_total")
 	PredicateStats = expvar.NewMap("dgraph_predicate_stats")
+	RetainedBytes = expvar.NewMap("dgraph_retained_bytes")
 	CacheHit = expvar.NewInt("dgraph_cache_hits_total")
 	CacheMiss = expvar.NewInt("dgraph_cache_miss_total")
 	CacheRace = expvar.NewInt("dgraph_cache_race_total")
+	KeyFilterSkips = expvar.NewInt("dgraph_key_filter_skips_total")
+	DroppedSpans = expvar.NewInt("dgraph_dropped_spans_total")
 	MaxPlSize = expvar.NewInt("dgraph_max_list_bytes")
 	MaxPlLength = expvar.NewInt("dgraph_max_list_length")
+	CommitBatchSize = expvar.NewInt("dgraph_commit_batch_size")
+	ChangelogArchiveLag = expvar.NewMap("dgraph_changelog_archive_lag_seconds")
+	ThrottledRequests = expvar.NewMap("dgraph_throttled_requests_total")
+	Events = expvar.NewMap("dgraph_events_total")
+	Stalls = expvar.NewInt("dgraph_stalls_total")
+	CDCMessages = expvar.NewMap("dgraph_cdc_messages_total")
+	CDCErrors = expvar.NewInt("dgraph_cdc_errors_total")
+	ElasticUpdates = expvar.NewInt("dgraph_elastic_updates_total")
+	ElasticErrors = expvar.NewInt("dgraph_elastic_errors_total")
+	WebhookPosts = expvar.NewMap("dgraph_webhook_posts_total")
+	WebhookFailures = expvar.NewInt("dgraph_webhook_failures_total")
+	UDFErrors = expvar.NewMap("dgraph_udf_errors_total")
+	expvar.Publish("dgraph_memory_bytes", expvar.Func(func() interface{} {
+		return MemoryUsage()
+	}))
+
+	// Latencies from 1ms to about 65s.
+	latencyBuckets := prometheus.ExponentialBuckets(0.001, 2, 17)
+	QueryLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dgraph_query_latency_seconds",
+		Help:    "Seconds to run query blocks, by the type of their root function.",
+		Buckets: latencyBuckets,
+	}, []string{"func"})
+	ProposalLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dgraph_raft_proposal_latency_seconds",
+		Help:    "Seconds from proposing changes to Raft to their being applied, by group.",
+		Buckets: latencyBuckets,
+	}, []string{"group"})
+	MutationEdges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dgraph_mutation_edges_total",
+		Help: "Edges mutated, by operation.",
+	}, []string{"op"})
+	PredicateReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dgraph_predicate_reads_total",
+		Help: "Posting lists read, by predicate.",
+	}, []string{"predicate"})
+	PredicateWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dgraph_predicate_writes_total",
+		Help: "Edges written, by predicate.",
+	}, []string{"predicate"})
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	}
+	RaftReplicationLag = gauge("dgraph_raft_replication_lag_entries",
+		"Entries a peer is behind the log of the leader, by group and peer.", "group", "peer")
+	RaftPeerSnapshot = gauge("dgraph_raft_peer_snapshot",
+		"Whether the leader waits for a snapshot to catch a peer up, by group and peer.",
+		"group", "peer")
+	RaftLeader = gauge("dgraph_raft_leader", "Whether the server is the leader, by group.",
+		"group")
+	RaftApplyLag = gauge("dgraph_raft_apply_lag_entries",
+		"Entries committed and not applied yet, by group.", "group")
+	RaftApplyQueue = gauge("dgraph_raft_apply_queue_entries",
+		"Committed entries queued to be applied, by group.", "group")
+	RaftApplyQueueSize = gauge("dgraph_raft_apply_queue_size",
+		"Most committed entries queued to be applied, by group.", "group")
+	RaftPendingProposals = gauge("dgraph_raft_pending_proposals",
+		"Proposals waiting to be committed and applied, by group.", "group")
+	RaftPendingProposalsLimit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dgraph_raft_pending_proposals_limit",
+		Help: "Most proposals waiting to be committed and applied, across groups.",
+	})
+	SnapshotReceiving = gauge("dgraph_snapshot_receiving",
+		"Whether a snapshot is being received from a peer, by group.", "group")
+	SnapshotReceivedKeys = gauge("dgraph_snapshot_received_keys",
+		"Keys received of the snapshot being received, or last received, by group.", "group")
+	SnapshotReceivedBytes = gauge("dgraph_snapshot_received_bytes",
+		"Bytes received of the snapshot being received, or last received, by group.", "group")
+	prometheus.MustRegister(QueryLatency, ProposalLatency, MutationEdges, PredicateReads,
+		PredicateWrites, RaftReplicationLag, RaftPeerSnapshot, RaftLeader, RaftApplyLag,
+		RaftApplyQueue, RaftApplyQueueSize, RaftPendingProposals, RaftPendingProposalsLimit,
+		SnapshotReceiving, SnapshotReceivedKeys, SnapshotReceivedBytes)
 
 	ticker := time.NewTicker(5 * time.Second)
 
@@ -118,6 +278,16 @@ func init() {
 			"dgraph_cache_race_total",
 			nil, nil,
 		),
+		"dgraph_key_filter_skips_total": prometheus.NewDesc(
+			"dgraph_key_filter_skips_total",
+			"dgraph_key_filter_skips_total",
+			nil, nil,
+		),
+		"dgraph_dropped_spans_total": prometheus.NewDesc(
+			"dgraph_dropped_spans_total",
+			"dgraph_dropped_spans_total",
+			nil, nil,
+		),
 		"dgraph_posting_reads_total": prometheus.NewDesc(
 			"dgraph_posting_reads_total",
 			"dgraph_posting_reads_total",
@@ -138,6 +308,71 @@ func init() {
 			"dgraph_max_list_length",
 			nil, nil,
 		),
+		"dgraph_commit_batch_size": prometheus.NewDesc(
+			"dgraph_commit_batch_size",
+			"dgraph_commit_batch_size",
+			nil, nil,
+		),
+		"dgraph_changelog_archive_lag_seconds": prometheus.NewDesc(
+			"dgraph_changelog_archive_lag_seconds",
+			"dgraph_changelog_archive_lag_seconds",
+			[]string{"group"}, nil,
+		),
+		"dgraph_memory_bytes": prometheus.NewDesc(
+			"dgraph_memory_bytes",
+			"dgraph_memory_bytes",
+			[]string{"subsystem"}, nil,
+		),
+		"dgraph_throttled_requests_total": prometheus.NewDesc(
+			"dgraph_throttled_requests_total",
+			"dgraph_throttled_requests_total",
+			[]string{"reason"}, nil,
+		),
+		"dgraph_events_total": prometheus.NewDesc(
+			"dgraph_events_total",
+			"dgraph_events_total",
+			[]string{"type"}, nil,
+		),
+		"dgraph_stalls_total": prometheus.NewDesc(
+			"dgraph_stalls_total",
+			"dgraph_stalls_total",
+			nil, nil,
+		),
+		"dgraph_cdc_messages_total": prometheus.NewDesc(
+			"dgraph_cdc_messages_total",
+			"dgraph_cdc_messages_total",
+			[]string{"topic"}, nil,
+		),
+		"dgraph_cdc_errors_total": prometheus.NewDesc(
+			"dgraph_cdc_errors_total",
+			"dgraph_cdc_errors_total",
+			nil, nil,
+		),
+		"dgraph_elastic_updates_total": prometheus.NewDesc(
+			"dgraph_elastic_updates_total",
+			"dgraph_elastic_updates_total",
+			nil, nil,
+		),
+		"dgraph_elastic_errors_total": prometheus.NewDesc(
+			"dgraph_elastic_errors_total",
+			"dgraph_elastic_errors_total",
+			nil, nil,
+		),
+		"dgraph_webhook_posts_total": prometheus.NewDesc(
+			"dgraph_webhook_posts_total",
+			"dgraph_webhook_posts_total",
+			[]string{"webhook"}, nil,
+		),
+		"dgraph_webhook_failures_total": prometheus.NewDesc(
+			"dgraph_webhook_failures_total",
+			"dgraph_webhook_failures_total",
+			nil, nil,
+		),
+		"dgraph_udf_errors_total": prometheus.NewDesc(
+			"dgraph_udf_errors_total",
+			"dgraph_udf_errors_total",
+			[]string{"function"}, nil,
+		),
 		"dgraph_pending_proposals_total": prometheus.NewDesc(
 			"dgraph_pending_proposals_total",
 			"dgraph_pending_proposals_total",
@@ -223,6 +458,11 @@ func init() {
 			"dgraph_predicate_stats",
 			[]string{"name"}, nil,
 		),
+		"dgraph_retained_bytes": prometheus.NewDesc(
+			"dgraph_retained_bytes",
+			"dgraph_retained_bytes",
+			[]string{"name"}, nil,
+		),
 		"badger_disk_reads_total": prometheus.NewDesc(
 			"badger_disk_reads_total",
 			"badger_disk_reads_total",
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicateLabel(t *testing.T) {
+	defer func(n int) { Config.MetricsPredicates = n }(Config.MetricsPredicates)
+	Config.MetricsPredicates = 2
+	predicateLabels.m = make(map[string]struct{})
+
+	require.Equal(t, "name", PredicateLabel("name"))
+	require.Equal(t, "acme::friend", PredicateLabel("acme::friend"))
+	require.Equal(t, "_other_", PredicateLabel("age"))
+	// Predicates keep the label they were given first.
+	require.Equal(t, "name", PredicateLabel("name"))
+
+	Config.MetricsPredicates = 0
+	predicateLabels.m = make(map[string]struct{})
+	require.Equal(t, "_other_", PredicateLabel("name"))
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x
+
+import "strings"
+
+// NamespaceSep separates the namespace of a predicate from its name, as in acme::name.
+const NamespaceSep = "::"
+
+// NamespacedAttr returns the stored name of the predicate attr of namespace ns. The ~ of a reverse
+// predicate stays in front.
+func NamespacedAttr(ns, attr string) string {
+	if ns == "" {
+		return attr
+	}
+	if strings.HasPrefix(attr, "~") {
+		return "~" + ns + NamespaceSep + attr[1:]
+	}
+	return ns + NamespaceSep + attr
+}
+
+// ParseNamespacedAttr returns the namespace and the name of the stored predicate attr, with an
+// empty namespace for predicates outside namespaces.
+func ParseNamespacedAttr(attr string) (ns, name string) {
+	a := strings.TrimPrefix(attr, "~")
+	idx := strings.Index(a, NamespaceSep)
+	if idx < 0 {
+		return "", attr
+	}
+	ns, name = a[:idx], a[idx+len(NamespaceSep):]
+	if len(a) < len(attr) {
+		name = "~" + name
+	}
+	return ns, name
+}
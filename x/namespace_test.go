@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacedAttr(t *testing.T) {
+	require.Equal(t, "name", NamespacedAttr("", "name"))
+	require.Equal(t, "acme::name", NamespacedAttr("acme", "name"))
+	require.Equal(t, "~acme::friend", NamespacedAttr("acme", "~friend"))
+
+	for _, attr := range []string{"name", "~friend", "dgraph.type"} {
+		ns, name := ParseNamespacedAttr(NamespacedAttr("acme", attr))
+		require.Equal(t, "acme", ns)
+		require.Equal(t, attr, name)
+	}
+	ns, name := ParseNamespacedAttr("~friend")
+	require.Equal(t, "", ns)
+	require.Equal(t, "~friend", name)
+}
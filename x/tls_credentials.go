@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package x
+
+import (
+	"net"
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSCredentials are gRPC transport credentials made from a TLSHelperConfig, which can be
+// reloaded from its files, so that certificates and CAs are rotated without a restart.
+// Connections already made keep the credentials they were made with, while new connections
+// and reconnections use the reloaded ones.
+type TLSCredentials struct {
+	sync.RWMutex
+	config TLSHelperConfig
+	creds  credentials.TransportCredentials
+}
+
+var _ credentials.TransportCredentials = (*TLSCredentials)(nil)
+
+// NewTLSCredentials returns the credentials of config.
+func NewTLSCredentials(config TLSHelperConfig) (*TLSCredentials, error) {
+	c := &TLSCredentials{config: config}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload reads the certificates and CAs of the credentials again. The current ones are kept if
+// it fails.
+func (c *TLSCredentials) Reload() error {
+	c.Lock()
+	defer c.Unlock()
+	cfg, _, err := GenerateTLSConfig(c.config)
+	if err != nil {
+		return err
+	}
+	c.creds = credentials.NewTLS(cfg)
+	return nil
+}
+
+func (c *TLSCredentials) current() credentials.TransportCredentials {
+	c.RLock()
+	defer c.RUnlock()
+	return c.creds
+}
+
+func (c *TLSCredentials) ClientHandshake(ctx context.Context, authority string,
+	conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *TLSCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ServerHandshake(conn)
+}
+
+func (c *TLSCredentials) Info() credentials.ProtocolInfo {
+	return c.current().Info()
+}
+
+func (c *TLSCredentials) Clone() credentials.TransportCredentials {
+	c.RLock()
+	defer c.RUnlock()
+	return &TLSCredentials{config: c.config, creds: c.creds.Clone()}
+}
+
+// OverrideServerName sets the name the certificates of servers are verified against, instead of
+// the host they're dialed at.
+func (c *TLSCredentials) OverrideServerName(name string) error {
+	c.Lock()
+	c.config.ServerName = name
+	c.Unlock()
+	return c.Reload()
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package x
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// writeCert writes a certificate for name, signed by parent, and its key to dir. A nil parent
+// makes a CA.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, key
+}
+
+// writeCluster writes a new CA, and the certificates of node1 and node2 signed by it.
+func writeCluster(t *testing.T, dir string) {
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "node1", ca, caKey)
+	writeCert(t, dir, "node2", ca, caKey)
+}
+
+func peerCredentials(t *testing.T, dir, name string, typ tlsConfigType) *TLSCredentials {
+	c, err := NewTLSCredentials(TLSHelperConfig{
+		ConfigType:    typ,
+		CertRequired:  true,
+		Cert:          filepath.Join(dir, name+".crt"),
+		Key:           filepath.Join(dir, name+".key"),
+		RootCACerts:   filepath.Join(dir, "ca.crt"),
+		ClientAuth:    "REQUIREANDVERIFY",
+		ClientCACerts: filepath.Join(dir, "ca.crt"),
+	})
+	require.NoError(t, err)
+	return c
+}
+
+// handshake runs a TLS handshake from client to server, which is dialed as node1.
+func handshake(client, server *TLSCredentials) error {
+	c, s := net.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := server.ServerHandshake(s)
+		s.Close()
+		errCh <- err
+	}()
+	_, _, err := client.ClientHandshake(context.Background(), "node1:12345", c)
+	c.Close()
+	if serr := <-errCh; err == nil {
+		err = serr
+	}
+	return err
+}
+
+func TestTLSCredentialsMutualAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeCluster(t, dir)
+
+	server := peerCredentials(t, dir, "node1", TLSServerConfig)
+	client := peerCredentials(t, dir, "node2", TLSClientConfig)
+	require.NoError(t, handshake(client, server))
+
+	// Clients without a certificate of the CA are refused.
+	anon, err := NewTLSCredentials(TLSHelperConfig{
+		ConfigType:  TLSClientConfig,
+		RootCACerts: filepath.Join(dir, "ca.crt"),
+	})
+	require.NoError(t, err)
+	require.Error(t, handshake(anon, server))
+
+	other, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(other)
+	writeCluster(t, other)
+	require.Error(t, handshake(peerCredentials(t, other, "node2", TLSClientConfig), server))
+}
+
+func TestTLSCredentialsReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeCluster(t, dir)
+
+	server := peerCredentials(t, dir, "node1", TLSServerConfig)
+	client := peerCredentials(t, dir, "node2", TLSClientConfig)
+	require.NoError(t, handshake(client, server))
+
+	// The CA is rotated, and the server reloaded before the client.
+	writeCluster(t, dir)
+	require.NoError(t, server.Reload())
+	require.Error(t, handshake(client, server))
+	require.NoError(t, client.Reload())
+	require.NoError(t, handshake(client, server))
+
+	// Failed reloads keep the current credentials.
+	require.NoError(t, os.Remove(filepath.Join(dir, "node1.key")))
+	require.Error(t, server.Reload())
+	require.NoError(t, handshake(client, server))
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package x
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+
+	// MaxWebSocketMessage is the size of the largest message read from a WebSocket.
+	MaxWebSocketMessage = 4 << 20
+
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// WebSocket is the server side of a WebSocket connection (RFC 6455). It only does what's needed
+// to exchange messages with clients: there are no extensions or subprotocols. Pings from the
+// client are answered while reading.
+type WebSocket struct {
+	conn net.Conn
+	r    *bufio.Reader
+	wmu  sync.Mutex
+}
+
+func headerHas(h http.Header, key, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(key)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// UpgradeWebSocket completes the WebSocket handshake for r, and takes over its connection. If r
+// isn't a valid handshake, an error is written to w and returned. Browsers send the Origin of the
+// page opening the WebSocket, which must be allowed by originAllowed, so that other sites can't
+// open one with the cookies or credentials of their users. Requests of other clients have none.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request,
+	originAllowed func(origin string) bool) (*WebSocket, error) {
+	if r.Method != "GET" || !headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, Errorf("Not a WebSocket handshake")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(origin) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return nil, Errorf("WebSocket from origin not allowed: %q", origin)
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, Errorf("Unsupported WebSocket version: %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, Errorf("Missing Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSockets aren't supported", http.StatusInternalServerError)
+		return nil, Errorf("Connection can't be taken over for a WebSocket")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, Wrapf(err, "While taking over connection for a WebSocket")
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\n" +
+		"Connection: Upgrade\r\nSec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocket{conn: conn, r: rw.Reader}, nil
+}
+
+func (ws *WebSocket) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [8]byte
+	if _, err = io.ReadFull(ws.r, h[:2]); err != nil {
+		return
+	}
+	fin, op = h[0]&0x80 != 0, h[0]&0x0f
+	if h[0]&0x70 != 0 {
+		return fin, op, nil, Errorf("Reserved bits set in WebSocket frame")
+	}
+	if h[1]&0x80 == 0 {
+		return fin, op, nil, Errorf("Unmasked WebSocket frame from client")
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		if _, err = io.ReadFull(ws.r, h[:2]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(h[:2]))
+	case 127:
+		if _, err = io.ReadFull(ws.r, h[:8]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(h[:8])
+	}
+	if n > MaxWebSocketMessage {
+		return fin, op, nil, Errorf("WebSocket frame of %d bytes is too large", n)
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.r, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(ws.r, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+func (ws *WebSocket) writeFrame(op byte, payload []byte) error {
+	ws.wmu.Lock()
+	defer ws.wmu.Unlock()
+	h := make([]byte, 2, 10)
+	h[0] = 0x80 | op
+	switch n := len(payload); {
+	case n < 126:
+		h[1] = byte(n)
+	case n <= 0xffff:
+		h[1] = 126
+		h = h[:4]
+		binary.BigEndian.PutUint16(h[2:], uint16(n))
+	default:
+		h[1] = 127
+		h = h[:10]
+		binary.BigEndian.PutUint64(h[2:], uint64(n))
+	}
+	if _, err := ws.conn.Write(h); err != nil {
+		return err
+	}
+	_, err := ws.conn.Write(payload)
+	return err
+}
+
+// ReadMessage returns the next text or binary message sent by the client. It returns io.EOF once
+// the client has closed the connection.
+func (ws *WebSocket) ReadMessage() ([]byte, error) {
+	var msg []byte
+	started := false
+	for {
+		fin, op, payload, err := ws.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsPing:
+			if err := ws.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			// Echo the status code, as the closing handshake asks for.
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			ws.writeFrame(wsClose, payload)
+			return nil, io.EOF
+		case wsText, wsBinary:
+			if started {
+				return nil, Errorf("Expected a continuation frame")
+			}
+			started = true
+		case wsContinuation:
+			if !started {
+				return nil, Errorf("Unexpected continuation frame")
+			}
+		default:
+			return nil, Errorf("Unknown WebSocket opcode: %d", op)
+		}
+		if len(msg)+len(payload) > MaxWebSocketMessage {
+			return nil, Errorf("WebSocket message is too large")
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// WriteMessage sends msg to the client as a text message. It's safe to call concurrently.
+func (ws *WebSocket) WriteMessage(msg []byte) error {
+	return ws.writeFrame(wsText, msg)
+}
+
+// Close sends a close frame to the client, and closes the connection.
+func (ws *WebSocket) Close() error {
+	ws.writeFrame(wsClose, []byte{0x03, 0xe8})
+	return ws.conn.Close()
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package x
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clientFrame returns a masked frame, as sent by clients.
+func clientFrame(fin bool, op byte, payload string) []byte {
+	mask := []byte{1, 2, 3, 4}
+	b := []byte{op, 0x80 | byte(len(payload))}
+	if fin {
+		b[0] |= 0x80
+	}
+	b = append(b, mask...)
+	for i := 0; i < len(payload); i++ {
+		b = append(b, payload[i]^mask[i%4])
+	}
+	return b
+}
+
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, string) {
+	var h [2]byte
+	_, err := io.ReadFull(r, h[:])
+	require.NoError(t, err)
+	require.Equal(t, byte(0), h[1]&0x80, "Server frames aren't masked")
+	payload := make([]byte, h[1]&0x7f)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+	return h[0] & 0x0f, string(payload)
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// The example in RFC 6455.
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestWebSocket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := UpgradeWebSocket(w, r, func(string) bool { return true })
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			ws.WriteMessage([]byte(strings.ToUpper(string(msg))))
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"))
+	require.NoError(t, err)
+	r := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	conn.Write(clientFrame(true, wsText, "hello"))
+	op, msg := readServerFrame(t, r)
+	require.Equal(t, byte(wsText), op)
+	require.Equal(t, "HELLO", msg)
+
+	// A fragmented message, with a ping in between.
+	conn.Write(clientFrame(false, wsText, "frag"))
+	conn.Write(clientFrame(true, wsPing, "p"))
+	conn.Write(clientFrame(true, wsContinuation, "mented"))
+	op, msg = readServerFrame(t, r)
+	require.Equal(t, byte(wsPong), op)
+	require.Equal(t, "p", msg)
+	op, msg = readServerFrame(t, r)
+	require.Equal(t, "FRAGMENTED", msg)
+
+	conn.Write(clientFrame(true, wsClose, "\x03\xe8"))
+	op, _ = readServerFrame(t, r)
+	require.Equal(t, byte(wsClose), op)
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := UpgradeWebSocket(w, r, func(o string) bool { return o == "https://a.example" })
+		if err != nil {
+			return
+		}
+		ws.Close()
+	}))
+	defer srv.Close()
+
+	handshake := func(origin string) int {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+		require.NoError(t, err)
+		defer conn.Close()
+		req := "GET / HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\n" +
+			"Connection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+			"Sec-WebSocket-Version: 13\r\n"
+		if origin != "" {
+			req += "Origin: " + origin + "\r\n"
+		}
+		_, err = conn.Write([]byte(req + "\r\n"))
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	require.Equal(t, http.StatusForbidden, handshake("https://evil.example"))
+	require.Equal(t, http.StatusSwitchingProtocols, handshake("https://a.example"))
+	// Clients other than browsers send no origin.
+	require.Equal(t, http.StatusSwitchingProtocols, handshake(""))
+}
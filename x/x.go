@@ -43,6 +43,7 @@ const (
 	ErrorNoPermission       = "ErrorNoPermission"
 	ErrorInvalidMutation    = "ErrorInvalidMutation"
 	ErrorServiceUnavailable = "ErrorServiceUnavailable"
+	ErrorTooManyRequests    = "ErrorTooManyRequests"
 	ValidHostnameRegex      = "^(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\\-]*[a-zA-Z0-9])\\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\\-]*[A-Za-z0-9])$"
 	Star                    = "_STAR_ALL"
 	GrpcMaxSize             = 256 << 20